@@ -6,6 +6,7 @@ import (
 
 	_ "github.com/undrift/drift/internal/preinit" // must be first — see package doc
 	"github.com/undrift/drift/internal/cmd"
+	"github.com/undrift/drift/internal/errs"
 )
 
 // Version is set via ldflags at build time
@@ -19,7 +20,9 @@ func main() {
 
 	cmd.SetVersion(version)
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		if !errs.IsCancelled(err) && !errs.IsReported(err) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(cmd.ExitCode(err))
 	}
 }
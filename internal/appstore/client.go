@@ -0,0 +1,365 @@
+// Package appstore is a small App Store Connect API client for an app's
+// TestFlight builds, and the metadata drift records on them.
+package appstore
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/metrics"
+)
+
+// DefaultBaseURL is the App Store Connect API.
+const DefaultBaseURL = "https://api.appstoreconnect.apple.com"
+
+// tokenLifetime is how long a signed token is valid; Apple rejects more
+// than 20 minutes.
+const tokenLifetime = 15 * time.Minute
+
+// Client calls the App Store Connect API with a team API key.
+type Client struct {
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+
+	keyID      string
+	issuerID   string
+	key        *ecdsa.PrivateKey
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the API key keyID, issued by issuerID,
+// whose private key is the .p8 file at keyPath.
+func NewClient(keyID, issuerID, keyPath string) (*Client, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, errs.Configf("failed to read App Store Connect key: %v", err)
+	}
+	key, err := ParsePrivateKey(data)
+	if err != nil {
+		return nil, errs.Configf("%s: %v", keyPath, err)
+	}
+	return &Client{
+		BaseURL:  DefaultBaseURL,
+		keyID:    keyID,
+		issuerID: issuerID,
+		key:      key,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: metrics.Transport(nil),
+		},
+	}, nil
+}
+
+// ParsePrivateKey parses a PEM-encoded P-256 key as downloaded from App
+// Store Connect (AuthKey_XXXXXXXXXX.p8).
+func ParsePrivateKey(pemData []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("not a PEM-encoded key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an ECDSA key")
+	}
+	return key, nil
+}
+
+// token signs a short-lived ES256 JWT for the API.
+func (c *Client) token() (string, error) {
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": c.keyID, "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss": c.issuerID,
+		"iat": now.Unix(),
+		"exp": now.Add(tokenLifetime).Unix(),
+		"aud": "appstoreconnect-v1",
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign App Store Connect token: %w", err)
+	}
+	// JWS wants the fixed-size r || s encoding, not ASN.1.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// apiError is the error document the API returns.
+type apiError struct {
+	Errors []struct {
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	} `json:"errors"`
+}
+
+// get fetches path with query and decodes the JSON response into out.
+func (c *Client) get(path string, query url.Values, out interface{}) error {
+	return c.do("GET", path, query, nil, out)
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into out, if out is not nil.
+func (c *Client) do(method, path string, query url.Values, body, out interface{}) error {
+	token, err := c.token()
+	if err != nil {
+		return err
+	}
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	u := base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errs.Networkf("failed to reach App Store Connect: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := string(respBody)
+		var apiErr apiError
+		if json.Unmarshal(respBody, &apiErr) == nil && len(apiErr.Errors) > 0 {
+			msg = apiErr.Errors[0].Detail
+			if msg == "" {
+				msg = apiErr.Errors[0].Title
+			}
+		}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return errs.Authf("App Store Connect rejected the API key (status %d): %s", resp.StatusCode, msg)
+		}
+		return fmt.Errorf("App Store Connect API error (status %d): %s", resp.StatusCode, msg)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse App Store Connect response: %w", err)
+	}
+	return nil
+}
+
+// AppID returns the App Store Connect ID of the app with bundleID.
+func (c *Client) AppID(bundleID string) (string, error) {
+	var resp struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	query := url.Values{"filter[bundleId]": {bundleID}, "fields[apps]": {"bundleId"}}
+	if err := c.get("/v1/apps", query, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Data) == 0 {
+		return "", fmt.Errorf("no app with bundle ID %s in App Store Connect", bundleID)
+	}
+	return resp.Data[0].ID, nil
+}
+
+// Build is an uploaded build of an app.
+type Build struct {
+	ID string `json:"id"`
+	// Version is the build number (CFBundleVersion).
+	Version string `json:"version"`
+	// MarketingVersion is the version the build belongs to
+	// (CFBundleShortVersionString).
+	MarketingVersion string `json:"marketing_version"`
+	// ProcessingState is PROCESSING, FAILED, INVALID or VALID.
+	ProcessingState string    `json:"processing_state"`
+	Expired         bool      `json:"expired"`
+	UploadedDate    time.Time `json:"uploaded_date"`
+	// WhatToTest is the build's TestFlight notes, preferring en-US.
+	WhatToTest string `json:"what_to_test,omitempty"`
+
+	localizationID string
+}
+
+// Number returns the build number as an integer, or 0 when it is not one.
+func (b Build) Number() int {
+	n, err := strconv.Atoi(b.Version)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Metadata returns the drift metadata in the build's What to Test notes.
+func (b Build) Metadata() (Metadata, bool) {
+	return ParseMetadata(b.WhatToTest)
+}
+
+// relationship is a JSON:API resource linkage.
+type relationship struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// ids returns the resource IDs a to-one or to-many linkage points at.
+func (r relationship) ids() []string {
+	type ref struct {
+		ID string `json:"id"`
+	}
+	var many []ref
+	if json.Unmarshal(r.Data, &many) != nil {
+		var one ref
+		if json.Unmarshal(r.Data, &one) != nil || one.ID == "" {
+			return nil
+		}
+		many = []ref{one}
+	}
+	ids := make([]string, len(many))
+	for i, r := range many {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+// Builds returns up to limit of the app's most recently uploaded builds,
+// with their marketing version and What to Test notes.
+func (c *Client) Builds(appID string, limit int) ([]Build, error) {
+	var resp struct {
+		Data []struct {
+			ID         string `json:"id"`
+			Attributes struct {
+				Version         string    `json:"version"`
+				ProcessingState string    `json:"processingState"`
+				Expired         bool      `json:"expired"`
+				UploadedDate    time.Time `json:"uploadedDate"`
+			} `json:"attributes"`
+			Relationships struct {
+				PreReleaseVersion      relationship `json:"preReleaseVersion"`
+				BetaBuildLocalizations relationship `json:"betaBuildLocalizations"`
+			} `json:"relationships"`
+		} `json:"data"`
+		Included []struct {
+			Type       string `json:"type"`
+			ID         string `json:"id"`
+			Attributes struct {
+				Version    string `json:"version"`
+				Locale     string `json:"locale"`
+				WhatToTest string `json:"whatToTest"`
+			} `json:"attributes"`
+		} `json:"included"`
+	}
+	query := url.Values{
+		"filter[app]": {appID},
+		"sort":        {"-uploadedDate"},
+		"limit":       {strconv.Itoa(limit)},
+		"include":     {"preReleaseVersion,betaBuildLocalizations"},
+	}
+	if err := c.get("/v1/builds", query, &resp); err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	type localization struct{ locale, whatToTest string }
+	localizations := make(map[string]localization)
+	for _, inc := range resp.Included {
+		switch inc.Type {
+		case "preReleaseVersions":
+			versions[inc.ID] = inc.Attributes.Version
+		case "betaBuildLocalizations":
+			localizations[inc.ID] = localization{inc.Attributes.Locale, inc.Attributes.WhatToTest}
+		}
+	}
+
+	builds := make([]Build, 0, len(resp.Data))
+	for _, d := range resp.Data {
+		b := Build{
+			ID:              d.ID,
+			Version:         d.Attributes.Version,
+			ProcessingState: d.Attributes.ProcessingState,
+			Expired:         d.Attributes.Expired,
+			UploadedDate:    d.Attributes.UploadedDate,
+		}
+		if ids := d.Relationships.PreReleaseVersion.ids(); len(ids) > 0 {
+			b.MarketingVersion = versions[ids[0]]
+		}
+		for _, id := range d.Relationships.BetaBuildLocalizations.ids() {
+			l, ok := localizations[id]
+			if !ok || (b.localizationID != "" && l.locale != "en-US") {
+				continue
+			}
+			b.localizationID, b.WhatToTest = id, l.whatToTest
+		}
+		builds = append(builds, b)
+	}
+	return builds, nil
+}
+
+// SetWhatToTest replaces the build's en-US What to Test notes, or the
+// notes of its only localization.
+func (c *Client) SetWhatToTest(b Build, text string) error {
+	if b.localizationID != "" {
+		body := map[string]interface{}{"data": map[string]interface{}{
+			"type":       "betaBuildLocalizations",
+			"id":         b.localizationID,
+			"attributes": map[string]string{"whatToTest": text},
+		}}
+		return c.do("PATCH", "/v1/betaBuildLocalizations/"+b.localizationID, nil, body, nil)
+	}
+	body := map[string]interface{}{"data": map[string]interface{}{
+		"type":       "betaBuildLocalizations",
+		"attributes": map[string]string{"locale": "en-US", "whatToTest": text},
+		"relationships": map[string]interface{}{
+			"build": map[string]interface{}{"data": map[string]string{"type": "builds", "id": b.ID}},
+		},
+	}}
+	return c.do("POST", "/v1/betaBuildLocalizations", nil, body, nil)
+}
+
+// LatestBuildNumber returns the highest numeric build number among builds,
+// or 0 if there is none.
+func LatestBuildNumber(builds []Build) int {
+	latest := 0
+	for _, b := range builds {
+		latest = max(latest, b.Number())
+	}
+	return latest
+}
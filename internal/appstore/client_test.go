@@ -0,0 +1,172 @@
+package appstore
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "AuthKey_TEST.p8")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewClient("KEY123", "issuer-1", keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = server.URL
+	return client, key
+}
+
+func TestClientSignsRequests(t *testing.T) {
+	var authorization string
+	client, key := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/v1/apps" || r.URL.Query().Get("filter[bundleId]") != "com.example.app" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": [{"id": "1234567890"}]}`))
+	})
+
+	id, err := client.AppID("com.example.app")
+	if err != nil || id != "1234567890" {
+		t.Fatalf("AppID() = %q, %v", id, err)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(authorization, "Bearer "), ".")
+	if len(parts) != 3 {
+		t.Fatalf("Authorization = %q, want a bearer JWT", authorization)
+	}
+	var header, claims map[string]interface{}
+	for i, v := range []*map[string]interface{}{&header, &claims} {
+		data, _ := base64.RawURLEncoding.DecodeString(parts[i])
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if header["alg"] != "ES256" || header["kid"] != "KEY123" {
+		t.Errorf("JWT header = %v", header)
+	}
+	if claims["iss"] != "issuer-1" || claims["aud"] != "appstoreconnect-v1" {
+		t.Errorf("JWT claims = %v", claims)
+	}
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if len(sig) != 64 || !ecdsa.Verify(&key.PublicKey, hash[:], r, s) {
+		t.Error("JWT signature does not verify")
+	}
+}
+
+func TestClientBuilds(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("filter[app]") != "app-1" || r.URL.Query().Get("sort") != "-uploadedDate" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"data": [
+			{"id": "b3", "attributes": {"version": "41", "processingState": "PROCESSING", "uploadedDate": "2026-10-15T09:00:00Z"},
+			 "relationships": {"preReleaseVersion": {"data": {"type": "preReleaseVersions", "id": "v2"}}, "betaBuildLocalizations": {"data": []}}},
+			{"id": "b2", "attributes": {"version": "42", "processingState": "VALID", "uploadedDate": "2026-10-14T09:00:00Z"},
+			 "relationships": {"preReleaseVersion": {"data": {"type": "preReleaseVersions", "id": "v2"}},
+			   "betaBuildLocalizations": {"data": [{"type": "betaBuildLocalizations", "id": "l-de"}, {"type": "betaBuildLocalizations", "id": "l-en"}]}}},
+			{"id": "b1", "attributes": {"version": "1.0.7", "processingState": "VALID", "expired": true, "uploadedDate": "2026-09-01T09:00:00Z"},
+			 "relationships": {"preReleaseVersion": {"data": {"type": "preReleaseVersions", "id": "v1"}}}}
+		],
+		"included": [
+			{"type": "preReleaseVersions", "id": "v1", "attributes": {"version": "1.0"}},
+			{"type": "preReleaseVersions", "id": "v2", "attributes": {"version": "1.1"}},
+			{"type": "betaBuildLocalizations", "id": "l-de", "attributes": {"locale": "de-DE", "whatToTest": "Anmeldung"}},
+			{"type": "betaBuildLocalizations", "id": "l-en", "attributes": {"locale": "en-US", "whatToTest": "Login flow\n\ndrift: environment=Production supabase_branch=main"}}
+		]}`))
+	})
+
+	builds, err := client.Builds("app-1", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builds) != 3 || builds[0].ProcessingState != "PROCESSING" || !builds[2].Expired {
+		t.Fatalf("Builds() = %+v", builds)
+	}
+	if builds[0].MarketingVersion != "1.1" || builds[2].MarketingVersion != "1.0" {
+		t.Errorf("marketing versions = %q, %q", builds[0].MarketingVersion, builds[2].MarketingVersion)
+	}
+	if m, ok := builds[1].Metadata(); !ok || m.Environment != "Production" || m.SupabaseBranch != "main" {
+		t.Errorf("build 42 metadata = %+v, %v (notes %q)", m, ok, builds[1].WhatToTest)
+	}
+	if _, ok := builds[0].Metadata(); ok {
+		t.Error("build 41 has no notes but reported metadata")
+	}
+	if got := LatestBuildNumber(builds); got != 42 {
+		t.Errorf("LatestBuildNumber() = %d, want 42", got)
+	}
+}
+
+func TestClientSetWhatToTest(t *testing.T) {
+	var requests []string
+	var bodies []map[string]interface{}
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	})
+
+	if err := client.SetWhatToTest(Build{ID: "b1"}, "notes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetWhatToTest(Build{ID: "b2", localizationID: "l-en"}, "notes"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"POST /v1/betaBuildLocalizations", "PATCH /v1/betaBuildLocalizations/l-en"}
+	if strings.Join(requests, ",") != strings.Join(want, ",") {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+	data, _ := json.Marshal(bodies[0])
+	if !strings.Contains(string(data), `"build":{"data":{"id":"b1","type":"builds"}}`) {
+		t.Errorf("create body = %s", data)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errors": [{"title": "Authentication credentials are missing or invalid.", "detail": "Provide a properly configured and signed bearer token."}]}`))
+	})
+	_, err := client.AppID("com.example.app")
+	if err == nil || !strings.Contains(err.Error(), "status 401): Provide a properly configured") {
+		t.Errorf("AppID() error = %v", err)
+	}
+
+	if _, err := ParsePrivateKey([]byte("not a key")); err == nil {
+		t.Error("ParsePrivateKey() accepted garbage")
+	}
+}
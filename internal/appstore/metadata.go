@@ -0,0 +1,71 @@
+package appstore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// metadataPrefix starts the line drift adds to a build's What to Test notes.
+const metadataPrefix = "drift:"
+
+// Metadata is the Supabase environment a build was produced against, as
+// recorded by 'drift appstore tag'.
+type Metadata struct {
+	Environment    string `json:"environment"`
+	SupabaseBranch string `json:"supabase_branch,omitempty"`
+	GitBranch      string `json:"git_branch,omitempty"`
+}
+
+// String renders m as the line stored in What to Test, e.g.
+//
+//	drift: environment=Development supabase_branch=feature-login git_branch=feature/login
+func (m Metadata) String() string {
+	parts := []string{metadataPrefix, "environment=" + m.Environment}
+	if m.SupabaseBranch != "" {
+		parts = append(parts, "supabase_branch="+m.SupabaseBranch)
+	}
+	if m.GitBranch != "" {
+		parts = append(parts, "git_branch="+m.GitBranch)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseMetadata finds the drift line in What to Test notes.
+func ParseMetadata(whatToTest string) (Metadata, bool) {
+	for _, line := range strings.Split(whatToTest, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), metadataPrefix)
+		if !ok {
+			continue
+		}
+		var m Metadata
+		for _, field := range strings.Fields(rest) {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "environment":
+				m.Environment = value
+			case "supabase_branch":
+				m.SupabaseBranch = value
+			case "git_branch":
+				m.GitBranch = value
+			}
+		}
+		return m, m.Environment != ""
+	}
+	return Metadata{}, false
+}
+
+// WithMetadata returns whatToTest with its drift line replaced by m, or m
+// appended when there is none. Testers' notes are kept.
+func WithMetadata(whatToTest string, m Metadata) string {
+	var kept []string
+	for _, line := range strings.Split(whatToTest, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), metadataPrefix) {
+			kept = append(kept, line)
+		}
+	}
+	notes := strings.TrimRight(strings.Join(kept, "\n"), "\n ")
+	if notes == "" {
+		return m.String()
+	}
+	return fmt.Sprintf("%s\n\n%s", notes, m)
+}
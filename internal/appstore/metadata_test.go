@@ -0,0 +1,29 @@
+package appstore
+
+import "testing"
+
+func TestMetadataRoundTrip(t *testing.T) {
+	m := Metadata{Environment: "Feature", SupabaseBranch: "feature-login", GitBranch: "feature/login"}
+	notes := WithMetadata("Please test the new login screen.", m)
+	want := "Please test the new login screen.\n\ndrift: environment=Feature supabase_branch=feature-login git_branch=feature/login"
+	if notes != want {
+		t.Errorf("WithMetadata() = %q, want %q", notes, want)
+	}
+	if got, ok := ParseMetadata(notes); !ok || got != m {
+		t.Errorf("ParseMetadata() = %+v, %v", got, ok)
+	}
+
+	// Tagging again replaces the line rather than adding another.
+	retagged := WithMetadata(notes, Metadata{Environment: "Production", SupabaseBranch: "main"})
+	want = "Please test the new login screen.\n\ndrift: environment=Production supabase_branch=main"
+	if retagged != want {
+		t.Errorf("retagged = %q, want %q", retagged, want)
+	}
+
+	if got := WithMetadata("", Metadata{Environment: "Development"}); got != "drift: environment=Development" {
+		t.Errorf("WithMetadata(empty) = %q", got)
+	}
+	if _, ok := ParseMetadata("Nothing to see here"); ok {
+		t.Error("ParseMetadata() found metadata in plain notes")
+	}
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/appstore"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+var appstoreCmd = &cobra.Command{
+	Use:     "appstore",
+	Aliases: []string{"asc"},
+	Short:   "TestFlight builds and the environments they were built against",
+	Long: `Read the app's TestFlight builds from the App Store Connect API.
+
+Needs apple.bundle_id in .drift.yaml and an App Store Connect API key in
+.drift.local.yaml:
+
+  apple:
+    app_store_connect:
+      key_id: ABC123DEFG
+      issuer_id: 69a6de7e-0000-0000-0000-000000000000
+      key_path: secrets/AuthKey_ABC123DEFG.p8`,
+}
+
+var appstoreBuildsCmd = &cobra.Command{
+	Use:   "builds",
+	Short: "List recent TestFlight builds with their processing state",
+	Long: `List the most recently uploaded builds with their version, processing
+state, and the Supabase environment each was built against.
+
+The environment comes from the line 'drift appstore tag' adds to the build's
+What to Test notes; untagged builds show "-".`,
+	Example: `  drift appstore builds
+  drift appstore builds --limit 50
+  drift appstore builds --json`,
+	Args: cobra.NoArgs,
+	RunE: Run(runAppstoreBuilds, RequireProject),
+}
+
+var appstoreTagCmd = &cobra.Command{
+	Use:   "tag [build-number]",
+	Short: "Record the current Supabase environment on a TestFlight build",
+	Long: `Add a line naming the Supabase environment in the generated xcconfig to
+the build's What to Test notes, so 'drift appstore builds' can show which
+backend the build talks to. Existing notes are kept; an earlier drift line is
+replaced.
+
+The build defaults to CURRENT_PROJECT_VERSION from the version file, so it
+can run right after an upload, e.g. at the end of a Fastlane beta lane:
+
+  sh("drift appstore tag")`,
+	Example: `  drift appstore tag
+  drift appstore tag 42`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runAppstoreTag, RequireProject),
+}
+
+var (
+	appstoreBuildsLimit int
+	appstoreBuildsJSON  bool
+	appstoreTagDryRun   bool
+)
+
+func init() {
+	appstoreBuildsCmd.Flags().IntVarP(&appstoreBuildsLimit, "limit", "n", 20, "Number of builds to list")
+	appstoreBuildsCmd.Flags().BoolVar(&appstoreBuildsJSON, "json", false, "Output as JSON")
+	appstoreTagCmd.Flags().BoolVar(&appstoreTagDryRun, "dry-run", false, "Show the notes without updating the build")
+
+	appstoreCmd.AddCommand(appstoreBuildsCmd)
+	appstoreCmd.AddCommand(appstoreTagCmd)
+	rootCmd.AddCommand(appstoreCmd)
+}
+
+// appstoreBuildRow is a build as printed by 'drift appstore builds --json'.
+type appstoreBuildRow struct {
+	appstore.Build
+	Drift *appstore.Metadata `json:"drift,omitempty"`
+}
+
+func runAppstoreBuilds(ctx *Context) error {
+	if appstoreBuildsLimit < 1 || appstoreBuildsLimit > 200 {
+		return errs.Validationf("--limit must be between 1 and 200")
+	}
+	_, builds, err := fetchTestFlightBuilds(ctx.Config(), appstoreBuildsLimit)
+	if err != nil {
+		return err
+	}
+
+	if ctx.JSON() {
+		rows := make([]appstoreBuildRow, len(builds))
+		for i, b := range builds {
+			rows[i].Build = b
+			if m, ok := b.Metadata(); ok {
+				rows[i].Drift = &m
+			}
+		}
+		return ctx.PrintJSON(rows)
+	}
+
+	if len(builds) == 0 {
+		ui.Info("No builds uploaded yet")
+		return nil
+	}
+	table := ui.NewTable([]string{"Build", "Version", "Uploaded", "State", "Environment", "Supabase Branch"})
+	for _, b := range builds {
+		state, stateColor := buildState(b)
+		env, branch := "-", "-"
+		if m, ok := b.Metadata(); ok {
+			env = m.Environment
+			if m.SupabaseBranch != "" {
+				branch = m.SupabaseBranch
+			}
+		}
+		table.AddColoredRow([]string{
+			b.Version,
+			b.MarketingVersion,
+			b.UploadedDate.Local().Format("2006-01-02 15:04"),
+			state,
+			env,
+			branch,
+		}, []tablewriter.Colors{{}, {}, {}, stateColor, envTableColor(env), {}})
+	}
+	table.Render()
+	return nil
+}
+
+// buildState returns the state shown for b and its color.
+func buildState(b appstore.Build) (string, tablewriter.Colors) {
+	if b.Expired {
+		return "EXPIRED", ui.TableColor.Normal
+	}
+	switch b.ProcessingState {
+	case "VALID":
+		return b.ProcessingState, ui.TableColor.Green
+	case "PROCESSING":
+		return b.ProcessingState, ui.TableColor.Yellow
+	case "FAILED", "INVALID":
+		return b.ProcessingState, ui.TableColor.Red
+	}
+	return b.ProcessingState, ui.TableColor.Normal
+}
+
+// envTableColor colors an environment name as envColorString does.
+func envTableColor(env string) tablewriter.Colors {
+	switch env {
+	case "Production":
+		return ui.TableColor.Red
+	case "Development":
+		return ui.TableColor.Yellow
+	case "Feature":
+		return ui.TableColor.Green
+	}
+	return ui.TableColor.Normal
+}
+
+func runAppstoreTag(ctx *Context) error {
+	cfg := ctx.Config()
+
+	number := ctx.Arg(0)
+	if number == "" {
+		info, err := xcode.ReadVersionFile(cfg.GetVersionFilePath())
+		if err != nil {
+			return errs.Configf("%v (pass the build number instead)", err)
+		}
+		number = strconv.Itoa(info.BuildNumber)
+	}
+
+	env, err := xcode.GetCurrentEnvironment(cfg.GetXcconfigPath())
+	if err != nil {
+		return errs.Configf("%v (run 'drift env setup' first)", err)
+	}
+	meta := appstore.Metadata{Environment: env}
+	if gen, ok, err := readGeneratedEnvFile(cfg); err == nil && ok {
+		meta.SupabaseBranch, meta.GitBranch = gen.SupabaseBranch, gen.GitBranch
+	}
+
+	client, builds, err := fetchTestFlightBuilds(cfg, 50)
+	if err != nil {
+		return err
+	}
+	var build *appstore.Build
+	for i := range builds {
+		if builds[i].Version == number {
+			build = &builds[i]
+			break
+		}
+	}
+	if build == nil {
+		return errs.Validationf("build %s not found among the latest %d builds (a new upload can take a few minutes to appear)", number, len(builds))
+	}
+
+	notes := appstore.WithMetadata(build.WhatToTest, meta)
+	ui.KeyValue("Build", fmt.Sprintf("%s (%s)", build.Version, build.MarketingVersion))
+	ui.KeyValue("Environment", envColorString(env))
+	if meta.SupabaseBranch != "" {
+		ui.KeyValue("Supabase Branch", ui.Cyan(meta.SupabaseBranch))
+	}
+	if appstoreTagDryRun {
+		ui.NewLine()
+		fmt.Println(notes)
+		return nil
+	}
+	if err := client.SetWhatToTest(*build, notes); err != nil {
+		return err
+	}
+	ui.Successf("Tagged build %s with %s", build.Version, env)
+	return nil
+}
+
+// fetchTestFlightBuilds returns up to limit of the newest builds of
+// apple.bundle_id, and the client used, for commands that write back.
+func fetchTestFlightBuilds(cfg *config.Config, limit int) (*appstore.Client, []appstore.Build, error) {
+	client, err := appStoreClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	sp := ui.NewSpinner("Fetching TestFlight builds")
+	sp.Start()
+	defer sp.Stop()
+	appID, err := client.AppID(cfg.Apple.BundleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	builds, err := client.Builds(appID, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, builds, nil
+}
+
+// appStoreClient creates an App Store Connect client from
+// apple.app_store_connect. APP_STORE_CONNECT_KEY_ID, _ISSUER_ID and
+// _KEY_PATH override it, for CI.
+func appStoreClient(cfg *config.Config) (*appstore.Client, error) {
+	if cfg.Apple.BundleID == "" {
+		return nil, errs.Configf("apple.bundle_id is not set in .drift.yaml")
+	}
+	asc := cfg.Apple.AppStoreConnect
+	for env, field := range map[string]*string{
+		"APP_STORE_CONNECT_KEY_ID":    &asc.KeyID,
+		"APP_STORE_CONNECT_ISSUER_ID": &asc.IssuerID,
+		"APP_STORE_CONNECT_KEY_PATH":  &asc.KeyPath,
+	} {
+		if v := os.Getenv(env); v != "" {
+			*field = v
+		}
+	}
+	if !asc.IsSet() {
+		return nil, errs.Configf("no App Store Connect API key configured\n\n" +
+			"Set apple.app_store_connect (key_id, issuer_id, key_path) in .drift.local.yaml")
+	}
+	keyPath := asc.KeyPath
+	if !filepath.IsAbs(keyPath) {
+		keyPath = filepath.Join(cfg.ProjectRoot(), keyPath)
+	}
+	return appstore.NewClient(asc.KeyID, asc.IssuerID, keyPath)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/appstore"
+	"github.com/undrift/drift/internal/config"
+)
+
+func TestAppStoreClientConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if _, err := appStoreClient(cfg); err == nil || !strings.Contains(err.Error(), "apple.bundle_id") {
+		t.Errorf("missing bundle ID: err = %v", err)
+	}
+
+	cfg.Apple.BundleID = "com.example.app"
+	if _, err := appStoreClient(cfg); err == nil || !strings.Contains(err.Error(), ".drift.local.yaml") {
+		t.Errorf("missing key: err = %v", err)
+	}
+
+	// Environment variables fill in what the config leaves out.
+	cfg.Apple.AppStoreConnect = config.AppStoreConnectConfig{KeyID: "KEY", IssuerID: "issuer"}
+	t.Setenv("APP_STORE_CONNECT_KEY_PATH", "/nonexistent/AuthKey_KEY.p8")
+	if _, err := appStoreClient(cfg); err == nil || !strings.Contains(err.Error(), "/nonexistent/AuthKey_KEY.p8") {
+		t.Errorf("key path from env: err = %v", err)
+	}
+}
+
+func TestBuildState(t *testing.T) {
+	tests := []struct {
+		build appstore.Build
+		want  string
+	}{
+		{appstore.Build{ProcessingState: "VALID"}, "VALID"},
+		{appstore.Build{ProcessingState: "PROCESSING"}, "PROCESSING"},
+		{appstore.Build{ProcessingState: "VALID", Expired: true}, "EXPIRED"},
+	}
+	for _, tt := range tests {
+		if got, _ := buildState(tt.build); got != tt.want {
+			t.Errorf("buildState(%+v) = %s, want %s", tt.build, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage Supabase Auth settings",
+	Long: `Manage Supabase Auth settings for each environment.
+
+Auth settings belong to the Supabase branch, so every preview branch starts
+with its own site URL and redirect allow list.`,
+}
+
+var authRedirectsCmd = &cobra.Command{
+	Use:   "redirects",
+	Short: "Manage the auth site URL and allowed redirect URLs",
+	Long: `Manage the site URL and allowed redirect URLs of a Supabase branch.
+
+The URLs each environment needs come from environments.<env>.site_url and
+environments.<env>.redirect_urls in .drift.yaml. They are templates that may
+use:
+
+  {branch}            Git branch (feature/login)
+  {branch-slug}       Git branch as preview hosts spell it (feature-login)
+  {supabase-branch}   Supabase branch name
+  {ref}               Supabase project ref
+
+The target branch is resolved from the current git branch, or --branch.`,
+}
+
+var authRedirectsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the live site URL and redirect URLs",
+	Long: `Show the live site URL and allowed redirect URLs of the target branch,
+marking URLs that .drift.yaml expects but the branch does not allow yet, and
+allowed URLs that .drift.yaml does not mention.`,
+	Example: `  drift auth redirects list
+  drift auth redirects list -b feature/login
+  drift auth redirects list --json`,
+	Args: cobra.NoArgs,
+	RunE: Run(runAuthRedirectsList, RequireProject),
+}
+
+var authRedirectsAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Allow one more redirect URL",
+	Long: `Add a URL to the allowed redirect URLs of the target branch. The URL may
+use the same placeholders as environments.<env>.redirect_urls.`,
+	Example: `  drift auth redirects add 'http://localhost:3000/**'
+  drift auth redirects add 'https://{branch-slug}.vercel.app/**' -b feature/login`,
+	Args: cobra.ExactArgs(1),
+	RunE: Run(runAuthRedirectsAdd, RequireProject),
+}
+
+var authRedirectsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Apply the URLs from .drift.yaml to the target branch",
+	Long: `Set the site URL and add the redirect URLs configured for the target's
+environment. URLs allowed on the branch but missing from .drift.yaml are kept
+unless --prune is set.
+
+Run it after creating a preview branch so OAuth sign-in works against the
+branch's frontend deployment.`,
+	Example: `  drift auth redirects sync
+  drift auth redirects sync -b feature/login --dry-run
+  drift auth redirects sync --prune -y`,
+	Args: cobra.NoArgs,
+	RunE: Run(runAuthRedirectsSync, RequireProject),
+}
+
+var (
+	authBranchFlag     string
+	authRedirectsJSON  bool
+	authRedirectsDry   bool
+	authRedirectsPrune bool
+)
+
+func init() {
+	authRedirectsCmd.PersistentFlags().StringVarP(&authBranchFlag, "branch", "b", "", "Target Supabase branch (default: current git branch)")
+	authRedirectsListCmd.Flags().BoolVar(&authRedirectsJSON, "json", false, "Output as JSON")
+	authRedirectsSyncCmd.Flags().BoolVar(&authRedirectsDry, "dry-run", false, "Show the changes without applying them")
+	authRedirectsSyncCmd.Flags().BoolVar(&authRedirectsPrune, "prune", false, "Also remove allowed URLs that .drift.yaml does not list")
+
+	authRedirectsCmd.AddCommand(authRedirectsListCmd)
+	authRedirectsCmd.AddCommand(authRedirectsAddCmd)
+	authRedirectsCmd.AddCommand(authRedirectsSyncCmd)
+	authCmd.AddCommand(authRedirectsCmd)
+	rootCmd.AddCommand(authCmd)
+}
+
+// authRedirects is a branch's auth URLs, live or as configured.
+type authRedirects struct {
+	SiteURL      string   `json:"site_url"`
+	RedirectURLs []string `json:"redirect_urls"`
+}
+
+// authRedirectsPlan is what sync changes on a branch.
+type authRedirectsPlan struct {
+	SiteURL string   `json:"site_url,omitempty"` // new site URL, "" to keep it
+	Add     []string `json:"add,omitempty"`
+	Remove  []string `json:"remove,omitempty"`
+}
+
+func (p authRedirectsPlan) empty() bool {
+	return p.SiteURL == "" && len(p.Add) == 0 && len(p.Remove) == 0
+}
+
+// expandAuthURL fills in the placeholders of an auth URL template for info.
+func expandAuthURL(template string, info *supabase.BranchInfo) string {
+	supabaseBranch := ""
+	if info.SupabaseBranch != nil {
+		supabaseBranch = info.SupabaseBranch.Name
+	}
+	return strings.NewReplacer(
+		"{branch-slug}", branchSlug(info.GitBranch),
+		"{branch}", info.GitBranch,
+		"{supabase-branch}", supabaseBranch,
+		"{ref}", info.ProjectRef,
+	).Replace(template)
+}
+
+// branchSlug spells branch the way preview hosts put it in hostnames:
+// lowercase, with each run of other characters turned into one hyphen.
+func branchSlug(branch string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(branch) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// configuredAuthRedirects expands the auth URLs .drift.yaml sets for info's
+// environment.
+func configuredAuthRedirects(cfg *config.Config, info *supabase.BranchInfo) authRedirects {
+	var want authRedirects
+	envCfg := cfg.GetEnvironmentConfig(string(info.Environment))
+	if envCfg == nil {
+		return want
+	}
+	if envCfg.SiteURL != "" {
+		want.SiteURL = expandAuthURL(envCfg.SiteURL, info)
+	}
+	for _, template := range envCfg.RedirectURLs {
+		want.RedirectURLs = append(want.RedirectURLs, expandAuthURL(template, info))
+	}
+	return want
+}
+
+// liveAuthRedirects reads the auth URLs from the Management API settings,
+// which keep the allow list as one comma-separated string.
+func liveAuthRedirects(settings map[string]interface{}) authRedirects {
+	var live authRedirects
+	live.SiteURL, _ = settings["site_url"].(string)
+	list, _ := settings["uri_allow_list"].(string)
+	for _, u := range strings.Split(list, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			live.RedirectURLs = append(live.RedirectURLs, u)
+		}
+	}
+	return live
+}
+
+// planAuthRedirects compares live with want. With prune, live URLs that want
+// does not list are removed.
+func planAuthRedirects(live, want authRedirects, prune bool) authRedirectsPlan {
+	var plan authRedirectsPlan
+	if want.SiteURL != "" && want.SiteURL != live.SiteURL {
+		plan.SiteURL = want.SiteURL
+	}
+	allowed := stringSet(live.RedirectURLs)
+	for _, u := range want.RedirectURLs {
+		if !allowed[u] {
+			plan.Add = append(plan.Add, u)
+			allowed[u] = true
+		}
+	}
+	if prune {
+		wanted := stringSet(want.RedirectURLs)
+		for _, u := range live.RedirectURLs {
+			if !wanted[u] {
+				plan.Remove = append(plan.Remove, u)
+			}
+		}
+	}
+	return plan
+}
+
+// changes is the auth config update that applies the plan to live.
+func (p authRedirectsPlan) changes(live authRedirects) map[string]interface{} {
+	changes := make(map[string]interface{})
+	if p.SiteURL != "" {
+		changes["site_url"] = p.SiteURL
+	}
+	if len(p.Add) > 0 || len(p.Remove) > 0 {
+		removed := stringSet(p.Remove)
+		var urls []string
+		for _, u := range live.RedirectURLs {
+			if !removed[u] {
+				urls = append(urls, u)
+			}
+		}
+		changes["uri_allow_list"] = strings.Join(append(urls, p.Add...), ",")
+	}
+	return changes
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// loadAuthRedirects resolves the target branch and reads its live auth URLs.
+func loadAuthRedirects(ctx *Context) (*supabase.BranchInfo, *supabase.ManagementClient, authRedirects, error) {
+	info, err := ctx.Target(authBranchFlag)
+	if err != nil {
+		return nil, nil, authRedirects{}, err
+	}
+	mgmt, err := supabase.NewManagementClient()
+	if err != nil {
+		return nil, nil, authRedirects{}, err
+	}
+
+	sp := ui.NewSpinner("Reading auth settings")
+	sp.Start()
+	settings, err := mgmt.GetAuthConfig(info.ProjectRef)
+	if err != nil {
+		sp.Fail("Failed to read auth settings")
+		return nil, nil, authRedirects{}, err
+	}
+	sp.Stop()
+	return info, mgmt, liveAuthRedirects(settings), nil
+}
+
+func printAuthTarget(info *supabase.BranchInfo) {
+	ui.Header("Auth Redirects: " + info.SupabaseBranch.Name)
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+}
+
+func runAuthRedirectsList(ctx *Context) error {
+	info, _, live, err := loadAuthRedirects(ctx)
+	if err != nil {
+		return err
+	}
+	want := configuredAuthRedirects(ctx.Config(), info)
+
+	type urlStatus struct {
+		URL    string `json:"url"`
+		Status string `json:"status"`
+	}
+	var urls []urlStatus
+	allowed := stringSet(live.RedirectURLs)
+	wanted := stringSet(want.RedirectURLs)
+	for _, u := range live.RedirectURLs {
+		status := "allowed"
+		if len(want.RedirectURLs) > 0 && !wanted[u] {
+			status = "not in config"
+		}
+		urls = append(urls, urlStatus{URL: u, Status: status})
+	}
+	for _, u := range want.RedirectURLs {
+		if !allowed[u] {
+			urls = append(urls, urlStatus{URL: u, Status: "missing"})
+		}
+	}
+
+	if ctx.JSON() {
+		return ctx.PrintJSON(struct {
+			Branch            string      `json:"branch"`
+			Environment       string      `json:"environment"`
+			ProjectRef        string      `json:"project_ref"`
+			SiteURL           string      `json:"site_url"`
+			ConfiguredSiteURL string      `json:"configured_site_url,omitempty"`
+			RedirectURLs      []urlStatus `json:"redirect_urls"`
+		}{info.SupabaseBranch.Name, string(info.Environment), info.ProjectRef, live.SiteURL, want.SiteURL, urls})
+	}
+
+	printAuthTarget(info)
+	ui.KeyValue("Site URL", ui.Cyan(live.SiteURL))
+	if want.SiteURL != "" && want.SiteURL != live.SiteURL {
+		ui.Warningf("Site URL differs from .drift.yaml (%s)", want.SiteURL)
+	}
+	ui.NewLine()
+
+	if len(urls) == 0 {
+		ui.Info("No redirect URLs are allowed")
+		return nil
+	}
+	missing := 0
+	table := ui.NewTable([]string{"Redirect URL", "Status"})
+	for _, u := range urls {
+		status := ui.Green(u.Status)
+		switch u.Status {
+		case "missing":
+			status = ui.Yellow(u.Status)
+			missing++
+		case "not in config":
+			status = ui.Dim(u.Status)
+		}
+		table.AddRow([]string{u.URL, status})
+	}
+	table.Render()
+
+	if missing > 0 || (want.SiteURL != "" && want.SiteURL != live.SiteURL) {
+		ui.NewLine()
+		ui.Info("Run 'drift auth redirects sync' to apply .drift.yaml")
+	}
+	return nil
+}
+
+func runAuthRedirectsAdd(ctx *Context) error {
+	info, mgmt, live, err := loadAuthRedirects(ctx)
+	if err != nil {
+		return err
+	}
+	u := expandAuthURL(ctx.Arg(0), info)
+	if !strings.Contains(u, "://") || strings.Contains(u, ",") {
+		return errs.Validationf("invalid redirect URL %q: expected scheme://host[/path] without commas", u)
+	}
+	if stringSet(live.RedirectURLs)[u] {
+		ui.Infof("%s is already allowed on %s", u, info.SupabaseBranch.Name)
+		return nil
+	}
+
+	return applyAuthRedirects(ctx, mgmt, info, live, authRedirectsPlan{Add: []string{u}}, "auth redirects add")
+}
+
+func runAuthRedirectsSync(ctx *Context) error {
+	info, mgmt, live, err := loadAuthRedirects(ctx)
+	if err != nil {
+		return err
+	}
+	want := configuredAuthRedirects(ctx.Config(), info)
+	if want.SiteURL == "" && len(want.RedirectURLs) == 0 {
+		return errs.Configf("no auth URLs configured for %s; set environments.<env>.site_url or redirect_urls in .drift.yaml", info.Environment)
+	}
+
+	printAuthTarget(info)
+	plan := planAuthRedirects(live, want, authRedirectsPrune)
+	if plan.empty() {
+		ui.Success("Auth URLs match .drift.yaml")
+		return nil
+	}
+
+	ui.NewLine()
+	if plan.SiteURL != "" {
+		ui.KeyValue("Site URL", fmt.Sprintf("%s → %s", ui.Dim(live.SiteURL), ui.Cyan(plan.SiteURL)))
+	}
+	for _, u := range plan.Add {
+		ui.Infof("%s %s", ui.Green("+"), u)
+	}
+	for _, u := range plan.Remove {
+		ui.Infof("%s %s", ui.Red("-"), u)
+	}
+	ui.NewLine()
+
+	return applyAuthRedirects(ctx, mgmt, info, live, plan, "auth redirects sync")
+}
+
+// applyAuthRedirects updates info's live auth URLs after the usual
+// environment confirmation.
+func applyAuthRedirects(ctx *Context, mgmt *supabase.ManagementClient, info *supabase.BranchInfo, live authRedirects, plan authRedirectsPlan, operation string) error {
+	return ctx.Apply(fmt.Sprintf("update the auth URLs of %s", info.SupabaseBranch.Name), func() error {
+		cfg := ctx.Config()
+		confirmed, err := ConfirmDeploymentOperation(info, cfg, "update auth redirect URLs")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return errs.Cancelled(operation)
+		}
+
+		start := time.Now()
+		err = mgmt.UpdateAuthConfig(info.ProjectRef, plan.changes(live))
+		notifyOperation(cfg, operation, string(info.Environment), info.SupabaseBranch.Name, start, err)
+		if err != nil {
+			return err
+		}
+		ui.Successf("Updated auth URLs on %s", info.SupabaseBranch.Name)
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestBranchSlug(t *testing.T) {
+	tests := map[string]string{
+		"feature/login":      "feature-login",
+		"Fix/OAuth_Callback": "fix-oauth-callback",
+		"feat//double--dash": "feat-double-dash",
+		"-leading/trailing-": "leading-trailing",
+		"development":        "development",
+	}
+	for branch, want := range tests {
+		if got := branchSlug(branch); got != want {
+			t.Errorf("branchSlug(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
+
+func TestConfiguredAuthRedirects(t *testing.T) {
+	cfg := &config.Config{
+		Environments: map[string]config.EnvironmentConfig{
+			"feature": {
+				SiteURL: "https://{branch-slug}.vercel.app",
+				RedirectURLs: []string{
+					"https://{branch-slug}.vercel.app/**",
+					"myapp://{supabase-branch}/callback",
+					"https://{ref}.example.com/{branch}",
+				},
+			},
+		},
+	}
+	info := &supabase.BranchInfo{
+		GitBranch:      "feature/login",
+		SupabaseBranch: &supabase.Branch{Name: "feature-login-sb"},
+		Environment:    supabase.EnvFeature,
+		ProjectRef:     "abc123",
+	}
+
+	got := configuredAuthRedirects(cfg, info)
+	want := authRedirects{
+		SiteURL: "https://feature-login.vercel.app",
+		RedirectURLs: []string{
+			"https://feature-login.vercel.app/**",
+			"myapp://feature-login-sb/callback",
+			"https://abc123.example.com/feature/login",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("configuredAuthRedirects() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlanAuthRedirects(t *testing.T) {
+	live := liveAuthRedirects(map[string]interface{}{
+		"site_url":       "http://localhost:3000",
+		"uri_allow_list": "http://localhost:3000/**, https://old.example.com",
+	})
+	want := authRedirects{
+		SiteURL:      "https://app.example.com",
+		RedirectURLs: []string{"http://localhost:3000/**", "https://app.example.com/**"},
+	}
+
+	plan := planAuthRedirects(live, want, false)
+	if plan.SiteURL != "https://app.example.com" || !reflect.DeepEqual(plan.Add, []string{"https://app.example.com/**"}) || plan.Remove != nil {
+		t.Fatalf("planAuthRedirects() = %+v", plan)
+	}
+	changes := plan.changes(live)
+	if changes["uri_allow_list"] != "http://localhost:3000/**,https://old.example.com,https://app.example.com/**" {
+		t.Errorf("uri_allow_list = %v, want live URLs kept and new one appended", changes["uri_allow_list"])
+	}
+
+	plan = planAuthRedirects(live, want, true)
+	if !reflect.DeepEqual(plan.Remove, []string{"https://old.example.com"}) {
+		t.Fatalf("planAuthRedirects(prune) Remove = %v", plan.Remove)
+	}
+	if got := plan.changes(live)["uri_allow_list"]; got != "http://localhost:3000/**,https://app.example.com/**" {
+		t.Errorf("pruned uri_allow_list = %v", got)
+	}
+
+	if plan := planAuthRedirects(live, authRedirects{RedirectURLs: live.RedirectURLs}, false); !plan.empty() {
+		t.Errorf("planAuthRedirects() with nothing to change = %+v, want empty", plan)
+	}
+}
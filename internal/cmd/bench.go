@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark Supabase services",
+	Long:  `Measure latency against Supabase services for the resolved branch.`,
+}
+
+var benchDbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Benchmark database connection and query latency",
+	Long: `Measure database latency for the resolved branch.
+
+For each pooler mode (transaction on 6543, session on 5432) this records:
+  - TCP connect time
+  - TLS negotiation + handshake time
+  - SELECT 1 round-trip time (via psql \timing over one session)
+
+Results are printed as min/p50/p90/p99/max so environments can be
+compared with numbers instead of impressions.
+
+Connection timing needs no credentials. Query timing needs the
+database password: non-production branches use the API-provided
+password; production requires --password or PROD_PASSWORD.`,
+	Example: `  drift bench db                   # Both pooler modes, 10 iterations
+  drift bench db -n 50             # More samples
+  drift bench db --mode session    # Session mode only
+  drift bench db -b development    # Benchmark the development branch
+  drift bench db --skip-query      # Connection timing only`,
+	Args: cobra.NoArgs,
+	RunE: runBenchDb,
+}
+
+var (
+	benchBranchFlag     string
+	benchIterationsFlag int
+	benchModeFlag       string
+	benchPasswordFlag   string
+	benchSkipQueryFlag  bool
+	benchTimeoutFlag    time.Duration
+)
+
+func init() {
+	benchDbCmd.Flags().StringVarP(&benchBranchFlag, "branch", "b", "", "Target Supabase branch (default: current git branch)")
+	benchDbCmd.Flags().IntVarP(&benchIterationsFlag, "iterations", "n", 10, "Number of samples per measurement")
+	benchDbCmd.Flags().StringVar(&benchModeFlag, "mode", "both", "Pooler mode to benchmark (both|transaction|session)")
+	benchDbCmd.Flags().StringVar(&benchPasswordFlag, "password", "", "Database password for query timing (or use env var)")
+	benchDbCmd.Flags().BoolVar(&benchSkipQueryFlag, "skip-query", false, "Only measure connection establishment")
+	benchDbCmd.Flags().DurationVar(&benchTimeoutFlag, "timeout", 10*time.Second, "Timeout per connection attempt")
+
+	benchCmd.AddCommand(benchDbCmd)
+	rootCmd.AddCommand(benchCmd)
+}
+
+func benchPoolerModes(mode string) ([]string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "both":
+		return []string{"transaction", "session"}, nil
+	case "transaction", "session":
+		return []string{strings.ToLower(strings.TrimSpace(mode))}, nil
+	default:
+		return nil, fmt.Errorf("invalid --mode value %q (use both, transaction, or session)", mode)
+	}
+}
+
+func runBenchDb(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	if benchIterationsFlag < 1 {
+		return fmt.Errorf("--iterations must be at least 1")
+	}
+	modes, err := benchPoolerModes(benchModeFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.LoadOrDefault()
+	client := supabase.NewClient()
+
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	sp := ui.NewSpinner("Resolving target environment")
+	sp.Start()
+	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, benchBranchFlag)
+	if err != nil {
+		sp.Stop()
+		return err
+	}
+
+	sp.UpdateMessage("Fetching connection info")
+	connInfo, connErr := client.GetBranchConnectionInfo(info.SupabaseBranch.GitBranch)
+	sp.Stop()
+
+	poolerHost := cfg.Database.GetPoolerHostForBranch(info.SupabaseBranch.GitBranch)
+	var password string
+	if connErr == nil && connInfo != nil {
+		if connInfo.PoolerHost != "" {
+			poolerHost = connInfo.PoolerHost
+		}
+		if info.Environment != supabase.EnvProduction {
+			password = supabase.ExtractPasswordFromURL(connInfo.PostgresURL)
+		}
+	} else if IsVerbose() {
+		ui.Warningf("Could not get connection info via API: %v", connErr)
+	}
+
+	if password == "" && !benchSkipQueryFlag {
+		password = benchPasswordFlag
+		if password == "" {
+			envKey := "dev"
+			if info.Environment == supabase.EnvProduction {
+				envKey = "prod"
+			}
+			password = getDbPassword(envKey)
+		}
+	}
+
+	ui.Header("Database Benchmark")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Pooler Host", ui.Cyan(poolerHost))
+	ui.KeyValue("Iterations", fmt.Sprintf("%d", benchIterationsFlag))
+	if info.IsFallback {
+		ui.Warningf("Using fallback target branch: %s", info.SupabaseBranch.GitBranch)
+	}
+	runQueries := !benchSkipQueryFlag
+	if runQueries && password == "" {
+		ui.Warning("No database password available; skipping query timing (use --password or PROD_PASSWORD)")
+		runQueries = false
+	}
+	ui.NewLine()
+
+	table := ui.NewTable([]string{"Mode", "Metric", "Min", "P50", "P90", "P99", "Max"})
+	for _, mode := range modes {
+		port := dbConnections(cfg).Port(database.PoolerMode(mode))
+
+		sp = ui.NewSpinner(fmt.Sprintf("Measuring %s mode connections (port %d)", mode, port))
+		sp.Start()
+		var tcpSamples, tlsSamples []time.Duration
+		var failures int
+		var lastErr error
+		for i := 0; i < benchIterationsFlag; i++ {
+			timing, err := database.MeasureConnect(poolerHost, port, benchTimeoutFlag)
+			if err != nil {
+				failures++
+				lastErr = err
+				continue
+			}
+			tcpSamples = append(tcpSamples, timing.TCP)
+			tlsSamples = append(tlsSamples, timing.TLS)
+		}
+		sp.Stop()
+
+		if failures > 0 {
+			ui.Warningf("%s mode: %d/%d connection attempts failed: %v", mode, failures, benchIterationsFlag, lastErr)
+		}
+		addBenchRow(table, mode, "tcp connect", tcpSamples)
+		addBenchRow(table, mode, "tls handshake", tlsSamples)
+
+		if !runQueries {
+			continue
+		}
+
+		sp = ui.NewSpinner(fmt.Sprintf("Measuring %s mode query latency", mode))
+		sp.Start()
+		opts := database.DefaultRestoreOptions()
+		opts.Host = poolerHost
+		opts.Port = port
+		opts.User = fmt.Sprintf("postgres.%s", info.ProjectRef)
+		opts.Password = password
+		querySamples, err := database.MeasureQueryLatency(opts, benchIterationsFlag)
+		sp.Stop()
+		if err != nil {
+			ui.Warningf("%s mode: %v", mode, err)
+			continue
+		}
+		addBenchRow(table, mode, "select 1", querySamples)
+	}
+
+	table.Render()
+	return nil
+}
+
+func addBenchRow(table *ui.Table, mode, metric string, samples []time.Duration) {
+	if len(samples) == 0 {
+		table.AddRow([]string{mode, metric, "-", "-", "-", "-", "-"})
+		return
+	}
+	stats := database.ComputeLatencyStats(samples)
+	table.AddRow([]string{
+		mode,
+		metric,
+		formatBenchDuration(stats.Min),
+		formatBenchDuration(stats.P50),
+		formatBenchDuration(stats.P90),
+		formatBenchDuration(stats.P99),
+		formatBenchDuration(stats.Max),
+	})
+}
+
+func formatBenchDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"sort"
-	"strings"
 
 	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/git"
 	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
 )
@@ -23,49 +25,31 @@ type ResolveTargetOptions struct {
 // ResolveSupabaseTarget resolves the effective Supabase branch with explicit fallback behavior.
 func ResolveSupabaseTarget(client *supabase.Client, opts ResolveTargetOptions) (*supabase.BranchInfo, error) {
 	targetBranch := opts.GitBranch
-	info := &supabase.BranchInfo{
-		GitBranch: opts.GitBranch,
-	}
-
 	if opts.OverrideBranch != "" {
 		targetBranch = opts.OverrideBranch
-		info.IsOverride = true
-		info.OverrideFrom = opts.GitBranch
 	}
 	if IsVerbose() {
 		ui.Infof("Branch resolution: git=%s override=%s fallback=%s", opts.GitBranch, opts.OverrideBranch, opts.FallbackBranch)
 	}
 
-	branch, env, err := client.ResolveBranch(targetBranch)
-	if err != nil {
-		return nil, err
-	}
-	if branch != nil {
-		if opts.DisallowProdSelection && isProductionSupabaseBranch(branch) && targetBranch != opts.GitBranch {
-			return nil, fmt.Errorf("refusing to target production branch '%s' via override; remove override or use a non-production branch", targetBranch)
-		}
+	info, err := client.ResolveTarget(supabase.TargetOptions{
+		GitBranch:             opts.GitBranch,
+		OverrideBranch:        opts.OverrideBranch,
+		FallbackBranch:        opts.FallbackBranch,
+		DisallowProdSelection: opts.DisallowProdSelection,
+	})
+	if err == nil {
 		if IsVerbose() {
-			ui.Infof("Resolved exact branch match: %s (%s) -> project %s", branch.GitBranch, env, branch.ProjectRef)
+			if info.IsFallback {
+				ui.Infof("Using configured fallback branch: %s (%s)", info.SupabaseBranch.GitBranch, info.Environment)
+			} else {
+				ui.Infof("Resolved exact branch match: %s (%s) -> project %s", info.SupabaseBranch.GitBranch, info.Environment, info.ProjectRef)
+			}
 		}
-		return buildBranchInfo(client, info, branch, env, false), nil
+		return info, nil
 	}
-
-	fallback := strings.TrimSpace(opts.FallbackBranch)
-	if fallback != "" {
-		fallbackBranch, fallbackEnv, err := client.ResolveBranch(fallback)
-		if err != nil {
-			return nil, err
-		}
-		if fallbackBranch == nil {
-			return nil, fmt.Errorf("fallback branch '%s' was not found", fallback)
-		}
-		if opts.DisallowProdSelection && isProductionSupabaseBranch(fallbackBranch) {
-			return nil, fmt.Errorf("refusing to use production branch '%s' as fallback target", fallback)
-		}
-		if IsVerbose() {
-			ui.Infof("Using configured fallback branch: %s (%s)", fallbackBranch.GitBranch, fallbackEnv)
-		}
-		return buildBranchInfo(client, info, fallbackBranch, fallbackEnv, true), nil
+	if !errors.Is(err, supabase.ErrNoBranchMatch) {
+		return nil, err
 	}
 
 	if opts.AllowInteractive && !IsYes() {
@@ -80,7 +64,12 @@ func ResolveSupabaseTarget(client *supabase.Client, opts ResolveTargetOptions) (
 		if IsVerbose() {
 			ui.Infof("Using interactive fallback branch: %s (%s)", fallbackBranch.GitBranch, fallbackEnv)
 		}
-		return buildBranchInfo(client, info, fallbackBranch, fallbackEnv, true), nil
+		base := &supabase.BranchInfo{GitBranch: opts.GitBranch}
+		if opts.OverrideBranch != "" {
+			base.IsOverride = true
+			base.OverrideFrom = opts.GitBranch
+		}
+		return client.NewBranchInfo(base, fallbackBranch, fallbackEnv, true), nil
 	}
 
 	return nil, fmt.Errorf(
@@ -130,7 +119,7 @@ func promptForFallbackBranch(client *supabase.Client, label string, disallowProd
 	for _, b := range branches {
 		branch := b
 		env := environmentForBranch(&branch)
-		if disallowProd && isProductionSupabaseBranch(&branch) {
+		if disallowProd && supabase.IsProductionBranch(&branch) {
 			continue
 		}
 		candidates = append(candidates, candidate{
@@ -164,25 +153,6 @@ func promptForFallbackBranch(client *supabase.Client, label string, disallowProd
 	return &selected.Branch, selected.Env, nil
 }
 
-func buildBranchInfo(client *supabase.Client, base *supabase.BranchInfo, branch *supabase.Branch, env supabase.Environment, isFallback bool) *supabase.BranchInfo {
-	info := &supabase.BranchInfo{
-		GitBranch:      base.GitBranch,
-		SupabaseBranch: branch,
-		Environment:    env,
-		ProjectRef:     branch.ProjectRef,
-		APIURL:         client.GetBranchURL(branch.ProjectRef),
-		IsFallback:     isFallback,
-		IsOverride:     base.IsOverride,
-		OverrideFrom:   base.OverrideFrom,
-	}
-
-	if project, err := client.FindProjectByRef(branch.ProjectRef); err == nil && project != nil {
-		info.Region = project.Region
-	}
-
-	return info
-}
-
 func environmentForBranch(branch *supabase.Branch) supabase.Environment {
 	if branch.IsDefault {
 		return supabase.EnvProduction
@@ -193,25 +163,6 @@ func environmentForBranch(branch *supabase.Branch) supabase.Environment {
 	return supabase.EnvFeature
 }
 
-func isProductionSupabaseBranch(branch *supabase.Branch) bool {
-	if branch == nil {
-		return false
-	}
-	if branch.IsDefault {
-		return true
-	}
-	return isProtectedBranchName(branch.Name) || isProtectedBranchName(branch.GitBranch)
-}
-
-func isProtectedBranchName(name string) bool {
-	switch strings.ToLower(strings.TrimSpace(name)) {
-	case "main", "master", "production", "prod":
-		return true
-	default:
-		return false
-	}
-}
-
 func envWeight(env supabase.Environment) int {
 	switch env {
 	case supabase.EnvDevelopment:
@@ -224,3 +175,34 @@ func envWeight(env supabase.Environment) int {
 		return 3
 	}
 }
+
+// resolveTargetDB resolves the target branch (explicit or from the current
+// git branch), prints it, and returns a database connection to it.
+func resolveTargetDB(client *supabase.Client, cfg *config.Config, explicit string) (*supabase.BranchInfo, database.RestoreOptions, error) {
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		return nil, database.RestoreOptions{}, fmt.Errorf("failed to get current git branch: %w", err)
+	}
+
+	sp := ui.NewSpinner("Resolving Supabase branch")
+	sp.Start()
+	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, explicit)
+	if err != nil {
+		sp.Fail("Failed to resolve Supabase branch")
+		return nil, database.RestoreOptions{}, err
+	}
+	sp.Stop()
+
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+
+	dbURL, err := getDbURLForProject(info.ProjectRef)
+	if err != nil {
+		return nil, database.RestoreOptions{}, err
+	}
+	opts, err := restoreOptionsFromDBURL(dbURL)
+	if err != nil {
+		return nil, database.RestoreOptions{}, err
+	}
+	return info, opts, nil
+}
@@ -102,6 +102,7 @@ func runBranchesList(cmd *cobra.Command, args []string) error {
 	table.Render()
 
 	fmt.Printf("\nTotal: %d branches (%d active, %d paused)\n", len(entries), active, paused)
+	warnExpiredPreviewBranches(branches)
 	return nil
 }
 
@@ -0,0 +1,434 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// ── autoexpire ──────────────────────────────────────────────────────────────
+
+var branchesAutoexpireCmd = &cobra.Command{
+	Use:   "autoexpire",
+	Short: "Warn about or delete preview branches past their TTL",
+	Long: `Find Supabase preview branches that have been idle longer than
+supabase.branches.preview_ttl and, with --delete, remove them together with
+their local worktrees and tmux sessions.
+
+A branch's last activity is the newest of its Supabase update time and the
+last commit on its git branch (local or origin). Production and Development
+branches, protected branches, and branches matching supabase.branches.keep
+never expire; use 'drift branches keep' to add one.
+
+Without --delete nothing is changed, so the command is safe to run from cron
+or a shell hook to get warnings:
+
+  supabase:
+    branches:
+      preview_ttl: 14d
+      keep:
+        - demo
+        - release/*
+
+Worktrees with uncommitted changes are left alone, and so is their
+Supabase branch.`,
+	Example: `  drift branches autoexpire                    # Report expired and expiring branches
+  drift branches autoexpire --delete           # Delete expired branches (asks first)
+  drift branches autoexpire --delete --yes     # Unattended, e.g. from cron
+  drift branches autoexpire --ttl 7d --dry-run # Preview a shorter TTL`,
+	Args: cobra.NoArgs,
+	RunE: Run(runBranchesAutoexpire, RequireProject),
+}
+
+var branchesKeepCmd = &cobra.Command{
+	Use:   "keep <git-branch>...",
+	Short: "Exempt preview branches from autoexpire",
+	Long: `Add git branches, or patterns like release/*, to supabase.branches.keep
+in .drift.yaml so 'drift branches autoexpire' never deletes them. Use
+--remove to let them expire again.`,
+	Example: `  drift branches keep demo
+  drift branches keep 'release/*'
+  drift branches keep --remove demo`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: Run(runBranchesKeep, RequireProject),
+}
+
+var (
+	branchesAutoexpireDelete bool
+	branchesAutoexpireTTL    string
+	branchesAutoexpireDryRun bool
+	branchesKeepRemove       bool
+)
+
+// autoexpireWarnWithin is how soon before expiry a branch is reported.
+const autoexpireWarnWithin = 3 * 24 * time.Hour
+
+func init() {
+	branchesAutoexpireCmd.Flags().BoolVar(&branchesAutoexpireDelete, "delete", false, "Delete expired branches with their worktrees and tmux sessions")
+	branchesAutoexpireCmd.Flags().StringVar(&branchesAutoexpireTTL, "ttl", "", "Override supabase.branches.preview_ttl (e.g. 14d, 72h)")
+	branchesAutoexpireCmd.Flags().BoolVar(&branchesAutoexpireDryRun, "dry-run", false, "Show what --delete would remove")
+	branchesKeepCmd.Flags().BoolVar(&branchesKeepRemove, "remove", false, "Remove branches from the keep list")
+
+	branchesCmd.AddCommand(branchesAutoexpireCmd)
+	branchesCmd.AddCommand(branchesKeepCmd)
+}
+
+// previewExpiry is the autoexpire state of one preview branch.
+type previewExpiry struct {
+	Branch       supabase.Branch
+	LastActivity time.Time
+	// ExpiresIn is negative once the branch has expired.
+	ExpiresIn time.Duration
+	Kept      bool
+}
+
+func (e previewExpiry) expired() bool {
+	return !e.Kept && e.ExpiresIn <= 0
+}
+
+func runBranchesAutoexpire(ctx *Context) error {
+	cfg := ctx.Config()
+
+	ttlSpec := cfg.Supabase.Branches.PreviewTTL
+	if branchesAutoexpireTTL != "" {
+		ttlSpec = branchesAutoexpireTTL
+	}
+	ttl, err := config.ParseTTL(ttlSpec)
+	if err != nil {
+		return errs.Validationf("preview TTL: %v", err)
+	}
+	if ttl == 0 {
+		ui.Info("No preview TTL configured; set supabase.branches.preview_ttl (e.g. 14d) or pass --ttl")
+		return nil
+	}
+
+	sp := ui.NewSpinner("Fetching branches")
+	sp.Start()
+	branches, err := ctx.Client().GetBranches()
+	if err != nil {
+		sp.Fail("Failed to fetch Supabase branches")
+		return err
+	}
+	sp.Stop()
+
+	expiries := previewExpiries(branches, cfg, ttl, time.Now(), lastBranchCommit)
+	if len(expiries) == 0 {
+		ui.Info("No preview branches")
+		return nil
+	}
+
+	ui.Header("Preview Branch Expiry")
+	ui.KeyValue("TTL", formatTTL(ttl))
+	ui.NewLine()
+
+	var expired []previewExpiry
+	var expiring int
+	table := ui.NewTable([]string{"Git Branch", "Status", "Last Activity", "Expiry"})
+	for _, e := range expiries {
+		var expiry string
+		switch {
+		case e.Kept:
+			expiry = ui.Dim("kept")
+		case e.expired():
+			expiry = ui.Red(fmt.Sprintf("expired %s ago", formatTTL(-e.ExpiresIn)))
+			expired = append(expired, e)
+		case e.ExpiresIn <= autoexpireWarnWithin:
+			expiry = ui.Yellow("in " + formatTTL(e.ExpiresIn))
+			expiring++
+		default:
+			expiry = "in " + formatTTL(e.ExpiresIn)
+		}
+		activity := "unknown"
+		if !e.LastActivity.IsZero() {
+			activity = formatBackupAge(e.LastActivity)
+		}
+		table.AddRow([]string{e.Branch.GitBranch, e.Branch.Status, activity, expiry})
+	}
+	table.Render()
+	ui.NewLine()
+
+	if expiring > 0 {
+		ui.Warningf("%d branch(es) expire within %s", expiring, formatTTL(autoexpireWarnWithin))
+	}
+	if len(expired) == 0 {
+		ui.Success("No preview branches past their TTL")
+		return nil
+	}
+	if !branchesAutoexpireDelete {
+		ui.Warningf("%d branch(es) past their TTL", len(expired))
+		ui.Info("Run 'drift branches autoexpire --delete' to remove them, or 'drift branches keep <branch>' to exempt one")
+		return nil
+	}
+	if fetchedAt, cached := supabase.CachedSince(); cached {
+		return errs.Networkf("Supabase API unavailable - the branch list is cached from %s; refusing to delete from stale data", formatBackupAge(fetchedAt))
+	}
+
+	if ctx.DryRun() {
+		for _, e := range expired {
+			ui.Infof("Would delete %s with its worktrees and tmux sessions", e.Branch.GitBranch)
+		}
+		return nil
+	}
+
+	if !IsYes() {
+		ok, err := ui.PromptYesNo(fmt.Sprintf("Delete %d expired branches with their worktrees and tmux sessions? This cannot be undone", len(expired)), false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errs.Cancelled("autoexpire")
+		}
+	}
+
+	var succeeded int
+	for _, e := range expired {
+		if expirePreviewBranch(ctx.Client(), cfg, e.Branch.GitBranch) {
+			succeeded++
+		}
+	}
+	ui.Successf("Deleted %d/%d expired branches", succeeded, len(expired))
+	return nil
+}
+
+// previewExpiries returns the expiry state of each Feature branch, expired
+// first. lastCommit reports the last commit on a git branch, if known.
+func previewExpiries(branches []supabase.Branch, cfg *config.Config, ttl time.Duration, now time.Time, lastCommit func(string) (time.Time, bool)) []previewExpiry {
+	protected := make(map[string]bool, len(cfg.Supabase.ProtectedBranches))
+	for _, b := range cfg.Supabase.ProtectedBranches {
+		protected[b] = true
+	}
+
+	var expiries []previewExpiry
+	for i := range branches {
+		b := branches[i]
+		if environmentForBranch(&b) != supabase.EnvFeature {
+			continue
+		}
+
+		last := parseBranchTime(b.UpdatedAt)
+		if created := parseBranchTime(b.CreatedAt); created.After(last) {
+			last = created
+		}
+		if t, ok := lastCommit(b.GitBranch); ok && t.After(last) {
+			last = t
+		}
+
+		e := previewExpiry{
+			Branch:       b,
+			LastActivity: last,
+			Kept:         protected[b.GitBranch] || cfg.Supabase.Branches.IsKept(b.GitBranch),
+		}
+		// Without any activity time the branch cannot be aged; never expire it.
+		if last.IsZero() {
+			e.ExpiresIn = ttl
+		} else {
+			e.ExpiresIn = last.Add(ttl).Sub(now)
+		}
+		expiries = append(expiries, e)
+	}
+
+	sort.SliceStable(expiries, func(i, j int) bool {
+		if expiries[i].Kept != expiries[j].Kept {
+			return !expiries[i].Kept
+		}
+		if expiries[i].ExpiresIn != expiries[j].ExpiresIn {
+			return expiries[i].ExpiresIn < expiries[j].ExpiresIn
+		}
+		return expiries[i].Branch.GitBranch < expiries[j].Branch.GitBranch
+	})
+	return expiries
+}
+
+// warnExpiredPreviewBranches points at autoexpire when branches are past
+// supabase.branches.preview_ttl.
+func warnExpiredPreviewBranches(branches []supabase.Branch) {
+	cfg := config.LoadOrDefault()
+	ttl, err := cfg.Supabase.Branches.PreviewTTLDuration()
+	if err != nil || ttl == 0 {
+		return
+	}
+	var n int
+	for _, e := range previewExpiries(branches, cfg, ttl, time.Now(), lastBranchCommit) {
+		if e.expired() {
+			n++
+		}
+	}
+	if n > 0 {
+		ui.Warningf("%d preview branch(es) idle longer than %s; run 'drift branches autoexpire'", n, formatTTL(ttl))
+	}
+}
+
+// lastBranchCommit returns the newest last-commit time of gitBranch locally
+// and on origin.
+func lastBranchCommit(gitBranch string) (time.Time, bool) {
+	var last time.Time
+	for _, ref := range []string{"refs/heads/" + gitBranch, "refs/remotes/origin/" + gitBranch} {
+		if t, err := git.LastCommitTime(ref); err == nil && t.After(last) {
+			last = t
+		}
+	}
+	return last, !last.IsZero()
+}
+
+func parseBranchTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// expirePreviewBranch removes gitBranch's tmux sessions and worktrees, then
+// its Supabase branch. A worktree that cannot be removed keeps the branch.
+func expirePreviewBranch(client *supabase.Client, cfg *config.Config, gitBranch string) bool {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		ui.Warningf("Could not list worktrees: %v", err)
+	}
+
+	sessions := []string{branchSessionName(cfg.Project.Name, gitBranch)}
+	var remove []git.Worktree
+	for _, wt := range worktrees {
+		if wt.Branch != gitBranch || wt.IsBare {
+			continue
+		}
+		if wt.IsCurrent {
+			ui.Warningf("Skipping %s: its worktree is the current directory", gitBranch)
+			return false
+		}
+		sessions = append(sessions, worktreeSessionNames(cfg.Project.Name, &wt)...)
+		remove = append(remove, wt)
+	}
+
+	for _, wt := range remove {
+		if err := git.RemoveWorktree(wt.Path, false); err != nil {
+			ui.Warningf("Skipping %s: could not remove worktree %s: %v", gitBranch, wt.Path, err)
+			return false
+		}
+		releaseWorktreePorts(wt.Path)
+		ui.Successf("Removed worktree %s", wt.Path)
+	}
+	killTmuxSessions(sessions)
+
+	sp := ui.NewSpinner(fmt.Sprintf("Deleting %s", gitBranch))
+	sp.Start()
+	if err := client.DeleteBranch(gitBranch); err != nil {
+		sp.Fail(fmt.Sprintf("Failed to delete %s: %s", gitBranch, err))
+		return false
+	}
+	sp.Success(fmt.Sprintf("Deleted %s", gitBranch))
+	return true
+}
+
+// killTmuxSessions kills whichever of names are running.
+func killTmuxSessions(names []string) {
+	running, err := listTmuxSessions()
+	if err != nil {
+		return
+	}
+	live := make(map[string]bool, len(running))
+	for _, s := range running {
+		live[s.Name] = true
+	}
+	for _, name := range names {
+		if !live[name] {
+			continue
+		}
+		if result, err := shell.Run("tmux", "kill-session", "-t", name); err != nil || result.ExitCode != 0 {
+			ui.Warningf("Could not kill tmux session %s", name)
+			continue
+		}
+		ui.Successf("Killed tmux session %s", name)
+	}
+}
+
+// formatTTL formats d in whole days when it is at least a day, else hours.
+func formatTTL(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+// ── keep ────────────────────────────────────────────────────────────────────
+
+func runBranchesKeep(ctx *Context) error {
+	cfg := ctx.Config()
+	var changed []string
+	err := config.UpdateScope(cfg.ProjectRoot(), config.ScopeShared, func(doc map[string]interface{}) error {
+		branches := ensureChildMap(ensureChildMap(doc, "supabase"), "branches")
+		keep := toStringSlice(branches["keep"])
+		keep, changed = updateKeepList(keep, ctx.Args, branchesKeepRemove)
+		if len(keep) == 0 {
+			delete(branches, "keep")
+		} else {
+			branches["keep"] = keep
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(changed) == 0 {
+		ui.Info("Nothing to change")
+		return nil
+	}
+	verb := "Keeping"
+	if branchesKeepRemove {
+		verb = "No longer keeping"
+	}
+	ui.Successf("%s %s (supabase.branches.keep)", verb, strings.Join(changed, ", "))
+	return nil
+}
+
+// updateKeepList adds or removes names and returns the new list with the
+// names that changed.
+func updateKeepList(keep, names []string, remove bool) ([]string, []string) {
+	present := make(map[string]bool, len(keep))
+	for _, k := range keep {
+		present[k] = true
+	}
+
+	var changed []string
+	if remove {
+		drop := make(map[string]bool, len(names))
+		for _, n := range names {
+			if present[n] && !drop[n] {
+				drop[n] = true
+				changed = append(changed, n)
+			}
+		}
+		var kept []string
+		for _, k := range keep {
+			if !drop[k] {
+				kept = append(kept, k)
+			}
+		}
+		return kept, changed
+	}
+
+	for _, n := range names {
+		if !present[n] {
+			present[n] = true
+			keep = append(keep, n)
+			changed = append(changed, n)
+		}
+	}
+	return keep, changed
+}
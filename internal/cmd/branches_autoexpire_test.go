@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestPreviewExpiries(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ttl := 14 * 24 * time.Hour
+	cfg := config.DefaultConfig()
+	cfg.Supabase.ProtectedBranches = []string{"staging"}
+	cfg.Supabase.Branches.Keep = []string{"release/*"}
+
+	branches := []supabase.Branch{
+		{GitBranch: "main", IsDefault: true, UpdatedAt: "2025-01-01T00:00:00Z"},
+		{GitBranch: "develop", Persistent: true, UpdatedAt: "2025-01-01T00:00:00Z"},
+		{GitBranch: "feature/old", UpdatedAt: "2026-02-01T12:00:00Z"},
+		{GitBranch: "feature/recent-commit", UpdatedAt: "2026-01-01T00:00:00Z"},
+		{GitBranch: "feature/soon", CreatedAt: "2026-02-16T12:00:00Z"},
+		{GitBranch: "release/1.0", UpdatedAt: "2025-06-01T00:00:00Z"},
+		{GitBranch: "staging", UpdatedAt: "2025-06-01T00:00:00Z"},
+		{GitBranch: "feature/unknown"},
+	}
+	commits := map[string]time.Time{
+		"feature/recent-commit": now.Add(-48 * time.Hour),
+	}
+	lastCommit := func(b string) (time.Time, bool) {
+		t, ok := commits[b]
+		return t, ok
+	}
+
+	got := previewExpiries(branches, cfg, ttl, now, lastCommit)
+
+	var order []string
+	states := make(map[string]previewExpiry)
+	for _, e := range got {
+		order = append(order, e.Branch.GitBranch)
+		states[e.Branch.GitBranch] = e
+	}
+	want := []string{"feature/old", "feature/soon", "feature/recent-commit", "feature/unknown", "release/1.0", "staging"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+
+	if e := states["feature/old"]; !e.expired() || e.ExpiresIn != -14*24*time.Hour {
+		t.Errorf("feature/old = %+v, want expired 14 days ago", e)
+	}
+	if e := states["feature/soon"]; e.expired() || e.ExpiresIn != 24*time.Hour {
+		t.Errorf("feature/soon = %+v, want expiring in a day", e)
+	}
+	if e := states["feature/recent-commit"]; e.expired() || !e.LastActivity.Equal(commits["feature/recent-commit"]) {
+		t.Errorf("feature/recent-commit = %+v, want the recent commit to count as activity", e)
+	}
+	if e := states["feature/unknown"]; e.expired() {
+		t.Errorf("feature/unknown = %+v, a branch without timestamps must not expire", e)
+	}
+	for _, name := range []string{"release/1.0", "staging"} {
+		if e := states[name]; !e.Kept || e.expired() {
+			t.Errorf("%s = %+v, want kept", name, e)
+		}
+	}
+}
+
+func TestUpdateKeepList(t *testing.T) {
+	keep, changed := updateKeepList([]string{"demo"}, []string{"demo", "release/*", "release/*"}, false)
+	if !reflect.DeepEqual(keep, []string{"demo", "release/*"}) || !reflect.DeepEqual(changed, []string{"release/*"}) {
+		t.Errorf("add = %v, %v", keep, changed)
+	}
+
+	keep, changed = updateKeepList(keep, []string{"demo", "missing"}, true)
+	if !reflect.DeepEqual(keep, []string{"release/*"}) || !reflect.DeepEqual(changed, []string{"demo"}) {
+		t.Errorf("remove = %v, %v", keep, changed)
+	}
+}
+
+func TestFormatTTL(t *testing.T) {
+	tests := map[time.Duration]string{
+		14 * 24 * time.Hour: "14d",
+		30 * time.Hour:      "1d",
+		5 * time.Hour:       "5h",
+		20 * time.Minute:    "20m",
+	}
+	for d, want := range tests {
+		if got := formatTTL(d); got != want {
+			t.Errorf("formatTTL(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
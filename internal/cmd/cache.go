@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage cached Supabase data for offline use",
+	Long: `Drift caches Supabase branch and function lists under .drift/cache/.
+When the Supabase API is unreachable, read-only commands such as 'env show',
+'worktree info', and 'deploy status' fall back to the cache and show how old
+the data is. Pass --offline (or set DRIFT_OFFLINE=1) to skip the network.
+
+Cached data older than supabase.cache_ttl (default 24h) is ignored.`,
+}
+
+var cacheWarmCmd = &cobra.Command{
+	Use:   "warm",
+	Short: "Fetch branches and deployed functions into the cache",
+	Long: `Fetch the Supabase branch list plus deployed functions for production,
+development, and the current branch, so they are available offline.`,
+	RunE: runCacheWarm,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete cached Supabase data",
+	RunE:  runCacheClear,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheWarmCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheWarm(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	if supabase.IsOffline() {
+		return fmt.Errorf("cannot warm the cache in offline mode")
+	}
+	cfg := config.LoadOrDefault()
+
+	ui.Header("Warm Cache")
+
+	client := supabase.NewClient()
+	sp := ui.NewSpinner("Fetching branches")
+	sp.Start()
+	branches, err := client.GetBranches()
+	if err != nil {
+		sp.Fail("Failed to fetch branches")
+		return err
+	}
+	if _, stale := supabase.CachedSince(); stale {
+		sp.Fail("Supabase API unreachable")
+		return fmt.Errorf("could not reach the Supabase API; cache left unchanged")
+	}
+	sp.Success(fmt.Sprintf("Cached %d branches", len(branches)))
+
+	// Production, development, and whichever branch the current checkout uses.
+	gitBranch, _ := git.CurrentBranch()
+	current := gitBranch
+	if cfg.Supabase.OverrideBranch != "" {
+		current = cfg.Supabase.OverrideBranch
+	}
+	refs := make(map[string]string)
+	for _, b := range branches {
+		if b.IsDefault || b.Persistent || (current != "" && (b.GitBranch == current || b.Name == current)) {
+			refs[b.ProjectRef] = b.Name
+		}
+	}
+
+	for ref, name := range refs {
+		sp := ui.NewSpinner(fmt.Sprintf("Fetching functions for %s", name))
+		sp.Start()
+		functions, err := client.ListDeployedFunctions(ref)
+		if err != nil {
+			sp.Fail(fmt.Sprintf("Could not fetch functions for %s: %v", name, err))
+			continue
+		}
+		sp.Success(fmt.Sprintf("Cached %d functions for %s", len(functions), name))
+	}
+
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	if err := supabase.ClearCache(); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	ui.Success("Cleared cached Supabase data")
+	return nil
+}
+
+// printCacheBanner notes when output is based on cached Supabase data.
+func printCacheBanner() {
+	if fetchedAt, ok := supabase.CachedSince(); ok {
+		ui.NewLine()
+		ui.Warningf("Supabase API unavailable - showing data cached %s", formatBackupAge(fetchedAt))
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
 	"github.com/undrift/drift/internal/git"
 	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
@@ -79,7 +81,7 @@ func init() {
 func runConfigShow(cmd *cobra.Command, args []string) error {
 	cfg, err := config.LoadWithLocal()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return errs.Config(fmt.Errorf("failed to load config: %w", err))
 	}
 
 	ui.Header("Drift Configuration")
@@ -91,6 +93,12 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 
 	ui.NewLine()
 	ui.SubHeader("Supabase")
+	if cfg.Supabase.ActiveProject != "" {
+		ui.KeyValue("Project", fmt.Sprintf("%s (of %s)", ui.Cyan(cfg.Supabase.ActiveProject), strings.Join(cfg.Supabase.ProjectNames(), ", ")))
+		if cfg.Supabase.EnvPrefix != "" {
+			ui.KeyValue("Env Prefix", cfg.Supabase.EnvPrefix)
+		}
+	}
 	ui.KeyValue("Project Ref", cfg.Supabase.ProjectRef)
 	ui.KeyValue("Project Name", cfg.Supabase.ProjectName)
 
@@ -209,7 +217,7 @@ func runConfigSetBranch(cmd *cobra.Command, args []string) error {
 		}
 		return fmt.Errorf("Supabase branch '%s' not found", targetBranch)
 	}
-	if isProductionSupabaseBranch(branch) {
+	if supabase.IsProductionBranch(branch) {
 		return fmt.Errorf("refusing to set production branch '%s' as override target", targetBranch)
 	}
 
@@ -276,7 +284,7 @@ func runConfigInitLocal(cmd *cobra.Command, args []string) error {
 
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return errs.Config(fmt.Errorf("failed to load config: %w", err))
 	}
 
 	localPath := filepath.Join(cfg.ProjectRoot(), config.LocalConfigFilename)
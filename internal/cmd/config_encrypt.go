@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var configEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt secret values in .drift.local.yaml",
+	Long: `Encrypt every plaintext environments.<env>.secrets value in .drift.local.yaml
+with age, so the file can be committed or synced safely.
+
+Values are encrypted to the team's age recipients listed under
+encryption.local_recipients in .drift.yaml (encryption.age_recipient is used
+if the list is empty). Drift decrypts them transparently when loading config,
+using encryption.age_identity, $DRIFT_AGE_IDENTITY, or ~/.config/age/keys.txt.
+
+Use --rotate after changing recipients to re-encrypt existing values.
+
+Example config:
+  encryption:
+    local_recipients:
+      - age1qyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqs3290gq  # alice
+      - age1zvkyg2lqzraa2lnjvqej32nkuu0ues2s82hzrye869xeexvn73equnujwj  # bob`,
+	Example: `  drift config encrypt
+  drift config encrypt --rotate`,
+	RunE: runConfigEncrypt,
+}
+
+var configDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt secret values in .drift.local.yaml back to plaintext",
+	Long:  `Replace encrypted environments.<env>.secrets values in .drift.local.yaml with their plaintext.`,
+	RunE:  runConfigDecrypt,
+}
+
+var configEncryptRotateFlag bool
+
+func init() {
+	configEncryptCmd.Flags().BoolVar(&configEncryptRotateFlag, "rotate", false, "Re-encrypt already encrypted values to the current recipients")
+
+	configCmd.AddCommand(configEncryptCmd)
+	configCmd.AddCommand(configDecryptCmd)
+}
+
+func runConfigEncrypt(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	recipients := cfg.Encryption.LocalRecipients()
+	if len(recipients) == 0 {
+		return fmt.Errorf("no age recipients configured; set encryption.local_recipients in .drift.yaml")
+	}
+	identity := cfg.Encryption.IdentityPath()
+
+	ui.Header("Encrypt Local Secrets")
+	ui.KeyValue("Recipients", fmt.Sprintf("%d", len(recipients)))
+
+	return rewriteLocalSecrets(func(value string) (string, bool, error) {
+		if config.IsEncryptedValue(value) {
+			if !configEncryptRotateFlag {
+				return value, false, nil
+			}
+			plain, err := config.DecryptValue(value, identity)
+			if err != nil {
+				return "", false, err
+			}
+			value = plain
+		}
+		encrypted, err := config.EncryptValue(value, recipients)
+		if err != nil {
+			return "", false, err
+		}
+		return encrypted, true, nil
+	}, "Encrypted")
+}
+
+func runConfigDecrypt(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	identity := cfg.Encryption.IdentityPath()
+
+	ui.Header("Decrypt Local Secrets")
+
+	return rewriteLocalSecrets(func(value string) (string, bool, error) {
+		if !config.IsEncryptedValue(value) {
+			return value, false, nil
+		}
+		plain, err := config.DecryptValue(value, identity)
+		if err != nil {
+			return "", false, err
+		}
+		return plain, true, nil
+	}, "Decrypted")
+}
+
+// rewriteLocalSecrets applies fn to every environments.*.secrets value in
+// .drift.local.yaml and writes the file if anything changed.
+func rewriteLocalSecrets(fn func(value string) (string, bool, error), verb string) error {
+	localPath, err := config.FindLocalConfigFile()
+	if err != nil {
+		return err
+	}
+	var changed []string
+	err = config.UpdateYAML(localPath, true, func(doc map[string]interface{}) error {
+		var err error
+		changed, err = transformLocalSecrets(doc, fn)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", config.LocalConfigFilename, err)
+	}
+	ui.NewLine()
+	if len(changed) == 0 {
+		ui.Info("Nothing to do")
+		return nil
+	}
+
+	for _, key := range changed {
+		ui.List(key)
+	}
+	ui.NewLine()
+	ui.Successf("%s %d secret(s) in %s", verb, len(changed), config.LocalConfigFilename)
+	return nil
+}
+
+// transformLocalSecrets applies fn to each string secret value in doc and
+// returns the sorted env.KEY names that changed.
+func transformLocalSecrets(doc map[string]interface{}, fn func(value string) (string, bool, error)) ([]string, error) {
+	envs, ok := doc["environments"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	var changed []string
+	for envName, rawEnv := range envs {
+		env, ok := rawEnv.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		secrets, ok := env["secrets"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, rawValue := range secrets {
+			value, ok := rawValue.(string)
+			if !ok {
+				value = fmt.Sprint(rawValue)
+			}
+			updated, didChange, err := fn(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", envName, key, err)
+			}
+			if didChange {
+				secrets[key] = updated
+				changed = append(changed, envName+"."+key)
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransformLocalSecrets(t *testing.T) {
+	doc := map[string]interface{}{
+		"environments": map[string]interface{}{
+			"development": map[string]interface{}{
+				"secrets": map[string]interface{}{
+					"API_KEY": "abc",
+					"DONE":    "drift-enc:xyz",
+				},
+			},
+			"production": map[string]interface{}{
+				"skip_secrets": []interface{}{"DEBUG"},
+			},
+		},
+	}
+
+	changed, err := transformLocalSecrets(doc, func(value string) (string, bool, error) {
+		if strings.HasPrefix(value, "drift-enc:") {
+			return value, false, nil
+		}
+		return "drift-enc:" + value, true, nil
+	})
+	if err != nil {
+		t.Fatalf("transformLocalSecrets() error = %v", err)
+	}
+	if !reflect.DeepEqual(changed, []string{"development.API_KEY"}) {
+		t.Errorf("changed = %v, want [development.API_KEY]", changed)
+	}
+
+	secrets := doc["environments"].(map[string]interface{})["development"].(map[string]interface{})["secrets"].(map[string]interface{})
+	if secrets["API_KEY"] != "drift-enc:abc" || secrets["DONE"] != "drift-enc:xyz" {
+		t.Errorf("unexpected secrets after transform: %v", secrets)
+	}
+}
@@ -2,14 +2,12 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
 	"github.com/undrift/drift/internal/ui"
-	"gopkg.in/yaml.v3"
 )
 
 var configSetSecretCmd = &cobra.Command{
@@ -124,48 +122,31 @@ func runConfigSetSecret(cmd *cobra.Command, args []string) error {
 	mainPath := cfg.ConfigPath()
 	localPath := filepath.Join(cfg.ProjectRoot(), config.LocalConfigFilename)
 
-	mainDoc, err := loadYAMLDoc(mainPath, false)
+	err = config.UpdateYAML(mainPath, false, func(mainDoc map[string]interface{}) error {
+		updateSupabaseSecretsToPush(mainDoc, secretName, includeInPush)
+		if setDefault {
+			updateSupabaseDefaultSecret(mainDoc, secretName, defaultValue)
+		}
+		updateEnvironmentSkipSecret(mainDoc, "production", secretName, !pushToProduction)
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to read %s: %w", mainPath, err)
-	}
-
-	updateSupabaseSecretsToPush(mainDoc, secretName, includeInPush)
-	if setDefault {
-		updateSupabaseDefaultSecret(mainDoc, secretName, defaultValue)
-	}
-	updateEnvironmentSkipSecret(mainDoc, "production", secretName, !pushToProduction)
-
-	if err := writeYAMLDoc(mainPath, mainDoc); err != nil {
-		return fmt.Errorf("failed to write %s: %w", mainPath, err)
+		return fmt.Errorf("failed to update %s: %w", mainPath, err)
 	}
 
-	localDoc := map[string]interface{}{}
-	localChanged := false
-	if setDevOverride || setFeatureOverride {
-		if !config.LocalConfigExists() {
-			if err := config.WriteLocalConfig(localPath); err != nil {
-				return fmt.Errorf("failed to create %s: %w", config.LocalConfigFilename, err)
+	localChanged := setDevOverride || setFeatureOverride
+	if localChanged {
+		err = config.UpdateScope(cfg.ProjectRoot(), config.ScopeLocal, func(localDoc map[string]interface{}) error {
+			if setDevOverride {
+				updateEnvironmentSecretValue(localDoc, "development", secretName, devOverrideVal)
 			}
-		}
-
-		localDoc, err = loadYAMLDoc(localPath, true)
+			if setFeatureOverride {
+				updateEnvironmentSecretValue(localDoc, "feature", secretName, featureOverrideVal)
+			}
+			return nil
+		})
 		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", localPath, err)
-		}
-
-		if setDevOverride {
-			updateEnvironmentSecretValue(localDoc, "development", secretName, devOverrideVal)
-			localChanged = true
-		}
-		if setFeatureOverride {
-			updateEnvironmentSecretValue(localDoc, "feature", secretName, featureOverrideVal)
-			localChanged = true
-		}
-	}
-
-	if localChanged {
-		if err := writeYAMLDoc(localPath, localDoc); err != nil {
-			return fmt.Errorf("failed to write %s: %w", localPath, err)
+			return fmt.Errorf("failed to update %s: %w", localPath, err)
 		}
 	}
 
@@ -185,32 +166,6 @@ func runConfigSetSecret(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func loadYAMLDoc(path string, allowMissing bool) (map[string]interface{}, error) {
-	doc := make(map[string]interface{})
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if allowMissing && os.IsNotExist(err) {
-			return doc, nil
-		}
-		return nil, err
-	}
-	if strings.TrimSpace(string(data)) == "" {
-		return doc, nil
-	}
-	if err := yaml.Unmarshal(data, &doc); err != nil {
-		return nil, err
-	}
-	return doc, nil
-}
-
-func writeYAMLDoc(path string, doc map[string]interface{}) error {
-	data, err := yaml.Marshal(doc)
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, data, 0644)
-}
-
 func ensureChildMap(parent map[string]interface{}, key string) map[string]interface{} {
 	if existing, ok := parent[key]; ok {
 		if m, ok := existing.(map[string]interface{}); ok {
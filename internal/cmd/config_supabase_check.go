@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var configSupabaseCheckCmd = &cobra.Command{
+	Use:   "supabase-check [target...]",
+	Short: "Compare supabase/config.toml with live project settings",
+	Long: `Compare the auth and storage settings in supabase/config.toml with the
+live settings of each environment, and optionally apply the differences.
+
+Targets are "production" (or "prod"), "development" (or "dev"), or branch
+names; the default is production and development. Only settings present in
+config.toml are compared. Values from a [remotes.<name>] section whose
+project_id matches the environment override the top-level ones, and
+env(NAME) values are read from the environment.
+
+Checked settings: auth site URL, redirect URLs, JWT expiry, signups,
+email confirmations, anonymous sign-ins, minimum password length, and the
+storage file size limit. Exits with code 6 when differences remain.`,
+	Example: `  drift config supabase-check              # production and development
+  drift config supabase-check dev feat/x   # Specific targets
+  drift config supabase-check dev --apply  # Push config.toml values to dev`,
+	RunE: Run(runConfigSupabaseCheck, RequireProject),
+}
+
+var (
+	configSupabaseCheckApply bool
+	configSupabaseCheckJSON  bool
+)
+
+func init() {
+	configSupabaseCheckCmd.Flags().BoolVar(&configSupabaseCheckApply, "apply", false, "Update live settings to match config.toml")
+	configSupabaseCheckCmd.Flags().BoolVar(&configSupabaseCheckJSON, "json", false, "Output as JSON")
+	configCmd.AddCommand(configSupabaseCheckCmd)
+}
+
+// settingKind is how a config.toml value maps to its API field.
+type settingKind int
+
+const (
+	settingString settingKind = iota
+	settingInt
+	settingBool
+	settingInvertedBool // config.toml enable_x is the API's disable_x
+	settingURLList      // config.toml array, API comma-separated string
+	settingSize         // config.toml "50MiB", API bytes
+)
+
+// supabaseSetting maps a config.toml key to a Management API field.
+type supabaseSetting struct {
+	Key     string
+	Service string // "auth" or "storage"
+	Field   string
+	Kind    settingKind
+}
+
+var supabaseSettings = []supabaseSetting{
+	{Key: "auth.site_url", Service: "auth", Field: "site_url", Kind: settingString},
+	{Key: "auth.additional_redirect_urls", Service: "auth", Field: "uri_allow_list", Kind: settingURLList},
+	{Key: "auth.jwt_expiry", Service: "auth", Field: "jwt_exp", Kind: settingInt},
+	{Key: "auth.enable_signup", Service: "auth", Field: "disable_signup", Kind: settingInvertedBool},
+	{Key: "auth.enable_anonymous_sign_ins", Service: "auth", Field: "external_anonymous_users_enabled", Kind: settingBool},
+	{Key: "auth.minimum_password_length", Service: "auth", Field: "password_min_length", Kind: settingInt},
+	{Key: "auth.email.enable_signup", Service: "auth", Field: "external_email_enabled", Kind: settingBool},
+	{Key: "auth.email.enable_confirmations", Service: "auth", Field: "mailer_autoconfirm", Kind: settingInvertedBool},
+	{Key: "auth.email.double_confirm_changes", Service: "auth", Field: "mailer_secure_email_change_enabled", Kind: settingBool},
+	{Key: "storage.file_size_limit", Service: "storage", Field: "fileSizeLimit", Kind: settingSize},
+}
+
+// toAPI converts a config.toml value to the API value and its display form.
+func (s supabaseSetting) toAPI(v interface{}) (interface{}, string, error) {
+	invalid := fmt.Errorf("%s: unexpected value %v", s.Key, v)
+	switch s.Kind {
+	case settingString:
+		str, ok := v.(string)
+		if !ok {
+			return nil, "", invalid
+		}
+		return str, str, nil
+	case settingInt:
+		n, ok := v.(int64)
+		if !ok {
+			return nil, "", invalid
+		}
+		return n, strconv.FormatInt(n, 10), nil
+	case settingBool, settingInvertedBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, "", invalid
+		}
+		if s.Kind == settingInvertedBool {
+			return !b, strconv.FormatBool(b), nil
+		}
+		return b, strconv.FormatBool(b), nil
+	case settingURLList:
+		urls, ok := v.([]string)
+		if !ok {
+			return nil, "", invalid
+		}
+		return strings.Join(urls, ","), formatURLList(urls), nil
+	case settingSize:
+		str, ok := v.(string)
+		if !ok {
+			if n, isInt := v.(int64); isInt {
+				return n, formatSettingSize(n), nil
+			}
+			return nil, "", invalid
+		}
+		n, err := supabase.ParseSize(str)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", s.Key, err)
+		}
+		return n, formatSettingSize(n), nil
+	}
+	return nil, "", invalid
+}
+
+// fromAPI formats a live API value the way toAPI formats config.toml
+// values, so the two compare as strings.
+func (s supabaseSetting) fromAPI(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	switch s.Kind {
+	case settingInt:
+		if f, ok := v.(float64); ok {
+			return strconv.FormatInt(int64(f), 10)
+		}
+	case settingBool:
+		if b, ok := v.(bool); ok {
+			return strconv.FormatBool(b)
+		}
+	case settingInvertedBool:
+		if b, ok := v.(bool); ok {
+			return strconv.FormatBool(!b)
+		}
+	case settingURLList:
+		if str, ok := v.(string); ok {
+			var urls []string
+			for _, u := range strings.Split(str, ",") {
+				if u = strings.TrimSpace(u); u != "" {
+					urls = append(urls, u)
+				}
+			}
+			return formatURLList(urls)
+		}
+	case settingSize:
+		if f, ok := v.(float64); ok {
+			return formatSettingSize(int64(f))
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// formatSettingSize shows the exact byte count, so sizes that round to the
+// same value still differ.
+func formatSettingSize(n int64) string {
+	return fmt.Sprintf("%s (%d bytes)", formatDiskSize(n), n)
+}
+
+// formatURLList sorts urls so order differences do not count.
+func formatURLList(urls []string) string {
+	sorted := append([]string(nil), urls...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+// settingDiff is a setting whose live value differs from config.toml.
+type settingDiff struct {
+	Setting string `json:"setting"`
+	Local   string `json:"config_toml"`
+	Live    string `json:"live"`
+	service string
+	field   string
+	value   interface{}
+}
+
+type supabaseCheckResult struct {
+	Target      string        `json:"target"`
+	Environment string        `json:"environment"`
+	ProjectRef  string        `json:"project_ref"`
+	Differences []settingDiff `json:"differences"`
+	Skipped     []string      `json:"skipped,omitempty"`
+	Applied     bool          `json:"applied"`
+}
+
+func runConfigSupabaseCheck(ctx *Context) error {
+	cfg := ctx.Config()
+	projectConfig, err := supabase.LoadProjectConfig(filepath.Join(cfg.ProjectRoot(), "supabase", "config.toml"))
+	if err != nil {
+		return errs.Config(fmt.Errorf("failed to read supabase/config.toml: %w", err))
+	}
+	mgmt, err := supabase.NewManagementClient()
+	if err != nil {
+		return err
+	}
+
+	targets := ctx.Args
+	if len(targets) == 0 {
+		targets = []string{"production", "development"}
+	}
+	gitBranch, err := ctx.GitBranch()
+	if err != nil {
+		gitBranch = ""
+	}
+
+	var results []supabaseCheckResult
+	remaining := 0
+	for _, target := range targets {
+		name := normalizeMatrixTarget(target)
+		var info *supabase.BranchInfo
+		if name == "production" || name == "development" {
+			info, err = resolveSchemeVariantTarget(ctx.Client(), cfg, gitBranch, name)
+		} else {
+			info, err = ctx.Target(name)
+		}
+		if err != nil {
+			return err
+		}
+
+		sp := ui.NewSpinner(fmt.Sprintf("Reading live settings for %s", name))
+		sp.Start()
+		result, err := checkSupabaseSettings(mgmt, projectConfig.ForProject(info.ProjectRef), info.ProjectRef)
+		if err != nil {
+			sp.Fail(fmt.Sprintf("Failed to read live settings for %s", name))
+			return err
+		}
+		sp.Stop()
+		result.Target = name
+		result.Environment = string(info.Environment)
+
+		if !ctx.JSON() {
+			printSupabaseCheckResult(info, result)
+		}
+		if configSupabaseCheckApply && len(result.Differences) > 0 {
+			if err := applySupabaseSettings(ctx, mgmt, info, result); err != nil {
+				return err
+			}
+		}
+		if !result.Applied {
+			remaining += len(result.Differences)
+		}
+		results = append(results, *result)
+	}
+
+	if ctx.JSON() {
+		if err := ctx.PrintJSON(results); err != nil {
+			return err
+		}
+	}
+	if remaining > 0 {
+		if !configSupabaseCheckApply && !ctx.JSON() {
+			ui.Info("Run with --apply to update the live settings, or change config.toml to match")
+		}
+		return errs.Validationf("%d setting(s) differ from supabase/config.toml", remaining)
+	}
+	return nil
+}
+
+// checkSupabaseSettings compares values, the config.toml settings for
+// projectRef, with the project's live settings.
+func checkSupabaseSettings(mgmt *supabase.ManagementClient, values map[string]interface{}, projectRef string) (*supabaseCheckResult, error) {
+	result := &supabaseCheckResult{ProjectRef: projectRef}
+	live := make(map[string]map[string]interface{})
+	for _, s := range supabaseSettings {
+		raw, ok := values[s.Key]
+		if !ok {
+			continue
+		}
+		raw, ok = supabase.ResolveEnv(raw)
+		if !ok {
+			result.Skipped = append(result.Skipped, s.Key+" (env var not set)")
+			continue
+		}
+		value, local, err := s.toAPI(raw)
+		if err != nil {
+			return nil, errs.Config(err)
+		}
+
+		settings, fetched := live[s.Service]
+		if !fetched {
+			var err error
+			if s.Service == "storage" {
+				settings, err = mgmt.GetStorageConfig(projectRef)
+			} else {
+				settings, err = mgmt.GetAuthConfig(projectRef)
+			}
+			if err != nil {
+				return nil, err
+			}
+			live[s.Service] = settings
+		}
+		if remote := s.fromAPI(settings[s.Field]); remote != local {
+			result.Differences = append(result.Differences, settingDiff{
+				Setting: s.Key, Local: local, Live: remote,
+				service: s.Service, field: s.Field, value: value,
+			})
+		}
+	}
+	return result, nil
+}
+
+func printSupabaseCheckResult(info *supabase.BranchInfo, result *supabaseCheckResult) {
+	ui.Header("Supabase Settings: " + result.Target)
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+	for _, s := range result.Skipped {
+		ui.Warningf("Skipped %s", s)
+	}
+	if len(result.Differences) == 0 {
+		ui.Success("Live settings match supabase/config.toml")
+		return
+	}
+	table := ui.NewTable([]string{"Setting", "config.toml", "Live"})
+	for _, d := range result.Differences {
+		table.AddRow([]string{d.Setting, d.Local, ui.Yellow(d.Live)})
+	}
+	table.Render()
+}
+
+// applySupabaseSettings updates info's live settings to the config.toml
+// values in result, after the usual environment confirmation.
+func applySupabaseSettings(ctx *Context, mgmt *supabase.ManagementClient, info *supabase.BranchInfo, result *supabaseCheckResult) error {
+	cfg := ctx.Config()
+	confirmed, err := ConfirmDeploymentOperation(info, cfg, fmt.Sprintf("update %d Supabase setting(s)", len(result.Differences)))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return errs.Cancelled("config supabase-check --apply")
+	}
+
+	changes := make(map[string]map[string]interface{})
+	for _, d := range result.Differences {
+		if changes[d.service] == nil {
+			changes[d.service] = make(map[string]interface{})
+		}
+		changes[d.service][d.field] = d.value
+	}
+
+	start := time.Now()
+	if c := changes["auth"]; c != nil {
+		err = mgmt.UpdateAuthConfig(info.ProjectRef, c)
+	}
+	if c := changes["storage"]; c != nil && err == nil {
+		err = mgmt.UpdateStorageConfig(info.ProjectRef, c)
+	}
+	notifyOperation(cfg, "config supabase-check --apply", string(info.Environment), info.SupabaseBranch.Name, start, err)
+	if err != nil {
+		return err
+	}
+	result.Applied = true
+	if !ctx.JSON() {
+		ui.Successf("Updated %d setting(s) on %s", len(result.Differences), info.SupabaseBranch.Name)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import "testing"
+
+func TestSupabaseSettingCompare(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting supabaseSetting
+		local   interface{}
+		live    interface{}
+		differs bool
+	}{
+		{"same string", supabaseSetting{Kind: settingString}, "https://app.example.com", "https://app.example.com", false},
+		{"int from json", supabaseSetting{Kind: settingInt}, int64(3600), float64(3600), false},
+		{"int differs", supabaseSetting{Kind: settingInt}, int64(3600), float64(7200), true},
+		{"inverted bool", supabaseSetting{Kind: settingInvertedBool}, true, false, false},
+		{"inverted bool differs", supabaseSetting{Kind: settingInvertedBool}, true, true, true},
+		{"url order ignored", supabaseSetting{Kind: settingURLList}, []string{"b://x", "a://y"}, "a://y, b://x", false},
+		{"url missing", supabaseSetting{Kind: settingURLList}, []string{"a://y", "b://x"}, "a://y", true},
+		{"size", supabaseSetting{Kind: settingSize}, "50MiB", float64(50 << 20), false},
+		{"size close but different", supabaseSetting{Kind: settingSize}, "50MiB", float64(50<<20 + 1), true},
+		{"unset live", supabaseSetting{Kind: settingString}, "x", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, local, err := tt.setting.toAPI(tt.local)
+			if err != nil {
+				t.Fatalf("toAPI() error = %v", err)
+			}
+			live := tt.setting.fromAPI(tt.live)
+			if differs := local != live; differs != tt.differs {
+				t.Errorf("config.toml %q vs live %q: differs = %v, want %v", local, live, differs, tt.differs)
+			}
+		})
+	}
+}
+
+func TestSupabaseSettingToAPI(t *testing.T) {
+	s := supabaseSetting{Key: "auth.additional_redirect_urls", Kind: settingURLList}
+	v, _, err := s.toAPI([]string{"a://x", "b://y"})
+	if err != nil || v != "a://x,b://y" {
+		t.Errorf("toAPI() = %v, %v; want comma-joined list", v, err)
+	}
+
+	s = supabaseSetting{Key: "auth.enable_signup", Kind: settingInvertedBool}
+	if v, _, _ := s.toAPI(true); v != false {
+		t.Errorf("toAPI(enable_signup=true) = %v, want disable_signup=false", v)
+	}
+
+	s = supabaseSetting{Key: "auth.jwt_expiry", Kind: settingInt}
+	if _, _, err := s.toAPI("soon"); err == nil {
+		t.Error("toAPI() with a string for an int setting should fail")
+	}
+}
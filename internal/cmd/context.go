@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// Context is the state commands share within one run: merged config, the
+// current git branch, the Supabase client, and resolved branch targets. Each
+// is resolved on first use and reused, so helpers called from a command do
+// not reload config or resolve the branch again.
+type Context struct {
+	Cmd  *cobra.Command
+	Args []string
+
+	// Out is where machine-readable output goes. With --json it is the
+	// original stdout while human output is moved to stderr.
+	Out io.Writer
+
+	cfg       *config.Config
+	client    *supabase.Client
+	gitBranch string
+	gitErr    error
+	gitDone   bool
+	targets   map[string]*supabase.BranchInfo
+}
+
+// RunFunc is a command body that receives the shared Context.
+type RunFunc func(ctx *Context) error
+
+// Middleware wraps a RunFunc with behavior that applies before or after it.
+type Middleware func(next RunFunc) RunFunc
+
+// globalMiddleware wraps every command built with Run, outermost first.
+var globalMiddleware = []Middleware{traceMiddleware, jsonMiddleware, dryRunMiddleware}
+
+// sharedConfig is the merged config loaded by initConfig for this run.
+var sharedConfig *config.Config
+
+type contextKey struct{}
+
+// Run adapts fn to cobra's RunE. It builds the command's Context and wraps
+// fn in the global middleware, then in mws in order.
+func Run(fn RunFunc, mws ...Middleware) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		ctx := &Context{Cmd: cmd, Args: args, Out: os.Stdout}
+		parent := cmd.Context()
+		if parent == nil {
+			parent = context.Background()
+		}
+		cmd.SetContext(context.WithValue(parent, contextKey{}, ctx))
+
+		run := fn
+		for i := len(mws) - 1; i >= 0; i-- {
+			run = mws[i](run)
+		}
+		for i := len(globalMiddleware) - 1; i >= 0; i-- {
+			run = globalMiddleware[i](run)
+		}
+		return run(ctx)
+	}
+}
+
+// ContextFor returns the Context of a command started with Run, or a fresh
+// one for commands that have not been converted yet.
+func ContextFor(cmd *cobra.Command) *Context {
+	if cmd != nil && cmd.Context() != nil {
+		if ctx, ok := cmd.Context().Value(contextKey{}).(*Context); ok {
+			return ctx
+		}
+	}
+	return &Context{Cmd: cmd, Out: os.Stdout}
+}
+
+// worktreeContext returns a Context for running another command's body in
+// the worktree that is now the working directory. Config and the git branch
+// are read from that worktree rather than taken from this run.
+func worktreeContext(cmd *cobra.Command) *Context {
+	return &Context{Cmd: cmd, Out: os.Stdout, cfg: config.LoadOrDefault()}
+}
+
+// RequireProject stops the command, like RequireInit, outside an initialized
+// drift project.
+func RequireProject(next RunFunc) RunFunc {
+	return func(ctx *Context) error {
+		if !RequireInit() {
+			return requireInitErr
+		}
+		return next(ctx)
+	}
+}
+
+// Config returns the merged config, loading it on first use.
+func (c *Context) Config() *config.Config {
+	if c.cfg == nil {
+		if sharedConfig != nil {
+			c.cfg = sharedConfig
+		} else {
+			c.cfg = config.LoadOrDefault()
+		}
+	}
+	return c.cfg
+}
+
+// Client returns the Supabase client.
+func (c *Context) Client() *supabase.Client {
+	if c.client == nil {
+		c.client = supabase.NewClient()
+	}
+	return c.client
+}
+
+// GitBranch returns the current git branch.
+func (c *Context) GitBranch() (string, error) {
+	if !c.gitDone {
+		c.gitBranch, c.gitErr = git.CurrentBranch()
+		if c.gitErr != nil {
+			c.gitErr = fmt.Errorf("failed to get current git branch: %w", c.gitErr)
+		}
+		c.gitDone = true
+	}
+	return c.gitBranch, c.gitErr
+}
+
+// Target resolves the Supabase branch for explicit, or for the current git
+// branch when explicit is empty, with the usual override and fallback rules.
+// Results are cached per explicit value.
+func (c *Context) Target(explicit string) (*supabase.BranchInfo, error) {
+	if info, ok := c.targets[explicit]; ok {
+		return info, nil
+	}
+	gitBranch, err := c.GitBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	sp := ui.NewSpinner("Resolving Supabase branch")
+	sp.Start()
+	info, err := ResolveSupabaseTargetForCurrentBranch(c.Client(), c.Config(), gitBranch, explicit)
+	if err != nil {
+		sp.Fail("Failed to resolve Supabase branch")
+		return nil, err
+	}
+	sp.Stop()
+
+	if c.targets == nil {
+		c.targets = make(map[string]*supabase.BranchInfo)
+	}
+	c.targets[explicit] = info
+	return info, nil
+}
+
+// WithArgs returns a copy of c with different arguments, for running another
+// command body with the state resolved so far.
+func (c *Context) WithArgs(args ...string) *Context {
+	next := *c
+	next.Args = args
+	return &next
+}
+
+// Arg returns the i-th argument, or "" when there are fewer.
+func (c *Context) Arg(i int) string {
+	if i < len(c.Args) {
+		return c.Args[i]
+	}
+	return ""
+}
+
+// JSON reports whether the command has a --json flag and it is set.
+func (c *Context) JSON() bool {
+	return c.boolFlag("json")
+}
+
+// DryRun reports whether the command has a --dry-run flag and it is set.
+func (c *Context) DryRun() bool {
+	return c.boolFlag("dry-run")
+}
+
+// PrintJSON writes v to Out as indented JSON.
+func (c *Context) PrintJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.Out, string(data))
+	return err
+}
+
+// Apply runs fn unless this is a dry run, in which case it reports what
+// would have been done instead.
+func (c *Context) Apply(description string, fn func() error) error {
+	if c.DryRun() {
+		ui.Infof("Would %s", description)
+		return nil
+	}
+	return fn()
+}
+
+func (c *Context) boolFlag(name string) bool {
+	if c.Cmd == nil {
+		return false
+	}
+	f := c.Cmd.Flags().Lookup(name)
+	return f != nil && f.Value.Type() == "bool" && f.Value.String() == "true"
+}
+
+// traceMiddleware logs each command's arguments and duration in verbose
+// mode.
+func traceMiddleware(next RunFunc) RunFunc {
+	return func(ctx *Context) error {
+		if !IsVerbose() {
+			return next(ctx)
+		}
+		start := time.Now()
+		shell.VerboseLog("%s %s", ctx.Cmd.CommandPath(), strings.Join(ctx.Args, " "))
+		err := next(ctx)
+		status := "ok"
+		if err != nil {
+			status = err.Error()
+		}
+		shell.VerboseLog("%s finished in %s (%s)", ctx.Cmd.CommandPath(), time.Since(start).Round(time.Millisecond), status)
+		return err
+	}
+}
+
+// jsonMiddleware moves human output to stderr while --json is set, so stdout
+// carries only what the command writes to Out.
+func jsonMiddleware(next RunFunc) RunFunc {
+	return func(ctx *Context) error {
+		if !ctx.JSON() {
+			return next(ctx)
+		}
+		stdout := os.Stdout
+		ctx.Out = stdout
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+		return next(ctx)
+	}
+}
+
+// dryRunMiddleware announces dry runs before the command starts.
+func dryRunMiddleware(next RunFunc) RunFunc {
+	return func(ctx *Context) error {
+		if ctx.DryRun() {
+			ui.Info("Dry run: no changes will be made")
+		}
+		return next(ctx)
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRun_MiddlewareOrder(t *testing.T) {
+	var calls []string
+	mark := func(name string) Middleware {
+		return func(next RunFunc) RunFunc {
+			return func(ctx *Context) error {
+				calls = append(calls, name)
+				return next(ctx)
+			}
+		}
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	var got *Context
+	runE := Run(func(ctx *Context) error {
+		got = ctx
+		calls = append(calls, "run")
+		return nil
+	}, mark("first"), mark("second"))
+
+	if err := runE(cmd, []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "first,second,run" {
+		t.Errorf("calls = %v, want first,second,run", calls)
+	}
+	if ContextFor(cmd) != got {
+		t.Error("ContextFor() did not return the command's Context")
+	}
+	if got.Arg(1) != "b" || got.Arg(2) != "" {
+		t.Errorf("Arg(1), Arg(2) = %q, %q; want b and empty", got.Arg(1), got.Arg(2))
+	}
+}
+
+func TestContext_DryRunApply(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().Bool("dry-run", false, "")
+	ctx := &Context{Cmd: cmd}
+
+	applied := false
+	apply := func() error { applied = true; return nil }
+
+	if err := ctx.Apply("do it", apply); err != nil || !applied {
+		t.Fatalf("Apply() without --dry-run: applied = %v, err = %v", applied, err)
+	}
+
+	applied = false
+	if err := cmd.Flags().Set("dry-run", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.Apply("do it", apply); err != nil || applied {
+		t.Errorf("Apply() with --dry-run: applied = %v, err = %v", applied, err)
+	}
+}
+
+func TestJSONMiddleware_SeparatesOutput(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().Bool("json", false, "")
+	if err := cmd.Flags().Set("json", "true"); err != nil {
+		t.Fatal(err)
+	}
+
+	stdoutR, stdoutW, _ := os.Pipe()
+	stderrR, stderrW, _ := os.Pipe()
+	origStdout, origStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdoutW, stderrW
+
+	err := Run(func(ctx *Context) error {
+		os.Stdout.WriteString("human\n")
+		return ctx.PrintJSON(map[string]int{"n": 1})
+	})(cmd, nil)
+
+	os.Stdout, os.Stderr = origStdout, origStderr
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	io.Copy(&out, stdoutR)
+	io.Copy(&errOut, stderrR)
+	if got := out.String(); got != "{\n  \"n\": 1\n}\n" {
+		t.Errorf("stdout = %q, want only JSON", got)
+	}
+	if !strings.Contains(errOut.String(), "human") {
+		t.Errorf("stderr = %q, want human output", errOut.String())
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,6 +12,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
 	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/errs"
 	"github.com/undrift/drift/internal/git"
 	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
@@ -44,7 +46,7 @@ Examples:
   drift db dump prod mybackup     # Dump to mybackup.backup
   drift db dump prod -o custom.sql  # Dump to custom.sql`,
 	Args: cobra.RangeArgs(1, 2),
-	RunE: runDbDump,
+	RunE: Run(runDbDump, RequireProject, GuardWorktree),
 }
 
 var dbPushCmd = &cobra.Command{
@@ -58,9 +60,12 @@ Examples:
   drift db push           # Interactive: select from all branches
   drift db push dev       # Push prod backup to development
   drift db push feature   # Push dev backup to current feature branch
-  drift db push feature -i prod_20260215_143000.backup`,
+  drift db push feature -i prod_20260215_143000.backup
+  drift db push feature --from-branch dev   # Stream dev straight in, no backup file
+  drift db push feature --wait             # Wait for a new preview branch to provision
+  drift db push feature --resume           # Continue an interrupted push`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: runDbPush,
+	RunE: Run(runDbPush, RequireProject, GuardWorktree),
 }
 
 var dbSeedCmd = &cobra.Command{
@@ -75,7 +80,7 @@ Examples:
   drift db seed                    # Generate seed.sql from dev
   drift db seed --source prod      # Generate from production
   drift db seed --tables users,profiles  # Only specific tables`,
-	RunE: runDbSeed,
+	RunE: Run(runDbSeed, RequireProject, GuardWorktree),
 }
 
 var dbListCmd = &cobra.Command{
@@ -88,7 +93,7 @@ Optional filters:
   dev / development     List development backups (dev*.backup)
   <name>.backup         List an exact backup file name`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: runDbList,
+	RunE: Run(runDbList),
 }
 
 var (
@@ -104,6 +109,7 @@ var (
 func init() {
 	dbDumpCmd.Flags().StringVarP(&dbOutputFlag, "output", "o", "", "Output file path")
 	dbPushCmd.Flags().StringVarP(&dbInputFlag, "input", "i", "", "Input backup file")
+	addProvisionWaitFlags(dbPushCmd)
 	dbPushCmd.Flags().StringVar(&dbPasswordFlag, "password", "", "Target database password (or use env var)")
 	dbPushCmd.Flags().StringVar(&dbPushPoolerMode, "pooler-mode", "prompt", "Pooler mode for restore (prompt|transaction|session)")
 	dbPushCmd.Flags().StringVar(&dbPushCopyScope, "copy-scope", "prompt", "Copy scope for plain SQL restore (prompt|safe|all)")
@@ -187,11 +193,11 @@ func selectDbPushPoolerMode() (string, error) {
 	}
 }
 
-func poolerPortForMode(mode string) int {
-	if mode == "session" {
-		return 5432
-	}
-	return 6543
+// dbConnections returns the connection builder for the project's pooler:
+// session mode on database.direct_port, transaction mode on
+// database.pooler_port.
+func dbConnections(cfg *config.Config) database.ConnectionBuilder {
+	return database.NewConnectionBuilder(cfg.Database.DirectPort, cfg.Database.GetPoolerPort())
 }
 
 func selectDbPushCopyScope() (string, error) {
@@ -224,12 +230,9 @@ func selectDbPushCopyScope() (string, error) {
 	}
 }
 
-func runDbDump(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	env := args[0]
-	cfg := config.LoadOrDefault()
+func runDbDump(ctx *Context) error {
+	env := ctx.Args[0]
+	cfg := ctx.Config()
 
 	// Validate environment
 	if env != "prod" && env != "production" && env != "dev" && env != "development" {
@@ -245,7 +248,7 @@ func runDbDump(cmd *cobra.Command, args []string) error {
 	ui.Header(fmt.Sprintf("Database Dump - %s", envName))
 
 	// Get branch info from Supabase
-	client := supabase.NewClient()
+	client := ctx.Client()
 	var branch *supabase.Branch
 	var err error
 
@@ -295,13 +298,16 @@ func runDbDump(cmd *cobra.Command, args []string) error {
 		poolerHost = cfg.Database.GetPoolerHostForBranch(gitBranch)
 	}
 
-	// Use session mode (port 5432) for pg_dump
-	poolerPort := 5432
+	poolerPort := dbConnections(cfg).Port(database.OpDump.Mode())
 	poolerUser := fmt.Sprintf("postgres.%s", projectRef)
 
 	ui.KeyValue("Environment", envColorString(envName))
 	ui.KeyValue("Project Ref", ui.Cyan(projectRef))
 	ui.KeyValue("Pooler", fmt.Sprintf("%s:%d", poolerHost, poolerPort))
+	excludedTables, ok, err := dbExcludedTables(cfg)
+	if err != nil || !ok {
+		return err
+	}
 
 	// Confirm for production
 	if isProd && !IsYes() {
@@ -310,7 +316,7 @@ func runDbDump(cmd *cobra.Command, args []string) error {
 		confirmed, err := ui.PromptYesNo("Continue?", true)
 		if err != nil || !confirmed {
 			ui.Info("Cancelled")
-			return nil
+			return errs.Cancelled("dump production")
 		}
 	}
 
@@ -320,13 +326,14 @@ func runDbDump(cmd *cobra.Command, args []string) error {
 	opts.Port = poolerPort
 	opts.User = poolerUser
 	opts.Password = password
+	opts.ExcludeTableData = excludedTables
 
 	// Determine output filename: -o flag > positional arg > default
 	if dbOutputFlag != "" {
 		opts.OutputFile = dbOutputFlag
-	} else if len(args) > 1 {
+	} else if len(ctx.Args) > 1 {
 		// User provided filename as second argument
-		filename := args[1]
+		filename := ctx.Args[1]
 		// Add .backup extension if not present
 		if !strings.HasSuffix(filename, ".backup") && !strings.HasSuffix(filename, ".sql") {
 			filename = filename + ".backup"
@@ -379,129 +386,145 @@ func runDbDump(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runDbPush(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
+func runDbPush(ctx *Context) error {
+	client := ctx.Client()
 
-	client := supabase.NewClient()
+	target, err := resolveDbPushTarget(client, ctx.Args)
+	if err != nil {
+		return err
+	}
+	if err := ensureBranchProvisioned(client, target.Branch); err != nil {
+		return err
+	}
+	if dbPushFromBranchFlag != "" {
+		return runDbPushFromBranch(client, target)
+	}
+	sourceFile := target.SourceFile
+	targetEnv := target.Env
+	targetBranch := target.Branch
 
-	// Validate target
-	var sourceFile string
-	var targetEnv string
-	var targetBranch *supabase.Branch
-	var targetGitBranch string
+	targetProjectRef := targetBranch.ProjectRef
+	cfg := ctx.Config()
 
-	// If no target specified, show interactive branch picker
-	if len(args) == 0 {
-		branches, err := client.GetBranches()
-		if err != nil {
-			return fmt.Errorf("failed to get branches: %w", err)
+	var resume *dbPushResume
+	if dbPushResumeFlag {
+		if resume, err = loadDbPushResume(cfg, targetBranch); err != nil {
+			return err
 		}
-
-		// Filter out production branch (can't push to prod)
-		var selectableBranches []supabase.Branch
-		for _, b := range branches {
-			if !b.IsDefault { // Exclude production
-				selectableBranches = append(selectableBranches, b)
-			}
+		sourceFile = resume.Backup
+		ui.Infof("Resuming the push of %s at %s (%d of %d chunks applied)",
+			backupDisplayPath(sourceFile, cfg.ProjectRoot()), resume.FailedChunk, resume.Applied, resume.Total)
+	} else {
+		sourceFile, err = selectDbPushBackup(ctx, target)
+		if err != nil || sourceFile == "" {
+			return err
 		}
+	}
 
-		if len(selectableBranches) == 0 {
-			return fmt.Errorf("no non-production branches available to push to")
-		}
+	ui.Header(fmt.Sprintf("Database Push - %s", targetEnv))
 
-		// Build options for picker
-		options := make([]string, len(selectableBranches))
-		for i, b := range selectableBranches {
-			envType := "Feature"
-			if b.Persistent {
-				envType = "Development"
-			}
-			options[i] = fmt.Sprintf("%s (%s) → %s", b.GitBranch, envType, b.ProjectRef)
-		}
+	opts, poolerMode, err := dbPushRestoreOptions(client, cfg, target)
+	if err != nil {
+		return err
+	}
+	copyScope := ""
+	if resume != nil {
+		copyScope = resume.CopyScope
+	} else if copyScope, err = selectDbPushCopyScope(); err != nil {
+		return err
+	}
 
-		ui.Header("Select Target Branch")
-		selected, err := ui.PromptSelect("Push backup to", options)
-		if err != nil {
-			return fmt.Errorf("branch selection cancelled: %w", err)
-		}
+	ui.KeyValue("Source", sourceFile)
+	ui.KeyValue("Target", envColorString(targetEnv))
+	ui.KeyValue("Project Ref", ui.Cyan(targetProjectRef))
+	if poolerMode == "transaction" {
+		ui.KeyValue("Pooler Mode", "transaction (recommended)")
+	} else {
+		ui.KeyValue("Pooler Mode", "session")
+	}
+	if copyScope == "all" {
+		ui.KeyValue("Copy Scope", "all insertable tables (best effort)")
+	} else {
+		ui.KeyValue("Copy Scope", "safe default (public + auth + migrations)")
+	}
+	ui.KeyValue("Pooler", fmt.Sprintf("%s:%d", opts.Host, opts.Port))
+	excludedTables, ok, err := dbExcludedTables(cfg)
+	if err != nil || !ok {
+		return err
+	}
 
-		// Find the selected branch
-		for i, opt := range options {
-			if opt == selected {
-				targetBranch = &selectableBranches[i]
-				targetGitBranch = targetBranch.GitBranch
-				if targetBranch.Persistent {
-					targetEnv = "Development"
-					sourceFile = "prod.backup"
-				} else {
-					targetEnv = "Feature"
-					sourceFile = "dev.backup"
-				}
-				break
-			}
-		}
+	if !confirmDbPush(targetEnv, "this backup") {
+		return errs.Cancelled("db push")
+	}
+
+	ui.NewLine()
 
-		if targetBranch == nil {
-			return fmt.Errorf("no branch selected")
+	opts.InputFile = sourceFile
+	// Use a single transaction so transaction-pooler mode keeps one backend
+	// for the full restore and session settings apply consistently. Large
+	// backups use one transaction per table instead, so a dropped
+	// connection can be resumed rather than rolling everything back.
+	opts.SingleTxn = true
+	opts.CopyAllInsertableTables = copyScope == "all"
+	opts.ExcludeTables = excludedTables
+	if resume != nil {
+		opts.Resumable = true
+		opts.ResumeFrom = resume.Applied
+	} else {
+		opts.Resumable = dbPushResumable(sourceFile)
+	}
+	if opts.Resumable {
+		ui.KeyValue("Transactions", "one per table (resumable with --resume)")
+		point := newDbPushResume(sourceFile, targetBranch, copyScope)
+		opts.OnChunk = func(applied, total int, chunk string) {
+			point.Applied, point.Total = applied, total
+			saveDbPushResume(cfg, targetBranch, point)
 		}
 	} else {
-		target := args[0]
+		ui.KeyValue("Transactions", "single (all or nothing)")
+	}
 
-		switch target {
-		case "dev", "development":
-			sourceFile = "prod.backup"
-			targetEnv = "Development"
-			branch, err := client.GetDevelopmentBranch()
-			if err != nil {
-				return fmt.Errorf("failed to get development branch: %w", err)
-			}
-			targetBranch = branch
-			targetGitBranch = branch.GitBranch
-		case "feature":
-			sourceFile = "dev.backup"
-			targetEnv = "Feature"
-			// Get project ref from current branch
-			gitBranch, err := git.CurrentBranch()
-			if err != nil {
-				return err
-			}
-			branch, _, err := client.ResolveBranch(gitBranch)
-			if err != nil {
-				return err
-			}
-			if branch == nil {
-				return fmt.Errorf("no Supabase branch found for '%s'", gitBranch)
-			}
-			targetBranch = branch
-			targetGitBranch = gitBranch
-		default:
-			// Try to find branch by name
-			branch, err := client.GetBranch(target)
-			if err != nil {
-				return fmt.Errorf("invalid target '%s': not a known target (dev, feature) or branch name", target)
-			}
-			if branch.IsDefault {
-				return fmt.Errorf("cannot push to production branch")
-			}
-			targetBranch = branch
-			targetGitBranch = branch.GitBranch
-			if branch.Persistent {
-				targetEnv = "Development"
-				sourceFile = "prod.backup"
-			} else {
-				targetEnv = "Feature"
-				sourceFile = "dev.backup"
-			}
+	start := time.Now()
+	release, err := acquirePushLock(opts, targetBranch.Name, "db push")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	resolveDbPushAuthTables(&opts, copyScope)
+
+	// Perform restore
+	sp := ui.NewSpinner(fmt.Sprintf("Restoring database from %s", sourceFile))
+	sp.Start()
+
+	if err := database.Restore(opts); err != nil {
+		sp.Fail("Restore failed")
+		notifyOperation(cfg, "db push", targetEnv, targetBranch.Name, start, err)
+		var restoreErr *database.RestoreError
+		if errors.As(err, &restoreErr) {
+			recordDbPushFailure(cfg, target, sourceFile, copyScope, restoreErr)
 		}
+		return err
 	}
 
-	targetProjectRef := targetBranch.ProjectRef
-	cfg := config.LoadOrDefault()
+	sp.Success("Database restored successfully")
+	clearDbPushResume(cfg, targetBranch)
+	notifyOperation(cfg, "db push", targetEnv, targetBranch.Name, start, nil)
+
+	printDbPushNextSteps()
+	return nil
+}
+
+// selectDbPushBackup picks the backup to push to target: --input, the
+// expected backup, or one chosen from the local backups, offering to refresh
+// it when it is old. It returns "" if the user cancels.
+func selectDbPushBackup(ctx *Context, target *dbPushTarget) (string, error) {
+	cfg := ctx.Config()
+	sourceFile := target.SourceFile
+	targetEnv := target.Env
 	backups, err := discoverLocalBackups(cfg)
 	if err != nil {
-		return err
+		return "", err
 	}
 	expectedSourceFile := sourceFile
 	sourcePrefix := "dev"
@@ -514,21 +537,21 @@ func runDbPush(cmd *cobra.Command, args []string) error {
 		sourceFile, err = resolveBackupInputPath(dbInputFlag, backups)
 		if err != nil {
 			if len(backups) == 0 {
-				return fmt.Errorf("%w\nNo backup files found in %s or %s", err, cfg.GetBackupPath(), cfg.ProjectRoot())
+				return "", fmt.Errorf("%w\nNo backup files found in %s or %s", err, cfg.GetBackupPath(), cfg.ProjectRoot())
 			}
-			return fmt.Errorf("%w\nRun 'drift db list' to view available backups", err)
+			return "", fmt.Errorf("%w\nRun 'drift db list' to view available backups", err)
 		}
 	} else {
 		if exact := findLocalBackupByName(backups, expectedSourceFile); exact != nil {
 			sourceFile = exact.Path
 		} else {
 			if len(backups) == 0 {
-				return fmt.Errorf("backup file not found: %s\nNo backup files found in %s or %s\nRun 'drift db dump %s' first", expectedSourceFile, cfg.GetBackupPath(), cfg.ProjectRoot(), sourcePrefix)
+				return "", fmt.Errorf("backup file not found: %s\nNo backup files found in %s or %s\nRun 'drift db dump %s' first", expectedSourceFile, cfg.GetBackupPath(), cfg.ProjectRoot(), sourcePrefix)
 			}
 
 			suggested := suggestLocalBackup(backups, expectedSourceFile, sourcePrefix)
 			if suggested == nil {
-				return fmt.Errorf("no backup files found")
+				return "", fmt.Errorf("no backup files found")
 			}
 
 			if IsYes() {
@@ -554,7 +577,7 @@ func runDbPush(cmd *cobra.Command, args []string) error {
 				ui.Header("Select Backup File")
 				selected, err := ui.PromptSelect("Use backup", options)
 				if err != nil {
-					return fmt.Errorf("backup selection cancelled: %w", err)
+					return "", fmt.Errorf("backup selection cancelled: %w", err)
 				}
 
 				sourceFile = lookup[selected].Path
@@ -564,7 +587,7 @@ func runDbPush(cmd *cobra.Command, args []string) error {
 
 	// Check source file exists
 	if _, err := os.Stat(sourceFile); os.IsNotExist(err) {
-		return fmt.Errorf("backup file not found: %s\nRun 'drift db dump' first", sourceFile)
+		return "", fmt.Errorf("backup file not found: %s\nRun 'drift db dump' first", sourceFile)
 	}
 
 	// Check backup freshness
@@ -580,8 +603,8 @@ func runDbPush(cmd *cobra.Command, args []string) error {
 					if targetEnv == "Feature" {
 						dumpEnv = "dev"
 					}
-					if err := runDbDump(cmd, []string{dumpEnv}); err != nil {
-						return err
+					if err := runDbDump(ctx.WithArgs(dumpEnv)); err != nil {
+						return "", err
 					}
 
 					refreshedBackups, discoverErr := discoverLocalBackups(cfg)
@@ -601,152 +624,245 @@ func runDbPush(cmd *cobra.Command, args []string) error {
 		confirmed, err := ui.PromptYesNo("Use selected backup?", true)
 		if err != nil || !confirmed {
 			ui.Info("Cancelled")
-			return nil
+			return "", nil
 		}
 	}
 
-	ui.Header(fmt.Sprintf("Database Push - %s", targetEnv))
+	return sourceFile, nil
+}
+
+// dbPushTarget is the branch a backup is restored to, with the backup that
+// is expected by default for it.
+type dbPushTarget struct {
+	Branch     *supabase.Branch
+	GitBranch  string
+	Env        string // Development or Feature
+	SourceFile string
+}
+
+// resolveDbPushTarget resolves the target argument of 'drift db push', or
+// shows a branch picker when there is none. Production is never a target.
+func resolveDbPushTarget(client *supabase.Client, args []string) (*dbPushTarget, error) {
+	target := &dbPushTarget{}
+
+	// If no target specified, show interactive branch picker
+	if len(args) == 0 {
+		branches, err := client.GetBranches()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get branches: %w", err)
+		}
+
+		// Filter out production branch (can't push to prod)
+		var selectableBranches []supabase.Branch
+		for _, b := range branches {
+			if !b.IsDefault { // Exclude production
+				selectableBranches = append(selectableBranches, b)
+			}
+		}
+
+		if len(selectableBranches) == 0 {
+			return nil, fmt.Errorf("no non-production branches available to push to")
+		}
+
+		// Build options for picker
+		options := make([]string, len(selectableBranches))
+		for i, b := range selectableBranches {
+			envType := "Feature"
+			if b.Persistent {
+				envType = "Development"
+			}
+			options[i] = fmt.Sprintf("%s (%s) → %s", b.GitBranch, envType, b.ProjectRef)
+		}
+
+		ui.Header("Select Target Branch")
+		selected, err := ui.PromptSelect("Push backup to", options)
+		if err != nil {
+			return nil, fmt.Errorf("branch selection cancelled: %w", err)
+		}
+
+		// Find the selected branch
+		for i, opt := range options {
+			if opt == selected {
+				target.Branch = &selectableBranches[i]
+				target.GitBranch = target.Branch.GitBranch
+				if target.Branch.Persistent {
+					target.Env = "Development"
+					target.SourceFile = "prod.backup"
+				} else {
+					target.Env = "Feature"
+					target.SourceFile = "dev.backup"
+				}
+				break
+			}
+		}
+
+		if target.Branch == nil {
+			return nil, fmt.Errorf("no branch selected")
+		}
+	} else {
+		name := args[0]
+
+		switch name {
+		case "dev", "development":
+			target.SourceFile = "prod.backup"
+			target.Env = "Development"
+			branch, err := client.GetDevelopmentBranch()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get development branch: %w", err)
+			}
+			target.Branch = branch
+			target.GitBranch = branch.GitBranch
+		case "feature":
+			target.SourceFile = "dev.backup"
+			target.Env = "Feature"
+			// Get project ref from current branch
+			gitBranch, err := git.CurrentBranch()
+			if err != nil {
+				return nil, err
+			}
+			branch, _, err := client.ResolveBranch(gitBranch)
+			if err != nil {
+				return nil, err
+			}
+			if branch == nil {
+				return nil, fmt.Errorf("no Supabase branch found for '%s'", gitBranch)
+			}
+			target.Branch = branch
+			target.GitBranch = gitBranch
+		default:
+			// Try to find branch by name
+			branch, err := client.GetBranch(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid target '%s': not a known target (dev, feature) or branch name", name)
+			}
+			if branch.IsDefault {
+				return nil, fmt.Errorf("cannot push to production branch")
+			}
+			target.Branch = branch
+			target.GitBranch = branch.GitBranch
+			if branch.Persistent {
+				target.Env = "Development"
+				target.SourceFile = "prod.backup"
+			} else {
+				target.Env = "Feature"
+				target.SourceFile = "dev.backup"
+			}
+		}
+	}
+
+	return target, nil
+}
+
+// dbPushRestoreOptions returns pooler connection options for the target
+// branch, and the selected pooler mode. The connection is probed first so a
+// host or mode that refuses connections fails before anything is changed.
+func dbPushRestoreOptions(client *supabase.Client, cfg *config.Config, target *dbPushTarget) (database.RestoreOptions, string, error) {
+	conn := database.Connection{User: fmt.Sprintf("postgres.%s", target.Branch.ProjectRef), Database: "postgres"}
 
 	// Get connection info using experimental API (includes correct pooler host)
-	connInfo, err := client.GetBranchConnectionInfo(targetGitBranch)
+	connInfo, err := client.GetBranchConnectionInfo(target.GitBranch)
 	if err != nil {
 		ui.Warning(fmt.Sprintf("Could not get connection info via API: %v", err))
 	}
 
 	// Get password - for non-production, API has the real password (no need for env vars)
-	var password string
 	if connInfo != nil && connInfo.PostgresURL != "" {
 		// Non-production branches: API returns the actual password
-		password = supabase.ExtractPasswordFromURL(connInfo.PostgresURL)
+		conn.Password = supabase.ExtractPasswordFromURL(connInfo.PostgresURL)
 	}
 	// Fallback to env vars if API didn't return password
-	if password == "" {
-		password = getDbPassword("dev")
+	if conn.Password == "" {
+		conn.Password = getDbPassword("dev")
 	}
 	// Last resort: prompt
-	if password == "" {
-		password, err = ui.PromptPassword("Target database password")
+	if conn.Password == "" {
+		conn.Password, err = ui.PromptPassword("Target database password")
 		if err != nil {
-			return err
+			return conn.RestoreOptions(), "", err
 		}
 	}
 
 	// Determine pooler host - prefer from API, fallback to config
-	var poolerHost string
 	if connInfo != nil && connInfo.PoolerHost != "" {
-		poolerHost = connInfo.PoolerHost
+		conn.Host = connInfo.PoolerHost
 	} else {
 		// Fallback to config (shouldn't happen if API works)
-		poolerHost = cfg.Database.GetPoolerHostForBranch(targetGitBranch)
+		conn.Host = cfg.Database.GetPoolerHostForBranch(target.GitBranch)
 	}
 
 	poolerMode, err := selectDbPushPoolerMode()
 	if err != nil {
-		return err
-	}
-
-	poolerPort := poolerPortForMode(poolerMode)
-	poolerUser := fmt.Sprintf("postgres.%s", targetProjectRef)
-	copyScope, err := selectDbPushCopyScope()
-	if err != nil {
-		return err
+		return conn.RestoreOptions(), "", err
 	}
 
-	ui.KeyValue("Source", sourceFile)
-	ui.KeyValue("Target", envColorString(targetEnv))
-	ui.KeyValue("Project Ref", ui.Cyan(targetProjectRef))
-	if poolerMode == "transaction" {
-		ui.KeyValue("Pooler Mode", "transaction (recommended)")
-	} else {
-		ui.KeyValue("Pooler Mode", "session")
+	builder := dbConnections(cfg)
+	conn = builder.WithMode(conn, database.PoolerMode(poolerMode))
+	if err := builder.Probe(conn); err != nil {
+		return conn.RestoreOptions(), "", errs.Networkf("%w\nRun 'drift db ping -b %s' to find a host and mode that work", err, target.GitBranch)
 	}
-	if copyScope == "all" {
-		ui.KeyValue("Copy Scope", "all insertable tables (best effort)")
-	} else {
-		ui.KeyValue("Copy Scope", "safe default (public + auth + migrations)")
-	}
-	ui.KeyValue("Pooler", fmt.Sprintf("%s:%d", poolerHost, poolerPort))
+	return conn.RestoreOptions(), poolerMode, nil
+}
 
-	// Confirm - stricter for development (permanent branch) vs feature (preview)
+// confirmDbPush confirms replacing the target database with source. It is
+// stricter for development (permanent branch) than for feature (preview).
+func confirmDbPush(targetEnv, source string) bool {
 	if targetEnv == "Development" {
 		// Development is a persistent branch, require stricter confirmation
-		confirmed, err := ConfirmDestructiveOperation("REPLACE the development database with this backup")
-		if err != nil || !confirmed {
-			return nil
-		}
-	} else if !IsYes() {
-		ui.NewLine()
-		ui.Warning("This will REPLACE the target database!")
-		confirmed, err := ui.PromptYesNo("Continue?", false)
-		if err != nil || !confirmed {
-			ui.Info("Cancelled")
-			return nil
-		}
+		confirmed, err := ConfirmDestructiveOperation("REPLACE the development database with " + source)
+		return err == nil && confirmed
+	}
+	if IsYes() {
+		return true
 	}
-
 	ui.NewLine()
+	ui.Warning("This will REPLACE the target database!")
+	confirmed, err := ui.PromptYesNo("Continue?", false)
+	if err != nil || !confirmed {
+		ui.Info("Cancelled")
+		return false
+	}
+	return true
+}
 
-	// Set up restore options using pooler connection
-	opts := database.DefaultRestoreOptions()
-	opts.Host = poolerHost
-	opts.Port = poolerPort
-	opts.User = poolerUser
-	opts.Password = password
-	opts.InputFile = sourceFile
-	// Use a single transaction so transaction-pooler mode keeps one backend
-	// for the full restore and session settings apply consistently.
-	opts.SingleTxn = true
-	opts.CopyAllInsertableTables = copyScope == "all"
-
-	// Resolve auth table copy scope up front so it's visible before restore.
-	if copyScope == "safe" {
-		authCopyTables, authTableErr := database.ResolveAllowedAuthCopyTables(opts)
-		if authTableErr != nil {
-			ui.Warning(fmt.Sprintf("Could not resolve auth copy tables ahead of restore: %v", authTableErr))
-		} else {
-			opts.AuthCopyTables = authCopyTables
-			if len(authCopyTables) == 0 {
-				ui.KeyValue("Auth Copy Tables", "none")
-			} else {
-				ui.KeyValue("Auth Copy Tables", strings.Join(authCopyTables, ", "))
-			}
-		}
-	} else {
+// resolveDbPushAuthTables resolves the auth table copy scope up front so
+// it's visible before restore.
+func resolveDbPushAuthTables(opts *database.RestoreOptions, copyScope string) {
+	if copyScope != "safe" {
 		ui.Warning("All-scope restore may still skip tables without INSERT privilege on target")
+		return
 	}
-
-	// Perform restore
-	sp := ui.NewSpinner(fmt.Sprintf("Restoring database from %s", sourceFile))
-	sp.Start()
-
-	if err := database.Restore(opts); err != nil {
-		sp.Fail("Restore failed")
-		return err
+	authCopyTables, err := database.ResolveAllowedAuthCopyTables(*opts)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not resolve auth copy tables ahead of restore: %v", err))
+		return
 	}
+	opts.AuthCopyTables = authCopyTables
+	if len(authCopyTables) == 0 {
+		ui.KeyValue("Auth Copy Tables", "none")
+	} else {
+		ui.KeyValue("Auth Copy Tables", strings.Join(authCopyTables, ", "))
+	}
+}
 
-	sp.Success("Database restored successfully")
-
-	// Show next steps
+func printDbPushNextSteps() {
 	ui.NewLine()
 	ui.SubHeader("Next Steps")
 	ui.List("drift migrate history    - Check if migrations need to be applied")
 	ui.List("drift migrate push       - Apply any pending migrations")
 	ui.List("drift deploy functions   - Deploy edge functions")
 	ui.List("drift secrets copy       - Copy secrets from dev to this branch")
-	ui.List("drift status             - Check service health")
-
-	return nil
+	ui.List("drift status services    - Check service health")
 }
 
-func runDbList(cmd *cobra.Command, args []string) error {
+func runDbList(ctx *Context) error {
 	ui.Header("Local Database Backups")
-	cfg := config.LoadOrDefault()
+	cfg := ctx.Config()
 	backups, err := discoverLocalBackups(cfg)
 	if err != nil {
 		return err
 	}
-	if len(args) > 0 {
-		backups = filterLocalBackups(backups, args[0])
+	if len(ctx.Args) > 0 {
+		backups = filterLocalBackups(backups, ctx.Args[0])
 	}
 
 	if len(backups) == 0 {
@@ -766,17 +882,14 @@ func runDbList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runDbSeed(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runDbSeed(ctx *Context) error {
+	cfg := ctx.Config()
 
 	ui.Header("Generate Seed Data")
 
 	// Interactive source selection if not specified via flag
 	source := dbSeedSource
-	if !cmd.Flags().Changed("source") {
+	if !ctx.Cmd.Flags().Changed("source") {
 		options := []string{
 			"prod - Production database",
 			"dev - Development database",
@@ -802,7 +915,7 @@ func runDbSeed(cmd *cobra.Command, args []string) error {
 	ui.KeyValue("Source", envColorString(sourceName))
 
 	// Get branch info
-	client := supabase.NewClient()
+	client := ctx.Client()
 	var branch *supabase.Branch
 	var err error
 
@@ -848,12 +961,12 @@ func runDbSeed(cmd *cobra.Command, args []string) error {
 		poolerHost = cfg.Database.GetPoolerHostForBranch(gitBranch)
 	}
 
-	poolerPort := 5432
+	poolerPort := dbConnections(cfg).Port(database.OpDump.Mode())
 	poolerUser := fmt.Sprintf("postgres.%s", projectRef)
 
 	// Query for public tables if not specified via flag
 	var selectedTables []string
-	if !cmd.Flags().Changed("tables") {
+	if !ctx.Cmd.Flags().Changed("tables") {
 		ui.NewLine()
 		sp := ui.NewSpinner("Fetching public tables")
 		sp.Start()
@@ -914,8 +1027,7 @@ func runDbSeed(cmd *cobra.Command, args []string) error {
 	ui.KeyValue("Pooler", fmt.Sprintf("%s:%d", poolerHost, poolerPort))
 
 	// Determine output path
-	outputPath := filepath.Join(cfg.Supabase.MigrationsDir, "..", "seed.sql")
-	outputPath = filepath.Clean(outputPath)
+	outputPath := defaultSeedPath(cfg)
 
 	ui.KeyValue("Output", outputPath)
 
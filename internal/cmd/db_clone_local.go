@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+var dbCloneToLocalCmd = &cobra.Command{
+	Use:   "clone-to-local",
+	Short: "Start local Supabase with migrations, data, and env config",
+	Long: `Set up a complete offline development environment in one step:
+
+  1. Start the local Supabase stack ('supabase start'), or reuse it if running
+  2. Apply migrations to the local database
+  3. Restore the freshest dev backup, or apply a seed profile with --seed
+  4. Generate the env config (.env.local or xcconfig) pointing at the local stack
+
+Backups come from 'drift db dump dev'; pick a different one with --input.
+Use --seed default for supabase/seed.sql or --seed <profile> for a profile
+from database.seed_profiles.`,
+	Example: `  drift db clone-to-local                     # Freshest dev backup
+  drift db clone-to-local -i prod_20260215.backup
+  drift db clone-to-local --seed demo         # Seed profile instead of a backup
+  drift db clone-to-local --no-data           # Schema only
+  drift db clone-to-local --no-env            # Leave env config untouched`,
+	Args: cobra.NoArgs,
+	RunE: Run(runDbCloneToLocal, RequireProject),
+}
+
+var (
+	dbCloneInputFlag  string
+	dbCloneSeedFlag   string
+	dbCloneNoDataFlag bool
+	dbCloneNoEnvFlag  bool
+)
+
+func init() {
+	dbCloneToLocalCmd.Flags().StringVarP(&dbCloneInputFlag, "input", "i", "", "Backup file to restore (default: freshest dev backup)")
+	dbCloneToLocalCmd.Flags().StringVar(&dbCloneSeedFlag, "seed", "", "Apply a seed instead of a backup ('default' or a database.seed_profiles name)")
+	dbCloneToLocalCmd.Flags().BoolVar(&dbCloneNoDataFlag, "no-data", false, "Skip restoring data")
+	dbCloneToLocalCmd.Flags().BoolVar(&dbCloneNoEnvFlag, "no-env", false, "Skip generating env config")
+
+	dbCmd.AddCommand(dbCloneToLocalCmd)
+}
+
+func runDbCloneToLocal(ctx *Context) error {
+	cfg := ctx.Config()
+
+	if dbCloneSeedFlag != "" && dbCloneInputFlag != "" {
+		return fmt.Errorf("use either --seed or --input, not both")
+	}
+	if dbCloneNoDataFlag && (dbCloneSeedFlag != "" || dbCloneInputFlag != "") {
+		return fmt.Errorf("--no-data cannot be combined with --seed or --input")
+	}
+	if !supabase.IsCLIInstalled() {
+		return fmt.Errorf("supabase CLI not found (install with: brew install supabase/tap/supabase)")
+	}
+
+	ui.Header("Clone to Local")
+
+	// Pick the data source up front so a missing backup fails before the stack starts
+	var dataFile, dataLabel string
+	var isSeed bool
+	if !dbCloneNoDataFlag {
+		var err error
+		dataFile, dataLabel, isSeed, err = selectCloneDataSource(cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	client := ctx.Client()
+	start := time.Now()
+
+	// 1. Local stack
+	sp := ui.NewSpinner("Checking local Supabase")
+	sp.Start()
+	stack, err := client.LocalStatus()
+	if err != nil {
+		sp.UpdateMessage("Starting local Supabase (this can take a few minutes)")
+		if err := client.StartLocal(); err != nil {
+			sp.Fail("Failed to start local Supabase")
+			return err
+		}
+		if stack, err = client.LocalStatus(); err != nil {
+			sp.Fail("Local Supabase did not come up")
+			return err
+		}
+		sp.Success("Local Supabase started")
+	} else {
+		sp.Success("Local Supabase already running")
+	}
+
+	// 2. Migrations
+	sp = ui.NewSpinner("Applying migrations")
+	sp.Start()
+	if err := client.MigrateLocal(); err != nil {
+		sp.Fail("Migrations failed")
+		return err
+	}
+	sp.Success("Migrations applied")
+
+	// 3. Data
+	switch {
+	case dataFile == "":
+		ui.Info("Skipping data restore")
+	case isSeed:
+		sp = ui.NewSpinner(fmt.Sprintf("Applying %s", dataLabel))
+		sp.Start()
+		if err := database.ApplySQLFile(stack.DBURL, dataFile); err != nil {
+			sp.Fail("Seed failed")
+			return err
+		}
+		sp.Success(fmt.Sprintf("Applied %s", dataLabel))
+	default:
+		if err := restoreBackupToLocal(stack, dataFile); err != nil {
+			return err
+		}
+	}
+
+	// 4. Env config
+	if dbCloneNoEnvFlag {
+		ui.Info("Skipping env config")
+	} else {
+		outputPath, err := generateLocalEnvConfig(cfg, stack)
+		if err != nil {
+			return err
+		}
+		ui.Successf("Generated %s for the local stack", backupDisplayPath(outputPath, cfg.ProjectRoot()))
+	}
+
+	ui.NewLine()
+	ui.Successf("Local environment ready in %s", time.Since(start).Round(time.Second))
+	ui.KeyValue("API URL", ui.Cyan(stack.APIURL))
+	ui.KeyValue("Database", ui.Cyan(stack.DBURL))
+	if stack.StudioURL != "" {
+		ui.KeyValue("Studio", ui.Cyan(stack.StudioURL))
+	}
+
+	ui.NewLine()
+	ui.SubHeader("Next Steps")
+	ui.List("supabase functions serve - Run Edge Functions locally")
+	ui.List("drift env setup          - Point env config back at the branch database")
+	ui.List("supabase stop            - Stop the local stack")
+
+	return nil
+}
+
+// selectCloneDataSource returns the file to load into the local database,
+// a display label, and whether it is a seed (plain SQL applied with psql)
+// rather than a backup.
+func selectCloneDataSource(cfg *config.Config) (string, string, bool, error) {
+	if dbCloneSeedFlag != "" {
+		path := defaultSeedPath(cfg)
+		label := "seed.sql"
+		if dbCloneSeedFlag != "default" {
+			profilePath, ok := cfg.Database.SeedProfiles[dbCloneSeedFlag]
+			if !ok {
+				return "", "", false, fmt.Errorf("unknown seed profile '%s' (configured: %s)", dbCloneSeedFlag, stringsJoinSorted(seedProfileNames(cfg.Database.SeedProfiles)))
+			}
+			path, label = profilePath, "profile "+dbCloneSeedFlag
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.ProjectRoot(), path)
+		}
+		return path, label, true, nil
+	}
+
+	backups, err := discoverLocalBackups(cfg)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	if dbCloneInputFlag != "" {
+		path, err := resolveBackupInputPath(dbCloneInputFlag, backups)
+		if err != nil {
+			return "", "", false, err
+		}
+		return path, filepath.Base(path), false, nil
+	}
+
+	devBackups := filterLocalBackups(backups, "dev")
+	if len(devBackups) == 0 {
+		return "", "", false, fmt.Errorf("no dev backups found in %s or %s\nRun 'drift db dump dev' first, or use --seed or --no-data", cfg.GetBackupPath(), cfg.ProjectRoot())
+	}
+	freshest := devBackups[0]
+	ui.KeyValue("Backup", fmt.Sprintf("%s %s", backupDisplayPath(freshest.Path, cfg.ProjectRoot()), ui.Dim("("+formatBackupAge(freshest.ModTime)+")")))
+	return freshest.Path, freshest.Name, false, nil
+}
+
+// restoreBackupToLocal restores a backup's data into the local database,
+// keeping the schema created by the migrations.
+func restoreBackupToLocal(stack *supabase.LocalStack, backupFile string) error {
+	host, port := supabase.ExtractHostPortFromURL(stack.DBURL)
+	opts := database.DefaultRestoreOptions()
+	opts.Host = host
+	opts.Port = port
+	opts.User = supabase.ExtractUserFromURL(stack.DBURL)
+	opts.Password = supabase.ExtractPasswordFromURL(stack.DBURL)
+	opts.InputFile = backupFile
+	opts.SingleTxn = true
+
+	if authTables, err := database.ResolveAllowedAuthCopyTables(opts); err == nil {
+		opts.AuthCopyTables = authTables
+	}
+
+	sp := ui.NewSpinner(fmt.Sprintf("Restoring %s", filepath.Base(backupFile)))
+	sp.Start()
+	if err := database.Restore(opts); err != nil {
+		sp.Fail("Restore failed")
+		return err
+	}
+	sp.Success("Data restored")
+	return nil
+}
+
+// generateLocalEnvConfig writes the project's env config for the local
+// stack and returns its path.
+func generateLocalEnvConfig(cfg *config.Config, stack *supabase.LocalStack) (string, error) {
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		gitBranch = "local"
+	}
+	info := &supabase.BranchInfo{
+		GitBranch:      gitBranch,
+		Environment:    supabase.EnvFeature,
+		APIURL:         strings.TrimRight(stack.APIURL, "/"),
+		ProjectRef:     "local",
+		SupabaseBranch: &supabase.Branch{Name: "local"},
+	}
+
+	if !cfg.Project.IsWebPlatform() {
+		outputPath := cfg.GetXcconfigPath()
+		generator := xcode.NewXcconfigGenerator(outputPath)
+		generator.VarPrefix = cfg.Supabase.EnvPrefix
+		applyXcconfigBanner(cfg, generator, info.Environment)
+		if err := generator.GenerateFromBranchInfo(info, stack.AnonKey); err != nil {
+			return "", fmt.Errorf("failed to generate %s: %w", cfg.Xcode.XcconfigOutput, err)
+		}
+		return outputPath, nil
+	}
+
+	outputPath := cfg.GetEnvLocalPath()
+	generator := web.NewEnvLocalGenerator(outputPath)
+	generator.VarPrefix = cfg.Supabase.EnvPrefix
+	generator.Framework = cfg.Web.Framework
+	if generator.Seal, err = envSecretSealer(cfg, info.ProjectRef); err != nil {
+		return "", err
+	}
+	generator.ServiceRolePolicy = cfg.Web.ServiceRoleKey
+	generator.ServerEnvPath = cfg.GetServerEnvPath()
+	applyEnvLocalBanner(cfg, generator, info.Environment)
+	applyEnvLocalPorts(cfg, generator, true)
+
+	secrets := &web.BranchSecretsInput{
+		AnonKey:           stack.AnonKey,
+		ServiceRoleKey:    stack.ServiceRoleKey,
+		DatabasePassword:  supabase.ExtractPasswordFromURL(stack.DBURL),
+		DirectDatabaseURL: stack.DBURL,
+		PoolerDatabaseURL: stack.DBURL, // the local stack has no pooler
+	}
+	if err := generator.GenerateFromBranchInfo(info, secrets); err != nil {
+		return "", fmt.Errorf("failed to generate %s: %w", cfg.Web.EnvOutput, err)
+	}
+	return outputPath, nil
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbCompareDataCmd = &cobra.Command{
+	Use:   "compare-data [branch]",
+	Short: "Compare per-table row counts between two branches",
+	Long: `Compare how many rows each user table holds on a branch and on another
+environment (prod, dev, or a Supabase branch name), to see how far a branch's
+data has drifted. Tables whose count differs by at least --threshold percent
+are highlighted.
+
+Counts are exact by default, which scans every table; --estimate uses the
+planner's statistics instead and returns immediately. --checksum also
+compares an md5 of each table's primary keys in key order, so tables with the
+same count but different rows show up too. Tables without a primary key are
+not checksummed.
+
+The branch defaults to the Supabase branch for the current git branch.`,
+	Example: `  drift db compare-data dev                  # Is dev roughly current with prod?
+  drift db compare-data feature-x --compare dev
+  drift db compare-data --checksum --threshold 5
+  drift db compare-data dev --estimate --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runDbCompareData, RequireProject),
+}
+
+var (
+	dbCompareDataCompareFlag   string
+	dbCompareDataThresholdFlag float64
+	dbCompareDataChecksumFlag  bool
+	dbCompareDataEstimateFlag  bool
+	dbCompareDataJSON          bool
+)
+
+func init() {
+	dbCompareDataCmd.Flags().StringVar(&dbCompareDataCompareFlag, "compare", "prod", "Environment or branch to compare against (prod|dev|<branch>)")
+	dbCompareDataCmd.Flags().Float64Var(&dbCompareDataThresholdFlag, "threshold", 10, "Highlight tables whose count differs by at least this percent")
+	dbCompareDataCmd.Flags().BoolVar(&dbCompareDataChecksumFlag, "checksum", false, "Also compare a checksum of each table's primary keys")
+	dbCompareDataCmd.Flags().BoolVar(&dbCompareDataEstimateFlag, "estimate", false, "Use planner row estimates instead of exact counts")
+	dbCompareDataCmd.Flags().BoolVar(&dbCompareDataJSON, "json", false, "Output as JSON")
+
+	dbCmd.AddCommand(dbCompareDataCmd)
+}
+
+// largeRowDelta reports whether d should be highlighted: the table is
+// missing on one side, its checksums differ, or its count moved by at least
+// threshold percent.
+func largeRowDelta(d database.RowCountDiff, threshold float64) bool {
+	if d.Missing != "" || d.ChecksumsDiffer() {
+		return true
+	}
+	return d.Delta != 0 && math.Abs(d.Percent) >= threshold
+}
+
+// formatRowCount prints a count, or "?" for a never-analyzed estimate.
+func formatRowCount(n int64) string {
+	if n < 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func runDbCompareData(ctx *Context) error {
+	client := ctx.Client()
+
+	info, err := ctx.Target(ctx.Arg(0))
+	if err != nil {
+		return err
+	}
+	other, err := resolveCompareBranch(client, dbCompareDataCompareFlag)
+	if err != nil {
+		return err
+	}
+	if other.ProjectRef == info.ProjectRef {
+		return fmt.Errorf("%s and %s are the same database", info.SupabaseBranch.Name, other.Name)
+	}
+
+	tables, err := fetchBranchRowCounts(info.SupabaseBranch.Name, info.ProjectRef)
+	if err != nil {
+		return err
+	}
+	otherTables, err := fetchBranchRowCounts(other.Name, other.ProjectRef)
+	if err != nil {
+		return err
+	}
+	diffs := database.CompareRowCounts(tables, otherTables)
+
+	if ctx.JSON() {
+		return ctx.PrintJSON(diffs)
+	}
+
+	ui.Header("Data Comparison")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Compared With", ui.Cyan(other.Name))
+	if dbCompareDataEstimateFlag {
+		ui.KeyValue("Counts", ui.Yellow("estimated"))
+	}
+	ui.NewLine()
+
+	if len(diffs) == 0 {
+		ui.Info("No user tables on either side")
+		return nil
+	}
+
+	headers := []string{"Table", info.SupabaseBranch.Name, other.Name, "Delta", "%"}
+	if dbCompareDataChecksumFlag {
+		headers = append(headers, "Keys")
+	}
+	table := ui.NewTable(headers)
+	flagged := 0
+	for _, d := range diffs {
+		row := []string{d.Table, formatRowCount(d.Rows), formatRowCount(d.OtherRows), fmt.Sprintf("%+d", d.Delta), fmt.Sprintf("%+.1f", d.Percent)}
+		switch d.Missing {
+		case "here":
+			row[1], row[3], row[4] = "missing", "-", "-"
+		case "other":
+			row[2], row[3], row[4] = "missing", "-", "-"
+		}
+		if dbCompareDataChecksumFlag {
+			switch {
+			case d.Missing != "":
+				row = append(row, "-")
+			case d.Checksum == "" || d.OtherChecksum == "":
+				row = append(row, "no pk")
+			case d.ChecksumsDiffer():
+				row = append(row, "differ")
+			default:
+				row = append(row, "match")
+			}
+		}
+
+		color := ui.TableColor.Normal
+		if largeRowDelta(d, dbCompareDataThresholdFlag) {
+			color = ui.TableColor.Yellow
+			if d.Missing != "" {
+				color = ui.TableColor.Red
+			}
+			flagged++
+		}
+		colors := make([]tablewriter.Colors, len(row))
+		for i := range colors {
+			colors[i] = color
+		}
+		table.AddColoredRow(row, colors)
+	}
+	table.Render()
+
+	ui.NewLine()
+	if flagged == 0 {
+		ui.Successf("All %d table(s) are within %.0f%% of %s", len(diffs), dbCompareDataThresholdFlag, other.Name)
+	} else {
+		ui.Warningf("%d of %d table(s) differ by %.0f%% or more, are missing, or hold different rows", flagged, len(diffs), dbCompareDataThresholdFlag)
+	}
+	return nil
+}
+
+// fetchBranchRowCounts counts the rows of every user table on a branch.
+func fetchBranchRowCounts(name, projectRef string) ([]database.TableRows, error) {
+	dbURL, err := getDbURLForProject(projectRef)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := restoreOptionsFromDBURL(dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	sp := ui.NewSpinner(fmt.Sprintf("Counting rows on %s", name))
+	sp.Start()
+	tables, err := database.FetchTableRows(opts)
+	if err == nil && !dbCompareDataEstimateFlag {
+		err = database.CountRows(opts, tables)
+	}
+	if err == nil && dbCompareDataChecksumFlag {
+		sp.UpdateMessage(fmt.Sprintf("Checksumming primary keys on %s", name))
+		err = database.ChecksumKeys(opts, tables)
+	}
+	if err != nil {
+		sp.Fail(fmt.Sprintf("Failed to count rows on %s", name))
+		return nil, err
+	}
+	sp.Stop()
+	return tables, nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/undrift/drift/internal/database"
+)
+
+func TestLargeRowDelta(t *testing.T) {
+	tests := []struct {
+		name string
+		diff database.RowCountDiff
+		want bool
+	}{
+		{"equal", database.RowCountDiff{Rows: 100, OtherRows: 100}, false},
+		{"below threshold", database.RowCountDiff{Rows: 95, OtherRows: 100, Delta: -5, Percent: -5}, false},
+		{"at threshold", database.RowCountDiff{Rows: 90, OtherRows: 100, Delta: -10, Percent: -10}, true},
+		{"growth", database.RowCountDiff{Rows: 150, OtherRows: 100, Delta: 50, Percent: 50}, true},
+		{"missing", database.RowCountDiff{OtherRows: 3, Missing: "here"}, true},
+		{"checksums differ", database.RowCountDiff{Rows: 10, OtherRows: 10, Checksum: "a", OtherChecksum: "b"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := largeRowDelta(tt.diff, 10); got != tt.want {
+				t.Errorf("largeRowDelta() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbIncludeExcludedFlag bool
+
+func init() {
+	dbDumpCmd.Flags().BoolVar(&dbIncludeExcludedFlag, "include-excluded", false, "Include data from database.push_exclude_tables (requires typed confirmation)")
+	dbPushCmd.Flags().BoolVar(&dbIncludeExcludedFlag, "include-excluded", false, "Include data from database.push_exclude_tables (requires typed confirmation)")
+}
+
+// dbExcludedTables returns the tables whose data must not be dumped or
+// pushed. With --include-excluded the list is waived after the user types
+// "yes"; --yes is not enough, so scripts can never copy them by accident.
+// ok is false when the user cancelled.
+func dbExcludedTables(cfg *config.Config) (tables []string, ok bool, err error) {
+	tables = cfg.Database.GetPushExcludeTables()
+	if len(tables) == 0 {
+		return nil, true, nil
+	}
+	if !dbIncludeExcludedFlag {
+		ui.KeyValue("Excluded Tables", strings.Join(tables, ", "))
+		return tables, true, nil
+	}
+
+	if IsYes() {
+		return nil, false, fmt.Errorf("--include-excluded needs typed confirmation and cannot be used with --yes")
+	}
+	ui.KeyValue("Excluded Tables", ui.Yellow("included: "+strings.Join(tables, ", ")))
+	confirmed, err := RequireDestructiveConfirmation("copy data from tables excluded by database.push_exclude_tables")
+	if err != nil {
+		return nil, false, err
+	}
+	return nil, confirmed, nil
+}
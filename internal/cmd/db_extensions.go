@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbExtensionsCmd = &cobra.Command{
+	Use:   "extensions [branch]",
+	Short: "List Postgres extensions and compare them across branches",
+	Long: `List installed Postgres extensions and versions on a branch.
+
+With --compare, the list is diffed against another environment (prod, dev, or
+a Supabase branch name). Extensions that local migrations create or use
+(e.g. net.http_post needs pg_net) but that are missing on the branch are
+flagged, since those migrations will fail there.
+
+The branch defaults to the Supabase branch for the current git branch.`,
+	Example: `  drift db extensions
+  drift db extensions --compare prod
+  drift db extensions feature-x --compare dev`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runDbExtensions, RequireProject),
+}
+
+var dbExtensionsCompareFlag string
+
+func init() {
+	dbExtensionsCmd.Flags().StringVar(&dbExtensionsCompareFlag, "compare", "", "Environment or branch to compare against (prod|dev|<branch>)")
+
+	dbCmd.AddCommand(dbExtensionsCmd)
+}
+
+func runDbExtensions(ctx *Context) error {
+	cfg, client := ctx.Config(), ctx.Client()
+
+	ui.Header("Database Extensions")
+
+	info, err := ctx.Target(ctx.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.NewLine()
+
+	exts, err := fetchBranchExtensions(info.ProjectRef)
+	if err != nil {
+		return err
+	}
+
+	ui.SubHeader(fmt.Sprintf("Installed (%d)", len(exts)))
+	fmt.Printf("  %-24s  %-10s  %s\n", "EXTENSION", "VERSION", "SCHEMA")
+	for _, e := range exts {
+		fmt.Printf("  %-24s  %-10s  %s\n", e.Name, e.Version, e.Schema)
+	}
+
+	missing := 0
+	if used, err := database.ExtensionsUsedByMigrations(cfg.GetMigrationsPath()); err != nil {
+		ui.Warning(fmt.Sprintf("Could not scan migrations: %v", err))
+	} else {
+		missing = reportMissingMigrationExtensions(exts, used)
+	}
+
+	if dbExtensionsCompareFlag != "" {
+		other, err := resolveCompareBranch(client, dbExtensionsCompareFlag)
+		if err != nil {
+			return err
+		}
+		otherExts, err := fetchBranchExtensions(other.ProjectRef)
+		if err != nil {
+			return err
+		}
+
+		ui.NewLine()
+		ui.SubHeader(fmt.Sprintf("Compared with %s", other.Name))
+		diffs := database.DiffExtensions(exts, otherExts)
+		if len(diffs) == 0 {
+			ui.Success("Extensions match")
+		}
+		for _, d := range diffs {
+			switch {
+			case d.Version == "":
+				ui.List(ui.Red(fmt.Sprintf("%s missing here (%s on %s)", d.Name, d.OtherVersion, other.Name)))
+			case d.OtherVersion == "":
+				ui.List(ui.Yellow(fmt.Sprintf("%s only here (%s)", d.Name, d.Version)))
+			default:
+				ui.List(ui.Yellow(fmt.Sprintf("%s %s here, %s on %s", d.Name, d.Version, d.OtherVersion, other.Name)))
+			}
+		}
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%d extension(s) used by migrations are not installed on %s", missing, info.SupabaseBranch.Name)
+	}
+	return nil
+}
+
+// reportMissingMigrationExtensions prints extensions referenced by migrations
+// that are not installed and returns how many there were.
+func reportMissingMigrationExtensions(exts []database.Extension, used map[string]string) int {
+	installed := make(map[string]bool, len(exts))
+	for _, e := range exts {
+		installed[e.Name] = true
+	}
+
+	var missing []string
+	for name := range used {
+		if !installed[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return 0
+	}
+	sort.Strings(missing)
+
+	ui.NewLine()
+	ui.SubHeader("Needed by Migrations")
+	for _, name := range missing {
+		ui.List(ui.Red(fmt.Sprintf("%s not installed (referenced in %s)", name, used[name])))
+	}
+	ui.Info("Enable them in the dashboard or add 'create extension if not exists' to an early migration")
+	return len(missing)
+}
+
+// resolveCompareBranch maps prod/dev shorthands or a branch name to a Supabase branch.
+func resolveCompareBranch(client *supabase.Client, target string) (*supabase.Branch, error) {
+	switch target {
+	case "prod", "production":
+		return client.GetProductionBranch()
+	case "dev", "development":
+		return client.GetDevelopmentBranch()
+	}
+	branch, err := client.GetBranch(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Supabase branch '%s': %w", target, err)
+	}
+	return branch, nil
+}
+
+func fetchBranchExtensions(projectRef string) ([]database.Extension, error) {
+	dbURL, err := getDbURLForProject(projectRef)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := restoreOptionsFromDBURL(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	exts, err := database.FetchExtensions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list extensions: %w", err)
+	}
+	return exts, nil
+}
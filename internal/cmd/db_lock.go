@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbLockCmd = &cobra.Command{
+	Use:   "lock [branch]",
+	Short: "Lock a branch database against concurrent pushes",
+	Long: `Take the push lock on a branch database so teammates' 'drift db push' and
+'drift migrate push' stop with "locked by <you> since <time>" until you run
+'drift db unlock'.
+
+Both push commands take the same lock automatically for their duration.
+The lock is a row in drift.locks on the target database and expires after
+2 hours, so a crashed push never blocks a branch for good.
+
+Without --hold, shows who holds the lock.`,
+	Example: `  drift db lock
+  drift db lock --hold
+  drift db lock development --hold`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runDbLock, RequireProject),
+}
+
+var dbUnlockCmd = &cobra.Command{
+	Use:   "unlock [branch]",
+	Short: "Release the push lock on a branch database",
+	Long: `Release the push lock taken by 'drift db lock --hold' or left behind by an
+interrupted push. Releasing a lock held by someone else requires --force.`,
+	Example: `  drift db unlock
+  drift db unlock development --force`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runDbUnlock, RequireProject),
+}
+
+var (
+	dbLockHoldFlag    bool
+	dbUnlockForceFlag bool
+)
+
+func init() {
+	dbLockCmd.Flags().BoolVar(&dbLockHoldFlag, "hold", false, "Take the lock until 'drift db unlock'")
+	dbUnlockCmd.Flags().BoolVar(&dbUnlockForceFlag, "force", false, "Release a lock held by someone else")
+
+	dbCmd.AddCommand(dbLockCmd)
+	dbCmd.AddCommand(dbUnlockCmd)
+}
+
+func runDbLock(ctx *Context) error {
+	cfg := ctx.Config()
+
+	ui.Header("Database Lock")
+
+	info, opts, err := resolveTargetDB(ctx.Client(), cfg, optionalArg(ctx.Args))
+	if err != nil {
+		return err
+	}
+	ui.NewLine()
+
+	if !dbLockHoldFlag {
+		lock, err := database.GetLock(opts, database.PushLockName)
+		if err != nil {
+			return err
+		}
+		if lock == nil {
+			ui.Successf("%s is not locked", info.SupabaseBranch.Name)
+			return nil
+		}
+		if lock.Stale(time.Now()) {
+			ui.Infof("%s is %s (expired; the next push will take it over)", info.SupabaseBranch.Name, lock)
+			return nil
+		}
+		ui.Warningf("%s is %s", info.SupabaseBranch.Name, lock)
+		return nil
+	}
+
+	owner := pushLockOwner("db lock")
+	held, err := database.AcquireLock(opts, owner)
+	if err != nil {
+		return err
+	}
+	if held != nil && !held.SameOwner(owner) {
+		return fmt.Errorf("%s is %s", info.SupabaseBranch.Name, held)
+	}
+	ui.Successf("Locked %s - run 'drift db unlock' when done", info.SupabaseBranch.Name)
+	return nil
+}
+
+func runDbUnlock(ctx *Context) error {
+	cfg := ctx.Config()
+
+	ui.Header("Database Unlock")
+
+	info, opts, err := resolveTargetDB(ctx.Client(), cfg, optionalArg(ctx.Args))
+	if err != nil {
+		return err
+	}
+	ui.NewLine()
+
+	owner := pushLockOwner("")
+	lock, err := database.GetLock(opts, database.PushLockName)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		ui.Successf("%s is not locked", info.SupabaseBranch.Name)
+		return nil
+	}
+	if !lock.SameOwner(owner) && !dbUnlockForceFlag {
+		return fmt.Errorf("%s is %s; use --force to release it anyway", info.SupabaseBranch.Name, lock)
+	}
+
+	released, err := database.ReleaseLock(opts, owner, dbUnlockForceFlag)
+	if err != nil {
+		return err
+	}
+	if !released {
+		ui.Infof("%s was already unlocked", info.SupabaseBranch.Name)
+		return nil
+	}
+	ui.Successf("Unlocked %s", info.SupabaseBranch.Name)
+	return nil
+}
+
+// acquirePushLock takes the push lock on a branch database for operation
+// and returns a function that releases it. A lock already held by the same
+// owner (e.g. via 'drift db lock --hold') is left in place on release.
+func acquirePushLock(opts database.RestoreOptions, branchName, operation string) (func(), error) {
+	owner := pushLockOwner(operation)
+	held, err := database.AcquireLock(opts, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", branchName, err)
+	}
+	if held != nil {
+		if !held.SameOwner(owner) {
+			return nil, fmt.Errorf("%s is %s\nWait for it to finish, or run 'drift db unlock %s --force' if it is stuck", branchName, held, branchName)
+		}
+		return func() {}, nil
+	}
+	return func() {
+		if _, err := database.ReleaseLock(opts, owner, false); err != nil {
+			ui.Warning(fmt.Sprintf("Could not release lock on %s: %v", branchName, err))
+		}
+	}, nil
+}
+
+// pushLockOwner identifies this user and machine as a lock holder.
+func pushLockOwner(operation string) database.Lock {
+	host, _ := os.Hostname()
+	return database.Lock{Name: database.PushLockName, Holder: currentActor(), Host: host, Operation: operation}
+}
+
+func optionalArg(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return ""
+}
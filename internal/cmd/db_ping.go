@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbPingCmd = &cobra.Command{
+	Use:   "ping",
+	Short: "Check which pooler host and port accept connections",
+	Long: `Connect to a branch's database through the connection pooler in both
+session mode (port 5432) and transaction mode (port 6543), and report which
+host/port combinations work.
+
+Every known pooler host is tried: the one Supabase reports for the branch,
+the one drift last discovered (cached for an hour), and the one configured in
+database.pooler_host / database.branch_pooler_hosts. A configured host that
+no longer works is the usual cause of slow, failing 'drift db push' runs.
+
+The branch defaults to the Supabase branch for the current git branch.`,
+	Example: `  drift db ping
+  drift db ping --branch dev
+  drift db ping -b main`,
+	Args: cobra.NoArgs,
+	RunE: Run(runDbPing, RequireProject),
+}
+
+var (
+	dbPingBranchFlag string
+	dbPingTimeout    time.Duration
+)
+
+func init() {
+	dbPingCmd.Flags().StringVarP(&dbPingBranchFlag, "branch", "b", "", "Target Supabase branch")
+	dbPingCmd.Flags().DurationVar(&dbPingTimeout, "timeout", 5*time.Second, "Connection timeout per attempt")
+
+	dbCmd.AddCommand(dbPingCmd)
+}
+
+// poolerCandidate is one host to try, with where drift learned of it.
+type poolerCandidate struct {
+	Host    string
+	Sources []string
+}
+
+// dbPingResult is the outcome of one host/port attempt.
+type dbPingResult struct {
+	Host    string
+	Mode    string
+	Port    int
+	Latency time.Duration
+	Err     error
+}
+
+// poolerCandidates merges hosts from each source, keeping the order given.
+func poolerCandidates(sources [][2]string) []poolerCandidate {
+	var candidates []poolerCandidate
+	index := make(map[string]int)
+	for _, s := range sources {
+		source, host := s[0], s[1]
+		if host == "" {
+			continue
+		}
+		if i, ok := index[host]; ok {
+			candidates[i].Sources = append(candidates[i].Sources, source)
+			continue
+		}
+		index[host] = len(candidates)
+		candidates = append(candidates, poolerCandidate{Host: host, Sources: []string{source}})
+	}
+	return candidates
+}
+
+func runDbPing(ctx *Context) error {
+	cfg, client := ctx.Config(), ctx.Client()
+
+	info, err := ctx.Target(dbPingBranchFlag)
+	if err != nil {
+		return err
+	}
+	sp := ui.NewSpinner("Discovering pooler host")
+	sp.Start()
+	branchName := info.SupabaseBranch.GitBranch
+	cached, hasCached := client.CachedPoolerDiscovery(branchName)
+	connInfo, connErr := client.GetBranchConnectionInfo(branchName)
+	sp.Stop()
+
+	ui.Header("Database Ping")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+
+	discovered := ""
+	switch {
+	case connErr != nil:
+		ui.Warning(fmt.Sprintf("Could not get connection info via API: %v", connErr))
+	case !connInfo.DiscoveredAt.IsZero():
+		ui.Warning("Could not get connection info via API; only cached and configured hosts can be tried")
+	default:
+		discovered = connInfo.PoolerHost
+	}
+	cachedHost := ""
+	if hasCached {
+		cachedHost = cached.Host
+	}
+	configured := cfg.Database.GetPoolerHostForBranch(branchName)
+
+	candidates := poolerCandidates([][2]string{
+		{"api", discovered},
+		{"cached", cachedHost},
+		{"config", configured},
+	})
+
+	password := ""
+	if info.Environment != supabase.EnvProduction && connErr == nil && connInfo.PostgresURL != "" {
+		password = supabase.ExtractPasswordFromURL(connInfo.PostgresURL)
+	}
+	if password == "" {
+		env := "dev"
+		if info.Environment == supabase.EnvProduction {
+			env = "prod"
+		}
+		password = getDbPassword(env)
+	}
+	if password == "" {
+		if password, err = ui.PromptPassword("Database password"); err != nil {
+			return err
+		}
+	}
+
+	ui.NewLine()
+	modes := []struct {
+		name string
+		port int
+	}{
+		{string(database.SessionMode), dbConnections(cfg).Port(database.SessionMode)},
+		{string(database.TransactionMode), dbConnections(cfg).Port(database.TransactionMode)},
+	}
+
+	var results []dbPingResult
+	fmt.Printf("  %-44s %-18s %-12s %s\n", "HOST", "SOURCE", "MODE", "RESULT")
+	for _, c := range candidates {
+		for _, m := range modes {
+			opts := database.DefaultRestoreOptions()
+			opts.Host = c.Host
+			opts.Port = m.port
+			opts.User = fmt.Sprintf("postgres.%s", info.ProjectRef)
+			opts.Password = password
+
+			latency, err := database.Ping(opts, dbPingTimeout)
+			results = append(results, dbPingResult{Host: c.Host, Mode: m.name, Port: m.port, Latency: latency, Err: err})
+
+			status := ui.Green(fmt.Sprintf("ok %s", latency.Round(time.Millisecond)))
+			if err != nil {
+				status = ui.Red("failed: " + truncateValue(firstLine(err.Error()), 60))
+			}
+			fmt.Printf("  %-44s %-18s %-12s %s\n",
+				fmt.Sprintf("%s:%d", truncateValue(c.Host, 38), m.port),
+				strings.Join(c.Sources, ","), m.name, status)
+		}
+	}
+
+	ui.NewLine()
+	working := firstWorkingPooler(results)
+	if working == nil {
+		return errs.Networkf("no pooler host accepted connections for %s", info.SupabaseBranch.Name)
+	}
+	client.RememberPooler(branchName, supabase.PoolerDiscovery{Host: working.Host, Port: working.Port, ProjectRef: info.ProjectRef})
+
+	ui.Success(fmt.Sprintf("Use %s:%d (%s mode)", working.Host, working.Port, working.Mode))
+	if configured != working.Host && !poolerHostWorks(results, configured) {
+		ui.Warning(fmt.Sprintf("Configured pooler host %s does not work for this branch", configured))
+		ui.Infof("Update database.pooler_host (or database.branch_pooler_hosts) to %s", working.Host)
+	}
+	return nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// firstWorkingPooler returns the first successful attempt, in candidate
+// order, so the API-reported host wins when several work.
+func firstWorkingPooler(results []dbPingResult) *dbPingResult {
+	for i := range results {
+		if results[i].Err == nil {
+			return &results[i]
+		}
+	}
+	return nil
+}
+
+// poolerHostWorks reports whether any attempt on host succeeded.
+func poolerHostWorks(results []dbPingResult, host string) bool {
+	for _, r := range results {
+		if r.Host == host && r.Err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPoolerCandidates(t *testing.T) {
+	got := poolerCandidates([][2]string{
+		{"api", "aws-1-us-east-2.pooler.supabase.com"},
+		{"cached", ""},
+		{"config", "aws-0-us-east-1.pooler.supabase.com"},
+		{"branch", "aws-1-us-east-2.pooler.supabase.com"},
+	})
+	want := []poolerCandidate{
+		{Host: "aws-1-us-east-2.pooler.supabase.com", Sources: []string{"api", "branch"}},
+		{Host: "aws-0-us-east-1.pooler.supabase.com", Sources: []string{"config"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("poolerCandidates() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFirstWorkingPooler(t *testing.T) {
+	failed := errors.New("timeout")
+	results := []dbPingResult{
+		{Host: "stale", Mode: "session", Port: 5432, Err: failed},
+		{Host: "stale", Mode: "transaction", Port: 6543, Err: failed},
+		{Host: "current", Mode: "session", Port: 5432, Err: failed},
+		{Host: "current", Mode: "transaction", Port: 6543},
+	}
+
+	working := firstWorkingPooler(results)
+	if working == nil || working.Host != "current" || working.Port != 6543 {
+		t.Fatalf("firstWorkingPooler() = %+v", working)
+	}
+	if poolerHostWorks(results, "stale") || !poolerHostWorks(results, "current") {
+		t.Error("poolerHostWorks() should only accept hosts with a successful attempt")
+	}
+	if firstWorkingPooler(results[:3]) != nil {
+		t.Error("firstWorkingPooler() should be nil when every attempt failed")
+	}
+}
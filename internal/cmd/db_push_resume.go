@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbPushResumeFlag bool
+
+// dbPushSingleTxnMaxBytes is the largest backup restored in a single
+// transaction. Rolling back a small restore costs little; larger ones are
+// restored one table per transaction so a failure can be resumed.
+const dbPushSingleTxnMaxBytes = 256 << 20
+
+func init() {
+	dbPushCmd.Flags().BoolVar(&dbPushResumeFlag, "resume", false, "Continue an interrupted push from the table where it stopped")
+}
+
+// dbPushResume is the resume point of a resumable push, kept in
+// .drift/restores/<branch>.json until the push completes.
+type dbPushResume struct {
+	Backup        string    `json:"backup"`
+	BackupSize    int64     `json:"backup_size"`
+	BackupModTime time.Time `json:"backup_mod_time"`
+	ProjectRef    string    `json:"project_ref"`
+	CopyScope     string    `json:"copy_scope"`
+	Applied       int       `json:"applied"`
+	Total         int       `json:"total"`
+	FailedChunk   string    `json:"failed_chunk,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// dbPushResumable reports whether the backup at path is restored table by
+// table. Only plain SQL backups can be; custom-format ones always restore
+// in one pg_restore transaction.
+func dbPushResumable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	plain, err := database.IsPlainSQLBackup(path)
+	return err == nil && plain && info.Size() > dbPushSingleTxnMaxBytes
+}
+
+func dbPushResumePath(cfg *config.Config, branch *supabase.Branch) string {
+	return state.Path(cfg.ProjectRoot(), state.Restores, state.BranchKey(branch.Name)+".json")
+}
+
+// newDbPushResume starts a resume point for pushing backup to branch.
+func newDbPushResume(backup string, branch *supabase.Branch, copyScope string) *dbPushResume {
+	point := &dbPushResume{Backup: backup, ProjectRef: branch.ProjectRef, CopyScope: copyScope}
+	if info, err := os.Stat(backup); err == nil {
+		point.BackupSize = info.Size()
+		point.BackupModTime = info.ModTime()
+	}
+	return point
+}
+
+// saveDbPushResume records how far a push got. Failing to record it only
+// warns: the push itself is unaffected.
+func saveDbPushResume(cfg *config.Config, branch *supabase.Branch, point *dbPushResume) {
+	point.UpdatedAt = time.Now()
+	if err := state.WriteJSON(cfg.ProjectRoot(), dbPushResumePath(cfg, branch), point); err != nil {
+		ui.Warning(fmt.Sprintf("Could not record resume point: %v", err))
+	}
+}
+
+// clearDbPushResume removes the resume point after a completed push.
+func clearDbPushResume(cfg *config.Config, branch *supabase.Branch) {
+	if err := os.Remove(dbPushResumePath(cfg, branch)); err != nil && !os.IsNotExist(err) {
+		ui.Warning(fmt.Sprintf("Could not remove resume point: %v", err))
+	}
+}
+
+// recordDbPushFailure saves where a resumable push stopped and tells the
+// user how to continue.
+func recordDbPushFailure(cfg *config.Config, target *dbPushTarget, backup, copyScope string, failure *database.RestoreError) {
+	point := newDbPushResume(backup, target.Branch, copyScope)
+	point.Applied = failure.Applied
+	point.Total = failure.Total
+	point.FailedChunk = failure.Chunk
+	point.Error = failure.Err.Error()
+	saveDbPushResume(cfg, target.Branch, point)
+
+	ui.NewLine()
+	if failure.Connection {
+		ui.Warningf("The connection dropped while restoring %s (%d of %d chunks applied)", failure.Chunk, failure.Applied, failure.Total)
+	} else {
+		ui.Warningf("Restoring %s failed (%d of %d chunks applied)", failure.Chunk, failure.Applied, failure.Total)
+	}
+	ui.Infof("Continue from %s with: drift db push %s --resume", failure.Chunk, target.Branch.Name)
+}
+
+// loadDbPushResume returns the resume point for branch, checking that the
+// backup it restores is unchanged.
+func loadDbPushResume(cfg *config.Config, branch *supabase.Branch) (*dbPushResume, error) {
+	var point dbPushResume
+	ok, err := state.ReadJSON(dbPushResumePath(cfg, branch), &point)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resume point: %w", err)
+	}
+	if !ok {
+		return nil, errs.Validationf("no interrupted push to resume for %s", branch.Name)
+	}
+	if point.ProjectRef != branch.ProjectRef {
+		return nil, errs.Validationf("the interrupted push targeted project %s, but %s is now %s; start a new push without --resume", point.ProjectRef, branch.Name, branch.ProjectRef)
+	}
+	info, err := os.Stat(point.Backup)
+	if err != nil {
+		return nil, errs.Validationf("backup %s of the interrupted push is gone; start a new push without --resume", point.Backup)
+	}
+	if info.Size() != point.BackupSize || !info.ModTime().Equal(point.BackupModTime) {
+		return nil, errs.Validationf("backup %s changed since the push was interrupted; start a new push without --resume", point.Backup)
+	}
+	return &point, nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestDbPushResume(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".drift.yaml")
+	if err := os.WriteFile(configPath, []byte("project:\n  type: web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	backup := filepath.Join(dir, "dev.backup")
+	if err := os.WriteFile(backup, []byte("-- dump\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	branch := &supabase.Branch{Name: "feat-login", ProjectRef: "abc123"}
+	target := &dbPushTarget{Branch: branch, GitBranch: "feat/login"}
+
+	if _, err := loadDbPushResume(cfg, branch); err == nil {
+		t.Fatal("expected an error without a resume point")
+	}
+
+	recordDbPushFailure(cfg, target, backup, "safe", &database.RestoreError{
+		Applied: 3, Total: 9, Chunk: "public.orders", Connection: true, Err: os.ErrDeadlineExceeded,
+	})
+	point, err := loadDbPushResume(cfg, branch)
+	if err != nil {
+		t.Fatalf("loadDbPushResume() error = %v", err)
+	}
+	if point.Applied != 3 || point.Total != 9 || point.FailedChunk != "public.orders" || point.CopyScope != "safe" || point.Backup != backup {
+		t.Errorf("resume point = %+v", point)
+	}
+
+	if _, err := loadDbPushResume(cfg, &supabase.Branch{Name: "feat-login", ProjectRef: "recreated"}); err == nil {
+		t.Error("expected an error for a recreated branch")
+	}
+
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(backup, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadDbPushResume(cfg, branch); err == nil {
+		t.Error("expected an error for a changed backup")
+	}
+
+	clearDbPushResume(cfg, branch)
+	if _, err := os.Stat(dbPushResumePath(cfg, branch)); !os.IsNotExist(err) {
+		t.Errorf("resume point not removed: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbPushFromBranchFlag string
+
+func init() {
+	dbPushCmd.Flags().StringVar(&dbPushFromBranchFlag, "from-branch", "", "Stream data directly from another branch (prod, dev, or a branch name) instead of a backup file")
+}
+
+// runDbPushFromBranch copies data from another Supabase branch into target
+// by piping pg_dump into psql, without writing a backup file.
+func runDbPushFromBranch(client *supabase.Client, target *dbPushTarget) error {
+	if dbInputFlag != "" {
+		return fmt.Errorf("--input and --from-branch cannot be used together")
+	}
+	cfg := config.LoadOrDefault()
+
+	source, err := resolveCompareBranch(client, dbPushFromBranchFlag)
+	if err != nil {
+		return err
+	}
+	if source.ProjectRef == target.Branch.ProjectRef {
+		return fmt.Errorf("source and target are the same branch (%s)", source.Name)
+	}
+	sourceIsProd := supabase.IsProductionBranch(source)
+
+	ui.Header(fmt.Sprintf("Database Push - %s", target.Env))
+
+	sourceOpts, err := branchDumpOptions(client, cfg, source, sourceIsProd)
+	if err != nil {
+		return err
+	}
+
+	if sourceIsProd && !IsYes() {
+		ui.Warning("You are about to read the PRODUCTION database")
+		confirmed, err := ui.PromptYesNo("Continue?", true)
+		if err != nil || !confirmed {
+			ui.Info("Cancelled")
+			return errs.Cancelled("db push")
+		}
+	}
+
+	opts, poolerMode, err := dbPushRestoreOptions(client, cfg, target)
+	if err != nil {
+		return err
+	}
+	copyScope, err := selectDbPushCopyScope()
+	if err != nil {
+		return err
+	}
+
+	ui.KeyValue("Source", fmt.Sprintf("%s (%s)", ui.Cyan(source.Name), source.ProjectRef))
+	ui.KeyValue("Target", envColorString(target.Env))
+	ui.KeyValue("Project Ref", ui.Cyan(target.Branch.ProjectRef))
+	ui.KeyValue("Pooler Mode", poolerMode)
+	if copyScope == "all" {
+		ui.KeyValue("Copy Scope", "all insertable tables (best effort)")
+	} else {
+		ui.KeyValue("Copy Scope", "safe default (public + auth + migrations)")
+	}
+	ui.KeyValue("Pooler", fmt.Sprintf("%s:%d", opts.Host, opts.Port))
+	excludedTables, ok, err := dbExcludedTables(cfg)
+	if err != nil || !ok {
+		return err
+	}
+
+	if !confirmDbPush(target.Env, "data from "+source.Name) {
+		return errs.Cancelled("db push")
+	}
+
+	ui.NewLine()
+
+	opts.SingleTxn = true
+	opts.CopyAllInsertableTables = copyScope == "all"
+	opts.ExcludeTables = excludedTables
+
+	start := time.Now()
+	release, err := acquirePushLock(opts, target.Branch.Name, "db push")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	resolveDbPushAuthTables(&opts, copyScope)
+
+	sp := ui.NewSpinner(fmt.Sprintf("Streaming data from %s", source.Name))
+	sp.Start()
+
+	var streamed int64
+	err = database.StreamRestore(database.StreamOptions{
+		Source: sourceOpts,
+		Target: opts,
+		Progress: func(bytes int64, table string) {
+			streamed = bytes
+			msg := fmt.Sprintf("Streaming data from %s (%.1f MB)", source.Name, float64(bytes)/1024/1024)
+			if table != "" {
+				msg += " - " + table
+			}
+			sp.UpdateMessage(msg)
+		},
+	})
+	if err != nil {
+		sp.Fail("Restore failed")
+		notifyOperation(cfg, "db push", target.Env, target.Branch.Name, start, err)
+		return err
+	}
+
+	sp.Success(fmt.Sprintf("Copied %.1f MB from %s in %s", float64(streamed)/1024/1024, source.Name, time.Since(start).Round(time.Second)))
+	notifyOperation(cfg, "db push", target.Env, target.Branch.Name, start, nil)
+
+	printDbPushNextSteps()
+	return nil
+}
+
+// branchDumpOptions returns session-pooler pg_dump options for branch.
+// Production needs PROD_PASSWORD (or a prompt); other branches get their
+// password from the API.
+func branchDumpOptions(client *supabase.Client, cfg *config.Config, branch *supabase.Branch, isProd bool) (database.DumpOptions, error) {
+	opts := database.DefaultDumpOptions()
+
+	connInfo, err := client.GetBranchConnectionInfo(branch.GitBranch)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not get connection info via API: %v", err))
+	}
+
+	if !isProd && connInfo != nil && connInfo.PostgresURL != "" {
+		opts.Password = supabase.ExtractPasswordFromURL(connInfo.PostgresURL)
+	}
+	if pw := cfg.Supabase.DBPasswordFor(branch.ProjectRef); !isProd && pw != "" {
+		opts.Password = pw
+	}
+	// --password belongs to the target, so only the per-environment
+	// variables apply to the source.
+	if opts.Password == "" {
+		if isProd {
+			opts.Password = os.Getenv("PROD_PASSWORD")
+		} else {
+			opts.Password = os.Getenv("DEV_PASSWORD")
+		}
+	}
+	if opts.Password == "" {
+		opts.Password, err = ui.PromptPassword("Source database password")
+		if err != nil {
+			return opts, err
+		}
+	}
+
+	if connInfo != nil && connInfo.PoolerHost != "" {
+		opts.Host = connInfo.PoolerHost
+	} else {
+		opts.Host = cfg.Database.GetPoolerHostForBranch(branch.GitBranch)
+	}
+	opts.Port = dbConnections(cfg).Port(database.OpDump.Mode())
+	opts.User = fmt.Sprintf("postgres.%s", branch.ProjectRef)
+	return opts, nil
+}
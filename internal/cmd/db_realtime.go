@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbRealtimeCmd = &cobra.Command{
+	Use:   "realtime",
+	Short: "Manage the supabase_realtime publication",
+	Long: `Keep the supabase_realtime publication in sync with database.realtime.tables
+in .drift.yaml. Preview branches are often created without the tables that
+live features depend on, which breaks realtime subscriptions silently.`,
+}
+
+var dbRealtimeSyncCmd = &cobra.Command{
+	Use:   "sync [branch]",
+	Short: "Sync realtime publication tables to a branch",
+	Long: `Add missing tables to and remove unlisted tables from the supabase_realtime
+publication on the target branch, then report drift against production.
+
+The target defaults to the Supabase branch for the current git branch.
+
+Example config:
+  database:
+    realtime:
+      tables:
+        - public.messages
+        - public.presence`,
+	Example: `  drift db realtime sync
+  drift db realtime sync feature-x --dry-run
+  drift db realtime sync --check`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runDbRealtimeSync, RequireProject),
+}
+
+var (
+	dbRealtimeDryRunFlag bool
+	dbRealtimeCheckFlag  bool
+)
+
+func init() {
+	dbRealtimeSyncCmd.Flags().BoolVar(&dbRealtimeDryRunFlag, "dry-run", false, "Show changes without applying them")
+	dbRealtimeSyncCmd.Flags().BoolVar(&dbRealtimeCheckFlag, "check", false, "Exit non-zero if the branch is out of sync (implies --dry-run)")
+
+	dbRealtimeCmd.AddCommand(dbRealtimeSyncCmd)
+	dbCmd.AddCommand(dbRealtimeCmd)
+}
+
+func runDbRealtimeSync(ctx *Context) error {
+	cfg := ctx.Config()
+
+	want := realtimeTables(cfg)
+	if len(want) == 0 {
+		return fmt.Errorf("no realtime tables configured; set database.realtime.tables in .drift.yaml")
+	}
+
+	ui.Header("Realtime Sync")
+
+	gitBranch, err := ctx.GitBranch()
+	if err != nil {
+		return err
+	}
+	explicit := ctx.Arg(0)
+
+	client := ctx.Client()
+	sp := ui.NewSpinner("Resolving Supabase branch")
+	sp.Start()
+	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, explicit)
+	if err != nil {
+		sp.Fail("Failed to resolve Supabase branch")
+		return err
+	}
+	sp.Stop()
+
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.NewLine()
+
+	dbURL, err := getDbURLForProject(info.ProjectRef)
+	if err != nil {
+		return err
+	}
+	opts, err := restoreOptionsFromDBURL(dbURL)
+	if err != nil {
+		return err
+	}
+
+	have, err := database.FetchPublicationTables(opts, database.RealtimePublication)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", database.RealtimePublication, err)
+	}
+	add, remove := database.PublicationDiff(want, have)
+	printPublicationDiff(add, remove)
+
+	dryRun := dbRealtimeDryRunFlag || dbRealtimeCheckFlag
+	if len(add) == 0 && len(remove) == 0 {
+		ui.Successf("%s matches config (%d tables)", database.RealtimePublication, len(want))
+	} else if dbRealtimeCheckFlag {
+		return fmt.Errorf("%s is out of sync on %s", database.RealtimePublication, info.SupabaseBranch.Name)
+	} else if dryRun {
+		ui.Info("Dry run - no changes made")
+	} else {
+		if info.Environment == supabase.EnvProduction {
+			confirmed, err := RequireProductionConfirmation(info.Environment, "change the realtime publication")
+			if err != nil || !confirmed {
+				return nil
+			}
+		}
+		if err := applyPublicationSync(opts, add, remove); err != nil {
+			return err
+		}
+		ui.Successf("Synced %s (%d added, %d removed)", database.RealtimePublication, len(add), len(remove))
+	}
+
+	if info.Environment != supabase.EnvProduction {
+		reportRealtimeProductionDrift(client, want)
+	}
+	return nil
+}
+
+// realtimeTables returns the configured realtime tables, schema-qualified.
+func realtimeTables(cfg *config.Config) []string {
+	var tables []string
+	for _, t := range cfg.Database.Realtime.Tables {
+		if t = database.NormalizeTableName(t); t != "" {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+func applyPublicationSync(opts database.RestoreOptions, add, remove []string) error {
+	sql := database.PublicationSyncSQL(database.RealtimePublication, add, remove)
+	result, err := database.ExecuteSQL(opts, sql)
+	if err != nil {
+		if result != nil && strings.TrimSpace(result.Stderr) != "" {
+			return fmt.Errorf("failed to update %s: %s", database.RealtimePublication, strings.TrimSpace(result.Stderr))
+		}
+		return fmt.Errorf("failed to update %s: %w", database.RealtimePublication, err)
+	}
+	return nil
+}
+
+// syncRealtimeAfterMigrate applies configured realtime tables after a
+// migration push, since migrations may create the tables for the first time.
+func syncRealtimeAfterMigrate(cfg *config.Config, dbURL string) {
+	want := realtimeTables(cfg)
+	if len(want) == 0 || dbURL == "" {
+		return
+	}
+	opts, err := restoreOptionsFromDBURL(dbURL)
+	if err != nil {
+		return
+	}
+	have, err := database.FetchPublicationTables(opts, database.RealtimePublication)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not check realtime publication: %v", err))
+		return
+	}
+	add, remove := database.PublicationDiff(want, have)
+	if len(add) == 0 && len(remove) == 0 {
+		return
+	}
+	if err := applyPublicationSync(opts, add, remove); err != nil {
+		ui.Warning(err.Error())
+		return
+	}
+	ui.Successf("Synced %s (%d added, %d removed)", database.RealtimePublication, len(add), len(remove))
+}
+
+// reportRealtimeProductionDrift warns when production's publication differs from config.
+func reportRealtimeProductionDrift(client *supabase.Client, want []string) {
+	prod, err := client.GetProductionBranch()
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not check production: %v", err))
+		return
+	}
+	dbURL, err := getDbURLForProject(prod.ProjectRef)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not check production: %v", err))
+		return
+	}
+	opts, err := restoreOptionsFromDBURL(dbURL)
+	if err != nil {
+		return
+	}
+	have, err := database.FetchPublicationTables(opts, database.RealtimePublication)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read production %s: %v", database.RealtimePublication, err))
+		return
+	}
+
+	ui.NewLine()
+	ui.SubHeader("Production")
+	add, remove := database.PublicationDiff(want, have)
+	if len(add) == 0 && len(remove) == 0 {
+		ui.Success("Production matches config")
+		return
+	}
+	for _, t := range add {
+		ui.List(ui.Yellow(t + " is configured but missing in production"))
+	}
+	for _, t := range remove {
+		ui.List(ui.Yellow(t + " is in production but not in config"))
+	}
+}
+
+func printPublicationDiff(add, remove []string) {
+	if len(add) == 0 && len(remove) == 0 {
+		return
+	}
+	ui.SubHeader("Changes")
+	for _, t := range add {
+		ui.List(ui.Green("+ " + t))
+	}
+	for _, t := range remove {
+		ui.List(ui.Red("- " + t))
+	}
+	ui.NewLine()
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbSeedApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Run seed.sql against an existing branch",
+	Long: `Execute seed SQL against an existing Supabase branch, so previews created
+before the seed changed (or reset by hand) can be topped up with data.
+
+By default the generated supabase/seed.sql is applied. Named seed profiles
+can be configured in .drift.yaml and picked with --profile (or from a menu):
+
+  database:
+    seed_profiles:
+      demo: supabase/seeds/demo.sql
+      load-test: supabase/seeds/load.sql
+
+The target defaults to the Supabase branch for the current git branch.
+Production and protected branches require typing 'yes'; development asks
+for confirmation. The file stops at the first error.`,
+	Example: `  drift db seed apply
+  drift db seed apply --branch feature-x
+  drift db seed apply --profile demo
+  drift db seed apply --file supabase/seeds/extra.sql`,
+	Args: cobra.NoArgs,
+	RunE: Run(runDbSeedApply, RequireProject, GuardWorktree),
+}
+
+var (
+	dbSeedApplyBranchFlag  string
+	dbSeedApplyProfileFlag string
+	dbSeedApplyFileFlag    string
+)
+
+func init() {
+	dbSeedApplyCmd.Flags().StringVarP(&dbSeedApplyBranchFlag, "branch", "b", "", "Target Supabase branch (default: current git branch)")
+	dbSeedApplyCmd.Flags().StringVar(&dbSeedApplyProfileFlag, "profile", "", "Seed profile from database.seed_profiles")
+	dbSeedApplyCmd.Flags().StringVarP(&dbSeedApplyFileFlag, "file", "f", "", "SQL file to apply (default: supabase/seed.sql)")
+
+	dbSeedCmd.AddCommand(dbSeedApplyCmd)
+}
+
+// defaultSeedPath returns where 'drift db seed' writes seed.sql (next to the migrations directory).
+func defaultSeedPath(cfg *config.Config) string {
+	return filepath.Clean(filepath.Join(cfg.Supabase.MigrationsDir, "..", "seed.sql"))
+}
+
+func runDbSeedApply(ctx *Context) error {
+	cfg := ctx.Config()
+
+	if dbSeedApplyProfileFlag != "" && dbSeedApplyFileFlag != "" {
+		return fmt.Errorf("use either --profile or --file, not both")
+	}
+
+	ui.Header("Apply Seed Data")
+
+	seedFile, label, err := selectSeedFile(cfg)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(seedFile) {
+		seedFile = filepath.Join(cfg.ProjectRoot(), seedFile)
+	}
+	stat, err := os.Stat(seedFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("seed file not found: %s (generate one with 'drift db seed')", seedFile)
+		}
+		return err
+	}
+
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	client := ctx.Client()
+	sp := ui.NewSpinner("Resolving target environment")
+	sp.Start()
+	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, dbSeedApplyBranchFlag)
+	sp.Stop()
+	if err != nil {
+		return err
+	}
+
+	ui.KeyValue("Seed", label)
+	ui.KeyValue("File", fmt.Sprintf("%s %s", seedFile, ui.Dim(fmt.Sprintf("(%.2f KB)", float64(stat.Size())/1024))))
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+
+	confirmed, err := ConfirmDeploymentOperation(info, cfg, fmt.Sprintf("apply %s to %s", filepath.Base(seedFile), info.SupabaseBranch.Name))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return nil
+	}
+
+	dbURL, err := getDbURLForProject(info.ProjectRef)
+	if err != nil {
+		return err
+	}
+
+	ui.NewLine()
+	sp = ui.NewSpinner(fmt.Sprintf("Applying %s", filepath.Base(seedFile)))
+	sp.Start()
+	if err := database.ApplySQLFile(dbURL, seedFile); err != nil {
+		sp.Fail("Seed failed")
+		return err
+	}
+	sp.Success(fmt.Sprintf("Applied %s to %s", label, info.SupabaseBranch.Name))
+	return nil
+}
+
+// selectSeedFile picks the seed to apply from --file, --profile, or a menu of
+// seed.sql and the configured profiles. It returns the path and a display label.
+func selectSeedFile(cfg *config.Config) (string, string, error) {
+	if dbSeedApplyFileFlag != "" {
+		return dbSeedApplyFileFlag, filepath.Base(dbSeedApplyFileFlag), nil
+	}
+
+	profiles := cfg.Database.SeedProfiles
+	if dbSeedApplyProfileFlag != "" {
+		path, ok := profiles[dbSeedApplyProfileFlag]
+		if !ok {
+			return "", "", fmt.Errorf("unknown seed profile '%s' (configured: %s)", dbSeedApplyProfileFlag, stringsJoinSorted(seedProfileNames(profiles)))
+		}
+		return path, "profile " + dbSeedApplyProfileFlag, nil
+	}
+
+	defaultPath := defaultSeedPath(cfg)
+	if len(profiles) == 0 || IsYes() {
+		return defaultPath, "seed.sql", nil
+	}
+
+	names := seedProfileNames(profiles)
+	options := []string{fmt.Sprintf("seed.sql (%s)", defaultPath)}
+	for _, name := range names {
+		options = append(options, fmt.Sprintf("%s (%s)", name, profiles[name]))
+	}
+	idx, _, err := ui.PromptSelectWithIndex("Select seed", options)
+	if err != nil {
+		return "", "", err
+	}
+	if idx == 0 {
+		return defaultPath, "seed.sql", nil
+	}
+	name := names[idx-1]
+	return profiles[name], "profile " + name, nil
+}
+
+func seedProfileNames(profiles map[string]string) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+)
+
+func TestSelectSeedFile(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Supabase.MigrationsDir = "supabase/migrations"
+
+	tests := []struct {
+		name      string
+		profiles  map[string]string
+		file      string
+		profile   string
+		wantPath  string
+		wantLabel string
+		wantErr   bool
+	}{
+		{name: "default", wantPath: "supabase/seed.sql", wantLabel: "seed.sql"},
+		{name: "file flag", file: "seeds/extra.sql", wantPath: "seeds/extra.sql", wantLabel: "extra.sql"},
+		{name: "profile", profiles: map[string]string{"demo": "seeds/demo.sql"}, profile: "demo", wantPath: "seeds/demo.sql", wantLabel: "profile demo"},
+		{name: "unknown profile", profiles: map[string]string{"demo": "seeds/demo.sql"}, profile: "load", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Database.SeedProfiles = tt.profiles
+			dbSeedApplyFileFlag, dbSeedApplyProfileFlag = tt.file, tt.profile
+			t.Cleanup(func() { dbSeedApplyFileFlag, dbSeedApplyProfileFlag = "", "" })
+
+			path, label, err := selectSeedFile(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectSeedFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if path != tt.wantPath || label != tt.wantLabel {
+				t.Errorf("selectSeedFile() = (%q, %q), want (%q, %q)", path, label, tt.wantPath, tt.wantLabel)
+			}
+		})
+	}
+}
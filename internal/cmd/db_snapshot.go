@@ -0,0 +1,401 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Create and restore server-side database snapshots",
+	Long: `Create named snapshots of a branch's database and restore them, or restore
+to any point in time, without a local backup file.
+
+Snapshots and point-in-time recovery (PITR) run on Supabase and depend on the
+project's plan. They need SUPABASE_ACCESS_TOKEN. Restoring replaces the whole
+database and makes the project unavailable until it finishes.`,
+	Example: `  drift db snapshot create before-push      # Snapshot the current branch
+  drift db snapshot list -b dev              # Snapshots and PITR window for dev
+  drift db snapshot restore before-push -b dev
+  drift db snapshot restore --at 30m -b dev  # Restore to 30 minutes ago
+  drift db snapshot restore --at "2026-03-01 14:05"`,
+}
+
+var dbSnapshotCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a named snapshot",
+	Long: `Create a named snapshot of the target branch's database. Without a name,
+one is generated from the branch and the current time.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runDbSnapshotCreate, RequireProject),
+}
+
+var dbSnapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List snapshots, daily backups, and the PITR window",
+	Args:  cobra.NoArgs,
+	RunE:  Run(runDbSnapshotList, RequireProject),
+}
+
+var dbSnapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [name]",
+	Short: "Restore a named snapshot or a point in time",
+	Long: `Restore the target branch's database to a named snapshot, or with --at to a
+point in time inside the PITR window.
+
+--at accepts an RFC 3339 time, a local "YYYY-MM-DD HH:MM[:SS]" time, or a
+duration meaning that long ago (e.g. 30m, 2h). Production and protected
+branches require typing the confirmation; other branches ask yes/no.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runDbSnapshotRestore, RequireProject, GuardWorktree),
+}
+
+var (
+	dbSnapshotBranch string
+	dbSnapshotAt     string
+	dbSnapshotJSON   bool
+	dbSnapshotDryRun bool
+)
+
+func init() {
+	dbSnapshotCmd.PersistentFlags().StringVarP(&dbSnapshotBranch, "branch", "b", "", "Target Supabase branch")
+	dbSnapshotCreateCmd.Flags().BoolVar(&dbSnapshotDryRun, "dry-run", false, "Show what would be created")
+	dbSnapshotListCmd.Flags().BoolVar(&dbSnapshotJSON, "json", false, "Output as JSON")
+	dbSnapshotRestoreCmd.Flags().StringVar(&dbSnapshotAt, "at", "", "Restore to this point in time instead of a snapshot")
+	dbSnapshotRestoreCmd.Flags().BoolVar(&dbSnapshotDryRun, "dry-run", false, "Show what would be restored")
+
+	dbSnapshotCmd.AddCommand(dbSnapshotCreateCmd)
+	dbSnapshotCmd.AddCommand(dbSnapshotListCmd)
+	dbSnapshotCmd.AddCommand(dbSnapshotRestoreCmd)
+	dbCmd.AddCommand(dbSnapshotCmd)
+}
+
+// snapshotRecord is a snapshot created with drift. The API can look up a
+// snapshot by name but not list them, so drift keeps its own record.
+type snapshotRecord struct {
+	Name       string    `json:"name"`
+	ProjectRef string    `json:"project_ref"`
+	Branch     string    `json:"branch"`
+	CreatedAt  time.Time `json:"created_at"`
+	CreatedBy  string    `json:"created_by,omitempty"`
+}
+
+type snapshotRegistry struct {
+	Snapshots []snapshotRecord `json:"snapshots"`
+}
+
+func loadSnapshotRegistry(cfg *config.Config) (*snapshotRegistry, error) {
+	var reg snapshotRegistry
+	if _, err := state.ReadJSON(state.Path(cfg.ProjectRoot(), state.Snapshots), &reg); err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+func saveSnapshotRegistry(cfg *config.Config, reg *snapshotRegistry) error {
+	root := cfg.ProjectRoot()
+	return state.WriteJSON(root, state.Path(root, state.Snapshots), reg)
+}
+
+// add records snap, replacing an earlier record with the same name on the
+// same project.
+func (r *snapshotRegistry) add(snap snapshotRecord) {
+	for i, s := range r.Snapshots {
+		if s.ProjectRef == snap.ProjectRef && s.Name == snap.Name {
+			r.Snapshots[i] = snap
+			return
+		}
+	}
+	r.Snapshots = append(r.Snapshots, snap)
+}
+
+// forProject returns the snapshots of projectRef, newest first.
+func (r *snapshotRegistry) forProject(projectRef string) []snapshotRecord {
+	var out []snapshotRecord
+	for _, s := range r.Snapshots {
+		if s.ProjectRef == projectRef {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// defaultSnapshotName names a snapshot after its branch and creation time.
+func defaultSnapshotName(branch string, now time.Time) string {
+	return fmt.Sprintf("%s-%s", state.BranchKey(branch), now.UTC().Format("20060102-150405"))
+}
+
+// parseRestoreTime parses --at: an RFC 3339 time, a local date and time, or
+// a duration before now.
+func parseRestoreTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errs.Validationf("--at is empty")
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, errs.Validationf("--at duration must be positive, got %s", s)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errs.Validationf("invalid --at %q (use RFC 3339, \"YYYY-MM-DD HH:MM\", or a duration like 30m)", s)
+}
+
+func runDbSnapshotCreate(ctx *Context) error {
+	cfg := ctx.Config()
+	info, err := ctx.Target(dbSnapshotBranch)
+	if err != nil {
+		return err
+	}
+	name := ctx.Arg(0)
+	if name == "" {
+		name = defaultSnapshotName(info.SupabaseBranch.Name, time.Now())
+	}
+
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Snapshot", name)
+
+	return ctx.Apply(fmt.Sprintf("create snapshot %s of %s", name, info.SupabaseBranch.Name), func() error {
+		mgmt, err := supabase.NewManagementClient()
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		sp := ui.NewSpinner("Creating snapshot")
+		sp.Start()
+		point, err := mgmt.CreateRestorePoint(info.ProjectRef, name)
+		notifyOperation(cfg, "db snapshot create", string(info.Environment), info.SupabaseBranch.Name, start, err)
+		if err != nil {
+			sp.Fail("Failed to create snapshot")
+			return err
+		}
+		sp.Stop()
+
+		reg, err := loadSnapshotRegistry(cfg)
+		if err != nil {
+			return err
+		}
+		reg.add(snapshotRecord{
+			Name:       name,
+			ProjectRef: info.ProjectRef,
+			Branch:     info.SupabaseBranch.Name,
+			CreatedAt:  time.Now().UTC(),
+			CreatedBy:  currentActor(),
+		})
+		if err := saveSnapshotRegistry(cfg, reg); err != nil {
+			ui.Warningf("Snapshot created but not recorded locally: %v", err)
+		}
+
+		ui.Successf("Created snapshot %s", point.Name)
+		ui.Infof("Restore it with: drift db snapshot restore %s -b %s", point.Name, info.SupabaseBranch.Name)
+		return nil
+	})
+}
+
+// snapshotListReport is the JSON output of 'drift db snapshot list'.
+type snapshotListReport struct {
+	ProjectRef   string             `json:"project_ref"`
+	Environment  string             `json:"environment"`
+	PITREnabled  bool               `json:"pitr_enabled"`
+	PITREarliest *time.Time         `json:"pitr_earliest,omitempty"`
+	PITRLatest   *time.Time         `json:"pitr_latest,omitempty"`
+	Backups      []supabase.Backup  `json:"backups"`
+	Snapshots    []snapshotListItem `json:"snapshots"`
+}
+
+type snapshotListItem struct {
+	snapshotRecord
+	Status string `json:"status"`
+}
+
+func runDbSnapshotList(ctx *Context) error {
+	cfg := ctx.Config()
+	info, err := ctx.Target(dbSnapshotBranch)
+	if err != nil {
+		return err
+	}
+	mgmt, err := supabase.NewManagementClient()
+	if err != nil {
+		return err
+	}
+
+	sp := ui.NewSpinner("Fetching backups")
+	sp.Start()
+	backups, err := mgmt.ListBackups(info.ProjectRef)
+	if err != nil {
+		sp.Fail("Failed to fetch backups")
+		return err
+	}
+	reg, err := loadSnapshotRegistry(cfg)
+	if err != nil {
+		sp.Fail("Failed to read snapshots")
+		return err
+	}
+	var items []snapshotListItem
+	for _, snap := range reg.forProject(info.ProjectRef) {
+		status := "unknown"
+		if point, err := mgmt.GetRestorePoint(info.ProjectRef, snap.Name); err == nil && point.Status != "" {
+			status = point.Status
+		}
+		items = append(items, snapshotListItem{snapshotRecord: snap, Status: status})
+	}
+	sp.Stop()
+
+	earliest, latest, pitr := backups.PITRWindow()
+	if ctx.JSON() {
+		report := snapshotListReport{
+			ProjectRef:  info.ProjectRef,
+			Environment: string(info.Environment),
+			PITREnabled: backups.PITREnabled,
+			Backups:     backups.Backups,
+			Snapshots:   items,
+		}
+		if pitr {
+			report.PITREarliest, report.PITRLatest = &earliest, &latest
+		}
+		return ctx.PrintJSON(report)
+	}
+
+	ui.Header("Database Snapshots")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	if pitr {
+		ui.KeyValue("PITR Window", fmt.Sprintf("%s to %s", earliest.Local().Format("2006-01-02 15:04"), latest.Local().Format("2006-01-02 15:04")))
+	} else {
+		ui.KeyValue("PITR", ui.Dim("not enabled on this plan"))
+	}
+	ui.NewLine()
+
+	ui.SubHeader("Snapshots")
+	if len(items) == 0 {
+		ui.Info("No snapshots created with drift. Create one with 'drift db snapshot create'")
+	} else {
+		table := ui.NewTable([]string{"Name", "Status", "Created", "By"})
+		for _, item := range items {
+			table.AddRow([]string{item.Name, item.Status, formatBackupAge(item.CreatedAt), item.CreatedBy})
+		}
+		table.Render()
+	}
+	ui.NewLine()
+
+	ui.SubHeader("Daily Backups")
+	if len(backups.Backups) == 0 {
+		ui.Info("No daily backups")
+		return nil
+	}
+	table := ui.NewTable([]string{"Taken", "Status", "Type"})
+	for _, b := range backups.Backups {
+		kind := "logical"
+		if b.IsPhysicalBackup {
+			kind = "physical"
+		}
+		table.AddRow([]string{b.InsertedAt, b.Status, kind})
+	}
+	table.Render()
+	return nil
+}
+
+func runDbSnapshotRestore(ctx *Context) error {
+	cfg := ctx.Config()
+	name := ctx.Arg(0)
+	if (name == "") == (dbSnapshotAt == "") {
+		return errs.Validationf("specify either a snapshot name or --at, not both")
+	}
+
+	info, err := ctx.Target(dbSnapshotBranch)
+	if err != nil {
+		return err
+	}
+	mgmt, err := supabase.NewManagementClient()
+	if err != nil {
+		return err
+	}
+
+	var target time.Time
+	description := "snapshot " + name
+	if dbSnapshotAt != "" {
+		if target, err = parseRestoreTime(dbSnapshotAt, time.Now()); err != nil {
+			return err
+		}
+		backups, err := mgmt.ListBackups(info.ProjectRef)
+		if err != nil {
+			return err
+		}
+		earliest, latest, ok := backups.PITRWindow()
+		if !ok {
+			return errs.Configf("point-in-time recovery is not enabled for this project. Enable the PITR add-on, or restore a named snapshot")
+		}
+		if target.Before(earliest) || target.After(latest) {
+			return errs.Validationf("%s is outside the PITR window (%s to %s)",
+				target.Local().Format(time.RFC3339), earliest.Local().Format(time.RFC3339), latest.Local().Format(time.RFC3339))
+		}
+		description = target.Local().Format("2006-01-02 15:04:05 MST")
+	} else if _, err := mgmt.GetRestorePoint(info.ProjectRef, name); err != nil {
+		return err
+	}
+
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+	ui.KeyValue("Restore To", description)
+	ui.Warning("All changes made after this point will be lost.")
+
+	if ctx.DryRun() {
+		ui.Infof("Would restore %s to %s", info.SupabaseBranch.Name, description)
+		return nil
+	}
+
+	operation := fmt.Sprintf("restore %s to %s", info.SupabaseBranch.Name, description)
+	confirmed, err := ConfirmDeploymentOperation(info, cfg, operation)
+	if err != nil {
+		return err
+	}
+	if confirmed && info.Environment == supabase.EnvFeature && !IsYes() {
+		confirmed, err = ui.PromptYesNo(fmt.Sprintf("Restore %s to %s?", info.SupabaseBranch.Name, description), false)
+		if err != nil {
+			return err
+		}
+	}
+	if !confirmed {
+		return errs.Cancelled("db snapshot restore")
+	}
+
+	start := time.Now()
+	sp := ui.NewSpinner("Starting restore")
+	sp.Start()
+	if dbSnapshotAt != "" {
+		err = mgmt.RestorePITR(info.ProjectRef, target)
+	} else {
+		err = mgmt.UndoToRestorePoint(info.ProjectRef, name)
+	}
+	notifyOperation(cfg, "db snapshot restore", string(info.Environment), info.SupabaseBranch.Name, start, err)
+	if err != nil {
+		sp.Fail("Failed to start restore")
+		return err
+	}
+	sp.Stop()
+
+	ui.Successf("Restore of %s started", info.SupabaseBranch.Name)
+	ui.Info("The project is unavailable until the restore finishes; check progress in the Supabase dashboard")
+	return nil
+}
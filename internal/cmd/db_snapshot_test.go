@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRestoreTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "30m", want: now.Add(-30 * time.Minute)},
+		{in: "2h", want: now.Add(-2 * time.Hour)},
+		{in: "2026-03-01T14:05:00Z", want: time.Date(2026, 3, 1, 14, 5, 0, 0, time.UTC)},
+		{in: "2026-03-01 14:05", want: time.Date(2026, 3, 1, 14, 5, 0, 0, time.UTC)},
+		{in: "2026-03-01 14:05:30", want: time.Date(2026, 3, 1, 14, 5, 30, 0, time.UTC)},
+		{in: "", wantErr: true},
+		{in: "-5m", wantErr: true},
+		{in: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseRestoreTime(tt.in, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRestoreTime(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("parseRestoreTime(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotRegistry(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var reg snapshotRegistry
+	reg.add(snapshotRecord{Name: "a", ProjectRef: "dev", CreatedAt: base})
+	reg.add(snapshotRecord{Name: "b", ProjectRef: "dev", CreatedAt: base.Add(time.Hour)})
+	reg.add(snapshotRecord{Name: "a", ProjectRef: "prod", CreatedAt: base})
+	reg.add(snapshotRecord{Name: "a", ProjectRef: "dev", CreatedAt: base.Add(2 * time.Hour)})
+
+	if len(reg.Snapshots) != 3 {
+		t.Fatalf("len(Snapshots) = %d, want 3 (re-created name replaces)", len(reg.Snapshots))
+	}
+	got := reg.forProject("dev")
+	if len(got) != 2 || got[0].Name != "a" || got[1].Name != "b" {
+		t.Errorf("forProject(dev) = %+v, want a then b", got)
+	}
+}
+
+func TestDefaultSnapshotName(t *testing.T) {
+	got := defaultSnapshotName("feature/login", time.Date(2026, 3, 1, 14, 5, 9, 0, time.UTC))
+	if want := "feature_login-20260301-140509"; got != want {
+		t.Errorf("defaultSnapshotName() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbSubsetCmd = &cobra.Command{
+	Use:   "subset <prod|dev> [target]",
+	Short: "Copy a referentially consistent subset of rows to a branch",
+	Long: `Copy a small, referentially consistent slice of data ("thin clone") from
+production or development into a non-production branch.
+
+Rows are picked from a root table (by --limit and/or --percent), then every
+row that references them is followed through foreign keys, and finally every
+row those rows reference is pulled in so the copy has no dangling FKs.
+Existing rows in the target are left untouched (ON CONFLICT DO NOTHING).
+
+The target defaults to the Supabase branch for the current git branch.
+Defaults come from database.subset in .drift.yaml:
+
+  database:
+    subset:
+      root_table: public.users
+      limit: 100
+      exclude: [public.audit_log]`,
+	Example: `  drift db subset dev
+  drift db subset prod --root users --limit 50
+  drift db subset dev feature-x --percent 5 --where "created_at > now() - interval '30 days'"
+  drift db subset prod --dry-run`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: Run(runDbSubset, RequireProject, GuardWorktree),
+}
+
+var (
+	dbSubsetRootFlag    string
+	dbSubsetLimitFlag   int
+	dbSubsetPercentFlag float64
+	dbSubsetWhereFlag   string
+	dbSubsetDryRunFlag  bool
+)
+
+func init() {
+	dbSubsetCmd.Flags().StringVar(&dbSubsetRootFlag, "root", "", "Root table to sample (default: database.subset.root_table)")
+	dbSubsetCmd.Flags().IntVar(&dbSubsetLimitFlag, "limit", 0, "Maximum root rows to copy")
+	dbSubsetCmd.Flags().Float64Var(&dbSubsetPercentFlag, "percent", 0, "Percentage of the root table to sample")
+	dbSubsetCmd.Flags().StringVar(&dbSubsetWhereFlag, "where", "", "SQL filter applied to the root table")
+	dbSubsetCmd.Flags().BoolVar(&dbSubsetDryRunFlag, "dry-run", false, "Show row counts without copying")
+	dbSubsetCmd.Flags().StringVar(&dbPasswordFlag, "password", "", "Source database password (or use env var)")
+
+	dbCmd.AddCommand(dbSubsetCmd)
+}
+
+func runDbSubset(ctx *Context) error {
+	cfg := ctx.Config()
+
+	source := ctx.Args[0]
+	if source != "prod" && source != "production" && source != "dev" && source != "development" {
+		return fmt.Errorf("invalid source: %s (use prod or dev)", source)
+	}
+	isProd := source == "prod" || source == "production"
+	sourceName := "Development"
+	if isProd {
+		sourceName = "Production"
+	}
+
+	sel, root, err := subsetSelectionFromFlags(ctx.Cmd, cfg.Database.Subset)
+	if err != nil {
+		return err
+	}
+	schemas := cfg.Database.Subset.Schemas
+	if len(schemas) == 0 {
+		schemas = []string{"public"}
+	}
+	exclude := make([]string, len(cfg.Database.Subset.Exclude))
+	for i, t := range cfg.Database.Subset.Exclude {
+		exclude[i] = database.NormalizeTableName(t)
+	}
+
+	ui.Header("Database Subset")
+
+	client := ctx.Client()
+
+	var sourceBranch *supabase.Branch
+	if isProd {
+		sourceBranch, err = client.GetProductionBranch()
+	} else {
+		sourceBranch, err = client.GetDevelopmentBranch()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s branch: %w", sourceName, err)
+	}
+
+	targetBranch, err := resolveSubsetTarget(client, cfg, ctx.Args)
+	if err != nil {
+		return err
+	}
+	if targetBranch.ProjectRef == sourceBranch.ProjectRef {
+		return fmt.Errorf("source and target are the same branch (%s)", targetBranch.Name)
+	}
+
+	ui.KeyValue("Source", envColorString(sourceName))
+	ui.KeyValue("Target", ui.Cyan(targetBranch.Name))
+	ui.KeyValue("Root Table", root)
+	if sel.Limit > 0 {
+		ui.KeyValue("Limit", fmt.Sprintf("%d", sel.Limit))
+	}
+	if sel.Percent > 0 {
+		ui.KeyValue("Percent", fmt.Sprintf("%g%%", sel.Percent))
+	}
+	if sel.Where != "" {
+		ui.KeyValue("Where", sel.Where)
+	}
+	ui.NewLine()
+
+	sourceOpts, err := subsetConnection(client, cfg, sourceBranch, !isProd, getDbPassword(source))
+	if err != nil {
+		return err
+	}
+
+	sp := ui.NewSpinner("Reading foreign keys")
+	sp.Start()
+	fks, err := database.FetchForeignKeys(sourceOpts, schemas)
+	if err != nil {
+		sp.Fail("Failed to read foreign keys")
+		return err
+	}
+	plan := database.PlanSubset(root, fks, exclude)
+	columns, err := database.FetchInsertableColumns(sourceOpts, plan.Tables)
+	if err != nil {
+		sp.Fail("Failed to read table columns")
+		return err
+	}
+	sp.Success(fmt.Sprintf("Planned subset across %d tables", len(plan.Tables)))
+
+	if dbSubsetDryRunFlag {
+		sp = ui.NewSpinner("Counting rows")
+		sp.Start()
+		result, err := database.RunScript(sourceOpts, plan.ExtractScript(sel, columns, ""))
+		if err != nil {
+			sp.Fail("Failed to count rows")
+			return err
+		}
+		sp.Stop()
+		printSubsetCounts(plan, database.ParseSubsetCounts(result.Stdout))
+		ui.NewLine()
+		ui.Info("Dry run - nothing was copied")
+		return nil
+	}
+
+	if isProd && !IsYes() {
+		ui.Warning("Production rows will be copied into " + targetBranch.Name)
+		confirmed, err := ui.PromptYesNo("Continue?", false)
+		if err != nil || !confirmed {
+			ui.Info("Cancelled")
+			return nil
+		}
+	}
+
+	targetOpts, err := subsetConnection(client, cfg, targetBranch, true, "")
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "drift-subset-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	sp = ui.NewSpinner(fmt.Sprintf("Extracting subset from %s", sourceBranch.Name))
+	sp.Start()
+	result, err := database.RunScript(sourceOpts, plan.ExtractScript(sel, columns, dir))
+	if err != nil {
+		sp.Fail("Extraction failed")
+		return err
+	}
+	counts := database.ParseSubsetCounts(result.Stdout)
+	sp.Success("Subset extracted")
+
+	sp = ui.NewSpinner(fmt.Sprintf("Loading subset into %s", targetBranch.Name))
+	sp.Start()
+	if _, err := database.RunScript(targetOpts, plan.LoadScript(columns, dir)); err != nil {
+		sp.Fail("Load failed")
+		return err
+	}
+	sp.Success(fmt.Sprintf("Subset loaded into %s", targetBranch.Name))
+
+	printSubsetCounts(plan, counts)
+	return nil
+}
+
+// subsetSelectionFromFlags merges flags over database.subset and returns the
+// selection plus the schema-qualified root table.
+func subsetSelectionFromFlags(cmd *cobra.Command, sc config.SubsetConfig) (database.SubsetSelection, string, error) {
+	sel := database.SubsetSelection{Limit: sc.Limit, Percent: sc.Percent, Where: sc.Where}
+	root := sc.RootTable
+	if cmd.Flags().Changed("root") {
+		root = dbSubsetRootFlag
+	}
+	if cmd.Flags().Changed("limit") {
+		sel.Limit = dbSubsetLimitFlag
+	}
+	if cmd.Flags().Changed("percent") {
+		sel.Percent = dbSubsetPercentFlag
+	}
+	if cmd.Flags().Changed("where") {
+		sel.Where = dbSubsetWhereFlag
+	}
+
+	root = database.NormalizeTableName(root)
+	if root == "" {
+		return sel, "", fmt.Errorf("no root table; use --root or set database.subset.root_table in .drift.yaml")
+	}
+	if sel.Percent < 0 || sel.Percent > 100 {
+		return sel, "", fmt.Errorf("--percent must be between 0 and 100")
+	}
+	if sel.Limit < 0 {
+		return sel, "", fmt.Errorf("--limit must not be negative")
+	}
+	if sel.Limit == 0 && sel.Percent == 0 && strings.TrimSpace(sel.Where) == "" {
+		return sel, "", fmt.Errorf("refusing to copy the whole root table; set --limit, --percent, or --where")
+	}
+	return sel, root, nil
+}
+
+// resolveSubsetTarget finds the target branch from args or the current git branch.
+func resolveSubsetTarget(client *supabase.Client, cfg *config.Config, args []string) (*supabase.Branch, error) {
+	var branch *supabase.Branch
+	if len(args) > 1 {
+		b, err := client.GetBranch(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to find Supabase branch '%s': %w", args[1], err)
+		}
+		branch = b
+	} else {
+		gitBranch, err := git.CurrentBranch()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current git branch: %w", err)
+		}
+		info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, "")
+		if err != nil {
+			return nil, err
+		}
+		branch = info.SupabaseBranch
+		if info.Environment == supabase.EnvProduction {
+			return nil, fmt.Errorf("refusing to load a subset into production")
+		}
+	}
+	if supabase.IsProductionBranch(branch) {
+		return nil, fmt.Errorf("refusing to load a subset into production")
+	}
+	return branch, nil
+}
+
+// subsetConnection returns session-mode pooler options for a branch. When
+// useAPIPassword is set the branch password is read from the Management API.
+func subsetConnection(client *supabase.Client, cfg *config.Config, branch *supabase.Branch, useAPIPassword bool, password string) (database.RestoreOptions, error) {
+	connInfo, err := client.GetBranchConnectionInfo(branch.GitBranch)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not get connection info for %s via API: %v", branch.Name, err))
+	}
+
+	if useAPIPassword && connInfo != nil && connInfo.PostgresURL != "" {
+		if pw := supabase.ExtractPasswordFromURL(connInfo.PostgresURL); pw != "" {
+			password = pw
+		}
+	}
+	if password == "" {
+		password, err = ui.PromptPassword(fmt.Sprintf("Database password for %s", branch.Name))
+		if err != nil {
+			return database.RestoreOptions{}, err
+		}
+	}
+
+	opts := database.DefaultRestoreOptions()
+	if connInfo != nil && connInfo.PoolerHost != "" {
+		opts.Host = connInfo.PoolerHost
+	} else {
+		opts.Host = cfg.Database.GetPoolerHostForBranch(branch.GitBranch)
+	}
+	opts.Port = dbConnections(cfg).Port(database.OpScript.Mode())
+	opts.User = fmt.Sprintf("postgres.%s", branch.ProjectRef)
+	opts.Password = password
+	return opts, nil
+}
+
+func printSubsetCounts(plan database.SubsetPlan, counts map[string]int) {
+	ui.NewLine()
+	ui.SubHeader("Rows")
+	total := 0
+	for _, t := range plan.Tables {
+		ui.KeyValue(t, fmt.Sprintf("%d", counts[t]))
+		total += counts[t]
+	}
+	ui.KeyValue("Total", fmt.Sprintf("%d", total))
+}
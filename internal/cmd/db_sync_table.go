@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var dbSyncTableCmd = &cobra.Command{
+	Use:   "sync-table <table>",
+	Short: "Upsert a lookup table's rows from one environment to another",
+	Long: `Copy the rows of a reference/lookup table (feature flags, plans,
+localization strings, ...) from a source environment into a target and
+upsert them on a key, without touching any other table.
+
+Rows are matched on --key (default: the table's primary key). Missing rows
+are inserted, rows whose values differ are updated, and rows that only exist
+in the target are left alone. Only columns present in both databases are
+copied. The load runs in a single transaction.
+
+--from and --to accept prod, dev, or a Supabase branch name. Writing into
+production requires typing 'yes'.`,
+	Example: `  drift db sync-table feature_flags
+  drift db sync-table public.plans --from prod --to dev
+  drift db sync-table translations --to feature-x --key locale,key
+  drift db sync-table feature_flags --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: Run(runDbSyncTable, RequireProject, GuardWorktree),
+}
+
+var (
+	dbSyncTableFromFlag   string
+	dbSyncTableToFlag     string
+	dbSyncTableKeyFlag    []string
+	dbSyncTableDryRunFlag bool
+)
+
+func init() {
+	dbSyncTableCmd.Flags().StringVar(&dbSyncTableFromFlag, "from", "prod", "Source: prod, dev, or a branch name")
+	dbSyncTableCmd.Flags().StringVar(&dbSyncTableToFlag, "to", "dev", "Target: prod, dev, or a branch name")
+	dbSyncTableCmd.Flags().StringSliceVar(&dbSyncTableKeyFlag, "key", nil, "Columns to match rows on (default: primary key)")
+	dbSyncTableCmd.Flags().BoolVar(&dbSyncTableDryRunFlag, "dry-run", false, "Show what would change without writing")
+	dbSyncTableCmd.Flags().StringVar(&dbPasswordFlag, "password", "", "Production database password (or use env var)")
+
+	dbCmd.AddCommand(dbSyncTableCmd)
+}
+
+func runDbSyncTable(ctx *Context) error {
+	cfg := ctx.Config()
+
+	table := database.NormalizeTableName(ctx.Args[0])
+	if table == "" {
+		return fmt.Errorf("table name is required")
+	}
+
+	ui.Header("Sync Table")
+
+	client := ctx.Client()
+	sourceBranch, err := resolveNamedBranch(client, dbSyncTableFromFlag)
+	if err != nil {
+		return err
+	}
+	targetBranch, err := resolveNamedBranch(client, dbSyncTableToFlag)
+	if err != nil {
+		return err
+	}
+	if sourceBranch.ProjectRef == targetBranch.ProjectRef {
+		return fmt.Errorf("source and target are the same branch (%s)", targetBranch.Name)
+	}
+	targetIsProd := supabase.IsProductionBranch(targetBranch)
+
+	ui.KeyValue("Table", table)
+	ui.KeyValue("Source", syncTableBranchLabel(sourceBranch))
+	ui.KeyValue("Target", syncTableBranchLabel(targetBranch))
+	ui.NewLine()
+
+	sourceOpts, err := syncTableConnection(client, cfg, sourceBranch)
+	if err != nil {
+		return err
+	}
+	targetOpts, err := syncTableConnection(client, cfg, targetBranch)
+	if err != nil {
+		return err
+	}
+
+	sp := ui.NewSpinner("Reading table definitions")
+	sp.Start()
+	sync, skipped, err := planTableSync(sourceOpts, targetOpts, table, dbSyncTableKeyFlag)
+	if err != nil {
+		sp.Fail("Failed to read table definitions")
+		return err
+	}
+	sp.Success(fmt.Sprintf("Matching on %s", strings.Join(sync.Key, ", ")))
+	if len(skipped) > 0 {
+		ui.Warning(fmt.Sprintf("Columns missing in %s will not be copied: %s", targetBranch.Name, strings.Join(skipped, ", ")))
+	}
+
+	if targetIsProd && !dbSyncTableDryRunFlag {
+		confirmed, err := RequireDestructiveConfirmation(fmt.Sprintf("upsert %s into PRODUCTION", table))
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "drift-sync-table-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "rows.csv")
+
+	sp = ui.NewSpinner(fmt.Sprintf("Exporting %s from %s", table, sourceBranch.Name))
+	sp.Start()
+	result, err := database.RunScript(sourceOpts, sync.ExportScript(file))
+	if err != nil {
+		sp.Fail("Export failed")
+		return err
+	}
+	sp.Success(fmt.Sprintf("Exported %s rows", strings.TrimSpace(result.Stdout)))
+
+	verb := "Upserting"
+	if dbSyncTableDryRunFlag {
+		verb = "Comparing"
+	}
+	sp = ui.NewSpinner(fmt.Sprintf("%s into %s", verb, targetBranch.Name))
+	sp.Start()
+	result, err = database.RunScript(targetOpts, sync.UpsertScript(file, dbSyncTableDryRunFlag))
+	if err != nil {
+		sp.Fail("Upsert failed")
+		return err
+	}
+	inserted, updated, err := database.ParseUpsertCounts(result.Stdout)
+	if err != nil {
+		sp.Fail("Upsert finished with unexpected output")
+		return err
+	}
+	sp.Stop()
+
+	ui.NewLine()
+	ui.KeyValue("Inserted", fmt.Sprintf("%d", inserted))
+	ui.KeyValue("Updated", fmt.Sprintf("%d", updated))
+	ui.NewLine()
+	if dbSyncTableDryRunFlag {
+		ui.Info("Dry run - changes were rolled back")
+	} else {
+		ui.Success(fmt.Sprintf("Synced %s into %s", table, targetBranch.Name))
+	}
+	return nil
+}
+
+// resolveNamedBranch maps prod, dev, or a branch name to a Supabase branch.
+func resolveNamedBranch(client *supabase.Client, name string) (*supabase.Branch, error) {
+	var (
+		branch *supabase.Branch
+		err    error
+	)
+	switch strings.ToLower(name) {
+	case "prod", "production":
+		branch, err = client.GetProductionBranch()
+	case "dev", "development":
+		branch, err = client.GetDevelopmentBranch()
+	default:
+		branch, err = client.GetBranch(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Supabase branch '%s': %w", name, err)
+	}
+	return branch, nil
+}
+
+// syncTableConnection connects to a branch, reading the password from the
+// Management API except for production, which uses --password or PROD_PASSWORD.
+func syncTableConnection(client *supabase.Client, cfg *config.Config, branch *supabase.Branch) (database.RestoreOptions, error) {
+	if supabase.IsProductionBranch(branch) {
+		return subsetConnection(client, cfg, branch, false, getDbPassword("prod"))
+	}
+	return subsetConnection(client, cfg, branch, true, "")
+}
+
+// planTableSync reads the columns on both sides and the key columns from
+// the target, returning the sync plan and the source columns the target lacks.
+func planTableSync(sourceOpts, targetOpts database.RestoreOptions, table string, key []string) (database.TableSync, []string, error) {
+	sourceCols, err := database.FetchInsertableColumns(sourceOpts, []string{table})
+	if err != nil {
+		return database.TableSync{}, nil, fmt.Errorf("source: %w", err)
+	}
+	targetCols, err := database.FetchInsertableColumns(targetOpts, []string{table})
+	if err != nil {
+		return database.TableSync{}, nil, fmt.Errorf("target: %w", err)
+	}
+	columns, skipped := database.CommonColumns(sourceCols[table], targetCols[table])
+
+	if len(key) == 0 {
+		key, err = database.FetchPrimaryKey(targetOpts, table)
+		if err != nil {
+			return database.TableSync{}, nil, err
+		}
+	}
+
+	sync := database.TableSync{Table: table, Key: key, Columns: columns}
+	if err := sync.Validate(); err != nil {
+		return database.TableSync{}, nil, err
+	}
+	return sync, skipped, nil
+}
+
+func syncTableBranchLabel(branch *supabase.Branch) string {
+	if supabase.IsProductionBranch(branch) {
+		return envColorString("Production") + ui.Dim(" ("+branch.Name+")")
+	}
+	return ui.Cyan(branch.Name)
+}
@@ -0,0 +1,427 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+)
+
+var dbUsersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Manage database roles and passwords on a branch",
+	Long: `List the Postgres roles on a branch, create read-only login roles for
+analysts and tools, and rotate the postgres password of a preview branch.
+
+Without a subcommand, the roles on the current branch are listed.`,
+	Example: `  drift db users
+  drift db users create-readonly analyst --valid-until 30d
+  drift db users rotate-password feature-x`,
+	Args: cobra.NoArgs,
+	RunE: Run(runDbUsersList, RequireProject),
+}
+
+var dbUsersListCmd = &cobra.Command{
+	Use:   "list [branch]",
+	Short: "List database roles on a branch",
+	Long: `List the Postgres roles on a branch with their login, superuser, and
+Row Level Security bypass attributes, password expiry, and role memberships.
+Roles created and managed by Supabase are marked as reserved.
+
+The branch defaults to the Supabase branch for the current git branch.`,
+	Example: `  drift db users list
+  drift db users list dev`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runDbUsersList, RequireProject),
+}
+
+var dbUsersCreateReadOnlyCmd = &cobra.Command{
+	Use:   "create-readonly <role> [branch]",
+	Short: "Create a read-only login role",
+	Long: `Create a login role that can read, but not change, every table in the
+given schemas, including tables created later. Sessions for the role are
+read-only by default and it has no other privileges.
+
+A random password is generated and printed once with the connection string;
+drift does not store it.
+
+--valid-until takes a date (2026-01-31) or a duration in days (30d) after
+which the password stops working. Row Level Security policies still apply
+to the role unless --bypass-rls is given.`,
+	Example: `  drift db users create-readonly analyst
+  drift db users create-readonly metabase dev --schema public,analytics
+  drift db users create-readonly contractor --valid-until 14d`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: Run(runDbUsersCreateReadOnly, RequireProject),
+}
+
+var dbUsersDropCmd = &cobra.Command{
+	Use:   "drop <role> [branch]",
+	Short: "Drop a role created with drift db users",
+	Long: `Revoke everything granted to a role and drop it. Roles managed by
+Supabase cannot be dropped.`,
+	Example: `  drift db users drop analyst
+  drift db users drop contractor dev`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: Run(runDbUsersDrop, RequireProject),
+}
+
+var dbUsersRotatePasswordCmd = &cobra.Command{
+	Use:   "rotate-password [branch]",
+	Short: "Rotate the postgres password of a preview branch",
+	Long: `Set a new random password for the postgres role of a non-production
+branch, for example after a connection string was shared or leaked.
+
+Supabase keeps reporting the branch's original password, so the new one is
+saved per project ref under supabase.db_passwords in .drift.local.yaml of
+every worktree (encrypted when encryption.local_recipients is set). drift
+uses it for env generation, migrations, and dumps. Worktrees whose
+generated env files contain the old password are regenerated.
+
+The production password is managed in the Supabase dashboard.`,
+	Example: `  drift db users rotate-password
+  drift db users rotate-password feature-x --yes`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runDbUsersRotatePassword, RequireProject),
+}
+
+var (
+	dbUsersSchemasFlag    []string
+	dbUsersValidUntilFlag string
+	dbUsersBypassRLSFlag  bool
+)
+
+func init() {
+	dbUsersCreateReadOnlyCmd.Flags().StringSliceVar(&dbUsersSchemasFlag, "schema", []string{"public"}, "Schemas the role can read")
+	dbUsersCreateReadOnlyCmd.Flags().StringVar(&dbUsersValidUntilFlag, "valid-until", "", "Password expiry as a date (2026-01-31) or days (30d)")
+	dbUsersCreateReadOnlyCmd.Flags().BoolVar(&dbUsersBypassRLSFlag, "bypass-rls", false, "Read rows regardless of Row Level Security policies")
+
+	dbUsersCmd.AddCommand(dbUsersListCmd)
+	dbUsersCmd.AddCommand(dbUsersCreateReadOnlyCmd)
+	dbUsersCmd.AddCommand(dbUsersDropCmd)
+	dbUsersCmd.AddCommand(dbUsersRotatePasswordCmd)
+	dbCmd.AddCommand(dbUsersCmd)
+}
+
+// resolveDbUsersTarget resolves the branch for a db users command and
+// returns its connection options.
+func resolveDbUsersTarget(ctx *Context, explicit string) (*supabase.BranchInfo, database.RestoreOptions, error) {
+	info, err := ctx.Target(explicit)
+	if err != nil {
+		return nil, database.RestoreOptions{}, err
+	}
+
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.NewLine()
+
+	dbURL, err := getDbURLForProject(info.ProjectRef)
+	if err != nil {
+		return nil, database.RestoreOptions{}, err
+	}
+	opts, err := restoreOptionsFromDBURL(dbURL)
+	if err != nil {
+		return nil, database.RestoreOptions{}, err
+	}
+	return info, opts, nil
+}
+
+func runDbUsersList(ctx *Context) error {
+	ui.Header("Database Users")
+
+	_, opts, err := resolveDbUsersTarget(ctx, ctx.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	roles, err := database.FetchRoles(opts)
+	if err != nil {
+		return fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	table := ui.NewTable([]string{"Role", "Login", "Attributes", "Expires", "Member Of"})
+	custom := 0
+	for _, r := range roles {
+		var attrs []string
+		if r.Superuser {
+			attrs = append(attrs, "superuser")
+		}
+		if r.BypassRLS {
+			attrs = append(attrs, "bypassrls")
+		}
+		if r.IsReserved() {
+			attrs = append(attrs, "reserved")
+		} else {
+			custom++
+		}
+		login := ""
+		if r.CanLogin {
+			login = "yes"
+		}
+		table.AddRow([]string{r.Name, login, strings.Join(attrs, ", "), r.ValidUntil, strings.Join(r.MemberOf, ", ")})
+	}
+	table.Render()
+
+	ui.NewLine()
+	ui.Infof("%d role(s), %d not managed by Supabase", len(roles), custom)
+	return nil
+}
+
+func runDbUsersCreateReadOnly(ctx *Context) error {
+	name := ctx.Arg(0)
+	if err := database.ValidateRoleName(name); err != nil {
+		return errs.Validation(err)
+	}
+	validUntil, err := parseValidUntil(dbUsersValidUntilFlag, time.Now())
+	if err != nil {
+		return errs.Validation(err)
+	}
+	if len(dbUsersSchemasFlag) == 0 {
+		return errs.Validationf("at least one --schema is required")
+	}
+
+	ui.Header("Create Read-Only Role")
+
+	info, opts, err := resolveDbUsersTarget(ctx, ctx.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	if info.Environment == supabase.EnvProduction {
+		confirmed, err := RequireProductionConfirmation(info.Environment, fmt.Sprintf("create role %s", name))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			ui.Info("Cancelled")
+			return errs.Cancelled("create role")
+		}
+	}
+
+	password, err := generateDBPassword(24)
+	if err != nil {
+		return fmt.Errorf("failed to generate password: %w", err)
+	}
+	role := database.ReadOnlyRole{
+		Name:       name,
+		Password:   password,
+		Schemas:    dbUsersSchemasFlag,
+		ValidUntil: validUntil,
+		BypassRLS:  dbUsersBypassRLSFlag,
+	}
+
+	sp := ui.NewSpinner(fmt.Sprintf("Creating role %s", name))
+	sp.Start()
+	if _, err := database.RunScript(opts, database.InTransaction(database.CreateReadOnlyRoleScript(role))); err != nil {
+		sp.Fail("Failed to create role")
+		return fmt.Errorf("failed to create role %s: %w", name, err)
+	}
+	sp.Success(fmt.Sprintf("Created role %s", name))
+
+	ui.NewLine()
+	ui.KeyValue("Schemas", strings.Join(role.Schemas, ", "))
+	if validUntil != "" {
+		ui.KeyValue("Expires", validUntil)
+	}
+	ui.KeyValue("Connection", roleConnectionURL(opts, name, password))
+	ui.NewLine()
+	ui.Warning("The password is not stored; save it now")
+	return nil
+}
+
+func runDbUsersDrop(ctx *Context) error {
+	name := ctx.Arg(0)
+	if err := database.ValidateRoleName(name); err != nil {
+		return errs.Validation(err)
+	}
+
+	ui.Header("Drop Role")
+
+	info, opts, err := resolveDbUsersTarget(ctx, ctx.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	if info.Environment == supabase.EnvProduction {
+		confirmed, err := RequireProductionConfirmation(info.Environment, fmt.Sprintf("drop role %s", name))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			ui.Info("Cancelled")
+			return errs.Cancelled("drop role")
+		}
+	} else if !IsYes() {
+		confirmed, err := ui.PromptYesNo(fmt.Sprintf("Drop role %s on %s?", name, info.SupabaseBranch.Name), false)
+		if err != nil || !confirmed {
+			ui.Info("Cancelled")
+			return errs.Cancelled("drop role")
+		}
+	}
+
+	sp := ui.NewSpinner(fmt.Sprintf("Dropping role %s", name))
+	sp.Start()
+	if _, err := database.RunScript(opts, database.InTransaction(database.DropRoleScript(name))); err != nil {
+		sp.Fail("Failed to drop role")
+		return fmt.Errorf("failed to drop role %s: %w", name, err)
+	}
+	sp.Success(fmt.Sprintf("Dropped role %s", name))
+	return nil
+}
+
+func runDbUsersRotatePassword(ctx *Context) error {
+	cfg := ctx.Config()
+
+	ui.Header("Rotate Database Password")
+
+	info, opts, err := resolveDbUsersTarget(ctx, ctx.Arg(0))
+	if err != nil {
+		return err
+	}
+	if info.Environment == supabase.EnvProduction {
+		return errs.Validationf("the production password is managed in the Supabase dashboard (Project Settings > Database)")
+	}
+
+	if !IsYes() {
+		confirmed, err := ui.PromptYesNo(fmt.Sprintf("Rotate the postgres password of %s? Existing connections using it will stop working", info.SupabaseBranch.Name), false)
+		if err != nil || !confirmed {
+			ui.Info("Cancelled")
+			return errs.Cancelled("rotate password")
+		}
+	}
+
+	password, err := generateDBPassword(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate password: %w", err)
+	}
+	oldPassword := opts.Password
+
+	sp := ui.NewSpinner("Setting new password")
+	sp.Start()
+	if _, err := database.RunScript(opts, database.InTransaction(database.SetPasswordScript("postgres", password))); err != nil {
+		sp.Fail("Failed to set password")
+		return fmt.Errorf("failed to rotate password: %w", err)
+	}
+	sp.Success("Password rotated")
+
+	ui.NewLine()
+	ui.SubHeader("Local Config")
+	if err := storeDBPassword(cfg, info.ProjectRef, password); err != nil {
+		ui.Warning(fmt.Sprintf("The new password could not be saved: %v", err))
+		ui.KeyValue("New Password", password)
+		ui.Info(fmt.Sprintf("Add it under supabase.db_passwords.%s in %s", info.ProjectRef, config.LocalConfigFilename))
+		return err
+	}
+
+	if oldPassword != "" {
+		ui.NewLine()
+		regenerateWorktreeEnvs(map[string]string{"postgres": oldPassword})
+	}
+	return nil
+}
+
+// storeDBPassword saves password for projectRef in the local config of every
+// worktree that has drift config.
+func storeDBPassword(cfg *config.Config, projectRef, password string) error {
+	value := password
+	if recipients := cfg.Encryption.LocalRecipients(); len(recipients) > 0 {
+		encrypted, err := config.EncryptValue(password, recipients)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt password: %w", err)
+		}
+		value = encrypted
+	}
+
+	roots := []string{cfg.ProjectRoot()}
+	if worktrees, err := git.ListWorktrees(); err == nil {
+		for _, wt := range worktrees {
+			if wt.IsBare || wt.Path == cfg.ProjectRoot() {
+				continue
+			}
+			if _, err := os.Stat(config.ScopePath(wt.Path, config.ScopeShared)); err != nil {
+				continue
+			}
+			roots = append(roots, wt.Path)
+		}
+	}
+
+	for _, root := range roots {
+		err := config.UpdateScope(root, config.ScopeLocal, func(doc map[string]interface{}) error {
+			sb := ensureChildMap(doc, "supabase")
+			ensureChildMap(sb, "db_passwords")[projectRef] = value
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", root, err)
+		}
+		ui.Successf("Saved to %s", config.ScopePath(root, config.ScopeLocal))
+	}
+	return nil
+}
+
+// applyDBPasswordOverride replaces the password Supabase reports for a branch
+// with one rotated by 'drift db users rotate-password'.
+func applyDBPasswordOverride(cfg *config.Config, projectRef string, secrets *web.BranchSecretsInput) {
+	pw := cfg.Supabase.DBPasswordFor(projectRef)
+	if pw == "" || secrets == nil {
+		return
+	}
+	secrets.DatabasePassword = pw
+	if secrets.DirectDatabaseURL != "" {
+		secrets.DirectDatabaseURL = supabase.ReplacePasswordInURL(secrets.DirectDatabaseURL, pw)
+	}
+	if secrets.PoolerDatabaseURL != "" {
+		secrets.PoolerDatabaseURL = supabase.ReplacePasswordInURL(secrets.PoolerDatabaseURL, pw)
+	}
+}
+
+// parseValidUntil turns a --valid-until value into a date. It accepts a
+// date (2006-01-02) or a number of days from now (30d).
+func parseValidUntil(value string, now time.Time) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("invalid --valid-until %q: use a positive number of days such as 30d", value)
+		}
+		return now.AddDate(0, 0, n).Format("2006-01-02"), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return "", fmt.Errorf("invalid --valid-until %q: use a date (2006-01-02) or days (30d)", value)
+	}
+	if !t.After(now) {
+		return "", fmt.Errorf("--valid-until %s is in the past", value)
+	}
+	return value, nil
+}
+
+// roleConnectionURL returns a connection string for role on the database in
+// opts. Through the pooler, users are addressed as <role>.<project-ref>.
+func roleConnectionURL(opts database.RestoreOptions, role, password string) string {
+	user := role
+	if ref, ok := strings.CutPrefix(opts.User, "postgres."); ok {
+		user = role + "." + ref
+	}
+	u := url.URL{
+		Scheme: "postgresql",
+		User:   url.UserPassword(user, password),
+		Host:   fmt.Sprintf("%s:%d", opts.Host, opts.Port),
+		Path:   "/" + opts.Database,
+	}
+	return u.String()
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/database"
+)
+
+func TestParseValidUntil(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"30d", "2026-11-15", false},
+		{"2027-01-31", "2027-01-31", false},
+		{"2026-01-31", "", true},
+		{"0d", "", true},
+		{"soon", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseValidUntil(tt.value, now)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseValidUntil(%q) = %q, %v; want %q, wantErr %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRoleConnectionURL(t *testing.T) {
+	pooler := database.RestoreOptions{Host: "aws-0-us-east-1.pooler.supabase.com", Port: 5432, User: "postgres.abcref", Database: "postgres"}
+	want := "postgresql://analyst.abcref:pw@aws-0-us-east-1.pooler.supabase.com:5432/postgres"
+	if got := roleConnectionURL(pooler, "analyst", "pw"); got != want {
+		t.Errorf("roleConnectionURL(pooler) = %q, want %q", got, want)
+	}
+
+	direct := database.RestoreOptions{Host: "db.abcref.supabase.co", Port: 5432, User: "postgres", Database: "postgres"}
+	want = "postgresql://analyst:pw@db.abcref.supabase.co:5432/postgres"
+	if got := roleConnectionURL(direct, "analyst", "pw"); got != want {
+		t.Errorf("roleConnectionURL(direct) = %q, want %q", got, want)
+	}
+}
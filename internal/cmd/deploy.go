@@ -5,9 +5,11 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
 	"github.com/undrift/drift/internal/git"
 	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
@@ -29,11 +31,13 @@ Commands:
   functions    - Deploy all Edge Functions
   secrets      - Set configured environment secrets
   all          - Deploy functions and set secrets
+  plan         - Preview everything a deploy would change
   status       - Show deployment target and local functions
   list-secrets - List configured secrets on environment`,
 	Example: `  drift deploy functions        # Deploy all functions
   drift deploy secrets          # Set environment secrets
   drift deploy all              # Full deployment
+  drift deploy plan             # Preview migrations, functions, and secrets
   drift deploy status           # Check deployment target`,
 }
 
@@ -43,15 +47,23 @@ var deployFunctionsCmd = &cobra.Command{
 	Long: `Deploy all Edge Functions from your local project to the target environment.
 
 Functions are deployed from the supabase/functions directory (or as
-configured in .drift.yaml). Each function is deployed individually
-and progress is shown during deployment.
+configured in .drift.yaml) and from any extra directories listed in
+supabase.functions.roots, such as packages/edge in a monorepo. Each
+function is deployed individually and progress is shown during deployment.
 
-Use --no-verify-jwt to deploy functions that don't require authentication.`,
+Import maps set per root are merged into one map, with each root's entries
+scoped to that root. --import-map replaces the merged map for this deploy.
+
+Use --no-verify-jwt to deploy functions that don't require authentication.
+Per-function settings in supabase.functions.overrides (verify_jwt,
+import_map) take precedence over the flag, so public webhooks can skip
+JWT verification while everything else keeps it.`,
 	Example: `  drift deploy functions             # Deploy to current branch's environment
   drift deploy functions -b dev      # Deploy to dev environment
   drift deploy functions --fallback-branch development
-  drift deploy functions --no-verify-jwt  # Skip JWT verification`,
-	RunE: runDeployFunctions,
+  drift deploy functions --no-verify-jwt  # Skip JWT verification
+  drift deploy functions --import-map packages/edge/import_map.json`,
+	RunE: Run(runDeployFunctions, RequireProject),
 }
 
 var deploySecretsCmd = &cobra.Command{
@@ -71,7 +83,7 @@ Use environments.<env>.skip_secrets to avoid pushing keys on specific environmen
 	Example: `  drift deploy secrets           # Set secrets for current environment
   drift deploy secrets -b feature/x   # Set secrets for a specific branch
   drift deploy secrets --key-search-dir ../shared-keys`,
-	RunE: runDeploySecrets,
+	RunE: Run(runDeploySecrets, RequireProject),
 }
 
 var deployAllCmd = &cobra.Command{
@@ -83,11 +95,20 @@ This is equivalent to running:
   drift deploy functions
   drift deploy secrets
 
-Confirmation is required for production deployments unless --yes is used.`,
+Before anything is deployed, it runs 'drift secrets check' and stops if a
+secret the functions read would be missing. Skip it with --skip-secrets-check.
+
+Confirmation is required for production deployments unless --yes is used.
+
+With --auto-approve, the command instead computes the same plan as
+'drift deploy plan' and applies exactly that plan without prompts: pending
+migrations, changed secrets, and new or changed functions. Add --prune to
+also delete deployed functions that are missing locally.`,
 	Example: `  drift deploy all           # Full deployment
   drift deploy all -y        # Skip confirmation
+  drift deploy all --auto-approve  # Apply the 'drift deploy plan' plan
   drift deploy all -b feature/my-branch   # Deploy to specific non-production branch`,
-	RunE: runDeployAll,
+	RunE: Run(runDeployAll, RequireProject),
 }
 
 var deployStatusCmd = &cobra.Command{
@@ -102,7 +123,7 @@ Displays:
 
 Use 'drift functions list' for a comparison of local vs deployed functions.`,
 	Example: `  drift deploy status`,
-	RunE:    runDeployStatus,
+	RunE:    Run(runDeployStatus, RequireProject),
 }
 
 var deployListSecretsCmd = &cobra.Command{
@@ -111,16 +132,28 @@ var deployListSecretsCmd = &cobra.Command{
 	Long: `List all secrets configured on the target Supabase environment.
 
 Shows the names of all secrets (values are not displayed for security).
-Use this to verify secrets are configured before deploying functions.`,
-	Example: `  drift deploy list-secrets        # List for current environment
-  drift deploy list-secrets -b dev # List for dev environment`,
-	RunE: runDeployListSecrets,
+Use this to verify secrets are configured before deploying functions.
+
+With --compare, lists the target and each compared branch in parallel and
+prints a matrix of which secrets each one has, with secrets missing on the
+target first and in red. "prod" and "dev" name the production and
+development branches.`,
+	Example: `  drift deploy list-secrets                          # List for current environment
+  drift deploy list-secrets -b dev                   # List for dev environment
+  drift deploy list-secrets --compare dev            # Current branch vs dev
+  drift deploy list-secrets -b feat-x --compare dev,prod
+  drift deploy list-secrets --compare prod --json`,
+	RunE: Run(runDeployListSecrets, RequireProject),
 }
 
 var (
 	deployBranchFlag    string
 	deployNoVerifyJWT   bool
+	deployImportMap     string
+	deploySkipSecrets   bool
 	deployKeySearchDirs []string
+	deployCompareFlag   []string
+	deploySecretsJSON   bool
 )
 
 func init() {
@@ -129,12 +162,17 @@ func init() {
 	deploySecretsCmd.Flags().StringVarP(&deployBranchFlag, "branch", "b", "", "Target Supabase branch")
 	deployAllCmd.Flags().StringVarP(&deployBranchFlag, "branch", "b", "", "Target Supabase branch")
 	deployListSecretsCmd.Flags().StringVarP(&deployBranchFlag, "branch", "b", "", "Target Supabase branch")
+	deployListSecretsCmd.Flags().StringSliceVar(&deployCompareFlag, "compare", nil, "Branches to compare the target with (can be repeated)")
+	deployListSecretsCmd.Flags().BoolVar(&deploySecretsJSON, "json", false, "Output as JSON (with --compare)")
 	deploySecretsCmd.Flags().StringSliceVar(&deployKeySearchDirs, "key-search-dir", nil, "Directory to search for APNs key files (can be repeated; overrides configured search paths)")
 	deployAllCmd.Flags().StringSliceVar(&deployKeySearchDirs, "key-search-dir", nil, "Directory to search for APNs key files (can be repeated; overrides configured search paths)")
 
 	// Add --no-verify-jwt flag to functions deployment
 	deployFunctionsCmd.Flags().BoolVar(&deployNoVerifyJWT, "no-verify-jwt", false, "Deploy functions without JWT verification")
 	deployAllCmd.Flags().BoolVar(&deployNoVerifyJWT, "no-verify-jwt", false, "Deploy functions without JWT verification")
+	deployFunctionsCmd.Flags().StringVar(&deployImportMap, "import-map", "", "Import map for all functions (replaces supabase.functions import maps)")
+	deployAllCmd.Flags().StringVar(&deployImportMap, "import-map", "", "Import map for all functions (replaces supabase.functions import maps)")
+	deployAllCmd.Flags().BoolVar(&deploySkipSecrets, "skip-secrets-check", false, "Deploy even if secrets the functions read are missing")
 
 	deployCmd.AddCommand(deployFunctionsCmd)
 	deployCmd.AddCommand(deploySecretsCmd)
@@ -144,39 +182,14 @@ func init() {
 	rootCmd.AddCommand(deployCmd)
 }
 
-func getDeployTarget() (*supabase.BranchInfo, error) {
-	cfg := config.LoadOrDefault()
-	client := supabase.NewClient()
-
-	currentBranch, err := git.CurrentBranch()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current branch: %w", err)
-	}
-
-	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, currentBranch, deployBranchFlag)
-	if err != nil {
-		return nil, err
-	}
-
-	return info, nil
-}
-
-func runDeployFunctions(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runDeployFunctions(ctx *Context) error {
+	cfg := ctx.Config()
 
 	// Get target environment
-	sp := ui.NewSpinner("Resolving target environment")
-	sp.Start()
-
-	info, err := getDeployTarget()
+	info, err := ctx.Target(deployBranchFlag)
 	if err != nil {
-		sp.Fail("Failed to resolve environment")
 		return err
 	}
-	sp.Stop()
 
 	ui.Header("Deploy Edge Functions")
 	ui.KeyValue("Environment", envColorString(string(info.Environment)))
@@ -192,17 +205,28 @@ func runDeployFunctions(cmd *cobra.Command, args []string) error {
 
 	// Confirm for protected/development environments
 	confirmed, err := ConfirmDeploymentOperation(info, cfg, "deploy Edge Functions")
-	if err != nil || !confirmed {
-		return nil
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return errs.Cancelled("deploy Edge Functions")
+	}
+	deployConfirmedTarget = info
+
+	if deployCheckSecrets {
+		if err := requireFunctionSecrets(cfg, info); err != nil {
+			return err
+		}
 	}
 
 	ui.NewLine()
 
 	// List functions
-	allFunctions, err := supabase.ListFunctions(cfg.GetFunctionsPath())
+	layout, err := loadFunctionsLayout(cfg, deployImportMap)
 	if err != nil {
 		return err
 	}
+	allFunctions := layout.Functions
 
 	// Filter out restricted functions for this environment
 	var functions []supabase.Function
@@ -233,10 +257,7 @@ func runDeployFunctions(cmd *cobra.Command, args []string) error {
 	}
 
 	// Deploy each function
-	client := supabase.NewClient()
-	opts := supabase.DeployOptions{
-		NoVerifyJWT: deployNoVerifyJWT,
-	}
+	client := ctx.Client()
 
 	if deployNoVerifyJWT {
 		ui.Infof("Deploying with --no-verify-jwt")
@@ -246,12 +267,13 @@ func runDeployFunctions(cmd *cobra.Command, args []string) error {
 		sp := ui.NewSpinner(fmt.Sprintf("Deploying %s", fn.Name))
 		sp.Start()
 
+		opts := functionDeployOptions(cfg, layout, fn.Name)
 		if err := client.DeployFunctionWithOptions(fn.Name, info.ProjectRef, opts); err != nil {
 			sp.Fail(fmt.Sprintf("Failed to deploy %s", fn.Name))
 			return err
 		}
 
-		sp.Success(fmt.Sprintf("Deployed %s", fn.Name))
+		sp.Success(fmt.Sprintf("Deployed %s%s", fn.Name, describeDeployOptions(opts)))
 	}
 
 	ui.NewLine()
@@ -268,22 +290,14 @@ func runDeployFunctions(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runDeploySecrets(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runDeploySecrets(ctx *Context) error {
+	cfg := ctx.Config()
 
 	// Get target environment
-	sp := ui.NewSpinner("Resolving target environment")
-	sp.Start()
-
-	info, err := getDeployTarget()
+	info, err := ctx.Target(deployBranchFlag)
 	if err != nil {
-		sp.Fail("Failed to resolve environment")
 		return err
 	}
-	sp.Stop()
 
 	ui.Header("Set Secrets")
 	ui.KeyValue("Environment", envColorString(string(info.Environment)))
@@ -299,30 +313,142 @@ func runDeploySecrets(cmd *cobra.Command, args []string) error {
 
 	// Confirm for protected/development environments
 	confirmed, err := ConfirmDeploymentOperation(info, cfg, "set secrets")
-	if err != nil || !confirmed {
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return errs.Cancelled("set secrets")
+	}
+
+	ui.NewLine()
+
+	client := ctx.Client()
+	set := collectDeploySecrets(cfg, info)
+
+	if set.PushKeyPattern != "" {
+		ui.Infof("Using per-environment push key: %s", set.PushKeyPattern)
+	}
+
+	ui.SubHeader("APNs Key Search")
+	for _, path := range set.SearchDirs {
+		ui.List(path)
+	}
+	ui.NewLine()
+
+	if set.APNsErr != nil {
+		ui.Warning(fmt.Sprintf("Could not load APNs secrets: %v", set.APNsErr))
+		ui.Info("Skipping APNs-derived secrets")
+	} else if set.MatchedKeyFile != "" {
+		ui.KeyValue("Matched Key File", set.MatchedKeyFile)
+	}
+
+	if len(set.SkippedByPolicy) > 0 {
+		ui.Infof("Skipping secrets by environment policy: %s", stringsJoinSorted(set.SkippedByPolicy))
+	}
+
+	secretsToPush := set.Secrets
+	if IsVerbose() {
+		if len(cfg.Supabase.SecretsToPush) > 0 {
+			ui.Infof("Configured supabase.secrets_to_push: %s", stringsJoinSorted(cfg.Supabase.SecretsToPush))
+		} else {
+			ui.Info("No supabase.secrets_to_push configured; pushing all discovered secrets")
+		}
+		if len(cfg.Supabase.DefaultSecrets) > 0 {
+			ui.Infof("Configured supabase.default_secrets: %s", stringsJoinSorted(secretMapKeys(cfg.Supabase.DefaultSecrets)))
+		}
+		ui.Infof("Discovered %d secret candidate(s): %s", len(set.Available), stringsJoinSorted(secretMapKeys(set.Available)))
+	}
+	if len(set.ConfiguredSkipped) > 0 {
+		ui.Infof("Configured secrets skipped by policy: %s", stringsJoinSorted(set.ConfiguredSkipped))
+	}
+	if len(set.MissingConfigured) > 0 {
+		ui.Warningf("Configured secrets not available for this run: %s", stringsJoinSorted(set.MissingConfigured))
+	}
+
+	if len(secretsToPush) == 0 {
+		ui.Warning("No secrets selected to push")
+		ui.Info("Update supabase.secrets_to_push/default_secrets or environment secrets in .drift.yaml/.drift.local.yaml")
 		return nil
 	}
 
+	ui.Infof("Pushing %d secret(s): %s", len(secretsToPush), stringsJoinSecretNames(secretsToPush))
+	sp := ui.NewSpinner(fmt.Sprintf("Setting %d secret(s)", len(secretsToPush)))
+	sp.Start()
+
+	report := client.ApplySecrets(info.ProjectRef, secretsToPush)
+	if err := finishSecretsSpinner(sp, report); err != nil {
+		return err
+	}
+
 	ui.NewLine()
+	ui.Success("Secrets configured successfully")
+
+	return nil
+}
+
+// functionDeployOptions returns the deploy options for a function: the
+// --no-verify-jwt flag and the layout's import map and workdir, overridden
+// by supabase.functions.overrides. layout may be nil.
+func functionDeployOptions(cfg *config.Config, layout *functionsLayout, name string) supabase.DeployOptions {
+	opts := supabase.DeployOptions{
+		NoVerifyJWT: deployNoVerifyJWT,
+		ImportMap:   functionImportMap(cfg, layout, name),
+	}
+	if layout != nil {
+		opts.Workdir = layout.Workdir
+	}
+
+	if override := cfg.GetFunctionOverride(name); override.VerifyJWT != nil {
+		opts.NoVerifyJWT = !*override.VerifyJWT
+	}
+	return opts
+}
 
-	client := supabase.NewClient()
+// describeDeployOptions summarizes non-default options for deploy output.
+func describeDeployOptions(opts supabase.DeployOptions) string {
+	var notes []string
+	if opts.NoVerifyJWT {
+		notes = append(notes, "no JWT verification")
+	}
+	if opts.ImportMap != "" {
+		notes = append(notes, "import map "+filepath.Base(opts.ImportMap))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return ui.Dim(" (" + strings.Join(notes, ", ") + ")")
+}
+
+// deploySecretSet is what 'drift deploy secrets' would push to an environment.
+type deploySecretSet struct {
+	Secrets           []supabase.Secret
+	Available         map[string]string // every candidate before secrets_to_push
+	MissingConfigured []string          // in secrets_to_push but not available
+	ConfiguredSkipped []string          // in secrets_to_push but skipped by policy
+	SkippedByPolicy   []string          // environments.<env>.skip_secrets
+	PushKeyPattern    string            // per-environment push key, if configured
+	SearchDirs        []string          // resolved APNs key search directories
+	MatchedKeyFile    string
+	APNsErr           error
+}
+
+// collectDeploySecrets gathers the secrets to push for info's environment
+// from default secrets, APNs credentials, and per-environment secrets.
+func collectDeploySecrets(cfg *config.Config, info *supabase.BranchInfo) *deploySecretSet {
+	set := &deploySecretSet{Available: make(map[string]string)}
 
 	// Check for per-environment configuration
 	envName := string(info.Environment)
 	envConfig := cfg.GetEnvironmentConfig(envName)
 
 	// Determine APNs settings
-	apnsEnv := cfg.Apple.PushEnvironment
+	apnsEnv := apnsEnvironmentFor(cfg, info.Environment)
 	pushKeyPattern := cfg.Apple.PushKeyPattern
 
-	if info.Environment == supabase.EnvProduction {
-		apnsEnv = "production"
-	}
-
 	// Override with per-environment push key if configured
 	if envConfig != nil && envConfig.PushKey != "" {
 		pushKeyPattern = envConfig.PushKey
-		ui.Infof("Using per-environment push key: %s", pushKeyPattern)
+		set.PushKeyPattern = pushKeyPattern
 	}
 
 	searchPaths := cfg.Apple.KeySearchPaths
@@ -332,12 +458,7 @@ func runDeploySecrets(cmd *cobra.Command, args []string) error {
 	if len(searchPaths) == 0 {
 		searchPaths = []string{cfg.Apple.SecretsDir, ".", ".."}
 	}
-
-	ui.SubHeader("APNs Key Search")
-	for _, path := range resolveSearchDirs(cfg.ProjectRoot(), searchPaths) {
-		ui.List(path)
-	}
-	ui.NewLine()
+	set.SearchDirs = resolveSearchDirs(cfg.ProjectRoot(), searchPaths)
 
 	apnsSecrets, apnsLookup, err := supabase.LoadAPNSSecretsFromConfigWithSearchPaths(
 		cfg.Apple.TeamID,
@@ -349,100 +470,99 @@ func runDeploySecrets(cmd *cobra.Command, args []string) error {
 		searchPaths,
 	)
 
-	availableSecrets := make(map[string]string)
 	for key, value := range cfg.Supabase.DefaultSecrets {
-		availableSecrets[key] = value
+		set.Available[key] = value
 	}
 
 	if err != nil {
-		ui.Warning(fmt.Sprintf("Could not load APNs secrets: %v", err))
-		ui.Info("Skipping APNs-derived secrets")
+		set.APNsErr = err
 	} else {
-		if apnsLookup != nil && apnsLookup.MatchedFile != "" {
-			ui.KeyValue("Matched Key File", apnsLookup.MatchedFile)
+		if apnsLookup != nil {
+			set.MatchedKeyFile = apnsLookup.MatchedFile
 		}
-		availableSecrets["APNS_KEY_ID"] = apnsSecrets.KeyID
-		availableSecrets["APNS_TEAM_ID"] = apnsSecrets.TeamID
-		availableSecrets["APNS_BUNDLE_ID"] = apnsSecrets.BundleID
-		availableSecrets["APNS_PRIVATE_KEY"] = apnsSecrets.PrivateKey
-		availableSecrets["APNS_ENVIRONMENT"] = apnsSecrets.Environment
+		set.Available["APNS_KEY_ID"] = apnsSecrets.KeyID
+		set.Available["APNS_TEAM_ID"] = apnsSecrets.TeamID
+		set.Available["APNS_BUNDLE_ID"] = apnsSecrets.BundleID
+		set.Available["APNS_PRIVATE_KEY"] = apnsSecrets.PrivateKey
+		set.Available["APNS_ENVIRONMENT"] = apnsSecrets.Environment
 	}
 
 	// Add per-environment secrets
-	if envConfig != nil && len(envConfig.Secrets) > 0 {
+	if envConfig != nil {
 		for key, value := range envConfig.Secrets {
-			availableSecrets[key] = value
+			set.Available[key] = value
 		}
 	}
 
 	skippedByPolicy := make(map[string]bool)
-	if envConfig != nil && len(envConfig.SkipSecrets) > 0 {
+	if envConfig != nil {
 		for _, key := range envConfig.SkipSecrets {
 			if key == "" {
 				continue
 			}
 			skippedByPolicy[key] = true
-			delete(availableSecrets, key)
+			delete(set.Available, key)
 		}
 	}
+	set.SkippedByPolicy = secretSetKeys(skippedByPolicy)
 
-	if len(skippedByPolicy) > 0 {
-		ui.Infof("Skipping secrets by environment policy: %s", stringsJoinSorted(secretSetKeys(skippedByPolicy)))
-	}
+	set.Secrets, set.MissingConfigured, set.ConfiguredSkipped = selectSecretsToPush(cfg.Supabase.SecretsToPush, set.Available, skippedByPolicy)
+	return set
+}
 
-	secretsToPush, missingConfigured, configuredSkipped := selectSecretsToPush(cfg.Supabase.SecretsToPush, availableSecrets, skippedByPolicy)
-	if IsVerbose() {
-		if len(cfg.Supabase.SecretsToPush) > 0 {
-			ui.Infof("Configured supabase.secrets_to_push: %s", stringsJoinSorted(cfg.Supabase.SecretsToPush))
-		} else {
-			ui.Info("No supabase.secrets_to_push configured; pushing all discovered secrets")
-		}
-		if len(cfg.Supabase.DefaultSecrets) > 0 {
-			ui.Infof("Configured supabase.default_secrets: %s", stringsJoinSorted(secretMapKeys(cfg.Supabase.DefaultSecrets)))
-		}
-		ui.Infof("Discovered %d secret candidate(s): %s", len(availableSecrets), stringsJoinSorted(secretMapKeys(availableSecrets)))
-	}
-	if len(configuredSkipped) > 0 {
-		ui.Infof("Configured secrets skipped by policy: %s", stringsJoinSorted(configuredSkipped))
-	}
-	if len(missingConfigured) > 0 {
-		ui.Warningf("Configured secrets not available for this run: %s", stringsJoinSorted(missingConfigured))
-	}
+// deployConfirmedTarget is set once a functions deploy is confirmed, so
+// 'deploy all' only sends notifications for deployments that actually ran.
+var deployConfirmedTarget *supabase.BranchInfo
 
-	if len(secretsToPush) == 0 {
-		ui.Warning("No secrets selected to push")
-		ui.Info("Update supabase.secrets_to_push/default_secrets or environment secrets in .drift.yaml/.drift.local.yaml")
-		return nil
-	}
+// deployCheckSecrets makes the functions deploy run the secrets check first.
+// 'deploy all' sets it, since it pushes the secrets the check counts on.
+var deployCheckSecrets bool
 
-	ui.Infof("Pushing %d secret(s): %s", len(secretsToPush), stringsJoinSecretNames(secretsToPush))
-	sp = ui.NewSpinner(fmt.Sprintf("Setting %d secret(s)", len(secretsToPush)))
+// requireFunctionSecrets fails when a secret the functions read would be
+// missing on info's branch after the deploy.
+func requireFunctionSecrets(cfg *config.Config, info *supabase.BranchInfo) error {
+	sp := ui.NewSpinner("Checking function secrets")
 	sp.Start()
-
-	if err := client.SetSecrets(info.ProjectRef, secretsToPush); err != nil {
-		sp.Fail("Failed to set secrets")
-		return err
+	report, err := checkFunctionSecrets(cfg, supabase.NewClient(), info)
+	if err != nil {
+		sp.Fail("Failed to check function secrets")
+		return fmt.Errorf("%w (use --skip-secrets-check to deploy without the check)", err)
+	}
+	sp.Stop()
+	if len(report.Missing) == 0 {
+		return nil
 	}
-	sp.Success(fmt.Sprintf("Set %d secret(s)", len(secretsToPush)))
-
 	ui.NewLine()
-	ui.Success("Secrets configured successfully")
-
-	return nil
+	printMissingSecrets(report)
+	return errs.Validationf("%d required secret(s) missing on %s (use --skip-secrets-check to deploy anyway)", len(report.Missing), info.SupabaseBranch.Name)
 }
 
-func runDeployAll(cmd *cobra.Command, args []string) error {
+func runDeployAll(ctx *Context) error {
+	if deployAutoApprove {
+		return runDeployAllPlan(ctx)
+	}
+	if deployPrune {
+		return fmt.Errorf("--prune requires --auto-approve")
+	}
+
 	ui.Header("Full Deployment")
 
-	// Deploy functions
-	if err := runDeployFunctions(cmd, args); err != nil {
-		return err
-	}
+	start := time.Now()
+	deployConfirmedTarget = nil
+	deployCheckSecrets = !deploySkipSecrets
+	defer func() { deployCheckSecrets = false }()
 
-	ui.NewLine()
+	// Deploy functions, then set secrets
+	err := runDeployFunctions(ctx)
+	if err == nil {
+		ui.NewLine()
+		err = runDeploySecrets(ctx)
+	}
 
-	// Set secrets
-	if err := runDeploySecrets(cmd, args); err != nil {
+	if info := deployConfirmedTarget; info != nil {
+		notifyOperation(ctx.Config(), "deploy all", string(info.Environment), info.SupabaseBranch.Name, start, err)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -452,11 +572,8 @@ func runDeployAll(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runDeployStatus(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runDeployStatus(ctx *Context) error {
+	cfg := ctx.Config()
 
 	// Get current git branch
 	gitBranch, err := git.CurrentBranch()
@@ -464,7 +581,7 @@ func runDeployStatus(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	client := supabase.NewClient()
+	client := ctx.Client()
 	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, "")
 	if err != nil {
 		ui.Warning(fmt.Sprintf("Could not resolve Supabase branch: %v", err))
@@ -493,34 +610,41 @@ func runDeployStatus(cmd *cobra.Command, args []string) error {
 	ui.NewLine()
 	ui.SubHeader("Edge Functions")
 
-	functions, err := supabase.ListFunctions(cfg.GetFunctionsPath())
+	functions, err := listLocalFunctions(cfg)
 	if err != nil {
 		ui.Warning(fmt.Sprintf("Could not list functions: %v", err))
 	} else {
+		deployed := make(map[string]supabase.DeployedFunction)
+		if list, err := client.ListDeployedFunctions(info.ProjectRef); err == nil {
+			for _, d := range list {
+				deployed[d.Name] = d
+			}
+		}
 		for _, fn := range functions {
-			ui.List(fn.Name)
+			if d, ok := deployed[fn.Name]; ok {
+				ui.List(fmt.Sprintf("%s %s", fn.Name, ui.Dim("v"+d.Version)))
+			} else {
+				ui.List(fmt.Sprintf("%s %s", fn.Name, ui.Yellow("(not deployed)")))
+			}
 		}
 		ui.Infof("Total: %d functions", len(functions))
 	}
 
+	printCacheBanner()
+
 	return nil
 }
 
-func runDeployListSecrets(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
+func runDeployListSecrets(ctx *Context) error {
+	if len(deployCompareFlag) > 0 {
+		return runDeployCompareSecrets(ctx)
 	}
 
 	// Get target environment
-	sp := ui.NewSpinner("Resolving target environment")
-	sp.Start()
-
-	info, err := getDeployTarget()
+	info, err := ctx.Target(deployBranchFlag)
 	if err != nil {
-		sp.Fail("Failed to resolve environment")
 		return err
 	}
-	sp.Stop()
 
 	ui.Header("Secrets")
 	ui.KeyValue("Environment", envColorString(string(info.Environment)))
@@ -538,8 +662,8 @@ func runDeployListSecrets(cmd *cobra.Command, args []string) error {
 	ui.NewLine()
 
 	// List secrets
-	client := supabase.NewClient()
-	sp = ui.NewSpinner("Fetching secrets")
+	client := ctx.Client()
+	sp := ui.NewSpinner("Fetching secrets")
 	sp.Start()
 
 	secrets, err := client.ListSecrets(info.ProjectRef)
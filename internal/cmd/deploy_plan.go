@@ -0,0 +1,602 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var deployPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Preview everything a deploy would change",
+	Long: `Show a single plan of what 'drift deploy all' would change on the target
+environment, without changing anything:
+
+  - Pending migrations (applied before anything else)
+  - Edge Functions to add, or to update because their source differs from
+    the deployed version (compared by hash of the downloaded source)
+  - Secrets to add or update (compared against the remote digests), and
+    secrets skipped by policy or missing
+
+Functions deployed but not in the local project are only listed unless
+--prune is given, in which case they are planned for deletion.
+
+Run 'drift deploy all --auto-approve' to execute the same plan without
+prompts.`,
+	Example: `  drift deploy plan                # Plan for current branch's environment
+  drift deploy plan -b dev         # Plan for dev environment
+  drift deploy plan --prune        # Include deletion of orphaned functions
+  drift deploy plan --json         # Machine-readable plan for CI`,
+	RunE: Run(runDeployPlan, RequireProject),
+}
+
+var (
+	deployAutoApprove bool
+	deployPrune       bool
+	deployPlanJSON    bool
+)
+
+func init() {
+	deployPlanCmd.Flags().StringVarP(&deployBranchFlag, "branch", "b", "", "Target Supabase branch")
+	deployPlanCmd.Flags().StringSliceVar(&deployKeySearchDirs, "key-search-dir", nil, "Directory to search for APNs key files (can be repeated; overrides configured search paths)")
+	deployPlanCmd.Flags().BoolVar(&deployPrune, "prune", false, "Plan deletion of deployed functions missing locally")
+	deployPlanCmd.Flags().BoolVar(&deployPlanJSON, "json", false, "Output the plan as JSON")
+
+	deployAllCmd.Flags().BoolVar(&deployAutoApprove, "auto-approve", false, "Compute the deploy plan and apply it without prompts")
+	deployAllCmd.Flags().BoolVar(&deployPrune, "prune", false, "With --auto-approve, delete deployed functions missing locally")
+
+	deployCmd.AddCommand(deployPlanCmd)
+}
+
+// planAction is what a deploy does to one resource.
+type planAction string
+
+const (
+	planAdd     planAction = "add"
+	planChange  planAction = "change"
+	planDestroy planAction = "destroy"
+	planSkip    planAction = "skip"
+)
+
+// Resource kinds in a deploy plan, in the order they are applied.
+const (
+	planMigration = "migration"
+	planSecret    = "secret"
+	planFunction  = "function"
+)
+
+// planItem is one planned change.
+type planItem struct {
+	Kind   string     `json:"kind"`
+	Name   string     `json:"name"`
+	Action planAction `json:"action"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// deployPlan is everything 'deploy all --auto-approve' would do.
+type deployPlan struct {
+	Target    envState   `json:"target"`
+	Items     []planItem `json:"items"`
+	Unchanged int        `json:"unchanged"`
+	Warnings  []string   `json:"warnings,omitempty"`
+
+	// secrets holds the values for planned secret changes; never serialized.
+	secrets []supabase.Secret
+}
+
+func (p *deployPlan) add(kind, name string, action planAction, reason string) {
+	p.Items = append(p.Items, planItem{Kind: kind, Name: name, Action: action, Reason: reason})
+}
+
+func (p *deployPlan) warn(format string, args ...interface{}) {
+	p.Warnings = append(p.Warnings, fmt.Sprintf(format, args...))
+}
+
+// count returns how many items have action.
+func (p *deployPlan) count(action planAction) int {
+	n := 0
+	for _, item := range p.Items {
+		if item.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// HasChanges reports whether applying the plan would change anything.
+func (p *deployPlan) HasChanges() bool {
+	return p.count(planAdd)+p.count(planChange)+p.count(planDestroy) > 0
+}
+
+// names returns the names of items of kind with one of actions.
+func (p *deployPlan) names(kind string, actions ...planAction) []string {
+	var names []string
+	for _, item := range p.Items {
+		if item.Kind != kind {
+			continue
+		}
+		for _, action := range actions {
+			if item.Action == action {
+				names = append(names, item.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+func runDeployPlan(ctx *Context) error {
+	cfg := ctx.Config()
+
+	info, err := ctx.Target(deployBranchFlag)
+	if err != nil {
+		return err
+	}
+
+	plan, err := buildDeployPlanWithSpinner(cfg, info)
+	if err != nil {
+		return err
+	}
+
+	if deployPlanJSON {
+		return ctx.PrintJSON(plan)
+	}
+
+	printDeployPlan(info, plan)
+
+	if plan.HasChanges() {
+		ui.NewLine()
+		ui.SubHeader("Next Steps")
+		if deployPrune {
+			ui.List("drift deploy all --auto-approve --prune - Apply this plan")
+		} else {
+			ui.List("drift deploy all --auto-approve - Apply this plan")
+		}
+	}
+	return nil
+}
+
+// runDeployAllPlan is 'deploy all --auto-approve': compute the plan, show
+// it, and apply it without prompting.
+func runDeployAllPlan(ctx *Context) error {
+	cfg := ctx.Config()
+
+	info, err := ctx.Target(deployBranchFlag)
+	if err != nil {
+		return err
+	}
+
+	plan, err := buildDeployPlanWithSpinner(cfg, info)
+	if err != nil {
+		return err
+	}
+	printDeployPlan(info, plan)
+
+	if !plan.HasChanges() {
+		return nil
+	}
+	if err := requireNotReviewMode(cfg, "deploy"); err != nil {
+		return err
+	}
+	if !deploySkipSecrets {
+		if err := requireFunctionSecrets(cfg, info); err != nil {
+			return err
+		}
+	}
+
+	ui.NewLine()
+	start := time.Now()
+	err = applyDeployPlan(cfg, info, plan)
+	notifyOperation(cfg, "deploy all", string(info.Environment), info.SupabaseBranch.Name, start, err)
+	if err != nil {
+		return err
+	}
+	if err := saveDeployManifest(cfg, plan, time.Now()); err != nil {
+		ui.Warningf("Could not record deploy manifest: %v", err)
+	}
+
+	ui.NewLine()
+	ui.Success("Full deployment complete!")
+	return nil
+}
+
+func buildDeployPlanWithSpinner(cfg *config.Config, info *supabase.BranchInfo) (*deployPlan, error) {
+	sp := ui.NewSpinner("Computing deploy plan")
+	sp.Start()
+
+	plan, err := buildDeployPlan(cfg, info, sp.UpdateMessage)
+	if err != nil {
+		sp.Fail("Failed to compute deploy plan")
+		return nil, err
+	}
+	sp.Stop()
+	return plan, nil
+}
+
+// buildDeployPlan compares the local project with info's environment.
+// Anything that cannot be checked is reported as a warning rather than
+// guessed at, except deployed functions, without which there is no plan.
+func buildDeployPlan(cfg *config.Config, info *supabase.BranchInfo, progress func(string)) (*deployPlan, error) {
+	plan := &deployPlan{Target: newEnvState(info), Items: []planItem{}}
+	client := supabase.NewClient()
+
+	// Migrations
+	progress("Checking migrations")
+	if local, err := getLocalMigrations(cfg); err != nil {
+		plan.warn("Could not list local migrations: %v", err)
+	} else if applied, err := getAppliedMigrations(info.ProjectRef); err != nil {
+		plan.warn("Could not check applied migrations: %v", err)
+	} else {
+		pending := findPendingMigrations(local, applied)
+		for _, m := range pending {
+			plan.add(planMigration, m, planAdd, "pending")
+		}
+		plan.Unchanged += len(local) - len(pending)
+	}
+
+	// Secrets
+	progress("Comparing secrets")
+	planSecrets(plan, collectDeploySecrets(cfg, info), client, info.ProjectRef)
+
+	// Functions
+	progress("Fetching deployed functions")
+	localFunctions, err := listLocalFunctions(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local functions: %w", err)
+	}
+	deployedFunctions, err := client.ListDeployedFunctions(info.ProjectRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployed functions: %w", err)
+	}
+	deployed := make(map[string]bool, len(deployedFunctions))
+	for _, fn := range deployedFunctions {
+		deployed[fn.Name] = true
+	}
+
+	envName := string(info.Environment)
+	for _, fn := range localFunctions {
+		switch {
+		case cfg.IsFunctionRestricted(fn.Name, envName):
+			plan.add(planFunction, fn.Name, planSkip, "restricted on "+envName)
+		case !deployed[fn.Name]:
+			plan.add(planFunction, fn.Name, planAdd, "not deployed")
+		default:
+			progress(fmt.Sprintf("Comparing %s with deployed source", fn.Name))
+			same, err := functionMatchesDeployed(client, fn, info.ProjectRef)
+			switch {
+			case err != nil:
+				plan.add(planFunction, fn.Name, planChange, fmt.Sprintf("could not compare: %v", err))
+			case same:
+				plan.Unchanged++
+			default:
+				plan.add(planFunction, fn.Name, planChange, "source differs from deployed")
+			}
+		}
+	}
+
+	for _, fn := range findOrphanedFunctions(localFunctions, deployedFunctions) {
+		if deployPrune {
+			plan.add(planFunction, fn.Name, planDestroy, "not in local project")
+		} else {
+			plan.add(planFunction, fn.Name, planSkip, "deployed only; --prune deletes it")
+		}
+	}
+
+	return plan, nil
+}
+
+// planSecrets plans secret changes by comparing values with the remote
+// secrets. The Management API returns a SHA-256 digest of each value.
+func planSecrets(plan *deployPlan, set *deploySecretSet, client *supabase.Client, projectRef string) {
+	if set.APNsErr != nil {
+		plan.warn("Could not load APNs secrets: %v", set.APNsErr)
+	}
+
+	remote, comparable, err := fetchRemoteSecrets(client, projectRef)
+	if err != nil {
+		plan.warn("Could not list remote secrets: %v", err)
+	}
+
+	for _, secret := range set.Secrets {
+		current, exists := remote[secret.Name]
+		switch {
+		case !exists:
+			plan.add(planSecret, secret.Name, planAdd, "")
+		case !comparable:
+			plan.add(planSecret, secret.Name, planChange, "remote value cannot be compared")
+		case supabase.SecretValueMatches(current, secret.Value):
+			plan.Unchanged++
+			continue
+		default:
+			plan.add(planSecret, secret.Name, planChange, "value differs")
+		}
+		plan.secrets = append(plan.secrets, secret)
+	}
+
+	for _, name := range set.SkippedByPolicy {
+		plan.add(planSecret, name, planSkip, "skip_secrets policy")
+	}
+	for _, name := range set.MissingConfigured {
+		plan.add(planSecret, name, planSkip, "in secrets_to_push but no value available")
+	}
+}
+
+// fetchRemoteSecrets returns the remote secrets by name. Values are only
+// comparable when read through the Management API; the CLI lists names.
+func fetchRemoteSecrets(client *supabase.Client, projectRef string) (map[string]string, bool, error) {
+	remote := make(map[string]string)
+	if mgmt, err := supabase.NewManagementClient(); err == nil {
+		secrets, err := mgmt.GetSecrets(projectRef)
+		if err != nil {
+			return remote, false, err
+		}
+		for _, s := range secrets {
+			remote[s.Name] = s.Value
+		}
+		return remote, true, nil
+	}
+
+	names, err := client.ListSecrets(projectRef)
+	if err != nil {
+		return remote, false, err
+	}
+	for _, name := range names {
+		remote[name] = ""
+	}
+	return remote, false, nil
+}
+
+// functionMatchesDeployed downloads the deployed source of fn and reports
+// whether it hashes the same as the local function directory.
+func functionMatchesDeployed(client *supabase.Client, fn supabase.Function, projectRef string) (bool, error) {
+	tempDir, err := client.DownloadFunctionToTemp(fn.Name, projectRef)
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	remoteDir := findDownloadedFunctionDir(tempDir, fn.Name)
+	if remoteDir == "" {
+		return false, fmt.Errorf("could not find index.ts in downloaded function")
+	}
+
+	localHash, err := hashFunctionSource(fn.Path)
+	if err != nil {
+		return false, err
+	}
+	remoteHash, err := hashFunctionSource(remoteDir)
+	if err != nil {
+		return false, err
+	}
+	return localHash == remoteHash, nil
+}
+
+// hashFunctionSource hashes the paths and contents of the files under dir,
+// ignoring dotfiles such as .env or editor state that are never deployed.
+func hashFunctionSource(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		h.Write([]byte{0})
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", dir, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// printDeployPlan renders the plan grouped by resource kind, Terraform style.
+func printDeployPlan(info *supabase.BranchInfo, plan *deployPlan) {
+	ui.Header("Deploy Plan")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+	if info.IsOverride {
+		ui.Infof("Override: using %s instead of %s", ui.Cyan(info.SupabaseBranch.Name), ui.Cyan(info.OverrideFrom))
+	}
+	if info.IsFallback {
+		ui.Warningf("Using fallback target branch: %s", info.SupabaseBranch.GitBranch)
+	}
+
+	for _, w := range plan.Warnings {
+		ui.Warning(w)
+	}
+
+	sections := []struct {
+		kind  string
+		title string
+	}{
+		{planMigration, "Migrations"},
+		{planSecret, "Secrets"},
+		{planFunction, "Edge Functions"},
+	}
+	for _, section := range sections {
+		var items []planItem
+		for _, item := range plan.Items {
+			if item.Kind == section.kind {
+				items = append(items, item)
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			return planActionOrder(items[i].Action) < planActionOrder(items[j].Action)
+		})
+
+		ui.NewLine()
+		ui.SubHeader(section.title)
+		for _, item := range items {
+			line := fmt.Sprintf("  %s %s", planActionSymbol(item.Action), item.Name)
+			if item.Reason != "" {
+				line += ui.Dim(fmt.Sprintf("  (%s)", item.Reason))
+			}
+			fmt.Println(line)
+		}
+	}
+
+	ui.NewLine()
+	if !plan.HasChanges() {
+		ui.Success("No changes. The environment matches your local project.")
+		return
+	}
+	fmt.Printf("Plan: %s to add, %s to change, %s to destroy.\n",
+		ui.Green(fmt.Sprintf("%d", plan.count(planAdd))),
+		ui.Yellow(fmt.Sprintf("%d", plan.count(planChange))),
+		ui.Red(fmt.Sprintf("%d", plan.count(planDestroy))))
+}
+
+func planActionSymbol(action planAction) string {
+	switch action {
+	case planAdd:
+		return ui.Green("+")
+	case planChange:
+		return ui.Yellow("~")
+	case planDestroy:
+		return ui.Red("-")
+	default:
+		return ui.Dim("#")
+	}
+}
+
+func planActionOrder(action planAction) int {
+	switch action {
+	case planAdd:
+		return 0
+	case planChange:
+		return 1
+	case planDestroy:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// applyDeployPlan executes exactly the planned changes: migrations first,
+// then secrets, then function deploys, then deletions.
+func applyDeployPlan(cfg *config.Config, info *supabase.BranchInfo, plan *deployPlan) error {
+	client := supabase.NewClient()
+
+	if migrations := plan.names(planMigration, planAdd); len(migrations) > 0 {
+		if err := pushPendingMigrations(cfg, info, migrations); err != nil {
+			return err
+		}
+		ui.NewLine()
+	}
+
+	if len(plan.secrets) > 0 {
+		sp := ui.NewSpinner(fmt.Sprintf("Setting %d secret(s)", len(plan.secrets)))
+		sp.Start()
+		report := client.ApplySecrets(info.ProjectRef, plan.secrets)
+		if err := finishSecretsSpinner(sp, report); err != nil {
+			return err
+		}
+	}
+
+	var layout *functionsLayout
+	if deploys := plan.names(planFunction, planAdd, planChange); len(deploys) > 0 {
+		var err error
+		if layout, err = loadFunctionsLayout(cfg, deployImportMap); err != nil {
+			return err
+		}
+	}
+	for _, name := range plan.names(planFunction, planAdd, planChange) {
+		sp := ui.NewSpinner(fmt.Sprintf("Deploying %s", name))
+		sp.Start()
+		opts := functionDeployOptions(cfg, layout, name)
+		if err := client.DeployFunctionWithOptions(name, info.ProjectRef, opts); err != nil {
+			sp.Fail(fmt.Sprintf("Failed to deploy %s", name))
+			return err
+		}
+		sp.Success(fmt.Sprintf("Deployed %s%s", name, describeDeployOptions(opts)))
+	}
+
+	for _, name := range plan.names(planFunction, planDestroy) {
+		sp := ui.NewSpinner(fmt.Sprintf("Deleting %s", name))
+		sp.Start()
+		if err := client.DeleteFunction(name, info.ProjectRef); err != nil {
+			sp.Fail(fmt.Sprintf("Failed to delete %s", name))
+			return err
+		}
+		sp.Success(fmt.Sprintf("Deleted %s", name))
+	}
+
+	return nil
+}
+
+// deployManifest records the last plan applied to a branch.
+type deployManifest struct {
+	AppliedAt time.Time  `json:"applied_at"`
+	Actor     string     `json:"actor"`
+	Target    envState   `json:"target"`
+	Items     []planItem `json:"items"`
+	// Aliases maps renamed functions to their current names, as recorded
+	// by 'drift functions rename'.
+	Aliases map[string]string `json:"aliases,omitempty"`
+}
+
+// deployManifestPath returns where the manifest for a Supabase branch is kept.
+func deployManifestPath(cfg *config.Config, supabaseBranch string) string {
+	return state.Path(cfg.ProjectRoot(), state.Manifests, state.BranchKey(supabaseBranch)+".json")
+}
+
+// saveDeployManifest writes the applied changes of plan under .drift/manifests.
+func saveDeployManifest(cfg *config.Config, plan *deployPlan, appliedAt time.Time) error {
+	path := deployManifestPath(cfg, plan.Target.SupabaseBranch)
+	var previous deployManifest
+	if _, err := state.ReadJSON(path, &previous); err != nil {
+		return err
+	}
+	manifest := deployManifest{
+		AppliedAt: appliedAt,
+		Actor:     currentActor(),
+		Target:    plan.Target,
+		Items:     []planItem{},
+		Aliases:   previous.Aliases,
+	}
+	for _, item := range plan.Items {
+		if item.Action != planSkip {
+			manifest.Items = append(manifest.Items, item)
+		}
+	}
+	return state.WriteJSON(cfg.ProjectRoot(), path, manifest)
+}
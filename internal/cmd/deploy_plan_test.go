@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFunctionFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestHashFunctionSource(t *testing.T) {
+	base := map[string]string{"index.ts": "serve()", "lib/util.ts": "export {}"}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		same  bool
+	}{
+		{"identical", map[string]string{"index.ts": "serve()", "lib/util.ts": "export {}"}, true},
+		{"dotfiles ignored", map[string]string{"index.ts": "serve()", "lib/util.ts": "export {}", ".env": "KEY=1", ".vscode/settings.json": "{}"}, true},
+		{"content differs", map[string]string{"index.ts": "serve(handler)", "lib/util.ts": "export {}"}, false},
+		{"file renamed", map[string]string{"index.ts": "serve()", "lib/utils.ts": "export {}"}, false},
+		{"file missing", map[string]string{"index.ts": "serve()"}, false},
+	}
+
+	want, err := hashFunctionSource(writeFunctionFiles(t, base))
+	if err != nil {
+		t.Fatalf("hashFunctionSource() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hashFunctionSource(writeFunctionFiles(t, tt.files))
+			if err != nil {
+				t.Fatalf("hashFunctionSource() error = %v", err)
+			}
+			if (got == want) != tt.same {
+				t.Errorf("hash equal = %v, want %v", got == want, tt.same)
+			}
+		})
+	}
+}
+
+func TestDeployPlanCounts(t *testing.T) {
+	plan := &deployPlan{}
+	if plan.HasChanges() {
+		t.Error("empty plan HasChanges() = true")
+	}
+
+	plan.add(planMigration, "20260101000000_init.sql", planAdd, "pending")
+	plan.add(planSecret, "STRIPE_KEY", planChange, "value differs")
+	plan.add(planFunction, "send-email", planAdd, "not deployed")
+	plan.add(planFunction, "webhook", planChange, "source differs from deployed")
+	plan.add(planFunction, "old-cron", planSkip, "deployed only")
+
+	if !plan.HasChanges() {
+		t.Error("HasChanges() = false, want true")
+	}
+	if got := plan.count(planAdd); got != 2 {
+		t.Errorf("count(add) = %d, want 2", got)
+	}
+	if got := plan.count(planDestroy); got != 0 {
+		t.Errorf("count(destroy) = %d, want 0", got)
+	}
+	got := plan.names(planFunction, planAdd, planChange)
+	if len(got) != 2 || got[0] != "send-email" || got[1] != "webhook" {
+		t.Errorf("names(function, add, change) = %v", got)
+	}
+
+	skipOnly := &deployPlan{}
+	skipOnly.add(planFunction, "old-cron", planSkip, "deployed only")
+	if skipOnly.HasChanges() {
+		t.Error("plan with only skips HasChanges() = true")
+	}
+}
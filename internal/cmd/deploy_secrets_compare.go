@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+// secretsMatrix is which secrets are set on each of several branches. The
+// first branch is the target the others are compared against.
+type secretsMatrix struct {
+	Branches []string           `json:"branches"`
+	Secrets  []secretsMatrixRow `json:"secrets"`
+	Missing  []string           `json:"missing_on_target"`
+	Errors   map[string]string  `json:"errors,omitempty"`
+}
+
+// secretsMatrixRow is one secret and the branches it is set on.
+type secretsMatrixRow struct {
+	Name    string          `json:"name"`
+	Present map[string]bool `json:"present"`
+}
+
+// buildSecretsMatrix combines the secret names listed for each branch.
+// Branches missing from names could not be listed and are left out of
+// the comparison. Rows missing on the target come first.
+func buildSecretsMatrix(branches []string, names map[string][]string, failed map[string]string) secretsMatrix {
+	m := secretsMatrix{Branches: branches, Secrets: []secretsMatrixRow{}, Missing: []string{}, Errors: failed}
+
+	all := make(map[string]bool)
+	for _, list := range names {
+		for _, n := range list {
+			all[n] = true
+		}
+	}
+	sets := make(map[string]map[string]bool, len(names))
+	for branch, list := range names {
+		sets[branch] = make(map[string]bool, len(list))
+		for _, n := range list {
+			sets[branch][n] = true
+		}
+	}
+
+	target := branches[0]
+	_, targetListed := sets[target]
+	for name := range all {
+		row := secretsMatrixRow{Name: name, Present: make(map[string]bool)}
+		for _, b := range branches {
+			if set, ok := sets[b]; ok {
+				row.Present[b] = set[name]
+			}
+		}
+		if targetListed && !row.Present[target] {
+			m.Missing = append(m.Missing, name)
+		}
+		m.Secrets = append(m.Secrets, row)
+	}
+
+	sort.Strings(m.Missing)
+	sort.Slice(m.Secrets, func(i, j int) bool {
+		a, b := m.Secrets[i], m.Secrets[j]
+		if targetListed && a.Present[target] != b.Present[target] {
+			return !a.Present[target]
+		}
+		return a.Name < b.Name
+	})
+	return m
+}
+
+// runDeployCompareSecrets lists secrets on the target and each --compare
+// branch in parallel and prints which branches each secret is set on.
+func runDeployCompareSecrets(ctx *Context) error {
+	target, err := ctx.Target(deployBranchFlag)
+	if err != nil {
+		return err
+	}
+	infos := []*supabase.BranchInfo{target}
+	seen := map[string]bool{target.ProjectRef: true}
+	for _, name := range deployCompareFlag {
+		info, err := resolveNamedTarget(ctx, name)
+		if err != nil {
+			return err
+		}
+		if seen[info.ProjectRef] {
+			continue
+		}
+		seen[info.ProjectRef] = true
+		infos = append(infos, info)
+	}
+	if len(infos) < 2 {
+		return errs.Validationf("--compare resolved to %s itself; name a different branch", target.SupabaseBranch.Name)
+	}
+
+	branches := make([]string, len(infos))
+	for i, info := range infos {
+		branches[i] = info.SupabaseBranch.Name
+	}
+
+	var sp *ui.Spinner
+	if !ctx.JSON() {
+		sp = ui.NewSpinner(fmt.Sprintf("Fetching secrets from %d branches", len(infos)))
+		sp.Start()
+	}
+	client := ctx.Client()
+	lists := make([][]string, len(infos))
+	listErrs := make([]error, len(infos))
+	var wg sync.WaitGroup
+	for i := range infos {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lists[i], listErrs[i] = client.ListSecrets(infos[i].ProjectRef)
+		}(i)
+	}
+	wg.Wait()
+	if sp != nil {
+		sp.Stop()
+	}
+
+	if listErrs[0] != nil {
+		return fmt.Errorf("failed to list secrets on %s: %w", branches[0], listErrs[0])
+	}
+	names := make(map[string][]string)
+	failed := make(map[string]string)
+	for i, b := range branches {
+		if listErrs[i] != nil {
+			failed[b] = listErrs[i].Error()
+			continue
+		}
+		names[b] = lists[i]
+	}
+	if len(failed) == 0 {
+		failed = nil
+	}
+	matrix := buildSecretsMatrix(branches, names, failed)
+
+	if ctx.JSON() {
+		return ctx.PrintJSON(matrix)
+	}
+	printSecretsMatrix(matrix, infos)
+	return nil
+}
+
+// resolveNamedTarget resolves a branch named on the command line, where
+// prod and dev mean the production and development branches.
+func resolveNamedTarget(ctx *Context, name string) (*supabase.BranchInfo, error) {
+	switch name = normalizeMatrixTarget(name); name {
+	case "production", "development":
+		gitBranch, _ := ctx.GitBranch()
+		return resolveSchemeVariantTarget(ctx.Client(), ctx.Config(), gitBranch, name)
+	}
+	return ctx.Target(name)
+}
+
+// printSecretsMatrix prints the presence matrix, with secrets missing on
+// the target in red.
+func printSecretsMatrix(m secretsMatrix, infos []*supabase.BranchInfo) {
+	ui.Header("Secrets")
+	for i, info := range infos {
+		label := "Compare"
+		if i == 0 {
+			label = "Target"
+		}
+		ui.KeyValue(label, fmt.Sprintf("%s (%s)", ui.Cyan(m.Branches[i]), envColorString(string(info.Environment))))
+	}
+	for _, b := range m.Branches {
+		if msg, ok := m.Errors[b]; ok {
+			ui.Warningf("Could not list secrets on %s: %s", b, msg)
+		}
+	}
+	ui.NewLine()
+
+	if len(m.Secrets) == 0 {
+		ui.Info("No secrets configured on any branch")
+		return
+	}
+
+	table := ui.NewTable(append([]string{"Secret"}, m.Branches...))
+	target := m.Branches[0]
+	for _, row := range m.Secrets {
+		cells := []string{row.Name}
+		colors := []tablewriter.Colors{{}}
+		for _, b := range m.Branches {
+			present, listed := row.Present[b]
+			switch {
+			case !listed:
+				cells = append(cells, "?")
+				colors = append(colors, ui.TableColor.Normal)
+			case present:
+				cells = append(cells, "✓")
+				colors = append(colors, ui.TableColor.Green)
+			case b == target:
+				cells = append(cells, "missing")
+				colors = append(colors, ui.TableColor.Red)
+			default:
+				cells = append(cells, "-")
+				colors = append(colors, ui.TableColor.Yellow)
+			}
+		}
+		if !row.Present[target] {
+			colors[0] = ui.TableColor.Red
+		}
+		table.AddColoredRow(cells, colors)
+	}
+	table.Render()
+	ui.NewLine()
+
+	if len(m.Missing) == 0 {
+		ui.Successf("%s has every secret set on %s", target, strings.Join(m.Branches[1:], ", "))
+		return
+	}
+	ui.Warningf("%d secret(s) missing on %s: %s", len(m.Missing), target, strings.Join(m.Missing, ", "))
+	ui.Infof("Copy them with: drift secrets copy %s %s", infos[1].SupabaseBranch.GitBranch, infos[0].SupabaseBranch.GitBranch)
+}
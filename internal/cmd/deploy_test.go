@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+)
+
+func TestFunctionDeployOptions(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".drift.yaml")
+	content := `
+supabase:
+  functions:
+    overrides:
+      stripe-webhook:
+        verify_jwt: false
+        import_map: supabase/functions/stripe-webhook/deno.json
+      admin-report:
+        verify_jwt: true
+      absolute-map:
+        import_map: /opt/maps/import_map.json
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	saved := deployNoVerifyJWT
+	t.Cleanup(func() { deployNoVerifyJWT = saved })
+
+	tests := []struct {
+		name          string
+		flag          bool
+		function      string
+		wantNoVerify  bool
+		wantImportMap string
+	}{
+		{"default verifies", false, "send-email", false, ""},
+		{"flag disables verification", true, "send-email", true, ""},
+		{"override disables verification", false, "stripe-webhook", true, filepath.Join(dir, "supabase/functions/stripe-webhook/deno.json")},
+		{"override keeps verification despite flag", true, "admin-report", false, ""},
+		{"absolute import map", false, "absolute-map", false, "/opt/maps/import_map.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployNoVerifyJWT = tt.flag
+			opts := functionDeployOptions(cfg, nil, tt.function)
+			if opts.NoVerifyJWT != tt.wantNoVerify {
+				t.Errorf("NoVerifyJWT = %v, want %v", opts.NoVerifyJWT, tt.wantNoVerify)
+			}
+			if opts.ImportMap != tt.wantImportMap {
+				t.Errorf("ImportMap = %q, want %q", opts.ImportMap, tt.wantImportMap)
+			}
+		})
+	}
+}
+
+func TestBuildSecretsMatrix(t *testing.T) {
+	m := buildSecretsMatrix(
+		[]string{"feat-x", "develop", "main"},
+		map[string][]string{
+			"feat-x":  {"OPENAI_KEY"},
+			"develop": {"OPENAI_KEY", "STRIPE_KEY", "APNS_KEY"},
+		},
+		map[string]string{"main": "unauthorized"},
+	)
+
+	var order []string
+	for _, row := range m.Secrets {
+		order = append(order, row.Name)
+	}
+	if want := "APNS_KEY,STRIPE_KEY,OPENAI_KEY"; strings.Join(order, ",") != want {
+		t.Errorf("rows = %v, want missing-on-target first: %s", order, want)
+	}
+	if want := "APNS_KEY,STRIPE_KEY"; strings.Join(m.Missing, ",") != want {
+		t.Errorf("Missing = %v, want %s", m.Missing, want)
+	}
+	stripe := m.Secrets[1].Present
+	if stripe["feat-x"] || !stripe["develop"] {
+		t.Errorf("STRIPE_KEY present = %v", stripe)
+	}
+	if _, listed := stripe["main"]; listed {
+		t.Error("a branch that failed to list reported presence")
+	}
+}
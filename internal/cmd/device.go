@@ -47,11 +47,23 @@ This sets up:
   2. Port forwarding (localhost:8100 -> device:8100)
   3. WebDriverAgent build and launch
 
+With --all, WDA is started on every connected device at once. Each device
+gets its own local port (8100, 8101, ...), tracked in ~/.drift/devices.json
+so a device keeps its port across restarts. Output goes to per-device logs
+in ~/.drift/wda/logs.
+
+When device.wda_prebuilt points at a prebuilt WebDriverAgentRunner .ipa (path
+or URL), drift downloads it once, resigns it with apple.team_id, and installs
+it with go-ios instead of building from source. Downloads and resigned
+artifacts are cached in ~/.drift/wda. Use --rebuild to build from source.
+
 Examples:
   drift device start                     # Interactive device picker
   drift device start "Test dummy"        # Start on named device
   drift device start 00008120-xxx        # Start on device by UDID
-  drift device start --quick             # Skip rebuild if WDA running`,
+  drift device start --quick             # Skip rebuild if WDA running
+  drift device start --rebuild           # Build from source, ignore prebuilt
+  drift device start --all               # Every connected device, ports 8100, 8101, ...`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runDeviceStart,
 }
@@ -82,7 +94,8 @@ Examples:
   drift device build                          # Interactive picker
   drift device build "Test dummy"             # Build to named device
   drift device build --scheme "App (Debug)"   # Use specific scheme
-  drift device build --run                    # Build, install, and run`,
+  drift device build --run                    # Build, install, and run
+  drift device build --devices "iPhone 15,iPad"  # Build once, install on both`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runDeviceBuild,
 }
@@ -113,10 +126,17 @@ var (
 	deviceSchemeFlag    string
 	deviceRunFlag       bool
 	deviceSimulatorFlag string
+	deviceRebuildFlag   bool
+	deviceAllFlag       bool
+	deviceDevicesFlag   []string
 )
 
 func init() {
 	deviceStartCmd.Flags().BoolVarP(&deviceQuickFlag, "quick", "q", false, "Skip rebuild if WDA is already running")
+	deviceStartCmd.Flags().BoolVar(&deviceRebuildFlag, "rebuild", false, "Build WDA from source even when device.wda_prebuilt is set")
+	deviceStartCmd.Flags().BoolVar(&deviceAllFlag, "all", false, "Start WDA on every connected device, each on its own port")
+	deviceBuildCmd.Flags().StringSliceVar(&deviceDevicesFlag, "devices", nil, "Build once and install on several devices (comma-separated names or UDIDs)")
+	deviceRunCmd.Flags().StringSliceVar(&deviceDevicesFlag, "devices", nil, "Build once, install, and run on several devices (comma-separated names or UDIDs)")
 	deviceBuildCmd.Flags().StringVarP(&deviceSchemeFlag, "scheme", "s", "", "Xcode scheme to build")
 	deviceBuildCmd.Flags().BoolVarP(&deviceRunFlag, "run", "r", false, "Run app after installing")
 	deviceBuildCmd.Flags().StringVar(&deviceSimulatorFlag, "simulator", "", "Build for simulator (use device name or 'default' for iPhone 16 Pro)")
@@ -352,6 +372,14 @@ func runDeviceStart(cmd *cobra.Command, args []string) error {
 		wdaPort = 8100
 	}
 
+	if deviceAllFlag {
+		devices, err := getConnectedDevices(cfg)
+		if err != nil {
+			return err
+		}
+		return runDeviceStartAll(cfg, devices)
+	}
+
 	// Quick mode: check if WDA is already running
 	if deviceQuickFlag && checkWDAStatus(wdaPort) {
 		ui.Success(fmt.Sprintf("WDA is already running at http://localhost:%d", wdaPort))
@@ -395,13 +423,65 @@ func runDeviceStart(cmd *cobra.Command, args []string) error {
 	// Step 2: Start port forwarding
 	sp = ui.NewSpinner("Setting up port forwarding...")
 	sp.Start()
-	if err := startPortForwarding(device.UDID, wdaPort); err != nil {
+	if err := startPortForwarding(device.UDID, wdaPort, wdaPort); err != nil {
 		sp.Fail("Failed to start port forwarding")
 		return err
 	}
 	sp.Success("Port forwarding ready")
 
-	// Step 3: Build and run WDA
+	// Step 3: Install a prebuilt WDA, or build it from source
+	var wdaCmd *exec.Cmd
+	if cfg.Device.WDAPrebuilt != "" && !deviceRebuildFlag {
+		wdaCmd, err = startPrebuiltWDA(cfg, device)
+	} else {
+		wdaCmd, err = startWDAFromSource(cfg, device)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Wait for WDA to respond
+	ui.NewLine()
+	sp = ui.NewSpinner("Waiting for WebDriverAgent to respond...")
+	sp.Start()
+
+	for i := 0; i < 90; i++ { // 3 minutes timeout
+		if checkWDAStatus(wdaPort) {
+			sp.Success("WebDriverAgent is ready!")
+			break
+		}
+		time.Sleep(2 * time.Second)
+	}
+
+	if !checkWDAStatus(wdaPort) {
+		sp.Fail("Timeout waiting for WebDriverAgent")
+		ui.NewLine()
+		ui.Warning("WDA may still be building. Check the output above.")
+		ui.Info("Common issues:")
+		ui.List("Developer certificate not trusted on device")
+		ui.List("Device is locked")
+		ui.List("Provisioning profile issues")
+		return nil
+	}
+
+	ui.NewLine()
+	ui.Success(fmt.Sprintf("WDA ready at http://localhost:%d", wdaPort))
+	ui.NewLine()
+	ui.SubHeader("Quick Commands")
+	ui.List(fmt.Sprintf("Test:   curl http://localhost:%d/status", wdaPort))
+	ui.List("Stop:   drift device stop")
+	ui.List("Status: drift device status")
+	ui.NewLine()
+	ui.Info("Press Ctrl+C to stop WDA")
+
+	// Wait for xcodebuild to finish (or be killed)
+	wdaCmd.Wait()
+
+	return nil
+}
+
+// startWDAFromSource clones WebDriverAgent if needed and runs it on the device with xcodebuild.
+func startWDAFromSource(cfg *config.Config, device *ConnectedDevice) (*exec.Cmd, error) {
 	wdaPath := cfg.Device.WDAPath
 	if wdaPath == "" {
 		wdaPath = "/tmp/WebDriverAgent"
@@ -410,12 +490,12 @@ func runDeviceStart(cmd *cobra.Command, args []string) error {
 
 	// Clone WDA if needed
 	if _, err := os.Stat(wdaPath); os.IsNotExist(err) {
-		sp = ui.NewSpinner("Cloning WebDriverAgent...")
+		sp := ui.NewSpinner("Cloning WebDriverAgent...")
 		sp.Start()
 		result, err := shell.Run("git", "clone", "https://github.com/appium/WebDriverAgent.git", wdaPath)
 		if err != nil {
 			sp.Fail("Failed to clone WebDriverAgent")
-			return fmt.Errorf("git clone failed: %s", result.Stderr)
+			return nil, fmt.Errorf("git clone failed: %s", result.Stderr)
 		}
 		sp.Success("WebDriverAgent cloned")
 	}
@@ -448,47 +528,9 @@ func runDeviceStart(cmd *cobra.Command, args []string) error {
 	wdaCmd.Stderr = os.Stderr
 
 	if err := wdaCmd.Start(); err != nil {
-		return fmt.Errorf("failed to start xcodebuild: %w", err)
-	}
-
-	// Wait for WDA to respond
-	ui.NewLine()
-	sp = ui.NewSpinner("Waiting for WebDriverAgent to respond...")
-	sp.Start()
-
-	for i := 0; i < 90; i++ { // 3 minutes timeout
-		if checkWDAStatus(wdaPort) {
-			sp.Success("WebDriverAgent is ready!")
-			break
-		}
-		time.Sleep(2 * time.Second)
-	}
-
-	if !checkWDAStatus(wdaPort) {
-		sp.Fail("Timeout waiting for WebDriverAgent")
-		ui.NewLine()
-		ui.Warning("WDA may still be building. Check the output above.")
-		ui.Info("Common issues:")
-		ui.List("Developer certificate not trusted on device")
-		ui.List("Device is locked")
-		ui.List("Provisioning profile issues")
-		return nil
+		return nil, fmt.Errorf("failed to start xcodebuild: %w", err)
 	}
-
-	ui.NewLine()
-	ui.Success(fmt.Sprintf("WDA ready at http://localhost:%d", wdaPort))
-	ui.NewLine()
-	ui.SubHeader("Quick Commands")
-	ui.List(fmt.Sprintf("Test:   curl http://localhost:%d/status", wdaPort))
-	ui.List("Stop:   drift device stop")
-	ui.List("Status: drift device status")
-	ui.NewLine()
-	ui.Info("Press Ctrl+C to stop WDA")
-
-	// Wait for xcodebuild to finish (or be killed)
-	wdaCmd.Wait()
-
-	return nil
+	return wdaCmd, nil
 }
 
 func runDeviceStop(cmd *cobra.Command, args []string) error {
@@ -496,7 +538,8 @@ func runDeviceStop(cmd *cobra.Command, args []string) error {
 
 	// Kill xcodebuild WDA
 	shell.Run("pkill", "-f", "xcodebuild.*WebDriverAgent")
-	ui.Success("Stopped WebDriverAgent build")
+	shell.Run("pkill", "-f", "ios runwda")
+	ui.Success("Stopped WebDriverAgent")
 
 	// Kill port forwarding
 	shell.Run("pkill", "-f", "ios forward")
@@ -506,6 +549,14 @@ func runDeviceStop(cmd *cobra.Command, args []string) error {
 	shell.Run("pkill", "-f", "ios tunnel")
 	ui.Success("Stopped iOS tunnel")
 
+	// Forget per-device port assignments
+	if sessions, err := loadDeviceSessions(); err == nil && len(sessions.Sessions) > 0 {
+		sessions.Sessions = nil
+		if err := sessions.Save(); err != nil {
+			ui.Warning(fmt.Sprintf("Could not clear device sessions: %v", err))
+		}
+	}
+
 	ui.NewLine()
 	ui.Success("All device processes stopped")
 
@@ -553,6 +604,19 @@ func runDeviceStatus(cmd *cobra.Command, args []string) error {
 		ui.KeyValue("Forward", ui.Red("NOT RUNNING"))
 	}
 
+	// Per-device sessions from 'drift device start --all'
+	if sessions, err := loadDeviceSessions(); err == nil && len(sessions.Sessions) > 0 {
+		ui.NewLine()
+		ui.SubHeader("WDA Sessions")
+		for _, session := range sessions.Sessions {
+			state := ui.Red("NOT RUNNING")
+			if checkWDAStatus(session.Port) {
+				state = ui.Green("RUNNING")
+			}
+			fmt.Printf("  %-24s http://localhost:%-6d %s\n", ui.Cyan(session.Name), session.Port, state)
+		}
+	}
+
 	ui.NewLine()
 
 	// Connected devices
@@ -593,11 +657,14 @@ func runDeviceBuild(cmd *cobra.Command, args []string) error {
 
 	ui.Header("Build to Device")
 
-	// Select device
+	// Select device(s)
 	var device *ConnectedDevice
+	var devices []ConnectedDevice
 	var err error
 
-	if len(args) > 0 {
+	if names := splitDeviceList(deviceDevicesFlag); len(names) > 0 {
+		devices, err = resolveDevices(cfg, names)
+	} else if len(args) > 0 {
 		device, err = findDeviceByNameOrUDID(cfg, args[0])
 	} else {
 		device, err = selectDevice(cfg, "Select device to build to")
@@ -608,8 +675,16 @@ func runDeviceBuild(cmd *cobra.Command, args []string) error {
 	}
 
 	ui.NewLine()
-	ui.KeyValue("Device", ui.Cyan(device.Name))
-	ui.KeyValue("UDID", device.UDID)
+	if device != nil {
+		ui.KeyValue("Device", ui.Cyan(device.Name))
+		ui.KeyValue("UDID", device.UDID)
+	} else {
+		names := make([]string, len(devices))
+		for i, d := range devices {
+			names[i] = d.Name
+		}
+		ui.KeyValue("Devices", ui.Cyan(strings.Join(names, ", ")))
+	}
 
 	// Find Xcode project/workspace
 	projectRoot := cfg.ProjectRoot()
@@ -673,6 +748,10 @@ func runDeviceBuild(cmd *cobra.Command, args []string) error {
 
 	ui.NewLine()
 
+	if len(devices) > 0 {
+		return runDeviceBuildMulti(cfg, devices, xcodeFile, xcodeType, scheme)
+	}
+
 	// Build
 	buildArgs := []string{
 		fmt.Sprintf("-%s", xcodeType), xcodeFile,
@@ -797,13 +876,14 @@ func ensureIOSTunnel() error {
 	return nil
 }
 
-func startPortForwarding(udid string, port int) error {
+// startPortForwarding forwards localhost:hostPort to devicePort on the device.
+func startPortForwarding(udid string, hostPort, devicePort int) error {
 	// Kill existing
-	shell.Run("pkill", "-f", fmt.Sprintf("ios forward %d", port))
+	shell.Run("pkill", "-f", fmt.Sprintf("ios forward %d ", hostPort))
 	time.Sleep(1 * time.Second)
 
 	// Start forwarding in background
-	cmd := exec.Command("ios", "forward", fmt.Sprintf("%d", port), fmt.Sprintf("%d", port), fmt.Sprintf("--udid=%s", udid))
+	cmd := exec.Command("ios", "forward", fmt.Sprintf("%d", hostPort), fmt.Sprintf("%d", devicePort), fmt.Sprintf("--udid=%s", udid))
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	if err := cmd.Start(); err != nil {
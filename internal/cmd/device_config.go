@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var deviceAddCmd = &cobra.Command{
+	Use:   "add [device]",
+	Short: "Register a connected device in .drift.yaml",
+	Long: `Add a connected device to device.devices in .drift.yaml, filling in its
+UDID, model, and iOS version. Re-adding a device updates its entry.
+
+If no device is specified, shows an interactive picker of connected devices.`,
+	Example: `  drift device add
+  drift device add 00008120-xxx --name "Test dummy" --primary`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDeviceAdd,
+}
+
+var deviceRemoveCmd = &cobra.Command{
+	Use:     "remove [name|udid]",
+	Aliases: []string{"rm"},
+	Short:   "Remove a device from .drift.yaml",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    runDeviceRemove,
+}
+
+var (
+	deviceAddNameFlag    string
+	deviceAddPrimaryFlag bool
+	deviceAddNotesFlag   string
+)
+
+func init() {
+	deviceAddCmd.Flags().StringVar(&deviceAddNameFlag, "name", "", "Name to register the device under (default: device name)")
+	deviceAddCmd.Flags().BoolVar(&deviceAddPrimaryFlag, "primary", false, "Mark as the primary device")
+	deviceAddCmd.Flags().StringVar(&deviceAddNotesFlag, "notes", "", "Notes about the device")
+
+	deviceCmd.AddCommand(deviceAddCmd)
+	deviceCmd.AddCommand(deviceRemoveCmd)
+}
+
+func runDeviceAdd(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	ui.Header("Add Device")
+
+	var device *ConnectedDevice
+	var err error
+	if len(args) > 0 {
+		device, err = findDeviceByNameOrUDID(cfg, args[0])
+	} else {
+		device, err = selectDevice(cfg, "Select device to add")
+	}
+	if err != nil {
+		return err
+	}
+
+	name := deviceAddNameFlag
+	if name == "" {
+		defaultName := device.Name
+		if device.ConfigName != "" {
+			defaultName = device.ConfigName
+		}
+		name, err = ui.PromptString("Device name", defaultName)
+		if err != nil {
+			return err
+		}
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("device name is required")
+	}
+
+	primary := deviceAddPrimaryFlag
+	if !primary && !cmd.Flags().Changed("primary") {
+		if existing := cfg.GetDeviceByUDID(device.UDID); existing != nil {
+			primary = existing.Primary
+		} else if len(cfg.Device.Devices) == 0 {
+			primary = true
+		} else {
+			primary, err = ui.PromptYesNo("Make this the primary device?", false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	entry := config.DeviceEntry{
+		Name:    name,
+		UDID:    device.UDID,
+		Model:   device.Model,
+		OS:      device.OS,
+		Primary: primary,
+		Notes:   deviceAddNotesFlag,
+	}
+
+	path := cfg.ConfigPath()
+	var updated bool
+	err = config.UpdateYAML(path, false, func(doc map[string]interface{}) error {
+		updated = upsertDeviceEntry(doc, entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+
+	ui.NewLine()
+	ui.KeyValue("Name", ui.Cyan(entry.Name))
+	ui.KeyValue("UDID", entry.UDID)
+	if entry.Model != "" {
+		ui.KeyValue("Model", entry.Model)
+	}
+	if entry.OS != "" {
+		ui.KeyValue("iOS", entry.OS)
+	}
+	if entry.Primary {
+		ui.KeyValue("Primary", ui.Green("yes"))
+	}
+	ui.NewLine()
+	if updated {
+		ui.Successf("Updated %s in .drift.yaml", entry.Name)
+	} else {
+		ui.Successf("Added %s to .drift.yaml", entry.Name)
+	}
+	return nil
+}
+
+func runDeviceRemove(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	if len(cfg.Device.Devices) == 0 {
+		ui.Info("No devices configured")
+		return nil
+	}
+
+	query := ""
+	if len(args) > 0 {
+		query = args[0]
+	} else {
+		options := make([]string, len(cfg.Device.Devices))
+		for i, d := range cfg.Device.Devices {
+			options[i] = fmt.Sprintf("%s (%s)", d.Name, d.UDID)
+		}
+		idx, _, err := ui.PromptSelectWithIndex("Select device to remove", options)
+		if err != nil {
+			return err
+		}
+		query = cfg.Device.Devices[idx].UDID
+	}
+
+	path := cfg.ConfigPath()
+	var removed string
+	err := config.UpdateYAML(path, false, func(doc map[string]interface{}) error {
+		var ok bool
+		if removed, ok = removeDeviceEntry(doc, query); !ok {
+			return fmt.Errorf("no configured device matches '%s' (see 'drift device list')", query)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ui.Successf("Removed %s from .drift.yaml", removed)
+	return nil
+}
+
+// upsertDeviceEntry adds entry to device.devices, replacing an entry with
+// the same UDID. Notes on an existing entry are kept unless entry sets them.
+// A primary entry clears primary on all others. Reports whether an entry was replaced.
+func upsertDeviceEntry(doc map[string]interface{}, entry config.DeviceEntry) bool {
+	device := ensureChildMap(doc, "device")
+	list, _ := device["devices"].([]interface{})
+
+	replaced := false
+	for i, raw := range list {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if entry.Primary {
+			delete(m, "primary")
+		}
+		if fmt.Sprint(m["udid"]) != entry.UDID {
+			continue
+		}
+		if entry.Notes == "" {
+			if notes, ok := m["notes"].(string); ok {
+				entry.Notes = notes
+			}
+		}
+		list[i] = deviceEntryMap(entry)
+		replaced = true
+	}
+	if !replaced {
+		list = append(list, deviceEntryMap(entry))
+	}
+	device["devices"] = list
+	return replaced
+}
+
+// removeDeviceEntry removes the device.devices entry whose UDID or name
+// (case-insensitive) matches query and returns its name.
+func removeDeviceEntry(doc map[string]interface{}, query string) (string, bool) {
+	device, ok := doc["device"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	list, _ := device["devices"].([]interface{})
+
+	for i, raw := range list {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		udid, _ := m["udid"].(string)
+		if udid == query || strings.EqualFold(name, query) {
+			device["devices"] = append(list[:i], list[i+1:]...)
+			if strings.EqualFold(fmt.Sprint(device["default_device"]), name) {
+				delete(device, "default_device")
+			}
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func deviceEntryMap(entry config.DeviceEntry) map[string]interface{} {
+	m := map[string]interface{}{
+		"name": entry.Name,
+		"udid": entry.UDID,
+	}
+	if entry.Model != "" {
+		m["model"] = entry.Model
+	}
+	if entry.OS != "" {
+		m["os"] = entry.OS
+	}
+	if entry.Primary {
+		m["primary"] = true
+	}
+	if entry.Notes != "" {
+		m["notes"] = entry.Notes
+	}
+	return m
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+)
+
+func TestUpsertDeviceEntry(t *testing.T) {
+	doc := map[string]interface{}{
+		"device": map[string]interface{}{
+			"devices": []interface{}{
+				map[string]interface{}{"name": "Old", "udid": "aaa", "primary": true, "notes": "desk"},
+				map[string]interface{}{"name": "Other", "udid": "bbb"},
+			},
+		},
+	}
+
+	// Updating keeps notes and position.
+	if !upsertDeviceEntry(doc, config.DeviceEntry{Name: "iPhone", UDID: "aaa", OS: "18.1", Primary: true}) {
+		t.Fatal("expected existing entry to be replaced")
+	}
+	list := doc["device"].(map[string]interface{})["devices"].([]interface{})
+	want := map[string]interface{}{"name": "iPhone", "udid": "aaa", "os": "18.1", "primary": true, "notes": "desk"}
+	if !reflect.DeepEqual(list[0], want) {
+		t.Errorf("updated entry = %v, want %v", list[0], want)
+	}
+
+	// A new primary clears the previous one.
+	if upsertDeviceEntry(doc, config.DeviceEntry{Name: "iPad", UDID: "ccc", Primary: true}) {
+		t.Fatal("expected new entry to be appended")
+	}
+	list = doc["device"].(map[string]interface{})["devices"].([]interface{})
+	if len(list) != 3 {
+		t.Fatalf("len(devices) = %d, want 3", len(list))
+	}
+	if _, ok := list[0].(map[string]interface{})["primary"]; ok {
+		t.Error("previous primary flag was not cleared")
+	}
+	if list[2].(map[string]interface{})["primary"] != true {
+		t.Error("new entry is not primary")
+	}
+}
+
+func TestUpsertDeviceEntry_EmptyDoc(t *testing.T) {
+	doc := map[string]interface{}{}
+	upsertDeviceEntry(doc, config.DeviceEntry{Name: "iPhone", UDID: "aaa"})
+
+	list := doc["device"].(map[string]interface{})["devices"].([]interface{})
+	if len(list) != 1 || list[0].(map[string]interface{})["udid"] != "aaa" {
+		t.Errorf("devices = %v", list)
+	}
+}
+
+func TestRemoveDeviceEntry(t *testing.T) {
+	doc := map[string]interface{}{
+		"device": map[string]interface{}{
+			"default_device": "Test dummy",
+			"devices": []interface{}{
+				map[string]interface{}{"name": "Test dummy", "udid": "aaa"},
+				map[string]interface{}{"name": "iPad", "udid": "bbb"},
+			},
+		},
+	}
+
+	if name, ok := removeDeviceEntry(doc, "bbb"); !ok || name != "iPad" {
+		t.Errorf("remove by UDID = %q, %v", name, ok)
+	}
+	if name, ok := removeDeviceEntry(doc, "test DUMMY"); !ok || name != "Test dummy" {
+		t.Errorf("remove by name = %q, %v", name, ok)
+	}
+	device := doc["device"].(map[string]interface{})
+	if _, ok := device["default_device"]; ok {
+		t.Error("default_device should be cleared with its entry")
+	}
+	if _, ok := removeDeviceEntry(doc, "missing"); ok {
+		t.Error("expected no match")
+	}
+}
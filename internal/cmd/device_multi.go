@@ -0,0 +1,486 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// DeviceSessionsFilename is the per-user file tracking WDA sessions and their ports.
+const DeviceSessionsFilename = "devices.json"
+
+// DeviceSession is a WebDriverAgent session started by 'drift device start'.
+type DeviceSession struct {
+	UDID      string    `json:"udid"`
+	Name      string    `json:"name"`
+	Port      int       `json:"port"`
+	PID       int       `json:"pid,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// DeviceSessions is the set of tracked sessions (~/.drift/devices.json).
+type DeviceSessions struct {
+	Sessions []DeviceSession `json:"sessions"`
+
+	path string
+}
+
+// loadDeviceSessions reads the session file, returning an empty set if it doesn't exist.
+func loadDeviceSessions() (*DeviceSessions, error) {
+	home, err := config.DriftHome()
+	if err != nil {
+		return nil, err
+	}
+	state := &DeviceSessions{path: filepath.Join(home, DeviceSessionsFilename)}
+
+	data, err := os.ReadFile(state.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read device sessions: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse device sessions: %w", err)
+	}
+	return state, nil
+}
+
+// Save writes the sessions back to disk, ordered by port.
+func (s *DeviceSessions) Save() error {
+	sort.Slice(s.Sessions, func(i, j int) bool { return s.Sessions[i].Port < s.Sessions[j].Port })
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// Allocate returns the session for a device, assigning the lowest free port
+// at or above base if the device has none yet.
+func (s *DeviceSessions) Allocate(udid, name string, base int) *DeviceSession {
+	for i := range s.Sessions {
+		if s.Sessions[i].UDID == udid {
+			s.Sessions[i].Name = name
+			return &s.Sessions[i]
+		}
+	}
+
+	used := make(map[int]bool, len(s.Sessions))
+	for _, session := range s.Sessions {
+		used[session.Port] = true
+	}
+	port := base
+	for used[port] {
+		port++
+	}
+
+	s.Sessions = append(s.Sessions, DeviceSession{UDID: udid, Name: name, Port: port})
+	return &s.Sessions[len(s.Sessions)-1]
+}
+
+// Find returns the session for a device, or nil.
+func (s *DeviceSessions) Find(udid string) *DeviceSession {
+	for i := range s.Sessions {
+		if s.Sessions[i].UDID == udid {
+			return &s.Sessions[i]
+		}
+	}
+	return nil
+}
+
+// splitDeviceList parses a comma-separated --devices value.
+func splitDeviceList(value []string) []string {
+	var names []string
+	for _, v := range value {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// resolveDevices looks up each name or UDID among connected devices.
+func resolveDevices(cfg *config.Config, queries []string) ([]ConnectedDevice, error) {
+	var devices []ConnectedDevice
+	seen := make(map[string]bool)
+	for _, q := range queries {
+		device, err := findDeviceByNameOrUDID(cfg, q)
+		if err != nil {
+			return nil, err
+		}
+		if seen[device.UDID] {
+			continue
+		}
+		seen[device.UDID] = true
+		devices = append(devices, *device)
+	}
+	return devices, nil
+}
+
+// deviceLogPath returns the log file for a device's background WDA/install output.
+func deviceLogPath(udid string) (string, error) {
+	dir, err := wdaCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "logs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, udid+".log"), nil
+}
+
+// deviceResult is the outcome of a per-device parallel step.
+type deviceResult struct {
+	Device ConnectedDevice
+	Port   int
+	Cmd    *exec.Cmd
+	Done   chan struct{} // closed when Cmd exits
+	Err    error
+}
+
+// runDeviceStartAll starts WebDriverAgent on every connected device, each
+// forwarded to its own local port.
+func runDeviceStartAll(cfg *config.Config, devices []ConnectedDevice) error {
+	if len(devices) == 0 {
+		return fmt.Errorf("no devices connected\n\nConnect an iOS device via USB and try again")
+	}
+
+	devicePort := cfg.Device.WDAPort
+	if devicePort == 0 {
+		devicePort = 8100
+	}
+
+	ui.Header("Start WebDriverAgent")
+	ui.KeyValue("Devices", fmt.Sprintf("%d", len(devices)))
+	ui.NewLine()
+
+	sp := ui.NewSpinner("Starting iOS tunnel...")
+	sp.Start()
+	if err := ensureIOSTunnel(); err != nil {
+		sp.Fail("Failed to start iOS tunnel")
+		return err
+	}
+	sp.Success("iOS tunnel ready")
+
+	// Shared, serial preparation: one resign or one build for all devices.
+	var launch func(device ConnectedDevice, out io.Writer) (*exec.Cmd, error)
+	if cfg.Device.WDAPrebuilt != "" && !deviceRebuildFlag {
+		ipa, bundleID, err := preparePrebuiltWDA(cfg)
+		if err != nil {
+			return err
+		}
+		launch = func(device ConnectedDevice, out io.Writer) (*exec.Cmd, error) {
+			return launchPrebuiltWDA(ipa, bundleID, device.UDID, out)
+		}
+	} else {
+		wdaPath, derived, err := buildWDAForTesting(cfg)
+		if err != nil {
+			return err
+		}
+		launch = func(device ConnectedDevice, out io.Writer) (*exec.Cmd, error) {
+			return launchBuiltWDA(wdaPath, derived, device.UDID, out)
+		}
+	}
+
+	sessions, err := loadDeviceSessions()
+	if err != nil {
+		return err
+	}
+	ports := make([]int, len(devices))
+	for i, d := range devices {
+		ports[i] = sessions.Allocate(d.UDID, d.Name, devicePort).Port
+	}
+
+	ui.NewLine()
+	sp = ui.NewSpinner(fmt.Sprintf("Launching WebDriverAgent on %d devices...", len(devices)))
+	sp.Start()
+
+	results := make([]deviceResult, len(devices))
+	var wg sync.WaitGroup
+	for i := range devices {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = startWDAOnDevice(devices[i], ports[i], devicePort, launch)
+		}(i)
+	}
+	wg.Wait()
+	sp.Stop()
+
+	running := 0
+	for _, r := range results {
+		if r.Err != nil {
+			ui.Errorf("%s: %v", r.Device.Name, r.Err)
+			continue
+		}
+		running++
+		session := sessions.Find(r.Device.UDID)
+		session.PID = r.Cmd.Process.Pid
+		session.StartedAt = time.Now()
+		ui.Successf("%s → http://localhost:%d", ui.Cyan(r.Device.Name), r.Port)
+	}
+	if err := sessions.Save(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not save device sessions: %v", err))
+	}
+
+	if running == 0 {
+		return fmt.Errorf("WebDriverAgent did not start on any device")
+	}
+
+	ui.NewLine()
+	if logDir, err := wdaCacheDir(); err == nil {
+		ui.Infof("Logs: %s", filepath.Join(logDir, "logs"))
+	}
+	ui.Info("Press Ctrl+C to stop WDA on all devices")
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	done := make(chan struct{})
+	go func() {
+		for _, r := range results {
+			if r.Done != nil {
+				<-r.Done
+			}
+		}
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		for _, r := range results {
+			if r.Cmd != nil && r.Cmd.Process != nil {
+				_ = r.Cmd.Process.Kill()
+			}
+		}
+	case <-done:
+	}
+	return nil
+}
+
+// startWDAOnDevice forwards hostPort to the device, launches WDA, and waits
+// for it to answer. Output is written to the device's log file.
+func startWDAOnDevice(device ConnectedDevice, hostPort, devicePort int, launch func(ConnectedDevice, io.Writer) (*exec.Cmd, error)) deviceResult {
+	result := deviceResult{Device: device, Port: hostPort}
+
+	if err := startPortForwarding(device.UDID, hostPort, devicePort); err != nil {
+		result.Err = fmt.Errorf("port forwarding failed: %w", err)
+		return result
+	}
+
+	logPath, err := deviceLogPath(device.UDID)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	cmd, err := launch(device, logFile)
+	if err != nil {
+		logFile.Close()
+		result.Err = err
+		return result
+	}
+	result.Cmd = cmd
+	result.Done = make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		logFile.Close()
+		close(result.Done)
+	}()
+
+	for i := 0; i < 90; i++ { // 3 minutes timeout
+		if checkWDAStatus(hostPort) {
+			return result
+		}
+		select {
+		case <-result.Done:
+			result.Err = fmt.Errorf("WebDriverAgent exited (see %s)", logPath)
+			return result
+		case <-time.After(2 * time.Second):
+		}
+	}
+	result.Err = fmt.Errorf("timeout waiting for WebDriverAgent (see %s)", logPath)
+	return result
+}
+
+// buildWDAForTesting builds WebDriverAgentRunner once for any iOS device so
+// it can be launched on several devices with test-without-building.
+func buildWDAForTesting(cfg *config.Config) (string, string, error) {
+	wdaPath := cfg.Device.WDAPath
+	if wdaPath == "" {
+		wdaPath = "/tmp/WebDriverAgent"
+	}
+	derived := filepath.Join(os.Getenv("HOME"), "Library/Developer/Xcode/DerivedData/WDA-Drift")
+
+	if _, err := os.Stat(wdaPath); os.IsNotExist(err) {
+		sp := ui.NewSpinner("Cloning WebDriverAgent...")
+		sp.Start()
+		result, err := shell.Run("git", "clone", "https://github.com/appium/WebDriverAgent.git", wdaPath)
+		if err != nil || result.ExitCode != 0 {
+			sp.Fail("Failed to clone WebDriverAgent")
+			return "", "", fmt.Errorf("git clone failed: %s", result.Stderr)
+		}
+		sp.Success("WebDriverAgent cloned")
+	}
+
+	args := []string{
+		"-project", filepath.Join(wdaPath, "WebDriverAgent.xcodeproj"),
+		"-scheme", "WebDriverAgentRunner",
+		"-destination", "generic/platform=iOS",
+		"-derivedDataPath", derived,
+		"-allowProvisioningUpdates",
+	}
+	if cfg.Apple.TeamID != "" {
+		args = append(args, fmt.Sprintf("DEVELOPMENT_TEAM=%s", cfg.Apple.TeamID))
+	}
+	args = append(args, "build-for-testing")
+
+	ui.NewLine()
+	ui.Info("Building WebDriverAgent once for all devices...")
+	buildCmd := exec.Command("xcodebuild", args...)
+	buildCmd.Dir = wdaPath
+	buildCmd.Stdout = os.Stdout
+	buildCmd.Stderr = os.Stderr
+	if err := buildCmd.Run(); err != nil {
+		return "", "", fmt.Errorf("WebDriverAgent build failed: %w", err)
+	}
+	return wdaPath, derived, nil
+}
+
+// launchBuiltWDA runs a prebuilt-for-testing WebDriverAgentRunner on a device.
+func launchBuiltWDA(wdaPath, derived, udid string, out io.Writer) (*exec.Cmd, error) {
+	cmd := exec.Command("xcodebuild",
+		"-project", filepath.Join(wdaPath, "WebDriverAgent.xcodeproj"),
+		"-scheme", "WebDriverAgentRunner",
+		"-destination", fmt.Sprintf("id=%s", udid),
+		"-derivedDataPath", derived,
+		"test-without-building",
+	)
+	cmd.Dir = wdaPath
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start xcodebuild: %w", err)
+	}
+	return cmd, nil
+}
+
+// runDeviceBuildMulti builds the app once for generic iOS and installs it on
+// each device concurrently.
+func runDeviceBuildMulti(cfg *config.Config, devices []ConnectedDevice, xcodeFile, xcodeType, scheme string) error {
+	projectRoot := cfg.ProjectRoot()
+	derived := filepath.Join(os.Getenv("HOME"), "Library/Developer/Xcode/DerivedData", "Drift-"+filepath.Base(projectRoot))
+
+	buildArgs := []string{
+		fmt.Sprintf("-%s", xcodeType), xcodeFile,
+		"-scheme", scheme,
+		"-destination", "generic/platform=iOS",
+		"-derivedDataPath", derived,
+		"-allowProvisioningUpdates",
+	}
+	if cfg.Apple.TeamID != "" {
+		buildArgs = append(buildArgs, fmt.Sprintf("DEVELOPMENT_TEAM=%s", cfg.Apple.TeamID))
+	}
+	buildArgs = append(buildArgs, "build")
+
+	ui.Info("Building once for all devices...")
+	buildCmd := exec.Command("xcodebuild", buildArgs...)
+	buildCmd.Dir = projectRoot
+	buildCmd.Stdout = os.Stdout
+	buildCmd.Stderr = os.Stderr
+	if err := buildCmd.Run(); err != nil {
+		return fmt.Errorf("build failed: %w", err)
+	}
+
+	apps, _ := filepath.Glob(filepath.Join(derived, "Build", "Products", "*-iphoneos", "*.app"))
+	if len(apps) == 0 {
+		return fmt.Errorf("no .app found in %s", filepath.Join(derived, "Build", "Products"))
+	}
+	app := apps[0]
+
+	var bundleID string
+	if deviceRunFlag {
+		result, err := shell.Run("/usr/libexec/PlistBuddy", "-c", "Print :CFBundleIdentifier", filepath.Join(app, "Info.plist"))
+		if err == nil && result.ExitCode == 0 {
+			bundleID = strings.TrimSpace(result.Stdout)
+		}
+	}
+
+	ui.NewLine()
+	sp := ui.NewSpinner(fmt.Sprintf("Installing %s on %d devices...", filepath.Base(app), len(devices)))
+	sp.Start()
+
+	errs := make([]error, len(devices))
+	var wg sync.WaitGroup
+	for i := range devices {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = installAndLaunch(app, bundleID, devices[i].UDID)
+		}(i)
+	}
+	wg.Wait()
+	sp.Stop()
+
+	failed := 0
+	for i, d := range devices {
+		if errs[i] != nil {
+			failed++
+			ui.Errorf("%s: %v", d.Name, errs[i])
+			continue
+		}
+		if bundleID != "" {
+			ui.Successf("%s: installed and launched", ui.Cyan(d.Name))
+		} else {
+			ui.Successf("%s: installed", ui.Cyan(d.Name))
+		}
+	}
+	if deviceRunFlag && bundleID == "" {
+		ui.Warning("Could not read the bundle ID; app was not launched")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d devices failed", failed, len(devices))
+	}
+	return nil
+}
+
+func installAndLaunch(app, bundleID, udid string) error {
+	result, err := shell.Run("ios", "install", fmt.Sprintf("--path=%s", app), fmt.Sprintf("--udid=%s", udid))
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("install failed: %s", strings.TrimSpace(result.Stderr+" "+result.Stdout))
+	}
+	if bundleID == "" {
+		return nil
+	}
+	result, err = shell.Run("ios", "launch", bundleID, fmt.Sprintf("--udid=%s", udid))
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("launch failed: %s", strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+func TestDeviceSessionsAllocate(t *testing.T) {
+	sessions := &DeviceSessions{}
+
+	a := sessions.Allocate("udid-a", "iPhone A", 8100)
+	b := sessions.Allocate("udid-b", "iPhone B", 8100)
+	if a.Port != 8100 || b.Port != 8101 {
+		t.Fatalf("ports = %d, %d; want 8100, 8101", a.Port, b.Port)
+	}
+
+	// Existing devices keep their port.
+	if again := sessions.Allocate("udid-a", "Renamed", 8100); again.Port != 8100 || again.Name != "Renamed" {
+		t.Errorf("re-allocate = %+v, want port 8100 and updated name", again)
+	}
+
+	// Freed ports are reused.
+	sessions.Sessions = sessions.Sessions[1:]
+	if c := sessions.Allocate("udid-c", "iPad", 8100); c.Port != 8100 {
+		t.Errorf("new device port = %d, want 8100", c.Port)
+	}
+
+	if sessions.Find("udid-b") == nil || sessions.Find("missing") != nil {
+		t.Error("Find() returned unexpected result")
+	}
+}
+
+func TestSplitDeviceList(t *testing.T) {
+	tests := []struct {
+		input []string
+		want  []string
+	}{
+		{nil, nil},
+		{[]string{"iPhone 15"}, []string{"iPhone 15"}},
+		{[]string{"a, b", "c"}, []string{"a", "b", "c"}},
+		{[]string{" , a,,"}, []string{"a"}},
+	}
+	for _, tt := range tests {
+		if got := splitDeviceList(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitDeviceList(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestInstallAndLaunch_FailsOnExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		resp testutil.Response
+		want string
+	}{
+		{"install", testutil.Response{Args: "install", Stderr: "device locked", Exit: 1}, "install failed: device locked"},
+		{"launch", testutil.Response{Args: "launch", Stderr: "app not installed", Exit: 1}, "launch failed: app not installed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.NewFakeBin(t, "ios", tt.resp)
+
+			err := installAndLaunch("/tmp/App.app", "com.example.app", "udid-a")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("installAndLaunch() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/xcode"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// DefaultWDABundleID is the bundle ID of Appium's WebDriverAgent test runner.
+const DefaultWDABundleID = "com.facebook.WebDriverAgentRunner.xctrunner"
+
+// wdaCacheDir returns where downloaded and resigned WDA artifacts are kept (~/.drift/wda).
+func wdaCacheDir() (string, error) {
+	home, err := config.DriftHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "wda"), nil
+}
+
+// startPrebuiltWDA installs the configured prebuilt WDA runner (resigned for
+// the team) on the device and launches it with go-ios.
+func startPrebuiltWDA(cfg *config.Config, device *ConnectedDevice) (*exec.Cmd, error) {
+	ipa, bundleID, err := preparePrebuiltWDA(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var out io.Writer
+	if IsVerbose() {
+		out = os.Stdout
+	}
+	sp := ui.NewSpinner("Installing WebDriverAgent...")
+	sp.Start()
+	wdaCmd, err := launchPrebuiltWDA(ipa, bundleID, device.UDID, out)
+	if err != nil {
+		sp.Fail("Failed to start WebDriverAgent")
+		return nil, err
+	}
+	sp.Success("WebDriverAgent installed")
+	return wdaCmd, nil
+}
+
+// preparePrebuiltWDA fetches and resigns the configured prebuilt runner,
+// returning the .ipa to install and its bundle ID.
+func preparePrebuiltWDA(cfg *config.Config) (string, string, error) {
+	bundleID := cfg.Device.WDABundleID
+	if bundleID == "" {
+		bundleID = DefaultWDABundleID
+	}
+
+	sp := ui.NewSpinner("Fetching prebuilt WebDriverAgent...")
+	sp.Start()
+	source, err := fetchPrebuiltWDA(cfg, cfg.Device.WDAPrebuilt)
+	if err != nil {
+		sp.Fail("Failed to fetch prebuilt WebDriverAgent")
+		return "", "", err
+	}
+	sp.Success(fmt.Sprintf("Prebuilt WebDriverAgent: %s", filepath.Base(source)))
+
+	resigned, err := resignPrebuiltWDA(cfg, source, bundleID)
+	if err != nil {
+		return "", "", err
+	}
+	return resigned, bundleID, nil
+}
+
+// launchPrebuiltWDA installs ipa on a device and starts the runner in the
+// background. Runner output goes to out (discarded when nil).
+func launchPrebuiltWDA(ipa, bundleID, udid string, out io.Writer) (*exec.Cmd, error) {
+	result, err := shell.Run("ios", "install", fmt.Sprintf("--path=%s", ipa), fmt.Sprintf("--udid=%s", udid))
+	if err != nil || result.ExitCode != 0 {
+		return nil, fmt.Errorf("ios install failed: %s", strings.TrimSpace(result.Stderr+" "+result.Stdout))
+	}
+
+	wdaCmd := exec.Command("ios", "runwda",
+		fmt.Sprintf("--bundleid=%s", bundleID),
+		fmt.Sprintf("--testrunnerbundleid=%s", bundleID),
+		"--xctestconfig=WebDriverAgentRunner.xctest",
+		fmt.Sprintf("--udid=%s", udid),
+	)
+	if out != nil {
+		wdaCmd.Stdout = out
+		wdaCmd.Stderr = out
+	}
+	if err := wdaCmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ios runwda: %w", err)
+	}
+	return wdaCmd, nil
+}
+
+// fetchPrebuiltWDA returns a local path to the prebuilt .ipa, downloading
+// URLs into the WDA cache once.
+func fetchPrebuiltWDA(cfg *config.Config, location string) (string, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		path := location
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.ProjectRoot(), path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("prebuilt WDA not found: %s", path)
+		}
+		return path, nil
+	}
+
+	dir, err := wdaCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(location))
+	dest := filepath.Join(dir, "download-"+hex.EncodeToString(sum[:])[:12]+".ipa")
+	if _, err := os.Stat(dest); err == nil {
+		return dest, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	client := &http.Client{Timeout: 10 * time.Minute}
+	resp, err := client.Get(location)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", location, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(dir, "download-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to download %s: %w", location, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// resignPrebuiltWDA resigns source for the configured team, reusing a cached
+// result for the same artifact, team, and bundle ID.
+func resignPrebuiltWDA(cfg *config.Config, source, bundleID string) (string, error) {
+	teamID := cfg.Apple.TeamID
+	if teamID == "" {
+		return "", fmt.Errorf("apple.team_id is required to resign WebDriverAgent")
+	}
+
+	dir, err := wdaCacheDir()
+	if err != nil {
+		return "", err
+	}
+	digest, err := fileSHA256(source)
+	if err != nil {
+		return "", err
+	}
+	key := sha256.Sum256([]byte(digest + "|" + teamID + "|" + bundleID))
+	out := filepath.Join(dir, fmt.Sprintf("WebDriverAgentRunner-%s-%s.ipa", teamID, hex.EncodeToString(key[:])[:12]))
+	if _, err := os.Stat(out); err == nil {
+		ui.Success("Using cached resigned WebDriverAgent")
+		return out, nil
+	}
+
+	sp := ui.NewSpinner(fmt.Sprintf("Resigning WebDriverAgent for team %s...", teamID))
+	sp.Start()
+
+	profile := cfg.Device.WDAProfile
+	if profile == "" {
+		p, err := xcode.FindProvisioningProfile(teamID, bundleID)
+		if err != nil {
+			sp.Fail("No provisioning profile found")
+			return "", err
+		}
+		profile = p.Path
+	} else if !filepath.IsAbs(profile) {
+		profile = filepath.Join(cfg.ProjectRoot(), profile)
+	}
+	identity := cfg.Device.WDAIdentity
+	if identity == "" {
+		id, err := xcode.FindSigningIdentity(teamID)
+		if err != nil {
+			sp.Fail("No signing identity found")
+			return "", err
+		}
+		identity = id.Hash
+	}
+
+	if err := xcode.ResignIPA(source, out, xcode.ResignOptions{
+		Identity: identity,
+		Profile:  profile,
+		BundleID: bundleID,
+	}); err != nil {
+		sp.Fail("Failed to resign WebDriverAgent")
+		_ = os.Remove(out)
+		return "", err
+	}
+	sp.Success("WebDriverAgent resigned")
+	return out, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
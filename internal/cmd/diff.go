@@ -0,0 +1,500 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/mask"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare Supabase environments",
+	Long:  `Compare two Supabase environments side by side.`,
+}
+
+var diffAllCmd = &cobra.Command{
+	Use:   "all [env-a] [env-b]",
+	Short: "Diff two environments across env, schema, functions, secrets, and cron",
+	Long: `Compare two environments in one sectioned report:
+
+  env        drift-managed values and environments.<env> secrets from .drift.yaml
+  schema     tables (RLS, column count) and RLS policies
+  functions  deployed Edge Functions and a hash of their deployed source
+  secrets    Edge Function secret names
+  cron       pg_cron jobs (schedule, command, active)
+
+Environments are 'prod', 'dev', or a Supabase branch name and default to
+dev and prod, which makes this the release checklist comparison. Only
+differences are listed; a section that cannot be checked records the error
+instead of aborting the report. Schema and cron need database access
+(PROD_PASSWORD for production, or a prompt).`,
+	Example: `  drift diff all
+  drift diff all dev prod --skip functions
+  drift diff all feature-login dev
+  drift diff all --json > diff.json`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runDiffAll,
+}
+
+var (
+	diffJSONFlag   bool
+	diffOutputFlag string
+	diffSkipFlag   []string
+)
+
+// diffSectionNames are the sections of 'drift diff all', in report order.
+var diffSectionNames = []string{"env", "schema", "functions", "secrets", "cron"}
+
+func init() {
+	diffAllCmd.Flags().BoolVar(&diffJSONFlag, "json", false, "Output JSON instead of Markdown")
+	addShowSecretsFlag(diffAllCmd)
+	diffAllCmd.Flags().StringVarP(&diffOutputFlag, "output", "o", "", "Write the report to a file")
+	diffAllCmd.Flags().StringSliceVar(&diffSkipFlag, "skip", nil, "Sections to skip (env, schema, functions, secrets, cron)")
+
+	diffCmd.AddCommand(diffAllCmd)
+	rootCmd.AddCommand(diffCmd)
+}
+
+// envDiffReport compares two environments section by section.
+type envDiffReport struct {
+	Left        diffTarget    `json:"left"`
+	Right       diffTarget    `json:"right"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Sections    []diffSection `json:"sections"`
+}
+
+// diffTarget is one side of the comparison.
+type diffTarget struct {
+	Name        string `json:"name"`
+	Environment string `json:"environment"`
+	ProjectRef  string `json:"project_ref"`
+}
+
+// diffSection lists the items that differ in one dimension.
+type diffSection struct {
+	Name  string    `json:"name"`
+	Same  int       `json:"same"`
+	Rows  []diffRow `json:"differences"`
+	Error string    `json:"error,omitempty"`
+}
+
+// diffRow is one item that differs. An empty side means the item is missing
+// there.
+type diffRow struct {
+	Item  string `json:"item"`
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+func isDiffSection(name string) bool {
+	for _, s := range diffSectionNames {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func runDiffAll(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	left, right := "dev", "prod"
+	if len(args) > 0 {
+		left = args[0]
+	}
+	if len(args) > 1 {
+		right = args[1]
+	}
+
+	skip := make(map[string]bool)
+	for _, s := range diffSkipFlag {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !isDiffSection(s) {
+			return fmt.Errorf("unknown section '%s' (expected one of: %s)", s, strings.Join(diffSectionNames, ", "))
+		}
+		skip[s] = true
+	}
+
+	if diffJSONFlag {
+		// JSON output must stay clean: never prompt, fail instead.
+		yesFlag = true
+	}
+
+	var sp *ui.Spinner
+	progress := func(msg string) {
+		if diffJSONFlag {
+			return
+		}
+		if sp == nil {
+			sp = ui.NewSpinner(msg)
+			sp.Start()
+			return
+		}
+		sp.UpdateMessage(msg)
+	}
+
+	report, err := collectEnvDiff(cfg, displayPolicy(cmd, cfg), left, right, skip, progress)
+	if sp != nil {
+		sp.Stop()
+	}
+	if err != nil {
+		return err
+	}
+
+	var out string
+	if diffJSONFlag {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = string(data) + "\n"
+	} else {
+		out = renderDiffMarkdown(report)
+	}
+
+	if diffOutputFlag != "" {
+		if err := os.WriteFile(diffOutputFlag, []byte(out), 0644); err != nil {
+			return err
+		}
+		if !diffJSONFlag {
+			ui.Successf("Diff written to %s", diffOutputFlag)
+		}
+		return nil
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// collectEnvDiff resolves both environments and compares every section not
+// in skip. Section failures are recorded on the section.
+func collectEnvDiff(cfg *config.Config, policy *mask.Policy, left, right string, skip map[string]bool, progress func(string)) (*envDiffReport, error) {
+	client := supabase.NewClient()
+
+	progress("Resolving environments")
+	leftInfo, err := resolveDiffTarget(client, left)
+	if err != nil {
+		return nil, err
+	}
+	rightInfo, err := resolveDiffTarget(client, right)
+	if err != nil {
+		return nil, err
+	}
+	if leftInfo.ProjectRef == rightInfo.ProjectRef {
+		return nil, fmt.Errorf("'%s' and '%s' are the same Supabase branch", left, right)
+	}
+
+	report := &envDiffReport{
+		Left:        diffTargetFor(leftInfo),
+		Right:       diffTargetFor(rightInfo),
+		GeneratedAt: time.Now().UTC(),
+		Sections:    []diffSection{},
+	}
+
+	collectors := map[string]func() (map[string]string, map[string]string, error){
+		"env": func() (map[string]string, map[string]string, error) {
+			return diffEnvValues(cfg, policy, leftInfo), diffEnvValues(cfg, policy, rightInfo), nil
+		},
+		"schema": func() (map[string]string, map[string]string, error) {
+			return diffBothSides(leftInfo, rightInfo, fetchSchemaInventory)
+		},
+		"functions": func() (map[string]string, map[string]string, error) {
+			return diffBothSides(leftInfo, rightInfo, func(ref string) (map[string]string, error) {
+				return fetchFunctionHashes(client, ref)
+			})
+		},
+		"secrets": func() (map[string]string, map[string]string, error) {
+			return diffBothSides(leftInfo, rightInfo, func(ref string) (map[string]string, error) {
+				names, err := client.ListSecrets(ref)
+				if err != nil {
+					return nil, err
+				}
+				set := make(map[string]string, len(names))
+				for _, n := range names {
+					set[n] = "set"
+				}
+				return set, nil
+			})
+		},
+		"cron": func() (map[string]string, map[string]string, error) {
+			return diffBothSides(leftInfo, rightInfo, fetchCronInventory)
+		},
+	}
+
+	for _, name := range diffSectionNames {
+		if skip[name] {
+			continue
+		}
+		progress(fmt.Sprintf("Comparing %s", name))
+		section := diffSection{Name: name, Rows: []diffRow{}}
+		l, r, err := collectors[name]()
+		if err != nil {
+			section.Error = err.Error()
+		} else {
+			section.Rows, section.Same = diffValueMaps(l, r)
+		}
+		report.Sections = append(report.Sections, section)
+	}
+	return report, nil
+}
+
+// resolveDiffTarget resolves prod/dev or a branch name to a branch with its
+// environment type.
+func resolveDiffTarget(client *supabase.Client, target string) (*supabase.BranchInfo, error) {
+	branch, err := resolveCompareBranch(client, target)
+	if err != nil {
+		return nil, err
+	}
+
+	gitBranch := branch.GitBranch
+	if gitBranch == "" {
+		gitBranch = branch.Name
+	}
+	env := supabase.EnvFeature
+	if _, resolvedEnv, err := client.ResolveBranch(gitBranch); err == nil && resolvedEnv != "" {
+		env = resolvedEnv
+	}
+	return client.NewBranchInfo(&supabase.BranchInfo{GitBranch: gitBranch}, branch, env, false), nil
+}
+
+func diffTargetFor(info *supabase.BranchInfo) diffTarget {
+	return diffTarget{
+		Name:        info.SupabaseBranch.Name,
+		Environment: string(info.Environment),
+		ProjectRef:  info.ProjectRef,
+	}
+}
+
+// diffBothSides runs fetch for each side's project.
+func diffBothSides(left, right *supabase.BranchInfo, fetch func(projectRef string) (map[string]string, error)) (map[string]string, map[string]string, error) {
+	l, err := fetch(left.ProjectRef)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", left.SupabaseBranch.Name, err)
+	}
+	r, err := fetch(right.ProjectRef)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", right.SupabaseBranch.Name, err)
+	}
+	return l, r, nil
+}
+
+// diffEnvValues returns the env values drift would generate for info that
+// can be derived without fetching API keys, plus the environment's secrets
+// and skip_secrets from .drift.yaml. Secret values are masked by policy.
+func diffEnvValues(cfg *config.Config, policy *mask.Policy, info *supabase.BranchInfo) map[string]string {
+	values := make(map[string]string)
+
+	xcconfig := !cfg.Project.IsWebPlatform()
+	keys := xcconfigManagedKeys
+	if !xcconfig {
+		keys = webManagedKeys(cfg.Web.Framework)
+	}
+	for _, key := range keys {
+		if value, ok := expectedSetupValue(key, info, xcconfig); ok {
+			values[key] = value
+		}
+	}
+
+	if envCfg := cfg.GetEnvironmentConfig(string(info.Environment)); envCfg != nil {
+		for name, value := range envCfg.Secrets {
+			values["secrets."+name] = diffSecretValue(policy, value)
+		}
+		if len(envCfg.SkipSecrets) > 0 {
+			values["skip_secrets"] = stringsJoinSorted(envCfg.SkipSecrets)
+		}
+	}
+	return values
+}
+
+// diffSecretValue masks a secret but keeps a short hash so two different
+// values with the same prefix and suffix still compare as different.
+func diffSecretValue(policy *mask.Policy, value string) string {
+	if policy.Revealing() {
+		return value
+	}
+	sum := sha256.Sum256([]byte(value))
+	return fmt.Sprintf("%s (%s)", policy.Value(value), hex.EncodeToString(sum[:])[:8])
+}
+
+// fetchSchemaInventory summarizes tables and policies on a project.
+func fetchSchemaInventory(projectRef string) (map[string]string, error) {
+	opts, err := diffDatabaseOptions(projectRef)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := database.FetchTables(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	policies, err := database.FetchPolicies(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	items := make(map[string]string, len(tables)+len(policies))
+	for _, t := range tables {
+		rls := "RLS off"
+		if t.RLS {
+			rls = "RLS on"
+		}
+		items[fmt.Sprintf("table %s.%s", t.Schema, t.Name)] = fmt.Sprintf("%d columns, %s", t.Columns, rls)
+	}
+	for _, p := range policies {
+		items[fmt.Sprintf("policy %s.%s: %s", p.Schema, p.Table, p.Name)] = fmt.Sprintf("%s to %s", p.Command, p.Roles)
+	}
+	return items, nil
+}
+
+// fetchCronInventory lists pg_cron jobs on a project.
+func fetchCronInventory(projectRef string) (map[string]string, error) {
+	opts, err := diffDatabaseOptions(projectRef)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := database.FetchCronJobs(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cron jobs: %w", err)
+	}
+
+	items := make(map[string]string, len(jobs))
+	for _, j := range jobs {
+		value := fmt.Sprintf("%s: %s", j.Schedule, truncateValue(j.Command, 60))
+		if !j.Active {
+			value += " (inactive)"
+		}
+		items[j.Name] = value
+	}
+	return items, nil
+}
+
+func diffDatabaseOptions(projectRef string) (database.RestoreOptions, error) {
+	dbURL, err := getDbURLForProject(projectRef)
+	if err != nil {
+		return database.RestoreOptions{}, err
+	}
+	return restoreOptionsFromDBURL(dbURL)
+}
+
+// fetchFunctionHashes downloads each deployed function on a project and
+// hashes its source, so functions deployed from different code differ.
+func fetchFunctionHashes(client *supabase.Client, projectRef string) (map[string]string, error) {
+	deployed, err := client.ListDeployedFunctions(projectRef)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(deployed))
+	for _, fn := range deployed {
+		hash, err := deployedFunctionHash(client, fn.Name, projectRef)
+		if err != nil {
+			hashes[fn.Name] = fmt.Sprintf("v%s (source unavailable)", fn.Version)
+			continue
+		}
+		hashes[fn.Name] = hash[:12]
+	}
+	return hashes, nil
+}
+
+func deployedFunctionHash(client *supabase.Client, name, projectRef string) (string, error) {
+	tempDir, err := client.DownloadFunctionToTemp(name, projectRef)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tempDir)
+
+	dir := findDownloadedFunctionDir(tempDir, name)
+	if dir == "" {
+		return "", fmt.Errorf("could not find index.ts in downloaded function")
+	}
+	return hashFunctionSource(dir)
+}
+
+// diffValueMaps returns the items whose values differ between left and
+// right (including items on one side only), sorted by item, and the number
+// of items that match.
+func diffValueMaps(left, right map[string]string) ([]diffRow, int) {
+	rows := []diffRow{}
+	same := 0
+	for item, l := range left {
+		r, ok := right[item]
+		switch {
+		case !ok:
+			rows = append(rows, diffRow{Item: item, Left: l})
+		case l != r:
+			rows = append(rows, diffRow{Item: item, Left: l, Right: r})
+		default:
+			same++
+		}
+	}
+	for item, r := range right {
+		if _, ok := left[item]; !ok {
+			rows = append(rows, diffRow{Item: item, Right: r})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Item < rows[j].Item })
+	return rows, same
+}
+
+// renderDiffMarkdown renders the report with one table per section.
+func renderDiffMarkdown(r *envDiffReport) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Environment Diff: %s vs %s\n\n", r.Left.Name, r.Right.Name)
+	fmt.Fprintf(&b, "_Generated %s · `%s` (%s, %s) vs `%s` (%s, %s)_\n\n",
+		r.GeneratedAt.Format("2006-01-02 15:04 MST"),
+		r.Left.Name, r.Left.Environment, r.Left.ProjectRef,
+		r.Right.Name, r.Right.Environment, r.Right.ProjectRef)
+
+	b.WriteString("| Section | Differences | Matching |\n")
+	b.WriteString("|---------|-------------|----------|\n")
+	for _, s := range r.Sections {
+		differences := "0"
+		if s.Error != "" {
+			differences = "?"
+		} else if len(s.Rows) > 0 {
+			differences = fmt.Sprintf("**%d**", len(s.Rows))
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d |\n", s.Name, differences, s.Same)
+	}
+	b.WriteString("\n")
+
+	for _, s := range r.Sections {
+		if s.Error == "" && len(s.Rows) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "## %s\n\n", s.Name)
+		if s.Error != "" {
+			fmt.Fprintf(&b, "- Could not compare %s: %s\n\n", s.Name, s.Error)
+			continue
+		}
+		fmt.Fprintf(&b, "| Item | `%s` | `%s` |\n", r.Left.Name, r.Right.Name)
+		b.WriteString("|------|------|------|\n")
+		for _, row := range s.Rows {
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", row.Item, diffCell(row.Left), diffCell(row.Right))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// diffCell renders one side of a row for a Markdown table.
+func diffCell(value string) string {
+	if value == "" {
+		return "_missing_"
+	}
+	return strings.ReplaceAll(value, "|", "\\|")
+}
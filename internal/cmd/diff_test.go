@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffValueMaps(t *testing.T) {
+	left := map[string]string{
+		"send-push":     "a1b2c3",
+		"stripe-hook":   "d4e5f6",
+		"legacy-import": "0f0f0f",
+	}
+	right := map[string]string{
+		"send-push":   "a1b2c3",
+		"stripe-hook": "999999",
+		"nightly":     "abcdef",
+	}
+
+	rows, same := diffValueMaps(left, right)
+	want := []diffRow{
+		{Item: "legacy-import", Left: "0f0f0f"},
+		{Item: "nightly", Right: "abcdef"},
+		{Item: "stripe-hook", Left: "d4e5f6", Right: "999999"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("diffValueMaps() rows = %+v, want %+v", rows, want)
+	}
+	if same != 1 {
+		t.Errorf("diffValueMaps() same = %d, want 1", same)
+	}
+}
+
+func TestDiffSecretValue(t *testing.T) {
+	a := diffSecretValue(maskPolicy(nil), "sk_live_aaaa1111zzzz")
+	b := diffSecretValue(maskPolicy(nil), "sk_live_bbbb2222zzzz")
+	if a == b {
+		t.Errorf("different secrets rendered the same: %q", a)
+	}
+	if strings.Contains(a, "aaaa1111") {
+		t.Errorf("diffSecretValue() leaked the secret: %q", a)
+	}
+}
+
+func TestRenderDiffMarkdown(t *testing.T) {
+	r := &envDiffReport{
+		Left:        diffTarget{Name: "develop", Environment: "Development", ProjectRef: "dev123"},
+		Right:       diffTarget{Name: "main", Environment: "Production", ProjectRef: "prod456"},
+		GeneratedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Sections: []diffSection{
+			{Name: "env", Same: 4, Rows: []diffRow{}},
+			{Name: "secrets", Same: 2, Rows: []diffRow{{Item: "STRIPE_KEY", Left: "set"}}},
+			{Name: "cron", Rows: []diffRow{}, Error: "main: connection refused"},
+		},
+	}
+
+	md := renderDiffMarkdown(r)
+	for _, want := range []string{
+		"# Environment Diff: develop vs main",
+		"| env | 0 | 4 |",
+		"| secrets | **1** | 2 |",
+		"| cron | ? | 0 |",
+		"| `STRIPE_KEY` | set | _missing_ |",
+		"- Could not compare cron: main: connection refused",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "## env") {
+		t.Errorf("sections without differences should not get a table:\n%s", md)
+	}
+}
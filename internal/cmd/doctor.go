@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
 	"github.com/undrift/drift/internal/ui"
 	"github.com/undrift/drift/pkg/shell"
 )
@@ -98,7 +99,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 
 	if hasErrors {
 		ui.Error("Some checks failed. Please install missing dependencies.")
-		return fmt.Errorf("doctor checks failed")
+		return errs.Validationf("doctor checks failed")
 	}
 
 	ui.Success("All checks passed!")
@@ -2,13 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/envcrypt"
+	"github.com/undrift/drift/internal/errs"
 	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/secretref"
 	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
 	"github.com/undrift/drift/internal/web"
@@ -30,15 +36,19 @@ If no matching branch exists, Drift uses fallback resolution in this order:
   2) supabase.fallback_branch from .drift.local.yaml
   3) interactive non-production branch selection.
 
-For web projects, it generates .env.local with all Supabase credentials.
+For web projects, it generates .env.local with all Supabase credentials,
+or the file for web.framework: .env with VITE_* or EXPO_PUBLIC_* variables,
+or dart_defines.json for Flutter's --dart-define-from-file.
 For iOS/macOS projects, it generates Config.xcconfig.`,
 }
 
 var envShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current environment info",
-	Long:  `Display the current environment configuration, including git branch, Supabase branch, and project details.`,
-	RunE:  runEnvShow,
+	Long: `Display the current environment configuration, including git branch, Supabase branch,
+project details, and the variables in the generated env file. Secret values are
+masked unless --show-secrets is given; revealing them is recorded in the audit log.`,
+	RunE: Run(runEnvShow, RequireProject),
 }
 
 var envSetupCmd = &cobra.Command{
@@ -51,12 +61,34 @@ This command:
 2. Finds the matching Supabase branch (or falls back to development)
 3. Fetches the API keys for that branch
 4. Generates the appropriate config file:
-   - .env.local for web projects
+   - .env.local for web projects (.env for Vite/Expo, dart_defines.json for Flutter)
    - Config.xcconfig for iOS/macOS projects
 
 For web projects, you can copy custom variables from another .env.local file:
-  drift env setup --copy-custom-from /path/to/other/.env.local`,
-	RunE: runEnvSetup,
+  drift env setup --copy-custom-from /path/to/other/.env.local
+
+For iOS/macOS projects with several schemes, --all-schemes writes one variant
+per entry in xcode.schemes (e.g. Config.Production.xcconfig,
+Config.Development.xcconfig), each pointing at that environment's Supabase branch:
+  drift env setup --all-schemes
+
+--print (or --stdout) renders the config that would be written to stdout and
+leaves the current file untouched; status messages go to stderr. Secret values
+are masked unless --reveal is also given:
+  drift env setup --print
+  drift env setup -b dev --print --reveal > /tmp/dev.env
+
+A preview branch that is still provisioning has no working keys or pooler
+yet; --wait polls until it is healthy instead of failing:
+  drift env setup --wait
+
+Each successful setup keeps the fetched credentials in the secret store
+(encryption.backend: the OS keychain by default, or age). When the Supabase
+API cannot be reached, setup falls back to them; --skip-keys (or --offline)
+uses them without trying the API. Files written this way say so in their
+header, with the time the credentials were fetched:
+  drift env setup --skip-keys`,
+	RunE: Run(runEnvSetup, RequireProject, GuardWorktree),
 }
 
 var envSwitchCmd = &cobra.Command{
@@ -64,7 +96,7 @@ var envSwitchCmd = &cobra.Command{
 	Short: "Setup environment for a different Supabase branch",
 	Long:  `Generate environment config for a specific Supabase branch, regardless of the current git branch.`,
 	Args:  cobra.ExactArgs(1),
-	RunE:  runEnvSwitch,
+	RunE:  Run(runEnvSwitch, RequireProject, GuardWorktree),
 }
 
 var envValidateCmd = &cobra.Command{
@@ -78,7 +110,7 @@ Validation checks:
 3. Drift markers are intact (=== DRIFT MANAGED ===)
 4. Configured Xcode schemes exist (if applicable)
 5. DB_SCHEMA_VERSION matches latest migration (optional)`,
-	RunE: runEnvValidate,
+	RunE: Run(runEnvValidate, RequireProject),
 }
 
 var envDiffCmd = &cobra.Command{
@@ -88,32 +120,44 @@ var envDiffCmd = &cobra.Command{
 
 Shows differences in:
 - Supabase URL and Project Ref
-- API keys (masked)
+- API keys
 - Custom variables
 
+Values of secret-looking variables are masked (see masking in .drift.yaml);
+--show-secrets shows them and records that in the audit log.
+
 Examples:
   drift env diff main dev
   drift env diff main feat/new-feature`,
 	Args: cobra.ExactArgs(2),
-	RunE: runEnvDiff,
+	RunE: Run(runEnvDiff, RequireProject),
 }
 
 var (
-	envBranchFlag         string
-	envBuildServerFlag    bool
-	envCopyCustomFromFlag string
-	envCopyEnvFlag        bool
-	envSchemeFlag         string
-	envCIFlag             bool
+	envBranchFlag           string
+	envBuildServerFlag      bool
+	envCopyCustomFromFlag   string
+	envCopyEnvFlag          bool
+	envSchemeFlag           string
+	envCIFlag               bool
+	envAllowServiceRoleFlag bool
+	envAllSchemesFlag       bool
+	envSkipKeysFlag         bool
 )
 
 func init() {
 	envSetupCmd.Flags().StringVarP(&envBranchFlag, "branch", "b", "", "Override Supabase branch selection")
+	addProvisionWaitFlags(envSetupCmd)
 	envSetupCmd.Flags().BoolVar(&envBuildServerFlag, "build-server", false, "Also generate buildServer.json for sourcekit-lsp")
 	envSetupCmd.Flags().StringVar(&envCopyCustomFromFlag, "copy-custom-from", "", "Copy custom variables from a specific .env.local file path")
 	envSetupCmd.Flags().BoolVar(&envCopyEnvFlag, "copy-env", false, "Copy custom variables from another worktree (interactive picker)")
 	envSetupCmd.Flags().StringVar(&envSchemeFlag, "scheme", "", "Xcode scheme to use for buildServer.json (requires --build-server)")
-	envSetupCmd.Flags().BoolVar(&envCIFlag, "ci", false, "CI mode: read SUPABASE_URL and SUPABASE_ANON_KEY from environment variables")
+	envSetupCmd.Flags().BoolVar(&envAllowServiceRoleFlag, "allow-service-role-key", false, "Write SUPABASE_SERVICE_ROLE_KEY to .env.local even when web.service_role_key is deny")
+	envSetupCmd.Flags().BoolVar(&envAllSchemesFlag, "all-schemes", false, "Generate one xcconfig variant per environment in xcode.schemes")
+	envSetupCmd.Flags().BoolVar(&envSkipKeysFlag, "skip-keys", false, "Reuse the credentials cached by an earlier setup instead of fetching them (works offline)")
+	envSetupCmd.Flags().BoolVar(&envCIFlag, "ci", false, "CI mode: read SUPABASE_URL, SUPABASE_ANON_KEY, and optional DRIFT_ENVIRONMENT from environment variables")
+
+	addShowSecretsFlag(envShowCmd, envDiffCmd)
 
 	envCmd.AddCommand(envShowCmd)
 	envCmd.AddCommand(envSetupCmd)
@@ -123,22 +167,18 @@ func init() {
 	rootCmd.AddCommand(envCmd)
 }
 
-func runEnvShow(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runEnvShow(ctx *Context) error {
+	cfg := ctx.Config()
 
 	// Get current git branch
-	gitBranch, err := git.CurrentBranch()
+	gitBranch, err := ctx.GitBranch()
 	if err != nil {
-		return fmt.Errorf("failed to get current git branch: %w", err)
+		return err
 	}
 
 	ui.Header("Environment Info")
 
-	client := supabase.NewClient()
-	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, "")
+	info, err := ctx.Target("")
 	if err != nil {
 		ui.Warning(fmt.Sprintf("Could not resolve Supabase branch: %v", err))
 		ui.KeyValue("Git Branch", ui.Cyan(gitBranch))
@@ -162,6 +202,8 @@ func runEnvShow(cmd *cobra.Command, args []string) error {
 		ui.Warningf("Using fallback target branch: %s", info.SupabaseBranch.GitBranch)
 	}
 
+	printCacheBanner()
+
 	// Check config file status based on project type
 	ui.NewLine()
 
@@ -184,6 +226,7 @@ func runEnvShow(cmd *cobra.Command, args []string) error {
 					ui.Infof("Run 'drift env setup' to update")
 				}
 			}
+			printEnvFileVariables(ctx, envLocalPath)
 		} else {
 			ui.Warning(".env.local not found")
 			ui.Infof("Run 'drift env setup' to generate it")
@@ -207,6 +250,7 @@ func runEnvShow(cmd *cobra.Command, args []string) error {
 					ui.Infof("Run 'drift env setup' to update")
 				}
 			}
+			printEnvFileVariables(ctx, xcconfigPath)
 		} else {
 			ui.Warning("Config.xcconfig not found")
 			ui.Infof("Run 'drift env setup' to generate it")
@@ -216,44 +260,264 @@ func runEnvShow(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runEnvSetup(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
+// printEnvFileVariables lists the variables in a generated env file, with
+// secrets masked unless --show-secrets is given.
+func printEnvFileVariables(ctx *Context, path string) {
+	vars, err := readEnvFileVariables(path)
+	if err != nil || len(vars) == 0 {
+		return
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	policy := displayPolicy(ctx.Cmd, ctx.Config())
+	ui.NewLine()
+	ui.SubHeader("Variables")
+	for _, name := range names {
+		ui.KeyValue(name, truncateValue(policy.Display(name, vars[name]), 60))
+	}
+}
+
+func runEnvSetup(ctx *Context) error {
+	cfg := ctx.Config()
+
+	var printOut io.Writer
+	if envPrintFlag {
+		if envAllSchemesFlag {
+			return fmt.Errorf("--print cannot be combined with --all-schemes")
+		}
+		var restore func()
+		printOut, restore = redirectEnvPrint()
+		defer restore()
+	} else if envRevealFlag {
+		return fmt.Errorf("--reveal requires --print")
 	}
-	cfg := config.LoadOrDefault()
 
 	// CI mode: read from environment variables
 	if envCIFlag {
-		return runEnvSetupCI(cfg)
+		return runEnvSetupCI(cfg, printOut)
 	}
 
 	// Get current git branch
-	gitBranch, err := git.CurrentBranch()
+	gitBranch, err := ctx.GitBranch()
 	if err != nil {
-		return fmt.Errorf("failed to get current git branch: %w", err)
+		return err
 	}
 
-	if envBranchFlag != "" {
+	review, err := requestedEnvReview(cfg, gitBranch)
+	if err != nil {
+		return err
+	}
+	if review != nil {
+		if envAllSchemesFlag {
+			return fmt.Errorf("--review cannot be combined with --all-schemes")
+		}
+		ui.Infof("Review mode: targeting the preview branch for %s", review.label())
+	} else if envBranchFlag != "" {
 		ui.Infof("Using branch override: %s", envBranchFlag)
 	}
 
-	// Ensure Supabase is linked (uses project_ref from config)
-	if err := ensureSupabaseLinked(cfg); err != nil {
-		return err
+	if envSkipKeysFlag && (review != nil || envAllSchemesFlag) {
+		return fmt.Errorf("--skip-keys cannot be combined with --review or --all-schemes")
 	}
 
-	// Resolve Supabase branch
-	client := supabase.NewClient()
+	// Credentials come from the cache with --skip-keys or --offline, or when
+	// fetching them fails; review targets are never cached.
+	var creds *envCredentials
+	cacheID := envCredentialsID(cfg, gitBranch, envBranchFlag)
+	if (envSkipKeysFlag || supabase.IsOffline()) && review == nil && !envAllSchemesFlag {
+		if creds, err = cachedEnvCredentials(cfg, cacheID); err != nil {
+			return err
+		}
+	} else {
+		// Ensure Supabase is linked (uses project_ref from config)
+		if err := ensureSupabaseLinked(cfg); err != nil {
+			return err
+		}
 
-	sp := ui.NewSpinner("Resolving Supabase branch")
-	sp.Start()
+		if envAllSchemesFlag {
+			return runEnvSetupSchemeVariants(cfg, ctx.Client(), gitBranch)
+		}
 
-	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, envBranchFlag)
-	if err != nil {
-		sp.Fail("Failed to resolve Supabase branch")
-		return err
+		creds, err = fetchEnvCredentials(ctx, review)
+		switch {
+		case err == nil && review == nil:
+			saveEnvCredentials(cfg, cacheID, creds)
+		case err != nil && review == nil:
+			cached, cacheErr := loadEnvCredentials(cfg, cacheID)
+			if cacheErr != nil {
+				return err
+			}
+			ui.Warningf("Could not fetch credentials: %v", err)
+			creds = cached
+		case err != nil:
+			return err
+		}
+	}
+	if creds.cached {
+		ui.Warningf("Using credentials cached %s; run 'drift env setup' online to refresh them", formatBackupAge(creds.FetchedAt))
+	}
+	info, anonKey, serviceRoleKey, webSecrets := creds.Info, creds.AnonKey, creds.ServiceRoleKey, creds.Web
+
+	if printOut != nil {
+		return printEnvSetup(printOut, cfg, info, anonKey, webSecrets)
+	}
+
+	// Generate config file based on project type
+	var outputPath string
+	beginEnvSetupWrite(cfg)
+
+	if cfg.Project.IsWebPlatform() {
+		if !web.ValidFramework(cfg.Web.Framework) {
+			return fmt.Errorf("invalid web.framework %q (use nextjs, vite, expo, or flutter)", cfg.Web.Framework)
+		}
+		outputPath = cfg.GetEnvLocalPath()
+		envFileName := filepath.Base(outputPath)
+
+		sp := ui.NewSpinner("Generating " + envFileName)
+		sp.Start()
+
+		generator := web.NewEnvLocalGenerator(outputPath)
+
+		generator.VarPrefix = cfg.Supabase.EnvPrefix
+		generator.Framework = cfg.Web.Framework
+		if generator.Seal, err = envSecretSealer(cfg, info.ProjectRef); err != nil {
+			sp.Fail("Failed to initialize secret store")
+			return err
+		}
+		generator.ServiceRolePolicy = cfg.Web.ServiceRoleKey
+		generator.ServerEnvPath = cfg.GetServerEnvPath()
+		generator.CachedAt = creds.cachedAt()
+		applyEnvLocalBanner(cfg, generator, info.Environment)
+		applyEnvLocalPorts(cfg, generator, true)
+		if generator.ServiceRolePolicy == web.ServiceRoleDeny && envAllowServiceRoleFlag {
+			generator.ServiceRolePolicy = web.ServiceRoleInclude
+		}
+
+		if err := generator.GenerateFromBranchInfo(info, webSecrets); err != nil {
+			sp.Fail("Failed to generate " + envFileName)
+			return err
+		}
+
+		sp.Success(envFileName + " generated")
+		reportServiceRolePolicy(cfg, webSecrets)
+
+		// Copy custom variables from another worktree (interactive picker)
+		if envCopyEnvFlag {
+			sourcePath, err := selectWorktreeConfigFile(cfg, envFileName)
+			if err != nil {
+				ui.Warning(fmt.Sprintf("Could not select worktree: %v", err))
+			} else if sourcePath != "" {
+				envCopyCustomFromFlag = sourcePath
+			}
+		}
+
+		// Copy custom variables from another file if requested
+		if envCopyCustomFromFlag != "" {
+			if err := copyCustomVariables(envCopyCustomFromFlag, outputPath); err != nil {
+				ui.Warning(fmt.Sprintf("Could not copy custom variables: %v", err))
+			}
+		}
+	} else {
+		sp := ui.NewSpinner("Generating Config.xcconfig")
+		sp.Start()
+
+		outputPath = cfg.GetXcconfigPath()
+		generator := xcode.NewXcconfigGenerator(outputPath)
+		generator.VarPrefix = cfg.Supabase.EnvPrefix
+		if generator.Seal, err = envSecretSealer(cfg, info.ProjectRef); err != nil {
+			sp.Fail("Failed to initialize secret store")
+			return err
+		}
+		generator.CachedAt = creds.cachedAt()
+
+		applyXcconfigBanner(cfg, generator, info.Environment)
+
+		if err := generator.GenerateFromBranchInfo(info, anonKey); err != nil {
+			sp.Fail("Failed to generate xcconfig")
+			return err
+		}
+
+		sp.Success("Config.xcconfig generated")
+		generateIconBadge(cfg, info.Environment)
+
+		if cfg.Xcode.Sync.Enabled {
+			if err := syncPlistsForEnvironment(cfg, string(info.Environment)); err != nil {
+				ui.Warning(fmt.Sprintf("Could not sync plist values: %v", err))
+			}
+		}
+
+		// Copy custom variables from another worktree (interactive picker)
+		if envCopyEnvFlag {
+			xcconfigName := filepath.Base(cfg.GetXcconfigPath())
+			sourcePath, err := selectWorktreeConfigFile(cfg, xcconfigName)
+			if err != nil {
+				ui.Warning(fmt.Sprintf("Could not select worktree: %v", err))
+			} else if sourcePath != "" {
+				if err := copyXcconfigCustomVariables(sourcePath, outputPath); err != nil {
+					ui.Warning(fmt.Sprintf("Could not copy custom variables: %v", err))
+				}
+			}
+		}
+
+		// Generate buildServer.json if requested (only for Apple platforms)
+		if envBuildServerFlag {
+			if err := generateBuildServer(cfg, info, envSchemeFlag); err != nil {
+				ui.Warning(fmt.Sprintf("Could not generate buildServer.json: %v", err))
+			} else if bs, err := xcode.ReadBuildServerConfig(xcode.BuildServerFile); err == nil {
+				reportBuildServerProblems(bs)
+			}
+		} else {
+			refreshBuildServerIfStale(cfg, info)
+		}
+	}
+
+	// Display summary
+	ui.NewLine()
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+	ui.KeyValue("Output", outputPath)
+	if cfg.Encryption.Enabled {
+		ui.KeyValue("Secrets", fmt.Sprintf("encrypted (%s) - use 'drift run' to decrypt", cfg.Encryption.Backend))
+	}
+	checkSecretReferences(outputPath)
+
+	envKeys := map[string]string{"SUPABASE_ANON_KEY": anonKey}
+	if cfg.Project.IsWebPlatform() {
+		envKeys["SUPABASE_SERVICE_ROLE_KEY"] = serviceRoleKey
+	}
+	recordEnvLock(cfg, info, outputPath, envKeys)
+
+	return finishEnvSetupReview(cfg, review, info, outputPath)
+}
+
+// fetchEnvCredentials resolves the Supabase branch for env setup and
+// fetches its API keys, plus the database secrets for web projects.
+func fetchEnvCredentials(ctx *Context, review *envReview) (*envCredentials, error) {
+	cfg := ctx.Config()
+	client := ctx.Client()
+
+	var info *supabase.BranchInfo
+	var err error
+	if review != nil {
+		sp := ui.NewSpinner("Resolving Supabase branch")
+		sp.Start()
+		info, err = resolveReviewTarget(client, review)
+		if err != nil {
+			sp.Fail("Failed to resolve Supabase branch")
+			return nil, err
+		}
+		sp.Stop()
+	} else if info, err = ctx.Target(envBranchFlag); err != nil {
+		return nil, err
+	}
+	if err := noteBranchProvisioning(client, info.SupabaseBranch); err != nil {
+		return nil, err
 	}
-	sp.Stop()
 
 	if info.IsOverride {
 		ui.Infof("Override: using %s instead of %s", ui.Cyan(info.SupabaseBranch.Name), ui.Cyan(info.OverrideFrom))
@@ -264,7 +528,7 @@ func runEnvSetup(cmd *cobra.Command, args []string) error {
 	}
 
 	// Fetch API keys and secrets
-	sp = ui.NewSpinner("Fetching API keys")
+	sp := ui.NewSpinner("Fetching API keys")
 	sp.Start()
 
 	var anonKey, serviceRoleKey string
@@ -284,13 +548,14 @@ func runEnvSetup(cmd *cobra.Command, args []string) error {
 					DirectDatabaseURL: secrets.PostgresURLNonPooling,
 					PoolerDatabaseURL: secrets.PostgresURL,
 				}
+				applyDBPasswordOverride(cfg, info.ProjectRef, webSecrets)
 			}
 		} else {
 			// Fallback to API keys method
 			anonKey, err = client.GetAnonKey(info.ProjectRef)
 			if err != nil {
 				sp.Fail("Failed to fetch API keys")
-				return fmt.Errorf("failed to get anon key: %w", err)
+				return nil, fmt.Errorf("failed to get anon key: %w", err)
 			}
 			if cfg.Project.IsWebPlatform() {
 				serviceRoleKey, _ = client.GetServiceKey(info.ProjectRef)
@@ -298,6 +563,7 @@ func runEnvSetup(cmd *cobra.Command, args []string) error {
 					AnonKey:        anonKey,
 					ServiceRoleKey: serviceRoleKey,
 				}
+				applyDBPasswordOverride(cfg, info.ProjectRef, webSecrets)
 			}
 		}
 	} else {
@@ -305,7 +571,7 @@ func runEnvSetup(cmd *cobra.Command, args []string) error {
 		anonKey, err = client.GetAnonKey(info.ProjectRef)
 		if err != nil {
 			sp.Fail("Failed to fetch API keys")
-			return fmt.Errorf("failed to get anon key: %w", err)
+			return nil, fmt.Errorf("failed to get anon key: %w", err)
 		}
 		if cfg.Project.IsWebPlatform() {
 			serviceRoleKey, _ = client.GetServiceKey(info.ProjectRef)
@@ -317,88 +583,137 @@ func runEnvSetup(cmd *cobra.Command, args []string) error {
 	}
 	sp.Stop()
 
-	// Generate config file based on project type
-	var outputPath string
+	return &envCredentials{
+		Info:           info,
+		AnonKey:        anonKey,
+		ServiceRoleKey: serviceRoleKey,
+		Web:            webSecrets,
+		FetchedAt:      time.Now(),
+	}, nil
+}
 
+// runEnvSetupSchemeVariants writes one xcconfig per entry in xcode.schemes.
+func runEnvSetupSchemeVariants(cfg *config.Config, client *supabase.Client, gitBranch string) error {
 	if cfg.Project.IsWebPlatform() {
-		sp = ui.NewSpinner("Generating .env.local")
-		sp.Start()
-
-		outputPath = cfg.GetEnvLocalPath()
-		generator := web.NewEnvLocalGenerator(outputPath)
+		return fmt.Errorf("--all-schemes is only supported for iOS/macOS projects")
+	}
+	if len(cfg.Xcode.Schemes) == 0 {
+		return fmt.Errorf("no schemes configured; add xcode.schemes to .drift.yaml")
+	}
 
-		if err := generator.GenerateFromBranchInfo(info, webSecrets); err != nil {
-			sp.Fail("Failed to generate .env.local")
-			return err
-		}
+	envs := make([]string, 0, len(cfg.Xcode.Schemes))
+	for env := range cfg.Xcode.Schemes {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
 
-		sp.Success(".env.local generated")
+	for _, env := range envs {
+		scheme := cfg.Xcode.Schemes[env]
 
-		// Copy custom variables from another worktree (interactive picker)
-		if envCopyEnvFlag {
-			sourcePath, err := selectWorktreeConfigFile(cfg, ".env.local")
-			if err != nil {
-				ui.Warning(fmt.Sprintf("Could not select worktree: %v", err))
-			} else if sourcePath != "" {
-				envCopyCustomFromFlag = sourcePath
-			}
+		sp := ui.NewSpinner(fmt.Sprintf("Resolving Supabase branch for %s", scheme))
+		sp.Start()
+		info, err := resolveSchemeVariantTarget(client, cfg, gitBranch, env)
+		if err != nil {
+			sp.Fail(fmt.Sprintf("Failed to resolve Supabase branch for %s", scheme))
+			return err
 		}
 
-		// Copy custom variables from another file if requested
-		if envCopyCustomFromFlag != "" {
-			if err := copyCustomVariables(envCopyCustomFromFlag, outputPath); err != nil {
-				ui.Warning(fmt.Sprintf("Could not copy custom variables: %v", err))
-			}
+		anonKey, err := fetchAnonKeyForBranch(client, info)
+		if err != nil {
+			sp.Fail("Failed to fetch API keys")
+			return err
 		}
-	} else {
-		sp = ui.NewSpinner("Generating Config.xcconfig")
-		sp.Start()
 
-		outputPath = cfg.GetXcconfigPath()
+		// Name the variant after the scheme key: keys other than production
+		// and development can resolve to the same environment.
+		outputPath := xcode.VariantPath(cfg.GetXcconfigPath(), variantSuffix(env))
 		generator := xcode.NewXcconfigGenerator(outputPath)
-
+		generator.VarPrefix = cfg.Supabase.EnvPrefix
+		if generator.Seal, err = envSecretSealer(cfg, info.ProjectRef); err != nil {
+			sp.Fail("Failed to initialize secret store")
+			return err
+		}
+		applyXcconfigBanner(cfg, generator, info.Environment)
 		if err := generator.GenerateFromBranchInfo(info, anonKey); err != nil {
-			sp.Fail("Failed to generate xcconfig")
+			sp.Fail(fmt.Sprintf("Failed to generate %s", filepath.Base(outputPath)))
 			return err
 		}
+		sp.Success(fmt.Sprintf("%s generated", filepath.Base(outputPath)))
+		generateIconBadge(cfg, info.Environment)
 
-		sp.Success("Config.xcconfig generated")
+		ui.KeyValue("Scheme", scheme)
+		ui.KeyValue("Environment", envColorString(string(info.Environment)))
+		ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+		ui.KeyValue("Output", outputPath)
+		ui.NewLine()
+	}
 
-		// Copy custom variables from another worktree (interactive picker)
-		if envCopyEnvFlag {
-			xcconfigName := filepath.Base(cfg.GetXcconfigPath())
-			sourcePath, err := selectWorktreeConfigFile(cfg, xcconfigName)
-			if err != nil {
-				ui.Warning(fmt.Sprintf("Could not select worktree: %v", err))
-			} else if sourcePath != "" {
-				if err := copyXcconfigCustomVariables(sourcePath, outputPath); err != nil {
-					ui.Warning(fmt.Sprintf("Could not copy custom variables: %v", err))
-				}
-			}
-		}
+	ui.Info("Point each scheme's build configuration at its variant in Xcode (Project > Info > Configurations)")
+	return nil
+}
 
-		// Generate buildServer.json if requested (only for Apple platforms)
-		if envBuildServerFlag {
-			if err := generateBuildServer(cfg, info, envSchemeFlag); err != nil {
-				ui.Warning(fmt.Sprintf("Could not generate buildServer.json: %v", err))
-			}
+// resolveSchemeVariantTarget picks the Supabase branch for an xcode.schemes entry.
+// Production and development map to the project's fixed branches; any other
+// key follows the normal resolution for the current git branch.
+func resolveSchemeVariantTarget(client *supabase.Client, cfg *config.Config, gitBranch, env string) (*supabase.BranchInfo, error) {
+	base := &supabase.BranchInfo{GitBranch: gitBranch}
+
+	switch strings.ToLower(env) {
+	case "production":
+		branch, err := client.GetProductionBranch()
+		if err != nil {
+			return nil, err
 		}
+		return client.NewBranchInfo(base, branch, supabase.EnvProduction, false), nil
+	case "development":
+		branch, err := client.GetDevelopmentBranch()
+		if err != nil {
+			return nil, err
+		}
+		return client.NewBranchInfo(base, branch, supabase.EnvDevelopment, false), nil
+	default:
+		return ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, envBranchFlag)
 	}
+}
 
-	// Display summary
-	ui.NewLine()
-	ui.KeyValue("Environment", envColorString(string(info.Environment)))
-	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
-	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
-	ui.KeyValue("Output", outputPath)
+// reportServiceRolePolicy tells the user where the service role key went.
+func reportServiceRolePolicy(cfg *config.Config, secrets *web.BranchSecretsInput) {
+	if secrets == nil || secrets.ServiceRoleKey == "" {
+		return
+	}
+	if cfg.Web.Framework == web.FrameworkFlutter {
+		ui.Infof("SUPABASE_SERVICE_ROLE_KEY omitted (dart defines are compiled into the app)")
+		return
+	}
+	switch cfg.Web.ServiceRoleKey {
+	case web.ServiceRoleDeny:
+		if envAllowServiceRoleFlag {
+			ui.Warningf("SUPABASE_SERVICE_ROLE_KEY was written to %s (--allow-service-role-key)", cfg.Web.EnvOutput)
+			ui.Warning("This key bypasses Row Level Security. Never expose it to the browser or commit it.")
+			return
+		}
+		ui.Infof("SUPABASE_SERVICE_ROLE_KEY omitted (web.service_role_key: deny)")
+	case web.ServiceRoleServerOnly:
+		ui.Infof("SUPABASE_SERVICE_ROLE_KEY written to %s", cfg.Web.ServerEnvOutput)
+	}
+}
 
-	return nil
+// envSecretSealer returns the generator seal hook when encryption is enabled, or nil otherwise.
+func envSecretSealer(cfg *config.Config, projectRef string) (func(key, value string) (string, error), error) {
+	if !cfg.Encryption.Enabled {
+		return nil, nil
+	}
+	store, err := envcrypt.NewStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return envcrypt.NewSealer(store, cfg.Encryption.Keys, projectRef), nil
 }
 
-func runEnvSwitch(cmd *cobra.Command, args []string) error {
-	targetBranch := args[0]
+func runEnvSwitch(ctx *Context) error {
+	targetBranch := ctx.Args[0]
 	envBranchFlag = targetBranch
-	return runEnvSetup(cmd, args)
+	return runEnvSetup(ctx)
 }
 
 // envColorString returns the colored environment string.
@@ -413,9 +728,12 @@ func envColorString(env string) string {
 	}
 }
 
-// runEnvSetupCI generates Config.xcconfig or .env.local from environment variables.
-// This is used in CI pipelines where Supabase CLI is not available or configured.
-func runEnvSetupCI(cfg *config.Config) error {
+// runEnvSetupCI generates Config.xcconfig or .env.local from environment
+// variables, for CI pipelines where the Supabase CLI is not available or
+// configured. DRIFT_ENVIRONMENT and DRIFT_SUPABASE_BRANCH label the build;
+// without them it is a feature build of branch "ci". A non-nil printOut
+// prints the config there instead of writing it (see --print).
+func runEnvSetupCI(cfg *config.Config, printOut io.Writer) error {
 	ui.Info("CI mode: reading credentials from environment variables")
 
 	// Read required environment variables
@@ -429,11 +747,27 @@ func runEnvSetupCI(cfg *config.Config) error {
 		return fmt.Errorf("SUPABASE_ANON_KEY environment variable is not set")
 	}
 
+	environment, err := ciEnvironment(os.Getenv("DRIFT_ENVIRONMENT"))
+	if err != nil {
+		return err
+	}
+	supabaseBranch := os.Getenv("DRIFT_SUPABASE_BRANCH")
+	if supabaseBranch == "" {
+		supabaseBranch = "ci"
+	}
+
 	// Get git branch (optional in CI, may not have full git context)
 	gitBranch := "ci"
 	if branch, err := git.CurrentBranch(); err == nil {
 		gitBranch = branch
 	}
+	info := &supabase.BranchInfo{
+		GitBranch:      gitBranch,
+		Environment:    environment,
+		APIURL:         supabaseURL,
+		ProjectRef:     extractProjectRef(cfg, supabaseURL),
+		SupabaseBranch: &supabase.Branch{Name: supabaseBranch},
+	}
 
 	// Generate config file based on project type
 	var outputPath string
@@ -441,42 +775,33 @@ func runEnvSetupCI(cfg *config.Config) error {
 	if cfg.Project.IsWebPlatform() {
 		outputPath = cfg.GetEnvLocalPath()
 		generator := web.NewEnvLocalGenerator(outputPath)
-
-		// Create minimal BranchInfo for CI
-		info := &supabase.BranchInfo{
-			GitBranch:   gitBranch,
-			Environment: supabase.EnvFeature, // Default to feature for CI
-			APIURL:      supabaseURL,
-			ProjectRef:  extractProjectRef(supabaseURL),
-			SupabaseBranch: &supabase.Branch{
-				Name: "ci",
-			},
-		}
+		generator.VarPrefix = cfg.Supabase.EnvPrefix
+		generator.Framework = cfg.Web.Framework
 
 		webSecrets := &web.BranchSecretsInput{
 			AnonKey: supabaseAnonKey,
 		}
+		if printOut != nil {
+			return printEnvSetup(printOut, cfg, info, supabaseAnonKey, webSecrets)
+		}
 
+		applyEnvLocalBanner(cfg, generator, info.Environment)
+		applyEnvLocalPorts(cfg, generator, true)
 		if err := generator.GenerateFromBranchInfo(info, webSecrets); err != nil {
-			return fmt.Errorf("failed to generate .env.local: %w", err)
+			return fmt.Errorf("failed to generate %s: %w", cfg.Web.EnvOutput, err)
 		}
 
-		ui.Success(".env.local generated from environment variables")
+		ui.Successf("%s generated from environment variables", cfg.Web.EnvOutput)
 	} else {
 		outputPath = cfg.GetXcconfigPath()
 		generator := xcode.NewXcconfigGenerator(outputPath)
+		generator.VarPrefix = cfg.Supabase.EnvPrefix
 
-		// Create minimal BranchInfo for CI
-		info := &supabase.BranchInfo{
-			GitBranch:   gitBranch,
-			Environment: supabase.EnvFeature, // Default to feature for CI
-			APIURL:      supabaseURL,
-			ProjectRef:  extractProjectRef(supabaseURL),
-			SupabaseBranch: &supabase.Branch{
-				Name: "ci",
-			},
+		if printOut != nil {
+			return printEnvSetup(printOut, cfg, info, supabaseAnonKey, nil)
 		}
 
+		applyXcconfigBanner(cfg, generator, info.Environment)
 		if err := generator.GenerateFromBranchInfo(info, supabaseAnonKey); err != nil {
 			return fmt.Errorf("failed to generate Config.xcconfig: %w", err)
 		}
@@ -484,27 +809,46 @@ func runEnvSetupCI(cfg *config.Config) error {
 		ui.Success("Config.xcconfig generated from environment variables")
 	}
 
+	// No branch to record; drop a lock left from an earlier setup
+	if err := os.Remove(envLockPath(outputPath)); err != nil && !os.IsNotExist(err) {
+		ui.Warning(fmt.Sprintf("Could not remove %s: %v", envLockPath(outputPath), err))
+	}
+
 	// Display summary
 	ui.NewLine()
 	ui.KeyValue("Mode", ui.Cyan("CI"))
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
 	ui.KeyValue("Supabase URL", ui.Cyan(supabaseURL))
-	ui.KeyValue("Anon Key", ui.Cyan(maskValue(supabaseAnonKey)))
+	ui.KeyValue("Anon Key", ui.Cyan(maskPolicy(cfg).Value(supabaseAnonKey)))
 	ui.KeyValue("Output", outputPath)
 
 	return nil
 }
 
-// extractProjectRef attempts to extract the project ref from a Supabase URL.
-// Example: https://abcdefgh.supabase.co -> abcdefgh
-func extractProjectRef(url string) string {
-	// Remove protocol
-	url = strings.TrimPrefix(url, "https://")
-	url = strings.TrimPrefix(url, "http://")
+// ciEnvironment parses DRIFT_ENVIRONMENT for CI mode: production (prod),
+// development (dev), or feature, in any case. Empty means feature.
+func ciEnvironment(value string) (supabase.Environment, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "feature":
+		return supabase.EnvFeature, nil
+	case "production", "prod":
+		return supabase.EnvProduction, nil
+	case "development", "dev":
+		return supabase.EnvDevelopment, nil
+	}
+	return "", errs.Configf("invalid DRIFT_ENVIRONMENT %q (use production, development, or feature)", value)
+}
 
-	// Extract subdomain
-	parts := strings.Split(url, ".")
-	if len(parts) > 0 {
-		return parts[0]
+// extractProjectRef attempts to extract the project ref from a Supabase URL
+// using the configured API URL template.
+// Example: https://abcdefgh.supabase.co -> abcdefgh
+// Self-hosted URLs without a ref fall back to supabase.project_ref.
+func extractProjectRef(cfg *config.Config, url string) string {
+	if ref := supabase.ProjectRefFromURL(url); ref != "" {
+		return ref
+	}
+	if cfg.Supabase.ProjectRef != "" {
+		return cfg.Supabase.ProjectRef
 	}
 	return "unknown"
 }
@@ -652,14 +996,17 @@ func schemeExists(scheme string) bool {
 		}
 	}
 
-	// Also check if we can list schemes
-	result, err := shell.Run("xcodebuild", "-list", "-json")
+	// Fall back to asking xcodebuild (covers workspace-only schemes)
+	names, err := xcode.ListSchemesViaXcodebuild()
 	if err != nil {
 		return false
 	}
-
-	// Simple string check (not full JSON parsing for simplicity)
-	return len(result.Stdout) > 0 && (filepath.Base(scheme) != "" || os.Getenv("DRIFT_DEBUG") != "")
+	for _, name := range names {
+		if name == scheme {
+			return true
+		}
+	}
+	return false
 }
 
 // ensureSupabaseLinked checks if Supabase is linked in the current directory,
@@ -667,7 +1014,7 @@ func schemeExists(scheme string) bool {
 func ensureSupabaseLinked(cfg *config.Config) error {
 	// Check if Supabase CLI is available
 	if !shell.CommandExists("supabase") {
-		return fmt.Errorf("Supabase CLI not found. Install with: brew install supabase/tap/supabase")
+		return errs.Configf("Supabase CLI not found. Install with: brew install supabase/tap/supabase")
 	}
 
 	// Check if already linked by trying to list branches
@@ -685,7 +1032,7 @@ func ensureSupabaseLinked(cfg *config.Config) error {
 	if !strings.Contains(errMsg, "Have you run supabase link") {
 		// Some other error (auth, network, etc.) - report it instead of silently ignoring
 		if strings.Contains(errMsg, "not logged in") || strings.Contains(errMsg, "Access token") {
-			return fmt.Errorf("Supabase CLI not authenticated. Run 'supabase login' first")
+			return errs.Authf("Supabase CLI not authenticated. Run 'supabase login' first")
 		}
 		// For other errors, warn but continue (might be a network blip)
 		if errMsg != "" {
@@ -745,6 +1092,10 @@ func ensureSupabaseLinked(cfg *config.Config) error {
 // copyCustomVariables copies custom (non-drift-managed) variables from a source
 // .env.local file to a destination file.
 func copyCustomVariables(sourcePath, destPath string) error {
+	if strings.HasSuffix(destPath, ".json") {
+		return fmt.Errorf("copying custom variables is not supported for %s", filepath.Base(destPath))
+	}
+
 	// Read the source file
 	sourceData, err := os.ReadFile(sourcePath)
 	if err != nil {
@@ -869,11 +1220,8 @@ func copyXcconfigCustomVariables(sourcePath, destPath string) error {
 	return nil
 }
 
-func runEnvValidate(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runEnvValidate(ctx *Context) error {
+	cfg := ctx.Config()
 
 	ui.Header("Environment Validation")
 
@@ -929,24 +1277,19 @@ func runEnvValidate(cmd *cobra.Command, args []string) error {
 		ui.SubHeader("Required Variables")
 
 		if cfg.Project.IsWebPlatform() {
-			// Check for NEXT_PUBLIC_SUPABASE_URL and NEXT_PUBLIC_SUPABASE_ANON_KEY
-			requiredVars := []string{"NEXT_PUBLIC_SUPABASE_URL", "NEXT_PUBLIC_SUPABASE_ANON_KEY"}
+			// Check for e.g. NEXT_PUBLIC_SUPABASE_URL and NEXT_PUBLIC_SUPABASE_ANON_KEY
+			prefix := web.PublicPrefix(cfg.Web.Framework)
+			requiredVars := []string{prefix + "SUPABASE_URL", prefix + "SUPABASE_ANON_KEY"}
+			values, _ := web.ReadEnvLocal(envFilePath)
 			allPresent := true
 			for _, v := range requiredVars {
-				if strings.Contains(envFileContent, v+"=") {
+				if value, ok := values[v]; ok {
 					// Check if value is not empty
-					for _, line := range strings.Split(envFileContent, "\n") {
-						if strings.HasPrefix(line, v+"=") {
-							value := strings.TrimPrefix(line, v+"=")
-							value = strings.Trim(value, "\"' ")
-							if value != "" {
-								fmt.Printf("  %s %s\n", ui.Green("✓"), v)
-							} else {
-								fmt.Printf("  %s %s %s\n", ui.Red("✗"), v, ui.Red("(empty)"))
-								allPresent = false
-							}
-							break
-						}
+					if strings.Trim(value, "\"' ") != "" {
+						fmt.Printf("  %s %s\n", ui.Green("✓"), v)
+					} else {
+						fmt.Printf("  %s %s %s\n", ui.Red("✗"), v, ui.Red("(empty)"))
+						allPresent = false
 					}
 				} else {
 					fmt.Printf("  %s %s %s\n", ui.Red("✗"), v, ui.Red("(missing)"))
@@ -1019,25 +1362,34 @@ func runEnvValidate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Check 6: Env file still matches the branch it was generated from
+	if envFileContent != "" {
+		totalChecks++
+		ui.NewLine()
+		ui.SubHeader("Environment Lock")
+		if envLockStillValid(cfg, envFilePath) {
+			validCount++
+		} else {
+			hasErrors = true
+		}
+	}
+
 	// Summary
 	ui.NewLine()
 	if hasErrors {
 		ui.Warningf("Validation complete: %d/%d checks passed", validCount, totalChecks)
-		return fmt.Errorf("validation failed")
+		return errs.Validationf("validation failed")
 	}
 
 	ui.Successf("All %d validation checks passed", totalChecks)
 	return nil
 }
 
-func runEnvDiff(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runEnvDiff(ctx *Context) error {
+	cfg := ctx.Config()
 
-	branch1 := args[0]
-	branch2 := args[1]
+	branch1 := ctx.Args[0]
+	branch2 := ctx.Args[1]
 
 	ui.Header("Environment Diff")
 	ui.Infof("Comparing: %s vs %s", ui.Cyan(branch1), ui.Cyan(branch2))
@@ -1066,55 +1418,52 @@ func runEnvDiff(cmd *cobra.Command, args []string) error {
 	path1 := filepath.Join(wt1.Path, configFileName)
 	path2 := filepath.Join(wt2.Path, configFileName)
 
-	data1, err1 := os.ReadFile(path1)
-	data2, err2 := os.ReadFile(path2)
+	vars1, err1 := readEnvFileVariables(path1)
+	vars2, err2 := readEnvFileVariables(path2)
 
 	if err1 != nil && err2 != nil {
 		return fmt.Errorf("neither worktree has %s", configFileName)
 	}
-
-	// Parse variables from each file
-	vars1 := parseEnvVariables(string(data1))
-	vars2 := parseEnvVariables(string(data2))
+	prefix := web.PublicPrefix(cfg.Web.Framework)
+	policy := displayPolicy(ctx.Cmd, cfg)
 
 	// Compare and display differences
 	ui.SubHeader("Supabase Configuration")
 
-	// List of variables to compare (with masking for sensitive ones)
-	compareVars := []struct {
-		name   string
-		masked bool
-	}{
-		{"SUPABASE_URL", false},
-		{"NEXT_PUBLIC_SUPABASE_URL", false},
-		{"SUPABASE_ANON_KEY", true},
-		{"NEXT_PUBLIC_SUPABASE_ANON_KEY", true},
-		{"SUPABASE_PROJECT_REF", false},
-		{"DRIFT_ENVIRONMENT", false},
-		{"DRIFT_SUPABASE_BRANCH", false},
+	// Variables drift manages, compared first
+	compareVars := []string{
+		"SUPABASE_URL",
+		prefix + "SUPABASE_URL",
+		"SUPABASE_ANON_KEY",
+		prefix + "SUPABASE_ANON_KEY",
+		"SUPABASE_PROJECT_REF",
+		"DRIFT_ENVIRONMENT",
+		"DRIFT_SUPABASE_BRANCH",
 	}
 
 	hasDiff := false
-	for _, v := range compareVars {
-		val1 := vars1[v.name]
-		val2 := vars2[v.name]
-
-		if val1 == "" && val2 == "" {
+	seen := make(map[string]bool, len(compareVars))
+	for _, name := range compareVars {
+		// Flutter has no public prefix, so its names repeat the unprefixed ones.
+		if seen[name] {
 			continue
 		}
+		seen[name] = true
+		val1 := vars1[name]
+		val2 := vars2[name]
 
-		if v.masked {
-			val1 = maskValue(val1)
-			val2 = maskValue(val2)
+		if val1 == "" && val2 == "" {
+			continue
 		}
 
+		// Compare before masking: different secrets can mask the same.
 		if val1 == val2 {
-			fmt.Printf("  %s = %s (same)\n", v.name, ui.Dim(truncateValue(val1, 40)))
+			fmt.Printf("  %s = %s (same)\n", name, ui.Dim(truncateValue(policy.Display(name, val1), 40)))
 		} else {
 			hasDiff = true
-			fmt.Printf("  %s:\n", ui.Yellow(v.name))
-			fmt.Printf("    %s: %s\n", ui.Cyan(branch1), truncateValue(val1, 50))
-			fmt.Printf("    %s: %s\n", ui.Cyan(branch2), truncateValue(val2, 50))
+			fmt.Printf("  %s:\n", ui.Yellow(name))
+			fmt.Printf("    %s: %s\n", ui.Cyan(branch1), truncateValue(policy.Display(name, val1), 50))
+			fmt.Printf("    %s: %s\n", ui.Cyan(branch2), truncateValue(policy.Display(name, val2), 50))
 		}
 	}
 
@@ -1131,15 +1480,15 @@ func runEnvDiff(cmd *cobra.Command, args []string) error {
 		allVars[k] = true
 	}
 
-	// Filter out the compared ones
-	comparedNames := make(map[string]bool)
-	for _, v := range compareVars {
-		comparedNames[v.name] = true
+	names := make([]string, 0, len(allVars))
+	for name := range allVars {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
 	customDiff := false
-	for name := range allVars {
-		if comparedNames[name] {
+	for _, name := range names {
+		if seen[name] {
 			continue
 		}
 		// Skip comments and empty lines
@@ -1158,7 +1507,9 @@ func runEnvDiff(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  %s: only in %s\n", ui.Yellow(name), ui.Cyan(branch1))
 		} else if val1 != val2 {
 			customDiff = true
-			fmt.Printf("  %s: different values\n", ui.Yellow(name))
+			fmt.Printf("  %s:\n", ui.Yellow(name))
+			fmt.Printf("    %s: %s\n", ui.Cyan(branch1), truncateValue(policy.Display(name, val1), 50))
+			fmt.Printf("    %s: %s\n", ui.Cyan(branch2), truncateValue(policy.Display(name, val2), 50))
 		}
 	}
 
@@ -1204,12 +1555,17 @@ func parseEnvVariables(content string) map[string]string {
 	return vars
 }
 
-// maskValue masks a sensitive value, showing only the first and last few characters.
-func maskValue(value string) string {
-	if len(value) <= 8 {
-		return "****"
+// readEnvFileVariables reads the variables from a generated env file:
+// KEY=VALUE or xcconfig lines, or a dart-define JSON object.
+func readEnvFileVariables(path string) (map[string]string, error) {
+	if strings.HasSuffix(path, ".json") {
+		return web.ReadEnvLocal(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
-	return value[:4] + "****" + value[len(value)-4:]
+	return parseEnvVariables(string(data)), nil
 }
 
 // truncateValue truncates a value to the specified length.
@@ -1219,3 +1575,26 @@ func truncateValue(value string, maxLen int) string {
 	}
 	return value[:maxLen-3] + "..."
 }
+
+// checkSecretReferences reads the op:// and vault: references in the
+// generated file's custom variables so a missing CLI, expired session or
+// mistyped path shows up at setup rather than at 'drift run'. The resolved
+// values are discarded; only the references are kept on disk.
+func checkSecretReferences(path string) {
+	values, err := readGeneratedEnv(path)
+	if err != nil {
+		return
+	}
+	refs := make(map[string]string)
+	for _, key := range secretref.Keys(values) {
+		refs[key] = values[key]
+	}
+	if len(refs) == 0 {
+		return
+	}
+	if _, err := secretref.ResolveAll(refs); err != nil {
+		ui.Warningf("%v", err)
+		return
+	}
+	ui.KeyValue("Secret refs", fmt.Sprintf("%d from 1Password/Vault - use 'drift run' to load them", len(refs)))
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+// applyXcconfigBanner makes g write the environment banner for env when
+// banner.enabled is set.
+func applyXcconfigBanner(cfg *config.Config, g *xcode.XcconfigGenerator, env supabase.Environment) {
+	if !cfg.Banner.Enabled {
+		return
+	}
+	g.ShowBanner = true
+	g.BannerLabel = cfg.Banner.LabelFor(string(env))
+	g.AppIconSuffix = cfg.Banner.IconSuffixFor(string(env))
+}
+
+// applyEnvLocalBanner makes g write the environment banner for env when
+// banner.enabled is set.
+func applyEnvLocalBanner(cfg *config.Config, g *web.EnvLocalGenerator, env supabase.Environment) {
+	if !cfg.Banner.Enabled {
+		return
+	}
+	g.ShowBanner = true
+	g.BannerLabel = cfg.Banner.LabelFor(string(env))
+}
+
+// generateIconBadge writes the badged app icon for env when
+// banner.icon_badge.app_icon is set. Failures are warnings: a missing badge
+// should not stop env setup.
+func generateIconBadge(cfg *config.Config, env supabase.Environment) {
+	if !cfg.Banner.Enabled || cfg.Banner.IconBadge.AppIcon == "" {
+		return
+	}
+	suffix := cfg.Banner.IconSuffixFor(string(env))
+	if suffix == "" {
+		return
+	}
+
+	appIcon := cfg.Banner.IconBadge.AppIcon
+	if !filepath.IsAbs(appIcon) {
+		appIcon = filepath.Join(cfg.ProjectRoot(), appIcon)
+	}
+	dest, err := xcode.GenerateBadgedIconSet(appIcon, suffix, xcode.BadgeColor(string(env)))
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not generate badged app icon: %v", err))
+		return
+	}
+	ui.Successf("%s generated", filepath.Base(dest))
+}
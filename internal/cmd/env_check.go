@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+var envCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the generated env file matches the current branch",
+	Long: `Check that the generated env file (.env.local, Config.xcconfig, ...) still
+points at the Supabase branch the current git branch resolves to.
+
+The check uses cached branch data when it can, so it is fast enough to run
+from git hooks ('drift hooks install'), and only calls the Supabase API when
+nothing is cached. Exits with code 6 on a mismatch.
+
+When preferences.env_refresh in .drift.local.yaml is "regenerate", a
+mismatched file is regenerated with 'drift env setup' instead. --fix does the
+same for one run.`,
+	Example: `  drift env check
+  drift env check --quiet   # Print nothing unless the file is stale (used by hooks)
+  drift env check --fix     # Regenerate a stale file`,
+	Args: cobra.NoArgs,
+	RunE: Run(runEnvCheck, RequireProject),
+}
+
+var (
+	envCheckQuietFlag bool
+	envCheckFixFlag   bool
+)
+
+func init() {
+	envCheckCmd.Flags().BoolVarP(&envCheckQuietFlag, "quiet", "q", false, "Only print when the env file does not match")
+	envCheckCmd.Flags().BoolVar(&envCheckFixFlag, "fix", false, "Regenerate the env file when it does not match")
+	envCmd.AddCommand(envCheckCmd)
+}
+
+// generatedEnv is what a generated env file says it was generated for.
+type generatedEnv struct {
+	Path           string
+	GitBranch      string
+	SupabaseBranch string // without the (fallback)/(override) suffix
+	Fallback       bool
+}
+
+// readGeneratedEnvFile reads the branch markers drift writes into the env
+// file. ok is false when the file does not exist.
+func readGeneratedEnvFile(cfg *config.Config) (*generatedEnv, bool, error) {
+	root := cfg.ProjectRoot()
+	prefix := cfg.Supabase.EnvPrefix
+	var values map[string]string
+	var err error
+	gen := &generatedEnv{}
+	if cfg.Project.IsWebPlatform() {
+		gen.Path = filepath.Join(root, cfg.Web.EnvOutput)
+		values, err = web.ReadEnvLocal(gen.Path)
+		if err == nil {
+			gen.GitBranch, _ = web.PublicValue(values, prefix+"GIT_BRANCH")
+			gen.SupabaseBranch, _ = web.PublicValue(values, prefix+"SUPABASE_BRANCH")
+		}
+	} else {
+		gen.Path = filepath.Join(root, cfg.Xcode.XcconfigOutput)
+		values, err = xcode.ReadXcconfig(gen.Path)
+		if err == nil {
+			gen.GitBranch = values[prefix+"GIT_BRANCH_NAME"]
+			gen.SupabaseBranch = values[prefix+"SUPABASE_BRANCH_NAME"]
+		}
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return gen, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	gen.SupabaseBranch, gen.Fallback = parseSupabaseBranchDisplay(gen.SupabaseBranch)
+	return gen, true, nil
+}
+
+// parseSupabaseBranchDisplay undoes SupabaseBranchDisplay: it strips the
+// " (fallback)" or " (override)" suffix and reports whether it was a
+// fallback.
+func parseSupabaseBranchDisplay(display string) (string, bool) {
+	display = strings.TrimSpace(display)
+	if name, ok := strings.CutSuffix(display, " (fallback)"); ok {
+		return name, true
+	}
+	return strings.TrimSuffix(display, " (override)"), false
+}
+
+// resolveEnvCheckTarget resolves gitBranch the way 'drift env setup' does,
+// without prompting, from cached branches when possible. It returns nil
+// when the branch has no match and no fallback is configured.
+func resolveEnvCheckTarget(ctx *Context, gitBranch string) (*supabase.BranchInfo, error) {
+	cfg := ctx.Config()
+	fallback := GetFallbackBranch()
+	if fallback == "" {
+		fallback = cfg.Supabase.FallbackBranch
+	}
+	opts := supabase.TargetOptions{
+		GitBranch:      gitBranch,
+		OverrideBranch: cfg.Supabase.OverrideBranch,
+		FallbackBranch: fallback,
+	}
+
+	wasOffline := supabase.IsOffline()
+	supabase.SetOffline(true)
+	info, err := ctx.Client().ResolveTarget(opts)
+	supabase.SetOffline(wasOffline)
+	if err != nil && !wasOffline && !errors.Is(err, supabase.ErrNoBranchMatch) {
+		info, err = ctx.Client().ResolveTarget(opts)
+	}
+	if errors.Is(err, supabase.ErrNoBranchMatch) {
+		return nil, nil
+	}
+	return info, err
+}
+
+func runEnvCheck(ctx *Context) error {
+	cfg := ctx.Config()
+	gitBranch, err := git.CurrentBranch()
+	if err != nil || gitBranch == "" || gitBranch == "HEAD" {
+		// Detached checkouts (rebases, PR reviews) have no branch to match.
+		if !envCheckQuietFlag {
+			ui.Info("Not on a branch; nothing to check")
+		}
+		return nil
+	}
+
+	gen, ok, err := readGeneratedEnvFile(cfg)
+	if err != nil {
+		return err
+	}
+	rel, _ := filepath.Rel(cfg.ProjectRoot(), gen.Path)
+	if !ok {
+		if !envCheckQuietFlag {
+			ui.Warningf("%s has not been generated; run 'drift env setup'", rel)
+		}
+		return nil
+	}
+
+	info, err := resolveEnvCheckTarget(ctx, gitBranch)
+	if err != nil {
+		return err
+	}
+
+	if !envCheckQuietFlag {
+		ui.Header("Environment Check")
+		ui.KeyValue("Git Branch", ui.Cyan(gitBranch))
+		ui.KeyValue("Env File", rel)
+		ui.KeyValue("Generated For", fmt.Sprintf("%s (Supabase: %s)", gen.GitBranch, gen.SupabaseBranch))
+	}
+
+	switch {
+	case info == nil && gen.Fallback:
+		// No match and no configured fallback: setup asked which branch to
+		// use, and the answer still stands.
+		if !envCheckQuietFlag {
+			ui.Infof("No Supabase branch matches %s; keeping the chosen fallback %s", gitBranch, gen.SupabaseBranch)
+		}
+		return nil
+	case info == nil:
+		return errs.Validationf("%s targets Supabase branch %s, but no branch matches %s; run 'drift env setup' to choose one", rel, gen.SupabaseBranch, gitBranch)
+	case info.SupabaseBranch.Name == gen.SupabaseBranch:
+		if !envCheckQuietFlag {
+			ui.Successf("%s matches %s", rel, info.SupabaseBranch.Name)
+			if gen.GitBranch != gitBranch {
+				ui.Infof("It was generated on %s; run 'drift env setup' to update GIT_BRANCH", gen.GitBranch)
+			}
+		}
+		return nil
+	}
+
+	mismatch := fmt.Sprintf("%s targets Supabase branch %s, but %s uses %s", rel, gen.SupabaseBranch, gitBranch, info.SupabaseBranch.Name)
+	if envCheckFixFlag || cfg.GetEnvRefresh() == config.EnvRefreshRegenerate {
+		ui.Warning(mismatch)
+		ui.Infof("Regenerating %s...", rel)
+		return runEnvSetup(ctx)
+	}
+	return errs.Validationf("%s; run 'drift env setup'", mismatch)
+}
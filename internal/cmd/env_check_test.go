@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+)
+
+func TestParseSupabaseBranchDisplay(t *testing.T) {
+	tests := []struct {
+		display      string
+		wantBranch   string
+		wantFallback bool
+	}{
+		{"feat-payments", "feat-payments", false},
+		{"development (fallback)", "development", true},
+		{"v2/migration (override)", "v2/migration", false},
+		{"  main ", "main", false},
+	}
+	for _, tt := range tests {
+		branch, fallback := parseSupabaseBranchDisplay(tt.display)
+		if branch != tt.wantBranch || fallback != tt.wantFallback {
+			t.Errorf("parseSupabaseBranchDisplay(%q) = %q, %v; want %q, %v", tt.display, branch, fallback, tt.wantBranch, tt.wantFallback)
+		}
+	}
+}
+
+func TestReadGeneratedEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	cfg := &config.Config{
+		Project: config.ProjectConfig{Type: config.ProjectTypeWeb},
+		Web:     config.WebConfig{Framework: "nextjs", EnvOutput: ".env.local"},
+	}
+
+	if _, ok, err := readGeneratedEnvFile(cfg); err != nil || ok {
+		t.Fatalf("readGeneratedEnvFile() without a file = ok %v, err %v; want not found", ok, err)
+	}
+
+	content := "NEXT_PUBLIC_GIT_BRANCH=feat/login\nNEXT_PUBLIC_SUPABASE_BRANCH=development (fallback)\n"
+	if err := os.WriteFile(filepath.Join(dir, ".env.local"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gen, ok, err := readGeneratedEnvFile(cfg)
+	if err != nil || !ok {
+		t.Fatalf("readGeneratedEnvFile() = ok %v, err %v", ok, err)
+	}
+	if gen.GitBranch != "feat/login" || gen.SupabaseBranch != "development" || !gen.Fallback {
+		t.Errorf("readGeneratedEnvFile() = %+v, want feat/login on development (fallback)", gen)
+	}
+}
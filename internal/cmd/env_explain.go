@@ -0,0 +1,506 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/mask"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+var envExplainCmd = &cobra.Command{
+	Use:   "explain <VAR_NAME>",
+	Short: "Explain where a variable's effective value comes from",
+	Long: `Show every place a variable is defined and which definition wins:
+
+  - The drift-managed section of the generated env file
+  - The custom section below it (user-added, preserved by 'env setup')
+  - A copy from another worktree (via 'env setup --copy-env')
+  - The OS environment, which takes precedence over the file for web
+    frameworks
+  - supabase.default_secrets and per-environment secrets, which are
+    Edge Function secrets and are not read by the app
+
+It also shows what 'drift env setup' would change for the variable on the
+current branch.
+
+The name may be given with or without the framework's public prefix,
+e.g. SUPABASE_URL also matches NEXT_PUBLIC_SUPABASE_URL.`,
+	Example: `  drift env explain SUPABASE_URL
+  drift env explain NEXT_PUBLIC_SUPABASE_ANON_KEY
+  drift env explain STRIPE_SECRET_KEY`,
+	Args: cobra.ExactArgs(1),
+	RunE: Run(runEnvExplain, RequireProject),
+}
+
+func init() {
+	addShowSecretsFlag(envExplainCmd)
+	envCmd.AddCommand(envExplainCmd)
+}
+
+// Sections of a generated env file.
+const (
+	sectionManaged  = "drift-managed"
+	sectionCustom   = "custom"
+	sectionUnmarked = "unmarked"
+)
+
+// envAssignment is one KEY=VALUE line in an env file.
+type envAssignment struct {
+	Key     string
+	Value   string
+	Line    int
+	Section string
+}
+
+// envSource is a generated env file and its assignments.
+type envSource struct {
+	Path        string
+	Assignments []envAssignment
+}
+
+// envDefinition is an assignment of the explained variable.
+type envDefinition struct {
+	source *envSource
+	envAssignment
+}
+
+// xcconfigManagedKeys are the keys drift writes to the xcconfig.
+var xcconfigManagedKeys = []string{"SUPABASE_URL", "SUPABASE_ANON_KEY", "GIT_BRANCH_NAME", "SUPABASE_BRANCH_NAME", "DRIFT_ENVIRONMENT"}
+
+// webManagedKeys returns the keys drift writes for a web framework.
+func webManagedKeys(framework string) []string {
+	prefix := web.PublicPrefix(framework)
+	keys := []string{
+		prefix + "SUPABASE_URL",
+		prefix + "SUPABASE_ANON_KEY",
+		prefix + "GIT_BRANCH",
+		prefix + "SUPABASE_BRANCH",
+		prefix + "DRIFT_ENVIRONMENT",
+	}
+	if framework == web.FrameworkFlutter {
+		return keys
+	}
+	return append(keys, "SUPABASE_SERVICE_ROLE_KEY", "DATABASE_URL", "DATABASE_URL_POOLER", "DATABASE_URL_POOLER_SESSION")
+}
+
+// scanEnvAssignments parses an env file or xcconfig and records which
+// section each assignment is in. Files without drift markers report every
+// assignment as unmarked.
+func scanEnvAssignments(content string, xcconfig bool) []envAssignment {
+	startMarker, endMarker, comment, sep := web.DriftSectionStart, web.DriftSectionEnd, "#", "="
+	if xcconfig {
+		startMarker, endMarker, comment = xcode.XcconfigDriftStart, xcode.XcconfigDriftEnd, "//"
+	}
+
+	hasMarkers := strings.Contains(content, startMarker)
+	section := sectionUnmarked
+	if hasMarkers {
+		section = sectionCustom
+	}
+
+	var assignments []envAssignment
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == startMarker:
+			section = sectionManaged
+			continue
+		case line == endMarker:
+			section = sectionCustom
+			continue
+		case line == "" || strings.HasPrefix(line, comment):
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		idx := strings.Index(line, sep)
+		if idx <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:idx])
+		value := strings.TrimSpace(line[idx+1:])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		assignments = append(assignments, envAssignment{Key: key, Value: value, Line: lineNum, Section: section})
+	}
+	return assignments
+}
+
+// scanDartDefines reads a Flutter dart-define JSON file. Keys drift writes
+// are reported as managed; everything else is custom.
+func scanDartDefines(content string, managed []string) ([]envAssignment, error) {
+	values := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(content), &values); err != nil {
+		return nil, err
+	}
+
+	managedSet := make(map[string]bool, len(managed))
+	for _, key := range managed {
+		managedSet[key] = true
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var assignments []envAssignment
+	for _, key := range keys {
+		section := sectionCustom
+		if managedSet[key] {
+			section = sectionManaged
+		}
+		assignments = append(assignments, envAssignment{Key: key, Value: fmt.Sprint(values[key]), Section: section})
+	}
+	return assignments, nil
+}
+
+// explainCandidateNames returns the names that refer to the same variable:
+// the name itself plus the framework-prefixed or unprefixed variant.
+func explainCandidateNames(name, prefix string) []string {
+	names := []string{name}
+	if prefix == "" {
+		return names
+	}
+	if base := strings.TrimPrefix(name, prefix); base != name {
+		return append(names, base)
+	}
+	return append(names, prefix+name)
+}
+
+// envFilesForExplain returns the generated env files for the project type.
+func envFilesForExplain(cfg *config.Config) []string {
+	if !cfg.Project.IsWebPlatform() {
+		return []string{cfg.GetXcconfigPath()}
+	}
+	files := []string{cfg.GetEnvLocalPath()}
+	if cfg.Web.ServerEnvOutput != "" {
+		if serverPath := cfg.GetServerEnvPath(); serverPath != files[0] {
+			files = append(files, serverPath)
+		}
+	}
+	return files
+}
+
+// readEnvSource scans path, returning nil if it does not exist.
+func readEnvSource(cfg *config.Config, path string) (*envSource, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	source := &envSource{Path: path}
+	switch {
+	case !cfg.Project.IsWebPlatform():
+		source.Assignments = scanEnvAssignments(string(content), true)
+	case cfg.Web.Framework == web.FrameworkFlutter:
+		source.Assignments, err = scanDartDefines(string(content), webManagedKeys(cfg.Web.Framework))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+		}
+	default:
+		source.Assignments = scanEnvAssignments(string(content), false)
+	}
+	return source, nil
+}
+
+func runEnvExplain(ctx *Context) error {
+	cfg := ctx.Config()
+	isWeb := cfg.Project.IsWebPlatform()
+	prefix := ""
+	if isWeb {
+		prefix = web.PublicPrefix(cfg.Web.Framework)
+	}
+	names := explainCandidateNames(ctx.Args[0], prefix)
+	nameSet := make(map[string]bool, len(names))
+	for _, n := range names {
+		nameSet[n] = true
+	}
+
+	policy := displayPolicy(ctx.Cmd, cfg)
+
+	ui.Header("Explain " + ctx.Args[0])
+
+	// Definitions in the generated files, in load order
+	var defs []envDefinition
+	for _, path := range envFilesForExplain(cfg) {
+		source, err := readEnvSource(cfg, path)
+		if err != nil {
+			return err
+		}
+		if source == nil {
+			ui.Infof("%s does not exist", relPath(cfg, path))
+			continue
+		}
+		for _, a := range source.Assignments {
+			if nameSet[a.Key] {
+				defs = append(defs, envDefinition{source: source, envAssignment: a})
+			}
+		}
+	}
+
+	// The OS environment
+	type osValue struct{ key, value string }
+	var osValues []osValue
+	for _, n := range names {
+		if v, ok := os.LookupEnv(n); ok {
+			osValues = append(osValues, osValue{n, v})
+		}
+	}
+	osApplies := isWeb && cfg.Web.Framework != web.FrameworkFlutter
+
+	// Effective value
+	ui.SubHeader("Effective Value")
+	switch {
+	case osApplies && len(osValues) > 0:
+		ui.KeyValue("Value", policy.Display(osValues[0].key, osValues[0].value))
+		ui.KeyValue("Source", fmt.Sprintf("OS environment (%s)", osValues[0].key))
+	case len(defs) > 0:
+		winner := defs[len(defs)-1]
+		ui.KeyValue("Value", policy.Display(winner.Key, winner.Value))
+		ui.KeyValue("Source", fmt.Sprintf("%s %s section", explainLocation(cfg, winner.source.Path, winner.Line), winner.Section))
+	default:
+		ui.KeyValue("Value", ui.Dim("(not set)"))
+	}
+
+	// All definitions
+	ui.SubHeader("Definitions")
+	if len(defs) == 0 {
+		ui.Info("Not defined in any generated env file")
+	}
+	for i, d := range defs {
+		note := ""
+		if i < len(defs)-1 {
+			note = ui.Dim("  (overridden below)")
+		}
+		fmt.Printf("  %-28s %-14s %s=%s%s\n",
+			explainLocation(cfg, d.source.Path, d.Line), d.Section, d.Key, policy.Display(d.Key, d.Value), note)
+	}
+
+	ui.SubHeader("OS Environment")
+	switch {
+	case len(osValues) == 0:
+		ui.Info("Not set in the OS environment")
+	case osApplies:
+		for _, v := range osValues {
+			ui.Warningf("%s=%s is set in your shell and takes precedence over the env file", v.key, policy.Display(v.key, v.value))
+		}
+	default:
+		for _, v := range osValues {
+			ui.Infof("%s is set in your shell, but the app does not read it (values come from %s)",
+				v.key, filepath.Base(envFilesForExplain(cfg)[0]))
+		}
+	}
+
+	// Worktree copies only matter for custom variables; managed ones are
+	// regenerated per branch.
+	var custom []envDefinition
+	for _, d := range defs {
+		if d.Section != sectionManaged {
+			custom = append(custom, d)
+		}
+	}
+	if len(custom) > 0 {
+		last := custom[len(custom)-1]
+		explainWorktreeCopies(cfg, last.Key, last.Value, last.source.Path)
+	}
+
+	explainFunctionSecrets(cfg, names)
+
+	var effective *envDefinition
+	if len(defs) > 0 {
+		effective = &defs[len(defs)-1]
+	}
+	return explainSetupChange(cfg, policy, names, effective)
+}
+
+// explainLocation formats a file location relative to the project root.
+func explainLocation(cfg *config.Config, path string, line int) string {
+	if line == 0 {
+		return relPath(cfg, path)
+	}
+	return fmt.Sprintf("%s:%d", relPath(cfg, path), line)
+}
+
+// relPath returns path relative to the project root, if possible.
+func relPath(cfg *config.Config, path string) string {
+	if rel, err := filepath.Rel(cfg.ProjectRoot(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// explainWorktreeCopies reports other worktrees whose env file defines key
+// with the same value, which is what 'env setup --copy-env' leaves behind.
+func explainWorktreeCopies(cfg *config.Config, key, value, path string) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil || len(worktrees) < 2 {
+		return
+	}
+
+	var current string
+	for _, wt := range worktrees {
+		if wt.IsCurrent {
+			current = wt.Path
+		}
+	}
+	rel, err := filepath.Rel(current, path)
+	if current == "" || err != nil || strings.HasPrefix(rel, "..") {
+		return
+	}
+
+	var matches []string
+	for _, wt := range worktrees {
+		if wt.IsCurrent {
+			continue
+		}
+		source, err := readEnvSource(cfg, filepath.Join(wt.Path, rel))
+		if err != nil || source == nil {
+			continue
+		}
+		for _, a := range source.Assignments {
+			if a.Key == key && a.Value == value {
+				matches = append(matches, fmt.Sprintf("%s (%s)", wt.Branch, wt.Path))
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return
+	}
+
+	ui.SubHeader("Other Worktrees")
+	ui.Infof("Same value for %s in:", key)
+	for _, m := range matches {
+		ui.List(m)
+	}
+	ui.Info(ui.Dim("Likely copied with 'drift env setup --copy-env'"))
+}
+
+// explainFunctionSecrets reports names that are configured as Edge Function
+// secrets, which are commonly confused with app environment variables.
+func explainFunctionSecrets(cfg *config.Config, names []string) {
+	var found []string
+	for _, n := range names {
+		if _, ok := cfg.Supabase.DefaultSecrets[n]; ok {
+			found = append(found, fmt.Sprintf("supabase.default_secrets.%s", n))
+		}
+		envNames := make([]string, 0, len(cfg.Environments))
+		for env := range cfg.Environments {
+			envNames = append(envNames, env)
+		}
+		sort.Strings(envNames)
+		for _, env := range envNames {
+			if _, ok := cfg.Environments[env].Secrets[n]; ok {
+				found = append(found, fmt.Sprintf("environments.%s.secrets.%s", env, n))
+			}
+		}
+	}
+	if len(found) == 0 {
+		return
+	}
+
+	ui.SubHeader("Edge Function Secrets")
+	for _, f := range found {
+		ui.List(f)
+	}
+	ui.Info(ui.Dim("Pushed with 'drift deploy secrets'; Edge Functions read these, the app does not"))
+}
+
+// explainSetupChange shows what 'drift env setup' would do to the variable.
+func explainSetupChange(cfg *config.Config, policy *mask.Policy, names []string, current *envDefinition) error {
+	ui.SubHeader("After 'drift env setup'")
+
+	managedKeys := xcconfigManagedKeys
+	if cfg.Project.IsWebPlatform() {
+		managedKeys = webManagedKeys(cfg.Web.Framework)
+	}
+	managedKey := ""
+	for _, key := range managedKeys {
+		for _, n := range names {
+			if key == n {
+				managedKey = key
+			}
+		}
+	}
+
+	if managedKey == "" {
+		if current != nil {
+			ui.Info("Preserved: custom variables are carried over unchanged")
+		} else {
+			ui.Info("Not managed by drift; add it below the managed section to set it")
+		}
+		return nil
+	}
+
+	sp := ui.NewSpinner("Resolving target for current branch")
+	sp.Start()
+	info, err := resolveStateTarget(cfg, "")
+	if err != nil {
+		sp.Fail("Could not resolve target")
+		return err
+	}
+	sp.Stop()
+
+	expected, derivable := expectedSetupValue(managedKey, info, !cfg.Project.IsWebPlatform())
+	ui.KeyValue("Target", fmt.Sprintf("%s (%s)", newEnvState(info).SupabaseBranch, envColorString(string(info.Environment))))
+
+	switch {
+	case current == nil:
+		ui.Infof("Would be added as %s", managedKey)
+	case current.Section == sectionCustom:
+		ui.Warningf("Defined in the custom section; setup also writes %s to the managed section and the custom value still wins", managedKey)
+	case !derivable:
+		ui.Info("Refreshed from Supabase (API keys and database URLs are fetched at setup time)")
+	case current.Value == expected:
+		ui.Success("Unchanged")
+	default:
+		ui.Infof("Would change: %s → %s",
+			policy.Display(managedKey, current.Value), policy.Display(managedKey, expected))
+	}
+	return nil
+}
+
+// expectedSetupValue returns the value setup writes for a managed key, and
+// whether it can be derived without fetching keys from Supabase. The
+// xcconfig escapes '//' in URLs.
+func expectedSetupValue(key string, info *supabase.BranchInfo, xcconfig bool) (string, bool) {
+	state := newEnvState(info)
+	branchDisplay := web.EnvLocalData{
+		SupabaseBranch: state.SupabaseBranch,
+		IsFallback:     info.IsFallback,
+		IsOverride:     info.IsOverride,
+	}.SupabaseBranchDisplay()
+
+	switch {
+	case key == "SUPABASE_URL" && xcconfig:
+		return xcode.XcconfigData{APIURL: info.APIURL}.EscapedURL(), true
+	case strings.HasSuffix(key, "SUPABASE_URL"):
+		return info.APIURL, true
+	case strings.HasSuffix(key, "GIT_BRANCH") || key == "GIT_BRANCH_NAME":
+		return info.GitBranch, true
+	case strings.HasSuffix(key, "SUPABASE_BRANCH") || key == "SUPABASE_BRANCH_NAME":
+		return branchDisplay, true
+	case strings.HasSuffix(key, "DRIFT_ENVIRONMENT"):
+		return string(info.Environment), true
+	}
+	return "", false
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestScanEnvAssignments(t *testing.T) {
+	content := `# .env.local
+# === DRIFT MANAGED START ===
+NEXT_PUBLIC_SUPABASE_URL=https://abc.supabase.co
+DATABASE_URL="postgres://localhost"
+# === DRIFT MANAGED END ===
+
+# CUSTOM VARIABLES
+export STRIPE_KEY='sk_test'
+NEXT_PUBLIC_SUPABASE_URL=http://localhost:54321
+`
+	want := []envAssignment{
+		{Key: "NEXT_PUBLIC_SUPABASE_URL", Value: "https://abc.supabase.co", Line: 3, Section: sectionManaged},
+		{Key: "DATABASE_URL", Value: "postgres://localhost", Line: 4, Section: sectionManaged},
+		{Key: "STRIPE_KEY", Value: "sk_test", Line: 8, Section: sectionCustom},
+		{Key: "NEXT_PUBLIC_SUPABASE_URL", Value: "http://localhost:54321", Line: 9, Section: sectionCustom},
+	}
+	if got := scanEnvAssignments(content, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("scanEnvAssignments() = %+v, want %+v", got, want)
+	}
+
+	xcconfig := `// === DRIFT MANAGED START ===
+SUPABASE_URL = https:/$()/abc.supabase.co
+// === DRIFT MANAGED END ===
+API_HOST = example.com
+`
+	wantXc := []envAssignment{
+		{Key: "SUPABASE_URL", Value: "https:/$()/abc.supabase.co", Line: 2, Section: sectionManaged},
+		{Key: "API_HOST", Value: "example.com", Line: 4, Section: sectionCustom},
+	}
+	if got := scanEnvAssignments(xcconfig, true); !reflect.DeepEqual(got, wantXc) {
+		t.Errorf("scanEnvAssignments(xcconfig) = %+v, want %+v", got, wantXc)
+	}
+
+	legacy := scanEnvAssignments("FOO=bar\n", false)
+	if len(legacy) != 1 || legacy[0].Section != sectionUnmarked {
+		t.Errorf("legacy file = %+v, want one unmarked assignment", legacy)
+	}
+}
+
+func TestScanDartDefines(t *testing.T) {
+	got, err := scanDartDefines(`{"SUPABASE_URL": "https://abc.supabase.co", "SENTRY_DSN": "dsn"}`, []string{"SUPABASE_URL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []envAssignment{
+		{Key: "SENTRY_DSN", Value: "dsn", Section: sectionCustom},
+		{Key: "SUPABASE_URL", Value: "https://abc.supabase.co", Section: sectionManaged},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanDartDefines() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExplainCandidateNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{"SUPABASE_URL", "NEXT_PUBLIC_", []string{"SUPABASE_URL", "NEXT_PUBLIC_SUPABASE_URL"}},
+		{"VITE_SUPABASE_URL", "VITE_", []string{"VITE_SUPABASE_URL", "SUPABASE_URL"}},
+		{"SUPABASE_URL", "", []string{"SUPABASE_URL"}},
+	}
+	for _, tt := range tests {
+		if got := explainCandidateNames(tt.name, tt.prefix); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("explainCandidateNames(%q, %q) = %v, want %v", tt.name, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestExpectedSetupValue(t *testing.T) {
+	info := &supabase.BranchInfo{
+		GitBranch:      "feat/login",
+		SupabaseBranch: &supabase.Branch{Name: "dev"},
+		Environment:    supabase.EnvDevelopment,
+		APIURL:         "https://abc.supabase.co",
+		IsFallback:     true,
+	}
+	tests := []struct {
+		key       string
+		xcconfig  bool
+		want      string
+		derivable bool
+	}{
+		{"NEXT_PUBLIC_SUPABASE_URL", false, "https://abc.supabase.co", true},
+		{"SUPABASE_URL", false, "https://abc.supabase.co", true},
+		{"SUPABASE_URL", true, "https:/$()/abc.supabase.co", true},
+		{"VITE_GIT_BRANCH", false, "feat/login", true},
+		{"SUPABASE_BRANCH_NAME", true, "dev (fallback)", true},
+		{"DRIFT_ENVIRONMENT", true, "Development", true},
+		{"SUPABASE_ANON_KEY", false, "", false},
+		{"DATABASE_URL", false, "", false},
+	}
+	for _, tt := range tests {
+		got, derivable := expectedSetupValue(tt.key, info, tt.xcconfig)
+		if got != tt.want || derivable != tt.derivable {
+			t.Errorf("expectedSetupValue(%q, %v) = %q, %v, want %q, %v", tt.key, tt.xcconfig, got, derivable, tt.want, tt.derivable)
+		}
+	}
+}
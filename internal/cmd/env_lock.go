@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+// envLockFile is written next to the generated env file.
+const envLockFile = ".drift.lock"
+
+const envLockHeader = "# Generated by 'drift env setup'. Do not edit.\n# Records the Supabase branch the env file was generated from; values are hashes only.\n"
+
+// envLock is the environment state recorded by 'drift env setup'. Status and
+// validation compare it with the live branch, so a branch that was deleted
+// and recreated under the same name is noticed even though the env file
+// still names the right branch.
+type envLock struct {
+	GeneratedAt      time.Time         `yaml:"generated_at"`
+	GitBranch        string            `yaml:"git_branch"`
+	Environment      string            `yaml:"environment"`
+	ProjectRef       string            `yaml:"project_ref"`
+	SupabaseBranch   string            `yaml:"supabase_branch"`
+	SupabaseBranchID string            `yaml:"supabase_branch_id,omitempty"`
+	Keys             map[string]string `yaml:"keys,omitempty"`
+	MigrationHead    string            `yaml:"migration_head,omitempty"`
+}
+
+// envLockDrift is one difference between the lock and the live state.
+type envLockDrift struct {
+	Field   string
+	Locked  string
+	Current string
+	// Stale means the env file no longer matches its branch and needs
+	// regenerating; other drift is informational.
+	Stale bool
+}
+
+// envLockPath returns the lock path for the env file at envFile.
+func envLockPath(envFile string) string {
+	return filepath.Join(filepath.Dir(envFile), envLockFile)
+}
+
+// keyFingerprint returns a short, non-reversible fingerprint of a key.
+func keyFingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
+
+// newEnvLock records info and the keys written for it. Empty keys are left
+// out.
+func newEnvLock(cfg *config.Config, info *supabase.BranchInfo, keys map[string]string) *envLock {
+	lock := &envLock{
+		GeneratedAt:   time.Now().UTC().Truncate(time.Second),
+		GitBranch:     info.GitBranch,
+		Environment:   string(info.Environment),
+		ProjectRef:    info.ProjectRef,
+		MigrationHead: localMigrationHead(cfg),
+	}
+	if info.SupabaseBranch != nil {
+		lock.SupabaseBranch = info.SupabaseBranch.Name
+		lock.SupabaseBranchID = info.SupabaseBranch.ID
+	}
+	for name, value := range keys {
+		if value == "" {
+			continue
+		}
+		if lock.Keys == nil {
+			lock.Keys = make(map[string]string)
+		}
+		lock.Keys[name] = keyFingerprint(value)
+	}
+	return lock
+}
+
+// localMigrationHead returns the newest local migration's version, or ""
+// when there are none.
+func localMigrationHead(cfg *config.Config) string {
+	migrations, err := getLocalMigrations(cfg)
+	if err != nil || len(migrations) == 0 {
+		return ""
+	}
+	head := migrations[len(migrations)-1]
+	if i := strings.Index(head, "_"); i > 0 {
+		return head[:i]
+	}
+	return strings.TrimSuffix(head, ".sql")
+}
+
+func writeEnvLock(path string, lock *envLock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(envLockHeader), data...), 0644)
+}
+
+// readEnvLock reads the lock at path, returning nil when there is none.
+func readEnvLock(path string) (*envLock, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lock envLock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// compareEnvLock lists how current differs from locked. Fields current
+// leaves empty are not compared, so callers only pay for the lookups they
+// make; keys are compared only when both sides have them.
+func compareEnvLock(locked, current *envLock) []envLockDrift {
+	var drift []envLockDrift
+	check := func(field, l, c string, stale bool) {
+		if l != "" && c != "" && l != c {
+			drift = append(drift, envLockDrift{Field: field, Locked: l, Current: c, Stale: stale})
+		}
+	}
+	check("project_ref", locked.ProjectRef, current.ProjectRef, true)
+	check("supabase_branch", locked.SupabaseBranch, current.SupabaseBranch, true)
+	check("supabase_branch_id", locked.SupabaseBranchID, current.SupabaseBranchID, true)
+
+	names := make([]string, 0, len(locked.Keys))
+	for name := range locked.Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		check("keys."+name, locked.Keys[name], current.Keys[name], true)
+	}
+
+	check("migration_head", locked.MigrationHead, current.MigrationHead, false)
+	return drift
+}
+
+// describeEnvLockDrift explains one difference in a sentence.
+func describeEnvLockDrift(d envLockDrift) string {
+	switch {
+	case d.Field == "supabase_branch_id":
+		return "Supabase branch was recreated since env setup (branch ID changed)"
+	case d.Field == "project_ref":
+		return fmt.Sprintf("Branch project ref changed: %s -> %s", d.Locked, d.Current)
+	case d.Field == "supabase_branch":
+		return fmt.Sprintf("Env was generated for %s, but this branch now targets %s", d.Locked, d.Current)
+	case strings.HasPrefix(d.Field, "keys."):
+		return fmt.Sprintf("%s changed since env setup", strings.TrimPrefix(d.Field, "keys."))
+	case d.Field == "migration_head":
+		return fmt.Sprintf("Migration head changed since env setup (%s -> %s)", d.Locked, d.Current)
+	}
+	return fmt.Sprintf("%s changed: %s -> %s", d.Field, d.Locked, d.Current)
+}
+
+// recordEnvLock writes the lock for a freshly generated env file. Failing to
+// write it never fails setup.
+func recordEnvLock(cfg *config.Config, info *supabase.BranchInfo, outputPath string, keys map[string]string) {
+	path := envLockPath(outputPath)
+	if err := writeEnvLock(path, newEnvLock(cfg, info, keys)); err != nil {
+		ui.Warning(fmt.Sprintf("Could not write %s: %v", path, err))
+	}
+}
+
+// printEnvLockStatus compares the lock next to envFile with current and
+// prints the result. It returns false when the env file is stale.
+func printEnvLockStatus(envFile string, current *envLock) bool {
+	path := envLockPath(envFile)
+	locked, err := readEnvLock(path)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read %s: %v", path, err))
+		return true
+	}
+	if locked == nil {
+		ui.KeyValue("Lock", ui.Dim("none (run 'drift env setup' to record one)"))
+		return true
+	}
+
+	fresh := true
+	drift := compareEnvLock(locked, current)
+	for _, d := range drift {
+		if d.Stale {
+			fresh = false
+			ui.Warning(describeEnvLockDrift(d))
+		} else {
+			ui.Info(describeEnvLockDrift(d))
+		}
+	}
+	if fresh {
+		ui.KeyValue("Lock", ui.Green(fmt.Sprintf("✓ Matches %s", locked.SupabaseBranch)))
+	} else {
+		ui.KeyValue("Lock", ui.Yellow("⚠ Branch changed since env setup"))
+		ui.Infof("Run 'drift env setup' to regenerate")
+	}
+	return fresh
+}
+
+// envLockStillValid checks the env file's lock against the live branch,
+// including its keys, for 'drift env validate'. A missing lock passes, since
+// files generated before locks existed are not wrong.
+func envLockStillValid(cfg *config.Config, envFile string) bool {
+	if locked, err := readEnvLock(envLockPath(envFile)); err == nil && locked == nil {
+		return printEnvLockStatus(envFile, &envLock{})
+	}
+
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not get current git branch: %v", err))
+		return false
+	}
+	client := supabase.NewClient()
+	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, "")
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not resolve Supabase branch: %v", err))
+		return false
+	}
+
+	keys := make(map[string]string)
+	if anonKey, err := fetchAnonKeyForBranch(client, info); err == nil {
+		keys["SUPABASE_ANON_KEY"] = anonKey
+	} else {
+		ui.Warning(fmt.Sprintf("Could not fetch keys, skipping key check: %v", err))
+	}
+	return printEnvLockStatus(envFile, newEnvLock(cfg, info, keys))
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestEnvLockRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	cfg := loadConfigWithBackupDir(t, root, "backups")
+	cfg.Supabase.MigrationsDir = filepath.Join(root, "migrations")
+	if err := os.MkdirAll(cfg.Supabase.MigrationsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"20260101000000_init.sql", "20260301120000_add_posts.sql"} {
+		if err := os.WriteFile(filepath.Join(cfg.Supabase.MigrationsDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info := &supabase.BranchInfo{
+		GitBranch:      "feat/login",
+		Environment:    supabase.EnvFeature,
+		ProjectRef:     "abcdefgh",
+		SupabaseBranch: &supabase.Branch{ID: "br-1", Name: "feat-login"},
+	}
+	envFile := filepath.Join(root, ".env.local")
+	recordEnvLock(cfg, info, envFile, map[string]string{"SUPABASE_ANON_KEY": "anon-secret", "SUPABASE_SERVICE_ROLE_KEY": ""})
+
+	data, err := os.ReadFile(filepath.Join(root, ".drift.lock"))
+	if err != nil {
+		t.Fatalf("lock not written: %v", err)
+	}
+	if strings.Contains(string(data), "anon-secret") {
+		t.Error("lock should not contain key values")
+	}
+
+	lock, err := readEnvLock(envLockPath(envFile))
+	if err != nil || lock == nil {
+		t.Fatalf("readEnvLock() = %v, %v", lock, err)
+	}
+	if lock.SupabaseBranchID != "br-1" || lock.ProjectRef != "abcdefgh" || lock.MigrationHead != "20260301120000" {
+		t.Errorf("readEnvLock() = %+v", lock)
+	}
+	if lock.Keys["SUPABASE_ANON_KEY"] != keyFingerprint("anon-secret") {
+		t.Errorf("anon key fingerprint = %q", lock.Keys["SUPABASE_ANON_KEY"])
+	}
+	if _, ok := lock.Keys["SUPABASE_SERVICE_ROLE_KEY"]; ok {
+		t.Error("empty keys should not be recorded")
+	}
+
+	if missing, err := readEnvLock(filepath.Join(root, "nope", ".drift.lock")); missing != nil || err != nil {
+		t.Errorf("readEnvLock(missing) = %v, %v, want nil, nil", missing, err)
+	}
+}
+
+func TestCompareEnvLock(t *testing.T) {
+	locked := &envLock{
+		ProjectRef:       "abcdefgh",
+		SupabaseBranch:   "feat-login",
+		SupabaseBranchID: "br-1",
+		Keys:             map[string]string{"SUPABASE_ANON_KEY": keyFingerprint("anon")},
+		MigrationHead:    "20260101000000",
+	}
+
+	tests := []struct {
+		name      string
+		current   envLock
+		wantField string
+		wantStale bool
+	}{
+		{
+			name:    "unchanged",
+			current: envLock{ProjectRef: "abcdefgh", SupabaseBranch: "feat-login", SupabaseBranchID: "br-1", MigrationHead: "20260101000000"},
+		},
+		{
+			name:      "branch recreated",
+			current:   envLock{ProjectRef: "abcdefgh", SupabaseBranch: "feat-login", SupabaseBranchID: "br-2"},
+			wantField: "supabase_branch_id",
+			wantStale: true,
+		},
+		{
+			name:      "key rotated",
+			current:   envLock{ProjectRef: "abcdefgh", Keys: map[string]string{"SUPABASE_ANON_KEY": keyFingerprint("rotated")}},
+			wantField: "keys.SUPABASE_ANON_KEY",
+			wantStale: true,
+		},
+		{
+			name:      "new migration",
+			current:   envLock{ProjectRef: "abcdefgh", MigrationHead: "20260201000000"},
+			wantField: "migration_head",
+		},
+		{
+			name:    "nothing looked up",
+			current: envLock{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drift := compareEnvLock(locked, &tt.current)
+			if tt.wantField == "" {
+				if len(drift) != 0 {
+					t.Errorf("compareEnvLock() = %+v, want no drift", drift)
+				}
+				return
+			}
+			if len(drift) != 1 || drift[0].Field != tt.wantField || drift[0].Stale != tt.wantStale {
+				t.Errorf("compareEnvLock() = %+v, want %s (stale=%v)", drift, tt.wantField, tt.wantStale)
+			}
+		})
+	}
+}
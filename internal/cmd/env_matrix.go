@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+var envMatrixCmd = &cobra.Command{
+	Use:   "matrix <target>...",
+	Short: "Print a CI build matrix for several environments",
+	Long: `Resolve several targets at once and print a CI matrix with one entry per
+target: environment, Supabase branch, project ref, API URL, anon key, and the
+config file that leg should write.
+
+A target is "production" (or "prod"), "development" (or "dev"), or a branch
+name resolved like --branch. API keys are fetched once per project.
+
+--format json prints the entries as a JSON array. --format github prints a
+GitHub Actions matrix ({"include": [...]}) on one line and, when
+$GITHUB_OUTPUT is set, also writes it there as the "matrix" output.
+
+Each leg can then write its config to its output file without calling
+Supabase:
+
+  SUPABASE_URL=${{ matrix.supabase_url }} \
+  SUPABASE_ANON_KEY=${{ matrix.anon_key }} \
+  DRIFT_ENVIRONMENT=${{ matrix.environment }} \
+  DRIFT_SUPABASE_BRANCH=${{ matrix.supabase_branch }} \
+  drift env setup --ci --print --reveal > ${{ matrix.output }}`,
+	Example: `  drift env matrix dev prod
+  drift env matrix dev prod --format github
+  drift env matrix development feat/checkout --no-keys`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: Run(runEnvMatrix, RequireProject),
+}
+
+var (
+	envMatrixFormat string
+	envMatrixNoKeys bool
+)
+
+func init() {
+	envMatrixCmd.Flags().StringVar(&envMatrixFormat, "format", "json", "Output format: json or github")
+	envMatrixCmd.Flags().BoolVar(&envMatrixNoKeys, "no-keys", false, "Leave anon keys out of the matrix")
+	envCmd.AddCommand(envMatrixCmd)
+}
+
+// envMatrixLeg is one entry of the CI matrix.
+type envMatrixLeg struct {
+	Name           string `json:"name"`
+	Environment    string `json:"environment"`
+	SupabaseBranch string `json:"supabase_branch"`
+	ProjectRef     string `json:"project_ref"`
+	SupabaseURL    string `json:"supabase_url"`
+	AnonKey        string `json:"anon_key,omitempty"`
+	Output         string `json:"output"`
+}
+
+func runEnvMatrix(ctx *Context) error {
+	if envMatrixFormat != "json" && envMatrixFormat != "github" {
+		return fmt.Errorf("invalid --format %q (use json or github)", envMatrixFormat)
+	}
+
+	// stdout carries only the matrix.
+	out, restore := redirectEnvPrint()
+	defer restore()
+	ctx.Out = out
+
+	cfg := ctx.Config()
+	// CI checkouts are often detached; named targets do not need the branch.
+	gitBranch, err := ctx.GitBranch()
+	if err != nil {
+		gitBranch = "ci"
+	}
+
+	keys := make(map[string]string)
+	var legs []envMatrixLeg
+	seen := make(map[string]bool)
+	for _, target := range ctx.Args {
+		name := normalizeMatrixTarget(target)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		sp := ui.NewSpinner(fmt.Sprintf("Resolving %s", name))
+		sp.Start()
+		var info *supabase.BranchInfo
+		if name == "production" || name == "development" {
+			info, err = resolveSchemeVariantTarget(ctx.Client(), cfg, gitBranch, name)
+		} else {
+			info, err = ResolveSupabaseTargetForCurrentBranch(ctx.Client(), cfg, gitBranch, name)
+		}
+		if err != nil {
+			sp.Fail(fmt.Sprintf("Failed to resolve %s", name))
+			return err
+		}
+
+		leg := envMatrixLeg{
+			Name:           name,
+			Environment:    string(info.Environment),
+			SupabaseBranch: info.SupabaseBranch.Name,
+			ProjectRef:     info.ProjectRef,
+			SupabaseURL:    info.APIURL,
+			Output:         envMatrixOutputPath(cfg, name),
+		}
+		if !envMatrixNoKeys {
+			key, ok := keys[info.ProjectRef]
+			if !ok {
+				if key, err = fetchAnonKeyForBranch(ctx.Client(), info); err != nil {
+					sp.Fail("Failed to fetch API keys")
+					return err
+				}
+				keys[info.ProjectRef] = key
+			}
+			leg.AnonKey = key
+		}
+		sp.Stop()
+		legs = append(legs, leg)
+	}
+
+	if envMatrixFormat == "json" {
+		return ctx.PrintJSON(legs)
+	}
+
+	data, err := json.Marshal(map[string][]envMatrixLeg{"include": legs})
+	if err != nil {
+		return err
+	}
+	if path := os.Getenv("GITHUB_OUTPUT"); path != "" {
+		if err := appendEnvFile(path, map[string]string{"matrix": string(data)}); err != nil {
+			return fmt.Errorf("failed to write GITHUB_OUTPUT: %w", err)
+		}
+	}
+	_, err = fmt.Fprintln(ctx.Out, string(data))
+	return err
+}
+
+// normalizeMatrixTarget expands the prod/dev shorthands.
+func normalizeMatrixTarget(target string) string {
+	switch strings.ToLower(target) {
+	case "prod", "production":
+		return "production"
+	case "dev", "development":
+		return "development"
+	}
+	return target
+}
+
+// envMatrixOutputPath is the config file a leg writes, relative to the
+// project root, so legs building in parallel do not overwrite each other:
+// Config.xcconfig becomes Config.production.xcconfig and .env.local becomes
+// .env.production.local.
+func envMatrixOutputPath(cfg *config.Config, name string) string {
+	base := cfg.GetXcconfigPath()
+	if cfg.Project.IsWebPlatform() {
+		base = cfg.GetEnvLocalPath()
+	}
+	path := xcode.VariantPath(base, variantSuffix(name))
+	if rel, err := filepath.Rel(cfg.ProjectRoot(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// variantSuffix turns a leg or scheme name into the part of a variant file
+// name that sits before the extension.
+func variantSuffix(name string) string {
+	return strings.NewReplacer("/", "-", " ", "-").Replace(name)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+)
+
+func TestNormalizeMatrixTarget(t *testing.T) {
+	tests := map[string]string{
+		"prod":         "production",
+		"Production":   "production",
+		"dev":          "development",
+		"development":  "development",
+		"feat/payment": "feat/payment",
+	}
+	for in, want := range tests {
+		if got := normalizeMatrixTarget(in); got != want {
+			t.Errorf("normalizeMatrixTarget(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEnvMatrixOutputPath(t *testing.T) {
+	ios := &config.Config{Xcode: config.XcodeConfig{XcconfigOutput: "App/Config.xcconfig"}}
+	web := &config.Config{
+		Project: config.ProjectConfig{Type: config.ProjectTypeWeb},
+		Web:     config.WebConfig{EnvOutput: ".env.local"},
+	}
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		leg  string
+		want string
+	}{
+		{"ios production", ios, "production", "App/Config.production.xcconfig"},
+		{"ios branch", ios, "feat/payment", "App/Config.feat-payment.xcconfig"},
+		{"web development", web, "development", ".env.development.local"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := envMatrixOutputPath(tt.cfg, tt.leg); got != tt.want {
+				t.Errorf("envMatrixOutputPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/envcrypt"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/web"
+)
+
+// envCredentials is what env setup fetches for a branch. Each successful
+// fetch is kept in the secret store (see encryption.backend) so a later
+// setup can reuse it when the Supabase API cannot be reached.
+type envCredentials struct {
+	Info           *supabase.BranchInfo    `json:"info"`
+	AnonKey        string                  `json:"anon_key"`
+	ServiceRoleKey string                  `json:"service_role_key,omitempty"`
+	Web            *web.BranchSecretsInput `json:"web,omitempty"`
+	FetchedAt      time.Time               `json:"fetched_at"`
+
+	// cached is set when the credentials were loaded from the store.
+	cached bool
+}
+
+// envCredentialsIDUnsafe matches characters kept out of store ids, which
+// the age backend uses as file names.
+var envCredentialsIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// envCredentialsID names the cached credentials for the branch env setup
+// was asked for: the -b override, or else the git branch. The keychain is
+// shared by every project, so the id includes the project too.
+func envCredentialsID(cfg *config.Config, gitBranch, branchFlag string) string {
+	project := cfg.Supabase.ProjectRef
+	if project == "" {
+		project = cfg.Project.Name
+	}
+	target := gitBranch
+	if branchFlag != "" {
+		target = branchFlag
+	}
+	clean := func(s string) string { return envCredentialsIDUnsafe.ReplaceAllString(s, "_") }
+	return "env-cache." + clean(project) + "." + clean(target)
+}
+
+// saveEnvCredentials stores creds under id. Caching is best effort: when no
+// secret store is available the credentials are simply not kept.
+func saveEnvCredentials(cfg *config.Config, id string, creds *envCredentials) {
+	store, err := envcrypt.NewStore(cfg)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return
+	}
+	_ = store.Put(id, string(data))
+}
+
+// loadEnvCredentials returns the credentials stored under id.
+func loadEnvCredentials(cfg *config.Config, id string) (*envCredentials, error) {
+	store, err := envcrypt.NewStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeEnvCredentials(data)
+}
+
+// decodeEnvCredentials parses credentials written by saveEnvCredentials.
+func decodeEnvCredentials(data string) (*envCredentials, error) {
+	var creds envCredentials
+	if err := json.Unmarshal([]byte(data), &creds); err != nil {
+		return nil, fmt.Errorf("cached credentials are corrupt: %w", err)
+	}
+	if creds.Info == nil || creds.Info.SupabaseBranch == nil || creds.AnonKey == "" {
+		return nil, fmt.Errorf("cached credentials are incomplete")
+	}
+	creds.cached = true
+	return &creds, nil
+}
+
+// cachedAt is when cached credentials were fetched, or zero for
+// credentials fetched by this run.
+func (c *envCredentials) cachedAt() time.Time {
+	if !c.cached {
+		return time.Time{}
+	}
+	return c.FetchedAt
+}
+
+// cachedEnvCredentials loads the credentials stored under id, with an error
+// that says how to fill the cache when there are none.
+func cachedEnvCredentials(cfg *config.Config, id string) (*envCredentials, error) {
+	creds, err := loadEnvCredentials(cfg, id)
+	if err != nil {
+		return nil, errs.Configf("no cached credentials for this branch (%v); run 'drift env setup' once while online", err)
+	}
+	return creds, nil
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/web"
+)
+
+func TestEnvCredentialsID(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Supabase.ProjectRef = "abcdef"
+
+	if got := envCredentialsID(cfg, "feature/login", ""); got != "env-cache.abcdef.feature_login" {
+		t.Errorf("envCredentialsID() = %q", got)
+	}
+	if got := envCredentialsID(cfg, "feature/login", "dev"); got != "env-cache.abcdef.dev" {
+		t.Errorf("envCredentialsID() with -b = %q", got)
+	}
+
+	cfg.Supabase.ProjectRef = ""
+	cfg.Project.Name = "My App"
+	if got := envCredentialsID(cfg, "main", ""); got != "env-cache.My_App.main" {
+		t.Errorf("envCredentialsID() without project_ref = %q", got)
+	}
+}
+
+func TestDecodeEnvCredentials(t *testing.T) {
+	fetchedAt := time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+	creds := &envCredentials{
+		Info: &supabase.BranchInfo{
+			GitBranch:      "feature/login",
+			SupabaseBranch: &supabase.Branch{Name: "feature-login", ProjectRef: "branchref"},
+			Environment:    supabase.EnvFeature,
+			ProjectRef:     "branchref",
+			APIURL:         "https://branchref.supabase.co",
+		},
+		AnonKey:        "anon",
+		ServiceRoleKey: "service",
+		Web:            &web.BranchSecretsInput{AnonKey: "anon", DatabasePassword: "pw"},
+		FetchedAt:      fetchedAt,
+	}
+	if !creds.cachedAt().IsZero() {
+		t.Error("cachedAt() is set for freshly fetched credentials")
+	}
+
+	data, err := json.Marshal(creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeEnvCredentials(string(data))
+	if err != nil {
+		t.Fatalf("decodeEnvCredentials() error = %v", err)
+	}
+	if got.Info.SupabaseBranch.Name != "feature-login" || got.Info.Environment != supabase.EnvFeature {
+		t.Errorf("Info = %+v", got.Info)
+	}
+	if got.AnonKey != "anon" || got.ServiceRoleKey != "service" || got.Web.DatabasePassword != "pw" {
+		t.Errorf("keys = %q %q %+v", got.AnonKey, got.ServiceRoleKey, got.Web)
+	}
+	if !got.cachedAt().Equal(fetchedAt) {
+		t.Errorf("cachedAt() = %v, want %v", got.cachedAt(), fetchedAt)
+	}
+
+	for _, bad := range []string{"not json", `{"anon_key":"anon"}`, `{"info":{"SupabaseBranch":{"name":"x"}}}`} {
+		if _, err := decodeEnvCredentials(bad); err == nil {
+			t.Errorf("decodeEnvCredentials(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var envPRCommentCmd = &cobra.Command{
+	Use:   "pr-comment",
+	Short: "Render a PR comment describing the preview environment",
+	Long: `Render a Markdown comment describing the Supabase environment behind a
+pull request: the environment, Supabase branch, dashboard links, deployed
+Edge Functions, and applied migrations.
+
+By default the comment is printed to stdout, ready for 'gh pr comment'.
+With --post it is posted to the pull request with the GitHub CLI. Drift
+marks its comment, so posting again edits that comment instead of adding
+another one.
+
+Examples:
+  drift env pr-comment
+  drift env pr-comment --post
+  drift env pr-comment -b feat/login --post --pr 142`,
+	RunE: Run(runEnvPRComment, RequireProject),
+}
+
+var (
+	prCommentBranchFlag string
+	prCommentPostFlag   bool
+	prCommentPRFlag     int
+)
+
+// previewCommentMarker identifies drift's comment on a pull request so it can
+// be found and updated.
+const previewCommentMarker = "<!-- drift:preview-env -->"
+
+// recentMigrationsShown caps the applied migrations listed in a comment.
+const recentMigrationsShown = 10
+
+func init() {
+	envPRCommentCmd.Flags().StringVarP(&prCommentBranchFlag, "branch", "b", "", "Override Supabase branch selection")
+	envPRCommentCmd.Flags().BoolVar(&prCommentPostFlag, "post", false, "Post the comment to the pull request, updating drift's earlier comment")
+	envPRCommentCmd.Flags().IntVar(&prCommentPRFlag, "pr", 0, "Pull request to post to (default: the current branch's)")
+	envCmd.AddCommand(envPRCommentCmd)
+}
+
+// previewComment is what a PR comment says about a preview environment.
+type previewComment struct {
+	Environment       supabase.Environment
+	GitBranch         string
+	SupabaseBranch    string
+	ProjectRef        string
+	Status            string
+	Functions         []supabase.DeployedFunction
+	AppliedMigrations []string // oldest first
+	PendingMigrations []string
+	Errors            []string // checks that could not be run, as "check: reason"
+	GeneratedAt       time.Time
+}
+
+func runEnvPRComment(ctx *Context) error {
+	cfg := ctx.Config()
+	info, err := ctx.Target(prCommentBranchFlag)
+	if err != nil {
+		return err
+	}
+
+	sp := ui.NewSpinner("Collecting preview environment details")
+	sp.Start()
+	comment := collectPreviewComment(ctx.Client(), cfg, info, time.Now())
+	sp.Stop()
+
+	body := renderPreviewComment(comment)
+	if !prCommentPostFlag {
+		fmt.Fprint(ctx.Out, body)
+		return nil
+	}
+
+	var pr *git.PullRequest
+	if prCommentPRFlag > 0 {
+		pr, err = git.GetPullRequest(prCommentPRFlag)
+	} else {
+		pr, err = git.CurrentPullRequest()
+	}
+	if err != nil {
+		return err
+	}
+
+	updated, err := git.UpsertPullRequestComment(pr.Number, previewCommentMarker, body)
+	if err != nil {
+		return err
+	}
+	if updated {
+		ui.Successf("Updated preview comment on PR #%d", pr.Number)
+	} else {
+		ui.Successf("Posted preview comment on PR #%d", pr.Number)
+	}
+	ui.KeyValue("PR", pr.URL)
+	return nil
+}
+
+// collectPreviewComment gathers the details for a preview comment. Checks
+// that fail are recorded in Errors rather than failing the comment.
+func collectPreviewComment(client *supabase.Client, cfg *config.Config, info *supabase.BranchInfo, now time.Time) *previewComment {
+	c := &previewComment{
+		Environment: info.Environment,
+		GitBranch:   info.GitBranch,
+		ProjectRef:  info.ProjectRef,
+		GeneratedAt: now,
+	}
+	if info.SupabaseBranch != nil {
+		c.SupabaseBranch = info.SupabaseBranch.Name
+		c.Status = info.SupabaseBranch.Status
+	}
+
+	functions, err := client.ListDeployedFunctions(info.ProjectRef)
+	if err != nil {
+		c.Errors = append(c.Errors, "functions: "+err.Error())
+	} else {
+		sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+		c.Functions = functions
+	}
+
+	local, err := getLocalMigrations(cfg)
+	if err != nil {
+		c.Errors = append(c.Errors, "migrations: "+err.Error())
+		return c
+	}
+	details, err := getMigrationDetails(info.ProjectRef)
+	if err != nil {
+		c.Errors = append(c.Errors, "migrations: "+err.Error())
+		return c
+	}
+	c.AppliedMigrations, c.PendingMigrations = splitAppliedMigrations(local, details)
+	return c
+}
+
+// splitAppliedMigrations names the applied migrations, oldest first, using
+// local filenames where they are known, and lists local migrations that are
+// not applied yet.
+func splitAppliedMigrations(local []string, details map[string]string) (applied, pending []string) {
+	index := buildMigrationFilenameIndex(local)
+	timestamps := make([]string, 0, len(details))
+	appliedSet := make(map[string]bool, len(details))
+	for ts := range details {
+		timestamps = append(timestamps, ts)
+		appliedSet[ts] = true
+	}
+	sort.Strings(timestamps)
+	for _, ts := range timestamps {
+		if name, ok := index[ts]; ok {
+			applied = append(applied, name)
+		} else {
+			applied = append(applied, ts)
+		}
+	}
+	return applied, findPendingMigrations(local, appliedSet)
+}
+
+// renderPreviewComment renders the comment as GitHub Markdown, starting with
+// previewCommentMarker.
+func renderPreviewComment(c *previewComment) string {
+	var b strings.Builder
+
+	b.WriteString(previewCommentMarker + "\n")
+	b.WriteString("## Preview Environment\n\n")
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Environment | **%s** |\n", c.Environment)
+	if c.GitBranch != "" {
+		fmt.Fprintf(&b, "| Git branch | `%s` |\n", c.GitBranch)
+	}
+	fmt.Fprintf(&b, "| Supabase branch | `%s` |\n", c.SupabaseBranch)
+	fmt.Fprintf(&b, "| Project ref | `%s` |\n", c.ProjectRef)
+	if c.Status != "" {
+		fmt.Fprintf(&b, "| Status | %s |\n", c.Status)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "**Dashboard:** [Project](%s) · [Table editor](%s) · [SQL editor](%s) · [Edge Functions](%s) · [Logs](%s)\n\n",
+		GetProjectDashboardURL(c.ProjectRef), GetTableEditorURL(c.ProjectRef), GetSQLEditorURL(c.ProjectRef),
+		GetFunctionsURL(c.ProjectRef), GetLogsURL(c.ProjectRef))
+
+	b.WriteString("### Migrations\n\n")
+	if reason := previewCommentError(c.Errors, "migrations"); reason != "" {
+		fmt.Fprintf(&b, "Could not check migrations: %s\n\n", reason)
+	} else {
+		fmt.Fprintf(&b, "%d applied, %d pending.\n\n", len(c.AppliedMigrations), len(c.PendingMigrations))
+		writeReportList(&b, "Pending", c.PendingMigrations)
+		if len(c.AppliedMigrations) > 0 {
+			recent := c.AppliedMigrations
+			if len(recent) > recentMigrationsShown {
+				recent = recent[len(recent)-recentMigrationsShown:]
+			}
+			fmt.Fprintf(&b, "<details><summary>Latest applied (%d)</summary>\n\n", len(recent))
+			for i := len(recent) - 1; i >= 0; i-- {
+				fmt.Fprintf(&b, "- `%s`\n", recent[i])
+			}
+			b.WriteString("\n</details>\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("### Edge Functions\n\n")
+	if reason := previewCommentError(c.Errors, "functions"); reason != "" {
+		fmt.Fprintf(&b, "Could not list functions: %s\n\n", reason)
+	} else if len(c.Functions) == 0 {
+		b.WriteString("No functions deployed.\n\n")
+	} else {
+		b.WriteString("| Function | Version | Status | Updated |\n")
+		b.WriteString("|----------|---------|--------|---------|\n")
+		for _, fn := range c.Functions {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", fn.Name, fn.Version, fn.Status, fn.UpdatedAt)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "_Updated %s by drift_\n", c.GeneratedAt.Format("2006-01-02 15:04 MST"))
+	return b.String()
+}
+
+// previewCommentError returns why check failed, or "" if it did not.
+func previewCommentError(errors []string, check string) string {
+	for _, e := range errors {
+		if strings.HasPrefix(e, check+": ") {
+			return strings.TrimPrefix(e, check+": ")
+		}
+	}
+	return ""
+}
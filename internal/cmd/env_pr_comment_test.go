@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestSplitAppliedMigrations(t *testing.T) {
+	local := []string{"20260101000000_init.sql", "20260201000000_users.sql", "20260301000000_flags.sql"}
+	details := map[string]string{
+		"20260201000000": "2026-02-01 10:00",
+		"20260101000000": "2026-01-01 10:00",
+		"20251201000000": "2025-12-01 10:00", // applied remotely, not in the repo
+	}
+
+	applied, pending := splitAppliedMigrations(local, details)
+	wantApplied := []string{"20251201000000", "20260101000000_init.sql", "20260201000000_users.sql"}
+	if !reflect.DeepEqual(applied, wantApplied) {
+		t.Errorf("applied = %v, want %v", applied, wantApplied)
+	}
+	if !reflect.DeepEqual(pending, []string{"20260301000000_flags.sql"}) {
+		t.Errorf("pending = %v", pending)
+	}
+}
+
+func TestRenderPreviewComment(t *testing.T) {
+	c := &previewComment{
+		Environment:       supabase.EnvFeature,
+		GitBranch:         "feat/login",
+		SupabaseBranch:    "feat-login",
+		ProjectRef:        "abcdefghijklmnop",
+		Status:            "FUNCTIONS_DEPLOYED",
+		Functions:         []supabase.DeployedFunction{{Name: "send-email", Version: "3", Status: "ACTIVE"}},
+		AppliedMigrations: []string{"20260101000000_init.sql", "20260201000000_users.sql"},
+		PendingMigrations: []string{"20260301000000_flags.sql"},
+		GeneratedAt:       time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	out := renderPreviewComment(c)
+	if !strings.HasPrefix(out, previewCommentMarker+"\n") {
+		t.Errorf("comment does not start with the marker:\n%s", out)
+	}
+	for _, want := range []string{
+		"| Environment | **Feature** |",
+		"| Supabase branch | `feat-login` |",
+		GetSQLEditorURL("abcdefghijklmnop"),
+		"2 applied, 1 pending.",
+		"- Pending: `20260301000000_flags.sql`",
+		"| `send-email` | 3 | ACTIVE |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("comment missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "20260201000000_users.sql") > strings.Index(out, "20260101000000_init.sql") {
+		t.Error("latest applied migration should be listed first")
+	}
+
+	c.Errors = []string{"functions: not logged in"}
+	c.Functions = nil
+	if out := renderPreviewComment(c); !strings.Contains(out, "Could not list functions: not logged in") {
+		t.Errorf("failed check not reported:\n%s", out)
+	}
+}
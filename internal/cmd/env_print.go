@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/mask"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+var (
+	envPrintFlag  bool
+	envRevealFlag bool
+)
+
+func init() {
+	envSetupCmd.Flags().BoolVar(&envPrintFlag, "print", false, "Print the generated config to stdout instead of writing it")
+	envSetupCmd.Flags().BoolVar(&envPrintFlag, "stdout", false, "Alias for --print")
+	envSetupCmd.Flags().BoolVar(&envRevealFlag, "reveal", false, "Show secret values in --print output")
+}
+
+// redirectEnvPrint sends everything drift prints to stderr so that stdout
+// carries only the rendered config, and returns the original stdout and a
+// func that restores it.
+func redirectEnvPrint() (io.Writer, func()) {
+	stdout, colorOutput := os.Stdout, color.Output
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+	return stdout, func() {
+		os.Stdout = stdout
+		color.Output = colorOutput
+	}
+}
+
+// printEnvSetup renders the files 'drift env setup' would write for info and
+// prints them to out. Generation runs against copies in a temp directory, so
+// custom variables in the current files are included but nothing in the
+// project changes. Values are not encrypted, and secrets are masked unless
+// --reveal is set.
+func printEnvSetup(out io.Writer, cfg *config.Config, info *supabase.BranchInfo, anonKey string, webSecrets *web.BranchSecretsInput) error {
+	tmpDir, err := os.MkdirTemp("", "drift-env-print-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// stage copies target into tmpDir, keeping its file name since the
+	// generators render it into the header, and records it for printing.
+	type staged struct{ target, path string }
+	var files []staged
+	stage := func(target string) (string, error) {
+		dir := filepath.Join(tmpDir, fmt.Sprintf("%d", len(files)))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		path := filepath.Join(dir, filepath.Base(target))
+		if data, err := os.ReadFile(target); err == nil {
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				return "", err
+			}
+		}
+		files = append(files, staged{target: target, path: path})
+		return path, nil
+	}
+
+	if cfg.Project.IsWebPlatform() {
+		if !web.ValidFramework(cfg.Web.Framework) {
+			return fmt.Errorf("invalid web.framework %q (use nextjs, vite, expo, or flutter)", cfg.Web.Framework)
+		}
+		path, err := stage(cfg.GetEnvLocalPath())
+		if err != nil {
+			return err
+		}
+		generator := web.NewEnvLocalGenerator(path)
+		generator.VarPrefix = cfg.Supabase.EnvPrefix
+		generator.Framework = cfg.Web.Framework
+		generator.ServiceRolePolicy = cfg.Web.ServiceRoleKey
+		applyEnvLocalBanner(cfg, generator, info.Environment)
+		applyEnvLocalPorts(cfg, generator, false)
+		if generator.ServiceRolePolicy == web.ServiceRoleDeny && envAllowServiceRoleFlag {
+			generator.ServiceRolePolicy = web.ServiceRoleInclude
+		}
+		if generator.ServiceRolePolicy == web.ServiceRoleServerOnly && generator.Framework != web.FrameworkFlutter {
+			if generator.ServerEnvPath, err = stage(cfg.GetServerEnvPath()); err != nil {
+				return err
+			}
+		}
+		if err := generator.GenerateFromBranchInfo(info, webSecrets); err != nil {
+			return err
+		}
+	} else {
+		path, err := stage(cfg.GetXcconfigPath())
+		if err != nil {
+			return err
+		}
+		generator := xcode.NewXcconfigGenerator(path)
+		generator.VarPrefix = cfg.Supabase.EnvPrefix
+		applyXcconfigBanner(cfg, generator, info.Environment)
+		if err := generator.GenerateFromBranchInfo(info, anonKey); err != nil {
+			return err
+		}
+	}
+
+	for i, f := range files {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return err
+		}
+		content := string(data)
+		if !envRevealFlag {
+			content = maskRenderedSecrets(content, maskPolicy(cfg))
+		}
+		if len(files) > 1 {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "# ==> %s <==\n", f.target)
+		}
+		fmt.Fprint(out, content)
+		ui.Infof("Would write %s", f.target)
+	}
+
+	if !envRevealFlag {
+		ui.Info("Secret values are masked; pass --reveal to show them")
+	}
+	if cfg.Encryption.Enabled {
+		ui.Info("Values are shown unencrypted; 'drift env setup' would seal them in the secret store")
+	}
+	return nil
+}
+
+// renderedAssignment matches KEY=VALUE (env), KEY = VALUE (xcconfig), and
+// "KEY": VALUE (dart-define JSON) lines.
+var renderedAssignment = regexp.MustCompile(`^(\s*"?)([A-Za-z_][A-Za-z0-9_]*)("?\s*[=:]\s*)(.*?)(,?)$`)
+
+// maskRenderedSecrets masks the values of sensitive keys, and passwords
+// embedded in connection URLs, in rendered config content.
+func maskRenderedSecrets(content string, policy *mask.Policy) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		m := renderedAssignment.FindStringSubmatch(line)
+		if m == nil || m[4] == "" {
+			continue
+		}
+		value := m[4]
+		quote := ""
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			quote = value[:1]
+			value = value[1 : len(value)-1]
+		}
+		if masked := policy.Display(m[2], value); masked != value {
+			lines[i] = m[1] + m[2] + m[3] + quote + masked + quote + m[5]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
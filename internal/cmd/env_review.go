@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var (
+	envReviewFlag bool
+	envPRFlag     int
+)
+
+func init() {
+	envSetupCmd.Flags().BoolVar(&envReviewFlag, "review", false, "Target the PR preview branch read-only (blocks migrations and deploys)")
+	envSetupCmd.Flags().IntVar(&envPRFlag, "pr", 0, "With --review, the pull request to target (default: current branch)")
+}
+
+// envReview is the review target recorded in .drift/review.json. While it
+// exists, the env file is read-only and changes to the target are blocked.
+type envReview struct {
+	PR             int       `json:"pr,omitempty"`
+	HeadBranch     string    `json:"head_branch"`
+	SupabaseBranch string    `json:"supabase_branch,omitempty"`
+	EnvFile        string    `json:"env_file,omitempty"`
+	SetAt          time.Time `json:"set_at"`
+	Actor          string    `json:"actor,omitempty"`
+}
+
+// label describes the review target, e.g. "PR #142 (feat/login)".
+func (r *envReview) label() string {
+	if r.PR > 0 {
+		return fmt.Sprintf("PR #%d (%s)", r.PR, r.HeadBranch)
+	}
+	return r.HeadBranch
+}
+
+// loadEnvReview returns the active review target, or nil outside review mode.
+func loadEnvReview(cfg *config.Config) *envReview {
+	var review envReview
+	if ok, err := state.ReadJSON(state.Path(cfg.ProjectRoot(), state.Review), &review); !ok || err != nil {
+		return nil
+	}
+	return &review
+}
+
+// requestedEnvReview works out whether this env setup is in review mode and
+// which branch it reviews. Review mode is requested with --review or --pr, and
+// is implied in worktrees created with 'drift worktree create --review'.
+func requestedEnvReview(cfg *config.Config, gitBranch string) (*envReview, error) {
+	number := envPRFlag
+	if number == 0 {
+		if marked, ok := git.ReviewPullRequest(cfg.ProjectRoot()); ok {
+			number = marked
+		} else if !envReviewFlag {
+			return nil, nil
+		}
+	}
+
+	review := &envReview{PR: number, HeadBranch: envBranchFlag}
+	if review.HeadBranch == "" && number > 0 {
+		pr, err := git.GetPullRequest(number)
+		if err != nil {
+			return nil, fmt.Errorf("%w\nPass the PR's head branch with --branch instead", err)
+		}
+		review.HeadBranch = pr.HeadRefName
+	}
+	if review.HeadBranch == "" {
+		review.HeadBranch = gitBranch
+	}
+	return review, nil
+}
+
+// resolveReviewTarget finds the preview branch for review. Fallbacks and
+// persistent branches are refused: reviewing means looking at exactly the
+// branch the PR deployed.
+func resolveReviewTarget(client *supabase.Client, review *envReview) (*supabase.BranchInfo, error) {
+	info, err := ResolveSupabaseTarget(client, ResolveTargetOptions{GitBranch: review.HeadBranch})
+	if err != nil {
+		return nil, fmt.Errorf("no preview branch found for %s: %w", review.label(), err)
+	}
+	if info.IsFallback || info.Environment != supabase.EnvFeature {
+		return nil, fmt.Errorf("%s has no preview branch of its own (resolved to %s, %s)", review.label(), info.SupabaseBranch.Name, info.Environment)
+	}
+	return info, nil
+}
+
+// beginEnvSetupWrite makes a review-mode env file writable again so it can be
+// regenerated.
+func beginEnvSetupWrite(cfg *config.Config) {
+	if previous := loadEnvReview(cfg); previous != nil && previous.EnvFile != "" {
+		_ = os.Chmod(previous.EnvFile, 0644)
+	}
+}
+
+// finishEnvSetupReview records review mode after a successful env setup, or
+// leaves it if this setup was a normal one.
+func finishEnvSetupReview(cfg *config.Config, review *envReview, info *supabase.BranchInfo, outputPath string) error {
+	root := cfg.ProjectRoot()
+	if review == nil {
+		if loadEnvReview(cfg) != nil {
+			if err := state.Clean(root, []state.Area{state.Review}); err != nil {
+				return err
+			}
+			ui.Info("Left review mode")
+		}
+		return nil
+	}
+
+	review.SupabaseBranch = info.SupabaseBranch.Name
+	review.EnvFile = outputPath
+	review.SetAt = time.Now()
+	review.Actor = currentActor()
+	if err := state.WriteJSON(root, state.Path(root, state.Review), review); err != nil {
+		return fmt.Errorf("failed to record review mode: %w", err)
+	}
+	if err := os.Chmod(outputPath, 0444); err != nil {
+		ui.Warning(fmt.Sprintf("Could not make %s read-only: %v", outputPath, err))
+	}
+
+	ui.NewLine()
+	ui.Warningf("Review mode: env points at %s for %s", ui.Cyan(info.SupabaseBranch.Name), review.label())
+	ui.Info("Migrations, deploys, and seeds are blocked here; run 'drift env setup' without --review to leave")
+	return nil
+}
+
+// requireNotReviewMode refuses operation while the project is in review mode,
+// so nothing is pushed to someone else's preview branch by accident.
+func requireNotReviewMode(cfg *config.Config, operation string) error {
+	if cfg == nil {
+		return nil
+	}
+	review := loadEnvReview(cfg)
+	if review == nil {
+		return nil
+	}
+	return errs.Configf("cannot %s: env is in review mode for %s (%s)\nRun 'drift env setup' without --review to leave review mode", operation, review.label(), review.SupabaseBranch)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestEnvReviewLifecycle(t *testing.T) {
+	root := t.TempDir()
+	cfg := loadConfigWithBackupDir(t, root, "backups")
+	envFile := filepath.Join(root, ".env.local")
+	if err := os.WriteFile(envFile, []byte("SUPABASE_URL=x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := &supabase.BranchInfo{
+		Environment:    supabase.EnvFeature,
+		SupabaseBranch: &supabase.Branch{Name: "feat-login"},
+	}
+	review := &envReview{PR: 142, HeadBranch: "feat/login"}
+	if err := finishEnvSetupReview(cfg, review, info, envFile); err != nil {
+		t.Fatalf("finishEnvSetupReview() error = %v", err)
+	}
+
+	loaded := loadEnvReview(cfg)
+	if loaded == nil || loaded.PR != 142 || loaded.SupabaseBranch != "feat-login" {
+		t.Fatalf("loadEnvReview() = %+v", loaded)
+	}
+	if fi, err := os.Stat(envFile); err != nil || fi.Mode().Perm()&0222 != 0 {
+		t.Errorf("env file should be read-only, mode = %v", fi.Mode())
+	}
+
+	err := requireNotReviewMode(cfg, "push migrations")
+	if err == nil || !strings.Contains(err.Error(), "PR #142 (feat/login)") {
+		t.Errorf("requireNotReviewMode() error = %v, want review mode error", err)
+	}
+	if ok, err := ConfirmDeploymentOperation(info, cfg, "deploy Edge Functions"); ok || err == nil {
+		t.Errorf("ConfirmDeploymentOperation() = %v, %v, want blocked", ok, err)
+	}
+
+	// A normal env setup leaves review mode
+	beginEnvSetupWrite(cfg)
+	if fi, err := os.Stat(envFile); err != nil || fi.Mode().Perm()&0200 == 0 {
+		t.Errorf("env file should be writable again, mode = %v", fi.Mode())
+	}
+	if err := finishEnvSetupReview(cfg, nil, info, envFile); err != nil {
+		t.Fatalf("finishEnvSetupReview(nil) error = %v", err)
+	}
+	if loadEnvReview(cfg) != nil {
+		t.Error("review mode still active after normal setup")
+	}
+	if err := requireNotReviewMode(cfg, "push migrations"); err != nil {
+		t.Errorf("requireNotReviewMode() error = %v after leaving review mode", err)
+	}
+}
@@ -0,0 +1,437 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var envRotateKeysCmd = &cobra.Command{
+	Use:   "rotate-keys [branch]",
+	Short: "Rotate the Supabase API keys and update everything that uses them",
+	Long: `Rotate the anon and service role keys for an environment, then chase the
+old keys down.
+
+Publishable and secret keys are rotated through the Management API: new keys
+are created and the old ones are revoked once you confirm (or with
+--revoke-old). Legacy JWT keys can only be rotated by generating a new JWT
+secret in the dashboard; the command links to the page and waits for you.
+
+Afterwards it:
+  - re-runs 'drift env setup' in every worktree that still uses the old keys
+  - updates matching GitHub Actions secrets when 'gh' is available
+  - lists files that still reference the old keys, by value or by the
+    fingerprint recorded in .drift.lock`,
+	Example: `  drift env rotate-keys
+  drift env rotate-keys development --revoke-old
+  drift env rotate-keys --gh-env staging`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runEnvRotateKeys, RequireProject),
+}
+
+var (
+	envRotateRevokeOldFlag bool
+	envRotateNoCIFlag      bool
+	envRotateGhEnvFlag     string
+)
+
+func init() {
+	envRotateKeysCmd.Flags().BoolVar(&envRotateRevokeOldFlag, "revoke-old", false, "Revoke the old publishable and secret keys without asking")
+	envRotateKeysCmd.Flags().BoolVar(&envRotateNoCIFlag, "no-ci", false, "Do not update GitHub Actions secrets")
+	envRotateKeysCmd.Flags().StringVar(&envRotateGhEnvFlag, "gh-env", "", "GitHub environment whose secrets to update (default: repository secrets)")
+
+	envCmd.AddCommand(envRotateKeysCmd)
+}
+
+// ciKeySecrets maps the CI secret names drift updates to the key they hold.
+var ciKeySecrets = map[string]string{
+	"SUPABASE_ANON_KEY":             "anon",
+	"NEXT_PUBLIC_SUPABASE_ANON_KEY": "anon",
+	"VITE_SUPABASE_ANON_KEY":        "anon",
+	"SUPABASE_SERVICE_ROLE_KEY":     "service_role",
+}
+
+// keyScanSkipDirs are never searched for old key references.
+var keyScanSkipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"DerivedData":  true,
+	"Pods":         true,
+	".build":       true,
+	".next":        true,
+	".drift":       true,
+}
+
+// keyScanMaxSize skips files too large to be config.
+const keyScanMaxSize = 2 << 20
+
+// keyReference is a file that still refers to a rotated key.
+type keyReference struct {
+	Path string
+	Key  string
+	// Fingerprint is set when the file holds the key's fingerprint (a
+	// .drift.lock) rather than its value.
+	Fingerprint bool
+}
+
+func runEnvRotateKeys(ctx *Context) error {
+	cfg := ctx.Config()
+	if err := requireNotReviewMode(cfg, "rotate API keys"); err != nil {
+		return err
+	}
+
+	client := ctx.Client()
+	info, err := ctx.Target(ctx.Arg(0))
+	if err != nil {
+		return err
+	}
+	mgmt, err := supabase.NewManagementClient()
+	if err != nil {
+		return err
+	}
+
+	ui.Header("Rotate API Keys")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+
+	oldKeys, err := currentAPIKeys(client, info.ProjectRef)
+	if err != nil {
+		return err
+	}
+	for _, role := range sortedKeys(oldKeys) {
+		ui.KeyValue(role, ui.Dim(keyFingerprint(oldKeys[role])))
+	}
+	ui.NewLine()
+
+	keys, err := mgmt.ListAPIKeys(info.ProjectRef)
+	if err != nil {
+		return err
+	}
+	legacy := true
+	for _, k := range keys {
+		if k.APIKey == oldKeys["anon"] {
+			legacy = k.IsLegacy()
+		}
+	}
+	if legacy && IsYes() {
+		return fmt.Errorf("legacy JWT keys are rotated in the dashboard; run without --yes to be guided through it")
+	}
+
+	if info.Environment == supabase.EnvProduction {
+		confirmed, err := RequireProductionConfirmation(info.Environment, "rotate API keys")
+		if err != nil || !confirmed {
+			return errs.Cancelled("rotate API keys")
+		}
+	} else if !IsYes() {
+		confirmed, err := ui.PromptYesNo(fmt.Sprintf("Rotate the API keys for %s?", info.SupabaseBranch.Name), false)
+		if err != nil || !confirmed {
+			ui.Info("Cancelled")
+			return errs.Cancelled("rotate API keys")
+		}
+	}
+
+	var revoke []supabase.APIKey
+	if legacy {
+		if err := guideLegacyKeyRotation(info.ProjectRef); err != nil {
+			return err
+		}
+	} else {
+		revoke, err = rotateAPIKeys(mgmt, info.ProjectRef, keys, oldKeys)
+		if err != nil {
+			return err
+		}
+	}
+
+	newKeys, err := currentAPIKeys(client, info.ProjectRef)
+	if err != nil {
+		return err
+	}
+	if newKeys["anon"] == oldKeys["anon"] {
+		return fmt.Errorf("the anon key has not changed; nothing was rotated")
+	}
+	ui.Success("New keys are live")
+
+	if !envRotateNoCIFlag {
+		ui.NewLine()
+		updateCIKeySecrets(newKeys)
+	}
+
+	ui.NewLine()
+	regenerateWorktreeEnvs(oldKeys)
+
+	if len(revoke) > 0 {
+		ui.NewLine()
+		revokeOldAPIKeys(mgmt, info.ProjectRef, revoke)
+	}
+
+	ui.NewLine()
+	ui.SubHeader("Remaining References")
+	remaining := 0
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.IsBare {
+			continue
+		}
+		for _, ref := range findKeyReferences(wt.Path, oldKeys) {
+			remaining++
+			how := "value"
+			if ref.Fingerprint {
+				how = "fingerprint"
+			}
+			ui.List(fmt.Sprintf("%s %s", ref.Path, ui.Dim(fmt.Sprintf("(old %s %s)", ref.Key, how))))
+		}
+	}
+	if remaining == 0 {
+		ui.Success("No files reference the old keys")
+	} else {
+		ui.Warning(fmt.Sprintf("%d file(s) still reference the old keys; update them by hand", remaining))
+	}
+	ui.Info("Also update deployed apps, hosting providers, and secrets outside GitHub")
+	return nil
+}
+
+// currentAPIKeys returns the anon and service role keys drift writes for
+// projectRef. The service role key is optional.
+func currentAPIKeys(client *supabase.Client, projectRef string) (map[string]string, error) {
+	anon, err := client.GetAnonKey(projectRef)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]string{"anon": anon}
+	if service, err := client.GetServiceKey(projectRef); err == nil && service != "" {
+		keys["service_role"] = service
+	}
+	return keys, nil
+}
+
+// rotateAPIKeys creates a publishable and a secret key to replace the ones
+// in use and returns the keys to revoke afterwards.
+func rotateAPIKeys(mgmt *supabase.ManagementClient, projectRef string, keys []supabase.APIKey, oldKeys map[string]string) ([]supabase.APIKey, error) {
+	name := rotatedKeyName(time.Now())
+	var revoke []supabase.APIKey
+	for _, role := range sortedKeys(oldKeys) {
+		keyType := supabase.APIKeyPublishable
+		if role == "service_role" {
+			keyType = supabase.APIKeySecret
+		}
+
+		sp := ui.NewSpinner(fmt.Sprintf("Creating %s key %s", keyType, name))
+		sp.Start()
+		if _, err := mgmt.CreateAPIKey(projectRef, keyType, name); err != nil {
+			sp.Fail(fmt.Sprintf("Failed to create %s key", keyType))
+			return nil, err
+		}
+		sp.Success(fmt.Sprintf("Created %s key %s", keyType, name))
+
+		for _, k := range keys {
+			if k.APIKey == oldKeys[role] && !k.IsLegacy() {
+				revoke = append(revoke, k)
+			}
+		}
+	}
+	return revoke, nil
+}
+
+// rotatedKeyName names keys created by a rotation at now.
+func rotatedKeyName(now time.Time) string {
+	return "drift_" + now.UTC().Format("20060102_150405")
+}
+
+// guideLegacyKeyRotation walks the user through generating a new JWT secret,
+// which the Management API cannot do.
+func guideLegacyKeyRotation(projectRef string) error {
+	ui.Warning("This project uses legacy JWT keys, which change only when the JWT secret is rotated")
+	ui.Info("Rotating the JWT secret signs out every user and invalidates both keys at once")
+	ui.KeyValue("Dashboard", supabase.DashboardURL(projectRef, "settings/jwt"))
+	ui.NewLine()
+	done, err := ui.PromptYesNo("Have you generated a new JWT secret?", false)
+	if err != nil || !done {
+		return errs.Cancelled("rotate API keys")
+	}
+	return nil
+}
+
+// updateCIKeySecrets updates the GitHub Actions secrets in ciKeySecrets that
+// already exist in the repository.
+func updateCIKeySecrets(newKeys map[string]string) {
+	ui.SubHeader("CI Secrets")
+	if !shell.CommandExists("gh") {
+		ui.Info("gh not found; update CI secrets by hand")
+		return
+	}
+
+	listArgs := []string{"secret", "list"}
+	if envRotateGhEnvFlag != "" {
+		listArgs = append(listArgs, "--env", envRotateGhEnvFlag)
+	}
+	result, err := shell.Run("gh", listArgs...)
+	if err != nil || result.ExitCode != 0 {
+		ui.Warning("Could not list GitHub secrets; update CI secrets by hand")
+		return
+	}
+
+	updated := 0
+	for _, name := range parseGhSecretNames(result.Stdout) {
+		role, ok := ciKeySecrets[name]
+		if !ok || newKeys[role] == "" {
+			continue
+		}
+		setArgs := []string{"secret", "set", name}
+		if envRotateGhEnvFlag != "" {
+			setArgs = append(setArgs, "--env", envRotateGhEnvFlag)
+		}
+		// The value goes over stdin so it never appears in a process list.
+		if res, err := shell.RunWithInput(newKeys[role], "gh", setArgs...); err != nil || res.ExitCode != 0 {
+			ui.Warning(fmt.Sprintf("Failed to update %s", name))
+			continue
+		}
+		ui.Successf("Updated %s", name)
+		updated++
+	}
+	if updated == 0 {
+		ui.Info("No matching GitHub secrets to update")
+	}
+}
+
+// parseGhSecretNames returns the secret names from 'gh secret list' output.
+func parseGhSecretNames(output string) []string {
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == "NAME" {
+			continue
+		}
+		names = append(names, fields[0])
+	}
+	return names
+}
+
+// regenerateWorktreeEnvs re-runs 'drift env setup' in each worktree that
+// references oldKeys.
+func regenerateWorktreeEnvs(oldKeys map[string]string) {
+	ui.SubHeader("Worktrees")
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not list worktrees: %v", err))
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not find the drift executable: %v", err))
+		return
+	}
+
+	regenerated := 0
+	for _, wt := range worktrees {
+		if wt.IsBare || len(findKeyReferences(wt.Path, oldKeys)) == 0 {
+			continue
+		}
+		sp := ui.NewSpinner(fmt.Sprintf("Regenerating env in %s", wt.Path))
+		sp.Start()
+		result, err := shell.RunInDir(wt.Path, exe, "env", "setup", "--yes")
+		if err != nil || result.ExitCode != 0 {
+			sp.Fail(fmt.Sprintf("env setup failed in %s", wt.Path))
+			continue
+		}
+		sp.Success(fmt.Sprintf("Regenerated env in %s", wt.Path))
+		regenerated++
+	}
+	if regenerated == 0 {
+		ui.Info("No worktrees use the old keys")
+	}
+}
+
+// revokeOldAPIKeys deletes keys after confirmation or with --revoke-old.
+func revokeOldAPIKeys(mgmt *supabase.ManagementClient, projectRef string, keys []supabase.APIKey) {
+	ui.SubHeader("Old Keys")
+	if !envRotateRevokeOldFlag {
+		if IsYes() {
+			ui.Info("Old keys kept; rerun with --revoke-old once nothing uses them")
+			return
+		}
+		confirmed, err := ui.PromptYesNo("Revoke the old keys now? Clients still using them will fail", false)
+		if err != nil || !confirmed {
+			ui.Info("Old keys kept; revoke them in the dashboard once nothing uses them")
+			return
+		}
+	}
+	for _, k := range keys {
+		if err := mgmt.DeleteAPIKey(projectRef, k.ID); err != nil {
+			ui.Warning(fmt.Sprintf("Failed to revoke %s: %v", k.Name, err))
+			continue
+		}
+		ui.Successf("Revoked %s key %s", k.Type, k.Name)
+	}
+}
+
+// findKeyReferences lists files under root that contain a key from keys,
+// either verbatim or as the fingerprint written to .drift.lock.
+func findKeyReferences(root string, keys map[string]string) []keyReference {
+	type needle struct {
+		role        string
+		value       []byte
+		fingerprint bool
+	}
+	var needles []needle
+	for _, role := range sortedKeys(keys) {
+		if keys[role] == "" {
+			continue
+		}
+		needles = append(needles,
+			needle{role, []byte(keys[role]), false},
+			needle{role, []byte(keyFingerprint(keys[role])), true})
+	}
+
+	var refs []keyReference
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && keyScanSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err != nil || !info.Mode().IsRegular() || info.Size() > keyScanMaxSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for _, n := range needles {
+			if bytes.Contains(data, n.value) {
+				refs = append(refs, keyReference{Path: path, Key: n.role, Fingerprint: n.fingerprint})
+				break
+			}
+		}
+		return nil
+	})
+	return refs
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFindKeyReferences(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".env.local":          "NEXT_PUBLIC_SUPABASE_ANON_KEY=old-anon\n",
+		"ios/Config.xcconfig": "SUPABASE_ANON_KEY = new-anon\n",
+		".drift.lock":         "keys:\n  SUPABASE_SERVICE_ROLE_KEY: " + keyFingerprint("old-service") + "\n",
+		"node_modules/x/.env": "old-anon",
+		"scripts/seed.sh":     "curl -H 'apikey: old-service'",
+		"README.md":           "nothing to see",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refs := findKeyReferences(root, map[string]string{"anon": "old-anon", "service_role": "old-service"})
+	got := map[string]keyReference{}
+	for _, ref := range refs {
+		rel, _ := filepath.Rel(root, ref.Path)
+		ref.Path = rel
+		got[rel] = ref
+	}
+	want := map[string]keyReference{
+		".env.local":      {Path: ".env.local", Key: "anon"},
+		".drift.lock":     {Path: ".drift.lock", Key: "service_role", Fingerprint: true},
+		"scripts/seed.sh": {Path: "scripts/seed.sh", Key: "service_role"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findKeyReferences() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseGhSecretNames(t *testing.T) {
+	output := "NAME\tUPDATED\nSUPABASE_ANON_KEY\tabout 1 month ago\nSENTRY_DSN\t2026-01-02\n\n"
+	want := []string{"SUPABASE_ANON_KEY", "SENTRY_DSN"}
+	if got := parseGhSecretNames(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGhSecretNames() = %v, want %v", got, want)
+	}
+}
+
+func TestRotatedKeyName(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 30, 5, 0, time.UTC)
+	if got := rotatedKeyName(now); got != "drift_20261016_093005" {
+		t.Errorf("rotatedKeyName() = %q", got)
+	}
+}
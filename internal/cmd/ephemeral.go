@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+// ephemeralBranchPrefix marks preview branches owned by 'drift ephemeral'.
+// Prune only ever deletes branches with this prefix.
+const ephemeralBranchPrefix = "drift-ci-"
+
+var ephemeralCmd = &cobra.Command{
+	Use:   "ephemeral",
+	Short: "Short-lived Supabase branches for CI",
+	Long: `Create and destroy short-lived Supabase preview branches for CI runs.
+
+Branch names are derived from the CI run (GITHUB_RUN_ID, CI_PIPELINE_ID,
+BUILDKITE_BUILD_ID, CIRCLE_WORKFLOW_ID) so 'up' and 'down' in the same run
+agree without passing a name between steps.
+
+Commands:
+  up     - Create a branch, wait for it, seed it, and export credentials
+  down   - Delete the branch for this run
+  prune  - Delete leftover ephemeral branches older than a given age`,
+}
+
+var ephemeralUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Create an ephemeral branch for this CI run",
+	Long: `Create a Supabase preview branch for this CI run and wait until migrations
+have been applied.
+
+Credentials are written to --env-file (KEY=VALUE) and, on GitHub Actions,
+to $GITHUB_OUTPUT and $GITHUB_ENV with secrets masked in the log.`,
+	Example: `  drift ephemeral up --env-file .env.ci
+  drift ephemeral up --seed supabase/ci-seed.sql
+  drift ephemeral up --name drift-ci-pr-42`,
+	RunE: runEphemeralUp,
+}
+
+var ephemeralDownCmd = &cobra.Command{
+	Use:   "down [name]",
+	Short: "Delete the ephemeral branch for this CI run",
+	Long: `Delete the ephemeral branch created by 'drift ephemeral up'.
+
+Succeeds if the branch is already gone, so it is safe in an always() cleanup step.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEphemeralDown,
+}
+
+var ephemeralPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete orphaned ephemeral branches",
+	Long: `Delete ephemeral branches (named ` + ephemeralBranchPrefix + `*) older than --older-than.
+
+Use this on a schedule to clean up branches left behind by cancelled CI runs.`,
+	Example: `  drift ephemeral prune --older-than 6h
+  drift ephemeral prune --dry-run`,
+	RunE: runEphemeralPrune,
+}
+
+var (
+	ephemeralNameFlag         string
+	ephemeralSeedFlag         string
+	ephemeralEnvFileFlag      string
+	ephemeralTimeoutFlag      time.Duration
+	ephemeralOlderThanFlag    time.Duration
+	ephemeralDryRunFlag       bool
+	ephemeralGitHubOutputFlag bool
+)
+
+func init() {
+	ephemeralUpCmd.Flags().StringVar(&ephemeralNameFlag, "name", "", "Branch name (default: derived from the CI run)")
+	ephemeralUpCmd.Flags().StringVar(&ephemeralSeedFlag, "seed", "", "SQL file to apply once the branch is ready")
+	ephemeralUpCmd.Flags().StringVar(&ephemeralEnvFileFlag, "env-file", "", "Write credentials to this KEY=VALUE file")
+	ephemeralUpCmd.Flags().DurationVar(&ephemeralTimeoutFlag, "timeout", 10*time.Minute, "How long to wait for the branch to become ready")
+	ephemeralUpCmd.Flags().BoolVar(&ephemeralGitHubOutputFlag, "github-output", true, "Write step outputs and env when running on GitHub Actions")
+	ephemeralPruneCmd.Flags().DurationVar(&ephemeralOlderThanFlag, "older-than", 24*time.Hour, "Delete ephemeral branches created longer ago than this")
+	ephemeralPruneCmd.Flags().BoolVar(&ephemeralDryRunFlag, "dry-run", false, "List branches that would be deleted")
+
+	ephemeralCmd.AddCommand(ephemeralUpCmd)
+	ephemeralCmd.AddCommand(ephemeralDownCmd)
+	ephemeralCmd.AddCommand(ephemeralPruneCmd)
+	rootCmd.AddCommand(ephemeralCmd)
+}
+
+func runEphemeralUp(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	if err := ensureSupabaseLinked(cfg); err != nil {
+		return err
+	}
+	client := supabase.NewClient()
+
+	name := ephemeralNameFlag
+	if name == "" {
+		name = ephemeralBranchName(os.Getenv, time.Now())
+	}
+
+	ui.Header("Ephemeral Branch")
+	ui.KeyValue("Branch", ui.Cyan(name))
+	ui.NewLine()
+
+	if existing, _ := client.GetBranch(name); existing != nil {
+		ui.Infof("Branch %s already exists (status: %s); reusing it", name, existing.Status)
+	} else {
+		sp := ui.NewSpinner(fmt.Sprintf("Creating branch %s", name))
+		sp.Start()
+		if _, err := client.CreateBranch(name); err != nil {
+			sp.Fail("Failed to create branch")
+			return err
+		}
+		sp.Success(fmt.Sprintf("Created branch %s", name))
+	}
+
+	sp := ui.NewSpinner("Waiting for migrations")
+	sp.Start()
+	if _, err := client.WaitForBranch(name, supabase.WaitOptions{Timeout: ephemeralTimeoutFlag, Interval: 10 * time.Second}); err != nil {
+		sp.Fail("Branch did not become ready")
+		return err
+	}
+	sp.Success("Branch ready")
+
+	info, err := ResolveSupabaseTarget(client, ResolveTargetOptions{GitBranch: name})
+	if err != nil {
+		return err
+	}
+	values, err := fetchTestEnv(client, cfg, info)
+	if err != nil {
+		return err
+	}
+
+	if ephemeralSeedFlag != "" {
+		conn := values["DATABASE_URL_POOLER"]
+		if conn == "" {
+			conn = values["DATABASE_URL"]
+		}
+		if conn == "" {
+			return fmt.Errorf("no database URL available for %s; cannot apply seed", name)
+		}
+		sp = ui.NewSpinner(fmt.Sprintf("Applying %s", ephemeralSeedFlag))
+		sp.Start()
+		if err := database.ApplySQLFile(conn, ephemeralSeedFlag); err != nil {
+			sp.Fail("Seed failed")
+			return err
+		}
+		sp.Success(fmt.Sprintf("Applied %s", ephemeralSeedFlag))
+	}
+
+	if ephemeralEnvFileFlag != "" {
+		if err := os.WriteFile(ephemeralEnvFileFlag, []byte(formatEnvFile(values)), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", ephemeralEnvFileFlag, err)
+		}
+		ui.Successf("Credentials written to %s", ephemeralEnvFileFlag)
+	}
+
+	if ephemeralGitHubOutputFlag && os.Getenv("GITHUB_ACTIONS") == "true" {
+		if err := writeGitHubActionsOutputs(name, info, values); err != nil {
+			return err
+		}
+		ui.Success("Exported step outputs and env for GitHub Actions")
+	}
+
+	ui.NewLine()
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+	ui.KeyValue("API URL", info.APIURL)
+	return nil
+}
+
+func runEphemeralDown(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	if err := ensureSupabaseLinked(cfg); err != nil {
+		return err
+	}
+	client := supabase.NewClient()
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		name = ephemeralBranchName(os.Getenv, time.Now())
+		if strings.HasPrefix(name, ephemeralBranchPrefix+"local-") {
+			return fmt.Errorf("no CI run detected; pass the branch name: drift ephemeral down <name>")
+		}
+	}
+	if !strings.HasPrefix(name, ephemeralBranchPrefix) && !IsYes() {
+		return fmt.Errorf("refusing to delete '%s': not an ephemeral branch (use --yes to override)", name)
+	}
+
+	if existing, _ := client.GetBranch(name); existing == nil {
+		ui.Infof("Branch %s does not exist; nothing to do", name)
+		return nil
+	}
+
+	sp := ui.NewSpinner(fmt.Sprintf("Deleting branch %s", name))
+	sp.Start()
+	if err := client.DeleteBranch(name); err != nil {
+		sp.Fail("Failed to delete branch")
+		return err
+	}
+	sp.Success(fmt.Sprintf("Deleted branch %s", name))
+	return nil
+}
+
+func runEphemeralPrune(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	if err := ensureSupabaseLinked(cfg); err != nil {
+		return err
+	}
+	client := supabase.NewClient()
+
+	branches, err := client.GetBranches()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var stale []supabase.Branch
+	for _, b := range branches {
+		if isOrphanedEphemeralBranch(b, now, ephemeralOlderThanFlag) {
+			stale = append(stale, b)
+		}
+	}
+
+	if len(stale) == 0 {
+		ui.Infof("No ephemeral branches older than %s", ephemeralOlderThanFlag)
+		return nil
+	}
+
+	var failed int
+	for _, b := range stale {
+		if ephemeralDryRunFlag {
+			ui.List(fmt.Sprintf("%s (created %s)", b.Name, b.CreatedAt))
+			continue
+		}
+		if err := client.DeleteBranch(b.Name); err != nil {
+			ui.Errorf("%s: %v", b.Name, err)
+			failed++
+			continue
+		}
+		ui.Successf("Deleted %s", b.Name)
+	}
+
+	if ephemeralDryRunFlag {
+		ui.NewLine()
+		ui.Infof("%d branch(es) would be deleted", len(stale))
+		return nil
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d branches", failed, len(stale))
+	}
+	return nil
+}
+
+// ephemeralBranchName derives a stable branch name from the CI run, so that
+// separate up/down steps in one run resolve to the same branch.
+func ephemeralBranchName(getenv func(string) string, now time.Time) string {
+	var id string
+	switch {
+	case getenv("GITHUB_RUN_ID") != "":
+		id = "gh-" + getenv("GITHUB_RUN_ID")
+		if attempt := getenv("GITHUB_RUN_ATTEMPT"); attempt != "" {
+			id += "-" + attempt
+		}
+	case getenv("CI_PIPELINE_ID") != "":
+		id = "gl-" + getenv("CI_PIPELINE_ID")
+	case getenv("BUILDKITE_BUILD_ID") != "":
+		id = "bk-" + getenv("BUILDKITE_BUILD_ID")
+	case getenv("CIRCLE_WORKFLOW_ID") != "":
+		id = "cc-" + getenv("CIRCLE_WORKFLOW_ID")
+	default:
+		id = fmt.Sprintf("local-%d", now.Unix())
+	}
+	return ephemeralBranchPrefix + strings.ToLower(id)
+}
+
+// isOrphanedEphemeralBranch reports whether b is an ephemeral branch older than maxAge.
+// Branches with an unparseable creation time are left alone.
+func isOrphanedEphemeralBranch(b supabase.Branch, now time.Time, maxAge time.Duration) bool {
+	if !strings.HasPrefix(b.Name, ephemeralBranchPrefix) || b.IsDefault || b.Persistent {
+		return false
+	}
+	created, err := time.Parse(time.RFC3339, b.CreatedAt)
+	if err != nil {
+		return false
+	}
+	return now.Sub(created) > maxAge
+}
+
+// writeGitHubActionsOutputs appends step outputs and env to the files GitHub
+// Actions provides, masking secret values in the job log first.
+func writeGitHubActionsOutputs(name string, info *supabase.BranchInfo, values map[string]string) error {
+	for _, key := range []string{"SUPABASE_ANON_KEY", "SUPABASE_SERVICE_ROLE_KEY", "DATABASE_URL", "DATABASE_URL_POOLER"} {
+		if v := values[key]; v != "" {
+			fmt.Printf("::add-mask::%s\n", v)
+		}
+	}
+
+	outputs := map[string]string{
+		"branch":            name,
+		"project_ref":       info.ProjectRef,
+		"supabase_url":      values["SUPABASE_URL"],
+		"supabase_anon_key": values["SUPABASE_ANON_KEY"],
+	}
+	if path := os.Getenv("GITHUB_OUTPUT"); path != "" {
+		if err := appendEnvFile(path, outputs); err != nil {
+			return fmt.Errorf("failed to write GITHUB_OUTPUT: %w", err)
+		}
+	}
+	if path := os.Getenv("GITHUB_ENV"); path != "" {
+		if err := appendEnvFile(path, values); err != nil {
+			return fmt.Errorf("failed to write GITHUB_ENV: %w", err)
+		}
+	}
+	return nil
+}
+
+// appendEnvFile appends sorted KEY=VALUE lines to path.
+func appendEnvFile(path string, values map[string]string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(formatEnvFile(values))
+	return err
+}
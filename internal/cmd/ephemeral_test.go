@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestEphemeralBranchName(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"github", map[string]string{"GITHUB_RUN_ID": "123", "GITHUB_RUN_ATTEMPT": "2"}, "drift-ci-gh-123-2"},
+		{"gitlab", map[string]string{"CI_PIPELINE_ID": "456"}, "drift-ci-gl-456"},
+		{"circle uppercase id", map[string]string{"CIRCLE_WORKFLOW_ID": "AbC"}, "drift-ci-cc-abc"},
+		{"local", map[string]string{}, "drift-ci-local-1700000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := ephemeralBranchName(getenv, now); got != tt.want {
+				t.Errorf("ephemeralBranchName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsOrphanedEphemeralBranch(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-48 * time.Hour).Format(time.RFC3339)
+	recent := now.Add(-time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name   string
+		branch supabase.Branch
+		want   bool
+	}{
+		{"old ephemeral", supabase.Branch{Name: "drift-ci-gh-1", CreatedAt: old}, true},
+		{"recent ephemeral", supabase.Branch{Name: "drift-ci-gh-2", CreatedAt: recent}, false},
+		{"old feature branch", supabase.Branch{Name: "feat-login", CreatedAt: old}, false},
+		{"persistent", supabase.Branch{Name: "drift-ci-keep", CreatedAt: old, Persistent: true}, false},
+		{"bad timestamp", supabase.Branch{Name: "drift-ci-gh-3", CreatedAt: "yesterday"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOrphanedEphemeralBranch(tt.branch, now, 24*time.Hour); got != tt.want {
+				t.Errorf("isOrphanedEphemeralBranch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
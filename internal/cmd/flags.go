@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var flagsCmd = &cobra.Command{
+	Use:   "flags",
+	Short: "Manage feature flags per environment",
+	Long: `List, toggle, and copy feature flags stored in a database table.
+
+Flags live in public.feature_flags by default, with a text "key" column and
+a boolean "enabled" column. Point drift at a different table in .drift.yaml:
+
+  database:
+    flags:
+      table: public.feature_flags
+      key_column: key
+      enabled_column: enabled
+
+Changes follow the usual environment confirmations: production and protected
+branches require typing 'yes', development asks first.`,
+}
+
+var flagsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List flags on a branch",
+	Example: `  drift flags list
+  drift flags list --branch main`,
+	Args: cobra.NoArgs,
+	RunE: runFlagsList,
+}
+
+var flagsEnableCmd = &cobra.Command{
+	Use:   "enable <flag>...",
+	Short: "Enable flags on a branch",
+	Example: `  drift flags enable new_checkout
+  drift flags enable new_checkout dark_mode --branch feature/x`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFlagsSet(args, true)
+	},
+}
+
+var flagsDisableCmd = &cobra.Command{
+	Use:     "disable <flag>...",
+	Short:   "Disable flags on a branch",
+	Example: `  drift flags disable new_checkout --branch main`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFlagsSet(args, false)
+	},
+}
+
+var flagsCopyCmd = &cobra.Command{
+	Use:   "copy [flag]...",
+	Short: "Copy flag states from one environment to another",
+	Long: `Make flag states on the target match the source. Only flags that exist on
+both sides are changed; flags missing from the target are listed (create
+them with a migration or 'drift db sync-table'). Pass flag names to copy a
+subset.
+
+--from accepts prod, dev, or a branch name. --to defaults to the Supabase
+branch for the current git branch.`,
+	Example: `  drift flags copy --from dev
+  drift flags copy --from dev --to feature/x
+  drift flags copy new_checkout --from prod --dry-run`,
+	RunE: Run(runFlagsCopy, RequireProject),
+}
+
+var (
+	flagsBranchFlag string
+	flagsFromFlag   string
+	flagsToFlag     string
+	flagsDryRunFlag bool
+)
+
+func init() {
+	for _, c := range []*cobra.Command{flagsListCmd, flagsEnableCmd, flagsDisableCmd} {
+		c.Flags().StringVarP(&flagsBranchFlag, "branch", "b", "", "Target Supabase branch (default: current git branch)")
+	}
+	flagsCopyCmd.Flags().StringVar(&flagsFromFlag, "from", "dev", "Source: prod, dev, or a branch name")
+	flagsCopyCmd.Flags().StringVar(&flagsToFlag, "to", "", "Target branch (default: current git branch)")
+	flagsCopyCmd.Flags().BoolVar(&flagsDryRunFlag, "dry-run", false, "Show changes without applying them")
+
+	flagsCmd.AddCommand(flagsListCmd)
+	flagsCmd.AddCommand(flagsEnableCmd)
+	flagsCmd.AddCommand(flagsDisableCmd)
+	flagsCmd.AddCommand(flagsCopyCmd)
+	rootCmd.AddCommand(flagsCmd)
+}
+
+func runFlagsList(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	table := flagTable(cfg)
+
+	ui.Header("Feature Flags")
+
+	info, opts, err := resolveTargetDB(supabase.NewClient(), cfg, flagsBranchFlag)
+	if err != nil {
+		return err
+	}
+
+	flags, err := database.FetchFlags(opts, table)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", table.Table, err)
+	}
+	if len(flags) == 0 {
+		ui.Infof("No flags in %s on %s", table.Table, info.SupabaseBranch.Name)
+		return nil
+	}
+	for _, f := range flags {
+		ui.KeyValue(f.Name, flagStateString(f.Enabled))
+	}
+	return nil
+}
+
+func runFlagsSet(names []string, enabled bool) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	table := flagTable(cfg)
+
+	verb := "Disable"
+	if enabled {
+		verb = "Enable"
+	}
+	ui.Header(verb + " Flags")
+
+	info, opts, err := resolveTargetDB(supabase.NewClient(), cfg, flagsBranchFlag)
+	if err != nil {
+		return err
+	}
+
+	current, err := database.FetchFlags(opts, table)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", table.Table, err)
+	}
+	desired := make([]database.Flag, len(names))
+	for i, name := range names {
+		desired[i] = database.Flag{Name: name, Enabled: enabled}
+	}
+	changes, missing := database.FlagDiff(desired, current)
+	if len(missing) > 0 {
+		return fmt.Errorf("unknown flag(s) on %s: %v", info.SupabaseBranch.Name, missing)
+	}
+	if len(changes) == 0 {
+		ui.Successf("Already %sd on %s", flagStateVerb(enabled), info.SupabaseBranch.Name)
+		return nil
+	}
+
+	confirmed, err := ConfirmDeploymentOperation(info, cfg, fmt.Sprintf("%s %d flag(s) on %s", flagStateVerb(enabled), len(changes), info.SupabaseBranch.Name))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return nil
+	}
+
+	if err := applyFlagChanges(opts, table, changes); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		ui.Successf("%s %s", c.Name, flagStateString(c.To))
+	}
+	return nil
+}
+
+func runFlagsCopy(ctx *Context) error {
+	cfg, client := ctx.Config(), ctx.Client()
+	table := flagTable(cfg)
+
+	ui.Header("Copy Flags")
+
+	source, err := resolveNamedBranch(client, flagsFromFlag)
+	if err != nil {
+		return err
+	}
+	info, targetOpts, err := resolveTargetDB(client, cfg, flagsToFlag)
+	if err != nil {
+		return err
+	}
+	if source.ProjectRef == info.ProjectRef {
+		return fmt.Errorf("source and target are the same branch (%s)", source.Name)
+	}
+	ui.KeyValue("Source", ui.Cyan(source.Name))
+
+	sourceURL, err := getDbURLForProject(source.ProjectRef)
+	if err != nil {
+		return err
+	}
+	sourceOpts, err := restoreOptionsFromDBURL(sourceURL)
+	if err != nil {
+		return err
+	}
+
+	sourceFlags, err := database.FetchFlags(sourceOpts, table)
+	if err != nil {
+		return fmt.Errorf("failed to read %s on %s: %w", table.Table, source.Name, err)
+	}
+	if len(ctx.Args) > 0 {
+		sourceFlags, err = selectFlags(sourceFlags, ctx.Args)
+		if err != nil {
+			return fmt.Errorf("%w on %s", err, source.Name)
+		}
+	}
+	targetFlags, err := database.FetchFlags(targetOpts, table)
+	if err != nil {
+		return fmt.Errorf("failed to read %s on %s: %w", table.Table, info.SupabaseBranch.Name, err)
+	}
+
+	changes, missing := database.FlagDiff(sourceFlags, targetFlags)
+	ui.NewLine()
+	for _, c := range changes {
+		ui.KeyValue(c.Name, fmt.Sprintf("%s -> %s", flagStateString(c.From), flagStateString(c.To)))
+	}
+	for _, name := range missing {
+		ui.Warningf("%s is missing on %s", name, info.SupabaseBranch.Name)
+	}
+
+	if len(changes) == 0 {
+		ui.Successf("Flags on %s already match %s", info.SupabaseBranch.Name, source.Name)
+		return nil
+	}
+	if ctx.DryRun() {
+		ui.NewLine()
+		ui.Info("Dry run - no changes made")
+		return nil
+	}
+
+	confirmed, err := ConfirmDeploymentOperation(info, cfg, fmt.Sprintf("copy %d flag(s) from %s to %s", len(changes), source.Name, info.SupabaseBranch.Name))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return nil
+	}
+
+	if err := applyFlagChanges(targetOpts, table, changes); err != nil {
+		return err
+	}
+	ui.Successf("Copied %d flag(s) from %s to %s", len(changes), source.Name, info.SupabaseBranch.Name)
+	return nil
+}
+
+// flagTable returns the configured feature flag table.
+func flagTable(cfg *config.Config) database.FlagTable {
+	return database.FlagTable{
+		Table:   database.NormalizeTableName(cfg.Database.Flags.GetTable()),
+		Key:     cfg.Database.Flags.GetKeyColumn(),
+		Enabled: cfg.Database.Flags.GetEnabledColumn(),
+	}
+}
+
+// selectFlags keeps only the named flags, erroring on names not in flags.
+func selectFlags(flags []database.Flag, names []string) ([]database.Flag, error) {
+	byName := make(map[string]database.Flag, len(flags))
+	for _, f := range flags {
+		byName[f.Name] = f
+	}
+	selected := make([]database.Flag, 0, len(names))
+	var unknown []string
+	for _, name := range names {
+		f, ok := byName[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		selected = append(selected, f)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown flag(s) %v", unknown)
+	}
+	return selected, nil
+}
+
+func applyFlagChanges(opts database.RestoreOptions, table database.FlagTable, changes []database.FlagChange) error {
+	states := make(map[string]bool, len(changes))
+	for _, c := range changes {
+		states[c.Name] = c.To
+	}
+	sp := ui.NewSpinner("Updating flags")
+	sp.Start()
+	if _, err := database.RunScript(opts, database.SetFlagsScript(table, states)); err != nil {
+		sp.Fail("Failed to update flags")
+		return err
+	}
+	sp.Stop()
+	return nil
+}
+
+func flagStateString(enabled bool) string {
+	if enabled {
+		return ui.Green("on")
+	}
+	return ui.Dim("off")
+}
+
+func flagStateVerb(enabled bool) string {
+	if enabled {
+		return "enable"
+	}
+	return "disable"
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+)
+
+func TestFlagTable(t *testing.T) {
+	cfg := &config.Config{}
+	if got := flagTable(cfg); got != (database.FlagTable{Table: "public.feature_flags", Key: "key", Enabled: "enabled"}) {
+		t.Errorf("flagTable() defaults = %+v", got)
+	}
+
+	cfg.Database.Flags = config.FlagsConfig{Table: "flags", KeyColumn: "name", EnabledColumn: "is_on"}
+	if got := flagTable(cfg); got != (database.FlagTable{Table: "public.flags", Key: "name", Enabled: "is_on"}) {
+		t.Errorf("flagTable() configured = %+v", got)
+	}
+}
+
+func TestSelectFlags(t *testing.T) {
+	flags := []database.Flag{{Name: "a", Enabled: true}, {Name: "b"}}
+
+	got, err := selectFlags(flags, []string{"b"})
+	if err != nil || len(got) != 1 || got[0].Name != "b" {
+		t.Errorf("selectFlags() = %v, %v; want [b]", got, err)
+	}
+	if _, err := selectFlags(flags, []string{"a", "missing"}); err == nil {
+		t.Error("selectFlags() accepted an unknown flag")
+	}
+}
@@ -9,11 +9,12 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/undrift/drift/internal/config"
-	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/mask"
 	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/textdiff"
 	"github.com/undrift/drift/internal/ui"
-	"github.com/undrift/drift/pkg/shell"
+	"golang.org/x/term"
 )
 
 var functionsCmd = &cobra.Command{
@@ -24,8 +25,11 @@ var functionsCmd = &cobra.Command{
 The functions command provides tools for working with Edge Functions:
   - List deployed vs local functions and their sync status
   - View function logs for debugging
+  - Track invocations, error rates, and latency per function
   - Compare local code with deployed versions
   - Delete deployed functions
+  - Prune orphaned (deployed only) functions in bulk
+  - Download deployed functions into the local project
   - Create new functions from templates
   - Serve functions locally for development
 
@@ -38,9 +42,12 @@ If no matching Supabase branch exists, Drift resolves fallback in this order:
   3) interactive non-production branch selection.`,
 	Example: `  drift functions list           # Compare local vs deployed functions
   drift functions logs my-func    # View logs for a function
+  drift functions metrics         # Error rates and p95 per function
   drift functions diff my-func    # Compare local vs deployed code
+  drift functions download --missing # Recover deployed-only functions
   drift functions serve           # Run functions locally
-  drift functions delete my-func  # Delete a deployed function`,
+  drift functions delete my-func  # Delete a deployed function
+  drift functions prune           # Delete orphaned functions in bulk`,
 }
 
 var functionsListCmd = &cobra.Command{
@@ -57,7 +64,7 @@ The target environment is determined by your current git branch,
 or can be overridden with the --branch flag.`,
 	Example: `  drift functions list              # List for current branch
   drift functions list --branch dev # List for dev environment`,
-	RunE: runFunctionsList,
+	RunE: Run(runFunctionsList, RequireProject),
 }
 
 var functionsLogsCmd = &cobra.Command{
@@ -81,7 +88,7 @@ save them to a file.`,
   drift functions logs -b dev my-func # Logs from dev environment
   drift functions logs -o logs.txt fn # Save logs to file`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: runFunctionsLogs,
+	RunE: Run(runFunctionsLogs, RequireProject),
 }
 
 var functionsDeleteCmd = &cobra.Command{
@@ -98,7 +105,7 @@ Extra confirmation required for production environments.`,
   drift functions delete old-func -y # Skip confirmation
   drift functions delete             # Interactive: select function`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: runFunctionsDelete,
+	RunE: Run(runFunctionsDelete, RequireProject),
 }
 
 var functionsDiffCmd = &cobra.Command{
@@ -116,12 +123,65 @@ The diff shows:
   - Unchanged lines for context
 
 If no function name is provided, you'll be prompted to select from
-functions that exist both locally and remotely.`,
+functions that exist both locally and remotely.
+
+The diff is computed by drift itself, so no diff tool needs to be
+installed. Use --side-by-side for two columns, --stat for a summary, or
+--json for the hunks as data.`,
 	Example: `  drift functions diff my-func       # Diff specific function
   drift functions diff               # Interactive: select function
-  drift functions diff -b prod func  # Diff against production`,
+  drift functions diff -b prod func  # Diff against production
+  drift functions diff my-func --side-by-side
+  drift functions diff my-func --stat
+  drift functions diff my-func --json`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: runFunctionsDiff,
+	RunE: Run(runFunctionsDiff, RequireProject),
+}
+
+var functionsPruneCmd = &cobra.Command{
+	Use:   "prune [function-name...]",
+	Short: "Delete orphaned functions in bulk",
+	Long: `Delete deployed Edge Functions that no longer exist locally.
+
+Lists every "deployed only" function for the target environment with its
+version, last update, and last invocation from the function logs (within
+--since, where logs are available), then deletes the selected functions.
+
+By default shows an interactive multi-select with none pre-selected.
+Pass function names to prune specific orphans, or --all to select every
+orphaned function. Functions that exist locally are never pruned.
+
+Requires confirmation unless --yes flag is used. Protected and production
+environments require typing 'yes'.`,
+	Example: `  drift functions prune                  # Select orphans to delete
+  drift functions prune old-func legacy  # Prune specific orphans
+  drift functions prune --all -b dev     # Prune every orphan on dev
+  drift functions prune --since 168h     # Check a week of logs`,
+	RunE: Run(runFunctionsPrune, RequireProject),
+}
+
+var functionsDownloadCmd = &cobra.Command{
+	Use:   "download [function-name]",
+	Short: "Download deployed functions into the local project",
+	Long: `Download the deployed source of Edge Functions into your local
+functions directory (supabase/functions by default).
+
+Use this to recover functions that are deployed but missing locally
+(shown as "deployed only" by 'drift functions list'), or to reset a
+local function to the deployed version.
+
+With --all, every deployed function is downloaded. With --missing, only
+deployed functions that do not exist locally are downloaded. If no
+function name is provided, you'll be prompted to select one.
+
+Local functions that already exist are replaced only after confirmation,
+unless --yes flag is used.`,
+	Example: `  drift functions download my-func    # Download one function
+  drift functions download            # Interactive: select function
+  drift functions download --missing  # Recover deployed-only functions
+  drift functions download --all -b dev`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runFunctionsDownload, RequireProject),
 }
 
 var functionsServeCmd = &cobra.Command{
@@ -137,12 +197,29 @@ If a function name is provided, only that function is served.
 Otherwise, all functions are served.
 
 The --env-file flag can specify a custom environment file.
-By default, uses .env.local if it exists.`,
+By default, uses .env.local if it exists.
+
+Functions in every supabase.functions.roots directory are served, with the
+roots' import maps merged as for 'drift deploy functions'.
+
+Each log line is prefixed with the function it came from, in its own color.
+All functions share one edge runtime, so lines that name no function (such
+as console output) are credited to the function that last handled a request.
+
+If the server crashes it is restarted, backing off between attempts, and
+given up on after 5 crashes in a minute (--no-restart to exit at once).
+'drift functions serving' shows what is being served and on which routes.
+
+--inspect <name> serves only that function with the Deno debugger on
+127.0.0.1:8083; attach from chrome://inspect or your editor.`,
 	Example: `  drift functions serve              # Serve all functions
   drift functions serve my-func      # Serve specific function
-  drift functions serve --env .env   # Use custom env file`,
+  drift functions serve --env .env   # Use custom env file
+  drift functions serve --import-map import_map.json
+  drift functions serve --inspect my-func
+  drift functions serve --inspect my-func --inspect-mode wait`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: runFunctionsServe,
+	RunE: Run(runFunctionsServe, RequireProject),
 }
 
 var functionsNewCmd = &cobra.Command{
@@ -158,13 +235,24 @@ The function is created locally and can be deployed with:
 	Example: `  drift functions new send-email     # Create send-email function
   drift functions new process-webhook`,
 	Args: cobra.ExactArgs(1),
-	RunE: runFunctionsNew,
+	RunE: Run(runFunctionsNew, RequireProject),
 }
 
 var (
 	functionsBranchFlag string
 	functionsEnvFile    string
+	functionsImportMap  string
 	functionsLogsOutput string
+
+	functionsDownloadAll     bool
+	functionsDownloadMissing bool
+
+	functionsPruneAll   bool
+	functionsPruneSince time.Duration
+
+	functionsDiffSideBySide bool
+	functionsDiffStat       bool
+	functionsDiffContext    int
 )
 
 func init() {
@@ -173,56 +261,47 @@ func init() {
 	functionsLogsCmd.Flags().StringVarP(&functionsBranchFlag, "branch", "b", "", "Target Supabase branch")
 	functionsDeleteCmd.Flags().StringVarP(&functionsBranchFlag, "branch", "b", "", "Target Supabase branch")
 	functionsDiffCmd.Flags().StringVarP(&functionsBranchFlag, "branch", "b", "", "Target Supabase branch")
+	functionsDownloadCmd.Flags().StringVarP(&functionsBranchFlag, "branch", "b", "", "Target Supabase branch")
+	functionsPruneCmd.Flags().StringVarP(&functionsBranchFlag, "branch", "b", "", "Target Supabase branch")
+
+	// Selection and log window for prune
+	functionsDiffCmd.Flags().BoolVar(&functionsDiffSideBySide, "side-by-side", false, "Show deployed and local code in two columns")
+	functionsDiffCmd.Flags().BoolVar(&functionsDiffStat, "stat", false, "Show only a summary of changed lines")
+	functionsDiffCmd.Flags().IntVarP(&functionsDiffContext, "context", "U", 3, "Unchanged lines shown around each change")
+	functionsPruneCmd.Flags().BoolVar(&functionsPruneAll, "all", false, "Select all orphaned functions")
+	functionsPruneCmd.Flags().DurationVar(&functionsPruneSince, "since", 24*time.Hour, "How far back to look for invocations")
+
+	// Selection for download
+	functionsDownloadCmd.Flags().BoolVar(&functionsDownloadAll, "all", false, "Download all deployed functions")
+	functionsDownloadCmd.Flags().BoolVar(&functionsDownloadMissing, "missing", false, "Download only deployed functions missing locally")
 
 	// Env file for serve
 	functionsServeCmd.Flags().StringVar(&functionsEnvFile, "env", "", "Path to environment file (default: .env.local)")
+	functionsServeCmd.Flags().StringVar(&functionsImportMap, "import-map", "", "Import map for all functions (replaces supabase.functions import maps)")
 
 	// Output file for logs
 	functionsLogsCmd.Flags().StringVarP(&functionsLogsOutput, "output", "o", "", "Save logs to file instead of displaying")
+	addShowSecretsFlag(functionsLogsCmd)
 
 	functionsCmd.AddCommand(functionsListCmd)
 	functionsCmd.AddCommand(functionsLogsCmd)
 	functionsCmd.AddCommand(functionsDeleteCmd)
 	functionsCmd.AddCommand(functionsDiffCmd)
+	functionsCmd.AddCommand(functionsDownloadCmd)
+	functionsCmd.AddCommand(functionsPruneCmd)
 	functionsCmd.AddCommand(functionsServeCmd)
 	functionsCmd.AddCommand(functionsNewCmd)
 	rootCmd.AddCommand(functionsCmd)
 }
 
-// getFunctionsTarget resolves the target environment for functions commands.
-func getFunctionsTarget() (*supabase.BranchInfo, error) {
-	cfg := config.LoadOrDefault()
-	client := supabase.NewClient()
-
-	currentBranch, err := git.CurrentBranch()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current branch: %w", err)
-	}
-
-	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, currentBranch, functionsBranchFlag)
-	if err != nil {
-		return nil, err
-	}
-
-	return info, nil
-}
-
-func runFunctionsList(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runFunctionsList(ctx *Context) error {
+	cfg := ctx.Config()
 
 	// Resolve target environment
-	sp := ui.NewSpinner("Resolving target environment")
-	sp.Start()
-
-	info, err := getFunctionsTarget()
+	info, err := ctx.Target(functionsBranchFlag)
 	if err != nil {
-		sp.Fail("Failed to resolve environment")
 		return err
 	}
-	sp.Stop()
 
 	ui.Header("Edge Functions")
 	ui.KeyValue("Environment", envColorString(string(info.Environment)))
@@ -236,7 +315,7 @@ func runFunctionsList(cmd *cobra.Command, args []string) error {
 	ui.NewLine()
 
 	// Get local functions
-	localFunctions, err := supabase.ListFunctions(cfg.GetFunctionsPath())
+	localFunctions, err := listLocalFunctions(cfg)
 	localFuncMap := make(map[string]bool)
 	if err != nil {
 		if IsVerbose() {
@@ -249,10 +328,10 @@ func runFunctionsList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get deployed functions
-	sp = ui.NewSpinner("Fetching deployed functions")
+	sp := ui.NewSpinner("Fetching deployed functions")
 	sp.Start()
 
-	client := supabase.NewClient()
+	client := ctx.Client()
 	deployedFunctions, err := client.ListDeployedFunctions(info.ProjectRef)
 	sp.Stop()
 	if info.IsOverride && IsVerbose() {
@@ -341,7 +420,8 @@ func runFunctionsList(cmd *cobra.Command, args []string) error {
 		ui.List("drift deploy functions        - Deploy local functions")
 	}
 	if len(orphaned) > 0 {
-		ui.List("drift functions delete <name> - Remove orphaned functions")
+		ui.List("drift functions download --missing - Recover orphaned functions locally")
+		ui.List("drift functions prune         - Remove orphaned functions")
 	}
 	if len(synced) > 0 {
 		ui.List("drift functions diff <name>   - Verify deployed code matches local")
@@ -351,21 +431,12 @@ func runFunctionsList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runFunctionsLogs(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-
+func runFunctionsLogs(ctx *Context) error {
 	// Resolve target
-	sp := ui.NewSpinner("Resolving target environment")
-	sp.Start()
-
-	info, err := getFunctionsTarget()
+	info, err := ctx.Target(functionsBranchFlag)
 	if err != nil {
-		sp.Fail("Failed to resolve environment")
 		return err
 	}
-	sp.Stop()
 	if info.IsOverride && IsVerbose() {
 		ui.Infof("Override target: %s (from %s)", info.SupabaseBranch.Name, info.OverrideFrom)
 	}
@@ -375,12 +446,12 @@ func runFunctionsLogs(cmd *cobra.Command, args []string) error {
 
 	var functionName string
 
-	if len(args) > 0 {
-		functionName = args[0]
+	if len(ctx.Args) > 0 {
+		functionName = ctx.Args[0]
 	} else {
 		// Interactive: select from deployed functions
-		client := supabase.NewClient()
-		sp = ui.NewSpinner("Fetching deployed functions")
+		client := ctx.Client()
+		sp := ui.NewSpinner("Fetching deployed functions")
 		sp.Start()
 
 		deployedFunctions, err := client.ListDeployedFunctions(info.ProjectRef)
@@ -414,11 +485,12 @@ func runFunctionsLogs(cmd *cobra.Command, args []string) error {
 	ui.NewLine()
 
 	// Fetch logs
-	sp = ui.NewSpinner("Fetching logs")
+	sp := ui.NewSpinner("Fetching logs")
 	sp.Start()
 
-	client := supabase.NewClient()
+	client := ctx.Client()
 	logs, err := client.GetFunctionLogs(functionName, info.ProjectRef)
+	policy := displayPolicy(ctx.Cmd, ctx.Config())
 	sp.Stop()
 	if info.IsOverride && IsVerbose() {
 		ui.Infof("Override target: %s (from %s)", info.SupabaseBranch.Name, info.OverrideFrom)
@@ -455,7 +527,7 @@ func runFunctionsLogs(cmd *cobra.Command, args []string) error {
 
 		if outputFile != "" {
 			// Save to file
-			if err := saveLogsToFile(logs, outputFile, functionName, info); err != nil {
+			if err := saveLogsToFile(logs, outputFile, functionName, info, policy); err != nil {
 				return fmt.Errorf("failed to save logs: %w", err)
 			}
 			ui.Successf("Saved %d log entries to %s", len(logs), outputFile)
@@ -483,7 +555,7 @@ func runFunctionsLogs(cmd *cobra.Command, args []string) error {
 					level = ui.Dim(level)
 				}
 
-				fmt.Printf("%s [%s] %s\n", ui.Dim(timestamp), level, entry.EventMessage)
+				fmt.Printf("%s [%s] %s\n", ui.Dim(timestamp), level, policy.Text(entry.EventMessage))
 			}
 		}
 	}
@@ -498,8 +570,9 @@ func runFunctionsLogs(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// saveLogsToFile writes function logs to a file in a readable format.
-func saveLogsToFile(logs []supabase.FunctionLogEntry, filename, functionName string, info *supabase.BranchInfo) error {
+// saveLogsToFile writes function logs to a file in a readable format, with
+// secrets masked by policy.
+func saveLogsToFile(logs []supabase.FunctionLogEntry, filename, functionName string, info *supabase.BranchInfo, policy *mask.Policy) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
@@ -522,36 +595,27 @@ func saveLogsToFile(logs []supabase.FunctionLogEntry, filename, functionName str
 			timestamp = t.Local().Format("2006-01-02 15:04:05.000")
 		}
 
-		fmt.Fprintf(file, "%s [%s] %s\n", timestamp, entry.Level, entry.EventMessage)
+		fmt.Fprintf(file, "%s [%s] %s\n", timestamp, entry.Level, policy.Text(entry.EventMessage))
 	}
 
 	return nil
 }
 
-func runFunctionsDelete(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-
+func runFunctionsDelete(ctx *Context) error {
 	// Resolve target
-	sp := ui.NewSpinner("Resolving target environment")
-	sp.Start()
-
-	info, err := getFunctionsTarget()
+	info, err := ctx.Target(functionsBranchFlag)
 	if err != nil {
-		sp.Fail("Failed to resolve environment")
 		return err
 	}
-	sp.Stop()
 
 	var functionName string
 
-	if len(args) > 0 {
-		functionName = args[0]
+	if len(ctx.Args) > 0 {
+		functionName = ctx.Args[0]
 	} else {
 		// Interactive: select from deployed functions
-		client := supabase.NewClient()
-		sp = ui.NewSpinner("Fetching deployed functions")
+		client := ctx.Client()
+		sp := ui.NewSpinner("Fetching deployed functions")
 		sp.Start()
 
 		deployedFunctions, err := client.ListDeployedFunctions(info.ProjectRef)
@@ -604,10 +668,10 @@ func runFunctionsDelete(cmd *cobra.Command, args []string) error {
 	}
 
 	// Delete the function
-	sp = ui.NewSpinner(fmt.Sprintf("Deleting %s", functionName))
+	sp := ui.NewSpinner(fmt.Sprintf("Deleting %s", functionName))
 	sp.Start()
 
-	client := supabase.NewClient()
+	client := ctx.Client()
 	if err := client.DeleteFunction(functionName, info.ProjectRef); err != nil {
 		sp.Fail("Failed to delete function")
 		return err
@@ -623,36 +687,212 @@ func runFunctionsDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runFunctionsDiff(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
+func runFunctionsPrune(ctx *Context) error {
+	cfg := ctx.Config()
+
+	if functionsPruneSince <= 0 {
+		return fmt.Errorf("--since must be a positive duration")
+	}
+	if len(ctx.Args) > 0 && functionsPruneAll {
+		return fmt.Errorf("cannot combine function names with --all")
 	}
-	cfg := config.LoadOrDefault()
 
 	// Resolve target
-	sp := ui.NewSpinner("Resolving target environment")
+	info, err := ctx.Target(functionsBranchFlag)
+	if err != nil {
+		return err
+	}
+
+	localFunctions, err := listLocalFunctions(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to list local functions: %w", err)
+	}
+
+	sp := ui.NewSpinner("Fetching deployed functions")
 	sp.Start()
 
-	info, err := getFunctionsTarget()
+	client := ctx.Client()
+	deployedFunctions, err := client.ListDeployedFunctions(info.ProjectRef)
+	sp.Stop()
+
 	if err != nil {
-		sp.Fail("Failed to resolve environment")
-		return err
+		return fmt.Errorf("failed to list deployed functions: %w", err)
+	}
+
+	orphaned := findOrphanedFunctions(localFunctions, deployedFunctions)
+	if len(orphaned) == 0 {
+		ui.Success("No orphaned functions - every deployed function exists locally")
+		return nil
+	}
+
+	// Last invocations are best effort; logs need an access token.
+	var lastInvoked map[string]time.Time
+	sp = ui.NewSpinner("Checking function logs")
+	sp.Start()
+	if mgmt, err := supabase.NewManagementClient(); err == nil {
+		end := time.Now().UTC()
+		lastInvoked, err = mgmt.GetFunctionLastInvocations(info.ProjectRef, end.Add(-functionsPruneSince), end)
+		if err != nil && IsVerbose() {
+			ui.Warningf("Could not read function logs: %v", err)
+		}
 	}
 	sp.Stop()
 
+	ui.Header("Orphaned Functions")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+	if info.IsFallback {
+		ui.Warningf("Using fallback target branch: %s", info.SupabaseBranch.GitBranch)
+	}
+	ui.NewLine()
+
+	fmt.Printf("  %-30s %-9s %-22s %s\n", "NAME", "VERSION", "UPDATED", "LAST INVOKED")
+	for _, fn := range orphaned {
+		fmt.Printf("  %-30s %-9s %-22s %s\n", fn.Name, fn.Version, fn.UpdatedAt, lastInvokedLabel(lastInvoked, fn.Name, functionsPruneSince))
+	}
+	ui.NewLine()
+
+	names := make([]string, len(orphaned))
+	for i, fn := range orphaned {
+		names[i] = fn.Name
+	}
+
+	var selected []string
+	switch {
+	case len(ctx.Args) > 0:
+		orphanSet := make(map[string]bool, len(names))
+		for _, name := range names {
+			orphanSet[name] = true
+		}
+		for _, name := range ctx.Args {
+			if !orphanSet[name] {
+				return fmt.Errorf("'%s' is not an orphaned function (it exists locally or is not deployed)", name)
+			}
+		}
+		selected = ctx.Args
+	case functionsPruneAll:
+		selected = names
+	default:
+		// None pre-selected — destructive action
+		selected, err = ui.PromptMultiSelect("Select functions to delete", names, nil)
+		if err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			ui.Info("No functions selected")
+			return nil
+		}
+	}
+
+	fmt.Printf("\n%s Functions to delete (%d):\n", ui.Red("⚠"), len(selected))
+	for _, name := range selected {
+		line := fmt.Sprintf("  %s %s", ui.Red("✗"), name)
+		if _, recent := lastInvoked[name]; recent {
+			line += ui.Yellow(fmt.Sprintf(" (invoked %s)", formatBackupAge(lastInvoked[name])))
+		}
+		fmt.Println(line)
+	}
+
+	// Production safeguards
+	operation := fmt.Sprintf("delete %d deployed functions", len(selected))
+	confirmed, err := ConfirmDeploymentOperation(info, cfg, operation)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return errs.Cancelled(operation)
+	}
+	if info.Environment == supabase.EnvFeature && !IsYes() {
+		ok, err := ui.PromptYesNo("Delete these functions? Local files are not affected", false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			ui.Info("Cancelled")
+			return nil
+		}
+	}
+
+	var succeeded int
+	for _, name := range selected {
+		sp := ui.NewSpinner(fmt.Sprintf("Deleting %s", name))
+		sp.Start()
+		if err := client.DeleteFunction(name, info.ProjectRef); err != nil {
+			sp.Fail(fmt.Sprintf("Failed to delete %s: %s", name, err))
+			continue
+		}
+		sp.Success(fmt.Sprintf("Deleted %s", name))
+		succeeded++
+	}
+
+	ui.Successf("Deleted %d/%d functions", succeeded, len(selected))
+	if succeeded < len(selected) {
+		return fmt.Errorf("%d functions failed to delete", len(selected)-succeeded)
+	}
+	return nil
+}
+
+// findOrphanedFunctions returns deployed functions with no local source,
+// sorted by name.
+func findOrphanedFunctions(local []supabase.Function, deployed []supabase.DeployedFunction) []supabase.DeployedFunction {
+	localSet := make(map[string]bool, len(local))
+	for _, fn := range local {
+		localSet[fn.Name] = true
+	}
+
+	var orphaned []supabase.DeployedFunction
+	for _, fn := range deployed {
+		if !localSet[fn.Name] {
+			orphaned = append(orphaned, fn)
+		}
+	}
+	sort.Slice(orphaned, func(i, j int) bool {
+		return orphaned[i].Name < orphaned[j].Name
+	})
+	return orphaned
+}
+
+// lastInvokedLabel describes when a function was last invoked. A nil map
+// means the logs could not be read.
+func lastInvokedLabel(lastInvoked map[string]time.Time, name string, window time.Duration) string {
+	if lastInvoked == nil {
+		return ui.Dim("unknown")
+	}
+	if ts, ok := lastInvoked[name]; ok {
+		return ui.Yellow(formatBackupAge(ts))
+	}
+	since := window.String()
+	if strings.HasSuffix(since, "m0s") {
+		since = strings.TrimSuffix(since, "0s")
+	}
+	if strings.HasSuffix(since, "h0m") {
+		since = strings.TrimSuffix(since, "0m")
+	}
+	return ui.Dim(fmt.Sprintf("not in last %s", since))
+}
+
+func runFunctionsDiff(ctx *Context) error {
+	cfg := ctx.Config()
+
+	// Resolve target
+	info, err := ctx.Target(functionsBranchFlag)
+	if err != nil {
+		return err
+	}
+
 	var functionName string
 
-	if len(args) > 0 {
-		functionName = args[0]
+	if len(ctx.Args) > 0 {
+		functionName = ctx.Args[0]
 	} else {
 		// Interactive: find functions that exist both locally and remotely
-		localFunctions, err := supabase.ListFunctions(cfg.GetFunctionsPath())
+		localFunctions, err := listLocalFunctions(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to list local functions: %w", err)
 		}
 
-		client := supabase.NewClient()
-		sp = ui.NewSpinner("Fetching deployed functions")
+		client := ctx.Client()
+		sp := ui.NewSpinner("Fetching deployed functions")
 		sp.Start()
 
 		deployedFunctions, err := client.ListDeployedFunctions(info.ProjectRef)
@@ -699,16 +939,23 @@ func runFunctionsDiff(cmd *cobra.Command, args []string) error {
 
 	// Check local function exists
 	localPath := filepath.Join(cfg.GetFunctionsPath(), functionName, "index.ts")
+	if localFunctions, err := listLocalFunctions(cfg); err == nil {
+		for _, fn := range localFunctions {
+			if fn.Name == functionName {
+				localPath = filepath.Join(fn.Path, "index.ts")
+			}
+		}
+	}
 	if _, err := os.Stat(localPath); os.IsNotExist(err) {
 		ui.Errorf("Local function not found: %s", localPath)
 		return nil
 	}
 
 	// Download deployed function
-	sp = ui.NewSpinner("Downloading deployed function")
+	sp := ui.NewSpinner("Downloading deployed function")
 	sp.Start()
 
-	client := supabase.NewClient()
+	client := ctx.Client()
 	tempDir, err := client.DownloadFunctionToTemp(functionName, info.ProjectRef)
 	if err != nil {
 		sp.Fail("Failed to download function")
@@ -717,64 +964,294 @@ func runFunctionsDiff(cmd *cobra.Command, args []string) error {
 	defer os.RemoveAll(tempDir)
 	sp.Stop()
 
-	// Try different possible paths where the function might be downloaded
-	possiblePaths := []string{
-		filepath.Join(tempDir, "supabase", "functions", functionName, "index.ts"),
-		filepath.Join(tempDir, functionName, "index.ts"),
-		filepath.Join(tempDir, "index.ts"),
-	}
-
-	var remotePath string
-	for _, p := range possiblePaths {
-		if _, err := os.Stat(p); err == nil {
-			remotePath = p
-			break
-		}
-	}
-
-	if remotePath == "" {
+	remoteDir := findDownloadedFunctionDir(tempDir, functionName)
+	if remoteDir == "" {
 		// Debug: list what's in temp dir
 		if IsVerbose() {
 			ui.Warningf("Downloaded function has unexpected structure in %s", tempDir)
 		}
 		return fmt.Errorf("could not find index.ts in downloaded function")
 	}
+	remotePath := filepath.Join(remoteDir, "index.ts")
+
+	d, err := diffFunctionSource(functionName, remotePath, localPath)
+	if err != nil {
+		return err
+	}
+	if ctx.JSON() {
+		return ctx.PrintJSON(d)
+	}
 
-	// Run diff
 	ui.SubHeader("Differences (local vs deployed)")
 	ui.NewLine()
+	printFunctionDiff(d)
 
-	result, _ := shell.Run("diff", "--color=always", "-u", remotePath, localPath)
+	// Next steps
+	ui.NewLine()
+	ui.SubHeader("Next Steps")
+	ui.List("drift deploy functions        - Deploy changes")
+	ui.List("drift functions list          - See all function statuses")
+
+	return nil
+}
+
+// diffFunctionSource diffs the deployed source of a function against the
+// local one.
+func diffFunctionSource(name, remotePath, localPath string) (*textdiff.Diff, error) {
+	remote, err := os.ReadFile(remotePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployed function: %w", err)
+	}
+	local, err := os.ReadFile(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local function: %w", err)
+	}
+	return textdiff.Compute("deployed/"+name+"/index.ts", string(remote), "local/"+name+"/index.ts", string(local), functionsDiffContext), nil
+}
 
-	if result.Stdout == "" && result.ExitCode == 0 {
+// printFunctionDiff prints d in the view chosen by the diff flags.
+func printFunctionDiff(d *textdiff.Diff) {
+	if d.Equal() {
 		ui.Success("No differences found - local matches deployed!")
-	} else if result.Stdout != "" {
-		fmt.Println(result.Stdout)
+		return
+	}
+	style := textdiff.Style{Insert: ui.Green, Delete: ui.Red, Header: ui.Bold, Hunk: ui.Cyan}
+	switch {
+	case functionsDiffStat:
+		textdiff.Stat(os.Stdout, d, 40, style)
+	case functionsDiffSideBySide:
+		textdiff.SideBySide(os.Stdout, d, terminalWidth(), style)
+	default:
+		textdiff.Unified(os.Stdout, d, style)
 		ui.NewLine()
 		ui.Info("Legend: Lines starting with + are in local, - are in deployed")
-	} else if result.ExitCode != 0 && result.Stderr != "" {
-		ui.Warningf("Diff error: %s", result.Stderr)
+	}
+}
+
+// terminalWidth returns the width of the terminal on stdout, or 120 when
+// stdout is not a terminal.
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	return 120
+}
+
+func runFunctionsDownload(ctx *Context) error {
+	cfg := ctx.Config()
+
+	if functionsDownloadAll && functionsDownloadMissing {
+		return fmt.Errorf("--all and --missing cannot be used together")
+	}
+	if len(ctx.Args) > 0 && (functionsDownloadAll || functionsDownloadMissing) {
+		return fmt.Errorf("cannot combine a function name with --all or --missing")
+	}
+
+	// Resolve target
+	info, err := ctx.Target(functionsBranchFlag)
+	if err != nil {
+		return err
+	}
+
+	client := ctx.Client()
+	functionsPath := cfg.GetFunctionsPath()
+
+	var names []string
+	if len(ctx.Args) > 0 {
+		names = []string{ctx.Args[0]}
+	} else {
+		sp := ui.NewSpinner("Fetching deployed functions")
+		sp.Start()
+
+		deployedFunctions, err := client.ListDeployedFunctions(info.ProjectRef)
+		sp.Stop()
+
+		if err != nil {
+			return fmt.Errorf("failed to list deployed functions: %w", err)
+		}
+
+		if len(deployedFunctions) == 0 {
+			ui.Info("No deployed functions found")
+			return nil
+		}
+
+		deployed := make([]string, len(deployedFunctions))
+		for i, fn := range deployedFunctions {
+			deployed[i] = fn.Name
+		}
+		sort.Strings(deployed)
+
+		switch {
+		case functionsDownloadAll:
+			names = deployed
+		case functionsDownloadMissing:
+			for _, name := range deployed {
+				if !localFunctionExists(functionsPath, name) {
+					names = append(names, name)
+				}
+			}
+			if len(names) == 0 {
+				ui.Success("All deployed functions exist locally")
+				return nil
+			}
+		default:
+			selected, err := ui.PromptSelect("Select function to download", deployed)
+			if err != nil {
+				return err
+			}
+			names = []string{selected}
+		}
+	}
+
+	ui.Header("Download Functions")
+	ui.KeyValue("Functions", ui.Cyan(strings.Join(names, ", ")))
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+	ui.KeyValue("Destination", functionsPath)
+	ui.NewLine()
+
+	// Confirm before replacing local code
+	var existing []string
+	for _, name := range names {
+		if localFunctionExists(functionsPath, name) {
+			existing = append(existing, name)
+		}
+	}
+	if len(existing) > 0 && !IsYes() {
+		ui.Warning("These local functions will be replaced with the deployed version:")
+		for _, name := range existing {
+			ui.List(name)
+		}
+		ui.NewLine()
+
+		confirmed, err := ui.PromptYesNo("Overwrite local files?", false)
+		if err != nil || !confirmed {
+			ui.Info("Cancelled")
+			return nil
+		}
+	}
+
+	var failed int
+	for _, name := range names {
+		sp := ui.NewSpinner(fmt.Sprintf("Downloading %s", name))
+		sp.Start()
+
+		if err := downloadFunctionTo(client, name, info.ProjectRef, filepath.Join(functionsPath, name)); err != nil {
+			sp.Fail(fmt.Sprintf("%s: %v", name, err))
+			failed++
+			continue
+		}
+		sp.Success(fmt.Sprintf("Downloaded %s", name))
 	}
 
 	// Next steps
 	ui.NewLine()
 	ui.SubHeader("Next Steps")
-	ui.List("drift deploy functions        - Deploy changes")
+	ui.List("drift functions diff <name>   - Compare local vs deployed")
 	ui.List("drift functions list          - See all function statuses")
 
+	if failed > 0 {
+		return fmt.Errorf("%d of %d functions failed to download", failed, len(names))
+	}
 	return nil
 }
 
-func runFunctionsServe(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
+// localFunctionExists reports whether a function directory exists locally.
+func localFunctionExists(functionsPath, name string) bool {
+	info, err := os.Stat(filepath.Join(functionsPath, name))
+	return err == nil && info.IsDir()
+}
+
+// downloadFunctionTo downloads the deployed source of a function and
+// replaces dest with it.
+func downloadFunctionTo(client *supabase.Client, name, projectRef, dest string) error {
+	tempDir, err := client.DownloadFunctionToTemp(name, projectRef)
+	if err != nil {
+		return err
 	}
-	cfg := config.LoadOrDefault()
+	defer os.RemoveAll(tempDir)
 
-	var functionName string
-	if len(args) > 0 {
-		functionName = args[0]
+	src := findDownloadedFunctionDir(tempDir, name)
+	if src == "" {
+		return fmt.Errorf("could not find index.ts in downloaded function")
 	}
+	return replaceDir(src, dest)
+}
+
+// findDownloadedFunctionDir returns the directory holding index.ts in the
+// output of 'supabase functions download', whose layout varies between CLI
+// versions. It returns "" when none is found.
+func findDownloadedFunctionDir(tempDir, name string) string {
+	candidates := []string{
+		filepath.Join(tempDir, "supabase", "functions", name),
+		filepath.Join(tempDir, name),
+		tempDir,
+	}
+	for _, dir := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, "index.ts")); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
+
+// replaceDir replaces dest with a copy of src. The copy is staged next to
+// dest so a failed copy leaves the existing directory untouched.
+func replaceDir(src, dest string) error {
+	parent := filepath.Dir(dest)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", parent, err)
+	}
+
+	staging, err := os.MkdirTemp(parent, "."+filepath.Base(dest)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := copyDir(src, staging); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", dest, err)
+	}
+	if err := os.Rename(staging, dest); err != nil {
+		return fmt.Errorf("failed to move function into place: %w", err)
+	}
+	return nil
+}
+
+// copyDir copies the files under src into dest, preserving file modes.
+func copyDir(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		fileInfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, fileInfo.Mode().Perm())
+	})
+}
+
+func runFunctionsServe(ctx *Context) error {
+	cfg := ctx.Config()
+
+	functionName := ctx.Arg(0)
 
 	// Determine env file
 	envFile := functionsEnvFile
@@ -789,6 +1266,16 @@ func runFunctionsServe(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if functionsInspect != "" {
+		if functionName != "" && functionName != functionsInspect {
+			return fmt.Errorf("--inspect %s conflicts with serving %s", functionsInspect, functionName)
+		}
+		if !validInspectMode(functionsInspectMode) {
+			return fmt.Errorf("invalid --inspect-mode %q (use run, brk, or wait)", functionsInspectMode)
+		}
+		functionName = functionsInspect
+	}
+
 	ui.Header("Serve Edge Functions")
 	if functionName != "" {
 		ui.KeyValue("Function", ui.Cyan(functionName))
@@ -798,29 +1285,64 @@ func runFunctionsServe(cmd *cobra.Command, args []string) error {
 	if envFile != "" {
 		ui.KeyValue("Env File", ui.Cyan(envFile))
 	}
-	ui.KeyValue("Functions Path", ui.Cyan(cfg.GetFunctionsPath()))
+	for _, path := range functionRootPaths(cfg) {
+		ui.KeyValue("Functions Path", ui.Cyan(path))
+	}
+
+	layout, err := loadFunctionsLayout(cfg, functionsImportMap)
+	if err != nil {
+		return err
+	}
+	opts := supabase.ServeOptions{EnvFile: envFile, ImportMap: layout.ImportMap, Workdir: layout.Workdir}
+	if opts.ImportMap != "" {
+		ui.KeyValue("Import Map", ui.Cyan(opts.ImportMap))
+	}
+	if opts.Workdir != "" && opts.EnvFile != "" {
+		// The CLI would resolve a relative env file against the workdir.
+		if opts.EnvFile, err = filepath.Abs(opts.EnvFile); err != nil {
+			return err
+		}
+	}
+	if functionsInspect != "" {
+		opts.InspectMode = functionsInspectMode
+	}
+
+	var names []string
+	if functionName == "" {
+		for _, fn := range layout.Functions {
+			names = append(names, fn.Name)
+		}
+	} else if layoutHasFunction(layout, functionName) {
+		names = []string{functionName}
+	} else {
+		return fmt.Errorf("function '%s' not found in %s", functionName, strings.Join(functionRootPaths(cfg), ", "))
+	}
 	ui.NewLine()
 
 	ui.Info("Starting local function server...")
 	ui.Info("Press Ctrl+C to stop")
 	ui.NewLine()
 
-	// Run serve command
-	client := supabase.NewClient()
-	if err := client.ServeFunction(functionName, envFile); err != nil {
+	if err := serveFunctionsSupervised(cfg, functionName, opts, names); err != nil {
 		return fmt.Errorf("failed to serve functions: %w", err)
 	}
 
 	return nil
 }
 
-func runFunctionsNew(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
+func layoutHasFunction(layout *functionsLayout, name string) bool {
+	for _, fn := range layout.Functions {
+		if fn.Name == name {
+			return true
+		}
 	}
-	cfg := config.LoadOrDefault()
+	return false
+}
+
+func runFunctionsNew(ctx *Context) error {
+	cfg := ctx.Config()
 
-	functionName := args[0]
+	functionName := ctx.Args[0]
 
 	// Validate name
 	if strings.ContainsAny(functionName, " /\\") {
@@ -842,7 +1364,7 @@ func runFunctionsNew(cmd *cobra.Command, args []string) error {
 	sp := ui.NewSpinner(fmt.Sprintf("Creating %s", functionName))
 	sp.Start()
 
-	client := supabase.NewClient()
+	client := ctx.Client()
 	if err := client.NewFunction(functionName); err != nil {
 		sp.Fail("Failed to create function")
 		return err
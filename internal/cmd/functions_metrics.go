@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var functionsMetricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Show invocation counts, error rates, and latency per function",
+	Long: `Show how each Edge Function is doing on the target branch: invocations,
+errors (5xx responses), error rate, and p95 execution time over --since.
+
+Metrics come from the function edge logs and need SUPABASE_ACCESS_TOKEN.
+Functions whose error rate is at or above --error-threshold are flagged and
+listed first. Deployed functions with no invocations in the window are shown
+at the end, so a function that silently stopped being called stands out.`,
+	Example: `  drift functions metrics                     # Last 24 hours
+  drift functions metrics --since 1h          # Last hour
+  drift functions metrics -b dev --error-threshold 1
+  drift functions metrics --json | jq '.functions[] | select(.flagged)'`,
+	Args: cobra.NoArgs,
+	RunE: Run(runFunctionsMetrics, RequireProject),
+}
+
+var (
+	functionsMetricsSince     time.Duration
+	functionsMetricsThreshold float64
+	functionsMetricsJSON      bool
+)
+
+func init() {
+	functionsMetricsCmd.Flags().StringVarP(&functionsBranchFlag, "branch", "b", "", "Target Supabase branch")
+	functionsMetricsCmd.Flags().DurationVar(&functionsMetricsSince, "since", 24*time.Hour, "How far back to look")
+	functionsMetricsCmd.Flags().Float64Var(&functionsMetricsThreshold, "error-threshold", 5, "Flag functions with at least this error rate (percent)")
+	functionsMetricsCmd.Flags().BoolVar(&functionsMetricsJSON, "json", false, "Output as JSON")
+
+	functionsCmd.AddCommand(functionsMetricsCmd)
+}
+
+// functionMetricsRow is one function in the metrics report.
+type functionMetricsRow struct {
+	Name        string  `json:"name"`
+	Invocations int     `json:"invocations"`
+	Errors      int     `json:"errors"`
+	ErrorRate   float64 `json:"error_rate"`
+	P95Ms       int64   `json:"p95_ms"`
+	Flagged     bool    `json:"flagged"`
+}
+
+type functionMetricsReport struct {
+	ProjectRef     string               `json:"project_ref"`
+	Environment    string               `json:"environment"`
+	Since          string               `json:"since"`
+	ErrorThreshold float64              `json:"error_threshold"`
+	Functions      []functionMetricsRow `json:"functions"`
+}
+
+func runFunctionsMetrics(ctx *Context) error {
+	if functionsMetricsSince <= 0 {
+		return fmt.Errorf("--since must be a positive duration")
+	}
+	if functionsMetricsThreshold < 0 || functionsMetricsThreshold > 100 {
+		return fmt.Errorf("--error-threshold must be between 0 and 100")
+	}
+
+	info, err := ctx.Target(functionsBranchFlag)
+	if err != nil {
+		return err
+	}
+
+	mgmt, err := supabase.NewManagementClient()
+	if err != nil {
+		return err
+	}
+
+	sp := ui.NewSpinner("Fetching function metrics")
+	sp.Start()
+	end := time.Now().UTC()
+	metrics, err := mgmt.GetFunctionMetrics(info.ProjectRef, end.Add(-functionsMetricsSince), end)
+	if err != nil {
+		sp.Fail("Failed to fetch function metrics")
+		return err
+	}
+
+	// Deployed functions without invocations are best effort.
+	var deployed []string
+	if fns, err := ctx.Client().ListDeployedFunctions(info.ProjectRef); err == nil {
+		for _, fn := range fns {
+			deployed = append(deployed, fn.Name)
+		}
+	} else if IsVerbose() {
+		ui.Warningf("Could not list deployed functions: %v", err)
+	}
+	sp.Stop()
+
+	rows := buildFunctionMetricsRows(metrics, deployed, functionsMetricsThreshold/100)
+
+	if ctx.JSON() {
+		return ctx.PrintJSON(functionMetricsReport{
+			ProjectRef:     info.ProjectRef,
+			Environment:    string(info.Environment),
+			Since:          functionsMetricsSince.String(),
+			ErrorThreshold: functionsMetricsThreshold,
+			Functions:      rows,
+		})
+	}
+
+	ui.Header("Function Metrics")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+	ui.KeyValue("Window", "last "+functionsMetricsSince.String())
+	if info.IsFallback {
+		ui.Warningf("Using fallback target branch: %s", info.SupabaseBranch.GitBranch)
+	}
+	ui.NewLine()
+
+	if len(rows) == 0 {
+		ui.Infof("No function invocations in the last %s", functionsMetricsSince)
+		return nil
+	}
+
+	table := ui.NewTable([]string{"Function", "Invocations", "Errors", "Error Rate", "P95"})
+	flagged := 0
+	for _, row := range rows {
+		rate, p95 := "-", "-"
+		if row.Invocations > 0 {
+			rate = formatErrorRate(row.ErrorRate)
+			p95 = (time.Duration(row.P95Ms) * time.Millisecond).String()
+		}
+		name := row.Name
+		if row.Flagged {
+			flagged++
+			name = ui.Red(name)
+			rate = ui.Red(rate)
+		}
+		table.AddRow([]string{name, strconv.Itoa(row.Invocations), strconv.Itoa(row.Errors), rate, p95})
+	}
+	table.Render()
+	ui.NewLine()
+
+	if flagged > 0 {
+		ui.Warningf("%d function(s) at or above the %s error rate threshold", flagged, formatErrorRate(functionsMetricsThreshold/100))
+		ui.Info("Run 'drift functions logs <name>' to see their errors")
+	} else {
+		ui.Success("No function is above the error rate threshold")
+	}
+	return nil
+}
+
+// buildFunctionMetricsRows turns metrics into report rows. Deployed
+// functions with no invocations are added with zero counts. Rows are sorted
+// flagged first, then by error rate, invocations, and name.
+func buildFunctionMetricsRows(metrics map[string]supabase.FunctionMetrics, deployed []string, threshold float64) []functionMetricsRow {
+	rows := make([]functionMetricsRow, 0, len(metrics))
+	for _, m := range metrics {
+		rate := m.ErrorRate()
+		rows = append(rows, functionMetricsRow{
+			Name:        m.Name,
+			Invocations: m.Invocations,
+			Errors:      m.Errors,
+			ErrorRate:   rate,
+			P95Ms:       m.P95.Milliseconds(),
+			Flagged:     m.Errors > 0 && rate >= threshold,
+		})
+	}
+	for _, name := range deployed {
+		if _, ok := metrics[name]; !ok {
+			rows = append(rows, functionMetricsRow{Name: name})
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Flagged != b.Flagged {
+			return a.Flagged
+		}
+		if a.ErrorRate != b.ErrorRate {
+			return a.ErrorRate > b.ErrorRate
+		}
+		if a.Invocations != b.Invocations {
+			return a.Invocations > b.Invocations
+		}
+		return a.Name < b.Name
+	})
+	return rows
+}
+
+// formatErrorRate formats a 0-1 rate as a percentage.
+func formatErrorRate(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'f', 1, 64) + "%"
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestBuildFunctionMetricsRows(t *testing.T) {
+	metrics := map[string]supabase.FunctionMetrics{
+		"send-email": {Name: "send-email", Invocations: 200, Errors: 4, P95: 120 * time.Millisecond},
+		"webhook":    {Name: "webhook", Invocations: 20, Errors: 5, P95: 2 * time.Second},
+		"search":     {Name: "search", Invocations: 500},
+		"api":        {Name: "api", Invocations: 50},
+	}
+	rows := buildFunctionMetricsRows(metrics, []string{"api", "old-cron", "search"}, 0.05)
+
+	var names []string
+	for _, row := range rows {
+		names = append(names, row.Name)
+	}
+	want := []string{"webhook", "send-email", "search", "api", "old-cron"}
+	if len(names) != len(want) {
+		t.Fatalf("rows = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("rows = %v, want %v", names, want)
+		}
+	}
+
+	if !rows[0].Flagged || rows[0].ErrorRate != 0.25 || rows[0].P95Ms != 2000 {
+		t.Errorf("webhook = %+v, want flagged at 25%%", rows[0])
+	}
+	if rows[1].Flagged {
+		t.Errorf("send-email at 2%% should not be flagged: %+v", rows[1])
+	}
+	if rows[4].Invocations != 0 || rows[4].Flagged {
+		t.Errorf("old-cron = %+v, want an idle deployed function", rows[4])
+	}
+}
+
+func TestFormatErrorRate(t *testing.T) {
+	if got := formatErrorRate(0.0234); got != "2.3%" {
+		t.Errorf("formatErrorRate(0.0234) = %q, want 2.3%%", got)
+	}
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var functionsRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a function locally and on the target branch",
+	Long: `Rename an Edge Function without leaving its old deployment behind.
+
+Steps:
+  1. Rename the function directory
+  2. Rewrite relative imports of the old directory in every function root,
+     including _shared, and its [functions.<name>] section in
+     supabase/config.toml
+  3. Deploy the function under the new name and check that it is ACTIVE
+  4. With --delete-old, delete the old deployed function
+  5. Record old → new in the branch's deploy manifest
+
+Settings in .drift.yaml that name the old function (overrides, restricted)
+are reported, not rewritten.`,
+	Example: `  drift functions rename send-mail send-email
+  drift functions rename send-mail send-email --delete-old
+  drift functions rename send-mail send-email --no-deploy   # Local changes only
+  drift functions rename old new -b dev --delete-old -y`,
+	Args: cobra.ExactArgs(2),
+	RunE: Run(runFunctionsRename, RequireProject),
+}
+
+var (
+	functionsRenameNoDeploy  bool
+	functionsRenameDeleteOld bool
+)
+
+func init() {
+	functionsRenameCmd.Flags().StringVarP(&functionsBranchFlag, "branch", "b", "", "Target Supabase branch")
+	functionsRenameCmd.Flags().BoolVar(&functionsRenameNoDeploy, "no-deploy", false, "Only rename locally")
+	functionsRenameCmd.Flags().BoolVar(&functionsRenameDeleteOld, "delete-old", false, "Delete the old deployed function once the new one is active")
+
+	functionsCmd.AddCommand(functionsRenameCmd)
+}
+
+func runFunctionsRename(ctx *Context) error {
+	cfg := ctx.Config()
+	oldName, newName := ctx.Arg(0), ctx.Arg(1)
+
+	if strings.ContainsAny(newName, " /\\") || strings.HasPrefix(newName, "_") {
+		return errs.Validationf("invalid function name %q: no spaces or slashes, and no leading underscore", newName)
+	}
+	if oldName == newName {
+		return errs.Validationf("old and new names are the same")
+	}
+	if functionsRenameDeleteOld && functionsRenameNoDeploy {
+		return errs.Validationf("--delete-old needs the new function deployed; drop --no-deploy")
+	}
+
+	functions, err := listLocalFunctions(cfg)
+	if err != nil {
+		return err
+	}
+	var fn *supabase.Function
+	for i := range functions {
+		switch functions[i].Name {
+		case oldName:
+			fn = &functions[i]
+		case newName:
+			return errs.Validationf("function %s already exists at %s", newName, functions[i].Path)
+		}
+	}
+	if fn == nil {
+		return errs.Validationf("function %s not found in %s", oldName, strings.Join(functionRootPaths(cfg), ", "))
+	}
+
+	ui.Header("Rename Function")
+	ui.KeyValue("From", ui.Cyan(oldName))
+	ui.KeyValue("To", ui.Cyan(newName))
+
+	// Resolve and confirm the target before touching any files, so a
+	// cancelled deploy leaves the tree as it was.
+	var info *supabase.BranchInfo
+	if !functionsRenameNoDeploy {
+		info, err = ctx.Target(functionsBranchFlag)
+		if err != nil {
+			return err
+		}
+		ui.KeyValue("Environment", envColorString(string(info.Environment)))
+		ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+		if cfg.IsFunctionRestricted(newName, string(info.Environment)) {
+			return errs.Validationf("%s is restricted in %s; deploy it elsewhere or use --no-deploy", newName, info.Environment)
+		}
+		confirmed, err := ConfirmDeploymentOperation(info, cfg, fmt.Sprintf("deploy %s (renamed from %s)", newName, oldName))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return errs.Cancelled("functions rename")
+		}
+	}
+	ui.NewLine()
+
+	if err := renameFunctionLocally(cfg, fn, newName); err != nil {
+		return err
+	}
+	warnFunctionConfigReferences(cfg, oldName)
+
+	if functionsRenameNoDeploy {
+		ui.NewLine()
+		ui.SubHeader("Next Steps")
+		ui.List("drift deploy functions - Deploy " + newName)
+		ui.List(fmt.Sprintf("drift functions delete %s - Remove the old deployment", oldName))
+		return nil
+	}
+
+	ui.NewLine()
+	return deployRenamedFunction(ctx, info, oldName, newName)
+}
+
+// renameFunctionLocally moves fn's directory to newName and rewrites what
+// refers to it.
+func renameFunctionLocally(cfg *config.Config, fn *supabase.Function, newName string) error {
+	newPath := filepath.Join(filepath.Dir(fn.Path), newName)
+	if _, err := os.Stat(newPath); err == nil {
+		return errs.Validationf("%s already exists", newPath)
+	}
+	if err := os.Rename(fn.Path, newPath); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", fn.Path, err)
+	}
+	ui.Successf("Renamed %s", relPath(cfg, newPath))
+
+	for _, root := range functionRootPaths(cfg) {
+		changed, err := rewriteFunctionImports(root, fn.Name, newName)
+		if err != nil {
+			return err
+		}
+		for _, path := range changed {
+			ui.Successf("Updated imports in %s", relPath(cfg, path))
+		}
+	}
+
+	tomlPath := filepath.Join(cfg.ProjectRoot(), "supabase", "config.toml")
+	renamed, err := renameFunctionConfigSection(tomlPath, fn.Name, newName)
+	if err != nil {
+		return err
+	}
+	if renamed {
+		ui.Successf("Renamed [functions.%s] in %s", fn.Name, relPath(cfg, tomlPath))
+	}
+	return nil
+}
+
+// functionImportPattern matches a relative import path whose directory
+// segment is the function name, capturing what comes before and after it.
+func functionImportPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(["'](?:\.\.?/)+(?:[\w.-]+/)*)` + regexp.QuoteMeta(name) + `(["'/])`)
+}
+
+// rewriteFunctionImports rewrites relative imports of oldName to newName in
+// the source files under root and returns the files it changed.
+func rewriteFunctionImports(root, oldName, newName string) ([]string, error) {
+	pattern := functionImportPattern(oldName)
+	var changed []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "node_modules" || (strings.HasPrefix(d.Name(), ".") && path != root) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".ts", ".tsx", ".js", ".jsx", ".mjs", ".mts", ".json":
+		default:
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		updated := pattern.ReplaceAll(data, []byte("${1}"+newName+"${2}"))
+		if string(updated) == string(data) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, updated, info.Mode().Perm()); err != nil {
+			return err
+		}
+		changed = append(changed, path)
+		return nil
+	})
+	return changed, err
+}
+
+// renameFunctionConfigSection renames [functions.oldName] (and its nested
+// tables) in supabase/config.toml. It reports false when there is nothing to
+// rename.
+func renameFunctionConfigSection(path, oldName, newName string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	header := regexp.MustCompile(`(?m)^(\s*\[\s*functions\.)(?:` + regexp.QuoteMeta(oldName) + `|"` + regexp.QuoteMeta(oldName) + `")(\s*[\].])`)
+	updated := header.ReplaceAll(data, []byte("${1}"+newName+"${2}"))
+	if string(updated) == string(data) {
+		return false, nil
+	}
+	return true, os.WriteFile(path, updated, 0644)
+}
+
+// warnFunctionConfigReferences points out .drift.yaml settings that still
+// name the old function.
+func warnFunctionConfigReferences(cfg *config.Config, oldName string) {
+	if _, ok := cfg.Supabase.Functions.Overrides[oldName]; ok {
+		ui.Warningf("Rename supabase.functions.overrides.%s in .drift.yaml", oldName)
+	}
+	for _, r := range cfg.Supabase.Functions.Restricted {
+		if r.Name == oldName {
+			ui.Warningf("Rename %s in supabase.functions.restricted in .drift.yaml", oldName)
+			break
+		}
+	}
+}
+
+// deployRenamedFunction deploys newName to info, checks it is active,
+// optionally deletes oldName, and records the alias.
+func deployRenamedFunction(ctx *Context, info *supabase.BranchInfo, oldName, newName string) error {
+	cfg := ctx.Config()
+	layout, err := loadFunctionsLayout(cfg, "")
+	if err != nil {
+		return err
+	}
+	client := ctx.Client()
+	start := time.Now()
+
+	sp := ui.NewSpinner(fmt.Sprintf("Deploying %s", newName))
+	sp.Start()
+	opts := functionDeployOptions(cfg, layout, newName)
+	if err := client.DeployFunctionWithOptions(newName, info.ProjectRef, opts); err != nil {
+		sp.Fail(fmt.Sprintf("Failed to deploy %s", newName))
+		notifyOperation(cfg, "functions rename", string(info.Environment), info.SupabaseBranch.Name, start, err)
+		return err
+	}
+	sp.Success(fmt.Sprintf("Deployed %s%s", newName, describeDeployOptions(opts)))
+
+	sp = ui.NewSpinner(fmt.Sprintf("Verifying %s", newName))
+	sp.Start()
+	if err := client.VerifyFunctionDeployed(newName, info.ProjectRef); err != nil {
+		sp.Fail(fmt.Sprintf("Could not verify %s", newName))
+		ui.Warningf("Kept %s deployed; delete it with 'drift functions delete %s' once %s works", oldName, oldName, newName)
+		notifyOperation(cfg, "functions rename", string(info.Environment), info.SupabaseBranch.Name, start, err)
+		return err
+	}
+	sp.Success(fmt.Sprintf("%s is active", newName))
+
+	if functionsRenameDeleteOld {
+		sp = ui.NewSpinner(fmt.Sprintf("Deleting %s", oldName))
+		sp.Start()
+		if err := client.DeleteFunction(oldName, info.ProjectRef); err != nil {
+			sp.Fail(fmt.Sprintf("Failed to delete %s", oldName))
+			notifyOperation(cfg, "functions rename", string(info.Environment), info.SupabaseBranch.Name, start, err)
+			return err
+		}
+		sp.Success(fmt.Sprintf("Deleted %s", oldName))
+	}
+	notifyOperation(cfg, "functions rename", string(info.Environment), info.SupabaseBranch.Name, start, nil)
+
+	if err := recordFunctionAlias(cfg, info.SupabaseBranch.Name, oldName, newName); err != nil {
+		ui.Warningf("Could not record the rename in the deploy manifest: %v", err)
+	}
+
+	ui.NewLine()
+	ui.Successf("Renamed %s to %s on %s", oldName, newName, info.SupabaseBranch.Name)
+	if !functionsRenameDeleteOld {
+		ui.NewLine()
+		ui.SubHeader("Next Steps")
+		ui.List(fmt.Sprintf("drift functions delete %s -b %s - Remove the old deployment once callers use %s", oldName, info.SupabaseBranch.Name, newName))
+	}
+	return nil
+}
+
+// recordFunctionAlias adds oldName → newName to the aliases in the deploy
+// manifest of supabaseBranch. Aliases that pointed at oldName now point at
+// newName.
+func recordFunctionAlias(cfg *config.Config, supabaseBranch, oldName, newName string) error {
+	path := deployManifestPath(cfg, supabaseBranch)
+	var manifest deployManifest
+	if _, err := state.ReadJSON(path, &manifest); err != nil {
+		return err
+	}
+	if manifest.Aliases == nil {
+		manifest.Aliases = make(map[string]string)
+	}
+	for from, to := range manifest.Aliases {
+		if to == oldName {
+			manifest.Aliases[from] = newName
+		}
+	}
+	delete(manifest.Aliases, newName)
+	manifest.Aliases[oldName] = newName
+	if manifest.Items == nil {
+		manifest.Items = []planItem{}
+	}
+	return state.WriteJSON(cfg.ProjectRoot(), path, manifest)
+}
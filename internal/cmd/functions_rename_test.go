@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/state"
+)
+
+func TestRenameFunctionLocally(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"supabase/functions/send-mail/index.ts":  "import { render } from \"./render.ts\";\n",
+		"supabase/functions/send-mail/render.ts": "export const render = 1;\n",
+		"supabase/functions/_shared/mail.ts":     "export { render } from '../send-mail/render.ts';\n",
+		"supabase/functions/notify/index.ts":     "import \"../send-mail/index.ts\";\nimport \"../send-mail-v2/index.ts\";\nconst url = \"/functions/v1/send-mail\";\n",
+		"supabase/config.toml":                   "[functions.send-mail]\nverify_jwt = false\n\n[functions.send-mail-v2]\nverify_jwt = true\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configPath := filepath.Join(dir, ".drift.yaml")
+	if err := os.WriteFile(configPath, []byte("project:\n  name: test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	functions, err := listLocalFunctions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range functions {
+		if functions[i].Name == "send-mail" {
+			if err := renameFunctionLocally(cfg, &functions[i], "send-email"); err != nil {
+				t.Fatalf("renameFunctionLocally() error = %v", err)
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "supabase/functions/send-email/render.ts")); err != nil {
+		t.Errorf("renamed directory missing: %v", err)
+	}
+	want := map[string]string{
+		"supabase/functions/_shared/mail.ts": "export { render } from '../send-email/render.ts';\n",
+		// Only relative imports of the directory change, not other strings
+		// or functions sharing the prefix.
+		"supabase/functions/notify/index.ts": "import \"../send-email/index.ts\";\nimport \"../send-mail-v2/index.ts\";\nconst url = \"/functions/v1/send-mail\";\n",
+		"supabase/config.toml":               "[functions.send-email]\nverify_jwt = false\n\n[functions.send-mail-v2]\nverify_jwt = true\n",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("%s =\n%s\nwant\n%s", name, data, content)
+		}
+	}
+}
+
+func TestRecordFunctionAlias(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".drift.yaml")
+	if err := os.WriteFile(configPath, []byte("project:\n  name: test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFromPath(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recordFunctionAlias(cfg, "dev", "mail", "send-mail"); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordFunctionAlias(cfg, "dev", "send-mail", "send-email"); err != nil {
+		t.Fatal(err)
+	}
+	// A later deploy keeps the aliases.
+	plan := &deployPlan{Target: envState{SupabaseBranch: "dev"}}
+	plan.add(planFunction, "send-email", planChange, "")
+	if err := saveDeployManifest(cfg, plan, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	var manifest deployManifest
+	if _, err := state.ReadJSON(deployManifestPath(cfg, "dev"), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Aliases["mail"] != "send-email" || manifest.Aliases["send-mail"] != "send-email" || len(manifest.Aliases) != 2 {
+		t.Errorf("Aliases = %v, want mail and send-mail pointing at send-email", manifest.Aliases)
+	}
+	if len(manifest.Items) != 1 {
+		t.Errorf("Items = %+v, want the applied plan", manifest.Items)
+	}
+}
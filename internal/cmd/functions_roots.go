@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/supabase"
+)
+
+// functionsLayout is the project's functions across every functions root,
+// plus the generated files the Supabase CLI needs to find them.
+type functionsLayout struct {
+	Functions []supabase.Function
+	// ImportMap is the merged import map, empty when no root has one.
+	ImportMap string
+	// Workdir is a staged CLI workdir, empty when functions_dir is the only
+	// root.
+	Workdir string
+}
+
+// listLocalFunctions lists the functions in every configured root, sorted
+// by name. A name found in two roots is an error, since both would deploy
+// to the same function.
+func listLocalFunctions(cfg *config.Config) ([]supabase.Function, error) {
+	var all []supabase.Function
+	seen := make(map[string]string)
+	for _, root := range cfg.GetFunctionRoots() {
+		functions, err := supabase.ListFunctions(root.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, fn := range functions {
+			if other, ok := seen[fn.Name]; ok {
+				return nil, fmt.Errorf("function '%s' exists in both %s and %s", fn.Name, other, root.Path)
+			}
+			seen[fn.Name] = root.Path
+			all = append(all, fn)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all, nil
+}
+
+// loadFunctionsLayout lists local functions and writes what deploy and serve
+// need for them under .drift/functions: the root import maps merged into
+// one file, and, when functions live outside functions_dir, a workdir whose
+// config.toml points the CLI at each function. importMap, from --import-map,
+// replaces the merged map when set.
+func loadFunctionsLayout(cfg *config.Config, importMap string) (*functionsLayout, error) {
+	functions, err := listLocalFunctions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	layout := &functionsLayout{Functions: functions}
+	root := cfg.ProjectRoot()
+	roots := cfg.GetFunctionRoots()
+
+	var maps []supabase.ScopedImportMap
+	for i, r := range roots {
+		if r.ImportMap == "" {
+			continue
+		}
+		scope := r.Path
+		if i == 0 {
+			scope = "" // functions_dir's map also covers shared code elsewhere
+		}
+		maps = append(maps, supabase.ScopedImportMap{Root: scope, Path: r.ImportMap})
+	}
+
+	if importMap != "" {
+		if layout.ImportMap, err = filepath.Abs(importMap); err != nil {
+			return nil, err
+		}
+	} else if len(maps) > 0 {
+		merged, err := supabase.MergeImportMaps(maps)
+		if err != nil {
+			return nil, err
+		}
+		if err := state.Ensure(root); err != nil {
+			return nil, err
+		}
+		layout.ImportMap = state.Path(root, state.Functions, "import_map.json")
+		if err := supabase.WriteImportMap(layout.ImportMap, merged); err != nil {
+			return nil, fmt.Errorf("failed to write merged import map: %w", err)
+		}
+	}
+
+	if len(roots) == 1 {
+		return layout, nil
+	}
+
+	entries := make([]supabase.FunctionEntry, len(functions))
+	for i, fn := range functions {
+		entries[i] = supabase.FunctionEntry{
+			Name:       fn.Name,
+			Entrypoint: filepath.Join(fn.Path, "index.ts"),
+			ImportMap:  functionImportMap(cfg, layout, fn.Name),
+		}
+	}
+	if err := state.Ensure(root); err != nil {
+		return nil, err
+	}
+	layout.Workdir = state.Path(root, state.Functions, "workdir")
+	projectConfig := filepath.Join(root, "supabase", "config.toml")
+	if err := supabase.StageWorkdir(projectConfig, layout.Workdir, entries); err != nil {
+		return nil, fmt.Errorf("failed to stage functions workdir: %w", err)
+	}
+	return layout, nil
+}
+
+// functionImportMap returns the import map for one function: its
+// supabase.functions.overrides entry, else the layout's map.
+func functionImportMap(cfg *config.Config, layout *functionsLayout, name string) string {
+	if override := cfg.GetFunctionOverride(name).ImportMap; override != "" {
+		if filepath.IsAbs(override) {
+			return override
+		}
+		return filepath.Join(cfg.ProjectRoot(), override)
+	}
+	if layout != nil {
+		return layout.ImportMap
+	}
+	return ""
+}
+
+// functionRootPaths returns the path of every functions root.
+func functionRootPaths(cfg *config.Config) []string {
+	roots := cfg.GetFunctionRoots()
+	paths := make([]string, len(roots))
+	for i, r := range roots {
+		paths[i] = r.Path
+	}
+	return paths
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+)
+
+func TestListLocalFunctions_Roots(t *testing.T) {
+	dir := t.TempDir()
+	for _, fn := range []string{"supabase/functions/send-email", "packages/edge/billing", "packages/edge/_shared"} {
+		if err := os.MkdirAll(filepath.Join(dir, fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fn, "index.ts"), []byte("export {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configPath := filepath.Join(dir, ".drift.yaml")
+	if err := os.WriteFile(configPath, []byte("supabase:\n  functions:\n    roots:\n      - path: packages/edge\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	functions, err := listLocalFunctions(cfg)
+	if err != nil {
+		t.Fatalf("listLocalFunctions() error = %v", err)
+	}
+	if len(functions) != 2 || functions[0].Name != "billing" || functions[1].Name != "send-email" {
+		t.Fatalf("listLocalFunctions() = %+v, want billing and send-email", functions)
+	}
+	if functions[0].Root != filepath.Join(dir, "packages/edge") {
+		t.Errorf("billing Root = %q", functions[0].Root)
+	}
+
+	// The same name in two roots would deploy to one function.
+	if err := os.MkdirAll(filepath.Join(dir, "packages/edge/send-email"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "packages/edge/send-email/index.ts"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listLocalFunctions(cfg); err == nil || !strings.Contains(err.Error(), "send-email") {
+		t.Errorf("listLocalFunctions() error = %v, want duplicate send-email", err)
+	}
+}
@@ -0,0 +1,415 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var functionsServingCmd = &cobra.Command{
+	Use:   "serving",
+	Short: "Show which functions 'drift functions serve' is serving",
+	Long: `Show the functions a running 'drift functions serve' is serving in this
+project, with their local routes, how often the server has restarted, and
+the debugger address when --inspect is on.
+
+The same summary is available from 'drift serve' at /serving.`,
+	Example: `  drift functions serving
+  drift functions serving --json | jq -r '.functions[].route'`,
+	Args: cobra.NoArgs,
+	RunE: Run(runFunctionsServing, RequireProject),
+}
+
+var (
+	functionsInspect     string
+	functionsInspectMode string
+	functionsNoRestart   bool
+	functionsServingJSON bool
+)
+
+func init() {
+	functionsServeCmd.Flags().StringVar(&functionsInspect, "inspect", "", "Serve only this function with the Deno debugger enabled")
+	functionsServeCmd.Flags().StringVar(&functionsInspectMode, "inspect-mode", "brk", "With --inspect: run, brk (pause on first line), or wait (for a debugger)")
+	functionsServeCmd.Flags().BoolVar(&functionsNoRestart, "no-restart", false, "Exit instead of restarting when the server crashes")
+	functionsServingCmd.Flags().BoolVar(&functionsServingJSON, "json", false, "Output as JSON")
+
+	functionsCmd.AddCommand(functionsServingCmd)
+}
+
+const (
+	// localFunctionsURL is the local API's default address, used when
+	// 'supabase status' cannot tell us.
+	localFunctionsURL = "http://127.0.0.1:54321"
+	// functionsInspectorAddr is where the edge runtime listens for a
+	// debugger.
+	functionsInspectorAddr = "127.0.0.1:8083"
+
+	// A server that crashes more than serveMaxCrashes times within
+	// serveCrashWindow is not restarted again.
+	serveMaxCrashes  = 5
+	serveCrashWindow = time.Minute
+)
+
+// functionsServeStatus is what a running 'drift functions serve' reports in
+// .drift/functions/serve.json.
+type functionsServeStatus struct {
+	PID       int               `json:"pid"`
+	StartedAt time.Time         `json:"started_at"`
+	Restarts  int               `json:"restarts"`
+	LastCrash *time.Time        `json:"last_crash,omitempty"`
+	BaseURL   string            `json:"base_url"`
+	Functions []servedFunction  `json:"functions"`
+	Inspect   *servedInspection `json:"inspect,omitempty"`
+}
+
+type servedFunction struct {
+	Name  string `json:"name"`
+	Route string `json:"route"`
+}
+
+type servedInspection struct {
+	Function string `json:"function"`
+	Mode     string `json:"mode"`
+	Address  string `json:"address"`
+}
+
+// newFunctionsServeStatus describes serving names from baseURL.
+func newFunctionsServeStatus(baseURL string, names []string) *functionsServeStatus {
+	status := &functionsServeStatus{
+		PID:       os.Getpid(),
+		StartedAt: time.Now(),
+		BaseURL:   baseURL,
+	}
+	for _, name := range names {
+		status.Functions = append(status.Functions, servedFunction{
+			Name:  name,
+			Route: strings.TrimSuffix(baseURL, "/") + "/functions/v1/" + name,
+		})
+	}
+	return status
+}
+
+func functionsServeStatusPath(cfg *config.Config) string {
+	return state.Path(cfg.ProjectRoot(), state.Functions, "serve.json")
+}
+
+// loadFunctionsServeStatus returns the status of the server running for
+// cfg's project, or nil when none is. A status left by a server that did
+// not exit cleanly is ignored.
+func loadFunctionsServeStatus(cfg *config.Config) (*functionsServeStatus, error) {
+	var status functionsServeStatus
+	ok, err := state.ReadJSON(functionsServeStatusPath(cfg), &status)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if !processAlive(status.PID) {
+		return nil, nil
+	}
+	return &status, nil
+}
+
+// processAlive reports whether a process with pid exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// serveLogRouter prefixes each line of the function server's output with
+// the function it came from. The edge runtime runs every function in one
+// process, so attribution is best effort: a line naming a function's path
+// or route is that function's, and lines without one (console output,
+// stack traces) go to the function named most recently.
+type serveLogRouter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	colors  map[string]func(a ...interface{}) string
+	width   int
+	current string
+}
+
+var serveLogColors = []func(a ...interface{}) string{ui.Cyan, ui.Magenta, ui.Green, ui.Yellow, ui.Blue}
+
+// serveFunctionPath matches functions/<name> and functions/v1/<name>.
+var serveFunctionPath = regexp.MustCompile(`functions/(?:v1/)?([A-Za-z0-9_-]+)`)
+
+func newServeLogRouter(out io.Writer, names []string) *serveLogRouter {
+	r := &serveLogRouter{out: out, colors: make(map[string]func(a ...interface{}) string), width: len("serve")}
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	for i, name := range sorted {
+		r.colors[name] = serveLogColors[i%len(serveLogColors)]
+		if len(name) > r.width {
+			r.width = len(name)
+		}
+	}
+	return r
+}
+
+// attribute returns the function line belongs to, or "" for server output.
+func (r *serveLogRouter) attribute(line string) string {
+	for _, m := range serveFunctionPath.FindAllStringSubmatch(line, -1) {
+		if _, ok := r.colors[m[1]]; ok {
+			r.current = m[1]
+			return m[1]
+		}
+	}
+	if strings.TrimSpace(line) == "" {
+		return ""
+	}
+	return r.current
+}
+
+func (r *serveLogRouter) writeLine(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	line = strings.TrimRight(line, "\r")
+	name := r.attribute(line)
+	prefix := ui.Dim(fmt.Sprintf("%-*s |", r.width, "serve"))
+	if name != "" {
+		prefix = r.colors[name](fmt.Sprintf("%-*s |", r.width, name))
+	}
+	fmt.Fprintf(r.out, "%s %s\n", prefix, line)
+}
+
+// stream returns a writer for one of the server's output streams.
+func (r *serveLogRouter) stream() *serveLogStream {
+	return &serveLogStream{router: r}
+}
+
+// serveLogStream splits a stream into lines for its router.
+type serveLogStream struct {
+	router *serveLogRouter
+	buf    []byte
+}
+
+func (s *serveLogStream) Write(p []byte) (int, error) {
+	s.buf = append(s.buf, p...)
+	for {
+		i := bytes.IndexByte(s.buf, '\n')
+		if i < 0 {
+			break
+		}
+		s.router.writeLine(string(s.buf[:i]))
+		s.buf = s.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush writes a trailing line that has no newline.
+func (s *serveLogStream) flush() {
+	if len(s.buf) > 0 {
+		s.router.writeLine(string(s.buf))
+		s.buf = nil
+	}
+}
+
+// serveSupervisor keeps the function server running, restarting it when it
+// crashes until it crashes too often.
+type serveSupervisor struct {
+	// start launches a new server process.
+	start func() (*exec.Cmd, error)
+	// restart is false for --no-restart.
+	restart bool
+	// backoff is the wait after the first crash, growing with each crash
+	// in the window.
+	backoff time.Duration
+	// exited runs after each server process exits and its output is
+	// copied.
+	exited func()
+	// onCrash is told about each crash that will be restarted.
+	onCrash func(err error, restarts int)
+}
+
+// run serves until the server exits cleanly, stop receives a signal (which
+// is passed on to the server), or the server crashes and is not restarted.
+func (s *serveSupervisor) run(stop <-chan os.Signal) error {
+	var crashes []time.Time
+	restarts := 0
+	for {
+		cmd, err := s.start()
+		if err != nil {
+			return err
+		}
+		done := make(chan error, 1)
+		go func() {
+			err := cmd.Wait()
+			if s.exited != nil {
+				s.exited()
+			}
+			done <- err
+		}()
+
+		select {
+		case sig := <-stop:
+			_ = cmd.Process.Signal(sig)
+			<-done
+			return nil
+		case err = <-done:
+		}
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-stop:
+			return nil // the server exited because of the same Ctrl+C
+		default:
+		}
+
+		now := time.Now()
+		recent := crashes[:0]
+		for _, t := range crashes {
+			if now.Sub(t) < serveCrashWindow {
+				recent = append(recent, t)
+			}
+		}
+		crashes = append(recent, now)
+		if !s.restart {
+			return fmt.Errorf("function server exited: %w", err)
+		}
+		if len(crashes) > serveMaxCrashes {
+			return fmt.Errorf("function server crashed %d times in %s, giving up: %w", len(crashes), serveCrashWindow, err)
+		}
+
+		restarts++
+		if s.onCrash != nil {
+			s.onCrash(err, restarts)
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(s.backoff * time.Duration(len(crashes))):
+		}
+	}
+}
+
+// serveFunctionsSupervised runs the Supabase CLI's function server with
+// prefixed logs, restarting it on crashes, and records what it serves in
+// .drift/functions/serve.json while it runs.
+func serveFunctionsSupervised(cfg *config.Config, name string, opts supabase.ServeOptions, names []string) error {
+	baseURL := localFunctionsURL
+	if stack, err := supabase.NewClient().LocalStatus(); err == nil && stack.APIURL != "" {
+		baseURL = stack.APIURL
+	}
+
+	status := newFunctionsServeStatus(baseURL, names)
+	if opts.InspectMode != "" {
+		status.Inspect = &servedInspection{Function: name, Mode: opts.InspectMode, Address: functionsInspectorAddr}
+	}
+	statusPath := functionsServeStatusPath(cfg)
+	root := cfg.ProjectRoot()
+	if err := state.WriteJSON(root, statusPath, status); err != nil {
+		ui.Warning(fmt.Sprintf("Could not record serve status: %v", err))
+	}
+	defer os.Remove(statusPath)
+
+	for _, fn := range status.Functions {
+		ui.List(fmt.Sprintf("%s  %s", fn.Name, ui.Dim(fn.Route)))
+	}
+	if status.Inspect != nil {
+		ui.KeyValue("Debugger", ui.Cyan(fmt.Sprintf("ws://%s (open chrome://inspect)", status.Inspect.Address)))
+	}
+	ui.NewLine()
+
+	router := newServeLogRouter(os.Stdout, names)
+	args := supabase.ServeArgs(name, opts)
+	var stdout, stderr *serveLogStream
+	supervisor := &serveSupervisor{
+		restart: !functionsNoRestart,
+		backoff: time.Second,
+		start: func() (*exec.Cmd, error) {
+			stdout, stderr = router.stream(), router.stream()
+			cmd := exec.Command("supabase", args...)
+			cmd.Stdin = os.Stdin
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+			if err := cmd.Start(); err != nil {
+				return nil, fmt.Errorf("failed to start function server: %w", err)
+			}
+			return cmd, nil
+		},
+		exited: func() {
+			stdout.flush()
+			stderr.flush()
+		},
+		onCrash: func(err error, restarts int) {
+			ui.Warningf("Function server crashed (%v), restarting...", err)
+			now := time.Now()
+			status.Restarts = restarts
+			status.LastCrash = &now
+			if err := state.WriteJSON(root, statusPath, status); err != nil {
+				ui.Warning(fmt.Sprintf("Could not record serve status: %v", err))
+			}
+		},
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	return supervisor.run(stop)
+}
+
+func runFunctionsServing(ctx *Context) error {
+	status, err := loadFunctionsServeStatus(ctx.Config())
+	if err != nil {
+		return err
+	}
+
+	if ctx.JSON() {
+		if status == nil {
+			return ctx.PrintJSON(map[string]bool{"serving": false})
+		}
+		return ctx.PrintJSON(status)
+	}
+
+	if status == nil {
+		ui.Info("No 'drift functions serve' running in this project")
+		return nil
+	}
+
+	ui.Header("Serving Edge Functions")
+	ui.KeyValue("PID", fmt.Sprintf("%d", status.PID))
+	ui.KeyValue("Running For", time.Since(status.StartedAt).Round(time.Second).String())
+	if status.Restarts > 0 {
+		ui.KeyValue("Restarts", ui.Yellow(fmt.Sprintf("%d (last crash %s ago)", status.Restarts, time.Since(*status.LastCrash).Round(time.Second))))
+	} else {
+		ui.KeyValue("Restarts", "0")
+	}
+	if status.Inspect != nil {
+		ui.KeyValue("Debugger", ui.Cyan(fmt.Sprintf("ws://%s (%s, %s)", status.Inspect.Address, status.Inspect.Function, status.Inspect.Mode)))
+	}
+	ui.NewLine()
+	for _, fn := range status.Functions {
+		ui.List(fmt.Sprintf("%s  %s", ui.Cyan(fn.Name), fn.Route))
+	}
+	return nil
+}
+
+// validInspectMode reports whether mode is an edge runtime inspect mode.
+func validInspectMode(mode string) bool {
+	switch mode {
+	case "run", "brk", "wait":
+		return true
+	}
+	return false
+}
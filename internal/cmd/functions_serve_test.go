@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/state"
+)
+
+func TestServeLogRouter(t *testing.T) {
+	var out bytes.Buffer
+	router := newServeLogRouter(&out, []string{"send-email", "billing"})
+	stream := router.stream()
+
+	fmt.Fprint(stream, "Serving functions on http://127.0.0.1:54321/functions/v1/<function-name>\n")
+	fmt.Fprint(stream, "serving the request with supabase/functions/billing\n")
+	fmt.Fprint(stream, "[Info] charge created\n[Error] card declined")
+	stream.flush()
+	fmt.Fprint(stream, "POST /functions/v1/send-email 200\n")
+
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		"serve      | Serving functions on http://127.0.0.1:54321/functions/v1/<function-name>",
+		"billing    | serving the request with supabase/functions/billing",
+		"billing    | [Info] charge created",
+		"billing    | [Error] card declined",
+		"send-email | POST /functions/v1/send-email 200",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("router output:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// crashingServer returns a start func whose process fails the first
+// failures times and then exits cleanly.
+func crashingServer(t *testing.T, failures int) (func() (*exec.Cmd, error), func() int) {
+	counter := filepath.Join(t.TempDir(), "starts")
+	script := fmt.Sprintf(`n=$(cat %[1]s 2>/dev/null || echo 0); n=$((n+1)); echo $n > %[1]s; [ $n -gt %[2]d ]`, counter, failures)
+	start := func() (*exec.Cmd, error) {
+		cmd := exec.Command("sh", "-c", script)
+		return cmd, cmd.Start()
+	}
+	starts := func() int {
+		data, _ := os.ReadFile(counter)
+		var n int
+		fmt.Sscanf(string(data), "%d", &n)
+		return n
+	}
+	return start, starts
+}
+
+func TestServeSupervisor(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		restart    bool
+		wantErr    string
+		wantStarts int
+	}{
+		{name: "clean exit", failures: 0, restart: true, wantStarts: 1},
+		{name: "restarts after crashes", failures: 2, restart: true, wantStarts: 3},
+		{name: "no restart", failures: 1, restart: false, wantErr: "exited", wantStarts: 1},
+		{name: "gives up", failures: 10, restart: true, wantErr: "giving up", wantStarts: serveMaxCrashes + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, starts := crashingServer(t, tt.failures)
+			restarts := 0
+			s := &serveSupervisor{
+				start:   start,
+				restart: tt.restart,
+				backoff: time.Millisecond,
+				onCrash: func(err error, n int) { restarts = n },
+			}
+			err := s.run(make(chan os.Signal))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := starts(); got != tt.wantStarts {
+				t.Errorf("server started %d times, want %d", got, tt.wantStarts)
+			}
+			if tt.wantErr == "" && restarts != tt.failures {
+				t.Errorf("restarts = %d, want %d", restarts, tt.failures)
+			}
+		})
+	}
+}
+
+func TestLoadFunctionsServeStatus(t *testing.T) {
+	root := t.TempDir()
+	cfg := loadConfigWithBackupDir(t, root, "backups")
+
+	if status, err := loadFunctionsServeStatus(cfg); status != nil || err != nil {
+		t.Fatalf("loadFunctionsServeStatus() with no server = %v, %v", status, err)
+	}
+
+	status := newFunctionsServeStatus("http://127.0.0.1:54321/", []string{"billing"})
+	if status.Functions[0].Route != "http://127.0.0.1:54321/functions/v1/billing" {
+		t.Errorf("route = %q", status.Functions[0].Route)
+	}
+	if err := state.WriteJSON(root, functionsServeStatusPath(cfg), status); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadFunctionsServeStatus(cfg)
+	if err != nil || loaded == nil || loaded.PID != os.Getpid() || len(loaded.Functions) != 1 {
+		t.Fatalf("loadFunctionsServeStatus() = %+v, %v", loaded, err)
+	}
+
+	// A status left behind by a server that is gone is ignored.
+	status.PID = 1 << 30
+	if err := state.WriteJSON(root, functionsServeStatusPath(cfg), status); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := loadFunctionsServeStatus(cfg); loaded != nil || err != nil {
+		t.Errorf("loadFunctionsServeStatus() for dead server = %+v, %v", loaded, err)
+	}
+}
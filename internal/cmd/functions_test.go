@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestFindDownloadedFunctionDir(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string // directory under tempDir holding index.ts, or "-" for none
+		want   string
+	}{
+		{"supabase layout", "supabase/functions/send-email", "supabase/functions/send-email"},
+		{"function dir", "send-email", "send-email"},
+		{"flat", ".", "."},
+		{"missing", "-", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			if tt.layout != "-" {
+				dir := filepath.Join(tempDir, tt.layout)
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "index.ts"), []byte("// fn"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want := ""
+			if tt.want != "" {
+				want = filepath.Join(tempDir, tt.want)
+			}
+			if got := findDownloadedFunctionDir(tempDir, "send-email"); got != want {
+				t.Errorf("findDownloadedFunctionDir() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestReplaceDir(t *testing.T) {
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "index.ts"), []byte("deployed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "lib", "util.ts"), []byte("util"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	functionsPath := filepath.Join(t.TempDir(), "functions")
+	dest := filepath.Join(functionsPath, "send-email")
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dest, "index.ts"), []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dest, "stale.ts"), []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replaceDir(src, dest); err != nil {
+		t.Fatalf("replaceDir() error = %v", err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dest, "index.ts")); string(data) != "deployed" {
+		t.Errorf("index.ts = %q, want %q", data, "deployed")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "lib", "util.ts")); string(data) != "util" {
+		t.Errorf("lib/util.ts = %q, want %q", data, "util")
+	}
+	if _, err := os.Stat(filepath.Join(dest, "stale.ts")); !os.IsNotExist(err) {
+		t.Error("stale.ts should have been removed")
+	}
+
+	entries, err := os.ReadDir(functionsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("functions dir has %d entries, want only the function (staging left behind?)", len(entries))
+	}
+	if !localFunctionExists(functionsPath, "send-email") {
+		t.Error("localFunctionExists() = false, want true")
+	}
+}
+
+func TestFindOrphanedFunctions(t *testing.T) {
+	local := []supabase.Function{{Name: "send-email"}, {Name: "webhook"}}
+	deployed := []supabase.DeployedFunction{{Name: "webhook"}, {Name: "old-cron"}, {Name: "legacy"}, {Name: "send-email"}}
+
+	got := findOrphanedFunctions(local, deployed)
+	want := []string{"legacy", "old-cron"}
+	if len(got) != len(want) {
+		t.Fatalf("findOrphanedFunctions() = %v, want %v", got, want)
+	}
+	for i, name := range want {
+		if got[i].Name != name {
+			t.Errorf("findOrphanedFunctions()[%d] = %q, want %q", i, got[i].Name, name)
+		}
+	}
+}
+
+func TestLastInvokedLabel(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	invoked := map[string]time.Time{"webhook": time.Now().Add(-2 * time.Hour)}
+
+	tests := []struct {
+		name        string
+		lastInvoked map[string]time.Time
+		fn          string
+		window      time.Duration
+		want        string
+	}{
+		{"logs unavailable", nil, "webhook", 24 * time.Hour, "unknown"},
+		{"invoked", invoked, "webhook", 24 * time.Hour, "2 hours ago"},
+		{"not invoked", invoked, "legacy", 24 * time.Hour, "not in last 24h"},
+		{"not invoked odd window", invoked, "legacy", 90 * time.Minute, "not in last 1h30m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastInvokedLabel(tt.lastInvoked, tt.fn, tt.window); got != tt.want {
+				t.Errorf("lastInvokedLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/guard"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+)
+
+var guardCmd = &cobra.Command{
+	Use:   "guard",
+	Short: "Keep drift-generated secrets out of git",
+	Long: `Protect the high-risk files drift generates from being committed.
+
+drift guard:
+  1. Ensures generated files are gitignored (.env.local, Config.xcconfig,
+     backups/, and supabase/seed.sql when it inserts into auth.users)
+  2. Reports whether the drift pre-commit hook is installed
+  3. Scans staged changes for Supabase keys
+
+Use 'drift guard install-hook' to run the staged scan on every commit.`,
+	Example: `  drift guard                 # Fix .gitignore and scan staged changes
+  drift guard --check         # Report only, exit non-zero on problems
+  drift guard install-hook    # Install the pre-commit hook
+  drift guard scan            # Scan staged changes (used by the hook)`,
+	Args: cobra.NoArgs,
+	RunE: runGuard,
+}
+
+var guardScanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Scan staged changes for Supabase secrets",
+	Long: `Scan staged changes for Supabase service role keys, secret API keys,
+access tokens, and database URLs with passwords. Also fails if a
+drift-generated secrets file is staged.
+
+Exits non-zero when anything is found, so it can be used as a pre-commit hook.`,
+	Args: cobra.NoArgs,
+	RunE: runGuardScan,
+}
+
+var guardInstallHookCmd = &cobra.Command{
+	Use:   "install-hook",
+	Short: "Install a pre-commit hook that runs 'drift guard scan'",
+	Args:  cobra.NoArgs,
+	RunE:  runGuardInstallHook,
+}
+
+var (
+	guardCheckFlag bool
+	guardForceFlag bool
+)
+
+func init() {
+	guardCmd.Flags().BoolVar(&guardCheckFlag, "check", false, "Only report problems; do not modify .gitignore")
+	guardInstallHookCmd.Flags().BoolVar(&guardForceFlag, "force", false, "Replace an existing pre-commit hook")
+
+	guardCmd.AddCommand(guardScanCmd)
+	guardCmd.AddCommand(guardInstallHookCmd)
+	rootCmd.AddCommand(guardCmd)
+}
+
+// guardedPaths returns project-relative paths drift generates that must be gitignored.
+// Directories end with a slash.
+func guardedPaths(cfg *config.Config) []string {
+	root := cfg.ProjectRoot()
+	paths := []string{config.LocalConfigFilename}
+
+	if cfg.Project.IsWebPlatform() {
+		paths = append(paths, cfg.Web.EnvOutput)
+		if cfg.Web.ServiceRoleKey == web.ServiceRoleServerOnly {
+			paths = append(paths, cfg.Web.ServerEnvOutput)
+		}
+	} else {
+		paths = append(paths, cfg.Xcode.XcconfigOutput)
+	}
+
+	paths = append(paths, strings.TrimSuffix(cfg.Database.BackupDir, "/")+"/")
+
+	seedPath := filepath.Join(root, "supabase", "seed.sql")
+	if guard.SeedContainsAuthUsers(seedPath) {
+		paths = append(paths, "supabase/seed.sql")
+	}
+
+	return paths
+}
+
+func runGuard(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+
+	cfg := config.LoadOrDefault()
+	root := cfg.ProjectRoot()
+	problems := 0
+
+	ui.Header("Drift Guard")
+
+	// 1. Gitignore coverage
+	ui.SubHeader("Gitignore")
+	var missing []string
+	for _, p := range guardedPaths(cfg) {
+		if git.IsIgnored(filepath.Join(root, p)) {
+			ui.Success(p)
+			continue
+		}
+		missing = append(missing, p)
+	}
+
+	if len(missing) > 0 {
+		if guardCheckFlag {
+			for _, p := range missing {
+				ui.Errorf("%s is not gitignored", p)
+			}
+			problems += len(missing)
+		} else {
+			if err := guard.AppendGitignore(root, missing); err != nil {
+				return fmt.Errorf("failed to update .gitignore: %w", err)
+			}
+			for _, p := range missing {
+				ui.Successf("%s added to .gitignore", p)
+			}
+		}
+	}
+
+	// 2. Pre-commit hook
+	ui.SubHeader("Pre-commit Hook")
+	if hooksDir, err := git.GetHooksDir(); err == nil && guard.IsHookInstalled(hooksDir) {
+		ui.Success("drift pre-commit hook installed")
+	} else {
+		ui.Warning("drift pre-commit hook not installed")
+		ui.Info("Run 'drift guard install-hook' to scan every commit")
+	}
+
+	// 3. Staged changes
+	ui.SubHeader("Staged Changes")
+	n, err := reportStagedSecrets(cfg)
+	if err != nil {
+		return err
+	}
+	problems += n
+
+	if problems > 0 {
+		return fmt.Errorf("drift guard found %d problem(s)", problems)
+	}
+	return nil
+}
+
+func runGuardScan(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+
+	cfg := config.LoadOrDefault()
+	n, err := reportStagedSecrets(cfg)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		ui.NewLine()
+		ui.Info("Unstage the affected files or remove the secrets, then commit again.")
+		return fmt.Errorf("commit blocked: %d potential secret(s) staged", n)
+	}
+	return nil
+}
+
+// reportStagedSecrets prints staged generated files and secret findings, returning the count.
+func reportStagedSecrets(cfg *config.Config) (int, error) {
+	staged, err := git.StagedFiles()
+	if err != nil {
+		return 0, err
+	}
+	if len(staged) == 0 {
+		ui.Info("No staged changes")
+		return 0, nil
+	}
+
+	count := 0
+	guarded := guardedPaths(cfg)
+	for _, file := range staged {
+		for _, p := range guarded {
+			if file == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(file, p)) {
+				ui.Errorf("%s is a drift-generated secrets file and is staged", file)
+				count++
+				break
+			}
+		}
+	}
+
+	diff, err := git.StagedDiff()
+	if err != nil {
+		return count, err
+	}
+	for _, f := range guard.ScanDiff(diff) {
+		ui.Errorf("%s:%d: %s (%s)", f.File, f.Line, f.Rule, f.Match)
+		count++
+	}
+
+	if count == 0 {
+		ui.Successf("No secrets found in %d staged file(s)", len(staged))
+	}
+	return count, nil
+}
+
+func runGuardInstallHook(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+
+	hooksDir, err := git.GetHooksDir()
+	if err != nil {
+		return err
+	}
+
+	hookPath, err := guard.InstallPreCommitHook(hooksDir, guardForceFlag)
+	if err != nil {
+		return err
+	}
+
+	ui.Successf("Installed pre-commit hook: %s", hookPath)
+	ui.Info("Staged changes will be scanned for Supabase secrets on every commit")
+	return nil
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/guard"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage drift's git hooks",
+	Long: `Show which drift git hooks are installed.
+
+'drift hooks install' adds post-checkout and post-merge hooks that run
+'drift env check --quiet' after every branch switch and pull, so a generated
+env file pointing at another branch's Supabase project is caught right away.
+Set preferences.env_refresh: regenerate in .drift.local.yaml to have the
+hooks regenerate the file instead of warning.
+
+The pre-commit secret scan is installed separately with
+'drift guard install-hook'.`,
+	Example: `  drift hooks
+  drift hooks install
+  drift hooks install --force   # Replace existing non-drift hooks
+  drift hooks uninstall`,
+	Args: cobra.NoArgs,
+	RunE: Run(runHooksStatus, RequireProject),
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install post-checkout and post-merge hooks that check the env file",
+	Args:  cobra.NoArgs,
+	RunE:  Run(runHooksInstall, RequireProject),
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the hooks installed by 'drift hooks install'",
+	Args:  cobra.NoArgs,
+	RunE:  Run(runHooksUninstall, RequireProject),
+}
+
+var hooksForceFlag bool
+
+func init() {
+	hooksInstallCmd.Flags().BoolVar(&hooksForceFlag, "force", false, "Replace existing hooks not written by drift")
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+	rootCmd.AddCommand(hooksCmd)
+}
+
+// envHookMarker identifies a hook written by 'drift hooks install'.
+const envHookMarker = "# installed by drift hooks"
+
+// envHooks are the hooks 'drift hooks install' manages.
+var envHooks = []string{"post-checkout", "post-merge"}
+
+// envHookScript never fails: a checkout or merge has already happened by
+// the time it runs. post-checkout's third argument is 0 for file checkouts,
+// which do not change the branch.
+const envHookScript = `#!/bin/sh
+` + envHookMarker + `
+# Checks the generated env file still matches the branch after a checkout or merge.
+# Remove with: drift hooks uninstall
+if [ "$(basename "$0")" = "post-checkout" ] && [ "$3" = "0" ]; then
+  exit 0
+fi
+if command -v drift >/dev/null 2>&1; then
+  drift env check --quiet || true
+fi
+exit 0
+`
+
+// installEnvHook writes the env check hook to hooksDir/name. An existing
+// hook not written by drift is only replaced when force is set.
+func installEnvHook(hooksDir, name string, force bool) (string, error) {
+	hookPath := filepath.Join(hooksDir, name)
+	if data, err := os.ReadFile(hookPath); err == nil {
+		if !strings.Contains(string(data), envHookMarker) && !force {
+			return hookPath, fmt.Errorf("a %s hook already exists at %s (use --force to replace it)", name, hookPath)
+		}
+	}
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return hookPath, fmt.Errorf("failed to create hooks dir: %w", err)
+	}
+	if err := os.WriteFile(hookPath, []byte(envHookScript), 0755); err != nil {
+		return hookPath, fmt.Errorf("failed to write hook: %w", err)
+	}
+	return hookPath, nil
+}
+
+// isEnvHookInstalled reports whether hooksDir/name was written by drift.
+func isEnvHookInstalled(hooksDir, name string) bool {
+	data, err := os.ReadFile(filepath.Join(hooksDir, name))
+	return err == nil && strings.Contains(string(data), envHookMarker)
+}
+
+// removeEnvHook deletes hooksDir/name if drift wrote it. It reports whether
+// a hook was removed.
+func removeEnvHook(hooksDir, name string) (bool, error) {
+	if !isEnvHookInstalled(hooksDir, name) {
+		return false, nil
+	}
+	if err := os.Remove(filepath.Join(hooksDir, name)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func runHooksStatus(ctx *Context) error {
+	hooksDir, err := git.GetHooksDir()
+	if err != nil {
+		return err
+	}
+
+	ui.Header("Git Hooks")
+	ui.KeyValue("Hooks Dir", hooksDir)
+	ui.NewLine()
+	for _, name := range envHooks {
+		status := ui.Dim("not installed")
+		if isEnvHookInstalled(hooksDir, name) {
+			status = ui.Green("env check")
+		} else if _, err := os.Stat(filepath.Join(hooksDir, name)); err == nil {
+			status = ui.Yellow("other hook")
+		}
+		ui.KeyValue(name, status)
+	}
+	preCommit := ui.Dim("not installed")
+	if guard.IsHookInstalled(hooksDir) {
+		preCommit = ui.Green("secret scan")
+	}
+	ui.KeyValue("pre-commit", preCommit)
+
+	ui.NewLine()
+	ui.Infof("Env refresh: %s (preferences.env_refresh)", ctx.Config().GetEnvRefresh())
+	return nil
+}
+
+func runHooksInstall(ctx *Context) error {
+	hooksDir, err := git.GetHooksDir()
+	if err != nil {
+		return err
+	}
+	for _, name := range envHooks {
+		hookPath, err := installEnvHook(hooksDir, name, hooksForceFlag)
+		if err != nil {
+			return err
+		}
+		ui.Successf("Installed %s hook: %s", name, hookPath)
+	}
+	ui.Infof("The env file is checked after every checkout and merge (on mismatch: %s)", ctx.Config().GetEnvRefresh())
+	return nil
+}
+
+func runHooksUninstall(ctx *Context) error {
+	hooksDir, err := git.GetHooksDir()
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, name := range envHooks {
+		ok, err := removeEnvHook(hooksDir, name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			ui.Successf("Removed %s hook", name)
+			removed++
+		}
+	}
+	if removed == 0 {
+		ui.Info("No drift env hooks installed")
+	}
+	return nil
+}
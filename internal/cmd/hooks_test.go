@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallEnvHook(t *testing.T) {
+	hooksDir := filepath.Join(t.TempDir(), "hooks")
+
+	path, err := installEnvHook(hooksDir, "post-checkout", false)
+	if err != nil {
+		t.Fatalf("installEnvHook() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&0111 == 0 {
+		t.Fatalf("hook not written as executable: %v", err)
+	}
+	if !isEnvHookInstalled(hooksDir, "post-checkout") {
+		t.Error("isEnvHookInstalled() = false after install")
+	}
+	// Reinstalling over drift's own hook needs no --force.
+	if _, err := installEnvHook(hooksDir, "post-checkout", false); err != nil {
+		t.Errorf("reinstall error = %v", err)
+	}
+
+	other := filepath.Join(hooksDir, "post-merge")
+	if err := os.WriteFile(other, []byte("#!/bin/sh\nmake deps\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := installEnvHook(hooksDir, "post-merge", false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("installEnvHook() over another hook error = %v, want a --force hint", err)
+	}
+	if removed, _ := removeEnvHook(hooksDir, "post-merge"); removed {
+		t.Error("removeEnvHook() removed a hook drift did not write")
+	}
+	if _, err := installEnvHook(hooksDir, "post-merge", true); err != nil {
+		t.Errorf("installEnvHook(force) error = %v", err)
+	}
+
+	for _, name := range envHooks {
+		removed, err := removeEnvHook(hooksDir, name)
+		if err != nil || !removed {
+			t.Errorf("removeEnvHook(%s) = %v, %v; want removed", name, removed, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ide"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var ideCmd = &cobra.Command{
+	Use:   "ide",
+	Short: "Generate editor configuration",
+}
+
+var ideVSCodeCmd = &cobra.Command{
+	Use:   "vscode",
+	Short: "Generate .vscode/tasks.json and launch.json entries",
+	Long: `Generate VS Code tasks and launch configurations wired to drift commands.
+
+Tasks:
+  drift: env setup [(development|production)]
+  drift: functions serve, drift: migrate status
+  drift: device start, drift: device run       (iOS/macOS projects)
+
+Launch configurations, one per environment, each running the matching
+env setup task first:
+  drift: Next.js (<env>)                        (web projects)
+  drift: Attach <app> (<env>)                   (iOS/macOS projects, Swift extension)
+
+Entries whose label or name starts with "drift: " are owned by drift and
+replaced on every run; your own tasks and configurations are kept.`,
+	Example: `  drift ide vscode`,
+	Args:    cobra.NoArgs,
+	RunE:    runIDEVSCode,
+}
+
+func init() {
+	ideCmd.AddCommand(ideVSCodeCmd)
+	rootCmd.AddCommand(ideCmd)
+}
+
+func runIDEVSCode(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	ui.Header("VS Code")
+
+	spec := ide.VSCodeSpec{
+		Web:          cfg.Project.IsWebPlatform(),
+		Apple:        cfg.Project.IsApplePlatform(),
+		AppName:      cfg.Project.Name,
+		Environments: ide.DefaultVSCodeEnvironments(),
+	}
+
+	dir := filepath.Join(cfg.ProjectRoot(), ".vscode")
+	files := []struct {
+		name    string
+		listKey string
+		nameKey string
+		entries []map[string]interface{}
+	}{
+		{"tasks.json", "tasks", "label", ide.VSCodeTasks(spec)},
+		{"launch.json", "configurations", "name", ide.VSCodeLaunchConfigs(spec)},
+	}
+
+	for _, f := range files {
+		defaults := map[string]interface{}{"version": "2.0.0"}
+		if f.name == "launch.json" {
+			defaults["version"] = "0.2.0"
+		}
+		changed, err := ide.MergeManagedEntries(filepath.Join(dir, f.name), f.listKey, f.nameKey, defaults, f.entries)
+		if err != nil {
+			return err
+		}
+		rel := filepath.Join(".vscode", f.name)
+		if changed {
+			ui.Successf("Updated %s (%d drift entries)", rel, len(f.entries))
+		} else {
+			ui.Infof("%s already up to date", rel)
+		}
+	}
+	return nil
+}
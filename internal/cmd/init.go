@@ -11,6 +11,7 @@ import (
 	"github.com/undrift/drift/internal/config"
 	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
 	"github.com/undrift/drift/internal/xcode"
 	"github.com/undrift/drift/pkg/shell"
 )
@@ -23,7 +24,10 @@ var initCmd = &cobra.Command{
 This command creates a .drift.yaml configuration file with 
 detected settings and sensible defaults.
 
-Use --fallback-branch to seed supabase.fallback_branch in .drift.local.yaml.`,
+Use --fallback-branch to seed supabase.fallback_branch in .drift.local.yaml.
+
+Pass --name, --type and --project-ref (or --skip-link) to answer the prompts
+up front, e.g. from scripts or 'drift new'.`,
 	RunE: runInit,
 }
 
@@ -32,13 +36,26 @@ var (
 	initSupabaseProject  string
 	initSkipSupabaseLink bool
 	initFallbackBranch   string
+	initNameFlag         string
+	initTypeFlag         string
+	initProjectRefFlag   string
+	initTeamIDFlag       string
+	initBundleIDFlag     string
 )
 
+// initProjectTypes are the project types 'drift init' accepts.
+var initProjectTypes = []string{"ios", "macos", "multiplatform", "web"}
+
 func init() {
 	initCmd.Flags().BoolVarP(&initForceFlag, "force", "f", false, "Overwrite existing .drift.yaml")
 	initCmd.Flags().StringVarP(&initSupabaseProject, "supabase-project", "s", "", "Supabase project name to link")
 	initCmd.Flags().BoolVar(&initSkipSupabaseLink, "skip-link", false, "Skip Supabase project linking")
 	initCmd.Flags().StringVar(&initFallbackBranch, "fallback-branch", "", "Set default Supabase fallback branch in .drift.local.yaml")
+	initCmd.Flags().StringVar(&initNameFlag, "name", "", "Project name (skips the prompt)")
+	initCmd.Flags().StringVar(&initTypeFlag, "type", "", "Project type: ios, macos, multiplatform, or web (skips the prompt)")
+	initCmd.Flags().StringVar(&initProjectRefFlag, "project-ref", "", "Supabase project ref to link (skips project selection)")
+	initCmd.Flags().StringVar(&initTeamIDFlag, "team-id", "", "APNs Team ID (skips the prompt)")
+	initCmd.Flags().StringVar(&initBundleIDFlag, "bundle-id", "", "Bundle ID (skips the prompt)")
 	rootCmd.AddCommand(initCmd)
 }
 
@@ -66,29 +83,49 @@ func runInit(cmd *cobra.Command, args []string) error {
 	ui.SubHeader("Configuration")
 
 	// Ask for project name
-	name, err := ui.PromptString("Project name", projectName)
-	if err != nil {
-		return err
+	name := initNameFlag
+	if name == "" {
+		var err error
+		name, err = ui.PromptString("Project name", projectName)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Ask for project type (put detected type first)
-	typeOptions := []string{"ios", "macos", "multiplatform", "web"}
-	// Reorder to put detected type first
-	for i, opt := range typeOptions {
-		if opt == projectType {
-			typeOptions[0], typeOptions[i] = typeOptions[i], typeOptions[0]
-			break
+	pType := initTypeFlag
+	if pType != "" {
+		valid := false
+		for _, t := range initProjectTypes {
+			valid = valid || t == pType
 		}
+		if !valid {
+			return fmt.Errorf("invalid --type %q (use %s)", pType, strings.Join(initProjectTypes, ", "))
+		}
+	} else {
+		// Ask for project type (put detected type first)
+		typeOptions := append([]string(nil), initProjectTypes...)
+		// Reorder to put detected type first
+		for i, opt := range typeOptions {
+			if opt == projectType {
+				typeOptions[0], typeOptions[i] = typeOptions[i], typeOptions[0]
+				break
+			}
+		}
+		idx, _, err := ui.PromptSelectWithIndex("Project type", typeOptions)
+		if err != nil {
+			return err
+		}
+		pType = typeOptions[idx]
 	}
-	idx, _, err := ui.PromptSelectWithIndex("Project type", typeOptions)
-	if err != nil {
-		return err
-	}
-	pType := typeOptions[idx]
 
 	// Handle Supabase project linking
 	var supabaseProjectRef, supabaseProjectName string
-	if !initSkipSupabaseLink {
+	if initProjectRefFlag != "" {
+		supabaseProjectRef = initProjectRefFlag
+		if project, err := supabase.NewClient().FindProjectByRef(initProjectRefFlag); err == nil {
+			supabaseProjectName = project.Name
+		}
+	} else if !initSkipSupabaseLink {
 		ref, projName, err := resolveSupabaseProject()
 		if err != nil {
 			ui.Warning(fmt.Sprintf("Could not link Supabase project: %v", err))
@@ -102,13 +139,19 @@ func runInit(cmd *cobra.Command, args []string) error {
 	// Detect APNs settings (only for Apple platforms)
 	var teamID, bundleID string
 	if pType != "web" {
-		teamID = detectTeamID()
-		bundleID = detectBundleID()
-
+		teamID = initTeamIDFlag
 		if teamID == "" {
-			teamID, _ = ui.PromptString("APNs Team ID (optional)", "")
+			teamID = detectTeamID()
 		}
+		bundleID = initBundleIDFlag
 		if bundleID == "" {
+			bundleID = detectBundleID()
+		}
+
+		if teamID == "" && !IsYes() {
+			teamID, _ = ui.PromptString("APNs Team ID (optional)", "")
+		}
+		if bundleID == "" && !IsYes() {
 			bundleID, _ = ui.PromptString("Bundle ID (optional)", "")
 		}
 	}
@@ -119,7 +162,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	// Write configuration file
 	configPath := ".drift.yaml"
-	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+	if err := config.WriteFileAtomic(configPath, []byte(configContent), 0644); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
@@ -133,7 +176,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 	} else {
 		ui.Success(fmt.Sprintf("Created %s", localConfigPath))
 		if initFallbackBranch != "" {
-			if isProtectedBranchName(initFallbackBranch) {
+			if supabase.IsProtectedBranchName(initFallbackBranch) {
 				ui.Warning(fmt.Sprintf("Refusing to set production-like fallback branch '%s' in %s", initFallbackBranch, localConfigPath))
 			} else if err := config.UpdateLocalSupabaseOverrides(localConfigPath, "", initFallbackBranch); err != nil {
 				ui.Warning(fmt.Sprintf("Could not set fallback branch in %s: %v", localConfigPath, err))
@@ -151,6 +194,11 @@ func runInit(cmd *cobra.Command, args []string) error {
 		ui.Success("Added .drift.local.yaml to .gitignore")
 	}
 
+	// Register in ~/.drift/projects.yaml so 'drift -p <name>' works from anywhere
+	if err := registerProject(name, cwd); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register project: %v", err))
+	}
+
 	// Link Supabase project if we have a ref
 	if supabaseProjectRef != "" {
 		ui.NewLine()
@@ -174,7 +222,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		ui.NumberedList(1, "Link Supabase project: supabase link")
 		if pType == "web" {
 			ui.NumberedList(2, "Run 'drift env setup' to generate .env.local")
-			ui.NumberedList(3, "Add .env.local to .gitignore")
+			ui.NumberedList(3, "Run 'drift guard' to gitignore generated secrets files")
 		} else {
 			ui.NumberedList(2, "Run 'drift env setup' to generate Config.xcconfig")
 			ui.NumberedList(3, "Add Config.xcconfig to your Xcode project")
@@ -182,7 +230,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 	} else {
 		if pType == "web" {
 			ui.NumberedList(1, "Run 'drift env setup' to generate .env.local")
-			ui.NumberedList(2, "Add .env.local to .gitignore")
+			ui.NumberedList(2, "Run 'drift guard' to gitignore generated secrets files")
 		} else {
 			ui.NumberedList(1, "Run 'drift env setup' to generate Config.xcconfig")
 			ui.NumberedList(2, "Add Config.xcconfig to your Xcode project")
@@ -319,6 +367,11 @@ func detectProjectName() string {
 }
 
 func detectProjectType() string {
+	// Expo and Flutter apps use the web env generation (see web.framework)
+	if web.DetectFramework(".") != "" {
+		return "web"
+	}
+
 	// Check for Next.js / web project (package.json with next)
 	if _, err := os.Stat("package.json"); err == nil {
 		data, err := os.ReadFile("package.json")
@@ -552,10 +605,15 @@ project:
 	// Add platform-specific config
 	if projectType == "web" {
 		// Web project config
-		configContent += `web:
-  env_output: .env.local
+		framework := web.DetectFramework(".")
+		if framework == "" {
+			framework = web.FrameworkNextJS
+		}
+		configContent += fmt.Sprintf(`web:
+  framework: %s # nextjs, vite, expo, flutter
+  env_output: %s
 
-`
+`, framework, web.DefaultEnvOutput(framework))
 	} else {
 		// Apple platform config
 		// Detect xcconfig path
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/mask"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Tail Postgres, Edge Function, and Auth logs in one stream",
+	Long: `Show logs from multiple Supabase services for the resolved branch
+as a single, timestamped, color-coded stream.
+
+Sources:
+  postgres   Database logs (aliases: pg, db)
+  functions  Edge Function console output (aliases: fn, edge)
+  auth       Auth (GoTrue) logs
+
+By default all sources are shown. Use --source to restrict the stream,
+--grep to filter by message text, and --follow to keep polling for new
+entries until interrupted.
+
+The target branch is resolved the same way as other commands: --branch,
+then override_branch, then the current git branch (with fallback).`,
+	Example: `  drift logs                          # Last 15 minutes, all sources
+  drift logs -f                       # Follow all sources
+  drift logs -s postgres -s auth      # Only Postgres and Auth
+  drift logs --since 1h --grep error  # Errors from the last hour
+  drift logs -b development -f        # Follow the development branch`,
+	Args: cobra.NoArgs,
+	RunE: runLogs,
+}
+
+var (
+	logsBranchFlag   string
+	logsSourceFlags  []string
+	logsSinceFlag    time.Duration
+	logsFollowFlag   bool
+	logsIntervalFlag time.Duration
+	logsGrepFlag     string
+	logsLevelFlag    string
+	logsLimitFlag    int
+)
+
+func init() {
+	logsCmd.Flags().StringVarP(&logsBranchFlag, "branch", "b", "", "Target Supabase branch (default: current git branch)")
+	logsCmd.Flags().StringSliceVarP(&logsSourceFlags, "source", "s", nil, "Log sources to include (postgres, functions, auth)")
+	logsCmd.Flags().DurationVar(&logsSinceFlag, "since", 15*time.Minute, "How far back to fetch logs")
+	logsCmd.Flags().BoolVarP(&logsFollowFlag, "follow", "f", false, "Keep polling for new log entries")
+	logsCmd.Flags().DurationVar(&logsIntervalFlag, "interval", 5*time.Second, "Polling interval when following")
+	logsCmd.Flags().StringVar(&logsGrepFlag, "grep", "", "Only show entries whose message contains this text (case-insensitive)")
+	logsCmd.Flags().StringVar(&logsLevelFlag, "level", "", "Only show entries at this level (e.g. error, warning, log)")
+	logsCmd.Flags().IntVar(&logsLimitFlag, "limit", 100, "Maximum entries to fetch per source per poll")
+	addShowSecretsFlag(logsCmd)
+	rootCmd.AddCommand(logsCmd)
+}
+
+// logFilter holds the client-side filters applied to the merged stream.
+type logFilter struct {
+	grep  string
+	level string
+}
+
+func (f logFilter) matches(entry supabase.LogEntry) bool {
+	if f.level != "" && !strings.EqualFold(entry.Level, f.level) {
+		return false
+	}
+	if f.grep != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(f.grep)) {
+		return false
+	}
+	return true
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+
+	sources, err := parseLogSources(logsSourceFlags)
+	if err != nil {
+		return err
+	}
+	if logsSinceFlag <= 0 {
+		return fmt.Errorf("--since must be a positive duration")
+	}
+	if logsFollowFlag && logsIntervalFlag < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+
+	cfg := config.LoadOrDefault()
+	client := supabase.NewClient()
+
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	sp := ui.NewSpinner("Resolving target environment")
+	sp.Start()
+	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, logsBranchFlag)
+	sp.Stop()
+	if err != nil {
+		return err
+	}
+
+	mgmt, err := supabase.NewManagementClient()
+	if err != nil {
+		return err
+	}
+
+	ui.Header("Logs")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Project Ref", ui.Cyan(info.ProjectRef))
+	ui.KeyValue("Sources", formatLogSources(sources))
+	if info.IsFallback {
+		ui.Warningf("Using fallback target branch: %s", info.SupabaseBranch.GitBranch)
+	}
+	ui.NewLine()
+
+	filter := logFilter{grep: logsGrepFlag, level: logsLevelFlag}
+	policy := displayPolicy(cmd, cfg)
+	seen := make(map[string]time.Time)
+
+	end := time.Now().UTC()
+	start := end.Add(-logsSinceFlag)
+	printed, err := printLogWindow(mgmt, info.ProjectRef, sources, start, end, filter, policy, seen)
+	if err != nil {
+		return err
+	}
+
+	if !logsFollowFlag {
+		if printed == 0 {
+			ui.Infof("No log entries in the last %s", logsSinceFlag)
+		}
+		return nil
+	}
+
+	ui.NewLine()
+	ui.Info("Following logs (Ctrl+C to stop)...")
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(logsIntervalFlag)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ui.NewLine()
+			return nil
+		case <-ticker.C:
+			// Overlap the window slightly; the analytics backend ingests with a short delay.
+			start = end.Add(-30 * time.Second)
+			end = time.Now().UTC()
+			pruneSeenLogs(seen, start)
+			if _, err := printLogWindow(mgmt, info.ProjectRef, sources, start, end, filter, policy, seen); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// printLogWindow fetches every source for the window, prints unseen entries with secrets masked, and returns how many were printed.
+// A source that fails is reported and skipped; it is an error only when every source fails.
+func printLogWindow(mgmt *supabase.ManagementClient, projectRef string, sources []supabase.LogSource, start, end time.Time, filter logFilter, policy *mask.Policy, seen map[string]time.Time) (int, error) {
+	var groups [][]supabase.LogEntry
+	var lastErr error
+	for _, source := range sources {
+		entries, err := mgmt.GetServiceLogs(projectRef, source, start, end, logsLimitFlag)
+		if err != nil {
+			ui.Warningf("%v", err)
+			lastErr = err
+			continue
+		}
+		groups = append(groups, entries)
+	}
+	if len(groups) == 0 && lastErr != nil {
+		return 0, fmt.Errorf("failed to fetch logs from every source: %w", lastErr)
+	}
+
+	printed := 0
+	for _, entry := range supabase.MergeLogEntries(groups...) {
+		key := logEntryKey(entry)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = entry.Timestamp
+
+		if !filter.matches(entry) {
+			continue
+		}
+		fmt.Println(formatLogEntry(entry, policy))
+		printed++
+	}
+	return printed, nil
+}
+
+// pruneSeenLogs forgets entries older than before, which later windows no
+// longer cover, so following logs does not grow seen without bound.
+func pruneSeenLogs(seen map[string]time.Time, before time.Time) {
+	for key, ts := range seen {
+		if ts.Before(before) {
+			delete(seen, key)
+		}
+	}
+}
+
+func parseLogSources(values []string) ([]supabase.LogSource, error) {
+	if len(values) == 0 {
+		return supabase.AllLogSources(), nil
+	}
+
+	seen := make(map[supabase.LogSource]bool)
+	var sources []supabase.LogSource
+	for _, v := range values {
+		source, err := supabase.ParseLogSource(v)
+		if err != nil {
+			return nil, err
+		}
+		if seen[source] {
+			continue
+		}
+		seen[source] = true
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func formatLogSources(sources []supabase.LogSource) string {
+	names := make([]string, len(sources))
+	for i, s := range sources {
+		names[i] = logSourceColor(s)(string(s))
+	}
+	return strings.Join(names, ", ")
+}
+
+func logEntryKey(entry supabase.LogEntry) string {
+	return fmt.Sprintf("%s|%d|%s", entry.Source, entry.Timestamp.UnixMicro(), entry.Message)
+}
+
+func logSourceColor(source supabase.LogSource) func(a ...interface{}) string {
+	switch source {
+	case supabase.LogSourcePostgres:
+		return ui.Blue
+	case supabase.LogSourceFunctions:
+		return ui.Magenta
+	case supabase.LogSourceAuth:
+		return ui.Cyan
+	default:
+		return fmt.Sprint
+	}
+}
+
+func colorLogLevel(level string) string {
+	switch strings.ToLower(level) {
+	case "error", "fatal", "panic":
+		return ui.Red(level)
+	case "warning", "warn":
+		return ui.Yellow(level)
+	case "info", "log":
+		return ui.Cyan(level)
+	default:
+		return ui.Dim(level)
+	}
+}
+
+func formatLogEntry(entry supabase.LogEntry, policy *mask.Policy) string {
+	level := entry.Level
+	if level == "" {
+		level = "-"
+	}
+	return fmt.Sprintf("%s %s [%s] %s",
+		ui.Dim(entry.Timestamp.Local().Format("15:04:05.000")),
+		logSourceColor(entry.Source)(fmt.Sprintf("%-9s", entry.Source)),
+		colorLogLevel(level),
+		policy.Text(strings.TrimSpace(entry.Message)),
+	)
+}
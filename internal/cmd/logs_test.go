@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPruneSeenLogs(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	seen := map[string]time.Time{
+		"old":    now.Add(-2 * time.Minute),
+		"edge":   now.Add(-30 * time.Second),
+		"recent": now.Add(-5 * time.Second),
+	}
+
+	pruneSeenLogs(seen, now.Add(-30*time.Second))
+
+	if _, ok := seen["old"]; ok {
+		t.Error("entry older than the window was kept")
+	}
+	for _, key := range []string{"edge", "recent"} {
+		if _, ok := seen[key]; !ok {
+			t.Errorf("entry %q inside the window was pruned", key)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/mask"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var showSecretsFlag bool
+
+// addShowSecretsFlag registers --show-secrets on commands that mask values.
+func addShowSecretsFlag(cmds ...*cobra.Command) {
+	for _, c := range cmds {
+		c.Flags().BoolVar(&showSecretsFlag, "show-secrets", false, "Show secret values unmasked (recorded in the audit log)")
+	}
+}
+
+// maskPolicy returns the project's masking policy: the default sensitive
+// names plus masking.sensitive, minus masking.public.
+func maskPolicy(cfg *config.Config) *mask.Policy {
+	if cfg == nil {
+		return mask.New(nil, nil)
+	}
+	return mask.New(cfg.Masking.Sensitive, cfg.Masking.Public)
+}
+
+// displayPolicy returns the policy for what cmd prints. With --show-secrets
+// values are revealed, and the reveal is written to the audit log first so
+// there is a record of who saw them.
+func displayPolicy(cmd *cobra.Command, cfg *config.Config) *mask.Policy {
+	policy := maskPolicy(cfg)
+	if !showSecretsFlag {
+		return policy
+	}
+	if cfg != nil {
+		recordAudit(cfg, "show-secrets: "+cmd.CommandPath(), "", "", time.Now(), nil)
+	}
+	// stderr, so JSON on stdout stays clean
+	fmt.Fprintf(os.Stderr, "%s Showing secret values unmasked; this is recorded in the audit log\n", ui.Yellow("⚠"))
+	return policy.Reveal()
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/state"
+)
+
+func TestDisplayPolicy_ShowSecretsIsAudited(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".drift.yaml")
+	yaml := "project:\n  type: web\nmasking:\n  sensitive: [STRIPE_*]\n  public: [SUPABASE_ANON_KEY]\n"
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	cmd := &cobra.Command{Use: "show"}
+
+	policy := displayPolicy(cmd, cfg)
+	if !policy.Sensitive("STRIPE_ACCOUNT") || policy.Sensitive("SUPABASE_ANON_KEY") {
+		t.Error("masking config not applied")
+	}
+	if got := policy.Display("STRIPE_ACCOUNT", "acct_1234567890"); got != "****" {
+		t.Errorf("Display() = %q", got)
+	}
+	if entries, _ := state.ReadAudit(cfg.ProjectRoot()); len(entries) != 0 {
+		t.Errorf("masked output was audited: %+v", entries)
+	}
+
+	showSecretsFlag = true
+	defer func() { showSecretsFlag = false }()
+	policy = displayPolicy(cmd, cfg)
+	if got := policy.Display("STRIPE_ACCOUNT", "acct_1234567890"); got != "acct_1234567890" {
+		t.Errorf("Display() with --show-secrets = %q", got)
+	}
+	entries, err := state.ReadAudit(cfg.ProjectRoot())
+	if err != nil || len(entries) != 1 || !strings.HasPrefix(entries[0].Operation, "show-secrets") {
+		t.Errorf("audit entries = %+v, %v", entries, err)
+	}
+}
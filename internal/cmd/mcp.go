@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/mcp"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Model Context Protocol server",
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve drift tools over MCP (stdio)",
+	Long: `Run drift as an MCP server on stdin/stdout so coding agents and other
+MCP clients can query project state through structured tool calls instead
+of parsing CLI output.
+
+Tools:
+  env_resolve        Resolve the Supabase branch for a git branch
+  worktree_list      List git worktrees with change counts
+  worktree_create    Create a worktree for a branch
+  functions_list     Local Edge Functions and their deployed versions
+  migration_status   Local, applied, and pending migrations
+  device_status      WebDriverAgent, tunnel, and device session status
+
+Tool calls never prompt: resolution that would normally ask for a fallback
+branch fails instead. Human-readable progress goes to stderr.
+
+Register with an MCP client, e.g. in .mcp.json:
+  {
+    "mcpServers": {
+      "drift": { "command": "drift", "args": ["mcp", "serve"] }
+    }
+  }`,
+	Args: cobra.NoArgs,
+	RunE: runMCPServe,
+}
+
+func init() {
+	mcpCmd.AddCommand(mcpServeCmd)
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCPServe(cmd *cobra.Command, args []string) error {
+	// The protocol owns stdout; everything else drift prints goes to stderr.
+	protocol := os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+	defer func() { os.Stdout = protocol }()
+
+	// Tool calls must never block on a prompt.
+	yesFlag = true
+
+	server := mcp.NewServer("drift", version)
+	registerMCPTools(server)
+	return server.Serve(os.Stdin, protocol)
+}
+
+func registerMCPTools(server *mcp.Server) {
+	server.AddTool(mcp.Tool{
+		Name:        "env_resolve",
+		Description: "Resolve which Supabase branch, environment, and project a git branch targets (default: current branch, honoring override_branch).",
+		InputSchema: mcp.ObjectSchema(map[string]interface{}{
+			"branch": mcp.StringProperty("Git or Supabase branch to resolve instead of the current branch"),
+		}),
+		Handler: mcpEnvResolve,
+	})
+	server.AddTool(mcp.Tool{
+		Name:        "worktree_list",
+		Description: "List git worktrees with their branch, path, and uncommitted change count.",
+		Handler:     mcpWorktreeList,
+	})
+	server.AddTool(mcp.Tool{
+		Name:        "worktree_create",
+		Description: "Create a git worktree for a branch (existing local/remote branch, or a new branch from a base) and copy worktree.copy_on_create files. Does not run env setup.",
+		InputSchema: mcp.ObjectSchema(map[string]interface{}{
+			"branch": mcp.StringProperty("Branch to create the worktree for"),
+			"from":   mcp.StringProperty("Base branch for new branches (default: development)"),
+		}, "branch"),
+		Handler: mcpWorktreeCreate,
+	})
+	server.AddTool(mcp.Tool{
+		Name:        "functions_list",
+		Description: "List local Edge Functions and the version deployed to the resolved Supabase branch.",
+		InputSchema: mcp.ObjectSchema(map[string]interface{}{
+			"branch": mcp.StringProperty("Target Supabase branch (default: current git branch)"),
+		}),
+		Handler: mcpFunctionsList,
+	})
+	server.AddTool(mcp.Tool{
+		Name:        "migration_status",
+		Description: "List local migrations and which are applied or pending on the resolved Supabase branch.",
+		InputSchema: mcp.ObjectSchema(map[string]interface{}{
+			"branch": mcp.StringProperty("Target Supabase branch (default: current git branch)"),
+		}),
+		Handler: mcpMigrationStatus,
+	})
+	server.AddTool(mcp.Tool{
+		Name:        "device_status",
+		Description: "Report WebDriverAgent, go-ios tunnel, and port-forward status, plus per-device WDA sessions.",
+		Handler:     mcpDeviceStatus,
+	})
+}
+
+// mcpBranchArgs are the arguments shared by tools that target a Supabase branch.
+type mcpBranchArgs struct {
+	Branch string `json:"branch"`
+}
+
+func mcpEnvResolve(raw json.RawMessage) (interface{}, error) {
+	var args mcpBranchArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	return collectEnvState(args.Branch)
+}
+
+func mcpWorktreeList(json.RawMessage) (interface{}, error) {
+	worktrees, err := collectWorktreeStates()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"worktrees": worktrees}, nil
+}
+
+func mcpWorktreeCreate(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Branch string `json:"branch"`
+		From   string `json:"from"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	if args.Branch == "" {
+		return nil, fmt.Errorf("branch is required")
+	}
+	if args.From == "" {
+		args.From = "development"
+	}
+	cfg, err := loadProjectState()
+	if err != nil {
+		return nil, err
+	}
+
+	if git.WorktreeExists(args.Branch) {
+		wt, err := git.GetWorktree(args.Branch)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"branch": args.Branch, "path": wt.Path, "created": false}, nil
+	}
+
+	path, err := createWorktreeForBranch(cfg, args.Branch, args.From)
+	if err != nil {
+		return nil, err
+	}
+
+	var copied []string
+	if mainPath, err := git.GetMainWorktreePath(); err == nil {
+		copied = copyWorktreeFiles(mainPath, path, cfg.Worktree.CopyOnCreate)
+	}
+	return map[string]interface{}{"branch": args.Branch, "path": path, "created": true, "copied": copied}, nil
+}
+
+func mcpFunctionsList(raw json.RawMessage) (interface{}, error) {
+	var args mcpBranchArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	return collectFunctionsState(args.Branch)
+}
+
+func mcpMigrationStatus(raw json.RawMessage) (interface{}, error) {
+	var args mcpBranchArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	return collectMigrationsState(args.Branch)
+}
+
+func mcpDeviceStatus(json.RawMessage) (interface{}, error) {
+	return collectDeviceState(), nil
+}
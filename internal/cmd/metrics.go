@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/metrics"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Show where drift commands spend their time",
+	Long: `Every drift command is timed, along with the external programs it runs
+(supabase, git, psql, xcodebuild, ...) and the Supabase API hosts it calls.
+The most recent command is kept in .drift/metrics/last.json.
+
+Set preferences.metrics_history: true in .drift.local.yaml to also append
+each command to .drift/metrics/history.jsonl for 'drift metrics history'.
+
+Only the command path, the drift version, program names, and API hosts are
+recorded: no arguments, branch names, or output.`,
+}
+
+var metricsLastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "Show timings for the previous command",
+	Args:  cobra.NoArgs,
+	RunE:  runMetricsLast,
+}
+
+var metricsHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Summarize recorded command timings by drift version",
+	Long: `Summarize the opt-in history by command and drift version, so the effect
+of an upgrade shows up as a change in the median.`,
+	Example: `  drift metrics history
+  drift metrics history --command "drift env setup"`,
+	Args: cobra.NoArgs,
+	RunE: runMetricsHistory,
+}
+
+var (
+	metricsJSONFlag    bool
+	metricsCommandFlag string
+)
+
+func init() {
+	metricsLastCmd.Flags().BoolVar(&metricsJSONFlag, "json", false, "Output JSON")
+	metricsHistoryCmd.Flags().BoolVar(&metricsJSONFlag, "json", false, "Output JSON")
+	metricsHistoryCmd.Flags().StringVar(&metricsCommandFlag, "command", "", "Only show this command (e.g. \"drift deploy all\")")
+
+	metricsCmd.AddCommand(metricsLastCmd)
+	metricsCmd.AddCommand(metricsHistoryCmd)
+	rootCmd.AddCommand(metricsCmd)
+}
+
+// beginMetrics starts timing cmd. 'drift metrics' itself is not timed so
+// that 'drift metrics last' shows the command before it.
+func beginMetrics(cmd *cobra.Command) {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == metricsCmd {
+			return
+		}
+	}
+	shell.SetObserver(func(name string, d time.Duration) {
+		metrics.Record(metrics.KindExec, name, d)
+	})
+	metrics.Begin(cmd.CommandPath(), version)
+}
+
+// finishMetrics stores the timed run in the project's state directory.
+// Failures are only reported in verbose mode: timing must never break a
+// command.
+func finishMetrics(cmdErr error) {
+	run := metrics.End(cmdErr)
+	if run == nil || !config.Exists() {
+		return
+	}
+	cfg, err := config.LoadWithLocal()
+	if err != nil {
+		return
+	}
+	shell.VerboseLog("%s took %s (%s external)", run.Command, run.Duration.Round(time.Millisecond), run.External().Round(time.Millisecond))
+
+	root := cfg.ProjectRoot()
+	if err := metrics.SaveLast(root, run); err != nil {
+		shell.VerboseLog("could not save metrics: %v", err)
+	}
+	if cfg.IsMetricsHistoryEnabled() {
+		if err := metrics.AppendHistory(root, run); err != nil {
+			shell.VerboseLog("could not save metrics history: %v", err)
+		}
+	}
+}
+
+func runMetricsLast(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	run, err := metrics.LoadLast(cfg.ProjectRoot())
+	if err != nil {
+		return err
+	}
+	if metricsJSONFlag {
+		return printMetricsJSON(run)
+	}
+	if run == nil {
+		ui.Info("No command has been timed yet")
+		return nil
+	}
+
+	ui.Header("Last Command")
+	result := ui.Green("ok")
+	if run.Failed {
+		result = ui.Red("failed")
+	}
+	ui.KeyValue("Command", fmt.Sprintf("%s (%s)", ui.Cyan(run.Command), result))
+	ui.KeyValue("Version", run.Version)
+	ui.KeyValue("Started", run.Start.Local().Format("2006-01-02 15:04:05"))
+	ui.KeyValue("Duration", formatMetricsDuration(run.Duration))
+
+	external := run.External()
+	ui.KeyValue("External", fmt.Sprintf("%s (%s)", formatMetricsDuration(external), metricsShare(external, run.Duration)))
+	if external < run.Duration {
+		ui.KeyValue("Drift", formatMetricsDuration(run.Duration-external))
+	}
+
+	if len(run.Calls) == 0 {
+		return nil
+	}
+	ui.NewLine()
+	fmt.Printf("  %-5s %-40s %6s %10s %7s\n", "KIND", "NAME", "CALLS", "TIME", "SHARE")
+	for _, c := range run.Calls {
+		fmt.Printf("  %-5s %-40s %6d %10s %7s\n", c.Kind, truncateValue(c.Name, 40), c.Count, formatMetricsDuration(c.Duration), metricsShare(c.Duration, run.Duration))
+	}
+	return nil
+}
+
+func runMetricsHistory(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	runs, err := metrics.ReadHistory(cfg.ProjectRoot())
+	if err != nil {
+		return err
+	}
+	if metricsCommandFlag != "" {
+		var filtered []metrics.Run
+		for _, r := range runs {
+			if r.Command == metricsCommandFlag {
+				filtered = append(filtered, r)
+			}
+		}
+		runs = filtered
+	}
+	summaries := metrics.Summarize(runs)
+	if metricsJSONFlag {
+		return printMetricsJSON(summaries)
+	}
+
+	ui.Header("Command Timings")
+	if len(runs) == 0 {
+		if !cfg.IsMetricsHistoryEnabled() {
+			ui.Info("History is off. Set preferences.metrics_history: true in .drift.local.yaml to record it")
+		} else {
+			ui.Info("No commands recorded yet")
+		}
+		return nil
+	}
+
+	fmt.Printf("  %-28s %-10s %5s %10s %10s %10s  %s\n", "COMMAND", "VERSION", "RUNS", "MEDIAN", "MAX", "EXTERNAL", "SLOWEST CALL")
+	for _, s := range summaries {
+		runsCol := fmt.Sprintf("%d", s.Runs)
+		if s.Failed > 0 {
+			runsCol = fmt.Sprintf("%d/%d", s.Runs-s.Failed, s.Runs)
+		}
+		fmt.Printf("  %-28s %-10s %5s %10s %10s %10s  %s\n",
+			truncateValue(s.Command, 28), truncateValue(s.Version, 10), runsCol,
+			formatMetricsDuration(s.Median), formatMetricsDuration(s.Max), formatMetricsDuration(s.External), ui.Dim(s.Top))
+	}
+	ui.NewLine()
+	ui.Info(fmt.Sprintf("%d runs recorded; EXTERNAL is the median time in external programs and API calls", len(runs)))
+	return nil
+}
+
+func printMetricsJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// formatMetricsDuration rounds d to a precision that suits its size.
+func formatMetricsDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(10 * time.Millisecond).String()
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+// metricsShare formats part as a percentage of total.
+func metricsShare(part, total time.Duration) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", float64(part)/float64(total)*100)
+}
@@ -2,16 +2,15 @@ package cmd
 
 import (
 	"fmt"
-	"net/url"
 	"os"
 	"sort"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
 	"github.com/undrift/drift/internal/database"
-	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/errs"
 	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
 	"github.com/undrift/drift/pkg/shell"
@@ -31,14 +30,14 @@ var migratePushCmd = &cobra.Command{
 If no branch is specified, uses the current git branch.
 Protected branches (main, master) are blocked by default.`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: runMigratePush,
+	RunE: Run(runMigratePush, RequireProject, GuardWorktree),
 }
 
 var migrateStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show migration status",
 	Long:  `Show the current migration status for the project.`,
-	RunE:  runMigrateStatus,
+	RunE:  Run(runMigrateStatus, RequireProject),
 }
 
 var migrateNewCmd = &cobra.Command{
@@ -46,7 +45,7 @@ var migrateNewCmd = &cobra.Command{
 	Short: "Create a new migration",
 	Long:  `Create a new migration file with the given name.`,
 	Args:  cobra.ExactArgs(1),
-	RunE:  runMigrateNew,
+	RunE:  Run(runMigrateNew),
 }
 
 var migrateHistoryCmd = &cobra.Command{
@@ -57,7 +56,7 @@ var migrateHistoryCmd = &cobra.Command{
 Displays all migrations with their status (applied/pending) and timestamps.
 If no branch is specified, uses the current git branch.`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: runMigrateHistory,
+	RunE: Run(runMigrateHistory, RequireProject),
 }
 
 var (
@@ -82,21 +81,15 @@ func init() {
 	rootCmd.AddCommand(migrateCmd)
 }
 
-func runMigratePush(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runMigratePush(ctx *Context) error {
+	cfg := ctx.Config()
 
 	// Determine target branch
-	targetBranch := ""
-	if len(args) > 0 {
-		targetBranch = args[0]
-	} else {
+	targetBranch := ctx.Arg(0)
+	if targetBranch == "" {
 		var err error
-		targetBranch, err = git.CurrentBranch()
-		if err != nil {
-			return fmt.Errorf("failed to get current branch: %w", err)
+		if targetBranch, err = ctx.GitBranch(); err != nil {
+			return err
 		}
 	}
 
@@ -111,7 +104,7 @@ func runMigratePush(cmd *cobra.Command, args []string) error {
 	overrideBranch := cfg.Supabase.OverrideBranch
 
 	// Resolve Supabase branch
-	client := supabase.NewClient()
+	client := ctx.Client()
 
 	sp := ui.NewSpinner("Resolving Supabase branch")
 	sp.Start()
@@ -169,43 +162,88 @@ func runMigratePush(cmd *cobra.Command, args []string) error {
 
 	ui.NewLine()
 
+	warnMigrationConflicts(cfg, appliedMigrations)
+
 	if migrateDryRunFlag {
 		ui.Info("Dry run - no changes made")
 		return nil
 	}
 
+	if err := requireNotReviewMode(cfg, "push migrations"); err != nil {
+		return err
+	}
+
 	// Confirm for production (stricter - requires typing "yes")
 	if info.Environment == supabase.EnvProduction {
 		confirmed, err := RequireProductionConfirmation(info.Environment, "push migrations")
 		if err != nil || !confirmed {
-			return nil
+			return errs.Cancelled("push migrations")
 		}
 	} else if !IsYes() {
 		// Normal confirmation for non-production
 		confirmed, err := ui.PromptYesNo(fmt.Sprintf("Push %d migration(s) to %s?", len(pendingMigrations), info.SupabaseBranch.Name), true)
 		if err != nil || !confirmed {
 			ui.Info("Cancelled")
-			return nil
+			return errs.Cancelled("push migrations")
 		}
 	}
 
 	ui.NewLine()
 
+	if err := pushPendingMigrations(cfg, info, pendingMigrations); err != nil {
+		return err
+	}
+
+	// Next steps
+	ui.NewLine()
+	ui.SubHeader("Next Steps")
+	ui.List("drift migrate history     - View migration status")
+	ui.List("drift deploy functions    - Deploy edge functions")
+	ui.List("drift status              - Check overall project status")
+
+	return nil
+}
+
+// pushPendingMigrations pushes migrations to info's branch under the push
+// lock and verifies that each pending migration was applied. Confirmation
+// is the caller's responsibility.
+func pushPendingMigrations(cfg *config.Config, info *supabase.BranchInfo, pendingMigrations []string) error {
+	// Production pushes are announced to the configured notification channels.
+	start := time.Now()
+	notifyPush := func(err error) {
+		if info.Environment == supabase.EnvProduction {
+			notifyOperation(cfg, "migrate push", string(info.Environment), info.SupabaseBranch.Name, start, err)
+		}
+	}
+
 	// Some migrations alter the supabase_realtime publication directly.
 	// Ensure it exists before push so these migrations don't fail on branches
 	// where Supabase hasn't created it yet.
 	dbURL, urlErr := getDbURLForProject(info.ProjectRef)
 	if urlErr == nil && dbURL != "" {
+		opts, err := restoreOptionsFromDBURL(dbURL)
+		if err != nil {
+			return err
+		}
+		release, err := acquirePushLock(opts, info.SupabaseBranch.Name, "migrate push")
+		if err != nil {
+			return err
+		}
+		defer release()
+
 		if err := ensureSupabaseRealtimePublication(dbURL); err != nil {
 			ui.Warning(fmt.Sprintf("Could not ensure realtime publication: %v", err))
 		}
+	} else {
+		ui.Warning("Could not connect to take the push lock; pushing without it")
 	}
 
 	// Push migrations
-	sp = ui.NewSpinner("Pushing migrations")
+	sp := ui.NewSpinner("Pushing migrations")
 	sp.Start()
 
 	var result *shell.Result
+	var err error
 	if urlErr == nil && dbURL != "" {
 		// Use --db-url for direct connection (works for preview branches)
 		result, err = shell.Run("supabase", "db", "push", "--db-url", dbURL)
@@ -219,7 +257,9 @@ func runMigratePush(cmd *cobra.Command, args []string) error {
 		if result != nil && result.Stderr != "" {
 			ui.Error(result.Stderr)
 		}
-		return fmt.Errorf("failed to push migrations: %w", err)
+		err = fmt.Errorf("failed to push migrations: %w", err)
+		notifyPush(err)
+		return err
 	}
 
 	// Check for actual errors in stderr (not just informational messages)
@@ -236,6 +276,7 @@ func runMigratePush(cmd *cobra.Command, args []string) error {
 					ui.Error(strings.TrimSpace(line))
 				}
 			}
+			notifyPush(fmt.Errorf("migration push encountered errors"))
 			return fmt.Errorf("migration push failed - see errors above")
 		}
 	}
@@ -259,6 +300,7 @@ func runMigratePush(cmd *cobra.Command, args []string) error {
 	if verifyErr != nil {
 		ui.Warning(fmt.Sprintf("Could not verify migrations: %v", verifyErr))
 		ui.Success(fmt.Sprintf("Pushed %d migration(s) - verify with 'drift migrate history'", len(pendingMigrations)))
+		notifyPush(nil)
 	} else {
 		// Check which migrations are now applied
 		appliedCount := 0
@@ -282,29 +324,36 @@ func runMigratePush(cmd *cobra.Command, args []string) error {
 			ui.NewLine()
 			ui.Infof("Successfully applied: %d/%d migrations", appliedCount, len(pendingMigrations))
 			ui.Info("Run 'drift migrate history' for details or try pushing again")
+			notifyPush(fmt.Errorf("%d of %d migration(s) may not have been applied", len(failedMigrations), len(pendingMigrations)))
 		} else {
 			ui.Success(fmt.Sprintf("All %d migration(s) applied successfully", appliedCount))
+			notifyPush(nil)
 		}
 	}
 
-	// Next steps
-	ui.NewLine()
-	ui.SubHeader("Next Steps")
-	ui.List("drift migrate history     - View migration status")
-	ui.List("drift deploy functions    - Deploy edge functions")
-	ui.List("drift status              - Check overall project status")
-
+	if urlErr == nil {
+		syncRealtimeAfterMigrate(cfg, dbURL)
+	}
 	return nil
 }
 
 // getLocalMigrations returns a sorted list of migration filenames from the migrations directory.
 func getLocalMigrations(cfg *config.Config) ([]string, error) {
-	migrationsDir := cfg.Supabase.MigrationsDir
-	if migrationsDir == "" {
-		migrationsDir = "supabase/migrations"
+	return listMigrationFiles(migrationsDirFor(cfg))
+}
+
+// migrationsDirFor returns the configured migrations directory, relative to
+// the working directory unless configured as an absolute path.
+func migrationsDirFor(cfg *config.Config) string {
+	if cfg.Supabase.MigrationsDir == "" {
+		return "supabase/migrations"
 	}
+	return cfg.Supabase.MigrationsDir
+}
 
-	entries, err := os.ReadDir(migrationsDir)
+// listMigrationFiles returns the sorted .sql filenames in dir.
+func listMigrationFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("could not read migrations directory: %w", err)
 	}
@@ -334,14 +383,24 @@ func getAppliedMigrations(projectRef string) (map[string]bool, error) {
 	}
 
 	applied := make(map[string]bool)
+	timestamps := make([]string, 0, len(details))
 	for timestamp := range details {
 		applied[timestamp] = true
+		timestamps = append(timestamps, timestamp)
 	}
+	sort.Strings(timestamps)
+	// Cached for 'drift prompt', which never touches the network.
+	supabase.SaveCached(appliedMigrationsCacheKey(projectRef), timestamps)
 
 	return applied, nil
 }
 
-// getDbURLForProject gets the database URL for a project ref.
+func appliedMigrationsCacheKey(projectRef string) string {
+	return "migrations-" + projectRef
+}
+
+// getDbURLForProject gets the database URL for a project ref. The URL uses
+// the session pooler, which the Supabase CLI needs for prepared statements.
 func getDbURLForProject(projectRef string) (string, error) {
 	client := supabase.NewClient()
 
@@ -365,9 +424,10 @@ func getDbURLForProject(projectRef string) (string, error) {
 		return "", fmt.Errorf("could not find git branch for project ref %s", projectRef)
 	}
 
+	cfg := config.LoadOrDefault()
+
 	// For production, require explicit password and build URL
 	if isProduction {
-		cfg := config.LoadOrDefault()
 		poolerHost := cfg.Database.GetPoolerHostForBranch(gitBranch)
 		poolerPort := cfg.Database.GetPoolerPort()
 
@@ -402,7 +462,8 @@ func getDbURLForProject(projectRef string) (string, error) {
 			_ = os.Setenv("PROD_PASSWORD", pw)
 		}
 		// Build production connection URL
-		return fmt.Sprintf("postgresql://postgres.%s:%s@%s:%d/postgres", projectRef, pw, poolerHost, poolerPort), nil
+		conn := database.Connection{Host: poolerHost, Port: poolerPort, User: "postgres." + projectRef, Password: pw, Database: "postgres"}
+		return dbConnections(cfg).For(conn, database.OpMigrate).URL(), nil
 	}
 
 	// For non-production, get URL from experimental API
@@ -415,11 +476,14 @@ func getDbURLForProject(projectRef string) (string, error) {
 		return "", fmt.Errorf("could not get database URL from connection info")
 	}
 
-	// Use session mode (port 5432) instead of transaction mode (port 6543)
-	// Transaction mode doesn't support prepared statements which supabase CLI uses
-	url := strings.Replace(connInfo.PostgresURL, ":6543/", ":5432/", 1)
-
-	return url, nil
+	conn, err := database.ParseConnectionURL(connInfo.PostgresURL)
+	if err != nil {
+		return "", err
+	}
+	if pw := cfg.Supabase.DBPasswordFor(projectRef); pw != "" {
+		conn.Password = pw
+	}
+	return dbConnections(cfg).For(conn, database.OpMigrate).URL(), nil
 }
 
 func ensureSupabaseRealtimePublication(dbURL string) error {
@@ -428,17 +492,7 @@ func ensureSupabaseRealtimePublication(dbURL string) error {
 		return err
 	}
 
-	sql := `
-DO $$
-BEGIN
-    IF NOT EXISTS (
-        SELECT 1 FROM pg_publication WHERE pubname = 'supabase_realtime'
-    ) THEN
-        CREATE PUBLICATION supabase_realtime;
-    END IF;
-END $$;`
-
-	result, err := database.ExecuteSQL(opts, sql)
+	result, err := database.ExecuteSQL(opts, database.EnsureRealtimePublicationSQL)
 	if err != nil {
 		return err
 	}
@@ -456,40 +510,11 @@ END $$;`
 }
 
 func restoreOptionsFromDBURL(dbURL string) (database.RestoreOptions, error) {
-	opts := database.DefaultRestoreOptions()
-
-	parsed, err := url.Parse(dbURL)
+	conn, err := database.ParseConnectionURL(dbURL)
 	if err != nil {
-		return opts, fmt.Errorf("invalid db url: %w", err)
-	}
-	if parsed.Hostname() == "" {
-		return opts, fmt.Errorf("invalid db url: missing host")
-	}
-
-	opts.Host = parsed.Hostname()
-	opts.Database = strings.TrimPrefix(parsed.Path, "/")
-	if opts.Database == "" {
-		opts.Database = "postgres"
+		return database.DefaultRestoreOptions(), err
 	}
-
-	if parsed.Port() != "" {
-		port, err := strconv.Atoi(parsed.Port())
-		if err != nil {
-			return opts, fmt.Errorf("invalid db url port %q: %w", parsed.Port(), err)
-		}
-		opts.Port = port
-	}
-
-	if parsed.User != nil {
-		if username := parsed.User.Username(); username != "" {
-			opts.User = username
-		}
-		if password, ok := parsed.User.Password(); ok {
-			opts.Password = password
-		}
-	}
-
-	return opts, nil
+	return conn.RestoreOptions(), nil
 }
 
 // findPendingMigrations returns migrations that exist locally but aren't applied remotely.
@@ -508,22 +533,19 @@ func findPendingMigrations(local []string, applied map[string]bool) []string {
 	return pending
 }
 
-func runMigrateStatus(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runMigrateStatus(ctx *Context) error {
+	cfg := ctx.Config()
 	ui.Header("Migration Status")
 
 	// Get current git branch
-	gitBranch, err := git.CurrentBranch()
+	gitBranch, err := ctx.GitBranch()
 	if err != nil {
 		return err
 	}
 
 	// Apply override from config
 	overrideBranch := cfg.Supabase.OverrideBranch
-	client := supabase.NewClient()
+	client := ctx.Client()
 	info, err := client.GetBranchInfoWithOverride(gitBranch, overrideBranch)
 	if err != nil {
 		ui.Warning(fmt.Sprintf("Could not resolve Supabase branch: %v", err))
@@ -579,8 +601,8 @@ func runMigrateStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runMigrateNew(cmd *cobra.Command, args []string) error {
-	name := args[0]
+func runMigrateNew(ctx *Context) error {
+	name := ctx.Args[0]
 
 	ui.Infof("Creating migration: %s", name)
 
@@ -601,21 +623,15 @@ func runMigrateNew(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runMigrateHistory(cmd *cobra.Command, args []string) error {
-	if !RequireInit() {
-		return nil
-	}
-	cfg := config.LoadOrDefault()
+func runMigrateHistory(ctx *Context) error {
+	cfg := ctx.Config()
 
 	// Determine target branch
-	targetBranch := ""
-	if len(args) > 0 {
-		targetBranch = args[0]
-	} else {
+	targetBranch := ctx.Arg(0)
+	if targetBranch == "" {
 		var err error
-		targetBranch, err = git.CurrentBranch()
-		if err != nil {
-			return fmt.Errorf("failed to get current branch: %w", err)
+		if targetBranch, err = ctx.GitBranch(); err != nil {
+			return err
 		}
 	}
 
@@ -625,7 +641,7 @@ func runMigrateHistory(cmd *cobra.Command, args []string) error {
 	overrideBranch := cfg.Supabase.OverrideBranch
 
 	// Resolve Supabase branch
-	client := supabase.NewClient()
+	client := ctx.Client()
 	info, err := client.GetBranchInfoWithOverride(targetBranch, overrideBranch)
 	if err != nil {
 		return fmt.Errorf("could not resolve Supabase branch: %w", err)
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var migrateConflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "Check worktrees for conflicting migrations",
+	Long: `Scan the migrations directory of every worktree and report:
+
+  collision     two different migrations with the same timestamp
+  out-of-order  a migration not yet applied to the --against branch that is
+                older than the newest migration already applied there
+  parallel      two worktrees each adding new migrations; whichever merges
+                second may land out of order
+
+Nothing is changed. The command exits non-zero when collisions or
+out-of-order migrations are found, so it can gate CI. 'drift migrate push'
+runs the same check for the current worktree and warns before pushing.`,
+	Example: `  drift migrate conflicts
+  drift migrate conflicts --against prod`,
+	Args: cobra.NoArgs,
+	RunE: Run(runMigrateConflicts, RequireProject),
+}
+
+var migrateConflictsAgainstFlag string
+
+func init() {
+	migrateConflictsCmd.Flags().StringVar(&migrateConflictsAgainstFlag, "against", "dev", "Branch whose applied migrations define the order (prod, dev, or a branch name)")
+	migrateCmd.AddCommand(migrateConflictsCmd)
+}
+
+// Kinds of migration conflict.
+const (
+	conflictCollision  = "collision"
+	conflictOutOfOrder = "out-of-order"
+	conflictParallel   = "parallel"
+)
+
+// worktreeMigrations are the migration files checked out in one worktree.
+type worktreeMigrations struct {
+	Label   string
+	Path    string
+	Current bool
+	Files   []string
+}
+
+// migrationRef is a migration file in a worktree.
+type migrationRef struct {
+	Worktree string
+	File     string
+	Current  bool
+}
+
+// migrationConflict is one problem found across worktrees.
+type migrationConflict struct {
+	Kind    string
+	Message string
+	Refs    []migrationRef
+}
+
+// involvesCurrent reports whether the conflict touches the current worktree.
+func (c migrationConflict) involvesCurrent() bool {
+	for _, r := range c.Refs {
+		if r.Current {
+			return true
+		}
+	}
+	return false
+}
+
+func runMigrateConflicts(ctx *Context) error {
+	cfg := ctx.Config()
+
+	ui.Header("Migration Conflicts")
+
+	sets, err := collectWorktreeMigrations(cfg)
+	if err != nil {
+		return err
+	}
+	files := 0
+	for _, set := range sets {
+		files += len(set.Files)
+	}
+	ui.KeyValue("Worktrees", fmt.Sprintf("%d (%d migration files)", len(sets), files))
+
+	var applied map[string]bool
+	client := ctx.Client()
+	sp := ui.NewSpinner(fmt.Sprintf("Checking migrations applied to %s", migrateConflictsAgainstFlag))
+	sp.Start()
+	branch, err := resolveCompareBranch(client, migrateConflictsAgainstFlag)
+	if err == nil {
+		applied, err = getAppliedMigrations(branch.ProjectRef)
+	}
+	if err != nil {
+		sp.Fail("Could not check applied migrations")
+		ui.Warning(fmt.Sprintf("Skipping out-of-order check: %v", err))
+	} else {
+		sp.Stop()
+		ui.KeyValue("Against", fmt.Sprintf("%s (%d applied)", ui.Cyan(branch.Name), len(applied)))
+	}
+	ui.NewLine()
+
+	conflicts := findMigrationConflicts(sets, applied)
+	if len(conflicts) == 0 {
+		ui.Success("No migration conflicts across worktrees")
+		return nil
+	}
+	printMigrationConflicts(conflicts)
+
+	blocking := 0
+	for _, c := range conflicts {
+		if c.Kind != conflictParallel {
+			blocking++
+		}
+	}
+	if blocking > 0 {
+		return fmt.Errorf("%d migration conflict(s) found", blocking)
+	}
+	return nil
+}
+
+// warnMigrationConflicts prints conflicts that involve the current worktree.
+// It is advisory: errors scanning worktrees are ignored.
+func warnMigrationConflicts(cfg *config.Config, applied map[string]bool) {
+	sets, err := collectWorktreeMigrations(cfg)
+	if err != nil {
+		return
+	}
+
+	var relevant []migrationConflict
+	for _, c := range findMigrationConflicts(sets, applied) {
+		if c.involvesCurrent() {
+			relevant = append(relevant, c)
+		}
+	}
+	if len(relevant) == 0 {
+		return
+	}
+
+	ui.Warningf("%d migration conflict(s) with this worktree", len(relevant))
+	printMigrationConflicts(relevant)
+	ui.Info("Run 'drift migrate conflicts' for details across all worktrees")
+	ui.NewLine()
+}
+
+// collectWorktreeMigrations lists the migration files in every worktree of
+// the repository. Worktrees without a migrations directory are skipped.
+func collectWorktreeMigrations(cfg *config.Config) ([]worktreeMigrations, error) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	projectDir, err := filepath.Rel(repoRoot, cfg.ProjectRoot())
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsDir := migrationsDirFor(cfg)
+	var sets []worktreeMigrations
+	for _, wt := range worktrees {
+		if wt.IsBare {
+			continue
+		}
+		dir := migrationsDir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(wt.Path, projectDir, dir)
+		}
+		files, err := listMigrationFiles(dir)
+		if err != nil {
+			continue
+		}
+
+		label := wt.Branch
+		if label == "" || label == "(detached)" {
+			label = filepath.Base(wt.Path)
+		}
+		sets = append(sets, worktreeMigrations{
+			Label:   label,
+			Path:    wt.Path,
+			Current: wt.IsCurrent || wt.Path == repoRoot,
+			Files:   files,
+		})
+	}
+	return sets, nil
+}
+
+// findMigrationConflicts checks the worktrees' migrations against each other
+// and, when applied is known, against the migrations already applied
+// remotely. A file with the same name in several worktrees is the same
+// migration and never conflicts with itself.
+func findMigrationConflicts(sets []worktreeMigrations, applied map[string]bool) []migrationConflict {
+	refsByFile := make(map[string][]migrationRef)
+	for _, set := range sets {
+		for _, f := range set.Files {
+			refsByFile[f] = append(refsByFile[f], migrationRef{Worktree: set.Label, File: f, Current: set.Current})
+		}
+	}
+	files := make([]string, 0, len(refsByFile))
+	for f := range refsByFile {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	var conflicts []migrationConflict
+
+	// Different files sharing a timestamp collide on merge.
+	byTimestamp := make(map[string][]string)
+	for _, f := range files {
+		ts := migrationTimestampFromFilename(f)
+		byTimestamp[ts] = append(byTimestamp[ts], f)
+	}
+	for _, f := range files {
+		ts := migrationTimestampFromFilename(f)
+		same := byTimestamp[ts]
+		if len(same) < 2 || same[0] != f {
+			continue
+		}
+		var refs []migrationRef
+		for _, name := range same {
+			refs = append(refs, refsByFile[name]...)
+		}
+		conflicts = append(conflicts, migrationConflict{
+			Kind:    conflictCollision,
+			Message: fmt.Sprintf("%d migrations share timestamp %s", len(same), ts),
+			Refs:    refs,
+		})
+	}
+
+	// Unapplied migrations older than the newest applied one are rejected
+	// by 'supabase db push' without --include-all.
+	latestApplied := ""
+	for ts := range applied {
+		if ts > latestApplied {
+			latestApplied = ts
+		}
+	}
+	if latestApplied != "" {
+		for _, f := range files {
+			ts := migrationTimestampFromFilename(f)
+			if applied[ts] || ts >= latestApplied {
+				continue
+			}
+			conflicts = append(conflicts, migrationConflict{
+				Kind:    conflictOutOfOrder,
+				Message: fmt.Sprintf("%s is not applied but older than the newest applied migration %s", ts, latestApplied),
+				Refs:    refsByFile[f],
+			})
+		}
+	}
+
+	// Worktrees adding their own migrations in parallel depend on merge order.
+	type owned struct {
+		set   worktreeMigrations
+		files []string
+	}
+	var adders []owned
+	for _, set := range sets {
+		var own []string
+		for _, f := range set.Files {
+			if len(refsByFile[f]) == 1 && !applied[migrationTimestampFromFilename(f)] {
+				own = append(own, f)
+			}
+		}
+		if len(own) > 0 {
+			adders = append(adders, owned{set: set, files: own})
+		}
+	}
+	for i := 0; i < len(adders); i++ {
+		for j := i + 1; j < len(adders); j++ {
+			a, b := adders[i], adders[j]
+			aFirst, aLast := migrationTimestampFromFilename(a.files[0]), migrationTimestampFromFilename(a.files[len(a.files)-1])
+			bFirst, bLast := migrationTimestampFromFilename(b.files[0]), migrationTimestampFromFilename(b.files[len(b.files)-1])
+
+			var message string
+			switch {
+			case aLast < bFirst:
+				message = fmt.Sprintf("merge %s before %s, or %s's migrations land out of order", a.set.Label, b.set.Label, a.set.Label)
+			case bLast < aFirst:
+				message = fmt.Sprintf("merge %s before %s, or %s's migrations land out of order", b.set.Label, a.set.Label, b.set.Label)
+			default:
+				message = fmt.Sprintf("%s and %s add interleaved migrations; whichever merges second must re-timestamp", a.set.Label, b.set.Label)
+			}
+
+			var refs []migrationRef
+			for _, f := range a.files {
+				refs = append(refs, migrationRef{Worktree: a.set.Label, File: f, Current: a.set.Current})
+			}
+			for _, f := range b.files {
+				refs = append(refs, migrationRef{Worktree: b.set.Label, File: f, Current: b.set.Current})
+			}
+			conflicts = append(conflicts, migrationConflict{Kind: conflictParallel, Message: message, Refs: refs})
+		}
+	}
+
+	return conflicts
+}
+
+func printMigrationConflicts(conflicts []migrationConflict) {
+	for _, c := range conflicts {
+		label := ui.Yellow(c.Kind)
+		if c.Kind != conflictParallel {
+			label = ui.Red(c.Kind)
+		}
+		fmt.Printf("  %s  %s\n", label, c.Message)
+		for _, r := range c.Refs {
+			worktree := r.Worktree
+			if r.Current {
+				worktree += " (current)"
+			}
+			fmt.Printf("      %s  %s\n", ui.Dim(fmt.Sprintf("%-28s", worktree)), r.File)
+		}
+	}
+	ui.NewLine()
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindMigrationConflicts(t *testing.T) {
+	shared := []string{"20260101000000_init.sql", "20260201000000_profiles.sql"}
+	with := func(extra ...string) []string {
+		return append(append([]string{}, shared...), extra...)
+	}
+
+	tests := []struct {
+		name    string
+		sets    []worktreeMigrations
+		applied map[string]bool
+		want    []string // "kind: message substring"
+	}{
+		{
+			name: "shared history only",
+			sets: []worktreeMigrations{
+				{Label: "main", Files: shared},
+				{Label: "feature-a", Files: shared},
+			},
+			applied: map[string]bool{"20260101000000": true, "20260201000000": true},
+		},
+		{
+			name: "timestamp collision",
+			sets: []worktreeMigrations{
+				{Label: "feature-a", Files: with("20260301000000_add_plans.sql")},
+				{Label: "feature-b", Files: with("20260301000000_add_teams.sql")},
+			},
+			want: []string{"collision: 2 migrations share timestamp 20260301000000", "parallel: feature-a and feature-b add interleaved"},
+		},
+		{
+			name: "out of order against applied",
+			sets: []worktreeMigrations{
+				{Label: "feature-a", Files: with("20260115000000_late.sql")},
+			},
+			applied: map[string]bool{"20260101000000": true, "20260201000000": true},
+			want:    []string{"out-of-order: 20260115000000 is not applied but older than the newest applied migration 20260201000000"},
+		},
+		{
+			name: "parallel with a safe order",
+			sets: []worktreeMigrations{
+				{Label: "feature-b", Files: with("20260305000000_b.sql")},
+				{Label: "feature-a", Files: with("20260301000000_a.sql")},
+			},
+			applied: map[string]bool{"20260101000000": true, "20260201000000": true},
+			want:    []string{"parallel: merge feature-a before feature-b"},
+		},
+		{
+			name: "applied migrations are not parallel work",
+			sets: []worktreeMigrations{
+				{Label: "main", Files: with("20260301000000_a.sql")},
+				{Label: "feature-b", Files: with("20260305000000_b.sql")},
+			},
+			applied: map[string]bool{"20260101000000": true, "20260201000000": true, "20260301000000": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts := findMigrationConflicts(tt.sets, tt.applied)
+			if len(conflicts) != len(tt.want) {
+				t.Fatalf("findMigrationConflicts() = %+v, want %d conflict(s)", conflicts, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := conflicts[i].Kind + ": " + conflicts[i].Message
+				if !strings.HasPrefix(got, want) {
+					t.Errorf("conflict %d = %q, want prefix %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrationConflictInvolvesCurrent(t *testing.T) {
+	sets := []worktreeMigrations{
+		{Label: "feature-a", Current: true, Files: []string{"20260301000000_a.sql"}},
+		{Label: "feature-b", Files: []string{"20260301000000_b.sql"}},
+		{Label: "feature-c", Files: []string{"20260401000000_c.sql", "20260401000000_d.sql"}},
+	}
+
+	var current int
+	for _, c := range findMigrationConflicts(sets, nil) {
+		if c.involvesCurrent() {
+			current++
+		}
+	}
+	// The a/b collision plus a's parallel pairs with b and c.
+	if current != 3 {
+		t.Errorf("conflicts involving current = %d, want 3", current)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+func TestParseMigrationListRows_Golden(t *testing.T) {
+	output, err := os.ReadFile("testdata/migration_list.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := []string{
+		"20260101000000_init.sql",
+		"20260215035000_add_profiles.sql",
+		"20260215062000_add_preferences.sql",
+	}
+	index := buildMigrationFilenameIndex(local)
+
+	var got strings.Builder
+	for _, row := range parseMigrationListRows(string(output)) {
+		fmt.Fprintf(&got, "local=%q remote=%q applied_at=%q file=%q\n", row.Local, row.Remote, row.AppliedAt, migrationFileForRow(row, index))
+	}
+	testutil.Golden(t, "migration_list", []byte(got.String()))
+}
+
+func TestGetMigrationDetails_FakeCLI(t *testing.T) {
+	output, err := os.ReadFile("testdata/migration_list.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := testutil.NewFakeBin(t, "supabase",
+		testutil.Response{Args: "branches list", Stdout: `[
+  {"name": "main", "git_branch": "main", "project_ref": "prod1", "is_default": true},
+  {"name": "feature-migrations", "git_branch": "feature/migrations-fake", "project_ref": "feat1"}
+]`},
+		testutil.Response{Args: "branches get feature/migrations-fake", Stdout: `POSTGRES_URL="postgresql://postgres.feat1:pw@aws-1-us-east-2.pooler.supabase.com:6543/postgres"`},
+		testutil.Response{Args: "migration list", Stdout: string(output)},
+	)
+
+	details, err := getMigrationDetails("feat1")
+	if err != nil {
+		t.Fatalf("getMigrationDetails() error = %v", err)
+	}
+	want := map[string]string{
+		"20260101000000": "2026-01-01 00:00",
+		"20260215035000": "2026-02-15 03:50",
+	}
+	if len(details) != len(want) {
+		t.Errorf("getMigrationDetails() = %v, want %v", details, want)
+	}
+	for k, v := range want {
+		if details[k] != v {
+			t.Errorf("details[%s] = %q, want %q", k, details[k], v)
+		}
+	}
+
+	calls := fake.CallLines()
+	last := calls[len(calls)-1]
+	if !strings.HasPrefix(last, "migration list --db-url postgresql://postgres.feat1:pw@aws-1-us-east-2.pooler.supabase.com:5432/postgres") {
+		t.Errorf("migration list call = %q, want the session-mode pooler URL", last)
+	}
+}
@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// shadowBranchPrefix marks preview branches owned by 'drift migrate verify'.
+const shadowBranchPrefix = "drift-verify-"
+
+var migrateVerifyCmd = &cobra.Command{
+	Use:   "verify [branch]",
+	Short: "Dry-run pending migrations against a shadow branch",
+	Long: `Apply pending migrations to a disposable Supabase branch before pushing
+them to the real one.
+
+The shadow branch (` + shadowBranchPrefix + `<branch>) is created or reused, brought
+up to the migrations already applied on the target, optionally filled with
+the target's data, and then receives the pending migrations with the same
+'supabase db push' that 'drift migrate push' runs. Each --check SQL file is
+then run against it; a check fails on its first error, so use ASSERT or
+RAISE EXCEPTION for conditions.
+
+The shadow branch is deleted after a successful run unless --keep is set,
+and kept for inspection after a failure.`,
+	Example: `  drift migrate verify
+  drift migrate verify --with-data
+  drift migrate verify --check supabase/checks/rls.sql --check supabase/checks/counts.sql
+  drift migrate verify development --keep`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runMigrateVerify, RequireProject),
+}
+
+var (
+	migrateVerifyChecksFlag   []string
+	migrateVerifyWithDataFlag bool
+	migrateVerifyKeepFlag     bool
+	migrateVerifyTimeoutFlag  time.Duration
+)
+
+func init() {
+	migrateVerifyCmd.Flags().StringArrayVar(&migrateVerifyChecksFlag, "check", nil, "SQL file to run against the shadow branch after migrating (repeatable)")
+	migrateVerifyCmd.Flags().BoolVar(&migrateVerifyWithDataFlag, "with-data", false, "Copy the target's data into the shadow branch before migrating")
+	migrateVerifyCmd.Flags().BoolVar(&migrateVerifyKeepFlag, "keep", false, "Keep the shadow branch after a successful run")
+	migrateVerifyCmd.Flags().DurationVar(&migrateVerifyTimeoutFlag, "timeout", 10*time.Minute, "How long to wait for the shadow branch to become ready")
+
+	migrateCmd.AddCommand(migrateVerifyCmd)
+}
+
+func runMigrateVerify(ctx *Context) error {
+	cfg := ctx.Config()
+	if err := ensureSupabaseLinked(cfg); err != nil {
+		return err
+	}
+	for _, check := range migrateVerifyChecksFlag {
+		if _, err := os.Stat(check); err != nil {
+			return fmt.Errorf("check file %s: %w", check, err)
+		}
+	}
+
+	targetBranch := ctx.Arg(0)
+	if targetBranch == "" {
+		var err error
+		if targetBranch, err = ctx.GitBranch(); err != nil {
+			return err
+		}
+	}
+
+	ui.Header("Verify Migrations")
+
+	client := ctx.Client()
+	sp := ui.NewSpinner("Resolving Supabase branch")
+	sp.Start()
+	target, err := client.GetBranchInfoWithOverride(targetBranch, cfg.Supabase.OverrideBranch)
+	if err != nil {
+		sp.Fail("Failed to resolve branch")
+		return err
+	}
+	sp.Stop()
+
+	shadowName := shadowBranchName(target.SupabaseBranch.Name)
+	ui.KeyValue("Target", fmt.Sprintf("%s (%s)", ui.Cyan(target.SupabaseBranch.Name), envColorString(string(target.Environment))))
+	ui.KeyValue("Shadow", ui.Cyan(shadowName))
+	ui.NewLine()
+
+	localMigrations, err := getLocalMigrations(cfg)
+	if err != nil {
+		return err
+	}
+	targetApplied, err := getAppliedMigrations(target.ProjectRef)
+	if err != nil {
+		return fmt.Errorf("could not read applied migrations on %s: %w", target.SupabaseBranch.Name, err)
+	}
+	pending := findPendingMigrations(localMigrations, targetApplied)
+	if len(pending) == 0 {
+		ui.Success("No pending migrations - nothing to verify")
+		return nil
+	}
+
+	ui.SubHeader(fmt.Sprintf("Pending Migrations (%d)", len(pending)))
+	for _, m := range pending {
+		ui.List(m)
+	}
+	ui.NewLine()
+
+	if migrateVerifyWithDataFlag && target.Environment == supabase.EnvProduction && !IsYes() {
+		ui.Warning("--with-data copies PRODUCTION data into the shadow branch")
+		confirmed, err := ui.PromptYesNo("Continue?", false)
+		if err != nil || !confirmed {
+			ui.Info("Cancelled")
+			return errs.Cancelled("verify migrations")
+		}
+	}
+
+	shadow, shadowApplied, err := prepareShadowBranch(client, shadowName, targetApplied)
+	if err != nil {
+		return err
+	}
+
+	shadowURL, err := getDbURLForProject(shadow.ProjectRef)
+	if err != nil {
+		return fmt.Errorf("could not get a database URL for %s: %w", shadowName, err)
+	}
+
+	// Replay what the target already has so the pending migrations run
+	// against the same schema they will meet on push.
+	if catchUp := findShadowCatchUp(localMigrations, targetApplied, shadowApplied); len(catchUp) > 0 {
+		sp = ui.NewSpinner(fmt.Sprintf("Applying %d migration(s) already on %s", len(catchUp), target.SupabaseBranch.Name))
+		sp.Start()
+		if err := pushMigrationSubset(cfg, shadowURL, catchUp); err != nil {
+			sp.Fail("Could not bring the shadow branch up to the target")
+			return keepShadowOnFailure(shadowName, err)
+		}
+		sp.Success(fmt.Sprintf("Shadow branch matches %s", target.SupabaseBranch.Name))
+	}
+
+	if migrateVerifyWithDataFlag {
+		if err := copyTargetDataToShadow(client, cfg, target, shadowURL); err != nil {
+			return keepShadowOnFailure(shadowName, err)
+		}
+	}
+
+	sp = ui.NewSpinner(fmt.Sprintf("Applying %d pending migration(s) to the shadow branch", len(pending)))
+	sp.Start()
+	result, err := shell.Run("supabase", "db", "push", "--db-url", shadowURL)
+	if pushErr := migrationPushError(result, err); pushErr != nil {
+		sp.Fail("Migrations failed on the shadow branch")
+		return keepShadowOnFailure(shadowName, errs.Validation(pushErr))
+	}
+	sp.Stop()
+
+	if applied, err := getAppliedMigrations(shadow.ProjectRef); err != nil {
+		ui.Warning(fmt.Sprintf("Could not confirm applied migrations: %v", err))
+	} else if missing := findPendingMigrations(pending, applied); len(missing) > 0 {
+		for _, m := range missing {
+			ui.List(ui.Yellow(m))
+		}
+		return keepShadowOnFailure(shadowName, errs.Validationf("%d migration(s) were not recorded on the shadow branch", len(missing)))
+	}
+	ui.Successf("Applied %d migration(s) to %s", len(pending), shadowName)
+
+	if len(migrateVerifyChecksFlag) > 0 {
+		ui.NewLine()
+		ui.SubHeader("Checks")
+		failed := 0
+		for _, check := range migrateVerifyChecksFlag {
+			if err := database.ApplySQLFile(shadowURL, check); err != nil {
+				ui.Errorf("%s: %v", check, err)
+				failed++
+				continue
+			}
+			ui.Successf("%s", check)
+		}
+		if failed > 0 {
+			return keepShadowOnFailure(shadowName, errs.Validationf("%d of %d check(s) failed", failed, len(migrateVerifyChecksFlag)))
+		}
+	}
+
+	ui.NewLine()
+	ui.Success(fmt.Sprintf("Pending migrations verified against %s", target.SupabaseBranch.Name))
+
+	if migrateVerifyKeepFlag {
+		ui.Infof("Keeping %s; delete it with 'drift branches delete %s'", shadowName, shadowName)
+	} else {
+		sp = ui.NewSpinner(fmt.Sprintf("Deleting %s", shadowName))
+		sp.Start()
+		if err := client.DeleteBranch(shadowName); err != nil {
+			sp.Fail("Could not delete the shadow branch")
+			ui.Warning(err.Error())
+		} else {
+			sp.Success(fmt.Sprintf("Deleted %s", shadowName))
+		}
+	}
+
+	ui.NewLine()
+	ui.SubHeader("Next Steps")
+	ui.List(fmt.Sprintf("drift migrate push %s", targetBranch))
+	return nil
+}
+
+// shadowBranchName returns the shadow branch used to verify migrations for
+// the Supabase branch target.
+func shadowBranchName(target string) string {
+	return shadowBranchPrefix + strings.ToLower(state.BranchKey(target))
+}
+
+// prepareShadowBranch creates name, or reuses it when it holds nothing the
+// target lacks, and waits for it to become ready. A shadow branch left with
+// migrations from an earlier run is recreated.
+func prepareShadowBranch(client *supabase.Client, name string, targetApplied map[string]bool) (*supabase.BranchInfo, map[string]bool, error) {
+	if existing, _ := client.GetBranch(name); existing != nil {
+		info, applied, err := readyShadowBranch(client, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(shadowExtraMigrations(applied, targetApplied)) == 0 {
+			ui.Infof("Reusing shadow branch %s", name)
+			return info, applied, nil
+		}
+
+		sp := ui.NewSpinner(fmt.Sprintf("Recreating %s (left over from an earlier run)", name))
+		sp.Start()
+		if err := client.DeleteBranch(name); err != nil {
+			sp.Fail("Failed to delete the stale shadow branch")
+			return nil, nil, err
+		}
+		sp.Stop()
+	}
+
+	sp := ui.NewSpinner(fmt.Sprintf("Creating shadow branch %s", name))
+	sp.Start()
+	if _, err := client.CreateBranch(name); err != nil {
+		sp.Fail("Failed to create shadow branch")
+		return nil, nil, err
+	}
+	sp.Success(fmt.Sprintf("Created shadow branch %s", name))
+
+	info, applied, err := readyShadowBranch(client, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if extra := shadowExtraMigrations(applied, targetApplied); len(extra) > 0 {
+		return nil, nil, keepShadowOnFailure(name, fmt.Errorf("new shadow branch already has migrations the target lacks (%s)", strings.Join(extra, ", ")))
+	}
+	return info, applied, nil
+}
+
+// readyShadowBranch waits for name and returns its connection info and
+// applied migrations.
+func readyShadowBranch(client *supabase.Client, name string) (*supabase.BranchInfo, map[string]bool, error) {
+	sp := ui.NewSpinner("Waiting for the shadow branch")
+	sp.Start()
+	if _, err := client.WaitForBranch(name, supabase.WaitOptions{Timeout: migrateVerifyTimeoutFlag, Interval: 10 * time.Second}); err != nil {
+		sp.Fail("Shadow branch did not become ready")
+		return nil, nil, err
+	}
+	sp.Success("Shadow branch ready")
+
+	info, err := ResolveSupabaseTarget(client, ResolveTargetOptions{GitBranch: name})
+	if err != nil {
+		return nil, nil, err
+	}
+	applied, err := getAppliedMigrations(info.ProjectRef)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read applied migrations on %s: %w", name, err)
+	}
+	return info, applied, nil
+}
+
+// shadowExtraMigrations returns the sorted versions applied on the shadow
+// branch but not on the target.
+func shadowExtraMigrations(shadowApplied, targetApplied map[string]bool) []string {
+	var extra []string
+	for version := range shadowApplied {
+		if !targetApplied[version] {
+			extra = append(extra, version)
+		}
+	}
+	sort.Strings(extra)
+	return extra
+}
+
+// findShadowCatchUp returns the local migrations applied on the target but
+// not yet on the shadow branch.
+func findShadowCatchUp(local []string, targetApplied, shadowApplied map[string]bool) []string {
+	var catchUp []string
+	for _, m := range local {
+		version := migrationTimestampFromFilename(m)
+		if targetApplied[version] && !shadowApplied[version] {
+			catchUp = append(catchUp, m)
+		}
+	}
+	return catchUp
+}
+
+// pushMigrationSubset pushes only migrations to dbURL by staging them in a
+// temporary supabase workdir.
+func pushMigrationSubset(cfg *config.Config, dbURL string, migrations []string) error {
+	workdir, err := os.MkdirTemp("", "drift-verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workdir)
+
+	if err := stageMigrationSubset(migrationsDirFor(cfg), workdir, migrations); err != nil {
+		return err
+	}
+	if data, err := os.ReadFile(filepath.Join("supabase", "config.toml")); err == nil {
+		if err := os.WriteFile(filepath.Join(workdir, "supabase", "config.toml"), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	result, err := shell.Run("supabase", "db", "push", "--db-url", dbURL, "--workdir", workdir)
+	return migrationPushError(result, err)
+}
+
+// stageMigrationSubset copies migrations from srcDir into
+// workdir/supabase/migrations.
+func stageMigrationSubset(srcDir, workdir string, migrations []string) error {
+	dst := filepath.Join(workdir, "supabase", "migrations")
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		data, err := os.ReadFile(filepath.Join(srcDir, m))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dst, m), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrationPushError turns a 'supabase db push' result into an error,
+// treating SQL errors reported on stderr as failures.
+func migrationPushError(result *shell.Result, err error) error {
+	var stderr string
+	if result != nil {
+		stderr = strings.TrimSpace(result.Stderr)
+	}
+	lower := strings.ToLower(stderr)
+	if err == nil && !strings.Contains(lower, "error:") && !strings.Contains(lower, "sqlstate") {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		l := strings.ToLower(line)
+		if strings.Contains(l, "error") || strings.Contains(l, "sqlstate") || strings.Contains(l, "at statement") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	if len(lines) > 0 {
+		return fmt.Errorf("migration push failed:\n%s", strings.Join(lines, "\n"))
+	}
+	if err != nil {
+		return fmt.Errorf("migration push failed: %w", err)
+	}
+	return fmt.Errorf("migration push failed: %s", stderr)
+}
+
+// copyTargetDataToShadow streams the target's data into the shadow branch
+// using the safe copy scope of 'drift db push --from-branch'.
+func copyTargetDataToShadow(client *supabase.Client, cfg *config.Config, target *supabase.BranchInfo, shadowURL string) error {
+	source, err := branchDumpOptions(client, cfg, target.SupabaseBranch, supabase.IsProductionBranch(target.SupabaseBranch))
+	if err != nil {
+		return err
+	}
+	opts, err := restoreOptionsFromDBURL(shadowURL)
+	if err != nil {
+		return err
+	}
+	opts.SingleTxn = true
+
+	sp := ui.NewSpinner(fmt.Sprintf("Copying data from %s", target.SupabaseBranch.Name))
+	sp.Start()
+	err = database.StreamRestore(database.StreamOptions{
+		Source: source,
+		Target: opts,
+		Progress: func(bytes int64, table string) {
+			msg := fmt.Sprintf("Copying data from %s (%.1f MB)", target.SupabaseBranch.Name, float64(bytes)/1024/1024)
+			if table != "" {
+				msg += " - " + table
+			}
+			sp.UpdateMessage(msg)
+		},
+	})
+	if err != nil {
+		sp.Fail("Data copy failed")
+		return err
+	}
+	sp.Success(fmt.Sprintf("Copied data from %s", target.SupabaseBranch.Name))
+	return nil
+}
+
+// keepShadowOnFailure tells the user the shadow branch was kept for
+// inspection and returns err.
+func keepShadowOnFailure(name string, err error) error {
+	ui.NewLine()
+	ui.Infof("Shadow branch %s was kept for inspection; delete it with 'drift branches delete %s'", name, name)
+	return err
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+func TestShadowBranchName(t *testing.T) {
+	tests := map[string]string{
+		"development": "drift-verify-development",
+		"feat/Login":  "drift-verify-feat_login",
+		"release-1.2": "drift-verify-release-1.2",
+	}
+	for target, want := range tests {
+		if got := shadowBranchName(target); got != want {
+			t.Errorf("shadowBranchName(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestShadowMigrationSets(t *testing.T) {
+	local := []string{"20240101000000_init.sql", "20240201000000_users.sql", "20240301000000_orders.sql"}
+	target := map[string]bool{"20240101000000": true, "20240201000000": true}
+
+	tests := []struct {
+		name        string
+		shadow      map[string]bool
+		wantCatchUp []string
+		wantExtra   []string
+	}{
+		{name: "fresh", shadow: map[string]bool{}, wantCatchUp: []string{"20240101000000_init.sql", "20240201000000_users.sql"}},
+		{name: "partly applied", shadow: map[string]bool{"20240101000000": true}, wantCatchUp: []string{"20240201000000_users.sql"}},
+		{name: "left over", shadow: map[string]bool{"20240101000000": true, "20240201000000": true, "20240301000000": true}, wantExtra: []string{"20240301000000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findShadowCatchUp(local, target, tt.shadow); !reflect.DeepEqual(got, tt.wantCatchUp) {
+				t.Errorf("findShadowCatchUp() = %v, want %v", got, tt.wantCatchUp)
+			}
+			if got := shadowExtraMigrations(tt.shadow, target); !reflect.DeepEqual(got, tt.wantExtra) {
+				t.Errorf("shadowExtraMigrations() = %v, want %v", got, tt.wantExtra)
+			}
+		})
+	}
+}
+
+func TestStageMigrationSubset(t *testing.T) {
+	src := t.TempDir()
+	for _, name := range []string{"1_a.sql", "2_b.sql"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte("select 1;"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workdir := t.TempDir()
+	if err := stageMigrationSubset(src, workdir, []string{"1_a.sql"}); err != nil {
+		t.Fatalf("stageMigrationSubset() error = %v", err)
+	}
+	got, err := listMigrationFiles(filepath.Join(workdir, "supabase", "migrations"))
+	if err != nil || !reflect.DeepEqual(got, []string{"1_a.sql"}) {
+		t.Errorf("staged migrations = %v, %v", got, err)
+	}
+}
+
+func TestMigrationPushError(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *shell.Result
+		err     error
+		wantErr bool
+	}{
+		{name: "clean", result: &shell.Result{Stderr: "Applying migration 20240301000000_orders.sql..."}},
+		{name: "sql error", result: &shell.Result{Stderr: "ERROR: column \"total\" contains null values (SQLSTATE 23502)"}, wantErr: true},
+		{name: "command failed", result: &shell.Result{}, err: errors.New("exit status 1"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := migrationPushError(tt.result, tt.err); (err != nil) != tt.wantErr {
+				t.Errorf("migrationPushError() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Create a new app with its own Supabase backend",
+	Long: `Create a new project from a starter template, wired to its own Supabase
+project.
+
+This command:
+  1. Clones the template into ./<name> and starts a fresh git history
+  2. Creates a Supabase project (or links one with --supabase-project)
+  3. Runs 'drift init' with the answers below
+  4. Generates env files, and creates a first worktree with --worktree
+
+Templates:
+  ios-supabase   SwiftUI app with Supabase auth (project type ios)
+  next-supabase  Next.js app with Supabase auth (project type web)
+
+Use --template-url to start from your own fork of a template. A new
+project's database password is generated unless --db-password is set and is
+printed once; keep it, since migrations need it as SUPABASE_DB_PASSWORD.`,
+	Example: `  drift new todo --template ios-supabase --bundle-id com.example.todo
+  drift new dashboard --template next-supabase --org-id abcdefghij --region eu-west-1
+  drift new dashboard --template next-supabase --supabase-project dashboard-staging
+  drift new todo --template ios-supabase --worktree feat/onboarding`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNew,
+}
+
+var (
+	newTemplateFlag        string
+	newTemplateURLFlag     string
+	newSupabaseProjectFlag string
+	newOrgIDFlag           string
+	newRegionFlag          string
+	newDBPasswordFlag      string
+	newSkipSupabaseFlag    bool
+	newWorktreeFlag        string
+	newBundleIDFlag        string
+	newTeamIDFlag          string
+	newTimeoutFlag         time.Duration
+)
+
+func init() {
+	newCmd.Flags().StringVarP(&newTemplateFlag, "template", "t", "", "Starter template: ios-supabase or next-supabase")
+	newCmd.Flags().StringVar(&newTemplateURLFlag, "template-url", "", "Clone this git URL instead of the template's default repository")
+	newCmd.Flags().StringVar(&newSupabaseProjectFlag, "supabase-project", "", "Link an existing Supabase project by name instead of creating one")
+	newCmd.Flags().StringVar(&newOrgIDFlag, "org-id", "", "Supabase organization for the new project (default: your only organization, or prompt)")
+	newCmd.Flags().StringVar(&newRegionFlag, "region", "us-east-1", "Region for the new Supabase project")
+	newCmd.Flags().StringVar(&newDBPasswordFlag, "db-password", "", "Database password for the new project (default: generated)")
+	newCmd.Flags().BoolVar(&newSkipSupabaseFlag, "skip-supabase", false, "Do not create or link a Supabase project")
+	newCmd.Flags().StringVar(&newWorktreeFlag, "worktree", "", "Also create a worktree for this branch")
+	newCmd.Flags().StringVar(&newBundleIDFlag, "bundle-id", "", "Bundle ID for Apple templates")
+	newCmd.Flags().StringVar(&newTeamIDFlag, "team-id", "", "APNs Team ID for Apple templates")
+	newCmd.Flags().DurationVar(&newTimeoutFlag, "timeout", 10*time.Minute, "How long to wait for a new Supabase project to come up")
+	newCmd.MarkFlagRequired("template")
+
+	rootCmd.AddCommand(newCmd)
+}
+
+// projectTemplate is a starter repository for 'drift new'.
+type projectTemplate struct {
+	Name        string
+	Repo        string
+	ProjectType string // answer for 'drift init --type'
+}
+
+var projectTemplates = []projectTemplate{
+	{Name: "ios-supabase", Repo: "https://github.com/undrift/template-ios-supabase.git", ProjectType: "ios"},
+	{Name: "next-supabase", Repo: "https://github.com/undrift/template-next-supabase.git", ProjectType: "web"},
+}
+
+func findProjectTemplate(name string) (projectTemplate, error) {
+	var names []string
+	for _, t := range projectTemplates {
+		if t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return projectTemplate{}, fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+func runNew(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	tmpl, err := findProjectTemplate(newTemplateFlag)
+	if err != nil {
+		return err
+	}
+	if newTemplateURLFlag != "" {
+		tmpl.Repo = newTemplateURLFlag
+	}
+
+	dir, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not find the drift executable: %w", err)
+	}
+
+	ui.Header("New Project")
+	ui.KeyValue("Name", ui.Cyan(filepath.Base(dir)))
+	ui.KeyValue("Template", fmt.Sprintf("%s (%s)", tmpl.Name, tmpl.Repo))
+	ui.KeyValue("Directory", dir)
+	ui.NewLine()
+
+	sp := ui.NewSpinner(fmt.Sprintf("Cloning %s", tmpl.Name))
+	sp.Start()
+	if err := cloneProjectTemplate(tmpl.Repo, dir); err != nil {
+		sp.Fail("Failed to clone template")
+		return err
+	}
+	sp.Success(fmt.Sprintf("Cloned %s", tmpl.Name))
+
+	var projectRef, dbPassword string
+	if !newSkipSupabaseFlag {
+		projectRef, dbPassword, err = newSupabaseProject(filepath.Base(dir))
+		if err != nil {
+			ui.Infof("The template is in %s; finish with 'cd %s && drift init'", dir, name)
+			return err
+		}
+	}
+
+	initArgs := []string{"init", "--yes", "--name", filepath.Base(dir), "--type", tmpl.ProjectType}
+	if projectRef != "" {
+		initArgs = append(initArgs, "--project-ref", projectRef)
+	} else {
+		initArgs = append(initArgs, "--skip-link")
+	}
+	if newBundleIDFlag != "" {
+		initArgs = append(initArgs, "--bundle-id", newBundleIDFlag)
+	}
+	if newTeamIDFlag != "" {
+		initArgs = append(initArgs, "--team-id", newTeamIDFlag)
+	}
+	env := map[string]string{}
+	if dbPassword != "" {
+		// 'supabase link' reads the password from here instead of prompting.
+		env["SUPABASE_DB_PASSWORD"] = dbPassword
+	}
+	if err := runNewStep(dir, env, "Running drift init", exe, initArgs...); err != nil {
+		return err
+	}
+
+	shell.RunInDir(dir, "git", "add", "-A")
+	if err := runNewStep(dir, nil, "Creating the initial commit", "git", "commit", "-q", "-m", fmt.Sprintf("Initial commit from %s template", tmpl.Name)); err != nil {
+		ui.Info("Commit the template yourself before creating worktrees")
+	}
+
+	if projectRef != "" {
+		if err := runNewStep(dir, env, "Generating env files", exe, "env", "setup", "--yes"); err != nil {
+			ui.Infof("Retry with 'cd %s && drift env setup'", name)
+		}
+	}
+	if newWorktreeFlag != "" {
+		if err := runNewStep(dir, env, fmt.Sprintf("Creating worktree %s", newWorktreeFlag), exe, "worktree", "create", newWorktreeFlag, "--yes"); err != nil {
+			ui.Infof("Retry with 'cd %s && drift worktree create %s'", name, newWorktreeFlag)
+		}
+	}
+
+	ui.NewLine()
+	ui.Success(fmt.Sprintf("Created %s", filepath.Base(dir)))
+	if dbPassword != "" && newDBPasswordFlag == "" {
+		ui.NewLine()
+		ui.KeyValue("Database Password", dbPassword)
+		ui.Warning("Store this password now; drift does not save it. Migrations need it as SUPABASE_DB_PASSWORD.")
+	}
+
+	ui.NewLine()
+	ui.SubHeader("Next Steps")
+	ui.NumberedList(1, fmt.Sprintf("cd %s", name))
+	if tmpl.ProjectType == "web" {
+		ui.NumberedList(2, "npm install && npm run dev")
+	} else {
+		ui.NumberedList(2, "drift open (or open the Xcode project) and run the app")
+	}
+	ui.NumberedList(3, "drift status")
+	return nil
+}
+
+// cloneProjectTemplate clones repo into dir and replaces its history with a
+// fresh repository on main.
+func cloneProjectTemplate(repo, dir string) error {
+	result, err := shell.Run("git", "clone", "--depth", "1", repo, dir)
+	if err != nil {
+		errMsg := err.Error()
+		if result != nil && result.Stderr != "" {
+			errMsg = strings.TrimSpace(result.Stderr)
+		}
+		return fmt.Errorf("git clone %s: %s", repo, errMsg)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		return err
+	}
+	if result, err := shell.RunInDir(dir, "git", "init", "-q", "-b", "main"); err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("git init failed in %s", dir)
+	}
+	return nil
+}
+
+// newSupabaseProject links --supabase-project or creates a project named
+// name, returning its ref and the database password when one was set.
+func newSupabaseProject(name string) (string, string, error) {
+	client := supabase.NewClient()
+	if newSupabaseProjectFlag != "" {
+		ref, _, err := findSupabaseProjectByName(client, newSupabaseProjectFlag)
+		return ref, newDBPasswordFlag, err
+	}
+
+	orgID, err := resolveNewProjectOrg(client)
+	if err != nil {
+		return "", "", err
+	}
+	password := newDBPasswordFlag
+	if password == "" {
+		password, err = generateDBPassword(24)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	sp := ui.NewSpinner(fmt.Sprintf("Creating Supabase project %s in %s", name, newRegionFlag))
+	sp.Start()
+	project, err := client.CreateProject(name, orgID, newRegionFlag, password)
+	if err != nil {
+		sp.Fail("Failed to create Supabase project")
+		return "", "", err
+	}
+	sp.Success(fmt.Sprintf("Created Supabase project %s (%s)", name, project.Ref))
+
+	sp = ui.NewSpinner("Waiting for the project to come up")
+	sp.Start()
+	deadline := time.Now().Add(newTimeoutFlag)
+	for {
+		p, err := client.FindProjectByRef(project.Ref)
+		if err == nil && p.Status == "ACTIVE_HEALTHY" {
+			break
+		}
+		if time.Now().After(deadline) {
+			sp.Fail("Project did not come up in time")
+			return "", "", fmt.Errorf("project %s is not ready after %s", project.Ref, newTimeoutFlag)
+		}
+		time.Sleep(10 * time.Second)
+	}
+	sp.Success("Project is up")
+	return project.Ref, password, nil
+}
+
+// resolveNewProjectOrg returns --org-id, the user's only organization, or
+// the one they pick.
+func resolveNewProjectOrg(client *supabase.Client) (string, error) {
+	if newOrgIDFlag != "" {
+		return newOrgIDFlag, nil
+	}
+	orgs, err := client.ListOrganizations()
+	if err != nil {
+		return "", err
+	}
+	switch len(orgs) {
+	case 0:
+		return "", fmt.Errorf("no Supabase organizations found")
+	case 1:
+		return orgs[0].ID, nil
+	}
+	if IsYes() {
+		return "", fmt.Errorf("you belong to %d organizations; pass --org-id", len(orgs))
+	}
+	options := make([]string, len(orgs))
+	for i, o := range orgs {
+		options[i] = fmt.Sprintf("%s (%s)", o.Name, o.ID)
+	}
+	idx, _, err := ui.PromptSelectWithIndex("Supabase organization", options)
+	if err != nil {
+		return "", err
+	}
+	return orgs[idx].ID, nil
+}
+
+// runNewStep runs a command in dir behind a spinner, showing its output
+// only when it fails.
+func runNewStep(dir string, env map[string]string, label, name string, args ...string) error {
+	sp := ui.NewSpinner(label)
+	sp.Start()
+	result, err := shell.RunInDirWithEnv(dir, env, name, args...)
+	if err == nil && result.ExitCode == 0 {
+		sp.Success(label)
+		return nil
+	}
+	sp.Fail(fmt.Sprintf("%s failed", label))
+	if result != nil {
+		if out := strings.TrimSpace(result.Stdout + "\n" + result.Stderr); out != "" {
+			fmt.Println(out)
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("exit code %d", result.ExitCode)
+	}
+	return fmt.Errorf("%s: %w", label, err)
+}
+
+// generateDBPassword returns a random alphanumeric password of length n.
+func generateDBPassword(n int) (string, error) {
+	const alphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	b := make([]byte, n)
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = alphabet[idx.Int64()]
+	}
+	return string(b), nil
+}
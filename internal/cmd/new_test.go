@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindProjectTemplate(t *testing.T) {
+	for _, name := range []string{"ios-supabase", "next-supabase"} {
+		if tmpl, err := findProjectTemplate(name); err != nil || tmpl.Repo == "" {
+			t.Errorf("findProjectTemplate(%q) = %+v, %v", name, tmpl, err)
+		}
+	}
+	if _, err := findProjectTemplate("rails"); err == nil || !strings.Contains(err.Error(), "ios-supabase") {
+		t.Errorf("findProjectTemplate(unknown) error = %v, want the available templates", err)
+	}
+}
+
+func TestGenerateDBPassword(t *testing.T) {
+	a, err := generateDBPassword(24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := generateDBPassword(24)
+	if len(a) != 24 || a == b {
+		t.Errorf("generateDBPassword() = %q, %q", a, b)
+	}
+	for _, c := range a {
+		if !strings.ContainsRune("abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789", c) {
+			t.Errorf("generateDBPassword() contains %q", c)
+		}
+	}
+}
+
+func TestCloneProjectTemplate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git(repo, "init", "-q")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("template"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(repo, "add", "-A")
+	git(repo, "commit", "-q", "-m", "template history")
+
+	dir := filepath.Join(t.TempDir(), "app")
+	if err := cloneProjectTemplate("file://"+repo, dir); err != nil {
+		t.Fatalf("cloneProjectTemplate() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README.md")); err != nil {
+		t.Errorf("template files not cloned: %v", err)
+	}
+	out, err := exec.Command("git", "-C", dir, "log", "--oneline").CombinedOutput()
+	if err == nil && strings.Contains(string(out), "template history") {
+		t.Errorf("template history was kept: %s", out)
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/notify"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/ui"
+)
+
+// notifyOperation records the result of an operation that started at start
+// in the audit log and posts it to notifications.slack_webhook /
+// notifications.webhook. Delivery failures only warn; they never change the
+// command's result.
+func notifyOperation(cfg *config.Config, operation, environment, branch string, start time.Time, opErr error) {
+	recordAudit(cfg, operation, environment, branch, start, opErr)
+
+	n := notify.Notifier{
+		SlackWebhook: cfg.Notifications.GetSlackWebhook(),
+		Webhook:      cfg.Notifications.GetWebhook(),
+	}
+	if !n.Enabled() {
+		return
+	}
+
+	event := notify.Event{
+		Project:     cfg.Project.Name,
+		Operation:   operation,
+		Environment: environment,
+		Branch:      branch,
+		Actor:       currentActor(),
+		Duration:    time.Since(start),
+		Err:         opErr,
+	}
+	if err := n.Send(event); err != nil {
+		ui.Warning(fmt.Sprintf("Could not send notification: %v", err))
+	}
+}
+
+// currentActor names the person running drift: git user.name, then $USER.
+func currentActor() string {
+	if name, _ := git.UserName(); name != "" {
+		return name
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "unknown"
+}
+
+// recordAudit appends the operation to .drift/audit.log. The log is best
+// effort, so failures are ignored.
+func recordAudit(cfg *config.Config, operation, environment, branch string, start time.Time, opErr error) {
+	entry := state.AuditEntry{
+		Time:        start,
+		Operation:   operation,
+		Environment: environment,
+		Branch:      branch,
+		Actor:       currentActor(),
+		Duration:    time.Since(start).Round(time.Millisecond).String(),
+	}
+	if opErr != nil {
+		entry.Error = opErr.Error()
+	}
+	_ = state.AppendAudit(cfg.ProjectRoot(), entry)
+}
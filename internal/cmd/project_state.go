@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// Structured project state shared by 'drift mcp serve' and 'drift serve'.
+// Collectors never prompt; ambiguous branch resolution is an error.
+
+// loadProjectState loads config for a caller that needs an initialized project.
+func loadProjectState() (*config.Config, error) {
+	if !git.IsGitRepository() {
+		return nil, fmt.Errorf("not in a git repository")
+	}
+	if !config.Exists() {
+		return nil, fmt.Errorf("no .drift.yaml found; run 'drift init'")
+	}
+	return config.LoadOrDefault(), nil
+}
+
+// resolveStateTarget resolves the Supabase target like
+// ResolveSupabaseTargetForCurrentBranch, but without the interactive fallback.
+func resolveStateTarget(cfg *config.Config, branch string) (*supabase.BranchInfo, error) {
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	opts := ResolveTargetOptions{
+		GitBranch:      gitBranch,
+		FallbackBranch: GetFallbackBranch(),
+	}
+	if opts.FallbackBranch == "" {
+		opts.FallbackBranch = cfg.Supabase.FallbackBranch
+	}
+	if branch != "" {
+		opts.GitBranch = branch
+		opts.DisallowProdSelection = true
+	} else if cfg.Supabase.OverrideBranch != "" {
+		opts.OverrideBranch = cfg.Supabase.OverrideBranch
+		opts.DisallowProdSelection = true
+	}
+	return ResolveSupabaseTarget(supabase.NewClient(), opts)
+}
+
+// envState is the resolved Supabase target.
+type envState struct {
+	GitBranch      string `json:"git_branch"`
+	SupabaseBranch string `json:"supabase_branch"`
+	Environment    string `json:"environment"`
+	ProjectRef     string `json:"project_ref"`
+	APIURL         string `json:"api_url,omitempty"`
+	IsOverride     bool   `json:"is_override,omitempty"`
+	OverrideFrom   string `json:"override_from,omitempty"`
+	IsFallback     bool   `json:"is_fallback,omitempty"`
+	Offline        bool   `json:"offline,omitempty"`
+}
+
+func newEnvState(info *supabase.BranchInfo) envState {
+	state := envState{
+		GitBranch:    info.GitBranch,
+		Environment:  string(info.Environment),
+		ProjectRef:   info.ProjectRef,
+		APIURL:       info.APIURL,
+		IsOverride:   info.IsOverride,
+		OverrideFrom: info.OverrideFrom,
+		IsFallback:   info.IsFallback,
+		Offline:      supabase.IsOffline(),
+	}
+	if info.SupabaseBranch != nil {
+		state.SupabaseBranch = info.SupabaseBranch.Name
+	}
+	return state
+}
+
+// collectEnvState resolves the Supabase target for branch.
+func collectEnvState(branch string) (*envState, error) {
+	cfg, err := loadProjectState()
+	if err != nil {
+		return nil, err
+	}
+	info, err := resolveStateTarget(cfg, branch)
+	if err != nil {
+		return nil, err
+	}
+	state := newEnvState(info)
+	return &state, nil
+}
+
+// worktreeState is one git worktree.
+type worktreeState struct {
+	Branch  string `json:"branch"`
+	Path    string `json:"path"`
+	Commit  string `json:"commit"`
+	Current bool   `json:"current,omitempty"`
+	Locked  bool   `json:"locked,omitempty"`
+	Changes int    `json:"uncommitted_changes"`
+}
+
+// collectWorktreeStates lists non-bare worktrees with their uncommitted change counts.
+func collectWorktreeStates() ([]worktreeState, error) {
+	if !git.IsGitRepository() {
+		return nil, fmt.Errorf("not in a git repository")
+	}
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]worktreeState, 0, len(worktrees))
+	for _, wt := range worktrees {
+		if wt.IsBare {
+			continue
+		}
+		changes, _ := git.GetUncommittedChanges(wt.Path)
+		states = append(states, worktreeState{
+			Branch:  wt.Branch,
+			Path:    wt.Path,
+			Commit:  wt.Commit,
+			Current: wt.IsCurrent,
+			Locked:  wt.IsLocked,
+			Changes: changes,
+		})
+	}
+	return states, nil
+}
+
+// functionState is a local Edge Function and its deployment.
+type functionState struct {
+	Name            string `json:"name"`
+	Deployed        bool   `json:"deployed"`
+	DeployedVersion string `json:"deployed_version,omitempty"`
+	Status          string `json:"status,omitempty"`
+	UpdatedAt       string `json:"updated_at,omitempty"`
+}
+
+// functionsState compares local Edge Functions with those deployed on the target.
+type functionsState struct {
+	Target      envState        `json:"target"`
+	Functions   []functionState `json:"functions"`
+	NotDeployed []string        `json:"not_deployed"` // local only
+	RemoteOnly  []string        `json:"remote_only"`  // deployed but not in the repo
+}
+
+// collectFunctionsState lists local functions against the branch's deployments.
+func collectFunctionsState(branch string) (*functionsState, error) {
+	cfg, err := loadProjectState()
+	if err != nil {
+		return nil, err
+	}
+	info, err := resolveStateTarget(cfg, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := listLocalFunctions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	deployed, err := supabase.NewClient().ListDeployedFunctions(info.ProjectRef)
+	if err != nil {
+		return nil, err
+	}
+	return compareFunctions(newEnvState(info), local, deployed), nil
+}
+
+// compareFunctions builds the function drift between local and deployed functions.
+func compareFunctions(target envState, local []supabase.Function, deployed []supabase.DeployedFunction) *functionsState {
+	state := &functionsState{
+		Target:      target,
+		Functions:   make([]functionState, 0, len(local)),
+		NotDeployed: []string{},
+		RemoteOnly:  []string{},
+	}
+
+	byName := make(map[string]supabase.DeployedFunction, len(deployed))
+	for _, d := range deployed {
+		byName[d.Name] = d
+	}
+	localNames := make(map[string]bool, len(local))
+	for _, fn := range local {
+		localNames[fn.Name] = true
+		entry := functionState{Name: fn.Name}
+		if d, ok := byName[fn.Name]; ok {
+			entry.Deployed = true
+			entry.DeployedVersion = d.Version
+			entry.Status = d.Status
+			entry.UpdatedAt = d.UpdatedAt
+		} else {
+			state.NotDeployed = append(state.NotDeployed, fn.Name)
+		}
+		state.Functions = append(state.Functions, entry)
+	}
+	for _, d := range deployed {
+		if !localNames[d.Name] {
+			state.RemoteOnly = append(state.RemoteOnly, d.Name)
+		}
+	}
+	sort.Strings(state.RemoteOnly)
+	return state
+}
+
+// migrationsState is local vs applied migrations on the target.
+type migrationsState struct {
+	Target       envState `json:"target"`
+	LocalCount   int      `json:"local_count"`
+	AppliedCount int      `json:"applied_count"`
+	Pending      []string `json:"pending"`
+}
+
+// collectMigrationsState lists migrations not yet applied to the branch's database.
+func collectMigrationsState(branch string) (*migrationsState, error) {
+	cfg, err := loadProjectState()
+	if err != nil {
+		return nil, err
+	}
+	info, err := resolveStateTarget(cfg, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := getLocalMigrations(cfg)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := getAppliedMigrations(info.ProjectRef)
+	if err != nil {
+		return nil, err
+	}
+	pending := findPendingMigrations(local, applied)
+	if pending == nil {
+		pending = []string{}
+	}
+
+	return &migrationsState{
+		Target:       newEnvState(info),
+		LocalCount:   len(local),
+		AppliedCount: len(applied),
+		Pending:      pending,
+	}, nil
+}
+
+// wdaSessionState is a per-device WebDriverAgent session.
+type wdaSessionState struct {
+	Name    string `json:"name"`
+	UDID    string `json:"udid"`
+	URL     string `json:"url"`
+	Running bool   `json:"running"`
+}
+
+// deviceState is WebDriverAgent and go-ios tunnel status.
+type deviceState struct {
+	WDAURL         string            `json:"wda_url"`
+	WDARunning     bool              `json:"wda_running"`
+	Tunnel         string            `json:"tunnel"` // healthy, stale, or not_running
+	PortForwarding bool              `json:"port_forwarding"`
+	Sessions       []wdaSessionState `json:"sessions"`
+}
+
+// collectDeviceState checks WDA, the tunnel, port forwarding, and tracked sessions.
+func collectDeviceState() *deviceState {
+	cfg := config.LoadOrDefault()
+	wdaPort := cfg.Device.WDAPort
+	if wdaPort == 0 {
+		wdaPort = 8100
+	}
+
+	state := &deviceState{
+		WDAURL:     fmt.Sprintf("http://localhost:%d", wdaPort),
+		WDARunning: checkWDAStatus(wdaPort),
+		Tunnel:     "not_running",
+		Sessions:   []wdaSessionState{},
+	}
+	if result, _ := shell.Run("pgrep", "-f", "ios tunnel"); result != nil && result.ExitCode == 0 {
+		state.Tunnel = "stale"
+		if list, _ := shell.Run("ios", "list"); list != nil && list.ExitCode == 0 && strings.Contains(list.Stdout, "deviceList") {
+			state.Tunnel = "healthy"
+		}
+	}
+	if result, _ := shell.Run("pgrep", "-f", fmt.Sprintf("ios forward %d", wdaPort)); result != nil && result.ExitCode == 0 {
+		state.PortForwarding = true
+	}
+
+	if sessions, err := loadDeviceSessions(); err == nil {
+		for _, s := range sessions.Sessions {
+			state.Sessions = append(state.Sessions, wdaSessionState{
+				Name:    s.Name,
+				UDID:    s.UDID,
+				URL:     fmt.Sprintf("http://localhost:%d", s.Port),
+				Running: checkWDAStatus(s.Port),
+			})
+		}
+	}
+	return state
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "Manage the registry of drift projects on this machine",
+	Long: `Register drift projects in ~/.drift/projects.yaml so commands can be run
+from anywhere with 'drift -p <name> ...'.
+
+'drift projects switch' selects a default project, used when drift is run
+outside of any project directory. Projects are registered automatically by
+'drift init'.`,
+	Example: `  drift projects add
+  drift projects list
+  drift -p myapp worktree list
+  drift projects switch myapp`,
+}
+
+var projectsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List registered projects with their current branch",
+	RunE:    runProjectsList,
+}
+
+var projectsAddCmd = &cobra.Command{
+	Use:   "add [name] [path]",
+	Short: "Register a project",
+	Long: `Register a drift project. The path defaults to the current project root
+and the name to project.name from its .drift.yaml.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runProjectsAdd,
+}
+
+var projectsRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Unregister a project",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runProjectsRemove,
+}
+
+var projectsSwitchCmd = &cobra.Command{
+	Use:   "switch [name]",
+	Short: "Set the default project used outside project directories",
+	Long: `Set the default project. When drift runs outside any project directory
+and -p is not given, commands run in the default project.
+
+Without a name, shows a picker. Use --clear to unset the default.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runProjectsSwitch,
+}
+
+var projectsSwitchClearFlag bool
+
+func init() {
+	projectsSwitchCmd.Flags().BoolVar(&projectsSwitchClearFlag, "clear", false, "Clear the default project")
+
+	projectsCmd.AddCommand(projectsListCmd)
+	projectsCmd.AddCommand(projectsAddCmd)
+	projectsCmd.AddCommand(projectsRemoveCmd)
+	projectsCmd.AddCommand(projectsSwitchCmd)
+	rootCmd.AddCommand(projectsCmd)
+}
+
+// enterProjectDir changes into the project selected with -p, or the default
+// project when drift is run outside any project. 'init', 'new' and 'projects'
+// always run where they were invoked.
+func enterProjectDir(cmd *cobra.Command) error {
+	if projectFlag == "" && (cmd.Name() == "init" || cmd == newCmd || isProjectsCommand(cmd)) {
+		return nil
+	}
+	if projectFlag == "" {
+		if _, err := config.FindConfigFile(); err == nil {
+			return nil
+		}
+	}
+
+	reg, err := config.LoadProjectsRegistry()
+	if err != nil {
+		if projectFlag != "" {
+			return err
+		}
+		return nil
+	}
+
+	var project *config.RegisteredProject
+	if projectFlag != "" {
+		project = reg.Find(projectFlag)
+		if project == nil {
+			return fmt.Errorf("unknown project '%s' (see 'drift projects list')", projectFlag)
+		}
+	} else {
+		project = reg.CurrentProject()
+		if project == nil {
+			return nil
+		}
+	}
+
+	if err := os.Chdir(project.Path); err != nil {
+		return fmt.Errorf("failed to enter project '%s': %w", project.Name, err)
+	}
+	return nil
+}
+
+func isProjectsCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == projectsCmd {
+			return true
+		}
+	}
+	return false
+}
+
+func runProjectsList(cmd *cobra.Command, args []string) error {
+	reg, err := config.LoadProjectsRegistry()
+	if err != nil {
+		return err
+	}
+
+	ui.Header("Projects")
+	if len(reg.Projects) == 0 {
+		ui.Info("No projects registered. Run 'drift projects add' inside a project.")
+		return nil
+	}
+
+	cwd, _ := os.Getwd()
+	for _, p := range reg.Projects {
+		marker := "  "
+		if strings.EqualFold(p.Name, reg.Current) {
+			marker = ui.Green("* ")
+		}
+
+		name := fmt.Sprintf("%-20s", p.Name)
+		if cwd == p.Path || strings.HasPrefix(cwd, p.Path+string(filepath.Separator)) {
+			name = ui.Cyan(name)
+		}
+		fmt.Printf("%s%s  %s  %s\n", marker, name, ui.Dim(fmt.Sprintf("%-40s", p.Path)), projectBranchState(p.Path))
+	}
+
+	if reg.Current != "" {
+		ui.NewLine()
+		ui.Infof("Default project: %s", reg.Current)
+	}
+	return nil
+}
+
+// projectBranchState describes the git branch and dirty state of a project directory.
+func projectBranchState(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return ui.Red("missing")
+	}
+	result, err := shell.RunInDir(path, "git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || result.ExitCode != 0 {
+		return ui.Dim("not a git repo")
+	}
+	branch := strings.TrimSpace(result.Stdout)
+
+	status, err := shell.RunInDir(path, "git", "status", "--porcelain")
+	if err == nil && status.ExitCode == 0 && strings.TrimSpace(status.Stdout) != "" {
+		return branch + ui.Yellow(" (dirty)")
+	}
+	return branch
+}
+
+func runProjectsAdd(cmd *cobra.Command, args []string) error {
+	var path string
+	if len(args) > 1 {
+		abs, err := filepath.Abs(args[1])
+		if err != nil {
+			return err
+		}
+		path = abs
+	} else {
+		configPath, err := config.FindConfigFile()
+		if err != nil {
+			return fmt.Errorf("not in a drift project; pass a path or run 'drift init' first")
+		}
+		path = filepath.Dir(configPath)
+	}
+
+	configPath, err := config.FindConfigFileFromDir(path)
+	if err != nil {
+		return fmt.Errorf("no .drift.yaml found at %s", path)
+	}
+	path = filepath.Dir(configPath)
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else if cfg, err := config.LoadFromPath(configPath); err == nil {
+		name = cfg.Project.Name
+	}
+	if name == "" {
+		name = filepath.Base(path)
+	}
+
+	if err := registerProject(name, path); err != nil {
+		return err
+	}
+	ui.Successf("Registered %s → %s", name, path)
+	return nil
+}
+
+// registerProject adds or updates a registry entry.
+func registerProject(name, path string) error {
+	reg, err := config.LoadProjectsRegistry()
+	if err != nil {
+		return err
+	}
+	if err := reg.Add(name, path); err != nil {
+		return err
+	}
+	return reg.Save()
+}
+
+func runProjectsRemove(cmd *cobra.Command, args []string) error {
+	reg, err := config.LoadProjectsRegistry()
+	if err != nil {
+		return err
+	}
+	if !reg.Remove(args[0]) {
+		return fmt.Errorf("unknown project '%s'", args[0])
+	}
+	if err := reg.Save(); err != nil {
+		return err
+	}
+	ui.Successf("Removed %s from the registry", args[0])
+	return nil
+}
+
+func runProjectsSwitch(cmd *cobra.Command, args []string) error {
+	reg, err := config.LoadProjectsRegistry()
+	if err != nil {
+		return err
+	}
+
+	if projectsSwitchClearFlag {
+		reg.Current = ""
+		if err := reg.Save(); err != nil {
+			return err
+		}
+		ui.Success("Cleared default project")
+		return nil
+	}
+
+	if len(reg.Projects) == 0 {
+		return fmt.Errorf("no projects registered; run 'drift projects add' inside a project")
+	}
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		options := make([]string, len(reg.Projects))
+		for i, p := range reg.Projects {
+			options[i] = p.Name
+		}
+		name, err = ui.PromptSelect("Default project", options)
+		if err != nil {
+			return err
+		}
+	}
+
+	project := reg.Find(name)
+	if project == nil {
+		return fmt.Errorf("unknown project '%s' (see 'drift projects list')", name)
+	}
+	reg.Current = project.Name
+	if err := reg.Save(); err != nil {
+		return err
+	}
+	ui.Successf("Default project: %s (%s)", project.Name, project.Path)
+	return nil
+}
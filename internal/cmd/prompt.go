@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+// defaultPromptFormat renders e.g. "feature/x → feat ↑2 env!".
+const defaultPromptFormat = `{{.GitBranch}} → {{envcolor .Env}}{{if .Pending}} {{yellow (printf "↑%d" .Pending)}}{{end}}{{if .EnvStale}} {{red "env!"}}{{end}}`
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a compact status segment for your shell prompt",
+	Long: `Print one short, colored line for embedding in PS1 or a starship custom
+module: the git branch, the Supabase environment it maps to, pending
+migrations, and whether the generated env file matches the branch.
+
+The prompt never calls the Supabase API or the database. Branches come from
+the offline cache ('drift cache warm') and applied migrations from the last
+'drift migrate' run, so it returns in milliseconds. Outside a drift project,
+or when nothing is cached, it prints what it knows and never fails.
+
+Customize the output with --format, a Go template with these fields:
+  .GitBranch  current git branch
+  .Branch     Supabase branch name
+  .Env        prod, dev, feat, or ? when unknown
+  .Pending    local migrations not applied to the branch (0 when unknown)
+  .EnvStale   env file missing or generated for another environment
+and the functions red, green, yellow, cyan, dim, and envcolor.`,
+	Example: `  drift prompt
+  drift prompt --format '{{.Branch}}:{{.Env}}'
+  drift prompt --format '{{envcolor .Env}}{{if .Pending}}+{{.Pending}}{{end}}'`,
+	Args: cobra.NoArgs,
+	RunE: runPrompt,
+}
+
+var promptFormatFlag string
+
+func init() {
+	promptCmd.Flags().StringVar(&promptFormatFlag, "format", defaultPromptFormat, "Go template for the segment")
+	rootCmd.AddCommand(promptCmd)
+}
+
+// promptState is the data available to the --format template.
+type promptState struct {
+	GitBranch string
+	Branch    string
+	Env       string
+	Pending   int
+	EnvStale  bool
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	// Prompts capture stdout, so decide on color from the flag and NO_COLOR
+	// rather than from whether stdout is a terminal.
+	color.NoColor = noColor || os.Getenv("NO_COLOR") != ""
+
+	if !git.IsGitRepository() || !config.Exists() {
+		return nil
+	}
+	supabase.SetOffline(true)
+
+	out, err := renderPrompt(promptFormatFlag, collectPromptState(config.LoadOrDefault()))
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
+
+// collectPromptState gathers the prompt fields from git, the offline cache,
+// and local files. Anything that cannot be determined is left empty.
+func collectPromptState(cfg *config.Config) promptState {
+	state := promptState{Env: "?"}
+	state.GitBranch, _ = git.CurrentBranch()
+
+	info, err := resolveStateTarget(cfg, "")
+	if err != nil || info == nil {
+		return state
+	}
+	state.Env = promptEnvLabel(info.Environment)
+	if info.SupabaseBranch != nil {
+		state.Branch = info.SupabaseBranch.Name
+	}
+
+	if envFile := checkConfigFileStatus(cfg, info); !envFile.exists || !envFile.isUpToDate {
+		state.EnvStale = true
+	}
+
+	var applied []string
+	if supabase.LoadCached(appliedMigrationsCacheKey(info.ProjectRef), &applied) {
+		if local, err := getLocalMigrations(cfg); err == nil {
+			state.Pending = countPendingMigrations(local, applied)
+		}
+	}
+	return state
+}
+
+// countPendingMigrations counts local migration files whose timestamp is
+// not in applied.
+func countPendingMigrations(local, applied []string) int {
+	appliedSet := make(map[string]bool, len(applied))
+	for _, timestamp := range applied {
+		appliedSet[timestamp] = true
+	}
+	return len(findPendingMigrations(local, appliedSet))
+}
+
+// promptEnvLabel shortens an environment for the prompt.
+func promptEnvLabel(env supabase.Environment) string {
+	switch env {
+	case supabase.EnvProduction:
+		return "prod"
+	case supabase.EnvDevelopment:
+		return "dev"
+	case supabase.EnvFeature:
+		return "feat"
+	default:
+		return "?"
+	}
+}
+
+// renderPrompt executes a --format template against state.
+func renderPrompt(format string, state promptState) (string, error) {
+	tmpl, err := template.New("prompt").Funcs(template.FuncMap{
+		"red":      func(s string) string { return ui.Red(s) },
+		"green":    func(s string) string { return ui.Green(s) },
+		"yellow":   func(s string) string { return ui.Yellow(s) },
+		"cyan":     func(s string) string { return ui.Cyan(s) },
+		"dim":      func(s string) string { return ui.Dim(s) },
+		"envcolor": promptEnvColor,
+	}).Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("invalid --format: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, state); err != nil {
+		return "", fmt.Errorf("invalid --format: %w", err)
+	}
+	return b.String(), nil
+}
+
+// promptEnvColor colors an environment label like envColorString, dimming
+// unknown environments.
+func promptEnvColor(label string) string {
+	switch label {
+	case "prod":
+		return ui.Red(label)
+	case "dev":
+		return ui.Yellow(label)
+	case "?", "":
+		return ui.Dim(label)
+	default:
+		return ui.Green(label)
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	tests := []struct {
+		name   string
+		format string
+		state  promptState
+		want   string
+	}{
+		{
+			name:   "clean feature branch",
+			format: defaultPromptFormat,
+			state:  promptState{GitBranch: "feature/x", Branch: "feature-x", Env: "feat"},
+			want:   "feature/x → feat",
+		},
+		{
+			name:   "pending and stale",
+			format: defaultPromptFormat,
+			state:  promptState{GitBranch: "main", Env: "prod", Pending: 2, EnvStale: true},
+			want:   "main → prod ↑2 env!",
+		},
+		{
+			name:   "unknown target",
+			format: defaultPromptFormat,
+			state:  promptState{GitBranch: "scratch", Env: "?"},
+			want:   "scratch → ?",
+		},
+		{
+			name:   "custom format",
+			format: "{{.Branch}}:{{.Env}}{{if .Pending}}+{{.Pending}}{{end}}",
+			state:  promptState{Branch: "development", Env: "dev", Pending: 1},
+			want:   "development:dev+1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderPrompt(tt.format, tt.state)
+			if err != nil {
+				t.Fatalf("renderPrompt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := renderPrompt("{{.Nope}}", promptState{}); err == nil {
+		t.Error("renderPrompt() with unknown field: expected error")
+	}
+	if _, err := renderPrompt("{{", promptState{}); err == nil {
+		t.Error("renderPrompt() with bad template: expected error")
+	}
+}
+
+func TestCountPendingMigrations(t *testing.T) {
+	local := []string{"20260101000000_init.sql", "20260201000000_add_plans.sql", "20260301000000_add_flags.sql"}
+
+	tests := []struct {
+		name    string
+		applied []string
+		want    int
+	}{
+		{"all applied", []string{"20260101000000", "20260201000000", "20260301000000"}, 0},
+		{"one pending", []string{"20260101000000", "20260201000000"}, 1},
+		{"none applied", nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countPendingMigrations(local, tt.applied); got != tt.want {
+				t.Errorf("countPendingMigrations() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptEnvLabel(t *testing.T) {
+	tests := map[supabase.Environment]string{
+		supabase.EnvProduction:  "prod",
+		supabase.EnvDevelopment: "dev",
+		supabase.EnvFeature:     "feat",
+		"":                      "?",
+	}
+	for env, want := range tests {
+		if got := promptEnvLabel(env); got != want {
+			t.Errorf("promptEnvLabel(%q) = %q, want %q", env, got, want)
+		}
+	}
+}
@@ -26,11 +26,15 @@ func ConfirmProductionOperation(env supabase.Environment, operation string) (boo
 	return confirmOperation(env, operation, ProtectionStandard)
 }
 
-// ConfirmDeploymentOperation applies environment-aware confirmations for deployment-style changes:
+// ConfirmDeploymentOperation applies environment-aware confirmations for deployment-style changes
+// (and refuses them outright in review mode):
 // - production/protected branches: strict confirmation
 // - development: standard confirmation
 // - feature branches: no prompt
 func ConfirmDeploymentOperation(info *supabase.BranchInfo, cfg *config.Config, operation string) (bool, error) {
+	if err := requireNotReviewMode(cfg, operation); err != nil {
+		return false, err
+	}
 	if IsYes() {
 		return true, nil
 	}
@@ -40,7 +44,7 @@ func ConfirmDeploymentOperation(info *supabase.BranchInfo, cfg *config.Config, o
 
 	protected := info.Environment == supabase.EnvProduction
 	if cfg != nil && info.SupabaseBranch != nil {
-		if cfg.IsProtectedBranch(info.SupabaseBranch.GitBranch) || cfg.IsProtectedBranch(info.SupabaseBranch.Name) || isProtectedBranchName(info.SupabaseBranch.GitBranch) || isProtectedBranchName(info.SupabaseBranch.Name) {
+		if cfg.IsProtectedBranch(info.SupabaseBranch.GitBranch) || cfg.IsProtectedBranch(info.SupabaseBranch.Name) || supabase.IsProtectedBranchName(info.SupabaseBranch.GitBranch) || supabase.IsProtectedBranchName(info.SupabaseBranch.Name) {
 			protected = true
 		}
 	}
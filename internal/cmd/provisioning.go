@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var (
+	provisionWaitFlag    bool
+	provisionTimeoutFlag time.Duration
+)
+
+// provisionPollInterval is how often --wait checks the branch status.
+const provisionPollInterval = 10 * time.Second
+
+// addProvisionWaitFlags registers --wait and --wait-timeout on commands that
+// need a fully provisioned branch.
+func addProvisionWaitFlags(cmds ...*cobra.Command) {
+	for _, c := range cmds {
+		c.Flags().BoolVar(&provisionWaitFlag, "wait", false, "Wait for a branch that is still provisioning to become healthy")
+		c.Flags().DurationVar(&provisionTimeoutFlag, "wait-timeout", 15*time.Minute, "How long --wait waits")
+	}
+}
+
+// ensureBranchProvisioned stops a command from running against a branch that
+// is still being set up. With --wait it polls until the branch is healthy;
+// otherwise it fails with a hint to use --wait.
+func ensureBranchProvisioned(client *supabase.Client, branch *supabase.Branch) error {
+	if branch == nil {
+		return nil
+	}
+	if !provisionWaitFlag {
+		if supabase.BranchProvisioning(branch.Status) {
+			return errs.Validationf("Supabase branch %s is still provisioning (status: %s); re-run with --wait", branch.Name, branch.Status)
+		}
+		return nil
+	}
+
+	start := time.Now()
+	label := fmt.Sprintf("Waiting for %s to provision", branch.Name)
+	sp := ui.NewSpinner(label)
+	sp.Start()
+	ready, err := client.WaitForBranch(branch.Name, supabase.WaitOptions{
+		Timeout:     provisionTimeoutFlag,
+		Interval:    provisionPollInterval,
+		CheckHealth: true,
+		Progress: func(status string) {
+			sp.UpdateMessage(fmt.Sprintf("%s (%s, %s)", label, status, time.Since(start).Round(time.Second)))
+		},
+	})
+	if err != nil {
+		sp.Fail(fmt.Sprintf("%s is not ready", branch.Name))
+		return err
+	}
+	sp.Success(fmt.Sprintf("%s is ready", branch.Name))
+	branch.Status = ready.Status
+	return nil
+}
+
+// noteBranchProvisioning is ensureBranchProvisioned for commands that are
+// still useful while a branch is being set up, such as writing its config:
+// without --wait it warns instead of failing.
+func noteBranchProvisioning(client *supabase.Client, branch *supabase.Branch) error {
+	if !provisionWaitFlag && branch != nil && supabase.BranchProvisioning(branch.Status) {
+		ui.Warningf("Supabase branch %s is still provisioning (status: %s); it may not accept connections yet (use --wait to wait for it)", branch.Name, branch.Status)
+		return nil
+	}
+	return ensureBranchProvisioned(client, branch)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestEnsureBranchProvisioned_WithoutWait(t *testing.T) {
+	provisionWaitFlag = false
+
+	err := ensureBranchProvisioned(nil, &supabase.Branch{Name: "feature-x", Status: "RUNNING_MIGRATIONS"})
+	if err == nil || !strings.Contains(err.Error(), "--wait") {
+		t.Fatalf("ensureBranchProvisioned(provisioning) = %v, want a hint to use --wait", err)
+	}
+	if code := errs.ExitCode(err); code != 6 {
+		t.Errorf("exit code = %d, want 6", code)
+	}
+
+	for _, status := range []string{"MIGRATIONS_PASSED", "FUNCTIONS_DEPLOYED", ""} {
+		if err := ensureBranchProvisioned(nil, &supabase.Branch{Name: "dev", Status: status}); err != nil {
+			t.Errorf("ensureBranchProvisioned(%q) = %v, want nil", status, err)
+		}
+	}
+	if err := ensureBranchProvisioned(nil, nil); err != nil {
+		t.Errorf("ensureBranchProvisioned(nil) = %v", err)
+	}
+}
+
+func TestNoteBranchProvisioning_WarnsWithoutWait(t *testing.T) {
+	provisionWaitFlag = false
+
+	if err := noteBranchProvisioning(nil, &supabase.Branch{Name: "feature-x", Status: "RUNNING_MIGRATIONS"}); err != nil {
+		t.Errorf("noteBranchProvisioning(provisioning) = %v, want a warning only", err)
+	}
+	if err := noteBranchProvisioning(nil, nil); err != nil {
+		t.Errorf("noteBranchProvisioning(nil) = %v", err)
+	}
+}
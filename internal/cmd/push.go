@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Push notification testing helpers",
+}
+
+var pushTokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "Find recently registered APNs device tokens",
+	Long: `List APNs device tokens recently registered against the resolved Supabase
+branch, pick one, and copy it to the clipboard.
+
+Tokens are read from one of two sources:
+  table  The table configured under apple.push_tokens (newest registrations first).
+         When environment_column is set, only tokens for the branch's APNs
+         environment (sandbox/development or production) are shown.
+  logs   Edge Function console output, scanned for 64-character hex tokens.
+
+The table source is used when apple.push_tokens.table is configured,
+otherwise logs. Use --wait to block until a new token is registered (e.g.
+after reinstalling the app on a device).
+
+Example config:
+  apple:
+    push_tokens:
+      table: public.device_tokens
+      token_column: token
+      created_column: created_at
+      environment_column: apns_environment`,
+	Example: `  drift push tokens                   # Pick from tokens registered in the last 24h
+  drift push tokens --wait            # Wait for the next registration
+  drift push tokens --source logs     # Scan Edge Function logs instead
+  drift push tokens --print           # Print the newest token (for scripts)`,
+	Args: cobra.NoArgs,
+	RunE: runPushTokens,
+}
+
+var (
+	pushTokensBranchFlag string
+	pushTokensSourceFlag string
+	pushTokensSinceFlag  time.Duration
+	pushTokensLimitFlag  int
+	pushTokensWaitFlag   bool
+	pushTokensPrintFlag  bool
+)
+
+func init() {
+	pushTokensCmd.Flags().StringVarP(&pushTokensBranchFlag, "branch", "b", "", "Target Supabase branch (default: current git branch)")
+	pushTokensCmd.Flags().StringVarP(&pushTokensSourceFlag, "source", "s", "", "Where to look for tokens: table or logs")
+	pushTokensCmd.Flags().DurationVar(&pushTokensSinceFlag, "since", 24*time.Hour, "How far back to look for registrations")
+	pushTokensCmd.Flags().IntVar(&pushTokensLimitFlag, "limit", 20, "Maximum tokens to list")
+	pushTokensCmd.Flags().BoolVarP(&pushTokensWaitFlag, "wait", "w", false, "Wait for a newly registered token")
+	pushTokensCmd.Flags().BoolVar(&pushTokensPrintFlag, "print", false, "Print the selected token only (newest unless interactive)")
+
+	pushCmd.AddCommand(pushTokensCmd)
+	rootCmd.AddCommand(pushCmd)
+}
+
+// pushTokenLister fetches tokens registered since a given time.
+type pushTokenLister func(since time.Time) ([]database.PushToken, error)
+
+func runPushTokens(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	if pushTokensSinceFlag <= 0 {
+		return fmt.Errorf("--since must be a positive duration")
+	}
+
+	cfg := config.LoadOrDefault()
+	client := supabase.NewClient()
+
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	sp := ui.NewSpinner("Resolving target environment")
+	sp.Start()
+	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, pushTokensBranchFlag)
+	sp.Stop()
+	if err != nil {
+		return err
+	}
+
+	source := strings.ToLower(pushTokensSourceFlag)
+	if source == "" {
+		source = "logs"
+		if cfg.Apple.PushTokens.Table != "" {
+			source = "table"
+		}
+	}
+	apnsEnv := apnsEnvironmentFor(cfg, info.Environment)
+
+	var list pushTokenLister
+	switch source {
+	case "table":
+		list, err = pushTokensFromTable(cfg, info, apnsEnv)
+	case "logs":
+		list, err = pushTokensFromLogs(info)
+	default:
+		return fmt.Errorf("unknown source '%s' (valid: table, logs)", pushTokensSourceFlag)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !pushTokensPrintFlag {
+		ui.Header("Push Tokens")
+		ui.KeyValue("Environment", envColorString(string(info.Environment)))
+		ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+		ui.KeyValue("APNs Environment", apnsEnv)
+		ui.KeyValue("Source", source)
+		ui.NewLine()
+	}
+
+	var tokens []database.PushToken
+	if pushTokensWaitFlag {
+		tokens, err = waitForPushToken(list)
+	} else {
+		tokens, err = list(time.Now().Add(-pushTokensSinceFlag))
+	}
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("no device tokens registered in the last %s", pushTokensSinceFlag)
+	}
+
+	token := tokens[0]
+	if len(tokens) > 1 && !pushTokensPrintFlag {
+		labels := make([]string, len(tokens))
+		for i, t := range tokens {
+			labels[i] = formatPushToken(t)
+		}
+		idx, _, err := ui.PromptSelectWithIndex("Device token", labels)
+		if err != nil {
+			return err
+		}
+		token = tokens[idx]
+	}
+
+	if pushTokensPrintFlag {
+		fmt.Println(token.Token)
+		return nil
+	}
+
+	ui.NewLine()
+	ui.KeyValue("Token", token.Token)
+	if err := copyToClipboard(token.Token); err != nil {
+		ui.Infof("Copy it manually (%v)", err)
+	} else {
+		ui.Success("Copied to clipboard")
+	}
+	return nil
+}
+
+// apnsEnvironmentFor returns the APNs environment used by a Supabase environment.
+func apnsEnvironmentFor(cfg *config.Config, env supabase.Environment) string {
+	if env == supabase.EnvProduction {
+		return "production"
+	}
+	return cfg.Apple.PushEnvironment
+}
+
+func pushTokensFromTable(cfg *config.Config, info *supabase.BranchInfo, apnsEnv string) (pushTokenLister, error) {
+	tc := cfg.Apple.PushTokens
+	if tc.Table == "" {
+		return nil, fmt.Errorf("no token table configured; set apple.push_tokens.table in .drift.yaml or use --source logs")
+	}
+
+	dbURL, err := getDbURLForProject(info.ProjectRef)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := restoreOptionsFromDBURL(dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	table := database.PushTokenTable{
+		Table:             tc.Table,
+		TokenColumn:       tc.TokenColumn,
+		CreatedColumn:     tc.CreatedColumn,
+		EnvironmentColumn: tc.EnvironmentColumn,
+	}
+	envValues := database.APNsEnvironmentValues(apnsEnv)
+
+	return func(since time.Time) ([]database.PushToken, error) {
+		return database.FetchRecentPushTokens(opts, table, envValues, since, pushTokensLimitFlag)
+	}, nil
+}
+
+func pushTokensFromLogs(info *supabase.BranchInfo) (pushTokenLister, error) {
+	mgmt, err := supabase.NewManagementClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(since time.Time) ([]database.PushToken, error) {
+		entries, err := mgmt.GetServiceLogs(info.ProjectRef, supabase.LogSourceFunctions, since.UTC(), time.Now().UTC(), 1000)
+		if err != nil {
+			return nil, err
+		}
+		return pushTokensFromLogEntries(entries, pushTokensLimitFlag), nil
+	}, nil
+}
+
+// pushTokensFromLogEntries extracts distinct tokens from log entries, newest first.
+func pushTokensFromLogEntries(entries []supabase.LogEntry, limit int) []database.PushToken {
+	entries = supabase.MergeLogEntries(entries)
+
+	var tokens []database.PushToken
+	seen := make(map[string]bool)
+	for i := len(entries) - 1; i >= 0; i-- {
+		for _, token := range supabase.ExtractAPNsTokens(entries[i].Message) {
+			if seen[token] {
+				continue
+			}
+			seen[token] = true
+			tokens = append(tokens, database.PushToken{
+				Token:     token,
+				CreatedAt: entries[i].Timestamp.Local().Format("2006-01-02 15:04:05"),
+			})
+			if limit > 0 && len(tokens) >= limit {
+				return tokens
+			}
+		}
+	}
+	return tokens
+}
+
+// waitForPushToken polls until a token not present at start is registered,
+// and returns the new tokens.
+func waitForPushToken(list pushTokenLister) ([]database.PushToken, error) {
+	start := time.Now()
+	existing, err := list(start.Add(-pushTokensSinceFlag))
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		known[t.Token] = true
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ui.Info("Waiting for a device to register (Ctrl+C to stop)...")
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for a device token")
+		case <-ticker.C:
+			// Overlap the window; log ingestion lags by a few seconds.
+			tokens, err := list(start.Add(-time.Minute))
+			if err != nil {
+				if IsVerbose() {
+					ui.Warningf("%v", err)
+				}
+				continue
+			}
+			var fresh []database.PushToken
+			for _, t := range tokens {
+				if !known[t.Token] {
+					fresh = append(fresh, t)
+				}
+			}
+			if len(fresh) > 0 {
+				return fresh, nil
+			}
+		}
+	}
+}
+
+func formatPushToken(t database.PushToken) string {
+	label := t.Token
+	if len(label) > 20 {
+		label = label[:8] + "…" + label[len(label)-8:]
+	}
+	if t.Environment != "" {
+		label += "  " + t.Environment
+	}
+	if t.CreatedAt != "" {
+		label += "  " + t.CreatedAt
+	}
+	return label
+}
+
+// copyToClipboard writes text to the system clipboard.
+func copyToClipboard(text string) error {
+	candidates := [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	if runtime.GOOS == "darwin" {
+		candidates = [][]string{{"pbcopy"}}
+	}
+	for _, c := range candidates {
+		if !shell.CommandExists(c[0]) {
+			continue
+		}
+		result, err := shell.RunWithInput(text, c[0], c[1:]...)
+		if err != nil {
+			return fmt.Errorf("%s failed: %s", c[0], strings.TrimSpace(result.Stderr))
+		}
+		return nil
+	}
+	return fmt.Errorf("no clipboard tool found")
+}
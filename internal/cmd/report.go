@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate an environment drift report across branches",
+	Long: `Check every Supabase branch and render a Markdown report of:
+
+  - migrations not yet applied to each branch
+  - Edge Functions not deployed (or deployed but no longer in the repo)
+  - secrets missing compared with supabase.secrets_to_push
+    (or with production when that list is empty)
+  - preview branches not updated in --stale-days
+  - local backups older than --backup-days
+
+The Markdown is meant for pasting into Slack or a PR; use --json for
+tooling. Checking production migrations needs PROD_PASSWORD (or a prompt).`,
+	Example: `  drift report
+  drift report --output report.md
+  drift report --json > report.json
+  drift report --stale-days 7 --backup-days 3`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
+}
+
+var (
+	reportJSONFlag       bool
+	reportOutputFlag     string
+	reportStaleDaysFlag  int
+	reportBackupDaysFlag int
+)
+
+func init() {
+	reportCmd.Flags().BoolVar(&reportJSONFlag, "json", false, "Output JSON instead of Markdown")
+	reportCmd.Flags().StringVarP(&reportOutputFlag, "output", "o", "", "Write the report to a file")
+	reportCmd.Flags().IntVar(&reportStaleDaysFlag, "stale-days", 14, "Flag preview branches not updated in this many days")
+	reportCmd.Flags().IntVar(&reportBackupDaysFlag, "backup-days", 7, "Flag local backups older than this many days")
+
+	rootCmd.AddCommand(reportCmd)
+}
+
+// envReport is the aggregated state of all branches.
+type envReport struct {
+	Project         string         `json:"project"`
+	GeneratedAt     time.Time      `json:"generated_at"`
+	LocalMigrations int            `json:"local_migrations"`
+	LocalFunctions  int            `json:"local_functions"`
+	Branches        []branchReport `json:"branches"`
+	StaleBranches   []staleBranch  `json:"stale_branches"`
+	Backups         []backupReport `json:"backups"`
+}
+
+// branchReport is the drift found on one Supabase branch.
+type branchReport struct {
+	Name                 string   `json:"name"`
+	GitBranch            string   `json:"git_branch"`
+	Environment          string   `json:"environment"`
+	ProjectRef           string   `json:"project_ref"`
+	Status               string   `json:"status,omitempty"`
+	UpdatedAt            string   `json:"updated_at,omitempty"`
+	PendingMigrations    []string `json:"pending_migrations"`
+	FunctionsNotDeployed []string `json:"functions_not_deployed"`
+	FunctionsRemoteOnly  []string `json:"functions_remote_only"`
+	MissingSecrets       []string `json:"missing_secrets"`
+	Errors               []string `json:"errors,omitempty"`
+}
+
+// issueCount returns the number of drift findings on the branch.
+func (b branchReport) issueCount() int {
+	return len(b.PendingMigrations) + len(b.FunctionsNotDeployed) + len(b.FunctionsRemoteOnly) + len(b.MissingSecrets)
+}
+
+// staleBranch is a preview branch that has not been updated recently.
+type staleBranch struct {
+	Name      string `json:"name"`
+	GitBranch string `json:"git_branch"`
+	UpdatedAt string `json:"updated_at"`
+	AgeDays   int    `json:"age_days"`
+}
+
+// backupReport is the newest local backup for an environment prefix.
+type backupReport struct {
+	Name    string  `json:"name"`
+	Path    string  `json:"path"`
+	AgeDays float64 `json:"age_days"`
+	Stale   bool    `json:"stale"`
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	if reportJSONFlag {
+		// JSON output must stay clean: never prompt, fail instead.
+		yesFlag = true
+	}
+
+	var sp *ui.Spinner
+	progress := func(msg string) {
+		if reportJSONFlag {
+			return
+		}
+		if sp == nil {
+			sp = ui.NewSpinner(msg)
+			sp.Start()
+			return
+		}
+		sp.UpdateMessage(msg)
+	}
+
+	report, err := collectEnvReport(cfg, time.Now(), progress)
+	if sp != nil {
+		sp.Stop()
+	}
+	if err != nil {
+		return err
+	}
+
+	var out string
+	if reportJSONFlag {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = string(data) + "\n"
+	} else {
+		out = renderReportMarkdown(report, reportStaleDaysFlag)
+	}
+
+	if reportOutputFlag != "" {
+		if err := os.WriteFile(reportOutputFlag, []byte(out), 0644); err != nil {
+			return err
+		}
+		if !reportJSONFlag {
+			ui.Successf("Report written to %s", reportOutputFlag)
+		}
+		return nil
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// collectEnvReport checks every Supabase branch. Per-branch failures are
+// recorded on the branch rather than aborting the report.
+func collectEnvReport(cfg *config.Config, now time.Time, progress func(string)) (*envReport, error) {
+	client := supabase.NewClient()
+
+	progress("Listing Supabase branches")
+	branches, err := client.GetBranches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		if reportBranchRank(branches[i]) != reportBranchRank(branches[j]) {
+			return reportBranchRank(branches[i]) < reportBranchRank(branches[j])
+		}
+		return branches[i].Name < branches[j].Name
+	})
+
+	localMigrations, err := getLocalMigrations(cfg)
+	if err != nil {
+		return nil, err
+	}
+	localFunctions, err := listLocalFunctions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &envReport{
+		Project:         cfg.Project.Name,
+		GeneratedAt:     now.UTC(),
+		LocalMigrations: len(localMigrations),
+		LocalFunctions:  len(localFunctions),
+		Branches:        []branchReport{},
+		StaleBranches:   []staleBranch{},
+		Backups:         []backupReport{},
+	}
+
+	secretsByRef := make(map[string][]string)
+	var prodSecrets []string
+	for _, b := range branches {
+		progress(fmt.Sprintf("Checking secrets on %s", b.Name))
+		names, err := listSecrets(b.ProjectRef)
+		if err == nil {
+			secretsByRef[b.ProjectRef] = names
+			if b.IsDefault {
+				prodSecrets = names
+			}
+		}
+	}
+	expectedSecrets := cfg.Supabase.SecretsToPush
+	if len(expectedSecrets) == 0 {
+		expectedSecrets = prodSecrets
+	}
+
+	for i := range branches {
+		b := &branches[i]
+		entry := branchReport{
+			Name:                 b.Name,
+			GitBranch:            b.GitBranch,
+			Environment:          string(environmentForBranch(b)),
+			ProjectRef:           b.ProjectRef,
+			Status:               b.Status,
+			UpdatedAt:            b.UpdatedAt,
+			PendingMigrations:    []string{},
+			FunctionsNotDeployed: []string{},
+			FunctionsRemoteOnly:  []string{},
+			MissingSecrets:       []string{},
+		}
+
+		progress(fmt.Sprintf("Checking migrations on %s", b.Name))
+		if applied, err := getAppliedMigrations(b.ProjectRef); err != nil {
+			entry.Errors = append(entry.Errors, fmt.Sprintf("migrations: %v", err))
+		} else if pending := findPendingMigrations(localMigrations, applied); len(pending) > 0 {
+			entry.PendingMigrations = pending
+		}
+
+		progress(fmt.Sprintf("Checking functions on %s", b.Name))
+		if deployed, err := client.ListDeployedFunctions(b.ProjectRef); err != nil {
+			entry.Errors = append(entry.Errors, fmt.Sprintf("functions: %v", err))
+		} else {
+			state := compareFunctions(envState{}, localFunctions, deployed)
+			entry.FunctionsNotDeployed = state.NotDeployed
+			entry.FunctionsRemoteOnly = state.RemoteOnly
+		}
+
+		if names, ok := secretsByRef[b.ProjectRef]; !ok {
+			entry.Errors = append(entry.Errors, "secrets: could not list secrets")
+		} else if !b.IsDefault || len(cfg.Supabase.SecretsToPush) > 0 {
+			entry.MissingSecrets = missingNames(expectedSecrets, names)
+		}
+
+		report.Branches = append(report.Branches, entry)
+
+		if stale, ok := staleBranchFor(*b, now, reportStaleDaysFlag); ok {
+			report.StaleBranches = append(report.StaleBranches, stale)
+		}
+	}
+
+	progress("Checking local backups")
+	report.Backups = collectBackupReports(cfg, now, reportBackupDaysFlag)
+	return report, nil
+}
+
+// reportBranchRank orders production, then development, then previews.
+func reportBranchRank(b supabase.Branch) int {
+	switch {
+	case b.IsDefault:
+		return 0
+	case b.Persistent:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// staleBranchFor reports a preview branch whose last update is older than days.
+func staleBranchFor(b supabase.Branch, now time.Time, days int) (staleBranch, bool) {
+	if b.IsDefault || b.Persistent || days <= 0 || b.UpdatedAt == "" {
+		return staleBranch{}, false
+	}
+	updated, err := time.Parse(time.RFC3339, b.UpdatedAt)
+	if err != nil {
+		return staleBranch{}, false
+	}
+	age := int(now.Sub(updated).Hours() / 24)
+	if age < days {
+		return staleBranch{}, false
+	}
+	return staleBranch{Name: b.Name, GitBranch: b.GitBranch, UpdatedAt: b.UpdatedAt, AgeDays: age}, true
+}
+
+// collectBackupReports returns the newest prod and dev backups with their age.
+func collectBackupReports(cfg *config.Config, now time.Time, maxDays int) []backupReport {
+	reports := []backupReport{}
+	backups, err := discoverLocalBackups(cfg)
+	if err != nil {
+		return reports
+	}
+	for _, prefix := range []string{"prod", "dev"} {
+		latest := suggestLocalBackup(backups, "", prefix)
+		if latest == nil || !strings.HasPrefix(strings.ToLower(latest.Name), prefix) {
+			continue
+		}
+		age := now.Sub(latest.ModTime).Hours() / 24
+		reports = append(reports, backupReport{
+			Name:    latest.Name,
+			Path:    backupDisplayPath(latest.Path, cfg.ProjectRoot()),
+			AgeDays: age,
+			Stale:   maxDays > 0 && age > float64(maxDays),
+		})
+	}
+	return reports
+}
+
+// missingNames returns the names in want that are not in have, sorted.
+func missingNames(want, have []string) []string {
+	present := make(map[string]bool, len(have))
+	for _, n := range have {
+		present[n] = true
+	}
+	missing := []string{}
+	for _, n := range want {
+		if !present[n] {
+			missing = append(missing, n)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// renderReportMarkdown renders the report for Slack or a PR description.
+func renderReportMarkdown(r *envReport, staleDays int) string {
+	var b strings.Builder
+
+	title := "Environment Report"
+	if r.Project != "" {
+		title += " - " + r.Project
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "_Generated %s · %d local migrations · %d local functions_\n\n",
+		r.GeneratedAt.Format("2006-01-02 15:04 MST"), r.LocalMigrations, r.LocalFunctions)
+
+	b.WriteString("## Branches\n\n")
+	b.WriteString("| Branch | Environment | Pending migrations | Functions drift | Missing secrets |\n")
+	b.WriteString("|--------|-------------|--------------------|-----------------|-----------------|\n")
+	for _, br := range r.Branches {
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n", br.Name, br.Environment,
+			reportCount(len(br.PendingMigrations), br.Errors, "migrations"),
+			reportCount(len(br.FunctionsNotDeployed)+len(br.FunctionsRemoteOnly), br.Errors, "functions"),
+			reportCount(len(br.MissingSecrets), br.Errors, "secrets"))
+	}
+	b.WriteString("\n")
+
+	var details strings.Builder
+	for _, br := range r.Branches {
+		if br.issueCount() == 0 && len(br.Errors) == 0 {
+			continue
+		}
+		fmt.Fprintf(&details, "### `%s` (%s)\n\n", br.Name, br.Environment)
+		writeReportList(&details, "Pending migrations", br.PendingMigrations)
+		writeReportList(&details, "Functions not deployed", br.FunctionsNotDeployed)
+		writeReportList(&details, "Functions deployed but not in the repo", br.FunctionsRemoteOnly)
+		writeReportList(&details, "Missing secrets", br.MissingSecrets)
+		for _, e := range br.Errors {
+			fmt.Fprintf(&details, "- Could not check %s\n", e)
+		}
+		details.WriteString("\n")
+	}
+	if details.Len() > 0 {
+		b.WriteString("## Drift\n\n")
+		b.WriteString(details.String())
+	} else {
+		b.WriteString("No drift found across branches.\n\n")
+	}
+
+	if len(r.StaleBranches) > 0 {
+		fmt.Fprintf(&b, "## Stale Preview Branches (%d+ days)\n\n", staleDays)
+		for _, s := range r.StaleBranches {
+			fmt.Fprintf(&b, "- `%s` - last updated %d days ago\n", s.Name, s.AgeDays)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Backups) > 0 {
+		b.WriteString("## Local Backups\n\n")
+		for _, bk := range r.Backups {
+			marker := ""
+			if bk.Stale {
+				marker = " **(stale)**"
+			}
+			fmt.Fprintf(&b, "- `%s` - %.1f days old%s\n", bk.Path, bk.AgeDays, marker)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// reportCount renders a finding count, or "?" when that check failed.
+func reportCount(n int, errors []string, check string) string {
+	for _, e := range errors {
+		if strings.HasPrefix(e, check+":") {
+			return "?"
+		}
+	}
+	if n == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("**%d**", n)
+}
+
+func writeReportList(b *strings.Builder, label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "`" + item + "`"
+	}
+	fmt.Fprintf(b, "- %s: %s\n", label, strings.Join(quoted, ", "))
+}
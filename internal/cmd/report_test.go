@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestStaleBranchFor(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -20).Format(time.RFC3339)
+	recent := now.AddDate(0, 0, -2).Format(time.RFC3339)
+
+	tests := []struct {
+		name   string
+		branch supabase.Branch
+		want   bool
+	}{
+		{"old preview", supabase.Branch{Name: "feature-x", UpdatedAt: old}, true},
+		{"recent preview", supabase.Branch{Name: "feature-y", UpdatedAt: recent}, false},
+		{"old production", supabase.Branch{Name: "main", IsDefault: true, UpdatedAt: old}, false},
+		{"old development", supabase.Branch{Name: "development", Persistent: true, UpdatedAt: old}, false},
+		{"unparseable", supabase.Branch{Name: "feature-z", UpdatedAt: "yesterday"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := staleBranchFor(tt.branch, now, 14)
+			if ok != tt.want {
+				t.Fatalf("staleBranchFor() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && got.AgeDays != 20 {
+				t.Errorf("AgeDays = %d, want 20", got.AgeDays)
+			}
+		})
+	}
+}
+
+func TestMissingNames(t *testing.T) {
+	got := missingNames([]string{"APNS_KEY_ID", "STRIPE_KEY", "APNS_TEAM_ID"}, []string{"APNS_KEY_ID"})
+	if want := []string{"APNS_TEAM_ID", "STRIPE_KEY"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("missingNames() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderReportMarkdown(t *testing.T) {
+	r := &envReport{
+		Project:     "App",
+		GeneratedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Branches: []branchReport{
+			{Name: "main", Environment: "Production"},
+			{Name: "feature-x", Environment: "Feature", PendingMigrations: []string{"20260301_add_plans.sql"}, MissingSecrets: []string{"STRIPE_KEY"}, Errors: []string{"functions: timeout"}},
+		},
+		StaleBranches: []staleBranch{{Name: "feature-old", AgeDays: 30}},
+		Backups:       []backupReport{{Path: "backups/prod.backup", AgeDays: 9, Stale: true}},
+	}
+
+	md := renderReportMarkdown(r, 14)
+	for _, want := range []string{
+		"# Environment Report - App",
+		"| `main` | Production | 0 | 0 | 0 |",
+		"| `feature-x` | Feature | **1** | ? | **1** |",
+		"- Pending migrations: `20260301_add_plans.sql`",
+		"- Could not check functions: timeout",
+		"- `feature-old` - last updated 30 days ago",
+		"- `backups/prod.backup` - 9.0 days old **(stale)**",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("report missing %q\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "### `main`") {
+		t.Error("report lists details for a branch without drift")
+	}
+
+	clean := renderReportMarkdown(&envReport{Branches: []branchReport{{Name: "main", Environment: "Production"}}}, 14)
+	if !strings.Contains(clean, "No drift found") {
+		t.Errorf("clean report missing summary line\n%s", clean)
+	}
+}
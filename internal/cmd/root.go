@@ -2,12 +2,17 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
 	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
 	"github.com/undrift/drift/pkg/shell"
 )
@@ -19,6 +24,17 @@ var (
 	noColor            bool
 	yesFlag            bool
 	fallbackBranchFlag string
+	projectFlag        string
+	offlineFlag        bool
+
+	// supabaseProjectFlag selects an entry of supabase.projects. It is
+	// registered on the commands that talk to a single Supabase project.
+	supabaseProjectFlag string
+
+	// requireInitErr is set when RequireInit fails, so commands that return
+	// nil after it still exit with ExitConfig. RequireInit has already shown
+	// the problem, so it is marked as reported.
+	requireInitErr error
 )
 
 // SetVersion sets the version string (called from main).
@@ -46,45 +62,132 @@ Get started:
   drift open          Open Supabase dashboard or related URLs`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := enterProjectDir(cmd); err != nil {
+			return err
+		}
+		config.SelectSupabaseProject(supabaseProjectSelection())
+		if err := initConfig(); err != nil {
+			return err
+		}
+		beginMetrics(cmd)
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// Pass the error to ExitCode for the process exit code.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if err == nil {
+		err = requireInitErr
+	}
+	finishMetrics(err)
+	return err
 }
 
-func init() {
-	cobra.OnInitialize(initConfig)
+// ExitCode returns the process exit code for an error from Execute (see
+// package errs). Management API responses are classified by status.
+func ExitCode(err error) int {
+	var apiErr *supabase.APIError
+	if errors.As(err, &apiErr) && errs.ExitCode(err) == errs.ExitFailure {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			return errs.ExitAuth
+		case apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500:
+			return errs.ExitNetwork
+		}
+	}
+	return errs.ExitCode(err)
+}
 
+func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .drift.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
 	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "skip confirmation prompts")
+	rootCmd.PersistentFlags().StringVarP(&projectFlag, "project", "p", "", "Run in a project from the registry (see 'drift projects')")
+	rootCmd.PersistentFlags().BoolVar(&offlineFlag, "offline", false, "Use cached Supabase data instead of calling the API")
 	rootCmd.PersistentFlags().StringVar(&fallbackBranchFlag, "fallback-branch", "", "Supabase branch to use when no exact branch match exists (non-production only)")
 
+	// --project already selects a registered repo, so the Supabase project
+	// flag gets its own name.
+	for _, c := range supabaseProjectCommands() {
+		c.PersistentFlags().StringVar(&supabaseProjectFlag, "supabase-project", "", "Supabase project from supabase.projects to use (default: the first)")
+	}
+
 	// Version flag
 	rootCmd.Version = version
 	rootCmd.SetVersionTemplate("drift {{.Version}}\n")
 }
 
-func initConfig() {
+// supabaseProjectCommands are the command trees that work against a single
+// Supabase project or its functions/migrations directories, and so take
+// --supabase-project.
+func supabaseProjectCommands() []*cobra.Command {
+	return []*cobra.Command{
+		envCmd, dbCmd, deployCmd, functionsCmd, migrateCmd, secretsCmd,
+		diffCmd, statusCmd, reportCmd, testCmd, branchesCmd, storageCmd,
+		authCmd,
+	}
+}
+
+// supabaseProjectSelection returns the supabase.projects entry to use:
+// --supabase-project, else DRIFT_SUPABASE_PROJECT, else "" for the first.
+func supabaseProjectSelection() string {
+	if supabaseProjectFlag != "" {
+		return supabaseProjectFlag
+	}
+	return os.Getenv("DRIFT_SUPABASE_PROJECT")
+}
+
+func initConfig() error {
 	if noColor {
 		os.Setenv("NO_COLOR", "1")
 	}
 
+	cfg, err := config.LoadWithLocal()
+	var projectErr *config.SupabaseProjectError
+	if errors.As(err, &projectErr) {
+		return errs.Config(err)
+	}
+	if err == nil && cfg.LocalDecryptError() != nil {
+		ui.Warning(cfg.LocalDecryptError().Error())
+	}
+	sharedConfig = nil
+	if err == nil {
+		sharedConfig = cfg
+	}
+
+	if err == nil {
+		ttl, _ := time.ParseDuration(cfg.Supabase.CacheTTL)
+		supabase.ConfigureCache(cfg.ProjectRoot(), ttl)
+		supabase.ConfigureEndpoints(endpointsFromConfig(cfg.Supabase.Endpoints))
+		if cfg.Supabase.Retries != nil {
+			supabase.ConfigureRetries(*cfg.Supabase.Retries)
+		}
+		// Single-project repos keep using the linked project.
+		if cfg.Supabase.ActiveProject != "" {
+			supabase.ConfigureProjectRef(cfg.Supabase.ProjectRef)
+		}
+	}
+	if offlineFlag || os.Getenv("DRIFT_OFFLINE") == "1" {
+		supabase.SetOffline(true)
+	}
+
 	// Check verbose from flag first
 	if verbose {
 		shell.SetVerbose(true)
-		return
+		return nil
 	}
 
 	// Check verbose from local config preferences
-	cfg, err := config.LoadWithLocal()
 	if err == nil && cfg.IsVerbose() {
 		shell.SetVerbose(true)
 		verbose = true // Also set the flag for IsVerbose() checks
 	}
+	return nil
 }
 
 // IsVerbose returns whether verbose mode is enabled.
@@ -123,12 +226,26 @@ func PrintVersion() {
 func RequireInit() bool {
 	if !git.IsGitRepository() {
 		ui.Error("Not in a git repository")
+		requireInitErr = errs.Reported(errs.Configf("not in a git repository"))
 		return false
 	}
 	if !config.Exists() {
 		ui.Warning("No .drift.yaml found")
 		ui.Info("Run 'drift init' to create one")
+		requireInitErr = errs.Reported(errs.Configf("no .drift.yaml found"))
 		return false
 	}
 	return true
 }
+
+// endpointsFromConfig converts supabase.endpoints into supabase.Endpoints.
+func endpointsFromConfig(e config.EndpointsConfig) supabase.Endpoints {
+	return supabase.Endpoints{
+		APIURL:        e.APIURL,
+		ManagementURL: e.ManagementAPIURL,
+		DatabaseHost:  e.DatabaseHost,
+		PoolerHost:    e.PoolerHost,
+		DashboardURL:  e.DashboardURL,
+		DefaultRegion: e.DefaultRegion,
+	}
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import "testing"
+
+func TestSupabaseProjectFlagRegistered(t *testing.T) {
+	for _, path := range [][]string{
+		{"migrate", "push"},
+		{"migrate", "status"},
+		{"secrets", "check"},
+		{"diff", "all"},
+		{"env", "setup"},
+		{"deploy", "functions"},
+	} {
+		c, _, err := rootCmd.Find(path)
+		if err != nil {
+			t.Fatalf("Find(%v) error = %v", path, err)
+		}
+		if c.Flags().Lookup("supabase-project") == nil && c.InheritedFlags().Lookup("supabase-project") == nil {
+			t.Errorf("%s does not accept --supabase-project", c.CommandPath())
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/envcrypt"
+	"github.com/undrift/drift/internal/secretref"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run -- <command> [args...]",
+	Short: "Run a command with the generated environment loaded",
+	Long: `Run a command with variables from the generated env file
+(.env.local for web projects, Config.xcconfig for Apple projects) exported
+into its environment.
+
+When encryption is enabled in .drift.yaml, drift-secret: references are
+decrypted from the configured backend just before the command starts, so
+plaintext secrets never need to be written to disk.
+
+Custom variables may also point into a secret manager; they are read with
+the manager's CLI when the command starts:
+
+  op://vault/item/field   1Password (op read)
+  vault:kv/path#key       HashiCorp Vault (vault kv get)`,
+	Example: `  drift run -- npm run dev
+  drift run -- npx prisma migrate deploy
+  drift run --env-file apps/web/.env.local -- node script.js`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRun,
+}
+
+var envResolveCmd = &cobra.Command{
+	Use:   "resolve <KEY>",
+	Short: "Print the decrypted value of a generated env variable",
+	Long: `Print the value of a variable from the generated env file, decrypting
+it if it is a drift-secret: reference or reading it from 1Password or Vault
+if it is an op:// or vault: reference.
+
+Intended for build scripts (e.g. an Xcode Run Script phase) that need a
+single secret without loading the whole environment.`,
+	Example: `  drift env resolve SUPABASE_SERVICE_ROLE_KEY
+  export DATABASE_URL="$(drift env resolve DATABASE_URL)"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEnvResolve,
+}
+
+var runEnvFileFlag string
+
+func init() {
+	runCmd.Flags().StringVar(&runEnvFileFlag, "env-file", "", "Env file to load (default: generated .env.local or xcconfig)")
+	rootCmd.AddCommand(runCmd)
+	envCmd.AddCommand(envResolveCmd)
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+
+	cfg := config.LoadOrDefault()
+	values, err := loadResolvedEnv(cfg, runEnvFileFlag)
+	if err != nil {
+		return err
+	}
+
+	child := exec.Command(args[0], args[1:]...)
+	child.Env = mergeEnv(os.Environ(), values)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	if err := child.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	return nil
+}
+
+func runEnvResolve(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+
+	cfg := config.LoadOrDefault()
+	path := generatedEnvPath(cfg)
+	values, err := readGeneratedEnv(path)
+	if err != nil {
+		return err
+	}
+
+	value, ok := values[args[0]]
+	if !ok {
+		return fmt.Errorf("%s is not set in %s", args[0], path)
+	}
+	if envcrypt.IsReference(value) {
+		store, err := envcrypt.NewStore(cfg)
+		if err != nil {
+			return err
+		}
+		if value, err = envcrypt.Resolve(store, value); err != nil {
+			return err
+		}
+	}
+	if value, err = secretref.Resolve(value); err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+// generatedEnvPath returns the env file drift generates for this project type.
+func generatedEnvPath(cfg *config.Config) string {
+	if cfg.Project.IsWebPlatform() {
+		return cfg.GetEnvLocalPath()
+	}
+	return cfg.GetXcconfigPath()
+}
+
+// readGeneratedEnv reads KEY=VALUE pairs from an env file, undoing xcconfig URL escaping.
+func readGeneratedEnv(path string) (map[string]string, error) {
+	if strings.HasSuffix(path, ".xcconfig") {
+		values, err := xcode.ReadXcconfig(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s (run 'drift env setup' first): %w", path, err)
+		}
+		for k, v := range values {
+			values[k] = strings.ReplaceAll(v, "/$()/", "//")
+		}
+		return values, nil
+	}
+
+	values, err := web.ReadEnvLocal(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s (run 'drift env setup' first): %w", path, err)
+	}
+	return values, nil
+}
+
+// loadResolvedEnv reads the env file, decrypts any drift-secret references,
+// and reads op:// and vault: references from their secret managers.
+func loadResolvedEnv(cfg *config.Config, path string) (map[string]string, error) {
+	if path == "" {
+		path = generatedEnvPath(cfg)
+	}
+	values, err := readGeneratedEnv(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Server-only secrets live in a separate file; load them too.
+	if cfg.Project.IsWebPlatform() && cfg.Web.ServiceRoleKey == web.ServiceRoleServerOnly {
+		if serverValues, err := web.ReadEnvLocal(cfg.GetServerEnvPath()); err == nil {
+			for k, v := range serverValues {
+				values[k] = v
+			}
+		}
+	}
+
+	resolved, err := envcrypt.ResolveAll(values, func() (envcrypt.Store, error) {
+		return envcrypt.NewStore(cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err = secretref.ResolveAll(resolved); err != nil {
+		return nil, err
+	}
+	if IsVerbose() {
+		keys := make([]string, 0, len(resolved))
+		for k := range resolved {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		ui.Infof("Loaded %d variables from %s: %s", len(keys), path, strings.Join(keys, ", "))
+	}
+	return resolved, nil
+}
+
+// mergeEnv overlays values onto a KEY=VALUE environment list.
+func mergeEnv(base []string, values map[string]string) []string {
+	merged := make([]string, 0, len(base)+len(values))
+	for _, kv := range base {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, override := values[key]; override {
+			continue
+		}
+		merged = append(merged, kv)
+	}
+	for k, v := range values {
+		merged = append(merged, k+"="+v)
+	}
+	return merged
+}
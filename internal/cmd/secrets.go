@@ -21,6 +21,7 @@ Commands:
   list  - List all secrets for a branch
   diff  - Compare secrets between two branches
   copy  - Copy secrets from one branch to another
+  check - Check that the secrets functions read are configured
 
 Secrets are environment variables available to Edge Functions.
 Common secrets include API keys, service tokens, and configuration values.`,
@@ -252,13 +253,10 @@ func runSecretsCopy(cmd *cobra.Command, args []string) error {
 	}
 
 	ui.Infof("Found %d secret(s) to copy:", len(sourceSecrets))
+	policy := maskPolicy(cfg)
 	for _, s := range sourceSecrets {
-		// Show name but mask value
-		maskedValue := "****"
-		if len(s.Value) > 4 {
-			maskedValue = s.Value[:2] + "****" + s.Value[len(s.Value)-2:]
-		}
-		ui.List(fmt.Sprintf("%s = %s", s.Name, maskedValue))
+		// Every function secret is masked, whatever its name
+		ui.List(fmt.Sprintf("%s = %s", s.Name, policy.Value(s.Value)))
 	}
 	ui.NewLine()
 
@@ -277,16 +275,11 @@ func runSecretsCopy(cmd *cobra.Command, args []string) error {
 	sp = ui.NewSpinner("Copying secrets to target")
 	sp.Start()
 
-	err = mgmtClient.SetSecrets(targetInfo.ProjectRef, sourceSecrets)
-	sp.Stop()
-
-	if err != nil {
-		ui.Error(fmt.Sprintf("Failed to copy secrets: %v", err))
-		return copySecretsManual(sourceInfo, targetInfo, sourceBranch, targetBranch)
+	report := mgmtClient.ApplySecrets(targetInfo.ProjectRef, sourceSecrets)
+	if err := finishSecretsSpinner(sp, report); err != nil {
+		return err
 	}
 
-	ui.Success(fmt.Sprintf("Copied %d secret(s) to %s", len(sourceSecrets), targetBranch))
-
 	// Next steps
 	ui.NewLine()
 	ui.SubHeader("Next Steps")
@@ -328,8 +321,8 @@ func copySecretsManual(sourceInfo, targetInfo *supabase.BranchInfo, sourceBranch
 
 	ui.NewLine()
 	ui.Info("2. Copy from Supabase Dashboard:")
-	ui.List(fmt.Sprintf("Source: https://supabase.com/dashboard/project/%s/settings/functions", sourceInfo.ProjectRef))
-	ui.List(fmt.Sprintf("Target: https://supabase.com/dashboard/project/%s/settings/functions", targetInfo.ProjectRef))
+	ui.List("Source: " + supabase.DashboardURL(sourceInfo.ProjectRef, "settings/functions"))
+	ui.List("Target: " + supabase.DashboardURL(targetInfo.ProjectRef, "settings/functions"))
 
 	return nil
 }
@@ -544,3 +537,34 @@ func runSecretsDiff(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// finishSecretsSpinner ends sp with the report's created/updated/unchanged
+// summary, lists each secret that changed or failed, and returns the
+// report's error.
+func finishSecretsSpinner(sp *ui.Spinner, report *supabase.SecretsReport) error {
+	summary := "Secrets: " + report.Summary()
+	if len(report.Failed()) > 0 {
+		sp.Fail(summary)
+	} else {
+		sp.Success(summary)
+	}
+
+	for _, r := range report.Results {
+		switch r.Status {
+		case supabase.SecretCreated:
+			ui.List(ui.Green("+ ") + r.Name)
+		case supabase.SecretUpdated, supabase.SecretSet:
+			ui.List(ui.Yellow("~ ") + r.Name)
+		case supabase.SecretFailed:
+			ui.List(fmt.Sprintf("%s%s: %v", ui.Red("x "), r.Name, r.Err))
+		case supabase.SecretUnchanged:
+			if IsVerbose() {
+				ui.List(ui.Dim("= " + r.Name))
+			}
+		}
+	}
+	if IsVerbose() {
+		ui.Infof("%d API call(s)", report.Calls)
+	}
+	return report.Err()
+}
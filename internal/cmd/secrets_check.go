@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var secretsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that every secret the functions need is configured",
+	Long: `Check that the env vars the Edge Functions read are available on the target
+branch, so a missing or misspelled secret fails here instead of at runtime.
+
+Required names come from each function's Deno.env.get("NAME") calls, including
+shared code it imports from ../_shared, and from the env.required list in the
+function's function.yaml. Names in env.optional, and the variables the Edge
+Runtime sets itself (SUPABASE_URL, SUPABASE_SERVICE_ROLE_KEY, ...), are not
+required. A name counts as available when it is already a secret on the
+branch or 'drift deploy secrets' would set it.
+
+'drift deploy all' runs the same check before deploying.`,
+	Example: `  drift secrets check
+  drift secrets check -b dev
+  drift secrets check --json`,
+	Args: cobra.NoArgs,
+	RunE: Run(runSecretsCheck, RequireProject),
+}
+
+var (
+	secretsCheckBranch string
+	secretsCheckJSON   bool
+)
+
+func init() {
+	secretsCheckCmd.Flags().StringVarP(&secretsCheckBranch, "branch", "b", "", "Target Supabase branch")
+	secretsCheckCmd.Flags().BoolVar(&secretsCheckJSON, "json", false, "Output as JSON")
+	secretsCmd.AddCommand(secretsCheckCmd)
+}
+
+// missingSecret is a required env var that would be unset at runtime.
+type missingSecret struct {
+	Name       string   `json:"name"`
+	Functions  []string `json:"functions"`
+	Source     string   `json:"source"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+type secretsCheckReport struct {
+	ProjectRef  string          `json:"project_ref"`
+	Environment string          `json:"environment"`
+	Functions   int             `json:"functions"`
+	Missing     []missingSecret `json:"missing"`
+}
+
+func runSecretsCheck(ctx *Context) error {
+	cfg := ctx.Config()
+	info, err := ctx.Target(secretsCheckBranch)
+	if err != nil {
+		return err
+	}
+
+	report, err := checkFunctionSecrets(cfg, ctx.Client(), info)
+	if err != nil {
+		return err
+	}
+
+	if ctx.JSON() {
+		if err := ctx.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		ui.Header("Function Secrets Check")
+		ui.KeyValue("Environment", envColorString(string(info.Environment)))
+		ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+		ui.NewLine()
+		printMissingSecrets(report)
+	}
+	if len(report.Missing) > 0 {
+		return errs.Validationf("%d required secret(s) missing on %s", len(report.Missing), info.SupabaseBranch.Name)
+	}
+	return nil
+}
+
+// checkFunctionSecrets compares what the functions deployable to info's
+// environment read against the secrets that will exist there: those already
+// on the branch plus those 'drift deploy secrets' would push.
+func checkFunctionSecrets(cfg *config.Config, client *supabase.Client, info *supabase.BranchInfo) (*secretsCheckReport, error) {
+	functions, err := listLocalFunctions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var envs []*supabase.FunctionEnv
+	for _, fn := range functions {
+		if cfg.IsFunctionRestricted(fn.Name, string(info.Environment)) {
+			continue
+		}
+		env, err := supabase.ScanFunctionEnv(fn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan function %s: %w", fn.Name, err)
+		}
+		envs = append(envs, env)
+	}
+
+	remote, err := client.ListSecrets(info.ProjectRef)
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[string]bool)
+	for _, name := range remote {
+		available[name] = true
+	}
+	for _, s := range collectDeploySecrets(cfg, info).Secrets {
+		available[s.Name] = true
+	}
+
+	return &secretsCheckReport{
+		ProjectRef:  info.ProjectRef,
+		Environment: string(info.Environment),
+		Functions:   len(envs),
+		Missing:     findMissingSecrets(envs, available),
+	}, nil
+}
+
+// findMissingSecrets returns the required names not in available, sorted,
+// with the functions that need each and the closest available name when it
+// looks like a typo.
+func findMissingSecrets(envs []*supabase.FunctionEnv, available map[string]bool) []missingSecret {
+	byName := make(map[string]*missingSecret)
+	for _, env := range envs {
+		for _, name := range env.Required {
+			if available[name] {
+				continue
+			}
+			m, ok := byName[name]
+			if !ok {
+				m = &missingSecret{Name: name, Source: env.Sources[name], Suggestion: closestSecretName(name, available)}
+				byName[name] = m
+			}
+			m.Functions = append(m.Functions, env.Function)
+		}
+	}
+
+	missing := make([]missingSecret, 0, len(byName))
+	for _, m := range byName {
+		sort.Strings(m.Functions)
+		missing = append(missing, *m)
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Name < missing[j].Name })
+	return missing
+}
+
+// closestSecretName returns the available name within two edits of name,
+// ignoring case, or "" when there is none.
+func closestSecretName(name string, available map[string]bool) string {
+	best, bestDist := "", 3
+	for candidate := range available {
+		d := editDistance(strings.ToUpper(name), strings.ToUpper(candidate))
+		if d < bestDist || (d == bestDist && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func printMissingSecrets(report *secretsCheckReport) {
+	if len(report.Missing) == 0 {
+		ui.Successf("All secrets required by %d function(s) are configured", report.Functions)
+		return
+	}
+	ui.Errorf("%d required secret(s) are not configured:", len(report.Missing))
+	for _, m := range report.Missing {
+		line := fmt.Sprintf("%s %s %s", ui.Bold(m.Name), ui.Dim("needed by "+strings.Join(m.Functions, ", ")), ui.Dim("("+m.Source+")"))
+		if m.Suggestion != "" {
+			line += ui.Yellow(fmt.Sprintf(" did you mean %s?", m.Suggestion))
+		}
+		ui.List(line)
+	}
+	ui.NewLine()
+	ui.Info("Set them with 'drift secrets copy' or in environments.<env>.secrets, or mark them env.optional in the function's function.yaml")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestFindMissingSecrets(t *testing.T) {
+	envs := []*supabase.FunctionEnv{
+		{
+			Function: "checkout",
+			Required: []string{"STRIPE_SECRET_KEY", "STRIPE_WEBHOOK_SECRT"},
+			Sources:  map[string]string{"STRIPE_SECRET_KEY": "checkout/index.ts:1", "STRIPE_WEBHOOK_SECRT": "checkout/index.ts:2"},
+		},
+		{
+			Function: "billing",
+			Required: []string{"STRIPE_WEBHOOK_SECRT", "RESEND_API_KEY"},
+			Sources:  map[string]string{"STRIPE_WEBHOOK_SECRT": "billing/index.ts:9", "RESEND_API_KEY": "billing/index.ts:3"},
+		},
+	}
+	available := map[string]bool{"STRIPE_SECRET_KEY": true, "STRIPE_WEBHOOK_SECRET": true}
+
+	got := findMissingSecrets(envs, available)
+	want := []missingSecret{
+		{Name: "RESEND_API_KEY", Functions: []string{"billing"}, Source: "billing/index.ts:3"},
+		{Name: "STRIPE_WEBHOOK_SECRT", Functions: []string{"billing", "checkout"}, Source: "checkout/index.ts:2", Suggestion: "STRIPE_WEBHOOK_SECRET"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findMissingSecrets() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestClosestSecretName(t *testing.T) {
+	available := map[string]bool{"OPENAI_API_KEY": true, "SENTRY_DSN": true}
+	tests := map[string]string{
+		"OPENAI_APIKEY":  "OPENAI_API_KEY",
+		"openai_api_key": "OPENAI_API_KEY",
+		"SENTRY_DNS":     "SENTRY_DSN",
+		"RESEND_API_KEY": "",
+	}
+	for name, want := range tests {
+		if got := closestSecretName(name, available); got != want {
+			t.Errorf("closestSecretName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"SECRET", "SECRT", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve read-only project state as JSON over HTTP",
+	Long: `Start a local HTTP server with read-only JSON endpoints for editor
+extensions and status-bar widgets, so they can show drift state without
+spawning the CLI on every refresh.
+
+Endpoints (GET only):
+  /             Available endpoints and drift version
+  /env          Resolved Supabase branch and environment
+  /worktrees    Git worktrees and uncommitted change counts
+  /migrations   Pending migrations on the resolved branch
+  /functions    Local vs deployed Edge Functions (function drift)
+  /devices      WebDriverAgent, tunnel, and device session status
+  /serving      Functions served by 'drift functions serve', with routes
+
+/env, /migrations, and /functions accept ?branch=<name> to target a branch
+other than the current one. Responses are cached for --cache-ttl; errors
+are returned as {"error": "..."} with status 500.
+
+The server binds to localhost only.`,
+	Example: `  drift serve                        # http://127.0.0.1:7777
+  drift serve --port 9000
+  curl -s localhost:7777/env | jq .environment`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var (
+	servePortFlag     int
+	serveCacheTTLFlag time.Duration
+)
+
+func init() {
+	serveCmd.Flags().IntVar(&servePortFlag, "port", 7777, "Port to listen on")
+	serveCmd.Flags().DurationVar(&serveCacheTTLFlag, "cache-ttl", 15*time.Second, "How long to reuse a response before recomputing it")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// stateCollector computes the JSON payload for an endpoint.
+type stateCollector func(r *http.Request) (interface{}, error)
+
+// stateEndpoints maps endpoint paths to their collectors.
+func stateEndpoints() map[string]stateCollector {
+	return map[string]stateCollector{
+		"/env": func(r *http.Request) (interface{}, error) {
+			return collectEnvState(r.URL.Query().Get("branch"))
+		},
+		"/worktrees": func(r *http.Request) (interface{}, error) {
+			worktrees, err := collectWorktreeStates()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"worktrees": worktrees}, nil
+		},
+		"/migrations": func(r *http.Request) (interface{}, error) {
+			return collectMigrationsState(r.URL.Query().Get("branch"))
+		},
+		"/functions": func(r *http.Request) (interface{}, error) {
+			return collectFunctionsState(r.URL.Query().Get("branch"))
+		},
+		"/devices": func(r *http.Request) (interface{}, error) {
+			return collectDeviceState(), nil
+		},
+		"/serving": func(r *http.Request) (interface{}, error) {
+			status, err := loadFunctionsServeStatus(config.LoadOrDefault())
+			if err != nil || status == nil {
+				return map[string]bool{"serving": false}, err
+			}
+			return status, nil
+		},
+	}
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+
+	addr := net.JoinHostPort("127.0.0.1", fmt.Sprint(servePortFlag))
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newStateServer(stateEndpoints(), serveCacheTTLFlag),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.ListenAndServe() }()
+
+	ui.Successf("Serving drift state on http://%s (Ctrl+C to stop)", addr)
+
+	select {
+	case err := <-errCh:
+		if err != http.ErrServerClosed {
+			return fmt.Errorf("failed to serve on %s: %w", addr, err)
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		ui.NewLine()
+		ui.Info("Stopping server")
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// stateServer serves collector results as JSON, caching each URL for ttl.
+// Collectors run one at a time since they shell out to git and supabase.
+type stateServer struct {
+	endpoints map[string]stateCollector
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedState
+}
+
+type cachedState struct {
+	body   []byte
+	status int
+	at     time.Time
+}
+
+func newStateServer(endpoints map[string]stateCollector, ttl time.Duration) *stateServer {
+	return &stateServer{
+		endpoints: endpoints,
+		ttl:       ttl,
+		now:       time.Now,
+		cache:     make(map[string]cachedState),
+	}
+}
+
+func (s *stateServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeStateJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "read-only endpoint"})
+		return
+	}
+
+	if r.URL.Path == "/" {
+		paths := make([]string, 0, len(s.endpoints))
+		for path := range s.endpoints {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		writeStateJSON(w, http.StatusOK, map[string]interface{}{"version": version, "endpoints": paths})
+		return
+	}
+
+	collect, ok := s.endpoints[r.URL.Path]
+	if !ok {
+		writeStateJSON(w, http.StatusNotFound, map[string]string{"error": "unknown endpoint " + r.URL.Path})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.URL.Path + "?" + r.URL.RawQuery
+	if cached, ok := s.cache[key]; ok && s.now().Sub(cached.at) < s.ttl {
+		writeStateBody(w, cached.status, cached.body)
+		return
+	}
+
+	status := http.StatusOK
+	value, err := collect(r)
+	if err != nil {
+		status = http.StatusInternalServerError
+		value = map[string]string{"error": err.Error()}
+	}
+	body, err := json.Marshal(value)
+	if err != nil {
+		writeStateJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	s.cache[key] = cachedState{body: body, status: status, at: s.now()}
+	writeStateBody(w, status, body)
+}
+
+func writeStateJSON(w http.ResponseWriter, status int, value interface{}) {
+	body, _ := json.Marshal(value)
+	writeStateBody(w, status, body)
+}
+
+func writeStateBody(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
+}
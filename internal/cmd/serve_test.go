@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestStateServer(t *testing.T) {
+	calls := 0
+	server := newStateServer(map[string]stateCollector{
+		"/env": func(r *http.Request) (interface{}, error) {
+			calls++
+			return map[string]string{"branch": r.URL.Query().Get("branch")}, nil
+		},
+		"/broken": func(*http.Request) (interface{}, error) {
+			return nil, errors.New("no supabase branch")
+		},
+	}, time.Minute)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	server.now = func() time.Time { return now }
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantBody   string
+		wantCalls  int
+	}{
+		{"index", http.MethodGet, "/", http.StatusOK, `"endpoints":["/broken","/env"]`, 0},
+		{"collect", http.MethodGet, "/env?branch=feat", http.StatusOK, `{"branch":"feat"}`, 1},
+		{"cached", http.MethodGet, "/env?branch=feat", http.StatusOK, `{"branch":"feat"}`, 1},
+		{"query is part of key", http.MethodGet, "/env", http.StatusOK, `{"branch":""}`, 2},
+		{"error", http.MethodGet, "/broken", http.StatusInternalServerError, `{"error":"no supabase branch"}`, 2},
+		{"unknown", http.MethodGet, "/nope", http.StatusNotFound, `unknown endpoint`, 2},
+		{"read only", http.MethodPost, "/env", http.StatusMethodNotAllowed, `read-only`, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("collector calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	now = now.Add(2 * time.Minute)
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/env?branch=feat", nil))
+	if calls != 3 {
+		t.Errorf("collector calls after ttl = %d, want 3", calls)
+	}
+}
+
+func TestCompareFunctions(t *testing.T) {
+	local := []supabase.Function{{Name: "auth"}, {Name: "push"}}
+	deployed := []supabase.DeployedFunction{
+		{Name: "legacy", Version: "3"},
+		{Name: "auth", Version: "12", Status: "ACTIVE"},
+	}
+
+	state := compareFunctions(envState{}, local, deployed)
+
+	want := []functionState{
+		{Name: "auth", Deployed: true, DeployedVersion: "12", Status: "ACTIVE"},
+		{Name: "push"},
+	}
+	if !reflect.DeepEqual(state.Functions, want) {
+		t.Errorf("Functions = %+v, want %+v", state.Functions, want)
+	}
+	if !reflect.DeepEqual(state.NotDeployed, []string{"push"}) {
+		t.Errorf("NotDeployed = %v, want [push]", state.NotDeployed)
+	}
+	if !reflect.DeepEqual(state.RemoteOnly, []string{"legacy"}) {
+		t.Errorf("RemoteOnly = %v, want [legacy]", state.RemoteOnly)
+	}
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init <zsh|bash|fish>",
+	Short: "Print shell functions for jumping between worktrees",
+	Long: `Print shell code that defines a dcd function, so you can cd into a
+worktree by branch name:
+
+  dcd feature/login
+
+drift itself cannot change your shell's directory, so the function runs
+'drift worktree path' and cds there. Branch names tab-complete.
+
+With --banner or --tmux, a hook also runs whenever you cd into a different
+worktree:
+
+  --banner  print the branch, its Supabase environment and whether the env
+            file is stale, as 'drift prompt' does (offline, no API calls)
+  --tmux    inside tmux, switch to the worktree's session if one is running
+
+Add it to your shell's startup file:
+
+  zsh   echo 'eval "$(drift shell-init zsh)"' >> ~/.zshrc
+  bash  echo 'eval "$(drift shell-init bash)"' >> ~/.bashrc
+  fish  echo 'drift shell-init fish | source' >> ~/.config/fish/config.fish`,
+	Example: `  eval "$(drift shell-init zsh)"
+  eval "$(drift shell-init bash --banner)"
+  drift shell-init fish --banner --tmux | source
+  eval "$(drift shell-init zsh --cmd wt)"   # name the function wt`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"zsh", "bash", "fish"},
+	RunE:      runShellInit,
+}
+
+var shellInitHookCmd = &cobra.Command{
+	Use:    "hook",
+	Short:  "Run the directory-change hook installed by shell-init",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runShellInitHook,
+}
+
+var (
+	shellInitFuncName string
+	shellInitBanner   bool
+	shellInitTmux     bool
+)
+
+func init() {
+	shellInitCmd.Flags().StringVar(&shellInitFuncName, "cmd", "dcd", "Name of the cd function")
+	for _, cmd := range []*cobra.Command{shellInitCmd, shellInitHookCmd} {
+		cmd.Flags().BoolVar(&shellInitBanner, "banner", false, "Show the environment when entering a worktree")
+		cmd.Flags().BoolVar(&shellInitTmux, "tmux", false, "Switch to the worktree's tmux session when entering it")
+	}
+	shellInitCmd.AddCommand(shellInitHookCmd)
+	rootCmd.AddCommand(shellInitCmd)
+}
+
+// shellFuncName is what --cmd accepts: a name every supported shell takes
+// for a function without quoting.
+var shellFuncName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// shellInitScript is the data for the shell templates.
+type shellInitScript struct {
+	Func string
+	// HookArgs are the flags passed to 'drift shell-init hook', or empty
+	// when no hook is installed.
+	HookArgs string
+}
+
+// worktreeBranches lists the worktree branches for completion; cobra
+// separates each branch from its description with a tab.
+const worktreeBranches = `command drift __complete worktree path '' 2>/dev/null | command grep -v '^:' | command cut -f1`
+
+var shellInitTemplates = map[string]string{
+	"zsh": `# drift shell integration for zsh: eval "$(drift shell-init zsh)"
+
+{{.Func}}() {
+  if [ $# -ne 1 ]; then
+    echo "usage: {{.Func}} <branch>" >&2
+    return 2
+  fi
+  local dir
+  dir="$(command drift worktree path "$1")" || return
+  builtin cd -- "$dir"
+}
+
+_drift_{{.Func}}() {
+  local -a branches
+  branches=(${(f)"$(` + worktreeBranches + `)"})
+  compadd -a branches
+}
+if (( $+functions[compdef] )); then
+  compdef _drift_{{.Func}} {{.Func}}
+fi
+{{- if .HookArgs}}
+
+_drift_chpwd() {
+  local root
+  root="$(command git rev-parse --show-toplevel 2>/dev/null)"
+  [ "$root" = "${_DRIFT_WORKTREE-}" ] && return
+  _DRIFT_WORKTREE="$root"
+  [ -n "$root" ] && command drift shell-init hook {{.HookArgs}}
+}
+_DRIFT_WORKTREE="$(command git rev-parse --show-toplevel 2>/dev/null)"
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _drift_chpwd
+{{- end}}
+`,
+	"bash": `# drift shell integration for bash: eval "$(drift shell-init bash)"
+
+{{.Func}}() {
+  if [ $# -ne 1 ]; then
+    echo "usage: {{.Func}} <branch>" >&2
+    return 2
+  fi
+  local dir
+  dir="$(command drift worktree path "$1")" || return
+  builtin cd -- "$dir"
+}
+
+_drift_{{.Func}}() {
+  local IFS=$'\n'
+  COMPREPLY=($(compgen -W "$(` + worktreeBranches + `)" -- "${COMP_WORDS[COMP_CWORD]}"))
+}
+complete -F _drift_{{.Func}} {{.Func}}
+{{- if .HookArgs}}
+
+# bash has no chpwd hook, so check for a new directory before each prompt,
+# keeping $? for prompts that show it.
+_drift_prompt_command() {
+  local status=$?
+  if [ "$PWD" != "${_DRIFT_PWD-}" ]; then
+    _DRIFT_PWD="$PWD"
+    local root
+    root="$(command git rev-parse --show-toplevel 2>/dev/null)"
+    if [ "$root" != "${_DRIFT_WORKTREE-}" ]; then
+      _DRIFT_WORKTREE="$root"
+      [ -n "$root" ] && command drift shell-init hook {{.HookArgs}}
+    fi
+  fi
+  return $status
+}
+_DRIFT_PWD="$PWD"
+_DRIFT_WORKTREE="$(command git rev-parse --show-toplevel 2>/dev/null)"
+case ";${PROMPT_COMMAND-};" in
+  *";_drift_prompt_command;"*) ;;
+  *) PROMPT_COMMAND="_drift_prompt_command${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+{{- end}}
+`,
+	"fish": `# drift shell integration for fish: drift shell-init fish | source
+
+function {{.Func}} --description 'cd into a drift worktree by branch'
+    if test (count $argv) -ne 1
+        echo "usage: {{.Func}} <branch>" >&2
+        return 2
+    end
+    set -l dir (command drift worktree path $argv[1]); or return
+    builtin cd -- $dir
+end
+
+complete -c {{.Func}} -f -a '(command drift __complete worktree path "" 2>/dev/null | string match -v -r "^:")'
+{{- if .HookArgs}}
+
+set -g _drift_worktree (command git rev-parse --show-toplevel 2>/dev/null)
+function _drift_chpwd --on-variable PWD
+    set -l root (command git rev-parse --show-toplevel 2>/dev/null)
+    test "$root" = "$_drift_worktree"; and return
+    set -g _drift_worktree $root
+    test -n "$root"; and command drift shell-init hook {{.HookArgs}}
+end
+{{- end}}
+`,
+}
+
+func runShellInit(cmd *cobra.Command, args []string) error {
+	if !shellFuncName.MatchString(shellInitFuncName) {
+		return errs.Validationf("--cmd %q is not a valid function name", shellInitFuncName)
+	}
+	script, err := renderShellInit(args[0], shellInitFuncName, shellInitBanner, shellInitTmux)
+	if err != nil {
+		return err
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// renderShellInit returns the integration script for shellName.
+func renderShellInit(shellName, funcName string, banner, tmux bool) (string, error) {
+	text, ok := shellInitTemplates[shellName]
+	if !ok {
+		return "", errs.Validationf("unsupported shell %q (use zsh, bash, or fish)", shellName)
+	}
+
+	data := shellInitScript{Func: funcName}
+	var hookArgs []string
+	if banner {
+		hookArgs = append(hookArgs, "--banner")
+	}
+	if tmux {
+		hookArgs = append(hookArgs, "--tmux")
+	}
+	data.HookArgs = strings.Join(hookArgs, " ")
+
+	tmpl := template.Must(template.New(shellName).Parse(text))
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// runShellInitHook runs when the shell enters a different worktree. It
+// must be quick and quiet: it never calls the network and prints nothing
+// outside a drift project.
+func runShellInitHook(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepository() || !config.Exists() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	if shellInitTmux && os.Getenv("TMUX") != "" {
+		switchToWorktreeSession(cfg)
+	}
+	if shellInitBanner {
+		color.NoColor = noColor || os.Getenv("NO_COLOR") != ""
+		supabase.SetOffline(true)
+		out, err := renderPrompt(defaultPromptFormat, collectPromptState(cfg))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %s\n", ui.Dim("drift:"), out)
+	}
+	return nil
+}
+
+// switchToWorktreeSession switches the tmux client to the running session
+// for the current worktree, if there is one and it is not already current.
+func switchToWorktreeSession(cfg *config.Config) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return
+	}
+	cwd, _ := os.Getwd()
+	wt := git.ContainingWorktree(worktrees, cwd)
+	if wt == nil {
+		return
+	}
+	sessions, err := listTmuxSessions()
+	if err != nil {
+		return
+	}
+	session, ok := resolveSendSession("", sessions, cfg.Project.Name, wt)
+	if !ok {
+		return
+	}
+	if current, err := getCurrentTmuxSession(); err == nil && current == session {
+		return
+	}
+	shell.Run("tmux", "switch-client", "-t", session)
+}
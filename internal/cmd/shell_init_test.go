@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderShellInit(t *testing.T) {
+	for _, sh := range []string{"zsh", "bash", "fish"} {
+		script, err := renderShellInit(sh, "wt", false, false)
+		if err != nil {
+			t.Fatalf("%s: %v", sh, err)
+		}
+		if !strings.Contains(script, "command drift worktree path") || !strings.Contains(script, "wt") {
+			t.Errorf("%s script does not define wt:\n%s", sh, script)
+		}
+		if strings.Contains(script, "shell-init hook") || strings.Contains(script, "dcd") {
+			t.Errorf("%s script without hooks:\n%s", sh, script)
+		}
+
+		script, err = renderShellInit(sh, "dcd", true, true)
+		if err != nil {
+			t.Fatalf("%s: %v", sh, err)
+		}
+		if !strings.Contains(script, "command drift shell-init hook --banner --tmux") {
+			t.Errorf("%s script does not install the hook:\n%s", sh, script)
+		}
+	}
+
+	if _, err := renderShellInit("powershell", "dcd", false, false); err == nil {
+		t.Error("renderShellInit(powershell) did not fail")
+	}
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect and clean the .drift/ state directory",
+	Long: `Drift keeps state that must survive between commands in .drift/ at the
+project root (gitignored, except for encrypted secrets):
+
+  cache       Cached Supabase API responses (see 'drift cache')
+  manifests   Per-branch record of the last applied 'deploy all --auto-approve'
+  audit       Log of deploys, migrations, and restores (audit.log)
+  archive     Worktrees removed by 'drift worktree archive'
+  review      Pull request targeted by 'drift env setup --review'
+  metrics     Timings of recent commands (see 'drift metrics')
+  functions   Merged import maps, CLI workdir, and serve status for functions
+  tmux        Saved tmux session layouts (see 'drift tmux save')
+  ports       Local ports assigned to each worktree (see 'drift worktree ports')
+  snapshots   Named database snapshots (see 'drift db snapshot')
+  stacks      Parent branches of stacked worktrees (see 'drift worktree restack')
+  secrets     age-encrypted env secrets (never cleaned)
+
+Device sessions are tracked in ~/.drift/devices.json instead, since devices
+are shared by every project on the machine.`,
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show what drift has stored for this project",
+	Args:  cobra.NoArgs,
+	RunE:  runStateShow,
+}
+
+var stateCleanCmd = &cobra.Command{
+	Use:   "clean [area...]",
+	Short: "Remove stored state",
+	Long: `Remove stored state. Without arguments, the cache, deploy manifests,
+and generated functions files are removed. Name areas to remove others, or pass --all for everything
+except secrets.`,
+	Example: `  drift state clean                # cache, manifests, and functions
+  drift state clean audit          # clear the audit log
+  drift state clean --all`,
+	RunE: runStateClean,
+}
+
+var (
+	stateAuditLimit int
+	stateCleanAll   bool
+)
+
+func init() {
+	stateShowCmd.Flags().IntVar(&stateAuditLimit, "audit", 5, "Number of recent audit entries to show")
+	stateCleanCmd.Flags().BoolVar(&stateCleanAll, "all", false, "Remove every area except secrets")
+
+	stateCmd.AddCommand(stateShowCmd)
+	stateCmd.AddCommand(stateCleanCmd)
+	rootCmd.AddCommand(stateCmd)
+}
+
+func runStateShow(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	root := cfg.ProjectRoot()
+
+	ui.Header("Drift State")
+	ui.KeyValue("Directory", state.Root(root))
+	ui.NewLine()
+
+	usage, err := state.Usage(root)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("  %-10s %6s %10s  %-16s %s\n", "AREA", "FILES", "SIZE", "UPDATED", "DESCRIPTION")
+	for _, u := range usage {
+		updated := ui.Dim("-")
+		if !u.Modified.IsZero() {
+			updated = formatBackupAge(u.Modified)
+		}
+		fmt.Printf("  %-10s %6d %10s  %-16s %s\n", u.Area.Name, u.Files, formatStateSize(u.Bytes), updated, ui.Dim(u.Area.Description))
+	}
+
+	if manifests := loadDeployManifests(root); len(manifests) > 0 {
+		ui.NewLine()
+		ui.SubHeader("Last Deploys")
+		for _, m := range manifests {
+			ui.List(fmt.Sprintf("%-30s %-14s %d changes  %s", m.Target.SupabaseBranch, envColorString(m.Target.Environment), len(m.Items), ui.Dim(formatBackupAge(m.AppliedAt)+" by "+m.Actor)))
+		}
+	}
+
+	entries, err := state.ReadAudit(root)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 && stateAuditLimit > 0 {
+		ui.NewLine()
+		ui.SubHeader("Recent Operations")
+		if len(entries) > stateAuditLimit {
+			entries = entries[len(entries)-stateAuditLimit:]
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			e := entries[i]
+			result := ui.Green("ok")
+			if e.Error != "" {
+				result = ui.Red("failed")
+			}
+			target := e.Branch
+			if e.Environment != "" {
+				target = fmt.Sprintf("%s (%s)", e.Branch, e.Environment)
+			}
+			ui.List(fmt.Sprintf("%-14s %-16s %-30s %s %s", formatBackupAge(e.Time), e.Operation, target, result, ui.Dim(e.Actor)))
+		}
+	}
+	return nil
+}
+
+func runStateClean(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	root := cfg.ProjectRoot()
+
+	areas, err := selectStateAreas(args, stateCleanAll)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(areas))
+	for i, a := range areas {
+		names[i] = a.Name
+	}
+	if !IsYes() {
+		confirmed, err := ui.PromptYesNo(fmt.Sprintf("Remove %s from %s?", strings.Join(names, ", "), state.Dir), true)
+		if err != nil || !confirmed {
+			ui.Info("Cancelled")
+			return nil
+		}
+	}
+
+	if err := state.Clean(root, areas); err != nil {
+		return err
+	}
+	ui.Successf("Removed %s", strings.Join(names, ", "))
+	return nil
+}
+
+// loadDeployManifests reads every deploy manifest, most recent first.
+// Unreadable manifests are skipped.
+func loadDeployManifests(root string) []deployManifest {
+	paths, _ := filepath.Glob(state.Path(root, state.Manifests, "*.json"))
+	var manifests []deployManifest
+	for _, path := range paths {
+		var m deployManifest
+		if ok, err := state.ReadJSON(path, &m); ok && err == nil {
+			manifests = append(manifests, m)
+		}
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].AppliedAt.After(manifests[j].AppliedAt) })
+	return manifests
+}
+
+// selectStateAreas resolves the areas named on the command line; no names
+// means the default areas.
+func selectStateAreas(names []string, all bool) ([]state.Area, error) {
+	if all && len(names) > 0 {
+		return nil, fmt.Errorf("use either area names or --all, not both")
+	}
+
+	var areas []state.Area
+	switch {
+	case all:
+		for _, a := range state.Areas() {
+			if !a.Protected {
+				areas = append(areas, a)
+			}
+		}
+	case len(names) == 0:
+		for _, a := range state.Areas() {
+			if a.Default {
+				areas = append(areas, a)
+			}
+		}
+	default:
+		for _, name := range names {
+			a, ok := state.LookupArea(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown state area '%s'", name)
+			}
+			if a.Protected {
+				return nil, fmt.Errorf("%s cannot be removed with 'drift state clean'", a.Name)
+			}
+			areas = append(areas, a)
+		}
+	}
+	return areas, nil
+}
+
+// formatStateSize formats a byte count for display.
+func formatStateSize(n int64) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/1024/1024)
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
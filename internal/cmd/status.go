@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/database"
 	"github.com/undrift/drift/internal/git"
 	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
@@ -26,7 +27,9 @@ This includes:
   - Current git branch and Supabase environment
   - Config file status (.env.local or Config.xcconfig)
   - Migration status
-  - Edge functions count`,
+  - Edge functions count
+
+Use 'drift status services' for detailed per-service health probes.`,
 	RunE: runStatus,
 }
 
@@ -81,6 +84,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	configStatus := checkConfigFileStatus(cfg, info)
 	printConfigStatus(configStatus)
+	if configStatus.exists && info != nil {
+		printEnvLockStatus(configStatus.path, newEnvLock(cfg, info, nil))
+	}
 
 	// === MIGRATIONS ===
 	ui.NewLine()
@@ -282,7 +288,7 @@ func printFunctionsStatus(cfg *config.Config) {
 		return
 	}
 
-	functions, err := supabase.ListFunctions(functionsPath)
+	functions, err := listLocalFunctions(cfg)
 	if err != nil {
 		ui.Warning(fmt.Sprintf("Could not list functions: %v", err))
 		return
@@ -299,7 +305,7 @@ func printFunctionsStatus(cfg *config.Config) {
 
 func printServiceHealth(projectRef string) {
 	// Check API health by hitting the REST endpoint
-	apiURL := fmt.Sprintf("https://%s.supabase.co/rest/v1/", projectRef)
+	apiURL := supabase.APIURL(projectRef) + "/rest/v1/"
 
 	if statusVerboseFlag {
 		ui.Infof("Checking API health: %s", apiURL)
@@ -432,53 +438,53 @@ func printWorktreeStatus() {
 
 // GetProjectDashboardURL returns the URL for the Supabase project dashboard.
 func GetProjectDashboardURL(projectRef string) string {
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s", projectRef)
+	return supabase.DashboardURL(projectRef, "")
 }
 
 // GetTableEditorURL returns the URL for the Supabase table editor.
 func GetTableEditorURL(projectRef string) string {
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s/editor", projectRef)
+	return supabase.DashboardURL(projectRef, "editor")
 }
 
 // GetSQLEditorURL returns the URL for the Supabase SQL editor.
 func GetSQLEditorURL(projectRef string) string {
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s/sql", projectRef)
+	return supabase.DashboardURL(projectRef, "sql")
 }
 
 // GetFunctionsURL returns the URL for Edge Functions.
 func GetFunctionsURL(projectRef string) string {
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s/functions", projectRef)
+	return supabase.DashboardURL(projectRef, "functions")
 }
 
 // GetAuthURL returns the URL for Auth settings.
 func GetAuthURL(projectRef string) string {
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s/auth/users", projectRef)
+	return supabase.DashboardURL(projectRef, "auth/users")
 }
 
 // GetStorageURL returns the URL for Storage.
 func GetStorageURL(projectRef string) string {
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s/storage/buckets", projectRef)
+	return supabase.DashboardURL(projectRef, "storage/buckets")
 }
 
 // GetLogsURL returns the URL for Logs Explorer.
 func GetLogsURL(projectRef string) string {
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s/logs/explorer", projectRef)
+	return supabase.DashboardURL(projectRef, "logs/explorer")
 }
 
 // GetSettingsURL returns the URL for project settings.
 func GetSettingsURL(projectRef string) string {
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s/settings/general", projectRef)
+	return supabase.DashboardURL(projectRef, "settings/general")
 }
 
 // GetAPISettingsURL returns the URL for API settings (keys).
 func GetAPISettingsURL(projectRef string) string {
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s/settings/api", projectRef)
+	return supabase.DashboardURL(projectRef, "settings/api")
 }
 
 // GetBranchesURL returns the URL for Supabase branches.
 func GetBranchesURL(projectRef string) string {
 	// Branches are at the organization level, need parent project
-	return fmt.Sprintf("https://supabase.com/dashboard/project/%s/branches", projectRef)
+	return supabase.DashboardURL(projectRef, "branches")
 }
 
 // GetRepoURL returns the GitHub repo URL from git remote.
@@ -565,7 +571,8 @@ func getDbURLForBranch(info *supabase.BranchInfo) string {
 		if pw == "" {
 			return ""
 		}
-		return fmt.Sprintf("postgresql://postgres.%s:%s@%s:%d/postgres", info.ProjectRef, pw, poolerHost, poolerPort)
+		conn := database.Connection{Host: poolerHost, Port: poolerPort, User: "postgres." + info.ProjectRef, Password: pw, Database: "postgres"}
+		return dbConnections(cfg).For(conn, database.OpMigrate).URL()
 	}
 
 	// For non-production, try experimental API
@@ -579,9 +586,11 @@ func getDbURLForBranch(info *supabase.BranchInfo) string {
 		return ""
 	}
 
-	// Use session mode (port 5432) instead of transaction mode (port 6543)
-	// Transaction mode doesn't support prepared statements which supabase CLI uses
-	url := strings.Replace(connInfo.PostgresURL, ":6543/", ":5432/", 1)
-
-	return url
+	// The URL is handed to the Supabase CLI, which needs session mode for
+	// prepared statements.
+	conn, err := database.ParseConnectionURL(connInfo.PostgresURL)
+	if err != nil {
+		return ""
+	}
+	return dbConnections(config.LoadOrDefault()).For(conn, database.OpMigrate).URL()
 }
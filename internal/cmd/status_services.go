@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var statusServicesCmd = &cobra.Command{
+	Use:   "services",
+	Short: "Probe Supabase service health for the resolved branch",
+	Long: `Probe each Supabase service for the resolved branch and report latency.
+
+Checks:
+  rest       PostgREST endpoint reachability (critical)
+  auth       Auth /health endpoint (critical)
+  realtime   Websocket upgrade handshake
+  storage    Bucket listing
+  functions  Edge Functions gateway (or a specific function with --function)
+
+Exits non-zero when any critical service is down, so it can be used
+as a readiness gate in scripts and CI.`,
+	Example: `  drift status services              # Table output for current branch
+  drift status services --json       # Machine-readable report
+  drift status services -b development
+  drift status services --function health-check`,
+	Args: cobra.NoArgs,
+	RunE: runStatusServices,
+}
+
+var (
+	statusServicesBranch   string
+	statusServicesJSON     bool
+	statusServicesFunction string
+	statusServicesTimeout  time.Duration
+)
+
+func init() {
+	statusServicesCmd.Flags().StringVarP(&statusServicesBranch, "branch", "b", "", "Target Supabase branch (default: current git branch)")
+	statusServicesCmd.Flags().BoolVar(&statusServicesJSON, "json", false, "Output the report as JSON")
+	statusServicesCmd.Flags().StringVar(&statusServicesFunction, "function", "", "Edge Function to ping instead of the gateway root")
+	statusServicesCmd.Flags().DurationVar(&statusServicesTimeout, "timeout", 10*time.Second, "Per-check timeout")
+	statusCmd.AddCommand(statusServicesCmd)
+}
+
+func runStatusServices(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	client := supabase.NewClient()
+
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current git branch: %w", err)
+	}
+
+	sp := ui.NewSpinner("Resolving target environment")
+	if !statusServicesJSON {
+		sp.Start()
+	}
+	info, err := ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, statusServicesBranch)
+	if err != nil {
+		sp.Stop()
+		return err
+	}
+
+	sp.UpdateMessage("Fetching API keys")
+	anonKey, err := fetchAnonKeyForBranch(client, info)
+	if err != nil {
+		sp.Stop()
+		return err
+	}
+
+	sp.UpdateMessage("Probing services")
+	report := supabase.ProbeServices(info.ProjectRef, supabase.HealthProbeOptions{
+		APIURL:   info.APIURL,
+		AnonKey:  anonKey,
+		Function: statusServicesFunction,
+		Timeout:  statusServicesTimeout,
+	})
+	sp.Stop()
+
+	if statusServicesJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printHealthReport(info, report)
+	}
+
+	if failed := report.CriticalFailures(); len(failed) > 0 {
+		names := make([]string, len(failed))
+		for i, f := range failed {
+			names[i] = f.Service
+		}
+		return fmt.Errorf("critical services unhealthy: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// fetchAnonKeyForBranch returns the anon key, preferring branch secrets for non-production targets.
+func fetchAnonKeyForBranch(client *supabase.Client, info *supabase.BranchInfo) (string, error) {
+	if info.Environment != supabase.EnvProduction {
+		if secrets, err := client.GetBranchSecrets(info.SupabaseBranch.Name); err == nil && secrets.SupabaseAnonKey != "" {
+			return secrets.SupabaseAnonKey, nil
+		}
+	}
+
+	anonKey, err := client.GetAnonKey(info.ProjectRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to get anon key: %w", err)
+	}
+	return anonKey, nil
+}
+
+func printHealthReport(info *supabase.BranchInfo, report *supabase.HealthReport) {
+	ui.Header("Service Health")
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("API URL", ui.Cyan(report.APIURL))
+	if info.IsFallback {
+		ui.Warningf("Using fallback target branch: %s", info.SupabaseBranch.GitBranch)
+	}
+	ui.NewLine()
+
+	table := ui.NewTable([]string{"Service", "Status", "Latency", "Code", "Detail"})
+	for _, s := range report.Services {
+		status := "✓ healthy"
+		color := ui.TableColor.Green
+		if !s.Healthy {
+			if s.Critical {
+				status = "✗ down"
+				color = ui.TableColor.Red
+			} else {
+				status = "⚠ degraded"
+				color = ui.TableColor.Yellow
+			}
+		}
+
+		code := "-"
+		if s.StatusCode != 0 {
+			code = fmt.Sprintf("%d", s.StatusCode)
+		}
+
+		name := s.Service
+		if s.Critical {
+			name += " *"
+		}
+
+		table.AddColoredRow(
+			[]string{name, status, fmt.Sprintf("%dms", s.LatencyMS), code, s.Detail},
+			[]tablewriter.Colors{{}, color, {}, {}, {}},
+		)
+	}
+	table.Render()
+
+	ui.NewLine()
+	ui.Infof("%s marks critical services", ui.Dim("*"))
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test [suite]",
+	Short: "Run tests against the branch's Supabase environment",
+	Long: `Run a test suite with Supabase credentials for the current branch injected
+into its environment. The suite defaults to "unit".
+
+Commands come from test.commands in .drift.yaml. Without configuration:
+  unit       npm test (web) or xcodebuild test -scheme <scheme> (iOS/macOS)
+  functions  deno test --allow-all <functions_dir>
+
+With --ephemeral, a disposable Supabase preview branch is created for the run
+and deleted afterwards (use --keep to leave it for debugging). The generated
+env files are never touched; variables are passed to the test process and also
+written to a temporary file exposed as DRIFT_TEST_ENV_FILE.
+
+Example config:
+  test:
+    commands:
+      unit: xcodebuild test -scheme "MyApp (Debug)" -destination "platform=iOS Simulator,name=iPhone 16"
+      e2e: npm run test:e2e`,
+	Example: `  drift test
+  drift test e2e --ephemeral
+  drift test functions --branch dev`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTest,
+}
+
+var (
+	testBranchFlag          string
+	testEphemeralFlag       bool
+	testKeepFlag            bool
+	testTimeoutFlag         time.Duration
+	testAllowProductionFlag bool
+)
+
+func init() {
+	testCmd.Flags().StringVarP(&testBranchFlag, "branch", "b", "", "Run against a specific Supabase branch")
+	testCmd.Flags().BoolVar(&testEphemeralFlag, "ephemeral", false, "Create a disposable Supabase branch for this run")
+	testCmd.Flags().BoolVar(&testKeepFlag, "keep", false, "Keep the ephemeral branch after the run")
+	testCmd.Flags().DurationVar(&testTimeoutFlag, "timeout", 10*time.Minute, "How long to wait for the ephemeral branch to become ready")
+	testCmd.Flags().BoolVar(&testAllowProductionFlag, "allow-production", false, "Allow running tests against production")
+	rootCmd.AddCommand(testCmd)
+}
+
+func runTest(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	suite := "unit"
+	if len(args) > 0 {
+		suite = args[0]
+	}
+	if testEphemeralFlag && testBranchFlag != "" {
+		return fmt.Errorf("--ephemeral and --branch cannot be used together")
+	}
+
+	gitBranch, err := git.CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current git branch: %w", err)
+	}
+
+	if err := ensureSupabaseLinked(cfg); err != nil {
+		return err
+	}
+	client := supabase.NewClient()
+
+	var info *supabase.BranchInfo
+	if testEphemeralFlag {
+		name := fmt.Sprintf("drift-test-%d", time.Now().Unix())
+		teardown, err := createEphemeralTestBranch(client, name)
+		if teardown != nil {
+			defer teardown()
+		}
+		if err != nil {
+			return err
+		}
+		info, err = ResolveSupabaseTarget(client, ResolveTargetOptions{GitBranch: name})
+		if err != nil {
+			return err
+		}
+		info.GitBranch = gitBranch
+	} else {
+		sp := ui.NewSpinner("Resolving Supabase branch")
+		sp.Start()
+		info, err = ResolveSupabaseTargetForCurrentBranch(client, cfg, gitBranch, testBranchFlag)
+		if err != nil {
+			sp.Fail("Failed to resolve Supabase branch")
+			return err
+		}
+		sp.Stop()
+	}
+
+	if info.Environment == supabase.EnvProduction && !testAllowProductionFlag {
+		return fmt.Errorf("refusing to run tests against production; use --ephemeral, --branch, or --allow-production")
+	}
+
+	command, err := testCommandFor(cfg, info, suite)
+	if err != nil {
+		return err
+	}
+
+	sp := ui.NewSpinner("Fetching API keys")
+	sp.Start()
+	values, err := fetchTestEnv(client, cfg, info)
+	if err != nil {
+		sp.Fail("Failed to fetch API keys")
+		return err
+	}
+	sp.Stop()
+
+	envFile, err := writeTestEnvFile(values)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(envFile)
+	values["DRIFT_TEST_ENV_FILE"] = envFile
+
+	ui.KeyValue("Suite", suite)
+	ui.KeyValue("Environment", envColorString(string(info.Environment)))
+	ui.KeyValue("Supabase Branch", ui.Cyan(info.SupabaseBranch.Name))
+	ui.KeyValue("Command", command)
+	ui.NewLine()
+
+	child := exec.Command("sh", "-c", command)
+	child.Dir = cfg.ProjectRoot()
+	child.Env = mergeEnv(os.Environ(), values)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	runErr := child.Run()
+
+	ui.NewLine()
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		ui.Errorf("%s tests failed (exit %d)", suite, exitErr.ExitCode())
+		return fmt.Errorf("%s tests failed", suite)
+	}
+	if runErr != nil {
+		return fmt.Errorf("failed to run tests: %w", runErr)
+	}
+	ui.Successf("%s tests passed", suite)
+	return nil
+}
+
+// createEphemeralTestBranch creates a preview branch and waits for it to be ready.
+// The returned teardown deletes the branch (unless --keep) and is non-nil
+// whenever the branch may exist, even if err is set.
+func createEphemeralTestBranch(client *supabase.Client, name string) (func(), error) {
+	sp := ui.NewSpinner(fmt.Sprintf("Creating ephemeral branch %s", name))
+	sp.Start()
+	if _, err := client.CreateBranch(name); err != nil {
+		sp.Fail("Failed to create ephemeral branch")
+		return nil, err
+	}
+
+	// Delete the branch even if the run is interrupted.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, syscall.SIGINT, syscall.SIGTERM)
+
+	var once sync.Once
+	teardown := func() {
+		once.Do(func() {
+			signal.Stop(interrupts)
+			if testKeepFlag {
+				ui.Infof("Keeping ephemeral branch %s (--keep)", name)
+				return
+			}
+			dsp := ui.NewSpinner(fmt.Sprintf("Deleting ephemeral branch %s", name))
+			dsp.Start()
+			if err := client.DeleteBranch(name); err != nil {
+				dsp.Fail(fmt.Sprintf("Failed to delete %s: %v", name, err))
+				return
+			}
+			dsp.Success(fmt.Sprintf("Deleted ephemeral branch %s", name))
+		})
+	}
+	go func() {
+		if _, ok := <-interrupts; ok {
+			ui.NewLine()
+			teardown()
+			os.Exit(130)
+		}
+	}()
+
+	if _, err := client.WaitForBranch(name, supabase.WaitOptions{Timeout: testTimeoutFlag, Interval: 10 * time.Second}); err != nil {
+		sp.Fail("Ephemeral branch did not become ready")
+		return teardown, err
+	}
+	sp.Success(fmt.Sprintf("Ephemeral branch %s ready", name))
+	return teardown, nil
+}
+
+// testCommandFor returns the shell command for a suite, falling back to project defaults.
+func testCommandFor(cfg *config.Config, info *supabase.BranchInfo, suite string) (string, error) {
+	if command, ok := cfg.Test.Commands[suite]; ok && strings.TrimSpace(command) != "" {
+		return command, nil
+	}
+
+	switch suite {
+	case "unit":
+		if cfg.Project.IsWebPlatform() {
+			return "npm test", nil
+		}
+		scheme := getSchemeForEnvironment(cfg, info)
+		if scheme == "" {
+			return "", fmt.Errorf("could not determine Xcode scheme; set test.commands.unit in .drift.yaml")
+		}
+		return fmt.Sprintf("xcodebuild test -scheme %q", scheme), nil
+	case "functions":
+		command := "deno test --allow-all"
+		for _, path := range functionRootPaths(cfg) {
+			command += fmt.Sprintf(" %q", path)
+		}
+		return command, nil
+	}
+
+	return "", fmt.Errorf("no command configured for suite '%s'; set test.commands.%s in .drift.yaml", suite, suite)
+}
+
+// fetchTestEnv gathers the Supabase credentials exported to the test process.
+func fetchTestEnv(client *supabase.Client, cfg *config.Config, info *supabase.BranchInfo) (map[string]string, error) {
+	var secrets *supabase.BranchSecrets
+	if info.Environment != supabase.EnvProduction {
+		secrets, _ = client.GetBranchSecrets(info.SupabaseBranch.Name)
+	}
+	if secrets == nil || secrets.SupabaseAnonKey == "" {
+		anonKey, err := client.GetAnonKey(info.ProjectRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get anon key: %w", err)
+		}
+		serviceKey, _ := client.GetServiceKey(info.ProjectRef)
+		secrets = &supabase.BranchSecrets{
+			SupabaseAnonKey:        anonKey,
+			SupabaseServiceRoleKey: serviceKey,
+		}
+	}
+	publicPrefix := ""
+	if cfg.Project.IsWebPlatform() {
+		publicPrefix = web.PublicPrefix(cfg.Web.Framework)
+	}
+	return testEnvValues(info, secrets, publicPrefix), nil
+}
+
+// testEnvValues maps resolved branch credentials onto the variables tests read.
+// A non-empty publicPrefix (e.g. NEXT_PUBLIC_) also sets the client-side names.
+func testEnvValues(info *supabase.BranchInfo, secrets *supabase.BranchSecrets, publicPrefix string) map[string]string {
+	values := map[string]string{
+		"SUPABASE_URL":         info.APIURL,
+		"SUPABASE_ANON_KEY":    secrets.SupabaseAnonKey,
+		"GIT_BRANCH_NAME":      info.GitBranch,
+		"SUPABASE_BRANCH_NAME": info.SupabaseBranch.Name,
+		"DRIFT_ENVIRONMENT":    string(info.Environment),
+	}
+	if secrets.SupabaseServiceRoleKey != "" {
+		values["SUPABASE_SERVICE_ROLE_KEY"] = secrets.SupabaseServiceRoleKey
+	}
+	if secrets.PostgresURLNonPooling != "" {
+		values["DATABASE_URL"] = secrets.PostgresURLNonPooling
+	}
+	if secrets.PostgresURL != "" {
+		values["DATABASE_URL_POOLER"] = secrets.PostgresURL
+	}
+	if publicPrefix != "" {
+		values[publicPrefix+"SUPABASE_URL"] = values["SUPABASE_URL"]
+		values[publicPrefix+"SUPABASE_ANON_KEY"] = values["SUPABASE_ANON_KEY"]
+	}
+	return values
+}
+
+// writeTestEnvFile writes values to a private temporary KEY=VALUE file.
+func writeTestEnvFile(values map[string]string) (string, error) {
+	f, err := os.CreateTemp("", "drift-test-*.env")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary env file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(formatEnvFile(values)); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write temporary env file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// formatEnvFile renders values as sorted KEY=VALUE lines.
+func formatEnvFile(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", k, values[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func TestTestCommandFor(t *testing.T) {
+	info := &supabase.BranchInfo{Environment: supabase.EnvFeature}
+
+	web := &config.Config{Project: config.ProjectConfig{Type: config.ProjectTypeWeb}}
+	if got, err := testCommandFor(web, info, "unit"); err != nil || got != "npm test" {
+		t.Errorf("web unit = %q, %v; want npm test", got, err)
+	}
+
+	web.Test.Commands = map[string]string{"unit": "pnpm vitest run", "e2e": "npx playwright test"}
+	if got, _ := testCommandFor(web, info, "unit"); got != "pnpm vitest run" {
+		t.Errorf("configured unit = %q, want pnpm vitest run", got)
+	}
+	if got, _ := testCommandFor(web, info, "e2e"); got != "npx playwright test" {
+		t.Errorf("configured e2e = %q, want npx playwright test", got)
+	}
+
+	ios := &config.Config{
+		Project: config.ProjectConfig{Type: config.ProjectTypeIOS},
+		Xcode:   config.XcodeConfig{Schemes: map[string]string{"feature": "MyApp (Debug)"}},
+	}
+	if got, _ := testCommandFor(ios, info, "unit"); got != `xcodebuild test -scheme "MyApp (Debug)"` {
+		t.Errorf("ios unit = %q", got)
+	}
+	if _, err := testCommandFor(ios, info, "e2e"); err == nil {
+		t.Error("expected error for unconfigured e2e suite")
+	}
+}
+
+func TestTestEnvValues(t *testing.T) {
+	info := &supabase.BranchInfo{
+		GitBranch:      "feat/login",
+		SupabaseBranch: &supabase.Branch{Name: "drift-test-1"},
+		Environment:    supabase.EnvFeature,
+		APIURL:         "https://abc.supabase.co",
+	}
+	secrets := &supabase.BranchSecrets{
+		SupabaseAnonKey:       "anon",
+		PostgresURLNonPooling: "postgresql://direct",
+	}
+
+	values := testEnvValues(info, secrets, "NEXT_PUBLIC_")
+	want := map[string]string{
+		"SUPABASE_URL":                  "https://abc.supabase.co",
+		"SUPABASE_ANON_KEY":             "anon",
+		"SUPABASE_BRANCH_NAME":          "drift-test-1",
+		"DRIFT_ENVIRONMENT":             "Feature",
+		"DATABASE_URL":                  "postgresql://direct",
+		"NEXT_PUBLIC_SUPABASE_URL":      "https://abc.supabase.co",
+		"NEXT_PUBLIC_SUPABASE_ANON_KEY": "anon",
+	}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %q, want %q", k, values[k], v)
+		}
+	}
+	if _, ok := values["SUPABASE_SERVICE_ROLE_KEY"]; ok {
+		t.Error("SUPABASE_SERVICE_ROLE_KEY should be omitted when empty")
+	}
+
+	path, err := writeTestEnvFile(values)
+	if err != nil {
+		t.Fatalf("writeTestEnvFile() error = %v", err)
+	}
+	defer os.Remove(path)
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "SUPABASE_ANON_KEY=anon\n") {
+		t.Errorf("env file missing SUPABASE_ANON_KEY: %s", data)
+	}
+}
@@ -35,7 +35,10 @@ Use --claude to filter sessions that have Claude Code running.`,
   drift tmux attach       # Attach to session (from outside tmux)
   drift tmux switch       # Switch session (from inside tmux)
   drift tmux switch --claude # Switch to Claude session
-  drift tmux kill         # Kill a session (interactive)`,
+  drift tmux kill         # Kill a session (interactive)
+  drift tmux send feat/x -- make build # Run a command in a session
+  drift tmux save         # Save worktree session layouts
+  drift tmux restore      # Recreate them, e.g. after a reboot`,
 	RunE: runTmuxInteractive,
 }
 
@@ -236,10 +239,7 @@ func getCurrentWorktreeName() string {
 		// Get current branch
 		branch, err := git.CurrentBranch()
 		if err == nil {
-			// Sanitize branch name for tmux
-			sanitized := strings.ReplaceAll(branch, "/", "-")
-			sanitized = strings.ReplaceAll(sanitized, ".", "-")
-			return fmt.Sprintf("%s-%s", cfg.Project.Name, sanitized)
+			return branchSessionName(cfg.Project.Name, branch)
 		}
 	}
 
@@ -251,6 +251,14 @@ func getCurrentWorktreeName() string {
 	return filepath.Base(cwd)
 }
 
+// branchSessionName returns the tmux session name drift uses for a project branch.
+func branchSessionName(project, branch string) string {
+	// Sanitize branch name for tmux
+	sanitized := strings.ReplaceAll(branch, "/", "-")
+	sanitized = strings.ReplaceAll(sanitized, ".", "-")
+	return fmt.Sprintf("%s-%s", project, sanitized)
+}
+
 // getCurrentTmuxSession returns the name of the current tmux session.
 func getCurrentTmuxSession() (string, error) {
 	result, err := shell.Run("tmux", "display-message", "-p", "#S")
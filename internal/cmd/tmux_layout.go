@@ -0,0 +1,403 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var tmuxSaveCmd = &cobra.Command{
+	Use:   "save [branch]",
+	Short: "Save the window and pane layout of worktree sessions",
+	Long: `Snapshot the windows, pane layouts, and pane working directories of every
+drift-managed tmux session, one file per worktree, in .drift/tmux/ of the main
+worktree. With a branch, only that worktree's session is saved.
+
+A drift-managed session is one named after a worktree's branch or directory
+(see 'drift tmux send'). Restore the layouts with 'drift tmux restore', e.g.
+after a reboot. Programs running in panes are not saved.`,
+	Example: `  drift tmux save
+  drift tmux save feat/login`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTmuxSave,
+}
+
+var tmuxRestoreCmd = &cobra.Command{
+	Use:   "restore [branch]",
+	Short: "Recreate worktree sessions saved with 'drift tmux save'",
+	Long: `Recreate the tmux sessions saved with 'drift tmux save', detached, with
+their windows, pane layouts, and working directories. With a branch, only that
+worktree's session is restored.
+
+Sessions that are already running are left alone, and snapshots for
+worktrees that no longer exist are skipped.`,
+	Example: `  drift tmux restore
+  drift tmux restore feat/login`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTmuxRestore,
+}
+
+func init() {
+	tmuxCmd.AddCommand(tmuxSaveCmd)
+	tmuxCmd.AddCommand(tmuxRestoreCmd)
+}
+
+// tmuxSnapshot is the saved layout of one worktree's session.
+type tmuxSnapshot struct {
+	Session  string               `json:"session"`
+	Branch   string               `json:"branch"`
+	Worktree string               `json:"worktree"`
+	SavedAt  time.Time            `json:"saved_at"`
+	Windows  []tmuxWindowSnapshot `json:"windows"`
+}
+
+type tmuxWindowSnapshot struct {
+	Index  int                `json:"index"`
+	Name   string             `json:"name"`
+	Layout string             `json:"layout"`
+	Active bool               `json:"active,omitempty"`
+	Panes  []tmuxPaneSnapshot `json:"panes"`
+}
+
+type tmuxPaneSnapshot struct {
+	Index  int    `json:"index"`
+	Dir    string `json:"dir"`
+	Active bool   `json:"active,omitempty"`
+}
+
+// tmuxPaneFormat is the list-panes format parseTmuxPanes reads. The layout
+// and name may contain commas and spaces but not tabs.
+const tmuxPaneFormat = "#{window_index}\t#{window_name}\t#{window_layout}\t#{window_active}\t#{pane_index}\t#{pane_current_path}\t#{pane_active}"
+
+// parseTmuxPanes builds the windows of a snapshot from list-panes output in
+// tmuxPaneFormat, ordered by window and pane index.
+func parseTmuxPanes(output string) ([]tmuxWindowSnapshot, error) {
+	byIndex := make(map[int]*tmuxWindowSnapshot)
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.Split(line, "\t")
+		if len(parts) != 7 {
+			return nil, fmt.Errorf("unexpected list-panes line: %q", line)
+		}
+		windowIndex, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("bad window index in %q", line)
+		}
+		paneIndex, err := strconv.Atoi(parts[4])
+		if err != nil {
+			return nil, fmt.Errorf("bad pane index in %q", line)
+		}
+
+		w, ok := byIndex[windowIndex]
+		if !ok {
+			w = &tmuxWindowSnapshot{Index: windowIndex, Name: parts[1], Layout: parts[2], Active: parts[3] == "1"}
+			byIndex[windowIndex] = w
+		}
+		w.Panes = append(w.Panes, tmuxPaneSnapshot{Index: paneIndex, Dir: parts[5], Active: parts[6] == "1"})
+	}
+
+	windows := make([]tmuxWindowSnapshot, 0, len(byIndex))
+	for _, w := range byIndex {
+		sort.Slice(w.Panes, func(i, j int) bool { return w.Panes[i].Index < w.Panes[j].Index })
+		windows = append(windows, *w)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Index < windows[j].Index })
+	return windows, nil
+}
+
+// tmuxLayoutRoot returns the main worktree, whose .drift/tmux holds the
+// snapshots for every worktree.
+func tmuxLayoutRoot() (string, error) {
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		return "", fmt.Errorf("failed to find main worktree: %w", err)
+	}
+	return mainPath, nil
+}
+
+// tmuxLayoutWorktrees returns the worktrees to save or restore: all of them,
+// or the one for branch.
+func tmuxLayoutWorktrees(branch string) ([]git.Worktree, error) {
+	if branch != "" {
+		wt, err := git.GetWorktree(branch)
+		if err != nil {
+			return nil, err
+		}
+		return []git.Worktree{*wt}, nil
+	}
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	var result []git.Worktree
+	for _, wt := range worktrees {
+		if !wt.IsBare && wt.Branch != "" {
+			result = append(result, wt)
+		}
+	}
+	return result, nil
+}
+
+func tmuxProjectName() string {
+	if cfg, err := config.Load(); err == nil {
+		return cfg.Project.Name
+	}
+	return ""
+}
+
+func runTmuxSave(cmd *cobra.Command, args []string) error {
+	if !shell.CommandExists("tmux") {
+		ui.Error("tmux is not installed")
+		ui.Info("Install with: brew install tmux")
+		return fmt.Errorf("tmux not found")
+	}
+
+	branch := ""
+	if len(args) > 0 {
+		branch = args[0]
+	}
+	root, err := tmuxLayoutRoot()
+	if err != nil {
+		return err
+	}
+	worktrees, err := tmuxLayoutWorktrees(branch)
+	if err != nil {
+		return err
+	}
+	sessions, err := listTmuxSessions()
+	if err != nil {
+		return err
+	}
+	project := tmuxProjectName()
+
+	saved := 0
+	for i := range worktrees {
+		wt := &worktrees[i]
+		session, ok := resolveSendSession(wt.Branch, sessions, project, wt)
+		if !ok {
+			continue
+		}
+		result, err := shell.Run("tmux", "list-panes", "-s", "-t", session, "-F", tmuxPaneFormat)
+		if err != nil || result.ExitCode != 0 {
+			ui.Warning(fmt.Sprintf("Could not read layout of %s: %s", session, strings.TrimSpace(result.Stderr)))
+			continue
+		}
+		windows, err := parseTmuxPanes(result.Stdout)
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Could not read layout of %s: %v", session, err))
+			continue
+		}
+
+		snapshot := tmuxSnapshot{
+			Session:  session,
+			Branch:   wt.Branch,
+			Worktree: wt.Path,
+			SavedAt:  time.Now(),
+			Windows:  windows,
+		}
+		path := state.Path(root, state.Tmux, state.BranchKey(wt.Branch)+".json")
+		if err := state.WriteJSON(root, path, snapshot); err != nil {
+			return fmt.Errorf("failed to save %s: %w", session, err)
+		}
+		ui.Successf("Saved %s (%d windows)", session, len(windows))
+		saved++
+	}
+
+	if saved == 0 {
+		if branch != "" {
+			return fmt.Errorf("no tmux session running for %s", branch)
+		}
+		ui.Info("No worktree tmux sessions running")
+		return nil
+	}
+	ui.Infof("Restore with: drift tmux restore")
+	return nil
+}
+
+// loadTmuxSnapshots reads the saved snapshots, or only branch's.
+func loadTmuxSnapshots(root, branch string) ([]tmuxSnapshot, error) {
+	dir := state.Path(root, state.Tmux)
+	var paths []string
+	if branch != "" {
+		paths = []string{filepath.Join(dir, state.BranchKey(branch)+".json")}
+	} else {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+				paths = append(paths, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+
+	var snapshots []tmuxSnapshot
+	for _, path := range paths {
+		var s tmuxSnapshot
+		ok, err := state.ReadJSON(path, &s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if ok {
+			snapshots = append(snapshots, s)
+		}
+	}
+	return snapshots, nil
+}
+
+func runTmuxRestore(cmd *cobra.Command, args []string) error {
+	if !shell.CommandExists("tmux") {
+		ui.Error("tmux is not installed")
+		ui.Info("Install with: brew install tmux")
+		return fmt.Errorf("tmux not found")
+	}
+
+	branch := ""
+	if len(args) > 0 {
+		branch = args[0]
+	}
+	root, err := tmuxLayoutRoot()
+	if err != nil {
+		return err
+	}
+	snapshots, err := loadTmuxSnapshots(root, branch)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		if branch != "" {
+			return fmt.Errorf("no saved layout for %s", branch)
+		}
+		ui.Info("No saved tmux layouts")
+		ui.Info("Save the current ones with: drift tmux save")
+		return nil
+	}
+
+	sessions, err := listTmuxSessions()
+	if err != nil {
+		return err
+	}
+	running := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		running[s.Name] = true
+	}
+
+	restored, failed := 0, 0
+	for _, snapshot := range snapshots {
+		if running[snapshot.Session] {
+			ui.Infof("%s is already running", snapshot.Session)
+			continue
+		}
+		if _, err := os.Stat(snapshot.Worktree); err != nil {
+			ui.Warningf("Skipping %s: worktree %s no longer exists", snapshot.Session, snapshot.Worktree)
+			continue
+		}
+		if err := restoreTmuxSnapshot(snapshot); err != nil {
+			ui.Warning(err.Error())
+			failed++
+			continue
+		}
+		ui.Successf("Restored %s (%d windows)", snapshot.Session, len(snapshot.Windows))
+		restored++
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to restore %d sessions", failed)
+	}
+	if restored > 0 {
+		ui.Infof("Attach with: drift tmux attach")
+	}
+	return nil
+}
+
+// restoreTmuxSnapshot recreates a session, detached. Windows are created in
+// saved order, each pane is split off in its saved directory (or the
+// worktree, if that is gone), and the saved layout is applied on top.
+func restoreTmuxSnapshot(s tmuxSnapshot) error {
+	dir := func(p tmuxPaneSnapshot) string {
+		if p.Dir != "" {
+			if info, err := os.Stat(p.Dir); err == nil && info.IsDir() {
+				return p.Dir
+			}
+		}
+		return s.Worktree
+	}
+	tmux := func(args ...string) (string, error) {
+		result, err := shell.Run("tmux", args...)
+		if err != nil || result.ExitCode != 0 {
+			return "", fmt.Errorf("failed to restore %s: tmux %s: %s", s.Session, args[0], strings.TrimSpace(result.Stderr))
+		}
+		return strings.TrimSpace(result.Stdout), nil
+	}
+
+	windows := s.Windows
+	if len(windows) == 0 {
+		windows = []tmuxWindowSnapshot{{Panes: []tmuxPaneSnapshot{{Dir: s.Worktree}}}}
+	}
+
+	activeWindow := ""
+	for i, w := range windows {
+		panes := w.Panes
+		if len(panes) == 0 {
+			panes = []tmuxPaneSnapshot{{Dir: s.Worktree}}
+		}
+
+		args := []string{"new-window", "-d", "-t", s.Session + ":", "-P", "-F", "#{pane_id}", "-c", dir(panes[0])}
+		if i == 0 {
+			args = []string{"new-session", "-d", "-s", s.Session, "-P", "-F", "#{pane_id}", "-c", dir(panes[0])}
+		}
+		if w.Name != "" {
+			args = append(args, "-n", w.Name)
+		}
+		firstPane, err := tmux(args...)
+		if err != nil {
+			return err
+		}
+
+		activePane := firstPane
+		for _, p := range panes[1:] {
+			pane, err := tmux("split-window", "-d", "-t", firstPane, "-P", "-F", "#{pane_id}", "-c", dir(p))
+			if err != nil {
+				return err
+			}
+			if p.Active {
+				activePane = pane
+			}
+		}
+		if w.Layout != "" && len(panes) > 1 {
+			if _, err := tmux("select-layout", "-t", firstPane, w.Layout); err != nil {
+				return err
+			}
+		}
+		if _, err := tmux("select-pane", "-t", activePane); err != nil {
+			return err
+		}
+		if w.Active {
+			activeWindow = firstPane
+		}
+	}
+
+	if activeWindow != "" {
+		if _, err := tmux("select-window", "-t", activeWindow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTmuxPanes(t *testing.T) {
+	output := strings.Join([]string{
+		"2\tserver\tb25f,80x24,0,0,3\t0\t0\t/src/app/api\t1",
+		"1\teditor\t5e1c,160x48,0,0{80x48,0,0,1,79x48,81,0,2}\t1\t1\t/src/app/web\t1",
+		"1\teditor\t5e1c,160x48,0,0{80x48,0,0,1,79x48,81,0,2}\t1\t0\t/src/app\t0",
+		"",
+	}, "\n")
+
+	windows, err := parseTmuxPanes(output)
+	if err != nil {
+		t.Fatalf("parseTmuxPanes() error = %v", err)
+	}
+	if len(windows) != 2 || windows[0].Index != 1 || windows[1].Index != 2 {
+		t.Fatalf("parseTmuxPanes() windows = %+v", windows)
+	}
+	editor := windows[0]
+	if editor.Name != "editor" || !editor.Active || !strings.Contains(editor.Layout, "{80x48") {
+		t.Errorf("editor window = %+v", editor)
+	}
+	if len(editor.Panes) != 2 || editor.Panes[0].Dir != "/src/app" || !editor.Panes[1].Active {
+		t.Errorf("editor panes = %+v", editor.Panes)
+	}
+
+	if _, err := parseTmuxPanes("1\tonly three\tfields"); err == nil {
+		t.Error("parseTmuxPanes() should reject malformed lines")
+	}
+}
+
+func TestRestoreTmuxSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		`echo "$@" >> ` + calls,
+		`echo "%1"`,
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(dir, "tmux"), []byte(script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	worktree := t.TempDir()
+	snapshot := tmuxSnapshot{
+		Session:  "myapp-feat-login",
+		Worktree: worktree,
+		Windows: []tmuxWindowSnapshot{
+			{Index: 1, Name: "editor", Layout: "5e1c,160x48", Panes: []tmuxPaneSnapshot{{Dir: worktree}, {Dir: "/gone", Active: true}}},
+			{Index: 2, Name: "server", Active: true, Panes: []tmuxPaneSnapshot{{Dir: worktree}}},
+		},
+	}
+	if err := restoreTmuxSnapshot(snapshot); err != nil {
+		t.Fatalf("restoreTmuxSnapshot() error = %v", err)
+	}
+
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{
+		"new-session -d -s myapp-feat-login -P -F #{pane_id} -c " + worktree + " -n editor",
+		"split-window -d -t %1 -P -F #{pane_id} -c " + worktree,
+		"select-layout -t %1 5e1c,160x48",
+		"select-pane -t %1",
+		"new-window -d -t myapp-feat-login: -P -F #{pane_id} -c " + worktree + " -n server",
+		"select-pane -t %1",
+		"select-window -t %1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tmux calls:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
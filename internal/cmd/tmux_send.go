@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var tmuxSendCmd = &cobra.Command{
+	Use:   "send <session|branch> -- <command>",
+	Short: "Run a command in a tmux session without attaching",
+	Long: `Type a command into a tmux session and press Enter, using tmux send-keys.
+
+The target is a session name or a branch with a worktree. For a branch, the
+session is the one drift names after the project and branch, or after the
+worktree directory. With --create, a missing session is created, detached,
+in the worktree.
+
+With --all, the command is sent to every session that belongs to a worktree,
+for example to start a build in every agent session at once.`,
+	Example: `  drift tmux send feat/login -- npm run build
+  drift tmux send myapp-main --window 2 -- make test
+  drift tmux send feat/new --create -- drift env setup
+  drift tmux send --all -- git pull`,
+	RunE: runTmuxSend,
+}
+
+var (
+	tmuxSendWindow  string
+	tmuxSendCreate  bool
+	tmuxSendAll     bool
+	tmuxSendNoEnter bool
+)
+
+func init() {
+	tmuxSendCmd.Flags().StringVarP(&tmuxSendWindow, "window", "w", "", "Target window (index or name) in the session")
+	tmuxSendCmd.Flags().BoolVar(&tmuxSendCreate, "create", false, "Create the session in the worktree if it does not exist")
+	tmuxSendCmd.Flags().BoolVar(&tmuxSendAll, "all", false, "Send to every worktree session")
+	tmuxSendCmd.Flags().BoolVar(&tmuxSendNoEnter, "no-enter", false, "Type the command without pressing Enter")
+
+	tmuxCmd.AddCommand(tmuxSendCmd)
+}
+
+// splitSendArgs separates the target from the command after "--". dashAt is
+// cmd.ArgsLenAtDash(). With all set, there is no target.
+func splitSendArgs(args []string, dashAt int, all bool) (string, string, error) {
+	if dashAt < 0 {
+		return "", "", fmt.Errorf("separate the command with --, e.g. drift tmux send <session> -- make build")
+	}
+	targets, command := args[:dashAt], args[dashAt:]
+	if len(command) == 0 {
+		return "", "", fmt.Errorf("no command given after --")
+	}
+	if all {
+		if len(targets) > 0 {
+			return "", "", fmt.Errorf("--all cannot be combined with a target session")
+		}
+		return "", strings.Join(command, " "), nil
+	}
+	if len(targets) != 1 {
+		return "", "", fmt.Errorf("expected one target session or branch, got %d", len(targets))
+	}
+	return targets[0], strings.Join(command, " "), nil
+}
+
+// worktreeSessionNames returns the session names drift uses for a worktree,
+// preferred first.
+func worktreeSessionNames(project string, wt *git.Worktree) []string {
+	var names []string
+	if project != "" && wt.Branch != "" {
+		names = append(names, branchSessionName(project, wt.Branch))
+	}
+	return append(names, filepath.Base(wt.Path))
+}
+
+// resolveSendSession picks the running session for target: a session of that
+// name, else a session named for target's worktree. It returns false when no
+// session is running.
+func resolveSendSession(target string, sessions []TmuxSession, project string, wt *git.Worktree) (string, bool) {
+	running := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		running[s.Name] = true
+	}
+	if running[target] {
+		return target, true
+	}
+	if wt == nil {
+		return "", false
+	}
+	for _, name := range worktreeSessionNames(project, wt) {
+		if running[name] {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// sendKeysTarget returns the tmux target for a session and optional window.
+func sendKeysTarget(session, window string) string {
+	if window == "" {
+		return session
+	}
+	return session + ":" + window
+}
+
+// tmuxSendKeys types command literally into target, then presses Enter
+// unless enter is false.
+func tmuxSendKeys(target, command string, enter bool) error {
+	result, err := shell.Run("tmux", "send-keys", "-t", target, "-l", command)
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to send to %s: %s", target, strings.TrimSpace(result.Stderr))
+	}
+	if !enter {
+		return nil
+	}
+	result, err = shell.Run("tmux", "send-keys", "-t", target, "Enter")
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to send Enter to %s: %s", target, strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
+
+func runTmuxSend(cmd *cobra.Command, args []string) error {
+	target, command, err := splitSendArgs(args, cmd.ArgsLenAtDash(), tmuxSendAll)
+	if err != nil {
+		return err
+	}
+
+	if !shell.CommandExists("tmux") {
+		ui.Error("tmux is not installed")
+		ui.Info("Install with: brew install tmux")
+		return fmt.Errorf("tmux not found")
+	}
+
+	if tmuxSendAll {
+		sessions, err := getWorktreeSessions()
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			ui.Info("No worktree tmux sessions running")
+			return nil
+		}
+		failed := 0
+		for _, s := range sessions {
+			if err := tmuxSendKeys(sendKeysTarget(s.Name, tmuxSendWindow), command, !tmuxSendNoEnter); err != nil {
+				ui.Warning(err.Error())
+				failed++
+				continue
+			}
+			ui.Successf("Sent to %s", s.Name)
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to send to %d of %d sessions", failed, len(sessions))
+		}
+		return nil
+	}
+
+	project := ""
+	if cfg, err := config.Load(); err == nil {
+		project = cfg.Project.Name
+	}
+	wt, _ := git.GetWorktree(target)
+
+	sessions, err := listTmuxSessions()
+	if err != nil {
+		return err
+	}
+	session, ok := resolveSendSession(target, sessions, project, wt)
+	if !ok {
+		if wt == nil {
+			return fmt.Errorf("no tmux session or worktree named '%s'", target)
+		}
+		session = worktreeSessionNames(project, wt)[0]
+		if !tmuxSendCreate {
+			ui.Infof("Create it with: drift tmux send %s --create -- %s", target, command)
+			return fmt.Errorf("no tmux session running for %s", target)
+		}
+		result, err := shell.Run("tmux", "new-session", "-d", "-s", session, "-c", wt.Path)
+		if err != nil || result.ExitCode != 0 {
+			return fmt.Errorf("failed to create session: %s", result.Stderr)
+		}
+		ui.Successf("Created tmux session: %s", session)
+	}
+
+	tmuxTarget := sendKeysTarget(session, tmuxSendWindow)
+	if err := tmuxSendKeys(tmuxTarget, command, !tmuxSendNoEnter); err != nil {
+		return err
+	}
+	ui.Successf("Sent to %s", tmuxTarget)
+	return nil
+}
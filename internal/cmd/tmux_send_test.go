@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/undrift/drift/internal/git"
+)
+
+func TestSplitSendArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		dashAt      int
+		all         bool
+		wantTarget  string
+		wantCommand string
+		wantErr     bool
+	}{
+		{name: "target and command", args: []string{"feat/x", "npm", "run", "build"}, dashAt: 1, wantTarget: "feat/x", wantCommand: "npm run build"},
+		{name: "all", args: []string{"git", "pull"}, dashAt: 0, all: true, wantCommand: "git pull"},
+		{name: "no dash", args: []string{"feat/x", "make"}, dashAt: -1, wantErr: true},
+		{name: "no command", args: []string{"feat/x"}, dashAt: 1, wantErr: true},
+		{name: "no target", args: []string{"make"}, dashAt: 0, wantErr: true},
+		{name: "all with target", args: []string{"feat/x", "make"}, dashAt: 1, all: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, command, err := splitSendArgs(tt.args, tt.dashAt, tt.all)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitSendArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if target != tt.wantTarget || command != tt.wantCommand {
+				t.Errorf("splitSendArgs() = %q, %q, want %q, %q", target, command, tt.wantTarget, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestResolveSendSession(t *testing.T) {
+	wt := &git.Worktree{Path: "/src/myapp-feat-login", Branch: "feat/login"}
+
+	tests := []struct {
+		name     string
+		target   string
+		sessions []string
+		wt       *git.Worktree
+		want     string
+		wantOK   bool
+	}{
+		{name: "session name", target: "scratch", sessions: []string{"scratch"}, want: "scratch", wantOK: true},
+		{name: "branch session", target: "feat/login", sessions: []string{"myapp-feat-login"}, wt: wt, want: "myapp-feat-login", wantOK: true},
+		{name: "directory session", target: "feat/login", sessions: []string{"other", "login-wt"}, wt: &git.Worktree{Path: "/src/login-wt", Branch: "feat/login"}, want: "login-wt", wantOK: true},
+		{name: "not running", target: "feat/login", sessions: []string{"other"}, wt: wt},
+		{name: "unknown", target: "nope", sessions: []string{"other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sessions []TmuxSession
+			for _, name := range tt.sessions {
+				sessions = append(sessions, TmuxSession{Name: name})
+			}
+			got, ok := resolveSendSession(tt.target, sessions, "myapp", tt.wt)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("resolveSendSession() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if got := sendKeysTarget("myapp-main", "2"); got != "myapp-main:2" {
+		t.Errorf("sendKeysTarget() = %q", got)
+	}
+}
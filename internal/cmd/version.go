@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/appstore"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Manage the marketing version and build number",
+	Long: `Show and change MARKETING_VERSION and CURRENT_PROJECT_VERSION in the
+version file (xcode.version_file, default Version.xcconfig).
+
+drift keeps both settings between DRIFT MANAGED markers; anything else in the
+file is left alone.`,
+}
+
+var versionShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the current version and build number",
+	Args:  cobra.NoArgs,
+	RunE:  Run(runVersionShow, RequireProject),
+}
+
+var versionBumpCmd = &cobra.Command{
+	Use:   "bump [build|patch|minor|major]",
+	Short: "Increment the build number or marketing version",
+	Long: `Increment the build number (the default) or a part of the marketing
+version.
+
+Where the next build number comes from is set per environment in
+xcode.build_numbers:
+
+  local       one above CURRENT_PROJECT_VERSION (default)
+  testflight  one above the newest build in App Store Connect, so builds
+              uploaded from other machines or CI are never reused
+
+The environment is the one in the generated xcconfig, or --env. TestFlight
+lookups need apple.bundle_id and an App Store Connect API key
+(apple.app_store_connect in .drift.local.yaml).`,
+	Example: `  drift version bump
+  drift version bump --env production --commit --push
+  drift version bump minor`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"build", "patch", "minor", "major"},
+	RunE:      Run(runVersionBump, RequireProject),
+}
+
+var versionSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the marketing version or build number",
+	Example: `  drift version set --marketing 2.0.0
+  drift version set --build 100
+  drift version set --marketing 2.0.0 --build 1`,
+	Args: cobra.NoArgs,
+	RunE: Run(runVersionSet, RequireProject),
+}
+
+var (
+	versionShowJSON     bool
+	versionBumpEnv      string
+	versionBumpDryRun   bool
+	versionCommitFlag   bool
+	versionPushFlag     bool
+	versionSetMarketing string
+	versionSetBuild     int
+)
+
+func init() {
+	versionShowCmd.Flags().BoolVar(&versionShowJSON, "json", false, "Output as JSON")
+
+	versionBumpCmd.Flags().StringVar(&versionBumpEnv, "env", "", "Environment whose build number source to use (production, development, feature)")
+	versionBumpCmd.Flags().BoolVar(&versionBumpDryRun, "dry-run", false, "Show the new version without writing it")
+	for _, c := range []*cobra.Command{versionBumpCmd, versionSetCmd} {
+		c.Flags().BoolVar(&versionCommitFlag, "commit", false, "Commit the version file")
+		c.Flags().BoolVar(&versionPushFlag, "push", false, "Push after committing (implies --commit)")
+	}
+
+	versionSetCmd.Flags().StringVarP(&versionSetMarketing, "marketing", "m", "", "Marketing version (e.g. 1.2.3)")
+	versionSetCmd.Flags().IntVarP(&versionSetBuild, "build", "b", 0, "Build number")
+
+	versionCmd.AddCommand(versionShowCmd)
+	versionCmd.AddCommand(versionBumpCmd)
+	versionCmd.AddCommand(versionSetCmd)
+	rootCmd.AddCommand(versionCmd)
+}
+
+var marketingVersionRe = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+
+func runVersionShow(ctx *Context) error {
+	cfg := ctx.Config()
+	path := cfg.GetVersionFilePath()
+	info, err := xcode.ReadVersionFile(path)
+	if err != nil {
+		return errs.Configf("%v (run 'drift version set --marketing 1.0.0 --build 1' to create it)", err)
+	}
+
+	if ctx.JSON() {
+		return ctx.PrintJSON(map[string]interface{}{
+			"marketing_version": info.MarketingVersion,
+			"build_number":      info.BuildNumber,
+			"file":              path,
+		})
+	}
+
+	ui.Header("Version Info")
+	if info.MarketingVersion != "" {
+		ui.KeyValue("Marketing Version", ui.Cyan(info.MarketingVersion))
+	} else {
+		ui.KeyValue("Marketing Version", ui.Dim("(not set)"))
+	}
+	ui.KeyValue("Build Number", ui.Cyan(strconv.Itoa(info.BuildNumber)))
+	ui.KeyValue("Version File", cfg.Xcode.VersionFile)
+	for _, env := range []string{"production", "development", "feature"} {
+		if source := cfg.Xcode.BuildNumberSource(env); source != config.BuildNumberLocal {
+			ui.KeyValue("Builds ("+env+")", source)
+		}
+	}
+	return nil
+}
+
+func runVersionBump(ctx *Context) error {
+	cfg := ctx.Config()
+	path := cfg.GetVersionFilePath()
+	info, err := xcode.ReadVersionFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		info = &xcode.VersionInfo{}
+	}
+	next := *info
+
+	part := ctx.Arg(0)
+	if part == "" {
+		part = "build"
+	}
+	var message string
+	switch part {
+	case "build":
+		env := versionBumpEnv
+		if env == "" {
+			env, _ = xcode.GetCurrentEnvironment(cfg.GetXcconfigPath())
+		}
+		source := cfg.Xcode.BuildNumberSource(env)
+		next.BuildNumber, err = nextBuildNumber(source, info.BuildNumber, func() (int, error) {
+			return latestTestFlightBuild(cfg)
+		})
+		if err != nil {
+			return err
+		}
+		if env != "" {
+			ui.KeyValue("Environment", envColorString(env))
+		}
+		ui.KeyValue("Source", source)
+		ui.KeyValue("Build Number", fmt.Sprintf("%d → %s", info.BuildNumber, ui.Cyan(strconv.Itoa(next.BuildNumber))))
+		message = fmt.Sprintf("Bump build number to %d", next.BuildNumber)
+	case "major", "minor", "patch":
+		if next.MarketingVersion, err = xcode.BumpMarketingVersion(info.MarketingVersion, part); err != nil {
+			return errs.Validation(err)
+		}
+		ui.KeyValue("Marketing Version", fmt.Sprintf("%s → %s", info.MarketingVersion, ui.Cyan(next.MarketingVersion)))
+		message = fmt.Sprintf("Bump version to %s", next.MarketingVersion)
+	default:
+		return errs.Validationf("unknown version part %q (use build, patch, minor or major)", part)
+	}
+
+	if versionBumpDryRun {
+		ui.Infof("Dry run: %s not written", cfg.Xcode.VersionFile)
+		return nil
+	}
+	return writeVersion(cfg, &next, message)
+}
+
+func runVersionSet(ctx *Context) error {
+	cfg := ctx.Config()
+	marketingSet := ctx.Cmd.Flags().Changed("marketing")
+	buildSet := ctx.Cmd.Flags().Changed("build")
+	if !marketingSet && !buildSet {
+		return errs.Validationf("nothing to set (use --marketing and/or --build)")
+	}
+	if marketingSet && !marketingVersionRe.MatchString(versionSetMarketing) {
+		return errs.Validationf("invalid marketing version %q (use MAJOR.MINOR.PATCH, e.g. 1.2.3)", versionSetMarketing)
+	}
+	if buildSet && versionSetBuild < 1 {
+		return errs.Validationf("build number must be a positive integer")
+	}
+
+	info, err := xcode.ReadVersionFile(cfg.GetVersionFilePath())
+	if err != nil {
+		info = &xcode.VersionInfo{}
+	}
+	var message string
+	if marketingSet {
+		info.MarketingVersion = versionSetMarketing
+		message = "Set version to " + versionSetMarketing
+	}
+	if buildSet {
+		info.BuildNumber = versionSetBuild
+		if message == "" {
+			message = fmt.Sprintf("Set build number to %d", versionSetBuild)
+		} else {
+			message += fmt.Sprintf(" (%d)", versionSetBuild)
+		}
+	}
+	ui.KeyValue("Marketing Version", ui.Cyan(info.MarketingVersion))
+	ui.KeyValue("Build Number", ui.Cyan(strconv.Itoa(info.BuildNumber)))
+	return writeVersion(cfg, info, message)
+}
+
+// writeVersion writes info to the version file and commits (and pushes) it
+// when asked.
+func writeVersion(cfg *config.Config, info *xcode.VersionInfo, message string) error {
+	path := cfg.GetVersionFilePath()
+	if err := xcode.WriteVersionFile(path, info); err != nil {
+		return fmt.Errorf("failed to write version file: %w", err)
+	}
+	ui.Successf("%s in %s", message, cfg.Xcode.VersionFile)
+
+	if !versionCommitFlag && !versionPushFlag {
+		return nil
+	}
+	if err := git.CommitPaths(message, path); err != nil {
+		return err
+	}
+	ui.Success("Committed " + cfg.Xcode.VersionFile)
+	if versionPushFlag {
+		if err := git.Push(); err != nil {
+			return err
+		}
+		ui.Success("Pushed")
+	}
+	return nil
+}
+
+// nextBuildNumber returns the build number after current. For the
+// testflight source it is also above the newest App Store Connect build, so
+// a build uploaded from elsewhere is never reused.
+func nextBuildNumber(source string, current int, latestTestFlight func() (int, error)) (int, error) {
+	switch source {
+	case config.BuildNumberLocal:
+		return current + 1, nil
+	case config.BuildNumberTestFlight:
+		latest, err := latestTestFlight()
+		if err != nil {
+			return 0, err
+		}
+		return max(current, latest) + 1, nil
+	}
+	return 0, errs.Configf("unknown build number source %q in xcode.build_numbers (use %s or %s)",
+		source, config.BuildNumberLocal, config.BuildNumberTestFlight)
+}
+
+// latestTestFlightBuild returns the highest build number uploaded for
+// apple.bundle_id.
+func latestTestFlightBuild(cfg *config.Config) (int, error) {
+	_, builds, err := fetchTestFlightBuilds(cfg, 50)
+	if err != nil {
+		return 0, err
+	}
+	return appstore.LatestBuildNumber(builds), nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+)
+
+func TestNextBuildNumber(t *testing.T) {
+	xc := config.XcodeConfig{BuildNumbers: map[string]string{"production": "testflight"}}
+	latest := func() (int, error) { return 57, nil }
+
+	tests := []struct {
+		env     string
+		current int
+		want    int
+	}{
+		{"Production", 42, 58},  // TestFlight is ahead of the file
+		{"production", 60, 61},  // the file is ahead of TestFlight
+		{"Development", 42, 43}, // local by default
+		{"", 0, 1},
+	}
+	for _, tt := range tests {
+		got, err := nextBuildNumber(xc.BuildNumberSource(tt.env), tt.current, latest)
+		if err != nil || got != tt.want {
+			t.Errorf("nextBuildNumber(%s, %d) = %d, %v; want %d", tt.env, tt.current, got, err, tt.want)
+		}
+	}
+
+	failing := func() (int, error) { return 0, errors.New("no API key") }
+	if _, err := nextBuildNumber(config.BuildNumberTestFlight, 1, failing); err == nil {
+		t.Error("expected the TestFlight error")
+	}
+	if _, err := nextBuildNumber(config.BuildNumberLocal, 1, failing); err != nil {
+		t.Errorf("local source looked up TestFlight: %v", err)
+	}
+	if _, err := nextBuildNumber("ci", 1, latest); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
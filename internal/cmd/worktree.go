@@ -9,8 +9,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
 	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
 	"github.com/undrift/drift/internal/ui"
-	"github.com/undrift/drift/pkg/shell"
+	"github.com/undrift/drift/internal/web"
 )
 
 var worktreeCmd = &cobra.Command{
@@ -49,12 +50,21 @@ branch name and select the base branch.
 The branch can be:
 - An existing local branch
 - An existing remote branch (will create a tracking branch)
-- A new branch name (will create from the selected base)`,
+- A new branch name (will create from the selected base)
+
+With --from-pr, the worktree is created from a GitHub pull request. PRs from
+this repository check out their head branch; PRs from forks are fetched into
+pr/<number>. Add --review to check out the PR head detached in a separate
+review worktree instead, leaving your branches untouched. Running the same
+command again moves the review worktree to the PR's latest commit. PR details
+come from the GitHub CLI (gh) when it is installed.`,
 	Example: `  drift worktree create
   drift worktree create feat/my-feature
   drift worktree create feat/my-feature --open
   drift worktree create fix/bug-123 --from main
-  drift worktree create feat/quick-test --no-setup`,
+  drift worktree create feat/quick-test --no-setup
+  drift worktree create --from-pr 142
+  drift worktree create --from-pr 142 --review`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runWorktreeCreate,
 }
@@ -62,10 +72,22 @@ The branch can be:
 
 var wtOpenCmd = &cobra.Command{
 	Use:   "open [branch]",
-	Short: "Open worktree in VS Code",
-	Long:  `Open a worktree in VS Code. If no branch is specified, shows an interactive picker.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runWorktreeOpen,
+	Short: "Open worktree in an editor or terminal",
+	Long: `Open a worktree in an editor or terminal. If no branch is specified, shows an interactive picker.
+
+The opener comes from --with, else preferences.opener, else preferences.editor,
+else VS Code. Builtin openers: vscode, cursor, iterm, wezterm, tmux, ghostty,
+terminal, finder. Define more (or override these) under preferences.openers
+in .drift.local.yaml with argument templates using {path} and {name}.
+
+--wait blocks until the editor or window is closed, for use as a git or
+script hook. Openers need wait_args to support it.`,
+	Example: `  drift worktree open feat/new-ui
+  drift worktree open feat/new-ui --with cursor
+  drift worktree open feat/new-ui --with tmux
+  drift worktree open feat/new-ui --wait`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWorktreeOpen,
 }
 
 var wtDeleteCmd = &cobra.Command{
@@ -79,16 +101,22 @@ var wtDeleteCmd = &cobra.Command{
 var wtPathCmd = &cobra.Command{
 	Use:   "path <branch>",
 	Short: "Print absolute path to worktree",
-	Long:  `Print the absolute path to a worktree directory.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runWorktreePath,
+	Long: `Print the absolute path to a worktree directory.
+
+See 'drift shell-init' for a dcd function that cds there.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorktreeBranches,
+	RunE:              runWorktreePath,
 }
 
 var wtPruneCmd = &cobra.Command{
 	Use:   "prune",
 	Short: "Clean stale worktree entries",
-	Long:  `Remove stale worktree entries for worktrees that no longer exist on disk.`,
-	RunE:  runWorktreePrune,
+	Long: `Remove stale worktree entries for worktrees that no longer exist on disk.
+
+Locked worktrees, duplicate checkouts and broken .git links are left alone;
+use 'drift worktree doctor' for those.`,
+	RunE: runWorktreePrune,
 }
 
 var wtInfoCmd = &cobra.Command{
@@ -135,17 +163,24 @@ var (
 	wtFinderFlag  bool
 	wtTermFlag    bool
 	wtNoSetupFlag bool
+	wtWithFlag    string
+	wtWaitFlag    bool
 )
 
 func init() {
 	// Create flags
 	wtCreateCmd.Flags().StringVar(&wtFromFlag, "from", "development", "Base branch for new branches")
-	wtCreateCmd.Flags().BoolVar(&wtOpenFlag, "open", false, "Open in VS Code after setup")
+	wtCreateCmd.Flags().BoolVar(&wtOpenFlag, "open", false, "Open with the configured opener after setup")
 	wtCreateCmd.Flags().BoolVar(&wtNoSetupFlag, "no-setup", false, "Skip file copying and environment setup")
 
 	// Open flags
-	wtOpenCmd.Flags().BoolVar(&wtFinderFlag, "finder", false, "Open in Finder instead of VS Code")
-	wtOpenCmd.Flags().BoolVar(&wtTermFlag, "terminal", false, "Open in Terminal instead of VS Code")
+	wtOpenCmd.Flags().BoolVar(&wtFinderFlag, "finder", false, "Open in Finder (same as --with finder)")
+	wtOpenCmd.Flags().BoolVar(&wtTermFlag, "terminal", false, "Open in Terminal (same as --with terminal)")
+	wtOpenCmd.Flags().StringVar(&wtWithFlag, "with", "", "Opener to use (vscode, cursor, iterm, wezterm, tmux, ghostty, or a custom opener)")
+	wtOpenCmd.Flags().BoolVar(&wtWaitFlag, "wait", false, "Block until the editor or window is closed")
+	wtOpenCmd.RegisterFlagCompletionFunc("with", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeOpeners(config.LoadOrDefault()), cobra.ShellCompDirectiveNoFileComp
+	})
 
 	// Delete flags
 	wtDeleteCmd.Flags().BoolVarP(&wtForceFlag, "force", "f", false, "Force delete even with uncommitted changes")
@@ -178,10 +213,21 @@ func runWorktreeList(cmd *cobra.Command, args []string) error {
 
 	ui.Header("Git Worktrees")
 
+	stacks, err := loadWorktreeStacks()
+	if err != nil {
+		stacks = &worktreeStacks{}
+	}
+
 	for _, wt := range worktrees {
 		branchDisplay := wt.Branch
 		if branchDisplay == "" {
-			branchDisplay = "(bare)"
+			if number, ok := git.ReviewPullRequest(wt.Path); ok && !wt.IsBare {
+				branchDisplay = fmt.Sprintf("review/pr-%d", number)
+			} else if wt.IsBare {
+				branchDisplay = "(bare)"
+			} else {
+				branchDisplay = "(detached)"
+			}
 		}
 
 		// Color based on branch type
@@ -235,7 +281,12 @@ func runWorktreeList(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		fmt.Printf("  %s%s %s%s%s\n", branchColored, statusIndicators, ui.Dim(wt.Path), locked, current)
+		stacked := ""
+		if wt.Branch != "" {
+			stacked = stackLabel(stacks, wt.Branch)
+		}
+
+		fmt.Printf("  %s%s%s %s%s%s\n", branchColored, statusIndicators, stacked, ui.Dim(wt.Path), locked, current)
 	}
 
 	ui.NewLine()
@@ -243,7 +294,7 @@ func runWorktreeList(cmd *cobra.Command, args []string) error {
 
 	// Show legend
 	ui.NewLine()
-	ui.Info("Legend: " + ui.Yellow("●") + " uncommitted  " + ui.Green("↑") + " ahead  " + ui.Red("↓") + " behind")
+	ui.Info("Legend: " + ui.Yellow("●") + " uncommitted  " + ui.Green("↑") + " ahead  " + ui.Red("↓") + " behind  " + ui.Dim("⤷") + " stacked on")
 
 	return nil
 }
@@ -254,6 +305,19 @@ func runWorktreeCreate(cmd *cobra.Command, args []string) error {
 	}
 	cfg := config.LoadOrDefault()
 
+	if wtFromPRFlag > 0 {
+		if len(args) > 0 {
+			return fmt.Errorf("use either a branch or --from-pr, not both")
+		}
+		if wtStackFlag {
+			return fmt.Errorf("use either --stack or --from-pr, not both")
+		}
+		return runWorktreeCreateFromPR(cmd, cfg, wtFromPRFlag)
+	}
+	if wtReviewFlag {
+		return fmt.Errorf("--review requires --from-pr")
+	}
+
 	var branch string
 	if len(args) == 1 {
 		branch = args[0]
@@ -275,44 +339,21 @@ func runWorktreeCreate(cmd *cobra.Command, args []string) error {
 	var wtPath string
 
 	if !worktreeExists {
-		// Determine worktree path
-		wtPath = git.GetWorktreePath(cfg.Project.Name, branch, cfg.Worktree.NamingPattern)
-
-		ui.Infof("Creating worktree for branch '%s'", branch)
-		ui.KeyValue("Path", wtPath)
-
-		// Check if branch exists locally
-		if git.BranchExists(branch) {
-			ui.Info("Using existing local branch")
-			if err := git.CreateWorktree(wtPath, branch, false, ""); err != nil {
-				return err
-			}
-		} else if git.RemoteBranchExists("origin", branch) {
-			// Branch exists on remote, create tracking branch
-			ui.Info("Creating from remote branch")
-			if err := git.CreateWorktreeFromRemote(wtPath, branch, branch); err != nil {
-				return err
+		var path string
+		var err error
+		if wtStackFlag {
+			parent, parentErr := stackParent(cmd, len(args) == 0)
+			if parentErr != nil {
+				return parentErr
 			}
+			path, err = createStackedWorktree(cfg, branch, parent)
 		} else {
-			// Create new branch from base
-			ui.Infof("Creating new branch from %s", wtFromFlag)
-
-			// First ensure we have the latest from remote
-			_ = git.Fetch("origin")
-
-			baseBranch := "origin/" + wtFromFlag
-			if !git.RemoteBranchExists("origin", wtFromFlag) {
-				if git.BranchExists(wtFromFlag) {
-					baseBranch = wtFromFlag
-				} else {
-					return fmt.Errorf("base branch '%s' not found", wtFromFlag)
-				}
-			}
-
-			if err := git.CreateWorktree(wtPath, branch, true, baseBranch); err != nil {
-				return err
-			}
+			path, err = createWorktreeForBranch(cfg, branch, wtFromFlag)
 		}
+		if err != nil {
+			return err
+		}
+		wtPath = path
 
 		ui.Success(fmt.Sprintf("Worktree created at %s", wtPath))
 	} else {
@@ -325,6 +366,13 @@ func runWorktreeCreate(cmd *cobra.Command, args []string) error {
 		ui.Info("Worktree already exists, continuing with setup...")
 	}
 
+	return finishWorktreeCreate(cmd, cfg, wtPath, "")
+}
+
+// finishWorktreeCreate copies files into a new worktree, generates its env
+// config for envBranch (the worktree's own branch when empty), and opens it
+// if requested.
+func finishWorktreeCreate(cmd *cobra.Command, cfg *config.Config, wtPath, envBranch string) error {
 	// Skip setup if --no-setup flag is set
 	if wtNoSetupFlag {
 		return nil
@@ -336,31 +384,7 @@ func runWorktreeCreate(cmd *cobra.Command, args []string) error {
 	ui.SubHeader("Setting up worktree")
 
 	// Copy files
-	for _, pattern := range cfg.Worktree.CopyOnCreate {
-		matches, err := filepath.Glob(filepath.Join(mainPath, pattern))
-		if err != nil {
-			continue
-		}
-
-		for _, src := range matches {
-			filename := filepath.Base(src)
-			dst := filepath.Join(wtPath, filename)
-
-			// Copy file
-			data, err := os.ReadFile(src)
-			if err != nil {
-				ui.Warning(fmt.Sprintf("Could not read %s: %v", filename, err))
-				continue
-			}
-
-			if err := os.WriteFile(dst, data, 0644); err != nil {
-				ui.Warning(fmt.Sprintf("Could not copy %s: %v", filename, err))
-				continue
-			}
-
-			ui.Success(fmt.Sprintf("Copied %s", filename))
-		}
-	}
+	copyWorktreeFiles(mainPath, wtPath, cfg.Worktree.CopyOnCreate)
 
 	// Setup environment config if enabled
 	if cfg.Worktree.AutoSetupXcconfig {
@@ -368,11 +392,11 @@ func runWorktreeCreate(cmd *cobra.Command, args []string) error {
 		originalDir, _ := os.Getwd()
 		if err := os.Chdir(wtPath); err == nil {
 			if cfg.Project.IsWebPlatform() {
-				ui.Info("Setting up .env.local...")
+				ui.Infof("Setting up %s...", cfg.Web.EnvOutput)
 
 				// Check if main worktree has custom variables to copy
-				mainEnvPath := filepath.Join(mainPath, ".env.local")
-				if _, statErr := os.Stat(mainEnvPath); statErr == nil {
+				mainEnvPath := filepath.Join(mainPath, cfg.Web.EnvOutput)
+				if _, statErr := os.Stat(mainEnvPath); statErr == nil && cfg.Web.Framework != web.FrameworkFlutter {
 					envCopyCustomFromFlag = mainEnvPath
 				}
 			} else {
@@ -380,12 +404,13 @@ func runWorktreeCreate(cmd *cobra.Command, args []string) error {
 			}
 
 			// Run env setup in the new worktree
-			envBranchFlag = "" // Reset flag
-			if err := runEnvSetup(cmd, nil); err != nil {
+			envBranchFlag = envBranch
+			if err := runEnvSetup(worktreeContext(cmd)); err != nil {
 				ui.Warning(fmt.Sprintf("Could not setup environment config: %v", err))
 			}
 
-			// Reset the copy flag
+			// Reset the flags
+			envBranchFlag = ""
 			envCopyCustomFromFlag = ""
 
 			os.Chdir(originalDir)
@@ -396,17 +421,65 @@ func runWorktreeCreate(cmd *cobra.Command, args []string) error {
 	ui.Success("Worktree is ready!")
 	ui.KeyValue("Path", wtPath)
 
-	// Open in VS Code if requested
+	// Open with the configured opener if requested
 	if wtOpenFlag {
-		ui.Info("Opening in VS Code...")
-		if err := shell.RunInteractive("code", wtPath); err != nil {
-			ui.Warning("Could not open VS Code")
+		opener, err := resolveWorktreeOpener(cfg, "")
+		if err == nil {
+			ui.Infof("Opening with %s...", opener.Name)
+			err = openWorktree(opener, wtPath, filepath.Base(wtPath), false)
+		}
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Could not open worktree: %v", err))
 		}
 	}
 
 	return nil
 }
 
+// createWorktreeForBranch creates a worktree for branch at the configured
+// path, tracking an existing local or remote branch or branching from from.
+func createWorktreeForBranch(cfg *config.Config, branch, from string) (string, error) {
+	wtPath := git.GetWorktreePath(cfg.Project.Name, branch, cfg.Worktree.NamingPattern)
+
+	ui.Infof("Creating worktree for branch '%s'", branch)
+	ui.KeyValue("Path", wtPath)
+
+	// Check if branch exists locally
+	if git.BranchExists(branch) {
+		ui.Info("Using existing local branch")
+		if err := git.CreateWorktree(wtPath, branch, false, ""); err != nil {
+			return "", err
+		}
+	} else if git.RemoteBranchExists("origin", branch) {
+		// Branch exists on remote, create tracking branch
+		ui.Info("Creating from remote branch")
+		if err := git.CreateWorktreeFromRemote(wtPath, branch, branch); err != nil {
+			return "", err
+		}
+	} else {
+		// Create new branch from base
+		ui.Infof("Creating new branch from %s", from)
+
+		// First ensure we have the latest from remote
+		_ = git.Fetch("origin")
+
+		baseBranch := "origin/" + from
+		if !git.RemoteBranchExists("origin", from) {
+			if git.BranchExists(from) {
+				baseBranch = from
+			} else {
+				return "", fmt.Errorf("base branch '%s' not found", from)
+			}
+		}
+
+		if err := git.CreateWorktree(wtPath, branch, true, baseBranch); err != nil {
+			return "", err
+		}
+	}
+
+	return wtPath, nil
+}
+
 // selectOrCreateBranch presents an interactive menu to select an existing branch
 // or create a new one.
 func selectOrCreateBranch(cfg *config.Config) (string, error) {
@@ -557,7 +630,23 @@ func createNewBranchInteractive(_ *config.Config) (string, error) {
 }
 
 func runWorktreeOpen(cmd *cobra.Command, args []string) error {
-	var wtPath string
+	cfg := config.LoadOrDefault()
+
+	with := wtWithFlag
+	if wtFinderFlag {
+		with = "finder"
+	} else if wtTermFlag {
+		with = "terminal"
+	}
+	opener, err := resolveWorktreeOpener(cfg, with)
+	if err != nil {
+		return err
+	}
+	if wtWaitFlag && !opener.CanWait() {
+		return fmt.Errorf("opener %q cannot wait; configure wait_args for it under preferences.openers", opener.Name)
+	}
+
+	var wtPath, wtBranch string
 
 	if len(args) == 1 {
 		wt, err := git.GetWorktree(args[0])
@@ -565,6 +654,7 @@ func runWorktreeOpen(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		wtPath = wt.Path
+		wtBranch = wt.Branch
 	} else {
 		// Interactive selection
 		worktrees, err := git.ListWorktrees()
@@ -579,11 +669,13 @@ func runWorktreeOpen(cmd *cobra.Command, args []string) error {
 		// Filter out current worktree
 		options := []string{}
 		paths := []string{}
+		branches := []string{}
 		for _, wt := range worktrees {
 			if !wt.IsCurrent {
 				display := fmt.Sprintf("%s (%s)", wt.Branch, wt.Path)
 				options = append(options, display)
 				paths = append(paths, wt.Path)
+				branches = append(branches, wt.Branch)
 			}
 		}
 
@@ -597,20 +689,15 @@ func runWorktreeOpen(cmd *cobra.Command, args []string) error {
 		}
 
 		wtPath = paths[idx]
+		wtBranch = branches[idx]
 	}
 
-	if wtFinderFlag {
-		ui.Info("Opening in Finder...")
-		return shell.RunInteractive("open", wtPath)
-	}
-
-	if wtTermFlag {
-		ui.Info("Opening in Terminal...")
-		return shell.RunInteractive("open", "-a", "Terminal", wtPath)
+	if wtWaitFlag {
+		ui.Infof("Opening with %s and waiting for it to close...", opener.Name)
+	} else {
+		ui.Infof("Opening with %s...", opener.Name)
 	}
-
-	ui.Info("Opening in VS Code...")
-	return shell.RunInteractive("code", wtPath)
+	return openWorktree(opener, wtPath, wtBranch, wtWaitFlag)
 }
 
 func runWorktreeDelete(cmd *cobra.Command, args []string) error {
@@ -669,6 +756,7 @@ func runWorktreeDelete(cmd *cobra.Command, args []string) error {
 	if err := git.RemoveWorktree(wt.Path, wtForceFlag); err != nil {
 		return err
 	}
+	releaseWorktreePorts(wt.Path)
 
 	// Optionally delete branch
 	if !wtForceFlag {
@@ -678,6 +766,7 @@ func runWorktreeDelete(cmd *cobra.Command, args []string) error {
 				ui.Warning(fmt.Sprintf("Could not delete branch: %v", err))
 			} else {
 				ui.Success(fmt.Sprintf("Deleted branch '%s'", wt.Branch))
+				forgetStackedBranch(wt.Branch)
 			}
 		}
 	}
@@ -696,6 +785,26 @@ func runWorktreePath(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// completeWorktreeBranches completes the branch checked out in each
+// worktree, described by its path.
+func completeWorktreeBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var branches []string
+	for _, wt := range worktrees {
+		if wt.Branch == "" || wt.Branch == "(detached)" || !strings.HasPrefix(wt.Branch, toComplete) {
+			continue
+		}
+		branches = append(branches, wt.Branch+"\t"+wt.Path)
+	}
+	return branches, cobra.ShellCompDirectiveNoFileComp
+}
+
 func runWorktreePrune(cmd *cobra.Command, args []string) error {
 	ui.Info("Pruning stale worktree entries...")
 
@@ -740,6 +849,18 @@ func runWorktreeInfo(cmd *cobra.Command, args []string) error {
 	ui.Header("Worktree Info")
 	ui.KeyValue("Branch", ui.Cyan(wt.Branch))
 	ui.KeyValue("Path", wt.Path)
+	if number, ok := git.ReviewPullRequest(wt.Path); ok {
+		ui.KeyValue("Review", ui.Yellow(fmt.Sprintf("PR #%d (detached, read-only)", number)))
+	}
+	if stacks, err := loadWorktreeStacks(); err == nil {
+		if rec := stacks.parent(wt.Branch); rec != nil {
+			parent := ui.Cyan(rec.Parent)
+			if needsRestack(rec) {
+				parent += ui.Yellow(" (needs restack)")
+			}
+			ui.KeyValue("Stacked On", parent)
+		}
+	}
 
 	// Get ahead/behind counts
 	ahead, behind, err := git.GetAheadBehind(wt.Path, wt.Branch)
@@ -767,15 +888,22 @@ func runWorktreeInfo(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Environment detection
+	// Environment detection, from the Supabase branch mapping when available
 	env := "Feature"
 	if wt.Branch == "main" || wt.Branch == "master" {
 		env = "Production"
 	} else if wt.Branch == "development" || wt.Branch == "dev" {
 		env = "Development"
 	}
+	client := supabase.NewClient()
+	if branch, branchEnv, err := client.ResolveBranch(wt.Branch); err == nil && branch != nil {
+		env = string(branchEnv)
+		ui.KeyValue("Supabase Branch", ui.Cyan(branch.Name))
+	}
 	ui.KeyValue("Environment", envColorString(env))
 
+	printCacheBanner()
+
 	return nil
 }
 
@@ -826,8 +954,18 @@ func runWorktreeCleanup(cmd *cobra.Command, args []string) error {
 	ui.Infof("Found %d worktree(s) with merged branches:", len(cleanupCandidates))
 	ui.NewLine()
 
+	var reclaimable int64
 	for _, wt := range cleanupCandidates {
-		ui.List(fmt.Sprintf("%s → %s", ui.Cyan(wt.Branch), ui.Dim(wt.Path)))
+		size := ""
+		if du, err := measureDiskUsage(wt.Path); err == nil {
+			reclaimable += du.Total
+			size = " " + ui.Dim("("+formatDiskSize(du.Total)+")")
+		}
+		ui.List(fmt.Sprintf("%s → %s%s", ui.Cyan(wt.Branch), ui.Dim(wt.Path), size))
+	}
+	if reclaimable > 0 {
+		ui.NewLine()
+		ui.Infof("Deleting them frees about %s ('drift worktree du' shows all worktrees)", formatDiskSize(reclaimable))
 	}
 
 	ui.NewLine()
@@ -855,6 +993,7 @@ func runWorktreeCleanup(cmd *cobra.Command, args []string) error {
 			ui.Warning(fmt.Sprintf("Could not delete worktree: %v", err))
 			continue
 		}
+		releaseWorktreePorts(wt.Path)
 		ui.Success(fmt.Sprintf("Deleted worktree: %s", wt.Path))
 
 		// Ask about deleting the branch
@@ -872,6 +1011,7 @@ func runWorktreeCleanup(cmd *cobra.Command, args []string) error {
 			ui.Warning(fmt.Sprintf("Could not delete local branch: %v", err))
 		} else {
 			ui.Success(fmt.Sprintf("Deleted local branch: %s", wt.Branch))
+			forgetStackedBranch(wt.Branch)
 		}
 
 		deletedCount++
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+	"github.com/undrift/drift/internal/xcode"
+)
+
+var wtArchiveCmd = &cobra.Command{
+	Use:   "archive [branch]",
+	Short: "Archive a worktree without deleting its branch",
+	Long: `Stash away a stale worktree so it can be brought back later.
+
+This command:
+1. Pushes the branch to origin (so the work is safe off-machine)
+2. Removes the worktree directory (the local branch is kept)
+3. Records the last commit, environment, and path in .drift/archive.json
+
+Use 'drift worktree restore <branch>' to recreate it with full setup.
+If no branch is specified, shows an interactive picker.`,
+	Example: `  drift worktree archive feat/old-experiment
+  drift worktree archive --no-push       # Keep the branch local only
+  drift worktree restore feat/old-experiment`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWorktreeArchive,
+}
+
+var wtRestoreCmd = &cobra.Command{
+	Use:   "restore [branch]",
+	Short: "Restore an archived worktree with full setup",
+	Long: `Recreate a worktree recorded by 'drift worktree archive'.
+
+The worktree is created the same way as 'drift worktree create' (file copying
+and environment config included) and the archive entry is removed.
+If no branch is specified, shows a picker of archived worktrees.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWorktreeRestore,
+}
+
+var (
+	wtArchiveNoPushFlag bool
+	wtArchiveForceFlag  bool
+)
+
+func init() {
+	wtArchiveCmd.Flags().BoolVar(&wtArchiveNoPushFlag, "no-push", false, "Do not push the branch before archiving")
+	wtArchiveCmd.Flags().BoolVarP(&wtArchiveForceFlag, "force", "f", false, "Archive even with uncommitted changes (they will be lost)")
+
+	worktreeCmd.AddCommand(wtArchiveCmd)
+	worktreeCmd.AddCommand(wtRestoreCmd)
+}
+
+// archiveFile is the archive location relative to the main worktree.
+const archiveFile = state.Dir + "/archive.json"
+
+// ArchivedWorktree records a worktree removed by 'drift worktree archive'.
+type ArchivedWorktree struct {
+	Branch         string    `json:"branch"`
+	Path           string    `json:"path"`
+	Commit         string    `json:"commit"`
+	CommitSubject  string    `json:"commit_subject,omitempty"`
+	Environment    string    `json:"environment,omitempty"`
+	SupabaseBranch string    `json:"supabase_branch,omitempty"`
+	Pushed         bool      `json:"pushed"`
+	ArchivedAt     time.Time `json:"archived_at"`
+}
+
+func worktreeArchivePath() (string, error) {
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mainPath, archiveFile), nil
+}
+
+func loadWorktreeArchive() ([]ArchivedWorktree, error) {
+	path, err := worktreeArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ArchivedWorktree
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", archiveFile, err)
+	}
+	return entries, nil
+}
+
+func saveWorktreeArchive(entries []ArchivedWorktree) error {
+	path, err := worktreeArchivePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ArchivedAt.After(entries[j].ArchivedAt) })
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// worktreeEnvironment reads the environment and Supabase branch from a worktree's generated config.
+func worktreeEnvironment(cfg *config.Config, wtPath string) (env, supabaseBranch string) {
+	if cfg.Project.IsWebPlatform() {
+		path := filepath.Join(wtPath, cfg.Web.EnvOutput)
+		env, _ = web.GetCurrentEnvironment(path)
+		if values, err := web.ReadEnvLocal(path); err == nil {
+			supabaseBranch, _ = web.PublicValue(values, cfg.Supabase.EnvPrefix+"SUPABASE_BRANCH")
+		}
+		return env, supabaseBranch
+	}
+
+	path := filepath.Join(wtPath, cfg.Xcode.XcconfigOutput)
+	env, _ = xcode.GetCurrentEnvironment(path)
+	if values, err := xcode.ReadXcconfig(path); err == nil {
+		supabaseBranch = values[cfg.Supabase.EnvPrefix+"SUPABASE_BRANCH_NAME"]
+	}
+	return env, supabaseBranch
+}
+
+func runWorktreeArchive(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	var wt *git.Worktree
+	if len(args) == 1 {
+		var err error
+		wt, err = git.GetWorktree(args[0])
+		if err != nil {
+			return err
+		}
+	} else {
+		worktrees, err := git.ListWorktrees()
+		if err != nil {
+			return err
+		}
+		mainPath, _ := git.GetMainWorktreePath()
+
+		options := []string{}
+		candidates := []*git.Worktree{}
+		for i := range worktrees {
+			w := &worktrees[i]
+			if !w.IsCurrent && !w.IsBare && w.Path != mainPath {
+				options = append(options, fmt.Sprintf("%s (%s)", w.Branch, w.Path))
+				candidates = append(candidates, w)
+			}
+		}
+		if len(options) == 0 {
+			return fmt.Errorf("no worktrees available to archive")
+		}
+
+		idx, _, err := ui.PromptSelectWithIndex("Select worktree to archive", options)
+		if err != nil {
+			return err
+		}
+		wt = candidates[idx]
+	}
+
+	if err := ensureMovableWorktree(wt); err != nil {
+		return err
+	}
+
+	changes, err := git.GetUncommittedChanges(wt.Path)
+	if err == nil && changes > 0 && !wtArchiveForceFlag {
+		return fmt.Errorf("worktree has %d uncommitted change(s); commit them or use --force", changes)
+	}
+
+	entry := ArchivedWorktree{
+		Branch:     wt.Branch,
+		Path:       wt.Path,
+		Commit:     wt.Commit,
+		ArchivedAt: time.Now(),
+	}
+	entry.CommitSubject, _ = git.GetLastCommitSubject(wt.Path)
+	entry.Environment, entry.SupabaseBranch = worktreeEnvironment(cfg, wt.Path)
+
+	if !wtArchiveNoPushFlag {
+		ui.Infof("Pushing '%s' to origin", wt.Branch)
+		if err := git.PushBranch(wt.Path, "origin", wt.Branch); err != nil {
+			return fmt.Errorf("%w (use --no-push to archive without pushing)", err)
+		}
+		entry.Pushed = true
+	}
+
+	if err := archiveWorktree(wt.Path, entry, wtArchiveForceFlag); err != nil {
+		return err
+	}
+
+	ui.Successf("Archived '%s'", wt.Branch)
+	ui.KeyValue("Commit", fmt.Sprintf("%.8s %s", entry.Commit, entry.CommitSubject))
+	if entry.Environment != "" {
+		ui.KeyValue("Environment", envColorString(entry.Environment))
+	}
+	ui.Infof("Restore with: drift worktree restore %s", wt.Branch)
+	return nil
+}
+
+// archiveWorktree removes the worktree at path and records entry for it.
+// The archive is only written once the worktree is gone, so a failed removal
+// leaves no entry pointing at a worktree that still exists.
+func archiveWorktree(path string, entry ArchivedWorktree, force bool) error {
+	entries, err := loadWorktreeArchive()
+	if err != nil {
+		return err
+	}
+
+	if err := git.RemoveWorktree(path, force); err != nil {
+		return err
+	}
+	releaseWorktreePorts(path)
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.Branch != entry.Branch {
+			kept = append(kept, e)
+		}
+	}
+	if err := saveWorktreeArchive(append(kept, entry)); err != nil {
+		return fmt.Errorf("worktree removed but the archive could not be written: %w", err)
+	}
+	return nil
+}
+
+func runWorktreeRestore(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+
+	entries, err := loadWorktreeArchive()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no archived worktrees")
+	}
+
+	idx := -1
+	if len(args) == 1 {
+		for i, e := range entries {
+			if e.Branch == args[0] {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return fmt.Errorf("no archived worktree for branch '%s'", args[0])
+		}
+	} else {
+		options := make([]string, len(entries))
+		for i, e := range entries {
+			options[i] = fmt.Sprintf("%s (archived %s, %.8s)", e.Branch, e.ArchivedAt.Format("2006-01-02"), e.Commit)
+		}
+		idx, _, err = ui.PromptSelectWithIndex("Select worktree to restore", options)
+		if err != nil {
+			return err
+		}
+	}
+
+	entry := entries[idx]
+	if !git.BranchExists(entry.Branch) && !git.RemoteBranchExists("origin", entry.Branch) {
+		return fmt.Errorf("branch '%s' no longer exists locally or on origin", entry.Branch)
+	}
+
+	wtNoSetupFlag = false
+	if err := runWorktreeCreate(cmd, []string{entry.Branch}); err != nil {
+		return err
+	}
+
+	if head, err := git.GetCommitHash(entry.Branch); err == nil && entry.Commit != "" && head != entry.Commit {
+		ui.Warningf("Branch has moved since it was archived (was %.8s, now %.8s)", entry.Commit, head)
+	}
+
+	remaining := append(entries[:idx:idx], entries[idx+1:]...)
+	if err := saveWorktreeArchive(remaining); err != nil {
+		ui.Warningf("Could not update archive: %v", err)
+	}
+	return nil
+}
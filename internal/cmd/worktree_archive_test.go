@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+func fakeArchiveGit(t *testing.T, remove testutil.Response) *testutil.FakeBin {
+	t.Helper()
+	main := t.TempDir()
+	remove.Args = "worktree remove"
+	return testutil.NewFakeBin(t, "git",
+		testutil.Response{Args: "rev-parse --git-common-dir", Stdout: filepath.Join(main, ".git")},
+		remove,
+	)
+}
+
+func TestArchiveWorktree_RecordsEntryAfterRemoval(t *testing.T) {
+	fake := fakeArchiveGit(t, testutil.Response{})
+	entry := ArchivedWorktree{Branch: "feat/old", Path: "/wt/old", Commit: "abc123", ArchivedAt: time.Now()}
+
+	if err := archiveWorktree(entry.Path, entry, false); err != nil {
+		t.Fatalf("archiveWorktree() error = %v", err)
+	}
+
+	entries, err := loadWorktreeArchive()
+	if err != nil {
+		t.Fatalf("loadWorktreeArchive() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Branch != "feat/old" {
+		t.Errorf("archive = %+v, want the feat/old entry", entries)
+	}
+	removed := false
+	for _, call := range fake.CallLines() {
+		if call == "worktree remove /wt/old" {
+			removed = true
+		}
+	}
+	if !removed {
+		t.Errorf("worktree was not removed: %v", fake.CallLines())
+	}
+}
+
+func TestArchiveWorktree_NoEntryWhenRemoveFails(t *testing.T) {
+	fakeArchiveGit(t, testutil.Response{Stderr: "fatal: '/wt/old' contains modified or untracked files", Exit: 128})
+	entry := ArchivedWorktree{Branch: "feat/old", Path: "/wt/old", Commit: "abc123", ArchivedAt: time.Now()}
+
+	if err := archiveWorktree(entry.Path, entry, false); err == nil {
+		t.Fatal("archiveWorktree() succeeded when git worktree remove failed")
+	}
+
+	entries, err := loadWorktreeArchive()
+	if err != nil {
+		t.Fatalf("loadWorktreeArchive() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("archive = %+v, want no entries", entries)
+	}
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ui"
+)
+
+// copyPlaceholder matches {port}, {port:<label>}, {name}, and {path} in
+// transform replacements.
+var copyPlaceholder = regexp.MustCompile(`\{(port(?::[A-Za-z0-9_-]+)?|name|path)\}`)
+
+// copyContext expands placeholders for one worktree. Ports are allocated on
+// first use and reused, so every {port:api} in the worktree gets the same
+// number.
+type copyContext struct {
+	wtPath   string
+	ports    map[string]int
+	freePort func() (int, error)
+}
+
+func newCopyContext(wtPath string) *copyContext {
+	return &copyContext{wtPath: wtPath, ports: make(map[string]int), freePort: findFreePort}
+}
+
+// expand substitutes placeholders in s.
+func (c *copyContext) expand(s string) (string, error) {
+	var expandErr error
+	out := copyPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		key := strings.Trim(m, "{}")
+		switch key {
+		case "name":
+			return filepath.Base(c.wtPath)
+		case "path":
+			return c.wtPath
+		}
+		if port, ok := c.ports[key]; ok {
+			return strconv.Itoa(port)
+		}
+		port, err := c.uniquePort()
+		if err != nil {
+			expandErr = err
+			return m
+		}
+		c.ports[key] = port
+		return strconv.Itoa(port)
+	})
+	return out, expandErr
+}
+
+// uniquePort returns a free port not already handed out to another label.
+func (c *copyContext) uniquePort() (int, error) {
+	used := make(map[int]bool, len(c.ports))
+	for _, p := range c.ports {
+		used[p] = true
+	}
+	for i := 0; i < 10; i++ {
+		port, err := c.freePort()
+		if err != nil {
+			return 0, fmt.Errorf("failed to find a free port: %w", err)
+		}
+		if !used[port] {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("failed to find a free port")
+}
+
+// findFreePort asks the OS for a port that is free on localhost.
+func findFreePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// applyTransforms rewrites data with each transform in order. It returns the
+// transforms that matched nothing, for warnings.
+func (c *copyContext) applyTransforms(data []byte, transforms []config.CopyTransform) ([]byte, []string, error) {
+	var unmatched []string
+	for _, t := range transforms {
+		with, err := c.expand(t.With)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case t.Replace != "" && t.Regex != "":
+			return nil, nil, fmt.Errorf("transform sets both replace and regex")
+		case t.Replace != "":
+			if !strings.Contains(string(data), t.Replace) {
+				unmatched = append(unmatched, t.Replace)
+				continue
+			}
+			data = []byte(strings.ReplaceAll(string(data), t.Replace, with))
+		case t.Regex != "":
+			re, err := regexp.Compile(t.Regex)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid transform regex %q: %w", t.Regex, err)
+			}
+			if !re.Match(data) {
+				unmatched = append(unmatched, t.Regex)
+				continue
+			}
+			data = re.ReplaceAll(data, []byte(with))
+		default:
+			return nil, nil, fmt.Errorf("transform needs replace or regex")
+		}
+	}
+	return data, unmatched, nil
+}
+
+// copyDestination returns where src, a match of rule, is copied to in wtPath.
+// Destinations must stay inside the worktree.
+func copyDestination(wtPath string, rule config.CopyRule, src string) (string, error) {
+	var dst string
+	switch {
+	case rule.To == "":
+		dst = filepath.Join(wtPath, filepath.Base(src))
+	case rule.IsDirTarget():
+		dst = filepath.Join(wtPath, filepath.FromSlash(rule.To), filepath.Base(src))
+	default:
+		dst = filepath.Join(wtPath, filepath.FromSlash(rule.To))
+	}
+	rel, err := filepath.Rel(wtPath, dst)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("destination %s is outside the worktree", rule.To)
+	}
+	return dst, nil
+}
+
+// copyWorktreeFiles copies files matching worktree.copy_on_create from the
+// main worktree into a new worktree, applying each rule's destination and
+// transforms, and returns the destinations relative to the worktree.
+func copyWorktreeFiles(mainPath, wtPath string, rules []config.CopyRule) []string {
+	ctx := newCopyContext(wtPath)
+	var copied []string
+	for _, rule := range rules {
+		matches, err := filepath.Glob(filepath.Join(mainPath, rule.From))
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Invalid copy_on_create pattern %q: %v", rule.From, err))
+			continue
+		}
+
+		for _, src := range matches {
+			if info, err := os.Stat(src); err != nil || info.IsDir() {
+				continue
+			}
+			name, _ := filepath.Rel(mainPath, src)
+
+			dst, err := copyDestination(wtPath, rule, src)
+			if err != nil {
+				ui.Warning(fmt.Sprintf("Could not copy %s: %v", name, err))
+				continue
+			}
+			rel, _ := filepath.Rel(wtPath, dst)
+
+			data, err := os.ReadFile(src)
+			if err != nil {
+				ui.Warning(fmt.Sprintf("Could not read %s: %v", name, err))
+				continue
+			}
+
+			if len(rule.Transforms) > 0 {
+				var unmatched []string
+				data, unmatched, err = ctx.applyTransforms(data, rule.Transforms)
+				if err != nil {
+					ui.Warning(fmt.Sprintf("Could not transform %s: %v", name, err))
+					continue
+				}
+				for _, u := range unmatched {
+					ui.Warning(fmt.Sprintf("%s: %q matched nothing", name, u))
+				}
+			}
+
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				ui.Warning(fmt.Sprintf("Could not copy %s: %v", name, err))
+				continue
+			}
+			if err := os.WriteFile(dst, data, 0644); err != nil {
+				ui.Warning(fmt.Sprintf("Could not copy %s: %v", name, err))
+				continue
+			}
+
+			if rel == name {
+				ui.Success(fmt.Sprintf("Copied %s", rel))
+			} else {
+				ui.Success(fmt.Sprintf("Copied %s to %s", name, rel))
+			}
+			copied = append(copied, rel)
+		}
+	}
+
+	labels := make([]string, 0, len(ctx.ports))
+	for key := range ctx.ports {
+		labels = append(labels, key)
+	}
+	sort.Strings(labels)
+	for _, key := range labels {
+		label := "Port"
+		if name, ok := strings.CutPrefix(key, "port:"); ok {
+			label = fmt.Sprintf("Port (%s)", name)
+		}
+		ui.KeyValue(label, strconv.Itoa(ctx.ports[key]))
+	}
+	return copied
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/undrift/drift/internal/config"
+)
+
+func TestCopyDestination(t *testing.T) {
+	wt := filepath.Join(t.TempDir(), "app-feat")
+	tests := []struct {
+		rule    config.CopyRule
+		src     string
+		want    string
+		wantErr bool
+	}{
+		{config.CopyRule{From: ".env"}, "/main/.env", ".env", false},
+		{config.CopyRule{From: "secrets/*"}, "/main/secrets/key.p8", "key.p8", false},
+		{config.CopyRule{From: "config/dev.json", To: "apps/web/config.json"}, "/main/config/dev.json", "apps/web/config.json", false},
+		{config.CopyRule{From: "config/dev.json", To: "apps/web/"}, "/main/config/dev.json", "apps/web/dev.json", false},
+		{config.CopyRule{From: "secrets/*.p8", To: "ios/keys"}, "/main/secrets/key.p8", "ios/keys/key.p8", false},
+		{config.CopyRule{From: ".env", To: "../elsewhere/.env"}, "/main/.env", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := copyDestination(wt, tt.rule, tt.src)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("copyDestination(%+v) error = %v, wantErr %v", tt.rule, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if want := filepath.Join(wt, tt.want); got != want {
+			t.Errorf("copyDestination(%+v) = %q, want %q", tt.rule, got, want)
+		}
+	}
+}
+
+func TestCopyContextApplyTransforms(t *testing.T) {
+	next := 4000
+	ctx := newCopyContext("/work/app-feat")
+	ctx.freePort = func() (int, error) {
+		next++
+		return next, nil
+	}
+
+	data := []byte("PORT=3000\nAPI_URL=http://localhost:8080\nNAME=app\n")
+	got, unmatched, err := ctx.applyTransforms(data, []config.CopyTransform{
+		{Replace: "PORT=3000", With: "PORT={port}"},
+		{Regex: `localhost:\d+`, With: "localhost:{port:api}"},
+		{Replace: "NAME=app", With: "NAME={name}"},
+		{Replace: "MISSING=1", With: "x"},
+	})
+	if err != nil {
+		t.Fatalf("applyTransforms() error = %v", err)
+	}
+	want := "PORT=4001\nAPI_URL=http://localhost:4002\nNAME=app-feat\n"
+	if string(got) != want {
+		t.Errorf("applyTransforms() = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(unmatched, []string{"MISSING=1"}) {
+		t.Errorf("unmatched = %v, want [MISSING=1]", unmatched)
+	}
+
+	// The same label keeps its port across files.
+	got, _, err = ctx.applyTransforms([]byte("port: 3000"), []config.CopyTransform{{Replace: "3000", With: "{port}"}})
+	if err != nil || string(got) != "port: 4001" {
+		t.Errorf("applyTransforms() second file = %q, %v; want %q", got, err, "port: 4001")
+	}
+
+	if _, _, err := ctx.applyTransforms(data, []config.CopyTransform{{With: "x"}}); err == nil {
+		t.Error("applyTransforms() with neither replace nor regex: expected error")
+	}
+	if _, _, err := ctx.applyTransforms(data, []config.CopyTransform{{Regex: "(", With: "x"}}); err == nil {
+		t.Error("applyTransforms() with invalid regex: expected error")
+	}
+}
+
+func TestCopyWorktreeFiles(t *testing.T) {
+	mainPath := t.TempDir()
+	wtPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mainPath, "secrets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		".env":              "PORT=3000\n",
+		"secrets/a.p8":      "a",
+		"secrets/b.p8":      "b",
+		"config/local.json": `{"port": 3000}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(mainPath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copied := copyWorktreeFiles(mainPath, wtPath, []config.CopyRule{
+		{From: ".env", Transforms: []config.CopyTransform{{Replace: "PORT=3000", With: "PORT={name}"}}},
+		{From: "secrets/*.p8", To: "ios/keys/"},
+		{From: "config/local.json", To: "apps/web/config.json"},
+	})
+
+	want := []string{".env", filepath.Join("ios", "keys", "a.p8"), filepath.Join("ios", "keys", "b.p8"), filepath.Join("apps", "web", "config.json")}
+	if !reflect.DeepEqual(copied, want) {
+		t.Errorf("copyWorktreeFiles() = %v, want %v", copied, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wtPath, ".env")); string(data) != "PORT="+filepath.Base(wtPath)+"\n" {
+		t.Errorf(".env = %q, want transformed", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wtPath, "apps", "web", "config.json")); string(data) != `{"port": 3000}` {
+		t.Errorf("config.json = %q, want copied unchanged", data)
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var wtDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Find and repair broken worktrees",
+	Long: `Check every worktree for problems 'drift worktree prune' does not cover:
+
+  missing           the directory was deleted or moved outside git
+  stale-lock        locked by a 'git worktree add' that never finished
+  duplicate-branch  the same branch is checked out in two worktrees
+  broken-gitdir     the worktree's .git file and the repository no longer
+                    point at each other
+
+With --fix, offers a repair for each problem: remove or re-create a missing
+worktree, unlock a stale lock, detach HEAD in the later duplicate, or run
+'git worktree repair'. With --yes, the first repair is applied without
+asking.
+
+Exits non-zero while problems remain.`,
+	Example: `  drift worktree doctor
+  drift worktree doctor --fix
+  drift worktree doctor --fix --dry-run
+  drift worktree doctor --json`,
+	Args: cobra.NoArgs,
+	RunE: Run(runWorktreeDoctor, RequireProject),
+}
+
+var (
+	wtDoctorFix    bool
+	wtDoctorDryRun bool
+	wtDoctorJSON   bool
+)
+
+func init() {
+	wtDoctorCmd.Flags().BoolVar(&wtDoctorFix, "fix", false, "Offer a repair for each problem")
+	wtDoctorCmd.Flags().BoolVar(&wtDoctorDryRun, "dry-run", false, "Show the repairs without applying them")
+	wtDoctorCmd.Flags().BoolVar(&wtDoctorJSON, "json", false, "Output as JSON")
+	worktreeCmd.AddCommand(wtDoctorCmd)
+}
+
+// worktreeRepair is one way to fix a worktree problem.
+type worktreeRepair struct {
+	Description string
+	Apply       func() error
+}
+
+// worktreeRepairs returns the repairs offered for p, the preferred one
+// first.
+func worktreeRepairs(p git.WorktreeProblem) []worktreeRepair {
+	unlock := func() error {
+		if !p.Locked {
+			return nil
+		}
+		return git.UnlockWorktree(p.Path)
+	}
+
+	switch p.Kind {
+	case git.ProblemMissing:
+		remove := func() error {
+			if err := unlock(); err != nil {
+				return err
+			}
+			return git.RemoveWorktree(p.Path, true)
+		}
+		repairs := []worktreeRepair{{"Remove the worktree entry", remove}}
+		if p.Branch != "" && p.Branch != "(detached)" {
+			repairs = append(repairs, worktreeRepair{
+				fmt.Sprintf("Re-create %s on %s", p.Path, p.Branch),
+				func() error {
+					if err := remove(); err != nil {
+						return err
+					}
+					return git.CreateWorktree(p.Path, p.Branch, false, "")
+				},
+			})
+		}
+		return repairs
+	case git.ProblemStaleLock:
+		return []worktreeRepair{{"Unlock the worktree", unlock}}
+	case git.ProblemDuplicateBranch:
+		return []worktreeRepair{{
+			fmt.Sprintf("Detach HEAD in %s, keeping its files", p.Path),
+			func() error { return git.DetachWorktree(p.Path, "HEAD") },
+		}}
+	case git.ProblemBrokenGitdir:
+		return []worktreeRepair{{
+			"Run 'git worktree repair'",
+			func() error { return git.RepairWorktrees(p.Path) },
+		}}
+	}
+	return nil
+}
+
+func runWorktreeDoctor(ctx *Context) error {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	problems := git.DiagnoseWorktrees(worktrees)
+
+	if ctx.JSON() {
+		if problems == nil {
+			problems = []git.WorktreeProblem{}
+		}
+		if err := ctx.PrintJSON(problems); err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			return errs.Validationf("%d worktree problem(s) found", len(problems))
+		}
+		return nil
+	}
+
+	ui.Header("Worktree Doctor")
+	if len(problems) == 0 {
+		ui.Successf("%d worktree(s), no problems found", len(worktrees))
+		return nil
+	}
+
+	for _, p := range problems {
+		name := p.Path
+		if p.Branch != "" {
+			name = fmt.Sprintf("%s %s", p.Path, ui.Dim("("+p.Branch+")"))
+		}
+		ui.Errorf("%s  %s", ui.Yellow(string(p.Kind)), name)
+		fmt.Printf("    %s\n", ui.Dim(p.Detail))
+	}
+	ui.NewLine()
+
+	if !wtDoctorFix {
+		return errs.Validationf("%d worktree problem(s) found (run 'drift worktree doctor --fix' to repair)", len(problems))
+	}
+
+	remaining := 0
+	for _, p := range problems {
+		repair, ok, err := chooseWorktreeRepair(p)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			remaining++
+			continue
+		}
+		err = ctx.Apply(fmt.Sprintf("%s (%s)", repair.Description, p.Path), repair.Apply)
+		if err != nil {
+			ui.Warningf("%s: %v", p.Path, err)
+			remaining++
+			continue
+		}
+		if !ctx.DryRun() {
+			ui.Successf("%s: %s", p.Path, repair.Description)
+		}
+	}
+
+	if ctx.DryRun() {
+		return nil
+	}
+	if remaining > 0 {
+		return errs.Validationf("%d worktree problem(s) left unrepaired", remaining)
+	}
+	ui.NewLine()
+	ui.Success("All worktree problems repaired")
+	return nil
+}
+
+// chooseWorktreeRepair asks which repair to apply to p, or picks the first
+// one under --yes. It reports false when the problem is skipped.
+func chooseWorktreeRepair(p git.WorktreeProblem) (worktreeRepair, bool, error) {
+	repairs := worktreeRepairs(p)
+	if len(repairs) == 0 {
+		return worktreeRepair{}, false, nil
+	}
+	if IsYes() {
+		return repairs[0], true, nil
+	}
+
+	items := make([]string, 0, len(repairs)+1)
+	for _, r := range repairs {
+		items = append(items, r.Description)
+	}
+	items = append(items, "Skip")
+	idx, _, err := ui.PromptSelectWithIndex(fmt.Sprintf("%s: %s", p.Kind, p.Path), items)
+	if err != nil {
+		return worktreeRepair{}, false, errs.Cancelled("worktree doctor")
+	}
+	if idx == len(repairs) {
+		return worktreeRepair{}, false, nil
+	}
+	return repairs[idx], true, nil
+}
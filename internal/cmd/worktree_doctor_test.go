@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/undrift/drift/internal/git"
+)
+
+func TestWorktreeRepairs(t *testing.T) {
+	tests := []struct {
+		problem git.WorktreeProblem
+		want    []string
+	}{
+		{
+			git.WorktreeProblem{Kind: git.ProblemMissing, Path: "/wt/login", Branch: "feature/login"},
+			[]string{"Remove the worktree entry", "Re-create /wt/login on feature/login"},
+		},
+		{
+			git.WorktreeProblem{Kind: git.ProblemMissing, Path: "/wt/review", Branch: "(detached)"},
+			[]string{"Remove the worktree entry"},
+		},
+		{
+			git.WorktreeProblem{Kind: git.ProblemStaleLock, Path: "/wt/a", Locked: true},
+			[]string{"Unlock the worktree"},
+		},
+		{
+			git.WorktreeProblem{Kind: git.ProblemDuplicateBranch, Path: "/wt/b", Branch: "main"},
+			[]string{"Detach HEAD in /wt/b, keeping its files"},
+		},
+		{
+			git.WorktreeProblem{Kind: git.ProblemBrokenGitdir, Path: "/wt/c"},
+			[]string{"Run 'git worktree repair'"},
+		},
+	}
+	for _, tt := range tests {
+		repairs := worktreeRepairs(tt.problem)
+		if len(repairs) != len(tt.want) {
+			t.Errorf("%s %s: %d repairs, want %d", tt.problem.Kind, tt.problem.Path, len(repairs), len(tt.want))
+			continue
+		}
+		for i, r := range repairs {
+			if r.Description != tt.want[i] {
+				t.Errorf("%s repair %d = %q, want %q", tt.problem.Kind, i, r.Description, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var wtDuCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Show disk usage per worktree",
+	Long: `Show how much disk each worktree uses, with node_modules and Xcode
+DerivedData broken out, and suggest what to clean up.
+
+DerivedData counts both DerivedData directories inside the worktree and the
+worktree's folders in ~/Library/Developer/Xcode/DerivedData. A worktree is
+stale when no file outside node_modules and DerivedData has changed and
+nothing has been committed for --stale-days days.`,
+	Example: `  drift worktree du
+  drift worktree du --stale-days 14
+  drift worktree du --json | jq '.worktrees[] | select(.stale)'`,
+	Args: cobra.NoArgs,
+	RunE: Run(runWorktreeDu, RequireProject),
+}
+
+var (
+	wtDuStaleDays int
+	wtDuJSON      bool
+)
+
+func init() {
+	wtDuCmd.Flags().IntVar(&wtDuStaleDays, "stale-days", 30, "Flag worktrees untouched for this many days")
+	wtDuCmd.Flags().BoolVar(&wtDuJSON, "json", false, "Output as JSON")
+	worktreeCmd.AddCommand(wtDuCmd)
+}
+
+// diskUsage is the size of a directory tree. Total includes NodeModules and
+// DerivedData, whose directories are listed in CacheDirs. Newest is the
+// latest modification time outside them.
+type diskUsage struct {
+	Total       int64
+	NodeModules int64
+	DerivedData int64
+	CacheDirs   []string
+	Newest      time.Time
+}
+
+// worktreeUsage is one worktree in the du report.
+type worktreeUsage struct {
+	Branch       string    `json:"branch"`
+	Path         string    `json:"path"`
+	TotalBytes   int64     `json:"total_bytes"`
+	NodeModules  int64     `json:"node_modules_bytes"`
+	DerivedData  int64     `json:"derived_data_bytes"`
+	LastActivity time.Time `json:"last_activity,omitempty"`
+	Stale        bool      `json:"stale"`
+	Merged       bool      `json:"merged"`
+	Current      bool      `json:"current"`
+
+	cacheDirs []string
+}
+
+// name is the worktree's branch, or its directory name when detached.
+func (u worktreeUsage) name() string {
+	if u.Branch == "" {
+		return filepath.Base(u.Path)
+	}
+	return u.Branch
+}
+
+type worktreeDuReport struct {
+	StaleDays  int             `json:"stale_days"`
+	TotalBytes int64           `json:"total_bytes"`
+	Worktrees  []worktreeUsage `json:"worktrees"`
+}
+
+func runWorktreeDu(ctx *Context) error {
+	if wtDuStaleDays < 1 {
+		return fmt.Errorf("--stale-days must be at least 1")
+	}
+
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	merged := make(map[string]bool)
+	if branches, err := git.GetMergedBranches(); err == nil {
+		for _, b := range branches {
+			merged[b] = true
+		}
+	}
+
+	sp := ui.NewSpinner("Measuring worktrees")
+	sp.Start()
+	derived := xcodeDerivedData(xcodeDerivedDataDir())
+	now := time.Now()
+	var usages []worktreeUsage
+	for _, wt := range worktrees {
+		if wt.IsBare {
+			continue
+		}
+		du, err := measureDiskUsage(wt.Path)
+		if err != nil {
+			if IsVerbose() {
+				ui.Warningf("Could not measure %s: %v", wt.Path, err)
+			}
+			continue
+		}
+		usage := worktreeUsage{
+			Branch:       wt.Branch,
+			Path:         wt.Path,
+			TotalBytes:   du.Total,
+			NodeModules:  du.NodeModules,
+			DerivedData:  du.DerivedData,
+			LastActivity: du.Newest,
+			Merged:       wt.Branch != "" && merged[wt.Branch] && !isMainlineBranch(wt.Branch),
+			Current:      wt.IsCurrent,
+			cacheDirs:    du.CacheDirs,
+		}
+		if t, err := git.LastCommitTime(wt.Commit); wt.Commit != "" && err == nil && t.After(usage.LastActivity) {
+			usage.LastActivity = t
+		}
+		usages = append(usages, usage)
+	}
+	assignDerivedData(usages, derived)
+	for i := range usages {
+		usages[i].Stale = isStaleWorktree(usages[i], now, wtDuStaleDays)
+	}
+	sp.Stop()
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].TotalBytes != usages[j].TotalBytes {
+			return usages[i].TotalBytes > usages[j].TotalBytes
+		}
+		return usages[i].Path < usages[j].Path
+	})
+	var total int64
+	for _, u := range usages {
+		total += u.TotalBytes
+	}
+
+	if ctx.JSON() {
+		return ctx.PrintJSON(worktreeDuReport{StaleDays: wtDuStaleDays, TotalBytes: total, Worktrees: usages})
+	}
+
+	ui.Header("Worktree Disk Usage")
+	if len(usages) == 0 {
+		ui.Info("No worktrees found")
+		return nil
+	}
+
+	table := ui.NewTable([]string{"Worktree", "Total", "node_modules", "DerivedData", "Last Activity"})
+	for _, u := range usages {
+		name := u.name()
+		if u.Current {
+			name += " (current)"
+		}
+		activity := "-"
+		if !u.LastActivity.IsZero() {
+			activity = formatBackupAge(u.LastActivity)
+		}
+		switch {
+		case u.Merged:
+			name = ui.Yellow(name + " [merged]")
+		case u.Stale:
+			name = ui.Yellow(name + " [stale]")
+		}
+		table.AddRow([]string{name, formatDiskSize(u.TotalBytes), formatDiskSize(u.NodeModules), formatDiskSize(u.DerivedData), activity})
+	}
+	table.Render()
+	ui.NewLine()
+	ui.KeyValue("Total", formatDiskSize(total))
+
+	suggestions := worktreeCleanupSuggestions(usages, wtDuStaleDays)
+	if len(suggestions) == 0 {
+		ui.Success("Nothing to clean up")
+		return nil
+	}
+	ui.NewLine()
+	ui.SubHeader("Suggestions")
+	for _, s := range suggestions {
+		ui.List(s)
+	}
+	return nil
+}
+
+// isMainlineBranch reports whether branch is one cleanup never touches.
+func isMainlineBranch(branch string) bool {
+	return branch == "main" || branch == "master" || branch == "development"
+}
+
+// isStaleWorktree reports whether u has had no activity for days. The
+// current worktree, detached worktrees, and mainline branches are never
+// stale.
+func isStaleWorktree(u worktreeUsage, now time.Time, days int) bool {
+	if u.Current || u.Branch == "" || isMainlineBranch(u.Branch) || u.LastActivity.IsZero() {
+		return false
+	}
+	return now.Sub(u.LastActivity) >= time.Duration(days)*24*time.Hour
+}
+
+// worktreeCleanupSuggestions lists commands that would free the most space,
+// largest first.
+func worktreeCleanupSuggestions(usages []worktreeUsage, staleDays int) []string {
+	var merged, stale, caches []worktreeUsage
+	for _, u := range usages {
+		switch {
+		case u.Current:
+		case u.Merged:
+			merged = append(merged, u)
+		case u.Stale:
+			stale = append(stale, u)
+		case u.NodeModules+u.DerivedData > 0 && !isMainlineBranch(u.Branch):
+			caches = append(caches, u)
+		}
+	}
+
+	var out []string
+	if len(merged) > 0 {
+		var size int64
+		for _, u := range merged {
+			size += u.TotalBytes
+		}
+		out = append(out, fmt.Sprintf("%d merged worktree(s) hold %s: run 'drift worktree cleanup'", len(merged), formatDiskSize(size)))
+	}
+	for _, u := range stale {
+		out = append(out, fmt.Sprintf("%s is untouched for %d+ days and holds %s: run 'drift worktree archive %s'",
+			u.Branch, staleDays, formatDiskSize(u.TotalBytes), u.Branch))
+	}
+	for _, u := range caches {
+		// Only mention build caches worth the rebuild.
+		if u.NodeModules+u.DerivedData < 1<<30 {
+			continue
+		}
+		out = append(out, fmt.Sprintf("%s has %s of build caches; delete %s to reclaim it",
+			u.name(), formatDiskSize(u.NodeModules+u.DerivedData), strings.Join(u.cacheDirs, ", ")))
+	}
+	return out
+}
+
+// measureDiskUsage walks root without following symlinks. The .git entry is
+// skipped: for linked worktrees it is a file, and the main worktree's
+// repository is shared by all of them.
+func measureDiskUsage(root string) (diskUsage, error) {
+	var du diskUsage
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() && path != root {
+			switch d.Name() {
+			case ".git":
+				return filepath.SkipDir
+			case "node_modules":
+				size := dirSize(path)
+				du.NodeModules += size
+				du.Total += size
+				du.CacheDirs = append(du.CacheDirs, path)
+				return filepath.SkipDir
+			case "DerivedData":
+				size := dirSize(path)
+				du.DerivedData += size
+				du.Total += size
+				du.CacheDirs = append(du.CacheDirs, path)
+				return filepath.SkipDir
+			}
+		}
+		if d.Name() == ".git" && path != root {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			du.Total += info.Size()
+			if info.ModTime().After(du.Newest) {
+				du.Newest = info.ModTime()
+			}
+		}
+		return nil
+	})
+	return du, err
+}
+
+// dirSize returns the total size of the regular files under dir.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// derivedDataFolder is one project folder in Xcode's DerivedData.
+type derivedDataFolder struct {
+	Path      string
+	Workspace string
+	Size      int64
+}
+
+func xcodeDerivedDataDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "Library", "Developer", "Xcode", "DerivedData")
+}
+
+var workspacePathPlist = regexp.MustCompile(`<key>WorkspacePath</key>\s*<string>([^<]+)</string>`)
+
+// xcodeDerivedData lists the folders in dir that record which workspace
+// they were built from.
+func xcodeDerivedData(dir string) []derivedDataFolder {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var folders []derivedDataFolder
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(filepath.Join(path, "info.plist"))
+		if err != nil {
+			continue
+		}
+		m := workspacePathPlist.FindSubmatch(data)
+		if m == nil {
+			continue
+		}
+		folders = append(folders, derivedDataFolder{Path: path, Workspace: string(m[1]), Size: dirSize(path)})
+	}
+	return folders
+}
+
+// assignDerivedData adds each DerivedData folder to the worktree containing
+// its workspace, choosing the deepest worktree when paths nest.
+func assignDerivedData(usages []worktreeUsage, folders []derivedDataFolder) {
+	for _, f := range folders {
+		best := -1
+		for i, u := range usages {
+			root := filepath.Clean(u.Path)
+			if f.Workspace != root && !strings.HasPrefix(f.Workspace, root+string(filepath.Separator)) {
+				continue
+			}
+			if best < 0 || len(root) > len(usages[best].Path) {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		usages[best].DerivedData += f.Size
+		usages[best].TotalBytes += f.Size
+		usages[best].cacheDirs = append(usages[best].cacheDirs, f.Path)
+	}
+}
+
+// formatDiskSize formats a byte count for display.
+func formatDiskSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSizedFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMeasureDiskUsage(t *testing.T) {
+	root := t.TempDir()
+	writeSizedFile(t, filepath.Join(root, "src", "main.go"), 100)
+	writeSizedFile(t, filepath.Join(root, "web", "node_modules", "pkg", "index.js"), 1000)
+	writeSizedFile(t, filepath.Join(root, "build", "DerivedData", "App", "app.o"), 5000)
+	writeSizedFile(t, filepath.Join(root, ".git"), 40)
+
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "src", "main.go"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	du, err := measureDiskUsage(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if du.Total != 6100 || du.NodeModules != 1000 || du.DerivedData != 5000 {
+		t.Errorf("measureDiskUsage() = %+v, want total 6100, node_modules 1000, DerivedData 5000", du)
+	}
+	if len(du.CacheDirs) != 2 {
+		t.Errorf("CacheDirs = %v, want 2 entries", du.CacheDirs)
+	}
+	// Cache files are newer but do not count as activity.
+	if d := du.Newest.Sub(old); d < -time.Second || d > time.Second {
+		t.Errorf("Newest = %v, want %v", du.Newest, old)
+	}
+}
+
+func TestAssignDerivedData(t *testing.T) {
+	dir := t.TempDir()
+	writeSizedFile(t, filepath.Join(dir, "App-abc", "Build", "app.o"), 300)
+	writeSizedFile(t, filepath.Join(dir, "App-abc", "info.plist"), 0)
+	plist := `<?xml version="1.0"?><plist><dict>
+	<key>WorkspacePath</key>
+	<string>/src/app-feat/App.xcodeproj</string>
+</dict></plist>`
+	if err := os.WriteFile(filepath.Join(dir, "App-abc", "info.plist"), []byte(plist), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeSizedFile(t, filepath.Join(dir, "Other-def", "Build", "x.o"), 10)
+
+	folders := xcodeDerivedData(dir)
+	if len(folders) != 1 || folders[0].Workspace != "/src/app-feat/App.xcodeproj" {
+		t.Fatalf("xcodeDerivedData() = %+v", folders)
+	}
+
+	usages := []worktreeUsage{{Path: "/src/app", TotalBytes: 10}, {Path: "/src/app-feat", TotalBytes: 10}}
+	assignDerivedData(usages, folders)
+	if usages[0].DerivedData != 0 || usages[1].DerivedData != folders[0].Size || usages[1].TotalBytes != 10+folders[0].Size {
+		t.Errorf("assignDerivedData() = %+v", usages)
+	}
+}
+
+func TestIsStaleWorktree(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		u    worktreeUsage
+		want bool
+	}{
+		{"old feature", worktreeUsage{Branch: "feat/a", LastActivity: now.AddDate(0, 0, -40)}, true},
+		{"recent feature", worktreeUsage{Branch: "feat/a", LastActivity: now.AddDate(0, 0, -3)}, false},
+		{"old main", worktreeUsage{Branch: "main", LastActivity: now.AddDate(0, 0, -40)}, false},
+		{"old current", worktreeUsage{Branch: "feat/a", Current: true, LastActivity: now.AddDate(0, 0, -40)}, false},
+		{"detached", worktreeUsage{LastActivity: now.AddDate(0, 0, -40)}, false},
+		{"unknown activity", worktreeUsage{Branch: "feat/a"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStaleWorktree(tt.u, now, 30); got != tt.want {
+				t.Errorf("isStaleWorktree() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorktreeCleanupSuggestions(t *testing.T) {
+	usages := []worktreeUsage{
+		{Branch: "feat/big", Path: "/w/big", TotalBytes: 3 << 30, NodeModules: 2 << 30, cacheDirs: []string{"/w/big/node_modules"}},
+		{Branch: "feat/done", Path: "/w/done", TotalBytes: 1 << 30, Merged: true},
+		{Branch: "feat/old", Path: "/w/old", TotalBytes: 1 << 20, Stale: true},
+		{Branch: "feat/small", Path: "/w/small", TotalBytes: 1 << 20, NodeModules: 1 << 10},
+		{Branch: "feat/here", Path: "/w/here", Current: true, Stale: true},
+	}
+	got := worktreeCleanupSuggestions(usages, 30)
+	if len(got) != 3 {
+		t.Fatalf("suggestions = %q, want 3", got)
+	}
+	for i, want := range []string{"drift worktree cleanup", "drift worktree archive feat/old", "/w/big/node_modules"} {
+		if !strings.Contains(got[i], want) {
+			t.Errorf("suggestion %d = %q, want it to mention %q", i, got[i], want)
+		}
+	}
+}
+
+func TestFormatDiskSize(t *testing.T) {
+	tests := map[int64]string{512: "512 B", 2048: "2.0 KB", 5 << 20: "5.0 MB", 3 << 30: "3.0 GB"}
+	for n, want := range tests {
+		if got := formatDiskSize(n); got != want {
+			t.Errorf("formatDiskSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
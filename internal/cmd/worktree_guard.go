@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ui"
+)
+
+// worktreeMismatch is a command run from one worktree while the drift
+// project it loaded lives in another. The Supabase environment follows the
+// working directory's branch, but files are written to the other worktree.
+type worktreeMismatch struct {
+	Dir           string // worktree holding the working directory
+	Branch        string
+	ProjectDir    string // worktree holding the loaded .drift.yaml
+	ProjectBranch string
+}
+
+// detectWorktreeMismatch compares the worktrees holding cwd and projectRoot.
+// It returns nil when they are the same or either is not in a worktree.
+func detectWorktreeMismatch(worktrees []git.Worktree, cwd, projectRoot string) *worktreeMismatch {
+	here := git.ContainingWorktree(worktrees, cwd)
+	project := git.ContainingWorktree(worktrees, projectRoot)
+	if here == nil || project == nil || here.Path == project.Path {
+		return nil
+	}
+	return &worktreeMismatch{
+		Dir:           here.Path,
+		Branch:        here.Branch,
+		ProjectDir:    project.Path,
+		ProjectBranch: project.Branch,
+	}
+}
+
+// GuardWorktree warns before a command that targets an environment runs
+// from a worktree other than the one holding the loaded .drift.yaml, which
+// happens after cd-ing into a worktree nested in another one. Interactive
+// runs are asked to confirm; with --yes the warning alone is printed.
+// Set worktree.guard: false to turn the check off.
+func GuardWorktree(next RunFunc) RunFunc {
+	return func(ctx *Context) error {
+		if err := checkWorktree(ctx); err != nil {
+			return err
+		}
+		return next(ctx)
+	}
+}
+
+func checkWorktree(ctx *Context) error {
+	cfg := ctx.Config()
+	if !cfg.Worktree.GuardEnabled() || GetConfigFile() != "" {
+		return nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return nil
+	}
+	m := detectWorktreeMismatch(worktrees, cwd, cfg.ProjectRoot())
+	if m == nil {
+		return nil
+	}
+
+	ui.Warning("You are running drift from a different worktree than the project it loaded")
+	ui.KeyValue("Working directory", fmt.Sprintf("%s (%s)", m.Dir, m.Branch))
+	ui.KeyValue("Project", fmt.Sprintf("%s (%s)", m.ProjectDir, m.ProjectBranch))
+	ui.Infof("drift would target the environment for %s but write files under %s", m.Branch, m.ProjectDir)
+	if IsYes() {
+		return nil
+	}
+	ok, err := ui.PromptYesNo("Continue anyway?", false)
+	if err != nil || !ok {
+		return errs.Cancelled(ctx.Cmd.CommandPath())
+	}
+	return nil
+}
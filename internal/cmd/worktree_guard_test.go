@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/undrift/drift/internal/git"
+)
+
+func TestDetectWorktreeMismatch(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "app")
+	nested := filepath.Join(main, ".worktrees", "feat-login")
+	if err := os.MkdirAll(filepath.Join(nested, "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	worktrees := []git.Worktree{
+		{Path: main, Branch: "main"},
+		{Path: nested, Branch: "feat/login"},
+	}
+
+	m := detectWorktreeMismatch(worktrees, filepath.Join(nested, "web"), main)
+	if m == nil {
+		t.Fatal("expected a mismatch from a nested worktree")
+	}
+	if m.Branch != "feat/login" || m.ProjectBranch != "main" {
+		t.Errorf("mismatch = %+v", m)
+	}
+
+	if m := detectWorktreeMismatch(worktrees, filepath.Join(nested, "web"), nested); m != nil {
+		t.Errorf("same worktree reported as mismatch: %+v", m)
+	}
+	if m := detectWorktreeMismatch(worktrees, root, main); m != nil {
+		t.Errorf("directory outside any worktree reported as mismatch: %+v", m)
+	}
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var wtRenameCmd = &cobra.Command{
+	Use:   "rename <old-branch> <new-branch>",
+	Short: "Rename a worktree's branch and directory together",
+	Long: `Rename the branch checked out in a worktree and keep everything that
+depends on the name in sync:
+
+- Renames the local branch (git branch -m)
+- Moves the worktree to the path given by worktree.naming_pattern
+- Regenerates environment config for the new branch name
+- Renames the matching tmux session
+
+The remote branch is not renamed; push the new branch when ready.`,
+	Example: `  drift worktree rename feat/login feat/auth-login
+  drift worktree rename fix/typo fix/header-typo --no-setup`,
+	Args: cobra.ExactArgs(2),
+	RunE: runWorktreeRename,
+}
+
+var wtMoveCmd = &cobra.Command{
+	Use:   "move <branch> <new-path>",
+	Short: "Move a worktree to a new directory",
+	Long: `Move a worktree with git worktree move and rename the matching tmux
+session to the new directory name.`,
+	Example: `  drift worktree move feat/login ../myapp-login`,
+	Args:    cobra.ExactArgs(2),
+	RunE:    runWorktreeMove,
+}
+
+var wtRenameNoSetupFlag bool
+
+func init() {
+	wtRenameCmd.Flags().BoolVar(&wtRenameNoSetupFlag, "no-setup", false, "Skip environment config regeneration")
+
+	worktreeCmd.AddCommand(wtRenameCmd)
+	worktreeCmd.AddCommand(wtMoveCmd)
+}
+
+func runWorktreeRename(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+	oldBranch, newBranch := args[0], args[1]
+
+	wt, err := git.GetWorktree(oldBranch)
+	if err != nil {
+		return err
+	}
+	if err := ensureMovableWorktree(wt); err != nil {
+		return err
+	}
+	if git.BranchExists(newBranch) {
+		return fmt.Errorf("branch '%s' already exists", newBranch)
+	}
+
+	newPath := git.GetWorktreePath(cfg.Project.Name, newBranch, cfg.Worktree.NamingPattern)
+	if newPath != wt.Path {
+		if _, err := os.Stat(newPath); err == nil {
+			return fmt.Errorf("target path already exists: %s", newPath)
+		}
+	}
+
+	ui.Infof("Renaming '%s' to '%s'", oldBranch, newBranch)
+	if newPath != wt.Path {
+		ui.KeyValue("From", wt.Path)
+		ui.KeyValue("To", newPath)
+	}
+
+	if !IsYes() {
+		confirmed, err := ui.PromptYesNo("Continue?", true)
+		if err != nil || !confirmed {
+			ui.Info("Cancelled")
+			return nil
+		}
+	}
+
+	if err := renameWorktree(wt.Path, newPath, oldBranch, newBranch); err != nil {
+		return err
+	}
+	ui.Successf("Renamed branch to '%s'", newBranch)
+	if newPath != wt.Path {
+		ui.Successf("Moved worktree to %s", newPath)
+		moveWorktreePorts(wt.Path, newPath)
+	}
+
+	renameWorktreeSession(map[string]string{
+		branchSessionName(cfg.Project.Name, oldBranch): branchSessionName(cfg.Project.Name, newBranch),
+		tmuxSafeName(filepath.Base(wt.Path)):           tmuxSafeName(filepath.Base(newPath)),
+	})
+
+	if !wtRenameNoSetupFlag && cfg.Worktree.AutoSetupXcconfig {
+		originalDir, _ := os.Getwd()
+		if err := os.Chdir(newPath); err == nil {
+			ui.Info("Regenerating environment config...")
+			envBranchFlag = ""
+			if err := runEnvSetup(worktreeContext(cmd)); err != nil {
+				ui.Warning(fmt.Sprintf("Could not setup environment config: %v", err))
+			}
+			os.Chdir(originalDir)
+		}
+	}
+
+	if git.RemoteBranchExists("origin", oldBranch) {
+		ui.NewLine()
+		ui.Infof("Remote branch origin/%s was not renamed", oldBranch)
+		ui.Infof("Push the new name with: git push -u origin %s", newBranch)
+	}
+
+	ui.NewLine()
+	ui.Success("Worktree renamed")
+	ui.KeyValue("Path", newPath)
+	return nil
+}
+
+// renameWorktree renames oldBranch to newBranch and moves its worktree from
+// oldPath to newPath. If the move fails the branch is renamed back, so the
+// worktree is never left checked out on a name that no longer matches it.
+func renameWorktree(oldPath, newPath, oldBranch, newBranch string) error {
+	if err := git.RenameBranch(oldBranch, newBranch); err != nil {
+		return err
+	}
+	if newPath == oldPath {
+		return nil
+	}
+	if err := git.MoveWorktree(oldPath, newPath); err != nil {
+		if rollbackErr := git.RenameBranch(newBranch, oldBranch); rollbackErr != nil {
+			return fmt.Errorf("%w; renaming the branch back to '%s' also failed: %v", err, oldBranch, rollbackErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func runWorktreeMove(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+
+	wt, err := git.GetWorktree(args[0])
+	if err != nil {
+		return err
+	}
+	if err := ensureMovableWorktree(wt); err != nil {
+		return err
+	}
+
+	newPath, err := filepath.Abs(args[1])
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("target path already exists: %s", newPath)
+	}
+
+	if err := git.MoveWorktree(wt.Path, newPath); err != nil {
+		return err
+	}
+	ui.Successf("Moved worktree to %s", newPath)
+
+	renameWorktreeSession(map[string]string{
+		tmuxSafeName(filepath.Base(wt.Path)): tmuxSafeName(filepath.Base(newPath)),
+	})
+	return nil
+}
+
+// ensureMovableWorktree rejects the main worktree and the one we are standing in.
+func ensureMovableWorktree(wt *git.Worktree) error {
+	if mainPath, err := git.GetMainWorktreePath(); err == nil && mainPath == wt.Path {
+		return fmt.Errorf("the main worktree cannot be moved or archived")
+	}
+	if wt.IsCurrent {
+		return fmt.Errorf("'%s' is the current worktree; run this from another worktree", wt.Branch)
+	}
+	if wt.IsLocked {
+		return fmt.Errorf("worktree is locked: %s", wt.Path)
+	}
+	return nil
+}
+
+// tmuxSafeName replaces characters tmux does not allow in session names.
+func tmuxSafeName(name string) string {
+	name = strings.ReplaceAll(name, ":", "-")
+	return strings.ReplaceAll(name, ".", "-")
+}
+
+// renameWorktreeSession renames existing tmux sessions using the old -> new name pairs.
+func renameWorktreeSession(renames map[string]string) {
+	if !shell.CommandExists("tmux") {
+		return
+	}
+	sessions, err := listTmuxSessions()
+	if err != nil {
+		return
+	}
+
+	existing := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		existing[s.Name] = true
+	}
+
+	for old, newName := range renames {
+		if old == newName || !existing[old] || existing[newName] {
+			continue
+		}
+		result, err := shell.Run("tmux", "rename-session", "-t", "="+old, newName)
+		if err != nil || result.ExitCode != 0 {
+			ui.Warningf("Could not rename tmux session '%s'", old)
+			continue
+		}
+		existing[newName] = true
+		ui.Successf("Renamed tmux session '%s' to '%s'", old, newName)
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+func TestRenameWorktree_MovesAfterRename(t *testing.T) {
+	fake := testutil.NewFakeBin(t, "git")
+
+	if err := renameWorktree("/wt/old", "/wt/new", "feat/old", "feat/new"); err != nil {
+		t.Fatalf("renameWorktree() error = %v", err)
+	}
+
+	want := []string{
+		"branch -m feat/old feat/new",
+		"worktree move /wt/old /wt/new",
+	}
+	if got := fake.CallLines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("git calls = %v, want %v", got, want)
+	}
+}
+
+func TestRenameWorktree_RollsBackBranchWhenMoveFails(t *testing.T) {
+	fake := testutil.NewFakeBin(t, "git",
+		testutil.Response{Args: "worktree move", Stderr: "fatal: '/wt/new' already exists", Exit: 128},
+	)
+
+	err := renameWorktree("/wt/old", "/wt/new", "feat/old", "feat/new")
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("renameWorktree() error = %v, want the move failure", err)
+	}
+
+	want := []string{
+		"branch -m feat/old feat/new",
+		"worktree move /wt/old /wt/new",
+		"branch -m feat/new feat/old",
+	}
+	if got := fake.CallLines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("git calls = %v, want %v", got, want)
+	}
+}
+
+func TestRenameWorktree_SamePathOnlyRenames(t *testing.T) {
+	fake := testutil.NewFakeBin(t, "git")
+
+	if err := renameWorktree("/wt/app", "/wt/app", "feat/old", "feat/new"); err != nil {
+		t.Fatalf("renameWorktree() error = %v", err)
+	}
+	if got := fake.CallLines(); len(got) != 1 {
+		t.Errorf("git calls = %v, want only the branch rename", got)
+	}
+}
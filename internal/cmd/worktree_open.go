@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/ide"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// customOpeners converts the openers defined in preferences.
+func customOpeners(cfg *config.Config) map[string]ide.Opener {
+	openers := make(map[string]ide.Opener, len(cfg.Preferences.Openers))
+	for name, o := range cfg.Preferences.Openers {
+		openers[name] = ide.Opener{Command: o.Command, Args: o.Args, WaitArgs: o.WaitArgs}
+	}
+	return openers
+}
+
+// resolveWorktreeOpener returns the opener called name, or the configured
+// default when name is empty.
+func resolveWorktreeOpener(cfg *config.Config, name string) (ide.Opener, error) {
+	if name == "" {
+		name = cfg.GetOpener()
+	}
+	return ide.ResolveOpener(name, customOpeners(cfg))
+}
+
+// openWorktree opens the worktree at path with opener. With wait it blocks
+// until the editor or window is closed.
+func openWorktree(opener ide.Opener, path, branch string, wait bool) error {
+	name, args, err := opener.CommandLine(path, branch, wait)
+	if err != nil {
+		return err
+	}
+	if !shell.CommandExists(name) {
+		return fmt.Errorf("%s not found in PATH (opener %q)", name, opener.Name)
+	}
+	return shell.RunInteractive(name, args...)
+}
+
+// completeOpeners completes --with values.
+func completeOpeners(cfg *config.Config) []string {
+	return ide.OpenerNames(customOpeners(cfg))
+}
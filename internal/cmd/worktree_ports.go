@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ports"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/web"
+)
+
+var wtPortsCmd = &cobra.Command{
+	Use:   "ports",
+	Short: "Show the local ports assigned to each worktree",
+	Long: `Show the local ports drift has assigned to each worktree.
+
+Ports are configured in worktree.ports as env var names with a base port:
+
+  worktree:
+    ports:
+      PORT: 3000
+      STORYBOOK_PORT: 6006
+
+The first time 'drift env setup' runs in a worktree, each name gets the first
+port at or above its base that no other worktree holds and nothing is
+listening on. The assignment is kept in .drift/ports.json of the main
+worktree, written to the generated env file, and released when the worktree
+is deleted or archived.`,
+	Example: `  drift worktree ports`,
+	Args:    cobra.NoArgs,
+	RunE:    Run(runWorktreePorts, RequireProject),
+}
+
+func init() {
+	worktreeCmd.AddCommand(wtPortsCmd)
+}
+
+func runWorktreePorts(ctx *Context) error {
+	cfg := ctx.Config()
+
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		return fmt.Errorf("failed to find main worktree: %w", err)
+	}
+	reg, err := ports.Load(mainPath)
+	if err != nil {
+		return err
+	}
+	if removed := reg.Prune(); len(removed) > 0 {
+		if err := reg.Save(mainPath); err != nil {
+			return err
+		}
+		for _, path := range removed {
+			ui.Infof("Released ports of removed worktree %s", path)
+		}
+	}
+
+	ui.Header("Worktree Ports")
+	if len(cfg.Worktree.Ports) == 0 {
+		ui.Info("No ports configured; add worktree.ports to .drift.yaml")
+		return nil
+	}
+	if len(reg.Worktrees) == 0 {
+		ui.Info("No ports assigned yet; run 'drift env setup' in a worktree")
+		return nil
+	}
+
+	current := ports.Key(cfg.ProjectRoot())
+	paths := make([]string, 0, len(reg.Worktrees))
+	for path := range reg.Worktrees {
+		paths = append(paths, path)
+	}
+	// Main worktree first, then by path.
+	sort.Slice(paths, func(i, j int) bool {
+		if (paths[i] == mainPath) != (paths[j] == mainPath) {
+			return paths[i] == mainPath
+		}
+		return paths[i] < paths[j]
+	})
+
+	table := ui.NewTable([]string{"Worktree", "Env", "Port"})
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if path == current {
+			name += " (current)"
+		}
+		for _, env := range ports.Names(reg.Worktrees[path]) {
+			table.AddRow([]string{name, env, strconv.Itoa(reg.Worktrees[path][env])})
+			name = ""
+		}
+	}
+	table.Render()
+	return nil
+}
+
+// applyEnvLocalPorts makes g write the ports assigned to the current
+// worktree, assigning any that are missing. With allocate unset only
+// existing assignments are used, so previews never change the registry.
+func applyEnvLocalPorts(cfg *config.Config, g *web.EnvLocalGenerator, allocate bool) {
+	if len(cfg.Worktree.Ports) == 0 {
+		return
+	}
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		mainPath = cfg.ProjectRoot()
+	}
+	reg, err := ports.Load(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read worktree ports: %v", err))
+		return
+	}
+
+	wtPath := cfg.ProjectRoot()
+	assigned := reg.Lookup(wtPath)
+	if allocate {
+		pruned := len(reg.Prune()) > 0
+		var changed bool
+		assigned, changed, err = reg.Allocate(wtPath, cfg.Worktree.Ports, ports.Available)
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Could not assign worktree ports: %v", err))
+			return
+		}
+		if changed || pruned {
+			if err := reg.Save(mainPath); err != nil {
+				ui.Warning(fmt.Sprintf("Could not save worktree ports: %v", err))
+			}
+		}
+	}
+
+	g.Ports = nil
+	for _, env := range ports.Names(assigned) {
+		g.Ports = append(g.Ports, web.LocalPort{Env: env, Port: assigned[env]})
+	}
+}
+
+// releaseWorktreePorts frees the ports of a removed worktree.
+func releaseWorktreePorts(wtPath string) {
+	updateWorktreePorts(func(reg *ports.Registry) bool {
+		return reg.Release(wtPath)
+	})
+}
+
+// moveWorktreePorts keeps a moved worktree's ports.
+func moveWorktreePorts(oldPath, newPath string) {
+	updateWorktreePorts(func(reg *ports.Registry) bool {
+		assigned := reg.Lookup(oldPath)
+		if !reg.Release(oldPath) {
+			return false
+		}
+		reg.Worktrees[ports.Key(newPath)] = assigned
+		return true
+	})
+}
+
+func updateWorktreePorts(fn func(reg *ports.Registry) bool) {
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		return
+	}
+	reg, err := ports.Load(mainPath)
+	if err != nil || !fn(reg) {
+		return
+	}
+	if err := reg.Save(mainPath); err != nil {
+		ui.Warning(fmt.Sprintf("Could not update worktree ports: %v", err))
+	}
+}
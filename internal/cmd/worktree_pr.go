@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var (
+	wtFromPRFlag int
+	wtReviewFlag bool
+)
+
+func init() {
+	wtCreateCmd.Flags().IntVar(&wtFromPRFlag, "from-pr", 0, "Create the worktree from a GitHub pull request number")
+	wtCreateCmd.Flags().BoolVar(&wtReviewFlag, "review", false, "With --from-pr, check out the PR detached and read-only")
+}
+
+// runWorktreeCreateFromPR creates (or refreshes) a worktree for a GitHub pull
+// request and then runs the usual setup.
+func runWorktreeCreateFromPR(cmd *cobra.Command, cfg *config.Config, number int) error {
+	pr, err := git.GetPullRequest(number)
+	if err != nil {
+		// The head ref can still be fetched without gh; only the metadata is lost
+		ui.Warning(err.Error())
+		ui.Info("Continuing without PR details")
+	} else {
+		printPullRequest(pr)
+	}
+
+	if wtReviewFlag {
+		wtPath, err := createReviewWorktree(cfg, number)
+		if err != nil {
+			return err
+		}
+		envBranch := ""
+		if pr != nil {
+			envBranch = pr.HeadRefName
+		}
+		if err := finishWorktreeCreate(cmd, cfg, wtPath, envBranch); err != nil {
+			return err
+		}
+		ui.NewLine()
+		ui.Infof("Review worktree for PR #%d is detached; commits here are not on any branch", number)
+		ui.Infof("Refresh with 'drift worktree create --from-pr %d --review', remove with 'git worktree remove %s'", number, wtPath)
+		return nil
+	}
+
+	// Same-repo PRs get a real tracking branch; forks and unknown PRs get pr/<n>
+	sameRepo := pr != nil && !pr.IsCrossRepo
+	branch := fmt.Sprintf("pr/%d", number)
+	if sameRepo {
+		branch = pr.HeadRefName
+	}
+
+	var wtPath string
+	if wt, err := git.GetWorktree(branch); err == nil {
+		wtPath = wt.Path
+		ui.Info("Worktree already exists, continuing with setup...")
+	} else if sameRepo {
+		sp := ui.NewSpinner("Fetching origin")
+		sp.Start()
+		if err := git.Fetch("origin"); err != nil {
+			sp.Fail("Fetch failed")
+			return err
+		}
+		sp.Stop()
+		base := wtFromFlag
+		if pr != nil && pr.BaseRefName != "" {
+			base = pr.BaseRefName
+		}
+		if wtPath, err = createWorktreeForBranch(cfg, branch, base); err != nil {
+			return err
+		}
+		ui.Success(fmt.Sprintf("Worktree created at %s", wtPath))
+	} else {
+		sp := ui.NewSpinner(fmt.Sprintf("Fetching PR #%d", number))
+		sp.Start()
+		if _, err := git.FetchPullRequest("origin", number, branch); err != nil {
+			sp.Fail("Fetch failed")
+			return err
+		}
+		sp.Success(fmt.Sprintf("Fetched PR #%d into %s", number, branch))
+
+		wtPath = git.GetWorktreePath(cfg.Project.Name, branch, cfg.Worktree.NamingPattern)
+		ui.KeyValue("Path", wtPath)
+		if err := git.CreateWorktree(wtPath, branch, false, ""); err != nil {
+			return err
+		}
+		ui.Success(fmt.Sprintf("Worktree created at %s", wtPath))
+	}
+
+	return finishWorktreeCreate(cmd, cfg, wtPath, "")
+}
+
+// createReviewWorktree checks out the PR head detached in a dedicated
+// worktree and marks it read-only. An existing review worktree is moved to
+// the latest head.
+func createReviewWorktree(cfg *config.Config, number int) (string, error) {
+	sp := ui.NewSpinner(fmt.Sprintf("Fetching PR #%d", number))
+	sp.Start()
+	commit, err := git.FetchPullRequest("origin", number, "")
+	if err != nil {
+		sp.Fail("Fetch failed")
+		return "", err
+	}
+	short := commit
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	sp.Success(fmt.Sprintf("Fetched PR #%d at %s", number, short))
+
+	wtPath := git.GetWorktreePath(cfg.Project.Name, fmt.Sprintf("review/pr-%d", number), cfg.Worktree.NamingPattern)
+	if git.WorktreePathExists(wtPath) {
+		if changes, err := git.GetUncommittedChanges(wtPath); err == nil && changes > 0 {
+			return "", fmt.Errorf("review worktree %s has %d uncommitted change(s); discard them before refreshing", wtPath, changes)
+		}
+		if err := git.DetachWorktree(wtPath, commit); err != nil {
+			return "", err
+		}
+		ui.Successf("Review worktree updated to %s", short)
+	} else {
+		ui.Infof("Creating review worktree for PR #%d", number)
+		ui.KeyValue("Path", wtPath)
+		if err := git.CreateDetachedWorktree(wtPath, commit); err != nil {
+			return "", err
+		}
+		ui.Success(fmt.Sprintf("Worktree created at %s", wtPath))
+	}
+
+	if err := git.MarkReviewWorktree(wtPath, number); err != nil {
+		ui.Warning(fmt.Sprintf("Could not mark worktree as review-only: %v", err))
+	}
+	return wtPath, nil
+}
+
+func printPullRequest(pr *git.PullRequest) {
+	ui.KeyValue("Pull Request", fmt.Sprintf("#%d %s", pr.Number, pr.Title))
+	if pr.Author.Login != "" {
+		ui.KeyValue("Author", pr.Author.Login)
+	}
+	ui.KeyValue("Branches", fmt.Sprintf("%s ← %s", pr.BaseRefName, ui.Cyan(pr.HeadRefName)))
+	if pr.State != "" && pr.State != "OPEN" {
+		ui.KeyValue("State", ui.Yellow(pr.State))
+	}
+	if pr.URL != "" {
+		ui.KeyValue("URL", ui.Dim(pr.URL))
+	}
+	ui.NewLine()
+}
@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/internal/ui"
+)
+
+var wtRestackCmd = &cobra.Command{
+	Use:   "restack [branch]",
+	Short: "Rebase stacked worktrees onto their updated parent",
+	Long: `Rebase the branches stacked on a branch after it changes, and the branches
+stacked on those, so every child starts from its parent's latest commit.
+
+Branches are stacked with 'drift worktree create <branch> --stack'. Without
+an argument the current branch's children are restacked. Each child is
+rebased in its own worktree, replaying only the commits made after the point
+it was last stacked, so an amended or rebased parent does not duplicate
+commits. Children with uncommitted changes are skipped.
+
+When a rebase hits conflicts it is left in progress: resolve them in that
+worktree, run 'git rebase --continue', then run 'drift worktree restack'
+again to carry on with the rest of the stack.`,
+	Example: `  drift worktree create feat/api-client --stack   # stack on the current branch
+  drift worktree create feat/ui --stack --from feat/api-client
+  drift worktree restack                          # after amending the current branch
+  drift worktree restack feat/api-client --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: Run(runWorktreeRestack, RequireProject),
+}
+
+var (
+	wtStackFlag         bool
+	wtRestackDryRunFlag bool
+)
+
+func init() {
+	wtCreateCmd.Flags().BoolVar(&wtStackFlag, "stack", false, "Stack the new branch on a feature branch (--from, or the current branch)")
+	wtRestackCmd.Flags().BoolVar(&wtRestackDryRunFlag, "dry-run", false, "Show what would be rebased")
+	worktreeCmd.AddCommand(wtRestackCmd)
+}
+
+// stackedBranch records that Branch was created on top of Parent.
+// ParentCommit is the parent's head when Branch was last stacked on it:
+// the commits after it are Branch's own.
+type stackedBranch struct {
+	Branch       string    `json:"branch"`
+	Parent       string    `json:"parent"`
+	ParentCommit string    `json:"parent_commit"`
+	StackedAt    time.Time `json:"stacked_at"`
+}
+
+// worktreeStacks is the stack record, kept in the main worktree so every
+// worktree sees the same stacks.
+type worktreeStacks struct {
+	Branches []stackedBranch `json:"branches"`
+}
+
+func loadWorktreeStacks() (*worktreeStacks, error) {
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		return nil, err
+	}
+	var stacks worktreeStacks
+	if _, err := state.ReadJSON(state.Path(mainPath, state.Stacks), &stacks); err != nil {
+		return nil, err
+	}
+	return &stacks, nil
+}
+
+func saveWorktreeStacks(stacks *worktreeStacks) error {
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		return err
+	}
+	sort.Slice(stacks.Branches, func(i, j int) bool { return stacks.Branches[i].Branch < stacks.Branches[j].Branch })
+	return state.WriteJSON(mainPath, state.Path(mainPath, state.Stacks), stacks)
+}
+
+// parent returns branch's record, or nil when branch is not stacked.
+func (s *worktreeStacks) parent(branch string) *stackedBranch {
+	for i := range s.Branches {
+		if s.Branches[i].Branch == branch {
+			return &s.Branches[i]
+		}
+	}
+	return nil
+}
+
+// set records rec, replacing any earlier record for the same branch.
+func (s *worktreeStacks) set(rec stackedBranch) {
+	if existing := s.parent(rec.Branch); existing != nil {
+		*existing = rec
+		return
+	}
+	s.Branches = append(s.Branches, rec)
+}
+
+// descendants returns the branches stacked on branch, directly or through
+// other stacked branches, with every parent before its children.
+func (s *worktreeStacks) descendants(branch string) []*stackedBranch {
+	var out []*stackedBranch
+	seen := map[string]bool{branch: true}
+	queue := []string{branch}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		var children []*stackedBranch
+		for i := range s.Branches {
+			rec := &s.Branches[i]
+			if rec.Parent == parent && !seen[rec.Branch] {
+				seen[rec.Branch] = true
+				children = append(children, rec)
+			}
+		}
+		sort.Slice(children, func(i, j int) bool { return children[i].Branch < children[j].Branch })
+		for _, c := range children {
+			out = append(out, c)
+			queue = append(queue, c.Branch)
+		}
+	}
+	return out
+}
+
+// remove forgets branch and moves its children onto its own parent. The
+// children keep their ParentCommit, so a restack drops the removed
+// branch's commits, as it should once they have been merged.
+func (s *worktreeStacks) remove(branch string) bool {
+	rec := s.parent(branch)
+	removed := false
+	var kept []stackedBranch
+	for _, b := range s.Branches {
+		if b.Branch == branch {
+			removed = true
+			continue
+		}
+		if b.Parent == branch && rec != nil {
+			b.Parent = rec.Parent
+			removed = true
+		}
+		kept = append(kept, b)
+	}
+	s.Branches = kept
+	return removed
+}
+
+// forgetStackedBranch drops a deleted branch from the stack record.
+func forgetStackedBranch(branch string) {
+	stacks, err := loadWorktreeStacks()
+	if err != nil || !stacks.remove(branch) {
+		return
+	}
+	if err := saveWorktreeStacks(stacks); err != nil {
+		ui.Warning(fmt.Sprintf("Could not update worktree stacks: %v", err))
+	}
+}
+
+// stackParent returns the branch a --stack worktree is created on: --from
+// when given (or chosen interactively), the current branch otherwise.
+func stackParent(cmd *cobra.Command, interactive bool) (string, error) {
+	parent := wtFromFlag
+	if !interactive && !cmd.Flags().Changed("from") {
+		current, err := git.CurrentBranch()
+		if err != nil {
+			return "", fmt.Errorf("could not determine the current branch to stack on: %w", err)
+		}
+		parent = current
+	}
+	if isMainlineBranch(parent) {
+		return "", fmt.Errorf("--stack needs a feature branch to stack on, not %s; use --from <branch>", parent)
+	}
+	if !git.BranchExists(parent) {
+		return "", fmt.Errorf("parent branch '%s' not found locally", parent)
+	}
+	return parent, nil
+}
+
+// createStackedWorktree creates a worktree for branch on top of the local
+// parent branch, which may have commits not yet pushed, and records the
+// stack. An existing branch is checked out as usual and stacked from its
+// merge base with parent.
+func createStackedWorktree(cfg *config.Config, branch, parent string) (string, error) {
+	var wtPath string
+	var err error
+	if git.BranchExists(branch) || git.RemoteBranchExists("origin", branch) {
+		wtPath, err = createWorktreeForBranch(cfg, branch, parent)
+	} else {
+		wtPath = git.GetWorktreePath(cfg.Project.Name, branch, cfg.Worktree.NamingPattern)
+		ui.Infof("Creating worktree for branch '%s'", branch)
+		ui.KeyValue("Path", wtPath)
+		ui.Infof("Stacking on %s", ui.Cyan(parent))
+		err = git.CreateWorktree(wtPath, branch, true, parent)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	base, err := git.MergeBase(parent, branch)
+	if err != nil {
+		return wtPath, err
+	}
+	stacks, err := loadWorktreeStacks()
+	if err != nil {
+		return wtPath, err
+	}
+	stacks.set(stackedBranch{Branch: branch, Parent: parent, ParentCommit: base, StackedAt: time.Now().UTC()})
+	return wtPath, saveWorktreeStacks(stacks)
+}
+
+// needsRestack reports whether rec's parent has commits its branch lacks.
+func needsRestack(rec *stackedBranch) bool {
+	return git.BranchExists(rec.Parent) && !git.IsAncestor(rec.Parent, rec.Branch)
+}
+
+// stackLabel is the list annotation for a stacked branch.
+func stackLabel(stacks *worktreeStacks, branch string) string {
+	rec := stacks.parent(branch)
+	if rec == nil {
+		return ""
+	}
+	label := ui.Dim(" ⤷ " + rec.Parent)
+	if needsRestack(rec) {
+		label += ui.Yellow(" (needs restack)")
+	}
+	return label
+}
+
+// restackWorktree returns the worktree branch is checked out in, or why it
+// cannot be rebased there.
+func restackWorktree(branch string) (string, string) {
+	wt, err := git.GetWorktree(branch)
+	if err != nil {
+		return "", "not checked out in a worktree"
+	}
+	if git.RebaseInProgress(wt.Path) {
+		return "", "a rebase is already in progress in " + wt.Path
+	}
+	if changes, _ := git.GetUncommittedChanges(wt.Path); changes > 0 {
+		return "", fmt.Sprintf("%d uncommitted change(s), commit or stash them first", changes)
+	}
+	return wt.Path, ""
+}
+
+func runWorktreeRestack(ctx *Context) error {
+	root := ctx.Arg(0)
+	if root == "" {
+		current, err := git.CurrentBranch()
+		if err != nil {
+			return fmt.Errorf("could not determine the current branch: %w", err)
+		}
+		root = current
+	}
+
+	stacks, err := loadWorktreeStacks()
+	if err != nil {
+		return err
+	}
+	children := stacks.descendants(root)
+	if len(children) == 0 {
+		ui.Infof("No branches are stacked on %s", root)
+		return nil
+	}
+
+	ui.Header("Restack: " + root)
+
+	blocked := make(map[string]bool)
+	restacked, skipped := 0, 0
+	for _, rec := range children {
+		if blocked[rec.Parent] {
+			ui.Warningf("%s: skipped until %s is restacked", rec.Branch, rec.Parent)
+			blocked[rec.Branch] = true
+			skipped++
+			continue
+		}
+		parentHead, err := git.GetCommitHash(rec.Parent)
+		if err != nil || !git.BranchExists(rec.Parent) {
+			ui.Warningf("%s: parent %s no longer exists", rec.Branch, rec.Parent)
+			blocked[rec.Branch] = true
+			skipped++
+			continue
+		}
+		if git.IsAncestor(rec.Parent, rec.Branch) {
+			ui.Successf("%s is up to date with %s", rec.Branch, rec.Parent)
+			rec.ParentCommit = parentHead
+			continue
+		}
+
+		wtPath, reason := restackWorktree(rec.Branch)
+		if reason != "" {
+			ui.Warningf("%s: %s", rec.Branch, reason)
+			blocked[rec.Branch] = true
+			skipped++
+			continue
+		}
+
+		// Replay only the child's own commits: those after the parent head
+		// it was stacked on, or after the merge base if that is unknown.
+		upstream := rec.ParentCommit
+		if upstream == "" || !git.IsAncestor(upstream, rec.Branch) {
+			if upstream, err = git.MergeBase(rec.Parent, rec.Branch); err != nil {
+				return err
+			}
+		}
+
+		if ctx.DryRun() {
+			ui.Infof("Would rebase %s onto %s", ui.Cyan(rec.Branch), ui.Cyan(rec.Parent))
+			continue
+		}
+
+		sp := ui.NewSpinner(fmt.Sprintf("Rebasing %s onto %s", rec.Branch, rec.Parent))
+		sp.Start()
+		if err := git.RebaseOnto(wtPath, rec.Parent, upstream); err != nil {
+			sp.Fail(fmt.Sprintf("Conflicts rebasing %s", rec.Branch))
+			if saveErr := saveWorktreeStacks(stacks); saveErr != nil {
+				ui.Warning(fmt.Sprintf("Could not update worktree stacks: %v", saveErr))
+			}
+			return fmt.Errorf("%w\nResolve the conflicts in %s, run 'git rebase --continue', then run 'drift worktree restack %s' again", err, wtPath, root)
+		}
+		sp.Success(fmt.Sprintf("Rebased %s onto %s", rec.Branch, rec.Parent))
+		rec.ParentCommit = parentHead
+		restacked++
+	}
+
+	if ctx.DryRun() {
+		return nil
+	}
+	if err := saveWorktreeStacks(stacks); err != nil {
+		return err
+	}
+
+	ui.NewLine()
+	if restacked > 0 {
+		ui.Successf("Restacked %d branch(es)", restacked)
+		ui.Info("Push them with 'git push --force-with-lease' to update their pull requests")
+	}
+	if skipped > 0 {
+		return fmt.Errorf("%d stacked branch(es) could not be restacked", skipped)
+	}
+	return nil
+}
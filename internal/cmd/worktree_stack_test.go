@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func stackFixture() *worktreeStacks {
+	return &worktreeStacks{Branches: []stackedBranch{
+		{Branch: "feat/ui", Parent: "feat/api", ParentCommit: "b1"},
+		{Branch: "feat/api", Parent: "feat/schema", ParentCommit: "a1"},
+		{Branch: "feat/docs", Parent: "feat/schema", ParentCommit: "a2"},
+		{Branch: "feat/other", Parent: "feat/unrelated", ParentCommit: "c1"},
+	}}
+}
+
+func stackBranchNames(recs []*stackedBranch) []string {
+	var names []string
+	for _, r := range recs {
+		names = append(names, r.Branch)
+	}
+	return names
+}
+
+func TestWorktreeStacksDescendants(t *testing.T) {
+	stacks := stackFixture()
+
+	got := stackBranchNames(stacks.descendants("feat/schema"))
+	want := []string{"feat/api", "feat/docs", "feat/ui"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("descendants(feat/schema) = %v, want %v", got, want)
+	}
+
+	if got := stackBranchNames(stacks.descendants("feat/api")); !reflect.DeepEqual(got, []string{"feat/ui"}) {
+		t.Errorf("descendants(feat/api) = %v, want [feat/ui]", got)
+	}
+	if got := stacks.descendants("feat/ui"); len(got) != 0 {
+		t.Errorf("descendants(feat/ui) = %v, want none", stackBranchNames(got))
+	}
+
+	// Records are returned by pointer so restack can update them in place.
+	stacks.descendants("feat/api")[0].ParentCommit = "b2"
+	if rec := stacks.parent("feat/ui"); rec.ParentCommit != "b2" {
+		t.Errorf("ParentCommit = %q after update, want b2", rec.ParentCommit)
+	}
+}
+
+func TestWorktreeStacksDescendants_Cycle(t *testing.T) {
+	stacks := &worktreeStacks{Branches: []stackedBranch{
+		{Branch: "a", Parent: "b"},
+		{Branch: "b", Parent: "a"},
+	}}
+	if got := stackBranchNames(stacks.descendants("a")); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("descendants(a) = %v, want [b]", got)
+	}
+}
+
+func TestWorktreeStacksRemove(t *testing.T) {
+	stacks := stackFixture()
+	if !stacks.remove("feat/api") {
+		t.Fatal("remove(feat/api) = false, want true")
+	}
+	if stacks.parent("feat/api") != nil {
+		t.Error("feat/api is still recorded after remove")
+	}
+	ui := stacks.parent("feat/ui")
+	if ui == nil || ui.Parent != "feat/schema" || ui.ParentCommit != "b1" {
+		t.Errorf("feat/ui = %+v, want moved onto feat/schema keeping its parent commit", ui)
+	}
+
+	if stacks.remove("feat/unknown") {
+		t.Error("remove() of an unstacked branch = true, want false")
+	}
+}
+
+func TestWorktreeStacksSet(t *testing.T) {
+	stacks := stackFixture()
+	stacks.set(stackedBranch{Branch: "feat/ui", Parent: "feat/docs", ParentCommit: "d1"})
+	stacks.set(stackedBranch{Branch: "feat/new", Parent: "feat/ui"})
+
+	if rec := stacks.parent("feat/ui"); rec.Parent != "feat/docs" {
+		t.Errorf("feat/ui parent = %q, want feat/docs", rec.Parent)
+	}
+	if len(stacks.Branches) != 5 {
+		t.Errorf("len(Branches) = %d, want 5", len(stacks.Branches))
+	}
+}
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/errs"
 	"github.com/undrift/drift/internal/ui"
 	"github.com/undrift/drift/internal/xcode"
 )
@@ -16,7 +18,9 @@ var xcodeCmd = &cobra.Command{
 
 Commands:
   schemes   - List all available schemes
-  validate  - Validate configured schemes exist`,
+  schemes map - Map environments to schemes and save to .drift.yaml
+  validate  - Validate configured schemes exist
+  sync      - Patch Info.plist/entitlements values for the current environment`,
 }
 
 var xcodeSchemesCmd = &cobra.Command{
@@ -38,9 +42,53 @@ This helps catch configuration errors before deployment or build operations.`,
 	RunE: runXcodeValidate,
 }
 
+var xcodeSchemesMapCmd = &cobra.Command{
+	Use:   "map",
+	Short: "Interactively map environments to Xcode schemes",
+	Long: `Pick the Xcode scheme used for each environment and save the mapping to
+xcode.schemes in .drift.yaml.
+
+Schemes are read once via 'xcodebuild -list -json' (falling back to scanning
+.xcodeproj/.xcworkspace files), then you choose a scheme for production,
+development, and feature. Choose "(none)" to leave an environment unmapped.`,
+	RunE: runXcodeSchemesMap,
+}
+
+var xcodeSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync Info.plist and entitlements values for an environment",
+	Long: `Patch the plist keys listed under xcode.sync.files in .drift.yaml with the
+values for the current environment.
+
+Editing goes through PlistBuddy, so only the listed keys are replaced and the
+rest of each file is left as-is. The environment defaults to the one recorded
+in the generated xcconfig; use --env to pick another.
+
+When xcode.sync.enabled is true, 'drift env setup' runs this step automatically.
+
+Example config:
+  xcode:
+    sync:
+      enabled: true
+      files:
+        - path: MyApp/MyApp.entitlements
+          environments:
+            production:
+              com.apple.developer.associated-domains: [applinks:example.com]
+            development:
+              com.apple.developer.associated-domains: [applinks:dev.example.com]`,
+	RunE: runXcodeSync,
+}
+
+var xcodeSyncEnvFlag string
+
 func init() {
+	xcodeSyncCmd.Flags().StringVar(&xcodeSyncEnvFlag, "env", "", "Environment to sync (default: environment in the generated xcconfig)")
+
+	xcodeSchemesCmd.AddCommand(xcodeSchemesMapCmd)
 	xcodeCmd.AddCommand(xcodeSchemesCmd)
 	xcodeCmd.AddCommand(xcodeValidateCmd)
+	xcodeCmd.AddCommand(xcodeSyncCmd)
 	rootCmd.AddCommand(xcodeCmd)
 }
 
@@ -173,9 +221,155 @@ func runXcodeValidate(cmd *cobra.Command, args []string) error {
 		for _, s := range schemes {
 			ui.List(s.Name)
 		}
-		return fmt.Errorf("scheme validation failed")
+		return errs.Validationf("scheme validation failed")
 	}
 
 	ui.Successf("All %d configured schemes are valid", validCount)
 	return nil
 }
+
+// schemeMapEnvironments is the order environments are offered in 'xcode schemes map'.
+var schemeMapEnvironments = []string{"production", "development", "feature"}
+
+const schemeMapNone = "(none)"
+
+func runXcodeSchemesMap(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	sp := ui.NewSpinner("Listing schemes via xcodebuild")
+	sp.Start()
+	names, err := xcode.ListSchemesViaXcodebuild()
+	if err != nil || len(names) == 0 {
+		schemes, _ := xcode.ListSchemes()
+		names = names[:0]
+		for _, s := range schemes {
+			names = append(names, s.Name)
+		}
+	}
+	if len(names) == 0 {
+		sp.Fail("No schemes found")
+		return fmt.Errorf("no Xcode schemes found in the current directory")
+	}
+	sp.Success(fmt.Sprintf("Found %d schemes", len(names)))
+	ui.NewLine()
+
+	mapping := make(map[string]string)
+	for _, env := range schemeMapEnvironments {
+		current := cfg.Xcode.Schemes[env]
+
+		// Offer the current mapping first so Enter keeps it.
+		items := make([]string, 0, len(names)+1)
+		if current != "" && sliceContains(names, current) {
+			items = append(items, current)
+		}
+		for _, name := range names {
+			if name != current {
+				items = append(items, name)
+			}
+		}
+		items = append(items, schemeMapNone)
+
+		label := fmt.Sprintf("Scheme for %s", env)
+		if current != "" {
+			label = fmt.Sprintf("Scheme for %s (current: %s)", env, current)
+		}
+		choice, err := ui.PromptSelect(label, items)
+		if err != nil {
+			return err
+		}
+		if choice != schemeMapNone {
+			mapping[env] = choice
+		}
+	}
+
+	path := cfg.ConfigPath()
+	err = config.UpdateYAML(path, false, func(doc map[string]interface{}) error {
+		xcodeDoc := ensureChildMap(doc, "xcode")
+		if len(mapping) == 0 {
+			delete(xcodeDoc, "schemes")
+		} else {
+			schemesDoc := make(map[string]interface{}, len(mapping))
+			for env, scheme := range mapping {
+				schemesDoc[env] = scheme
+			}
+			xcodeDoc["schemes"] = schemesDoc
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+
+	ui.NewLine()
+	ui.Successf("Saved scheme mapping to %s", filepath.Base(path))
+	for _, env := range schemeMapEnvironments {
+		if scheme, ok := mapping[env]; ok {
+			ui.KeyValue(env, scheme)
+		}
+	}
+	return nil
+}
+
+func runXcodeSync(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	if len(cfg.Xcode.Sync.Files) == 0 {
+		ui.Warning("No plist files configured under xcode.sync.files in .drift.yaml")
+		return nil
+	}
+
+	env := xcodeSyncEnvFlag
+	if env == "" {
+		current, err := xcode.GetCurrentEnvironment(cfg.GetXcconfigPath())
+		if err != nil {
+			return fmt.Errorf("could not determine environment (run 'drift env setup' or pass --env): %w", err)
+		}
+		env = current
+	}
+
+	ui.Header("Xcode Sync")
+	return syncPlistsForEnvironment(cfg, env)
+}
+
+// syncPlistsForEnvironment applies xcode.sync values for env to each configured plist.
+func syncPlistsForEnvironment(cfg *config.Config, env string) error {
+	var failed int
+	for _, file := range cfg.Xcode.Sync.Files {
+		values := file.ValuesFor(env)
+		if len(values) == 0 {
+			if IsVerbose() {
+				ui.Infof("%s: no values for %s", file.Path, env)
+			}
+			continue
+		}
+
+		path := file.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.ProjectRoot(), path)
+		}
+
+		keys, err := xcode.SyncPlist(path, values)
+		if err != nil {
+			ui.Errorf("%s: %v", file.Path, err)
+			failed++
+			continue
+		}
+		ui.Successf("%s: %d key(s) synced for %s", file.Path, len(keys), env)
+		if IsVerbose() {
+			for _, key := range keys {
+				ui.List(key)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d plist file(s) failed to sync", failed)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/ui"
+	"github.com/undrift/drift/internal/xcode"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+var xcodeBuildServerCmd = &cobra.Command{
+	Use:   "build-server",
+	Short: "Generate and validate buildServer.json for sourcekit-lsp",
+	Long: `Generate buildServer.json with xcode-build-server, then validate it.
+
+The scheme comes from --scheme, or from xcode.schemes for the environment in
+the generated xcconfig (override with --env). The file is only regenerated when
+it is missing or was generated for a different scheme, unless --force is set.
+
+Validation checks:
+1. bspVersion is supported by sourcekit-lsp
+2. The xcode-build-server binary and workspace exist
+3. An index store exists under build_root
+
+Examples:
+  drift xcode build-server                 # regenerate if stale, then validate
+  drift xcode build-server --check         # validate only; fails if stale
+  drift xcode build-server --vscode        # also configure .vscode/settings.json`,
+	RunE: runXcodeBuildServer,
+}
+
+var (
+	xcodeBuildServerSchemeFlag string
+	xcodeBuildServerEnvFlag    string
+	xcodeBuildServerCheckFlag  bool
+	xcodeBuildServerForceFlag  bool
+	xcodeBuildServerVSCodeFlag bool
+)
+
+func init() {
+	xcodeBuildServerCmd.Flags().StringVar(&xcodeBuildServerSchemeFlag, "scheme", "", "Xcode scheme to use (default: from xcode.schemes)")
+	xcodeBuildServerCmd.Flags().StringVar(&xcodeBuildServerEnvFlag, "env", "", "Environment used to pick the scheme (default: environment in the generated xcconfig)")
+	xcodeBuildServerCmd.Flags().BoolVar(&xcodeBuildServerCheckFlag, "check", false, "Only validate; exit non-zero if missing, stale, or invalid")
+	xcodeBuildServerCmd.Flags().BoolVar(&xcodeBuildServerForceFlag, "force", false, "Regenerate even if the current file matches the scheme")
+	xcodeBuildServerCmd.Flags().BoolVar(&xcodeBuildServerVSCodeFlag, "vscode", false, "Install sourcekit-lsp settings into .vscode/settings.json")
+
+	xcodeCmd.AddCommand(xcodeBuildServerCmd)
+}
+
+func runXcodeBuildServer(cmd *cobra.Command, args []string) error {
+	if !RequireInit() {
+		return nil
+	}
+	cfg := config.LoadOrDefault()
+
+	ui.Header("Build Server")
+
+	env := xcodeBuildServerEnvFlag
+	if env == "" {
+		env, _ = xcode.GetCurrentEnvironment(cfg.GetXcconfigPath())
+	}
+
+	scheme := xcodeBuildServerSchemeFlag
+	if scheme == "" && env != "" {
+		scheme = getSchemeForEnvironment(cfg, &supabase.BranchInfo{Environment: normalizeEnvironment(env)})
+	}
+	if env != "" {
+		ui.KeyValue("Environment", envColorString(string(normalizeEnvironment(env))))
+	}
+	if scheme != "" {
+		ui.KeyValue("Scheme", scheme)
+	}
+
+	existing, readErr := xcode.ReadBuildServerConfig(xcode.BuildServerFile)
+	stale := readErr != nil || existing.IsStaleFor(scheme)
+	if existing != nil {
+		ui.KeyValue("Current Scheme", existing.Scheme)
+	}
+	ui.NewLine()
+
+	if xcodeBuildServerCheckFlag {
+		if readErr != nil {
+			return fmt.Errorf("%s: %w", xcode.BuildServerFile, readErr)
+		}
+		if stale {
+			ui.Warningf("%s was generated for '%s', expected '%s'", xcode.BuildServerFile, existing.Scheme, scheme)
+		}
+		if !reportBuildServerProblems(existing) || stale {
+			return fmt.Errorf("%s needs regenerating (run 'drift xcode build-server')", xcode.BuildServerFile)
+		}
+		ui.Successf("%s is valid", xcode.BuildServerFile)
+		return nil
+	}
+
+	if stale || xcodeBuildServerForceFlag {
+		if scheme == "" {
+			return fmt.Errorf("could not determine Xcode scheme. Use --scheme or --env to specify one")
+		}
+		if err := generateBuildServer(cfg, &supabase.BranchInfo{Environment: normalizeEnvironment(env)}, scheme); err != nil {
+			return err
+		}
+	} else {
+		ui.Infof("%s is up to date for scheme: %s", xcode.BuildServerFile, existing.Scheme)
+	}
+
+	current, err := xcode.ReadBuildServerConfig(xcode.BuildServerFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", xcode.BuildServerFile, err)
+	}
+	valid := reportBuildServerProblems(current)
+
+	if xcodeBuildServerVSCodeFlag {
+		if err := installVSCodeSourceKitSettings(); err != nil {
+			return err
+		}
+	}
+
+	if !valid {
+		return fmt.Errorf("%s failed validation", xcode.BuildServerFile)
+	}
+	ui.Successf("%s is valid", xcode.BuildServerFile)
+	return nil
+}
+
+// reportBuildServerProblems prints validation problems and returns true if there were none.
+func reportBuildServerProblems(cfg *xcode.BuildServerConfig) bool {
+	problems := cfg.Validate()
+	for _, p := range problems {
+		ui.Warning(p)
+	}
+	return len(problems) == 0
+}
+
+// refreshBuildServerIfStale regenerates an existing buildServer.json when it
+// was generated for a different scheme than the one mapped to info.Environment.
+func refreshBuildServerIfStale(cfg *config.Config, info *supabase.BranchInfo) {
+	existing, err := xcode.ReadBuildServerConfig(xcode.BuildServerFile)
+	if err != nil {
+		return
+	}
+	scheme := getSchemeForEnvironment(cfg, info)
+	if !existing.IsStaleFor(scheme) {
+		return
+	}
+
+	ui.Infof("%s targets '%s'; regenerating for '%s'", xcode.BuildServerFile, existing.Scheme, scheme)
+	if err := generateBuildServer(cfg, info, scheme); err != nil {
+		ui.Warning(fmt.Sprintf("Could not regenerate %s: %v", xcode.BuildServerFile, err))
+	}
+}
+
+// installVSCodeSourceKitSettings points the Swift extension at Xcode's sourcekit-lsp.
+func installVSCodeSourceKitSettings() error {
+	result, err := shell.Run("xcrun", "--find", "sourcekit-lsp")
+	if err != nil {
+		return fmt.Errorf("could not locate sourcekit-lsp via xcrun: %w", err)
+	}
+	serverPath := strings.TrimSpace(result.Stdout)
+	if result.ExitCode != 0 || serverPath == "" {
+		msg := strings.TrimSpace(result.Stderr)
+		switch {
+		case msg != "":
+		case result.ExitCode != 0:
+			msg = fmt.Sprintf("xcrun exited with code %d", result.ExitCode)
+		default:
+			msg = "xcrun printed no path"
+		}
+		return fmt.Errorf("could not locate sourcekit-lsp via xcrun: %s", msg)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	settingsPath := filepath.Join(cwd, ".vscode", "settings.json")
+
+	changed, err := xcode.UpdateVSCodeSettings(settingsPath, map[string]interface{}{
+		"swift.sourcekit-lsp.serverPath":         serverPath,
+		"swift.autoGenerateLaunchConfigurations": false,
+	})
+	if err != nil {
+		return err
+	}
+	if changed {
+		ui.Successf("Updated %s", filepath.Join(".vscode", "settings.json"))
+	} else {
+		ui.Infof("%s already configured", filepath.Join(".vscode", "settings.json"))
+	}
+	return nil
+}
+
+// normalizeEnvironment maps config-style environment names onto supabase.Environment.
+func normalizeEnvironment(env string) supabase.Environment {
+	switch strings.ToLower(strings.TrimSpace(env)) {
+	case "production", "prod":
+		return supabase.EnvProduction
+	case "development", "dev":
+		return supabase.EnvDevelopment
+	default:
+		return supabase.EnvFeature
+	}
+}
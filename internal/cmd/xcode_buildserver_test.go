@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+func TestInstallVSCodeSourceKitSettings_XcrunFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		resp testutil.Response
+		want string
+	}{
+		{"exit code", testutil.Response{Stderr: "xcrun: error: unable to find utility \"sourcekit-lsp\"", Exit: 72}, "unable to find utility"},
+		{"empty output", testutil.Response{}, "printed no path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			testutil.NewFakeBin(t, "xcrun", tt.resp)
+
+			err := installVSCodeSourceKitSettings()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("installVSCodeSourceKitSettings() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import "strings"
+
+// BannerConfig adds environment identification to the files 'drift env setup'
+// generates, so apps can show which backend a build points to:
+// DRIFT_ENV_BANNER in every generated file and APP_ICON_SUFFIX in the
+// xcconfig (use it as ASSETCATALOG_COMPILER_APPICON_NAME = AppIcon$(APP_ICON_SUFFIX)).
+type BannerConfig struct {
+	Enabled      bool              `yaml:"enabled" mapstructure:"enabled"`
+	Labels       map[string]string `yaml:"labels,omitempty" mapstructure:"labels"`               // environment -> banner text
+	IconSuffixes map[string]string `yaml:"icon_suffixes,omitempty" mapstructure:"icon_suffixes"` // environment -> app icon suffix
+	IconBadge    IconBadgeConfig   `yaml:"icon_badge,omitempty" mapstructure:"icon_badge"`
+}
+
+// IconBadgeConfig generates a badged copy of the app icon for each
+// non-production environment, named after its icon suffix.
+type IconBadgeConfig struct {
+	AppIcon string `yaml:"app_icon,omitempty" mapstructure:"app_icon"` // e.g. MyApp/Assets.xcassets/AppIcon.appiconset
+}
+
+// LabelFor returns the banner text for environment. Production has no
+// banner unless one is configured; other environments default to their
+// name in upper case, e.g. DEVELOPMENT.
+func (b BannerConfig) LabelFor(environment string) string {
+	if v, ok := lookupEnvValue(b.Labels, environment); ok {
+		return v
+	}
+	if canonicalEnvironment(environment) == "production" {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimSpace(environment))
+}
+
+// IconSuffixFor returns the app icon suffix for environment: empty for
+// production, -dev for development, and -<name> for anything else.
+func (b BannerConfig) IconSuffixFor(environment string) string {
+	if v, ok := lookupEnvValue(b.IconSuffixes, environment); ok {
+		return v
+	}
+	switch env := canonicalEnvironment(environment); env {
+	case "production", "":
+		return ""
+	case "development":
+		return "-dev"
+	default:
+		return "-" + env
+	}
+}
+
+// lookupEnvValue finds environment in m using the same name normalization
+// as GetEnvironmentConfig.
+func lookupEnvValue(m map[string]string, environment string) (string, bool) {
+	for _, key := range environmentLookupKeys(environment) {
+		if v, ok := m[key]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// canonicalEnvironment returns the normalized name of environment, e.g.
+// "production" for Production or main.
+func canonicalEnvironment(environment string) string {
+	lower := strings.ToLower(strings.TrimSpace(environment))
+	switch lower {
+	case "prod", "production", "main", "master":
+		return "production"
+	case "dev", "development":
+		return "development"
+	case "feature", "preview":
+		return "feature"
+	}
+	return lower
+}
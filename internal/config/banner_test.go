@@ -0,0 +1,34 @@
+package config
+
+import "testing"
+
+func TestBannerConfig(t *testing.T) {
+	b := BannerConfig{
+		Labels:       map[string]string{"production": "", "feature": "PREVIEW"},
+		IconSuffixes: map[string]string{"staging": "-stg"},
+	}
+	tests := []struct {
+		env        string
+		wantLabel  string
+		wantSuffix string
+	}{
+		{"Production", "", ""},
+		{"Development", "DEVELOPMENT", "-dev"},
+		{"Feature", "PREVIEW", "-feature"},
+		{"staging", "STAGING", "-stg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			if got := b.LabelFor(tt.env); got != tt.wantLabel {
+				t.Errorf("LabelFor(%q) = %q, want %q", tt.env, got, tt.wantLabel)
+			}
+			if got := b.IconSuffixFor(tt.env); got != tt.wantSuffix {
+				t.Errorf("IconSuffixFor(%q) = %q, want %q", tt.env, got, tt.wantSuffix)
+			}
+		})
+	}
+
+	if got := (BannerConfig{Labels: map[string]string{"production": "LIVE"}}).LabelFor("main"); got != "LIVE" {
+		t.Errorf("LabelFor(main) = %q, want the production label", got)
+	}
+}
@@ -4,30 +4,41 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the .drift.yaml configuration file.
 type Config struct {
-	Project      ProjectConfig                `yaml:"project" mapstructure:"project"`
-	Supabase     SupabaseConfig               `yaml:"supabase" mapstructure:"supabase"`
-	Apple        AppleConfig                  `yaml:"apple" mapstructure:"apple"`
-	Xcode        XcodeConfig                  `yaml:"xcode" mapstructure:"xcode"`
-	Web          WebConfig                    `yaml:"web" mapstructure:"web"`
-	Database     DatabaseConfig               `yaml:"database" mapstructure:"database"`
-	Backup       BackupConfig                 `yaml:"backup" mapstructure:"backup"`
-	Worktree     WorktreeConfig               `yaml:"worktree" mapstructure:"worktree"`
-	Device       DeviceConfig                 `yaml:"device" mapstructure:"device"`
-	Environments map[string]EnvironmentConfig `yaml:"environments" mapstructure:"environments"`
+	Project       ProjectConfig                `yaml:"project" mapstructure:"project"`
+	Supabase      SupabaseConfig               `yaml:"supabase" mapstructure:"supabase"`
+	Apple         AppleConfig                  `yaml:"apple" mapstructure:"apple"`
+	Xcode         XcodeConfig                  `yaml:"xcode" mapstructure:"xcode"`
+	Web           WebConfig                    `yaml:"web" mapstructure:"web"`
+	Database      DatabaseConfig               `yaml:"database" mapstructure:"database"`
+	Backup        BackupConfig                 `yaml:"backup" mapstructure:"backup"`
+	Worktree      WorktreeConfig               `yaml:"worktree" mapstructure:"worktree"`
+	Device        DeviceConfig                 `yaml:"device" mapstructure:"device"`
+	Encryption    EncryptionConfig             `yaml:"encryption" mapstructure:"encryption"`
+	Test          TestConfig                   `yaml:"test" mapstructure:"test"`
+	Notifications NotificationsConfig          `yaml:"notifications" mapstructure:"notifications"`
+	Banner        BannerConfig                 `yaml:"banner" mapstructure:"banner"`
+	Masking       MaskingConfig                `yaml:"masking" mapstructure:"masking"`
+	Environments  map[string]EnvironmentConfig `yaml:"environments" mapstructure:"environments"`
 
 	// Preferences from .drift.local.yaml (merged at runtime)
 	Preferences PreferencesConfig `yaml:"-" mapstructure:"-"`
 
 	// Internal: path to the config file
 	configPath string
+
+	// Internal: error from decrypting .drift.local.yaml secrets, if any
+	localDecryptErr error
 }
 
 // ProjectType constants.
@@ -75,11 +86,106 @@ type SupabaseConfig struct {
 	SecretsToPush     []string          `yaml:"secrets_to_push" mapstructure:"secrets_to_push"`
 	DefaultSecrets    map[string]string `yaml:"default_secrets" mapstructure:"default_secrets"`
 	Functions         FunctionsConfig   `yaml:"functions" mapstructure:"functions"`
+	CacheTTL          string            `yaml:"cache_ttl" mapstructure:"cache_ttl"` // max age of cached API data used offline, e.g. "24h"
+	Endpoints         EndpointsConfig   `yaml:"endpoints,omitempty" mapstructure:"endpoints"`
+	Retries           *int              `yaml:"retries,omitempty" mapstructure:"retries"` // transient CLI/API failures are retried this many times; nil uses the default, 0 disables
+	Branches          BranchesConfig    `yaml:"branches,omitempty" mapstructure:"branches"`
+	Projects          []SupabaseProject `yaml:"projects,omitempty" mapstructure:"projects"` // repos that talk to several Supabase projects; the first is the default
+
+	// DBPasswords from .drift.local.yaml (merged at runtime)
+	DBPasswords map[string]string `yaml:"-" mapstructure:"-"`
+
+	// ActiveProject and EnvPrefix are set from the selected entry of
+	// Projects at load time (see UseSupabaseProject).
+	ActiveProject string `yaml:"-" mapstructure:"-"`
+	EnvPrefix     string `yaml:"-" mapstructure:"-"`
+}
+
+// EndpointsConfig overrides the hosted Supabase URLs for self-hosted
+// deployments and custom domains. Templates may use {ref} for the project
+// ref and, for the pooler host, {region}. Empty fields use supabase.com.
+type EndpointsConfig struct {
+	APIURL           string `yaml:"api_url,omitempty" mapstructure:"api_url"`                       // e.g. https://{ref}.example.com
+	ManagementAPIURL string `yaml:"management_api_url,omitempty" mapstructure:"management_api_url"` // e.g. https://api.example.com
+	DatabaseHost     string `yaml:"db_host,omitempty" mapstructure:"db_host"`                       // e.g. db.{ref}.example.com
+	PoolerHost       string `yaml:"pooler_host,omitempty" mapstructure:"pooler_host"`               // e.g. pooler.{region}.example.com
+	DashboardURL     string `yaml:"dashboard_url,omitempty" mapstructure:"dashboard_url"`           // e.g. https://studio.example.com/project/{ref}
+	DefaultRegion    string `yaml:"default_region,omitempty" mapstructure:"default_region"`         // region used when a project reports none
+}
+
+// BranchesConfig controls how long preview branches live.
+type BranchesConfig struct {
+	PreviewTTL string   `yaml:"preview_ttl,omitempty" mapstructure:"preview_ttl"` // e.g. "14d" or "72h"; empty disables autoexpire
+	Keep       []string `yaml:"keep,omitempty" mapstructure:"keep"`               // git branches, or patterns like release/*, that never expire
+}
+
+// PreviewTTLDuration parses PreviewTTL. It accepts Go durations and whole
+// days such as "14d", and returns 0 when no TTL is set.
+func (b BranchesConfig) PreviewTTLDuration() (time.Duration, error) {
+	return ParseTTL(b.PreviewTTL)
+}
+
+// IsKept reports whether gitBranch matches one of the keep patterns.
+func (b BranchesConfig) IsKept(gitBranch string) bool {
+	for _, pattern := range b.Keep {
+		if pattern == gitBranch {
+			return true
+		}
+		if ok, err := path.Match(pattern, gitBranch); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTTL parses a duration that may also be given in days, e.g. "14d".
+// An empty string is 0.
+func ParseTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: use e.g. 14d or 72h", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		d, err = time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: use e.g. 14d or 72h", s)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q: must be positive", s)
+	}
+	return d, nil
 }
 
 // FunctionsConfig holds Edge Functions configuration.
 type FunctionsConfig struct {
 	Restricted []FunctionRestriction `yaml:"restricted" mapstructure:"restricted"`
+	// Overrides holds per-function deploy settings, keyed by function name.
+	Overrides map[string]FunctionOverride `yaml:"overrides,omitempty" mapstructure:"overrides"`
+	// ImportMap is the import map for functions in functions_dir.
+	ImportMap string `yaml:"import_map,omitempty" mapstructure:"import_map"` // relative to the project root
+	// Roots lists extra directories holding functions, e.g. packages/edge.
+	Roots []FunctionRoot `yaml:"roots,omitempty" mapstructure:"roots"`
+}
+
+// FunctionRoot is a directory of Edge Functions outside functions_dir.
+type FunctionRoot struct {
+	Path      string `yaml:"path" mapstructure:"path"`                       // relative to the project root
+	ImportMap string `yaml:"import_map,omitempty" mapstructure:"import_map"` // relative to the project root
+}
+
+// FunctionOverride holds deploy settings for a single function.
+type FunctionOverride struct {
+	VerifyJWT *bool  `yaml:"verify_jwt,omitempty" mapstructure:"verify_jwt"` // nil follows --no-verify-jwt
+	ImportMap string `yaml:"import_map,omitempty" mapstructure:"import_map"` // relative to the project root
 }
 
 // FunctionRestriction defines a function that should be restricted in certain environments.
@@ -93,6 +199,12 @@ type EnvironmentConfig struct {
 	Secrets     map[string]string `yaml:"secrets" mapstructure:"secrets"`
 	PushKey     string            `yaml:"push_key" mapstructure:"push_key"`
 	SkipSecrets []string          `yaml:"skip_secrets" mapstructure:"skip_secrets"`
+
+	// SiteURL and RedirectURLs are auth URL templates for 'drift auth
+	// redirects'; they may use {branch}, {branch-slug}, {supabase-branch}
+	// and {ref}.
+	SiteURL      string   `yaml:"site_url" mapstructure:"site_url"`
+	RedirectURLs []string `yaml:"redirect_urls" mapstructure:"redirect_urls"`
 }
 
 // GetTargetBranch returns the Supabase branch to use for a git branch.
@@ -105,6 +217,12 @@ func (c *SupabaseConfig) GetTargetBranch(gitBranch string) string {
 	return gitBranch
 }
 
+// DBPasswordFor returns the postgres password recorded for the branch with
+// projectRef by 'drift db users rotate-password', or "".
+func (c *SupabaseConfig) DBPasswordFor(projectRef string) string {
+	return c.DBPasswords[projectRef]
+}
+
 // HasOverride returns true if an override branch is configured.
 func (c *SupabaseConfig) HasOverride() bool {
 	return c.OverrideBranch != ""
@@ -118,6 +236,32 @@ type AppleConfig struct {
 	PushEnvironment string   `yaml:"push_environment" mapstructure:"push_environment"` // development, production
 	SecretsDir      string   `yaml:"secrets_dir" mapstructure:"secrets_dir"`           // directory for API keys, certs (default: secrets)
 	KeySearchPaths  []string `yaml:"key_search_paths" mapstructure:"key_search_paths"` // search paths for APNs key files
+
+	PushTokens PushTokensConfig `yaml:"push_tokens" mapstructure:"push_tokens"`
+
+	// AppStoreConnect is the API key used to read TestFlight builds. It
+	// is usually set in .drift.local.yaml.
+	AppStoreConnect AppStoreConnectConfig `yaml:"app_store_connect,omitempty" mapstructure:"app_store_connect"`
+}
+
+// AppStoreConnectConfig identifies an App Store Connect API key.
+type AppStoreConnectConfig struct {
+	KeyID    string `yaml:"key_id,omitempty" mapstructure:"key_id"`
+	IssuerID string `yaml:"issuer_id,omitempty" mapstructure:"issuer_id"`
+	KeyPath  string `yaml:"key_path,omitempty" mapstructure:"key_path"` // .p8 file; relative paths are from the project root
+}
+
+// IsSet reports whether all three fields are filled in.
+func (a AppStoreConnectConfig) IsSet() bool {
+	return a.KeyID != "" && a.IssuerID != "" && a.KeyPath != ""
+}
+
+// PushTokensConfig tells 'drift push tokens' where the app registers APNs device tokens.
+type PushTokensConfig struct {
+	Table             string `yaml:"table" mapstructure:"table"`                           // e.g. public.device_tokens
+	TokenColumn       string `yaml:"token_column" mapstructure:"token_column"`             // default: token
+	CreatedColumn     string `yaml:"created_column" mapstructure:"created_column"`         // default: created_at
+	EnvironmentColumn string `yaml:"environment_column" mapstructure:"environment_column"` // optional: development/sandbox/production
 }
 
 // XcodeConfig holds Xcode-related configuration.
@@ -125,11 +269,70 @@ type XcodeConfig struct {
 	XcconfigOutput string            `yaml:"xcconfig_output" mapstructure:"xcconfig_output"`
 	VersionFile    string            `yaml:"version_file" mapstructure:"version_file"`
 	Schemes        map[string]string `yaml:"schemes" mapstructure:"schemes"`
+	Sync           XcodeSyncConfig   `yaml:"sync" mapstructure:"sync"`
+	// BuildNumbers maps an environment to where 'drift version bump' takes
+	// the next build number from: local (Version.xcconfig, the default) or
+	// testflight (one above the latest App Store Connect build).
+	BuildNumbers map[string]string `yaml:"build_numbers,omitempty" mapstructure:"build_numbers"`
+}
+
+// Build number sources for xcode.build_numbers.
+const (
+	BuildNumberLocal      = "local"
+	BuildNumberTestFlight = "testflight"
+)
+
+// BuildNumberSource returns the build number source for env (production,
+// development or feature; case-insensitive).
+func (x *XcodeConfig) BuildNumberSource(env string) string {
+	for name, source := range x.BuildNumbers {
+		if strings.EqualFold(name, env) && source != "" {
+			return source
+		}
+	}
+	return BuildNumberLocal
+}
+
+// XcodeSyncConfig controls per-environment patching of Info.plist and entitlements files.
+type XcodeSyncConfig struct {
+	Enabled bool            `yaml:"enabled" mapstructure:"enabled"`
+	Files   []PlistSyncFile `yaml:"files" mapstructure:"files"`
+}
+
+// PlistSyncFile lists the plist keys to set in one file, keyed by environment.
+type PlistSyncFile struct {
+	Path         string                            `yaml:"path" mapstructure:"path"`
+	Environments map[string]map[string]interface{} `yaml:"environments" mapstructure:"environments"`
+}
+
+// ValuesFor returns the plist values for an environment, using the same
+// name normalization and feature -> development fallback as GetEnvironmentConfig.
+func (f *PlistSyncFile) ValuesFor(environment string) map[string]interface{} {
+	keys := environmentLookupKeys(environment)
+	var merged map[string]interface{}
+
+	for i := len(keys) - 1; i >= 0; i-- {
+		values, ok := f.Environments[keys[i]]
+		if !ok {
+			continue
+		}
+		if merged == nil {
+			merged = make(map[string]interface{})
+		}
+		for k, v := range values {
+			merged[k] = v
+		}
+	}
+
+	return merged
 }
 
 // WebConfig holds web project configuration.
 type WebConfig struct {
-	EnvOutput string `yaml:"env_output" mapstructure:"env_output"` // .env.local by default
+	Framework       string `yaml:"framework" mapstructure:"framework"`                 // nextjs (default), vite, expo, flutter
+	EnvOutput       string `yaml:"env_output" mapstructure:"env_output"`               // .env.local by default; .env for vite/expo
+	ServiceRoleKey  string `yaml:"service_role_key" mapstructure:"service_role_key"`   // include, deny, server-only
+	ServerEnvOutput string `yaml:"server_env_output" mapstructure:"server_env_output"` // used by server-only policy
 }
 
 // DatabaseConfig holds database connection configuration.
@@ -142,6 +345,57 @@ type DatabaseConfig struct {
 	DumpFormat        string            `yaml:"dump_format" mapstructure:"dump_format"`   // custom, plain, directory, tar
 	BackupDir         string            `yaml:"backup_dir" mapstructure:"backup_dir"`     // local backup directory
 	PromptFresh       bool              `yaml:"prompt_fresh" mapstructure:"prompt_fresh"` // prompt when backup is stale
+	Subset            SubsetConfig      `yaml:"subset" mapstructure:"subset"`
+	Realtime          RealtimeConfig    `yaml:"realtime" mapstructure:"realtime"`
+	SeedProfiles      map[string]string `yaml:"seed_profiles" mapstructure:"seed_profiles"` // name -> SQL file for 'drift db seed apply'
+	Flags             FlagsConfig       `yaml:"flags" mapstructure:"flags"`
+	PushExcludeTables []string          `yaml:"push_exclude_tables" mapstructure:"push_exclude_tables"` // table data never dumped or pushed
+}
+
+// FlagsConfig describes the feature flag table used by 'drift flags'.
+type FlagsConfig struct {
+	Table         string `yaml:"table" mapstructure:"table"`                   // default: public.feature_flags
+	KeyColumn     string `yaml:"key_column" mapstructure:"key_column"`         // default: key
+	EnabledColumn string `yaml:"enabled_column" mapstructure:"enabled_column"` // boolean column, default: enabled
+}
+
+// GetTable returns the configured flag table or public.feature_flags.
+func (f *FlagsConfig) GetTable() string {
+	if f == nil || strings.TrimSpace(f.Table) == "" {
+		return "public.feature_flags"
+	}
+	return strings.TrimSpace(f.Table)
+}
+
+// GetKeyColumn returns the column holding flag names.
+func (f *FlagsConfig) GetKeyColumn() string {
+	if f == nil || strings.TrimSpace(f.KeyColumn) == "" {
+		return "key"
+	}
+	return strings.TrimSpace(f.KeyColumn)
+}
+
+// GetEnabledColumn returns the boolean column holding flag state.
+func (f *FlagsConfig) GetEnabledColumn() string {
+	if f == nil || strings.TrimSpace(f.EnabledColumn) == "" {
+		return "enabled"
+	}
+	return strings.TrimSpace(f.EnabledColumn)
+}
+
+// RealtimeConfig lists the tables that must be in the supabase_realtime publication.
+type RealtimeConfig struct {
+	Tables []string `yaml:"tables" mapstructure:"tables"` // e.g. public.messages
+}
+
+// SubsetConfig configures 'drift db subset' thin clones.
+type SubsetConfig struct {
+	RootTable string   `yaml:"root_table" mapstructure:"root_table"` // e.g. public.users
+	Limit     int      `yaml:"limit" mapstructure:"limit"`           // max root rows
+	Percent   float64  `yaml:"percent" mapstructure:"percent"`       // sample % of the root table
+	Where     string   `yaml:"where" mapstructure:"where"`           // optional filter on the root table
+	Schemas   []string `yaml:"schemas" mapstructure:"schemas"`       // schemas to follow FKs through (default: public)
+	Exclude   []string `yaml:"exclude" mapstructure:"exclude"`       // tables never copied
 }
 
 // GetPoolerHostForBranch resolves the pooler host for a git branch/environment label.
@@ -183,6 +437,54 @@ func (d *DatabaseConfig) GetPoolerPort() int {
 	return d.PoolerPort
 }
 
+// GetPushExcludeTables returns database.push_exclude_tables as lower-case
+// schema-qualified names; bare names are in the public schema.
+func (d *DatabaseConfig) GetPushExcludeTables() []string {
+	if d == nil {
+		return nil
+	}
+	var tables []string
+	seen := make(map[string]bool)
+	for _, t := range d.PushExcludeTables {
+		t = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(t), `"`, ""))
+		if t == "" {
+			continue
+		}
+		if !strings.Contains(t, ".") {
+			t = "public." + t
+		}
+		if !seen[t] {
+			seen[t] = true
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+// NotificationsConfig configures where deploy and database operation results are posted.
+// Values may reference environment variables (e.g. ${SLACK_WEBHOOK_URL}) to
+// keep webhook secrets out of .drift.yaml.
+type NotificationsConfig struct {
+	SlackWebhook string `yaml:"slack_webhook" mapstructure:"slack_webhook"` // Slack incoming webhook URL
+	Webhook      string `yaml:"webhook" mapstructure:"webhook"`             // generic JSON webhook URL
+}
+
+// GetSlackWebhook returns the Slack webhook URL with environment variables expanded.
+func (n *NotificationsConfig) GetSlackWebhook() string {
+	if n == nil {
+		return ""
+	}
+	return strings.TrimSpace(os.ExpandEnv(n.SlackWebhook))
+}
+
+// GetWebhook returns the generic webhook URL with environment variables expanded.
+func (n *NotificationsConfig) GetWebhook() string {
+	if n == nil {
+		return ""
+	}
+	return strings.TrimSpace(os.ExpandEnv(n.Webhook))
+}
+
 // BackupConfig holds backup storage configuration.
 type BackupConfig struct {
 	Provider      string `yaml:"provider" mapstructure:"provider"` // supabase, s3, backblaze
@@ -192,19 +494,106 @@ type BackupConfig struct {
 
 // WorktreeConfig holds git worktree configuration.
 type WorktreeConfig struct {
-	NamingPattern     string   `yaml:"naming_pattern" mapstructure:"naming_pattern"`
-	CopyOnCreate      []string `yaml:"copy_on_create" mapstructure:"copy_on_create"`
-	AutoSetupXcconfig bool     `yaml:"auto_setup_xcconfig" mapstructure:"auto_setup_xcconfig"`
+	NamingPattern     string     `yaml:"naming_pattern" mapstructure:"naming_pattern"`
+	CopyOnCreate      []CopyRule `yaml:"copy_on_create" mapstructure:"copy_on_create"`
+	AutoSetupXcconfig bool       `yaml:"auto_setup_xcconfig" mapstructure:"auto_setup_xcconfig"`
+	// Ports maps env var names to base ports. Each worktree gets its own
+	// port per name, written to the generated env file.
+	Ports map[string]int `yaml:"ports,omitempty" mapstructure:"ports"`
+	// Guard warns before env setup and database commands run from one
+	// worktree against the drift project of another. nil means enabled.
+	Guard *bool `yaml:"guard,omitempty" mapstructure:"guard"`
+}
+
+// GuardEnabled reports whether the wrong-worktree guard runs.
+func (w *WorktreeConfig) GuardEnabled() bool {
+	return w.Guard == nil || *w.Guard
+}
+
+// CopyRule is a worktree.copy_on_create entry: files matching the glob From
+// in the main worktree are copied to To in the new worktree, with Transforms
+// applied to their contents. A plain string entry is shorthand for a rule
+// with only From, which copies each match to the worktree root.
+type CopyRule struct {
+	From       string          `yaml:"from" mapstructure:"from"`
+	To         string          `yaml:"to,omitempty" mapstructure:"to"`
+	Transforms []CopyTransform `yaml:"transforms,omitempty" mapstructure:"transforms"`
+}
+
+// CopyTransform rewrites copied file contents. Replace matches literal text
+// and Regex a regular expression; With may reference regex groups ($1) and
+// the placeholders {port}, {port:<label>}, {name}, and {path}.
+type CopyTransform struct {
+	Replace string `yaml:"replace,omitempty" mapstructure:"replace"`
+	Regex   string `yaml:"regex,omitempty" mapstructure:"regex"`
+	With    string `yaml:"with" mapstructure:"with"`
+}
+
+// UnmarshalYAML accepts either a glob string or a rule mapping.
+func (r *CopyRule) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*r = CopyRule{From: node.Value}
+		return nil
+	}
+	type plain CopyRule
+	return node.Decode((*plain)(r))
+}
+
+// MarshalYAML writes rules with only From back as plain strings.
+func (r CopyRule) MarshalYAML() (interface{}, error) {
+	if r.To == "" && len(r.Transforms) == 0 {
+		return r.From, nil
+	}
+	type plain CopyRule
+	return plain(r), nil
+}
+
+// IsDirTarget reports whether To names a directory that matches are copied
+// into, rather than a single destination file. That is the case when To ends
+// in a slash or From is a glob.
+func (r CopyRule) IsDirTarget() bool {
+	return strings.HasSuffix(r.To, "/") || strings.ContainsAny(r.From, "*?[")
 }
 
 // DeviceConfig holds mobile device automation configuration.
 type DeviceConfig struct {
 	WDAPath       string        `yaml:"wda_path" mapstructure:"wda_path"`
+	WDAPrebuilt   string        `yaml:"wda_prebuilt" mapstructure:"wda_prebuilt"`   // path or URL to a prebuilt WebDriverAgentRunner .ipa
+	WDABundleID   string        `yaml:"wda_bundle_id" mapstructure:"wda_bundle_id"` // bundle ID to resign the prebuilt runner with
+	WDAProfile    string        `yaml:"wda_profile" mapstructure:"wda_profile"`     // .mobileprovision used for resigning (default: auto-detect)
+	WDAIdentity   string        `yaml:"wda_identity" mapstructure:"wda_identity"`   // code signing identity (default: auto-detect)
 	WDAPort       int           `yaml:"wda_port" mapstructure:"wda_port"`
 	DefaultDevice string        `yaml:"default_device" mapstructure:"default_device"`
 	Devices       []DeviceEntry `yaml:"devices" mapstructure:"devices"`
 }
 
+// MaskingConfig extends which variable names drift masks when it prints
+// values. Patterns are globs (STRIPE_*) or words of a name (WEBHOOK_SIGNING).
+type MaskingConfig struct {
+	Sensitive []string `yaml:"sensitive,omitempty" mapstructure:"sensitive"` // masked in addition to the defaults
+	Public    []string `yaml:"public,omitempty" mapstructure:"public"`       // never masked, e.g. *_PUBLISHABLE_KEY
+}
+
+// EncryptionConfig controls at-rest encryption of secret values in generated env files.
+// When enabled, listed keys are written as drift-secret: references and the real
+// values live in the configured backend until 'drift run' decrypts them.
+type EncryptionConfig struct {
+	Enabled      bool     `yaml:"enabled" mapstructure:"enabled"`
+	Backend      string   `yaml:"backend" mapstructure:"backend"`             // keychain, age
+	Keys         []string `yaml:"keys" mapstructure:"keys"`                   // variable names to encrypt
+	AgeRecipient string   `yaml:"age_recipient" mapstructure:"age_recipient"` // age public key (age backend)
+	AgeIdentity  string   `yaml:"age_identity" mapstructure:"age_identity"`   // age identity file (age backend)
+
+	// LocalRecipientKeys are the team's age public keys used by 'drift config encrypt'
+	// for environments.*.secrets in .drift.local.yaml.
+	LocalRecipientKeys []string `yaml:"local_recipients" mapstructure:"local_recipients"`
+}
+
+// TestConfig holds the commands run by 'drift test', keyed by suite name (unit, e2e, ...).
+type TestConfig struct {
+	Commands map[string]string `yaml:"commands" mapstructure:"commands"`
+}
+
 // DeviceEntry represents a configured test device.
 type DeviceEntry struct {
 	Name    string `yaml:"name" mapstructure:"name"`
@@ -308,6 +697,36 @@ func (c *Config) GetFunctionsPath() string {
 	return filepath.Join(c.ProjectRoot(), c.Supabase.FunctionsDir)
 }
 
+// GetFunctionRoots returns every functions directory with absolute paths,
+// functions_dir first. A root listed in supabase.functions.roots that is
+// functions_dir itself only contributes its import map.
+func (c *Config) GetFunctionRoots() []FunctionRoot {
+	abs := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(c.ProjectRoot(), path)
+	}
+
+	roots := []FunctionRoot{{Path: c.GetFunctionsPath(), ImportMap: abs(c.Supabase.Functions.ImportMap)}}
+	seen := map[string]int{filepath.Clean(roots[0].Path): 0}
+	for _, r := range c.Supabase.Functions.Roots {
+		if r.Path == "" {
+			continue
+		}
+		root := FunctionRoot{Path: filepath.Clean(abs(r.Path)), ImportMap: abs(r.ImportMap)}
+		if i, ok := seen[root.Path]; ok {
+			if root.ImportMap != "" {
+				roots[i].ImportMap = root.ImportMap
+			}
+			continue
+		}
+		seen[root.Path] = len(roots)
+		roots = append(roots, root)
+	}
+	return roots
+}
+
 // GetMigrationsPath returns the absolute path to the migrations directory.
 func (c *Config) GetMigrationsPath() string {
 	return filepath.Join(c.ProjectRoot(), c.Supabase.MigrationsDir)
@@ -328,6 +747,11 @@ func (c *Config) GetEnvLocalPath() string {
 	return filepath.Join(c.ProjectRoot(), c.Web.EnvOutput)
 }
 
+// GetServerEnvPath returns the absolute path to the server-only env file for web projects.
+func (c *Config) GetServerEnvPath() string {
+	return filepath.Join(c.ProjectRoot(), c.Web.ServerEnvOutput)
+}
+
 // GetSecretsPath returns the absolute path to the secrets directory.
 func (c *Config) GetSecretsPath() string {
 	return filepath.Join(c.ProjectRoot(), c.Apple.SecretsDir)
@@ -422,6 +846,12 @@ func (c *Config) IsFunctionRestricted(functionName, environment string) bool {
 	return false
 }
 
+// GetFunctionOverride returns the deploy settings for a function, or the
+// zero value when none are configured.
+func (c *Config) GetFunctionOverride(functionName string) FunctionOverride {
+	return c.Supabase.Functions.Overrides[functionName]
+}
+
 // GetRestrictedFunctions returns a list of function names restricted in the given environment.
 func (c *Config) GetRestrictedFunctions(environment string) []string {
 	var restricted []string
@@ -480,6 +910,12 @@ func (c *Config) GetEnvironmentConfig(environment string) *EnvironmentConfig {
 				merged.SkipSecrets = append(merged.SkipSecrets, secretKey)
 			}
 		}
+		if env.SiteURL != "" {
+			merged.SiteURL = env.SiteURL
+		}
+		if len(env.RedirectURLs) > 0 {
+			merged.RedirectURLs = env.RedirectURLs
+		}
 	}
 
 	if !found {
@@ -518,15 +954,7 @@ func environmentLookupKeys(environment string) []string {
 		keys = append(keys, lower)
 	}
 
-	canonical := lower
-	switch lower {
-	case "prod", "production", "main", "master":
-		canonical = "production"
-	case "dev", "development":
-		canonical = "development"
-	case "feature", "preview":
-		canonical = "feature"
-	}
+	canonical := canonicalEnvironment(raw)
 
 	if !containsString(keys, canonical) {
 		keys = append(keys, canonical)
@@ -625,7 +1053,36 @@ func (c *Config) GetEditor() string {
 	return "code" // Default to VS Code
 }
 
+// GetOpener returns the name of the opener for worktrees: the opener
+// preference, else the editor preference, else VS Code.
+func (c *Config) GetOpener() string {
+	if c.Preferences.Opener != "" {
+		return c.Preferences.Opener
+	}
+	return c.GetEditor()
+}
+
 // ShouldAutoOpenWorktree returns whether worktrees should auto-open after creation.
 func (c *Config) ShouldAutoOpenWorktree() bool {
 	return c.Preferences.AutoOpenWorktree
 }
+
+// Values of preferences.env_refresh.
+const (
+	EnvRefreshWarn       = "warn"
+	EnvRefreshRegenerate = "regenerate"
+)
+
+// GetEnvRefresh returns preferences.env_refresh, defaulting to warn.
+func (c *Config) GetEnvRefresh() string {
+	if strings.EqualFold(strings.TrimSpace(c.Preferences.EnvRefresh), EnvRefreshRegenerate) {
+		return EnvRefreshRegenerate
+	}
+	return EnvRefreshWarn
+}
+
+// IsMetricsHistoryEnabled returns whether command timings are kept for
+// 'drift metrics history'.
+func (c *Config) IsMetricsHistoryEnabled() bool {
+	return c.Preferences.MetricsHistory
+}
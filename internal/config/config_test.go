@@ -3,7 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestDefaultConfig_HasExpectedValues(t *testing.T) {
@@ -110,6 +114,30 @@ func TestMergeWithDefaults_FillsMissingValues(t *testing.T) {
 	}
 }
 
+func TestMergeWithDefaults_WebEnvOutputFollowsFramework(t *testing.T) {
+	tests := []struct {
+		framework string
+		want      string
+	}{
+		{"", ".env.local"},
+		{"nextjs", ".env.local"},
+		{"vite", ".env"},
+		{"expo", ".env"},
+		{"flutter", "dart_defines.json"},
+	}
+	for _, tt := range tests {
+		merged := MergeWithDefaults(&Config{Web: WebConfig{Framework: tt.framework}})
+		if merged.Web.EnvOutput != tt.want {
+			t.Errorf("framework %q: EnvOutput = %q, want %q", tt.framework, merged.Web.EnvOutput, tt.want)
+		}
+	}
+
+	merged := MergeWithDefaults(&Config{Web: WebConfig{Framework: "vite", EnvOutput: "apps/web/.env.development"}})
+	if merged.Web.EnvOutput != "apps/web/.env.development" {
+		t.Errorf("explicit env_output overridden, got %q", merged.Web.EnvOutput)
+	}
+}
+
 func TestMergeWithDefaults_PreservesExistingValues(t *testing.T) {
 	cfg := &Config{
 		Project: ProjectConfig{
@@ -203,6 +231,18 @@ func TestDatabaseConfig_GetPoolerHostForBranch_FeatureFallsBackToDevelopment(t *
 	}
 }
 
+func TestDatabaseConfig_GetPushExcludeTables_Normalizes(t *testing.T) {
+	db := &DatabaseConfig{
+		PushExcludeTables: []string{" analytics_events ", `"Audit".Log`, "public.analytics_events", ""},
+	}
+
+	got := db.GetPushExcludeTables()
+	want := []string{"public.analytics_events", "audit.log"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetPushExcludeTables() = %v, want %v", got, want)
+	}
+}
+
 func TestLoadFromPath_ValidConfig(t *testing.T) {
 	// Create a temp config file
 	tmpDir := t.TempDir()
@@ -514,6 +554,31 @@ func TestConfig_GetEnvironmentConfig_FeatureOverridesDevelopment(t *testing.T) {
 	}
 }
 
+func TestConfig_GetEnvironmentConfig_AuthURLs(t *testing.T) {
+	cfg := &Config{
+		Environments: map[string]EnvironmentConfig{
+			"development": {
+				SiteURL:      "https://dev.example.com",
+				RedirectURLs: []string{"https://dev.example.com/**"},
+			},
+			"feature": {
+				RedirectURLs: []string{"https://{branch-slug}.vercel.app/**"},
+			},
+		},
+	}
+
+	feature := cfg.GetEnvironmentConfig("Feature")
+	if feature == nil {
+		t.Fatal("GetEnvironmentConfig(Feature) returned nil")
+	}
+	if feature.SiteURL != "https://dev.example.com" {
+		t.Errorf("Feature inherited SiteURL = %q", feature.SiteURL)
+	}
+	if len(feature.RedirectURLs) != 1 || feature.RedirectURLs[0] != "https://{branch-slug}.vercel.app/**" {
+		t.Errorf("Feature RedirectURLs = %v, want only the feature templates", feature.RedirectURLs)
+	}
+}
+
 func TestConfig_GetMigrationsPath(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, ".drift.yaml")
@@ -798,8 +863,120 @@ worktree:
 	if len(cfg.Worktree.CopyOnCreate) != 3 {
 		t.Errorf("CopyOnCreate length = %d, want 3", len(cfg.Worktree.CopyOnCreate))
 	}
-	if cfg.Worktree.CopyOnCreate[0] != ".env" {
-		t.Errorf("CopyOnCreate[0] = %q, want %q", cfg.Worktree.CopyOnCreate[0], ".env")
+	if cfg.Worktree.CopyOnCreate[0].From != ".env" {
+		t.Errorf("CopyOnCreate[0].From = %q, want %q", cfg.Worktree.CopyOnCreate[0].From, ".env")
+	}
+}
+
+func TestLoadFromPath_CopyOnCreateRules(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".drift.yaml")
+
+	content := `
+worktree:
+  copy_on_create:
+    - .env
+    - from: "secrets/*.p8"
+      to: ios/keys/
+    - from: .env.local
+      transforms:
+        - replace: PORT=3000
+          with: PORT={port}
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	want := []CopyRule{
+		{From: ".env"},
+		{From: "secrets/*.p8", To: "ios/keys/"},
+		{From: ".env.local", Transforms: []CopyTransform{{Replace: "PORT=3000", With: "PORT={port}"}}},
+	}
+	if !reflect.DeepEqual(cfg.Worktree.CopyOnCreate, want) {
+		t.Errorf("CopyOnCreate = %+v, want %+v", cfg.Worktree.CopyOnCreate, want)
+	}
+
+	out, err := yaml.Marshal(cfg.Worktree.CopyOnCreate[:2])
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	if got := string(out); got != "- .env\n- from: secrets/*.p8\n  to: ios/keys/\n" {
+		t.Errorf("yaml.Marshal() = %q", got)
+	}
+}
+
+func TestLoadFromPath_FunctionOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".drift.yaml")
+
+	content := `
+supabase:
+  functions:
+    overrides:
+      stripe-webhook:
+        verify_jwt: false
+        import_map: supabase/functions/stripe-webhook/deno.json
+      send-email:
+        verify_jwt: true
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	webhook := cfg.GetFunctionOverride("stripe-webhook")
+	if webhook.VerifyJWT == nil || *webhook.VerifyJWT {
+		t.Errorf("stripe-webhook VerifyJWT = %v, want false", webhook.VerifyJWT)
+	}
+	if webhook.ImportMap != "supabase/functions/stripe-webhook/deno.json" {
+		t.Errorf("stripe-webhook ImportMap = %q", webhook.ImportMap)
+	}
+	if email := cfg.GetFunctionOverride("send-email"); email.VerifyJWT == nil || !*email.VerifyJWT {
+		t.Errorf("send-email VerifyJWT = %v, want true", email.VerifyJWT)
+	}
+	if other := cfg.GetFunctionOverride("other"); other.VerifyJWT != nil || other.ImportMap != "" {
+		t.Errorf("GetFunctionOverride(other) = %+v, want zero value", other)
+	}
+}
+
+func TestGetFunctionRoots(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".drift.yaml")
+
+	content := `
+supabase:
+  functions:
+    roots:
+      - path: packages/edge
+        import_map: packages/edge/import_map.json
+      - path: supabase/functions
+        import_map: supabase/functions/deno.json
+      - path: packages/edge/
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	want := []FunctionRoot{
+		{Path: filepath.Join(tmpDir, "supabase/functions"), ImportMap: filepath.Join(tmpDir, "supabase/functions/deno.json")},
+		{Path: filepath.Join(tmpDir, "packages/edge"), ImportMap: filepath.Join(tmpDir, "packages/edge/import_map.json")},
+	}
+	if got := cfg.GetFunctionRoots(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFunctionRoots() = %+v, want %+v", got, want)
 	}
 }
 
@@ -834,6 +1011,19 @@ func TestMergeLocalConfig_MergesSkipSecrets(t *testing.T) {
 	}
 }
 
+func TestMergeLocalConfig_DBPasswords(t *testing.T) {
+	local := &LocalConfig{}
+	local.Supabase.DBPasswords = map[string]string{"abcref": "rotated"}
+
+	merged := MergeLocalConfig(&Config{}, local)
+	if got := merged.Supabase.DBPasswordFor("abcref"); got != "rotated" {
+		t.Errorf("DBPasswordFor(abcref) = %q, want rotated", got)
+	}
+	if got := merged.Supabase.DBPasswordFor("otherref"); got != "" {
+		t.Errorf("DBPasswordFor(otherref) = %q, want empty", got)
+	}
+}
+
 func TestLoadLocalFromPath_PreservesSecretKeyCase(t *testing.T) {
 	tmpDir := t.TempDir()
 	localPath := filepath.Join(tmpDir, ".drift.local.yaml")
@@ -889,6 +1079,42 @@ func TestConfig_GetEnvironmentConfig_MergesSkipSecretsFromFallback(t *testing.T)
 	}
 }
 
+func TestPlistSyncFile_ValuesFor(t *testing.T) {
+	file := PlistSyncFile{
+		Path: "App/App.entitlements",
+		Environments: map[string]map[string]interface{}{
+			"production": {
+				"com.apple.developer.associated-domains": []interface{}{"applinks:example.com"},
+			},
+			"development": {
+				"com.apple.developer.associated-domains": []interface{}{"applinks:dev.example.com"},
+				"API_BASE_URL":                           "https://dev-api.example.com",
+			},
+			"feature": {
+				"API_BASE_URL": "https://preview-api.example.com",
+			},
+		},
+	}
+
+	prod := file.ValuesFor("Production")
+	if len(prod) != 1 {
+		t.Fatalf("ValuesFor(Production) = %v, want 1 key", prod)
+	}
+
+	feature := file.ValuesFor("Feature")
+	if got := feature["API_BASE_URL"]; got != "https://preview-api.example.com" {
+		t.Errorf("feature API_BASE_URL = %v, want feature-specific value", got)
+	}
+	domains, ok := feature["com.apple.developer.associated-domains"].([]interface{})
+	if !ok || len(domains) != 1 || domains[0] != "applinks:dev.example.com" {
+		t.Errorf("feature associated-domains = %v, want development fallback", feature["com.apple.developer.associated-domains"])
+	}
+
+	if got := file.ValuesFor("staging"); got != nil {
+		t.Errorf("ValuesFor(staging) = %v, want nil", got)
+	}
+}
+
 func containsValue(values []string, target string) bool {
 	for _, value := range values {
 		if value == target {
@@ -897,3 +1123,39 @@ func containsValue(values []string, target string) bool {
 	}
 	return false
 }
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"14d", 14 * 24 * time.Hour, false},
+		{"72h", 72 * time.Hour, false},
+		{"1.5d", 0, true},
+		{"0d", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTTL(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTTL(%q) = %v, %v; want %v, err %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBranchesConfig_IsKept(t *testing.T) {
+	b := BranchesConfig{Keep: []string{"demo", "release/*"}}
+	tests := map[string]bool{
+		"demo":            true,
+		"release/2.0":     true,
+		"release/2.0/fix": false,
+		"feature/demo":    false,
+	}
+	for branch, want := range tests {
+		if got := b.IsKept(branch); got != want {
+			t.Errorf("IsKept(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
@@ -26,6 +26,10 @@ func DefaultConfig() *Config {
 			PushEnvironment: "development",
 			SecretsDir:      "secrets",
 			KeySearchPaths:  []string{"secrets", ".", ".."},
+			PushTokens: PushTokensConfig{
+				TokenColumn:   "token",
+				CreatedColumn: "created_at",
+			},
 		},
 		Xcode: XcodeConfig{
 			XcconfigOutput: "Config.xcconfig",
@@ -37,7 +41,9 @@ func DefaultConfig() *Config {
 			},
 		},
 		Web: WebConfig{
-			EnvOutput: ".env.local",
+			EnvOutput:       ".env.local",
+			ServiceRoleKey:  "include",
+			ServerEnvOutput: ".env.server.local",
 		},
 		Database: DatabaseConfig{
 			PoolerHost:        DefaultDatabasePoolerHost,
@@ -56,7 +62,7 @@ func DefaultConfig() *Config {
 		},
 		Worktree: WorktreeConfig{
 			NamingPattern:     "{project}-{branch}",
-			CopyOnCreate:      []string{".env", "secrets/*"},
+			CopyOnCreate:      []CopyRule{{From: ".env"}, {From: "secrets/*"}},
 			AutoSetupXcconfig: true,
 		},
 		Device: DeviceConfig{
@@ -65,6 +71,16 @@ func DefaultConfig() *Config {
 			DefaultDevice: "",
 			Devices:       []DeviceEntry{},
 		},
+		Encryption: EncryptionConfig{
+			Enabled: false,
+			Backend: "keychain",
+			Keys: []string{
+				"SUPABASE_SERVICE_ROLE_KEY",
+				"DATABASE_URL",
+				"DATABASE_URL_POOLER",
+				"DATABASE_URL_POOLER_SESSION",
+			},
+		},
 	}
 }
 
@@ -101,6 +117,12 @@ func MergeWithDefaults(cfg *Config) *Config {
 	if len(cfg.Apple.KeySearchPaths) == 0 {
 		cfg.Apple.KeySearchPaths = defaults.Apple.KeySearchPaths
 	}
+	if cfg.Apple.PushTokens.TokenColumn == "" {
+		cfg.Apple.PushTokens.TokenColumn = defaults.Apple.PushTokens.TokenColumn
+	}
+	if cfg.Apple.PushTokens.CreatedColumn == "" {
+		cfg.Apple.PushTokens.CreatedColumn = defaults.Apple.PushTokens.CreatedColumn
+	}
 
 	// Xcode defaults
 	if cfg.Xcode.XcconfigOutput == "" {
@@ -112,7 +134,20 @@ func MergeWithDefaults(cfg *Config) *Config {
 
 	// Web defaults
 	if cfg.Web.EnvOutput == "" {
-		cfg.Web.EnvOutput = defaults.Web.EnvOutput
+		switch cfg.Web.Framework {
+		case "vite", "expo":
+			cfg.Web.EnvOutput = ".env"
+		case "flutter":
+			cfg.Web.EnvOutput = "dart_defines.json"
+		default:
+			cfg.Web.EnvOutput = defaults.Web.EnvOutput
+		}
+	}
+	if cfg.Web.ServiceRoleKey == "" {
+		cfg.Web.ServiceRoleKey = defaults.Web.ServiceRoleKey
+	}
+	if cfg.Web.ServerEnvOutput == "" {
+		cfg.Web.ServerEnvOutput = defaults.Web.ServerEnvOutput
 	}
 
 	// Database defaults
@@ -162,5 +197,13 @@ func MergeWithDefaults(cfg *Config) *Config {
 		cfg.Device.WDAPort = defaults.Device.WDAPort
 	}
 
+	// Encryption defaults
+	if cfg.Encryption.Backend == "" {
+		cfg.Encryption.Backend = defaults.Encryption.Backend
+	}
+	if len(cfg.Encryption.Keys) == 0 {
+		cfg.Encryption.Keys = defaults.Encryption.Keys
+	}
+
 	return cfg
 }
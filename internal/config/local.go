@@ -23,11 +23,15 @@ type LocalConfig struct {
 type LocalSupabaseConfig struct {
 	OverrideBranch string `yaml:"override_branch" mapstructure:"override_branch"`
 	FallbackBranch string `yaml:"fallback_branch" mapstructure:"fallback_branch"`
+	// DBPasswords maps a branch project ref to the postgres password set by
+	// 'drift db users rotate-password'. Values may be age-encrypted.
+	DBPasswords map[string]string `yaml:"db_passwords,omitempty" mapstructure:"db_passwords"`
 }
 
 // LocalAppleConfig holds local Apple/APNs overrides.
 type LocalAppleConfig struct {
-	KeySearchPaths []string `yaml:"key_search_paths" mapstructure:"key_search_paths"`
+	KeySearchPaths  []string              `yaml:"key_search_paths" mapstructure:"key_search_paths"`
+	AppStoreConnect AppStoreConnectConfig `yaml:"app_store_connect,omitempty" mapstructure:"app_store_connect"`
 }
 
 // LocalDeviceConfig holds local device overrides.
@@ -40,6 +44,22 @@ type PreferencesConfig struct {
 	Verbose          bool   `yaml:"verbose" mapstructure:"verbose"`
 	Editor           string `yaml:"editor" mapstructure:"editor"`
 	AutoOpenWorktree bool   `yaml:"auto_open_worktree" mapstructure:"auto_open_worktree"`
+	MetricsHistory   bool   `yaml:"metrics_history" mapstructure:"metrics_history"`
+	// EnvRefresh is what 'drift env check' does when the generated env
+	// file no longer matches the branch: warn (default) or regenerate.
+	EnvRefresh string `yaml:"env_refresh,omitempty" mapstructure:"env_refresh"`
+	// Opener names how worktrees are opened: a builtin (vscode, cursor,
+	// iterm, wezterm, tmux, ghostty, terminal, finder) or a key of Openers.
+	Opener  string                  `yaml:"opener,omitempty" mapstructure:"opener"`
+	Openers map[string]OpenerConfig `yaml:"openers,omitempty" mapstructure:"openers"`
+}
+
+// OpenerConfig defines or overrides a worktree opener. Args and WaitArgs may
+// use {path} and {name}; WaitArgs must block until the editor is closed.
+type OpenerConfig struct {
+	Command  string   `yaml:"command" mapstructure:"command"`
+	Args     []string `yaml:"args,omitempty" mapstructure:"args"`
+	WaitArgs []string `yaml:"wait_args,omitempty" mapstructure:"wait_args"`
 }
 
 // LocalConfigFilename is the name of the local config file.
@@ -111,11 +131,17 @@ func MergeLocalConfig(main *Config, local *LocalConfig) *Config {
 	if local.Supabase.FallbackBranch != "" {
 		main.Supabase.FallbackBranch = local.Supabase.FallbackBranch
 	}
+	if len(local.Supabase.DBPasswords) > 0 {
+		main.Supabase.DBPasswords = local.Supabase.DBPasswords
+	}
 
 	// Override Apple settings
 	if len(local.Apple.KeySearchPaths) > 0 {
 		main.Apple.KeySearchPaths = local.Apple.KeySearchPaths
 	}
+	if local.Apple.AppStoreConnect.IsSet() {
+		main.Apple.AppStoreConnect = local.Apple.AppStoreConnect
+	}
 
 	// Override Device settings
 	if local.Device.DefaultDevice != "" {
@@ -187,11 +213,30 @@ func LoadWithLocal() (*Config, error) {
 	local, err := LoadLocal(mainConfigPath)
 	if err != nil {
 		// Non-fatal: warn but continue with main config only
-		return cfg, nil
+		local = nil
+	}
+
+	var decryptErr error
+	if local != nil {
+		decryptErr = DecryptLocalSecrets(local, cfg.Encryption.IdentityPath())
 	}
 
 	// Merge local into main
-	return MergeLocalConfig(cfg, local), nil
+	merged := MergeLocalConfig(cfg, local)
+	merged.localDecryptErr = decryptErr
+
+	// Apply the selected Supabase project last: local branch overrides are
+	// meant for the default project only.
+	if err := merged.UseSupabaseProject(SelectedSupabaseProject()); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// LocalDecryptError returns the error from decrypting .drift.local.yaml
+// secrets during load, or nil. Undecryptable secrets are left out of the config.
+func (c *Config) LocalDecryptError() error {
+	return c.localDecryptErr
 }
 
 // LoadOrDefaultWithLocal tries to load config with local overrides, returns defaults if not found.
@@ -220,6 +265,10 @@ func GenerateLocalConfigContent() string {
 #     - "secrets"        # Relative to project root
 #     - "../shared-keys" # Relative path outside project
 #     - "/abs/path/to/keys" # Absolute path
+#   app_store_connect:   # API key for TestFlight build numbers and 'drift appstore'
+#     key_id: "ABC123DEFG"
+#     issuer_id: "69a6de7e-0000-0000-0000-000000000000"
+#     key_path: "secrets/AuthKey_ABC123DEFG.p8"
 
 # Local secret values (keep sensitive values out of .drift.yaml)
 # environments:
@@ -240,13 +289,20 @@ preferences:
   verbose: false                 # Show verbose output for all commands
   # editor: "cursor"             # Default editor for open commands
   # auto_open_worktree: true     # Open worktree in editor after create
+  # metrics_history: true        # Keep command timings for 'drift metrics history'
+  # opener: "cursor"            # How 'drift worktree open' opens worktrees
+  # openers:                     # Custom openers; args may use {path} and {name}
+  #   zed:
+  #     command: "zed"
+  #     args: ["{path}"]
+  #     wait_args: ["--wait", "{path}"]
 `
 }
 
 // WriteLocalConfig writes a new local config file.
 func WriteLocalConfig(path string) error {
 	content := GenerateLocalConfigContent()
-	return os.WriteFile(path, []byte(content), 0644)
+	return WriteFileAtomic(path, []byte(content), 0644)
 }
 
 // AddToGitignore adds .drift.local.yaml to .gitignore if not already present.
@@ -272,67 +328,42 @@ func AddToGitignore(projectRoot string) error {
 	newContent += "\n# Drift local config (developer-specific)\n"
 	newContent += LocalConfigFilename + "\n"
 
-	return os.WriteFile(gitignorePath, []byte(newContent), 0644)
+	return WriteFileAtomic(gitignorePath, []byte(newContent), 0644)
 }
 
 // UpdateLocalSupabaseOverrides updates Supabase override fields in .drift.local.yaml.
 func UpdateLocalSupabaseOverrides(localPath, overrideBranch, fallbackBranch string) error {
-	cfg := make(map[string]interface{})
-
-	// Read existing content if present.
-	if data, err := os.ReadFile(localPath); err == nil && strings.TrimSpace(string(data)) != "" {
-		if err := yaml.Unmarshal(data, &cfg); err != nil {
-			return err
+	return UpdateYAML(localPath, true, func(cfg map[string]interface{}) error {
+		supabaseSection, ok := cfg["supabase"].(map[string]interface{})
+		if !ok {
+			supabaseSection = make(map[string]interface{})
+			cfg["supabase"] = supabaseSection
 		}
-	}
-
-	supabaseSection, ok := cfg["supabase"].(map[string]interface{})
-	if !ok {
-		supabaseSection = make(map[string]interface{})
-		cfg["supabase"] = supabaseSection
-	}
-
-	if overrideBranch != "" {
-		supabaseSection["override_branch"] = overrideBranch
-	}
-	if fallbackBranch != "" {
-		supabaseSection["fallback_branch"] = fallbackBranch
-	}
 
-	newData, err := yaml.Marshal(cfg)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(localPath, newData, 0644)
+		if overrideBranch != "" {
+			supabaseSection["override_branch"] = overrideBranch
+		}
+		if fallbackBranch != "" {
+			supabaseSection["fallback_branch"] = fallbackBranch
+		}
+		return nil
+	})
 }
 
 // ClearLocalSupabaseOverride removes supabase.override_branch from .drift.local.yaml.
 func ClearLocalSupabaseOverride(localPath string) error {
-	cfg := make(map[string]interface{})
-
-	if data, err := os.ReadFile(localPath); err == nil && strings.TrimSpace(string(data)) != "" {
-		if err := yaml.Unmarshal(data, &cfg); err != nil {
-			return err
+	return UpdateYAML(localPath, true, func(cfg map[string]interface{}) error {
+		supabaseSection, ok := cfg["supabase"].(map[string]interface{})
+		if !ok {
+			return nil
 		}
-	}
 
-	supabaseSection, ok := cfg["supabase"].(map[string]interface{})
-	if !ok {
+		delete(supabaseSection, "override_branch")
+		if len(supabaseSection) == 0 {
+			delete(cfg, "supabase")
+		}
 		return nil
-	}
-
-	delete(supabaseSection, "override_branch")
-	if len(supabaseSection) == 0 {
-		delete(cfg, "supabase")
-	}
-
-	newData, err := yaml.Marshal(cfg)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(localPath, newData, 0644)
+	})
 }
 
 // contains checks if a string contains a substring (line-aware).
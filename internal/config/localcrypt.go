@@ -0,0 +1,181 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// EncryptedValuePrefix marks an age-encrypted value in .drift.local.yaml.
+// The remainder is the base64-encoded ASCII-armored age ciphertext.
+const EncryptedValuePrefix = "drift-enc:"
+
+// IsEncryptedValue reports whether value was written by EncryptValue.
+func IsEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, EncryptedValuePrefix)
+}
+
+// LocalRecipients returns the age recipients used for .drift.local.yaml secrets.
+// encryption.local_recipients wins; encryption.age_recipient is the fallback.
+func (e *EncryptionConfig) LocalRecipients() []string {
+	var recipients []string
+	for _, r := range e.LocalRecipientKeys {
+		if r = strings.TrimSpace(r); r != "" {
+			recipients = append(recipients, r)
+		}
+	}
+	if len(recipients) == 0 && strings.TrimSpace(e.AgeRecipient) != "" {
+		recipients = append(recipients, strings.TrimSpace(e.AgeRecipient))
+	}
+	return recipients
+}
+
+// IdentityPath returns the age identity file, defaulting to ~/.config/age/keys.txt.
+func (e *EncryptionConfig) IdentityPath() string {
+	if env := strings.TrimSpace(os.Getenv("DRIFT_AGE_IDENTITY")); env != "" {
+		return expandHome(env)
+	}
+	if identity := strings.TrimSpace(e.AgeIdentity); identity != "" {
+		return expandHome(identity)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "age", "keys.txt")
+}
+
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// EncryptValue encrypts value to every recipient with the age CLI.
+func EncryptValue(value string, recipients []string) (string, error) {
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("no age recipients configured; set encryption.local_recipients in .drift.yaml")
+	}
+	if !shell.CommandExists("age") {
+		return "", fmt.Errorf("'age' not found (brew install age)")
+	}
+
+	args := []string{"-a"}
+	for _, r := range recipients {
+		args = append(args, "-r", r)
+	}
+	result, err := shell.RunWithInput(value, "age", args...)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("age encryption failed: %s", result.Stderr)
+	}
+	return EncryptedValuePrefix + base64.StdEncoding.EncodeToString([]byte(result.Stdout+"\n")), nil
+}
+
+var (
+	decryptCacheMu sync.Mutex
+	decryptCache   = make(map[string]string)
+)
+
+// DecryptValue decrypts a value written by EncryptValue. Plain values are
+// returned unchanged. Results are cached for the life of the process since
+// config is loaded many times per command.
+func DecryptValue(value, identity string) (string, error) {
+	if !IsEncryptedValue(value) {
+		return value, nil
+	}
+
+	decryptCacheMu.Lock()
+	cached, ok := decryptCache[value]
+	decryptCacheMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	armored, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	if identity == "" {
+		return "", fmt.Errorf("no age identity available")
+	}
+	if !shell.CommandExists("age") {
+		return "", fmt.Errorf("'age' not found (brew install age)")
+	}
+
+	result, err := shell.RunWithInput(string(armored), "age", "-d", "-i", identity)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("age decryption failed: %s", result.Stderr)
+	}
+
+	decryptCacheMu.Lock()
+	decryptCache[value] = result.Stdout
+	decryptCacheMu.Unlock()
+	return result.Stdout, nil
+}
+
+// DecryptLocalSecrets decrypts environments.*.secrets and
+// supabase.db_passwords in place. Values that
+// cannot be decrypted are removed so ciphertext never reaches generated files;
+// the returned error lists the affected keys.
+func DecryptLocalSecrets(local *LocalConfig, identity string) error {
+	if local == nil {
+		return nil
+	}
+
+	var failed []string
+	var firstErr error
+	for envName, env := range local.Environments {
+		for key, value := range env.Secrets {
+			if !IsEncryptedValue(value) {
+				continue
+			}
+			plain, err := DecryptValue(value, identity)
+			if err != nil {
+				delete(env.Secrets, key)
+				failed = append(failed, envName+"."+key)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			env.Secrets[key] = plain
+		}
+	}
+
+	for ref, value := range local.Supabase.DBPasswords {
+		if !IsEncryptedValue(value) {
+			continue
+		}
+		plain, err := DecryptValue(value, identity)
+		if err != nil {
+			delete(local.Supabase.DBPasswords, ref)
+			failed = append(failed, "supabase.db_passwords."+ref)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		local.Supabase.DBPasswords[ref] = plain
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("could not decrypt %s in %s: %w", strings.Join(failed, ", "), LocalConfigFilename, firstErr)
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncryptionConfig_LocalRecipients(t *testing.T) {
+	e := EncryptionConfig{AgeRecipient: "age1fallback"}
+	if got := e.LocalRecipients(); !reflect.DeepEqual(got, []string{"age1fallback"}) {
+		t.Errorf("LocalRecipients() = %v, want fallback recipient", got)
+	}
+
+	e.LocalRecipientKeys = []string{" age1alice ", "", "age1bob"}
+	if got := e.LocalRecipients(); !reflect.DeepEqual(got, []string{"age1alice", "age1bob"}) {
+		t.Errorf("LocalRecipients() = %v, want [age1alice age1bob]", got)
+	}
+}
+
+func TestDecryptLocalSecrets_DropsUndecryptableValues(t *testing.T) {
+	local := &LocalConfig{Environments: map[string]EnvironmentConfig{
+		"development": {Secrets: map[string]string{
+			"PLAIN":  "value",
+			"BROKEN": EncryptedValuePrefix + "not base64!",
+		}},
+	}}
+
+	err := DecryptLocalSecrets(local, "/nonexistent/keys.txt")
+	if err == nil || !strings.Contains(err.Error(), "development.BROKEN") {
+		t.Fatalf("DecryptLocalSecrets() error = %v, want mention of development.BROKEN", err)
+	}
+	secrets := local.Environments["development"].Secrets
+	if _, ok := secrets["BROKEN"]; ok {
+		t.Error("undecryptable value should be removed")
+	}
+	if secrets["PLAIN"] != "value" {
+		t.Errorf("plain value changed: %q", secrets["PLAIN"])
+	}
+}
+
+func TestEncryptDecryptValue_RoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("age-keygen"); err != nil {
+		t.Skip("age not installed")
+	}
+	identity := filepath.Join(t.TempDir(), "keys.txt")
+	if out, err := exec.Command("age-keygen", "-o", identity).CombinedOutput(); err != nil {
+		t.Fatalf("age-keygen: %v: %s", err, out)
+	}
+	data, err := os.ReadFile(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recipient string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "# public key: ") {
+			recipient = strings.TrimPrefix(line, "# public key: ")
+		}
+	}
+
+	encrypted, err := EncryptValue("s3cret", []string{recipient})
+	if err != nil {
+		t.Fatalf("EncryptValue() error = %v", err)
+	}
+	if !IsEncryptedValue(encrypted) || strings.Contains(encrypted, "\n") {
+		t.Fatalf("EncryptValue() = %q, want single-line encrypted value", encrypted)
+	}
+	plain, err := DecryptValue(encrypted, identity)
+	if err != nil {
+		t.Fatalf("DecryptValue() error = %v", err)
+	}
+	if plain != "s3cret" {
+		t.Errorf("DecryptValue() = %q, want s3cret", plain)
+	}
+}
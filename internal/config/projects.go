@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectsRegistryFilename is the registry file inside the drift home directory.
+const ProjectsRegistryFilename = "projects.yaml"
+
+// ProjectsRegistry lists drift projects known on this machine (~/.drift/projects.yaml).
+type ProjectsRegistry struct {
+	Current  string              `yaml:"current,omitempty"`
+	Projects []RegisteredProject `yaml:"projects"`
+
+	path string
+}
+
+// RegisteredProject is one entry in the projects registry.
+type RegisteredProject struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+// DriftHome returns the per-user drift directory ($DRIFT_HOME or ~/.drift).
+func DriftHome() (string, error) {
+	if home := strings.TrimSpace(os.Getenv("DRIFT_HOME")); home != "" {
+		return home, nil
+	}
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(userHome, ".drift"), nil
+}
+
+// LoadProjectsRegistry reads the registry, returning an empty one if it doesn't exist.
+func LoadProjectsRegistry() (*ProjectsRegistry, error) {
+	home, err := DriftHome()
+	if err != nil {
+		return nil, err
+	}
+	return LoadProjectsRegistryFromPath(filepath.Join(home, ProjectsRegistryFilename))
+}
+
+// LoadProjectsRegistryFromPath reads a registry file from a specific path.
+func LoadProjectsRegistryFromPath(path string) (*ProjectsRegistry, error) {
+	reg := &ProjectsRegistry{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return reg, nil
+		}
+		return nil, fmt.Errorf("failed to read projects registry: %w", err)
+	}
+	if strings.TrimSpace(string(data)) != "" {
+		if err := yaml.Unmarshal(data, reg); err != nil {
+			return nil, fmt.Errorf("failed to parse projects registry: %w", err)
+		}
+	}
+	return reg, nil
+}
+
+// Save writes the registry back to disk.
+func (r *ProjectsRegistry) Save() error {
+	if r.path == "" {
+		return fmt.Errorf("projects registry has no path")
+	}
+	sort.Slice(r.Projects, func(i, j int) bool { return r.Projects[i].Name < r.Projects[j].Name })
+
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(r.path), err)
+	}
+	return WriteFileAtomic(r.path, data, 0644)
+}
+
+// Find returns the project with the given name, or nil.
+func (r *ProjectsRegistry) Find(name string) *RegisteredProject {
+	for i := range r.Projects {
+		if strings.EqualFold(r.Projects[i].Name, name) {
+			return &r.Projects[i]
+		}
+	}
+	return nil
+}
+
+// FindByPath returns the project registered at path, or nil.
+func (r *ProjectsRegistry) FindByPath(path string) *RegisteredProject {
+	clean := filepath.Clean(path)
+	for i := range r.Projects {
+		if filepath.Clean(r.Projects[i].Path) == clean {
+			return &r.Projects[i]
+		}
+	}
+	return nil
+}
+
+// Add registers a project, replacing any entry with the same name or path.
+// It returns an error if name is empty or path is not absolute.
+func (r *ProjectsRegistry) Add(name, path string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("project name is required")
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("project path must be absolute: %s", path)
+	}
+	path = filepath.Clean(path)
+
+	kept := r.Projects[:0]
+	for _, p := range r.Projects {
+		if strings.EqualFold(p.Name, name) || filepath.Clean(p.Path) == path {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	r.Projects = append(kept, RegisteredProject{Name: name, Path: path})
+	return nil
+}
+
+// Remove unregisters a project by name and reports whether it was present.
+func (r *ProjectsRegistry) Remove(name string) bool {
+	for i, p := range r.Projects {
+		if strings.EqualFold(p.Name, name) {
+			r.Projects = append(r.Projects[:i], r.Projects[i+1:]...)
+			if strings.EqualFold(r.Current, name) {
+				r.Current = ""
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentProject returns the project selected with 'drift projects switch', or nil.
+func (r *ProjectsRegistry) CurrentProject() *RegisteredProject {
+	if r.Current == "" {
+		return nil
+	}
+	return r.Find(r.Current)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectsRegistry_AddSaveLoad(t *testing.T) {
+	t.Setenv("DRIFT_HOME", t.TempDir())
+
+	reg, err := LoadProjectsRegistry()
+	if err != nil {
+		t.Fatalf("LoadProjectsRegistry() error = %v", err)
+	}
+	if len(reg.Projects) != 0 {
+		t.Fatalf("new registry has projects: %v", reg.Projects)
+	}
+
+	if err := reg.Add("zeta", "/work/zeta"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Add("alpha", "/work/alpha"); err != nil {
+		t.Fatal(err)
+	}
+	// Re-adding the same path under a new name replaces the entry.
+	if err := reg.Add("alpha-app", "/work/alpha/"); err != nil {
+		t.Fatal(err)
+	}
+	reg.Current = "zeta"
+	if err := reg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadProjectsRegistry()
+	if err != nil {
+		t.Fatalf("LoadProjectsRegistry() error = %v", err)
+	}
+	if len(loaded.Projects) != 2 || loaded.Projects[0].Name != "alpha-app" || loaded.Projects[1].Name != "zeta" {
+		t.Fatalf("Projects = %v, want [alpha-app zeta]", loaded.Projects)
+	}
+	if p := loaded.CurrentProject(); p == nil || p.Path != "/work/zeta" {
+		t.Errorf("CurrentProject() = %v, want zeta", p)
+	}
+	if p := loaded.Find("ZETA"); p == nil {
+		t.Error("Find() should be case-insensitive")
+	}
+	if p := loaded.FindByPath(filepath.Clean("/work/alpha")); p == nil || p.Name != "alpha-app" {
+		t.Errorf("FindByPath() = %v, want alpha-app", p)
+	}
+
+	if !loaded.Remove("zeta") {
+		t.Fatal("Remove() = false, want true")
+	}
+	if loaded.Current != "" {
+		t.Errorf("Current = %q after removing it, want empty", loaded.Current)
+	}
+	if loaded.Remove("zeta") {
+		t.Error("second Remove() = true, want false")
+	}
+}
+
+func TestProjectsRegistry_AddValidates(t *testing.T) {
+	reg := &ProjectsRegistry{}
+	if err := reg.Add("", "/work/app"); err == nil {
+		t.Error("Add() with empty name should fail")
+	}
+	if err := reg.Add("app", "relative/path"); err == nil {
+		t.Error("Add() with relative path should fail")
+	}
+}
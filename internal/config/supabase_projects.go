@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SupabaseProject is one entry of supabase.projects, for repos that talk to
+// more than one Supabase project (e.g. app + analytics). Unset fields are
+// inherited from the top-level supabase settings, except the branch mapping:
+// only the first (default) project inherits override_branch and
+// fallback_branch, including those set in .drift.local.yaml.
+type SupabaseProject struct {
+	Name              string   `yaml:"name" mapstructure:"name"`
+	ProjectRef        string   `yaml:"project_ref" mapstructure:"project_ref"`
+	ProjectName       string   `yaml:"project_name,omitempty" mapstructure:"project_name"`
+	FunctionsDir      string   `yaml:"functions_dir,omitempty" mapstructure:"functions_dir"`
+	MigrationsDir     string   `yaml:"migrations_dir,omitempty" mapstructure:"migrations_dir"`
+	ProtectedBranches []string `yaml:"protected_branches,omitempty" mapstructure:"protected_branches"`
+	OverrideBranch    string   `yaml:"override_branch,omitempty" mapstructure:"override_branch"`
+	FallbackBranch    string   `yaml:"fallback_branch,omitempty" mapstructure:"fallback_branch"`
+	EnvPrefix         string   `yaml:"env_prefix,omitempty" mapstructure:"env_prefix"` // prepended to generated variable names, e.g. ANALYTICS_
+	EnvOutput         string   `yaml:"env_output,omitempty" mapstructure:"env_output"` // generated env file; other projects default to the main one with the name inserted
+}
+
+// SupabaseProjectError reports an invalid supabase.projects entry or
+// selection.
+type SupabaseProjectError struct {
+	msg string
+}
+
+func (e *SupabaseProjectError) Error() string { return e.msg }
+
+func supabaseProjectErrorf(format string, args ...interface{}) error {
+	return &SupabaseProjectError{msg: fmt.Sprintf(format, args...)}
+}
+
+var (
+	supabaseProjectMu       sync.Mutex
+	selectedSupabaseProject string
+)
+
+// SelectSupabaseProject chooses the supabase.projects entry that later
+// loads apply (--supabase-project). Empty selects the first project.
+func SelectSupabaseProject(name string) {
+	supabaseProjectMu.Lock()
+	defer supabaseProjectMu.Unlock()
+	selectedSupabaseProject = name
+}
+
+// SelectedSupabaseProject returns the name passed to SelectSupabaseProject.
+func SelectedSupabaseProject() string {
+	supabaseProjectMu.Lock()
+	defer supabaseProjectMu.Unlock()
+	return selectedSupabaseProject
+}
+
+// ProjectNames returns the names of supabase.projects, in config order.
+func (c *SupabaseConfig) ProjectNames() []string {
+	names := make([]string, len(c.Projects))
+	for i, p := range c.Projects {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// validateProjects checks every project has a unique name and a ref.
+func (c *SupabaseConfig) validateProjects() error {
+	seen := make(map[string]bool, len(c.Projects))
+	for i, p := range c.Projects {
+		if p.Name == "" {
+			return supabaseProjectErrorf("supabase.projects[%d] has no name", i)
+		}
+		if seen[p.Name] {
+			return supabaseProjectErrorf("supabase.projects lists '%s' twice", p.Name)
+		}
+		seen[p.Name] = true
+		if p.ProjectRef == "" {
+			return supabaseProjectErrorf("supabase.projects '%s' has no project_ref", p.Name)
+		}
+	}
+	return nil
+}
+
+// UseSupabaseProject applies the named supabase.projects entry, or the first
+// one when name is empty, on top of the top-level supabase settings. It does
+// nothing for single-project configs.
+func (c *Config) UseSupabaseProject(name string) error {
+	s := &c.Supabase
+	if len(s.Projects) == 0 {
+		if name != "" {
+			return supabaseProjectErrorf("no supabase.projects are configured, so '%s' cannot be selected", name)
+		}
+		return nil
+	}
+	if err := s.validateProjects(); err != nil {
+		return err
+	}
+
+	index := 0
+	if name != "" {
+		index = -1
+		for i, p := range s.Projects {
+			if p.Name == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return supabaseProjectErrorf("unknown Supabase project '%s' (configured: %s)", name, strings.Join(s.ProjectNames(), ", "))
+		}
+	}
+	p := s.Projects[index]
+
+	s.ActiveProject = p.Name
+	s.EnvPrefix = p.EnvPrefix
+	s.ProjectRef = p.ProjectRef
+	setIfNotEmpty(&s.ProjectName, p.ProjectName)
+	setIfNotEmpty(&s.FunctionsDir, p.FunctionsDir)
+	setIfNotEmpty(&s.MigrationsDir, p.MigrationsDir)
+	if len(p.ProtectedBranches) > 0 {
+		s.ProtectedBranches = p.ProtectedBranches
+	}
+
+	if index == 0 {
+		// The default project keeps overrides from .drift.local.yaml.
+		if s.OverrideBranch == "" {
+			s.OverrideBranch = p.OverrideBranch
+		}
+		if s.FallbackBranch == "" {
+			s.FallbackBranch = p.FallbackBranch
+		}
+		setIfNotEmpty(&c.Web.EnvOutput, p.EnvOutput)
+		setIfNotEmpty(&c.Xcode.XcconfigOutput, p.EnvOutput)
+		return nil
+	}
+
+	// Other projects have their own branch mapping and env files, so they
+	// never overwrite the default project's.
+	s.OverrideBranch = p.OverrideBranch
+	s.FallbackBranch = p.FallbackBranch
+	if p.EnvOutput != "" {
+		c.Web.EnvOutput = p.EnvOutput
+		c.Xcode.XcconfigOutput = p.EnvOutput
+	} else {
+		c.Web.EnvOutput = projectOutputPath(c.Web.EnvOutput, p.Name)
+		c.Xcode.XcconfigOutput = projectOutputPath(c.Xcode.XcconfigOutput, p.Name)
+	}
+	c.Web.ServerEnvOutput = projectOutputPath(c.Web.ServerEnvOutput, p.Name)
+	return nil
+}
+
+// projectOutputPath inserts a project name before a generated file's
+// extension: .env.local -> .env.analytics.local, Config.xcconfig ->
+// Config.analytics.xcconfig, .env -> .env.analytics.
+func projectOutputPath(path, name string) string {
+	if path == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	if base == "" || strings.HasSuffix(base, "/") {
+		return path + "." + name
+	}
+	return base + "." + name + ext
+}
+
+func setIfNotEmpty(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
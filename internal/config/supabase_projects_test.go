@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func multiProjectConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.Supabase.OverrideBranch = "local-override"
+	cfg.Supabase.Projects = []SupabaseProject{
+		{Name: "app", ProjectRef: "appref"},
+		{Name: "analytics", ProjectRef: "anaref", FunctionsDir: "analytics/functions", EnvPrefix: "ANALYTICS_", FallbackBranch: "dev"},
+	}
+	return cfg
+}
+
+func TestUseSupabaseProject_Default(t *testing.T) {
+	cfg := multiProjectConfig()
+	functionsDir, envOutput := cfg.Supabase.FunctionsDir, cfg.Web.EnvOutput
+
+	if err := cfg.UseSupabaseProject(""); err != nil {
+		t.Fatalf("UseSupabaseProject() error = %v", err)
+	}
+	s := cfg.Supabase
+	if s.ActiveProject != "app" || s.ProjectRef != "appref" || s.EnvPrefix != "" {
+		t.Errorf("selected %q (%s, prefix %q), want app", s.ActiveProject, s.ProjectRef, s.EnvPrefix)
+	}
+	if s.OverrideBranch != "local-override" {
+		t.Errorf("OverrideBranch = %q, want the inherited local override", s.OverrideBranch)
+	}
+	if s.FunctionsDir != functionsDir || cfg.Web.EnvOutput != envOutput {
+		t.Errorf("FunctionsDir = %q, EnvOutput = %q, want inherited %q, %q", s.FunctionsDir, cfg.Web.EnvOutput, functionsDir, envOutput)
+	}
+}
+
+func TestUseSupabaseProject_Named(t *testing.T) {
+	cfg := multiProjectConfig()
+	if err := cfg.UseSupabaseProject("analytics"); err != nil {
+		t.Fatalf("UseSupabaseProject() error = %v", err)
+	}
+	s := cfg.Supabase
+	if s.ProjectRef != "anaref" || s.EnvPrefix != "ANALYTICS_" || s.FunctionsDir != "analytics/functions" {
+		t.Errorf("Supabase = ref %s, prefix %q, functions %q", s.ProjectRef, s.EnvPrefix, s.FunctionsDir)
+	}
+	if s.OverrideBranch != "" || s.FallbackBranch != "dev" {
+		t.Errorf("branch mapping = override %q, fallback %q, want its own", s.OverrideBranch, s.FallbackBranch)
+	}
+	if cfg.Web.EnvOutput != ".env.analytics.local" || cfg.Xcode.XcconfigOutput != "Config.analytics.xcconfig" {
+		t.Errorf("env outputs = %q, %q", cfg.Web.EnvOutput, cfg.Xcode.XcconfigOutput)
+	}
+}
+
+func TestUseSupabaseProject_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		projects  []SupabaseProject
+		selection string
+	}{
+		{"unknown", []SupabaseProject{{Name: "app", ProjectRef: "a"}}, "billing"},
+		{"not configured", nil, "app"},
+		{"duplicate", []SupabaseProject{{Name: "app", ProjectRef: "a"}, {Name: "app", ProjectRef: "b"}}, ""},
+		{"missing ref", []SupabaseProject{{Name: "app"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Supabase.Projects = tt.projects
+			err := cfg.UseSupabaseProject(tt.selection)
+			var projectErr *SupabaseProjectError
+			if !errors.As(err, &projectErr) {
+				t.Errorf("UseSupabaseProject(%q) error = %v, want a SupabaseProjectError", tt.selection, err)
+			}
+		})
+	}
+
+	if err := DefaultConfig().UseSupabaseProject(""); err != nil {
+		t.Errorf("single-project UseSupabaseProject() error = %v", err)
+	}
+}
+
+func TestProjectOutputPath(t *testing.T) {
+	tests := map[string]string{
+		".env.local":        ".env.analytics.local",
+		".env":              ".env.analytics",
+		"Config.xcconfig":   "Config.analytics.xcconfig",
+		"dart_defines.json": "dart_defines.analytics.json",
+		"app/.env":          "app/.env.analytics",
+	}
+	got := make(map[string]string, len(tests))
+	for in := range tests {
+		got[in] = projectOutputPath(in, "analytics")
+	}
+	if !reflect.DeepEqual(got, tests) {
+		t.Errorf("projectOutputPath() = %v, want %v", got, tests)
+	}
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scope is the config file a setting is written to.
+type Scope int
+
+const (
+	// ScopeShared is .drift.yaml, committed and shared by the team.
+	ScopeShared Scope = iota
+	// ScopeLocal is .drift.local.yaml, gitignored and per developer.
+	ScopeLocal
+)
+
+// localKeys are settings that only make sense per developer and therefore
+// belong in .drift.local.yaml. A trailing ".*" matches any child key.
+var localKeys = []string{
+	"supabase.override_branch",
+	"supabase.fallback_branch",
+	"supabase.db_passwords.*",
+	"apple.key_search_paths",
+	"device.default_device",
+	"preferences.*",
+}
+
+// ScopeFor returns where a dotted config key such as
+// "supabase.override_branch" should be written.
+func ScopeFor(key string) Scope {
+	for _, local := range localKeys {
+		if key == local {
+			return ScopeLocal
+		}
+		if prefix, ok := strings.CutSuffix(local, ".*"); ok && (key == prefix || strings.HasPrefix(key, prefix+".")) {
+			return ScopeLocal
+		}
+	}
+	return ScopeShared
+}
+
+// ScopePath returns the config file for scope in projectRoot.
+func ScopePath(projectRoot string, scope Scope) string {
+	if scope == ScopeLocal {
+		return filepath.Join(projectRoot, LocalConfigFilename)
+	}
+	for _, name := range []string{".drift.yaml", ".drift.yml"} {
+		path := filepath.Join(projectRoot, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(projectRoot, ".drift.yaml")
+}
+
+// UpdateScope applies fn to the config file for scope in projectRoot; see
+// UpdateYAML. A missing .drift.local.yaml is created from the template first.
+func UpdateScope(projectRoot string, scope Scope, fn func(doc map[string]interface{}) error) error {
+	path := ScopePath(projectRoot, scope)
+	if scope == ScopeLocal {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := WriteLocalConfig(path); err != nil {
+				return fmt.Errorf("failed to create %s: %w", LocalConfigFilename, err)
+			}
+		}
+	}
+	return UpdateYAML(path, scope == ScopeLocal, fn)
+}
+
+// UpdateYAML applies fn to the YAML file at path while holding an exclusive
+// lock, then writes it back atomically. fn works on a generic map; keys it
+// leaves unchanged keep their comments and order, and new keys are appended.
+// A missing file is treated as empty when allowMissing is set. Nothing is
+// written if fn leaves the document unchanged.
+func UpdateYAML(path string, allowMissing bool, fn func(doc map[string]interface{}) error) error {
+	unlock, err := lockConfig(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !(allowMissing && os.IsNotExist(err)) {
+		return err
+	}
+
+	var root yaml.Node
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+		}
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a YAML mapping", filepath.Base(path))
+	}
+
+	doc := make(map[string]interface{})
+	original := make(map[string]interface{})
+	for _, m := range []map[string]interface{}{doc, original} {
+		if err := root.Content[0].Decode(&m); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+		}
+	}
+	if err := fn(doc); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(doc, original) {
+		return nil
+	}
+	if err := mergeNode(root.Content[0], doc); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// WriteFileAtomic replaces path with data while holding the config lock, so
+// readers never see a partially written file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	unlock, err := lockConfig(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeFileAtomic(path, data, perm)
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// configLockTimeout is how long a write waits for another drift process.
+const configLockTimeout = 10 * time.Second
+
+// lockConfig takes an exclusive advisory lock for path. The lock file lives
+// in the temp directory, keyed by the absolute path, so project trees stay
+// clean. The lock is released by the returned function or when the process
+// exits.
+func lockConfig(path string) (func(), error) {
+	lockPath, err := configLockPath(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock for %s: %w", filepath.Base(path), err)
+	}
+
+	deadline := time.Now().Add(configLockTimeout)
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if err != syscall.EWOULDBLOCK || time.Now().After(deadline) {
+			f.Close()
+			if err == syscall.EWOULDBLOCK {
+				return nil, fmt.Errorf("%s is locked by another drift process", filepath.Base(path))
+			}
+			return nil, fmt.Errorf("failed to lock %s: %w", filepath.Base(path), err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+func configLockPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(os.TempDir(), "drift-locks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".lock"), nil
+}
+
+// mergeNode updates node in place so it encodes to value. Mappings are
+// merged key by key so untouched entries keep their comments and position;
+// anything else is replaced only if its decoded value changed.
+func mergeNode(node *yaml.Node, value interface{}) error {
+	if m, ok := value.(map[string]interface{}); ok && node.Kind == yaml.MappingNode {
+		seen := make(map[string]bool, len(m))
+		content := make([]*yaml.Node, 0, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			v, ok := m[key.Value]
+			if !ok {
+				continue
+			}
+			seen[key.Value] = true
+			if err := mergeNode(val, v); err != nil {
+				return err
+			}
+			content = append(content, key, val)
+		}
+
+		added := make([]string, 0, len(m))
+		for k := range m {
+			if !seen[k] {
+				added = append(added, k)
+			}
+		}
+		sort.Strings(added)
+		for _, k := range added {
+			val := &yaml.Node{}
+			if err := val.Encode(m[k]); err != nil {
+				return err
+			}
+			content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, val)
+		}
+		node.Content = content
+		return nil
+	}
+
+	var current interface{}
+	if err := node.Decode(&current); err == nil && reflect.DeepEqual(current, value) {
+		return nil
+	}
+	replacement := &yaml.Node{}
+	if err := replacement.Encode(value); err != nil {
+		return err
+	}
+	replacement.HeadComment = node.HeadComment
+	replacement.LineComment = node.LineComment
+	replacement.FootComment = node.FootComment
+	*node = *replacement
+	return nil
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUpdateYAML_PreservesComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".drift.yaml")
+	original := `# Drift configuration
+project:
+  name: app # display name
+  type: ios
+
+# Supabase settings
+supabase:
+  project_ref: abc123
+  protected_branches:
+    - main
+`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := UpdateYAML(path, false, func(doc map[string]interface{}) error {
+		supabase := doc["supabase"].(map[string]interface{})
+		supabase["project_ref"] = "xyz789"
+		doc["device"] = map[string]interface{}{"default_device": "iPhone"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateYAML() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		"# Drift configuration",
+		"name: app # display name",
+		"# Supabase settings",
+		"project_ref: xyz789",
+		"    - main",
+		"device:\n  default_device: iPhone",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "project:") > strings.Index(got, "supabase:") {
+		t.Errorf("key order changed:\n%s", got)
+	}
+}
+
+func TestUpdateYAML_UnchangedSkipsWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LocalConfigFilename)
+
+	err := UpdateYAML(path, true, func(doc map[string]interface{}) error { return nil })
+	if err != nil {
+		t.Fatalf("UpdateYAML() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("unchanged update created %s", path)
+	}
+
+	if err := UpdateYAML(path, false, func(doc map[string]interface{}) error { return nil }); err == nil {
+		t.Fatal("expected error for missing file without allowMissing")
+	}
+}
+
+func TestUpdateYAML_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".drift.yaml")
+	if err := os.WriteFile(path, []byte("counters: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := UpdateYAML(path, false, func(doc map[string]interface{}) error {
+				counters := doc["counters"].(map[string]interface{})
+				counters[fmt.Sprintf("w%02d", i)] = i
+				return nil
+			})
+			if err != nil {
+				t.Errorf("UpdateYAML() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < writers; i++ {
+		if !strings.Contains(string(data), fmt.Sprintf("w%02d: %d", i, i)) {
+			t.Errorf("lost update from writer %d:\n%s", i, data)
+		}
+	}
+}
+
+func TestScopeFor(t *testing.T) {
+	tests := []struct {
+		key  string
+		want Scope
+	}{
+		{"supabase.override_branch", ScopeLocal},
+		{"supabase.fallback_branch", ScopeLocal},
+		{"preferences.editor", ScopeLocal},
+		{"preferences", ScopeLocal},
+		{"device.default_device", ScopeLocal},
+		{"supabase.project_ref", ScopeShared},
+		{"device.devices", ScopeShared},
+		{"preferencesx", ScopeShared},
+	}
+	for _, tt := range tests {
+		if got := ScopeFor(tt.key); got != tt.want {
+			t.Errorf("ScopeFor(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
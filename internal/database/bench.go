@@ -0,0 +1,214 @@
+package database
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// sslRequestCode is the Postgres protocol code that asks the server to upgrade to TLS.
+const sslRequestCode = 80877103
+
+// ConnectTiming captures the phases of establishing a TLS connection to Postgres.
+type ConnectTiming struct {
+	TCP time.Duration // TCP dial
+	TLS time.Duration // SSLRequest negotiation + TLS handshake
+}
+
+// Total returns the full connection establishment time.
+func (t ConnectTiming) Total() time.Duration {
+	return t.TCP + t.TLS
+}
+
+// MeasureConnect dials host:port, negotiates SSL the way libpq does, and times each phase.
+// No authentication is performed, so no credentials are needed.
+func MeasureConnect(host string, port int, timeout time.Duration) (ConnectTiming, error) {
+	var timing ConnectTiming
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return timing, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+	timing.TCP = time.Since(start)
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return timing, err
+	}
+
+	start = time.Now()
+	req := make([]byte, 8)
+	binary.BigEndian.PutUint32(req[0:4], 8)
+	binary.BigEndian.PutUint32(req[4:8], sslRequestCode)
+	if _, err := conn.Write(req); err != nil {
+		return timing, fmt.Errorf("failed to send SSL request: %w", err)
+	}
+
+	reply := make([]byte, 1)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return timing, fmt.Errorf("failed to read SSL response: %w", err)
+	}
+	if reply[0] != 'S' {
+		return timing, fmt.Errorf("server at %s does not accept TLS", addr)
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+	if err := tlsConn.Handshake(); err != nil {
+		return timing, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	timing.TLS = time.Since(start)
+
+	return timing, nil
+}
+
+// MeasureQueryLatency runs `SELECT 1` iterations times over a single psql session
+// and returns the server round-trip time reported by psql's \timing.
+func MeasureQueryLatency(opts RestoreOptions, iterations int) ([]time.Duration, error) {
+	psql, err := findPGTool("psql")
+	if err != nil {
+		return nil, err
+	}
+	if iterations <= 0 {
+		iterations = 1
+	}
+
+	env := map[string]string{
+		"PGPASSWORD": opts.Password,
+		"PGSSLMODE":  "require",
+	}
+
+	args := []string{
+		"-X", "-q", "-A", "-t",
+		"-h", opts.Host,
+		"-p", fmt.Sprintf("%d", opts.Port),
+		"-U", opts.User,
+		"-d", opts.Database,
+		"-c", `\timing on`,
+	}
+	for i := 0; i < iterations; i++ {
+		args = append(args, "-c", "SELECT 1;")
+	}
+
+	result, err := shell.RunWithEnv(env, psql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query benchmark failed: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("query benchmark failed: %s", result.Stderr)
+	}
+
+	samples := parsePsqlTimings(result.Stdout)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("psql did not report any query timings")
+	}
+	return samples, nil
+}
+
+// Ping opens a psql session with opts, runs SELECT 1, and returns how long
+// it took. timeout bounds the connection attempt so an unreachable pooler
+// host fails fast.
+func Ping(opts RestoreOptions, timeout time.Duration) (time.Duration, error) {
+	psql, err := findPGTool("psql")
+	if err != nil {
+		return 0, err
+	}
+
+	env := map[string]string{
+		"PGPASSWORD":        opts.Password,
+		"PGSSLMODE":         "require",
+		"PGCONNECT_TIMEOUT": strconv.Itoa(max(1, int(timeout.Seconds()))),
+	}
+	args := []string{
+		"-X", "-q", "-A", "-t",
+		"-h", opts.Host,
+		"-p", fmt.Sprintf("%d", opts.Port),
+		"-U", opts.User,
+		"-d", opts.Database,
+		"-c", "SELECT 1;",
+	}
+
+	start := time.Now()
+	result, err := shell.RunWithEnv(env, psql, args...)
+	elapsed := time.Since(start)
+	if err != nil {
+		return elapsed, err
+	}
+	if result.ExitCode != 0 {
+		return elapsed, fmt.Errorf("%s", strings.TrimSpace(result.Stderr))
+	}
+	return elapsed, nil
+}
+
+var psqlTimingPattern = regexp.MustCompile(`Time: ([0-9]+(?:\.[0-9]+)?) ms`)
+
+// parsePsqlTimings extracts durations from psql "Time: 0.512 ms" lines.
+func parsePsqlTimings(output string) []time.Duration {
+	var samples []time.Duration
+	for _, match := range psqlTimingPattern.FindAllStringSubmatch(output, -1) {
+		ms, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, time.Duration(ms*float64(time.Millisecond)))
+	}
+	return samples
+}
+
+// LatencyStats summarizes a set of latency samples.
+type LatencyStats struct {
+	Count int
+	Min   time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+	Mean  time.Duration
+}
+
+// ComputeLatencyStats returns percentile statistics using nearest-rank.
+func ComputeLatencyStats(samples []time.Duration) LatencyStats {
+	if len(samples) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, s := range sorted {
+		sum += s
+	}
+
+	return LatencyStats{
+		Count: len(sorted),
+		Min:   sorted[0],
+		P50:   percentile(sorted, 50),
+		P90:   percentile(sorted, 90),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+		Mean:  sum / time.Duration(len(sorted)),
+	}
+}
+
+// percentile returns the nearest-rank percentile from an ascending slice.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
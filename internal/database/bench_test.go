@@ -0,0 +1,60 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePsqlTimings(t *testing.T) {
+	output := `Timing is on.
+1
+Time: 0.512 ms
+1
+Time: 12 ms
+1
+Time: 1234.567 ms (00:01.235)`
+
+	samples := parsePsqlTimings(output)
+	if len(samples) != 3 {
+		t.Fatalf("parsePsqlTimings returned %d samples, want 3", len(samples))
+	}
+	if samples[0] != 512*time.Microsecond {
+		t.Errorf("samples[0] = %v, want 512µs", samples[0])
+	}
+	if samples[1] != 12*time.Millisecond {
+		t.Errorf("samples[1] = %v, want 12ms", samples[1])
+	}
+}
+
+func TestComputeLatencyStats(t *testing.T) {
+	var samples []time.Duration
+	for i := 10; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := ComputeLatencyStats(samples)
+	if stats.Count != 10 {
+		t.Errorf("Count = %d, want 10", stats.Count)
+	}
+	if stats.Min != time.Millisecond || stats.Max != 10*time.Millisecond {
+		t.Errorf("Min/Max = %v/%v, want 1ms/10ms", stats.Min, stats.Max)
+	}
+	if stats.P50 != 5*time.Millisecond {
+		t.Errorf("P50 = %v, want 5ms", stats.P50)
+	}
+	if stats.P90 != 9*time.Millisecond {
+		t.Errorf("P90 = %v, want 9ms", stats.P90)
+	}
+	if stats.P99 != 10*time.Millisecond {
+		t.Errorf("P99 = %v, want 10ms", stats.P99)
+	}
+	if stats.Mean != 5500*time.Microsecond {
+		t.Errorf("Mean = %v, want 5.5ms", stats.Mean)
+	}
+}
+
+func TestComputeLatencyStats_Empty(t *testing.T) {
+	if stats := ComputeLatencyStats(nil); stats.Count != 0 {
+		t.Errorf("expected zero stats for empty input, got %+v", stats)
+	}
+}
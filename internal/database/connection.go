@@ -0,0 +1,215 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PoolerMode is a Supabase connection pooler mode.
+type PoolerMode string
+
+const (
+	// SessionMode keeps one server connection per client for the whole
+	// session, so prepared statements, temp tables, and SET persist.
+	SessionMode PoolerMode = "session"
+	// TransactionMode hands out a server connection per transaction. It
+	// scales further but breaks anything that relies on session state.
+	TransactionMode PoolerMode = "transaction"
+)
+
+// Default pooler ports: the pooler serves session mode on the Postgres port
+// and transaction mode on its own.
+const (
+	DefaultSessionPort     = 5432
+	DefaultTransactionPort = 6543
+)
+
+// Operation is what a connection is used for. It decides the pooler mode.
+type Operation string
+
+const (
+	// OpQuery runs self-contained statements with psql.
+	OpQuery Operation = "query"
+	// OpRestore applies a backup with psql or pg_restore.
+	OpRestore Operation = "restore"
+	// OpDump reads a consistent snapshot with pg_dump.
+	OpDump Operation = "dump"
+	// OpScript runs a psql script that keeps temp tables between statements.
+	OpScript Operation = "script"
+	// OpMigrate hands the connection to the Supabase CLI, which uses
+	// prepared statements.
+	OpMigrate Operation = "migrate"
+)
+
+// Mode returns the pooler mode op needs. Only self-contained statements and
+// restores, which run each transaction on its own, can use transaction mode.
+func (op Operation) Mode() PoolerMode {
+	switch op {
+	case OpQuery, OpRestore:
+		return TransactionMode
+	default:
+		return SessionMode
+	}
+}
+
+// Connection is how to reach a database.
+type Connection struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+	// Params are the URL query parameters, such as sslmode.
+	Params url.Values
+}
+
+// ParseConnectionURL parses a postgres:// or postgresql:// URL. The port
+// defaults to DefaultSessionPort and the database to postgres.
+func ParseConnectionURL(raw string) (Connection, error) {
+	conn := Connection{Port: DefaultSessionPort, User: "postgres", Database: "postgres"}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return conn, fmt.Errorf("invalid db url: %w", err)
+	}
+	if parsed.Hostname() == "" {
+		return conn, fmt.Errorf("invalid db url: missing host")
+	}
+	conn.Host = parsed.Hostname()
+	if db := strings.TrimPrefix(parsed.Path, "/"); db != "" {
+		conn.Database = db
+	}
+	if parsed.Port() != "" {
+		port, err := strconv.Atoi(parsed.Port())
+		if err != nil {
+			return conn, fmt.Errorf("invalid db url port %q: %w", parsed.Port(), err)
+		}
+		conn.Port = port
+	}
+	if parsed.User != nil {
+		if username := parsed.User.Username(); username != "" {
+			conn.User = username
+		}
+		if password, ok := parsed.User.Password(); ok {
+			conn.Password = password
+		}
+	}
+	if parsed.RawQuery != "" {
+		conn.Params = parsed.Query()
+	}
+	return conn, nil
+}
+
+// URL returns c as a postgresql:// URL, escaping the user and password.
+func (c Connection) URL() string {
+	u := url.URL{
+		Scheme: "postgresql",
+		User:   url.UserPassword(c.User, c.Password),
+		Host:   fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Path:   "/" + c.Database,
+	}
+	if c.Password == "" {
+		u.User = url.User(c.User)
+	}
+	if len(c.Params) > 0 {
+		u.RawQuery = c.Params.Encode()
+	}
+	return u.String()
+}
+
+// Address returns host:port.
+func (c Connection) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// RestoreOptions returns DefaultRestoreOptions connecting through c.
+func (c Connection) RestoreOptions() RestoreOptions {
+	opts := DefaultRestoreOptions()
+	opts.Host, opts.Port, opts.User, opts.Password, opts.Database = c.Host, c.Port, c.User, c.Password, c.Database
+	return opts
+}
+
+// DumpOptions returns DefaultDumpOptions connecting through c.
+func (c Connection) DumpOptions() DumpOptions {
+	opts := DefaultDumpOptions()
+	opts.Host, opts.Port, opts.User, opts.Password, opts.Database = c.Host, c.Port, c.User, c.Password, c.Database
+	return opts
+}
+
+// ConnectionBuilder points connections at the pooler port each operation
+// needs. Use NewConnectionBuilder.
+type ConnectionBuilder struct {
+	SessionPort     int
+	TransactionPort int
+	// ProbeTimeout bounds the connection attempt of Probe.
+	ProbeTimeout time.Duration
+}
+
+// NewConnectionBuilder returns a builder for a pooler serving session mode on
+// sessionPort and transaction mode on transactionPort. Zero ports use the
+// defaults.
+func NewConnectionBuilder(sessionPort, transactionPort int) ConnectionBuilder {
+	if sessionPort == 0 {
+		sessionPort = DefaultSessionPort
+	}
+	if transactionPort == 0 {
+		transactionPort = DefaultTransactionPort
+	}
+	return ConnectionBuilder{SessionPort: sessionPort, TransactionPort: transactionPort, ProbeTimeout: 10 * time.Second}
+}
+
+// Port returns the pooler port serving mode.
+func (b ConnectionBuilder) Port(mode PoolerMode) int {
+	if mode == TransactionMode {
+		return b.TransactionPort
+	}
+	return b.SessionPort
+}
+
+// WithMode returns c pointed at the pooler port for mode. Only a pooler port
+// (or no port) is changed: any other port is a direct or local database,
+// which supports every operation as is.
+func (b ConnectionBuilder) WithMode(c Connection, mode PoolerMode) Connection {
+	if c.Port == 0 || b.isPoolerPort(c.Port) {
+		c.Port = b.Port(mode)
+	}
+	return c
+}
+
+// For returns c pointed at the pooler port op needs.
+func (b ConnectionBuilder) For(c Connection, op Operation) Connection {
+	return b.WithMode(c, op.Mode())
+}
+
+// ModeOf reports which pooler mode c connects in, and false if c is not on a
+// pooler port.
+func (b ConnectionBuilder) ModeOf(c Connection) (PoolerMode, bool) {
+	switch c.Port {
+	case b.TransactionPort, DefaultTransactionPort:
+		return TransactionMode, true
+	case b.SessionPort, DefaultSessionPort:
+		return SessionMode, true
+	}
+	return "", false
+}
+
+func (b ConnectionBuilder) isPoolerPort(port int) bool {
+	_, ok := b.ModeOf(Connection{Port: port})
+	return ok
+}
+
+// Probe runs a query through c to check that it accepts connections before
+// an operation starts, naming the mode and address that failed.
+func (b ConnectionBuilder) Probe(c Connection) error {
+	if _, err := Ping(c.RestoreOptions(), b.ProbeTimeout); err != nil {
+		where := c.Address()
+		if mode, ok := b.ModeOf(c); ok {
+			where = fmt.Sprintf("%s (%s mode)", where, mode)
+		}
+		return fmt.Errorf("could not connect to %s: %w", where, err)
+	}
+	return nil
+}
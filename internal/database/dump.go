@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,6 +27,10 @@ type DumpOptions struct {
 	NoPrivileges bool
 	CleanFirst   bool
 	IfExists     bool
+
+	// ExcludeTableData lists tables dumped schema-only: their definitions
+	// are kept but no rows are written.
+	ExcludeTableData []string
 }
 
 // DefaultDumpOptions returns default dump options.
@@ -109,6 +114,8 @@ func Dump(opts DumpOptions) error {
 		args = append(args, "--if-exists")
 	}
 
+	args = append(args, excludeTableDataArgs(opts.ExcludeTableData)...)
+
 	// Set password via environment
 	env := map[string]string{
 		"PGPASSWORD": opts.Password,
@@ -123,24 +130,7 @@ func Dump(opts DumpOptions) error {
 		// Clean up any partial file
 		os.Remove(opts.OutputFile)
 
-		// Check for specific error types
-		stderrLower := strings.ToLower(errMsg)
-		if strings.Contains(stderrLower, "password authentication failed") ||
-			strings.Contains(stderrLower, "authentication failed") {
-			return fmt.Errorf("authentication failed - incorrect password\n\nCheck your database password and try again")
-		}
-		if strings.Contains(stderrLower, "could not connect") ||
-			strings.Contains(stderrLower, "connection refused") {
-			return fmt.Errorf("could not connect to database\n\nCheck:\n  1. Host is correct: %s:%d\n  2. Your network can reach the pooler\n  3. Supabase project is active", opts.Host, opts.Port)
-		}
-		if strings.Contains(stderrLower, "timeout") {
-			return fmt.Errorf("connection timed out\n\nThe database server at %s:%d is not responding", opts.Host, opts.Port)
-		}
-		if strings.Contains(stderrLower, "version mismatch") || strings.Contains(stderrLower, "aborting") {
-			return fmt.Errorf("pg_dump version mismatch: %s\n\nYour pg_dump must be >= the server version.\nInstall the latest: brew install postgresql", errMsg)
-		}
-
-		return fmt.Errorf("pg_dump failed: %s", errMsg)
+		return pgDumpError(errMsg, opts)
 	}
 
 	// Check for warnings in stderr even on success (e.g., version mismatch)
@@ -171,6 +161,44 @@ func Dump(opts DumpOptions) error {
 	return nil
 }
 
+// excludeTableDataArgs returns a pg_dump --exclude-table-data flag for each
+// table, qualified with public when no schema is given.
+func excludeTableDataArgs(tables []string) []string {
+	set := excludedTableSet(tables)
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := make([]string, 0, len(names))
+	for _, name := range names {
+		args = append(args, "--exclude-table-data="+name)
+	}
+	return args
+}
+
+// pgDumpError turns pg_dump's stderr into an actionable error.
+func pgDumpError(errMsg string, opts DumpOptions) error {
+	stderrLower := strings.ToLower(errMsg)
+	if strings.Contains(stderrLower, "password authentication failed") ||
+		strings.Contains(stderrLower, "authentication failed") {
+		return fmt.Errorf("authentication failed - incorrect password\n\nCheck your database password and try again")
+	}
+	if strings.Contains(stderrLower, "could not connect") ||
+		strings.Contains(stderrLower, "connection refused") {
+		return fmt.Errorf("could not connect to database\n\nCheck:\n  1. Host is correct: %s:%d\n  2. Your network can reach the pooler\n  3. Supabase project is active", opts.Host, opts.Port)
+	}
+	if strings.Contains(stderrLower, "timeout") {
+		return fmt.Errorf("connection timed out\n\nThe database server at %s:%d is not responding", opts.Host, opts.Port)
+	}
+	if strings.Contains(stderrLower, "version mismatch") || strings.Contains(stderrLower, "aborting") {
+		return fmt.Errorf("pg_dump version mismatch: %s\n\nYour pg_dump must be >= the server version.\nInstall the latest: brew install postgresql", errMsg)
+	}
+
+	return fmt.Errorf("pg_dump failed: %s", errMsg)
+}
+
 // DumpToFile dumps a database to a file with automatic naming.
 func DumpToFile(opts DumpOptions, prefix string) (string, error) {
 	if opts.OutputFile == "" {
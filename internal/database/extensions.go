@@ -0,0 +1,130 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Extension is an installed Postgres extension.
+type Extension struct {
+	Name    string
+	Version string
+	Schema  string
+}
+
+// ExtensionDiff describes one extension that differs between two databases.
+// An empty version means the extension is not installed on that side.
+type ExtensionDiff struct {
+	Name         string
+	Version      string
+	OtherVersion string
+}
+
+// FetchExtensions lists installed extensions, sorted by name.
+func FetchExtensions(opts RestoreOptions) ([]Extension, error) {
+	rows, err := queryRows(opts, `SELECT e.extname, e.extversion, n.nspname
+FROM pg_extension e
+JOIN pg_namespace n ON n.oid = e.extnamespace
+ORDER BY 1;`)
+	if err != nil {
+		return nil, err
+	}
+
+	exts := make([]Extension, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 3 {
+			continue
+		}
+		exts = append(exts, Extension{Name: row[0], Version: row[1], Schema: row[2]})
+	}
+	return exts, nil
+}
+
+// DiffExtensions returns extensions that are missing on either side or
+// installed at different versions, sorted by name.
+func DiffExtensions(exts, other []Extension) []ExtensionDiff {
+	versions := make(map[string]string, len(exts))
+	for _, e := range exts {
+		versions[e.Name] = e.Version
+	}
+	otherVersions := make(map[string]string, len(other))
+	for _, e := range other {
+		otherVersions[e.Name] = e.Version
+	}
+
+	var diffs []ExtensionDiff
+	for name, v := range versions {
+		if ov := otherVersions[name]; ov != v {
+			diffs = append(diffs, ExtensionDiff{Name: name, Version: v, OtherVersion: ov})
+		}
+	}
+	for name, ov := range otherVersions {
+		if _, ok := versions[name]; !ok {
+			diffs = append(diffs, ExtensionDiff{Name: name, OtherVersion: ov})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
+
+var createExtensionPattern = regexp.MustCompile(`(?i)create\s+extension\s+(?:if\s+not\s+exists\s+)?"?([a-z0-9_-]+)"?`)
+
+// extensionUsagePatterns detect extensions used without a CREATE EXTENSION.
+var extensionUsagePatterns = map[string]*regexp.Regexp{
+	"pg_net":         regexp.MustCompile(`(?i)\bnet\.http_(get|post|delete)\b`),
+	"pg_cron":        regexp.MustCompile(`(?i)\bcron\.(schedule|unschedule)\b`),
+	"uuid-ossp":      regexp.MustCompile(`(?i)\buuid_generate_v[14]\s*\(`),
+	"vector":         regexp.MustCompile(`(?i)\bvector\s*\(\s*\d+\s*\)`),
+	"pg_trgm":        regexp.MustCompile(`(?i)\bgin_trgm_ops\b`),
+	"pgcrypto":       regexp.MustCompile(`(?i)\b(gen_salt|crypt|pgp_sym_encrypt)\s*\(`),
+	"supabase_vault": regexp.MustCompile(`(?i)\bvault\.(secrets|decrypted_secrets|create_secret)\b`),
+}
+
+// ExtensionsUsedByMigrations scans .sql files in dir and returns the
+// extensions each one creates or appears to depend on, keyed by extension
+// name with the first migration file that referenced it.
+func ExtensionsUsedByMigrations(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	used := make(map[string]string)
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		for ext := range ExtensionsReferencedBySQL(string(data)) {
+			if _, ok := used[ext]; !ok {
+				used[ext] = name
+			}
+		}
+	}
+	return used, nil
+}
+
+// ExtensionsReferencedBySQL returns the extensions a SQL script creates or uses.
+func ExtensionsReferencedBySQL(sql string) map[string]bool {
+	refs := make(map[string]bool)
+	for _, m := range createExtensionPattern.FindAllStringSubmatch(sql, -1) {
+		refs[strings.ToLower(m[1])] = true
+	}
+	for ext, pattern := range extensionUsagePatterns {
+		if pattern.MatchString(sql) {
+			refs[ext] = true
+		}
+	}
+	return refs
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffExtensions(t *testing.T) {
+	branch := []Extension{
+		{Name: "plpgsql", Version: "1.0"},
+		{Name: "pgcrypto", Version: "1.3"},
+		{Name: "vector", Version: "0.7.0"},
+	}
+	prod := []Extension{
+		{Name: "plpgsql", Version: "1.0"},
+		{Name: "pg_net", Version: "0.10.0"},
+		{Name: "vector", Version: "0.8.0"},
+	}
+
+	got := DiffExtensions(branch, prod)
+	want := []ExtensionDiff{
+		{Name: "pg_net", OtherVersion: "0.10.0"},
+		{Name: "pgcrypto", Version: "1.3"},
+		{Name: "vector", Version: "0.7.0", OtherVersion: "0.8.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffExtensions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExtensionsReferencedBySQL(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{"create", `CREATE EXTENSION IF NOT EXISTS "uuid-ossp" WITH SCHEMA extensions;`, []string{"uuid-ossp"}},
+		{"pg_net usage", `select net.http_post(url := 'https://example.com');`, []string{"pg_net"}},
+		{"cron usage", `SELECT cron.schedule('nightly', '0 3 * * *', 'vacuum');`, []string{"pg_cron"}},
+		{"vector column", `alter table docs add column embedding vector(1536);`, []string{"vector"}},
+		{"plain", `create table t (id int);`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs := ExtensionsReferencedBySQL(tt.sql)
+			var got []string
+			for ext := range refs {
+				got = append(got, ext)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtensionsReferencedBySQL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtensionsUsedByMigrations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20240101000000_init.sql":   "create extension if not exists pg_net;",
+		"20240201000000_hooks.sql":  "select net.http_get('https://example.com');",
+		"20240301000000_search.sql": "create index on docs using gin (title gin_trgm_ops);",
+		"README.md":                 "create extension ignored;",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ExtensionsUsedByMigrations(dir)
+	if err != nil {
+		t.Fatalf("ExtensionsUsedByMigrations() error = %v", err)
+	}
+	want := map[string]string{
+		"pg_net":  "20240101000000_init.sql",
+		"pg_trgm": "20240301000000_search.sql",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtensionsUsedByMigrations() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FlagTable identifies the table and columns that hold feature flags.
+type FlagTable struct {
+	Table   string // schema-qualified table
+	Key     string // flag name column
+	Enabled string // boolean state column
+}
+
+// Flag is a feature flag and its state on one branch.
+type Flag struct {
+	Name    string
+	Enabled bool
+}
+
+// FlagChange is a flag whose state differs between two branches.
+type FlagChange struct {
+	Name string
+	From bool // state on the target
+	To   bool // state being applied
+}
+
+// FetchFlags returns all flags in the table, sorted by name.
+func FetchFlags(opts RestoreOptions, t FlagTable) ([]Flag, error) {
+	query := fmt.Sprintf("SELECT %s::text, coalesce(%s, false) FROM %s ORDER BY 1;",
+		quoteIdent(t.Key), quoteIdent(t.Enabled), quoteQualified(t.Table))
+
+	rows, err := queryRows(opts, query)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make([]Flag, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 2 {
+			continue
+		}
+		flags = append(flags, Flag{Name: row[0], Enabled: row[1] == "t"})
+	}
+	return flags, nil
+}
+
+// FlagDiff compares source flags with the target. It returns the flags whose
+// target state differs (sorted) and the source flags missing from the target.
+func FlagDiff(source, target []Flag) (changes []FlagChange, missing []string) {
+	have := make(map[string]bool, len(target))
+	for _, f := range target {
+		have[f.Name] = f.Enabled
+	}
+	for _, f := range source {
+		current, ok := have[f.Name]
+		if !ok {
+			missing = append(missing, f.Name)
+			continue
+		}
+		if current != f.Enabled {
+			changes = append(changes, FlagChange{Name: f.Name, From: current, To: f.Enabled})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	sort.Strings(missing)
+	return changes, missing
+}
+
+// SetFlagsScript returns a psql script that applies the given flag states in
+// one transaction.
+func SetFlagsScript(t FlagTable, states map[string]bool) string {
+	var on, off []string
+	for name, enabled := range states {
+		if enabled {
+			on = append(on, name)
+		} else {
+			off = append(off, name)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("\\set ON_ERROR_STOP on\n")
+	b.WriteString("BEGIN;\n")
+	for _, group := range []struct {
+		names []string
+		value string
+	}{{on, "true"}, {off, "false"}} {
+		if len(group.names) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "UPDATE %s SET %s = %s WHERE %s::text IN (%s);\n",
+			quoteQualified(t.Table), quoteIdent(t.Enabled), group.value, quoteIdent(t.Key), quoteLiteralList(group.names))
+	}
+	b.WriteString("COMMIT;\n")
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlagDiff(t *testing.T) {
+	source := []Flag{{"dark_mode", true}, {"new_checkout", true}, {"beta_search", false}, {"legacy", false}}
+	target := []Flag{{"new_checkout", false}, {"dark_mode", true}, {"legacy", true}}
+
+	changes, missing := FlagDiff(source, target)
+	wantChanges := []FlagChange{{Name: "legacy", From: true, To: false}, {Name: "new_checkout", From: false, To: true}}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("changes = %v, want %v", changes, wantChanges)
+	}
+	if !reflect.DeepEqual(missing, []string{"beta_search"}) {
+		t.Errorf("missing = %v, want [beta_search]", missing)
+	}
+}
+
+func TestSetFlagsScript(t *testing.T) {
+	table := FlagTable{Table: "public.feature_flags", Key: "key", Enabled: "enabled"}
+	script := SetFlagsScript(table, map[string]bool{"b": true, "a": true, "c": false})
+
+	for _, want := range []string{
+		`UPDATE "public"."feature_flags" SET "enabled" = true WHERE "key"::text IN ('a', 'b');`,
+		`UPDATE "public"."feature_flags" SET "enabled" = false WHERE "key"::text IN ('c');`,
+		"COMMIT;",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q\n%s", want, script)
+		}
+	}
+
+	script = SetFlagsScript(table, map[string]bool{"a": true})
+	if strings.Contains(script, "= false") {
+		t.Errorf("script updates disabled flags that were not requested:\n%s", script)
+	}
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// systemSchemas are managed by Postgres, Supabase, or drift itself and left
+// out of schema comparisons.
+var systemSchemas = []string{
+	"information_schema", "auth", "storage", "realtime", "_realtime", "_analytics",
+	"supabase_functions", "supabase_migrations", "extensions", "graphql",
+	"graphql_public", "net", "pgsodium", "pgsodium_masks", "vault", "cron",
+	"pgbouncer", "pgtle", MetadataSchema,
+}
+
+// userSchemaFilter returns a SQL condition on column that excludes system
+// schemas, except those listed in keep.
+func userSchemaFilter(column string, keep ...string) string {
+	kept := make(map[string]bool, len(keep))
+	for _, k := range keep {
+		kept[k] = true
+	}
+	var quoted []string
+	for _, s := range systemSchemas {
+		if !kept[s] {
+			quoted = append(quoted, "'"+s+"'")
+		}
+	}
+	return fmt.Sprintf("%s NOT IN (%s) AND %s NOT LIKE 'pg\\_%%'", column, strings.Join(quoted, ", "), column)
+}
+
+// Table summarizes one user table.
+type Table struct {
+	Schema  string
+	Name    string
+	RLS     bool
+	Columns int
+}
+
+// Policy is one row-level security policy.
+type Policy struct {
+	Schema  string
+	Table   string
+	Name    string
+	Command string
+	Roles   string
+}
+
+// CronJob is one pg_cron job.
+type CronJob struct {
+	Name     string
+	Schedule string
+	Command  string
+	Active   bool
+}
+
+// FetchTables lists tables in user schemas, sorted by schema and name.
+func FetchTables(opts RestoreOptions) ([]Table, error) {
+	rows, err := queryRows(opts, `SELECT n.nspname, c.relname, c.relrowsecurity,
+  (SELECT count(*) FROM pg_attribute a WHERE a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped)
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p') AND `+userSchemaFilter("n.nspname")+`
+ORDER BY 1, 2;`)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]Table, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 4 {
+			continue
+		}
+		columns, _ := strconv.Atoi(row[3])
+		tables = append(tables, Table{Schema: row[0], Name: row[1], RLS: row[2] == "t", Columns: columns})
+	}
+	return tables, nil
+}
+
+// FetchPolicies lists RLS policies on user tables and storage, sorted by
+// schema, table, and name.
+func FetchPolicies(opts RestoreOptions) ([]Policy, error) {
+	rows, err := queryRows(opts, `SELECT schemaname, tablename, policyname, cmd, array_to_string(roles, ',')
+FROM pg_policies
+WHERE `+userSchemaFilter("schemaname", "storage")+`
+ORDER BY 1, 2, 3;`)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]Policy, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 5 {
+			continue
+		}
+		policies = append(policies, Policy{Schema: row[0], Table: row[1], Name: row[2], Command: row[3], Roles: row[4]})
+	}
+	return policies, nil
+}
+
+// FetchCronJobs lists pg_cron jobs, sorted by name. It returns no jobs when
+// pg_cron is not installed.
+func FetchCronJobs(opts RestoreOptions) ([]CronJob, error) {
+	rows, err := queryRows(opts, `SELECT to_regclass('cron.job') IS NOT NULL;`)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 || rows[0][0] != "t" {
+		return nil, nil
+	}
+
+	rows, err = queryRows(opts, `SELECT coalesce(jobname, 'job ' || jobid), schedule,
+  regexp_replace(command, '\s+', ' ', 'g'), active
+FROM cron.job
+ORDER BY 1;`)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]CronJob, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 4 {
+			continue
+		}
+		jobs = append(jobs, CronJob{Name: row[0], Schedule: row[1], Command: strings.TrimSpace(row[2]), Active: row[3] == "t"})
+	}
+	return jobs, nil
+}
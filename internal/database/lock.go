@@ -0,0 +1,172 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// PushLockName is the lock taken by 'drift db push' and 'drift migrate push'.
+const PushLockName = "push"
+
+// LockTTL is how long a lock is honored before another holder may take it
+// over, so a crashed push does not block a branch forever.
+const LockTTL = 2 * time.Hour
+
+// MetadataSchema holds drift's own bookkeeping tables, such as drift.locks.
+// They belong to the database they live in: restores never copy them and
+// schema or data comparisons leave them out.
+const MetadataSchema = "drift"
+
+// ensureLockTableSQL creates the metadata table holding drift locks.
+const ensureLockTableSQL = `CREATE SCHEMA IF NOT EXISTS drift;
+CREATE TABLE IF NOT EXISTS drift.locks (
+    name text PRIMARY KEY,
+    holder text NOT NULL,
+    host text NOT NULL DEFAULT '',
+    operation text NOT NULL DEFAULT '',
+    acquired_at timestamptz NOT NULL DEFAULT now()
+);`
+
+// Lock is an advisory lock row in drift.locks.
+type Lock struct {
+	Name       string
+	Holder     string
+	Host       string
+	Operation  string
+	AcquiredAt time.Time
+}
+
+// SameOwner reports whether two locks were taken by the same holder on the same host.
+func (l Lock) SameOwner(other Lock) bool {
+	return l.Holder == other.Holder && l.Host == other.Host
+}
+
+// Stale reports whether the lock is older than LockTTL.
+func (l Lock) Stale(now time.Time) bool {
+	return now.Sub(l.AcquiredAt) > LockTTL
+}
+
+// String describes the lock, e.g. "locked by alice since 14:02 (db push on alice-mbp)".
+func (l Lock) String() string {
+	since := l.AcquiredAt.Local().Format("15:04")
+	if time.Since(l.AcquiredAt) > 24*time.Hour {
+		since = l.AcquiredAt.Local().Format("Jan 2 15:04")
+	}
+	detail := l.Operation
+	if l.Host != "" {
+		if detail != "" {
+			detail += " "
+		}
+		detail += "on " + l.Host
+	}
+	if detail == "" {
+		return fmt.Sprintf("locked by %s since %s", l.Holder, since)
+	}
+	return fmt.Sprintf("locked by %s since %s (%s)", l.Holder, since, detail)
+}
+
+// AcquireLock takes the named lock, replacing it if it is stale. When the
+// lock is held by someone else (or already by the same owner) it returns the
+// current holder and takes nothing.
+func AcquireLock(opts RestoreOptions, lock Lock) (*Lock, error) {
+	if _, err := RunScript(opts, "\\set ON_ERROR_STOP on\n"+ensureLockTableSQL+"\n"); err != nil {
+		return nil, fmt.Errorf("failed to create drift.locks: %w", err)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO drift.locks AS l (name, holder, host, operation)
+VALUES (%s, %s, %s, %s)
+ON CONFLICT (name) DO UPDATE SET holder = EXCLUDED.holder, host = EXCLUDED.host,
+    operation = EXCLUDED.operation, acquired_at = now()
+WHERE l.acquired_at < now() - interval '%d seconds'
+RETURNING name;`,
+		quoteLiteral(lock.Name), quoteLiteral(lock.Holder), quoteLiteral(lock.Host), quoteLiteral(lock.Operation),
+		int(LockTTL.Seconds()))
+
+	// Retry once in case the holder released the lock between the insert and the read.
+	for attempt := 0; attempt < 2; attempt++ {
+		rows, err := queryRows(opts, query)
+		if err != nil {
+			return nil, err
+		}
+		if returnedName(rows, lock.Name) {
+			return nil, nil
+		}
+
+		current, err := GetLock(opts, lock.Name)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil {
+			return current, nil
+		}
+	}
+	return nil, fmt.Errorf("could not acquire lock %s; try again", lock.Name)
+}
+
+// GetLock returns the named lock, or nil when it is not held.
+func GetLock(opts RestoreOptions, name string) (*Lock, error) {
+	exists, err := queryRows(opts, "SELECT to_regclass('drift.locks') IS NOT NULL;")
+	if err != nil {
+		return nil, err
+	}
+	if len(exists) == 0 || len(exists[0]) == 0 || exists[0][0] != "t" {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`SELECT name, holder, host, operation, extract(epoch FROM acquired_at)::bigint
+FROM drift.locks WHERE name = %s;`, quoteLiteral(name))
+	rows, err := queryRows(opts, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || len(rows[0]) != 5 {
+		return nil, nil
+	}
+	return parseLockRow(rows[0])
+}
+
+// ReleaseLock deletes the named lock. Unless force is set, only a lock held
+// by the same owner is released. It reports whether a row was deleted.
+func ReleaseLock(opts RestoreOptions, owner Lock, force bool) (bool, error) {
+	query := fmt.Sprintf("DELETE FROM drift.locks WHERE name = %s", quoteLiteral(owner.Name))
+	if !force {
+		query += fmt.Sprintf(" AND holder = %s AND host = %s", quoteLiteral(owner.Holder), quoteLiteral(owner.Host))
+	}
+	query += " RETURNING name;"
+
+	current, err := GetLock(opts, owner.Name)
+	if err != nil || current == nil {
+		return false, err
+	}
+	rows, err := queryRows(opts, query)
+	if err != nil {
+		return false, err
+	}
+	return returnedName(rows, owner.Name), nil
+}
+
+// returnedName reports whether a RETURNING name result contains name. psql
+// may also print the command tag, so the row count alone is not enough.
+func returnedName(rows [][]string, name string) bool {
+	for _, row := range rows {
+		if len(row) == 1 && row[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseLockRow(row []string) (*Lock, error) {
+	epoch, err := strconv.ParseInt(row[4], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lock timestamp %q", row[4])
+	}
+	return &Lock{
+		Name:       row[0],
+		Holder:     row[1],
+		Host:       row[2],
+		Operation:  row[3],
+		AcquiredAt: time.Unix(epoch, 0),
+	}, nil
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockString(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		lock Lock
+		want string
+	}{
+		{"full", Lock{Holder: "alice", Host: "alice-mbp", Operation: "db push", AcquiredAt: now}, "locked by alice since " + now.Format("15:04") + " (db push on alice-mbp)"},
+		{"no detail", Lock{Holder: "bob", AcquiredAt: now}, "locked by bob since " + now.Format("15:04")},
+		{"old", Lock{Holder: "bob", Host: "ci", AcquiredAt: now.Add(-48 * time.Hour)}, "(on ci)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.lock.String(); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockOwnershipAndStaleness(t *testing.T) {
+	now := time.Now()
+	lock := Lock{Holder: "alice", Host: "mbp", AcquiredAt: now.Add(-time.Hour)}
+
+	if !lock.SameOwner(Lock{Holder: "alice", Host: "mbp", Operation: "migrate push"}) {
+		t.Error("SameOwner() = false for the same holder and host")
+	}
+	if lock.SameOwner(Lock{Holder: "alice", Host: "ci"}) {
+		t.Error("SameOwner() = true for a different host")
+	}
+	if lock.Stale(now) {
+		t.Error("Stale() = true for a lock younger than LockTTL")
+	}
+	if !lock.Stale(now.Add(LockTTL)) {
+		t.Error("Stale() = false for a lock older than LockTTL")
+	}
+}
+
+func TestParseLockRow(t *testing.T) {
+	lock, err := parseLockRow([]string{"push", "alice", "mbp", "db push", "1700000000"})
+	if err != nil {
+		t.Fatalf("parseLockRow() error = %v", err)
+	}
+	if lock.Holder != "alice" || lock.Operation != "db push" || lock.AcquiredAt.Unix() != 1700000000 {
+		t.Errorf("parseLockRow() = %+v", lock)
+	}
+	if _, err := parseLockRow([]string{"push", "alice", "", "", "soon"}); err == nil {
+		t.Error("parseLockRow() accepted an invalid timestamp")
+	}
+}
+
+func TestReturnedName(t *testing.T) {
+	if !returnedName([][]string{{"push"}, {"INSERT 0 1"}}, "push") {
+		t.Error("returnedName() missed the returned row")
+	}
+	if returnedName([][]string{{"INSERT 0 0"}}, "push") {
+		t.Error("returnedName() treated a command tag as a row")
+	}
+}
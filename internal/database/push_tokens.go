@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PushTokenTable describes where an app stores APNs device tokens.
+type PushTokenTable struct {
+	Table             string
+	TokenColumn       string
+	CreatedColumn     string
+	EnvironmentColumn string // optional
+}
+
+// PushToken is a device token registration.
+type PushToken struct {
+	Token       string
+	Environment string
+	CreatedAt   string
+}
+
+// APNsEnvironmentValues returns the values an environment column may hold
+// for an APNs environment ("sandbox" is Apple's name for development).
+func APNsEnvironmentValues(apnsEnv string) []string {
+	if strings.EqualFold(apnsEnv, "production") {
+		return []string{"production", "prod"}
+	}
+	return []string{"development", "dev", "sandbox"}
+}
+
+// RecentPushTokensSQL builds the query for tokens registered since the given
+// time, newest first. envValues filters on the environment column, if any.
+func RecentPushTokensSQL(t PushTokenTable, envValues []string, since time.Time, limit int) string {
+	if limit <= 0 {
+		limit = 20
+	}
+	token := quoteIdent(t.TokenColumn)
+	created := quoteIdent(t.CreatedColumn)
+
+	env := "''"
+	var where []string
+	where = append(where, fmt.Sprintf("%s >= %s", created, quoteLiteral(since.UTC().Format(time.RFC3339))))
+	if t.EnvironmentColumn != "" {
+		env = quoteIdent(t.EnvironmentColumn) + "::text"
+		if len(envValues) > 0 {
+			where = append(where, fmt.Sprintf("lower(%s) IN (%s)", env, quoteLiteralList(envValues)))
+		}
+	}
+
+	// Keep the latest registration per token, then order newest first.
+	return fmt.Sprintf(`SELECT token, environment, created_at FROM (
+  SELECT DISTINCT ON (%[1]s) %[1]s::text AS token, %[2]s AS environment, %[3]s AS created_at
+  FROM %[4]s
+  WHERE %[5]s
+  ORDER BY %[1]s, %[3]s DESC
+) t
+ORDER BY created_at DESC
+LIMIT %[6]d;`, token, env, created, quoteQualified(NormalizeTableName(t.Table)), strings.Join(where, " AND "), limit)
+}
+
+// FetchRecentPushTokens lists tokens registered since the given time, newest first.
+func FetchRecentPushTokens(opts RestoreOptions, t PushTokenTable, envValues []string, since time.Time, limit int) ([]PushToken, error) {
+	rows, err := queryRows(opts, RecentPushTokensSQL(t, envValues, since, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]PushToken, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 3 || row[0] == "" {
+			continue
+		}
+		tokens = append(tokens, PushToken{Token: row[0], Environment: row[1], CreatedAt: row[2]})
+	}
+	return tokens, nil
+}
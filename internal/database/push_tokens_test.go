@@ -0,0 +1,46 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPNsEnvironmentValues(t *testing.T) {
+	if got := APNsEnvironmentValues("Production"); !reflect.DeepEqual(got, []string{"production", "prod"}) {
+		t.Errorf("APNsEnvironmentValues(Production) = %v", got)
+	}
+	if got := APNsEnvironmentValues("development"); !reflect.DeepEqual(got, []string{"development", "dev", "sandbox"}) {
+		t.Errorf("APNsEnvironmentValues(development) = %v", got)
+	}
+}
+
+func TestRecentPushTokensSQL(t *testing.T) {
+	since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	table := PushTokenTable{Table: "device_tokens", TokenColumn: "token", CreatedColumn: "created_at"}
+
+	sql := RecentPushTokensSQL(table, []string{"sandbox"}, since, 5)
+	for _, want := range []string{
+		`FROM "public"."device_tokens"`,
+		`DISTINCT ON ("token")`,
+		`"created_at" >= '2026-01-02T03:04:05Z'`,
+		`LIMIT 5;`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("SQL missing %q:\n%s", want, sql)
+		}
+	}
+	if strings.Contains(sql, "lower(") {
+		t.Errorf("SQL filters on environment without an environment column:\n%s", sql)
+	}
+
+	table.EnvironmentColumn = "apns_env"
+	sql = RecentPushTokensSQL(table, []string{"sandbox", "development"}, since, 0)
+	if !strings.Contains(sql, `lower("apns_env"::text) IN ('development', 'sandbox')`) {
+		t.Errorf("SQL missing environment filter:\n%s", sql)
+	}
+	if !strings.Contains(sql, "LIMIT 20;") {
+		t.Errorf("SQL missing default limit:\n%s", sql)
+	}
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RealtimePublication is the publication Supabase Realtime listens to.
+const RealtimePublication = "supabase_realtime"
+
+// EnsureRealtimePublicationSQL creates the realtime publication if it is missing.
+const EnsureRealtimePublicationSQL = `
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_publication WHERE pubname = 'supabase_realtime'
+    ) THEN
+        CREATE PUBLICATION supabase_realtime;
+    END IF;
+END $$;`
+
+// FetchPublicationTables returns the schema-qualified tables in a publication, sorted.
+func FetchPublicationTables(opts RestoreOptions, publication string) ([]string, error) {
+	query := fmt.Sprintf(
+		"SELECT schemaname || '.' || tablename FROM pg_publication_tables WHERE pubname = %s ORDER BY 1;",
+		quoteLiteral(publication))
+
+	rows, err := queryRows(opts, query)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]string, 0, len(rows))
+	for _, row := range rows {
+		tables = append(tables, row[0])
+	}
+	return tables, nil
+}
+
+// PublicationDiff compares desired and current publication tables. Both
+// results are sorted; table names should already be schema-qualified.
+func PublicationDiff(want, have []string) (add, remove []string) {
+	wantSet := make(map[string]bool, len(want))
+	for _, t := range want {
+		wantSet[t] = true
+	}
+	haveSet := make(map[string]bool, len(have))
+	for _, t := range have {
+		haveSet[t] = true
+	}
+
+	for t := range wantSet {
+		if !haveSet[t] {
+			add = append(add, t)
+		}
+	}
+	for t := range haveSet {
+		if !wantSet[t] {
+			remove = append(remove, t)
+		}
+	}
+	sort.Strings(add)
+	sort.Strings(remove)
+	return add, remove
+}
+
+// PublicationSyncSQL returns statements that add and drop tables from a
+// publication, creating the realtime publication first if needed.
+func PublicationSyncSQL(publication string, add, remove []string) string {
+	var b strings.Builder
+	if publication == RealtimePublication {
+		b.WriteString(strings.TrimSpace(EnsureRealtimePublicationSQL))
+		b.WriteString("\n")
+	}
+	for _, t := range add {
+		fmt.Fprintf(&b, "ALTER PUBLICATION %s ADD TABLE %s;\n", quoteIdent(publication), quoteQualified(t))
+	}
+	for _, t := range remove {
+		fmt.Fprintf(&b, "ALTER PUBLICATION %s DROP TABLE %s;\n", quoteIdent(publication), quoteQualified(t))
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPublicationDiff(t *testing.T) {
+	add, remove := PublicationDiff(
+		[]string{"public.messages", "public.presence", "public.rooms"},
+		[]string{"public.rooms", "public.legacy", "public.messages"},
+	)
+	if !reflect.DeepEqual(add, []string{"public.presence"}) {
+		t.Errorf("add = %v, want [public.presence]", add)
+	}
+	if !reflect.DeepEqual(remove, []string{"public.legacy"}) {
+		t.Errorf("remove = %v, want [public.legacy]", remove)
+	}
+
+	add, remove = PublicationDiff([]string{"public.a"}, []string{"public.a"})
+	if len(add) != 0 || len(remove) != 0 {
+		t.Errorf("in-sync diff = %v, %v; want empty", add, remove)
+	}
+}
+
+func TestPublicationSyncSQL(t *testing.T) {
+	sql := PublicationSyncSQL(RealtimePublication, []string{"public.messages"}, []string{"public.legacy"})
+
+	for _, want := range []string{
+		"CREATE PUBLICATION supabase_realtime;",
+		`ALTER PUBLICATION "supabase_realtime" ADD TABLE "public"."messages";`,
+		`ALTER PUBLICATION "supabase_realtime" DROP TABLE "public"."legacy";`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("sync SQL missing %q\n%s", want, sql)
+		}
+	}
+	if strings.Index(sql, "CREATE PUBLICATION") > strings.Index(sql, "ADD TABLE") {
+		t.Error("publication must be ensured before tables are added")
+	}
+}
@@ -3,7 +3,9 @@ package database
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -32,6 +34,41 @@ type RestoreOptions struct {
 	// "safe scope" (public + allowed auth + schema_migrations) to an
 	// "all insertable tables" scope based on target INSERT privileges.
 	CopyAllInsertableTables bool
+
+	// ExcludeTables lists schema-qualified tables whose data is never
+	// copied, whatever the scope. Their TRUNCATE is skipped too, so existing
+	// target rows are left alone.
+	ExcludeTables []string
+
+	// Resumable restores a plain SQL backup in chunks, one transaction each:
+	// the TRUNCATEs, then one chunk per table, then sequence values. A
+	// failure returns a *RestoreError saying how many chunks were applied,
+	// and a later restore with ResumeFrom set to that count continues from
+	// the failed chunk.
+	Resumable  bool
+	ResumeFrom int
+	// OnChunk, if set, is called after each chunk of a resumable restore
+	// is applied, with the number applied so far.
+	OnChunk func(applied, total int, chunk string)
+}
+
+// RestoreError reports a resumable restore that stopped part way through.
+type RestoreError struct {
+	Applied int    // chunks applied, including earlier runs
+	Total   int    // chunks in the restore
+	Chunk   string // the chunk that failed
+	// Connection is set when the connection was lost rather than a
+	// statement failing, so resuming is likely to succeed.
+	Connection bool
+	Err        error
+}
+
+func (e *RestoreError) Error() string {
+	return fmt.Sprintf("restore stopped at %s after %d of %d chunks: %v", e.Chunk, e.Applied, e.Total, e.Err)
+}
+
+func (e *RestoreError) Unwrap() error {
+	return e.Err
 }
 
 // DefaultRestoreOptions returns default restore options.
@@ -66,6 +103,12 @@ func Restore(opts RestoreOptions) error {
 	return restoreCustom(opts)
 }
 
+// IsPlainSQLBackup reports whether the backup at path is a plain SQL dump
+// rather than a pg_dump archive.
+func IsPlainSQLBackup(path string) (bool, error) {
+	return isPlainSQLFormat(path)
+}
+
 // isPlainSQLFormat checks if a backup file is plain SQL (text) vs custom (binary) format.
 func isPlainSQLFormat(filename string) (bool, error) {
 	file, err := os.Open(filename)
@@ -126,6 +169,15 @@ func restoreCustom(opts RestoreOptions) error {
 		args = append(args, "-j", fmt.Sprintf("%d", opts.Jobs))
 	}
 
+	if len(opts.ExcludeTables) > 0 {
+		listFile, err := restoreListWithoutTableData(pgRestore, opts.InputFile, opts.ExcludeTables)
+		if err != nil {
+			return fmt.Errorf("failed to filter excluded tables: %w", err)
+		}
+		defer os.Remove(listFile)
+		args = append(args, "-L", listFile)
+	}
+
 	args = append(args, opts.InputFile)
 
 	env := map[string]string{
@@ -173,12 +225,16 @@ func restoreSQL(opts RestoreOptions) error {
 	//    - auth.* tables with INSERT privileges on target
 	//    - supabase_migrations.schema_migrations
 	// 3. Add session_replication_role = replica to bypass trigger/constraint side effects
-	processedFile, err := preprocessBackupFileWithScope(opts.InputFile, allowedAuthTables, allowedAllTables)
+	processedFile, err := preprocessBackupFileWithScope(opts.InputFile, allowedAuthTables, allowedAllTables, excludedTableSet(opts.ExcludeTables))
 	if err != nil {
 		return fmt.Errorf("failed to preprocess backup: %w", err)
 	}
 	defer os.Remove(processedFile) // Clean up temp file
 
+	if opts.Resumable {
+		return restoreSQLChunks(psql, processedFile, opts)
+	}
+
 	args := []string{
 		"-h", opts.Host,
 		"-p", fmt.Sprintf("%d", opts.Port),
@@ -212,6 +268,173 @@ func restoreSQL(opts RestoreOptions) error {
 	return nil
 }
 
+// restoreSQLChunks applies a preprocessed restore script chunk by chunk,
+// each in its own psql transaction, skipping the first opts.ResumeFrom.
+func restoreSQLChunks(psql, processedFile string, opts RestoreOptions) error {
+	dir, err := os.MkdirTemp("", "drift-restore-chunks-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	chunks, err := splitRestoreScript(processedFile, dir)
+	if err != nil {
+		return fmt.Errorf("failed to split restore: %w", err)
+	}
+	if opts.ResumeFrom > len(chunks) {
+		return fmt.Errorf("resume point %d is past the end of the restore (%d chunks); the backup or copy scope changed", opts.ResumeFrom, len(chunks))
+	}
+
+	env := map[string]string{
+		"PGPASSWORD": opts.Password,
+	}
+	for i := opts.ResumeFrom; i < len(chunks); i++ {
+		chunk := chunks[i]
+		result, err := shell.RunWithEnv(env, psql,
+			"-h", opts.Host,
+			"-p", fmt.Sprintf("%d", opts.Port),
+			"-U", opts.User,
+			"-d", opts.Database,
+			"-v", "ON_ERROR_STOP=1",
+			"-1",
+			"-f", chunk.File,
+		)
+		if err != nil || result.ExitCode != 0 {
+			return &RestoreError{
+				Applied:    i,
+				Total:      len(chunks),
+				Chunk:      chunk.Name,
+				Connection: isConnectionFailure(result),
+				Err:        fmt.Errorf("psql restore failed: %s", psqlFailure(result, err)),
+			}
+		}
+		if opts.OnChunk != nil {
+			opts.OnChunk(i+1, len(chunks), chunk.Name)
+		}
+	}
+	return nil
+}
+
+// restoreChunk is one transaction of a resumable restore.
+type restoreChunk struct {
+	Name string // "truncate", a table name, or "sequences"
+	File string
+}
+
+// splitRestoreScript splits a script written by filterRestoreStream into
+// chunk files in dir: the TRUNCATEs, one chunk per COPY block, and the
+// remaining statements (sequence values). Each chunk sets
+// session_replication_role itself, since each runs in a new session.
+// Splitting the same script always gives the same chunks.
+func splitRestoreScript(path, dir string) ([]restoreChunk, error) {
+	input, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	var chunks []restoreChunk
+	write := func(name string, lines []string) error {
+		if len(lines) == 0 {
+			return nil
+		}
+		file := filepath.Join(dir, fmt.Sprintf("%04d.sql", len(chunks)))
+		content := "SET session_replication_role = replica;\n" + strings.Join(lines, "\n") + "\nSET session_replication_role = DEFAULT;\n"
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			return err
+		}
+		chunks = append(chunks, restoreChunk{Name: name, File: file})
+		return nil
+	}
+
+	scanner := bufio.NewScanner(input)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 10*1024*1024)
+
+	var truncates, rest, block []string
+	copyTable := ""
+	seenCopy := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if copyTable != "" {
+			block = append(block, line)
+			if trimmed == "\\." {
+				if err := write(copyTable, block); err != nil {
+					return nil, err
+				}
+				copyTable, block = "", nil
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(strings.ToUpper(trimmed), "COPY "):
+			if !seenCopy {
+				if err := write("truncate", truncates); err != nil {
+					return nil, err
+				}
+				seenCopy = true
+			}
+			copyTable = copyTargetTable(trimmed)
+			block = []string{line}
+		case trimmed == "" || strings.HasPrefix(trimmed, "--") || strings.HasPrefix(trimmed, "SET session_replication_role"):
+			// Chunks set the role themselves
+		case seenCopy:
+			rest = append(rest, line)
+		default:
+			truncates = append(truncates, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading restore script: %w", err)
+	}
+	if copyTable != "" {
+		return nil, fmt.Errorf("restore script ends inside the COPY block for %s", copyTable)
+	}
+	if !seenCopy {
+		rest = append(truncates, rest...)
+	}
+	if err := write("sequences", rest); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// isConnectionFailure reports whether psql failed because the connection
+// was lost or could not be made. psql exits with 2 when the connection to
+// the server goes bad.
+func isConnectionFailure(result *shell.Result) bool {
+	if result == nil {
+		return false
+	}
+	if result.ExitCode == 2 {
+		return true
+	}
+	stderr := strings.ToLower(result.Stderr)
+	for _, marker := range []string{"server closed the connection", "connection to server", "ssl syscall error", "could not connect", "terminating connection"} {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// psqlFailure describes why a psql run failed.
+func psqlFailure(result *shell.Result, err error) string {
+	if result != nil && result.Stderr != "" {
+		return result.Stderr
+	}
+	if err != nil {
+		return err.Error()
+	}
+	if result != nil {
+		return fmt.Sprintf("psql exited with code %d", result.ExitCode)
+	}
+	return "unknown error"
+}
+
 // preprocessBackupFile creates a modified copy of the backup file that:
 // 1. Removes \restrict/\unrestrict lines (Supabase psql guard metacommands)
 // 2. Keeps only safe data sync statements from full backups:
@@ -223,10 +446,10 @@ func restoreSQL(opts RestoreOptions) error {
 // 3. Inserts TRUNCATE ... CASCADE for each copied table before first COPY
 // 4. Wraps content with SET session_replication_role = replica
 func preprocessBackupFile(inputFile string, allowedAuthCopyTables map[string]bool) (string, error) {
-	return preprocessBackupFileWithScope(inputFile, allowedAuthCopyTables, nil)
+	return preprocessBackupFileWithScope(inputFile, allowedAuthCopyTables, nil, nil)
 }
 
-func preprocessBackupFileWithScope(inputFile string, allowedAuthCopyTables, allowedAllTables map[string]bool) (string, error) {
+func preprocessBackupFileWithScope(inputFile string, allowedAuthCopyTables, allowedAllTables, excludedTables map[string]bool) (string, error) {
 	// Two-pass approach: first collect all tables that will be COPYed,
 	// then emit all TRUNCATEs up front before any COPY blocks.
 	//
@@ -235,7 +458,7 @@ func preprocessBackupFileWithScope(inputFile string, allowedAuthCopyTables, allo
 	// that was already loaded earlier in alphabetical order.
 
 	// --- Pass 1: collect tables ---
-	tablesToCopy := collectCopyTables(inputFile, allowedAuthCopyTables, allowedAllTables)
+	tablesToCopy := collectCopyTables(inputFile, allowedAuthCopyTables, allowedAllTables, excludedTables)
 
 	// --- Pass 2: write processed file ---
 	input, err := os.Open(inputFile)
@@ -249,21 +472,42 @@ func preprocessBackupFileWithScope(inputFile string, allowedAuthCopyTables, allo
 		return "", err
 	}
 
+	if err := filterRestoreStream(tempFile, input, tablesToCopy, allowedAuthCopyTables, allowedAllTables, excludedTables, nil); err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return "", err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempFile.Name())
+		return "", err
+	}
+	return tempFile.Name(), nil
+}
+
+// filterRestoreStream writes the restorable part of a plain SQL dump read
+// from r to w: all TRUNCATEs for tablesToCopy up front, then the allowed
+// COPY blocks and setval statements, wrapped in session_replication_role =
+// replica. COPY blocks for excludedTables are dropped. onTable, if set, is
+// called as each kept COPY block starts.
+func filterRestoreStream(w io.Writer, r io.Reader, tablesToCopy []string, allowedAuthCopyTables, allowedAllTables, excludedTables map[string]bool, onTable func(table string)) error {
+	out := bufio.NewWriterSize(w, 256*1024)
+
 	// Write header to disable triggers during restore
 	// This prevents trigger-related errors (e.g., handle_new_user trigger on auth.users)
-	tempFile.WriteString("-- Drift: Disable triggers during restore\n")
-	tempFile.WriteString("SET session_replication_role = replica;\n\n")
+	out.WriteString("-- Drift: Disable triggers during restore\n")
+	out.WriteString("SET session_replication_role = replica;\n\n")
 
 	// Emit all TRUNCATEs up front so CASCADE doesn't destroy already-loaded data
 	if len(tablesToCopy) > 0 {
-		tempFile.WriteString("-- Drift: Truncate all target tables before loading data\n")
+		out.WriteString("-- Drift: Truncate all target tables before loading data\n")
 		for _, table := range tablesToCopy {
-			tempFile.WriteString(fmt.Sprintf("TRUNCATE TABLE %s CASCADE;\n", table))
+			out.WriteString(fmt.Sprintf("TRUNCATE TABLE %s CASCADE;\n", table))
 		}
-		tempFile.WriteString("\n")
+		out.WriteString("\n")
 	}
 
-	scanner := bufio.NewScanner(input)
+	scanner := bufio.NewScanner(r)
 	// Increase buffer size for long lines
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 10*1024*1024) // 10MB max line
@@ -282,7 +526,10 @@ func preprocessBackupFileWithScope(inputFile string, allowedAuthCopyTables, allo
 
 		if inCopyBlock {
 			if keepCopyBlock {
-				tempFile.WriteString(line + "\n")
+				// Stop early if the reader on the other end of w went away.
+				if _, err := out.WriteString(line + "\n"); err != nil {
+					return err
+				}
 			}
 			if trimmed == "\\." {
 				inCopyBlock = false
@@ -293,10 +540,13 @@ func preprocessBackupFileWithScope(inputFile string, allowedAuthCopyTables, allo
 
 		if strings.HasPrefix(upper, "COPY ") {
 			table := copyTargetTable(trimmed)
-			if isAllowedCopyTable(table, allowedAuthCopyTables, allowedAllTables) {
-				tempFile.WriteString(line + "\n")
+			if !excludedTables[table] && isAllowedCopyTable(table, allowedAuthCopyTables, allowedAllTables) {
+				out.WriteString(line + "\n")
 				inCopyBlock = true
 				keepCopyBlock = true
+				if onTable != nil {
+					onTable(table)
+				}
 			} else {
 				inCopyBlock = true
 				keepCopyBlock = false
@@ -305,27 +555,24 @@ func preprocessBackupFileWithScope(inputFile string, allowedAuthCopyTables, allo
 		}
 
 		if isAllowedSetvalStatement(trimmed, allowedAuthCopyTables, allowedAllTables) {
-			tempFile.WriteString(line + "\n")
+			out.WriteString(line + "\n")
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		tempFile.Close()
-		os.Remove(tempFile.Name())
-		return "", fmt.Errorf("error reading backup file: %w", err)
+		return fmt.Errorf("error reading backup file: %w", err)
 	}
 
 	// Write footer to restore normal trigger behavior
-	tempFile.WriteString("\n-- Drift: Restore normal trigger behavior\n")
-	tempFile.WriteString("SET session_replication_role = DEFAULT;\n")
+	out.WriteString("\n-- Drift: Restore normal trigger behavior\n")
+	out.WriteString("SET session_replication_role = DEFAULT;\n")
 
-	tempFile.Close()
-	return tempFile.Name(), nil
+	return out.Flush()
 }
 
 // collectCopyTables scans the backup file and returns an ordered list of
 // unique table names that will be COPYed (in the order they first appear).
-func collectCopyTables(inputFile string, allowedAuthCopyTables, allowedAllTables map[string]bool) []string {
+func collectCopyTables(inputFile string, allowedAuthCopyTables, allowedAllTables, excludedTables map[string]bool) []string {
 	f, err := os.Open(inputFile)
 	if err != nil {
 		return nil
@@ -355,7 +602,7 @@ func collectCopyTables(inputFile string, allowedAuthCopyTables, allowedAllTables
 		if strings.HasPrefix(upper, "COPY ") {
 			inCopy = true
 			table := copyTargetTable(trimmed)
-			if table != "" && !seen[table] && isAllowedCopyTable(table, allowedAuthCopyTables, allowedAllTables) {
+			if table != "" && !seen[table] && !excludedTables[table] && isAllowedCopyTable(table, allowedAuthCopyTables, allowedAllTables) {
 				seen[table] = true
 				tables = append(tables, table)
 			}
@@ -529,7 +776,73 @@ func normalizeQualifiedName(name string) string {
 	return strings.ToLower(n)
 }
 
+// excludedTableSet normalizes tables into a lookup set. Bare names are
+// assumed to be in public.
+func excludedTableSet(tables []string) map[string]bool {
+	set := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		name := normalizeQualifiedName(t)
+		if name == "" {
+			continue
+		}
+		if !strings.Contains(name, ".") {
+			name = "public." + name
+		}
+		set[name] = true
+	}
+	return set
+}
+
+// restoreListWithoutTableData writes a pg_restore table of contents for
+// inputFile without the TABLE DATA entries of excluded tables, for use with
+// pg_restore -L. The caller removes the returned file.
+func restoreListWithoutTableData(pgRestore, inputFile string, excluded []string) (string, error) {
+	result, err := shell.Run(pgRestore, "-l", inputFile)
+	if err != nil || result.ExitCode != 0 {
+		errMsg := result.Stderr
+		if errMsg == "" && err != nil {
+			errMsg = err.Error()
+		}
+		return "", fmt.Errorf("pg_restore -l failed: %s", strings.TrimSpace(errMsg))
+	}
+
+	listFile, err := os.CreateTemp("", "drift-restore-*.list")
+	if err != nil {
+		return "", err
+	}
+	list := filterRestoreList(result.Stdout, excludedTableSet(excluded))
+	if _, err := listFile.WriteString(list); err != nil {
+		listFile.Close()
+		os.Remove(listFile.Name())
+		return "", err
+	}
+	if err := listFile.Close(); err != nil {
+		os.Remove(listFile.Name())
+		return "", err
+	}
+	return listFile.Name(), nil
+}
+
+// filterRestoreList drops "TABLE DATA <schema> <table>" entries for excluded
+// tables and drift's metadata tables from pg_restore -l output.
+func filterRestoreList(list string, excluded map[string]bool) string {
+	var out strings.Builder
+	for _, line := range strings.SplitAfter(list, "\n") {
+		if _, rest, ok := strings.Cut(line, " TABLE DATA "); ok && !strings.HasPrefix(strings.TrimSpace(line), ";") {
+			fields := strings.Fields(rest)
+			if len(fields) >= 2 && (excluded[normalizeQualifiedName(fields[0]+"."+fields[1])] || fields[0] == MetadataSchema) {
+				continue
+			}
+		}
+		out.WriteString(line)
+	}
+	return out.String()
+}
+
 func isAllowedCopyTable(table string, allowedAuthCopyTables, allowedAllTables map[string]bool) bool {
+	if isMetadataTable(table) {
+		return false
+	}
 	if len(allowedAllTables) > 0 {
 		return allowedAllTables[table]
 	}
@@ -543,6 +856,13 @@ func isAllowedCopyTable(table string, allowedAuthCopyTables, allowedAllTables ma
 	return table == "supabase_migrations.schema_migrations"
 }
 
+// isMetadataTable reports whether table is in MetadataSchema. Copying one
+// would overwrite the target's own state, such as the lock held by the push
+// doing the copy.
+func isMetadataTable(table string) bool {
+	return strings.HasPrefix(table, MetadataSchema+".")
+}
+
 func isAllowedSetvalStatement(line string, allowedAuthCopyTables, allowedAllTables map[string]bool) bool {
 	trimmed := strings.TrimSpace(line)
 	upper := strings.ToUpper(trimmed)
@@ -551,6 +871,9 @@ func isAllowedSetvalStatement(line string, allowedAuthCopyTables, allowedAllTabl
 	}
 
 	lower := strings.ToLower(trimmed)
+	if strings.Contains(lower, "'"+MetadataSchema+".") {
+		return false
+	}
 	if len(allowedAllTables) > 0 {
 		return !strings.Contains(lower, "'pg_catalog.") &&
 			!strings.Contains(lower, "'information_schema.") &&
@@ -620,3 +943,17 @@ func ExecuteSQL(opts RestoreOptions, sql string) (*shell.Result, error) {
 
 	return shell.RunWithEnv(env, psql, args...)
 }
+
+// ApplySQLFile runs a SQL file against a connection URL, stopping at the first error.
+func ApplySQLFile(connURL, path string) error {
+	psql, err := findPGTool("psql")
+	if err != nil {
+		return err
+	}
+
+	result, err := shell.Run(psql, connURL, "-v", "ON_ERROR_STOP=1", "-f", path)
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to apply %s: %s", path, strings.TrimSpace(psqlFailure(result, err)))
+	}
+	return nil
+}
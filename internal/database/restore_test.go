@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/undrift/drift/internal/testutil"
 )
 
 func allowedAuthTablesForTest(tables ...string) map[string]bool {
@@ -323,7 +325,7 @@ func TestPreprocessBackupFileWithScope_AllowsNonPublicWhenEnabled(t *testing.T)
 	}
 
 	allowedAll := allowedAllTablesForTest("storage.vector_indexes", "public.users")
-	processedPath, err := preprocessBackupFileWithScope(inputPath, nil, allowedAll)
+	processedPath, err := preprocessBackupFileWithScope(inputPath, nil, allowedAll, nil)
 	if err != nil {
 		t.Fatalf("preprocessBackupFileWithScope() error = %v", err)
 	}
@@ -414,6 +416,74 @@ func TestPreprocessBackupFile_TruncatesBeforeAllCopies(t *testing.T) {
 	}
 }
 
+func TestPreprocessBackupFileWithScope_SkipsExcludedTables(t *testing.T) {
+	inputDir := t.TempDir()
+	inputPath := filepath.Join(inputDir, "input.sql")
+	input := strings.Join([]string{
+		"COPY public.analytics_events (id, name) FROM stdin;",
+		"event_row_1\tsignup",
+		"\\.",
+		"COPY public.users (id, username) FROM stdin;",
+		"public_row_1\ttaha",
+		"\\.",
+		"",
+	}, "\n")
+
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatalf("failed to write input file: %v", err)
+	}
+
+	excluded := excludedTableSet([]string{"analytics_events"})
+	processedPath, err := preprocessBackupFileWithScope(inputPath, nil, nil, excluded)
+	if err != nil {
+		t.Fatalf("preprocessBackupFileWithScope() error = %v", err)
+	}
+	defer os.Remove(processedPath)
+
+	processedBytes, err := os.ReadFile(processedPath)
+	if err != nil {
+		t.Fatalf("failed to read processed file: %v", err)
+	}
+	processed := string(processedBytes)
+
+	if strings.Contains(processed, "event_row_1") || strings.Contains(processed, "public.analytics_events") {
+		t.Fatalf("excluded table should be neither copied nor truncated:\n%s", processed)
+	}
+	if !strings.Contains(processed, "TRUNCATE TABLE public.users CASCADE;") || !strings.Contains(processed, "public_row_1\ttaha") {
+		t.Fatalf("other public tables should still be copied:\n%s", processed)
+	}
+}
+
+func TestFilterRestoreList_DropsExcludedTableData(t *testing.T) {
+	list := strings.Join([]string{
+		";",
+		"; Selected TOC Entries:",
+		"3401; 1259 16400 TABLE public analytics_events postgres",
+		"3402; 1259 16410 TABLE public users postgres",
+		"3501; 0 16400 TABLE DATA public analytics_events postgres",
+		"3502; 0 16410 TABLE DATA public users postgres",
+		"",
+	}, "\n")
+
+	got := filterRestoreList(list, excludedTableSet([]string{"public.analytics_events"}))
+	if strings.Contains(got, "TABLE DATA public analytics_events") {
+		t.Fatalf("list should drop excluded table data:\n%s", got)
+	}
+	for _, want := range []string{"TABLE public analytics_events", "TABLE DATA public users"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("list missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestExcludeTableDataArgs(t *testing.T) {
+	got := excludeTableDataArgs([]string{"audit.log", "analytics_events", "public.analytics_events"})
+	want := []string{"--exclude-table-data=audit.log", "--exclude-table-data=public.analytics_events"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("excludeTableDataArgs() = %v, want %v", got, want)
+	}
+}
+
 func TestIsAllowedSetvalStatement_AllScope(t *testing.T) {
 	allowedAll := allowedAllTablesForTest("storage.vector_indexes")
 
@@ -470,3 +540,182 @@ func hasFlag(args []string, flag string) bool {
 	}
 	return false
 }
+
+func TestPreprocessBackupFileWithScope_NeverCopiesDriftMetadata(t *testing.T) {
+	inputPath := filepath.Join(t.TempDir(), "input.sql")
+	input := strings.Join([]string{
+		"COPY drift.locks (name, holder, host, operation, acquired_at) FROM stdin;",
+		"push\talice\talice-mbp\tdb push\t2026-01-01 00:00:00+00",
+		"\\.",
+		"COPY public.users (id, username) FROM stdin;",
+		"public_row_1\ttaha",
+		"\\.",
+		"SELECT pg_catalog.setval('drift.locks_id_seq', 3, true);",
+		"",
+	}, "\n")
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatalf("failed to write input file: %v", err)
+	}
+
+	// --scope all lists every insertable table, drift.locks included.
+	allowedAll := allowedAllTablesForTest("drift.locks", "public.users")
+	processedPath, err := preprocessBackupFileWithScope(inputPath, nil, allowedAll, nil)
+	if err != nil {
+		t.Fatalf("preprocessBackupFileWithScope() error = %v", err)
+	}
+	defer os.Remove(processedPath)
+
+	processedBytes, err := os.ReadFile(processedPath)
+	if err != nil {
+		t.Fatalf("failed to read processed file: %v", err)
+	}
+	processed := string(processedBytes)
+	for _, unwanted := range []string{"TRUNCATE TABLE drift.locks", "COPY drift.locks", "alice-mbp", "drift.locks_id_seq"} {
+		if strings.Contains(processed, unwanted) {
+			t.Errorf("processed file should not contain %q:\n%s", unwanted, processed)
+		}
+	}
+	if !strings.Contains(processed, "COPY public.users") {
+		t.Errorf("processed file should keep public.users:\n%s", processed)
+	}
+
+	for _, allowed := range []map[string]bool{nil, allowedAll} {
+		if isAllowedCopyTable("drift.locks", nil, allowed) {
+			t.Errorf("isAllowedCopyTable(drift.locks, all=%v) = true", allowed)
+		}
+	}
+}
+
+func TestFilterRestoreList_DropsDriftMetadata(t *testing.T) {
+	list := "3501; 0 16400 TABLE DATA drift locks postgres\n3502; 0 16410 TABLE DATA public users postgres\n"
+	got := filterRestoreList(list, nil)
+	if strings.Contains(got, "TABLE DATA drift locks") || !strings.Contains(got, "TABLE DATA public users") {
+		t.Fatalf("list should drop only drift metadata data:\n%s", got)
+	}
+}
+
+func writeResumableScript(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "processed.sql")
+	script := strings.Join([]string{
+		"-- Drift: Disable triggers during restore",
+		"SET session_replication_role = replica;",
+		"",
+		"TRUNCATE TABLE public.a CASCADE;",
+		"TRUNCATE TABLE public.b CASCADE;",
+		"",
+		"COPY public.a (id) FROM stdin;",
+		"1",
+		"\\.",
+		"COPY public.b (id) FROM stdin;",
+		"2",
+		"\\.",
+		"SELECT pg_catalog.setval('public.a_id_seq', 1, true);",
+		"",
+		"-- Drift: Restore normal trigger behavior",
+		"SET session_replication_role = DEFAULT;",
+		"",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSplitRestoreScript(t *testing.T) {
+	chunks, err := splitRestoreScript(writeResumableScript(t), t.TempDir())
+	if err != nil {
+		t.Fatalf("splitRestoreScript() error = %v", err)
+	}
+
+	var names []string
+	for _, c := range chunks {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "truncate,public.a,public.b,sequences" {
+		t.Fatalf("chunks = %v", names)
+	}
+
+	data, err := os.ReadFile(chunks[2].File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SET session_replication_role = replica;\nCOPY public.b (id) FROM stdin;\n2\n\\.\nSET session_replication_role = DEFAULT;\n"
+	if string(data) != want {
+		t.Errorf("chunk public.b = %q, want %q", data, want)
+	}
+}
+
+func TestRestoreSQLChunks_ResumesAfterConnectionLoss(t *testing.T) {
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "applied.log")
+	failMarker := filepath.Join(tempDir, "fail")
+	psqlScript := strings.Join([]string{
+		"#!/bin/sh",
+		"for last; do :; done",
+		"if grep -q 'COPY public.b' \"$last\" && [ -f \"$DRIFT_TEST_FAIL\" ]; then",
+		"  echo 'server closed the connection unexpectedly' 1>&2",
+		"  exit 2",
+		"fi",
+		"sed -n 2p \"$last\" >> \"$DRIFT_TEST_LOG\"",
+		"",
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(tempDir, "psql"), []byte(psqlScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", tempDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DRIFT_TEST_LOG", logPath)
+	t.Setenv("DRIFT_TEST_FAIL", failMarker)
+	if err := os.WriteFile(failMarker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	script := writeResumableScript(t)
+	var progress []string
+	opts := RestoreOptions{
+		Host: "localhost", Port: 5432, Database: "postgres", User: "postgres",
+		Resumable: true,
+		OnChunk: func(applied, total int, chunk string) {
+			progress = append(progress, fmt.Sprintf("%d/%d %s", applied, total, chunk))
+		},
+	}
+
+	err := restoreSQLChunks(filepath.Join(tempDir, "psql"), script, opts)
+	restoreErr, ok := err.(*RestoreError)
+	if !ok {
+		t.Fatalf("restoreSQLChunks() error = %v, want *RestoreError", err)
+	}
+	if restoreErr.Applied != 2 || restoreErr.Total != 4 || restoreErr.Chunk != "public.b" || !restoreErr.Connection {
+		t.Errorf("RestoreError = %+v", restoreErr)
+	}
+	if strings.Join(progress, ",") != "1/4 truncate,2/4 public.a" {
+		t.Errorf("progress = %v", progress)
+	}
+
+	os.Remove(failMarker)
+	opts.ResumeFrom = restoreErr.Applied
+	if err := restoreSQLChunks(filepath.Join(tempDir, "psql"), script, opts); err != nil {
+		t.Fatalf("resumed restoreSQLChunks() error = %v", err)
+	}
+
+	applied, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "TRUNCATE TABLE public.a CASCADE;\nCOPY public.a (id) FROM stdin;\nCOPY public.b (id) FROM stdin;\nSELECT pg_catalog.setval('public.a_id_seq', 1, true);\n"
+	if string(applied) != want {
+		t.Errorf("applied chunks = %q, want %q", applied, want)
+	}
+}
+
+func TestApplySQLFile_FailsOnPsqlExitCode(t *testing.T) {
+	testutil.NewFakeBin(t, "psql", testutil.Response{
+		Stderr: `psql:seed.sql:4: ERROR:  duplicate key value violates unique constraint "users_pkey"`,
+		Exit:   3,
+	})
+
+	err := ApplySQLFile("postgresql://postgres@localhost:5432/postgres", "seed.sql")
+	if err == nil || !strings.Contains(err.Error(), "duplicate key value") {
+		t.Errorf("ApplySQLFile() error = %v, want the psql error", err)
+	}
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Role is a Postgres role on a branch database.
+type Role struct {
+	Name       string
+	CanLogin   bool
+	Superuser  bool
+	BypassRLS  bool
+	ValidUntil string // empty when the password never expires
+	MemberOf   []string
+}
+
+// IsReserved reports whether the role is created and managed by Supabase.
+func (r Role) IsReserved() bool {
+	return reservedRoles[r.Name] || strings.HasPrefix(r.Name, "supabase_") || strings.HasPrefix(r.Name, "pg_")
+}
+
+// reservedRoles are the roles every Supabase project ships with. drift never
+// creates, alters, or drops them (postgres's password excepted).
+var reservedRoles = map[string]bool{
+	"anon":                       true,
+	"authenticated":              true,
+	"authenticator":              true,
+	"dashboard_user":             true,
+	"pgbouncer":                  true,
+	"pgsodium_keyholder":         true,
+	"pgsodium_keyiduser":         true,
+	"pgsodium_keymaker":          true,
+	"postgres":                   true,
+	"service_role":               true,
+	"supabase_admin":             true,
+	"supabase_auth_admin":        true,
+	"supabase_functions_admin":   true,
+	"supabase_read_only_user":    true,
+	"supabase_realtime_admin":    true,
+	"supabase_replication_admin": true,
+	"supabase_storage_admin":     true,
+}
+
+// FetchRoles lists the roles on the database, excluding pg_* built-ins,
+// sorted by name.
+func FetchRoles(opts RestoreOptions) ([]Role, error) {
+	rows, err := queryRows(opts, `SELECT r.rolname, r.rolcanlogin, r.rolsuper, r.rolbypassrls,
+       COALESCE(to_char(r.rolvaliduntil, 'YYYY-MM-DD'), ''),
+       COALESCE((SELECT string_agg(g.rolname, ',' ORDER BY g.rolname)
+                 FROM pg_auth_members m JOIN pg_roles g ON g.oid = m.roleid
+                 WHERE m.member = r.oid), '')
+FROM pg_roles r
+WHERE r.rolname NOT LIKE 'pg\_%'
+ORDER BY 1;`)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]Role, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 6 {
+			continue
+		}
+		role := Role{
+			Name:       row[0],
+			CanLogin:   row[1] == "t",
+			Superuser:  row[2] == "t",
+			BypassRLS:  row[3] == "t",
+			ValidUntil: row[4],
+		}
+		if row[5] != "" {
+			role.MemberOf = strings.Split(row[5], ",")
+		}
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// ReadOnlyRole describes a login role limited to reading the given schemas.
+type ReadOnlyRole struct {
+	Name       string
+	Password   string
+	Schemas    []string
+	ValidUntil string // timestamp, e.g. 2026-01-31; empty never expires
+	BypassRLS  bool   // read rows regardless of Row Level Security policies
+}
+
+// ValidateRoleName rejects names that are not plain lowercase identifiers or
+// that collide with roles Supabase manages.
+func ValidateRoleName(name string) error {
+	if name == "" || len(name) > 63 {
+		return fmt.Errorf("role name must be 1-63 characters")
+	}
+	for i, r := range name {
+		if !(r >= 'a' && r <= 'z' || r == '_' || (i > 0 && r >= '0' && r <= '9')) {
+			return fmt.Errorf("role name %q must be lowercase letters, digits, and underscores", name)
+		}
+	}
+	if (Role{Name: name}).IsReserved() {
+		return fmt.Errorf("%s is a Supabase-managed role", name)
+	}
+	return nil
+}
+
+// CreateReadOnlyRoleScript returns SQL that creates role with SELECT on every
+// existing and future table in its schemas and read-only transactions by
+// default.
+func CreateReadOnlyRoleScript(role ReadOnlyRole) string {
+	var b strings.Builder
+	attrs := "LOGIN NOSUPERUSER NOCREATEDB NOCREATEROLE NOREPLICATION"
+	if role.BypassRLS {
+		attrs += " BYPASSRLS"
+	}
+	fmt.Fprintf(&b, "CREATE ROLE %s WITH %s PASSWORD %s", quoteIdent(role.Name), attrs, quoteLiteral(role.Password))
+	if role.ValidUntil != "" {
+		fmt.Fprintf(&b, " VALID UNTIL %s", quoteLiteral(role.ValidUntil))
+	}
+	b.WriteString(";\n")
+	fmt.Fprintf(&b, "ALTER ROLE %s SET default_transaction_read_only = on;\n", quoteIdent(role.Name))
+	fmt.Fprintf(&b, "GRANT CONNECT ON DATABASE postgres TO %s;\n", quoteIdent(role.Name))
+	for _, schema := range role.Schemas {
+		s, r := quoteIdent(schema), quoteIdent(role.Name)
+		fmt.Fprintf(&b, "GRANT USAGE ON SCHEMA %s TO %s;\n", s, r)
+		fmt.Fprintf(&b, "GRANT SELECT ON ALL TABLES IN SCHEMA %s TO %s;\n", s, r)
+		fmt.Fprintf(&b, "ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT SELECT ON TABLES TO %s;\n", s, r)
+	}
+	return b.String()
+}
+
+// DropRoleScript returns SQL that revokes everything granted to role and
+// drops it.
+func DropRoleScript(name string) string {
+	r := quoteIdent(name)
+	return fmt.Sprintf("DROP OWNED BY %s;\nDROP ROLE %s;\n", r, r)
+}
+
+// SetPasswordScript returns SQL that changes role's password.
+func SetPasswordScript(name, password string) string {
+	return fmt.Sprintf("ALTER ROLE %s WITH PASSWORD %s;\n", quoteIdent(name), quoteLiteral(password))
+}
+
+// InTransaction wraps script so RunScript applies it in one transaction,
+// stopping and rolling back at the first error.
+func InTransaction(script string) string {
+	return "\\set ON_ERROR_STOP on\nBEGIN;\n" + script + "COMMIT;\n"
+}
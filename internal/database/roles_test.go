@@ -0,0 +1,78 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRoleName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"analyst", false},
+		{"metabase_ro2", false},
+		{"", true},
+		{"2fast", true},
+		{"Analyst", true},
+		{"drop-me", true},
+		{"a;b", true},
+		{strings.Repeat("a", 64), true},
+		{"postgres", true},
+		{"authenticated", true},
+		{"supabase_custom", true},
+		{"pg_monitor", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateRoleName(tt.name); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateRoleName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCreateReadOnlyRoleScript(t *testing.T) {
+	script := CreateReadOnlyRoleScript(ReadOnlyRole{
+		Name:       "analyst",
+		Password:   "it's secret",
+		Schemas:    []string{"public", "analytics"},
+		ValidUntil: "2026-01-31",
+	})
+
+	for _, want := range []string{
+		`CREATE ROLE "analyst" WITH LOGIN NOSUPERUSER NOCREATEDB NOCREATEROLE NOREPLICATION PASSWORD 'it''s secret' VALID UNTIL '2026-01-31';`,
+		`ALTER ROLE "analyst" SET default_transaction_read_only = on;`,
+		`GRANT USAGE ON SCHEMA "public" TO "analyst";`,
+		`GRANT SELECT ON ALL TABLES IN SCHEMA "analytics" TO "analyst";`,
+		`ALTER DEFAULT PRIVILEGES IN SCHEMA "analytics" GRANT SELECT ON TABLES TO "analyst";`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "BYPASSRLS") {
+		t.Errorf("script grants BYPASSRLS without BypassRLS:\n%s", script)
+	}
+
+	script = CreateReadOnlyRoleScript(ReadOnlyRole{Name: "bi", Password: "x", Schemas: []string{"public"}, BypassRLS: true})
+	if !strings.Contains(script, "NOREPLICATION BYPASSRLS PASSWORD") {
+		t.Errorf("script missing BYPASSRLS:\n%s", script)
+	}
+	if strings.Contains(script, "VALID UNTIL") {
+		t.Errorf("script sets VALID UNTIL without ValidUntil:\n%s", script)
+	}
+}
+
+func TestDropRoleScript(t *testing.T) {
+	want := "DROP OWNED BY \"analyst\";\nDROP ROLE \"analyst\";\n"
+	if got := DropRoleScript("analyst"); got != want {
+		t.Errorf("DropRoleScript() = %q, want %q", got, want)
+	}
+}
+
+func TestInTransaction(t *testing.T) {
+	want := "\\set ON_ERROR_STOP on\nBEGIN;\nDROP ROLE \"bi\";\nCOMMIT;\n"
+	if got := InTransaction("DROP ROLE \"bi\";\n"); got != want {
+		t.Errorf("InTransaction() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TableRows is the row count of one user table, and optionally a checksum
+// of its primary keys.
+type TableRows struct {
+	Table      string   // schema-qualified table
+	PrimaryKey []string // primary key columns, in key order; empty when there is none
+	Rows       int64    // exact count, or the planner estimate (-1 when never analyzed)
+	Checksum   string   // md5 of the ordered primary keys; empty when not computed
+}
+
+// RowCountDiff compares one table's rows across two databases. Missing
+// reports which side lacks the table ("here" or "other").
+type RowCountDiff struct {
+	Table         string  `json:"table"`
+	Rows          int64   `json:"rows"`
+	OtherRows     int64   `json:"other_rows"`
+	Delta         int64   `json:"delta"`
+	Percent       float64 `json:"percent"`
+	Checksum      string  `json:"checksum,omitempty"`
+	OtherChecksum string  `json:"other_checksum,omitempty"`
+	Missing       string  `json:"missing,omitempty"`
+}
+
+// keySeparator joins primary key column names in query output. Column names
+// may contain commas; they do not contain control characters in practice.
+const keySeparator = "\x1f"
+
+// FetchTableRows lists user tables with their planner row estimates and
+// primary keys, sorted by name.
+func FetchTableRows(opts RestoreOptions) ([]TableRows, error) {
+	rows, err := queryRows(opts, `SELECT n.nspname || '.' || c.relname, c.reltuples::bigint,
+  coalesce((SELECT string_agg(a.attname, E'\x1f' ORDER BY array_position(i.indkey::int2[], a.attnum))
+    FROM pg_index i
+    JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+    WHERE i.indrelid = c.oid AND i.indisprimary), '')
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p') AND NOT c.relispartition AND `+userSchemaFilter("n.nspname")+`
+ORDER BY 1;`)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]TableRows, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		estimate, _ := strconv.ParseInt(row[1], 10, 64)
+		t := TableRows{Table: row[0], Rows: estimate}
+		if len(row) > 2 && row[2] != "" {
+			t.PrimaryKey = strings.Split(row[2], keySeparator)
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// CountRows replaces the estimates in tables with exact counts, using one
+// query for all of them.
+func CountRows(opts RestoreOptions, tables []TableRows) error {
+	if len(tables) == 0 {
+		return nil
+	}
+	rows, err := queryRows(opts, rowCountQuery(tables))
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		if len(row) == 2 {
+			counts[row[0]], _ = strconv.ParseInt(row[1], 10, 64)
+		}
+	}
+	for i := range tables {
+		if n, ok := counts[tables[i].Table]; ok {
+			tables[i].Rows = n
+		}
+	}
+	return nil
+}
+
+// ChecksumKeys sets the checksum of every table with a primary key: the md5
+// of its key values in key order. Equal checksums mean both sides hold the
+// same set of rows, though not necessarily the same column values.
+func ChecksumKeys(opts RestoreOptions, tables []TableRows) error {
+	query := keyChecksumQuery(tables)
+	if query == "" {
+		return nil
+	}
+	rows, err := queryRows(opts, query)
+	if err != nil {
+		return err
+	}
+	sums := make(map[string]string, len(rows))
+	for _, row := range rows {
+		if len(row) == 2 {
+			sums[row[0]] = row[1]
+		}
+	}
+	for i := range tables {
+		tables[i].Checksum = sums[tables[i].Table]
+	}
+	return nil
+}
+
+func rowCountQuery(tables []TableRows) string {
+	parts := make([]string, len(tables))
+	for i, t := range tables {
+		parts[i] = fmt.Sprintf("SELECT %s, (SELECT count(*) FROM %s)", quoteLiteral(t.Table), quoteQualified(t.Table))
+	}
+	return strings.Join(parts, "\nUNION ALL ") + ";"
+}
+
+// keyChecksumQuery returns a query yielding (table, checksum) for every
+// table with a primary key, or "" when none has one.
+func keyChecksumQuery(tables []TableRows) string {
+	var parts []string
+	for _, t := range tables {
+		if len(t.PrimaryKey) == 0 {
+			continue
+		}
+		key := quoteColumns(t.PrimaryKey)
+		parts = append(parts, fmt.Sprintf(
+			"SELECT %s, (SELECT md5(coalesce(string_agg(row(%s)::text, ',' ORDER BY %s), '')) FROM %s)",
+			quoteLiteral(t.Table), key, key, quoteQualified(t.Table)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\nUNION ALL ") + ";"
+}
+
+// CompareRowCounts pairs up the tables of two databases, sorted by name.
+// Percent is the delta relative to the other side's count.
+func CompareRowCounts(tables, other []TableRows) []RowCountDiff {
+	byName := make(map[string]TableRows, len(other))
+	for _, t := range other {
+		byName[t.Table] = t
+	}
+	seen := make(map[string]bool, len(tables))
+
+	var diffs []RowCountDiff
+	for _, t := range tables {
+		seen[t.Table] = true
+		o, ok := byName[t.Table]
+		if !ok {
+			diffs = append(diffs, RowCountDiff{Table: t.Table, Rows: t.Rows, Checksum: t.Checksum, Missing: "other"})
+			continue
+		}
+		d := RowCountDiff{
+			Table:         t.Table,
+			Rows:          t.Rows,
+			OtherRows:     o.Rows,
+			Delta:         t.Rows - o.Rows,
+			Checksum:      t.Checksum,
+			OtherChecksum: o.Checksum,
+		}
+		switch {
+		case o.Rows > 0:
+			d.Percent = float64(d.Delta) / float64(o.Rows) * 100
+		case d.Delta > 0:
+			d.Percent = 100
+		}
+		diffs = append(diffs, d)
+	}
+	for _, o := range other {
+		if !seen[o.Table] {
+			diffs = append(diffs, RowCountDiff{Table: o.Table, OtherRows: o.Rows, OtherChecksum: o.Checksum, Missing: "here"})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Table < diffs[j].Table })
+	return diffs
+}
+
+// ChecksumsDiffer reports whether both sides have a checksum and they differ.
+func (d RowCountDiff) ChecksumsDiffer() bool {
+	return d.Checksum != "" && d.OtherChecksum != "" && d.Checksum != d.OtherChecksum
+}
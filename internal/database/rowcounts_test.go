@@ -0,0 +1,68 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareRowCounts(t *testing.T) {
+	dev := []TableRows{
+		{Table: "public.orders", Rows: 80, Checksum: "a"},
+		{Table: "public.profiles", Rows: 100, Checksum: "b"},
+		{Table: "public.scratch", Rows: 5},
+		{Table: "public.tags", Rows: 3},
+	}
+	prod := []TableRows{
+		{Table: "public.audit", Rows: 10},
+		{Table: "public.orders", Rows: 100, Checksum: "c"},
+		{Table: "public.profiles", Rows: 100, Checksum: "b"},
+		{Table: "public.tags", Rows: 0},
+	}
+
+	got := CompareRowCounts(dev, prod)
+	want := []RowCountDiff{
+		{Table: "public.audit", OtherRows: 10, Missing: "here"},
+		{Table: "public.orders", Rows: 80, OtherRows: 100, Delta: -20, Percent: -20, Checksum: "a", OtherChecksum: "c"},
+		{Table: "public.profiles", Rows: 100, OtherRows: 100, Checksum: "b", OtherChecksum: "b"},
+		{Table: "public.scratch", Rows: 5, Missing: "other"},
+		{Table: "public.tags", Rows: 3, Delta: 3, Percent: 100},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareRowCounts() = %+v, want %+v", got, want)
+	}
+
+	if !got[1].ChecksumsDiffer() {
+		t.Error("ChecksumsDiffer() = false for public.orders")
+	}
+	if got[2].ChecksumsDiffer() || got[4].ChecksumsDiffer() {
+		t.Error("ChecksumsDiffer() = true for matching or missing checksums")
+	}
+}
+
+func TestRowCountQuery(t *testing.T) {
+	got := rowCountQuery([]TableRows{{Table: "public.users"}, {Table: `app.Order"s`}})
+	want := `SELECT 'public.users', (SELECT count(*) FROM "public"."users")
+UNION ALL SELECT 'app.Order"s', (SELECT count(*) FROM "app"."Order""s");`
+	if got != want {
+		t.Errorf("rowCountQuery() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestKeyChecksumQuery(t *testing.T) {
+	if got := keyChecksumQuery([]TableRows{{Table: "public.logs"}}); got != "" {
+		t.Errorf("keyChecksumQuery() without keys = %q, want empty", got)
+	}
+
+	got := keyChecksumQuery([]TableRows{
+		{Table: "public.logs"},
+		{Table: "public.members", PrimaryKey: []string{"org_id", "user_id"}},
+	})
+	want := `SELECT 'public.members', (SELECT md5(coalesce(string_agg(row("org_id", "user_id")::text, ',' ORDER BY "org_id", "user_id"), '')) FROM "public"."members");`
+	if got != want {
+		t.Errorf("keyChecksumQuery() =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(got, "logs") {
+		t.Error("keyChecksumQuery() includes a table without a primary key")
+	}
+}
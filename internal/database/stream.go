@@ -0,0 +1,225 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// StreamOptions holds options for copying data straight from one database
+// into another.
+type StreamOptions struct {
+	// Source is the pg_dump connection. OutputFile and Format are ignored.
+	Source DumpOptions
+	// Target is the psql connection and copy scope. InputFile is ignored.
+	Target RestoreOptions
+	// Progress, if set, is called periodically with the bytes read from
+	// pg_dump so far and the table being copied.
+	Progress func(bytes int64, table string)
+}
+
+// safeScopeSchemas are the schemas dumped for a safe-scope stream; nothing
+// outside them survives restore filtering anyway.
+var safeScopeSchemas = []string{"public", "auth", "supabase_migrations"}
+
+// StreamRestore pipes a data-only pg_dump of the source into psql on the
+// target, with the same filtering Restore applies to a plain SQL backup, so
+// nothing is written to disk.
+func StreamRestore(opts StreamOptions) error {
+	pgDump, err := findPGTool("pg_dump")
+	if err != nil {
+		return err
+	}
+	psql, err := findPGTool("psql")
+	if err != nil {
+		return err
+	}
+
+	target := opts.Target
+	allowedAuthTables := map[string]bool{}
+	allowedAllTables := map[string]bool{}
+	if target.CopyAllInsertableTables {
+		allowedAllTables, err = resolveAllInsertableCopyTables(target)
+		if err != nil {
+			return fmt.Errorf("failed to resolve insertable table scope: %w", err)
+		}
+	} else {
+		allowedAuthTables, err = resolveAllowedAuthCopyTables(target)
+		if err != nil {
+			return fmt.Errorf("failed to resolve auth copy tables: %w", err)
+		}
+	}
+
+	// The dump arrives in one pass, so the tables to truncate come from the
+	// source catalog instead of a first pass over a file.
+	excludedTables := excludedTableSet(target.ExcludeTables)
+	tablesToCopy, err := sourceCopyTables(opts.Source, allowedAuthTables, allowedAllTables, excludedTables)
+	if err != nil {
+		return fmt.Errorf("failed to list source tables: %w", err)
+	}
+
+	dumpArgs := []string{
+		"-h", opts.Source.Host,
+		"-p", fmt.Sprintf("%d", opts.Source.Port),
+		"-U", opts.Source.User,
+		"-d", opts.Source.Database,
+		"-F", "p",
+		"--data-only",
+		"-O", "-x",
+	}
+	if !target.CopyAllInsertableTables {
+		for _, schema := range safeScopeSchemas {
+			dumpArgs = append(dumpArgs, "-n", schema)
+		}
+	}
+	dumpArgs = append(dumpArgs, excludeTableDataArgs(append(opts.Source.ExcludeTableData, target.ExcludeTables...))...)
+	dump := exec.Command(pgDump, dumpArgs...)
+	dump.Env = append(os.Environ(), "PGPASSWORD="+opts.Source.Password)
+	var dumpStderr bytes.Buffer
+	dump.Stderr = &dumpStderr
+	dumpOut, err := dump.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	restoreArgs := []string{
+		"-h", target.Host,
+		"-p", fmt.Sprintf("%d", target.Port),
+		"-U", target.User,
+		"-d", target.Database,
+		"-q",
+		"-v", "ON_ERROR_STOP=1",
+		"-f", "-",
+	}
+	if target.SingleTxn {
+		restoreArgs = append(restoreArgs, "-1")
+	}
+	restore := exec.Command(psql, restoreArgs...)
+	restore.Env = append(os.Environ(), "PGPASSWORD="+target.Password)
+	restore.Stdout = io.Discard
+	var restoreStderr bytes.Buffer
+	restore.Stderr = &restoreStderr
+	restoreIn, err := restore.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := restore.Start(); err != nil {
+		return fmt.Errorf("failed to start psql: %w", err)
+	}
+	if err := dump.Start(); err != nil {
+		restore.Process.Kill()
+		restore.Wait()
+		return fmt.Errorf("failed to start pg_dump: %w", err)
+	}
+
+	reader := &progressReader{r: dumpOut, fn: opts.Progress}
+	filterErr := filterRestoreStream(restoreIn, reader, tablesToCopy, allowedAuthTables, allowedAllTables, excludedTables, reader.setTable)
+	if filterErr != nil {
+		dump.Process.Kill()
+	}
+	dumpErr := dump.Wait()
+
+	if filterErr != nil || dumpErr != nil {
+		// psql must never see EOF after a partial stream: in a single
+		// transaction it would commit whatever it had received.
+		restore.Process.Kill()
+		restore.Wait()
+		if msg := strings.TrimSpace(restoreStderr.String()); msg != "" {
+			return fmt.Errorf("psql restore failed: %s", msg)
+		}
+		if dumpErr != nil {
+			msg := strings.TrimSpace(dumpStderr.String())
+			if msg == "" {
+				msg = dumpErr.Error()
+			}
+			return pgDumpError(msg, opts.Source)
+		}
+		return filterErr
+	}
+
+	restoreIn.Close()
+	if err := restore.Wait(); err != nil {
+		msg := strings.TrimSpace(restoreStderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("psql restore failed: %s", msg)
+	}
+	reader.report(true)
+	return nil
+}
+
+// sourceCopyTables lists the source tables that pg_dump will COPY and the
+// restore scope keeps, in the order a file-based restore would truncate them.
+func sourceCopyTables(source DumpOptions, allowedAuthCopyTables, allowedAllTables, excludedTables map[string]bool) ([]string, error) {
+	rows, err := queryRows(RestoreOptions{
+		Host:     source.Host,
+		Port:     source.Port,
+		Database: source.Database,
+		User:     source.User,
+		Password: source.Password,
+	}, `SELECT format('%I.%I', n.nspname, c.relname)
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind = 'r'
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname NOT LIKE 'pg_toast%'
+  AND n.nspname NOT LIKE 'pg_temp_%'
+ORDER BY n.nspname, c.relname;`)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []string
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		table := normalizeQualifiedName(row[0])
+		if !excludedTables[table] && isAllowedCopyTable(table, allowedAuthCopyTables, allowedAllTables) {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// progressReader counts bytes read and reports them at most every
+// progressInterval.
+type progressReader struct {
+	r     io.Reader
+	fn    func(bytes int64, table string)
+	n     int64
+	table string
+	last  time.Time
+}
+
+const progressInterval = 250 * time.Millisecond
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+	p.report(false)
+	return n, err
+}
+
+func (p *progressReader) setTable(table string) {
+	p.table = table
+	p.report(true)
+}
+
+func (p *progressReader) report(force bool) {
+	if p.fn == nil {
+		return
+	}
+	now := time.Now()
+	if !force && now.Sub(p.last) < progressInterval {
+		return
+	}
+	p.last = now
+	p.fn(p.n, p.table)
+}
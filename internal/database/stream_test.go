@@ -0,0 +1,79 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterRestoreStream(t *testing.T) {
+	input := strings.Join([]string{
+		"\\restrict abc123",
+		"COPY public.profiles (id, name) FROM stdin;",
+		"1\tAda",
+		"\\.",
+		"COPY storage.objects (id) FROM stdin;",
+		"obj-1",
+		"\\.",
+		"SELECT pg_catalog.setval('public.profiles_id_seq', 1, true);",
+		"COPY supabase_migrations.schema_migrations (version) FROM stdin;",
+		"20260101000000",
+		"\\.",
+		"",
+	}, "\n")
+
+	var out strings.Builder
+	var started []string
+	tables := []string{"public.profiles", "supabase_migrations.schema_migrations"}
+	err := filterRestoreStream(&out, strings.NewReader(input), tables, allowedAuthTablesForTest(), nil, nil, func(table string) {
+		started = append(started, table)
+	})
+	if err != nil {
+		t.Fatalf("filterRestoreStream() error = %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"SET session_replication_role = replica;",
+		"TRUNCATE TABLE public.profiles CASCADE;\nTRUNCATE TABLE supabase_migrations.schema_migrations CASCADE;",
+		"1\tAda",
+		"SELECT pg_catalog.setval('public.profiles_id_seq', 1, true);",
+		"SET session_replication_role = DEFAULT;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "obj-1") || strings.Contains(got, "\\restrict") {
+		t.Errorf("output kept filtered content:\n%s", got)
+	}
+	if want := tables; !reflect.DeepEqual(started, want) {
+		t.Errorf("onTable calls = %v, want %v", started, want)
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	var reports []int64
+	var lastTable string
+	p := &progressReader{r: strings.NewReader("0123456789"), fn: func(n int64, table string) {
+		reports = append(reports, n)
+		lastTable = table
+	}}
+
+	buf := make([]byte, 4)
+	for {
+		if _, err := p.Read(buf); err != nil {
+			break
+		}
+	}
+	p.setTable("public.profiles")
+
+	if p.n != 10 {
+		t.Errorf("bytes read = %d, want 10", p.n)
+	}
+	// The first read reports immediately, the rest are throttled until the
+	// forced report from setTable.
+	if len(reports) != 2 || reports[len(reports)-1] != 10 || lastTable != "public.profiles" {
+		t.Errorf("reports = %v (table %q), want [4 10] with public.profiles", reports, lastTable)
+	}
+}
@@ -0,0 +1,414 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// ForeignKey describes one FK constraint between two schema-qualified tables.
+type ForeignKey struct {
+	ChildTable    string
+	ChildColumns  []string
+	ParentTable   string
+	ParentColumns []string
+}
+
+// SubsetSelection picks the root rows of a subset.
+type SubsetSelection struct {
+	Limit   int     // maximum root rows (0 = no limit)
+	Percent float64 // sample percentage of the root table (0 = no sampling)
+	Where   string  // optional SQL filter on the root table
+}
+
+// SubsetPlan is the traversal order used to collect a referentially consistent subset.
+//
+// Rows are gathered in two phases: Descend walks from the root to every table
+// that (transitively) references it, pulling in rows that point at rows
+// already selected. Ascend then walks each FK from a selected table to its
+// parent so every referenced row is present too.
+type SubsetPlan struct {
+	Root    string
+	Tables  []string     // every table that may receive rows, root first
+	Descend []ForeignKey // child-of-selected edges, in BFS order from the root
+	Ascend  []ForeignKey // parent-of-selected edges, children before parents
+}
+
+// maxAscendPasses bounds the parent closure for cyclic or self-referencing FKs.
+const maxAscendPasses = 5
+
+// PlanSubset builds a traversal plan rooted at root. FKs touching a table in
+// exclude are ignored.
+func PlanSubset(root string, fks []ForeignKey, exclude []string) SubsetPlan {
+	skip := make(map[string]bool, len(exclude))
+	for _, t := range exclude {
+		skip[t] = true
+	}
+
+	var edges []ForeignKey
+	for _, fk := range fks {
+		if skip[fk.ChildTable] || skip[fk.ParentTable] {
+			continue
+		}
+		edges = append(edges, fk)
+	}
+
+	plan := SubsetPlan{Root: root}
+	seen := map[string]bool{root: true}
+	plan.Tables = append(plan.Tables, root)
+
+	// Descend: BFS over "who references me".
+	queue := []string{root}
+	usedDescend := make(map[int]bool)
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for i, fk := range edges {
+			if fk.ParentTable != parent || fk.ChildTable == parent || usedDescend[i] {
+				continue
+			}
+			usedDescend[i] = true
+			plan.Descend = append(plan.Descend, fk)
+			if !seen[fk.ChildTable] {
+				seen[fk.ChildTable] = true
+				plan.Tables = append(plan.Tables, fk.ChildTable)
+				queue = append(queue, fk.ChildTable)
+			}
+		}
+	}
+
+	// Ascend: BFS over "who do I reference", starting from every descended table.
+	queue = append([]string{}, plan.Tables...)
+	usedAscend := make(map[int]bool)
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = queue[1:]
+		for i, fk := range edges {
+			if fk.ChildTable != child || usedAscend[i] {
+				continue
+			}
+			usedAscend[i] = true
+			plan.Ascend = append(plan.Ascend, fk)
+			if !seen[fk.ParentTable] {
+				seen[fk.ParentTable] = true
+				plan.Tables = append(plan.Tables, fk.ParentTable)
+				queue = append(queue, fk.ParentTable)
+			}
+		}
+	}
+
+	return plan
+}
+
+// subsetTempTable returns the temp table holding selected row ids for table i.
+func subsetTempTable(i int) string {
+	return fmt.Sprintf("_drift_subset_%d", i)
+}
+
+func (p SubsetPlan) tableIndex(table string) int {
+	for i, t := range p.Tables {
+		if t == table {
+			return i
+		}
+	}
+	return -1
+}
+
+// ExtractScript returns a psql script that collects the subset on the source
+// database in temp tables, prints a "table|rows" line per table, and, when
+// dir is non-empty, exports each table to <dir>/<index>.csv.
+// columns maps each table to the columns to export.
+func (p SubsetPlan) ExtractScript(sel SubsetSelection, columns map[string][]string, dir string) string {
+	var b strings.Builder
+	b.WriteString("\\set ON_ERROR_STOP on\n")
+	b.WriteString("BEGIN;\n")
+	for i := range p.Tables {
+		fmt.Fprintf(&b, "CREATE TEMP TABLE %s (rid tid PRIMARY KEY) ON COMMIT DROP;\n", subsetTempTable(i))
+	}
+
+	// Root rows.
+	root := quoteQualified(p.Root)
+	from := root
+	if sel.Percent > 0 {
+		from += " TABLESAMPLE BERNOULLI (" + strconv.FormatFloat(sel.Percent, 'f', -1, 64) + ")"
+	}
+	fmt.Fprintf(&b, "INSERT INTO %s SELECT ctid FROM %s", subsetTempTable(0), from)
+	if strings.TrimSpace(sel.Where) != "" {
+		fmt.Fprintf(&b, " WHERE %s", sel.Where)
+	}
+	if sel.Limit > 0 {
+		fmt.Fprintf(&b, " ORDER BY random() LIMIT %d", sel.Limit)
+	}
+	b.WriteString(";\n")
+
+	for _, fk := range p.Descend {
+		b.WriteString(p.edgeInsert(fk, false))
+	}
+	for pass := 0; pass < maxAscendPasses; pass++ {
+		for _, fk := range p.Ascend {
+			b.WriteString(p.edgeInsert(fk, true))
+		}
+	}
+
+	for i, t := range p.Tables {
+		fmt.Fprintf(&b, "SELECT %s, count(*) FROM %s;\n", quoteLiteral(t), subsetTempTable(i))
+	}
+
+	if dir != "" {
+		for i, t := range p.Tables {
+			cols := quoteColumns(columns[t])
+			file := filepath.Join(dir, fmt.Sprintf("%d.csv", i))
+			fmt.Fprintf(&b, "\\copy (SELECT %s FROM %s WHERE ctid IN (SELECT rid FROM %s)) TO %s WITH (FORMAT csv)\n",
+				cols, quoteQualified(t), subsetTempTable(i), quoteLiteral(file))
+		}
+	}
+
+	b.WriteString("COMMIT;\n")
+	return b.String()
+}
+
+// edgeInsert adds rows reachable over fk to the destination temp table.
+// When ascending, parents of selected children are added; otherwise children
+// of selected parents are added.
+func (p SubsetPlan) edgeInsert(fk ForeignKey, ascend bool) string {
+	child, parent := p.tableIndex(fk.ChildTable), p.tableIndex(fk.ParentTable)
+	childCols := qualifyColumns("c", fk.ChildColumns)
+	parentCols := qualifyColumns("p", fk.ParentColumns)
+
+	if ascend {
+		return fmt.Sprintf(
+			"INSERT INTO %s SELECT p.ctid FROM %s p WHERE (%s) IN (SELECT %s FROM %s c WHERE c.ctid IN (SELECT rid FROM %s)) ON CONFLICT DO NOTHING;\n",
+			subsetTempTable(parent), quoteQualified(fk.ParentTable), parentCols, childCols,
+			quoteQualified(fk.ChildTable), subsetTempTable(child))
+	}
+	return fmt.Sprintf(
+		"INSERT INTO %s SELECT c.ctid FROM %s c WHERE (%s) IN (SELECT %s FROM %s p WHERE p.ctid IN (SELECT rid FROM %s)) ON CONFLICT DO NOTHING;\n",
+		subsetTempTable(child), quoteQualified(fk.ChildTable), childCols, parentCols,
+		quoteQualified(fk.ParentTable), subsetTempTable(parent))
+}
+
+// LoadScript returns a psql script that inserts the CSVs written by
+// ExtractScript into the target, skipping rows whose keys already exist.
+// FK triggers are disabled for the session so load order does not matter.
+func (p SubsetPlan) LoadScript(columns map[string][]string, dir string) string {
+	var b strings.Builder
+	b.WriteString("\\set ON_ERROR_STOP on\n")
+	b.WriteString("BEGIN;\n")
+	b.WriteString("SET LOCAL session_replication_role = replica;\n")
+
+	for j, t := range p.Tables {
+		cols := quoteColumns(columns[t])
+		file := filepath.Join(dir, fmt.Sprintf("%d.csv", j))
+		tmp := subsetTempTable(j)
+		fmt.Fprintf(&b, "CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA;\n", tmp, cols, quoteQualified(t))
+		fmt.Fprintf(&b, "\\copy %s FROM %s WITH (FORMAT csv)\n", tmp, quoteLiteral(file))
+		fmt.Fprintf(&b, "INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT %s FROM %s ON CONFLICT DO NOTHING;\n",
+			quoteQualified(t), cols, cols, tmp)
+	}
+
+	b.WriteString("COMMIT;\n")
+	return b.String()
+}
+
+// ParseSubsetCounts parses the "table|rows" lines printed by ExtractScript.
+func ParseSubsetCounts(output string) map[string]int {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) != 2 {
+			continue
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		counts[parts[0]] = n
+	}
+	return counts
+}
+
+// FetchForeignKeys lists FK constraints between tables in the given schemas.
+func FetchForeignKeys(opts RestoreOptions, schemas []string) ([]ForeignKey, error) {
+	query := fmt.Sprintf(`SELECT
+  cn.nspname || '.' || cl.relname,
+  array_to_string(ARRAY(SELECT a.attname FROM unnest(con.conkey) WITH ORDINALITY k(n, i) JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.n ORDER BY k.i), ','),
+  pn.nspname || '.' || pl.relname,
+  array_to_string(ARRAY(SELECT a.attname FROM unnest(con.confkey) WITH ORDINALITY k(n, i) JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.n ORDER BY k.i), ',')
+FROM pg_constraint con
+JOIN pg_class cl ON cl.oid = con.conrelid
+JOIN pg_namespace cn ON cn.oid = cl.relnamespace
+JOIN pg_class pl ON pl.oid = con.confrelid
+JOIN pg_namespace pn ON pn.oid = pl.relnamespace
+WHERE con.contype = 'f' AND cn.nspname IN (%s) AND pn.nspname IN (%s)
+ORDER BY 1, 3;`, quoteLiteralList(schemas), quoteLiteralList(schemas))
+
+	rows, err := queryRows(opts, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var fks []ForeignKey
+	for _, row := range rows {
+		if len(row) != 4 {
+			continue
+		}
+		fks = append(fks, ForeignKey{
+			ChildTable:    row[0],
+			ChildColumns:  strings.Split(row[1], ","),
+			ParentTable:   row[2],
+			ParentColumns: strings.Split(row[3], ","),
+		})
+	}
+	return fks, nil
+}
+
+// FetchInsertableColumns returns the non-generated columns of each table, in order.
+func FetchInsertableColumns(opts RestoreOptions, tables []string) (map[string][]string, error) {
+	query := fmt.Sprintf(`SELECT n.nspname || '.' || c.relname, a.attname
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE a.attnum > 0 AND NOT a.attisdropped AND a.attgenerated = ''
+  AND n.nspname || '.' || c.relname IN (%s)
+ORDER BY 1, a.attnum;`, quoteLiteralList(tables))
+
+	rows, err := queryRows(opts, query)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string][]string)
+	for _, row := range rows {
+		if len(row) == 2 {
+			columns[row[0]] = append(columns[row[0]], row[1])
+		}
+	}
+	for _, t := range tables {
+		if len(columns[t]) == 0 {
+			return nil, fmt.Errorf("table %s not found", t)
+		}
+	}
+	return columns, nil
+}
+
+// RunScript runs a psql script file against the database and returns its
+// output. A non-zero psql exit, as after an error under ON_ERROR_STOP, is an
+// error. The script goes through a private temp file, so passwords in it
+// never appear in process arguments or verbose logs.
+func RunScript(opts RestoreOptions, script string) (*shell.Result, error) {
+	psql, err := findPGTool("psql")
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.CreateTemp("", "drift-*.sql")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(script); err != nil {
+		f.Close()
+		return nil, err
+	}
+	f.Close()
+
+	env := map[string]string{"PGPASSWORD": opts.Password}
+	result, err := shell.RunWithEnv(env, psql,
+		"-h", opts.Host,
+		"-p", fmt.Sprintf("%d", opts.Port),
+		"-U", opts.User,
+		"-d", opts.Database,
+		"-t", "-A", "-q",
+		"-f", f.Name(),
+	)
+	if err != nil || result.ExitCode != 0 {
+		return result, fmt.Errorf("psql script failed: %s", psqlFailure(result, err))
+	}
+	return result, nil
+}
+
+// queryRows runs a query and splits unaligned output into fields.
+func queryRows(opts RestoreOptions, query string) ([][]string, error) {
+	psql, err := findPGTool("psql")
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{"PGPASSWORD": opts.Password}
+	result, err := shell.RunWithEnv(env, psql,
+		"-h", opts.Host,
+		"-p", fmt.Sprintf("%d", opts.Port),
+		"-U", opts.User,
+		"-d", opts.Database,
+		"-t", "-A", "-F", "\t",
+		"-c", query,
+	)
+	if err != nil || result.ExitCode != 0 {
+		return nil, fmt.Errorf("query failed: %s", psqlFailure(result, err))
+	}
+
+	var rows [][]string
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		rows = append(rows, strings.Split(line, "\t"))
+	}
+	return rows, nil
+}
+
+// NormalizeTableName qualifies a bare table name with the public schema.
+func NormalizeTableName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return "public." + name
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteQualified(table string) string {
+	schema, name, ok := strings.Cut(table, ".")
+	if !ok {
+		return quoteIdent(table)
+	}
+	return quoteIdent(schema) + "." + quoteIdent(name)
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func quoteLiteralList(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	quoted := make([]string, len(sorted))
+	for i, v := range sorted {
+		quoted[i] = quoteLiteral(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func quoteColumns(cols []string) string {
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = quoteIdent(c)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func qualifyColumns(alias string, cols []string) string {
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = alias + "." + quoteIdent(c)
+	}
+	return strings.Join(quoted, ", ")
+}
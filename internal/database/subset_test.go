@@ -0,0 +1,128 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+func subsetFixtureFKs() []ForeignKey {
+	return []ForeignKey{
+		{ChildTable: "public.posts", ChildColumns: []string{"author_id"}, ParentTable: "public.users", ParentColumns: []string{"id"}},
+		{ChildTable: "public.comments", ChildColumns: []string{"post_id"}, ParentTable: "public.posts", ParentColumns: []string{"id"}},
+		{ChildTable: "public.comments", ChildColumns: []string{"user_id"}, ParentTable: "public.users", ParentColumns: []string{"id"}},
+		{ChildTable: "public.users", ChildColumns: []string{"org_id"}, ParentTable: "public.orgs", ParentColumns: []string{"id"}},
+		{ChildTable: "public.audit_log", ChildColumns: []string{"user_id"}, ParentTable: "public.users", ParentColumns: []string{"id"}},
+		{ChildTable: "public.users", ChildColumns: []string{"invited_by"}, ParentTable: "public.users", ParentColumns: []string{"id"}},
+	}
+}
+
+func TestPlanSubset(t *testing.T) {
+	plan := PlanSubset("public.users", subsetFixtureFKs(), []string{"public.audit_log"})
+
+	wantTables := []string{"public.users", "public.posts", "public.comments", "public.orgs"}
+	if !reflect.DeepEqual(plan.Tables, wantTables) {
+		t.Errorf("Tables = %v, want %v", plan.Tables, wantTables)
+	}
+	if len(plan.Descend) != 3 {
+		t.Errorf("Descend has %d edges, want 3: %v", len(plan.Descend), plan.Descend)
+	}
+	for _, fk := range append(plan.Descend, plan.Ascend...) {
+		if fk.ChildTable == "public.audit_log" {
+			t.Errorf("excluded table in plan: %v", fk)
+		}
+	}
+
+	var selfRef bool
+	for _, fk := range plan.Ascend {
+		if fk.ChildTable == "public.users" && fk.ParentTable == "public.users" {
+			selfRef = true
+		}
+	}
+	if !selfRef {
+		t.Error("self-referencing FK missing from Ascend")
+	}
+}
+
+func TestSubsetPlanExtractScript(t *testing.T) {
+	plan := PlanSubset("public.users", subsetFixtureFKs(), nil)
+	columns := map[string][]string{"public.users": {"id", "org_id"}}
+	script := plan.ExtractScript(SubsetSelection{Limit: 100, Where: "deleted_at IS NULL"}, columns, "/tmp/out")
+
+	for _, want := range []string{
+		`INSERT INTO _drift_subset_0 SELECT ctid FROM "public"."users" WHERE deleted_at IS NULL ORDER BY random() LIMIT 100;`,
+		`INSERT INTO _drift_subset_1 SELECT c.ctid FROM "public"."posts" c WHERE (c."author_id") IN (SELECT p."id" FROM "public"."users" p`,
+		`SELECT 'public.users', count(*) FROM _drift_subset_0;`,
+		`\copy (SELECT "id", "org_id" FROM "public"."users" WHERE ctid IN (SELECT rid FROM _drift_subset_0)) TO '/tmp/out/0.csv' WITH (FORMAT csv)`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q\n%s", want, script)
+		}
+	}
+
+	dry := plan.ExtractScript(SubsetSelection{Percent: 2.5}, columns, "")
+	if !strings.Contains(dry, "TABLESAMPLE BERNOULLI (2.5)") {
+		t.Errorf("percent selection missing TABLESAMPLE:\n%s", dry)
+	}
+	if strings.Contains(dry, `\copy`) {
+		t.Error("dry-run script should not export rows")
+	}
+}
+
+func TestSubsetPlanLoadScript(t *testing.T) {
+	plan := PlanSubset("public.users", subsetFixtureFKs()[:1], nil)
+	columns := map[string][]string{
+		"public.users": {"id"},
+		"public.posts": {"id", "author_id"},
+	}
+	script := plan.LoadScript(columns, "/tmp/out")
+
+	if !strings.Contains(script, "SET LOCAL session_replication_role = replica;") {
+		t.Error("load script should disable FK triggers")
+	}
+	users := strings.Index(script, `INSERT INTO "public"."users"`)
+	posts := strings.Index(script, `INSERT INTO "public"."posts" ("id", "author_id") OVERRIDING SYSTEM VALUE`)
+	if users < 0 || posts < 0 {
+		t.Fatalf("load script missing inserts:\n%s", script)
+	}
+}
+
+func TestParseSubsetCounts(t *testing.T) {
+	got := ParseSubsetCounts("public.users|100\npublic.posts|420\nnoise\n")
+	want := map[string]int{"public.users": 100, "public.posts": 420}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSubsetCounts() = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeTableName(t *testing.T) {
+	tests := map[string]string{
+		"users":      "public.users",
+		"auth.users": "auth.users",
+		" profiles ": "public.profiles",
+		"":           "",
+	}
+	for in, want := range tests {
+		if got := NormalizeTableName(in); got != want {
+			t.Errorf("NormalizeTableName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRunScript_FailsOnPsqlExitCode(t *testing.T) {
+	testutil.NewFakeBin(t, "psql", testutil.Response{
+		Stderr: `psql:/tmp/drift-1.sql:3: ERROR:  relation "public.posts" does not exist`,
+		Exit:   3,
+	})
+	opts := RestoreOptions{Host: "localhost", Port: 5432, User: "postgres", Database: "postgres"}
+
+	_, err := RunScript(opts, "\\set ON_ERROR_STOP on\nSELECT 1;\n")
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("RunScript() error = %v, want the psql error", err)
+	}
+	if _, err := queryRows(opts, "SELECT 1"); err == nil {
+		t.Error("queryRows() succeeded when psql exited non-zero")
+	}
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TableSync describes copying one table's rows from a source to a target database.
+type TableSync struct {
+	Table   string   // schema-qualified table
+	Key     []string // conflict columns (primary key or a unique constraint)
+	Columns []string // columns copied, in order; must include Key
+}
+
+// FetchPrimaryKey returns the primary key columns of a table, in key order.
+func FetchPrimaryKey(opts RestoreOptions, table string) ([]string, error) {
+	query := fmt.Sprintf(`SELECT a.attname
+FROM pg_index i
+JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+WHERE i.indrelid = %s::regclass AND i.indisprimary
+ORDER BY array_position(i.indkey::int2[], a.attnum);`, quoteLiteral(quoteQualified(table)))
+
+	rows, err := queryRows(opts, query)
+	if err != nil {
+		return nil, err
+	}
+	var key []string
+	for _, row := range rows {
+		if len(row) == 1 {
+			key = append(key, row[0])
+		}
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%s has no primary key; pass --key", table)
+	}
+	return key, nil
+}
+
+// CommonColumns returns the source columns that also exist in the target,
+// in source order, plus the source columns the target lacks.
+func CommonColumns(source, target []string) (common, missing []string) {
+	inTarget := make(map[string]bool, len(target))
+	for _, c := range target {
+		inTarget[c] = true
+	}
+	for _, c := range source {
+		if inTarget[c] {
+			common = append(common, c)
+		} else {
+			missing = append(missing, c)
+		}
+	}
+	return common, missing
+}
+
+// Validate checks that every key column is copied.
+func (s TableSync) Validate() error {
+	copied := make(map[string]bool, len(s.Columns))
+	for _, c := range s.Columns {
+		copied[c] = true
+	}
+	for _, k := range s.Key {
+		if !copied[k] {
+			return fmt.Errorf("key column %s is not present in both tables", k)
+		}
+	}
+	if len(s.Key) == 0 {
+		return fmt.Errorf("no key columns for %s", s.Table)
+	}
+	return nil
+}
+
+// ExportScript returns a psql script that writes the table to file as CSV
+// and prints the row count.
+func (s TableSync) ExportScript(file string) string {
+	var b strings.Builder
+	b.WriteString("\\set ON_ERROR_STOP on\n")
+	fmt.Fprintf(&b, "SELECT count(*) FROM %s;\n", quoteQualified(s.Table))
+	fmt.Fprintf(&b, "\\copy (SELECT %s FROM %s) TO %s WITH (FORMAT csv)\n",
+		quoteColumns(s.Columns), quoteQualified(s.Table), quoteLiteral(file))
+	return b.String()
+}
+
+// UpsertScript returns a psql script that loads file into the target and
+// upserts it on Key, updating only rows whose values differ. It prints
+// "inserted|updated". Rows missing from the source are left alone. When
+// dryRun is set the transaction is rolled back after counting.
+func (s TableSync) UpsertScript(file string, dryRun bool) string {
+	table := quoteQualified(s.Table)
+	cols := quoteColumns(s.Columns)
+
+	isKey := make(map[string]bool, len(s.Key))
+	for _, k := range s.Key {
+		isKey[k] = true
+	}
+	var set, current, incoming []string
+	for _, c := range s.Columns {
+		if isKey[c] {
+			continue
+		}
+		set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", quoteIdent(c), quoteIdent(c)))
+		current = append(current, "t."+quoteIdent(c))
+		incoming = append(incoming, "EXCLUDED."+quoteIdent(c))
+	}
+
+	conflict := "DO NOTHING"
+	if len(set) > 0 {
+		conflict = fmt.Sprintf("DO UPDATE SET %s WHERE (%s) IS DISTINCT FROM (%s)",
+			strings.Join(set, ", "), strings.Join(current, ", "), strings.Join(incoming, ", "))
+	}
+
+	var b strings.Builder
+	b.WriteString("\\set ON_ERROR_STOP on\n")
+	b.WriteString("BEGIN;\n")
+	fmt.Fprintf(&b, "CREATE TEMP TABLE drift_sync ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA;\n", cols, table)
+	fmt.Fprintf(&b, "\\copy drift_sync FROM %s WITH (FORMAT csv)\n", quoteLiteral(file))
+	fmt.Fprintf(&b, "WITH upserted AS (INSERT INTO %s AS t (%s) OVERRIDING SYSTEM VALUE SELECT %s FROM drift_sync ON CONFLICT (%s) %s RETURNING (xmax = 0) AS inserted)\n",
+		table, cols, cols, quoteColumns(s.Key), conflict)
+	b.WriteString("SELECT count(*) FILTER (WHERE inserted), count(*) FILTER (WHERE NOT inserted) FROM upserted;\n")
+	if dryRun {
+		b.WriteString("ROLLBACK;\n")
+	} else {
+		b.WriteString("COMMIT;\n")
+	}
+	return b.String()
+}
+
+// ParseUpsertCounts parses the "inserted|updated" line printed by UpsertScript.
+func ParseUpsertCounts(output string) (inserted, updated int, err error) {
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) != 2 {
+			continue
+		}
+		i, err1 := strconv.Atoi(parts[0])
+		u, err2 := strconv.Atoi(parts[1])
+		if err1 == nil && err2 == nil {
+			return i, u, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("could not read upsert counts from psql output")
+}
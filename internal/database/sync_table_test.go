@@ -0,0 +1,71 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommonColumns(t *testing.T) {
+	common, missing := CommonColumns([]string{"id", "name", "beta"}, []string{"name", "id", "legacy"})
+	if !reflect.DeepEqual(common, []string{"id", "name"}) {
+		t.Errorf("common = %v, want [id name]", common)
+	}
+	if !reflect.DeepEqual(missing, []string{"beta"}) {
+		t.Errorf("missing = %v, want [beta]", missing)
+	}
+}
+
+func TestTableSyncValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sync    TableSync
+		wantErr bool
+	}{
+		{"ok", TableSync{Table: "public.plans", Key: []string{"id"}, Columns: []string{"id", "name"}}, false},
+		{"no key", TableSync{Table: "public.plans", Columns: []string{"id"}}, true},
+		{"key not copied", TableSync{Table: "public.plans", Key: []string{"slug"}, Columns: []string{"id"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sync.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTableSyncUpsertScript(t *testing.T) {
+	sync := TableSync{Table: "public.translations", Key: []string{"locale", "key"}, Columns: []string{"locale", "key", "value"}}
+
+	script := sync.UpsertScript("/tmp/rows.csv", false)
+	for _, want := range []string{
+		`CREATE TEMP TABLE drift_sync ON COMMIT DROP AS SELECT "locale", "key", "value" FROM "public"."translations" WITH NO DATA;`,
+		`\copy drift_sync FROM '/tmp/rows.csv' WITH (FORMAT csv)`,
+		`ON CONFLICT ("locale", "key") DO UPDATE SET "value" = EXCLUDED."value" WHERE (t."value") IS DISTINCT FROM (EXCLUDED."value")`,
+		"COMMIT;",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q\n%s", want, script)
+		}
+	}
+
+	keyOnly := TableSync{Table: "public.tags", Key: []string{"name"}, Columns: []string{"name"}}
+	script = keyOnly.UpsertScript("/tmp/rows.csv", true)
+	if !strings.Contains(script, `ON CONFLICT ("name") DO NOTHING`) {
+		t.Errorf("key-only table should not update:\n%s", script)
+	}
+	if !strings.Contains(script, "ROLLBACK;") || strings.Contains(script, "COMMIT;") {
+		t.Errorf("dry run should roll back:\n%s", script)
+	}
+}
+
+func TestParseUpsertCounts(t *testing.T) {
+	inserted, updated, err := ParseUpsertCounts("\n3|12\n")
+	if err != nil || inserted != 3 || updated != 12 {
+		t.Errorf("ParseUpsertCounts() = %d, %d, %v; want 3, 12, nil", inserted, updated, err)
+	}
+	if _, _, err := ParseUpsertCounts("ERROR"); err == nil {
+		t.Error("ParseUpsertCounts() accepted output without counts")
+	}
+}
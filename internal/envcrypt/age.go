@@ -0,0 +1,80 @@
+package envcrypt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/undrift/drift/internal/state"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// AgeSecretsDir is where age-encrypted values are kept, relative to the project root.
+const AgeSecretsDir = state.Dir + "/secrets"
+
+// ageStore encrypts each secret to its own file with the age CLI.
+type ageStore struct {
+	dir       string
+	recipient string
+	identity  string
+}
+
+func newAgeStore(projectRoot, recipient, identity string) (Store, error) {
+	if !shell.CommandExists("age") {
+		return nil, fmt.Errorf("'age' not found (brew install age)")
+	}
+	if strings.TrimSpace(recipient) == "" {
+		return nil, fmt.Errorf("encryption.age_recipient is required for the age backend")
+	}
+	if strings.TrimSpace(identity) == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("encryption.age_identity is required: %w", err)
+		}
+		identity = filepath.Join(home, ".config", "age", "keys.txt")
+	} else if strings.HasPrefix(identity, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			identity = filepath.Join(home, identity[2:])
+		}
+	}
+
+	return &ageStore{
+		dir:       filepath.Join(projectRoot, AgeSecretsDir),
+		recipient: recipient,
+		identity:  identity,
+	}, nil
+}
+
+func (s *ageStore) path(id string) string {
+	return filepath.Join(s.dir, id+".age")
+}
+
+func (s *ageStore) Put(id, value string) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", s.dir, err)
+	}
+	result, err := shell.RunWithInput(value, "age", "-r", s.recipient, "-o", s.path(id))
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("age encryption failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+func (s *ageStore) Get(id string) (string, error) {
+	path := s.path(id)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("no encrypted value at %s (re-run 'drift env setup')", path)
+	}
+	result, err := shell.Run("age", "-d", "-i", s.identity, path)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("age decryption failed: %s", result.Stderr)
+	}
+	return result.Stdout, nil
+}
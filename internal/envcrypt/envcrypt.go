@@ -0,0 +1,122 @@
+// Package envcrypt keeps secret values for generated env files out of plaintext on disk.
+//
+// When encryption is enabled, generators write a reference such as
+// "drift-secret:abcdefgh.SUPABASE_SERVICE_ROLE_KEY" in place of the raw value
+// and the value itself is stored in a backend (OS keychain or age-encrypted file).
+// References are resolved on demand by 'drift run' and 'drift env resolve'.
+package envcrypt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/undrift/drift/internal/config"
+)
+
+// ReferencePrefix marks a value as a reference into the secret store.
+const ReferencePrefix = "drift-secret:"
+
+// Backend names accepted in encryption.backend.
+const (
+	BackendKeychain = "keychain"
+	BackendAge      = "age"
+)
+
+// Store persists secret values by id.
+type Store interface {
+	Put(id, value string) error
+	Get(id string) (string, error)
+}
+
+// NewStore returns the store configured in cfg.Encryption.
+func NewStore(cfg *config.Config) (Store, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Encryption.Backend)) {
+	case "", BackendKeychain:
+		return newKeychainStore()
+	case BackendAge:
+		return newAgeStore(cfg.ProjectRoot(), cfg.Encryption.AgeRecipient, cfg.Encryption.AgeIdentity)
+	default:
+		return nil, fmt.Errorf("unknown encryption backend %q (use keychain or age)", cfg.Encryption.Backend)
+	}
+}
+
+// Reference returns the placeholder written to env files for a stored secret.
+func Reference(id string) string {
+	return ReferencePrefix + id
+}
+
+// IsReference reports whether value is a drift-secret reference.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, ReferencePrefix)
+}
+
+// SecretID builds the store id for a variable scoped to a Supabase project ref.
+func SecretID(scope, key string) string {
+	if scope == "" {
+		return key
+	}
+	return scope + "." + key
+}
+
+// SealFunc replaces a plaintext value with what should be written to disk.
+type SealFunc func(key, value string) (string, error)
+
+// NewSealer returns a SealFunc that stores values for the listed keys and
+// returns references for them. Other keys, empty values, and values that are
+// already references pass through unchanged.
+func NewSealer(store Store, keys []string, scope string) SealFunc {
+	sealed := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		sealed[strings.TrimSpace(k)] = true
+	}
+
+	return func(key, value string) (string, error) {
+		if !sealed[key] || value == "" || IsReference(value) {
+			return value, nil
+		}
+		id := SecretID(scope, key)
+		if err := store.Put(id, value); err != nil {
+			return "", fmt.Errorf("failed to store %s: %w", key, err)
+		}
+		return Reference(id), nil
+	}
+}
+
+// Resolve returns the plaintext for value, fetching it from store if it is a reference.
+func Resolve(store Store, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	id := strings.TrimPrefix(value, ReferencePrefix)
+	secret, err := store.Get(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", id, err)
+	}
+	return secret, nil
+}
+
+// ResolveAll resolves every reference in values. The store is only created
+// (via newStore) if at least one reference is present.
+func ResolveAll(values map[string]string, newStore func() (Store, error)) (map[string]string, error) {
+	resolved := make(map[string]string, len(values))
+	var store Store
+	for key, value := range values {
+		if !IsReference(value) {
+			resolved[key] = value
+			continue
+		}
+		if store == nil {
+			s, err := newStore()
+			if err != nil {
+				return nil, err
+			}
+			store = s
+		}
+		secret, err := Resolve(store, value)
+		if err != nil {
+			return nil, err
+		}
+		resolved[key] = secret
+	}
+	return resolved, nil
+}
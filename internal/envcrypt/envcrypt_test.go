@@ -0,0 +1,71 @@
+package envcrypt
+
+import (
+	"fmt"
+	"testing"
+)
+
+type memoryStore map[string]string
+
+func (m memoryStore) Put(id, value string) error {
+	m[id] = value
+	return nil
+}
+
+func (m memoryStore) Get(id string) (string, error) {
+	v, ok := m[id]
+	if !ok {
+		return "", fmt.Errorf("not found")
+	}
+	return v, nil
+}
+
+func TestNewSealer_SealsOnlyConfiguredKeys(t *testing.T) {
+	store := memoryStore{}
+	seal := NewSealer(store, []string{"SUPABASE_SERVICE_ROLE_KEY"}, "abcdefgh")
+
+	got, err := seal("SUPABASE_SERVICE_ROLE_KEY", "secret")
+	if err != nil {
+		t.Fatalf("seal returned error: %v", err)
+	}
+	if got != "drift-secret:abcdefgh.SUPABASE_SERVICE_ROLE_KEY" {
+		t.Errorf("seal = %q, want reference", got)
+	}
+	if store["abcdefgh.SUPABASE_SERVICE_ROLE_KEY"] != "secret" {
+		t.Errorf("secret was not stored: %v", store)
+	}
+
+	if got, _ := seal("NEXT_PUBLIC_SUPABASE_URL", "https://x.supabase.co"); got != "https://x.supabase.co" {
+		t.Errorf("unlisted key was sealed: %q", got)
+	}
+	if got, _ := seal("SUPABASE_SERVICE_ROLE_KEY", ""); got != "" {
+		t.Errorf("empty value was sealed: %q", got)
+	}
+}
+
+func TestResolveAll(t *testing.T) {
+	store := memoryStore{"ref.DATABASE_URL": "postgres://u:p@h/db"}
+	values := map[string]string{
+		"DATABASE_URL": Reference("ref.DATABASE_URL"),
+		"PLAIN":        "value",
+	}
+
+	resolved, err := ResolveAll(values, func() (Store, error) { return store, nil })
+	if err != nil {
+		t.Fatalf("ResolveAll returned error: %v", err)
+	}
+	if resolved["DATABASE_URL"] != "postgres://u:p@h/db" || resolved["PLAIN"] != "value" {
+		t.Errorf("unexpected resolved values: %v", resolved)
+	}
+}
+
+func TestResolveAll_NoReferencesSkipsStore(t *testing.T) {
+	called := false
+	_, err := ResolveAll(map[string]string{"A": "1"}, func() (Store, error) {
+		called = true
+		return nil, fmt.Errorf("unavailable")
+	})
+	if err != nil || called {
+		t.Errorf("store should not be created without references (err=%v, called=%v)", err, called)
+	}
+}
@@ -0,0 +1,78 @@
+package envcrypt
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// keychainService is the service name secrets are filed under in the OS keychain.
+const keychainService = "drift"
+
+// keychainStore stores secrets in the macOS Keychain or the Linux Secret Service.
+type keychainStore struct {
+	goos string
+}
+
+func newKeychainStore() (Store, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		if !shell.CommandExists("security") {
+			return nil, fmt.Errorf("'security' command not found")
+		}
+	case "linux":
+		if !shell.CommandExists("secret-tool") {
+			return nil, fmt.Errorf("'secret-tool' not found (install libsecret-tools) or use encryption.backend: age")
+		}
+	default:
+		return nil, fmt.Errorf("keychain backend is not supported on %s; use encryption.backend: age", runtime.GOOS)
+	}
+	return &keychainStore{goos: runtime.GOOS}, nil
+}
+
+func (s *keychainStore) Put(id, value string) error {
+	var result *shell.Result
+	var err error
+	if s.goos == "darwin" {
+		// Use interactive mode so the secret is passed on stdin, not argv.
+		line := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			keychainService, securityQuote(id), securityQuote(value))
+		result, err = shell.RunWithInput(line, "security", "-i")
+	} else {
+		result, err = shell.RunWithInput(value, "secret-tool", "store",
+			"--label", "drift "+id, "service", keychainService, "account", id)
+	}
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("keychain write failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+func (s *keychainStore) Get(id string) (string, error) {
+	var result *shell.Result
+	var err error
+	if s.goos == "darwin" {
+		result, err = shell.Run("security", "find-generic-password", "-s", keychainService, "-a", id, "-w")
+	} else {
+		result, err = shell.Run("secret-tool", "lookup", "service", keychainService, "account", id)
+	}
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("secret not found in keychain (re-run 'drift env setup')")
+	}
+	return result.Stdout, nil
+}
+
+// securityQuote quotes a value for the `security -i` command parser.
+func securityQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
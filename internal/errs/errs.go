@@ -0,0 +1,178 @@
+// Package errs classifies drift errors so the CLI can exit with a code that
+// tells scripts and CI what went wrong without parsing stderr. Commands wrap
+// errors with the constructors here; ExitCode maps them to exit codes.
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// Exit codes. Anything unclassified exits with ExitFailure.
+const (
+	ExitOK         = 0
+	ExitFailure    = 1
+	ExitConfig     = 3   // missing or invalid configuration, missing tools
+	ExitAuth       = 4   // not logged in, bad token, permission denied
+	ExitNetwork    = 5   // Supabase or another service unreachable
+	ExitValidation = 6   // checks ran and found problems
+	ExitCancelled  = 130 // the user declined a confirmation
+)
+
+// ConfigError means drift is not set up to run the command: no .drift.yaml,
+// an invalid setting, or a missing dependency.
+type ConfigError struct{ Err error }
+
+func (e *ConfigError) Error() string { return e.Err.Error() }
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// AuthError means credentials are missing or were rejected.
+type AuthError struct{ Err error }
+
+func (e *AuthError) Error() string { return e.Err.Error() }
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// NetworkError means a remote service could not be reached.
+type NetworkError struct{ Err error }
+
+func (e *NetworkError) Error() string { return e.Err.Error() }
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// ValidationFailed means a check ran to completion and reported problems.
+type ValidationFailed struct{ Err error }
+
+func (e *ValidationFailed) Error() string { return e.Err.Error() }
+func (e *ValidationFailed) Unwrap() error { return e.Err }
+
+// UserCancelled means the user declined to continue. It is not printed as an
+// error.
+type UserCancelled struct{ Operation string }
+
+func (e *UserCancelled) Error() string {
+	if e.Operation == "" {
+		return "cancelled"
+	}
+	return e.Operation + " cancelled"
+}
+
+// ReportedError is an error the command has already shown to the user. It
+// keeps err's exit code but is not printed again.
+type ReportedError struct{ Err error }
+
+func (e *ReportedError) Error() string { return e.Err.Error() }
+func (e *ReportedError) Unwrap() error { return e.Err }
+
+// Config wraps err as a ConfigError. A nil err stays nil.
+func Config(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ConfigError{Err: err}
+}
+
+// Configf returns a ConfigError with a formatted message.
+func Configf(format string, args ...interface{}) error {
+	return &ConfigError{Err: fmt.Errorf(format, args...)}
+}
+
+// Auth wraps err as an AuthError. A nil err stays nil.
+func Auth(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &AuthError{Err: err}
+}
+
+// Authf returns an AuthError with a formatted message.
+func Authf(format string, args ...interface{}) error {
+	return &AuthError{Err: fmt.Errorf(format, args...)}
+}
+
+// Network wraps err as a NetworkError. A nil err stays nil.
+func Network(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &NetworkError{Err: err}
+}
+
+// Networkf returns a NetworkError with a formatted message.
+func Networkf(format string, args ...interface{}) error {
+	return &NetworkError{Err: fmt.Errorf(format, args...)}
+}
+
+// Validation wraps err as a ValidationFailed. A nil err stays nil.
+func Validation(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ValidationFailed{Err: err}
+}
+
+// Validationf returns a ValidationFailed with a formatted message.
+func Validationf(format string, args ...interface{}) error {
+	return &ValidationFailed{Err: fmt.Errorf(format, args...)}
+}
+
+// Cancelled returns a UserCancelled for operation, e.g. "push migrations".
+func Cancelled(operation string) error {
+	return &UserCancelled{Operation: operation}
+}
+
+// Reported marks err as already shown to the user. A nil err stays nil.
+func Reported(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ReportedError{Err: err}
+}
+
+// IsReported reports whether err was marked with Reported.
+func IsReported(err error) bool {
+	var r *ReportedError
+	return errors.As(err, &r)
+}
+
+// IsCancelled reports whether err is a UserCancelled.
+func IsCancelled(err error) bool {
+	var c *UserCancelled
+	return errors.As(err, &c)
+}
+
+// ExitCode returns the exit code for err. Errors that were not wrapped are
+// still recognised as network errors when they come from the net package.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var (
+		cancelled  *UserCancelled
+		config     *ConfigError
+		auth       *AuthError
+		network    *NetworkError
+		validation *ValidationFailed
+	)
+	switch {
+	case errors.As(err, &cancelled):
+		return ExitCancelled
+	case errors.As(err, &auth):
+		return ExitAuth
+	case errors.As(err, &config):
+		return ExitConfig
+	case errors.As(err, &validation):
+		return ExitValidation
+	case errors.As(err, &network):
+		return ExitNetwork
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	if errors.As(err, &netErr) || errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded) {
+		return ExitNetwork
+	}
+	return ExitFailure
+}
@@ -0,0 +1,58 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"plain", errors.New("boom"), ExitFailure},
+		{"config", Configf("no .drift.yaml"), ExitConfig},
+		{"auth", Auth(errors.New("401")), ExitAuth},
+		{"network", Networkf("unreachable"), ExitNetwork},
+		{"validation", Validationf("2 checks failed"), ExitValidation},
+		{"cancelled", Cancelled("push migrations"), ExitCancelled},
+		{"wrapped", fmt.Errorf("deploy: %w", Authf("token expired")), ExitAuth},
+		{"url error", fmt.Errorf("fetch: %w", &url.Error{Op: "Get", URL: "https://api", Err: errors.New("dial tcp: no such host")}), ExitNetwork},
+		{"auth wins over network", Auth(Networkf("401 from proxy")), ExitAuth},
+		{"reported keeps code", Reported(Configf("no .drift.yaml found")), ExitConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapKeepsMessageAndNil(t *testing.T) {
+	if Config(nil) != nil || Auth(nil) != nil || Network(nil) != nil || Reported(nil) != nil {
+		t.Error("wrapping nil should return nil")
+	}
+	inner := errors.New("Supabase CLI not found")
+	err := Config(inner)
+	if err.Error() != inner.Error() || !errors.Is(err, inner) {
+		t.Errorf("Config() = %v, should keep and unwrap to %v", err, inner)
+	}
+	if !IsCancelled(fmt.Errorf("x: %w", Cancelled(""))) || Cancelled("").Error() != "cancelled" {
+		t.Error("Cancelled() should be recognised through wrapping")
+	}
+}
+
+func TestIsReported(t *testing.T) {
+	if !IsReported(fmt.Errorf("x: %w", Reported(errors.New("shown")))) {
+		t.Error("Reported() should be recognised through wrapping")
+	}
+	if IsReported(Configf("not shown")) {
+		t.Error("IsReported() should be false for unmarked errors")
+	}
+}
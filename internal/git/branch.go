@@ -1,9 +1,12 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/undrift/drift/pkg/shell"
 )
 
@@ -29,6 +32,13 @@ func CurrentBranch() (string, error) {
 
 // BranchExists checks if a branch exists locally.
 func BranchExists(branch string) bool {
+	if repo, err := openRepository("."); err == nil {
+		_, err := repo.ResolveRevision(plumbing.Revision(branch))
+		if err == nil || errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return err == nil
+		}
+		// Revision syntax go-git does not understand; ask git.
+	}
 	result, _ := shell.Run("git", "rev-parse", "--verify", branch)
 	return result != nil && result.ExitCode == 0
 }
@@ -40,6 +50,10 @@ func RemoteBranchExists(remote, branch string) bool {
 	}
 
 	ref := fmt.Sprintf("refs/remotes/%s/%s", remote, branch)
+	if repo, err := openRepository("."); err == nil {
+		_, err := repo.Reference(plumbing.ReferenceName(ref), true)
+		return err == nil
+	}
 	result, _ := shell.Run("git", "rev-parse", "--verify", ref)
 	return result != nil && result.ExitCode == 0
 }
@@ -120,6 +134,19 @@ func DeleteBranch(name string, force bool) error {
 	return nil
 }
 
+// RenameBranch renames a local branch. Worktrees that have it checked out follow the rename.
+func RenameBranch(oldName, newName string) error {
+	result, err := shell.Run("git", "branch", "-m", oldName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename branch %s: %w", oldName, err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to rename branch %s: %s", oldName, result.Stderr)
+	}
+
+	return nil
+}
+
 // TrackingBranch returns the tracking branch for the current branch.
 func TrackingBranch() (string, error) {
 	result, err := shell.Run("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
@@ -202,3 +229,14 @@ func GetShortCommitHash(ref string) (string, error) {
 	return result.Stdout, nil
 }
 
+// LastCommitTime returns the committer date of the newest commit on ref.
+func LastCommitTime(ref string) (time.Time, error) {
+	result, err := shell.Run("git", "log", "-1", "--format=%cI", ref, "--")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read last commit of %s: %w", ref, err)
+	}
+	if result.ExitCode != 0 {
+		return time.Time{}, fmt.Errorf("failed to read last commit of %s: %s", ref, strings.TrimSpace(result.Stderr))
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(result.Stdout))
+}
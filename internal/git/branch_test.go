@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestCurrentBranch(t *testing.T) {
@@ -387,3 +388,20 @@ func TestRemoteBranchExists_NoRemote(t *testing.T) {
 		t.Error("RemoteBranchExists() = true, want false when no remote exists")
 	}
 }
+
+func TestLastCommitTime(t *testing.T) {
+	repo := setupTestRepo(t)
+	defer chdir(t, repo.path)()
+
+	got, err := LastCommitTime("HEAD")
+	if err != nil {
+		t.Fatalf("LastCommitTime() error = %v", err)
+	}
+	if age := time.Since(got); age < 0 || age > time.Hour {
+		t.Errorf("LastCommitTime() = %v, want the initial commit just made", got)
+	}
+
+	if _, err := LastCommitTime("no-such-branch"); err == nil {
+		t.Error("LastCommitTime() should fail for a missing ref")
+	}
+}
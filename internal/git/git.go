@@ -99,6 +99,15 @@ func GetRemoteURL(remote string) (string, error) {
 	return result.Stdout, nil
 }
 
+// UserName returns the configured git user.name.
+func UserName() (string, error) {
+	result, err := shell.Run("git", "config", "user.name")
+	if err != nil {
+		return "", fmt.Errorf("failed to get git user.name: %w", err)
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
 // Fetch fetches from the specified remote.
 func Fetch(remote string) error {
 	if remote == "" {
@@ -187,3 +196,83 @@ func Pull() error {
 	return nil
 }
 
+
+// CommitPaths stages paths and commits them, leaving other staged changes
+// out of the commit.
+func CommitPaths(message string, paths ...string) error {
+	result, err := shell.Run("git", append([]string{"add", "--"}, paths...)...)
+	if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", strings.Join(paths, ", "), err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to stage %s: %s", strings.Join(paths, ", "), result.Stderr)
+	}
+	result, err = shell.Run("git", append([]string{"commit", "-m", message, "--"}, paths...)...)
+	if err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to commit: %s", result.Stderr)
+	}
+	return nil
+}
+
+// Push pushes the current branch to its upstream.
+func Push() error {
+	result, err := shell.Run("git", "push")
+	if err != nil {
+		return fmt.Errorf("failed to push: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to push: %s", result.Stderr)
+	}
+	return nil
+}
+
+// IsIgnored returns true if path is matched by a gitignore rule.
+func IsIgnored(path string) bool {
+	result, err := shell.Run("git", "check-ignore", "-q", "--no-index", path)
+	return err == nil && result.ExitCode == 0
+}
+
+// StagedFiles returns the paths of files staged for commit (excluding deletions).
+func StagedFiles() ([]string, error) {
+	result, err := shell.Run("git", "diff", "--cached", "--name-only", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to list staged files: %s", result.Stderr)
+	}
+	if result.Stdout == "" {
+		return nil, nil
+	}
+	return strings.Split(result.Stdout, "\n"), nil
+}
+
+// StagedDiff returns the staged changes as a zero-context unified diff.
+func StagedDiff() (string, error) {
+	result, err := shell.Run("git", "diff", "--cached", "--no-color", "--no-ext-diff", "-U0")
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged diff: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to get staged diff: %s", result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+// GetHooksDir returns the directory git runs hooks from (honours core.hooksPath).
+func GetHooksDir() (string, error) {
+	result, err := shell.Run("git", "rev-parse", "--git-path", "hooks")
+	if err != nil || result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to get hooks dir")
+	}
+
+	hooksDir := result.Stdout
+	if !filepath.IsAbs(hooksDir) {
+		cwd, _ := os.Getwd()
+		hooksDir = filepath.Join(cwd, hooksDir)
+	}
+	return hooksDir, nil
+}
@@ -0,0 +1,252 @@
+package git
+
+import (
+	"container/heap"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// The read-only queries that run once per worktree (listing worktrees,
+// checking branches, counting commits) are answered in-process with go-git
+// and git's own administrative files. Anything that writes still runs the
+// git binary. When a repository layout is not handled here, callers fall
+// back to shelling out.
+
+// errNativeUnsupported means the repository must be read with the git
+// binary instead.
+var errNativeUnsupported = errors.New("repository layout not supported natively")
+
+// openRepository opens the repository containing dir, sharing refs and
+// objects with the main worktree when dir is a linked worktree.
+func openRepository(dir string) (*gogit.Repository, error) {
+	if os.Getenv("GIT_DIR") != "" || os.Getenv("GIT_WORK_TREE") != "" {
+		return nil, errNativeUnsupported
+	}
+	return gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+}
+
+// commonGitDir finds the .git directory shared by all worktrees of the
+// repository containing dir.
+func commonGitDir(dir string) (string, error) {
+	if os.Getenv("GIT_DIR") != "" || os.Getenv("GIT_WORK_TREE") != "" {
+		return "", errNativeUnsupported
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			if info.IsDir() {
+				return dotGit, nil
+			}
+			return commonDirFromFile(dir, dotGit)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", gogit.ErrRepositoryNotExists
+		}
+		dir = parent
+	}
+}
+
+// commonDirFromFile follows a linked worktree's .git file to its
+// administrative directory, and from there to the common directory.
+func commonDirFromFile(wtPath, dotGit string) (string, error) {
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	adminDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", errNativeUnsupported
+	}
+	adminDir = absFrom(wtPath, strings.TrimSpace(adminDir))
+	common, err := os.ReadFile(filepath.Join(adminDir, "commondir"))
+	if err != nil {
+		// A submodule's .git file points at its own repository.
+		return "", errNativeUnsupported
+	}
+	return absFrom(adminDir, strings.TrimSpace(string(common))), nil
+}
+
+// listWorktreesNative reads the worktrees of the repository containing dir
+// the way 'git worktree list' does: the main worktree first, then the
+// linked ones recorded under .git/worktrees, sorted by path.
+func listWorktreesNative(dir string) ([]Worktree, error) {
+	common, err := commonGitDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Base(common) != ".git" {
+		// Bare repositories and separate git dirs.
+		return nil, errNativeUnsupported
+	}
+	mainPath := filepath.Dir(common)
+	repo, err := gogit.PlainOpen(mainPath)
+	if err != nil {
+		return nil, err
+	}
+
+	main := Worktree{Path: resolvePath(mainPath)}
+	if err := readWorktreeHead(repo, filepath.Join(common, "HEAD"), &main); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(common, "worktrees"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var linked []Worktree
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		adminDir := filepath.Join(common, "worktrees", entry.Name())
+		gitdir, err := os.ReadFile(filepath.Join(adminDir, "gitdir"))
+		if err != nil {
+			// git skips entries without a gitdir file too.
+			continue
+		}
+		dotGit := absFrom(adminDir, strings.TrimSpace(string(gitdir)))
+		wt := Worktree{Path: strings.TrimSuffix(dotGit, string(filepath.Separator)+".git")}
+
+		if reason, err := os.ReadFile(filepath.Join(adminDir, "locked")); err == nil {
+			wt.IsLocked = true
+			wt.LockReason = strings.TrimSpace(string(reason))
+		}
+		if _, err := os.Stat(dotGit); os.IsNotExist(err) && !wt.IsLocked {
+			wt.Prunable = "gitdir file points to non-existent location"
+		}
+		if err := readWorktreeHead(repo, filepath.Join(adminDir, "HEAD"), &wt); err != nil {
+			return nil, err
+		}
+		linked = append(linked, wt)
+	}
+	sort.Slice(linked, func(i, j int) bool { return linked[i].Path < linked[j].Path })
+
+	currentDir, _ := os.Getwd()
+	worktrees := append([]Worktree{main}, linked...)
+	for i := range worktrees {
+		worktrees[i].IsCurrent = worktrees[i].Path == currentDir
+	}
+	return worktrees, nil
+}
+
+// readWorktreeHead sets wt's branch and commit from its HEAD file.
+func readWorktreeHead(repo *gogit.Repository, headPath string, wt *Worktree) error {
+	data, err := os.ReadFile(headPath)
+	if err != nil {
+		return err
+	}
+	head := strings.TrimSpace(string(data))
+
+	target, ok := strings.CutPrefix(head, "ref:")
+	if !ok {
+		wt.Branch = "(detached)"
+		wt.Commit = head
+		return nil
+	}
+	name := plumbing.ReferenceName(strings.TrimSpace(target))
+	wt.Branch = strings.TrimPrefix(name.String(), "refs/heads/")
+
+	ref, err := repo.Reference(name, true)
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		// An unborn branch, as in a repository without commits.
+		wt.Commit = plumbing.ZeroHash.String()
+	case err != nil:
+		return err
+	default:
+		wt.Commit = ref.Hash().String()
+	}
+	return nil
+}
+
+// aheadBehind counts the commits reachable from a but not b (ahead) and
+// from b but not a (behind), as 'git rev-list --left-right --count a...b'.
+//
+// It walks both histories newest first, marking each commit with the side
+// it was reached from, and stops once every commit still queued is
+// reachable from both.
+func aheadBehind(repo *gogit.Repository, a, b plumbing.Hash) (ahead, behind int, err error) {
+	const left, right = 1, 2
+	flags := map[plumbing.Hash]uint8{}
+	queue := &commitQueue{}
+
+	mark := func(h plumbing.Hash, f uint8) error {
+		if flags[h]|f == flags[h] {
+			return nil
+		}
+		flags[h] |= f
+		c, err := repo.CommitObject(h)
+		if err != nil {
+			return err
+		}
+		heap.Push(queue, c)
+		return nil
+	}
+	if err := mark(a, left); err != nil {
+		return 0, 0, err
+	}
+	if err := mark(b, right); err != nil {
+		return 0, 0, err
+	}
+
+	for queue.Len() > 0 && !queue.allMarked(flags, left|right) {
+		c := heap.Pop(queue).(*object.Commit)
+		for _, p := range c.ParentHashes {
+			if err := mark(p, flags[c.Hash]); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	for _, f := range flags {
+		switch f {
+		case left:
+			ahead++
+		case right:
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// commitQueue is a heap of commits, newest committer date first.
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	return q[i].Committer.When.After(q[j].Committer.When)
+}
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)   { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// allMarked reports whether every queued commit has all of want set.
+func (q commitQueue) allMarked(flags map[plumbing.Hash]uint8, want uint8) bool {
+	for _, c := range q {
+		if flags[c.Hash]&want != want {
+			return false
+		}
+	}
+	return true
+}
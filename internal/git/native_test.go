@@ -0,0 +1,102 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/undrift/drift/pkg/shell"
+)
+
+func TestListWorktreesNativeMatchesGit(t *testing.T) {
+	repo := setupTestRepo(t)
+	defer chdir(t, repo.path)()
+
+	parent := t.TempDir()
+	gitIn(t, repo.path, "worktree", "add", "-q", "-b", "feature/b", filepath.Join(parent, "b"))
+	gitIn(t, repo.path, "worktree", "add", "-q", "-b", "feature/a", filepath.Join(parent, "a"))
+	gitIn(t, repo.path, "worktree", "add", "-q", "--detach", filepath.Join(parent, "detached"))
+	gitIn(t, repo.path, "worktree", "add", "-q", "-b", "locked", filepath.Join(parent, "locked"))
+	gitIn(t, repo.path, "worktree", "lock", "--reason", "on a USB drive", filepath.Join(parent, "locked"))
+	gitIn(t, repo.path, "worktree", "add", "-q", "-b", "gone", filepath.Join(parent, "gone"))
+	if err := os.RemoveAll(filepath.Join(parent, "gone")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{repo.path, filepath.Join(parent, "a")} {
+		defer chdir(t, dir)()
+		native, err := listWorktreesNative(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		porcelain, err := listWorktreesPorcelain()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(native, porcelain) {
+			t.Errorf("from %s:\nnative    %+v\nporcelain %+v", dir, native, porcelain)
+		}
+	}
+}
+
+func TestAheadBehindMatchesGit(t *testing.T) {
+	repo := setupTestRepo(t)
+	defer chdir(t, repo.path)()
+	base, _ := CurrentBranch()
+
+	// base: 2 more commits, then a merge of a side branch.
+	// topic: forked from the first commit, 3 commits, merges base once.
+	gitIn(t, repo.path, "checkout", "-q", "-b", "topic")
+	commitFile(t, repo.path, "t1", "1")
+	gitIn(t, repo.path, "checkout", "-q", base)
+	commitFile(t, repo.path, "m1", "1")
+	gitIn(t, repo.path, "checkout", "-q", "-b", "side")
+	commitFile(t, repo.path, "s1", "1")
+	gitIn(t, repo.path, "checkout", "-q", base)
+	commitFile(t, repo.path, "m2", "1")
+	gitIn(t, repo.path, "merge", "-q", "--no-edit", "side")
+	gitIn(t, repo.path, "checkout", "-q", "topic")
+	gitIn(t, repo.path, "merge", "-q", "--no-edit", "side")
+	commitFile(t, repo.path, "t2", "1")
+
+	r, err := gogit.PlainOpen(repo.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs := [][2]string{{"topic", base}, {base, "topic"}, {"topic", "topic"}, {"side", base}}
+	for _, pair := range pairs {
+		a, _ := r.ResolveRevision(plumbing.Revision(pair[0]))
+		b, _ := r.ResolveRevision(plumbing.Revision(pair[1]))
+		ahead, behind, err := aheadBehind(r, *a, *b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, _ := shell.Run("git", "rev-list", "--left-right", "--count", pair[0]+"..."+pair[1])
+		got := fmt.Sprintf("%d\t%d", ahead, behind)
+		if got != strings.TrimSpace(result.Stdout) {
+			t.Errorf("%s...%s = %q, git says %q", pair[0], pair[1], got, result.Stdout)
+		}
+	}
+}
+
+func TestRemoteBranchExistsNative(t *testing.T) {
+	repo := setupTestRepo(t)
+	defer chdir(t, repo.path)()
+
+	gitIn(t, repo.path, "update-ref", "refs/remotes/origin/feature/x", "HEAD")
+	if !RemoteBranchExists("origin", "feature/x") {
+		t.Error("RemoteBranchExists(origin, feature/x) = false")
+	}
+	if RemoteBranchExists("origin", "feature/y") {
+		t.Error("RemoteBranchExists(origin, feature/y) = true")
+	}
+	// Revision syntax beyond branch names still works.
+	if !BranchExists("HEAD~0") || !BranchExists("origin/feature/x") {
+		t.Error("BranchExists() rejected a valid revision")
+	}
+}
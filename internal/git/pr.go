@@ -0,0 +1,211 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// PullRequest is the subset of GitHub pull request metadata drift uses.
+type PullRequest struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	HeadRefName string `json:"headRefName"`
+	BaseRefName string `json:"baseRefName"`
+	IsCrossRepo bool   `json:"isCrossRepository"`
+	State       string `json:"state"`
+	Author      struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// GetPullRequest looks up a pull request with the GitHub CLI ('gh').
+func GetPullRequest(number int) (*PullRequest, error) {
+	if !shell.CommandExists("gh") {
+		return nil, fmt.Errorf("GitHub CLI not found (install with: brew install gh)")
+	}
+
+	result, err := shell.Run("gh", "pr", "view", strconv.Itoa(number), "--json", pullRequestFields)
+	if err != nil || result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to look up PR #%d: %s", number, commandError(result, err))
+	}
+
+	return parsePullRequest(result.Stdout)
+}
+
+// CurrentPullRequest looks up the pull request for the current branch.
+func CurrentPullRequest() (*PullRequest, error) {
+	if !shell.CommandExists("gh") {
+		return nil, fmt.Errorf("GitHub CLI not found (install with: brew install gh)")
+	}
+
+	result, err := shell.Run("gh", "pr", "view", "--json", pullRequestFields)
+	if err != nil || result.ExitCode != 0 {
+		return nil, fmt.Errorf("no pull request found for the current branch: %s", commandError(result, err))
+	}
+
+	return parsePullRequest(result.Stdout)
+}
+
+const pullRequestFields = "number,title,url,headRefName,baseRefName,isCrossRepository,state,author"
+
+func parsePullRequest(data string) (*PullRequest, error) {
+	var pr PullRequest
+	if err := json.Unmarshal([]byte(data), &pr); err != nil {
+		return nil, fmt.Errorf("failed to parse PR details: %w", err)
+	}
+	if pr.Number == 0 || pr.HeadRefName == "" {
+		return nil, fmt.Errorf("incomplete PR details from gh")
+	}
+	return &pr, nil
+}
+
+// UpsertPullRequestComment posts body as a comment on PR number, or edits
+// the existing comment containing marker so repeated runs keep a single
+// comment up to date. It reports whether an existing comment was updated.
+func UpsertPullRequestComment(number int, marker, body string) (bool, error) {
+	if !shell.CommandExists("gh") {
+		return false, fmt.Errorf("GitHub CLI not found (install with: brew install gh)")
+	}
+
+	listPath := fmt.Sprintf("repos/{owner}/{repo}/issues/%d/comments", number)
+	query := fmt.Sprintf(".[] | select(.body | contains(%q)) | .id", marker)
+	result, err := shell.Run("gh", "api", "--paginate", listPath, "--jq", query)
+	if err != nil || result.ExitCode != 0 {
+		return false, fmt.Errorf("failed to list comments on PR #%d: %s", number, commandError(result, err))
+	}
+
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return false, err
+	}
+
+	if id, ok := firstCommentID(result.Stdout); ok {
+		editPath := fmt.Sprintf("repos/{owner}/{repo}/issues/comments/%s", id)
+		result, err = shell.RunWithInput(string(payload), "gh", "api", "-X", "PATCH", editPath, "--input", "-")
+		if err != nil || result.ExitCode != 0 {
+			return false, fmt.Errorf("failed to update comment on PR #%d: %s", number, commandError(result, err))
+		}
+		return true, nil
+	}
+
+	result, err = shell.RunWithInput(string(payload), "gh", "api", "-X", "POST", listPath, "--input", "-")
+	if err != nil || result.ExitCode != 0 {
+		return false, fmt.Errorf("failed to comment on PR #%d: %s", number, commandError(result, err))
+	}
+	return false, nil
+}
+
+// firstCommentID returns the first comment ID in gh's line-per-ID output.
+func firstCommentID(output string) (string, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if _, err := strconv.ParseInt(line, 10, 64); err == nil {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// FetchPullRequest fetches the head of PR number from remote and returns
+// its commit. If localBranch is set, the branch is created or moved to it.
+// This works for pull requests from forks too.
+func FetchPullRequest(remote string, number int, localBranch string) (string, error) {
+	if remote == "" {
+		remote = "origin"
+	}
+
+	refspec := fmt.Sprintf("pull/%d/head", number)
+	if localBranch != "" {
+		refspec = fmt.Sprintf("+%s:refs/heads/%s", refspec, localBranch)
+	}
+	result, err := shell.Run("git", "fetch", remote, refspec)
+	if err != nil || result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to fetch PR #%d from %s: %s", number, remote, commandError(result, err))
+	}
+
+	ref := "FETCH_HEAD"
+	if localBranch != "" {
+		ref = "refs/heads/" + localBranch
+	}
+	return GetCommitHash(ref)
+}
+
+// CreateDetachedWorktree creates a worktree at path with HEAD detached at ref.
+func CreateDetachedWorktree(path, ref string) error {
+	result, err := shell.Run("git", "worktree", "add", "--detach", path, ref)
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to create worktree: %s", commandError(result, err))
+	}
+	return nil
+}
+
+// DetachWorktree moves the worktree at wtPath to ref with a detached HEAD.
+func DetachWorktree(wtPath, ref string) error {
+	result, err := shell.RunInDir(wtPath, "git", "checkout", "--detach", ref)
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to update worktree: %s", commandError(result, err))
+	}
+	return nil
+}
+
+// reviewMarkerFile lives in a worktree's private git dir, so it never shows
+// up in 'git status' and disappears with the worktree.
+const reviewMarkerFile = "drift-review"
+
+// MarkReviewWorktree records that the worktree at wtPath is a read-only
+// checkout of PR number.
+func MarkReviewWorktree(wtPath string, number int) error {
+	gitDir, err := worktreeGitDir(wtPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(gitDir, reviewMarkerFile), []byte(strconv.Itoa(number)+"\n"), 0644)
+}
+
+// ReviewPullRequest returns the PR number if the worktree at wtPath was
+// created in review mode.
+func ReviewPullRequest(wtPath string) (int, bool) {
+	gitDir, err := worktreeGitDir(wtPath)
+	if err != nil {
+		return 0, false
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, reviewMarkerFile))
+	if err != nil {
+		return 0, false
+	}
+	number, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, false
+	}
+	return number, true
+}
+
+func worktreeGitDir(wtPath string) (string, error) {
+	result, err := shell.RunInDir(wtPath, "git", "rev-parse", "--git-dir")
+	if err != nil || result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to get git dir for %s: %s", wtPath, commandError(result, err))
+	}
+	gitDir := result.Stdout
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(wtPath, gitDir)
+	}
+	return gitDir, nil
+}
+
+// commandError returns the most useful description of a failed command.
+func commandError(result *shell.Result, err error) string {
+	if result != nil && result.Stderr != "" {
+		return result.Stderr
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "unknown error"
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+func TestParsePullRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "same repository",
+			data: `{"number":142,"title":"Add login","url":"https://github.com/o/r/pull/142","headRefName":"feat/login","baseRefName":"development","isCrossRepository":false,"state":"OPEN","author":{"login":"sam"}}`,
+		},
+		{name: "missing head ref", data: `{"number":142}`, wantErr: true},
+		{name: "invalid json", data: `not json`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr, err := parsePullRequest(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePullRequest() error = %v", err)
+			}
+			if pr.Number != 142 || pr.HeadRefName != "feat/login" || pr.BaseRefName != "development" || pr.Author.Login != "sam" || pr.IsCrossRepo {
+				t.Errorf("parsePullRequest() = %+v", pr)
+			}
+		})
+	}
+}
+
+func TestReviewWorktreeMarker(t *testing.T) {
+	repo := setupTestRepo(t)
+	defer chdir(t, repo.path)()
+
+	wtPath := filepath.Join(t.TempDir(), "review-pr-7")
+	defer func() {
+		cmd := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		cmd.Dir = repo.path
+		cmd.Run()
+	}()
+
+	if err := CreateDetachedWorktree(wtPath, "HEAD"); err != nil {
+		t.Fatalf("CreateDetachedWorktree() error = %v", err)
+	}
+	if _, ok := ReviewPullRequest(wtPath); ok {
+		t.Fatal("new worktree reported as review worktree")
+	}
+
+	if err := MarkReviewWorktree(wtPath, 7); err != nil {
+		t.Fatalf("MarkReviewWorktree() error = %v", err)
+	}
+	number, ok := ReviewPullRequest(wtPath)
+	if !ok || number != 7 {
+		t.Errorf("ReviewPullRequest() = %d, %v, want 7, true", number, ok)
+	}
+	if _, ok := ReviewPullRequest(repo.path); ok {
+		t.Error("main worktree reported as review worktree")
+	}
+
+	if err := DetachWorktree(wtPath, "does-not-exist"); err == nil {
+		t.Error("DetachWorktree() expected error for unknown ref")
+	}
+}
+
+func TestUpsertPullRequestComment(t *testing.T) {
+	const marker = "<!-- drift:test -->"
+
+	t.Run("updates the marked comment", func(t *testing.T) {
+		gh := testutil.NewFakeBin(t, "gh", testutil.Response{Args: "api --paginate", Stdout: "4711\n4712\n"})
+		updated, err := UpsertPullRequestComment(9, marker, marker+"\nbody")
+		if err != nil {
+			t.Fatalf("UpsertPullRequestComment() error = %v", err)
+		}
+		if !updated {
+			t.Error("expected existing comment to be updated")
+		}
+		calls := gh.CallLines()
+		if len(calls) != 2 || calls[1] != "api -X PATCH repos/{owner}/{repo}/issues/comments/4711 --input -" {
+			t.Errorf("gh calls = %q", calls)
+		}
+	})
+
+	t.Run("posts a new comment", func(t *testing.T) {
+		gh := testutil.NewFakeBin(t, "gh")
+		updated, err := UpsertPullRequestComment(9, marker, marker+"\nbody")
+		if err != nil {
+			t.Fatalf("UpsertPullRequestComment() error = %v", err)
+		}
+		if updated {
+			t.Error("expected a new comment")
+		}
+		calls := gh.CallLines()
+		if len(calls) != 2 || calls[1] != "api -X POST repos/{owner}/{repo}/issues/9/comments --input -" {
+			t.Errorf("gh calls = %q", calls)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// IsAncestor reports whether ancestor is reachable from ref.
+func IsAncestor(ancestor, ref string) bool {
+	result, err := shell.Run("git", "merge-base", "--is-ancestor", ancestor, ref)
+	return err == nil && result.ExitCode == 0
+}
+
+// MergeBase returns the best common ancestor of a and b.
+func MergeBase(a, b string) (string, error) {
+	result, err := shell.Run("git", "merge-base", a, b)
+	if err != nil || result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to find merge base of %s and %s: %s", a, b, commandError(result, err))
+	}
+	return result.Stdout, nil
+}
+
+// RebaseOnto replays the commits of the branch checked out in wtPath that
+// are not in upstream onto onto. On conflict the rebase is left in progress
+// for the user to resolve.
+func RebaseOnto(wtPath, onto, upstream string) error {
+	result, err := shell.RunInDir(wtPath, "git", "rebase", "--onto", onto, upstream)
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("rebase onto %s stopped: %s", onto, commandError(result, err))
+	}
+	return nil
+}
+
+// RebaseInProgress reports whether a rebase is paused in wtPath.
+func RebaseInProgress(wtPath string) bool {
+	gitDir, err := worktreeGitDir(wtPath)
+	if err != nil {
+		return false
+	}
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(gitDir, dir)); err == nil {
+			return true
+		}
+	}
+	return false
+}
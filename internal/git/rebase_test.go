@@ -0,0 +1,90 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, dir, "add", name)
+	gitIn(t, dir, "commit", "-q", "-m", "update "+name)
+}
+
+func TestRebaseOnto(t *testing.T) {
+	repo := setupTestRepo(t)
+	defer chdir(t, repo.path)()
+
+	gitIn(t, repo.path, "checkout", "-q", "-b", "parent")
+	commitFile(t, repo.path, "parent.txt", "one\n")
+	oldParent, err := GetCommitHash("parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, repo.path, "checkout", "-q", "-b", "child")
+	commitFile(t, repo.path, "child.txt", "child\n")
+
+	// Amend the parent, as a review fixup would.
+	gitIn(t, repo.path, "checkout", "-q", "parent")
+	if err := os.WriteFile(filepath.Join(repo.path, "parent.txt"), []byte("two\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, repo.path, "commit", "-q", "-a", "--amend", "-m", "update parent.txt")
+
+	if IsAncestor("parent", "child") {
+		t.Fatal("IsAncestor() = true before restacking")
+	}
+
+	gitIn(t, repo.path, "checkout", "-q", "child")
+	if err := RebaseOnto(repo.path, "parent", oldParent); err != nil {
+		t.Fatalf("RebaseOnto() error = %v", err)
+	}
+	if !IsAncestor("parent", "child") {
+		t.Error("IsAncestor() = false after RebaseOnto()")
+	}
+	if RebaseInProgress(repo.path) {
+		t.Error("RebaseInProgress() = true after a clean rebase")
+	}
+
+	base, err := MergeBase("parent", "child")
+	if err != nil {
+		t.Fatalf("MergeBase() error = %v", err)
+	}
+	if head, _ := GetCommitHash("parent"); base != head {
+		t.Errorf("MergeBase() = %s, want parent head %s", base, head)
+	}
+}
+
+func TestRebaseOnto_Conflict(t *testing.T) {
+	repo := setupTestRepo(t)
+	defer chdir(t, repo.path)()
+
+	gitIn(t, repo.path, "checkout", "-q", "-b", "parent")
+	base, _ := GetCommitHash("HEAD")
+	gitIn(t, repo.path, "checkout", "-q", "-b", "child")
+	commitFile(t, repo.path, "README.md", "child\n")
+	gitIn(t, repo.path, "checkout", "-q", "parent")
+	commitFile(t, repo.path, "README.md", "parent\n")
+
+	gitIn(t, repo.path, "checkout", "-q", "child")
+	if err := RebaseOnto(repo.path, "parent", base); err == nil {
+		t.Fatal("RebaseOnto() should fail on a conflict")
+	}
+	if !RebaseInProgress(repo.path) {
+		t.Error("RebaseInProgress() = false, want the conflicted rebase left in progress")
+	}
+}
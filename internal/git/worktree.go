@@ -6,21 +6,36 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/undrift/drift/pkg/shell"
 )
 
 // Worktree represents a git worktree.
 type Worktree struct {
-	Path     string
-	Commit   string
-	Branch   string
-	IsBare   bool
-	IsLocked bool
+	Path      string
+	Commit    string
+	Branch    string
+	IsBare    bool
+	IsLocked  bool
 	IsCurrent bool
+	// LockReason is the reason given to 'git worktree lock', if any.
+	LockReason string
+	// Prunable is set when git would prune the worktree, to its reason
+	// (e.g. "gitdir file points to non-existent location").
+	Prunable string
 }
 
 // ListWorktrees returns all worktrees in the repository.
 func ListWorktrees() ([]Worktree, error) {
+	if worktrees, err := listWorktreesNative("."); err == nil {
+		return worktrees, nil
+	}
+	return listWorktreesPorcelain()
+}
+
+// listWorktreesPorcelain parses 'git worktree list --porcelain', for
+// repositories listWorktreesNative cannot read.
+func listWorktreesPorcelain() ([]Worktree, error) {
 	result, err := shell.Run("git", "worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
@@ -61,9 +76,17 @@ func ListWorktrees() ([]Worktree, error) {
 			if current != nil {
 				current.IsBare = true
 			}
-		} else if line == "locked" {
+		} else if line == "locked" || strings.HasPrefix(line, "locked ") {
 			if current != nil {
 				current.IsLocked = true
+				current.LockReason = strings.TrimSpace(strings.TrimPrefix(line, "locked"))
+			}
+		} else if strings.HasPrefix(line, "prunable") {
+			if current != nil {
+				current.Prunable = strings.TrimSpace(strings.TrimPrefix(line, "prunable"))
+				if current.Prunable == "" {
+					current.Prunable = "prunable"
+				}
 			}
 		} else if line == "detached" {
 			if current != nil {
@@ -176,12 +199,8 @@ func RemoveWorktree(path string, force bool) error {
 	args = append(args, path)
 
 	result, err := shell.Run("git", args...)
-	if err != nil {
-		errMsg := result.Stderr
-		if errMsg == "" {
-			errMsg = err.Error()
-		}
-		return fmt.Errorf("failed to remove worktree: %s", errMsg)
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to remove worktree: %s", commandError(result, err))
 	}
 
 	return nil
@@ -222,10 +241,13 @@ func UnlockWorktree(path string) error {
 
 // MoveWorktree moves a worktree to a new path.
 func MoveWorktree(oldPath, newPath string) error {
-	_, err := shell.Run("git", "worktree", "move", oldPath, newPath)
+	result, err := shell.Run("git", "worktree", "move", oldPath, newPath)
 	if err != nil {
 		return fmt.Errorf("failed to move worktree: %w", err)
 	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to move worktree: %s", result.Stderr)
+	}
 	return nil
 }
 
@@ -250,6 +272,36 @@ func CurrentWorktree() (*Worktree, error) {
 	return GetWorktreeByPath(cwd)
 }
 
+// ContainingWorktree returns the worktree whose directory holds path, or nil.
+// Worktrees can be created inside another worktree, so the innermost one wins.
+func ContainingWorktree(worktrees []Worktree, path string) *Worktree {
+	path = resolvePath(path)
+	var best *Worktree
+	for i := range worktrees {
+		wt := &worktrees[i]
+		root := resolvePath(wt.Path)
+		if path != root && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			continue
+		}
+		if best == nil || len(root) > len(resolvePath(best.Path)) {
+			best = wt
+		}
+	}
+	return best
+}
+
+// resolvePath returns path absolute and with symlinks resolved, as git
+// reports worktree paths, or cleaned if it cannot be resolved.
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
 // GetWorktreePath generates a worktree path based on naming pattern.
 func GetWorktreePath(projectName, branch, pattern string) string {
 	// Get the parent directory of the main worktree
@@ -287,6 +339,17 @@ func GetAheadBehind(wtPath, branch string) (ahead, behind int, err error) {
 	// Fetch first to get latest
 	_, _ = shell.RunInDir(wtPath, "git", "fetch", "origin", branch, "--quiet")
 
+	if repo, err := openRepository(wtPath); err == nil {
+		local, errLocal := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+		remote, errRemote := repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
+		if errLocal != nil || errRemote != nil {
+			return 0, 0, nil
+		}
+		if ahead, behind, err := aheadBehind(repo, local.Hash(), remote.Hash()); err == nil {
+			return ahead, behind, nil
+		}
+	}
+
 	// Get rev-list counts
 	result, err := shell.RunInDir(wtPath, "git", "rev-list", "--left-right", "--count", fmt.Sprintf("%s...origin/%s", branch, branch))
 	if err != nil {
@@ -376,3 +439,27 @@ func DeleteRemoteBranch(remote, branch string) error {
 	return nil
 }
 
+
+// PushBranch pushes a branch from a worktree and sets its upstream.
+func PushBranch(wtPath, remote, branch string) error {
+	result, err := shell.RunInDir(wtPath, "git", "push", "-u", remote, branch)
+	if err != nil {
+		return fmt.Errorf("failed to push %s: %w", branch, err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to push %s: %s", branch, result.Stderr)
+	}
+	return nil
+}
+
+// GetLastCommitSubject returns the subject line of the HEAD commit in a worktree.
+func GetLastCommitSubject(wtPath string) (string, error) {
+	result, err := shell.RunInDir(wtPath, "git", "log", "-1", "--format=%s")
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to read last commit: %s", result.Stderr)
+	}
+	return result.Stdout, nil
+}
@@ -0,0 +1,159 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// WorktreeProblemKind names a way a worktree can be broken.
+type WorktreeProblemKind string
+
+const (
+	// ProblemMissing is a worktree whose directory was deleted or moved
+	// outside git.
+	ProblemMissing WorktreeProblemKind = "missing"
+	// ProblemStaleLock is a lock left behind by an interrupted
+	// 'git worktree add', which keeps git from pruning the worktree.
+	ProblemStaleLock WorktreeProblemKind = "stale-lock"
+	// ProblemDuplicateBranch is a branch checked out in more than one
+	// worktree (possible with --force), so commits in one silently change
+	// the other's HEAD.
+	ProblemDuplicateBranch WorktreeProblemKind = "duplicate-branch"
+	// ProblemBrokenGitdir is a worktree whose .git file and git's record of
+	// it no longer point at each other, usually after the repository or
+	// the worktree was moved by hand.
+	ProblemBrokenGitdir WorktreeProblemKind = "broken-gitdir"
+)
+
+// initializingLock is the lock reason git writes while 'git worktree add'
+// runs; it is removed when the add finishes.
+const initializingLock = "initializing"
+
+// WorktreeProblem is one problem found by DiagnoseWorktrees.
+type WorktreeProblem struct {
+	Kind   WorktreeProblemKind `json:"kind"`
+	Path   string              `json:"path"`
+	Branch string              `json:"branch,omitempty"`
+	Detail string              `json:"detail"`
+	Locked bool                `json:"locked,omitempty"`
+	// Others are the other worktrees with the same branch, for
+	// ProblemDuplicateBranch.
+	Others []string `json:"others,omitempty"`
+}
+
+// DiagnoseWorktrees checks worktrees, as listed by ListWorktrees, for
+// missing directories, stale locks, branches checked out twice and broken
+// .git pointers. The first entry is the main worktree and is only checked
+// for duplicate branches.
+func DiagnoseWorktrees(worktrees []Worktree) []WorktreeProblem {
+	var problems []WorktreeProblem
+	byBranch := make(map[string][]string)
+
+	for i, wt := range worktrees {
+		if wt.IsBare {
+			continue
+		}
+		if wt.Branch != "" && wt.Branch != "(detached)" {
+			byBranch[wt.Branch] = append(byBranch[wt.Branch], wt.Path)
+		}
+		if i == 0 {
+			continue
+		}
+
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+			detail := "directory no longer exists"
+			if wt.Prunable != "" {
+				detail = wt.Prunable
+			}
+			problems = append(problems, WorktreeProblem{
+				Kind: ProblemMissing, Path: wt.Path, Branch: wt.Branch, Detail: detail, Locked: wt.IsLocked,
+			})
+			continue
+		}
+		if wt.IsLocked && wt.LockReason == initializingLock {
+			problems = append(problems, WorktreeProblem{
+				Kind: ProblemStaleLock, Path: wt.Path, Branch: wt.Branch, Locked: true,
+				Detail: "locked by a 'git worktree add' that did not finish",
+			})
+		}
+		if err := CheckGitdir(wt.Path); err != nil {
+			problems = append(problems, WorktreeProblem{
+				Kind: ProblemBrokenGitdir, Path: wt.Path, Branch: wt.Branch, Detail: err.Error(), Locked: wt.IsLocked,
+			})
+		}
+	}
+
+	// Report each duplicate once, against the later checkouts.
+	for _, wt := range worktrees {
+		paths := byBranch[wt.Branch]
+		if len(paths) < 2 || paths[0] == wt.Path {
+			continue
+		}
+		problems = append(problems, WorktreeProblem{
+			Kind: ProblemDuplicateBranch, Path: wt.Path, Branch: wt.Branch,
+			Detail: fmt.Sprintf("%s is also checked out in %s", wt.Branch, paths[0]),
+			Others: []string{paths[0]},
+		})
+	}
+	return problems
+}
+
+// CheckGitdir verifies that a linked worktree's .git file points at its
+// administrative directory in the main repository, and that git's record
+// there points back at the worktree.
+func CheckGitdir(wtPath string) error {
+	dotGit := filepath.Join(wtPath, ".git")
+	info, err := os.Lstat(dotGit)
+	if err != nil {
+		return fmt.Errorf(".git is missing")
+	}
+	if info.IsDir() {
+		return fmt.Errorf(".git is a directory, so git no longer treats this as a linked worktree")
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return fmt.Errorf("cannot read .git: %v", err)
+	}
+	adminDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return fmt.Errorf(".git does not contain a gitdir: line")
+	}
+	adminDir = absFrom(wtPath, strings.TrimSpace(adminDir))
+	if _, err := os.Stat(adminDir); err != nil {
+		return fmt.Errorf(".git points to %s, which does not exist", adminDir)
+	}
+
+	back, err := os.ReadFile(filepath.Join(adminDir, "gitdir"))
+	if err != nil {
+		return fmt.Errorf("%s has no gitdir file", adminDir)
+	}
+	backPath := absFrom(adminDir, strings.TrimSpace(string(back)))
+	if resolvePath(backPath) != resolvePath(dotGit) {
+		return fmt.Errorf("git records this worktree at %s", filepath.Dir(backPath))
+	}
+	return nil
+}
+
+// absFrom resolves path relative to dir; git writes relative gitdir paths
+// when worktree.useRelativePaths is set.
+func absFrom(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
+
+// RepairWorktrees runs 'git worktree repair' for paths, rewriting the .git
+// files and git's records so they point at each other again.
+func RepairWorktrees(paths ...string) error {
+	result, err := shell.Run("git", append([]string{"worktree", "repair"}, paths...)...)
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to repair worktrees: %s", commandError(result, err))
+	}
+	return nil
+}
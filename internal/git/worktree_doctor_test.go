@@ -0,0 +1,80 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestDiagnoseWorktrees(t *testing.T) {
+	repo := setupTestRepo(t)
+	defer chdir(t, repo.path)()
+	main, err := CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := t.TempDir()
+	wt := func(name string) string { return filepath.Join(parent, name) }
+	for _, name := range []string{"gone", "stuck", "moved", "ok"} {
+		gitIn(t, repo.path, "worktree", "add", "-q", "-b", name, wt(name))
+	}
+	gitIn(t, repo.path, "worktree", "add", "-q", "-f", wt("dup"), main)
+
+	// Deleted outside git.
+	if err := os.RemoveAll(wt("gone")); err != nil {
+		t.Fatal(err)
+	}
+	// Left locked by an interrupted 'git worktree add'.
+	if err := os.WriteFile(filepath.Join(repo.path, ".git", "worktrees", "stuck", "locked"), []byte("initializing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// .git pointing at a repository that is no longer there.
+	if err := os.WriteFile(filepath.Join(wt("moved"), ".git"), []byte("gitdir: /nonexistent/.git/worktrees/moved\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := DiagnoseWorktrees(worktrees)
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, string(p.Kind)+" "+filepath.Base(p.Path))
+	}
+	sort.Strings(got)
+	want := []string{"broken-gitdir moved", "duplicate-branch dup", "missing gone", "stale-lock stuck"}
+	if len(got) != len(want) {
+		t.Fatalf("problems = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("problems = %v, want %v", got, want)
+			break
+		}
+	}
+
+	// The repairs clear them.
+	if err := RepairWorktrees(); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckGitdir(wt("moved")); err != nil {
+		t.Errorf("after repair: %v", err)
+	}
+	if err := DetachWorktree(wt("dup"), "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnlockWorktree(wt("stuck")); err != nil {
+		t.Fatal(err)
+	}
+	if err := PruneWorktrees(); err != nil {
+		t.Fatal(err)
+	}
+	worktrees, _ = ListWorktrees()
+	if left := DiagnoseWorktrees(worktrees); len(left) != 0 {
+		t.Errorf("problems after repair = %+v", left)
+	}
+}
@@ -479,3 +479,41 @@ func hasPrefix(path, prefix string) bool {
 func containsName(path, name string) bool {
 	return filepath.Base(path) == name
 }
+
+func TestContainingWorktree(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "app")
+	nested := filepath.Join(main, ".worktrees", "feat-login")
+	sibling := filepath.Join(root, "app-feat-search")
+	for _, dir := range []string{filepath.Join(nested, "src"), sibling} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	worktrees := []Worktree{
+		{Path: main, Branch: "main"},
+		{Path: nested, Branch: "feat/login"},
+		{Path: sibling, Branch: "feat/search"},
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{main, "main"},
+		{filepath.Join(nested, "src"), "feat/login"},
+		{sibling, "feat/search"},
+		{root, ""},
+		{main + "-other", ""},
+	}
+	for _, tt := range tests {
+		got := ContainingWorktree(worktrees, tt.path)
+		branch := ""
+		if got != nil {
+			branch = got.Branch
+		}
+		if branch != tt.want {
+			t.Errorf("ContainingWorktree(%q) = %q, want %q", tt.path, branch, tt.want)
+		}
+	}
+}
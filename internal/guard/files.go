@@ -0,0 +1,83 @@
+package guard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitignoreHeader precedes entries drift appends to .gitignore.
+const gitignoreHeader = "# Drift generated files (contain secrets)"
+
+// AppendGitignore appends paths to <root>/.gitignore under a drift header.
+func AppendGitignore(root string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	gitignorePath := filepath.Join(root, ".gitignore")
+
+	content := ""
+	if data, err := os.ReadFile(gitignorePath); err == nil {
+		content = string(data)
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += "\n" + gitignoreHeader + "\n"
+	for _, p := range paths {
+		content += p + "\n"
+	}
+
+	return os.WriteFile(gitignorePath, []byte(content), 0644)
+}
+
+// SeedContainsAuthUsers reports whether a seed file inserts into auth.users,
+// which usually means it carries real user emails and password hashes.
+func SeedContainsAuthUsers(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(data)), "auth.users")
+}
+
+// hookMarker identifies a pre-commit hook installed by drift.
+const hookMarker = "# installed by drift guard"
+
+const preCommitHook = `#!/bin/sh
+` + hookMarker + `
+# Blocks commits that contain Supabase keys or drift-generated secrets files.
+# Bypass (not recommended): git commit --no-verify
+if command -v drift >/dev/null 2>&1; then
+  exec drift guard scan
+fi
+echo "drift not found in PATH; skipping secret scan" >&2
+`
+
+// InstallPreCommitHook writes the drift pre-commit hook into hooksDir.
+// An existing hook not written by drift is only replaced when force is set.
+func InstallPreCommitHook(hooksDir string, force bool) (string, error) {
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+
+	if data, err := os.ReadFile(hookPath); err == nil {
+		if !strings.Contains(string(data), hookMarker) && !force {
+			return hookPath, fmt.Errorf("a pre-commit hook already exists at %s (use --force to replace it)", hookPath)
+		}
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return hookPath, fmt.Errorf("failed to create hooks dir: %w", err)
+	}
+	if err := os.WriteFile(hookPath, []byte(preCommitHook), 0755); err != nil {
+		return hookPath, fmt.Errorf("failed to write hook: %w", err)
+	}
+	return hookPath, nil
+}
+
+// IsHookInstalled reports whether the drift pre-commit hook is present in hooksDir.
+func IsHookInstalled(hooksDir string) bool {
+	data, err := os.ReadFile(filepath.Join(hooksDir, "pre-commit"))
+	return err == nil && strings.Contains(string(data), hookMarker)
+}
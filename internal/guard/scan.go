@@ -0,0 +1,117 @@
+// Package guard detects Supabase secrets before they are committed and keeps
+// drift-generated files out of version control.
+package guard
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Finding is a suspected secret in a staged change.
+type Finding struct {
+	File  string
+	Line  int
+	Rule  string
+	Match string // redacted
+}
+
+type secretRule struct {
+	name    string
+	pattern *regexp.Regexp
+	// accept filters raw matches; nil accepts all.
+	accept func(match []string) bool
+}
+
+var secretRules = []secretRule{
+	{
+		name:    "Supabase service role key",
+		pattern: regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.(eyJ[A-Za-z0-9_-]+)\.[A-Za-z0-9_-]+`),
+		accept:  func(m []string) bool { return jwtRole(m[1]) == "service_role" },
+	},
+	{
+		name:    "Supabase secret API key",
+		pattern: regexp.MustCompile(`sb_secret_[A-Za-z0-9_-]{16,}`),
+	},
+	{
+		name:    "Supabase access token",
+		pattern: regexp.MustCompile(`sbp_[a-f0-9]{40}`),
+	},
+	{
+		name:    "Database URL with password",
+		pattern: regexp.MustCompile(`postgres(?:ql)?://[^:/\s]+:([^@\s]+)@[^\s'"]*supabase\.(?:co|com)`),
+		accept: func(m []string) bool {
+			return m[1] != "[YOUR-PASSWORD]" && !strings.HasPrefix(m[1], "$")
+		},
+	},
+}
+
+// jwtRole returns the "role" claim of a base64url-encoded JWT payload.
+func jwtRole(payload string) string {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(payload, "="))
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Role string `json:"role"`
+	}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return ""
+	}
+	return claims.Role
+}
+
+// ScanLine returns the rules that match a single line of text.
+func ScanLine(file string, lineNo int, line string) []Finding {
+	var findings []Finding
+	for _, rule := range secretRules {
+		for _, m := range rule.pattern.FindAllStringSubmatch(line, -1) {
+			if rule.accept != nil && !rule.accept(m) {
+				continue
+			}
+			findings = append(findings, Finding{
+				File:  file,
+				Line:  lineNo,
+				Rule:  rule.name,
+				Match: redact(m[0]),
+			})
+		}
+	}
+	return findings
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// ScanDiff scans the added lines of a unified diff for secrets.
+func ScanDiff(diff string) []Finding {
+	var findings []Finding
+	var file string
+	var lineNo int
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+		case strings.HasPrefix(line, "@@"):
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				lineNo, _ = strconv.Atoi(m[1])
+			}
+		case strings.HasPrefix(line, "+"):
+			findings = append(findings, ScanLine(file, lineNo, line[1:])...)
+			lineNo++
+		case strings.HasPrefix(line, " "):
+			lineNo++
+		}
+	}
+	return findings
+}
+
+// redact keeps a short prefix of a secret so it can be located without being re-exposed.
+func redact(s string) string {
+	if len(s) <= 12 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:12] + "…"
+}
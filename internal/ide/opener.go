@@ -0,0 +1,150 @@
+package ide
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Placeholders substituted in opener argument templates.
+const (
+	PathPlaceholder = "{path}" // absolute worktree path
+	NamePlaceholder = "{name}" // worktree branch, or directory name when unknown
+)
+
+// Opener launches a worktree in an editor or terminal. Args and WaitArgs are
+// argument templates; WaitArgs is used instead of Args with --wait and must
+// block until the editor or window is closed. Openers without WaitArgs
+// cannot wait.
+type Opener struct {
+	Name     string
+	Command  string
+	Args     []string
+	WaitArgs []string
+}
+
+// DefaultOpener is used when no opener is configured.
+const DefaultOpener = "vscode"
+
+// BuiltinOpeners returns the openers drift knows without configuration.
+func BuiltinOpeners() map[string]Opener {
+	return map[string]Opener{
+		"vscode": {
+			Command:  "code",
+			Args:     []string{PathPlaceholder},
+			WaitArgs: []string{"--wait", PathPlaceholder},
+		},
+		"cursor": {
+			Command:  "cursor",
+			Args:     []string{PathPlaceholder},
+			WaitArgs: []string{"--wait", PathPlaceholder},
+		},
+		"finder": {
+			Command: "open",
+			Args:    []string{PathPlaceholder},
+		},
+		"terminal": {
+			Command: "open",
+			Args:    []string{"-a", "Terminal", PathPlaceholder},
+		},
+		"iterm": {
+			Command: "open",
+			Args:    []string{"-a", "iTerm", PathPlaceholder},
+		},
+		"wezterm": {
+			Command:  "wezterm",
+			Args:     []string{"cli", "spawn", "--new-window", "--cwd", PathPlaceholder},
+			WaitArgs: []string{"start", "--always-new-process", "--cwd", PathPlaceholder},
+		},
+		"tmux": {
+			Command: "tmux",
+			Args:    []string{"new-window", "-c", PathPlaceholder, "-n", NamePlaceholder},
+		},
+		"ghostty": {
+			Command:  "open",
+			Args:     []string{"-na", "Ghostty", "--args", "--working-directory=" + PathPlaceholder},
+			WaitArgs: []string{"-W", "-na", "Ghostty", "--args", "--working-directory=" + PathPlaceholder},
+		},
+	}
+}
+
+// editorAliases maps editor commands to the builtin opener for them, so a
+// preferences.editor of "code" picks the vscode opener.
+var editorAliases = map[string]string{
+	"code": "vscode",
+}
+
+// ResolveOpener returns the opener called name. Custom openers override
+// builtins of the same name. A name that is neither is treated as an editor
+// command that takes the path as its only argument, which keeps existing
+// preferences.editor values such as "zed" working.
+func ResolveOpener(name string, custom map[string]Opener) (Opener, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = DefaultOpener
+	}
+	if alias, ok := editorAliases[name]; ok {
+		if _, overridden := custom[name]; !overridden {
+			name = alias
+		}
+	}
+
+	if o, ok := custom[name]; ok {
+		if o.Command == "" {
+			return Opener{}, fmt.Errorf("opener %q has no command", name)
+		}
+		if len(o.Args) == 0 {
+			o.Args = []string{PathPlaceholder}
+		}
+		o.Name = name
+		return o, nil
+	}
+	if o, ok := BuiltinOpeners()[name]; ok {
+		o.Name = name
+		return o, nil
+	}
+	if strings.ContainsAny(name, " \t") {
+		return Opener{}, fmt.Errorf("unknown opener %q (available: %s)", name, strings.Join(OpenerNames(custom), ", "))
+	}
+	return Opener{Name: name, Command: name, Args: []string{PathPlaceholder}}, nil
+}
+
+// OpenerNames returns the builtin and custom opener names, sorted.
+func OpenerNames(custom map[string]Opener) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range BuiltinOpeners() {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range custom {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CanWait reports whether the opener can block until it is closed.
+func (o Opener) CanWait() bool {
+	return len(o.WaitArgs) > 0
+}
+
+// CommandLine returns the command line that opens path, substituting name
+// for {name}.
+func (o Opener) CommandLine(path, name string, wait bool) (string, []string, error) {
+	tmpl := o.Args
+	if wait {
+		if !o.CanWait() {
+			return "", nil, fmt.Errorf("opener %q cannot wait; configure wait_args for it", o.Name)
+		}
+		tmpl = o.WaitArgs
+	}
+	r := strings.NewReplacer(PathPlaceholder, path, NamePlaceholder, name)
+	args := make([]string, len(tmpl))
+	for i, a := range tmpl {
+		args[i] = r.Replace(a)
+	}
+	return o.Command, args, nil
+}
@@ -0,0 +1,75 @@
+package ide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveOpener(t *testing.T) {
+	custom := map[string]Opener{
+		"zed":    {Command: "zed", WaitArgs: []string{"--wait", "{path}"}},
+		"cursor": {Command: "cursor-nightly", Args: []string{"-n", "{path}"}},
+		"broken": {},
+	}
+	tests := []struct {
+		name        string
+		opener      string
+		wantCommand string
+		wantArgs    []string
+		wantErr     bool
+	}{
+		{"default", "", "code", []string{"/wt"}, false},
+		{"code alias", "code", "code", []string{"/wt"}, false},
+		{"builtin", "tmux", "tmux", []string{"new-window", "-c", "/wt", "-n", "feat/x"}, false},
+		{"custom overrides builtin", "cursor", "cursor-nightly", []string{"-n", "/wt"}, false},
+		{"custom defaults args", "zed", "zed", []string{"/wt"}, false},
+		{"plain editor command", "subl", "subl", []string{"/wt"}, false},
+		{"custom without command", "broken", "", nil, true},
+		{"unknown with spaces", "my editor", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := ResolveOpener(tt.opener, custom)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveOpener(%q) error = %v, wantErr %v", tt.opener, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			name, args, err := o.CommandLine("/wt", "feat/x", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.wantCommand || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("CommandLine() = %s %v, want %s %v", name, args, tt.wantCommand, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestOpenerWait(t *testing.T) {
+	vscode, _ := ResolveOpener("vscode", nil)
+	_, args, err := vscode.CommandLine("/wt", "main", true)
+	if err != nil || !reflect.DeepEqual(args, []string{"--wait", "/wt"}) {
+		t.Errorf("vscode wait args = %v, %v", args, err)
+	}
+
+	tmux, _ := ResolveOpener("tmux", nil)
+	if tmux.CanWait() {
+		t.Error("tmux should not support waiting")
+	}
+	if _, _, err := tmux.CommandLine("/wt", "main", true); err == nil {
+		t.Error("CommandLine(wait) should fail for an opener without wait_args")
+	}
+}
+
+func TestOpenerNames(t *testing.T) {
+	names := OpenerNames(map[string]Opener{"zed": {Command: "zed"}, "vscode": {Command: "code"}})
+	seen := map[string]int{}
+	for _, n := range names {
+		seen[n]++
+	}
+	if seen["zed"] != 1 || seen["vscode"] != 1 || seen["ghostty"] != 1 {
+		t.Errorf("OpenerNames() = %v", names)
+	}
+}
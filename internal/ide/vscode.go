@@ -0,0 +1,177 @@
+// Package ide generates editor configuration wired to drift commands.
+package ide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManagedPrefix marks task labels and launch configuration names owned by
+// drift. Entries with this prefix are replaced on regeneration; everything
+// else in the file is preserved.
+const ManagedPrefix = "drift: "
+
+// VSCodeEnvironment is an environment to generate per-environment entries for.
+type VSCodeEnvironment struct {
+	Name   string // display name, e.g. "Development"
+	Branch string // branch passed to 'drift env setup --branch'
+}
+
+// VSCodeSpec describes the project for generating VS Code configuration.
+type VSCodeSpec struct {
+	Web          bool
+	Apple        bool
+	AppName      string // process name to attach the Swift debugger to
+	Environments []VSCodeEnvironment
+}
+
+// DefaultVSCodeEnvironments are the long-lived environments drift resolves by name.
+func DefaultVSCodeEnvironments() []VSCodeEnvironment {
+	return []VSCodeEnvironment{
+		{Name: "Development", Branch: "development"},
+		{Name: "Production", Branch: "main"},
+	}
+}
+
+// EnvSetupTaskLabel returns the label of the env setup task for env (or the
+// current branch when env is nil).
+func EnvSetupTaskLabel(env *VSCodeEnvironment) string {
+	if env == nil {
+		return ManagedPrefix + "env setup"
+	}
+	return fmt.Sprintf("%senv setup (%s)", ManagedPrefix, strings.ToLower(env.Name))
+}
+
+func driftTask(label string, args ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"label":          label,
+		"type":           "shell",
+		"command":        "drift",
+		"args":           args,
+		"problemMatcher": []interface{}{},
+	}
+}
+
+// VSCodeTasks returns the drift-managed entries for .vscode/tasks.json.
+func VSCodeTasks(spec VSCodeSpec) []map[string]interface{} {
+	tasks := []map[string]interface{}{driftTask(EnvSetupTaskLabel(nil), "env", "setup")}
+	for i := range spec.Environments {
+		env := &spec.Environments[i]
+		tasks = append(tasks, driftTask(EnvSetupTaskLabel(env), "env", "setup", "--branch", env.Branch))
+	}
+
+	serve := driftTask(ManagedPrefix+"functions serve", "functions", "serve")
+	serve["isBackground"] = true
+	tasks = append(tasks, serve)
+	tasks = append(tasks, driftTask(ManagedPrefix+"migrate status", "migrate", "status"))
+
+	if spec.Apple {
+		tasks = append(tasks,
+			driftTask(ManagedPrefix+"device start", "device", "start"),
+			driftTask(ManagedPrefix+"device run", "device", "run"),
+		)
+	}
+	return tasks
+}
+
+// VSCodeLaunchConfigs returns the drift-managed entries for .vscode/launch.json.
+// Each environment gets a configuration whose preLaunchTask regenerates the
+// env config for that environment first.
+func VSCodeLaunchConfigs(spec VSCodeSpec) []map[string]interface{} {
+	envs := []*VSCodeEnvironment{nil}
+	for i := range spec.Environments {
+		envs = append(envs, &spec.Environments[i])
+	}
+	envName := func(env *VSCodeEnvironment) string {
+		if env == nil {
+			return "current branch"
+		}
+		return env.Name
+	}
+
+	var configs []map[string]interface{}
+	if spec.Web {
+		for _, env := range envs {
+			configs = append(configs, map[string]interface{}{
+				"name":          fmt.Sprintf("%sNext.js (%s)", ManagedPrefix, envName(env)),
+				"type":          "node-terminal",
+				"request":       "launch",
+				"command":       "drift run -- npm run dev",
+				"preLaunchTask": EnvSetupTaskLabel(env),
+			})
+		}
+	}
+	if spec.Apple && spec.AppName != "" {
+		for _, env := range envs {
+			configs = append(configs, map[string]interface{}{
+				"name":          fmt.Sprintf("%sAttach %s (%s)", ManagedPrefix, spec.AppName, envName(env)),
+				"type":          "swift",
+				"request":       "attach",
+				"program":       spec.AppName,
+				"waitFor":       true,
+				"preLaunchTask": EnvSetupTaskLabel(env),
+			})
+		}
+	}
+	return configs
+}
+
+// MergeManagedEntries replaces the drift-managed entries of the listKey array
+// in the JSON file at path with entries, keeping user entries after them.
+// Entries are identified by nameKey starting with ManagedPrefix. Top-level
+// defaults are set only when missing. It returns true when the file changed.
+func MergeManagedEntries(path, listKey, nameKey string, defaults map[string]interface{}, entries []map[string]interface{}) (bool, error) {
+	current := make(map[string]interface{})
+	var before []byte
+	if data, err := os.ReadFile(path); err == nil {
+		before = data
+		if strings.TrimSpace(string(data)) != "" {
+			if err := json.Unmarshal(data, &current); err != nil {
+				return false, fmt.Errorf("could not parse %s (comments are not supported; edit it manually): %w", path, err)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	for key, value := range defaults {
+		if _, ok := current[key]; !ok {
+			current[key] = value
+		}
+	}
+
+	existing, _ := current[listKey].([]interface{})
+	merged := make([]interface{}, 0, len(entries)+len(existing))
+	for _, entry := range entries {
+		merged = append(merged, entry)
+	}
+	for _, item := range existing {
+		if m, ok := item.(map[string]interface{}); ok {
+			if name, _ := m[nameKey].(string); strings.HasPrefix(name, ManagedPrefix) {
+				continue
+			}
+		}
+		merged = append(merged, item)
+	}
+	current[listKey] = merged
+
+	data, err := json.MarshalIndent(current, "", "    ")
+	if err != nil {
+		return false, err
+	}
+	data = append(data, '\n')
+	if bytes.Equal(data, before) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,123 @@
+package ide
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVSCodeTasks(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      VSCodeSpec
+		wantCount int
+		wantLabel string
+	}{
+		{"web", VSCodeSpec{Web: true, Environments: DefaultVSCodeEnvironments()}, 5, "drift: env setup (production)"},
+		{"apple", VSCodeSpec{Apple: true, Environments: DefaultVSCodeEnvironments()}, 7, "drift: device run"},
+		{"no environments", VSCodeSpec{Web: true}, 3, "drift: functions serve"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := VSCodeTasks(tt.spec)
+			if len(tasks) != tt.wantCount {
+				t.Errorf("VSCodeTasks() returned %d tasks, want %d", len(tasks), tt.wantCount)
+			}
+			found := false
+			for _, task := range tasks {
+				if task["label"] == tt.wantLabel {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("VSCodeTasks() missing %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestVSCodeLaunchConfigs(t *testing.T) {
+	spec := VSCodeSpec{Web: true, Apple: true, AppName: "Drift", Environments: DefaultVSCodeEnvironments()}
+	configs := VSCodeLaunchConfigs(spec)
+	if len(configs) != 6 {
+		t.Fatalf("VSCodeLaunchConfigs() returned %d configs, want 6", len(configs))
+	}
+
+	tasks := make(map[string]bool)
+	for _, task := range VSCodeTasks(spec) {
+		tasks[task["label"].(string)] = true
+	}
+	for _, c := range configs {
+		if pre := c["preLaunchTask"].(string); !tasks[pre] {
+			t.Errorf("%s: preLaunchTask %q is not a generated task", c["name"], pre)
+		}
+	}
+
+	if got := VSCodeLaunchConfigs(VSCodeSpec{Apple: true}); len(got) != 0 {
+		t.Errorf("Apple spec without an app name produced %d configs, want 0", len(got))
+	}
+}
+
+func TestMergeManagedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".vscode", "tasks.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	existing := `{"version": "2.0.0", "tasks": [
+		{"label": "drift: stale", "command": "drift"},
+		{"label": "lint", "command": "npm run lint"}
+	]}`
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []map[string]interface{}{{"label": "drift: env setup", "command": "drift"}}
+	changed, err := MergeManagedEntries(path, "tasks", "label", map[string]interface{}{"version": "9"}, entries)
+	if err != nil {
+		t.Fatalf("MergeManagedEntries() error = %v", err)
+	}
+	if !changed {
+		t.Error("first merge reported no change")
+	}
+
+	changed, err = MergeManagedEntries(path, "tasks", "label", nil, entries)
+	if err != nil {
+		t.Fatalf("MergeManagedEntries() error = %v", err)
+	}
+	if changed {
+		t.Error("second merge reported a change")
+	}
+
+	data, _ := os.ReadFile(path)
+	var got struct {
+		Version string `json:"version"`
+		Tasks   []struct {
+			Label string `json:"label"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("tasks.json is not valid JSON: %v", err)
+	}
+	if got.Version != "2.0.0" {
+		t.Errorf("version = %q, want existing 2.0.0 kept", got.Version)
+	}
+	var labels []string
+	for _, task := range got.Tasks {
+		labels = append(labels, task.Label)
+	}
+	if len(labels) != 2 || labels[0] != "drift: env setup" || labels[1] != "lint" {
+		t.Errorf("labels = %v, want [drift: env setup lint]", labels)
+	}
+}
+
+func TestMergeManagedEntries_RejectsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "launch.json")
+	if err := os.WriteFile(path, []byte("{\n  // comment\n}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergeManagedEntries(path, "configurations", "name", nil, nil); err == nil {
+		t.Error("MergeManagedEntries() accepted a file with comments")
+	}
+}
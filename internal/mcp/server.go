@@ -0,0 +1,240 @@
+// Package mcp implements a minimal Model Context Protocol server over stdio.
+//
+// Only the tools capability is supported: clients can list tools and call
+// them. Messages are newline-delimited JSON-RPC 2.0.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ProtocolVersion is the MCP revision this server implements.
+const ProtocolVersion = "2024-11-05"
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Handler runs a tool call. args is the raw "arguments" object (may be empty).
+// The returned value is encoded as JSON text content.
+type Handler func(args json.RawMessage) (interface{}, error)
+
+// Tool is a callable tool exposed to clients.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]interface{}
+	Handler     Handler
+}
+
+// Server dispatches MCP requests to registered tools.
+type Server struct {
+	name    string
+	version string
+	tools   []Tool
+	byName  map[string]int
+
+	mu sync.Mutex // serializes writes
+}
+
+// NewServer creates a server that reports name and version to clients.
+func NewServer(name, version string) *Server {
+	return &Server{name: name, version: version, byName: make(map[string]int)}
+}
+
+// AddTool registers a tool. A nil InputSchema accepts no arguments.
+func (s *Server) AddTool(tool Tool) {
+	if tool.InputSchema == nil {
+		tool.InputSchema = ObjectSchema(nil)
+	}
+	s.byName[tool.Name] = len(s.tools)
+	s.tools = append(s.tools, tool)
+}
+
+// ObjectSchema builds a JSON Schema object with the given properties and
+// required property names.
+func ObjectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// StringProperty returns a JSON Schema string property.
+func StringProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+// BoolProperty returns a JSON Schema boolean property.
+func BoolProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "boolean", "description": description}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+// Serve reads requests from r and writes responses to w until r is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if resp := s.handle(line); resp != nil {
+			if err := s.write(w, resp); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) write(w io.Writer, resp *response) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// handle processes one JSON-RPC message and returns the response to send,
+// or nil for notifications.
+func (s *Server) handle(message []byte) *response {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParseError, "parse error")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if len(req.ID) == 0 {
+			return nil
+		}
+		return errorResponse(req.ID, codeInvalidRequest, "invalid request")
+	}
+
+	// Notifications (no id) never get a response.
+	isNotification := len(req.ID) == 0
+
+	var result interface{}
+	var rerr *rpcError
+	switch req.Method {
+	case "initialize":
+		result = map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": s.name, "version": s.version},
+		}
+	case "ping":
+		result = map[string]interface{}{}
+	case "tools/list":
+		result = map[string]interface{}{"tools": s.listTools()}
+	case "tools/call":
+		result, rerr = s.callTool(req.Params)
+	default:
+		if isNotification {
+			return nil
+		}
+		rerr = &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+
+	if isNotification {
+		return nil
+	}
+	if rerr != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rerr}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) listTools() []map[string]interface{} {
+	tools := make([]map[string]interface{}, len(s.tools))
+	for i, t := range s.tools {
+		tools[i] = map[string]interface{}{
+			"name":        t.Name,
+			"description": t.Description,
+			"inputSchema": t.InputSchema,
+		}
+	}
+	return tools
+}
+
+// callTool runs a tool. Tool failures are reported in the result with
+// isError set, as the protocol expects; only malformed calls are RPC errors.
+func (s *Server) callTool(params json.RawMessage) (interface{}, *rpcError) {
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &call); err != nil || call.Name == "" {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "tools/call requires a tool name"}
+	}
+	idx, ok := s.byName[call.Name]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", call.Name)}
+	}
+
+	args := call.Arguments
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	value, err := s.tools[idx].Handler(args)
+	if err != nil {
+		return callResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return callResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return callResult{Content: []textContent{{Type: "text", Text: string(text)}}}, nil
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
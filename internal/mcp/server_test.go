@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testServer() *Server {
+	s := NewServer("drift", "test")
+	s.AddTool(Tool{
+		Name:        "echo",
+		Description: "Echo the name argument",
+		InputSchema: ObjectSchema(map[string]interface{}{"name": StringProperty("Name")}, "name"),
+		Handler: func(args json.RawMessage) (interface{}, error) {
+			var in struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(args, &in); err != nil {
+				return nil, err
+			}
+			return map[string]string{"name": in.Name}, nil
+		},
+	})
+	s.AddTool(Tool{
+		Name: "fail",
+		Handler: func(json.RawMessage) (interface{}, error) {
+			return nil, errors.New("boom")
+		},
+	})
+	return s
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantNil   bool
+		wantCode  int
+		wantInRes string
+	}{
+		{name: "initialize", message: `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`, wantInRes: `"protocolVersion":"2024-11-05"`},
+		{name: "initialized notification", message: `{"jsonrpc":"2.0","method":"notifications/initialized"}`, wantNil: true},
+		{name: "ping", message: `{"jsonrpc":"2.0","id":"a","method":"ping"}`, wantInRes: `"result":{}`},
+		{name: "list tools", message: `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, wantInRes: `"name":"echo"`},
+		{name: "call tool", message: `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"name":"feat-x"}}}`, wantInRes: `feat-x`},
+		{name: "tool error", message: `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"fail"}}`, wantInRes: `"isError":true`},
+		{name: "unknown tool", message: `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"nope"}}`, wantCode: codeInvalidParams},
+		{name: "unknown method", message: `{"jsonrpc":"2.0","id":6,"method":"resources/list"}`, wantCode: codeMethodNotFound},
+		{name: "parse error", message: `{not json`, wantCode: codeParseError},
+		{name: "invalid request", message: `{"id":7,"method":"ping"}`, wantCode: codeInvalidRequest},
+	}
+
+	s := testServer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := s.handle([]byte(tt.message))
+			if tt.wantNil {
+				if resp != nil {
+					t.Fatalf("handle() = %+v, want nil", resp)
+				}
+				return
+			}
+			if resp == nil {
+				t.Fatal("handle() = nil, want response")
+			}
+			if tt.wantCode != 0 {
+				if resp.Error == nil || resp.Error.Code != tt.wantCode {
+					t.Fatalf("handle() error = %+v, want code %d", resp.Error, tt.wantCode)
+				}
+				return
+			}
+			data, _ := json.Marshal(resp)
+			if !strings.Contains(string(data), tt.wantInRes) {
+				t.Errorf("handle() = %s, want it to contain %s", data, tt.wantInRes)
+			}
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"name":"x"}}}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := testServer().Serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Serve() wrote %d responses, want 2:\n%s", len(lines), out.String())
+	}
+	var resp struct {
+		ID     int `json:"id"`
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", lines[1], err)
+	}
+	if resp.ID != 2 || len(resp.Result.Content) != 1 || !strings.Contains(resp.Result.Content[0].Text, `"name": "x"`) {
+		t.Errorf("unexpected tools/call response: %s", lines[1])
+	}
+}
@@ -0,0 +1,131 @@
+// Package metrics times the running drift command and the external
+// processes and API calls it makes, so 'drift metrics' can show where the
+// time went. Nothing identifying is recorded: only the command path, the
+// drift version, program names, and API hosts.
+package metrics
+
+import (
+	"net/http"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kinds of external call.
+const (
+	KindExec = "exec"
+	KindHTTP = "http"
+)
+
+// Call is the total time spent in one external program or API host.
+type Call struct {
+	Kind     string        `json:"kind"`
+	Name     string        `json:"name"`
+	Count    int           `json:"count"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Run is the timing of one drift command.
+type Run struct {
+	Command  string        `json:"command"`
+	Version  string        `json:"version"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Failed   bool          `json:"failed,omitempty"`
+	Calls    []Call        `json:"calls,omitempty"`
+}
+
+// External returns the summed duration of all external calls. Calls made
+// in parallel can add up to more than the run's wall time.
+func (r *Run) External() time.Duration {
+	var total time.Duration
+	for _, c := range r.Calls {
+		total += c.Duration
+	}
+	return total
+}
+
+type callKey struct {
+	kind string
+	name string
+}
+
+var (
+	mu      sync.Mutex
+	current *Run
+	calls   map[callKey]*Call
+)
+
+// Begin starts timing command. Calls recorded before Begin are dropped.
+func Begin(command, version string) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = &Run{Command: command, Version: version, Start: time.Now()}
+	calls = make(map[callKey]*Call)
+}
+
+// Record adds d to the time spent in the external program or host name.
+// It is safe for concurrent use and does nothing outside Begin/End.
+func Record(kind, name string, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		return
+	}
+	if kind == KindExec {
+		name = filepath.Base(name)
+	}
+	key := callKey{kind: kind, name: name}
+	c, ok := calls[key]
+	if !ok {
+		c = &Call{Kind: kind, Name: name}
+		calls[key] = c
+	}
+	c.Count++
+	c.Duration += d
+}
+
+// End stops timing and returns the run, with calls ordered by time spent.
+// It returns nil if Begin was not called.
+func End(err error) *Run {
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		return nil
+	}
+	run := current
+	run.Duration = time.Since(run.Start)
+	run.Failed = err != nil
+	for _, c := range calls {
+		run.Calls = append(run.Calls, *c)
+	}
+	sort.Slice(run.Calls, func(i, j int) bool {
+		if run.Calls[i].Duration != run.Calls[j].Duration {
+			return run.Calls[i].Duration > run.Calls[j].Duration
+		}
+		return run.Calls[i].Name < run.Calls[j].Name
+	})
+	current, calls = nil, nil
+	return run
+}
+
+// Transport wraps base (http.DefaultTransport if nil) so each request's
+// time is recorded against its host.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper{base: base}
+}
+
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+func (t roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	Record(KindHTTP, req.URL.Host, time.Since(start))
+	return resp, err
+}
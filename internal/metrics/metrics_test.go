@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecorder_AggregatesCalls(t *testing.T) {
+	Record(KindExec, "git", time.Second) // before Begin: dropped
+
+	Begin("drift deploy all", "1.2.0")
+	Record(KindExec, "/usr/local/bin/supabase", 3*time.Second)
+	Record(KindExec, "supabase", 2*time.Second)
+	Record(KindExec, "git", 100*time.Millisecond)
+	Record(KindHTTP, "api.supabase.com", 500*time.Millisecond)
+	run := End(errors.New("boom"))
+
+	if run == nil || run.Command != "drift deploy all" || run.Version != "1.2.0" || !run.Failed {
+		t.Fatalf("End() = %+v", run)
+	}
+	want := []Call{
+		{Kind: KindExec, Name: "supabase", Count: 2, Duration: 5 * time.Second},
+		{Kind: KindHTTP, Name: "api.supabase.com", Count: 1, Duration: 500 * time.Millisecond},
+		{Kind: KindExec, Name: "git", Count: 1, Duration: 100 * time.Millisecond},
+	}
+	if len(run.Calls) != len(want) {
+		t.Fatalf("Calls = %+v, want %+v", run.Calls, want)
+	}
+	for i := range want {
+		if run.Calls[i] != want[i] {
+			t.Errorf("Calls[%d] = %+v, want %+v", i, run.Calls[i], want[i])
+		}
+	}
+	if got := run.External(); got != 5600*time.Millisecond {
+		t.Errorf("External() = %s, want 5.6s", got)
+	}
+	if End(nil) != nil {
+		t.Error("second End() should return nil")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	runs := []Run{
+		{Command: "drift env setup", Version: "1.0.0", Duration: 4 * time.Second, Calls: []Call{{Name: "supabase", Duration: 3 * time.Second}}},
+		{Command: "drift env setup", Version: "1.0.0", Duration: 6 * time.Second, Failed: true, Calls: []Call{{Name: "supabase", Duration: 5 * time.Second}}},
+		{Command: "drift env setup", Version: "1.1.0", Duration: time.Second, Calls: []Call{{Name: "api.supabase.com", Duration: 800 * time.Millisecond}}},
+		{Command: "drift deploy all", Version: "1.1.0", Duration: 9 * time.Second},
+	}
+
+	got := Summarize(runs)
+	if len(got) != 3 {
+		t.Fatalf("Summarize() returned %d summaries, want 3: %+v", len(got), got)
+	}
+	if got[0].Command != "drift deploy all" {
+		t.Errorf("first summary = %q, want commands sorted", got[0].Command)
+	}
+	old, upgraded := got[1], got[2]
+	if old.Version != "1.0.0" || upgraded.Version != "1.1.0" {
+		t.Fatalf("versions = %s, %s; want oldest first", old.Version, upgraded.Version)
+	}
+	if old.Runs != 2 || old.Failed != 1 || old.Median != 5*time.Second || old.Max != 6*time.Second {
+		t.Errorf("1.0.0 summary = %+v", old)
+	}
+	if old.External != 4*time.Second || old.Top != "supabase" {
+		t.Errorf("1.0.0 external = %s top %q, want 4s supabase", old.External, old.Top)
+	}
+	if upgraded.Median != time.Second || upgraded.Top != "api.supabase.com" {
+		t.Errorf("1.1.0 summary = %+v", upgraded)
+	}
+}
+
+func TestHistoryRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	run := &Run{Command: "drift status", Version: "dev", Start: time.Now().UTC(), Duration: time.Second}
+
+	if last, err := LoadLast(root); err != nil || last != nil {
+		t.Fatalf("LoadLast() on empty project = %+v, %v", last, err)
+	}
+	if err := SaveLast(root, run); err != nil {
+		t.Fatalf("SaveLast() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := AppendHistory(root, run); err != nil {
+			t.Fatalf("AppendHistory() error = %v", err)
+		}
+	}
+
+	last, err := LoadLast(root)
+	if err != nil || last == nil || last.Command != "drift status" {
+		t.Fatalf("LoadLast() = %+v, %v", last, err)
+	}
+	history, err := ReadHistory(root)
+	if err != nil || len(history) != 2 {
+		t.Fatalf("ReadHistory() = %d runs, %v; want 2", len(history), err)
+	}
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/undrift/drift/internal/state"
+)
+
+const (
+	lastFile    = "last.json"
+	historyFile = "history.jsonl"
+)
+
+// SaveLast stores run as the project's most recent command.
+func SaveLast(projectRoot string, run *Run) error {
+	return state.WriteJSON(projectRoot, state.Path(projectRoot, state.Metrics, lastFile), run)
+}
+
+// LoadLast returns the project's most recent command, or nil if none was
+// recorded.
+func LoadLast(projectRoot string) (*Run, error) {
+	var run Run
+	ok, err := state.ReadJSON(state.Path(projectRoot, state.Metrics, lastFile), &run)
+	if !ok || err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// AppendHistory adds run to the project's opt-in history as a JSON line.
+func AppendHistory(projectRoot string, run *Run) error {
+	if err := state.Ensure(projectRoot); err != nil {
+		return err
+	}
+	path := state.Path(projectRoot, state.Metrics, historyFile)
+	if err := os.MkdirAll(state.Path(projectRoot, state.Metrics), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// ReadHistory returns the recorded history, oldest first. Malformed lines
+// are skipped.
+func ReadHistory(projectRoot string) ([]Run, error) {
+	f, err := os.Open(state.Path(projectRoot, state.Metrics, historyFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Run
+		if err := json.Unmarshal(scanner.Bytes(), &r); err == nil {
+			runs = append(runs, r)
+		}
+	}
+	return runs, scanner.Err()
+}
+
+// Summary aggregates the runs of one command on one drift version.
+type Summary struct {
+	Command  string        `json:"command"`
+	Version  string        `json:"version"`
+	Runs     int           `json:"runs"`
+	Failed   int           `json:"failed"`
+	Median   time.Duration `json:"median"`
+	Max      time.Duration `json:"max"`
+	External time.Duration `json:"external"` // median external time
+	// Top is the external program or host with the most total time.
+	Top string `json:"top,omitempty"`
+}
+
+// Summarize groups runs by command and version, ordered by command and
+// then by the version's first appearance, so releases read oldest first.
+func Summarize(runs []Run) []Summary {
+	type group struct {
+		summary   Summary
+		first     int
+		durations []time.Duration
+		external  []time.Duration
+		byCall    map[string]time.Duration
+	}
+	groups := make(map[[2]string]*group)
+	for i, r := range runs {
+		key := [2]string{r.Command, r.Version}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{summary: Summary{Command: r.Command, Version: r.Version}, first: i, byCall: make(map[string]time.Duration)}
+			groups[key] = g
+		}
+		g.summary.Runs++
+		if r.Failed {
+			g.summary.Failed++
+		}
+		if r.Duration > g.summary.Max {
+			g.summary.Max = r.Duration
+		}
+		g.durations = append(g.durations, r.Duration)
+		g.external = append(g.external, r.External())
+		for _, c := range r.Calls {
+			g.byCall[c.Name] += c.Duration
+		}
+	}
+
+	ordered := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		g.summary.Median = median(g.durations)
+		g.summary.External = median(g.external)
+		var top time.Duration
+		for name, d := range g.byCall {
+			if d > top || (d == top && name < g.summary.Top) {
+				top, g.summary.Top = d, name
+			}
+		}
+		ordered = append(ordered, g)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].summary.Command != ordered[j].summary.Command {
+			return ordered[i].summary.Command < ordered[j].summary.Command
+		}
+		return ordered[i].first < ordered[j].first
+	})
+
+	summaries := make([]Summary, len(ordered))
+	for i, g := range ordered {
+		summaries[i] = g.summary
+	}
+	return summaries
+}
+
+func median(values []time.Duration) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,156 @@
+// Package notify posts deploy and database operation results to Slack or a
+// generic webhook.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event describes one finished operation.
+type Event struct {
+	Project     string        `json:"project,omitempty"`
+	Operation   string        `json:"operation"` // e.g. "deploy all", "db push"
+	Environment string        `json:"environment"`
+	Branch      string        `json:"branch"`
+	Actor       string        `json:"actor"`
+	Duration    time.Duration `json:"-"`
+	Err         error         `json:"-"`
+}
+
+// Succeeded reports whether the operation finished without error.
+func (e Event) Succeeded() bool {
+	return e.Err == nil
+}
+
+// Summary returns a one-line description, e.g. "db push to feature-x succeeded".
+func (e Event) Summary() string {
+	status := "succeeded"
+	if !e.Succeeded() {
+		status = "failed"
+	}
+	summary := fmt.Sprintf("%s to %s %s", e.Operation, e.Branch, status)
+	if e.Project != "" {
+		summary = fmt.Sprintf("[%s] %s", e.Project, summary)
+	}
+	return summary
+}
+
+// Notifier sends events to the configured destinations.
+type Notifier struct {
+	SlackWebhook string
+	Webhook      string
+	HTTPClient   *http.Client
+}
+
+// Enabled reports whether any destination is configured.
+func (n Notifier) Enabled() bool {
+	return n.SlackWebhook != "" || n.Webhook != ""
+}
+
+// Send posts the event to every configured destination and returns the
+// first error encountered.
+func (n Notifier) Send(e Event) error {
+	var firstErr error
+	if n.SlackWebhook != "" {
+		if err := n.post(n.SlackWebhook, SlackPayload(e)); err != nil {
+			firstErr = fmt.Errorf("slack: %w", err)
+		}
+	}
+	if n.Webhook != "" {
+		if err := n.post(n.Webhook, WebhookPayload(e)); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("webhook: %w", err)
+		}
+	}
+	return firstErr
+}
+
+// SlackPayload builds an incoming-webhook message with the event fields.
+func SlackPayload(e Event) map[string]interface{} {
+	color := "good"
+	icon := ":white_check_mark:"
+	if !e.Succeeded() {
+		color = "danger"
+		icon = ":x:"
+	}
+
+	fields := []map[string]interface{}{
+		{"title": "Environment", "value": e.Environment, "short": true},
+		{"title": "Branch", "value": e.Branch, "short": true},
+		{"title": "Actor", "value": e.Actor, "short": true},
+		{"title": "Duration", "value": formatDuration(e.Duration), "short": true},
+	}
+	if e.Err != nil {
+		fields = append(fields, map[string]interface{}{"title": "Error", "value": truncate(e.Err.Error(), 500), "short": false})
+	}
+
+	return map[string]interface{}{
+		"text": fmt.Sprintf("%s %s", icon, e.Summary()),
+		"attachments": []map[string]interface{}{
+			{"color": color, "fields": fields},
+		},
+	}
+}
+
+// WebhookPayload builds the JSON body for a generic webhook.
+func WebhookPayload(e Event) map[string]interface{} {
+	payload := map[string]interface{}{
+		"operation":        e.Operation,
+		"status":           "success",
+		"environment":      e.Environment,
+		"branch":           e.Branch,
+		"actor":            e.Actor,
+		"duration_seconds": int(e.Duration.Round(time.Second).Seconds()),
+		"summary":          e.Summary(),
+	}
+	if e.Project != "" {
+		payload["project"] = e.Project
+	}
+	if e.Err != nil {
+		payload["status"] = "failure"
+		payload["error"] = e.Err.Error()
+	}
+	return payload
+}
+
+func (n Notifier) post(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	client := n.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return "<1s"
+	}
+	return d.Round(time.Second).String()
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{"success", Event{Operation: "db push", Branch: "feature-x"}, "db push to feature-x succeeded"},
+		{"failure", Event{Operation: "deploy all", Branch: "main", Err: errors.New("boom")}, "deploy all to main failed"},
+		{"project", Event{Project: "app", Operation: "db push", Branch: "dev"}, "[app] db push to dev succeeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookPayload(t *testing.T) {
+	payload := WebhookPayload(Event{Operation: "migrate push", Environment: "Production", Branch: "main", Actor: "alice", Duration: 90 * time.Second, Err: errors.New("syntax error")})
+	if payload["status"] != "failure" || payload["error"] != "syntax error" || payload["duration_seconds"] != 90 {
+		t.Errorf("WebhookPayload() = %v", payload)
+	}
+	if _, ok := payload["project"]; ok {
+		t.Error("WebhookPayload() included an empty project")
+	}
+}
+
+func TestNotifierSend(t *testing.T) {
+	var slackBody, hookBody map[string]interface{}
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &slackBody)
+	}))
+	defer slack.Close()
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &hookBody)
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer hook.Close()
+
+	n := Notifier{SlackWebhook: slack.URL, Webhook: hook.URL}
+	err := n.Send(Event{Operation: "db push", Environment: "Feature", Branch: "feature-x", Actor: "alice"})
+	if err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("Send() error = %v, want webhook status 403", err)
+	}
+	if text, _ := slackBody["text"].(string); !strings.Contains(text, "db push to feature-x succeeded") {
+		t.Errorf("slack text = %q", text)
+	}
+	if hookBody["actor"] != "alice" {
+		t.Errorf("webhook body = %v", hookBody)
+	}
+
+	if (Notifier{}).Enabled() {
+		t.Error("empty Notifier reports Enabled")
+	}
+}
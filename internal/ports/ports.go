@@ -0,0 +1,179 @@
+// Package ports assigns each worktree its own local ports for dev servers
+// and tools, so several worktrees can run side by side. Assignments are kept
+// in .drift/ports.json of the main worktree and are stable: a worktree keeps
+// its ports until it is removed.
+package ports
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/undrift/drift/internal/state"
+)
+
+// searchRange is how far above its base port a service's port may be.
+const searchRange = 1000
+
+// Registry maps worktree paths to their ports, keyed by env var name.
+type Registry struct {
+	Worktrees map[string]map[string]int `json:"worktrees"`
+}
+
+// Path returns the registry file for the main worktree at mainRoot.
+func Path(mainRoot string) string {
+	return state.Path(mainRoot, state.Ports)
+}
+
+// Load reads the registry for mainRoot. A missing file is an empty registry.
+func Load(mainRoot string) (*Registry, error) {
+	r := &Registry{}
+	if _, err := state.ReadJSON(Path(mainRoot), r); err != nil {
+		return nil, err
+	}
+	if r.Worktrees == nil {
+		r.Worktrees = make(map[string]map[string]int)
+	}
+	return r, nil
+}
+
+// Save writes the registry for mainRoot.
+func (r *Registry) Save(mainRoot string) error {
+	return state.WriteJSON(mainRoot, Path(mainRoot), r)
+}
+
+// Key returns the registry key for a worktree path: the absolute path with
+// symlinks resolved, so paths from git and from the working directory agree.
+func Key(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// Lookup returns the ports of the worktree at wtPath.
+func (r *Registry) Lookup(wtPath string) map[string]int {
+	return r.Worktrees[Key(wtPath)]
+}
+
+// Prune drops worktrees whose directory no longer exists and returns their
+// paths.
+func (r *Registry) Prune() []string {
+	var removed []string
+	for path := range r.Worktrees {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			delete(r.Worktrees, path)
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// Allocate returns the ports of the worktree at wtPath for each service in
+// bases (env var name -> base port). Ports it already has are kept; a new
+// service gets the first port at or above its base that no other worktree
+// holds and available reports free. Services no longer in bases are
+// dropped. changed reports whether the registry needs saving.
+func (r *Registry) Allocate(wtPath string, bases map[string]int, available func(int) bool) (assigned map[string]int, changed bool, err error) {
+	wtPath = Key(wtPath)
+	taken := make(map[int]bool)
+	for path, ports := range r.Worktrees {
+		if path == wtPath {
+			continue
+		}
+		for _, p := range ports {
+			taken[p] = true
+		}
+	}
+
+	current := r.Worktrees[wtPath]
+	assigned = make(map[string]int, len(bases))
+	for name := range bases {
+		if p, ok := current[name]; ok && !taken[p] {
+			assigned[name] = p
+			taken[p] = true
+		}
+	}
+
+	for _, name := range sortedKeys(bases) {
+		if _, ok := assigned[name]; ok {
+			continue
+		}
+		base := bases[name]
+		port := 0
+		for p := base; p < base+searchRange && p <= 65535; p++ {
+			if !taken[p] && available(p) {
+				port = p
+				break
+			}
+		}
+		if port == 0 {
+			return nil, false, fmt.Errorf("no free port for %s in %d-%d", name, base, base+searchRange-1)
+		}
+		assigned[name] = port
+		taken[port] = true
+	}
+
+	if !equalPorts(current, assigned) {
+		if len(assigned) == 0 {
+			delete(r.Worktrees, wtPath)
+		} else {
+			r.Worktrees[wtPath] = assigned
+		}
+		changed = true
+	}
+	return assigned, changed, nil
+}
+
+// Release removes the worktree at wtPath. It reports whether it had ports.
+func (r *Registry) Release(wtPath string) bool {
+	wtPath = Key(wtPath)
+	if _, ok := r.Worktrees[wtPath]; !ok {
+		return false
+	}
+	delete(r.Worktrees, wtPath)
+	return true
+}
+
+// Available reports whether port can be bound on localhost.
+func Available(port int) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
+
+// Names returns the env var names in ports, sorted.
+func Names(ports map[string]int) []string {
+	return sortedKeys(ports)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func equalPorts(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
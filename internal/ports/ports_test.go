@@ -0,0 +1,90 @@
+package ports
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	root := t.TempDir()
+	main, feat, fix := filepath.Join(root, "app"), filepath.Join(root, "app-feat"), filepath.Join(root, "app-fix")
+	for _, dir := range []string{main, feat, fix} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	busy := map[int]bool{3001: true}
+	available := func(p int) bool { return !busy[p] }
+	bases := map[string]int{"PORT": 3000, "STORYBOOK_PORT": 6006}
+
+	r := &Registry{Worktrees: make(map[string]map[string]int)}
+	got, changed, err := r.Allocate(main, bases, available)
+	if err != nil || !changed {
+		t.Fatalf("Allocate(main) changed = %v, err = %v", changed, err)
+	}
+	if want := map[string]int{"PORT": 3000, "STORYBOOK_PORT": 6006}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Allocate(main) = %v, want %v", got, want)
+	}
+
+	// 3000 is held by main and 3001 is in use by another process.
+	got, _, err = r.Allocate(feat, bases, available)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"PORT": 3002, "STORYBOOK_PORT": 6007}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Allocate(feat) = %v, want %v", got, want)
+	}
+
+	// Assignments are stable even once the port is busy (its own dev server).
+	busy[3000] = true
+	got, changed, err = r.Allocate(main, bases, available)
+	if err != nil || changed || got["PORT"] != 3000 {
+		t.Errorf("Allocate(main) again = %v, changed = %v, err = %v; want PORT 3000 unchanged", got, changed, err)
+	}
+
+	// Removed services are dropped.
+	got, changed, _ = r.Allocate(feat, map[string]int{"PORT": 3000}, available)
+	if !changed || !reflect.DeepEqual(got, map[string]int{"PORT": 3002}) {
+		t.Errorf("Allocate(feat, PORT only) = %v, changed = %v", got, changed)
+	}
+
+	if err := os.Remove(fix); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.Allocate(fix, bases, available); err != nil {
+		t.Fatal(err)
+	}
+	if removed := r.Prune(); !reflect.DeepEqual(removed, []string{Key(fix)}) {
+		t.Errorf("Prune() = %v, want [%s]", removed, Key(fix))
+	}
+	if !r.Release(feat) || r.Lookup(feat) != nil {
+		t.Error("Release(feat) did not remove its ports")
+	}
+}
+
+func TestAllocate_NoFreePort(t *testing.T) {
+	r := &Registry{Worktrees: make(map[string]map[string]int)}
+	_, _, err := r.Allocate(t.TempDir(), map[string]int{"PORT": 3000}, func(int) bool { return false })
+	if err == nil {
+		t.Error("Allocate() with no free ports: expected error")
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	root := t.TempDir()
+	r, err := Load(root)
+	if err != nil || len(r.Worktrees) != 0 {
+		t.Fatalf("Load() of missing registry = %+v, %v", r, err)
+	}
+	r.Worktrees["/work/app"] = map[string]int{"PORT": 3000}
+	if err := r.Save(root); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(root)
+	if err != nil || !reflect.DeepEqual(loaded, r) {
+		t.Errorf("Load() = %+v, %v; want %+v", loaded, err, r)
+	}
+}
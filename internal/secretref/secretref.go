@@ -0,0 +1,131 @@
+// Package secretref resolves env values that point into an external secret
+// manager instead of holding the secret:
+//
+//	op://vault/item/field   read with the 1Password CLI (op read)
+//	vault:kv/path#key       read with the HashiCorp Vault CLI (vault kv get)
+//
+// The reference is what stays in env files; the value is fetched when a
+// command needs it, so the plaintext is never written to disk.
+package secretref
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// Prefixes that mark a value as a reference.
+const (
+	OnePasswordPrefix = "op://"
+	VaultPrefix       = "vault:"
+)
+
+// Reference is a parsed secret manager reference.
+type Reference struct {
+	// Manager is "1password" or "vault".
+	Manager string
+	// Path is the op:// URI for 1Password, or the secret path for Vault.
+	Path string
+	// Key is the Vault field; empty for 1Password, whose URI names the field.
+	Key string
+}
+
+// Tool returns the CLI that resolves r.
+func (r Reference) Tool() string {
+	if r.Manager == "vault" {
+		return "vault"
+	}
+	return "op"
+}
+
+// IsReference reports whether value is a secret manager reference.
+func IsReference(value string) bool {
+	_, ok, _ := Parse(value)
+	return ok
+}
+
+// Parse parses value as a reference. ok is false for ordinary values; err
+// is set for values that look like a reference but are malformed.
+func Parse(value string) (ref Reference, ok bool, err error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, OnePasswordPrefix):
+		parts := strings.Split(strings.TrimPrefix(value, OnePasswordPrefix), "/")
+		if len(parts) < 3 || parts[0] == "" || parts[len(parts)-1] == "" {
+			return ref, true, fmt.Errorf("invalid 1Password reference %q (use op://vault/item/field)", value)
+		}
+		return Reference{Manager: "1password", Path: value}, true, nil
+	case strings.HasPrefix(value, VaultPrefix):
+		path, key, found := strings.Cut(strings.TrimPrefix(value, VaultPrefix), "#")
+		if !found || path == "" || key == "" {
+			return ref, true, fmt.Errorf("invalid Vault reference %q (use vault:path/to/secret#key)", value)
+		}
+		return Reference{Manager: "vault", Path: path, Key: key}, true, nil
+	}
+	return ref, false, nil
+}
+
+// Resolve returns the secret value references. Ordinary values are
+// returned unchanged.
+func Resolve(value string) (string, error) {
+	ref, ok, err := Parse(value)
+	if !ok || err != nil {
+		return value, err
+	}
+	if !shell.CommandExists(ref.Tool()) {
+		return "", fmt.Errorf("%s CLI not found in PATH (needed for %s)", ref.Tool(), value)
+	}
+
+	var args []string
+	if ref.Manager == "vault" {
+		args = []string{"kv", "get", "-field=" + ref.Key, ref.Path}
+	} else {
+		args = []string{"read", ref.Path}
+	}
+	result, err := shell.Run(ref.Tool(), args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", value, err)
+	}
+	if result.ExitCode != 0 {
+		msg := result.Stderr
+		if msg == "" {
+			msg = fmt.Sprintf("%s exited with code %d", ref.Tool(), result.ExitCode)
+		}
+		return "", fmt.Errorf("failed to read %s: %s", value, msg)
+	}
+	return result.Stdout, nil
+}
+
+// ResolveAll returns a copy of values with every reference resolved. All
+// failures are reported together, by key.
+func ResolveAll(values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(values))
+	var failed []string
+	for key, value := range values {
+		secret, err := Resolve(value)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		resolved[key] = secret
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return nil, fmt.Errorf("could not resolve secret references:\n  %s", strings.Join(failed, "\n  "))
+	}
+	return resolved, nil
+}
+
+// Keys returns the sorted keys of values that hold references.
+func Keys(values map[string]string) []string {
+	var keys []string
+	for key, value := range values {
+		if IsReference(value) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
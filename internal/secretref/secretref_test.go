@@ -0,0 +1,76 @@
+package secretref
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Reference
+		ok      bool
+		wantErr bool
+	}{
+		{value: "op://Dev/Stripe/secret key", want: Reference{Manager: "1password", Path: "op://Dev/Stripe/secret key"}, ok: true},
+		{value: "op://Dev/Stripe/section/field", want: Reference{Manager: "1password", Path: "op://Dev/Stripe/section/field"}, ok: true},
+		{value: "vault:kv/apps/web#sentry_dsn", want: Reference{Manager: "vault", Path: "kv/apps/web", Key: "sentry_dsn"}, ok: true},
+		{value: "op://Dev/Stripe", ok: true, wantErr: true},
+		{value: "vault:kv/apps/web", ok: true, wantErr: true},
+		{value: "https://example.com"},
+		{value: "sk_test_123"},
+	}
+	for _, tt := range tests {
+		ref, ok, err := Parse(tt.value)
+		if ok != tt.ok || (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) ok = %v, err = %v", tt.value, ok, err)
+			continue
+		}
+		if !tt.wantErr && ref != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.value, ref, tt.want)
+		}
+	}
+}
+
+func TestResolveAll(t *testing.T) {
+	op := testutil.NewFakeBin(t, "op",
+		testutil.Response{Args: "read op://Dev/Stripe/secret", Stdout: "sk_live_abc\n"},
+		testutil.Response{Args: "read op://Dev/Missing/field", Stderr: `"Missing" isn't an item`, Exit: 1},
+	)
+	vault := testutil.NewFakeBin(t, "vault",
+		testutil.Response{Args: "kv get -field=dsn kv/apps/web", Stdout: "https://sentry.example/1"},
+	)
+
+	resolved, err := ResolveAll(map[string]string{
+		"STRIPE_SECRET_KEY": "op://Dev/Stripe/secret",
+		"SENTRY_DSN":        "vault:kv/apps/web#dsn",
+		"APP_NAME":          "drift",
+	})
+	if err != nil {
+		t.Fatalf("ResolveAll() error = %v", err)
+	}
+	want := map[string]string{"STRIPE_SECRET_KEY": "sk_live_abc", "SENTRY_DSN": "https://sentry.example/1", "APP_NAME": "drift"}
+	for k, v := range want {
+		if resolved[k] != v {
+			t.Errorf("%s = %q, want %q", k, resolved[k], v)
+		}
+	}
+	if calls := vault.CallLines(); len(calls) != 1 {
+		t.Errorf("vault calls = %v", calls)
+	}
+
+	_, err = ResolveAll(map[string]string{"A": "op://Dev/Missing/field", "B": "vault:kv#"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{`A: failed to read op://Dev/Missing/field: "Missing" isn't an item`, "B: invalid Vault reference"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+	if len(op.CallLines()) != 2 {
+		t.Errorf("op calls = %v", op.CallLines())
+	}
+}
@@ -0,0 +1,261 @@
+// Package state manages the per-project .drift/ directory, where drift keeps
+// artifacts that must survive between commands: API caches, deploy
+// manifests, the audit log, worktree archives, review mode, command
+// timings, generated Edge Functions files, saved tmux layouts, worktree
+// port assignments, named database snapshots, stacked worktree parents, and
+// resume points of interrupted restores.
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Dir is the state directory, relative to the project root.
+const Dir = ".drift"
+
+// Area is one kind of state kept under Dir.
+type Area struct {
+	Name        string
+	Path        string // relative to Dir
+	Description string
+	// Default areas are removed by 'drift state clean' without arguments.
+	Default bool
+	// Protected areas are never removed by 'drift state clean'.
+	Protected bool
+}
+
+// The state areas drift knows about.
+var (
+	Cache     = Area{Name: "cache", Path: "cache", Description: "Cached Supabase API responses", Default: true}
+	Manifests = Area{Name: "manifests", Path: "manifests", Description: "Per-branch records of the last applied deploy", Default: true}
+	Audit     = Area{Name: "audit", Path: "audit.log", Description: "Log of deploys, migrations, and restores"}
+	Archive   = Area{Name: "archive", Path: "archive.json", Description: "Worktrees removed by 'drift worktree archive'"}
+	Review    = Area{Name: "review", Path: "review.json", Description: "Pull request targeted by 'drift env setup --review'"}
+	Metrics   = Area{Name: "metrics", Path: "metrics", Description: "Timings of recent commands (drift metrics)"}
+	Functions = Area{Name: "functions", Path: "functions", Description: "Merged import maps, CLI workdir, and serve status for functions", Default: true}
+	Tmux      = Area{Name: "tmux", Path: "tmux", Description: "Saved tmux session layouts (drift tmux save)"}
+	Ports     = Area{Name: "ports", Path: "ports.json", Description: "Local ports assigned to each worktree"}
+	Snapshots = Area{Name: "snapshots", Path: "snapshots.json", Description: "Named database snapshots created with 'drift db snapshot'"}
+	Stacks    = Area{Name: "stacks", Path: "stacks.json", Description: "Parent branches of stacked worktrees"}
+	Restores  = Area{Name: "restores", Path: "restores", Description: "Resume points of interrupted 'drift db push' restores"}
+	Secrets   = Area{Name: "secrets", Path: "secrets", Description: "age-encrypted env secrets", Protected: true}
+)
+
+// Areas returns all state areas in display order.
+func Areas() []Area {
+	return []Area{Cache, Manifests, Audit, Archive, Review, Metrics, Functions, Tmux, Ports, Snapshots, Stacks, Restores, Secrets}
+}
+
+// LookupArea returns the area with name.
+func LookupArea(name string) (Area, bool) {
+	for _, a := range Areas() {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Area{}, false
+}
+
+// Root returns the state directory for projectRoot.
+func Root(projectRoot string) string {
+	return filepath.Join(projectRoot, Dir)
+}
+
+// Path returns the location of area, joined with elem, under projectRoot.
+func Path(projectRoot string, area Area, elem ...string) string {
+	return filepath.Join(append([]string{Root(projectRoot), area.Path}, elem...)...)
+}
+
+var branchKeyUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// BranchKey turns a branch name into a file name, e.g. feature/login -> feature_login.
+func BranchKey(branch string) string {
+	key := strings.Trim(branchKeyUnsafe.ReplaceAllString(branch, "_"), "_.")
+	if key == "" {
+		return "default"
+	}
+	return key
+}
+
+// gitignore keeps local state out of git. Encrypted secrets are meant to be
+// shared, so they stay visible.
+const gitignore = `# Managed by drift: local state, not committed.
+*
+!secrets/
+!secrets/**
+`
+
+// Ensure creates the state directory and its .gitignore.
+func Ensure(projectRoot string) error {
+	root := Root(projectRoot)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", Dir, err)
+	}
+	ignore := filepath.Join(root, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if err := os.WriteFile(ignore, []byte(gitignore), 0644); err != nil {
+			return fmt.Errorf("failed to write %s/.gitignore: %w", Dir, err)
+		}
+	}
+	return nil
+}
+
+// WriteJSON atomically writes v as indented JSON to path under projectRoot's
+// state directory, creating parent directories as needed.
+func WriteJSON(projectRoot, path string, v interface{}) error {
+	if err := Ensure(projectRoot); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadJSON loads path into v. It reports false if path does not exist.
+func ReadJSON(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return true, nil
+}
+
+// AuditEntry is one line of the audit log.
+type AuditEntry struct {
+	Time        time.Time `json:"time"`
+	Operation   string    `json:"operation"`
+	Environment string    `json:"environment,omitempty"`
+	Branch      string    `json:"branch,omitempty"`
+	Actor       string    `json:"actor,omitempty"`
+	Duration    string    `json:"duration,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// AppendAudit adds e to the audit log as a JSON line.
+func AppendAudit(projectRoot string, e AuditEntry) error {
+	if err := Ensure(projectRoot); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(Path(projectRoot, Audit), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// ReadAudit returns the audit log, oldest first. Malformed lines are skipped.
+func ReadAudit(projectRoot string) ([]AuditEntry, error) {
+	f, err := os.Open(Path(projectRoot, Audit))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// AreaUsage is the disk usage of one area.
+type AreaUsage struct {
+	Area     Area
+	Files    int
+	Bytes    int64
+	Modified time.Time // most recent change; zero if empty
+}
+
+// Usage reports the disk usage of every area under projectRoot.
+func Usage(projectRoot string) ([]AreaUsage, error) {
+	var usage []AreaUsage
+	for _, area := range Areas() {
+		u := AreaUsage{Area: area}
+		err := filepath.WalkDir(Path(projectRoot, area), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() || d.Name() == ".gitignore" {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			u.Files++
+			u.Bytes += info.Size()
+			if info.ModTime().After(u.Modified) {
+				u.Modified = info.ModTime()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, nil
+}
+
+// Clean removes areas under projectRoot. Protected areas are refused.
+func Clean(projectRoot string, areas []Area) error {
+	for _, area := range areas {
+		if area.Protected {
+			return fmt.Errorf("%s cannot be removed with 'drift state clean'", area.Name)
+		}
+	}
+	for _, area := range areas {
+		if err := os.RemoveAll(Path(projectRoot, area)); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", area.Name, err)
+		}
+	}
+	return nil
+}
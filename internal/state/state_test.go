@@ -0,0 +1,109 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBranchKey(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"main", "main"},
+		{"feature/login", "feature_login"},
+		{"user/fix auth!", "user_fix_auth"},
+		{"../etc", "etc"},
+		{"", "default"},
+	}
+	for _, tt := range tests {
+		if got := BranchKey(tt.branch); got != tt.want {
+			t.Errorf("BranchKey(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestWriteReadJSON(t *testing.T) {
+	root := t.TempDir()
+	path := Path(root, Manifests, "dev.json")
+
+	type manifest struct{ Name string }
+	if err := WriteJSON(root, path, manifest{Name: "dev"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, Dir, ".gitignore")); err != nil {
+		t.Errorf("expected .gitignore in state dir: %v", err)
+	}
+
+	var got manifest
+	ok, err := ReadJSON(path, &got)
+	if err != nil || !ok || got.Name != "dev" {
+		t.Errorf("ReadJSON() = %v, %v, %+v", ok, err, got)
+	}
+
+	ok, err = ReadJSON(Path(root, Manifests, "missing.json"), &got)
+	if ok || err != nil {
+		t.Errorf("ReadJSON(missing) = %v, %v, want false, nil", ok, err)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for _, op := range []string{"deploy all", "migrate push"} {
+		if err := AppendAudit(root, AuditEntry{Time: now, Operation: op}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := ReadAudit(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Operation != "deploy all" || entries[1].Operation != "migrate push" {
+		t.Errorf("ReadAudit() = %+v", entries)
+	}
+	if !entries[0].Time.Equal(now) {
+		t.Errorf("entry time = %v, want %v", entries[0].Time, now)
+	}
+}
+
+func TestUsageAndClean(t *testing.T) {
+	root := t.TempDir()
+	if err := WriteJSON(root, Path(root, Cache, "branches.json"), []string{"main"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(Path(root, Secrets), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Path(root, Secrets, "KEY.age"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := Usage(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]int)
+	for _, u := range usage {
+		files[u.Area.Name] = u.Files
+	}
+	if files["cache"] != 1 || files["secrets"] != 1 || files["audit"] != 0 {
+		t.Errorf("Usage() files = %v", files)
+	}
+
+	if err := Clean(root, []Area{Secrets}); err == nil {
+		t.Error("Clean(secrets) succeeded, want error")
+	}
+	if err := Clean(root, []Area{Cache}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(Path(root, Cache)); !os.IsNotExist(err) {
+		t.Errorf("cache still exists after Clean: %v", err)
+	}
+	if _, err := os.Stat(Path(root, Secrets, "KEY.age")); err != nil {
+		t.Errorf("secrets removed by Clean: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package supabase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// API key types reported by the Management API.
+const (
+	APIKeyLegacy      = "legacy"
+	APIKeyPublishable = "publishable"
+	APIKeySecret      = "secret"
+)
+
+// APIKey is a project API key. Legacy keys are the anon and service_role
+// JWTs; publishable and secret keys replace them and can be rotated
+// individually.
+type APIKey struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name"`
+	Type       string `json:"type,omitempty"`
+	APIKey     string `json:"api_key"`
+	InsertedAt string `json:"inserted_at,omitempty"`
+}
+
+// IsLegacy reports whether k is a legacy JWT key. Older CLI and API
+// responses carry no type, and every key they return is legacy.
+func (k APIKey) IsLegacy() bool {
+	return k.Type == "" || k.Type == APIKeyLegacy
+}
+
+// pickAPIKey returns the key for role ("anon" or "service_role"): the legacy
+// key when the project still has one, otherwise the newest publishable or
+// secret key, so a rotated key takes over as soon as it exists.
+func pickAPIKey(keys []APIKey, role string) (APIKey, bool) {
+	names := []string{role, strings.ReplaceAll(role, "_", " ")}
+	if role == "anon" {
+		names = append(names, "anon key")
+	}
+	for _, k := range keys {
+		if !k.IsLegacy() || k.APIKey == "" {
+			continue
+		}
+		for _, name := range names {
+			if k.Name == name {
+				return k, true
+			}
+		}
+	}
+
+	want := APIKeyPublishable
+	if role == "service_role" {
+		want = APIKeySecret
+	}
+	var matches []APIKey
+	for _, k := range keys {
+		if k.Type == want && k.APIKey != "" {
+			matches = append(matches, k)
+		}
+	}
+	if len(matches) == 0 {
+		return APIKey{}, false
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].InsertedAt > matches[j].InsertedAt })
+	return matches[0], true
+}
+
+// ListAPIKeys returns a project's API keys with their values revealed.
+func (c *ManagementClient) ListAPIKeys(projectRef string) ([]APIKey, error) {
+	url := fmt.Sprintf("%s/v1/projects/%s/api-keys?reveal=true", ManagementAPIURL(), projectRef)
+	body, err := c.jsonRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(body, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// CreateAPIKey creates a publishable or secret key named name.
+func (c *ManagementClient) CreateAPIKey(projectRef, keyType, name string) (*APIKey, error) {
+	url := fmt.Sprintf("%s/v1/projects/%s/api-keys?reveal=true", ManagementAPIURL(), projectRef)
+	payload, err := json.Marshal(map[string]string{"type": keyType, "name": name})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.jsonRequest("POST", url, payload)
+	if err != nil {
+		return nil, err
+	}
+	var key APIKey
+	if err := json.Unmarshal(body, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse API key: %w", err)
+	}
+	return &key, nil
+}
+
+// DeleteAPIKey revokes the key with id.
+func (c *ManagementClient) DeleteAPIKey(projectRef, id string) error {
+	url := fmt.Sprintf("%s/v1/projects/%s/api-keys/%s", ManagementAPIURL(), projectRef, id)
+	_, err := c.jsonRequest("DELETE", url, nil)
+	return err
+}
+
+// jsonRequest sends a Management API request with an optional JSON payload
+// and returns the response body. Non-2xx responses are *APIError.
+func (c *ManagementClient) jsonRequest(method, url string, payload []byte) ([]byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = strings.NewReader(string(payload))
+	}
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the Management API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return body, nil
+}
@@ -0,0 +1,91 @@
+package supabase
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPickAPIKey(t *testing.T) {
+	legacy := []APIKey{
+		{Name: "anon", APIKey: "eyJ.anon"},
+		{Name: "service_role", APIKey: "eyJ.service"},
+		{Name: "default", Type: APIKeyPublishable, APIKey: "sb_publishable_a"},
+	}
+	rotated := []APIKey{
+		{Name: "anon", Type: APIKeyLegacy, APIKey: ""},
+		{Name: "default", Type: APIKeyPublishable, APIKey: "sb_publishable_old", InsertedAt: "2026-01-01T00:00:00Z"},
+		{Name: "drift_20261016_120000", Type: APIKeyPublishable, APIKey: "sb_publishable_new", InsertedAt: "2026-10-16T12:00:00Z"},
+		{Name: "default", Type: APIKeySecret, APIKey: "sb_secret_old", InsertedAt: "2026-01-01T00:00:00Z"},
+	}
+
+	tests := []struct {
+		name string
+		keys []APIKey
+		role string
+		want string
+	}{
+		{"legacy anon preferred", legacy, "anon", "eyJ.anon"},
+		{"legacy service role", legacy, "service_role", "eyJ.service"},
+		{"old cli label", []APIKey{{Name: "anon key", APIKey: "eyJ.old"}}, "anon", "eyJ.old"},
+		{"newest publishable", rotated, "anon", "sb_publishable_new"},
+		{"secret", rotated, "service_role", "sb_secret_old"},
+		{"missing", nil, "anon", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, _ := pickAPIKey(tt.keys, tt.role)
+			if key.APIKey != tt.want {
+				t.Errorf("pickAPIKey(%q) = %q, want %q", tt.role, key.APIKey, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagementClient_APIKeys(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode([]APIKey{{ID: "k1", Name: "default", Type: APIKeyPublishable, APIKey: "sb_publishable_a"}})
+		case http.MethodPost:
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(APIKey{ID: "k2", Name: body["name"], Type: body["type"], APIKey: "sb_secret_b"})
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	ConfigureEndpoints(Endpoints{ManagementURL: server.URL})
+	t.Cleanup(func() { ConfigureEndpoints(Endpoints{}) })
+	client := &ManagementClient{accessToken: "test", httpClient: server.Client()}
+
+	keys, err := client.ListAPIKeys("ref")
+	if err != nil || len(keys) != 1 || keys[0].IsLegacy() {
+		t.Fatalf("ListAPIKeys() = %+v, %v", keys, err)
+	}
+	created, err := client.CreateAPIKey("ref", APIKeySecret, "drift_1")
+	if err != nil || created.Name != "drift_1" || created.Type != APIKeySecret {
+		t.Fatalf("CreateAPIKey() = %+v, %v", created, err)
+	}
+	if err := client.DeleteAPIKey("ref", "k1"); err == nil {
+		t.Error("DeleteAPIKey() should report API errors")
+	}
+
+	want := []string{
+		"GET /v1/projects/ref/api-keys?reveal=true",
+		"POST /v1/projects/ref/api-keys?reveal=true",
+		"DELETE /v1/projects/ref/api-keys/k1",
+	}
+	for i, r := range want {
+		if i >= len(requests) || requests[i] != r {
+			t.Errorf("requests = %v, want %v", requests, want)
+			break
+		}
+	}
+}
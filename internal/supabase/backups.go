@@ -0,0 +1,120 @@
+package supabase
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Backups is a project's backup state: the daily backups Supabase keeps and,
+// on plans with point-in-time recovery, the window PITR can restore into.
+type Backups struct {
+	Region      string   `json:"region"`
+	WALGEnabled bool     `json:"walg_enabled"`
+	PITREnabled bool     `json:"pitr_enabled"`
+	Backups     []Backup `json:"backups"`
+	Physical    struct {
+		EarliestUnix int64 `json:"earliest_physical_backup_date_unix"`
+		LatestUnix   int64 `json:"latest_physical_backup_date_unix"`
+	} `json:"physical_backup_data"`
+}
+
+// Backup is one daily backup.
+type Backup struct {
+	IsPhysicalBackup bool   `json:"is_physical_backup"`
+	Status           string `json:"status"`
+	InsertedAt       string `json:"inserted_at"`
+}
+
+// PITRWindow returns the earliest and latest times PITR can restore to. ok
+// is false when PITR is not enabled or the window is unknown.
+func (b *Backups) PITRWindow() (earliest, latest time.Time, ok bool) {
+	if !b.PITREnabled || b.Physical.EarliestUnix == 0 || b.Physical.LatestUnix == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	return time.Unix(b.Physical.EarliestUnix, 0).UTC(), time.Unix(b.Physical.LatestUnix, 0).UTC(), true
+}
+
+// RestorePoint is a named snapshot of a project's database that it can be
+// rolled back to.
+type RestorePoint struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// ListBackups returns a project's backups and PITR window.
+func (c *ManagementClient) ListBackups(projectRef string) (*Backups, error) {
+	u := fmt.Sprintf("%s/v1/projects/%s/database/backups", ManagementAPIURL(), projectRef)
+	body, err := c.jsonRequest("GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	var backups Backups
+	if err := json.Unmarshal(body, &backups); err != nil {
+		return nil, fmt.Errorf("failed to parse backups: %w", err)
+	}
+	return &backups, nil
+}
+
+// RestorePITR starts restoring a project's database to target. The project
+// is unavailable until the restore finishes.
+func (c *ManagementClient) RestorePITR(projectRef string, target time.Time) error {
+	u := fmt.Sprintf("%s/v1/projects/%s/database/backups/restore-pitr", ManagementAPIURL(), projectRef)
+	payload, err := json.Marshal(map[string]int64{"recovery_time_target_unix": target.Unix()})
+	if err != nil {
+		return err
+	}
+	if _, err := c.jsonRequest("POST", u, payload); err != nil {
+		return fmt.Errorf("failed to start point-in-time restore: %w", err)
+	}
+	return nil
+}
+
+// CreateRestorePoint snapshots a project's database under name.
+func (c *ManagementClient) CreateRestorePoint(projectRef, name string) (*RestorePoint, error) {
+	u := fmt.Sprintf("%s/v1/projects/%s/database/backups/restore-point", ManagementAPIURL(), projectRef)
+	payload, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.jsonRequest("POST", u, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot %s: %w", name, err)
+	}
+	point := &RestorePoint{Name: name}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, point); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+		}
+	}
+	return point, nil
+}
+
+// GetRestorePoint returns the named snapshot of a project's database.
+func (c *ManagementClient) GetRestorePoint(projectRef, name string) (*RestorePoint, error) {
+	u := fmt.Sprintf("%s/v1/projects/%s/database/backups/restore-point?name=%s", ManagementAPIURL(), projectRef, url.QueryEscape(name))
+	body, err := c.jsonRequest("GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot %s: %w", name, err)
+	}
+	var point RestorePoint
+	if err := json.Unmarshal(body, &point); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return &point, nil
+}
+
+// UndoToRestorePoint starts rolling a project's database back to the named
+// snapshot. Changes made after the snapshot are lost.
+func (c *ManagementClient) UndoToRestorePoint(projectRef, name string) error {
+	u := fmt.Sprintf("%s/v1/projects/%s/database/backups/undo", ManagementAPIURL(), projectRef)
+	payload, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return err
+	}
+	if _, err := c.jsonRequest("POST", u, payload); err != nil {
+		return fmt.Errorf("failed to restore snapshot %s: %w", name, err)
+	}
+	return nil
+}
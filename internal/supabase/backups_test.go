@@ -0,0 +1,88 @@
+package supabase
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestManagementClient_Backups(t *testing.T) {
+	var requests []string
+	var payloads []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if body, _ := io.ReadAll(r.Body); len(body) > 0 {
+			var p map[string]interface{}
+			json.Unmarshal(body, &p)
+			payloads = append(payloads, p)
+		}
+		switch r.URL.Path {
+		case "/v1/projects/ref/database/backups":
+			w.Write([]byte(`{"region": "us-east-1", "pitr_enabled": true, "walg_enabled": true,
+				"backups": [{"is_physical_backup": true, "status": "COMPLETED", "inserted_at": "2026-03-01T00:00:00Z"}],
+				"physical_backup_data": {"earliest_physical_backup_date_unix": 1772323200, "latest_physical_backup_date_unix": 1772928000}}`))
+		case "/v1/projects/ref/database/backups/restore-point":
+			w.Write([]byte(`{"name": "before-push", "status": "AVAILABLE"}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	t.Cleanup(server.Close)
+	ConfigureEndpoints(Endpoints{ManagementURL: server.URL})
+	t.Cleanup(func() { ConfigureEndpoints(Endpoints{}) })
+	client := &ManagementClient{accessToken: "test", httpClient: server.Client()}
+
+	backups, err := client.ListBackups("ref")
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	earliest, latest, ok := backups.PITRWindow()
+	if !ok || !earliest.Equal(time.Unix(1772323200, 0)) || !latest.Equal(time.Unix(1772928000, 0)) || len(backups.Backups) != 1 {
+		t.Errorf("ListBackups() = %+v, window %v-%v %v", backups, earliest, latest, ok)
+	}
+
+	target := time.Unix(1772900000, 0)
+	if err := client.RestorePITR("ref", target); err != nil {
+		t.Fatalf("RestorePITR() error = %v", err)
+	}
+	if point, err := client.CreateRestorePoint("ref", "before-push"); err != nil || point.Status != "AVAILABLE" {
+		t.Fatalf("CreateRestorePoint() = %+v, %v", point, err)
+	}
+	if point, err := client.GetRestorePoint("ref", "before push"); err != nil || point.Name != "before-push" {
+		t.Fatalf("GetRestorePoint() = %+v, %v", point, err)
+	}
+	if err := client.UndoToRestorePoint("ref", "before-push"); err != nil {
+		t.Fatalf("UndoToRestorePoint() error = %v", err)
+	}
+
+	wantRequests := []string{
+		"GET /v1/projects/ref/database/backups",
+		"POST /v1/projects/ref/database/backups/restore-pitr",
+		"POST /v1/projects/ref/database/backups/restore-point",
+		"GET /v1/projects/ref/database/backups/restore-point?name=before+push",
+		"POST /v1/projects/ref/database/backups/undo",
+	}
+	for i, want := range wantRequests {
+		if i >= len(requests) || requests[i] != want {
+			t.Fatalf("requests = %v, want %v", requests, wantRequests)
+		}
+	}
+	if payloads[0]["recovery_time_target_unix"] != float64(1772900000) {
+		t.Errorf("restore-pitr payload = %v", payloads[0])
+	}
+	if payloads[2]["name"] != "before-push" {
+		t.Errorf("undo payload = %v", payloads[2])
+	}
+}
+
+func TestBackups_PITRWindow_Disabled(t *testing.T) {
+	b := &Backups{}
+	b.Physical.EarliestUnix = 1
+	b.Physical.LatestUnix = 2
+	if _, _, ok := b.PITRWindow(); ok {
+		t.Error("PITRWindow() ok without pitr_enabled")
+	}
+}
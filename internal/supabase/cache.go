@@ -0,0 +1,181 @@
+package supabase
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/undrift/drift/internal/state"
+)
+
+// CacheDir is where API responses are cached, relative to the project root.
+const CacheDir = state.Dir + "/cache"
+
+// DefaultCacheTTL is how old a cached response may be and still be used
+// when the Supabase API cannot be reached.
+const DefaultCacheTTL = 24 * time.Hour
+
+var (
+	cacheMu     sync.Mutex
+	cacheDir    string
+	cacheTTL    = DefaultCacheTTL
+	cacheUsedAt time.Time // oldest cached response served this process
+	offline     bool
+)
+
+// cacheEntry is the on-disk format of a cached response.
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// ConfigureCache enables the response cache under projectRoot/.drift/cache.
+// An empty projectRoot disables caching. ttl <= 0 uses DefaultCacheTTL.
+func ConfigureCache(projectRoot string, ttl time.Duration) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	cacheDir = ""
+	if projectRoot != "" {
+		cacheDir = filepath.Join(projectRoot, CacheDir)
+	}
+	cacheTTL = DefaultCacheTTL
+	if ttl > 0 {
+		cacheTTL = ttl
+	}
+}
+
+// SetOffline makes cached API calls skip the network and serve cached data only.
+func SetOffline(v bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	offline = v
+}
+
+// IsOffline reports whether offline mode is enabled.
+func IsOffline() bool {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	return offline
+}
+
+// errOffline is returned when offline mode is on and nothing is cached.
+var errOffline = errors.New("offline mode: no cached data (run 'drift cache warm' while online)")
+
+// CachedSince reports whether any response in this process came from the
+// cache (API unreachable or offline mode), and when the oldest one was fetched.
+func CachedSince() (time.Time, bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	return cacheUsedAt, !cacheUsedAt.IsZero()
+}
+
+// ClearCache removes all cached responses.
+func ClearCache() error {
+	cacheMu.Lock()
+	dir := cacheDir
+	cacheMu.Unlock()
+	if dir == "" {
+		return nil
+	}
+	return os.RemoveAll(dir)
+}
+
+var cacheKeyUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func cachePath(key string) string {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if cacheDir == "" {
+		return ""
+	}
+	return filepath.Join(cacheDir, cacheKeyUnsafe.ReplaceAllString(key, "_")+".json")
+}
+
+// writeCache stores v under key. Failures are ignored; the cache is best effort.
+func writeCache(key string, v interface{}) {
+	path := cachePath(key)
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	entry, err := json.Marshal(cacheEntry{FetchedAt: time.Now(), Data: data})
+	if err != nil {
+		return
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	// Keep the cache out of git without touching the project's .gitignore.
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		_ = os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+	_ = os.WriteFile(path, entry, 0644)
+}
+
+// readCache loads key into v if a cached response newer than the TTL exists,
+// and records that cached data was served.
+func readCache(key string, v interface{}) bool {
+	cacheMu.Lock()
+	ttl := cacheTTL
+	cacheMu.Unlock()
+
+	fetchedAt, ok := readCacheWithTTL(key, ttl, v)
+	if !ok {
+		return false
+	}
+
+	cacheMu.Lock()
+	if cacheUsedAt.IsZero() || fetchedAt.Before(cacheUsedAt) {
+		cacheUsedAt = fetchedAt
+	}
+	cacheMu.Unlock()
+	return true
+}
+
+// readCacheWithTTL loads key into v if it was cached within ttl, and returns
+// when it was fetched. Unlike readCache it does not mark the output as
+// coming from cached data.
+func readCacheWithTTL(key string, ttl time.Duration, v interface{}) (time.Time, bool) {
+	path := cachePath(key)
+	if path == "" {
+		return time.Time{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return time.Time{}, false
+	}
+	if time.Since(entry.FetchedAt) > ttl {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(entry.Data, v); err != nil {
+		return time.Time{}, false
+	}
+	return entry.FetchedAt, true
+}
+
+// SaveCached stores v under key for callers outside this package, such as
+// data fetched from the database rather than the Supabase API.
+func SaveCached(key string, v interface{}) {
+	writeCache(key, v)
+}
+
+// LoadCached loads a value stored with SaveCached into v, honoring the cache TTL.
+func LoadCached(key string, v interface{}) bool {
+	return readCache(key, v)
+}
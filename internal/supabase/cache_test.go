@@ -0,0 +1,102 @@
+package supabase
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func resetCache(t *testing.T, ttl time.Duration) string {
+	t.Helper()
+	root := t.TempDir()
+	ConfigureCache(root, ttl)
+	cacheMu.Lock()
+	cacheUsedAt = time.Time{}
+	cacheMu.Unlock()
+	t.Cleanup(func() {
+		ConfigureCache("", 0)
+		cacheMu.Lock()
+		cacheUsedAt = time.Time{}
+		cacheMu.Unlock()
+	})
+	return root
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	root := resetCache(t, 0)
+
+	want := []Branch{{Name: "main", ProjectRef: "abc", IsDefault: true}}
+	writeCache("branches-abc", want)
+
+	if _, ok := CachedSince(); ok {
+		t.Fatal("CachedSince() reported cache use before any read")
+	}
+
+	var got []Branch
+	if !readCache("branches-abc", &got) {
+		t.Fatal("readCache() = false, want true")
+	}
+	if len(got) != 1 || got[0].Name != "main" || got[0].ProjectRef != "abc" {
+		t.Errorf("readCache() = %+v, want %+v", got, want)
+	}
+	if _, ok := CachedSince(); !ok {
+		t.Error("CachedSince() = false after reading from cache")
+	}
+
+	ignore, err := os.ReadFile(filepath.Join(root, CacheDir, ".gitignore"))
+	if err != nil {
+		t.Fatalf("cache .gitignore missing: %v", err)
+	}
+	if string(ignore) != "*\n" {
+		t.Errorf(".gitignore = %q, want %q", ignore, "*\n")
+	}
+}
+
+func TestCacheExpired(t *testing.T) {
+	root := resetCache(t, time.Hour)
+
+	entry, _ := json.Marshal(cacheEntry{
+		FetchedAt: time.Now().Add(-2 * time.Hour),
+		Data:      json.RawMessage(`[]`),
+	})
+	dir := filepath.Join(root, CacheDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "branches-abc.json"), entry, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Branch
+	if readCache("branches-abc", &got) {
+		t.Error("readCache() returned an entry older than the TTL")
+	}
+	if _, ok := CachedSince(); ok {
+		t.Error("CachedSince() = true for an expired entry")
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	resetCache(t, 0)
+	ConfigureCache("", 0)
+
+	writeCache("branches-abc", []Branch{{Name: "main"}})
+	var got []Branch
+	if readCache("branches-abc", &got) {
+		t.Error("readCache() = true with caching disabled")
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	root := resetCache(t, 0)
+
+	writeCache("functions-abc", []DeployedFunction{{Name: "hello"}})
+	if err := ClearCache(); err != nil {
+		t.Fatalf("ClearCache() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, CacheDir)); !os.IsNotExist(err) {
+		t.Errorf("cache dir still exists after ClearCache()")
+	}
+}
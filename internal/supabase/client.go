@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/undrift/drift/pkg/shell"
 )
@@ -17,9 +18,27 @@ type Client struct {
 	ProjectRef string
 }
 
+// defaultProjectRef is used by NewClient instead of the linked project
+// (see ConfigureProjectRef).
+var (
+	defaultProjectRefMu sync.Mutex
+	defaultProjectRef   string
+)
+
+// ConfigureProjectRef makes NewClient target projectRef rather than the
+// linked project, for repos with several Supabase projects. Empty restores
+// the linked project.
+func ConfigureProjectRef(projectRef string) {
+	defaultProjectRefMu.Lock()
+	defer defaultProjectRefMu.Unlock()
+	defaultProjectRef = projectRef
+}
+
 // NewClient creates a new Supabase client.
 func NewClient() *Client {
-	return &Client{}
+	defaultProjectRefMu.Lock()
+	defer defaultProjectRefMu.Unlock()
+	return &Client{ProjectRef: defaultProjectRef}
 }
 
 // NewClientWithRef creates a new Supabase client with a specific project reference.
@@ -103,24 +122,18 @@ func (c *Client) GetAnonKey(projectRef string) (string, error) {
 		}
 	}
 
-	result, err := shell.Run("supabase", "projects", "api-keys", "--project-ref", projectRef, "--output", "json")
+	result, err := runCLI("projects", "api-keys", "--project-ref", projectRef, "--output", "json")
 	if err != nil {
 		return "", fmt.Errorf("failed to get API keys: %w", err)
 	}
 
-	var keys []struct {
-		Name   string `json:"name"`
-		APIKey string `json:"api_key"`
-	}
-
+	var keys []APIKey
 	if err := json.Unmarshal([]byte(result.Stdout), &keys); err != nil {
 		return "", fmt.Errorf("failed to parse API keys: %w", err)
 	}
 
-	for _, key := range keys {
-		if key.Name == "anon" || key.Name == "anon key" {
-			return key.APIKey, nil
-		}
+	if key, ok := pickAPIKey(keys, "anon"); ok {
+		return key.APIKey, nil
 	}
 
 	return "", fmt.Errorf("anon key not found in project %s", projectRef)
@@ -136,24 +149,18 @@ func (c *Client) GetServiceKey(projectRef string) (string, error) {
 		}
 	}
 
-	result, err := shell.Run("supabase", "projects", "api-keys", "--project-ref", projectRef, "--output", "json")
+	result, err := runCLI("projects", "api-keys", "--project-ref", projectRef, "--output", "json")
 	if err != nil {
 		return "", fmt.Errorf("failed to get API keys: %w", err)
 	}
 
-	var keys []struct {
-		Name   string `json:"name"`
-		APIKey string `json:"api_key"`
-	}
-
+	var keys []APIKey
 	if err := json.Unmarshal([]byte(result.Stdout), &keys); err != nil {
 		return "", fmt.Errorf("failed to parse API keys: %w", err)
 	}
 
-	for _, key := range keys {
-		if key.Name == "service_role" || key.Name == "service role" {
-			return key.APIKey, nil
-		}
+	if key, ok := pickAPIKey(keys, "service_role"); ok {
+		return key.APIKey, nil
 	}
 
 	return "", fmt.Errorf("service role key not found in project %s", projectRef)
@@ -0,0 +1,140 @@
+package supabase
+
+import (
+	"strings"
+	"sync"
+)
+
+// Placeholders substituted in endpoint templates.
+const (
+	RefPlaceholder    = "{ref}"
+	RegionPlaceholder = "{region}"
+)
+
+// Endpoints describes where a Supabase deployment is reachable. Templates
+// may contain {ref} for the project ref and, for the pooler, {region}.
+// Self-hosted deployments usually have a single project, so their
+// templates are plain URLs without placeholders.
+type Endpoints struct {
+	APIURL        string // e.g. https://{ref}.supabase.co
+	ManagementURL string // e.g. https://api.supabase.com
+	DatabaseHost  string // e.g. db.{ref}.supabase.co
+	PoolerHost    string // e.g. aws-0-{region}.pooler.supabase.com
+	DashboardURL  string // e.g. https://supabase.com/dashboard/project/{ref}
+	DefaultRegion string // used for {region} when a project's region is unknown
+}
+
+// DefaultEndpoints returns the endpoints of the hosted Supabase platform.
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		APIURL:        "https://{ref}.supabase.co",
+		ManagementURL: "https://api.supabase.com",
+		DatabaseHost:  "db.{ref}.supabase.co",
+		PoolerHost:    "aws-0-{region}.pooler.supabase.com",
+		DashboardURL:  "https://supabase.com/dashboard/project/{ref}",
+		DefaultRegion: "us-east-1",
+	}
+}
+
+var (
+	endpointsMu sync.Mutex
+	endpoints   = DefaultEndpoints()
+)
+
+// ConfigureEndpoints replaces the endpoints used for URL construction.
+// Empty fields keep their hosted-platform defaults.
+func ConfigureEndpoints(e Endpoints) {
+	defaults := DefaultEndpoints()
+	setDefault := func(field *string, def string) {
+		*field = strings.TrimRight(strings.TrimSpace(*field), "/")
+		if *field == "" {
+			*field = def
+		}
+	}
+	setDefault(&e.APIURL, defaults.APIURL)
+	setDefault(&e.ManagementURL, defaults.ManagementURL)
+	setDefault(&e.DatabaseHost, defaults.DatabaseHost)
+	setDefault(&e.PoolerHost, defaults.PoolerHost)
+	setDefault(&e.DashboardURL, defaults.DashboardURL)
+	setDefault(&e.DefaultRegion, defaults.DefaultRegion)
+
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	endpoints = e
+}
+
+// CurrentEndpoints returns the configured endpoints.
+func CurrentEndpoints() Endpoints {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	return endpoints
+}
+
+// IsSelfHosted reports whether any endpoint differs from the hosted platform.
+func IsSelfHosted() bool {
+	return CurrentEndpoints() != DefaultEndpoints()
+}
+
+// APIURL returns the API URL for a project.
+func APIURL(projectRef string) string {
+	return strings.ReplaceAll(CurrentEndpoints().APIURL, RefPlaceholder, projectRef)
+}
+
+// ManagementAPIURL returns the Management API base URL.
+func ManagementAPIURL() string {
+	return CurrentEndpoints().ManagementURL
+}
+
+// DatabaseHost returns the direct database host for a project.
+func DatabaseHost(projectRef string) string {
+	return strings.ReplaceAll(CurrentEndpoints().DatabaseHost, RefPlaceholder, projectRef)
+}
+
+// PoolerHost returns the connection pooler host for a project in region.
+// An empty region uses the configured default region.
+func PoolerHost(projectRef, region string) string {
+	e := CurrentEndpoints()
+	if region == "" {
+		region = e.DefaultRegion
+	}
+	host := strings.ReplaceAll(e.PoolerHost, RefPlaceholder, projectRef)
+	return strings.ReplaceAll(host, RegionPlaceholder, region)
+}
+
+// DashboardURL returns the dashboard URL for a project, with an optional
+// page path such as "sql" or "settings/api".
+func DashboardURL(projectRef, page string) string {
+	u := strings.ReplaceAll(CurrentEndpoints().DashboardURL, RefPlaceholder, projectRef)
+	if page != "" {
+		u += "/" + strings.TrimPrefix(page, "/")
+	}
+	return u
+}
+
+// ProjectRefFromURL extracts the project ref from an API URL by matching it
+// against the API URL template. It returns "" when the template has no
+// {ref} placeholder or the URL does not match it.
+func ProjectRefFromURL(apiURL string) string {
+	template := CurrentEndpoints().APIURL
+	idx := strings.Index(template, RefPlaceholder)
+	if idx == -1 {
+		return ""
+	}
+
+	prefix := stripScheme(template[:idx])
+	suffix := template[idx+len(RefPlaceholder):]
+	u := strings.TrimRight(stripScheme(apiURL), "/")
+	if !strings.HasPrefix(u, prefix) || !strings.HasSuffix(u, suffix) || len(u) <= len(prefix)+len(suffix) {
+		return ""
+	}
+	ref := u[len(prefix) : len(u)-len(suffix)]
+	if strings.ContainsAny(ref, "./") {
+		return ""
+	}
+	return ref
+}
+
+func stripScheme(u string) string {
+	u = strings.TrimPrefix(u, "https://")
+	return strings.TrimPrefix(u, "http://")
+}
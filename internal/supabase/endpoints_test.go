@@ -0,0 +1,76 @@
+package supabase
+
+import "testing"
+
+func TestEndpointsDefaults(t *testing.T) {
+	ConfigureEndpoints(Endpoints{})
+	defer ConfigureEndpoints(Endpoints{})
+
+	if IsSelfHosted() {
+		t.Error("IsSelfHosted() = true with default endpoints")
+	}
+	if got := APIURL("abc"); got != "https://abc.supabase.co" {
+		t.Errorf("APIURL() = %q", got)
+	}
+	if got := PoolerHost("abc", ""); got != "aws-0-us-east-1.pooler.supabase.com" {
+		t.Errorf("PoolerHost() = %q", got)
+	}
+	if got := DashboardURL("abc", "sql"); got != "https://supabase.com/dashboard/project/abc/sql" {
+		t.Errorf("DashboardURL() = %q", got)
+	}
+}
+
+func TestEndpointsCustom(t *testing.T) {
+	ConfigureEndpoints(Endpoints{
+		APIURL:        "https://{ref}.api.example.com/",
+		ManagementURL: "https://manage.example.com",
+		DatabaseHost:  "db-{ref}.example.com",
+		PoolerHost:    "pooler.{region}.example.com",
+		DefaultRegion: "eu-west-1",
+	})
+	defer ConfigureEndpoints(Endpoints{})
+
+	if !IsSelfHosted() {
+		t.Error("IsSelfHosted() = false with custom endpoints")
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"api url", APIURL("abc"), "https://abc.api.example.com"},
+		{"management", ManagementAPIURL(), "https://manage.example.com"},
+		{"db host", DatabaseHost("abc"), "db-abc.example.com"},
+		{"pooler default region", PoolerHost("abc", ""), "pooler.eu-west-1.example.com"},
+		{"pooler region", PoolerHost("abc", "us-east-2"), "pooler.us-east-2.example.com"},
+		{"dashboard default", DashboardURL("abc", "settings/api"), "https://supabase.com/dashboard/project/abc/settings/api"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestProjectRefFromURL(t *testing.T) {
+	defer ConfigureEndpoints(Endpoints{})
+
+	tests := []struct {
+		template string
+		url      string
+		want     string
+	}{
+		{"", "https://abcdefgh.supabase.co", "abcdefgh"},
+		{"", "https://abcdefgh.supabase.co/", "abcdefgh"},
+		{"", "http://localhost:54321", ""},
+		{"https://{ref}.api.example.com", "https://abc.api.example.com", "abc"},
+		{"https://example.com/projects/{ref}", "https://example.com/projects/abc", "abc"},
+		{"https://supabase.internal", "https://supabase.internal", ""},
+	}
+	for _, tt := range tests {
+		ConfigureEndpoints(Endpoints{APIURL: tt.template})
+		if got := ProjectRefFromURL(tt.url); got != tt.want {
+			t.Errorf("ProjectRefFromURL(%q) with %q = %q, want %q", tt.url, tt.template, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package supabase
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FunctionManifestFile is the optional per-function file that declares the
+// env vars a function needs.
+const FunctionManifestFile = "function.yaml"
+
+// FunctionManifest is a function's function.yaml:
+//
+//	env:
+//	  required: [STRIPE_SECRET_KEY]
+//	  optional: [SENTRY_DSN]
+//
+// Optional names are not required even when the code reads them.
+type FunctionManifest struct {
+	Env struct {
+		Required []string `yaml:"required"`
+		Optional []string `yaml:"optional"`
+	} `yaml:"env"`
+}
+
+// RuntimeEnv lists the variables the Edge Runtime sets for every function,
+// so they never need to be configured as secrets.
+var RuntimeEnv = []string{
+	"SUPABASE_URL",
+	"SUPABASE_ANON_KEY",
+	"SUPABASE_SERVICE_ROLE_KEY",
+	"SUPABASE_DB_URL",
+	"SUPABASE_PUBLISHABLE_KEYS",
+	"SUPABASE_SECRET_KEYS",
+	"SB_REGION",
+	"SB_EXECUTION_ID",
+	"DENO_DEPLOYMENT_ID",
+}
+
+// FunctionEnv is the env vars a function needs at runtime.
+type FunctionEnv struct {
+	Function string
+	// Required is sorted and excludes RuntimeEnv.
+	Required []string
+	// Sources maps each required name to where it was found: a file:line
+	// relative to the functions root, or function.yaml.
+	Sources map[string]string
+}
+
+var (
+	denoEnvGet      = regexp.MustCompile(`Deno\.env\.get\(\s*["'` + "`" + `]([A-Za-z_][A-Za-z0-9_]*)["'` + "`" + `]\s*\)`)
+	sharedImport    = regexp.MustCompile(`["']\.\./_shared/`)
+	functionSources = map[string]bool{".ts": true, ".tsx": true, ".js": true, ".jsx": true, ".mjs": true}
+)
+
+// ScanFunctionEnv returns the env vars fn reads with Deno.env.get("NAME")
+// plus those its function.yaml requires. When fn imports from ../_shared,
+// the shared code's reads count too. Names read only through a variable
+// cannot be found; declare those in function.yaml.
+func ScanFunctionEnv(fn Function) (*FunctionEnv, error) {
+	env := &FunctionEnv{Function: fn.Name, Sources: make(map[string]string)}
+
+	manifest, err := LoadFunctionManifest(fn.Path)
+	if err != nil {
+		return nil, err
+	}
+	optional := make(map[string]bool)
+	for _, name := range RuntimeEnv {
+		optional[name] = true
+	}
+	for _, name := range manifest.Env.Optional {
+		optional[name] = true
+	}
+	add := func(name, source string) {
+		if optional[name] {
+			return
+		}
+		if _, ok := env.Sources[name]; !ok {
+			env.Sources[name] = source
+		}
+	}
+
+	for _, name := range manifest.Env.Required {
+		add(name, filepath.Join(fn.Name, FunctionManifestFile))
+	}
+	usesShared, err := scanEnvReads(fn.Path, fn.Root, add)
+	if err != nil {
+		return nil, err
+	}
+	shared := filepath.Join(fn.Root, "_shared")
+	if _, err := os.Stat(shared); usesShared && err == nil {
+		if _, err := scanEnvReads(shared, fn.Root, add); err != nil {
+			return nil, err
+		}
+	}
+
+	for name := range env.Sources {
+		env.Required = append(env.Required, name)
+	}
+	sort.Strings(env.Required)
+	return env, nil
+}
+
+// LoadFunctionManifest reads dir's function.yaml. A missing file is an empty
+// manifest.
+func LoadFunctionManifest(dir string) (*FunctionManifest, error) {
+	var manifest FunctionManifest
+	data, err := os.ReadFile(filepath.Join(dir, FunctionManifestFile))
+	if os.IsNotExist(err) {
+		return &manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Join(dir, FunctionManifestFile), err)
+	}
+	return &manifest, nil
+}
+
+// scanEnvReads calls found for each Deno.env.get in the sources under dir,
+// with a file:line relative to root. It reports whether any file imports
+// from ../_shared.
+func scanEnvReads(dir, root string, found func(name, source string)) (bool, error) {
+	usesShared := false
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !functionSources[filepath.Ext(path)] || strings.HasSuffix(path, ".test.ts") {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := scanner.Text()
+			if sharedImport.MatchString(text) {
+				usesShared = true
+			}
+			for _, m := range denoEnvGet.FindAllStringSubmatch(text, -1) {
+				found(m[1], fmt.Sprintf("%s:%d", rel, line))
+			}
+		}
+		return scanner.Err()
+	})
+	return usesShared, err
+}
@@ -0,0 +1,66 @@
+package supabase
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFunctionFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanFunctionEnv(t *testing.T) {
+	root := t.TempDir()
+	writeFunctionFile(t, filepath.Join(root, "_shared", "stripe.ts"), `export const key = Deno.env.get("STRIPE_SECRET_KEY")!;`)
+	writeFunctionFile(t, filepath.Join(root, "checkout", "index.ts"), `import { key } from "../_shared/stripe.ts";
+const url = Deno.env.get('SUPABASE_URL');
+const hook = Deno.env.get("WEBHOOK_SECRET") ?? "";
+const dsn = Deno.env.get("SENTRY_DSN");
+`)
+	writeFunctionFile(t, filepath.Join(root, "checkout", "index.test.ts"), `Deno.env.get("TEST_ONLY")`)
+	writeFunctionFile(t, filepath.Join(root, "checkout", FunctionManifestFile), `env:
+  required: [PRICE_TABLE]
+  optional: [SENTRY_DSN]
+`)
+	writeFunctionFile(t, filepath.Join(root, "hello", "index.ts"), "const n = Deno.env.get(`GREETING`)\n")
+
+	env, err := ScanFunctionEnv(Function{Name: "checkout", Path: filepath.Join(root, "checkout"), Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"PRICE_TABLE", "STRIPE_SECRET_KEY", "WEBHOOK_SECRET"}
+	if !reflect.DeepEqual(env.Required, want) {
+		t.Errorf("Required = %v, want %v", env.Required, want)
+	}
+	if got := env.Sources["WEBHOOK_SECRET"]; got != filepath.Join("checkout", "index.ts")+":3" {
+		t.Errorf("Sources[WEBHOOK_SECRET] = %q", got)
+	}
+	if got := env.Sources["STRIPE_SECRET_KEY"]; got != filepath.Join("_shared", "stripe.ts")+":1" {
+		t.Errorf("Sources[STRIPE_SECRET_KEY] = %q", got)
+	}
+
+	// hello does not import _shared, so the shared read does not count.
+	env, err = ScanFunctionEnv(Function{Name: "hello", Path: filepath.Join(root, "hello"), Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(env.Required, []string{"GREETING"}) {
+		t.Errorf("hello Required = %v, want [GREETING]", env.Required)
+	}
+}
+
+func TestLoadFunctionManifest_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFunctionFile(t, filepath.Join(dir, FunctionManifestFile), "env: [")
+	if _, err := LoadFunctionManifest(dir); err == nil {
+		t.Error("expected error for invalid function.yaml")
+	}
+}
@@ -13,6 +13,7 @@ import (
 type Function struct {
 	Name string
 	Path string
+	Root string // functions directory the function was found in
 }
 
 // ListFunctions returns all Edge Functions in the functions directory.
@@ -47,6 +48,7 @@ func ListFunctions(functionsDir string) ([]Function, error) {
 		functions = append(functions, Function{
 			Name: entry.Name(),
 			Path: funcPath,
+			Root: functionsDir,
 		})
 	}
 
@@ -56,6 +58,8 @@ func ListFunctions(functionsDir string) ([]Function, error) {
 // DeployOptions holds options for function deployment.
 type DeployOptions struct {
 	NoVerifyJWT bool
+	ImportMap   string // passed as --import-map when set
+	Workdir     string // passed as --workdir when set, see StageWorkdir
 }
 
 // DeployFunction deploys a single Edge Function.
@@ -66,14 +70,20 @@ func (c *Client) DeployFunction(name, projectRef string) error {
 // DeployFunctionWithOptions deploys a single Edge Function with options.
 func (c *Client) DeployFunctionWithOptions(name, projectRef string, opts DeployOptions) error {
 	args := []string{"functions", "deploy", name}
+	if opts.Workdir != "" {
+		args = append(args, "--workdir", opts.Workdir)
+	}
 	if projectRef != "" {
 		args = append(args, "--project-ref", projectRef)
 	}
 	if opts.NoVerifyJWT {
 		args = append(args, "--no-verify-jwt")
 	}
+	if opts.ImportMap != "" {
+		args = append(args, "--import-map", opts.ImportMap)
+	}
 
-	result, err := shell.Run("supabase", args...)
+	result, err := runCLI(args...)
 	if err != nil {
 		errMsg := result.Stderr
 		if errMsg == "" {
@@ -114,17 +124,45 @@ func (c *Client) DeployAllFunctions(functionsDir, projectRef string) error {
 	return nil
 }
 
+// ServeOptions holds optional flags for the local function server.
+type ServeOptions struct {
+	EnvFile   string
+	ImportMap string
+	Workdir   string
+	// InspectMode enables the Deno debugger: run, brk, or wait.
+	InspectMode string
+}
+
 // ServeFunction starts a local function server.
 func (c *Client) ServeFunction(name string, envFile string) error {
+	return c.ServeFunctionWithOptions(name, ServeOptions{EnvFile: envFile})
+}
+
+// ServeFunctionWithOptions starts a local function server with options.
+func (c *Client) ServeFunctionWithOptions(name string, opts ServeOptions) error {
+	return shell.RunInteractive("supabase", ServeArgs(name, opts)...)
+}
+
+// ServeArgs returns the supabase CLI arguments that serve name (or every
+// function, if empty) with opts.
+func ServeArgs(name string, opts ServeOptions) []string {
 	args := []string{"functions", "serve"}
 	if name != "" {
 		args = append(args, name)
 	}
-	if envFile != "" {
-		args = append(args, "--env-file", envFile)
+	if opts.Workdir != "" {
+		args = append(args, "--workdir", opts.Workdir)
 	}
-
-	return shell.RunInteractive("supabase", args...)
+	if opts.EnvFile != "" {
+		args = append(args, "--env-file", opts.EnvFile)
+	}
+	if opts.ImportMap != "" {
+		args = append(args, "--import-map", opts.ImportMap)
+	}
+	if opts.InspectMode != "" {
+		args = append(args, "--inspect-mode", opts.InspectMode)
+	}
+	return args
 }
 
 // InvokeFunction invokes a function locally.
@@ -157,10 +195,33 @@ type DeployedFunction struct {
 }
 
 // ListDeployedFunctions returns all Edge Functions deployed on a project.
+// When the API cannot be reached, a recent cached list is returned instead.
 func (c *Client) ListDeployedFunctions(projectRef string) ([]DeployedFunction, error) {
+	key := "functions-" + projectRef
+	if IsOffline() {
+		var cached []DeployedFunction
+		if readCache(key, &cached) {
+			return cached, nil
+		}
+		return nil, errOffline
+	}
+
+	functions, err := c.fetchDeployedFunctions(projectRef)
+	if err != nil {
+		var cached []DeployedFunction
+		if readCache(key, &cached) {
+			return cached, nil
+		}
+		return nil, err
+	}
+	writeCache(key, functions)
+	return functions, nil
+}
+
+func (c *Client) fetchDeployedFunctions(projectRef string) ([]DeployedFunction, error) {
 	args := []string{"functions", "list", "--project-ref", projectRef}
 
-	result, err := shell.Run("supabase", args...)
+	result, err := runCLI(args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list deployed functions: %w", err)
 	}
@@ -215,11 +276,30 @@ func (c *Client) ListDeployedFunctions(projectRef string) ([]DeployedFunction, e
 	return functions, nil
 }
 
+// VerifyFunctionDeployed checks that name is deployed and active on the
+// project. Unlike ListDeployedFunctions it never answers from the cache.
+func (c *Client) VerifyFunctionDeployed(name, projectRef string) error {
+	functions, err := c.fetchDeployedFunctions(projectRef)
+	if err != nil {
+		return err
+	}
+	for _, fn := range functions {
+		if fn.Name != name {
+			continue
+		}
+		if !strings.EqualFold(fn.Status, "active") {
+			return fmt.Errorf("function '%s' is %s, not active", name, fn.Status)
+		}
+		return nil
+	}
+	return fmt.Errorf("function '%s' is not deployed", name)
+}
+
 // DeleteFunction deletes a deployed Edge Function.
 func (c *Client) DeleteFunction(name, projectRef string) error {
 	args := []string{"functions", "delete", name, "--project-ref", projectRef}
 
-	result, err := shell.Run("supabase", args...)
+	result, err := runCLI(args...)
 	if err != nil {
 		errMsg := result.Stderr
 		if errMsg == "" {
@@ -248,7 +328,7 @@ func (c *Client) DownloadFunction(name, projectRef, outputDir string) error {
 		}
 	}
 
-	result, err := shell.RunInDir(outputDir, "supabase", args...)
+	result, err := runCLIInDir(outputDir, args...)
 	if err != nil {
 		errMsg := result.Stderr
 		if errMsg == "" {
@@ -273,7 +353,7 @@ func (c *Client) DownloadFunctionToTemp(name, projectRef string) (string, error)
 
 	args := []string{"functions", "download", name, "--project-ref", projectRef}
 
-	result, err := shell.RunInDir(tempDir, "supabase", args...)
+	result, err := runCLIInDir(tempDir, args...)
 	if err != nil {
 		os.RemoveAll(tempDir)
 		errMsg := result.Stderr
@@ -0,0 +1,189 @@
+package supabase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/undrift/drift/internal/metrics"
+)
+
+// Service names reported by ProbeServices.
+const (
+	ServiceREST      = "rest"
+	ServiceAuth      = "auth"
+	ServiceRealtime  = "realtime"
+	ServiceStorage   = "storage"
+	ServiceFunctions = "functions"
+)
+
+// ServiceHealth is the result of probing a single Supabase service.
+type ServiceHealth struct {
+	Service    string        `json:"service"`
+	Endpoint   string        `json:"endpoint"`
+	Critical   bool          `json:"critical"`
+	Healthy    bool          `json:"healthy"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Latency    time.Duration `json:"-"`
+	LatencyMS  int64         `json:"latency_ms"`
+	Detail     string        `json:"detail,omitempty"`
+}
+
+// HealthReport aggregates service probes for one project.
+type HealthReport struct {
+	ProjectRef string          `json:"project_ref"`
+	APIURL     string          `json:"api_url"`
+	CheckedAt  time.Time       `json:"checked_at"`
+	Services   []ServiceHealth `json:"services"`
+}
+
+// CriticalFailures returns the critical services that are not healthy.
+func (r *HealthReport) CriticalFailures() []ServiceHealth {
+	var failed []ServiceHealth
+	for _, s := range r.Services {
+		if s.Critical && !s.Healthy {
+			failed = append(failed, s)
+		}
+	}
+	return failed
+}
+
+// HealthProbeOptions configures ProbeServices.
+type HealthProbeOptions struct {
+	APIURL   string
+	AnonKey  string
+	Function string // optional function to ping; otherwise the functions gateway is probed
+	Timeout  time.Duration
+}
+
+// ProbeServices checks REST, Auth, Realtime, Storage, and Edge Functions for a project.
+// REST and Auth are considered critical; the rest are informational.
+func ProbeServices(projectRef string, opts HealthProbeOptions) *HealthReport {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	httpClient := &http.Client{Timeout: timeout, Transport: metrics.Transport(nil)}
+	base := strings.TrimRight(opts.APIURL, "/")
+
+	report := &HealthReport{
+		ProjectRef: projectRef,
+		APIURL:     base,
+		CheckedAt:  time.Now().UTC(),
+	}
+
+	functionsPath := "/functions/v1/"
+	if opts.Function != "" {
+		functionsPath += opts.Function
+	}
+
+	report.Services = append(report.Services,
+		probeHTTP(httpClient, ServiceREST, base+"/rest/v1/", opts.AnonKey, true, restHealthy),
+		probeHTTP(httpClient, ServiceAuth, base+"/auth/v1/health", opts.AnonKey, true, okHealthy),
+		probeRealtime(httpClient, base+"/realtime/v1/websocket", opts.AnonKey),
+		probeHTTP(httpClient, ServiceStorage, base+"/storage/v1/bucket", opts.AnonKey, false, okHealthy),
+		probeHTTP(httpClient, ServiceFunctions, base+functionsPath, opts.AnonKey, false, gatewayHealthy),
+	)
+
+	return report
+}
+
+// restHealthy accepts 200 and 401 (PostgREST root requires a valid key on some projects).
+func restHealthy(code int) bool {
+	return code == http.StatusOK || code == http.StatusUnauthorized
+}
+
+func okHealthy(code int) bool {
+	return code == http.StatusOK
+}
+
+// gatewayHealthy treats any non-5xx answer as reachable; a 404 still proves the gateway is up.
+func gatewayHealthy(code int) bool {
+	return code > 0 && code < http.StatusInternalServerError
+}
+
+func probeHTTP(client *http.Client, service, endpoint, anonKey string, critical bool, healthy func(int) bool) ServiceHealth {
+	result := ServiceHealth{
+		Service:  service,
+		Endpoint: endpoint,
+		Critical: critical,
+	}
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	if anonKey != "" {
+		req.Header.Set("apikey", anonKey)
+		req.Header.Set("Authorization", "Bearer "+anonKey)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Latency = time.Since(start)
+	result.LatencyMS = result.Latency.Milliseconds()
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	result.StatusCode = resp.StatusCode
+	result.Healthy = healthy(resp.StatusCode)
+	if !result.Healthy {
+		result.Detail = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return result
+}
+
+// probeRealtime performs a websocket upgrade handshake and reports whether the server switched protocols.
+func probeRealtime(client *http.Client, endpoint, anonKey string) ServiceHealth {
+	url := endpoint + "?vsn=1.0.0"
+	if anonKey != "" {
+		url += "&apikey=" + anonKey
+	}
+
+	result := ServiceHealth{
+		Service:  ServiceRealtime,
+		Endpoint: endpoint,
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(nonce))
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Latency = time.Since(start)
+	result.LatencyMS = result.Latency.Milliseconds()
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	result.Healthy = resp.StatusCode == http.StatusSwitchingProtocols
+	if !result.Healthy {
+		result.Detail = fmt.Sprintf("websocket upgrade refused (status %d)", resp.StatusCode)
+	}
+	return result
+}
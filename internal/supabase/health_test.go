@@ -0,0 +1,62 @@
+package supabase
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbeServices_ReportsCriticalFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/v1/":
+			if r.Header.Get("apikey") != "anon" {
+				t.Errorf("rest probe missing apikey header")
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/auth/v1/health":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/storage/v1/bucket":
+			w.WriteHeader(http.StatusOK)
+		case "/functions/v1/":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	report := ProbeServices("ref", HealthProbeOptions{
+		APIURL:  server.URL + "/",
+		AnonKey: "anon",
+		Timeout: 2 * time.Second,
+	})
+
+	if len(report.Services) != 5 {
+		t.Fatalf("expected 5 services, got %d", len(report.Services))
+	}
+
+	byName := make(map[string]ServiceHealth)
+	for _, s := range report.Services {
+		byName[s.Service] = s
+	}
+
+	if !byName[ServiceREST].Healthy {
+		t.Errorf("rest should be healthy: %+v", byName[ServiceREST])
+	}
+	if byName[ServiceAuth].Healthy {
+		t.Errorf("auth should be unhealthy: %+v", byName[ServiceAuth])
+	}
+	if !byName[ServiceFunctions].Healthy {
+		t.Errorf("functions gateway 404 should count as reachable: %+v", byName[ServiceFunctions])
+	}
+	if byName[ServiceRealtime].Healthy {
+		t.Errorf("realtime should be unhealthy without a websocket upgrade: %+v", byName[ServiceRealtime])
+	}
+
+	failed := report.CriticalFailures()
+	if len(failed) != 1 || failed[0].Service != ServiceAuth {
+		t.Errorf("CriticalFailures() = %+v, want only auth", failed)
+	}
+}
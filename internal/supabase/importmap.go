@@ -0,0 +1,119 @@
+package supabase
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportMap is a Deno import map. deno.json files use the same keys, so
+// either can be loaded.
+type ImportMap struct {
+	Imports map[string]string            `json:"imports,omitempty"`
+	Scopes  map[string]map[string]string `json:"scopes,omitempty"`
+}
+
+// ScopedImportMap is an import map file and the functions root it applies
+// to. An empty Root applies the map everywhere.
+type ScopedImportMap struct {
+	Root string
+	Path string
+}
+
+// LoadImportMap reads an import map or deno.json and rewrites relative
+// targets and scopes to absolute paths, so the map still resolves after it
+// is merged and written elsewhere.
+func LoadImportMap(path string) (*ImportMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import map: %w", err)
+	}
+	var m ImportMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid import map %s: %w", path, err)
+	}
+
+	base := filepath.Dir(path)
+	out := &ImportMap{Imports: rebaseImports(m.Imports, base)}
+	for scope, imports := range m.Scopes {
+		if out.Scopes == nil {
+			out.Scopes = make(map[string]map[string]string)
+		}
+		out.Scopes[rebaseSpecifier(scope, base)] = rebaseImports(imports, base)
+	}
+	return out, nil
+}
+
+// MergeImportMaps combines the import maps of several functions roots into
+// one map for the Supabase CLI. Each map's imports are scoped to its root,
+// so roots can map the same specifier to different targets.
+func MergeImportMaps(maps []ScopedImportMap) (*ImportMap, error) {
+	merged := &ImportMap{Imports: map[string]string{}, Scopes: map[string]map[string]string{}}
+	for _, sm := range maps {
+		m, err := LoadImportMap(sm.Path)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case sm.Root == "":
+			for k, v := range m.Imports {
+				merged.Imports[k] = v
+			}
+		case len(m.Imports) > 0:
+			merged.Scopes[scopeKey(sm.Root)] = m.Imports
+		}
+		for scope, imports := range m.Scopes {
+			if existing, ok := merged.Scopes[scope]; ok {
+				for k, v := range imports {
+					existing[k] = v
+				}
+				continue
+			}
+			merged.Scopes[scope] = imports
+		}
+	}
+	return merged, nil
+}
+
+// WriteImportMap writes m as indented JSON to path.
+func WriteImportMap(path string, m *ImportMap) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func rebaseImports(imports map[string]string, base string) map[string]string {
+	if len(imports) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(imports))
+	for specifier, target := range imports {
+		out[specifier] = rebaseSpecifier(target, base)
+	}
+	return out
+}
+
+// rebaseSpecifier resolves "./" and "../" specifiers against base. Bare
+// specifiers and URLs (npm:, jsr:, https:, ...) are returned unchanged.
+func rebaseSpecifier(specifier, base string) string {
+	if !strings.HasPrefix(specifier, "./") && !strings.HasPrefix(specifier, "../") {
+		return specifier
+	}
+	resolved := filepath.ToSlash(filepath.Join(base, specifier))
+	if strings.HasSuffix(specifier, "/") {
+		resolved += "/"
+	}
+	return resolved
+}
+
+// scopeKey turns a directory into an import map scope covering its files.
+func scopeKey(dir string) string {
+	return strings.TrimSuffix(filepath.ToSlash(dir), "/") + "/"
+}
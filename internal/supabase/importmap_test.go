@@ -0,0 +1,63 @@
+package supabase
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeImportMaps(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) string {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	base := write("supabase/functions/import_map.json", `{
+  "imports": {"std/": "https://deno.land/std@0.208.0/", "shared/": "./_shared/"}
+}`)
+	edge := write("packages/edge/deno.json", `{
+  "imports": {"shared/": "../shared/src/", "zod": "npm:zod@3"},
+  "scopes": {"./billing/": {"zod": "npm:zod@2"}}
+}`)
+
+	merged, err := MergeImportMaps([]ScopedImportMap{
+		{Path: base},
+		{Root: filepath.Join(dir, "packages/edge"), Path: edge},
+	})
+	if err != nil {
+		t.Fatalf("MergeImportMaps() error = %v", err)
+	}
+
+	slash := filepath.ToSlash(dir)
+	want := &ImportMap{
+		Imports: map[string]string{
+			"std/":    "https://deno.land/std@0.208.0/",
+			"shared/": slash + "/supabase/functions/_shared/",
+		},
+		Scopes: map[string]map[string]string{
+			slash + "/packages/edge/": {
+				"shared/": slash + "/packages/shared/src/",
+				"zod":     "npm:zod@3",
+			},
+			slash + "/packages/edge/billing/": {"zod": "npm:zod@2"},
+		},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("MergeImportMaps() = %+v, want %+v", merged, want)
+	}
+}
+
+func TestMergeImportMaps_MissingFile(t *testing.T) {
+	_, err := MergeImportMaps([]ScopedImportMap{{Path: filepath.Join(t.TempDir(), "missing.json")}})
+	if err == nil {
+		t.Error("MergeImportMaps() should fail for a missing import map")
+	}
+}
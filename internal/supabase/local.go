@@ -0,0 +1,90 @@
+package supabase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// LocalStack holds connection details for the local Supabase stack started
+// with 'supabase start'.
+type LocalStack struct {
+	APIURL         string
+	DBURL          string
+	StudioURL      string
+	AnonKey        string
+	ServiceRoleKey string
+}
+
+// LocalStatus returns the running local stack, or an error if it is not running.
+func (c *Client) LocalStatus() (*LocalStack, error) {
+	result, err := shell.Run("supabase", "status", "-o", "env")
+	if err != nil || result.ExitCode != 0 {
+		return nil, fmt.Errorf("local Supabase is not running: %s", localErrorMessage(result, err))
+	}
+
+	stack := parseLocalStatusEnv(result.Stdout)
+	if stack.APIURL == "" || stack.DBURL == "" {
+		return nil, fmt.Errorf("local Supabase is not running")
+	}
+	return stack, nil
+}
+
+// StartLocal starts the local stack. It requires Docker.
+func (c *Client) StartLocal() error {
+	result, err := shell.Run("supabase", "start")
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to start local Supabase: %s", localErrorMessage(result, err))
+	}
+	return nil
+}
+
+// MigrateLocal applies pending migrations to the local database.
+func (c *Client) MigrateLocal() error {
+	result, err := shell.Run("supabase", "migration", "up", "--local")
+	if err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to apply migrations locally: %s", localErrorMessage(result, err))
+	}
+	return nil
+}
+
+// parseLocalStatusEnv parses the output of 'supabase status -o env'.
+func parseLocalStatusEnv(output string) *LocalStack {
+	stack := &LocalStack{}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		switch key {
+		case "API_URL":
+			stack.APIURL = value
+		case "DB_URL":
+			stack.DBURL = value
+		case "STUDIO_URL":
+			stack.StudioURL = value
+		case "ANON_KEY":
+			stack.AnonKey = value
+		case "SERVICE_ROLE_KEY":
+			stack.ServiceRoleKey = value
+		}
+	}
+	return stack
+}
+
+func localErrorMessage(result *shell.Result, err error) string {
+	if result != nil {
+		if msg := strings.TrimSpace(result.Stderr); msg != "" {
+			return msg
+		}
+		if msg := strings.TrimSpace(result.Stdout); msg != "" {
+			return msg
+		}
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "unknown error"
+}
@@ -0,0 +1,264 @@
+package supabase
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LogSource identifies a Supabase service that produces logs.
+type LogSource string
+
+const (
+	// LogSourcePostgres is the Postgres database log stream.
+	LogSourcePostgres LogSource = "postgres"
+	// LogSourceFunctions is the Edge Functions console log stream.
+	LogSourceFunctions LogSource = "functions"
+	// LogSourceAuth is the GoTrue auth service log stream.
+	LogSourceAuth LogSource = "auth"
+)
+
+// AllLogSources returns every supported log source in display order.
+func AllLogSources() []LogSource {
+	return []LogSource{LogSourcePostgres, LogSourceFunctions, LogSourceAuth}
+}
+
+// ParseLogSource normalizes a user-supplied source name (including common aliases).
+func ParseLogSource(name string) (LogSource, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "postgres", "pg", "db", "database":
+		return LogSourcePostgres, nil
+	case "functions", "function", "fn", "edge":
+		return LogSourceFunctions, nil
+	case "auth", "gotrue":
+		return LogSourceAuth, nil
+	default:
+		return "", fmt.Errorf("unknown log source '%s' (valid: postgres, functions, auth)", name)
+	}
+}
+
+// LogEntry is a single log line from any Supabase service.
+type LogEntry struct {
+	Timestamp time.Time
+	Source    LogSource
+	Level     string
+	Message   string
+}
+
+// logSourceQuery returns the analytics SQL used to read a log source.
+func logSourceQuery(source LogSource, limit int) (string, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	switch source {
+	case LogSourcePostgres:
+		return fmt.Sprintf(`SELECT t.timestamp, t.event_message, p.error_severity as level
+FROM postgres_logs t
+CROSS JOIN UNNEST(t.metadata) as m
+CROSS JOIN UNNEST(m.parsed) as p
+ORDER BY t.timestamp DESC
+LIMIT %d`, limit), nil
+	case LogSourceFunctions:
+		return fmt.Sprintf(`SELECT t.timestamp, t.event_message, m.level
+FROM function_logs t
+CROSS JOIN UNNEST(t.metadata) as m
+ORDER BY t.timestamp DESC
+LIMIT %d`, limit), nil
+	case LogSourceAuth:
+		return fmt.Sprintf(`SELECT t.timestamp, t.event_message, m.level
+FROM auth_logs t
+CROSS JOIN UNNEST(t.metadata) as m
+ORDER BY t.timestamp DESC
+LIMIT %d`, limit), nil
+	default:
+		return "", fmt.Errorf("unsupported log source: %s", source)
+	}
+}
+
+// GetServiceLogs retrieves logs for a single service between start and end.
+func (c *ManagementClient) GetServiceLogs(projectRef string, source LogSource, start, end time.Time, limit int) ([]LogEntry, error) {
+	sql, err := logSourceQuery(source, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := c.executeFunctionLogsQuery(projectRef, sql, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s logs: %w", source, err)
+	}
+
+	entries := make([]LogEntry, 0, len(raw))
+	for _, r := range raw {
+		ts, err := time.Parse(time.RFC3339Nano, r.Timestamp)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, LogEntry{
+			Timestamp: ts,
+			Source:    source,
+			Level:     strings.ToLower(r.Level),
+			Message:   r.EventMessage,
+		})
+	}
+
+	return entries, nil
+}
+
+// MergeLogEntries combines entries from several sources into one stream ordered oldest first.
+func MergeLogEntries(groups ...[]LogEntry) []LogEntry {
+	var merged []LogEntry
+	for _, g := range groups {
+		merged = append(merged, g...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Timestamp.Equal(merged[j].Timestamp) {
+			return merged[i].Source < merged[j].Source
+		}
+		return merged[i].Timestamp.Before(merged[j].Timestamp)
+	})
+
+	return merged
+}
+
+// apnsTokenPattern matches a hex-encoded APNs device token.
+var apnsTokenPattern = regexp.MustCompile(`(?i)\b[0-9a-f]{64}\b`)
+
+// ExtractAPNsTokens returns the distinct device tokens that appear in a log
+// message, lowercased, in order of appearance.
+func ExtractAPNsTokens(message string) []string {
+	var tokens []string
+	seen := make(map[string]bool)
+	for _, m := range apnsTokenPattern.FindAllString(message, -1) {
+		token := strings.ToLower(m)
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// GetFunctionLastInvocations returns when each Edge Function was last
+// invoked between start and end, keyed by function name. Functions with no
+// invocations in the window are absent from the map.
+func (c *ManagementClient) GetFunctionLastInvocations(projectRef string, start, end time.Time) (map[string]time.Time, error) {
+	sql := `SELECT MAX(t.timestamp) as timestamp, r.pathname as event_message, 'info' as level
+FROM function_edge_logs t
+CROSS JOIN UNNEST(t.metadata) as m
+CROSS JOIN UNNEST(m.request) as r
+GROUP BY r.pathname`
+
+	raw, err := c.executeFunctionLogsQuery(projectRef, sql, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch function invocations: %w", err)
+	}
+	return lastInvocations(raw), nil
+}
+
+// lastInvocations keeps the newest timestamp per function name.
+func lastInvocations(entries []FunctionLogEntry) map[string]time.Time {
+	last := make(map[string]time.Time)
+	for _, e := range entries {
+		name := functionNameFromPath(e.EventMessage)
+		if name == "" {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+		if err != nil {
+			continue
+		}
+		if ts.After(last[name]) {
+			last[name] = ts
+		}
+	}
+	return last
+}
+
+// functionNameFromPath extracts the function name from a request path or
+// URL such as "/functions/v1/send-email?id=1".
+func functionNameFromPath(path string) string {
+	const prefix = "/functions/v1/"
+	i := strings.Index(path, prefix)
+	if i < 0 {
+		return ""
+	}
+	name := path[i+len(prefix):]
+	if j := strings.IndexAny(name, "/?# "); j >= 0 {
+		name = name[:j]
+	}
+	return name
+}
+
+// FunctionMetrics summarizes the invocations of one Edge Function over a
+// window. Errors are invocations answered with a 5xx status.
+type FunctionMetrics struct {
+	Name        string
+	Invocations int
+	Errors      int
+	P95         time.Duration
+}
+
+// ErrorRate returns the share of invocations that failed, from 0 to 1.
+func (m FunctionMetrics) ErrorRate() float64 {
+	if m.Invocations == 0 {
+		return 0
+	}
+	return float64(m.Errors) / float64(m.Invocations)
+}
+
+// functionStatsRow is one row of the function metrics query, per request
+// path.
+type functionStatsRow struct {
+	Pathname    string  `json:"pathname"`
+	Invocations float64 `json:"invocations"`
+	Errors      float64 `json:"errors"`
+	P95Ms       float64 `json:"p95_ms"`
+}
+
+// GetFunctionMetrics returns invocation counts, errors, and p95 execution
+// time for each Edge Function invoked between start and end, keyed by
+// function name.
+func (c *ManagementClient) GetFunctionMetrics(projectRef string, start, end time.Time) (map[string]FunctionMetrics, error) {
+	sql := `SELECT r.pathname as pathname,
+  COUNT(*) as invocations,
+  COUNTIF(resp.status_code >= 500) as errors,
+  APPROX_QUANTILES(m.execution_time_ms, 100)[OFFSET(95)] as p95_ms
+FROM function_edge_logs t
+CROSS JOIN UNNEST(t.metadata) as m
+CROSS JOIN UNNEST(m.request) as r
+CROSS JOIN UNNEST(m.response) as resp
+GROUP BY r.pathname
+LIMIT 1000`
+
+	var rows []functionStatsRow
+	if err := c.queryLogs(projectRef, sql, start, end, &rows); err != nil {
+		return nil, fmt.Errorf("failed to fetch function metrics: %w", err)
+	}
+	return aggregateFunctionMetrics(rows), nil
+}
+
+// aggregateFunctionMetrics combines per-path rows into per-function metrics.
+// A function served on several paths reports the highest p95 among them.
+func aggregateFunctionMetrics(rows []functionStatsRow) map[string]FunctionMetrics {
+	metrics := make(map[string]FunctionMetrics)
+	for _, row := range rows {
+		name := functionNameFromPath(row.Pathname)
+		if name == "" {
+			continue
+		}
+		m := metrics[name]
+		m.Name = name
+		m.Invocations += int(row.Invocations)
+		m.Errors += int(row.Errors)
+		if p95 := time.Duration(row.P95Ms * float64(time.Millisecond)); p95 > m.P95 {
+			m.P95 = p95
+		}
+		metrics[name] = m
+	}
+	return metrics
+}
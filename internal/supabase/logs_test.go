@@ -0,0 +1,163 @@
+package supabase
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogSource(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LogSource
+		wantErr bool
+	}{
+		{"postgres", LogSourcePostgres, false},
+		{"DB", LogSourcePostgres, false},
+		{"edge", LogSourceFunctions, false},
+		{" functions ", LogSourceFunctions, false},
+		{"auth", LogSourceAuth, false},
+		{"realtime", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLogSource(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLogSource(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLogSource(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMergeLogEntries_OrdersOldestFirst(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	pg := []LogEntry{
+		{Timestamp: base.Add(3 * time.Second), Source: LogSourcePostgres, Message: "pg-2"},
+		{Timestamp: base.Add(1 * time.Second), Source: LogSourcePostgres, Message: "pg-1"},
+	}
+	auth := []LogEntry{
+		{Timestamp: base.Add(2 * time.Second), Source: LogSourceAuth, Message: "auth-1"},
+		{Timestamp: base.Add(3 * time.Second), Source: LogSourceAuth, Message: "auth-2"},
+	}
+
+	merged := MergeLogEntries(pg, auth)
+	want := []string{"pg-1", "auth-1", "auth-2", "pg-2"}
+	if len(merged) != len(want) {
+		t.Fatalf("MergeLogEntries length = %d, want %d", len(merged), len(want))
+	}
+	for i, msg := range want {
+		if merged[i].Message != msg {
+			t.Errorf("merged[%d] = %q, want %q", i, merged[i].Message, msg)
+		}
+	}
+}
+
+func TestLogSourceQuery_UnknownSource(t *testing.T) {
+	if _, err := logSourceQuery(LogSource("realtime"), 10); err == nil {
+		t.Error("expected error for unsupported source")
+	}
+}
+
+func TestExtractAPNsTokens(t *testing.T) {
+	token := "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"
+	other := "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{"plain", "registered device token " + token, []string{token}},
+		{"uppercase", "token=" + strings.ToUpper(token), []string{token}},
+		{"json", `{"token":"` + token + `","env":"sandbox"}`, []string{token}},
+		{"duplicates", token + " " + other + " " + token, []string{token, other}},
+		{"too short", "token " + token[:63], nil},
+		{"too long", "hash " + token + "ab", nil},
+		{"none", "push sent", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractAPNsTokens(tt.message)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ExtractAPNsTokens() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("ExtractAPNsTokens()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLastInvocations(t *testing.T) {
+	entries := []FunctionLogEntry{
+		{Timestamp: "2026-03-01T10:00:00Z", EventMessage: "/functions/v1/send-email"},
+		{Timestamp: "2026-03-02T10:00:00Z", EventMessage: "/functions/v1/send-email?retry=1"},
+		{Timestamp: "2026-02-01T10:00:00Z", EventMessage: "POST | 200 | https://abc.supabase.co/functions/v1/webhook/stripe"},
+		{Timestamp: "2026-03-05T10:00:00Z", EventMessage: "/rest/v1/users"},
+		{Timestamp: "not a time", EventMessage: "/functions/v1/broken"},
+	}
+
+	got := lastInvocations(entries)
+	want := map[string]string{
+		"send-email": "2026-03-02T10:00:00Z",
+		"webhook":    "2026-02-01T10:00:00Z",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("lastInvocations() = %v, want %d entries", got, len(want))
+	}
+	for name, ts := range want {
+		if got[name].Format(time.RFC3339) != ts {
+			t.Errorf("lastInvocations()[%q] = %v, want %s", name, got[name], ts)
+		}
+	}
+}
+
+func TestGetFunctionMetrics(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("sql")
+		w.Write([]byte(`{"result": [
+			{"pathname": "/functions/v1/send-email", "invocations": 200, "errors": 4, "p95_ms": 120.5},
+			{"pathname": "/functions/v1/api/users", "invocations": 10, "errors": 0, "p95_ms": 80},
+			{"pathname": "/functions/v1/api/orders", "invocations": 30, "errors": 3, "p95_ms": 300},
+			{"pathname": "/rest/v1/profiles", "invocations": 5, "errors": 0, "p95_ms": null}
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+	ConfigureEndpoints(Endpoints{ManagementURL: server.URL})
+	t.Cleanup(func() { ConfigureEndpoints(Endpoints{}) })
+	client := &ManagementClient{accessToken: "test", httpClient: server.Client()}
+
+	end := time.Now()
+	metrics, err := client.GetFunctionMetrics("ref", end.Add(-time.Hour), end)
+	if err != nil {
+		t.Fatalf("GetFunctionMetrics() error = %v", err)
+	}
+	if !strings.Contains(query, "function_edge_logs") {
+		t.Errorf("query = %q, want function_edge_logs", query)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("GetFunctionMetrics() = %+v, want send-email and api", metrics)
+	}
+
+	email := metrics["send-email"]
+	if email.Invocations != 200 || email.Errors != 4 || email.P95 != 120500*time.Microsecond {
+		t.Errorf("send-email = %+v", email)
+	}
+	if rate := email.ErrorRate(); rate != 0.02 {
+		t.Errorf("send-email ErrorRate() = %v, want 0.02", rate)
+	}
+	api := metrics["api"]
+	if api.Invocations != 40 || api.Errors != 3 || api.P95 != 300*time.Millisecond {
+		t.Errorf("api = %+v, want paths combined with the highest p95", api)
+	}
+}
@@ -15,10 +15,9 @@ import (
 	"sort"
 	"strings"
 	"time"
-)
 
-const (
-	managementAPIBaseURL = "https://api.supabase.com"
+	"github.com/undrift/drift/internal/errs"
+	"github.com/undrift/drift/internal/metrics"
 )
 
 // ManagementClient provides access to Supabase Management API.
@@ -38,7 +37,8 @@ func NewManagementClient() (*ManagementClient, error) {
 	return &ManagementClient{
 		accessToken: token,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: retryTransport{base: metrics.Transport(nil)},
 		},
 	}, nil
 }
@@ -76,7 +76,7 @@ func getAccessToken() (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("could not find Supabase access token\n\n" +
+	return "", errs.Authf("could not find Supabase access token\n\n" +
 		"Set SUPABASE_ACCESS_TOKEN environment variable or run 'supabase login'")
 }
 
@@ -145,7 +145,7 @@ func getTokenFromLinuxKeyring() string {
 
 // GetSecrets retrieves all secrets with their values for a project.
 func (c *ManagementClient) GetSecrets(projectRef string) ([]Secret, error) {
-	url := fmt.Sprintf("%s/v1/projects/%s/secrets", managementAPIBaseURL, projectRef)
+	url := fmt.Sprintf("%s/v1/projects/%s/secrets", ManagementAPIURL(), projectRef)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -180,38 +180,52 @@ func (c *ManagementClient) GetSecrets(projectRef string) ([]Secret, error) {
 
 // SetSecrets sets multiple secrets on a project (creates or updates).
 func (c *ManagementClient) SetSecrets(projectRef string, secrets []Secret) error {
-	url := fmt.Sprintf("%s/v1/projects/%s/secrets", managementAPIBaseURL, projectRef)
+	_, err := c.setSecrets(projectRef, secrets)
+	return err
+}
+
+// setSecrets is SetSecrets that also returns how many requests were sent,
+// counting retries.
+func (c *ManagementClient) setSecrets(projectRef string, secrets []Secret) (int, error) {
+	url := fmt.Sprintf("%s/v1/projects/%s/secrets", ManagementAPIURL(), projectRef)
 
 	jsonData, err := json.Marshal(secrets)
 	if err != nil {
-		return fmt.Errorf("failed to marshal secrets: %w", err)
+		return 0, fmt.Errorf("failed to marshal secrets: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonData)))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
+	// Setting a secret is an upsert, so resending after an ambiguous
+	// failure is safe.
+	calls := 0
+	req = countAttempts(idempotent(req), &calls)
 
 	req.Header.Set("Authorization", "Bearer "+c.accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
+	if calls == 0 {
+		calls = 1 // a transport without retries does not count
+	}
 	if err != nil {
-		return fmt.Errorf("failed to set secrets: %w", err)
+		return calls, fmt.Errorf("failed to set secrets: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return calls, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	return nil
+	return calls, nil
 }
 
 // DeleteSecret deletes a secret from a project.
 func (c *ManagementClient) DeleteSecret(projectRef string, secretName string) error {
-	url := fmt.Sprintf("%s/v1/projects/%s/secrets", managementAPIBaseURL, projectRef)
+	url := fmt.Sprintf("%s/v1/projects/%s/secrets", ManagementAPIURL(), projectRef)
 
 	// The delete endpoint uses a body with the names to delete
 	jsonData, err := json.Marshal([]string{secretName})
@@ -255,7 +269,7 @@ type ProjectStatusResponse struct {
 // GetProjectStatus returns the health status of a Supabase project.
 // Returns values like "ACTIVE_HEALTHY", "ACTIVE_UNHEALTHY", "INACTIVE", "COMING_UP", etc.
 func (c *ManagementClient) GetProjectStatus(projectRef string) (string, error) {
-	url := fmt.Sprintf("%s/v1/projects/%s/health", managementAPIBaseURL, projectRef)
+	url := fmt.Sprintf("%s/v1/projects/%s/health", ManagementAPIURL(), projectRef)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -391,8 +405,47 @@ LIMIT 50`, functionName)
 
 // executeFunctionLogsQuery executes a logs query and parses the response.
 func (c *ManagementClient) executeFunctionLogsQuery(projectRef, sql string, startTime, endTime time.Time) ([]FunctionLogEntry, error) {
+	var result []struct {
+		Timestamp    int64       `json:"timestamp"`
+		EventMessage string      `json:"event_message"`
+		Level        interface{} `json:"level"` // Can be string or int (status code)
+	}
+	if err := c.queryLogs(projectRef, sql, startTime, endTime, &result); err != nil {
+		return nil, err
+	}
+
+	// Convert to our format
+	var logs []FunctionLogEntry
+	for _, entry := range result {
+		// Convert timestamp from microseconds to RFC3339
+		ts := time.UnixMicro(entry.Timestamp).UTC().Format(time.RFC3339Nano)
+
+		// Convert level to string (could be int status code or string log level)
+		var level string
+		switch v := entry.Level.(type) {
+		case string:
+			level = v
+		case float64:
+			level = fmt.Sprintf("%d", int(v))
+		default:
+			level = "info"
+		}
+
+		logs = append(logs, FunctionLogEntry{
+			Timestamp:    ts,
+			EventMessage: entry.EventMessage,
+			Level:        level,
+		})
+	}
+
+	return logs, nil
+}
+
+// queryLogs runs an analytics SQL query over the logs between startTime and
+// endTime and decodes the result rows into out.
+func (c *ManagementClient) queryLogs(projectRef, sql string, startTime, endTime time.Time, out interface{}) error {
 	apiURL := fmt.Sprintf("%s/v1/projects/%s/analytics/endpoints/logs.all?sql=%s&iso_timestamp_start=%s&iso_timestamp_end=%s",
-		managementAPIBaseURL,
+		ManagementAPIURL(),
 		projectRef,
 		url.QueryEscape(sql),
 		url.QueryEscape(startTime.Format(time.RFC3339)),
@@ -401,7 +454,7 @@ func (c *ManagementClient) executeFunctionLogsQuery(projectRef, sql string, star
 
 	req, err := http.NewRequest("GET", apiURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.accessToken)
@@ -409,67 +462,44 @@ func (c *ManagementClient) executeFunctionLogsQuery(projectRef, sql string, star
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch logs: %w", err)
+		return fmt.Errorf("failed to fetch logs: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	// Parse the response
 	var response struct {
-		Result []struct {
-			Timestamp    int64       `json:"timestamp"`
-			EventMessage string      `json:"event_message"`
-			Level        interface{} `json:"level"` // Can be string or int (status code)
-		} `json:"result"`
-		Error interface{} `json:"error"`
+		Result json.RawMessage `json:"result"`
+		Error  interface{}     `json:"error"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse logs response: %w", err)
+		return fmt.Errorf("failed to parse logs response: %w", err)
 	}
 
 	if response.Error != nil {
-		return nil, fmt.Errorf("query error: %v", response.Error)
+		return fmt.Errorf("query error: %v", response.Error)
 	}
-
-	// Convert to our format
-	var logs []FunctionLogEntry
-	for _, entry := range response.Result {
-		// Convert timestamp from microseconds to RFC3339
-		ts := time.UnixMicro(entry.Timestamp).UTC().Format(time.RFC3339Nano)
-
-		// Convert level to string (could be int status code or string log level)
-		var level string
-		switch v := entry.Level.(type) {
-		case string:
-			level = v
-		case float64:
-			level = fmt.Sprintf("%d", int(v))
-		default:
-			level = "info"
-		}
-
-		logs = append(logs, FunctionLogEntry{
-			Timestamp:    ts,
-			EventMessage: entry.EventMessage,
-			Level:        level,
-		})
+	if len(response.Result) == 0 {
+		return nil
 	}
-
-	return logs, nil
+	if err := json.Unmarshal(response.Result, out); err != nil {
+		return fmt.Errorf("failed to parse logs response: %w", err)
+	}
+	return nil
 }
 
 // getProjectStatusFromInfo gets status from the project info endpoint.
 func (c *ManagementClient) getProjectStatusFromInfo(projectRef string) (string, error) {
-	url := fmt.Sprintf("%s/v1/projects/%s", managementAPIBaseURL, projectRef)
+	url := fmt.Sprintf("%s/v1/projects/%s", ManagementAPIURL(), projectRef)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -0,0 +1,63 @@
+package supabase
+
+import (
+	"sync"
+	"time"
+)
+
+// PoolerDiscoveryTTL is how long a discovered pooler host is trusted when
+// the branch lookup fails. Supabase occasionally moves projects between
+// regional poolers, so this is much shorter than the API response cache.
+const PoolerDiscoveryTTL = time.Hour
+
+// PoolerDiscovery is the pooler host found for a branch. Only the host,
+// port, and project ref are cached on disk, never credentials.
+type PoolerDiscovery struct {
+	Host         string    `json:"host"`
+	Port         int       `json:"port"`
+	ProjectRef   string    `json:"project_ref"`
+	DiscoveredAt time.Time `json:"-"`
+}
+
+var (
+	connInfoMu   sync.Mutex
+	connInfoMemo = make(map[string]*BranchConnectionInfo)
+)
+
+func (c *Client) poolerCacheKey(branchName string) string {
+	return "pooler-" + c.cacheScope() + "-" + branchName
+}
+
+// CachedPoolerDiscovery returns the pooler host discovered for branchName
+// within PoolerDiscoveryTTL.
+func (c *Client) CachedPoolerDiscovery(branchName string) (*PoolerDiscovery, bool) {
+	var d PoolerDiscovery
+	fetchedAt, ok := readCacheWithTTL(c.poolerCacheKey(branchName), PoolerDiscoveryTTL, &d)
+	if !ok || d.Host == "" {
+		return nil, false
+	}
+	d.DiscoveredAt = fetchedAt
+	return &d, true
+}
+
+// RememberPooler records a pooler host that works for branchName.
+func (c *Client) RememberPooler(branchName string, d PoolerDiscovery) {
+	writeCache(c.poolerCacheKey(branchName), d)
+}
+
+// connectionInfo returns the connection info a cached discovery can provide.
+func (d *PoolerDiscovery) connectionInfo() *BranchConnectionInfo {
+	return &BranchConnectionInfo{
+		PoolerHost:   d.Host,
+		PoolerPort:   d.Port,
+		ProjectRef:   d.ProjectRef,
+		DiscoveredAt: d.DiscoveredAt,
+	}
+}
+
+// resetConnectionInfoMemo forgets connection info looked up by this process.
+func resetConnectionInfoMemo() {
+	connInfoMu.Lock()
+	defer connInfoMu.Unlock()
+	connInfoMemo = make(map[string]*BranchConnectionInfo)
+}
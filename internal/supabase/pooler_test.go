@@ -0,0 +1,63 @@
+package supabase
+
+import (
+	"testing"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+// fakeSupabaseCLI puts a supabase script on PATH that prints output and
+// exits with code.
+func fakeSupabaseCLI(t *testing.T, output string, code int) *testutil.FakeBin {
+	t.Helper()
+	fake := testutil.NewFakeBin(t, "supabase", testutil.Response{Stdout: output, Exit: code})
+	resetConnectionInfoMemo()
+	t.Cleanup(resetConnectionInfoMemo)
+	return fake
+}
+
+func TestGetBranchConnectionInfo_MemoizesAndCachesPooler(t *testing.T) {
+	resetCache(t, 0)
+	fake := fakeSupabaseCLI(t, `POSTGRES_URL="postgresql://postgres.abc:pw@aws-1-us-east-2.pooler.supabase.com:6543/postgres"`, 0)
+	client := &Client{ProjectRef: "parent"}
+
+	for i := 0; i < 2; i++ {
+		info, err := client.GetBranchConnectionInfo("dev")
+		if err != nil {
+			t.Fatalf("GetBranchConnectionInfo() error = %v", err)
+		}
+		if info.PoolerHost != "aws-1-us-east-2.pooler.supabase.com" || info.PostgresURL == "" {
+			t.Fatalf("GetBranchConnectionInfo() = %+v", info)
+		}
+	}
+	if n := len(fake.Calls()); n != 1 {
+		t.Errorf("supabase called %d times, want 1", n)
+	}
+
+	d, ok := client.CachedPoolerDiscovery("dev")
+	if !ok || d.Host != "aws-1-us-east-2.pooler.supabase.com" || d.Port != 6543 || d.ProjectRef != "abc" {
+		t.Fatalf("CachedPoolerDiscovery() = %+v, %v", d, ok)
+	}
+	if _, ok := CachedSince(); ok {
+		t.Error("pooler discovery should not mark output as cached")
+	}
+}
+
+func TestGetBranchConnectionInfo_FallsBackToDiscovery(t *testing.T) {
+	resetCache(t, 0)
+	fakeSupabaseCLI(t, "error: branch lookup failed", 1)
+	client := &Client{ProjectRef: "parent"}
+
+	if _, err := client.GetBranchConnectionInfo("dev"); err == nil {
+		t.Fatal("GetBranchConnectionInfo() should fail without a discovered pooler")
+	}
+
+	client.RememberPooler("dev", PoolerDiscovery{Host: "aws-0-eu-west-1.pooler.supabase.com", Port: 5432, ProjectRef: "abc"})
+	info, err := client.GetBranchConnectionInfo("dev")
+	if err != nil {
+		t.Fatalf("GetBranchConnectionInfo() error = %v", err)
+	}
+	if info.PoolerHost != "aws-0-eu-west-1.pooler.supabase.com" || info.PostgresURL != "" || info.DiscoveredAt.IsZero() {
+		t.Errorf("GetBranchConnectionInfo() = %+v, want cached pooler without credentials", info)
+	}
+}
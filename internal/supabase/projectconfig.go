@@ -0,0 +1,235 @@
+package supabase
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ProjectConfig is the subset of supabase/config.toml drift reads: scalar
+// and string-array values keyed by their dotted path, e.g. "auth.site_url"
+// or "remotes.staging.auth.site_url". Inline tables and arrays of tables are
+// not supported and are skipped.
+type ProjectConfig struct {
+	Values map[string]interface{}
+}
+
+var (
+	tomlTableHeader = regexp.MustCompile(`^\[\s*([A-Za-z0-9_."-]+?)\s*\]$`)
+	tomlKeyValue    = regexp.MustCompile(`^([A-Za-z0-9_-]+|"[^"]*")\s*=\s*(.*)$`)
+	tomlEnvRef      = regexp.MustCompile(`^env\(([A-Za-z_][A-Za-z0-9_]*)\)$`)
+)
+
+// LoadProjectConfig reads a config.toml.
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ParseProjectConfig(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseProjectConfig parses config.toml content.
+func ParseProjectConfig(src string) (*ProjectConfig, error) {
+	cfg := &ProjectConfig{Values: make(map[string]interface{})}
+	table := ""
+	lines := strings.Split(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if line == "" || strings.HasPrefix(line, "[[") {
+			continue
+		}
+		if m := tomlTableHeader.FindStringSubmatch(line); m != nil {
+			table = strings.ReplaceAll(m[1], `"`, "")
+			continue
+		}
+		m := tomlKeyValue.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: cannot parse %q", i+1, line)
+		}
+		raw := strings.TrimSpace(m[2])
+		// Arrays may span lines until the closing bracket.
+		if strings.HasPrefix(raw, "[") {
+			for !strings.HasSuffix(raw, "]") && i+1 < len(lines) {
+				i++
+				raw += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+			}
+		}
+		value, ok, err := parseTOMLValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if !ok {
+			continue
+		}
+		key := strings.Trim(m[1], `"`)
+		if table != "" {
+			key = table + "." + key
+		}
+		cfg.Values[key] = value
+	}
+	return cfg, nil
+}
+
+// ForProject returns the values that apply to projectRef: the top-level
+// settings overridden by any [remotes.<name>] whose project_id is
+// projectRef. The remotes themselves are left out.
+func (c *ProjectConfig) ForProject(projectRef string) map[string]interface{} {
+	out := make(map[string]interface{})
+	for k, v := range c.Values {
+		if !strings.HasPrefix(k, "remotes.") {
+			out[k] = v
+		}
+	}
+	for k, v := range c.Values {
+		rest, ok := strings.CutPrefix(k, "remotes.")
+		if !ok {
+			continue
+		}
+		name, setting, ok := strings.Cut(rest, ".")
+		if !ok || setting == "project_id" {
+			continue
+		}
+		if c.Values["remotes."+name+".project_id"] == projectRef {
+			out[setting] = v
+		}
+	}
+	return out
+}
+
+// ResolveEnv replaces an "env(NAME)" string value with the environment
+// variable, as the Supabase CLI does. ok is false when the variable is not
+// set.
+func ResolveEnv(value interface{}) (interface{}, bool) {
+	s, isString := value.(string)
+	if !isString {
+		return value, true
+	}
+	m := tomlEnvRef.FindStringSubmatch(s)
+	if m == nil {
+		return value, true
+	}
+	v, ok := os.LookupEnv(m[1])
+	return v, ok
+}
+
+// parseTOMLValue parses a string, integer, boolean, or array of strings.
+// ok is false for other value types.
+func parseTOMLValue(raw string) (interface{}, bool, error) {
+	switch {
+	case raw == "true" || raw == "false":
+		return raw == "true", true, nil
+	case strings.HasPrefix(raw, `"`):
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid string %s", raw)
+		}
+		return s, true, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return nil, false, fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], true, nil
+	case strings.HasPrefix(raw, "["):
+		inner := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(raw, "["), "]"))
+		items := []string{}
+		for _, part := range splitTOMLArray(inner) {
+			v, ok, err := parseTOMLValue(part)
+			if err != nil {
+				return nil, false, err
+			}
+			s, isString := v.(string)
+			if !ok || !isString {
+				return nil, false, nil
+			}
+			items = append(items, s)
+		}
+		return items, true, nil
+	case strings.HasPrefix(raw, "{"):
+		return nil, false, nil
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 10, 64)
+	if err != nil {
+		return nil, false, nil
+	}
+	return n, true, nil
+}
+
+// splitTOMLArray splits array items on commas outside quotes.
+func splitTOMLArray(s string) []string {
+	var parts []string
+	var cur strings.Builder
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			if p := strings.TrimSpace(cur.String()); p != "" {
+				parts = append(parts, p)
+			}
+			cur.Reset()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	if p := strings.TrimSpace(cur.String()); p != "" {
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+// stripTOMLComment removes a # comment that is not inside a string.
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// ParseSize parses a config.toml size such as "50MiB" or "1GB" into bytes.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30},
+		{"KB", 1000}, {"MB", 1000 * 1000}, {"GB", 1000 * 1000 * 1000},
+		{"B", 1},
+	}
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(s, u.suffix); ok {
+			n, err := strconv.ParseInt(strings.TrimSpace(num), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size %q", s)
+			}
+			return n * u.factor, nil
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n, nil
+}
@@ -0,0 +1,130 @@
+package supabase
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const sampleProjectConfig = `# A comment
+project_id = "local"
+
+[auth]
+enabled = true
+site_url = "http://127.0.0.1:3000" # trailing comment
+additional_redirect_urls = [
+  "https://127.0.0.1:3000",
+  "app://callback#fragment",
+]
+jwt_expiry = 3_600
+enable_signup = true
+
+[auth.email]
+enable_confirmations = false
+
+[auth.external.apple]
+extra = { a = 1 }
+
+[storage]
+file_size_limit = "50MiB"
+
+[remotes.staging]
+project_id = "stagingref"
+
+[remotes.staging.auth]
+site_url = "env(STAGING_SITE_URL)"
+`
+
+func TestParseProjectConfig(t *testing.T) {
+	cfg, err := ParseProjectConfig(sampleProjectConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"project_id":                      "local",
+		"auth.enabled":                    true,
+		"auth.site_url":                   "http://127.0.0.1:3000",
+		"auth.additional_redirect_urls":   []string{"https://127.0.0.1:3000", "app://callback#fragment"},
+		"auth.jwt_expiry":                 int64(3600),
+		"auth.enable_signup":              true,
+		"auth.email.enable_confirmations": false,
+		"storage.file_size_limit":         "50MiB",
+		"remotes.staging.project_id":      "stagingref",
+		"remotes.staging.auth.site_url":   "env(STAGING_SITE_URL)",
+	}
+	if !reflect.DeepEqual(cfg.Values, want) {
+		t.Errorf("Values =\n%v\nwant\n%v", cfg.Values, want)
+	}
+
+	staging := cfg.ForProject("stagingref")
+	if staging["auth.site_url"] != "env(STAGING_SITE_URL)" || staging["auth.jwt_expiry"] != int64(3600) {
+		t.Errorf("ForProject(stagingref) = %v", staging)
+	}
+	if _, ok := staging["remotes.staging.project_id"]; ok {
+		t.Error("ForProject() kept remotes keys")
+	}
+	if prod := cfg.ForProject("prodref"); prod["auth.site_url"] != "http://127.0.0.1:3000" {
+		t.Errorf("ForProject(prodref) site_url = %v", prod["auth.site_url"])
+	}
+}
+
+func TestResolveEnv(t *testing.T) {
+	t.Setenv("DRIFT_TEST_SITE", "https://staging.example.com")
+	if v, ok := ResolveEnv("env(DRIFT_TEST_SITE)"); !ok || v != "https://staging.example.com" {
+		t.Errorf("ResolveEnv(set) = %v, %v", v, ok)
+	}
+	if _, ok := ResolveEnv("env(DRIFT_TEST_UNSET_VAR)"); ok {
+		t.Error("ResolveEnv(unset) ok = true")
+	}
+	if v, ok := ResolveEnv(int64(5)); !ok || v != int64(5) {
+		t.Errorf("ResolveEnv(int) = %v, %v", v, ok)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{"50MiB": 50 << 20, "1GB": 1000 * 1000 * 1000, "512KiB": 512 << 10, "100": 100, "10 MB": 10 * 1000 * 1000}
+	for in, want := range tests {
+		if got, err := ParseSize(in); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseSize("lots"); err == nil {
+		t.Error("ParseSize(lots) succeeded")
+	}
+}
+
+func TestManagementClient_ProjectConfig(t *testing.T) {
+	var patched map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/ref/config/auth" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "PATCH" {
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &patched)
+		}
+		w.Write([]byte(`{"site_url": "https://example.com", "jwt_exp": 3600}`))
+	}))
+	t.Cleanup(server.Close)
+	ConfigureEndpoints(Endpoints{ManagementURL: server.URL})
+	t.Cleanup(func() { ConfigureEndpoints(Endpoints{}) })
+	client := &ManagementClient{accessToken: "test", httpClient: server.Client()}
+
+	auth, err := client.GetAuthConfig("ref")
+	if err != nil || auth["site_url"] != "https://example.com" {
+		t.Fatalf("GetAuthConfig() = %v, %v", auth, err)
+	}
+	if err := client.UpdateAuthConfig("ref", map[string]interface{}{"site_url": "https://new.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if patched["site_url"] != "https://new.example.com" {
+		t.Errorf("PATCH body = %v", patched)
+	}
+	if _, err := client.GetStorageConfig("ref"); err == nil {
+		t.Error("GetStorageConfig() expected error for 404")
+	}
+}
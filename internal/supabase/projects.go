@@ -30,7 +30,16 @@ type ProjectDatabase struct {
 
 // ListProjects returns all Supabase projects accessible to the user.
 func (c *Client) ListProjects() ([]Project, error) {
-	result, err := shell.Run("supabase", "projects", "list", "--output", "json")
+	const key = "projects"
+	if IsOffline() {
+		var cached []Project
+		if readCache(key, &cached) {
+			return cached, nil
+		}
+		return nil, errOffline
+	}
+
+	result, err := runCLI("projects", "list", "--output", "json")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
@@ -40,6 +49,7 @@ func (c *Client) ListProjects() ([]Project, error) {
 		return nil, fmt.Errorf("failed to parse projects: %w", err)
 	}
 
+	writeCache(key, projects)
 	return projects, nil
 }
 
@@ -78,6 +88,52 @@ func (c *Client) FindProjectByRef(ref string) (*Project, error) {
 	return nil, fmt.Errorf("project with ref '%s' not found", ref)
 }
 
+// Organization is a Supabase organization.
+type Organization struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListOrganizations returns the organizations the user belongs to.
+func (c *Client) ListOrganizations() ([]Organization, error) {
+	result, err := runCLI("orgs", "list", "--output", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	var orgs []Organization
+	if err := json.Unmarshal([]byte(result.Stdout), &orgs); err != nil {
+		return nil, fmt.Errorf("failed to parse organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+// CreateProject creates a hosted project in orgID. The database password
+// is passed on the command line, as the Supabase CLI requires.
+func (c *Client) CreateProject(name, orgID, region, dbPassword string) (*Project, error) {
+	result, err := shell.Run("supabase", "projects", "create", name,
+		"--org-id", orgID,
+		"--region", region,
+		"--db-password", dbPassword,
+		"--output", "json")
+	if err != nil {
+		errMsg := err.Error()
+		if result != nil && result.Stderr != "" {
+			errMsg = strings.TrimSpace(result.Stderr)
+		}
+		return nil, fmt.Errorf("failed to create project: %s", errMsg)
+	}
+
+	var project Project
+	if err := json.Unmarshal([]byte(result.Stdout), &project); err != nil {
+		return nil, fmt.Errorf("failed to parse created project: %w", err)
+	}
+	if project.Ref == "" {
+		project.Ref = project.ID
+	}
+	return &project, nil
+}
+
 // LinkProject links the current directory to a Supabase project.
 func (c *Client) LinkProject(projectRef string) error {
 	result, err := shell.Run("supabase", "link", "--project-ref", projectRef)
@@ -0,0 +1,53 @@
+package supabase
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GetAuthConfig returns a project's live auth settings as the Management
+// API names them (site_url, uri_allow_list, jwt_exp, ...).
+func (c *ManagementClient) GetAuthConfig(projectRef string) (map[string]interface{}, error) {
+	return c.getProjectConfig(projectRef, "auth")
+}
+
+// UpdateAuthConfig changes the given auth settings and leaves the rest.
+func (c *ManagementClient) UpdateAuthConfig(projectRef string, changes map[string]interface{}) error {
+	return c.updateProjectConfig(projectRef, "auth", changes)
+}
+
+// GetStorageConfig returns a project's live storage settings (fileSizeLimit,
+// features).
+func (c *ManagementClient) GetStorageConfig(projectRef string) (map[string]interface{}, error) {
+	return c.getProjectConfig(projectRef, "storage")
+}
+
+// UpdateStorageConfig changes the given storage settings and leaves the rest.
+func (c *ManagementClient) UpdateStorageConfig(projectRef string, changes map[string]interface{}) error {
+	return c.updateProjectConfig(projectRef, "storage", changes)
+}
+
+func (c *ManagementClient) getProjectConfig(projectRef, service string) (map[string]interface{}, error) {
+	u := fmt.Sprintf("%s/v1/projects/%s/config/%s", ManagementAPIURL(), projectRef, service)
+	body, err := c.jsonRequest("GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s config: %w", service, err)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(body, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s config: %w", service, err)
+	}
+	return settings, nil
+}
+
+func (c *ManagementClient) updateProjectConfig(projectRef, service string, changes map[string]interface{}) error {
+	u := fmt.Sprintf("%s/v1/projects/%s/config/%s", ManagementAPIURL(), projectRef, service)
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	if _, err := c.jsonRequest("PATCH", u, payload); err != nil {
+		return fmt.Errorf("failed to update %s config: %w", service, err)
+	}
+	return nil
+}
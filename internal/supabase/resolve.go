@@ -0,0 +1,104 @@
+package supabase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoBranchMatch is returned by ResolveTarget when neither the target
+// branch nor a fallback branch matches a Supabase branch.
+var ErrNoBranchMatch = errors.New("no matching Supabase branch")
+
+// TargetOptions controls how a git branch maps to a Supabase branch.
+type TargetOptions struct {
+	GitBranch             string
+	OverrideBranch        string // takes priority over GitBranch (supabase.override_branch)
+	FallbackBranch        string // used when nothing matches (supabase.fallback_branch)
+	DisallowProdSelection bool   // refuse production when selected via override or fallback
+}
+
+// ResolveTarget resolves the Supabase branch for a git branch: an exact
+// match on the override (or git) branch first, then the fallback branch.
+// It never prompts; when nothing matches it returns an error wrapping
+// ErrNoBranchMatch so callers can offer their own fallback.
+func (c *Client) ResolveTarget(opts TargetOptions) (*BranchInfo, error) {
+	targetBranch := opts.GitBranch
+	base := &BranchInfo{GitBranch: opts.GitBranch}
+	if opts.OverrideBranch != "" {
+		targetBranch = opts.OverrideBranch
+		base.IsOverride = true
+		base.OverrideFrom = opts.GitBranch
+	}
+
+	branch, env, err := c.ResolveBranch(targetBranch)
+	if err != nil {
+		return nil, err
+	}
+	if branch != nil {
+		if opts.DisallowProdSelection && IsProductionBranch(branch) && targetBranch != opts.GitBranch {
+			return nil, fmt.Errorf("refusing to target production branch '%s' via override; remove override or use a non-production branch", targetBranch)
+		}
+		return c.NewBranchInfo(base, branch, env, false), nil
+	}
+
+	fallback := strings.TrimSpace(opts.FallbackBranch)
+	if fallback == "" {
+		return nil, fmt.Errorf("%w for '%s'", ErrNoBranchMatch, targetBranch)
+	}
+	fallbackBranch, fallbackEnv, err := c.ResolveBranch(fallback)
+	if err != nil {
+		return nil, err
+	}
+	if fallbackBranch == nil {
+		return nil, fmt.Errorf("fallback branch '%s' was not found", fallback)
+	}
+	if opts.DisallowProdSelection && IsProductionBranch(fallbackBranch) {
+		return nil, fmt.Errorf("refusing to use production branch '%s' as fallback target", fallback)
+	}
+	return c.NewBranchInfo(base, fallbackBranch, fallbackEnv, true), nil
+}
+
+// NewBranchInfo builds the resolved target for branch, carrying the git
+// branch and override details from base.
+func (c *Client) NewBranchInfo(base *BranchInfo, branch *Branch, env Environment, isFallback bool) *BranchInfo {
+	info := &BranchInfo{
+		GitBranch:      base.GitBranch,
+		SupabaseBranch: branch,
+		Environment:    env,
+		ProjectRef:     branch.ProjectRef,
+		APIURL:         c.GetBranchURL(branch.ProjectRef),
+		IsFallback:     isFallback,
+		IsOverride:     base.IsOverride,
+		OverrideFrom:   base.OverrideFrom,
+	}
+
+	if project, err := c.FindProjectByRef(branch.ProjectRef); err == nil && project != nil {
+		info.Region = project.Region
+	}
+
+	return info
+}
+
+// IsProductionBranch reports whether branch is the default branch or named
+// like a production branch.
+func IsProductionBranch(branch *Branch) bool {
+	if branch == nil {
+		return false
+	}
+	if branch.IsDefault {
+		return true
+	}
+	return IsProtectedBranchName(branch.Name) || IsProtectedBranchName(branch.GitBranch)
+}
+
+// IsProtectedBranchName reports whether name is a conventional production
+// branch name (main, master, production, prod).
+func IsProtectedBranchName(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "main", "master", "production", "prod":
+		return true
+	default:
+		return false
+	}
+}
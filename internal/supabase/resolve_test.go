@@ -0,0 +1,59 @@
+package supabase
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveTarget(t *testing.T) {
+	resetCache(t, 0)
+	SetOffline(true)
+	t.Cleanup(func() { SetOffline(false) })
+
+	writeCache("branches-linked", []Branch{
+		{Name: "main", GitBranch: "main", ProjectRef: "prod", IsDefault: true},
+		{Name: "development", GitBranch: "development", ProjectRef: "dev", Persistent: true},
+		{Name: "feature-x", GitBranch: "feature/x", ProjectRef: "fx"},
+	})
+	client := NewClient()
+
+	tests := []struct {
+		name         string
+		opts         TargetOptions
+		wantRef      string
+		wantEnv      Environment
+		wantFallback bool
+		wantOverride bool
+		wantErr      error
+	}{
+		{name: "exact match", opts: TargetOptions{GitBranch: "feature/x"}, wantRef: "fx", wantEnv: EnvFeature},
+		{name: "production", opts: TargetOptions{GitBranch: "main"}, wantRef: "prod", wantEnv: EnvProduction},
+		{name: "fallback", opts: TargetOptions{GitBranch: "feature/y", FallbackBranch: "development"}, wantRef: "dev", wantEnv: EnvDevelopment, wantFallback: true},
+		{name: "override", opts: TargetOptions{GitBranch: "feature/y", OverrideBranch: "feature/x"}, wantRef: "fx", wantEnv: EnvFeature, wantOverride: true},
+		{name: "no match", opts: TargetOptions{GitBranch: "feature/y"}, wantErr: ErrNoBranchMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := client.ResolveTarget(tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ResolveTarget() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveTarget() error = %v", err)
+			}
+			if info.ProjectRef != tt.wantRef || info.Environment != tt.wantEnv {
+				t.Errorf("ResolveTarget() = %s (%s), want %s (%s)", info.ProjectRef, info.Environment, tt.wantRef, tt.wantEnv)
+			}
+			if info.IsFallback != tt.wantFallback || info.IsOverride != tt.wantOverride {
+				t.Errorf("IsFallback = %v, IsOverride = %v; want %v, %v", info.IsFallback, info.IsOverride, tt.wantFallback, tt.wantOverride)
+			}
+		})
+	}
+
+	if _, err := client.ResolveTarget(TargetOptions{GitBranch: "feature/y", FallbackBranch: "main", DisallowProdSelection: true}); err == nil {
+		t.Error("ResolveTarget() allowed production as fallback with DisallowProdSelection")
+	}
+}
@@ -0,0 +1,215 @@
+package supabase
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// DefaultRetries is how many times a transient failure is retried when
+// supabase.retries is not set.
+const DefaultRetries = 3
+
+var (
+	retryMu     sync.Mutex
+	maxRetries  = DefaultRetries
+	retryBase   = 500 * time.Millisecond
+	retryMaxGap = 10 * time.Second
+	retrySleep  = time.Sleep
+)
+
+// ConfigureRetries sets how many times transient CLI and API failures are
+// retried. Zero disables retries; negative values keep the default.
+func ConfigureRetries(n int) {
+	retryMu.Lock()
+	defer retryMu.Unlock()
+	if n < 0 {
+		n = DefaultRetries
+	}
+	maxRetries = n
+}
+
+func retryLimit() int {
+	retryMu.Lock()
+	defer retryMu.Unlock()
+	return maxRetries
+}
+
+// retryDelay returns the wait before retry attempt (0-based): exponential
+// backoff capped at retryMaxGap, with up to half of it added as jitter so
+// parallel deploys don't retry in lockstep.
+func retryDelay(attempt int) time.Duration {
+	d := retryBase << uint(attempt)
+	if d <= 0 || d > retryMaxGap {
+		d = retryMaxGap
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// transientMarkers are substrings of CLI output that indicate a failure
+// worth retrying.
+var transientMarkers = []string{
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"i/o timeout",
+	"timeout awaiting",
+	"tls handshake timeout",
+	"no such host",
+	"unexpected eof",
+	"too many requests",
+	"rate limit",
+	"429",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"status 502",
+	"status 503",
+	"status 504",
+}
+
+// isTransientOutput reports whether CLI output describes a failure that is
+// likely to go away on its own.
+func isTransientOutput(output string) bool {
+	output = strings.ToLower(output)
+	for _, marker := range transientMarkers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// runCLI runs the supabase CLI, retrying with backoff while it fails with
+// output that looks transient. Use it for calls that are safe to repeat.
+func runCLI(args ...string) (*shell.Result, error) {
+	return runCLIInDir("", args...)
+}
+
+// runCLIInDir is runCLI in dir.
+func runCLIInDir(dir string, args ...string) (*shell.Result, error) {
+	limit := retryLimit()
+	for attempt := 0; ; attempt++ {
+		result, err := shell.RunInDir(dir, "supabase", args...)
+		if err != nil || result.ExitCode == 0 || attempt >= limit {
+			return result, err
+		}
+		if !isTransientOutput(result.Stderr + "\n" + result.Stdout) {
+			return result, err
+		}
+		delay := retryDelay(attempt)
+		shell.VerboseLog("supabase %s failed transiently, retrying in %s", strings.Join(args, " "), delay.Round(time.Millisecond))
+		retrySleep(delay)
+	}
+}
+
+type retryContextKey int
+
+const (
+	idempotentKey retryContextKey = iota
+	attemptsKey
+)
+
+// idempotent marks req as safe to send again after an ambiguous failure,
+// such as a POST that upserts.
+func idempotent(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), idempotentKey, true))
+}
+
+// countAttempts has retryTransport add every attempt it makes for req to n.
+func countAttempts(req *http.Request, n *int) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), attemptsKey, n))
+}
+
+// retryTransport retries requests that failed with a network error or a
+// 429/502/503/504 response. Only idempotent methods, and requests marked
+// with idempotent, are retried after an ambiguous failure; other POSTs are
+// retried on a 429 alone, which the server sends before doing any work.
+// Requests with a body are only retried when the body can be replayed.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limit := retryLimit()
+	attempts, _ := req.Context().Value(attemptsKey).(*int)
+	for attempt := 0; ; attempt++ {
+		if attempts != nil {
+			*attempts++
+		}
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= limit || !retryableResponse(resp, err) || !replayable(req, resp) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		delay := retryDelay(attempt)
+		if resp != nil {
+			if after := retryAfter(resp.Header.Get("Retry-After")); after > 0 && after <= retryMaxGap {
+				delay = after
+			}
+			resp.Body.Close()
+		}
+		shell.VerboseLog("%s %s failed transiently, retrying in %s", req.Method, req.URL.Path, delay.Round(time.Millisecond))
+
+		if req.GetBody != nil {
+			body, berr := req.GetBody()
+			if berr != nil {
+				return nil, berr
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		default:
+		}
+		retrySleep(delay)
+	}
+}
+
+// replayable reports whether req may be sent again after resp (nil for a
+// network error).
+func replayable(req *http.Request, resp *http.Response) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	if marked, _ := req.Context().Value(idempotentKey).(bool); marked {
+		return true
+	}
+	return resp != nil && resp.StatusCode == http.StatusTooManyRequests
+}
+
+func retryableResponse(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) || isTransientOutput(err.Error())
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(header string) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
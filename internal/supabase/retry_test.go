@@ -0,0 +1,124 @@
+package supabase
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func withRetries(t *testing.T, n int) {
+	t.Helper()
+	ConfigureRetries(n)
+	retrySleep = func(time.Duration) {}
+	t.Cleanup(func() {
+		ConfigureRetries(DefaultRetries)
+		retrySleep = time.Sleep
+	})
+}
+
+func TestIsTransientOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"read tcp 10.0.0.1:443: connection reset by peer", true},
+		{"Unexpected error retrieving branches: 429 Too Many Requests", true},
+		{"unexpected status 503: 503 Service Unavailable", true},
+		{"net/http: TLS handshake timeout", true},
+		{"Branch not found", false},
+		{"Invalid access token format", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isTransientOutput(tt.output); got != tt.want {
+			t.Errorf("isTransientOutput(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		d := retryDelay(attempt)
+		base := retryBase << uint(attempt)
+		if base > retryMaxGap {
+			base = retryMaxGap
+		}
+		if d < base || d > base+base/2 {
+			t.Errorf("retryDelay(%d) = %s, want between %s and %s", attempt, d, base, base+base/2)
+		}
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		idempotent bool
+		retries    int
+		failures   int
+		status     int
+		wantCalls  int32
+		wantCode   int
+	}{
+		{"recovers after 429", "PUT", false, 3, 2, http.StatusTooManyRequests, 3, http.StatusOK},
+		{"recovers after 503", "PUT", false, 3, 1, http.StatusServiceUnavailable, 2, http.StatusOK},
+		{"gives up after limit", "PUT", false, 2, 5, http.StatusBadGateway, 3, http.StatusBadGateway},
+		{"disabled", "PUT", false, 0, 1, http.StatusServiceUnavailable, 1, http.StatusServiceUnavailable},
+		{"client errors are not retried", "PUT", false, 3, 1, http.StatusNotFound, 1, http.StatusNotFound},
+		{"POST retried on 429", "POST", false, 3, 1, http.StatusTooManyRequests, 2, http.StatusOK},
+		{"POST not retried on 502", "POST", false, 3, 1, http.StatusBadGateway, 1, http.StatusBadGateway},
+		{"POST not retried on 504", "POST", false, 3, 1, http.StatusGatewayTimeout, 1, http.StatusGatewayTimeout},
+		{"idempotent POST retried on 503", "POST", true, 3, 1, http.StatusServiceUnavailable, 2, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRetries(t, tt.retries)
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				body, err := io.ReadAll(r.Body)
+				if err != nil || string(body) != `{"name":"x"}` {
+					t.Errorf("attempt %d got body %q", n, body)
+				}
+				if int(n) <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: retryTransport{base: http.DefaultTransport}}
+			req, _ := http.NewRequest(tt.method, server.URL, strings.NewReader(`{"name":"x"}`))
+			if tt.idempotent {
+				req = idempotent(req)
+			}
+			attempts := 0
+			req = countAttempts(req, &attempts)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantCode || calls != tt.wantCalls {
+				t.Errorf("got status %d after %d calls, want %d after %d", resp.StatusCode, calls, tt.wantCode, tt.wantCalls)
+			}
+			if int32(attempts) != calls {
+				t.Errorf("counted %d attempts, server saw %d", attempts, calls)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if got := retryAfter("2"); got != 2*time.Second {
+		t.Errorf("retryAfter(2) = %s", got)
+	}
+	if got := retryAfter("Wed, 21 Oct 2015 07:28:00 GMT"); got != 0 {
+		t.Errorf("retryAfter(date) = %s, want 0", got)
+	}
+}
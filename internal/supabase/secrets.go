@@ -6,8 +6,6 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
-
-	"github.com/undrift/drift/pkg/shell"
 )
 
 // Secret represents a Supabase secret.
@@ -41,7 +39,7 @@ func (c *Client) setSecretViaCLI(projectRef, name, value string) error {
 		args = append(args, "--project-ref", projectRef)
 	}
 
-	result, err := shell.Run("supabase", args...)
+	result, err := runCLI(args...)
 	if err != nil {
 		errMsg := result.Stderr
 		if errMsg == "" {
@@ -62,25 +60,35 @@ func (c *Client) setSecretViaCLI(projectRef, name, value string) error {
 	return nil
 }
 
-// SetSecrets sets multiple secrets at once.
-// Uses the Management API for reliable secret setting on both main projects and branches.
+// SetSecrets sets multiple secrets at once. See ApplySecrets for how they
+// are batched; the error names every secret that could not be set.
 func (c *Client) SetSecrets(projectRef string, secrets []Secret) error {
+	return c.ApplySecrets(projectRef, secrets).Err()
+}
+
+// ApplySecrets sets multiple secrets and reports the outcome of each.
+// Uses the Management API for reliable secret setting on both main projects
+// and branches, falling back to a single 'supabase secrets set' call.
+func (c *Client) ApplySecrets(projectRef string, secrets []Secret) *SecretsReport {
 	if len(secrets) == 0 {
-		return nil
+		return &SecretsReport{}
 	}
 
 	// Use Management API - more reliable for branches
 	mgmtClient, err := NewManagementClient()
-	if err != nil {
-		// Fall back to CLI if Management API is not available
-		return c.setSecretsViaCLI(projectRef, secrets)
+	if err == nil {
+		return mgmtClient.ApplySecrets(projectRef, secrets)
 	}
 
-	if err := mgmtClient.SetSecrets(projectRef, secrets); err != nil {
-		return fmt.Errorf("failed to set secrets: %w", err)
+	// Fall back to CLI if Management API is not available
+	secrets = dedupeSecrets(secrets)
+	err = c.setSecretsViaCLI(projectRef, secrets)
+	report := &SecretsReport{Calls: 1}
+	for _, s := range secrets {
+		report.Results = append(report.Results, secretResult(s.Name, SecretSet, err))
 	}
-
-	return nil
+	sort.Slice(report.Results, func(i, j int) bool { return report.Results[i].Name < report.Results[j].Name })
+	return report
 }
 
 // setSecretsViaCLI sets multiple secrets using the Supabase CLI (fallback).
@@ -94,7 +102,7 @@ func (c *Client) setSecretsViaCLI(projectRef string, secrets []Secret) error {
 		args = append(args, "--project-ref", projectRef)
 	}
 
-	result, err := shell.Run("supabase", args...)
+	result, err := runCLI(args...)
 	if err != nil {
 		errMsg := result.Stderr
 		if errMsg == "" {
@@ -146,7 +154,7 @@ func (c *Client) listSecretsViaCLI(projectRef string) ([]string, error) {
 		args = append(args, "--project-ref", projectRef)
 	}
 
-	result, err := shell.Run("supabase", args...)
+	result, err := runCLI(args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list secrets: %w", err)
 	}
@@ -183,7 +191,7 @@ func (c *Client) UnsetSecret(projectRef, name string) error {
 		args = append(args, "--project-ref", projectRef)
 	}
 
-	result, err := shell.Run("supabase", args...)
+	result, err := runCLI(args...)
 	if err != nil {
 		errMsg := result.Stderr
 		if errMsg == "" {
@@ -0,0 +1,204 @@
+package supabase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SecretsBatchSize is the most secrets sent in one API call.
+const SecretsBatchSize = 25
+
+// SecretStatus is the outcome of setting one secret.
+type SecretStatus string
+
+// Secret outcomes.
+const (
+	SecretCreated   SecretStatus = "created"
+	SecretUpdated   SecretStatus = "updated"
+	SecretUnchanged SecretStatus = "unchanged"
+	// SecretSet means the secret was written but its previous state is
+	// unknown (remote values could not be read).
+	SecretSet    SecretStatus = "set"
+	SecretFailed SecretStatus = "failed"
+)
+
+// SecretResult is the outcome for one secret.
+type SecretResult struct {
+	Name   string
+	Status SecretStatus
+	Err    error
+}
+
+// SecretsReport lists the outcome of every secret in a batch, by name.
+type SecretsReport struct {
+	Results []SecretResult
+	// Calls is the number of write requests made, including retries.
+	Calls int
+}
+
+// Count returns how many secrets ended with status.
+func (r *SecretsReport) Count(status SecretStatus) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the secrets that could not be set.
+func (r *SecretsReport) Failed() []SecretResult {
+	var failed []SecretResult
+	for _, res := range r.Results {
+		if res.Status == SecretFailed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Summary formats the counts, e.g. "2 created, 1 updated, 37 unchanged".
+func (r *SecretsReport) Summary() string {
+	var parts []string
+	for _, status := range []SecretStatus{SecretCreated, SecretUpdated, SecretSet, SecretUnchanged, SecretFailed} {
+		if n := r.Count(status); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing to set"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Err returns an error naming the failed secrets, or nil.
+func (r *SecretsReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(failed))
+	for i, f := range failed {
+		msgs[i] = fmt.Sprintf("%s: %v", f.Name, f.Err)
+	}
+	return fmt.Errorf("failed to set %d of %d secret(s): %s", len(failed), len(r.Results), strings.Join(msgs, "; "))
+}
+
+// SecretValueMatches reports whether a remote secret value, a SHA-256
+// digest or the plain value, matches value.
+func SecretValueMatches(remote, value string) bool {
+	sum := sha256.Sum256([]byte(value))
+	return strings.EqualFold(remote, hex.EncodeToString(sum[:])) || remote == value
+}
+
+// APIError is a non-success response from the Management API.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// isTransient reports whether err is a network error, rate limit, or server
+// error rather than the API rejecting the request.
+func isTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return err != nil
+}
+
+// ApplySecrets sets secrets on projectRef in as few calls as possible.
+// Secrets whose remote value already matches are skipped, the rest are sent
+// in chunks of SecretsBatchSize, and transient failures are retried by the
+// client's transport. A chunk the API rejects is retried one secret at a time so only the bad secrets
+// fail.
+func (c *ManagementClient) ApplySecrets(projectRef string, secrets []Secret) *SecretsReport {
+	secrets = dedupeSecrets(secrets)
+	report := &SecretsReport{}
+
+	remote, remoteErr := c.GetSecrets(projectRef)
+	existing := make(map[string]string, len(remote))
+	for _, s := range remote {
+		existing[s.Name] = s.Value
+	}
+
+	statusFor := make(map[string]SecretStatus, len(secrets))
+	var pending []Secret
+	for _, s := range secrets {
+		current, exists := existing[s.Name]
+		switch {
+		case remoteErr != nil:
+			statusFor[s.Name] = SecretSet
+		case !exists:
+			statusFor[s.Name] = SecretCreated
+		case SecretValueMatches(current, s.Value):
+			report.Results = append(report.Results, SecretResult{Name: s.Name, Status: SecretUnchanged})
+			continue
+		default:
+			statusFor[s.Name] = SecretUpdated
+		}
+		pending = append(pending, s)
+	}
+
+	for start := 0; start < len(pending); start += SecretsBatchSize {
+		end := start + SecretsBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		chunk := pending[start:end]
+
+		err := c.setSecretsCounted(projectRef, chunk, report)
+		if err != nil && len(chunk) > 1 && !isTransient(err) {
+			// One invalid secret fails the whole request; isolate it.
+			for _, s := range chunk {
+				err := c.setSecretsCounted(projectRef, []Secret{s}, report)
+				report.Results = append(report.Results, secretResult(s.Name, statusFor[s.Name], err))
+			}
+			continue
+		}
+		for _, s := range chunk {
+			report.Results = append(report.Results, secretResult(s.Name, statusFor[s.Name], err))
+		}
+	}
+
+	sort.Slice(report.Results, func(i, j int) bool { return report.Results[i].Name < report.Results[j].Name })
+	return report
+}
+
+func (c *ManagementClient) setSecretsCounted(projectRef string, secrets []Secret, report *SecretsReport) error {
+	calls, err := c.setSecrets(projectRef, secrets)
+	report.Calls += calls
+	return err
+}
+
+func secretResult(name string, status SecretStatus, err error) SecretResult {
+	if err != nil {
+		return SecretResult{Name: name, Status: SecretFailed, Err: err}
+	}
+	return SecretResult{Name: name, Status: status}
+}
+
+// dedupeSecrets keeps the last value given for each name.
+func dedupeSecrets(secrets []Secret) []Secret {
+	index := make(map[string]int, len(secrets))
+	var out []Secret
+	for _, s := range secrets {
+		if i, ok := index[s.Name]; ok {
+			out[i] = s
+			continue
+		}
+		index[s.Name] = len(out)
+		out = append(out, s)
+	}
+	return out
+}
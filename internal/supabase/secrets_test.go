@@ -0,0 +1,167 @@
+package supabase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSecretValueMatches(t *testing.T) {
+	sum := sha256.Sum256([]byte("s3cret"))
+	digest := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name   string
+		remote string
+		value  string
+		want   bool
+	}{
+		{"digest", digest, "s3cret", true},
+		{"uppercase digest", strings.ToUpper(digest), "s3cret", true},
+		{"plain value", "s3cret", "s3cret", true},
+		{"different", digest, "other", false},
+		{"empty remote", "", "s3cret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SecretValueMatches(tt.remote, tt.value); got != tt.want {
+				t.Errorf("SecretValueMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeSecretsAPI serves GET/POST /v1/projects/ref/secrets. POSTs containing
+// a secret named in reject fail with 400; the first failTransient POSTs
+// fail with 503.
+type fakeSecretsAPI struct {
+	mu            sync.Mutex
+	remote        map[string]string
+	reject        map[string]bool
+	failTransient int
+	posts         [][]Secret
+}
+
+func (f *fakeSecretsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == http.MethodGet {
+		var list []Secret
+		for name, value := range f.remote {
+			sum := sha256.Sum256([]byte(value))
+			list = append(list, Secret{Name: name, Value: hex.EncodeToString(sum[:])})
+		}
+		json.NewEncoder(w).Encode(list)
+		return
+	}
+
+	var batch []Secret
+	json.NewDecoder(r.Body).Decode(&batch)
+	f.posts = append(f.posts, batch)
+	if f.failTransient > 0 {
+		f.failTransient--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	for _, s := range batch {
+		if f.reject[s.Name] {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid secret name"))
+			return
+		}
+	}
+	for _, s := range batch {
+		f.remote[s.Name] = s.Value
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func newFakeSecretsClient(t *testing.T, api *fakeSecretsAPI) *ManagementClient {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	ConfigureEndpoints(Endpoints{ManagementURL: server.URL})
+	t.Cleanup(func() { ConfigureEndpoints(Endpoints{}) })
+
+	withRetries(t, 2)
+
+	return &ManagementClient{accessToken: "test", httpClient: &http.Client{Transport: retryTransport{base: server.Client().Transport}}}
+}
+
+func TestManagementClient_ApplySecrets_BatchesChangesOnly(t *testing.T) {
+	api := &fakeSecretsAPI{remote: map[string]string{"KEEP": "same", "ROTATE": "old"}}
+	client := newFakeSecretsClient(t, api)
+
+	secrets := []Secret{{Name: "KEEP", Value: "same"}, {Name: "ROTATE", Value: "new"}}
+	for i := 0; i < SecretsBatchSize+5; i++ {
+		secrets = append(secrets, Secret{Name: "NEW_" + strings.Repeat("X", i+1), Value: "v"})
+	}
+
+	report := client.ApplySecrets("ref", secrets)
+	if err := report.Err(); err != nil {
+		t.Fatalf("ApplySecrets() error = %v", err)
+	}
+	if got := report.Summary(); got != "30 created, 1 updated, 1 unchanged" {
+		t.Errorf("Summary() = %q", got)
+	}
+	if len(api.posts) != 2 || report.Calls != 2 {
+		t.Errorf("posts = %d, calls = %d; want 2 chunks", len(api.posts), report.Calls)
+	}
+	for _, post := range api.posts {
+		for _, s := range post {
+			if s.Name == "KEEP" {
+				t.Error("unchanged secret should not be sent")
+			}
+		}
+	}
+}
+
+func TestManagementClient_ApplySecrets_RetriesAndIsolatesFailures(t *testing.T) {
+	api := &fakeSecretsAPI{
+		remote:        map[string]string{},
+		reject:        map[string]bool{"BAD-NAME": true},
+		failTransient: 1,
+	}
+	client := newFakeSecretsClient(t, api)
+
+	report := client.ApplySecrets("ref", []Secret{
+		{Name: "A", Value: "1"},
+		{Name: "BAD-NAME", Value: "2"},
+		{Name: "C", Value: "3"},
+	})
+
+	if got := report.Summary(); got != "2 created, 1 failed" {
+		t.Errorf("Summary() = %q", got)
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Name != "BAD-NAME" || !strings.Contains(failed[0].Err.Error(), "status 400") {
+		t.Errorf("Failed() = %+v", failed)
+	}
+	// 503 retried, 400 on the chunk, then one call per secret.
+	if report.Calls != 5 {
+		t.Errorf("Calls = %d, want 5", report.Calls)
+	}
+	if api.remote["A"] != "1" || api.remote["C"] != "3" {
+		t.Errorf("remote = %v, want A and C set", api.remote)
+	}
+}
+
+func TestManagementClient_ApplySecrets_CallsMatchRequests(t *testing.T) {
+	api := &fakeSecretsAPI{remote: map[string]string{}, failTransient: 10}
+	client := newFakeSecretsClient(t, api)
+
+	report := client.ApplySecrets("ref", []Secret{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}})
+	if report.Err() == nil {
+		t.Fatal("ApplySecrets() should fail while the API keeps returning 503")
+	}
+	// One request plus two transport retries; no second retry loop on top.
+	if len(api.posts) != 3 || report.Calls != 3 {
+		t.Errorf("posts = %d, calls = %d; want 3 each", len(api.posts), report.Calls)
+	}
+}
@@ -0,0 +1,107 @@
+package supabase
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FunctionEntry locates one function for a staged workdir.
+type FunctionEntry struct {
+	Name       string
+	Entrypoint string // absolute path to index.ts
+	ImportMap  string // absolute path, optional
+}
+
+var functionTableHeader = regexp.MustCompile(`^\s*\[\s*functions\.(?:"([^"]+)"|([A-Za-z0-9_-]+))\s*\]`)
+
+// StageWorkdir builds a Supabase CLI workdir in stageDir whose config.toml is
+// the project's config.toml (if any) with an entrypoint, and import map, for
+// every function in entries. Passing it as --workdir lets the CLI deploy and
+// serve functions that live outside supabase/functions.
+func StageWorkdir(projectConfig, stageDir string, entries []FunctionEntry) error {
+	src, err := os.ReadFile(projectConfig)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", projectConfig, err)
+	}
+
+	supabaseDir := filepath.Join(stageDir, "supabase")
+	if err := os.MkdirAll(supabaseDir, 0755); err != nil {
+		return err
+	}
+	config, err := rewriteFunctionsConfig(string(src), entries, supabaseDir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(supabaseDir, "config.toml"), []byte(config), 0644)
+}
+
+// rewriteFunctionsConfig sets entrypoint and import_map in each entry's
+// [functions.<name>] table, adding tables that do not exist yet. Other keys,
+// such as verify_jwt, are kept. Paths are written relative to supabaseDir,
+// which is how the CLI resolves them.
+func rewriteFunctionsConfig(src string, entries []FunctionEntry, supabaseDir string) (string, error) {
+	byName := make(map[string]FunctionEntry, len(entries))
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+
+	var out []string
+	written := make(map[string]bool)
+	inEntry := false
+	for _, line := range strings.Split(strings.TrimRight(src, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inEntry = false
+			if m := functionTableHeader.FindStringSubmatch(line); m != nil {
+				name := m[1] + m[2]
+				if e, ok := byName[name]; ok {
+					keys, err := functionEntryKeys(e, supabaseDir)
+					if err != nil {
+						return "", err
+					}
+					out = append(out, line)
+					out = append(out, keys...)
+					written[name] = true
+					inEntry = true
+					continue
+				}
+			}
+		}
+		if inEntry && (strings.HasPrefix(trimmed, "entrypoint") || strings.HasPrefix(trimmed, "import_map")) {
+			continue
+		}
+		out = append(out, line)
+	}
+
+	for _, e := range entries {
+		if written[e.Name] {
+			continue
+		}
+		keys, err := functionEntryKeys(e, supabaseDir)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, "", fmt.Sprintf("[functions.%q]", e.Name))
+		out = append(out, keys...)
+	}
+	return strings.TrimLeft(strings.Join(out, "\n"), "\n") + "\n", nil
+}
+
+func functionEntryKeys(e FunctionEntry, supabaseDir string) ([]string, error) {
+	entrypoint, err := filepath.Rel(supabaseDir, e.Entrypoint)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{fmt.Sprintf("entrypoint = %q", filepath.ToSlash(entrypoint))}
+	if e.ImportMap != "" {
+		importMap, err := filepath.Rel(supabaseDir, e.ImportMap)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fmt.Sprintf("import_map = %q", filepath.ToSlash(importMap)))
+	}
+	return keys, nil
+}
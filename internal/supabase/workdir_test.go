@@ -0,0 +1,51 @@
+package supabase
+
+import "testing"
+
+func TestRewriteFunctionsConfig(t *testing.T) {
+	src := `project_id = "app"
+
+[functions.stripe-webhook]
+verify_jwt = false
+entrypoint = "./functions/stripe-webhook/index.ts"
+
+[db]
+port = 54322
+`
+	entries := []FunctionEntry{
+		{Name: "billing", Entrypoint: "/repo/packages/edge/billing/index.ts", ImportMap: "/repo/.drift/functions/import_map.json"},
+		{Name: "stripe-webhook", Entrypoint: "/repo/supabase/functions/stripe-webhook/index.ts"},
+	}
+
+	got, err := rewriteFunctionsConfig(src, entries, "/repo/.drift/functions/workdir/supabase")
+	if err != nil {
+		t.Fatalf("rewriteFunctionsConfig() error = %v", err)
+	}
+	want := `project_id = "app"
+
+[functions.stripe-webhook]
+entrypoint = "../../../../supabase/functions/stripe-webhook/index.ts"
+verify_jwt = false
+
+[db]
+port = 54322
+
+[functions."billing"]
+entrypoint = "../../../../packages/edge/billing/index.ts"
+import_map = "../../import_map.json"
+`
+	if got != want {
+		t.Errorf("rewriteFunctionsConfig() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRewriteFunctionsConfig_NoProjectConfig(t *testing.T) {
+	got, err := rewriteFunctionsConfig("", []FunctionEntry{{Name: "hello", Entrypoint: "/repo/edge/hello/index.ts"}}, "/repo/stage/supabase")
+	if err != nil {
+		t.Fatalf("rewriteFunctionsConfig() error = %v", err)
+	}
+	want := "[functions.\"hello\"]\nentrypoint = \"../../edge/hello/index.ts\"\n"
+	if got != want {
+		t.Errorf("rewriteFunctionsConfig() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+// Package testutil provides helpers for tests that exercise drift against
+// the external tools it shells out to: fake supabase, psql, and other
+// binaries placed on PATH, and golden-file comparisons.
+//
+// It is only imported from _test.go files.
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Response is what a fake binary does when it is called with arguments
+// starting with Args. An empty Args matches every call.
+type Response struct {
+	Args   string
+	Stdout string
+	Stderr string
+	Exit   int
+}
+
+// FakeBin is a fake executable on PATH that answers calls from a list of
+// canned responses and records the arguments of each call.
+type FakeBin struct {
+	t    *testing.T
+	name string
+	log  string
+}
+
+// NewFakeBin installs a fake executable called name on PATH for the rest of
+// the test. Each call is answered by the first response whose Args is a
+// prefix of the space-joined arguments; unmatched calls print nothing and
+// exit 0.
+func NewFakeBin(t *testing.T, name string, responses ...Response) *FakeBin {
+	t.Helper()
+	dir := t.TempDir()
+	f := &FakeBin{t: t, name: name, log: filepath.Join(dir, "calls")}
+
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	script.WriteString(`printf '%s\037' "$@" >> ` + shellQuote(f.log) + "\n")
+	script.WriteString(`printf '\n' >> ` + shellQuote(f.log) + "\n")
+	script.WriteString(`case "$*" in` + "\n")
+	for i, r := range responses {
+		stdout := filepath.Join(dir, "stdout-"+strconv.Itoa(i))
+		stderr := filepath.Join(dir, "stderr-"+strconv.Itoa(i))
+		writeFile(t, stdout, r.Stdout, 0644)
+		writeFile(t, stderr, r.Stderr, 0644)
+		fmt.Fprintf(&script, "  %s*)\n    cat %s\n    cat %s >&2\n    exit %d\n    ;;\n",
+			shellQuote(r.Args), shellQuote(stdout), shellQuote(stderr), r.Exit)
+	}
+	script.WriteString("esac\nexit 0\n")
+
+	writeFile(t, filepath.Join(dir, name), script.String(), 0755)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return f
+}
+
+// Calls returns the arguments of each call made so far, in order.
+func (f *FakeBin) Calls() [][]string {
+	f.t.Helper()
+	data, err := os.ReadFile(f.log)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		f.t.Fatalf("failed to read %s calls: %v", f.name, err)
+	}
+
+	var calls [][]string
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		args := strings.Split(line, "\037")
+		calls = append(calls, args[:len(args)-1])
+	}
+	return calls
+}
+
+// CallLines returns each call's arguments joined by spaces, which reads
+// better in test failures.
+func (f *FakeBin) CallLines() []string {
+	f.t.Helper()
+	var lines []string
+	for _, args := range f.Calls() {
+		lines = append(lines, strings.Join(args, " "))
+	}
+	return lines
+}
+
+func writeFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,58 @@
+package testutil
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFakeBin(t *testing.T) {
+	fake := NewFakeBin(t, "supabase",
+		Response{Args: "branches list", Stdout: `[{"name": "main"}]`},
+		Response{Args: "db push", Stderr: "it's broken", Exit: 3},
+	)
+
+	out, err := exec.Command("supabase", "branches", "list", "--output", "json").Output()
+	if err != nil {
+		t.Fatalf("branches list error = %v", err)
+	}
+	if string(out) != `[{"name": "main"}]` {
+		t.Errorf("branches list stdout = %q", out)
+	}
+
+	cmd := exec.Command("supabase", "db", "push")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 3 {
+		t.Errorf("db push error = %v, want exit 3", err)
+	}
+	if stderr.String() != "it's broken" {
+		t.Errorf("db push stderr = %q", stderr.String())
+	}
+
+	if out, err := exec.Command("supabase", "status").Output(); err != nil || len(out) != 0 {
+		t.Errorf("unmatched call = %q, %v; want no output", out, err)
+	}
+
+	want := [][]string{
+		{"branches", "list", "--output", "json"},
+		{"db", "push"},
+		{"status"},
+	}
+	if got := fake.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %q, want %q", got, want)
+	}
+}
+
+func TestFakeBin_ArgsWithSpaces(t *testing.T) {
+	fake := NewFakeBin(t, "psql")
+	if err := exec.Command("psql", "-c", "SELECT 'a b'").Run(); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"-c", "SELECT 'a b'"}}
+	if got := fake.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+package textdiff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Style colors rendered output. A nil function leaves text as is, so the
+// zero Style renders plain text.
+type Style struct {
+	Insert func(a ...interface{}) string
+	Delete func(a ...interface{}) string
+	Header func(a ...interface{}) string
+	Hunk   func(a ...interface{}) string
+}
+
+func apply(fn func(a ...interface{}) string, s string) string {
+	if fn == nil {
+		return s
+	}
+	return fn(s)
+}
+
+// Unified writes d in the unified format of diff -u.
+func Unified(w io.Writer, d *Diff, style Style) {
+	if d.Equal() {
+		return
+	}
+	fmt.Fprintln(w, apply(style.Header, "--- "+d.OldName))
+	fmt.Fprintln(w, apply(style.Header, "+++ "+d.NewName))
+	for _, h := range d.Hunks {
+		fmt.Fprintln(w, apply(style.Hunk, fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))))
+		for _, l := range h.Lines {
+			switch l.Op {
+			case Insert:
+				fmt.Fprintln(w, apply(style.Insert, "+"+l.Text))
+			case Delete:
+				fmt.Fprintln(w, apply(style.Delete, "-"+l.Text))
+			default:
+				fmt.Fprintln(w, " "+l.Text)
+			}
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// SideBySide writes d as two columns, old on the left and new on the
+// right, fitting within width characters. Deleted and inserted lines of the
+// same change are paired on a row.
+func SideBySide(w io.Writer, d *Diff, width int, style Style) {
+	if d.Equal() {
+		return
+	}
+	// Each side is a 5-char line number, a space, and the text; the
+	// separator column between them takes 3.
+	column := max((width-3)/2-6, 10)
+
+	fmt.Fprintf(w, "%s   %s\n",
+		apply(style.Header, pad(truncate(d.OldName, column+6), column+6)),
+		apply(style.Header, truncate(d.NewName, column+6)))
+	for i, h := range d.Hunks {
+		if i > 0 {
+			fmt.Fprintln(w, apply(style.Hunk, strings.Repeat("┈", column+6)+"   "+strings.Repeat("┈", column+6)))
+		}
+		for _, row := range pairRows(h.Lines) {
+			left := sideCell(row.old, lineNumber(row.old, true), column, style.Delete)
+			right := sideCell(row.new, lineNumber(row.new, false), column, style.Insert)
+			// The separator marks the change as diff -y does.
+			sep := "|"
+			switch {
+			case row.old == row.new:
+				sep = " "
+			case row.new == nil:
+				sep = "<"
+			case row.old == nil:
+				sep = ">"
+			}
+			fmt.Fprintf(w, "%s %s %s\n", left, sep, strings.TrimRight(right, " "))
+		}
+	}
+}
+
+func lineNumber(l *Line, old bool) int {
+	switch {
+	case l == nil:
+		return 0
+	case old:
+		return l.OldLine
+	default:
+		return l.NewLine
+	}
+}
+
+type sideRow struct {
+	old, new *Line
+}
+
+// pairRows lays out hunk lines as side-by-side rows: context lines on both
+// sides, and each change's deletions next to its insertions.
+func pairRows(lines []Line) []sideRow {
+	var rows []sideRow
+	for i := 0; i < len(lines); {
+		if lines[i].Op == Equal {
+			rows = append(rows, sideRow{old: &lines[i], new: &lines[i]})
+			i++
+			continue
+		}
+		var dels, ins []*Line
+		for ; i < len(lines) && lines[i].Op != Equal; i++ {
+			if lines[i].Op == Delete {
+				dels = append(dels, &lines[i])
+			} else {
+				ins = append(ins, &lines[i])
+			}
+		}
+		for j := 0; j < max(len(dels), len(ins)); j++ {
+			var row sideRow
+			if j < len(dels) {
+				row.old = dels[j]
+			}
+			if j < len(ins) {
+				row.new = ins[j]
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func sideCell(l *Line, number, column int, color func(a ...interface{}) string) string {
+	if l == nil {
+		return strings.Repeat(" ", column+6)
+	}
+	cell := fmt.Sprintf("%5d %s", number, pad(truncate(expandTabs(l.Text), column), column))
+	if l.Op == Equal {
+		return cell
+	}
+	return apply(color, cell)
+}
+
+// Stat writes a diffstat line for d, like diff --stat, with a bar scaled to
+// fit barWidth.
+func Stat(w io.Writer, d *Diff, barWidth int, style Style) {
+	total := d.Added + d.Removed
+	added, removed := d.Added, d.Removed
+	if total > barWidth && barWidth > 0 {
+		added = d.Added * barWidth / total
+		removed = d.Removed * barWidth / total
+		if d.Added > 0 && added == 0 {
+			added = 1
+		}
+		if d.Removed > 0 && removed == 0 {
+			removed = 1
+		}
+	}
+	fmt.Fprintf(w, " %s | %d %s%s\n", d.NewName, total,
+		apply(style.Insert, strings.Repeat("+", added)), apply(style.Delete, strings.Repeat("-", removed)))
+	fmt.Fprintf(w, " 1 file changed, %d insertion%s(+), %d deletion%s(-)\n",
+		d.Added, plural(d.Added), d.Removed, plural(d.Removed))
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,219 @@
+// Package textdiff computes line diffs between two texts and renders them
+// as unified, side-by-side, or stat views, so drift does not depend on an
+// external diff tool.
+package textdiff
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Op is what happens to a line between the old and new text.
+type Op int
+
+const (
+	// Equal lines are in both texts.
+	Equal Op = iota
+	// Delete lines are only in the old text.
+	Delete
+	// Insert lines are only in the new text.
+	Insert
+)
+
+var opNames = map[Op]string{Equal: "context", Delete: "delete", Insert: "insert"}
+
+// String returns the name used for op in JSON.
+func (op Op) String() string {
+	return opNames[op]
+}
+
+// MarshalJSON encodes op by name.
+func (op Op) MarshalJSON() ([]byte, error) {
+	return json.Marshal(op.String())
+}
+
+// Line is one line of a hunk. OldLine and NewLine are 1-based line numbers,
+// 0 for the side the line is not on.
+type Line struct {
+	Op      Op     `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+// Hunk is a run of changes with the unchanged lines around them.
+type Hunk struct {
+	OldStart int    `json:"old_start"`
+	OldLines int    `json:"old_lines"`
+	NewStart int    `json:"new_start"`
+	NewLines int    `json:"new_lines"`
+	Lines    []Line `json:"lines"`
+}
+
+// Diff is the difference between an old and a new text.
+type Diff struct {
+	OldName string `json:"old_name"`
+	NewName string `json:"new_name"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+	Hunks   []Hunk `json:"hunks"`
+}
+
+// Equal reports whether the texts have no differences.
+func (d *Diff) Equal() bool {
+	return len(d.Hunks) == 0
+}
+
+// Compute diffs oldText against newText line by line, keeping context
+// unchanged lines around each change.
+func Compute(oldName, oldText, newName, newText string, context int) *Diff {
+	d := &Diff{OldName: oldName, NewName: newName, Hunks: []Hunk{}}
+	lines := script(splitLines(oldText), splitLines(newText))
+	for _, l := range lines {
+		switch l.Op {
+		case Insert:
+			d.Added++
+		case Delete:
+			d.Removed++
+		}
+	}
+	d.Hunks = hunks(lines, max(context, 0))
+	return d
+}
+
+// splitLines splits text into lines without their line endings. A final
+// newline does not start another line.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	return lines
+}
+
+// script returns the shortest edit script turning a into b, using Myers'
+// O(ND) algorithm, as numbered lines.
+func script(a, b []string) []Line {
+	n, m := len(a), len(b)
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+search:
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back from the end, one edit per step of d.
+	var out []Line
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			out = append(out, Line{Op: Equal, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				out = append(out, Line{Op: Insert, Text: b[y-1], NewLine: y})
+			} else {
+				out = append(out, Line{Op: Delete, Text: a[x-1], OldLine: x})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// hunks groups an edit script into hunks with context lines around each
+// change. Changes closer than twice the context share a hunk.
+func hunks(lines []Line, context int) []Hunk {
+	var out []Hunk
+	i := 0
+	for i < len(lines) {
+		if lines[i].Op == Equal {
+			i++
+			continue
+		}
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].Op != Equal {
+				end = j
+				continue
+			}
+			if j-end > 2*context {
+				break
+			}
+		}
+		stop := min(end+context+1, len(lines))
+		out = append(out, newHunk(lines, start, stop))
+		i = stop
+	}
+	return out
+}
+
+// newHunk builds the hunk covering lines[start:stop], numbering it from the
+// lines before it.
+func newHunk(lines []Line, start, stop int) Hunk {
+	oldBefore, newBefore := 0, 0
+	for _, l := range lines[:start] {
+		if l.Op != Insert {
+			oldBefore++
+		}
+		if l.Op != Delete {
+			newBefore++
+		}
+	}
+	h := Hunk{Lines: append([]Line(nil), lines[start:stop]...)}
+	for _, l := range h.Lines {
+		if l.Op != Insert {
+			h.OldLines++
+		}
+		if l.Op != Delete {
+			h.NewLines++
+		}
+	}
+	// As in diff -u, an empty side starts at the line before the hunk.
+	h.OldStart, h.NewStart = oldBefore, newBefore
+	if h.OldLines > 0 {
+		h.OldStart++
+	}
+	if h.NewLines > 0 {
+		h.NewStart++
+	}
+	return h
+}
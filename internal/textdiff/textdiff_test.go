@@ -0,0 +1,146 @@
+package textdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const oldText = `import { serve } from "std/server.ts"
+
+serve(async (req) => {
+  const body = await req.json()
+  console.log(body)
+  return new Response("ok")
+})
+`
+
+const newText = `import { serve } from "std/server.ts"
+
+serve(async (req) => {
+  const body = await req.json()
+  if (!body.email) {
+    return new Response("missing email", { status: 400 })
+  }
+  return new Response("ok")
+})
+`
+
+func TestUnified(t *testing.T) {
+	d := Compute("deployed/index.ts", oldText, "local/index.ts", newText, 3)
+	if d.Added != 3 || d.Removed != 1 {
+		t.Errorf("Added, Removed = %d, %d, want 3, 1", d.Added, d.Removed)
+	}
+
+	var buf bytes.Buffer
+	Unified(&buf, d, Style{})
+	want := `--- deployed/index.ts
++++ local/index.ts
+@@ -2,6 +2,8 @@
+` + " \n" + ` serve(async (req) => {
+   const body = await req.json()
+-  console.log(body)
++  if (!body.email) {
++    return new Response("missing email", { status: 400 })
++  }
+   return new Response("ok")
+ })
+`
+	if buf.String() != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestComputeHunks(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 1; i <= 30; i++ {
+		line := strings.Repeat("x", i)
+		oldLines = append(oldLines, line)
+		switch i {
+		case 5, 25:
+			newLines = append(newLines, line+" changed")
+		default:
+			newLines = append(newLines, line)
+		}
+	}
+	d := Compute("a", strings.Join(oldLines, "\n"), "b", strings.Join(newLines, "\n"), 3)
+	if len(d.Hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(d.Hunks))
+	}
+	if h := d.Hunks[1]; h.OldStart != 22 || h.OldLines != 7 || h.NewStart != 22 || h.NewLines != 7 {
+		t.Errorf("second hunk = -%d,%d +%d,%d, want -22,7 +22,7", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+	}
+
+	// Changes within twice the context share a hunk.
+	if d := Compute("a", strings.Join(oldLines, "\n"), "b", strings.Join(newLines, "\n"), 10); len(d.Hunks) != 1 {
+		t.Errorf("got %d hunks with context 10, want 1", len(d.Hunks))
+	}
+}
+
+func TestComputeEdges(t *testing.T) {
+	if d := Compute("a", oldText, "b", oldText, 3); !d.Equal() || d.Added != 0 || d.Removed != 0 {
+		t.Errorf("identical texts: %+v", d)
+	}
+	if d := Compute("a", oldText, "b", strings.ReplaceAll(oldText, "\n", "\r\n"), 3); !d.Equal() {
+		t.Error("line endings should not count as changes")
+	}
+
+	d := Compute("a", "", "b", "one\ntwo\n", 3)
+	var buf bytes.Buffer
+	Unified(&buf, d, Style{})
+	if !strings.Contains(buf.String(), "@@ -0,0 +1,2 @@") {
+		t.Errorf("new file hunk header:\n%s", buf.String())
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	d := Compute("deployed", oldText, "local", newText, 1)
+	var buf bytes.Buffer
+	SideBySide(&buf, d, 80, Style{})
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+
+	var changed, added int
+	for _, line := range lines[1:] {
+		switch {
+		case strings.Contains(line, " | "):
+			changed++
+			if !strings.Contains(line, "console.log(body)") || !strings.Contains(line, "if (!body.email) {") {
+				t.Errorf("changed row does not pair the lines: %q", line)
+			}
+		case strings.Contains(line, " > "):
+			added++
+		}
+	}
+	if changed != 1 || added != 2 {
+		t.Errorf("changed, added rows = %d, %d, want 1, 2:\n%s", changed, added, buf.String())
+	}
+	for _, line := range lines {
+		if n := len([]rune(line)); n > 80 {
+			t.Errorf("row is %d characters wide: %q", n, line)
+		}
+	}
+}
+
+func TestStat(t *testing.T) {
+	d := Compute("deployed/index.ts", oldText, "local/index.ts", newText, 3)
+	var buf bytes.Buffer
+	Stat(&buf, d, 40, Style{})
+	want := " local/index.ts | 4 +++-\n 1 file changed, 3 insertions(+), 1 deletion(-)\n"
+	if buf.String() != want {
+		t.Errorf("Stat() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	d := Compute("a", "one\ntwo\n", "b", "one\n2\n", 3)
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"op":"context"`, `"op":"delete","text":"two","old_line":2`, `"op":"insert","text":"2","new_line":2`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON missing %s: %s", want, data)
+		}
+	}
+}
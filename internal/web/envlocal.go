@@ -2,6 +2,7 @@
 package web
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,8 +16,55 @@ import (
 // EnvLocalGenerator generates .env.local files for web projects.
 type EnvLocalGenerator struct {
 	OutputPath string
+
+	// Seal, when set, is applied to every drift-managed KEY=VALUE before the
+	// file is written, so secret values can be replaced with store references.
+	Seal func(key, value string) (string, error)
+
+	// ServiceRolePolicy controls where SUPABASE_SERVICE_ROLE_KEY is written.
+	// Empty is treated as ServiceRoleInclude.
+	ServiceRolePolicy string
+
+	// ServerEnvPath is the server-only env file used by ServiceRoleServerOnly.
+	ServerEnvPath string
+
+	// Framework selects variable naming and file format (see FrameworkNextJS).
+	// Empty is treated as FrameworkNextJS.
+	Framework string
+
+	// ShowBanner and BannerLabel are copied into EnvLocalData by
+	// GenerateFromBranchInfo.
+	ShowBanner  bool
+	BannerLabel string
+
+	// Ports are the worktree's local ports, copied into EnvLocalData by
+	// GenerateFromBranchInfo.
+	Ports []LocalPort
+
+	// VarPrefix is copied into EnvLocalData by GenerateFromBranchInfo.
+	VarPrefix string
+
+	// CachedAt, when set, is when the credentials were fetched; the file is
+	// being written from drift's credential cache instead of the API.
+	CachedAt time.Time
+}
+
+// LocalPort is a port assigned to the worktree, written as Env=Port.
+type LocalPort struct {
+	Env  string
+	Port int
 }
 
+// Service role key policies for web.service_role_key.
+const (
+	// ServiceRoleInclude writes the service role key into .env.local.
+	ServiceRoleInclude = "include"
+	// ServiceRoleDeny omits the service role key entirely (frontend-only projects).
+	ServiceRoleDeny = "deny"
+	// ServiceRoleServerOnly writes the service role key to a separate server-only file.
+	ServiceRoleServerOnly = "server-only"
+)
+
 // NewEnvLocalGenerator creates a new .env.local generator.
 func NewEnvLocalGenerator(outputPath string) *EnvLocalGenerator {
 	return &EnvLocalGenerator{
@@ -41,23 +89,43 @@ type EnvLocalData struct {
 	DirectDatabaseURL string // From POSTGRES_URL_NON_POOLING in branch secrets
 	PoolerDatabaseURL string // From POSTGRES_URL in branch secrets
 
+	// OmitServiceRoleKey leaves SUPABASE_SERVICE_ROLE_KEY out of .env.local.
+	// OmittedReason is written as a comment in its place.
+	OmitServiceRoleKey bool
+	OmittedReason      string
+
+	// PublicPrefix is prepended to client-exposed variables, e.g. NEXT_PUBLIC_.
+	PublicPrefix string
+	// VarPrefix is prepended to every drift-managed variable name (after
+	// PublicPrefix), so several Supabase projects' variables can coexist.
+	VarPrefix string
+	// FileName is the generated file's base name, shown in its header.
+	FileName string
+
 	IsFallback  bool
 	IsOverride  bool
 	GeneratedAt time.Time
+	// CachedAt is when cached credentials were fetched, or zero when they
+	// came from the Supabase API just now.
+	CachedAt time.Time
+
+	// ShowBanner adds DRIFT_ENV_BANNER so the app can show which
+	// environment it points to; BannerLabel is empty in production.
+	ShowBanner  bool
+	BannerLabel string
+
+	// Ports are written in their own section for dev servers and tools.
+	Ports []LocalPort
 }
 
 // DatabaseHost returns the direct database host.
 func (d EnvLocalData) DatabaseHost() string {
-	return fmt.Sprintf("db.%s.supabase.co", d.ProjectRef)
+	return supabase.DatabaseHost(d.ProjectRef)
 }
 
 // PoolerHost returns the connection pooler host.
 func (d EnvLocalData) PoolerHost() string {
-	region := d.Region
-	if region == "" {
-		region = "us-east-1"
-	}
-	return fmt.Sprintf("aws-0-%s.pooler.supabase.com", region)
+	return supabase.PoolerHost(d.ProjectRef, d.Region)
 }
 
 // DatabaseURL returns the direct database connection URL.
@@ -141,7 +209,7 @@ const (
 	DriftSectionEnd   = "# === DRIFT MANAGED END ==="
 )
 
-const envLocalTemplate = `# .env.local - SECRETS FILE (gitignored)
+const envLocalTemplate = `# {{.FileName}} - SECRETS FILE (gitignored)
 # Auto-generated by drift - DO NOT COMMIT THIS FILE
 #
 # Environment: {{.Environment}}
@@ -151,6 +219,10 @@ const envLocalTemplate = `# .env.local - SECRETS FILE (gitignored)
 # Using Fallback: {{.IsFallback}}
 # Using Override: {{.IsOverride}}
 # Generated: {{.GeneratedAt.Format "Mon Jan  2 15:04:05 MST 2006"}}
+{{- if not .CachedAt.IsZero}}
+# Generated from cache: credentials fetched {{.CachedAt.Format "Mon Jan  2 15:04:05 MST 2006"}}
+# Run 'drift env setup' online to refresh them.
+{{- end}}
 
 # === DRIFT MANAGED START ===
 # Variables below are managed by drift. Do not edit manually.
@@ -161,23 +233,25 @@ const envLocalTemplate = `# .env.local - SECRETS FILE (gitignored)
 # =============================================================================
 
 # Supabase project URL ({{.SupabaseBranchDisplay}} branch)
-NEXT_PUBLIC_SUPABASE_URL={{.APIURL}}
+{{.PublicPrefix}}{{.VarPrefix}}SUPABASE_URL={{.APIURL}}
 
 # Supabase anon key (Project Settings > API > anon public)
-NEXT_PUBLIC_SUPABASE_ANON_KEY={{.AnonKey}}
+{{.PublicPrefix}}{{.VarPrefix}}SUPABASE_ANON_KEY={{.AnonKey}}
 
 # Branch info for environment display
-NEXT_PUBLIC_GIT_BRANCH={{.GitBranch}}
-NEXT_PUBLIC_SUPABASE_BRANCH={{.SupabaseBranchDisplay}}
-NEXT_PUBLIC_DRIFT_ENVIRONMENT={{.Environment}}
-
+{{.PublicPrefix}}{{.VarPrefix}}GIT_BRANCH={{.GitBranch}}
+{{.PublicPrefix}}{{.VarPrefix}}SUPABASE_BRANCH={{.SupabaseBranchDisplay}}
+{{.PublicPrefix}}{{.VarPrefix}}DRIFT_ENVIRONMENT={{.Environment}}
+{{if .ShowBanner}}{{.PublicPrefix}}{{.VarPrefix}}DRIFT_ENV_BANNER={{.BannerLabel}}
+{{end}}
 # =============================================================================
-# SECRET VARIABLES (server-side only - DO NOT prefix with NEXT_PUBLIC_)
+# SECRET VARIABLES (server-side only - DO NOT prefix with {{.PublicPrefix}})
 # =============================================================================
 
-# Supabase service role key - KEEP SECRET!
-SUPABASE_SERVICE_ROLE_KEY={{.ServiceRoleKey}}
-
+{{if .OmitServiceRoleKey}}# {{.VarPrefix}}SUPABASE_SERVICE_ROLE_KEY omitted: {{.OmittedReason}}
+{{else}}# Supabase service role key - KEEP SECRET!
+{{.VarPrefix}}SUPABASE_SERVICE_ROLE_KEY={{.ServiceRoleKey}}
+{{end}}
 # =============================================================================
 # DATABASE CONNECTION STRINGS
 {{if .HasDatabasePassword}}# Password retrieved from Supabase
@@ -185,14 +259,20 @@ SUPABASE_SERVICE_ROLE_KEY={{.ServiceRoleKey}}
 {{end}}# =============================================================================
 
 # Direct database connection (for migrations, admin tasks)
-DATABASE_URL={{.DatabaseURL}}
+{{.VarPrefix}}DATABASE_URL={{.DatabaseURL}}
 
 # Connection pooler - Transaction mode (port 6543)
-DATABASE_URL_POOLER={{.PoolerURL}}
+{{.VarPrefix}}DATABASE_URL_POOLER={{.PoolerURL}}
 
 # Connection pooler - Session mode (port 5432)
-DATABASE_URL_POOLER_SESSION={{.PoolerSessionURL}}
+{{.VarPrefix}}DATABASE_URL_POOLER_SESSION={{.PoolerSessionURL}}
+{{if .Ports}}
+# =============================================================================
+# LOCAL PORTS (assigned to this worktree, see 'drift worktree ports')
+# =============================================================================
 
+{{range .Ports}}{{.Env}}={{.Port}}
+{{end}}{{end}}
 # === DRIFT MANAGED END ===
 
 # =============================================================================
@@ -202,6 +282,19 @@ DATABASE_URL_POOLER_SESSION={{.PoolerSessionURL}}
 
 // Generate generates the .env.local file, preserving user-added variables.
 func (g *EnvLocalGenerator) Generate(data EnvLocalData) error {
+	if data.VarPrefix == "" {
+		data.VarPrefix = g.VarPrefix
+	}
+	if g.Framework == FrameworkFlutter {
+		return g.generateDartDefines(data)
+	}
+	if data.PublicPrefix == "" {
+		data.PublicPrefix = PublicPrefix(g.Framework)
+	}
+	if data.FileName == "" {
+		data.FileName = filepath.Base(g.OutputPath)
+	}
+
 	tmpl, err := template.New("envlocal").Parse(envLocalTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
@@ -213,6 +306,14 @@ func (g *EnvLocalGenerator) Generate(data EnvLocalData) error {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
+	managed := driftContent.String()
+	if g.Seal != nil {
+		managed, err = sealEnvLines(managed, g.Seal)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check if file exists and has user content to preserve
 	userContent := ""
 	if existingData, err := os.ReadFile(g.OutputPath); err == nil {
@@ -220,7 +321,7 @@ func (g *EnvLocalGenerator) Generate(data EnvLocalData) error {
 	}
 
 	// Combine drift content with user content
-	finalContent := driftContent.String()
+	finalContent := managed
 	if userContent != "" {
 		finalContent = strings.TrimSuffix(finalContent, "\n") + "\n" + userContent
 	}
@@ -233,12 +334,71 @@ func (g *EnvLocalGenerator) Generate(data EnvLocalData) error {
 
 	// Write final content
 	if err := os.WriteFile(g.OutputPath, []byte(finalContent), 0644); err != nil {
-		return fmt.Errorf("failed to write .env.local: %w", err)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(g.OutputPath), err)
 	}
 
 	return nil
 }
 
+// dartDefineKeys are the keys drift manages in a Flutter dart-define file.
+var dartDefineKeys = []string{"SUPABASE_URL", "SUPABASE_ANON_KEY", "GIT_BRANCH", "SUPABASE_BRANCH", "DRIFT_ENVIRONMENT"}
+
+// generateDartDefines writes the public values as JSON for
+// 'flutter run --dart-define-from-file', preserving user-added keys. Dart
+// defines are compiled into the app, so the service role key and database
+// URLs are never written.
+func (g *EnvLocalGenerator) generateDartDefines(data EnvLocalData) error {
+	values := make(map[string]interface{})
+	if existing, err := os.ReadFile(g.OutputPath); err == nil {
+		if err := json.Unmarshal(existing, &values); err != nil {
+			return fmt.Errorf("failed to parse existing %s: %w", filepath.Base(g.OutputPath), err)
+		}
+	}
+	managed := []string{data.APIURL, data.AnonKey, data.GitBranch, data.SupabaseBranchDisplay(), data.Environment}
+	for i, key := range dartDefineKeys {
+		values[data.VarPrefix+key] = managed[i]
+	}
+	if data.ShowBanner {
+		values[data.VarPrefix+"DRIFT_ENV_BANNER"] = data.BannerLabel
+	} else {
+		delete(values, data.VarPrefix+"DRIFT_ENV_BANNER")
+	}
+
+	content, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(g.OutputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(g.OutputPath, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(g.OutputPath), err)
+	}
+	return nil
+}
+
+// sealEnvLines passes each KEY=VALUE line in content through seal.
+func sealEnvLines(content string, seal func(key, value string) (string, error)) (string, error) {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		parts := strings.SplitN(trimmed, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		sealed, err := seal(key, strings.TrimSpace(parts[1]))
+		if err != nil {
+			return "", err
+		}
+		lines[i] = key + "=" + sealed
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 // ExtractUserContent extracts user-added content from an existing .env.local file.
 // It looks for content after the DRIFT MANAGED END marker, or extracts non-drift
 // variables from legacy files without markers.
@@ -406,18 +566,110 @@ func (g *EnvLocalGenerator) GenerateFromBranchInfo(info *supabase.BranchInfo, se
 		IsFallback:        info.IsFallback,
 		IsOverride:        info.IsOverride,
 		GeneratedAt:       time.Now(),
+		CachedAt:          g.CachedAt,
+		ShowBanner:        g.ShowBanner,
+		BannerLabel:       g.BannerLabel,
+		Ports:             g.Ports,
+		VarPrefix:         g.VarPrefix,
+	}
+
+	if g.Framework == FrameworkFlutter {
+		return g.generateDartDefines(data)
+	}
+
+	switch g.ServiceRolePolicy {
+	case "", ServiceRoleInclude:
+	case ServiceRoleDeny:
+		data.OmitServiceRoleKey = true
+		data.OmittedReason = "web.service_role_key is set to deny"
+	case ServiceRoleServerOnly:
+		if g.ServerEnvPath == "" {
+			return fmt.Errorf("server-only service role policy requires a server env path")
+		}
+		data.OmitServiceRoleKey = true
+		data.OmittedReason = fmt.Sprintf("written to %s (server-only)", filepath.Base(g.ServerEnvPath))
+		if err := g.generateServerEnv(data); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid web.service_role_key policy %q (use include, deny, or server-only)", g.ServiceRolePolicy)
 	}
 
 	return g.Generate(data)
 }
 
+const serverEnvTemplate = `# Server-only secrets (gitignored) - NEVER load this file in client code.
+#
+# Environment: {{.Environment}}
+# Supabase Branch: {{.SupabaseBranch}}
+# Generated: {{.GeneratedAt.Format "Mon Jan  2 15:04:05 MST 2006"}}
+{{- if not .CachedAt.IsZero}}
+# Generated from cache: credentials fetched {{.CachedAt.Format "Mon Jan  2 15:04:05 MST 2006"}}
+# Run 'drift env setup' online to refresh them.
+{{- end}}
+
+# === DRIFT MANAGED START ===
+# Supabase service role key - bypasses Row Level Security
+{{.VarPrefix}}SUPABASE_SERVICE_ROLE_KEY={{.ServiceRoleKey}}
+# === DRIFT MANAGED END ===
+`
+
+// generateServerEnv writes the service role key to the server-only env file,
+// preserving user-added variables.
+func (g *EnvLocalGenerator) generateServerEnv(data EnvLocalData) error {
+	tmpl, err := template.New("serverenv").Parse(serverEnvTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var content strings.Builder
+	if err := tmpl.Execute(&content, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	managed := content.String()
+	if g.Seal != nil {
+		managed, err = sealEnvLines(managed, g.Seal)
+		if err != nil {
+			return err
+		}
+	}
+
+	if existingData, err := os.ReadFile(g.ServerEnvPath); err == nil {
+		if userContent := extractUserContent(string(existingData)); userContent != "" {
+			managed = strings.TrimSuffix(managed, "\n") + "\n" + userContent
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(g.ServerEnvPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(g.ServerEnvPath, []byte(managed), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(g.ServerEnvPath), err)
+	}
+	return nil
+}
+
 // ReadEnvLocal reads an existing .env.local file and returns its values as a map.
+// A .json path is read as a Flutter dart-define file.
 func ReadEnvLocal(path string) (map[string]string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
+	if strings.HasSuffix(path, ".json") {
+		var raw map[string]interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+		}
+		values := make(map[string]string, len(raw))
+		for k, v := range raw {
+			values[k] = fmt.Sprint(v)
+		}
+		return values, nil
+	}
+
 	values := make(map[string]string)
 	for _, line := range strings.Split(string(data), "\n") {
 		line = strings.TrimSpace(line)
@@ -447,7 +699,7 @@ func GetCurrentEnvironment(envLocalPath string) (string, error) {
 		return "", err
 	}
 
-	if env, ok := values["NEXT_PUBLIC_DRIFT_ENVIRONMENT"]; ok {
+	if env, ok := PublicValue(values, "DRIFT_ENVIRONMENT"); ok {
 		return env, nil
 	}
 
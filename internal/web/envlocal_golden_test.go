@@ -0,0 +1,95 @@
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/undrift/drift/internal/supabase"
+	"github.com/undrift/drift/internal/testutil"
+)
+
+// generatedLine matches the timestamp header, which changes on every run.
+var generatedLine = regexp.MustCompile(`(?m)^# Generated: .*$`)
+
+func TestGenerateFromBranchInfo_Golden(t *testing.T) {
+	t.Setenv("SUPABASE_DB_PASSWORD", "")
+
+	tests := []struct {
+		name     string
+		info     *supabase.BranchInfo
+		secrets  *BranchSecretsInput
+		setup    func(g *EnvLocalGenerator)
+		existing string
+	}{
+		{
+			name:    "envlocal_feature",
+			info:    testBranchInfo(),
+			secrets: &BranchSecretsInput{AnonKey: "anon", ServiceRoleKey: "service", DatabasePassword: "pw"},
+		},
+		{
+			name: "envlocal_fallback_vite",
+			info: &supabase.BranchInfo{
+				GitBranch:      "feature/y",
+				Environment:    supabase.EnvDevelopment,
+				ProjectRef:     "dev123",
+				Region:         "us-east-1",
+				APIURL:         "https://dev123.supabase.co",
+				SupabaseBranch: &supabase.Branch{Name: "development"},
+				IsFallback:     true,
+			},
+			secrets: &BranchSecretsInput{AnonKey: "anon"},
+			setup: func(g *EnvLocalGenerator) {
+				g.Framework = FrameworkVite
+				g.ShowBanner = true
+				g.BannerLabel = "DEV"
+			},
+		},
+		{
+			name: "envlocal_production_deny",
+			info: &supabase.BranchInfo{
+				GitBranch:      "main",
+				Environment:    supabase.EnvProduction,
+				ProjectRef:     "prod456",
+				APIURL:         "https://prod456.supabase.co",
+				SupabaseBranch: &supabase.Branch{Name: "main"},
+			},
+			secrets: &BranchSecretsInput{
+				AnonKey:           "anon",
+				ServiceRoleKey:    "service",
+				DatabasePassword:  "pw",
+				DirectDatabaseURL: "postgresql://postgres:pw@db.prod456.supabase.co:5432/postgres",
+				PoolerDatabaseURL: "postgresql://postgres.prod456:pw@aws-1-us-east-2.pooler.supabase.com:6543/postgres",
+			},
+			setup: func(g *EnvLocalGenerator) {
+				g.ServiceRolePolicy = ServiceRoleDeny
+				g.Ports = []LocalPort{{Env: "PORT", Port: 3002}}
+			},
+			existing: "NEXT_PUBLIC_SUPABASE_URL=old\n" + DriftSectionEnd + "\n\nSENTRY_DSN=https://sentry\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env.local")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			g := NewEnvLocalGenerator(path)
+			if tt.setup != nil {
+				tt.setup(g)
+			}
+			if err := g.GenerateFromBranchInfo(tt.info, tt.secrets); err != nil {
+				t.Fatalf("GenerateFromBranchInfo() error = %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testutil.Golden(t, tt.name, generatedLine.ReplaceAll(data, []byte("# Generated: <time>")))
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package web
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/supabase"
+)
+
+func testBranchInfo() *supabase.BranchInfo {
+	return &supabase.BranchInfo{
+		GitBranch:      "feature/x",
+		Environment:    supabase.EnvFeature,
+		ProjectRef:     "abc",
+		APIURL:         "https://abc.supabase.co",
+		SupabaseBranch: &supabase.Branch{Name: "feature-x"},
+	}
+}
+
+func TestGenerateFromBranchInfo_PublicPrefix(t *testing.T) {
+	tests := []struct {
+		framework string
+		want      string
+	}{
+		{"", "NEXT_PUBLIC_SUPABASE_URL=https://abc.supabase.co"},
+		{FrameworkVite, "VITE_SUPABASE_URL=https://abc.supabase.co"},
+		{FrameworkExpo, "EXPO_PUBLIC_SUPABASE_URL=https://abc.supabase.co"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.framework, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), DefaultEnvOutput(tt.framework))
+			g := NewEnvLocalGenerator(path)
+			g.Framework = tt.framework
+			if err := g.GenerateFromBranchInfo(testBranchInfo(), &BranchSecretsInput{AnonKey: "anon", ServiceRoleKey: "service"}); err != nil {
+				t.Fatalf("GenerateFromBranchInfo() error = %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("generated file missing %q\n%s", tt.want, data)
+			}
+			if env, err := GetCurrentEnvironment(path); err != nil || env != "Feature" {
+				t.Errorf("GetCurrentEnvironment() = %q, %v; want Feature", env, err)
+			}
+		})
+	}
+}
+
+func TestGenerateFromBranchInfo_VarPrefix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env.analytics.local")
+	g := NewEnvLocalGenerator(path)
+	g.VarPrefix = "ANALYTICS_"
+	if err := g.GenerateFromBranchInfo(testBranchInfo(), &BranchSecretsInput{AnonKey: "anon", ServiceRoleKey: "service"}); err != nil {
+		t.Fatalf("GenerateFromBranchInfo() error = %v", err)
+	}
+
+	values, err := ReadEnvLocal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"NEXT_PUBLIC_ANALYTICS_SUPABASE_URL", "NEXT_PUBLIC_ANALYTICS_SUPABASE_BRANCH", "ANALYTICS_SUPABASE_SERVICE_ROLE_KEY", "ANALYTICS_DATABASE_URL"} {
+		if _, ok := values[key]; !ok {
+			t.Errorf("generated file missing %s", key)
+		}
+	}
+	if _, ok := values["NEXT_PUBLIC_SUPABASE_URL"]; ok {
+		t.Error("generated file has an unprefixed NEXT_PUBLIC_SUPABASE_URL")
+	}
+}
+
+func TestGenerateFromBranchInfo_DartDefines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dart_defines.json")
+	if err := os.WriteFile(path, []byte(`{"SENTRY_DSN": "https://sentry", "SUPABASE_URL": "old", "RETRIES": 3}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewEnvLocalGenerator(path)
+	g.Framework = FrameworkFlutter
+	secrets := &BranchSecretsInput{AnonKey: "anon", ServiceRoleKey: "service", DatabasePassword: "pw"}
+	if err := g.GenerateFromBranchInfo(testBranchInfo(), secrets); err != nil {
+		t.Fatalf("GenerateFromBranchInfo() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("generated file is not JSON: %v\n%s", err, data)
+	}
+	for key, want := range map[string]interface{}{
+		"SUPABASE_URL":      "https://abc.supabase.co",
+		"SUPABASE_ANON_KEY": "anon",
+		"DRIFT_ENVIRONMENT": "Feature",
+		"SENTRY_DSN":        "https://sentry",
+		"RETRIES":           float64(3),
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %v", key, got[key], want)
+		}
+	}
+	for _, key := range []string{"SUPABASE_SERVICE_ROLE_KEY", "DATABASE_URL"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("%s written to dart defines", key)
+		}
+	}
+
+	if env, err := GetCurrentEnvironment(path); err != nil || env != "Feature" {
+		t.Errorf("GetCurrentEnvironment() = %q, %v; want Feature", env, err)
+	}
+}
+
+func TestDetectFramework(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"flutter", map[string]string{"pubspec.yaml": "name: app"}, FrameworkFlutter},
+		{"expo", map[string]string{"package.json": `{"dependencies": {"expo": "~51.0.0", "react-native": "0.74"}}`}, FrameworkExpo},
+		{"next", map[string]string{"package.json": `{"dependencies": {"next": "14.0.0"}}`}, FrameworkNextJS},
+		{"vite", map[string]string{"package.json": `{"devDependencies": {"vite": "^5.0.0"}}`}, FrameworkVite},
+		{"unknown", map[string]string{"package.json": `{"dependencies": {"express": "4"}}`}, ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := DetectFramework(dir); got != tt.want {
+				t.Errorf("DetectFramework() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateFromBranchInfo_Banner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env.local")
+	g := NewEnvLocalGenerator(path)
+	if err := g.GenerateFromBranchInfo(testBranchInfo(), &BranchSecretsInput{AnonKey: "anon"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "DRIFT_ENV_BANNER") {
+		t.Error("banner written without ShowBanner")
+	}
+
+	g.ShowBanner = true
+	g.BannerLabel = "FEATURE"
+	if err := g.GenerateFromBranchInfo(testBranchInfo(), &BranchSecretsInput{AnonKey: "anon"}); err != nil {
+		t.Fatal(err)
+	}
+	values, err := ReadEnvLocal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["NEXT_PUBLIC_DRIFT_ENV_BANNER"] != "FEATURE" {
+		t.Errorf("NEXT_PUBLIC_DRIFT_ENV_BANNER = %q, want FEATURE", values["NEXT_PUBLIC_DRIFT_ENV_BANNER"])
+	}
+}
+
+func TestGenerateFromBranchInfo_Ports(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env.local")
+	g := NewEnvLocalGenerator(path)
+	g.Ports = []LocalPort{{Env: "PORT", Port: 3001}, {Env: "STORYBOOK_PORT", Port: 6007}}
+	if err := g.GenerateFromBranchInfo(testBranchInfo(), &BranchSecretsInput{AnonKey: "anon"}); err != nil {
+		t.Fatal(err)
+	}
+	values, err := ReadEnvLocal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["PORT"] != "3001" || values["STORYBOOK_PORT"] != "6007" {
+		t.Errorf("ports = PORT=%q STORYBOOK_PORT=%q, want 3001 and 6007", values["PORT"], values["STORYBOOK_PORT"])
+	}
+}
+
+func TestGenerateFromBranchInfo_CachedAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env.local")
+	g := NewEnvLocalGenerator(path)
+	if err := g.GenerateFromBranchInfo(testBranchInfo(), &BranchSecretsInput{AnonKey: "anon"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "Generated from cache") {
+		t.Error("cache note written without CachedAt")
+	}
+
+	g.CachedAt = time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+	if err := g.GenerateFromBranchInfo(testBranchInfo(), &BranchSecretsInput{AnonKey: "anon"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# Generated from cache: credentials fetched Wed Mar  4 09:30:00 UTC 2026") {
+		t.Errorf("header does not note the cache:\n%s", data)
+	}
+	if values, _ := ReadEnvLocal(path); values["NEXT_PUBLIC_SUPABASE_ANON_KEY"] != "anon" {
+		t.Errorf("NEXT_PUBLIC_SUPABASE_ANON_KEY = %q, want anon", values["NEXT_PUBLIC_SUPABASE_ANON_KEY"])
+	}
+}
+
+func TestGenerateFromBranchInfo_ServiceRolePolicy(t *testing.T) {
+	tests := []struct {
+		policy      string
+		wantInLocal bool
+		wantServer  bool
+	}{
+		{"", true, false},
+		{ServiceRoleInclude, true, false},
+		{ServiceRoleDeny, false, false},
+		{ServiceRoleServerOnly, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, ".env.local")
+			serverPath := filepath.Join(dir, ".env.server.local")
+			g := NewEnvLocalGenerator(path)
+			g.ServiceRolePolicy = tt.policy
+			g.ServerEnvPath = serverPath
+			if err := g.GenerateFromBranchInfo(testBranchInfo(), &BranchSecretsInput{AnonKey: "anon", ServiceRoleKey: "service"}); err != nil {
+				t.Fatalf("GenerateFromBranchInfo() error = %v", err)
+			}
+
+			values, err := ReadEnvLocal(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := values["SUPABASE_SERVICE_ROLE_KEY"] == "service"; got != tt.wantInLocal {
+				t.Errorf(".env.local has the service role key = %v, want %v", got, tt.wantInLocal)
+			}
+			server, err := os.ReadFile(serverPath)
+			if gotServer := err == nil && strings.Contains(string(server), "SUPABASE_SERVICE_ROLE_KEY=service"); gotServer != tt.wantServer {
+				t.Errorf("server env has the service role key = %v, want %v", gotServer, tt.wantServer)
+			}
+		})
+	}
+}
+
+func TestGenerateFromBranchInfo_ServiceRolePolicyErrors(t *testing.T) {
+	secrets := &BranchSecretsInput{AnonKey: "anon", ServiceRoleKey: "service"}
+
+	g := NewEnvLocalGenerator(filepath.Join(t.TempDir(), ".env.local"))
+	g.ServiceRolePolicy = "public"
+	if err := g.GenerateFromBranchInfo(testBranchInfo(), secrets); err == nil || !strings.Contains(err.Error(), "invalid web.service_role_key") {
+		t.Errorf("unknown policy error = %v", err)
+	}
+
+	g.ServiceRolePolicy = ServiceRoleServerOnly
+	if err := g.GenerateFromBranchInfo(testBranchInfo(), secrets); err == nil || !strings.Contains(err.Error(), "server env path") {
+		t.Errorf("server-only without a path error = %v", err)
+	}
+}
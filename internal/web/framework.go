@@ -0,0 +1,93 @@
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Frameworks for web.framework. The framework decides the prefix of
+// client-exposed variables and the format of the generated file.
+const (
+	// FrameworkNextJS writes NEXT_PUBLIC_* variables to .env.local.
+	FrameworkNextJS = "nextjs"
+	// FrameworkVite writes VITE_* variables to .env.
+	FrameworkVite = "vite"
+	// FrameworkExpo writes EXPO_PUBLIC_* variables to .env.
+	FrameworkExpo = "expo"
+	// FrameworkFlutter writes a JSON file for --dart-define-from-file.
+	FrameworkFlutter = "flutter"
+)
+
+// ValidFramework reports whether framework is a known web.framework value.
+func ValidFramework(framework string) bool {
+	switch framework {
+	case "", FrameworkNextJS, FrameworkVite, FrameworkExpo, FrameworkFlutter:
+		return true
+	}
+	return false
+}
+
+// PublicPrefix returns the prefix that exposes a variable to client code.
+// Flutter has none: every dart-define is compiled into the app.
+func PublicPrefix(framework string) string {
+	switch framework {
+	case FrameworkVite:
+		return "VITE_"
+	case FrameworkExpo:
+		return "EXPO_PUBLIC_"
+	case FrameworkFlutter:
+		return ""
+	default:
+		return "NEXT_PUBLIC_"
+	}
+}
+
+// DefaultEnvOutput returns the generated file name for framework.
+func DefaultEnvOutput(framework string) string {
+	switch framework {
+	case FrameworkVite, FrameworkExpo:
+		return ".env"
+	case FrameworkFlutter:
+		return "dart_defines.json"
+	default:
+		return ".env.local"
+	}
+}
+
+// publicPrefixes lists every prefix drift may have written, so files
+// generated for any framework can be read back.
+var publicPrefixes = []string{"NEXT_PUBLIC_", "VITE_", "EXPO_PUBLIC_", ""}
+
+// PublicValue returns the public variable name (e.g. "SUPABASE_URL") from
+// values under whichever framework prefix it was written with.
+func PublicValue(values map[string]string, name string) (string, bool) {
+	for _, prefix := range publicPrefixes {
+		if v, ok := values[prefix+name]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// DetectFramework guesses the framework from files in dir, returning ""
+// when nothing matches.
+func DetectFramework(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, "pubspec.yaml")); err == nil {
+		return FrameworkFlutter
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	content := string(data)
+	switch {
+	case strings.Contains(content, `"expo"`):
+		return FrameworkExpo
+	case strings.Contains(content, `"next"`):
+		return FrameworkNextJS
+	case strings.Contains(content, `"vite"`):
+		return FrameworkVite
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package xcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BuildServerFile is the file xcode-build-server writes for sourcekit-lsp.
+const BuildServerFile = "buildServer.json"
+
+// SupportedBSPVersion is the Build Server Protocol version sourcekit-lsp expects.
+const SupportedBSPVersion = "2.0"
+
+// BuildServerConfig mirrors the buildServer.json written by xcode-build-server.
+type BuildServerConfig struct {
+	Name       string   `json:"name"`
+	Version    string   `json:"version"`
+	BSPVersion string   `json:"bspVersion"`
+	Languages  []string `json:"languages"`
+	Argv       []string `json:"argv"`
+	Workspace  string   `json:"workspace"`
+	BuildRoot  string   `json:"build_root"`
+	Scheme     string   `json:"scheme"`
+	Kind       string   `json:"kind"`
+}
+
+// ReadBuildServerConfig parses the buildServer.json at path.
+func ReadBuildServerConfig(path string) (*BuildServerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg BuildServerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Base(path), err)
+	}
+	return &cfg, nil
+}
+
+// IndexStorePath returns the first existing index store under the build root.
+// Xcode 14+ uses Index.noindex; older versions use Index.
+func (c *BuildServerConfig) IndexStorePath() string {
+	if c.BuildRoot == "" {
+		return ""
+	}
+	for _, dir := range []string{"Index.noindex", "Index"} {
+		path := filepath.Join(c.BuildRoot, dir, "DataStore")
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// Validate returns a list of problems that would stop sourcekit-lsp from
+// using this build server. An empty result means the file looks usable.
+func (c *BuildServerConfig) Validate() []string {
+	var problems []string
+
+	if c.BSPVersion != SupportedBSPVersion {
+		problems = append(problems, fmt.Sprintf("bspVersion is %q, expected %q", c.BSPVersion, SupportedBSPVersion))
+	}
+	if len(c.Argv) == 0 {
+		problems = append(problems, "argv is empty")
+	} else if _, err := os.Stat(c.Argv[0]); err != nil {
+		problems = append(problems, fmt.Sprintf("build server binary not found: %s", c.Argv[0]))
+	}
+	if c.Scheme == "" {
+		problems = append(problems, "scheme is empty")
+	}
+	if c.Workspace == "" {
+		problems = append(problems, "workspace is empty")
+	} else if _, err := os.Stat(c.Workspace); err != nil {
+		problems = append(problems, fmt.Sprintf("workspace not found: %s", c.Workspace))
+	}
+	if c.BuildRoot == "" {
+		problems = append(problems, "build_root is empty")
+	} else if c.IndexStorePath() == "" {
+		problems = append(problems, fmt.Sprintf("no index store under %s (build the scheme in Xcode once)", c.BuildRoot))
+	}
+
+	return problems
+}
+
+// IsStaleFor reports whether the file was generated for a different scheme.
+func (c *BuildServerConfig) IsStaleFor(scheme string) bool {
+	return scheme != "" && c.Scheme != scheme
+}
+
+// UpdateVSCodeSettings merges settings into the VS Code settings.json at path,
+// creating it if needed. Existing keys not in settings are preserved.
+// It returns true when the file was changed.
+func UpdateVSCodeSettings(path string, settings map[string]interface{}) (bool, error) {
+	current := make(map[string]interface{})
+	if data, err := os.ReadFile(path); err == nil {
+		if strings.TrimSpace(string(data)) != "" {
+			if err := json.Unmarshal(data, &current); err != nil {
+				return false, fmt.Errorf("could not parse %s (comments are not supported; edit it manually): %w", path, err)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	changed := false
+	for key, value := range settings {
+		if existing, ok := current[key]; ok && fmt.Sprint(existing) == fmt.Sprint(value) {
+			continue
+		}
+		current[key] = value
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+
+	data, err := json.MarshalIndent(current, "", "    ")
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,109 @@
+package xcode
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeBuildServerFixture(t *testing.T, dir string, cfg BuildServerConfig) string {
+	t.Helper()
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, BuildServerFile)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBuildServerConfigValidate(t *testing.T) {
+	tmpDir := t.TempDir()
+	binary := filepath.Join(tmpDir, "xcode-build-server")
+	workspace := filepath.Join(tmpDir, "App.xcodeproj")
+	buildRoot := filepath.Join(tmpDir, "DerivedData", "App-abc")
+	for _, dir := range []string{workspace, filepath.Join(buildRoot, "Index.noindex", "DataStore")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeBuildServerFixture(t, tmpDir, BuildServerConfig{
+		Name:       "xcode build server",
+		BSPVersion: "2.0",
+		Argv:       []string{binary},
+		Workspace:  workspace,
+		BuildRoot:  buildRoot,
+		Scheme:     "App (Development)",
+	})
+
+	cfg, err := ReadBuildServerConfig(path)
+	if err != nil {
+		t.Fatalf("ReadBuildServerConfig() error = %v", err)
+	}
+	if problems := cfg.Validate(); len(problems) != 0 {
+		t.Errorf("Validate() = %v, want no problems", problems)
+	}
+	if cfg.IsStaleFor("App (Development)") {
+		t.Error("IsStaleFor(same scheme) = true, want false")
+	}
+	if !cfg.IsStaleFor("App (Production)") {
+		t.Error("IsStaleFor(other scheme) = false, want true")
+	}
+
+	cfg.BSPVersion = "1.0"
+	cfg.BuildRoot = filepath.Join(tmpDir, "missing")
+	problems := cfg.Validate()
+	if len(problems) != 2 {
+		t.Fatalf("Validate() = %v, want 2 problems", problems)
+	}
+	if !strings.Contains(problems[0], "bspVersion") || !strings.Contains(problems[1], "index store") {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+}
+
+func TestUpdateVSCodeSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".vscode", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"editor.tabSize": 4}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := map[string]interface{}{"swift.sourcekit-lsp.serverPath": "/usr/bin/sourcekit-lsp"}
+	changed, err := UpdateVSCodeSettings(path, settings)
+	if err != nil {
+		t.Fatalf("UpdateVSCodeSettings() error = %v", err)
+	}
+	if !changed {
+		t.Error("first update reported no change")
+	}
+
+	changed, err = UpdateVSCodeSettings(path, settings)
+	if err != nil {
+		t.Fatalf("UpdateVSCodeSettings() error = %v", err)
+	}
+	if changed {
+		t.Error("second update reported a change")
+	}
+
+	data, _ := os.ReadFile(path)
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("settings.json is not valid JSON: %v", err)
+	}
+	if got["editor.tabSize"] != float64(4) {
+		t.Errorf("existing setting lost: %v", got)
+	}
+	if got["swift.sourcekit-lsp.serverPath"] != "/usr/bin/sourcekit-lsp" {
+		t.Errorf("new setting missing: %v", got)
+	}
+}
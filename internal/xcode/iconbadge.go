@@ -0,0 +1,126 @@
+package xcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Badge colors by environment name; anything else uses badgeDefault.
+var (
+	badgeColors = map[string]color.RGBA{
+		"development": {R: 0xF5, G: 0x9E, B: 0x0B, A: 0xFF}, // amber
+		"feature":     {R: 0x8B, G: 0x5C, B: 0xF6, A: 0xFF}, // violet
+	}
+	badgeDefault = color.RGBA{R: 0x0E, G: 0xA5, B: 0xE9, A: 0xFF} // sky
+)
+
+// BadgeColor returns the badge color for environment.
+func BadgeColor(environment string) color.RGBA {
+	if c, ok := badgeColors[strings.ToLower(environment)]; ok {
+		return c
+	}
+	return badgeDefault
+}
+
+// BadgedIconSetPath returns the icon set a badge for suffix is written to,
+// e.g. AppIcon.appiconset + "-dev" -> AppIcon-dev.appiconset.
+func BadgedIconSetPath(appIconSet, suffix string) string {
+	appIconSet = strings.TrimRight(appIconSet, "/")
+	ext := filepath.Ext(appIconSet)
+	return strings.TrimSuffix(appIconSet, ext) + suffix + ext
+}
+
+// GenerateBadgedIconSet copies the app icon set at appIconSet to the icon set
+// for suffix, drawing a band in badge across the bottom of every PNG. Other
+// files, including Contents.json, are copied unchanged. It returns the path
+// of the generated icon set.
+func GenerateBadgedIconSet(appIconSet, suffix string, badge color.Color) (string, error) {
+	if suffix == "" {
+		return "", fmt.Errorf("an icon suffix is required to badge %s", filepath.Base(appIconSet))
+	}
+	entries, err := os.ReadDir(appIconSet)
+	if err != nil {
+		return "", fmt.Errorf("failed to read app icon set: %w", err)
+	}
+
+	dest := BadgedIconSetPath(appIconSet, suffix)
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Base(dest), err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		src := filepath.Join(appIconSet, entry.Name())
+		dst := filepath.Join(dest, entry.Name())
+		if strings.EqualFold(filepath.Ext(entry.Name()), ".png") {
+			err = badgePNG(src, dst, badge)
+		} else {
+			err = copyIconFile(src, dst)
+		}
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+	}
+	return dest, nil
+}
+
+// badgePNG writes src to dst with a band across the bottom fifth.
+func badgePNG(src, dst string, badge color.Color) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	img, err := png.Decode(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	out := drawBadge(img, badge)
+
+	w, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, out); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// drawBadge returns a copy of img with a band in badge across the bottom
+// fifth, separated from the icon by a thin white line.
+func drawBadge(img image.Image, badge color.Color) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(b)
+	draw.Draw(out, b, img, b.Min, draw.Src)
+
+	height := b.Dy() / 5
+	if height < 1 {
+		height = 1
+	}
+	band := image.Rect(b.Min.X, b.Max.Y-height, b.Max.X, b.Max.Y)
+	draw.Draw(out, band, image.NewUniform(badge), image.Point{}, draw.Src)
+
+	if line := b.Dy() / 64; line > 0 {
+		edge := image.Rect(b.Min.X, band.Min.Y-line, b.Max.X, band.Min.Y)
+		draw.Draw(out, edge, image.NewUniform(color.White), image.Point{}, draw.Src)
+	}
+	return out
+}
+
+func copyIconFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
@@ -0,0 +1,71 @@
+package xcode
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBadgedIconSetPath(t *testing.T) {
+	got := BadgedIconSetPath("App/Assets.xcassets/AppIcon.appiconset/", "-dev")
+	if want := "App/Assets.xcassets/AppIcon-dev.appiconset"; got != want {
+		t.Errorf("BadgedIconSetPath() = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateBadgedIconSet(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "AppIcon.appiconset")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	icon := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			icon.Set(x, y, color.Black)
+		}
+	}
+	f, err := os.Create(filepath.Join(src, "icon-1024.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, icon); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	contents := `{"images":[{"filename":"icon-1024.png"}]}`
+	if err := os.WriteFile(filepath.Join(src, "Contents.json"), []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	badge := BadgeColor("Development")
+	dest, err := GenerateBadgedIconSet(src, "-dev", badge)
+	if err != nil {
+		t.Fatalf("GenerateBadgedIconSet() error = %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dest, "Contents.json")); err != nil || string(data) != contents {
+		t.Errorf("Contents.json = %q, %v; want a copy", data, err)
+	}
+	f, err = os.Open(filepath.Join(dest, "icon-1024.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	out, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := color.RGBAModel.Convert(out.At(50, 95)); got != badge {
+		t.Errorf("bottom pixel = %v, want badge color %v", got, badge)
+	}
+	if got := color.RGBAModel.Convert(out.At(50, 10)).(color.RGBA); got.R != 0 || got.A != 0xFF {
+		t.Errorf("top pixel = %v, want the original icon", got)
+	}
+
+	if _, err := GenerateBadgedIconSet(src, "", badge); err == nil {
+		t.Error("GenerateBadgedIconSet() with no suffix should fail")
+	}
+}
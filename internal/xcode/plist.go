@@ -0,0 +1,132 @@
+package xcode
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// PlistBuddyPath is the location of Apple's plist editor.
+const PlistBuddyPath = "/usr/libexec/PlistBuddy"
+
+// SyncPlist sets each key in values on the plist at path, replacing any
+// existing value. Keys not listed in values are left untouched.
+// It returns the keys that were written, in sorted order. If PlistBuddy
+// fails, the plist is restored to its original contents.
+func SyncPlist(path string, values map[string]interface{}) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("plist not found: %s", path)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var commands []string
+	for _, key := range keys {
+		cmds, err := PlistBuddyCommands(":"+key, values[key])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		commands = append(commands, cmds...)
+	}
+	if len(commands) == 0 {
+		return nil, nil
+	}
+
+	// The keys are deleted before the batch of Adds runs, so keep the
+	// original to put back if the Adds fail.
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// Delete fails when the key is absent, so run it separately and ignore the result.
+	for _, key := range keys {
+		_, _ = shell.Run(PlistBuddyPath, "-c", "Delete :"+key, path)
+	}
+
+	args := make([]string, 0, len(commands)*2+1)
+	for _, c := range commands {
+		args = append(args, "-c", c)
+	}
+	args = append(args, path)
+
+	result, err := shell.Run(PlistBuddyPath, args...)
+	if err != nil || result.ExitCode != 0 {
+		failure := fmt.Errorf("PlistBuddy failed on %s: %s", path, commandFailure(result, err))
+		if restoreErr := os.WriteFile(path, original, info.Mode().Perm()); restoreErr != nil {
+			return nil, fmt.Errorf("%w (restoring the original plist also failed: %v)", failure, restoreErr)
+		}
+		return nil, failure
+	}
+
+	return keys, nil
+}
+
+// PlistBuddyCommands returns the PlistBuddy "Add" commands that create entry
+// at keyPath with the given value. Arrays and dictionaries are expanded
+// recursively so each element gets its own typed entry.
+func PlistBuddyCommands(keyPath string, value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{fmt.Sprintf("Add %s string %s", keyPath, quotePlistValue(v))}, nil
+	case bool:
+		return []string{fmt.Sprintf("Add %s bool %t", keyPath, v)}, nil
+	case int:
+		return []string{fmt.Sprintf("Add %s integer %d", keyPath, v)}, nil
+	case int64:
+		return []string{fmt.Sprintf("Add %s integer %d", keyPath, v)}, nil
+	case float64:
+		return []string{fmt.Sprintf("Add %s real %s", keyPath, strconv.FormatFloat(v, 'f', -1, 64))}, nil
+	case []interface{}:
+		cmds := []string{fmt.Sprintf("Add %s array", keyPath)}
+		for i, item := range v {
+			child, err := PlistBuddyCommands(fmt.Sprintf("%s:%d", keyPath, i), item)
+			if err != nil {
+				return nil, err
+			}
+			cmds = append(cmds, child...)
+		}
+		return cmds, nil
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return PlistBuddyCommands(keyPath, items)
+	case map[string]interface{}:
+		cmds := []string{fmt.Sprintf("Add %s dict", keyPath)}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child, err := PlistBuddyCommands(keyPath+":"+key, v[key])
+			if err != nil {
+				return nil, err
+			}
+			cmds = append(cmds, child...)
+		}
+		return cmds, nil
+	case nil:
+		return nil, fmt.Errorf("value is empty")
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// quotePlistValue wraps a string for PlistBuddy's command parser.
+func quotePlistValue(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	return `"` + escaped + `"`
+}
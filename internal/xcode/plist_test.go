@@ -0,0 +1,78 @@
+package xcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlistBuddyCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		want  []string
+	}{
+		{
+			name:  "string",
+			key:   ":API_BASE_URL",
+			value: "https://api.example.com",
+			want:  []string{`Add :API_BASE_URL string "https://api.example.com"`},
+		},
+		{
+			name:  "string with quotes",
+			key:   ":Greeting",
+			value: `say "hi"`,
+			want:  []string{`Add :Greeting string "say \"hi\""`},
+		},
+		{
+			name:  "bool",
+			key:   ":UIFileSharingEnabled",
+			value: true,
+			want:  []string{"Add :UIFileSharingEnabled bool true"},
+		},
+		{
+			name:  "integer",
+			key:   ":Retries",
+			value: 3,
+			want:  []string{"Add :Retries integer 3"},
+		},
+		{
+			name:  "array",
+			key:   ":com.apple.developer.associated-domains",
+			value: []interface{}{"applinks:example.com", "webcredentials:example.com"},
+			want: []string{
+				"Add :com.apple.developer.associated-domains array",
+				`Add :com.apple.developer.associated-domains:0 string "applinks:example.com"`,
+				`Add :com.apple.developer.associated-domains:1 string "webcredentials:example.com"`,
+			},
+		},
+		{
+			name:  "dict",
+			key:   ":Endpoints",
+			value: map[string]interface{}{"rest": "https://a", "auth": "https://b"},
+			want: []string{
+				"Add :Endpoints dict",
+				`Add :Endpoints:auth string "https://b"`,
+				`Add :Endpoints:rest string "https://a"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PlistBuddyCommands(tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("PlistBuddyCommands() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlistBuddyCommands() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlistBuddyCommandsRejectsEmptyValue(t *testing.T) {
+	if _, err := PlistBuddyCommands(":Key", nil); err == nil {
+		t.Error("expected error for nil value")
+	}
+}
@@ -0,0 +1,298 @@
+package xcode
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/undrift/drift/pkg/shell"
+)
+
+// ProvisioningProfile is the subset of a .mobileprovision file drift needs for resigning.
+type ProvisioningProfile struct {
+	Path           string
+	Name           string
+	TeamID         string
+	AppID          string // application-identifier entitlement, e.g. TEAMID.com.example.*
+	ExpirationDate time.Time
+}
+
+// SigningIdentity is a code signing identity from the keychain.
+type SigningIdentity struct {
+	Hash string
+	Name string
+}
+
+// ResignOptions controls how an .ipa is resigned.
+type ResignOptions struct {
+	Identity string // signing identity name or SHA-1 hash
+	Profile  string // path to a .mobileprovision file
+	BundleID string // optional new CFBundleIdentifier for the main app
+}
+
+// ProvisioningProfileDirs returns the directories Xcode stores provisioning profiles in.
+func ProvisioningProfileDirs() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(home, "Library", "Developer", "Xcode", "UserData", "Provisioning Profiles"),
+		filepath.Join(home, "Library", "MobileDevice", "Provisioning Profiles"),
+	}
+}
+
+func plistStringPattern(key string) *regexp.Regexp {
+	return regexp.MustCompile(`<key>` + regexp.QuoteMeta(key) + `</key>\s*<string>([^<]*)</string>`)
+}
+
+var (
+	profileNamePattern    = plistStringPattern("Name")
+	profileAppIDPattern   = plistStringPattern("application-identifier")
+	profileTeamPattern    = regexp.MustCompile(`<key>TeamIdentifier</key>\s*<array>\s*<string>([^<]*)</string>`)
+	profileExpiresPattern = regexp.MustCompile(`<key>ExpirationDate</key>\s*<date>([^<]*)</date>`)
+	identityLinePattern   = regexp.MustCompile(`^\s*\d+\)\s+([0-9A-F]{40})\s+"([^"]+)"`)
+)
+
+// ParseProvisioningProfile reads the fields drift needs from a decoded
+// (XML plist) provisioning profile.
+func ParseProvisioningProfile(data []byte) (*ProvisioningProfile, error) {
+	text := string(data)
+	profile := &ProvisioningProfile{}
+	if m := profileNamePattern.FindStringSubmatch(text); m != nil {
+		profile.Name = m[1]
+	}
+	if m := profileTeamPattern.FindStringSubmatch(text); m != nil {
+		profile.TeamID = m[1]
+	}
+	if m := profileAppIDPattern.FindStringSubmatch(text); m != nil {
+		profile.AppID = m[1]
+	}
+	if m := profileExpiresPattern.FindStringSubmatch(text); m != nil {
+		if t, err := time.Parse(time.RFC3339, m[1]); err == nil {
+			profile.ExpirationDate = t
+		}
+	}
+	if profile.TeamID == "" || profile.AppID == "" {
+		return nil, fmt.Errorf("not a provisioning profile")
+	}
+	return profile, nil
+}
+
+// Matches reports whether the profile can sign bundleID for teamID.
+func (p *ProvisioningProfile) Matches(teamID, bundleID string) bool {
+	if p.TeamID != teamID {
+		return false
+	}
+	pattern := strings.TrimPrefix(p.AppID, p.TeamID+".")
+	if pattern == "*" {
+		return true
+	}
+	if strings.HasSuffix(pattern, ".*") {
+		return strings.HasPrefix(bundleID, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == bundleID
+}
+
+// DecodeProvisioningProfile decodes a .mobileprovision file with the security tool.
+func DecodeProvisioningProfile(path string) (*ProvisioningProfile, error) {
+	result, err := shell.Run("security", "cms", "-D", "-i", path)
+	if err != nil || result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to decode %s: %s", filepath.Base(path), commandFailure(result, err))
+	}
+	profile, err := ParseProvisioningProfile([]byte(result.Stdout))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	profile.Path = path
+	return profile, nil
+}
+
+// FindProvisioningProfile returns the unexpired installed profile for teamID
+// that covers bundleID, preferring explicit App IDs over wildcards and then
+// the latest expiration.
+func FindProvisioningProfile(teamID, bundleID string) (*ProvisioningProfile, error) {
+	var candidates []*ProvisioningProfile
+	for _, dir := range ProvisioningProfileDirs() {
+		paths, _ := filepath.Glob(filepath.Join(dir, "*.mobileprovision"))
+		for _, path := range paths {
+			profile, err := DecodeProvisioningProfile(path)
+			if err != nil || !profile.Matches(teamID, bundleID) {
+				continue
+			}
+			if !profile.ExpirationDate.IsZero() && profile.ExpirationDate.Before(time.Now()) {
+				continue
+			}
+			candidates = append(candidates, profile)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no provisioning profile for team %s covers %s (open the project in Xcode once, or set device.wda_profile)", teamID, bundleID)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		wi := strings.HasSuffix(candidates[i].AppID, "*")
+		wj := strings.HasSuffix(candidates[j].AppID, "*")
+		if wi != wj {
+			return !wi
+		}
+		return candidates[i].ExpirationDate.After(candidates[j].ExpirationDate)
+	})
+	return candidates[0], nil
+}
+
+// ParseSigningIdentities parses `security find-identity -v -p codesigning` output.
+func ParseSigningIdentities(output string) []SigningIdentity {
+	var identities []SigningIdentity
+	for _, line := range strings.Split(output, "\n") {
+		if m := identityLinePattern.FindStringSubmatch(line); m != nil {
+			identities = append(identities, SigningIdentity{Hash: m[1], Name: m[2]})
+		}
+	}
+	return identities
+}
+
+// FindSigningIdentity picks a valid code signing identity for teamID.
+// Distribution-style names carry the team ID in parentheses; otherwise the
+// first Apple Development identity is used.
+func FindSigningIdentity(teamID string) (*SigningIdentity, error) {
+	result, err := shell.Run("security", "find-identity", "-v", "-p", "codesigning")
+	if err != nil || result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to list signing identities: %s", commandFailure(result, err))
+	}
+	identities := ParseSigningIdentities(result.Stdout)
+
+	for _, id := range identities {
+		if teamID != "" && strings.Contains(id.Name, "("+teamID+")") {
+			return &id, nil
+		}
+	}
+	for _, id := range identities {
+		if strings.HasPrefix(id.Name, "Apple Development:") || strings.HasPrefix(id.Name, "iPhone Developer:") {
+			return &id, nil
+		}
+	}
+	return nil, fmt.Errorf("no code signing identity found for team %s", teamID)
+}
+
+// ResignIPA re-signs the app inside ipaPath with a new identity and
+// provisioning profile and writes the result to outPath.
+func ResignIPA(ipaPath, outPath string, opts ResignOptions) error {
+	work, err := os.MkdirTemp("", "drift-resign-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+
+	if result, err := shell.Run("unzip", "-q", ipaPath, "-d", work); err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to unpack %s: %s", filepath.Base(ipaPath), commandFailure(result, err))
+	}
+	apps, _ := filepath.Glob(filepath.Join(work, "Payload", "*.app"))
+	if len(apps) != 1 {
+		return fmt.Errorf("%s does not contain exactly one app in Payload/", filepath.Base(ipaPath))
+	}
+	app := apps[0]
+
+	// Entitlements come from the new profile.
+	decoded, err := shell.Run("security", "cms", "-D", "-i", opts.Profile)
+	if err != nil || decoded.ExitCode != 0 {
+		return fmt.Errorf("failed to decode profile: %s", commandFailure(decoded, err))
+	}
+	profilePlist := filepath.Join(work, "profile.plist")
+	if err := os.WriteFile(profilePlist, []byte(decoded.Stdout), 0644); err != nil {
+		return err
+	}
+	entitlements := filepath.Join(work, "entitlements.plist")
+	if result, err := shell.Run("plutil", "-extract", "Entitlements", "xml1", "-o", entitlements, profilePlist); err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to extract entitlements: %s", commandFailure(result, err))
+	}
+
+	profileData, err := os.ReadFile(opts.Profile)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(app, "embedded.mobileprovision"), profileData, 0644); err != nil {
+		return err
+	}
+
+	if opts.BundleID != "" {
+		infoPlist := filepath.Join(app, "Info.plist")
+		if result, err := shell.Run(PlistBuddyPath, "-c", "Set :CFBundleIdentifier "+opts.BundleID, infoPlist); err != nil || result.ExitCode != 0 {
+			return fmt.Errorf("failed to set bundle identifier: %s", commandFailure(result, err))
+		}
+	}
+
+	// Sign nested code innermost first, then the app itself.
+	for _, path := range NestedCodePaths(app) {
+		if result, err := shell.Run("codesign", "-f", "-s", opts.Identity, path); err != nil || result.ExitCode != 0 {
+			return fmt.Errorf("failed to sign %s: %s", filepath.Base(path), commandFailure(result, err))
+		}
+	}
+	if result, err := shell.Run("codesign", "-f", "-s", opts.Identity, "--entitlements", entitlements, app); err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to sign %s: %s", filepath.Base(app), commandFailure(result, err))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return err
+	}
+	absOut, err := filepath.Abs(outPath)
+	if err != nil {
+		return err
+	}
+	_ = os.Remove(absOut)
+	if result, err := shell.RunInDir(work, "zip", "-qry", absOut, "Payload"); err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to package %s: %s", filepath.Base(outPath), commandFailure(result, err))
+	}
+	return nil
+}
+
+// commandFailure describes why a tool run failed: its stderr, else its
+// stdout (PlistBuddy reports errors there), else the exec error or exit code.
+func commandFailure(result *shell.Result, err error) string {
+	if msg := strings.TrimSpace(result.Stderr); msg != "" {
+		return msg
+	}
+	if msg := strings.TrimSpace(result.Stdout); msg != "" {
+		return msg
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("exit code %d", result.ExitCode)
+}
+
+// NestedCodePaths returns frameworks, dylibs, test bundles, and extensions
+// inside an app bundle, deepest first so they can be signed in order.
+func NestedCodePaths(app string) []string {
+	var paths []string
+	_ = filepath.Walk(app, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == app {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".framework", ".xctest", ".appex", ".app":
+			if info.IsDir() {
+				paths = append(paths, path)
+			}
+		case ".dylib":
+			if !info.IsDir() {
+				paths = append(paths, path)
+			}
+		}
+		return nil
+	})
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		di := strings.Count(paths[i], string(filepath.Separator))
+		dj := strings.Count(paths[j], string(filepath.Separator))
+		if di != dj {
+			return di > dj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
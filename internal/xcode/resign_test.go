@@ -0,0 +1,140 @@
+package xcode
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/undrift/drift/internal/testutil"
+)
+
+const sampleProfile = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>AppIDName</key>
+	<string>XC Wildcard</string>
+	<key>ExpirationDate</key>
+	<date>2027-03-01T12:00:00Z</date>
+	<key>Entitlements</key>
+	<dict>
+		<key>application-identifier</key>
+		<string>ABCDE12345.*</string>
+		<key>get-task-allow</key>
+		<true/>
+	</dict>
+	<key>Name</key>
+	<string>iOS Team Provisioning Profile: *</string>
+	<key>TeamIdentifier</key>
+	<array>
+		<string>ABCDE12345</string>
+	</array>
+</dict>
+</plist>`
+
+func TestParseProvisioningProfile(t *testing.T) {
+	profile, err := ParseProvisioningProfile([]byte(sampleProfile))
+	if err != nil {
+		t.Fatalf("ParseProvisioningProfile() error = %v", err)
+	}
+	if profile.Name != "iOS Team Provisioning Profile: *" {
+		t.Errorf("Name = %q", profile.Name)
+	}
+	if profile.TeamID != "ABCDE12345" {
+		t.Errorf("TeamID = %q", profile.TeamID)
+	}
+	if profile.AppID != "ABCDE12345.*" {
+		t.Errorf("AppID = %q", profile.AppID)
+	}
+	if want := time.Date(2027, 3, 1, 12, 0, 0, 0, time.UTC); !profile.ExpirationDate.Equal(want) {
+		t.Errorf("ExpirationDate = %v, want %v", profile.ExpirationDate, want)
+	}
+
+	if _, err := ParseProvisioningProfile([]byte("<plist><dict></dict></plist>")); err == nil {
+		t.Error("expected error for plist without team or app ID")
+	}
+}
+
+func TestProvisioningProfileMatches(t *testing.T) {
+	tests := []struct {
+		appID    string
+		teamID   string
+		bundleID string
+		want     bool
+	}{
+		{"ABCDE12345.*", "ABCDE12345", "com.facebook.WebDriverAgentRunner.xctrunner", true},
+		{"ABCDE12345.*", "ZZZZZ99999", "com.facebook.WebDriverAgentRunner.xctrunner", false},
+		{"ABCDE12345.com.example.*", "ABCDE12345", "com.example.wda.xctrunner", true},
+		{"ABCDE12345.com.example.*", "ABCDE12345", "com.other.wda", false},
+		{"ABCDE12345.com.example.wda", "ABCDE12345", "com.example.wda", true},
+		{"ABCDE12345.com.example.wda", "ABCDE12345", "com.example.wda.xctrunner", false},
+	}
+
+	for _, tt := range tests {
+		p := &ProvisioningProfile{TeamID: "ABCDE12345", AppID: tt.appID}
+		if got := p.Matches(tt.teamID, tt.bundleID); got != tt.want {
+			t.Errorf("Matches(%q, %q) with %q = %v, want %v", tt.teamID, tt.bundleID, tt.appID, got, tt.want)
+		}
+	}
+}
+
+func TestParseSigningIdentities(t *testing.T) {
+	output := `  1) 0123456789ABCDEF0123456789ABCDEF01234567 "Apple Development: Jane Doe (XYZ987WVU6)"
+  2) 89ABCDEF0123456789ABCDEF0123456789ABCDEF "Apple Distribution: Example Inc (ABCDE12345)"
+     2 valid identities found`
+
+	want := []SigningIdentity{
+		{Hash: "0123456789ABCDEF0123456789ABCDEF01234567", Name: "Apple Development: Jane Doe (XYZ987WVU6)"},
+		{Hash: "89ABCDEF0123456789ABCDEF0123456789ABCDEF", Name: "Apple Distribution: Example Inc (ABCDE12345)"},
+	}
+	if got := ParseSigningIdentities(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSigningIdentities() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNestedCodePaths(t *testing.T) {
+	app := filepath.Join(t.TempDir(), "WebDriverAgentRunner-Runner.app")
+	dirs := []string{
+		"Frameworks/XCTest.framework",
+		"PlugIns/WebDriverAgentRunner.xctest/Frameworks/WebDriverAgentLib.framework",
+	}
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(app, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(app, "Frameworks", "libswift.dylib"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := NestedCodePaths(app)
+	want := []string{
+		filepath.Join(app, "PlugIns/WebDriverAgentRunner.xctest/Frameworks/WebDriverAgentLib.framework"),
+		filepath.Join(app, "Frameworks/XCTest.framework"),
+		filepath.Join(app, "Frameworks/libswift.dylib"),
+		filepath.Join(app, "PlugIns/WebDriverAgentRunner.xctest"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NestedCodePaths() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestResignIPA_FailsOnToolExitCode(t *testing.T) {
+	testutil.NewFakeBin(t, "unzip", testutil.Response{Stderr: "End-of-central-directory signature not found", Exit: 9})
+
+	err := ResignIPA("App.ipa", filepath.Join(t.TempDir(), "out.ipa"), ResignOptions{Identity: "ABC"})
+	if err == nil || !strings.Contains(err.Error(), "End-of-central-directory") {
+		t.Errorf("ResignIPA() error = %v, want unzip's stderr", err)
+	}
+}
+
+func TestFindSigningIdentity_FailsOnExitCode(t *testing.T) {
+	testutil.NewFakeBin(t, "security", testutil.Response{Exit: 1})
+
+	_, err := FindSigningIdentity("ABCDE12345")
+	if err == nil || !strings.Contains(err.Error(), "exit code 1") {
+		t.Errorf("FindSigningIdentity() error = %v, want the exit code", err)
+	}
+}
@@ -156,37 +156,57 @@ func listSchemesViaXcodebuild(flag, path string) ([]string, error) {
 		return nil, fmt.Errorf("xcodebuild failed: %w", err)
 	}
 
-	// Parse JSON output
-	var output map[string]interface{}
-	if err := json.Unmarshal([]byte(result.Stdout), &output); err != nil {
+	schemes, err := ParseXcodebuildList(result.Stdout)
+	if err != nil {
 		// Fall back to text parsing
 		return parseSchemesFromText(result.Stdout), nil
 	}
+	return schemes, nil
+}
 
-	var schemes []string
+// xcodebuildList mirrors the JSON printed by xcodebuild -list -json.
+type xcodebuildList struct {
+	Project *struct {
+		Name    string   `json:"name"`
+		Schemes []string `json:"schemes"`
+	} `json:"project"`
+	Workspace *struct {
+		Name    string   `json:"name"`
+		Schemes []string `json:"schemes"`
+	} `json:"workspace"`
+}
 
-	// Handle workspace output
-	if ws, ok := output["workspace"].(map[string]interface{}); ok {
-		if schemeList, ok := ws["schemes"].([]interface{}); ok {
-			for _, s := range schemeList {
-				if name, ok := s.(string); ok {
-					schemes = append(schemes, name)
-				}
-			}
-		}
+// ParseXcodebuildList parses xcodebuild -list -json output into a
+// deduplicated list of scheme names. Any log lines xcodebuild prints
+// before the JSON object are skipped.
+func ParseXcodebuildList(output string) ([]string, error) {
+	start := strings.Index(output, "{")
+	if start == -1 {
+		return nil, fmt.Errorf("no JSON object in xcodebuild output")
 	}
 
-	// Handle project output
-	if proj, ok := output["project"].(map[string]interface{}); ok {
-		if schemeList, ok := proj["schemes"].([]interface{}); ok {
-			for _, s := range schemeList {
-				if name, ok := s.(string); ok {
-					schemes = append(schemes, name)
-				}
-			}
-		}
+	var list xcodebuildList
+	if err := json.Unmarshal([]byte(output[start:]), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse xcodebuild output: %w", err)
 	}
 
+	var names []string
+	if list.Workspace != nil {
+		names = append(names, list.Workspace.Schemes...)
+	}
+	if list.Project != nil {
+		names = append(names, list.Project.Schemes...)
+	}
+
+	seen := make(map[string]bool)
+	var schemes []string
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		schemes = append(schemes, name)
+	}
 	return schemes, nil
 }
 
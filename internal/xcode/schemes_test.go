@@ -0,0 +1,57 @@
+package xcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseXcodebuildList(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name: "project",
+			output: `{
+  "project" : {
+    "configurations" : ["Debug", "Release"],
+    "name" : "MyApp",
+    "schemes" : ["MyApp (Development)", "MyApp (Production)"],
+    "targets" : ["MyApp"]
+  }
+}`,
+			want: []string{"MyApp (Development)", "MyApp (Production)"},
+		},
+		{
+			name: "workspace with leading log line",
+			output: `Command line invocation:
+    /usr/bin/xcodebuild -list -json
+{
+  "workspace" : {
+    "name" : "MyApp",
+    "schemes" : ["MyApp", "Pods-MyApp", "MyApp"]
+  }
+}`,
+			want: []string{"MyApp", "Pods-MyApp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseXcodebuildList(tt.output)
+			if err != nil {
+				t.Fatalf("ParseXcodebuildList() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseXcodebuildList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseXcodebuildListInvalid(t *testing.T) {
+	if _, err := ParseXcodebuildList("xcodebuild: error: no project"); err == nil {
+		t.Error("expected error for output without JSON")
+	}
+}
@@ -14,62 +14,99 @@ type VersionInfo struct {
 	BuildNumber      int    // e.g., 42
 }
 
-// ReadVersionFile reads version information from Version.xcconfig.
+var (
+	buildNumberRe      = regexp.MustCompile(`CURRENT_PROJECT_VERSION\s*=\s*(\d+)`)
+	marketingVersionRe = regexp.MustCompile(`MARKETING_VERSION\s*=\s*([^\s]+)`)
+	versionAssignRe    = regexp.MustCompile(`^\s*(MARKETING_VERSION|CURRENT_PROJECT_VERSION)\s*=`)
+)
+
+// ReadVersionFile reads version information from Version.xcconfig. When the
+// file has drift markers, only the managed section is read.
 func ReadVersionFile(path string) (*VersionInfo, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read version file: %w", err)
 	}
+	return parseVersion(string(data)), nil
+}
 
-	info := &VersionInfo{}
-	content := string(data)
+func parseVersion(content string) *VersionInfo {
+	if start, end, ok := managedSection(content); ok {
+		content = content[start:end]
+	}
 
-	// Parse CURRENT_PROJECT_VERSION (build number)
-	buildRe := regexp.MustCompile(`CURRENT_PROJECT_VERSION\s*=\s*(\d+)`)
-	if matches := buildRe.FindStringSubmatch(content); len(matches) > 1 {
+	info := &VersionInfo{}
+	if matches := buildNumberRe.FindStringSubmatch(content); len(matches) > 1 {
 		info.BuildNumber, _ = strconv.Atoi(matches[1])
 	}
-
-	// Parse MARKETING_VERSION
-	versionRe := regexp.MustCompile(`MARKETING_VERSION\s*=\s*([^\s]+)`)
-	if matches := versionRe.FindStringSubmatch(content); len(matches) > 1 {
+	if matches := marketingVersionRe.FindStringSubmatch(content); len(matches) > 1 {
 		info.MarketingVersion = matches[1]
 	}
+	return info
+}
 
-	return info, nil
+// managedSection returns the byte range of the drift markers and what they
+// enclose.
+func managedSection(content string) (start, end int, ok bool) {
+	start = strings.Index(content, XcconfigDriftStart)
+	if start < 0 {
+		return 0, 0, false
+	}
+	n := strings.Index(content[start:], XcconfigDriftEnd)
+	if n < 0 {
+		return 0, 0, false
+	}
+	return start, start + n + len(XcconfigDriftEnd), true
 }
 
-// WriteVersionFile writes version information to Version.xcconfig.
+// versionSection renders the managed section of Version.xcconfig. An empty
+// marketing version is left out.
+func versionSection(info *VersionInfo) string {
+	var b strings.Builder
+	b.WriteString(XcconfigDriftStart + "\n")
+	b.WriteString("// Run 'drift version bump' or 'drift version set' to change these values.\n")
+	if info.MarketingVersion != "" {
+		fmt.Fprintf(&b, "MARKETING_VERSION = %s\n", info.MarketingVersion)
+	}
+	fmt.Fprintf(&b, "CURRENT_PROJECT_VERSION = %d\n", info.BuildNumber)
+	b.WriteString(XcconfigDriftEnd)
+	return b.String()
+}
+
+// WriteVersionFile writes version information to Version.xcconfig, between
+// drift markers. Settings outside the markers are preserved; a file written
+// before drift used markers has its version lines moved into them. An empty
+// MarketingVersion keeps the current one.
 func WriteVersionFile(path string, info *VersionInfo) error {
-	// Read existing content to preserve any additional settings
 	existingContent := ""
 	if data, err := os.ReadFile(path); err == nil {
 		existingContent = string(data)
 	}
 
-	// Update or add CURRENT_PROJECT_VERSION
-	buildRe := regexp.MustCompile(`CURRENT_PROJECT_VERSION\s*=\s*\d+`)
-	newBuild := fmt.Sprintf("CURRENT_PROJECT_VERSION = %d", info.BuildNumber)
-
-	if buildRe.MatchString(existingContent) {
-		existingContent = buildRe.ReplaceAllString(existingContent, newBuild)
-	} else {
-		existingContent = strings.TrimSpace(existingContent) + "\n" + newBuild + "\n"
+	merged := *info
+	if merged.MarketingVersion == "" {
+		merged.MarketingVersion = parseVersion(existingContent).MarketingVersion
 	}
+	section := versionSection(&merged)
 
-	// Update or add MARKETING_VERSION if provided
-	if info.MarketingVersion != "" {
-		versionRe := regexp.MustCompile(`MARKETING_VERSION\s*=\s*[^\s]+`)
-		newVersion := fmt.Sprintf("MARKETING_VERSION = %s", info.MarketingVersion)
-
-		if versionRe.MatchString(existingContent) {
-			existingContent = versionRe.ReplaceAllString(existingContent, newVersion)
-		} else {
-			existingContent = strings.TrimSpace(existingContent) + "\n" + newVersion + "\n"
+	var content string
+	if start, end, ok := managedSection(existingContent); ok {
+		content = existingContent[:start] + section + existingContent[end:]
+	} else {
+		var kept []string
+		for _, line := range strings.Split(existingContent, "\n") {
+			if !versionAssignRe.MatchString(line) {
+				kept = append(kept, line)
+			}
 		}
+		rest := strings.TrimSpace(strings.Join(kept, "\n"))
+		if rest == "" {
+			rest = versionFileHeader
+		}
+		content = rest + "\n\n" + section + "\n"
 	}
 
-	return os.WriteFile(path, []byte(existingContent), 0644)
+	return os.WriteFile(path, []byte(content), 0644)
 }
 
 // IncrementBuildNumber reads the version file, increments the build number, and writes it back.
@@ -129,15 +166,42 @@ func GetMarketingVersion(path string) (string, error) {
 	return info.MarketingVersion, nil
 }
 
+const versionFileHeader = `// Version.xcconfig
+// Managed by drift`
+
 // CreateVersionFile creates a new Version.xcconfig with initial values.
 func CreateVersionFile(path string, marketingVersion string, buildNumber int) error {
-	content := fmt.Sprintf(`// Version.xcconfig
-// Managed by drift
-
-MARKETING_VERSION = %s
-CURRENT_PROJECT_VERSION = %d
-`, marketingVersion, buildNumber)
-
+	info := &VersionInfo{MarketingVersion: marketingVersion, BuildNumber: buildNumber}
+	content := versionFileHeader + "\n\n" + versionSection(info) + "\n"
 	return os.WriteFile(path, []byte(content), 0644)
 }
 
+// BumpMarketingVersion increments the major, minor or patch component of a
+// dotted version, resetting the components after it: 1.4.2 bumped by minor
+// is 1.5.0. Missing components count as 0.
+func BumpMarketingVersion(version, part string) (string, error) {
+	fields := strings.Split(version, ".")
+	if version == "" || len(fields) > 3 {
+		return "", fmt.Errorf("cannot bump marketing version %q (expected MAJOR.MINOR.PATCH)", version)
+	}
+	nums := make([]int, 3)
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("cannot bump marketing version %q (expected MAJOR.MINOR.PATCH)", version)
+		}
+		nums[i] = n
+	}
+
+	switch part {
+	case "major":
+		nums = []int{nums[0] + 1, 0, 0}
+	case "minor":
+		nums = []int{nums[0], nums[1] + 1, 0}
+	case "patch":
+		nums[2]++
+	default:
+		return "", fmt.Errorf("unknown version part %q (use major, minor or patch)", part)
+	}
+	return fmt.Sprintf("%d.%d.%d", nums[0], nums[1], nums[2]), nil
+}
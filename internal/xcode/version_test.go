@@ -3,6 +3,7 @@ package xcode
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -246,6 +247,97 @@ func TestCreateVersionFile(t *testing.T) {
 	}
 }
 
+func TestWriteVersionFile_ManagedSection(t *testing.T) {
+	versionFile := filepath.Join(t.TempDir(), "Version.xcconfig")
+
+	// A file from before markers: version lines move into the managed
+	// section, other settings stay.
+	legacy := `// Version.xcconfig
+MARKETING_VERSION = 1.2.3
+CURRENT_PROJECT_VERSION = 42
+SWIFT_VERSION = 5.0
+`
+	if err := os.WriteFile(versionFile, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteVersionFile(versionFile, &VersionInfo{BuildNumber: 43}); err != nil {
+		t.Fatal(err)
+	}
+	want := `// Version.xcconfig
+SWIFT_VERSION = 5.0
+
+// === DRIFT MANAGED START ===
+// Run 'drift version bump' or 'drift version set' to change these values.
+MARKETING_VERSION = 1.2.3
+CURRENT_PROJECT_VERSION = 43
+// === DRIFT MANAGED END ===
+`
+	data, _ := os.ReadFile(versionFile)
+	if string(data) != want {
+		t.Fatalf("migrated file =\n%s\nwant\n%s", data, want)
+	}
+
+	// Later writes only touch the managed section.
+	edited := strings.Replace(string(data), "SWIFT_VERSION = 5.0", "SWIFT_VERSION = 6.0\nCURRENT_PROJECT_VERSION_NOTE = keep", 1)
+	if err := os.WriteFile(versionFile, []byte(edited+"// trailing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteVersionFile(versionFile, &VersionInfo{MarketingVersion: "2.0.0", BuildNumber: 1}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(versionFile)
+	for _, s := range []string{"SWIFT_VERSION = 6.0\n", "// trailing\n", "MARKETING_VERSION = 2.0.0\nCURRENT_PROJECT_VERSION = 1\n"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("file missing %q:\n%s", s, data)
+		}
+	}
+	if strings.Count(string(data), XcconfigDriftStart) != 1 {
+		t.Errorf("expected one managed section:\n%s", data)
+	}
+}
+
+func TestReadVersionFile_PrefersManagedSection(t *testing.T) {
+	versionFile := filepath.Join(t.TempDir(), "Version.xcconfig")
+	content := `// MARKETING_VERSION = 0.0.1 (old)
+// === DRIFT MANAGED START ===
+MARKETING_VERSION = 3.1.0
+CURRENT_PROJECT_VERSION = 7
+// === DRIFT MANAGED END ===
+`
+	if err := os.WriteFile(versionFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := ReadVersionFile(versionFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.MarketingVersion != "3.1.0" || info.BuildNumber != 7 {
+		t.Errorf("ReadVersionFile() = %+v, want 3.1.0 (7)", info)
+	}
+}
+
+func TestBumpMarketingVersion(t *testing.T) {
+	tests := []struct {
+		version, part, want string
+		wantErr             bool
+	}{
+		{"1.4.2", "patch", "1.4.3", false},
+		{"1.4.2", "minor", "1.5.0", false},
+		{"1.4.2", "major", "2.0.0", false},
+		{"1.4", "patch", "1.4.1", false},
+		{"2", "minor", "2.1.0", false},
+		{"1.4.2-beta", "patch", "", true},
+		{"", "patch", "", true},
+		{"1.4.2", "build", "", true},
+	}
+	for _, tt := range tests {
+		got, err := BumpMarketingVersion(tt.version, tt.part)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("BumpMarketingVersion(%q, %q) = %q, %v; want %q", tt.version, tt.part, got, err, tt.want)
+		}
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsImpl(s, substr))
 }
@@ -16,6 +16,23 @@ import (
 type XcconfigGenerator struct {
 	OutputPath     string
 	BuildServerDir string
+
+	// Seal, when set, is applied to every drift-managed KEY = VALUE before the
+	// file is written, so secret values can be replaced with store references.
+	Seal func(key, value string) (string, error)
+
+	// ShowBanner adds DRIFT_ENV_BANNER and APP_ICON_SUFFIX so the app can
+	// show which environment it points to.
+	ShowBanner    bool
+	BannerLabel   string
+	AppIconSuffix string
+
+	// VarPrefix is copied into XcconfigData by GenerateFromBranchInfo.
+	VarPrefix string
+
+	// CachedAt, when set, is when the credentials were fetched; the file is
+	// being written from drift's credential cache instead of the API.
+	CachedAt time.Time
 }
 
 // NewXcconfigGenerator creates a new xcconfig generator.
@@ -26,6 +43,13 @@ func NewXcconfigGenerator(outputPath string) *XcconfigGenerator {
 	}
 }
 
+// VariantPath returns the path of a per-variant xcconfig next to basePath,
+// e.g. Config.xcconfig + "Production" -> Config.Production.xcconfig.
+func VariantPath(basePath, variant string) string {
+	ext := filepath.Ext(basePath)
+	return strings.TrimSuffix(basePath, ext) + "." + variant + ext
+}
+
 // XcconfigData holds the data for xcconfig generation.
 type XcconfigData struct {
 	GitBranch       string
@@ -37,6 +61,17 @@ type XcconfigData struct {
 	IsFallback      bool
 	IsOverride      bool
 	GeneratedAt     time.Time
+	// CachedAt is when cached credentials were fetched, or zero when they
+	// came from the Supabase API just now.
+	CachedAt time.Time
+
+	ShowBanner    bool
+	BannerLabel   string
+	AppIconSuffix string
+
+	// VarPrefix is prepended to every drift-managed setting name, so several
+	// Supabase projects' settings can coexist.
+	VarPrefix string
 }
 
 // SupabaseBranchDisplay returns the branch name with fallback/override suffix.
@@ -72,19 +107,28 @@ const xcconfigTemplate = `// Config.xcconfig - SECRETS FILE (gitignored)
 // Using Fallback: {{.IsFallback}}
 // Using Override: {{.IsOverride}}
 // Generated: {{.GeneratedAt.Format "Mon Jan  2 15:04:05 MST 2006"}}
+{{- if not .CachedAt.IsZero}}
+// Generated from cache: credentials fetched {{.CachedAt.Format "Mon Jan  2 15:04:05 MST 2006"}}
+// Run 'drift env setup' online to refresh them.
+{{- end}}
 
 // === DRIFT MANAGED START ===
 // Variables below are managed by drift. Do not edit manually.
 // Run 'drift env setup' to update these values.
 
-SUPABASE_URL = {{.EscapedURL}}
-SUPABASE_ANON_KEY = {{.AnonKey}}
+{{.VarPrefix}}SUPABASE_URL = {{.EscapedURL}}
+{{.VarPrefix}}SUPABASE_ANON_KEY = {{.AnonKey}}
 
 // Branch info for environment badge (shown in non-production builds)
-GIT_BRANCH_NAME = {{.GitBranch}}
-SUPABASE_BRANCH_NAME = {{.SupabaseBranchDisplay}}
-DRIFT_ENVIRONMENT = {{.Environment}}
-
+{{.VarPrefix}}GIT_BRANCH_NAME = {{.GitBranch}}
+{{.VarPrefix}}SUPABASE_BRANCH_NAME = {{.SupabaseBranchDisplay}}
+{{.VarPrefix}}DRIFT_ENVIRONMENT = {{.Environment}}
+{{if .ShowBanner}}
+// Environment banner (empty in production). Use the icon suffix as
+// ASSETCATALOG_COMPILER_APPICON_NAME = AppIcon$(APP_ICON_SUFFIX)
+{{.VarPrefix}}DRIFT_ENV_BANNER = {{.BannerLabel}}
+{{.VarPrefix}}APP_ICON_SUFFIX = {{.AppIconSuffix}}
+{{end}}
 // === DRIFT MANAGED END ===
 
 // =============================================================================
@@ -94,6 +138,9 @@ DRIFT_ENVIRONMENT = {{.Environment}}
 
 // Generate generates the Config.xcconfig file, preserving user-added variables.
 func (g *XcconfigGenerator) Generate(data XcconfigData) error {
+	if data.VarPrefix == "" {
+		data.VarPrefix = g.VarPrefix
+	}
 	tmpl, err := template.New("xcconfig").Parse(xcconfigTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
@@ -105,6 +152,14 @@ func (g *XcconfigGenerator) Generate(data XcconfigData) error {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
+	managed := driftContent.String()
+	if g.Seal != nil {
+		managed, err = sealXcconfigLines(managed, g.Seal)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check if file exists and has user content to preserve
 	userContent := ""
 	if existingData, err := os.ReadFile(g.OutputPath); err == nil {
@@ -112,7 +167,7 @@ func (g *XcconfigGenerator) Generate(data XcconfigData) error {
 	}
 
 	// Combine drift content with user content
-	finalContent := driftContent.String()
+	finalContent := managed
 	if userContent != "" {
 		finalContent = strings.TrimSuffix(finalContent, "\n") + "\n" + userContent
 	}
@@ -131,6 +186,28 @@ func (g *XcconfigGenerator) Generate(data XcconfigData) error {
 	return nil
 }
 
+// sealXcconfigLines passes each KEY = VALUE line in content through seal.
+func sealXcconfigLines(content string, seal func(key, value string) (string, error)) (string, error) {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		parts := strings.SplitN(trimmed, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		sealed, err := seal(key, strings.TrimSpace(parts[1]))
+		if err != nil {
+			return "", err
+		}
+		lines[i] = key + " = " + sealed
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 // extractXcconfigUserContent extracts user-added content from an existing xcconfig file.
 func extractXcconfigUserContent(content string) string {
 	// Check if file has drift markers
@@ -226,6 +303,11 @@ func (g *XcconfigGenerator) GenerateFromBranchInfo(info *supabase.BranchInfo, an
 		IsFallback:     info.IsFallback,
 		IsOverride:     info.IsOverride,
 		GeneratedAt:    time.Now(),
+		ShowBanner:     g.ShowBanner,
+		BannerLabel:    g.BannerLabel,
+		AppIconSuffix:  g.AppIconSuffix,
+		VarPrefix:      g.VarPrefix,
+		CachedAt:       g.CachedAt,
 	}
 
 	return g.Generate(data)
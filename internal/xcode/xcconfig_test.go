@@ -3,6 +3,7 @@ package xcode
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -142,6 +143,39 @@ func TestGenerateFromBranchInfo(t *testing.T) {
 	}
 }
 
+func TestGenerateFromBranchInfo_CachedAt(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "Config.xcconfig")
+	gen := NewXcconfigGenerator(configPath)
+	gen.CachedAt = time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+
+	info := &supabase.BranchInfo{
+		GitBranch:      "development",
+		SupabaseBranch: &supabase.Branch{Name: "development"},
+		ProjectRef:     "devref456",
+		APIURL:         "https://devref456.supabase.co",
+		Environment:    supabase.EnvDevelopment,
+	}
+	if err := gen.GenerateFromBranchInfo(info, "dev-anon-key"); err != nil {
+		t.Fatalf("GenerateFromBranchInfo failed: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "// Generated from cache: credentials fetched Wed Mar  4 09:30:00 UTC 2026"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("header does not note the cache:\n%s", data)
+	}
+	values, err := ReadXcconfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["SUPABASE_ANON_KEY"] != "dev-anon-key" {
+		t.Errorf("SUPABASE_ANON_KEY = %q, want dev-anon-key", values["SUPABASE_ANON_KEY"])
+	}
+}
+
 func TestReadXcconfig(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "Config.xcconfig")
@@ -283,3 +317,48 @@ func containsString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestVariantPath(t *testing.T) {
+	tests := []struct {
+		base    string
+		variant string
+		want    string
+	}{
+		{"/app/Config.xcconfig", "Production", "/app/Config.Production.xcconfig"},
+		{"Configs/Secrets.xcconfig", "Debug", "Configs/Secrets.Debug.xcconfig"},
+		{"Config", "Feature", "Config.Feature"},
+	}
+
+	for _, tt := range tests {
+		if got := VariantPath(tt.base, tt.variant); got != tt.want {
+			t.Errorf("VariantPath(%q, %q) = %q, want %q", tt.base, tt.variant, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateFromBranchInfoBanner(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "Config.xcconfig")
+	gen := NewXcconfigGenerator(configPath)
+	gen.ShowBanner = true
+	gen.BannerLabel = "DEVELOPMENT"
+	gen.AppIconSuffix = "-dev"
+
+	info := &supabase.BranchInfo{
+		GitBranch:      "development",
+		Environment:    supabase.EnvDevelopment,
+		ProjectRef:     "abc",
+		APIURL:         "https://abc.supabase.co",
+		SupabaseBranch: &supabase.Branch{Name: "development"},
+	}
+	if err := gen.GenerateFromBranchInfo(info, "anon"); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := ReadXcconfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["DRIFT_ENV_BANNER"] != "DEVELOPMENT" || values["APP_ICON_SUFFIX"] != "-dev" {
+		t.Errorf("banner values = %q, %q", values["DRIFT_ENV_BANNER"], values["APP_ICON_SUFFIX"])
+	}
+}
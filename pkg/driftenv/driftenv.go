@@ -0,0 +1,175 @@
+// Package driftenv resolves the drift environment for the current git branch
+// at runtime, so Go services can load branch-aware Supabase configuration at
+// startup instead of copying the .env.local generated by 'drift env setup'.
+//
+// Resolution follows the drift CLI: the Supabase branch matching the git
+// branch (or supabase.override_branch), then supabase.fallback_branch from
+// .drift.local.yaml. Credentials are fetched with the Supabase CLI, which
+// must be installed and logged in.
+//
+// The API mirrors github.com/joho/godotenv:
+//
+//	if err := driftenv.Load(); err != nil {
+//		log.Fatal(err)
+//	}
+//	url := os.Getenv("SUPABASE_URL")
+package driftenv
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/undrift/drift/internal/config"
+	"github.com/undrift/drift/internal/git"
+	"github.com/undrift/drift/internal/supabase"
+)
+
+// Options controls how the environment is resolved. The zero value resolves
+// the current git branch using .drift.yaml found from the working directory.
+type Options struct {
+	// GitBranch overrides the current git branch, e.g. in containers
+	// without a .git directory.
+	GitBranch string
+	// FallbackBranch overrides supabase.fallback_branch.
+	FallbackBranch string
+}
+
+// Env is a resolved drift environment.
+type Env struct {
+	GitBranch      string
+	SupabaseBranch string
+	Environment    string // Production, Development, or Feature
+	ProjectRef     string
+	URL            string
+	AnonKey        string
+	ServiceRoleKey string
+	DatabaseURL    string // direct connection; empty for production
+	PoolerURL      string // transaction pooler; empty for production
+	IsFallback     bool
+	IsOverride     bool
+}
+
+// Resolve finds the Supabase branch for the git branch and fetches its keys.
+func Resolve(opts Options) (*Env, error) {
+	cfg, err := config.LoadWithLocal()
+	if err != nil {
+		return nil, fmt.Errorf("driftenv: %w", err)
+	}
+	ttl, _ := time.ParseDuration(cfg.Supabase.CacheTTL)
+	supabase.ConfigureCache(cfg.ProjectRoot(), ttl)
+
+	gitBranch := opts.GitBranch
+	if gitBranch == "" {
+		if gitBranch, err = git.CurrentBranch(); err != nil {
+			return nil, fmt.Errorf("driftenv: failed to get current branch: %w", err)
+		}
+	}
+	fallback := opts.FallbackBranch
+	if fallback == "" {
+		fallback = cfg.Supabase.FallbackBranch
+	}
+
+	client := supabase.NewClient()
+	info, err := client.ResolveTarget(supabase.TargetOptions{
+		GitBranch:             gitBranch,
+		OverrideBranch:        cfg.Supabase.OverrideBranch,
+		FallbackBranch:        fallback,
+		DisallowProdSelection: cfg.Supabase.OverrideBranch != "",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("driftenv: %w", err)
+	}
+
+	env := newEnv(info)
+	if info.Environment != supabase.EnvProduction {
+		if secrets, err := client.GetBranchSecrets(info.SupabaseBranch.Name); err == nil {
+			env.AnonKey = secrets.SupabaseAnonKey
+			env.ServiceRoleKey = secrets.SupabaseServiceRoleKey
+			env.DatabaseURL = secrets.PostgresURLNonPooling
+			env.PoolerURL = secrets.PostgresURL
+		}
+	}
+	if env.AnonKey == "" {
+		if env.AnonKey, err = client.GetAnonKey(info.ProjectRef); err != nil {
+			return nil, fmt.Errorf("driftenv: failed to get anon key: %w", err)
+		}
+		env.ServiceRoleKey, _ = client.GetServiceKey(info.ProjectRef)
+	}
+	return env, nil
+}
+
+func newEnv(info *supabase.BranchInfo) *Env {
+	return &Env{
+		GitBranch:      info.GitBranch,
+		SupabaseBranch: info.SupabaseBranch.Name,
+		Environment:    string(info.Environment),
+		ProjectRef:     info.ProjectRef,
+		URL:            info.APIURL,
+		IsFallback:     info.IsFallback,
+		IsOverride:     info.IsOverride,
+	}
+}
+
+// Map returns the environment as variables, using the names 'drift test'
+// exports. Empty values are left out.
+func (e *Env) Map() map[string]string {
+	values := map[string]string{
+		"SUPABASE_URL":              e.URL,
+		"SUPABASE_ANON_KEY":         e.AnonKey,
+		"SUPABASE_SERVICE_ROLE_KEY": e.ServiceRoleKey,
+		"SUPABASE_PROJECT_REF":      e.ProjectRef,
+		"DATABASE_URL":              e.DatabaseURL,
+		"DATABASE_URL_POOLER":       e.PoolerURL,
+		"GIT_BRANCH_NAME":           e.GitBranch,
+		"SUPABASE_BRANCH_NAME":      e.SupabaseBranch,
+		"DRIFT_ENVIRONMENT":         e.Environment,
+	}
+	for k, v := range values {
+		if v == "" {
+			delete(values, k)
+		}
+	}
+	return values
+}
+
+// Read resolves the environment for the current branch and returns it as a map.
+func Read() (map[string]string, error) {
+	env, err := Resolve(Options{})
+	if err != nil {
+		return nil, err
+	}
+	return env.Map(), nil
+}
+
+// Load resolves the environment for the current branch and sets its
+// variables in the process environment. Variables that are already set are
+// left alone, so explicit configuration wins.
+func Load() error {
+	values, err := Read()
+	if err != nil {
+		return err
+	}
+	return apply(values, false)
+}
+
+// Overload is like Load but overwrites variables that are already set.
+func Overload() error {
+	values, err := Read()
+	if err != nil {
+		return err
+	}
+	return apply(values, true)
+}
+
+func apply(values map[string]string, override bool) error {
+	for k, v := range values {
+		if _, set := os.LookupEnv(k); set && !override {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return fmt.Errorf("driftenv: failed to set %s: %w", k, err)
+		}
+	}
+	return nil
+}
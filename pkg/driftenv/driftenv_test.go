@@ -0,0 +1,63 @@
+package driftenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEnvMap(t *testing.T) {
+	env := &Env{
+		GitBranch:      "feature/x",
+		SupabaseBranch: "feature-x",
+		Environment:    "Feature",
+		ProjectRef:     "abc",
+		URL:            "https://abc.supabase.co",
+		AnonKey:        "anon",
+	}
+
+	got := env.Map()
+	want := map[string]string{
+		"SUPABASE_URL":         "https://abc.supabase.co",
+		"SUPABASE_ANON_KEY":    "anon",
+		"SUPABASE_PROJECT_REF": "abc",
+		"GIT_BRANCH_NAME":      "feature/x",
+		"SUPABASE_BRANCH_NAME": "feature-x",
+		"DRIFT_ENVIRONMENT":    "Feature",
+	}
+	if len(got) != len(want) {
+		t.Errorf("Map() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("Map()[%s] = %q, want %q", k, got[k], v)
+		}
+	}
+	if _, ok := got["SUPABASE_SERVICE_ROLE_KEY"]; ok {
+		t.Error("Map() includes empty SUPABASE_SERVICE_ROLE_KEY")
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Setenv("SUPABASE_URL", "https://explicit.example.com")
+	t.Setenv("DRIFT_ENVIRONMENT", "")
+	os.Unsetenv("DRIFT_ENVIRONMENT")
+
+	values := map[string]string{"SUPABASE_URL": "https://abc.supabase.co", "DRIFT_ENVIRONMENT": "Feature"}
+
+	if err := apply(values, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("SUPABASE_URL"); got != "https://explicit.example.com" {
+		t.Errorf("Load overwrote SUPABASE_URL: %q", got)
+	}
+	if got := os.Getenv("DRIFT_ENVIRONMENT"); got != "Feature" {
+		t.Errorf("DRIFT_ENVIRONMENT = %q, want Feature", got)
+	}
+
+	if err := apply(values, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("SUPABASE_URL"); got != "https://abc.supabase.co" {
+		t.Errorf("Overload kept SUPABASE_URL: %q", got)
+	}
+}
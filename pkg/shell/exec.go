@@ -25,6 +25,15 @@ func IsVerbose() bool {
 	return verboseMode
 }
 
+// observer, if set, is told how long each command took.
+var observer func(name string, d time.Duration)
+
+// SetObserver registers fn to be called with the name and duration of every
+// command run through this package. Pass nil to remove it.
+func SetObserver(fn func(name string, d time.Duration)) {
+	observer = fn
+}
+
 // Result holds the output and exit code of a command execution.
 type Result struct {
 	Stdout   string
@@ -143,6 +152,9 @@ func runCmd(ctx context.Context, dir string, env map[string]string, interactive
 		ExitCode: 0,
 		Duration: time.Since(start),
 	}
+	if observer != nil {
+		observer(name, result.Duration)
+	}
 
 	// Log result if verbose mode is enabled
 	if verboseMode && !interactive {
@@ -291,6 +303,9 @@ func RunWithInput(input string, name string, args ...string) (*Result, error) {
 		ExitCode: 0,
 		Duration: time.Since(start),
 	}
+	if observer != nil {
+		observer(name, result.Duration)
+	}
 
 	if err == nil {
 		return result, nil